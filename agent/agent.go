@@ -46,6 +46,12 @@ func NewAgent(cfg *config.Config) *Agent {
 type inputUnit struct {
 	dst    chan<- telegraf.Metric
 	inputs []*models.RunningInput
+
+	// Service inputs with dependencies are started once the dependencies
+	// are available, using the given accumulator
+	delayed map[*models.RunningInput]telegraf.Accumulator
+	started map[*models.RunningInput]bool
+	sync.Mutex
 }
 
 //  ______     ┌───────────┐     ______
@@ -335,7 +341,9 @@ func (a *Agent) startInputs(
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dst:     dst,
+		delayed: make(map[*models.RunningInput]telegraf.Accumulator),
+		started: make(map[*models.RunningInput]bool),
 	}
 
 	for _, input := range inputs {
@@ -355,9 +363,15 @@ func (a *Agent) startInputs(
 			acc := NewAccumulator(input, dst)
 			acc.SetPrecision(getPrecision(precision, interval))
 
+			if input.HasDependencies() {
+				unit.delayed[input] = acc
+				unit.inputs = append(unit.inputs, input)
+				continue
+			}
+
 			err := si.Start(acc)
 			if err != nil {
-				stopServiceInputs(unit.inputs)
+				stopServiceInputs(unit)
 				return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
 			}
 		}
@@ -417,6 +431,13 @@ func (a *Agent) runInputs(
 		wg.Add(1)
 		go func(input *models.RunningInput) {
 			defer wg.Done()
+			if !input.WaitForDependencies(ctx) {
+				return
+			}
+			if err := unit.startDelayed(input); err != nil {
+				log.Printf("E! [agent] Starting input %s: %v", input.LogName(), err)
+				return
+			}
 			a.gatherLoop(ctx, acc, input, ticker, interval)
 		}(input)
 	}
@@ -424,7 +445,7 @@ func (a *Agent) runInputs(
 	wg.Wait()
 
 	log.Printf("D! [agent] Stopping service inputs")
-	stopServiceInputs(unit.inputs)
+	stopServiceInputs(unit)
 
 	close(unit.dst)
 	log.Printf("D! [agent] Input channel closed")
@@ -440,7 +461,9 @@ func (a *Agent) testStartInputs(
 	log.Printf("D! [agent] Starting service inputs")
 
 	unit := &inputUnit{
-		dst: dst,
+		dst:     dst,
+		delayed: make(map[*models.RunningInput]telegraf.Accumulator),
+		started: make(map[*models.RunningInput]bool),
 	}
 
 	for _, input := range inputs {
@@ -452,6 +475,12 @@ func (a *Agent) testStartInputs(
 			acc := NewAccumulator(input, dst)
 			acc.SetPrecision(time.Nanosecond)
 
+			if input.HasDependencies() {
+				unit.delayed[input] = acc
+				unit.inputs = append(unit.inputs, input)
+				continue
+			}
+
 			err := si.Start(acc)
 			if err != nil {
 				log.Printf("E! [agent] Starting input %s: %v", input.LogName(), err)
@@ -485,6 +514,14 @@ func (a *Agent) testRunInputs(
 		go func(input *models.RunningInput) {
 			defer wg.Done()
 
+			if !input.WaitForDependencies(ctx) {
+				return
+			}
+			if err := unit.startDelayed(input); err != nil {
+				log.Printf("E! [agent] Starting input %s: %v", input.LogName(), err)
+				return
+			}
+
 			// Overwrite agent interval if this plugin has its own.
 			interval := time.Duration(a.Config.Agent.Interval)
 			if input.Config.Interval != 0 {
@@ -525,15 +562,38 @@ func (a *Agent) testRunInputs(
 	}
 
 	log.Printf("D! [agent] Stopping service inputs")
-	stopServiceInputs(unit.inputs)
+	stopServiceInputs(unit)
 
 	close(unit.dst)
 	log.Printf("D! [agent] Input channel closed")
 }
 
-// stopServiceInputs stops all service inputs.
-func stopServiceInputs(inputs []*models.RunningInput) {
-	for _, input := range inputs {
+// startDelayed starts the service input if it was delayed until its
+// dependencies are available.
+func (u *inputUnit) startDelayed(input *models.RunningInput) error {
+	acc, found := u.delayed[input]
+	if !found {
+		return nil
+	}
+	if err := input.Input.(telegraf.ServiceInput).Start(acc); err != nil {
+		return err
+	}
+
+	u.Lock()
+	u.started[input] = true
+	u.Unlock()
+	return nil
+}
+
+// stopServiceInputs stops all started service inputs.
+func stopServiceInputs(unit *inputUnit) {
+	unit.Lock()
+	defer unit.Unlock()
+
+	for _, input := range unit.inputs {
+		if _, found := unit.delayed[input]; found && !unit.started[input] {
+			continue
+		}
 		if si, ok := input.Input.(telegraf.ServiceInput); ok {
 			si.Stop()
 		}
@@ -868,7 +928,17 @@ func (a *Agent) runOutputs(
 		}(output)
 	}
 
+	var dedup *deduplicator
+	if a.Config.Agent.DedupWindow > 0 {
+		dedup = newDeduplicator(time.Duration(a.Config.Agent.DedupWindow))
+	}
+
 	for metric := range unit.src {
+		if dedup != nil && dedup.isDuplicate(metric) {
+			metric.Drop()
+			continue
+		}
+
 		for i, output := range unit.outputs {
 			if i == len(a.Config.Outputs)-1 {
 				output.AddMetric(metric)
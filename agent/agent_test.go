@@ -178,6 +178,44 @@ func TestWindow(t *testing.T) {
 	}
 }
 
+func TestDelayedServiceInput(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "ready")
+	input := &serviceInput{}
+	ri := models.NewRunningInput(input, &models.InputConfig{
+		Name:                 "service",
+		StartupErrorBehavior: "retry",
+		StartupProbes:        []string{"file://" + fn},
+		StartupProbeTimeout:  10 * time.Millisecond,
+	})
+	require.NoError(t, ri.Init())
+
+	c := config.NewConfig()
+	c.Agent.Interval = config.Duration(10 * time.Millisecond)
+	a := NewAgent(c)
+
+	// The input must not be started before its dependencies are available
+	dst := make(chan telegraf.Metric, 100)
+	unit, err := a.startInputs(dst, []*models.RunningInput{ri})
+	require.NoError(t, err)
+	require.False(t, input.isStarted())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.runInputs(ctx, time.Now(), unit)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, input.isStarted())
+
+	require.NoError(t, os.WriteFile(fn, nil, 0600))
+	require.Eventually(t, input.isStarted, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+	require.True(t, input.stopped)
+}
+
 func TestCases(t *testing.T) {
 	// Get all directories in testcases
 	folders, err := os.ReadDir("testcases")
@@ -260,3 +298,36 @@ func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metr
 	}
 	return received, nil
 }
+
+type serviceInput struct {
+	started bool
+	stopped bool
+	sync.Mutex
+}
+
+func (*serviceInput) SampleConfig() string {
+	return ""
+}
+
+func (i *serviceInput) Start(telegraf.Accumulator) error {
+	i.Lock()
+	defer i.Unlock()
+	i.started = true
+	return nil
+}
+
+func (*serviceInput) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (i *serviceInput) Stop() {
+	i.Lock()
+	defer i.Unlock()
+	i.stopped = true
+}
+
+func (i *serviceInput) isStarted() bool {
+	i.Lock()
+	defer i.Unlock()
+	return i.started
+}
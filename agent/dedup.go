@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// AgentMetricsDeduplicated counts the metrics dropped by the agent-level
+// deduplication stage.
+var AgentMetricsDeduplicated = selfstat.Register("agent", "metrics_deduplicated", map[string]string{})
+
+// deduplicator drops exact duplicates of metrics seen within a sliding window.
+// Two metrics are considered identical if they share the name, tags, fields
+// and timestamp.
+type deduplicator struct {
+	window  time.Duration
+	seen    map[uint64]time.Time
+	cleaned time.Time
+	now     func() time.Time
+}
+
+func newDeduplicator(window time.Duration) *deduplicator {
+	return &deduplicator{
+		window:  window,
+		seen:    make(map[uint64]time.Time),
+		cleaned: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// isDuplicate returns true if an identical metric passed the deduplicator
+// within the window. The metric is remembered otherwise.
+func (d *deduplicator) isDuplicate(m telegraf.Metric) bool {
+	now := d.now()
+	d.cleanup(now)
+
+	id := pointHash(m)
+	if last, found := d.seen[id]; found && now.Sub(last) < d.window {
+		AgentMetricsDeduplicated.Incr(1)
+		return true
+	}
+	d.seen[id] = now
+	return false
+}
+
+// cleanup removes expired entries, but at most once per window to save CPU.
+func (d *deduplicator) cleanup(now time.Time) {
+	if now.Sub(d.cleaned) < d.window {
+		return
+	}
+	d.cleaned = now
+	for id, last := range d.seen {
+		if now.Sub(last) >= d.window {
+			delete(d.seen, id)
+		}
+	}
+}
+
+// pointHash computes a hash over the series, the fields and the timestamp of
+// the metric.
+func pointHash(m telegraf.Metric) uint64 {
+	var buf [8]byte
+
+	h := fnv.New64a()
+	binary.LittleEndian.PutUint64(buf[:], m.HashID())
+	h.Write(buf[:])
+
+	fields := m.FieldList()
+	keys := make([]string, 0, len(fields))
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+		values[f.Key] = f.Value
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte("\n"))
+		switch v := values[k].(type) {
+		case float64:
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			h.Write([]byte{'f'})
+			h.Write(buf[:])
+		case int64:
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+			h.Write([]byte{'i'})
+			h.Write(buf[:])
+		case uint64:
+			binary.LittleEndian.PutUint64(buf[:], v)
+			h.Write([]byte{'u'})
+			h.Write(buf[:])
+		case bool:
+			if v {
+				h.Write([]byte{'b', 1})
+			} else {
+				h.Write([]byte{'b', 0})
+			}
+		case string:
+			h.Write([]byte{'s'})
+			h.Write([]byte(v))
+		}
+		h.Write([]byte("\n"))
+	}
+
+	binary.LittleEndian.PutUint64(buf[:], uint64(m.Time().UnixNano()))
+	h.Write(buf[:])
+
+	return h.Sum64()
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestDeduplicatorDropsIdenticalPoints(t *testing.T) {
+	now := time.Now()
+	d := newDeduplicator(time.Minute)
+	d.now = func() time.Time { return now }
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage": 42.0, "count": int64(3)},
+		time.Unix(0, 0),
+	)
+
+	require.False(t, d.isDuplicate(m))
+	require.True(t, d.isDuplicate(m.Copy()))
+
+	// Differing field values, tags or timestamps are no duplicates
+	changed := m.Copy()
+	changed.AddField("usage", 43.0)
+	require.False(t, d.isDuplicate(changed))
+
+	tagged := m.Copy()
+	tagged.AddTag("host", "b")
+	require.False(t, d.isDuplicate(tagged))
+
+	later := m.Copy()
+	later.SetTime(time.Unix(10, 0))
+	require.False(t, d.isDuplicate(later))
+}
+
+func TestDeduplicatorWindowExpires(t *testing.T) {
+	now := time.Now()
+	d := newDeduplicator(time.Minute)
+	d.now = func() time.Time { return now }
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"usage": 42.0},
+		time.Unix(0, 0),
+	)
+
+	require.False(t, d.isDuplicate(m))
+	now = now.Add(30 * time.Second)
+	require.True(t, d.isDuplicate(m))
+	now = now.Add(2 * time.Minute)
+	require.False(t, d.isDuplicate(m))
+	require.Len(t, d.seen, 1)
+}
+
+func TestPointHashFieldOrder(t *testing.T) {
+	a := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"a": int64(1), "b": "x"},
+		time.Unix(0, 0),
+	)
+	b := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"b": "x", "a": int64(1)},
+		time.Unix(0, 0),
+	)
+	c := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"a": uint64(1), "b": "x"},
+		time.Unix(0, 0),
+	)
+	require.Equal(t, pointHash(a), pointHash(b))
+	require.NotEqual(t, pointHash(a), pointHash(c))
+}
@@ -265,6 +265,11 @@ type AgentConfig struct {
 	// Flag to always keep tags explicitly defined in the global tags section
 	// and ensure those tags always pass filtering.
 	AlwaysIncludeGlobalTags bool `toml:"always_include_global_tags"`
+
+	// DedupWindow enables dropping exact duplicates (same name, tags, fields
+	// and timestamp) seen within the given window before metrics are handed
+	// to the outputs. A value of zero disables deduplication.
+	DedupWindow Duration `toml:"dedup_window"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -1117,9 +1122,11 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
+	var serializer *models.RunningSerializer
 	if t, ok := output.(telegraf.SerializerPlugin); ok {
 		missThreshold = 1
-		serializer, err := c.addSerializer(name, table)
+		var err error
+		serializer, err = c.addSerializer(name, table)
 		if err != nil {
 			return err
 		}
@@ -1128,7 +1135,8 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		// Keep the old interface for backward compatibility
 		// DEPRECATED: Please switch your plugin to telegraf.Serializers
 		missThreshold = 1
-		serializer, err := c.addSerializer(name, table)
+		var err error
+		serializer, err = c.addSerializer(name, table)
 		if err != nil {
 			return err
 		}
@@ -1139,6 +1147,18 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	if outputConfig.MaxBatchBytes > 0 {
+		if serializer == nil {
+			return fmt.Errorf("max_batch_bytes is not supported by output %q as it does not use a serializer", name)
+		}
+		// Measure batches with a separate instance as serializers might keep
+		// state between calls and are not safe for concurrent use
+		measure, err := c.addSerializer(name, table)
+		if err != nil {
+			return err
+		}
+		outputConfig.Serializer = measure.Serializer
+	}
 
 	if err := c.toml.UnmarshalTable(table, output); err != nil {
 		return err
@@ -1415,6 +1435,9 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 	c.getFieldString(tbl, "name_suffix", &cp.MeasurementSuffix)
 	c.getFieldString(tbl, "name_override", &cp.NameOverride)
 	c.getFieldString(tbl, "alias", &cp.Alias)
+	c.getFieldString(tbl, "startup_error_behavior", &cp.StartupErrorBehavior)
+	c.getFieldStringSlice(tbl, "startup_probes", &cp.StartupProbes)
+	c.getFieldDuration(tbl, "startup_probe_timeout", &cp.StartupProbeTimeout)
 
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1461,6 +1484,7 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 
 	c.getFieldInt(tbl, "metric_buffer_limit", &oc.MetricBufferLimit)
 	c.getFieldInt(tbl, "metric_batch_size", &oc.MetricBatchSize)
+	c.getFieldInt(tbl, "max_batch_bytes", &oc.MaxBatchBytes)
 	c.getFieldString(tbl, "alias", &oc.Alias)
 	c.getFieldString(tbl, "name_override", &oc.NameOverride)
 	c.getFieldString(tbl, "name_suffix", &oc.NameSuffix)
@@ -1485,10 +1509,11 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 		"grace",
 		"interval",
 		"lvm", // What is this used for?
-		"metric_batch_size", "metric_buffer_limit", "metricpass",
+		"max_batch_bytes", "metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
 		"pass", "period", "precision",
+		"startup_error_behavior", "startup_probe_timeout", "startup_probes",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags":
 
 	// Secret-store options to ignore
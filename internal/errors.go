@@ -0,0 +1,24 @@
+package internal
+
+// PartialWriteError indicates that only a subset of the metrics of a batch was
+// handled by an output. Accepted metrics are removed from the buffer as
+// written, rejected metrics are dropped and all other metrics are kept in the
+// buffer for the next write. The metrics are specified as indices into the
+// batch. If Err is nil, keeping metrics is not considered an error, e.g. for
+// outputs acknowledging metrics only after they are persisted.
+type PartialWriteError struct {
+	Err           error
+	MetricsAccept []int
+	MetricsReject []int
+}
+
+func (e *PartialWriteError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return "partial write"
+}
+
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
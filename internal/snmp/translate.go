@@ -145,6 +145,12 @@ func walkPaths(paths []string, log telegraf.Logger) ([]string, error) {
 type MibEntry struct {
 	MibName string
 	OidText string
+
+	// Conversion to apply to values of the object derived from its textual
+	// convention, e.g. "hwaddr", "ipaddr" or "datetime"
+	Conversion string
+	// Enums maps the integer values of enumerated objects to their names
+	Enums map[int64]string
 }
 
 func TrapLookup(oid string) (e MibEntry, err error) {
@@ -171,9 +177,33 @@ func TrapLookup(oid string) (e MibEntry, err error) {
 		e.MibName = module.Name
 	}
 
+	// Get information for formatting the values of the object
+	if node.Type != nil {
+		e.Conversion = typeConversion(node.Type.Name)
+		if node.Type.Enum != nil {
+			e.Enums = make(map[int64]string, len(node.Type.Enum.Values))
+			for _, v := range node.Type.Enum.Values {
+				e.Enums[v.Value] = v.Name
+			}
+		}
+	}
+
 	return e, nil
 }
 
+// typeConversion returns the value conversion for the given textual convention
+func typeConversion(name string) string {
+	switch name {
+	case "MacAddress", "PhysAddress":
+		return "hwaddr"
+	case "InetAddressIPv4", "InetAddressIPv6", "InetAddress", "IPSIpAddress":
+		return "ipaddr"
+	case "DateAndTime":
+		return "datetime"
+	}
+	return ""
+}
+
 // The following is for snmp
 
 func GetIndex(mibPrefix string, node gosmi.SmiNode) (col []string, tagOids map[string]struct{}) {
@@ -260,11 +290,9 @@ func SnmpTranslateCall(oid string) (mibName string, oidNum string, oidText strin
 		if tc[i].Type == nil {
 			break
 		}
-		switch tc[i].Type.Name {
-		case "MacAddress", "PhysAddress":
-			conversion = "hwaddr"
-		case "InetAddressIPv4", "InetAddressIPv6", "InetAddress", "IPSIpAddress":
-			conversion = "ipaddr"
+		switch c := typeConversion(tc[i].Type.Name); c {
+		case "hwaddr", "ipaddr":
+			conversion = c
 		}
 	}
 
@@ -90,6 +90,44 @@ func TestTrapLookupFail(t *testing.T) {
 	}
 }
 
+func TestTrapLookupValueFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		oid      string
+		expected MibEntry
+	}{
+		{
+			name: "Textual convention",
+			oid:  ".1.3.6.1.4.1.65000.1.3",
+			expected: MibEntry{
+				MibName:    "TGTEST-VALUES-MIB",
+				OidText:    "tgTestAddress.3",
+				Conversion: "hwaddr",
+			},
+		},
+		{
+			name: "Enumeration",
+			oid:  ".1.3.6.1.4.1.65000.2.3",
+			expected: MibEntry{
+				MibName: "TGTEST-VALUES-MIB",
+				OidText: "tgTestStatus.3",
+				Enums:   map[int64]string{1: "up", 2: "down"},
+			},
+		},
+	}
+
+	// Load the MIBs
+	require.NoError(t, LoadMibsFromPath([]string{"testdata/trapmibs"}, testutil.Logger{}, &GosmiMibLoader{}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := TrapLookup(tt.oid)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
 type TestingMibLoader struct {
 	folders []string
 	files   []string
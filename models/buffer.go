@@ -2,6 +2,7 @@ package models
 
 import (
 	"sync"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/selfstat"
@@ -29,6 +30,7 @@ type Buffer struct {
 	MetricsDropped selfstat.Stat
 	BufferSize     selfstat.Stat
 	BufferLimit    selfstat.Stat
+	BufferAge      selfstat.Stat
 }
 
 // NewBuffer returns a new empty Buffer with the given capacity.
@@ -70,9 +72,15 @@ func NewBuffer(name string, alias string, capacity int) *Buffer {
 			"buffer_limit",
 			tags,
 		),
+		BufferAge: selfstat.Register(
+			"write",
+			"buffer_age_ns",
+			tags,
+		),
 	}
 	b.BufferSize.Set(int64(0))
 	b.BufferLimit.Set(int64(capacity))
+	b.BufferAge.Set(int64(0))
 	return b
 }
 
@@ -88,6 +96,38 @@ func (b *Buffer) length() int {
 	return min(b.size+b.batchSize, b.cap)
 }
 
+// Age returns the age of the oldest metric currently in the buffer based on
+// its timestamp. Metrics of a batch being written are not considered.
+func (b *Buffer) Age() time.Duration {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.age()
+}
+
+func (b *Buffer) age() time.Duration {
+	if b.size == 0 || b.buf[b.first] == nil {
+		return 0
+	}
+	if age := time.Since(b.buf[b.first].Time()); age > 0 {
+		return age
+	}
+	return 0
+}
+
+// UpdateStats refreshes the buffer size and age statistics.
+func (b *Buffer) UpdateStats() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.updateStats()
+}
+
+func (b *Buffer) updateStats() {
+	b.BufferSize.Set(int64(b.length()))
+	b.BufferAge.Set(b.age().Nanoseconds())
+}
+
 func (b *Buffer) metricAdded() {
 	b.MetricsAdded.Incr(1)
 }
@@ -142,7 +182,7 @@ func (b *Buffer) Add(metrics ...telegraf.Metric) int {
 		}
 	}
 
-	b.BufferSize.Set(int64(b.length()))
+	b.updateStats()
 	return dropped
 }
 
@@ -184,7 +224,7 @@ func (b *Buffer) Accept(batch []telegraf.Metric) {
 	}
 
 	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
+	b.updateStats()
 }
 
 // Reject returns the batch, acquired from Batch(), to the buffer and marks it
@@ -193,6 +233,64 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 	b.Lock()
 	defer b.Unlock()
 
+	b.reject(batch)
+}
+
+// Partial marks the metrics of the batch, acquired from Batch(), with the given
+// indices as written or dropped respectively and returns all other metrics to
+// the buffer.
+func (b *Buffer) Partial(batch []telegraf.Metric, accept, reject []int) {
+	b.Lock()
+	defer b.Unlock()
+
+	handled := make([]bool, len(batch))
+	for _, idx := range accept {
+		if idx < 0 || idx >= len(batch) || handled[idx] {
+			continue
+		}
+		b.metricWritten(batch[idx])
+		handled[idx] = true
+	}
+	for _, idx := range reject {
+		if idx < 0 || idx >= len(batch) || handled[idx] {
+			continue
+		}
+		b.metricDropped(batch[idx])
+		handled[idx] = true
+	}
+
+	keep := make([]telegraf.Metric, 0, len(batch))
+	for i, m := range batch {
+		if !handled[i] {
+			keep = append(keep, m)
+		}
+	}
+	if len(keep) == 0 {
+		b.resetBatch()
+		b.updateStats()
+		return
+	}
+	b.reject(keep)
+}
+
+// Trim shortens the batch, acquired from Batch(), to its first n metrics and
+// returns the remaining metrics to the buffer.
+func (b *Buffer) Trim(batch []telegraf.Metric, n int) []telegraf.Metric {
+	if n >= len(batch) {
+		return batch
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.reject(batch[n:])
+	b.batchFirst = b.prevby(b.first, n)
+	b.batchSize = n
+
+	return batch[:n]
+}
+
+func (b *Buffer) reject(batch []telegraf.Metric) {
 	if len(batch) == 0 {
 		return
 	}
@@ -217,7 +315,7 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 	}
 
 	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
+	b.updateStats()
 }
 
 // next returns the next index with wrapping.
@@ -252,6 +252,49 @@ func TestBuffer_RejectNothingNewFull(t *testing.T) {
 		}, batch)
 }
 
+func TestBuffer_Trim(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	b.Add(MetricTime(3))
+	b.Add(MetricTime(4))
+	batch := b.Batch(3)
+	batch = b.Trim(batch, 1)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(1)}, batch)
+	require.Equal(t, 4, b.Len())
+
+	b.Accept(batch)
+	require.Equal(t, int64(1), b.MetricsWritten.Get())
+	require.Equal(t, 3, b.Len())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			MetricTime(2),
+			MetricTime(3),
+			MetricTime(4),
+		}, batch)
+}
+
+func TestBuffer_TrimReject(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	b.Add(MetricTime(3))
+	batch := b.Batch(3)
+	batch = b.Trim(batch, 2)
+	b.Reject(batch)
+
+	require.Equal(t, int64(0), b.MetricsDropped.Get())
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			MetricTime(1),
+			MetricTime(2),
+			MetricTime(3),
+		}, batch)
+}
+
 func TestBuffer_RejectNoRoom(t *testing.T) {
 	b := setup(NewBuffer("test", "", 5))
 	b.Add(MetricTime(1))
@@ -724,3 +767,62 @@ func TestBuffer_RejectEmptyBatch(t *testing.T) {
 		require.NotNil(t, m)
 	}
 }
+
+func TestBuffer_Partial(t *testing.T) {
+	var accept, reject int
+	mm := &MockMetric{
+		Metric: Metric(),
+		AcceptF: func() {
+			accept++
+		},
+		RejectF: func() {
+			reject++
+		},
+	}
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(mm, mm, mm, mm)
+	batch := b.Batch(3)
+	b.Partial(batch, []int{0}, []int{2})
+	require.Equal(t, 1, accept)
+	require.Equal(t, 1, reject)
+	require.Equal(t, int64(1), b.MetricsWritten.Get())
+	require.Equal(t, int64(1), b.MetricsDropped.Get())
+
+	// The kept metric is returned to the front of the buffer
+	require.Equal(t, 2, b.Len())
+	batch = b.Batch(3)
+	require.Len(t, batch, 2)
+	b.Accept(batch)
+	require.Equal(t, 3, accept)
+	require.Equal(t, 0, b.Len())
+}
+
+func TestBuffer_AgeEmpty(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+
+	require.Zero(t, b.Age())
+	require.Zero(t, b.BufferAge.Get())
+}
+
+func TestBuffer_AgeOldestMetric(t *testing.T) {
+	now := time.Now()
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(now.Add(-time.Hour).Unix()), MetricTime(now.Unix()))
+
+	require.GreaterOrEqual(t, b.Age(), time.Hour)
+	require.GreaterOrEqual(t, b.BufferAge.Get(), time.Hour.Nanoseconds())
+	require.Equal(t, int64(2), b.BufferSize.Get())
+
+	// Metrics in a batch being written are not considered
+	batch := b.Batch(1)
+	require.Less(t, b.Age(), time.Hour)
+
+	// Rejecting the batch puts the oldest metric back
+	b.Reject(batch)
+	require.GreaterOrEqual(t, b.Age(), time.Hour)
+
+	b.Accept(b.Batch(2))
+	require.Zero(t, b.Age())
+	require.Zero(t, b.BufferAge.Get())
+	require.Zero(t, b.BufferSize.Get())
+}
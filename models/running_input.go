@@ -1,6 +1,9 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -19,6 +22,7 @@ type RunningInput struct {
 
 	log         telegraf.Logger
 	defaultTags map[string]string
+	probes      []startupProbe
 
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
@@ -78,6 +82,10 @@ type InputConfig struct {
 	Filter                  Filter
 	AlwaysIncludeLocalTags  bool
 	AlwaysIncludeGlobalTags bool
+
+	StartupErrorBehavior string
+	StartupProbes        []string
+	StartupProbeTimeout  time.Duration
 }
 
 func (r *RunningInput) metricFiltered(metric telegraf.Metric) {
@@ -89,6 +97,21 @@ func (r *RunningInput) LogName() string {
 }
 
 func (r *RunningInput) Init() error {
+	switch r.Config.StartupErrorBehavior {
+	case "", "error", "retry", "ignore":
+	default:
+		return fmt.Errorf("invalid 'startup_error_behavior' setting %q", r.Config.StartupErrorBehavior)
+	}
+
+	r.probes = make([]startupProbe, 0, len(r.Config.StartupProbes))
+	for _, address := range r.Config.StartupProbes {
+		probe, err := newStartupProbe(address)
+		if err != nil {
+			return err
+		}
+		r.probes = append(r.probes, probe)
+	}
+
 	if p, ok := r.Input.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -144,6 +167,53 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 	return metric
 }
 
+// HasDependencies returns true if the input has startup probes to wait for
+// before starting and gathering the input.
+func (r *RunningInput) HasDependencies() bool {
+	return len(r.probes) > 0
+}
+
+// WaitForDependencies blocks until all startup probes of the input succeed.
+// If the probes do not succeed within the probe timeout, the configured
+// startup error behavior decides how to proceed. The function returns false
+// if the input must not be gathered at all.
+func (r *RunningInput) WaitForDependencies(ctx context.Context) bool {
+	if len(r.probes) == 0 {
+		return true
+	}
+
+	timeout := r.Config.StartupProbeTimeout
+	if timeout == 0 {
+		timeout = DefaultStartupProbeTimeout
+	}
+
+	r.log.Debugf("Waiting for %d dependencies to become available", len(r.probes))
+	err := waitForProbes(ctx, r.probes, timeout)
+	if err == nil {
+		r.log.Debug("All dependencies are available")
+		return true
+	}
+	if !errors.Is(err, errProbeTimeout) {
+		return false
+	}
+
+	switch r.Config.StartupErrorBehavior {
+	case "retry":
+		r.log.Warnf("%v; waiting until dependencies are available", err)
+		if err := waitForProbes(ctx, r.probes, 0); err != nil {
+			return false
+		}
+		r.log.Info("All dependencies are available")
+		return true
+	case "ignore":
+		r.log.Warnf("%v; disabling plugin", err)
+		return false
+	}
+
+	r.log.Errorf("%v; starting to gather anyway", err)
+	return true
+}
+
 func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	start := time.Now()
 	err := r.Input.Gather(acc)
@@ -1,11 +1,15 @@
 package models
 
 import (
+	"errors"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -28,6 +32,11 @@ type OutputConfig struct {
 	FlushJitter       time.Duration
 	MetricBufferLimit int
 	MetricBatchSize   int
+	MaxBatchBytes     int
+
+	// Serializer used by the output, required to limit the batches by
+	// their serialized size
+	Serializer serializers.Serializer
 
 	NameOverride string
 	NamePrefix   string
@@ -201,14 +210,21 @@ func (r *RunningOutput) Write() error {
 	// Only process the metrics in the buffer now.  Metrics added while we are
 	// writing will be sent on the next call.
 	nBuffer := r.buffer.Len()
-	nBatches := nBuffer/r.MetricBatchSize + 1
-	for i := 0; i < nBatches; i++ {
-		batch := r.buffer.Batch(r.MetricBatchSize)
+	for written := 0; written < nBuffer; {
+		batch := r.batch()
 		if len(batch) == 0 {
 			break
 		}
+		written += len(batch)
 
 		err := r.writeMetrics(batch)
+		var partErr *internal.PartialWriteError
+		if errors.As(err, &partErr) {
+			// Kept metrics are returned to the front of the buffer, so stop
+			// here to not write them again within this call.
+			r.buffer.Partial(batch, partErr.MetricsAccept, partErr.MetricsReject)
+			return partErr.Err
+		}
 		if err != nil {
 			r.buffer.Reject(batch)
 			return err
@@ -220,12 +236,17 @@ func (r *RunningOutput) Write() error {
 
 // WriteBatch writes a single batch of metrics to the output.
 func (r *RunningOutput) WriteBatch() error {
-	batch := r.buffer.Batch(r.MetricBatchSize)
+	batch := r.batch()
 	if len(batch) == 0 {
 		return nil
 	}
 
 	err := r.writeMetrics(batch)
+	var partErr *internal.PartialWriteError
+	if errors.As(err, &partErr) {
+		r.buffer.Partial(batch, partErr.MetricsAccept, partErr.MetricsReject)
+		return partErr.Err
+	}
 	if err != nil {
 		r.buffer.Reject(batch)
 		return err
@@ -235,6 +256,31 @@ func (r *RunningOutput) WriteBatch() error {
 	return nil
 }
 
+// batch takes the next batch of metrics from the buffer. If a maximum batch
+// size in bytes is configured, the batch is cut at the last metric fitting into
+// the limit when serialized. A batch always contains at least one metric.
+func (r *RunningOutput) batch() []telegraf.Metric {
+	batch := r.buffer.Batch(r.MetricBatchSize)
+	if r.Config.MaxBatchBytes <= 0 || r.Config.Serializer == nil || len(batch) < 2 {
+		return batch
+	}
+
+	// Measure the batch the way the output serializes it to account for the
+	// framing of batch formats. Errors are left for the output to handle.
+	fits := func(n int) bool {
+		buf, err := r.Config.Serializer.SerializeBatch(batch[:n])
+		return err != nil || len(buf) <= r.Config.MaxBatchBytes
+	}
+	if fits(len(batch)) {
+		return batch
+	}
+
+	// Search for the largest number of metrics fitting into the limit
+	n := sort.Search(len(batch)-1, func(i int) bool { return !fits(i + 2) }) + 1
+	r.log.Debugf("Limiting batch to %d of %d metrics due to max_batch_bytes", n, len(batch))
+	return r.buffer.Trim(batch, n)
+}
+
 // Close closes the output
 func (r *RunningOutput) Close() {
 	err := r.Output.Close()
@@ -262,8 +308,12 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 }
 
 func (r *RunningOutput) LogBufferStatus() {
+	// Refresh the statistics as the age changes even without buffer activity
+	r.buffer.UpdateStats()
+
 	nBuffer := r.buffer.Len()
-	r.log.Debugf("Buffer fullness: %d / %d metrics", nBuffer, r.MetricBufferLimit)
+	r.log.Debugf("Buffer fullness: %d / %d metrics, oldest metric age: %s",
+		nBuffer, r.MetricBufferLimit, r.buffer.Age())
 }
 
 func (r *RunningOutput) Log() telegraf.Logger {
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -9,6 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/plugins/serializers/nowmetric"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -318,6 +322,79 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	require.Len(t, m.Metrics(), 10)
 }
 
+func TestRunningOutputMaxBatchBytes(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	buf, err := serializer.Serialize(first5[0])
+	require.NoError(t, err)
+
+	// Allow two metrics per batch
+	conf := &OutputConfig{
+		Filter:        Filter{},
+		MaxBatchBytes: 2*len(buf) + 1,
+		Serializer:    serializer,
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.WriteBatch())
+	require.Equal(t, []int{2}, m.batches)
+
+	require.NoError(t, ro.Write())
+	require.Equal(t, []int{2, 2, 1}, m.batches)
+	require.Len(t, m.Metrics(), 5)
+	require.Zero(t, ro.BufferLength())
+}
+
+func TestRunningOutputMaxBatchBytesBatchFormat(t *testing.T) {
+	serializer := &nowmetric.Serializer{}
+	require.NoError(t, serializer.Init())
+	buf, err := serializer.SerializeBatch(first5[:2])
+	require.NoError(t, err)
+
+	// The limit applies to the batch including the framing of the format
+	conf := &OutputConfig{
+		Filter:        Filter{},
+		MaxBatchBytes: len(buf),
+		Serializer:    serializer,
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.Write())
+	require.Equal(t, []int{2, 2, 1}, m.batches)
+	require.Len(t, m.Metrics(), 5)
+}
+
+func TestRunningOutputMaxBatchBytesOversized(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	// Metrics exceeding the limit on their own are sent one by one
+	conf := &OutputConfig{
+		Filter:        Filter{},
+		MaxBatchBytes: 1,
+		Serializer:    serializer,
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.Write())
+	require.Equal(t, []int{1, 1, 1, 1, 1}, m.batches)
+}
+
 // Verify that the order of points is preserved during write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -460,6 +537,54 @@ func TestRunningOutputWriteFailOrder3(t *testing.T) {
 	require.Equal(t, expected, m.Metrics())
 }
 
+func TestRunningOutputPartialWrite(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &partialOutput{
+		err: &internal.PartialWriteError{
+			Err:           errors.New("failed write"),
+			MetricsAccept: []int{0, 2},
+			MetricsReject: []int{1},
+		},
+	}
+	ro := NewRunningOutput(m, conf, 5, 10)
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.ErrorContains(t, ro.Write(), "failed write")
+	require.Len(t, m.batches, 1)
+	require.Equal(t, 2, ro.BufferLength())
+
+	// The kept metrics are written with the next call
+	m.err = nil
+	require.NoError(t, ro.Write())
+	require.Len(t, m.batches, 2)
+	testutil.RequireMetricsEqual(t, first5[3:], m.batches[1])
+	require.Equal(t, 0, ro.BufferLength())
+}
+
+func TestRunningOutputPartialWriteKeep(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	// Keeping metrics without an error is not reported as failure
+	m := &partialOutput{
+		err: &internal.PartialWriteError{MetricsAccept: []int{0}},
+	}
+	ro := NewRunningOutput(m, conf, 5, 10)
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.NoError(t, ro.Write())
+	require.Len(t, m.batches, 1)
+	require.Equal(t, 4, ro.BufferLength())
+}
+
 func TestInternalMetrics(t *testing.T) {
 	_ = NewRunningOutput(
 		&mockOutput{},
@@ -479,6 +604,7 @@ func TestInternalMetrics(t *testing.T) {
 				"alias":  "test_alias",
 			},
 			map[string]interface{}{
+				"buffer_age_ns":    0,
 				"buffer_limit":     10,
 				"buffer_size":      0,
 				"errors":           0,
@@ -507,6 +633,7 @@ type mockOutput struct {
 	sync.Mutex
 
 	metrics []telegraf.Metric
+	batches []int
 
 	// if true, mock write failure
 	failWrite bool
@@ -540,6 +667,7 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	}
 
 	m.metrics = append(m.metrics, metrics...)
+	m.batches = append(m.batches, len(metrics))
 	return nil
 }
 
@@ -549,6 +677,29 @@ func (m *mockOutput) Metrics() []telegraf.Metric {
 	return m.metrics
 }
 
+// partialOutput records the batches and returns the given error on write
+type partialOutput struct {
+	batches [][]telegraf.Metric
+	err     error
+}
+
+func (*partialOutput) Connect() error {
+	return nil
+}
+
+func (*partialOutput) Close() error {
+	return nil
+}
+
+func (*partialOutput) SampleConfig() string {
+	return ""
+}
+
+func (m *partialOutput) Write(metrics []telegraf.Metric) error {
+	m.batches = append(m.batches, metrics)
+	return m.err
+}
+
 type perfOutput struct {
 	// if true, mock write failure
 	failWrite bool
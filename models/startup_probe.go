@@ -0,0 +1,162 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// DefaultStartupProbeTimeout is the time to wait for the dependencies of an
+// input if no explicit timeout is configured.
+const DefaultStartupProbeTimeout = 30 * time.Second
+
+// Interval between two consecutive probing attempts and the maximum time
+// a single probe may take.
+const (
+	startupProbeInterval = time.Second
+	startupProbeTimeout  = 5 * time.Second
+)
+
+// startupProbe checks if a dependency of an input is available.
+type startupProbe interface {
+	Check(ctx context.Context) error
+	String() string
+}
+
+// newStartupProbe creates a probe from the given address. Supported schemes
+// are "tcp", "http", "https" and "file".
+func newStartupProbe(address string) (startupProbe, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing probe %q failed: %w", address, err)
+	}
+
+	switch u.Scheme {
+	case "tcp":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing host in probe %q", address)
+		}
+		return &tcpProbe{address: u.Host}, nil
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing host in probe %q", address)
+		}
+		return &httpProbe{url: u.String()}, nil
+	case "file":
+		path := u.Path
+		if u.Host != "" {
+			// Handle relative paths like "file://relative/path"
+			path = u.Host + u.Path
+		}
+		if path == "" {
+			return nil, fmt.Errorf("missing path in probe %q", address)
+		}
+		return &fileProbe{path: path}, nil
+	}
+	return nil, fmt.Errorf("unsupported scheme %q in probe %q", u.Scheme, address)
+}
+
+type tcpProbe struct {
+	address string
+}
+
+func (p *tcpProbe) Check(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (p *tcpProbe) String() string {
+	return "tcp://" + p.address
+}
+
+type httpProbe struct {
+	url string
+}
+
+func (p *httpProbe) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("received status %q", resp.Status)
+	}
+	return nil
+}
+
+func (p *httpProbe) String() string {
+	return p.url
+}
+
+type fileProbe struct {
+	path string
+}
+
+func (p *fileProbe) Check(context.Context) error {
+	_, err := os.Stat(p.path)
+	return err
+}
+
+func (p *fileProbe) String() string {
+	return "file://" + p.path
+}
+
+// checkProbes runs all probes once and returns the first error encountered.
+func checkProbes(ctx context.Context, probes []startupProbe) error {
+	for _, probe := range probes {
+		pctx, cancel := context.WithTimeout(ctx, startupProbeTimeout)
+		err := probe.Check(pctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("probe %s failed: %w", probe, err)
+		}
+	}
+	return nil
+}
+
+// errProbeTimeout is returned if the probes did not succeed within the timeout.
+var errProbeTimeout = errors.New("timeout waiting for dependencies")
+
+// waitForProbes checks the probes until all succeed, the timeout elapses or
+// the context is done. A timeout of zero waits until the context is done.
+func waitForProbes(ctx context.Context, probes []startupProbe, timeout time.Duration) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	ticker := time.NewTicker(startupProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		err := checkProbes(ctx, probes)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("%w: %w", errProbeTimeout, err)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartupProbeInvalid(t *testing.T) {
+	for _, address := range []string{"tcp://", "udp://localhost:53", "http://", "file://"} {
+		_, err := newStartupProbe(address)
+		require.Error(t, err, address)
+	}
+}
+
+func TestStartupProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	probe, err := newStartupProbe("tcp://" + address)
+	require.NoError(t, err)
+	require.NoError(t, probe.Check(context.Background()))
+
+	require.NoError(t, listener.Close())
+	require.Error(t, probe.Check(context.Background()))
+}
+
+func TestStartupProbeHTTP(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	probe, err := newStartupProbe(ts.URL)
+	require.NoError(t, err)
+	require.NoError(t, probe.Check(context.Background()))
+
+	healthy.Store(false)
+	require.ErrorContains(t, probe.Check(context.Background()), "503")
+}
+
+func TestStartupProbeFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "mysqld.sock")
+
+	probe, err := newStartupProbe("file://" + fn)
+	require.NoError(t, err)
+	require.Error(t, probe.Check(context.Background()))
+
+	require.NoError(t, os.WriteFile(fn, nil, 0600))
+	require.NoError(t, probe.Check(context.Background()))
+}
+
+func TestWaitForDependencies(t *testing.T) {
+	missing := "file://" + filepath.Join(t.TempDir(), "missing")
+	tests := []struct {
+		name     string
+		behavior string
+		expected bool
+	}{
+		{
+			name:     "default",
+			expected: true,
+		},
+		{
+			name:     "error",
+			behavior: "error",
+			expected: true,
+		},
+		{
+			name:     "ignore",
+			behavior: "ignore",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := NewRunningInput(&testInput{}, &InputConfig{
+				Name:                 "TestRunningInput",
+				StartupErrorBehavior: tt.behavior,
+				StartupProbes:        []string{missing},
+				StartupProbeTimeout:  10 * time.Millisecond,
+			})
+			require.NoError(t, ri.Init())
+			require.Equal(t, tt.expected, ri.WaitForDependencies(context.Background()))
+		})
+	}
+}
+
+func TestWaitForDependenciesRetry(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "ready")
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name:                 "TestRunningInput",
+		StartupErrorBehavior: "retry",
+		StartupProbes:        []string{"file://" + fn},
+		StartupProbeTimeout:  10 * time.Millisecond,
+	})
+	require.NoError(t, ri.Init())
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = os.WriteFile(fn, nil, 0600)
+	}()
+	require.True(t, ri.WaitForDependencies(context.Background()))
+
+	// Cancelling the context must stop waiting
+	require.NoError(t, os.Remove(fn))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.False(t, ri.WaitForDependencies(ctx))
+}
+
+func TestStartupErrorBehaviorInvalid(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name:                 "TestRunningInput",
+		StartupErrorBehavior: "foo",
+	})
+	require.ErrorContains(t, ri.Init(), "invalid 'startup_error_behavior'")
+}
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.anomaly
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/anomaly" // register plugin
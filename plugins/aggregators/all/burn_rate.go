@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.burn_rate
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/burn_rate" // register plugin
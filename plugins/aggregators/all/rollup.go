@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.rollup
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/rollup" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.sliding_quantile
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/sliding_quantile" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.topn
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/topn" // register plugin
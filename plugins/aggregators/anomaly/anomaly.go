@@ -0,0 +1,180 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package anomaly
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Anomaly struct {
+	Method       string          `toml:"method"`
+	Alpha        float64         `toml:"alpha"`
+	Beta         float64         `toml:"beta"`
+	Gamma        float64         `toml:"gamma"`
+	SeasonLength int             `toml:"season_length"`
+	WindowSize   int             `toml:"window_size"`
+	Warmup       int             `toml:"warmup"`
+	Threshold    float64         `toml:"threshold"`
+	Expiry       config.Duration `toml:"expiry"`
+	Log          telegraf.Logger `toml:"-"`
+
+	newModel func() model
+	cache    map[uint64]*series
+	scored   []telegraf.Metric
+	now      func() time.Time
+}
+
+// series holds the models of all numeric fields of a metric series
+type series struct {
+	models   map[string]model
+	lastSeen time.Time
+}
+
+func (*Anomaly) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Anomaly) Init() error {
+	if a.Alpha <= 0 || a.Alpha >= 1 {
+		return fmt.Errorf("alpha %v out of range", a.Alpha)
+	}
+	if a.Warmup < 0 {
+		return errors.New("warmup must not be negative")
+	}
+	if a.Threshold <= 0 {
+		return errors.New("threshold must be positive")
+	}
+
+	switch a.Method {
+	case "", "ewma":
+		a.newModel = func() model {
+			return &ewma{alpha: a.Alpha, warmup: a.Warmup}
+		}
+	case "mad":
+		if a.WindowSize < 2 {
+			return errors.New("window size must be at least two")
+		}
+		a.newModel = func() model {
+			return &mad{size: a.WindowSize, warmup: a.Warmup}
+		}
+	case "holt_winters":
+		if a.Beta <= 0 || a.Beta >= 1 {
+			return fmt.Errorf("beta %v out of range", a.Beta)
+		}
+		if a.Gamma <= 0 || a.Gamma >= 1 {
+			return fmt.Errorf("gamma %v out of range", a.Gamma)
+		}
+		if a.SeasonLength < 2 {
+			return errors.New("season length must be at least two")
+		}
+		a.newModel = func() model {
+			return &holtWinters{
+				alpha:  a.Alpha,
+				beta:   a.Beta,
+				gamma:  a.Gamma,
+				warmup: a.Warmup,
+				season: make([]float64, a.SeasonLength),
+			}
+		}
+	default:
+		return fmt.Errorf("unknown method %q", a.Method)
+	}
+
+	if a.now == nil {
+		a.now = time.Now
+	}
+	a.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (a *Anomaly) Add(in telegraf.Metric) {
+	id := in.HashID()
+	entry, found := a.cache[id]
+	if !found {
+		entry = &series{models: make(map[string]model)}
+		a.cache[id] = entry
+	}
+	entry.lastSeen = a.now()
+
+	out := in.Copy()
+	for _, field := range in.FieldList() {
+		v, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+
+		m, found := entry.models[field.Key]
+		if !found {
+			m = a.newModel()
+			entry.models[field.Key] = m
+		}
+		expected, zscore, ok := m.update(v)
+		if !ok {
+			continue
+		}
+		out.AddField(field.Key+"_expected", expected)
+		out.AddField(field.Key+"_zscore", zscore)
+		out.AddField(field.Key+"_anomaly", math.Abs(zscore) > a.Threshold)
+	}
+	a.scored = append(a.scored, out)
+}
+
+func (a *Anomaly) Push(acc telegraf.Accumulator) {
+	for _, m := range a.scored {
+		acc.AddMetric(m)
+	}
+
+	// Forget the models of series not seen for a while
+	if a.Expiry > 0 {
+		cutoff := a.now().Add(-time.Duration(a.Expiry))
+		for id, entry := range a.cache {
+			if entry.lastSeen.Before(cutoff) {
+				delete(a.cache, id)
+			}
+		}
+	}
+}
+
+// Reset only clears the scored metrics, the models are kept across periods.
+func (a *Anomaly) Reset() {
+	a.scored = nil
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("anomaly", func() telegraf.Aggregator {
+		return &Anomaly{
+			Alpha:        0.3,
+			Beta:         0.1,
+			Gamma:        0.1,
+			SeasonLength: 24,
+			WindowSize:   30,
+			Warmup:       10,
+			Threshold:    3.0,
+			Expiry:       config.Duration(time.Hour),
+		}
+	})
+}
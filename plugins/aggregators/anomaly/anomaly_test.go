@@ -0,0 +1,221 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Anomaly
+		expected string
+	}{
+		{
+			name:     "invalid alpha",
+			plugin:   &Anomaly{Alpha: 1.5, Threshold: 3},
+			expected: "alpha 1.5 out of range",
+		},
+		{
+			name:     "invalid threshold",
+			plugin:   &Anomaly{Alpha: 0.3},
+			expected: "threshold must be positive",
+		},
+		{
+			name:     "unknown method",
+			plugin:   &Anomaly{Method: "arima", Alpha: 0.3, Threshold: 3},
+			expected: `unknown method "arima"`,
+		},
+		{
+			name:     "mad without window",
+			plugin:   &Anomaly{Method: "mad", Alpha: 0.3, Threshold: 3},
+			expected: "window size must be at least two",
+		},
+		{
+			name:     "holt-winters without beta",
+			plugin:   &Anomaly{Method: "holt_winters", Alpha: 0.3, Gamma: 0.1, SeasonLength: 24, Threshold: 3},
+			expected: "beta 0 out of range",
+		},
+		{
+			name:     "holt-winters without season",
+			plugin:   &Anomaly{Method: "holt_winters", Alpha: 0.3, Beta: 0.1, Gamma: 0.1, Threshold: 3},
+			expected: "season length must be at least two",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	plugin := &Anomaly{
+		Alpha:     0.3,
+		Warmup:    5,
+		Threshold: 3,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Alternating values followed by a spike
+	values := make([]float64, 0, 21)
+	for i := 0; i < 20; i++ {
+		values = append(values, 10+2*float64(i%2))
+	}
+	values = append(values, 30)
+
+	actual := run(plugin, values)
+	require.Len(t, actual, len(values))
+
+	// No scores during warm-up
+	for _, m := range actual[:5] {
+		require.False(t, m.HasField("value_zscore"))
+	}
+	for _, m := range actual[5:20] {
+		require.True(t, m.HasField("value_zscore"))
+		require.Equal(t, false, m.Fields()["value_anomaly"])
+	}
+	spike := actual[20]
+	require.Equal(t, true, spike.Fields()["value_anomaly"])
+	require.InDelta(t, 11.18, spike.Fields()["value_expected"], 0.01)
+	require.InDelta(t, 19.12, spike.Fields()["value_zscore"], 0.01)
+	require.Equal(t, "ok", spike.Fields()["status"])
+}
+
+func TestMAD(t *testing.T) {
+	plugin := &Anomaly{
+		Method:     "mad",
+		Alpha:      0.3,
+		WindowSize: 5,
+		Warmup:     5,
+		Threshold:  3,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := run(plugin, []float64{10, 11, 9, 10, 12, 10, 20})
+	expected := []telegraf.Metric{
+		metric.New("test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"value":          10.0,
+				"status":         "ok",
+				"value_expected": 10.0,
+				"value_zscore":   0.0,
+				"value_anomaly":  false,
+			},
+			time.Unix(5, 0),
+		),
+		metric.New("test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"value":          20.0,
+				"status":         "ok",
+				"value_expected": 10.0,
+				"value_zscore":   10 / madScale,
+				"value_anomaly":  true,
+			},
+			time.Unix(6, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual[5:], cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestHoltWinters(t *testing.T) {
+	plugin := &Anomaly{
+		Method:       "holt_winters",
+		Alpha:        0.3,
+		Beta:         0.1,
+		Gamma:        0.1,
+		SeasonLength: 4,
+		Warmup:       4,
+		Threshold:    3,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Seasonal pattern with some noise followed by a value fitting the
+	// pattern at a different position
+	pattern := []float64{10, 20, 30, 20}
+	values := make([]float64, 0, 25)
+	for i := 0; i < 24; i++ {
+		noise := 0.5
+		if (i/4)%2 == 1 {
+			noise = -noise
+		}
+		if i%2 == 1 {
+			noise = -noise
+		}
+		values = append(values, pattern[i%4]+noise)
+	}
+	values = append(values, 30)
+
+	actual := run(plugin, values)
+	require.Len(t, actual, len(values))
+
+	// The first season initializes the model followed by the warm-up
+	for _, m := range actual[:8] {
+		require.False(t, m.HasField("value_zscore"))
+	}
+	for _, m := range actual[8:24] {
+		require.True(t, m.HasField("value_zscore"))
+		require.Equal(t, false, m.Fields()["value_anomaly"])
+	}
+	spike := actual[24]
+	require.Equal(t, true, spike.Fields()["value_anomaly"])
+	require.InDelta(t, 10.41, spike.Fields()["value_expected"], 0.01)
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	plugin := &Anomaly{
+		Alpha:     0.3,
+		Threshold: 3,
+		Expiry:    config.Duration(time.Minute),
+		Log:       testutil.Logger{},
+		now:       func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": 1.0}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Len(t, plugin.cache, 1)
+
+	acc.ClearMetrics()
+	now = now.Add(2 * time.Minute)
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Empty(t, plugin.cache)
+}
+
+// run adds metrics with the given values to the plugin and returns the
+// pushed metrics
+func run(plugin *Anomaly, values []float64) []telegraf.Metric {
+	for i, v := range values {
+		plugin.Add(metric.New(
+			"test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": v, "status": "ok"},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	plugin.Reset()
+	return acc.GetTelegrafMetrics()
+}
@@ -0,0 +1,149 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+)
+
+// model predicts the next value of a series and scores values against the
+// prediction
+type model interface {
+	// update returns the expected value and the z-score of the given value
+	// and updates the model afterwards. The returned flag is false if the
+	// model cannot score the value yet, e.g. during warm-up.
+	update(v float64) (expected, zscore float64, ok bool)
+}
+
+// ewma uses an exponentially weighted moving average and variance
+type ewma struct {
+	alpha  float64
+	warmup int
+
+	n        int
+	mean     float64
+	variance float64
+}
+
+func (m *ewma) update(v float64) (expected, zscore float64, ok bool) {
+	if m.n == 0 {
+		m.n, m.mean = 1, v
+		return 0, 0, false
+	}
+
+	expected = m.mean
+	if m.n >= m.warmup && m.variance > 0 {
+		zscore = (v - m.mean) / math.Sqrt(m.variance)
+		ok = true
+	}
+
+	diff := v - m.mean
+	incr := m.alpha * diff
+	m.mean += incr
+	m.variance = (1 - m.alpha) * (m.variance + diff*incr)
+	m.n++
+
+	return expected, zscore, ok
+}
+
+// mad uses the median and the median absolute deviation of a window of the
+// last values, which is robust against outliers in the window
+type mad struct {
+	size   int
+	warmup int
+
+	values []float64
+	next   int
+}
+
+// madScale makes the median absolute deviation a consistent estimator of the
+// standard deviation for normally distributed values
+const madScale = 1.4826
+
+func (m *mad) update(v float64) (expected, zscore float64, ok bool) {
+	if len(m.values) > 0 && len(m.values) >= m.warmup {
+		sorted := make([]float64, len(m.values))
+		copy(sorted, m.values)
+		expected = median(sorted)
+
+		for i, x := range sorted {
+			sorted[i] = math.Abs(x - expected)
+		}
+		if deviation := madScale * median(sorted); deviation > 0 {
+			zscore = (v - expected) / deviation
+			ok = true
+		}
+	}
+
+	if len(m.values) < m.size {
+		m.values = append(m.values, v)
+	} else {
+		m.values[m.next] = v
+		m.next = (m.next + 1) % m.size
+	}
+
+	return expected, zscore, ok
+}
+
+// median returns the median of the given values, the slice is sorted in place
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// holtWinters uses additive triple exponential smoothing to predict series
+// with trend and seasonality, the residuals are scored using their
+// exponentially weighted variance
+type holtWinters struct {
+	alpha  float64
+	beta   float64
+	gamma  float64
+	warmup int
+
+	n        int
+	level    float64
+	trend    float64
+	season   []float64
+	variance float64
+}
+
+func (m *holtWinters) update(v float64) (expected, zscore float64, ok bool) {
+	length := len(m.season)
+
+	// The first season initializes the level and the seasonal components
+	if m.n < length {
+		m.season[m.n] = v
+		m.n++
+		if m.n == length {
+			var sum float64
+			for _, x := range m.season {
+				sum += x
+			}
+			m.level = sum / float64(length)
+			for i := range m.season {
+				m.season[i] -= m.level
+			}
+		}
+		return 0, 0, false
+	}
+
+	i := m.n % length
+	expected = m.level + m.trend + m.season[i]
+	residual := v - expected
+	if m.n-length >= m.warmup && m.variance > 0 {
+		zscore = residual / math.Sqrt(m.variance)
+		ok = true
+	}
+
+	level := m.alpha*(v-m.season[i]) + (1-m.alpha)*(m.level+m.trend)
+	m.trend = m.beta*(level-m.level) + (1-m.beta)*m.trend
+	m.season[i] = m.gamma*(v-level) + (1-m.gamma)*m.season[i]
+	m.level = level
+	m.variance = m.alpha*residual*residual + (1-m.alpha)*m.variance
+	m.n++
+
+	return expected, zscore, ok
+}
@@ -0,0 +1,292 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package burn_rate
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SLO struct {
+	Name         string  `toml:"name"`
+	Objective    float64 `toml:"objective"`
+	TotalField   string  `toml:"total_field"`
+	ErrorField   string  `toml:"error_field"`
+	SuccessField string  `toml:"success_field"`
+}
+
+type BurnRate struct {
+	Windows    []config.Duration `toml:"windows"`
+	Resolution config.Duration   `toml:"resolution"`
+	Increments bool              `toml:"increments"`
+	SLOs       []SLO             `toml:"slo"`
+	Log        telegraf.Logger   `toml:"-"`
+
+	suffixes []string
+	longest  time.Duration
+	cache    map[seriesID]*series
+	now      func() time.Time
+}
+
+type seriesID struct {
+	slo  int
+	hash uint64
+}
+
+// series keeps the history of the event counts of a metric series for one
+// objective, buckets are identified by their start time divided by the
+// resolution
+type series struct {
+	name string
+	tags map[string]string
+
+	initialized bool
+	lastTotal   float64
+	lastErrors  float64
+	lastSeen    time.Time
+
+	buckets map[int64]*counts
+}
+
+type counts struct {
+	total  float64
+	errors float64
+}
+
+func (*BurnRate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SLO) Init() error {
+	if s.Name == "" {
+		return errors.New("name required")
+	}
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("objective %v out of range", s.Objective)
+	}
+	if s.TotalField == "" {
+		return errors.New("total field required")
+	}
+	if s.ErrorField == "" && s.SuccessField == "" {
+		return errors.New("error or success field required")
+	}
+	if s.ErrorField != "" && s.SuccessField != "" {
+		return errors.New("error and success field are mutually exclusive")
+	}
+	return nil
+}
+
+func (b *BurnRate) Init() error {
+	if b.Resolution <= 0 {
+		return errors.New("resolution must be positive")
+	}
+
+	if len(b.Windows) == 0 {
+		b.Windows = []config.Duration{
+			config.Duration(5 * time.Minute),
+			config.Duration(time.Hour),
+			config.Duration(6 * time.Hour),
+		}
+	}
+	duplicates := make(map[string]bool, len(b.Windows))
+	b.suffixes = make([]string, 0, len(b.Windows))
+	for _, w := range b.Windows {
+		window := time.Duration(w)
+		if window < time.Duration(b.Resolution) {
+			return fmt.Errorf("window %v shorter than resolution", window)
+		}
+		suffix := windowSuffix(window)
+		if duplicates[suffix] {
+			return fmt.Errorf("duplicate window %v", window)
+		}
+		duplicates[suffix] = true
+		b.suffixes = append(b.suffixes, suffix)
+		if window > b.longest {
+			b.longest = window
+		}
+	}
+
+	if len(b.SLOs) == 0 {
+		return errors.New("no slo defined")
+	}
+	for i := range b.SLOs {
+		if err := b.SLOs[i].Init(); err != nil {
+			return fmt.Errorf("slo %d: %w", i+1, err)
+		}
+	}
+
+	if b.now == nil {
+		b.now = time.Now
+	}
+	b.cache = make(map[seriesID]*series)
+
+	return nil
+}
+
+func (b *BurnRate) Add(in telegraf.Metric) {
+	for i, slo := range b.SLOs {
+		total, found := field(in, slo.TotalField)
+		if !found {
+			continue
+		}
+		var errs float64
+		if slo.ErrorField != "" {
+			errs, found = field(in, slo.ErrorField)
+		} else {
+			var success float64
+			success, found = field(in, slo.SuccessField)
+			errs = total - success
+		}
+		if !found {
+			continue
+		}
+
+		id := seriesID{slo: i, hash: in.HashID()}
+		entry, found := b.cache[id]
+		if !found {
+			tags := in.Tags()
+			tags["slo"] = slo.Name
+			entry = &series{
+				name:    in.Name(),
+				tags:    tags,
+				buckets: make(map[int64]*counts),
+			}
+			b.cache[id] = entry
+		}
+		entry.lastSeen = in.Time()
+
+		deltaTotal, deltaErrors := total, errs
+		if !b.Increments {
+			// The first sample of a counter only serves as reference
+			if !entry.initialized {
+				entry.initialized = true
+				entry.lastTotal, entry.lastErrors = total, errs
+				continue
+			}
+			deltaTotal = increase(entry.lastTotal, total)
+			deltaErrors = increase(entry.lastErrors, errs)
+			entry.lastTotal, entry.lastErrors = total, errs
+		}
+
+		bucket := in.Time().UnixNano() / int64(b.Resolution)
+		c, found := entry.buckets[bucket]
+		if !found {
+			c = &counts{}
+			entry.buckets[bucket] = c
+		}
+		c.total += deltaTotal
+		c.errors += deltaErrors
+	}
+}
+
+func (b *BurnRate) Push(acc telegraf.Accumulator) {
+	now := b.now()
+	oldest := b.firstBucket(now, b.longest)
+
+	for id, entry := range b.cache {
+		for bucket := range entry.buckets {
+			if bucket < oldest {
+				delete(entry.buckets, bucket)
+			}
+		}
+		if len(entry.buckets) == 0 {
+			// Keep the counter reference of recently seen series to not lose
+			// the next increase
+			if entry.lastSeen.Before(now.Add(-b.longest)) {
+				delete(b.cache, id)
+			}
+			continue
+		}
+
+		objective := b.SLOs[id.slo].Objective
+		fields := make(map[string]interface{}, 2*len(b.Windows))
+		for i, w := range b.Windows {
+			first := b.firstBucket(now, time.Duration(w))
+			var sum counts
+			for bucket, c := range entry.buckets {
+				if bucket >= first {
+					sum.total += c.total
+					sum.errors += c.errors
+				}
+			}
+			if sum.total <= 0 {
+				continue
+			}
+			ratio := sum.errors / sum.total
+			fields["error_ratio"+b.suffixes[i]] = ratio
+			fields["burn_rate"+b.suffixes[i]] = ratio / (1 - objective)
+		}
+		if len(fields) > 0 {
+			acc.AddFields(entry.name, fields, entry.tags)
+		}
+	}
+}
+
+// Reset is a no-op as the windows span multiple periods, the history is
+// expired on push instead.
+func (*BurnRate) Reset() {}
+
+// firstBucket returns the first bucket starting within the window of the
+// given duration ending at the given time
+func (b *BurnRate) firstBucket(t time.Time, window time.Duration) int64 {
+	cutoff := t.Add(-window).UnixNano()
+	first := cutoff / int64(b.Resolution)
+	if cutoff%int64(b.Resolution) > 0 {
+		first++
+	}
+	return first
+}
+
+// increase returns the increase of a counter taking counter resets into
+// account
+func increase(last, current float64) float64 {
+	if current < last {
+		return current
+	}
+	return current - last
+}
+
+// windowSuffix returns a short field-name suffix for the given window, e.g.
+// "_5m" instead of "_5m0s"
+func windowSuffix(window time.Duration) string {
+	s := window.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return "_" + s
+}
+
+func field(m telegraf.Metric, key string) (float64, bool) {
+	raw, found := m.GetField(key)
+	if !found {
+		return 0, false
+	}
+	switch v := raw.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("burn_rate", func() telegraf.Aggregator {
+		return &BurnRate{Resolution: config.Duration(time.Minute)}
+	})
+}
@@ -0,0 +1,208 @@
+package burn_rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		windows  []config.Duration
+		slo      SLO
+		expected string
+	}{
+		{
+			name:     "no name",
+			slo:      SLO{Objective: 0.99, TotalField: "requests", ErrorField: "errors"},
+			expected: "slo 1: name required",
+		},
+		{
+			name:     "objective out of range",
+			slo:      SLO{Name: "availability", Objective: 99.9, TotalField: "requests", ErrorField: "errors"},
+			expected: "slo 1: objective 99.9 out of range",
+		},
+		{
+			name:     "no error field",
+			slo:      SLO{Name: "availability", Objective: 0.99, TotalField: "requests"},
+			expected: "slo 1: error or success field required",
+		},
+		{
+			name: "error and success field",
+			slo: SLO{
+				Name:         "availability",
+				Objective:    0.99,
+				TotalField:   "requests",
+				ErrorField:   "errors",
+				SuccessField: "success",
+			},
+			expected: "slo 1: error and success field are mutually exclusive",
+		},
+		{
+			name:     "window shorter than resolution",
+			windows:  []config.Duration{config.Duration(time.Second)},
+			slo:      SLO{Name: "availability", Objective: 0.99, TotalField: "requests", ErrorField: "errors"},
+			expected: "window 1s shorter than resolution",
+		},
+		{
+			name:     "duplicate window",
+			windows:  []config.Duration{config.Duration(time.Hour), config.Duration(60 * time.Minute)},
+			slo:      SLO{Name: "availability", Objective: 0.99, TotalField: "requests", ErrorField: "errors"},
+			expected: "duplicate window 1h0m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &BurnRate{
+				Windows:    tt.windows,
+				Resolution: config.Duration(time.Minute),
+				SLOs:       []SLO{tt.slo},
+			}
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestCounters(t *testing.T) {
+	var now time.Time
+	plugin := &BurnRate{
+		Windows:    []config.Duration{config.Duration(5 * time.Minute), config.Duration(time.Hour)},
+		Resolution: config.Duration(time.Minute),
+		SLOs: []SLO{
+			{Name: "availability", Objective: 0.99, TotalField: "requests", ErrorField: "errors"},
+		},
+		Log: testutil.Logger{},
+		now: func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	samples := []struct {
+		ts       int64
+		requests int64
+		errors   int64
+	}{
+		{ts: 60, requests: 1000, errors: 0},
+		{ts: 120, requests: 2000, errors: 10},
+		{ts: 3540, requests: 3000, errors: 60},
+	}
+	for _, s := range samples {
+		plugin.Add(metric.New(
+			"nginx",
+			map[string]string{"server": "a"},
+			map[string]interface{}{"requests": s.requests, "errors": s.errors},
+			time.Unix(s.ts, 0),
+		))
+	}
+
+	var acc testutil.Accumulator
+	now = time.Unix(3600, 0)
+	plugin.Push(&acc)
+	plugin.Reset()
+
+	expected := []telegraf.Metric{
+		metric.New("nginx",
+			map[string]string{"server": "a", "slo": "availability"},
+			map[string]interface{}{
+				"error_ratio_5m": 0.05,
+				"burn_rate_5m":   5.0,
+				"error_ratio_1h": 0.03,
+				"burn_rate_1h":   3.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{testutil.IgnoreTime(), cmpopts.EquateApprox(0, 1e-9)}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// Only the long window still contains events
+	acc.ClearMetrics()
+	now = time.Unix(3900, 0)
+	plugin.Push(&acc)
+	plugin.Reset()
+	expected = []telegraf.Metric{
+		metric.New("nginx",
+			map[string]string{"server": "a", "slo": "availability"},
+			map[string]interface{}{"error_ratio_1h": 0.05, "burn_rate_1h": 5.0},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// The series is forgotten once it left all windows
+	acc.ClearMetrics()
+	now = time.Unix(7200, 0)
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Empty(t, plugin.cache)
+}
+
+func TestIncrementsWithSuccess(t *testing.T) {
+	plugin := &BurnRate{
+		Windows:    []config.Duration{config.Duration(5 * time.Minute)},
+		Resolution: config.Duration(time.Minute),
+		Increments: true,
+		SLOs: []SLO{
+			{Name: "availability", Objective: 0.9, TotalField: "requests", SuccessField: "success"},
+			{Name: "latency", Objective: 0.95, TotalField: "requests", SuccessField: "fast"},
+		},
+		Log: testutil.Logger{},
+		now: func() time.Time { return time.Unix(300, 0) },
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New(
+		"http",
+		map[string]string{},
+		map[string]interface{}{"requests": uint64(100), "success": uint64(98), "fast": 90.0},
+		time.Unix(60, 0),
+	))
+	plugin.Add(metric.New(
+		"http",
+		map[string]string{},
+		map[string]interface{}{"requests": uint64(100), "success": uint64(96), "fast": 100.0},
+		time.Unix(120, 0),
+	))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("http",
+			map[string]string{"slo": "availability"},
+			map[string]interface{}{"error_ratio_5m": 0.03, "burn_rate_5m": 0.3},
+			time.Unix(0, 0),
+		),
+		metric.New("http",
+			map[string]string{"slo": "latency"},
+			map[string]interface{}{"error_ratio_5m": 0.05, "burn_rate_5m": 1.0},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{testutil.IgnoreTime(), testutil.SortMetrics(), cmpopts.EquateApprox(0, 1e-9)}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestIncrease(t *testing.T) {
+	require.InDelta(t, 5.0, increase(10, 15), 0)
+	require.InDelta(t, 0.0, increase(10, 10), 0)
+	// Counter reset
+	require.InDelta(t, 3.0, increase(10, 3), 0)
+}
+
+func TestWindowSuffix(t *testing.T) {
+	require.Equal(t, "_5m", windowSuffix(5*time.Minute))
+	require.Equal(t, "_1h", windowSuffix(time.Hour))
+	require.Equal(t, "_1h30m", windowSuffix(90*time.Minute))
+	require.Equal(t, "_1m30s", windowSuffix(90*time.Second))
+	require.Equal(t, "_30s", windowSuffix(30*time.Second))
+}
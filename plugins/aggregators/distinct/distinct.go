@@ -0,0 +1,147 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package distinct
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Distinct struct {
+	Tags      []string        `toml:"tags"`
+	Fields    []string        `toml:"fields"`
+	Precision uint8           `toml:"precision"`
+	Log       telegraf.Logger `toml:"-"`
+
+	counted map[string]bool
+	cache   map[string]*aggregate
+}
+
+// aggregate holds the sketches of a series, series are identified by the
+// metric name and all tags except the counted ones
+type aggregate struct {
+	name     string
+	tags     map[string]string
+	sketches map[string]*hyperLogLog
+}
+
+func (*Distinct) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Distinct) Init() error {
+	if len(d.Tags) == 0 && len(d.Fields) == 0 {
+		return errors.New("no tags or fields to count")
+	}
+	if d.Precision < 4 || d.Precision > 16 {
+		return fmt.Errorf("precision %d out of range", d.Precision)
+	}
+
+	d.counted = make(map[string]bool, len(d.Tags))
+	for _, key := range d.Tags {
+		d.counted[key] = true
+	}
+	for _, key := range d.Fields {
+		if d.counted[key] {
+			return fmt.Errorf("%q used as tag and field", key)
+		}
+	}
+
+	d.Reset()
+
+	return nil
+}
+
+func (d *Distinct) Add(in telegraf.Metric) {
+	tags := make(map[string]string)
+	for _, tag := range in.TagList() {
+		if !d.counted[tag.Key] {
+			tags[tag.Key] = tag.Value
+		}
+	}
+
+	id := seriesKey(in.Name(), tags)
+	a, found := d.cache[id]
+	if !found {
+		a = &aggregate{
+			name:     in.Name(),
+			tags:     tags,
+			sketches: make(map[string]*hyperLogLog),
+		}
+		d.cache[id] = a
+	}
+
+	for _, key := range d.Tags {
+		if v, found := in.GetTag(key); found {
+			a.add(key, v, d.Precision)
+		}
+	}
+	for _, key := range d.Fields {
+		if v, found := in.GetField(key); found {
+			a.add(key, fmt.Sprint(v), d.Precision)
+		}
+	}
+}
+
+func (d *Distinct) Push(acc telegraf.Accumulator) {
+	for _, a := range d.cache {
+		if len(a.sketches) == 0 {
+			continue
+		}
+
+		fields := make(map[string]interface{}, 2*len(a.sketches))
+		for key, sketch := range a.sketches {
+			estimate := sketch.estimate()
+			fields[key+"_distinct"] = int64(math.Round(estimate))
+			fields[key+"_distinct_error"] = estimate * sketch.stdError()
+		}
+		acc.AddFields(a.name, fields, a.tags)
+	}
+}
+
+func (d *Distinct) Reset() {
+	d.cache = make(map[string]*aggregate)
+}
+
+func (a *aggregate) add(key, value string, precision uint8) {
+	sketch, found := a.sketches[key]
+	if !found {
+		sketch = newHyperLogLog(precision)
+		a.sketches[key] = sketch
+	}
+	sketch.add(value)
+}
+
+// seriesKey returns an identifier for the given name and tags
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteString("\x00")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(tags[k])
+	}
+	return b.String()
+}
+
+func init() {
+	aggregators.Add("distinct", func() telegraf.Aggregator {
+		return &Distinct{Precision: 14}
+	})
+}
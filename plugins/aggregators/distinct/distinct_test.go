@@ -0,0 +1,118 @@
+package distinct
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Distinct
+		expected string
+	}{
+		{
+			name:     "nothing to count",
+			plugin:   &Distinct{Precision: 14},
+			expected: "no tags or fields to count",
+		},
+		{
+			name:     "precision out of range",
+			plugin:   &Distinct{Tags: []string{"source"}, Precision: 20},
+			expected: "precision 20 out of range",
+		},
+		{
+			name:     "tag and field",
+			plugin:   &Distinct{Tags: []string{"user"}, Fields: []string{"user"}, Precision: 14},
+			expected: `"user" used as tag and field`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	plugin := &Distinct{
+		Tags:      []string{"source"},
+		Fields:    []string{"user"},
+		Precision: 14,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	requests := []struct {
+		server string
+		source string
+		user   interface{}
+	}{
+		{server: "a", source: "10.0.0.1", user: "alice"},
+		{server: "a", source: "10.0.0.2", user: "bob"},
+		{server: "a", source: "10.0.0.1", user: "alice"},
+		{server: "a", source: "10.0.0.3", user: "alice"},
+		{server: "b", source: "10.0.0.1", user: int64(42)},
+	}
+	for _, r := range requests {
+		plugin.Add(metric.New(
+			"http_request",
+			map[string]string{"server": r.server, "source": r.source},
+			map[string]interface{}{"user": r.user, "duration": 0.5},
+			time.Unix(0, 0),
+		))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("http_request",
+			map[string]string{"server": "a"},
+			map[string]interface{}{"source_distinct": int64(3), "user_distinct": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New("http_request",
+			map[string]string{"server": "b"},
+			map[string]interface{}{"source_distinct": int64(1), "user_distinct": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	for _, m := range actual {
+		require.InDelta(t, 0.0, m.Fields()["source_distinct_error"], 0.1)
+		require.InDelta(t, 0.0, m.Fields()["user_distinct_error"], 0.1)
+		m.RemoveField("source_distinct_error")
+		m.RemoveField("user_distinct_error")
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Values do not carry over to the next period
+	acc.ClearMetrics()
+	plugin.Reset()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestEstimate(t *testing.T) {
+	for _, n := range []int{1000, 10000, 100000} {
+		t.Run(fmt.Sprintf("%d values", n), func(t *testing.T) {
+			sketch := newHyperLogLog(14)
+			for i := 0; i < n; i++ {
+				// Add every value twice
+				sketch.add(fmt.Sprintf("user%d", i))
+				sketch.add(fmt.Sprintf("user%d", i))
+			}
+			estimate := sketch.estimate()
+			require.InDelta(t, float64(n), estimate, 3*sketch.stdError()*float64(n))
+		})
+	}
+}
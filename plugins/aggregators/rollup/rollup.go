@@ -0,0 +1,223 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rollup
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Rollup struct {
+	Resolutions   []config.Duration `toml:"resolutions"`
+	Stats         []string          `toml:"stats"`
+	ResolutionTag string            `toml:"resolution_tag"`
+	Log           telegraf.Logger   `toml:"-"`
+
+	levels []*level
+	now    func() time.Time
+}
+
+// level holds the open windows of one resolution
+type level struct {
+	resolution time.Duration
+	name       string
+	windows    map[windowID]*window
+
+	// Windows ending at or before the watermark are already emitted
+	watermark time.Time
+}
+
+type windowID struct {
+	start int64
+	hash  uint64
+}
+
+type window struct {
+	start  time.Time
+	name   string
+	tags   map[string]string
+	fields map[string]*stats
+}
+
+type stats struct {
+	count int64
+	min   float64
+	max   float64
+	sum   float64
+}
+
+func (*Rollup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rollup) Init() error {
+	if len(r.Resolutions) == 0 {
+		r.Resolutions = []config.Duration{
+			config.Duration(time.Minute),
+			config.Duration(5 * time.Minute),
+			config.Duration(time.Hour),
+		}
+	}
+	if len(r.Stats) == 0 {
+		r.Stats = []string{"min", "max", "mean", "sum", "count"}
+	}
+	for _, s := range r.Stats {
+		switch s {
+		case "min", "max", "mean", "sum", "count":
+		default:
+			return fmt.Errorf("unknown stat %q", s)
+		}
+	}
+	if r.ResolutionTag == "" {
+		return errors.New("resolution tag required")
+	}
+
+	seen := make(map[time.Duration]bool, len(r.Resolutions))
+	r.levels = make([]*level, 0, len(r.Resolutions))
+	for _, res := range r.Resolutions {
+		resolution := time.Duration(res)
+		if resolution <= 0 {
+			return errors.New("resolutions must be positive")
+		}
+		if seen[resolution] {
+			return fmt.Errorf("duplicate resolution %v", resolution)
+		}
+		seen[resolution] = true
+		r.levels = append(r.levels, &level{
+			resolution: resolution,
+			name:       formatDuration(resolution),
+			windows:    make(map[windowID]*window),
+		})
+	}
+
+	if r.now == nil {
+		r.now = time.Now
+	}
+
+	return nil
+}
+
+func (r *Rollup) Add(in telegraf.Metric) {
+	hash := in.HashID()
+	for _, l := range r.levels {
+		start := in.Time().Truncate(l.resolution)
+		if !start.Add(l.resolution).After(l.watermark) {
+			r.Log.Debugf("Dropping late metric %q for resolution %s", in.Name(), l.name)
+			continue
+		}
+
+		id := windowID{start: start.UnixNano(), hash: hash}
+		w, found := l.windows[id]
+		if !found {
+			tags := in.Tags()
+			tags[r.ResolutionTag] = l.name
+			w = &window{
+				start:  start,
+				name:   in.Name(),
+				tags:   tags,
+				fields: make(map[string]*stats),
+			}
+			l.windows[id] = w
+		}
+
+		for _, field := range in.FieldList() {
+			v, ok := convert(field.Value)
+			if !ok {
+				continue
+			}
+			s, found := w.fields[field.Key]
+			if !found {
+				w.fields[field.Key] = &stats{count: 1, min: v, max: v, sum: v}
+				continue
+			}
+			s.count++
+			s.min = math.Min(s.min, v)
+			s.max = math.Max(s.max, v)
+			s.sum += v
+		}
+	}
+}
+
+func (r *Rollup) Push(acc telegraf.Accumulator) {
+	now := r.now()
+	for _, l := range r.levels {
+		// Emit all windows completed by now
+		l.watermark = now.Truncate(l.resolution)
+		for id, w := range l.windows {
+			if w.start.Add(l.resolution).After(l.watermark) {
+				continue
+			}
+			if len(w.fields) > 0 {
+				acc.AddFields(w.name, r.fields(w), w.tags, w.start)
+			}
+			delete(l.windows, id)
+		}
+	}
+}
+
+// Reset is a no-op as windows of coarse resolutions span multiple periods,
+// windows are removed once they are emitted.
+func (*Rollup) Reset() {}
+
+func (r *Rollup) fields(w *window) map[string]interface{} {
+	fields := make(map[string]interface{}, len(w.fields)*len(r.Stats))
+	for key, s := range w.fields {
+		for _, stat := range r.Stats {
+			switch stat {
+			case "min":
+				fields[key+"_min"] = s.min
+			case "max":
+				fields[key+"_max"] = s.max
+			case "mean":
+				fields[key+"_mean"] = s.sum / float64(s.count)
+			case "sum":
+				fields[key+"_sum"] = s.sum
+			case "count":
+				fields[key+"_count"] = s.count
+			}
+		}
+	}
+	return fields
+}
+
+// formatDuration returns a short representation of the given duration, e.g.
+// "5m" instead of "5m0s"
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("rollup", func() telegraf.Aggregator {
+		return &Rollup{ResolutionTag: "resolution"}
+	})
+}
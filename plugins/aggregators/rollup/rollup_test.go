@@ -0,0 +1,143 @@
+package rollup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Rollup
+		expected string
+	}{
+		{
+			name:     "unknown stat",
+			plugin:   &Rollup{Stats: []string{"median"}, ResolutionTag: "resolution"},
+			expected: `unknown stat "median"`,
+		},
+		{
+			name:     "no resolution tag",
+			plugin:   &Rollup{},
+			expected: "resolution tag required",
+		},
+		{
+			name: "duplicate resolution",
+			plugin: &Rollup{
+				Resolutions:   []config.Duration{config.Duration(time.Minute), config.Duration(60 * time.Second)},
+				ResolutionTag: "resolution",
+			},
+			expected: "duplicate resolution 1m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRollup(t *testing.T) {
+	var now time.Time
+	plugin := &Rollup{
+		Resolutions:   []config.Duration{config.Duration(time.Minute), config.Duration(5 * time.Minute)},
+		ResolutionTag: "resolution",
+		Log:           testutil.Logger{},
+		now:           func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	for _, s := range []struct {
+		ts    int64
+		value int64
+	}{{0, 1}, {30, 3}, {60, 5}, {90, 7}, {290, 9}, {310, 11}} {
+		plugin.Add(sample(s.ts, s.value))
+	}
+
+	var acc testutil.Accumulator
+	now = time.Unix(120, 0)
+	plugin.Push(&acc)
+	plugin.Reset()
+	expected := []telegraf.Metric{
+		rollup("1m", 0, 1, 3, 2, 4, 2),
+		rollup("1m", 60, 5, 7, 6, 12, 2),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Late metrics are only accounted in windows not emitted yet
+	plugin.Add(sample(10, 2))
+
+	acc.ClearMetrics()
+	now = time.Unix(300, 0)
+	plugin.Push(&acc)
+	plugin.Reset()
+	expected = []telegraf.Metric{
+		rollup("1m", 240, 9, 9, 9, 9, 1),
+		rollup("5m", 0, 1, 9, 4.5, 27, 6),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	acc.ClearMetrics()
+	now = time.Unix(3600, 0)
+	plugin.Push(&acc)
+	expected = []telegraf.Metric{
+		rollup("1m", 300, 11, 11, 11, 11, 1),
+		rollup("5m", 300, 11, 11, 11, 11, 1),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestStats(t *testing.T) {
+	plugin := &Rollup{
+		Resolutions:   []config.Duration{config.Duration(time.Minute)},
+		Stats:         []string{"max", "count"},
+		ResolutionTag: "interval",
+		Log:           testutil.Logger{},
+		now:           func() time.Time { return time.Unix(60, 0) },
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(sample(10, 4))
+	plugin.Add(sample(20, 2))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"host": "a", "interval": "1m"},
+			map[string]interface{}{"usage_max": 4.0, "usage_count": int64(2)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func sample(ts, value int64) telegraf.Metric {
+	return metric.New("cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage": value, "state": "ok"},
+		time.Unix(ts, 0),
+	)
+}
+
+func rollup(resolution string, start int64, minimum, maximum, mean, sum float64, count int64) telegraf.Metric {
+	return metric.New("cpu",
+		map[string]string{"host": "a", "resolution": resolution},
+		map[string]interface{}{
+			"usage_min":   minimum,
+			"usage_max":   maximum,
+			"usage_mean":  mean,
+			"usage_sum":   sum,
+			"usage_count": count,
+		},
+		time.Unix(start, 0),
+	)
+}
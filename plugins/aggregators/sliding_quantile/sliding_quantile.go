@@ -0,0 +1,197 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sliding_quantile
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caio/go-tdigest"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SlidingQuantile struct {
+	Window      config.Duration `toml:"window"`
+	Buckets     int             `toml:"buckets"`
+	Quantiles   []float64       `toml:"quantiles"`
+	Compression float64         `toml:"compression"`
+	Log         telegraf.Logger `toml:"-"`
+
+	width    time.Duration
+	suffixes []string
+	cache    map[uint64]*series
+	now      func() time.Time
+}
+
+// series holds the digests of all fields of a metric series per bucket,
+// buckets are identified by the start time of the bucket divided by the
+// bucket width
+type series struct {
+	name   string
+	tags   map[string]string
+	fields map[string]map[int64]*tdigest.TDigest
+}
+
+func (*SlidingQuantile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SlidingQuantile) Init() error {
+	if s.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+	if s.Buckets < 1 {
+		return errors.New("buckets must be at least one")
+	}
+	s.width = time.Duration(s.Window) / time.Duration(s.Buckets)
+	if s.width <= 0 {
+		return errors.New("too many buckets for window")
+	}
+
+	if _, err := tdigest.New(tdigest.Compression(s.Compression)); err != nil {
+		return fmt.Errorf("invalid compression: %w", err)
+	}
+
+	if len(s.Quantiles) == 0 {
+		s.Quantiles = []float64{0.5, 0.9, 0.99}
+	}
+
+	duplicates := make(map[string]bool)
+	s.suffixes = make([]string, 0, len(s.Quantiles))
+	for _, qtl := range s.Quantiles {
+		if qtl < 0.0 || qtl > 1.0 {
+			return fmt.Errorf("quantile %v out of range", qtl)
+		}
+		suffix := fmt.Sprintf("_%03d", int(qtl*100.0))
+		if duplicates[suffix] {
+			return fmt.Errorf("duplicate quantile %v", qtl)
+		}
+		duplicates[suffix] = true
+		s.suffixes = append(s.suffixes, suffix)
+	}
+
+	if s.now == nil {
+		s.now = time.Now
+	}
+	s.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (s *SlidingQuantile) Add(in telegraf.Metric) {
+	id := in.HashID()
+	entry, found := s.cache[id]
+	if !found {
+		entry = &series{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]map[int64]*tdigest.TDigest),
+		}
+		s.cache[id] = entry
+	}
+
+	bucket := in.Time().UnixNano() / int64(s.width)
+	for _, field := range in.FieldList() {
+		v, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+
+		buckets, found := entry.fields[field.Key]
+		if !found {
+			buckets = make(map[int64]*tdigest.TDigest)
+			entry.fields[field.Key] = buckets
+		}
+		digest, found := buckets[bucket]
+		if !found {
+			// This should never error out as we tested it in Init()
+			digest, _ = tdigest.New(tdigest.Compression(s.Compression))
+			buckets[bucket] = digest
+		}
+		if err := digest.Add(v); err != nil {
+			s.Log.Errorf("Adding field %q failed: %v", field.Key, err)
+		}
+	}
+}
+
+func (s *SlidingQuantile) Push(acc telegraf.Accumulator) {
+	s.expire(s.now())
+
+	for _, entry := range s.cache {
+		fields := make(map[string]interface{}, len(entry.fields)*len(s.Quantiles))
+		for key, buckets := range entry.fields {
+			digest, _ := tdigest.New(tdigest.Compression(s.Compression))
+			for _, d := range buckets {
+				if err := digest.Merge(d); err != nil {
+					s.Log.Errorf("Merging buckets of field %q failed: %v", key, err)
+				}
+			}
+			for i, qtl := range s.Quantiles {
+				fields[key+s.suffixes[i]] = digest.Quantile(qtl)
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(entry.name, fields, entry.tags)
+		}
+	}
+}
+
+// Reset is a no-op as the window slides across periods, buckets are
+// expired on push instead.
+func (*SlidingQuantile) Reset() {}
+
+// expire removes all buckets starting before the window ending at the given
+// time as well as series without any remaining bucket
+func (s *SlidingQuantile) expire(t time.Time) {
+	cutoff := t.Add(-time.Duration(s.Window)).UnixNano()
+	oldest := cutoff / int64(s.width)
+	if cutoff%int64(s.width) > 0 {
+		oldest++
+	}
+
+	for id, entry := range s.cache {
+		for key, buckets := range entry.fields {
+			for bucket := range buckets {
+				if bucket < oldest {
+					delete(buckets, bucket)
+				}
+			}
+			if len(buckets) == 0 {
+				delete(entry.fields, key)
+			}
+		}
+		if len(entry.fields) == 0 {
+			delete(s.cache, id)
+		}
+	}
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("sliding_quantile", func() telegraf.Aggregator {
+		return &SlidingQuantile{
+			Window:      config.Duration(5 * time.Minute),
+			Buckets:     10,
+			Compression: 100,
+		}
+	})
+}
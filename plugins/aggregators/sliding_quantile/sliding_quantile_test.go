@@ -0,0 +1,153 @@
+package sliding_quantile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SlidingQuantile
+		expected string
+	}{
+		{
+			name:     "no window",
+			plugin:   &SlidingQuantile{Buckets: 10, Compression: 100},
+			expected: "window must be positive",
+		},
+		{
+			name:     "no buckets",
+			plugin:   &SlidingQuantile{Window: config.Duration(time.Minute), Compression: 100},
+			expected: "buckets must be at least one",
+		},
+		{
+			name: "quantile out of range",
+			plugin: &SlidingQuantile{
+				Window:      config.Duration(time.Minute),
+				Buckets:     10,
+				Compression: 100,
+				Quantiles:   []float64{1.5},
+			},
+			expected: "quantile 1.5 out of range",
+		},
+		{
+			name: "duplicate quantile",
+			plugin: &SlidingQuantile{
+				Window:      config.Duration(time.Minute),
+				Buckets:     10,
+				Compression: 100,
+				Quantiles:   []float64{0.5, 0.9, 0.5},
+			},
+			expected: "duplicate quantile 0.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInvalidCompression(t *testing.T) {
+	plugin := &SlidingQuantile{
+		Window:  config.Duration(time.Minute),
+		Buckets: 10,
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid compression")
+}
+
+func TestSlidingWindow(t *testing.T) {
+	var now time.Time
+	plugin := &SlidingQuantile{
+		Window:      config.Duration(3 * time.Minute),
+		Buckets:     3,
+		Compression: 100,
+		Log:         testutil.Logger{},
+		now:         func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	// Fill the bucket [540s, 600s) with values 0..99 and the bucket
+	// [600s, 660s) with values 100..199
+	for i := 0; i < 100; i++ {
+		plugin.Add(metric.New(
+			"latency",
+			map[string]string{"service": "api"},
+			map[string]interface{}{"value": int64(i), "status": "ok"},
+			time.Unix(540+int64(i%60), 0),
+		))
+	}
+
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		cmpopts.EquateApprox(0.02, 0),
+	}
+
+	var acc testutil.Accumulator
+	now = time.Unix(600, 0)
+	plugin.Push(&acc)
+	plugin.Reset()
+	expected := []telegraf.Metric{
+		metric.New("latency",
+			map[string]string{"service": "api"},
+			map[string]interface{}{"value_050": 49.5, "value_090": 89.1, "value_099": 98.01},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	for i := 100; i < 200; i++ {
+		plugin.Add(metric.New(
+			"latency",
+			map[string]string{"service": "api"},
+			map[string]interface{}{"value": float64(i)},
+			time.Unix(600+int64(i%60), 0),
+		))
+	}
+
+	// Both buckets are within the window
+	acc.ClearMetrics()
+	now = time.Unix(660, 0)
+	plugin.Push(&acc)
+	plugin.Reset()
+	expected = []telegraf.Metric{
+		metric.New("latency",
+			map[string]string{"service": "api"},
+			map[string]interface{}{"value_050": 99.5, "value_090": 179.1, "value_099": 197.01},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// The first bucket left the window
+	acc.ClearMetrics()
+	now = time.Unix(780, 0)
+	plugin.Push(&acc)
+	plugin.Reset()
+	expected = []telegraf.Metric{
+		metric.New("latency",
+			map[string]string{"service": "api"},
+			map[string]interface{}{"value_050": 149.5, "value_090": 189.1, "value_099": 198.01},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	// All buckets left the window
+	acc.ClearMetrics()
+	now = time.Unix(900, 0)
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Empty(t, plugin.cache)
+}
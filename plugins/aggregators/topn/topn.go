@@ -0,0 +1,296 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package topn
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TopN struct {
+	N           int             `toml:"n"`
+	Field       string          `toml:"field"`
+	GroupBy     []string        `toml:"group_by"`
+	Aggregation string          `toml:"aggregation"`
+	Bottom      bool            `toml:"bottom"`
+	Rollup      string          `toml:"rollup"`
+	OtherValue  string          `toml:"other_value"`
+	Log         telegraf.Logger `toml:"-"`
+
+	groups map[string]*group
+}
+
+// group collects the series sharing the name and the group-by tags
+type group struct {
+	name   string
+	tags   map[string]string
+	series map[uint64]*series
+}
+
+type series struct {
+	key    string
+	tags   map[string]string
+	fields map[string]*aggregate
+}
+
+// aggregate accumulates the values of a field of a series within a period
+type aggregate struct {
+	count int64
+	sum   float64
+	min   float64
+	max   float64
+	last  float64
+}
+
+// ranked is a series with its aggregated field values
+type ranked struct {
+	series *series
+	values map[string]float64
+}
+
+func (*TopN) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TopN) Init() error {
+	if t.N < 1 {
+		return errors.New("n must be at least one")
+	}
+	if t.Field == "" {
+		return errors.New("field required")
+	}
+
+	switch t.Aggregation {
+	case "":
+		t.Aggregation = "mean"
+	case "mean", "sum", "min", "max", "last":
+	default:
+		return fmt.Errorf("invalid aggregation %q", t.Aggregation)
+	}
+
+	switch t.Rollup {
+	case "", "sum", "mean", "min", "max":
+	default:
+		return fmt.Errorf("invalid rollup %q", t.Rollup)
+	}
+	if t.OtherValue == "" {
+		t.OtherValue = "other"
+	}
+
+	t.Reset()
+
+	return nil
+}
+
+func (t *TopN) Add(in telegraf.Metric) {
+	gkey := t.groupKey(in)
+	g, found := t.groups[gkey]
+	if !found {
+		tags := make(map[string]string, len(t.GroupBy))
+		for _, key := range t.GroupBy {
+			if v, ok := in.GetTag(key); ok {
+				tags[key] = v
+			}
+		}
+		g = &group{
+			name:   in.Name(),
+			tags:   tags,
+			series: make(map[uint64]*series),
+		}
+		t.groups[gkey] = g
+	}
+
+	id := in.HashID()
+	s, found := g.series[id]
+	if !found {
+		s = &series{
+			key:    seriesKey(in.TagList()),
+			tags:   in.Tags(),
+			fields: make(map[string]*aggregate),
+		}
+		g.series[id] = s
+	}
+
+	for _, field := range in.FieldList() {
+		v, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+		a, found := s.fields[field.Key]
+		if !found {
+			s.fields[field.Key] = &aggregate{count: 1, sum: v, min: v, max: v, last: v}
+			continue
+		}
+		a.count++
+		a.sum += v
+		a.min = math.Min(a.min, v)
+		a.max = math.Max(a.max, v)
+		a.last = v
+	}
+}
+
+func (t *TopN) Push(acc telegraf.Accumulator) {
+	for _, g := range t.groups {
+		// Series without the ranking field always belong to the remainder
+		candidates := make([]ranked, 0, len(g.series))
+		remainder := make([]ranked, 0)
+		for _, s := range g.series {
+			r := ranked{series: s, values: make(map[string]float64, len(s.fields))}
+			for k, a := range s.fields {
+				r.values[k] = a.value(t.Aggregation)
+			}
+			if _, found := r.values[t.Field]; found {
+				candidates = append(candidates, r)
+			} else {
+				remainder = append(remainder, r)
+			}
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			vi, vj := candidates[i].values[t.Field], candidates[j].values[t.Field]
+			if vi == vj {
+				return candidates[i].series.key < candidates[j].series.key
+			}
+			if t.Bottom {
+				return vi < vj
+			}
+			return vi > vj
+		})
+		if len(candidates) > t.N {
+			remainder = append(remainder, candidates[t.N:]...)
+			candidates = candidates[:t.N]
+		}
+
+		for _, r := range candidates {
+			fields := make(map[string]interface{}, len(r.values))
+			for k, v := range r.values {
+				fields[k] = v
+			}
+			acc.AddFields(g.name, fields, r.series.tags)
+		}
+
+		if t.Rollup != "" && len(remainder) > 0 {
+			t.pushRollup(acc, g, remainder)
+		}
+	}
+}
+
+func (t *TopN) Reset() {
+	t.groups = make(map[string]*group)
+}
+
+// pushRollup emits a single series aggregating the given remaining series of
+// the group, all tags except the group-by tags are replaced by the other-value
+func (t *TopN) pushRollup(acc telegraf.Accumulator, g *group, remainder []ranked) {
+	tags := make(map[string]string)
+	values := make(map[string][]float64)
+	for _, r := range remainder {
+		for k := range r.series.tags {
+			tags[k] = t.OtherValue
+		}
+		for k, v := range r.values {
+			values[k] = append(values[k], v)
+		}
+	}
+	for k, v := range g.tags {
+		tags[k] = v
+	}
+
+	fields := make(map[string]interface{}, len(values)+1)
+	for k, vs := range values {
+		fields[k] = rollup(t.Rollup, vs)
+	}
+	fields["series_count"] = int64(len(remainder))
+	acc.AddFields(g.name, fields, tags)
+}
+
+func (a *aggregate) value(aggregation string) float64 {
+	switch aggregation {
+	case "sum":
+		return a.sum
+	case "min":
+		return a.min
+	case "max":
+		return a.max
+	case "last":
+		return a.last
+	}
+	return a.sum / float64(a.count)
+}
+
+func rollup(function string, values []float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		switch function {
+		case "sum", "mean":
+			result += v
+		case "min":
+			result = math.Min(result, v)
+		case "max":
+			result = math.Max(result, v)
+		}
+	}
+	if function == "mean" {
+		result /= float64(len(values))
+	}
+	return result
+}
+
+// groupKey returns an identifier of the group of the given metric
+func (t *TopN) groupKey(m telegraf.Metric) string {
+	var b strings.Builder
+	b.WriteString(m.Name())
+	for _, key := range t.GroupBy {
+		v, _ := m.GetTag(key)
+		b.WriteString("\x00")
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+// seriesKey returns a stable identifier of the given sorted tags used to
+// order series with equal rank
+func seriesKey(tags []*telegraf.Tag) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		b.WriteString(tag.Key)
+		b.WriteString("=")
+		b.WriteString(tag.Value)
+		b.WriteString("\x00")
+	}
+	return b.String()
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("topn", func() telegraf.Aggregator {
+		return &TopN{
+			N:           10,
+			Field:       "value",
+			Aggregation: "mean",
+			Rollup:      "sum",
+			OtherValue:  "other",
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package topn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TopN
+		expected string
+	}{
+		{
+			name:     "invalid n",
+			plugin:   &TopN{Field: "value"},
+			expected: "n must be at least one",
+		},
+		{
+			name:     "no field",
+			plugin:   &TopN{N: 3},
+			expected: "field required",
+		},
+		{
+			name:     "invalid aggregation",
+			plugin:   &TopN{N: 3, Field: "value", Aggregation: "median"},
+			expected: `invalid aggregation "median"`,
+		},
+		{
+			name:     "invalid rollup",
+			plugin:   &TopN{N: 3, Field: "value", Rollup: "last"},
+			expected: `invalid rollup "last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTopN(t *testing.T) {
+	input := []telegraf.Metric{
+		process("a", "nginx", 10, 100),
+		process("a", "nginx", 20, 100),
+		process("a", "postgres", 40, 400),
+		process("a", "cron", 1, 10),
+		process("a", "sshd", 2, 20),
+		process("b", "nginx", 5, 50),
+		metric.New("procstat",
+			map[string]string{"host": "a", "process_name": "zombie"},
+			map[string]interface{}{"memory_rss": int64(5), "state": "Z"},
+			time.Unix(0, 0),
+		),
+	}
+
+	tests := []struct {
+		name     string
+		plugin   *TopN
+		expected []telegraf.Metric
+	}{
+		{
+			name: "top with rollup",
+			plugin: &TopN{
+				N:           2,
+				Field:       "cpu_usage",
+				GroupBy:     []string{"host"},
+				Aggregation: "mean",
+				Rollup:      "sum",
+			},
+			expected: []telegraf.Metric{
+				result("a", "postgres", 40, 400),
+				result("a", "nginx", 15, 100),
+				metric.New("procstat",
+					map[string]string{"host": "a", "process_name": "other"},
+					map[string]interface{}{"cpu_usage": 3.0, "memory_rss": 35.0, "series_count": int64(3)},
+					time.Unix(0, 0),
+				),
+				result("b", "nginx", 5, 50),
+			},
+		},
+		{
+			name: "bottom without rollup",
+			plugin: &TopN{
+				N:           1,
+				Field:       "cpu_usage",
+				GroupBy:     []string{"host"},
+				Aggregation: "max",
+				Bottom:      true,
+			},
+			expected: []telegraf.Metric{
+				result("a", "cron", 1, 10),
+				result("b", "nginx", 5, 50),
+			},
+		},
+		{
+			name: "global with mean rollup",
+			plugin: &TopN{
+				N:           1,
+				Field:       "cpu_usage",
+				Aggregation: "sum",
+				Rollup:      "mean",
+				OtherValue:  "rest",
+			},
+			expected: []telegraf.Metric{
+				result("a", "postgres", 40, 400),
+				metric.New("procstat",
+					map[string]string{"host": "rest", "process_name": "rest"},
+					map[string]interface{}{"cpu_usage": 9.5, "memory_rss": 57.0, "series_count": int64(5)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := tt.plugin
+			plugin.Log = testutil.Logger{}
+			require.NoError(t, plugin.Init())
+
+			for _, m := range input {
+				plugin.Add(m)
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+			// Series do not carry over to the next period
+			acc.ClearMetrics()
+			plugin.Reset()
+			plugin.Push(&acc)
+			require.Empty(t, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func process(host, name string, cpu float64, rss int64) telegraf.Metric {
+	return metric.New("procstat",
+		map[string]string{"host": host, "process_name": name},
+		map[string]interface{}{"cpu_usage": cpu, "memory_rss": rss, "state": "R"},
+		time.Unix(0, 0),
+	)
+}
+
+func result(host, name string, cpu, rss float64) telegraf.Metric {
+	return metric.New("procstat",
+		map[string]string{"host": host, "process_name": name},
+		map[string]interface{}{"cpu_usage": cpu, "memory_rss": rss},
+		time.Unix(0, 0),
+	)
+}
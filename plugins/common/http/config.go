@@ -29,6 +29,10 @@ type HTTPClientConfig struct {
 	tls.ClientConfig
 	oauthConfig.OAuth2Config
 	cookie.CookieAuthConfig
+
+	// Socks5 is not part of the common options but set by plugins offering
+	// a SOCKS5 proxy
+	Socks5 *proxy.Socks5ProxyConfig `toml:"-"`
 }
 
 func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger) (*http.Client, error) {
@@ -51,6 +55,17 @@ func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger
 		ResponseHeaderTimeout: time.Duration(h.ResponseHeaderTimeout),
 	}
 
+	// Connect via the SOCKS5 proxy if enabled. In case an HTTP proxy is
+	// configured as well, the connection to the HTTP proxy is established
+	// through the SOCKS5 proxy.
+	if h.Socks5 != nil && h.Socks5.Socks5ProxyEnabled {
+		dialer, err := h.Socks5.GetProxiedDialer()
+		if err != nil {
+			return nil, fmt.Errorf("failed to set socks5 proxy: %w", err)
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
 	// Register "http+unix" and "https+unix" protocol handler.
 	unixtransport.Register(transport)
 
@@ -64,7 +79,10 @@ func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger
 		Timeout:   time.Duration(timeout),
 	}
 
-	client = h.OAuth2Config.CreateOauth2Client(ctx, client)
+	client, err = h.OAuth2Config.CreateOauth2Client(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set OAuth2 config: %w", err)
+	}
 
 	if h.CookieAuthConfig.URL != "" {
 		if err := h.CookieAuthConfig.Start(client, log, clock.New()); err != nil {
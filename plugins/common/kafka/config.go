@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"strings"
@@ -28,10 +29,11 @@ func (k *ReadConfig) SetConfig(config *sarama.Config, log telegraf.Logger) error
 type WriteConfig struct {
 	Config
 
-	RequiredAcks     int  `toml:"required_acks"`
-	MaxRetry         int  `toml:"max_retry"`
-	MaxMessageBytes  int  `toml:"max_message_bytes"`
-	IdempotentWrites bool `toml:"idempotent_writes"`
+	RequiredAcks     int    `toml:"required_acks"`
+	MaxRetry         int    `toml:"max_retry"`
+	MaxMessageBytes  int    `toml:"max_message_bytes"`
+	IdempotentWrites bool   `toml:"idempotent_writes"`
+	TransactionalID  string `toml:"transactional_id"`
 }
 
 // SetConfig on the sarama.Config object from the WriteConfig struct.
@@ -43,6 +45,14 @@ func (k *WriteConfig) SetConfig(config *sarama.Config, log telegraf.Logger) erro
 		config.Producer.MaxMessageBytes = k.MaxMessageBytes
 	}
 	config.Producer.RequiredAcks = sarama.RequiredAcks(k.RequiredAcks)
+	if k.TransactionalID != "" {
+		// Transactions require an idempotent producer waiting for all replicas
+		if config.Producer.RequiredAcks != sarama.WaitForAll {
+			return errors.New("transactional writes require 'required_acks = -1'")
+		}
+		config.Producer.Idempotent = true
+		config.Producer.Transaction.ID = k.TransactionalID
+	}
 	if config.Producer.Idempotent {
 		config.Net.MaxOpenRequests = 1
 	}
@@ -4,7 +4,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestBackoffFunc(t *testing.T) {
@@ -20,3 +23,18 @@ func TestBackoffFunc(t *testing.T) {
 	f = makeBackoffFunc(b, 0)      // max = 0 means no max
 	require.Equal(t, b*8, f(3, 0)) // with no max, it's 2000
 }
+
+func TestTransactionalWriteConfig(t *testing.T) {
+	cfg := &WriteConfig{
+		RequiredAcks:    -1,
+		TransactionalID: "telegraf-1",
+	}
+	config := sarama.NewConfig()
+	require.NoError(t, cfg.SetConfig(config, testutil.Logger{}))
+	require.True(t, config.Producer.Idempotent)
+	require.Equal(t, "telegraf-1", config.Producer.Transaction.ID)
+	require.Equal(t, 1, config.Net.MaxOpenRequests)
+
+	cfg.RequiredAcks = 1
+	require.ErrorContains(t, cfg.SetConfig(sarama.NewConfig(), testutil.Logger{}), "required_acks")
+}
@@ -15,11 +15,13 @@ import (
 // mqtt v5-specific publish properties.
 // See https://docs.oasis-open.org/mqtt/mqtt/v5.0/os/mqtt-v5.0-os.html#_Toc3901109
 type PublishProperties struct {
-	ContentType    string            `toml:"content_type"`
-	ResponseTopic  string            `toml:"response_topic"`
-	MessageExpiry  config.Duration   `toml:"message_expiry"`
-	TopicAlias     *uint16           `toml:"topic_alias"`
-	UserProperties map[string]string `toml:"user_properties"`
+	ContentType       string            `toml:"content_type"`
+	ResponseTopic     string            `toml:"response_topic"`
+	MessageExpiry     config.Duration   `toml:"message_expiry"`
+	TopicAlias        *uint16           `toml:"topic_alias"`
+	TopicAliasMaximum uint16            `toml:"topic_alias_maximum"`
+	UserPropertyTags  []string          `toml:"user_property_tags"`
+	UserProperties    map[string]string `toml:"user_properties"`
 }
 
 type MqttConfig struct {
@@ -44,10 +46,12 @@ type MqttConfig struct {
 
 // Client is a protocol neutral MQTT client for connecting,
 // disconnecting, and publishing data to a topic.
-// The protocol specific clients must implement this interface
+// The protocol specific clients must implement this interface.
+// The user properties passed to Publish are only sent by clients supporting
+// them (MQTT 5) and are ignored otherwise.
 type Client interface {
 	Connect() (bool, error)
-	Publish(topic string, data []byte) error
+	Publish(topic string, data []byte, userProperties map[string]string) error
 	SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error
 	AddRoute(topic string, callback paho.MessageHandler)
 	Close() error
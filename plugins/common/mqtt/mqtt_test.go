@@ -3,6 +3,7 @@ package mqtt
 import (
 	"testing"
 
+	mqttv5 "github.com/eclipse/paho.golang/paho"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,3 +25,91 @@ func TestRandomClientID(t *testing.T) {
 	options2 := client2.client.OptionsReader()
 	require.NotEqual(t, options1.ClientID(), options2.ClientID())
 }
+
+func TestTopicAliasMutuallyExclusive(t *testing.T) {
+	cfg := &MqttConfig{
+		Servers: []string{"tcp://localhost:1883"},
+		PublishPropertiesV5: &PublishProperties{
+			TopicAlias:        new(uint16),
+			TopicAliasMaximum: 10,
+		},
+	}
+	_, err := NewMQTTv5Client(cfg)
+	require.ErrorContains(t, err, "cannot be used together")
+}
+
+func TestTopicAlias(t *testing.T) {
+	cfg := &MqttConfig{
+		Servers:             []string{"tcp://localhost:1883"},
+		PublishPropertiesV5: &PublishProperties{TopicAliasMaximum: 10},
+	}
+	client, err := NewMQTTv5Client(cfg)
+	require.NoError(t, err)
+
+	// No aliases are used before the broker announced its limit
+	topic, alias, _ := client.alias("a")
+	require.Equal(t, "a", topic)
+	require.Nil(t, alias)
+
+	// The broker limits the number of aliases
+	maximum := uint16(2)
+	client.onConnectionUp(nil, &mqttv5.Connack{
+		Properties: &mqttv5.ConnackProperties{TopicAliasMaximum: &maximum},
+	})
+
+	topic, alias, registering := client.alias("a")
+	require.Equal(t, "a", topic)
+	require.Equal(t, uint16(1), *alias)
+	require.True(t, registering)
+
+	topic, alias, registering = client.alias("a")
+	require.Empty(t, topic)
+	require.Equal(t, uint16(1), *alias)
+	require.False(t, registering)
+
+	topic, alias, _ = client.alias("b")
+	require.Equal(t, "b", topic)
+	require.Equal(t, uint16(2), *alias)
+
+	topic, alias, _ = client.alias("c")
+	require.Equal(t, "c", topic)
+	require.Nil(t, alias)
+
+	// Failed registrations are repeated with the next message
+	client.unregisterAlias("b")
+	topic, alias, registering = client.alias("b")
+	require.Equal(t, "b", topic)
+	require.Equal(t, uint16(2), *alias)
+	require.True(t, registering)
+
+	// Aliases are reset on reconnect
+	client.onConnectionUp(nil, &mqttv5.Connack{})
+	topic, alias, _ = client.alias("a")
+	require.Equal(t, "a", topic)
+	require.Nil(t, alias)
+}
+
+func TestPublishUserProperties(t *testing.T) {
+	cfg := &MqttConfig{
+		Servers: []string{"tcp://localhost:1883"},
+		PublishPropertiesV5: &PublishProperties{
+			ContentType:    "text/plain",
+			UserProperties: map[string]string{"static": "value"},
+		},
+	}
+	client, err := NewMQTTv5Client(cfg)
+	require.NoError(t, err)
+
+	require.Same(t, client.properties, client.publishProperties(nil))
+
+	properties := client.publishProperties(map[string]string{"host": "a", "region": "eu"})
+	require.Equal(t, "text/plain", properties.ContentType)
+	require.Equal(t, mqttv5.UserProperties{
+		{Key: "static", Value: "value"},
+		{Key: "host", Value: "a"},
+		{Key: "region", Value: "eu"},
+	}, properties.User)
+
+	// The static properties must not be modified
+	require.Len(t, client.properties.User, 1)
+}
@@ -104,7 +104,7 @@ func (m *mqttv311Client) Connect() (bool, error) {
 	return false, nil
 }
 
-func (m *mqttv311Client) Publish(topic string, body []byte) error {
+func (m *mqttv311Client) Publish(topic string, body []byte, _ map[string]string) error {
 	token := m.client.Publish(topic, byte(m.qos), m.retain, body)
 	if !token.WaitTimeout(m.timeout) {
 		return internal.ErrTimeout
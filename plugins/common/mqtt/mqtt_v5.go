@@ -2,8 +2,11 @@ package mqtt
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"sort"
+	"sync"
 	"time"
 
 	mqttv5auto "github.com/eclipse/paho.golang/autopaho"
@@ -23,6 +26,14 @@ type mqttv5Client struct {
 	qos        int
 	retain     bool
 	properties *mqttv5.PublishProperties
+
+	// Automatic topic aliasing, the aliases are only valid for the current
+	// network connection and are reset on reconnect
+	aliasMaximum uint16
+	aliasLimit   uint16
+	aliases      map[string]uint16
+	unregistered map[string]bool
+	aliasLock    sync.Mutex
 }
 
 func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
@@ -75,7 +86,13 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 	// Build the v5 specific publish properties if they are present in the config.
 	// These should not change during the lifecycle of the client.
 	var properties *mqttv5.PublishProperties
+	var aliasMaximum uint16
 	if cfg.PublishPropertiesV5 != nil {
+		if cfg.PublishPropertiesV5.TopicAlias != nil && cfg.PublishPropertiesV5.TopicAliasMaximum > 0 {
+			return nil, errors.New("'topic_alias' and 'topic_alias_maximum' cannot be used together")
+		}
+		aliasMaximum = cfg.PublishPropertiesV5.TopicAliasMaximum
+
 		properties = &mqttv5.PublishProperties{
 			ContentType:   cfg.PublishPropertiesV5.ContentType,
 			ResponseTopic: cfg.PublishPropertiesV5.ResponseTopic,
@@ -93,15 +110,91 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 		}
 	}
 
-	return &mqttv5Client{
-		options:    opts,
-		timeout:    time.Duration(cfg.Timeout),
-		username:   cfg.Username,
-		password:   cfg.Password,
-		qos:        cfg.QoS,
-		retain:     cfg.Retain,
-		properties: properties,
-	}, nil
+	client := &mqttv5Client{
+		options:      opts,
+		timeout:      time.Duration(cfg.Timeout),
+		username:     cfg.Username,
+		password:     cfg.Password,
+		qos:          cfg.QoS,
+		retain:       cfg.Retain,
+		properties:   properties,
+		aliasMaximum: aliasMaximum,
+	}
+	client.options.OnConnectionUp = client.onConnectionUp
+
+	return client, nil
+}
+
+func (m *mqttv5Client) onConnectionUp(_ *mqttv5auto.ConnectionManager, connack *mqttv5.Connack) {
+	m.aliasLock.Lock()
+	defer m.aliasLock.Unlock()
+
+	// The broker announces the number of topic aliases it accepts. If the
+	// property is missing, the broker does not accept any alias.
+	m.aliases = make(map[string]uint16)
+	m.unregistered = make(map[string]bool)
+	m.aliasLimit = 0
+	if connack != nil && connack.Properties != nil && connack.Properties.TopicAliasMaximum != nil {
+		m.aliasLimit = min(m.aliasMaximum, *connack.Properties.TopicAliasMaximum)
+	}
+}
+
+// alias returns the topic and alias to use for publishing to the given topic.
+// The first message to a topic registers the alias with the broker while
+// subsequent messages only send the alias with an empty topic.
+func (m *mqttv5Client) alias(topic string) (string, *uint16, bool) {
+	m.aliasLock.Lock()
+	defer m.aliasLock.Unlock()
+
+	if alias, found := m.aliases[topic]; found {
+		if m.unregistered[topic] {
+			delete(m.unregistered, topic)
+			return topic, &alias, true
+		}
+		return "", &alias, false
+	}
+	if len(m.aliases) >= int(m.aliasLimit) {
+		return topic, nil, false
+	}
+	alias := uint16(len(m.aliases) + 1)
+	m.aliases[topic] = alias
+	return topic, &alias, true
+}
+
+// unregisterAlias marks the alias of the topic as unknown to the broker so
+// that the next message registers the alias again
+func (m *mqttv5Client) unregisterAlias(topic string) {
+	m.aliasLock.Lock()
+	defer m.aliasLock.Unlock()
+
+	if _, found := m.aliases[topic]; found {
+		m.unregistered[topic] = true
+	}
+}
+
+func (m *mqttv5Client) publishProperties(userProperties map[string]string) *mqttv5.PublishProperties {
+	if len(userProperties) == 0 {
+		return m.properties
+	}
+
+	properties := &mqttv5.PublishProperties{}
+	if m.properties != nil {
+		*properties = *m.properties
+	}
+	properties.User = make([]mqttv5.UserProperty, 0, len(properties.User)+len(userProperties))
+	if m.properties != nil {
+		properties.User = append(properties.User, m.properties.User...)
+	}
+
+	keys := make([]string, 0, len(userProperties))
+	for k := range userProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		properties.User.Add(k, userProperties[k])
+	}
+	return properties
 }
 
 func (m *mqttv5Client) Connect() (bool, error) {
@@ -125,17 +218,39 @@ func (m *mqttv5Client) Connect() (bool, error) {
 	return false, client.AwaitConnection(context.Background())
 }
 
-func (m *mqttv5Client) Publish(topic string, body []byte) error {
+func (m *mqttv5Client) Publish(topic string, body []byte, userProperties map[string]string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
 	defer cancel()
 
+	properties := m.publishProperties(userProperties)
+	msgTopic := topic
+	var registered bool
+	if m.aliasMaximum > 0 {
+		var alias *uint16
+		msgTopic, alias, registered = m.alias(topic)
+		if alias != nil {
+			// Do not modify the shared properties
+			p := &mqttv5.PublishProperties{}
+			if properties != nil {
+				*p = *properties
+			}
+			p.TopicAlias = alias
+			properties = p
+		}
+	}
+
 	_, err := m.client.Publish(ctx, &mqttv5.Publish{
-		Topic:      topic,
+		Topic:      msgTopic,
 		QoS:        byte(m.qos),
 		Retain:     m.retain,
 		Payload:    body,
-		Properties: m.properties,
+		Properties: properties,
 	})
+	if err != nil && registered {
+		// The broker might not know about the alias, so register it again
+		// with the next message
+		m.unregisterAlias(topic)
+	}
 
 	return err
 }
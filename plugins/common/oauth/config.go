@@ -2,6 +2,7 @@ package oauth
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
 
@@ -16,11 +17,24 @@ type OAuth2Config struct {
 	TokenURL     string   `toml:"token_url"`
 	Audience     string   `toml:"audience"`
 	Scopes       []string `toml:"scopes"`
+	AuthStyle    string   `toml:"token_auth_style"`
 }
 
-func (o *OAuth2Config) CreateOauth2Client(ctx context.Context, client *http.Client) *http.Client {
+func (o *OAuth2Config) CreateOauth2Client(ctx context.Context, client *http.Client) (*http.Client, error) {
 	if o.ClientID == "" || o.ClientSecret == "" || o.TokenURL == "" {
-		return client
+		return client, nil
+	}
+
+	var style oauth2.AuthStyle
+	switch o.AuthStyle {
+	case "", "auto":
+		style = oauth2.AuthStyleAutoDetect
+	case "header":
+		style = oauth2.AuthStyleInHeader
+	case "params":
+		style = oauth2.AuthStyleInParams
+	default:
+		return nil, fmt.Errorf("invalid token_auth_style %q", o.AuthStyle)
 	}
 
 	oauthConfig := clientcredentials.Config{
@@ -29,14 +43,17 @@ func (o *OAuth2Config) CreateOauth2Client(ctx context.Context, client *http.Clie
 		TokenURL:       o.TokenURL,
 		Scopes:         o.Scopes,
 		EndpointParams: make(url.Values),
+		AuthStyle:      style,
 	}
 
 	if o.Audience != "" {
 		oauthConfig.EndpointParams.Add("audience", o.Audience)
 	}
 
+	// The returned client caches the token and automatically requests a new
+	// one from the token endpoint once the current token expired.
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
 	client = oauthConfig.Client(ctx)
 
-	return client
+	return client, nil
 }
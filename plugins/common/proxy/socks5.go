@@ -20,3 +20,13 @@ func (c *Socks5ProxyConfig) GetDialer() (proxy.Dialer, error) {
 	}
 	return proxy.SOCKS5("tcp", c.Socks5ProxyAddress, auth, proxy.Direct)
 }
+
+// GetProxiedDialer returns the SOCKS5 dialer wrapped to be usable as dial
+// function of HTTP transports
+func (c *Socks5ProxyConfig) GetProxiedDialer() (*ProxiedDialer, error) {
+	dialer, err := c.GetDialer()
+	if err != nil {
+		return nil, err
+	}
+	return &ProxiedDialer{dialer}, nil
+}
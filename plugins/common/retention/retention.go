@@ -0,0 +1,62 @@
+package retention
+
+import (
+	"errors"
+
+	"github.com/influxdata/telegraf"
+)
+
+// HintConfig maps the value of a tag to an output-specific retention hint,
+// e.g. a bucket, a retention period or a TTL clause. This allows to store
+// metrics of the same output with different retention, for example keeping
+// high-resolution debug metrics shorter than SLO metrics.
+type HintConfig struct {
+	RetentionTag        string            `toml:"retention_tag"`
+	RetentionHints      map[string]string `toml:"retention_hints"`
+	ExcludeRetentionTag bool              `toml:"exclude_retention_tag"`
+}
+
+// Validate checks the consistency of the retention settings. The method is
+// intentionally not called Init to avoid promoting it to outputs embedding
+// the config.
+func (c *HintConfig) Validate() error {
+	if c.RetentionTag == "" && len(c.RetentionHints) > 0 {
+		return errors.New("retention_hints requires retention_tag to be set")
+	}
+	if c.RetentionTag != "" && len(c.RetentionHints) == 0 {
+		return errors.New("retention_tag requires retention_hints to be set")
+	}
+	return nil
+}
+
+// Enabled returns true if retention hints are configured
+func (c *HintConfig) Enabled() bool {
+	return c.RetentionTag != ""
+}
+
+// Hint returns the hint mapped to the value of the retention tag of the
+// metric. False is returned for metrics without the tag or with a value
+// without mapping, in which case the output's default should be used.
+func (c *HintConfig) Hint(m telegraf.Metric) (string, bool) {
+	if c.RetentionTag == "" {
+		return "", false
+	}
+	class, found := m.GetTag(c.RetentionTag)
+	if !found {
+		return "", false
+	}
+	hint, found := c.RetentionHints[class]
+	return hint, found
+}
+
+// Strip returns the metric without the retention tag if the tag should be
+// excluded. The given metric is not modified so writes can be retried.
+func (c *HintConfig) Strip(m telegraf.Metric) telegraf.Metric {
+	if !c.ExcludeRetentionTag || !m.HasTag(c.RetentionTag) {
+		return m
+	}
+	m = m.Copy()
+	m.Accept()
+	m.RemoveTag(c.RetentionTag)
+	return m
+}
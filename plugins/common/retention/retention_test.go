@@ -0,0 +1,55 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestHint(t *testing.T) {
+	cfg := &HintConfig{
+		RetentionTag:   "retention",
+		RetentionHints: map[string]string{"debug": "7d", "slo": "400d"},
+	}
+	require.NoError(t, cfg.Validate())
+	require.True(t, cfg.Enabled())
+
+	m := testutil.MustMetric("cpu", map[string]string{"retention": "debug"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	hint, found := cfg.Hint(m)
+	require.True(t, found)
+	require.Equal(t, "7d", hint)
+
+	// Unmapped values and metrics without the tag have no hint
+	m = testutil.MustMetric("cpu", map[string]string{"retention": "other"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	_, found = cfg.Hint(m)
+	require.False(t, found)
+
+	m = testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	_, found = cfg.Hint(m)
+	require.False(t, found)
+}
+
+func TestStrip(t *testing.T) {
+	m := testutil.MustMetric("cpu", map[string]string{"retention": "debug", "host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+
+	cfg := &HintConfig{RetentionTag: "retention", RetentionHints: map[string]string{"debug": "7d"}}
+	require.Same(t, m, cfg.Strip(m))
+
+	cfg.ExcludeRetentionTag = true
+	stripped := cfg.Strip(m)
+	require.Equal(t, map[string]string{"host": "a"}, stripped.Tags())
+
+	// The original metric is kept for retries
+	require.Equal(t, map[string]string{"retention": "debug", "host": "a"}, m.Tags())
+}
+
+func TestValidateInvalid(t *testing.T) {
+	cfg := &HintConfig{RetentionHints: map[string]string{"debug": "7d"}}
+	require.ErrorContains(t, cfg.Validate(), "retention_hints requires retention_tag")
+
+	cfg = &HintConfig{RetentionTag: "retention"}
+	require.ErrorContains(t, cfg.Validate(), "retention_tag requires retention_hints")
+}
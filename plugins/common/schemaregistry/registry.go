@@ -0,0 +1,173 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// ErrRequest indicates a failure communicating with the schema registry,
+// e.g. because the registry is unavailable
+var ErrRequest = errors.New("schema registry request failed")
+
+// Schema is an Avro schema with its codec
+type Schema struct {
+	Schema string
+	Codec  *goavro.Codec
+}
+
+// Registry is a client for the Confluent Schema Registry caching the
+// retrieved schemas
+type Registry struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+
+	cache map[int]*Schema
+	sync.Mutex
+}
+
+// New creates a client for the registry at the given address, credentials
+// can be specified as user-info of the address
+func New(addr, caCertPath string) (*Registry, error) {
+	var tlsCfg *tls.Config
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tlsCfg = &tls.Config{
+			RootCAs: caCertPool,
+		}
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL failed: %w", err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	return &Registry{
+		url:      strings.TrimSuffix(u.String(), "/"),
+		username: username,
+		password: password,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsCfg,
+				MaxIdleConns:    10,
+				IdleConnTimeout: 90 * time.Second,
+			},
+			Timeout: 10 * time.Second,
+		},
+		cache: make(map[int]*Schema),
+	}, nil
+}
+
+// SchemaByID returns the schema with the given ID
+func (r *Registry) SchemaByID(id int) (*Schema, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if s, found := r.cache[id]; found {
+		return s, nil
+	}
+
+	var response struct {
+		Schema *string `json:"schema"`
+	}
+	if err := r.do(http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &response); err != nil {
+		return nil, err
+	}
+	if response.Schema == nil {
+		return nil, errors.New("malformed response from schema registry: no 'schema' key")
+	}
+
+	codec, err := goavro.NewCodec(*response.Schema)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{Schema: *response.Schema, Codec: codec}
+	r.cache[id] = s
+	return s, nil
+}
+
+// Register registers the schema for the subject and returns the schema ID.
+// Registering an already existing schema returns the existing ID.
+func (r *Registry) Register(subject, schema string) (int, error) {
+	return r.post("/subjects/"+url.PathEscape(subject)+"/versions", schema)
+}
+
+// Lookup returns the ID of the schema already registered for the subject
+func (r *Registry) Lookup(subject, schema string) (int, error) {
+	return r.post("/subjects/"+url.PathEscape(subject), schema)
+}
+
+func (r *Registry) post(path, schema string) (int, error) {
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		ID int `json:"id"`
+	}
+	if err := r.do(http.MethodPost, path, body, &response); err != nil {
+		return 0, err
+	}
+	return response.ID, nil
+}
+
+func (r *Registry) do(method, path string, body []byte, response interface{}) error {
+	req, err := http.NewRequest(method, r.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("schema registry returned %q: %s", resp.Status, string(msg))
+		// Client errors like unknown subjects will not resolve on retry
+		if resp.StatusCode < 500 {
+			return err
+		}
+		return fmt.Errorf("%w: %w", ErrRequest, err)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("decoding schema registry response failed: %w", err)
+	}
+	return nil
+}
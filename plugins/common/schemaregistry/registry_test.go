@@ -0,0 +1,76 @@
+package schemaregistry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaByID(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/schemas/ids/42", r.URL.Path)
+		username, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", username)
+		require.Equal(t, "secret", password)
+
+		_, err := w.Write([]byte(`{"schema": "{\"type\": \"string\"}"}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	registry, err := New(strings.Replace(ts.URL, "http://", "http://user:secret@", 1), "")
+	require.NoError(t, err)
+
+	schema, err := registry.SchemaByID(42)
+	require.NoError(t, err)
+	require.Equal(t, `{"type": "string"}`, schema.Schema)
+	require.NotNil(t, schema.Codec)
+
+	// The schema is cached
+	_, err = registry.SchemaByID(42)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestSchemaByIDMalformed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"id": 42}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	registry, err := New(ts.URL, "")
+	require.NoError(t, err)
+
+	_, err = registry.SchemaByID(42)
+	require.EqualError(t, err, "malformed response from schema registry: no 'schema' key")
+}
+
+func TestErrors(t *testing.T) {
+	var status atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer ts.Close()
+
+	registry, err := New(ts.URL, "")
+	require.NoError(t, err)
+
+	// Server errors might resolve on retry while client errors will not
+	status.Store(http.StatusServiceUnavailable)
+	_, err = registry.Register("cpu", `"string"`)
+	require.ErrorIs(t, err, ErrRequest)
+
+	status.Store(http.StatusUnprocessableEntity)
+	_, err = registry.Register("cpu", `"string"`)
+	require.ErrorContains(t, err, "422 Unprocessable Entity")
+	require.NotErrorIs(t, err, ErrRequest)
+}
@@ -0,0 +1,136 @@
+package starlark
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// modules caches the user modules loaded by all plugin instances. Loaded
+// modules are frozen and can thus be shared between instances.
+var modules = &moduleCache{entries: make(map[string]*moduleEntry)}
+
+type moduleCache struct {
+	entries map[string]*moduleEntry
+	sync.Mutex
+}
+
+type moduleEntry struct {
+	modTime time.Time
+	globals starlark.StringDict
+	err     error
+	ready   chan struct{}
+}
+
+// loadModule loads the user module from the given file. The chain contains
+// the modules currently being loaded to detect cycles.
+func loadModule(path string, chain []string, load func(string, []string) (starlark.StringDict, error)) (starlark.StringDict, error) {
+	for _, p := range chain {
+		if p == path {
+			return nil, fmt.Errorf("cycle in loading module %q", path)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reuse the module unless the file changed since loading it
+	modules.Lock()
+	if e, found := modules.entries[path]; found && e.modTime.Equal(info.ModTime()) {
+		modules.Unlock()
+		<-e.ready
+		return e.globals, e.err
+	}
+	e := &moduleEntry{modTime: info.ModTime(), ready: make(chan struct{})}
+	modules.entries[path] = e
+	modules.Unlock()
+
+	next := append(append(make([]string, 0, len(chain)+1), chain...), path)
+	e.globals, e.err = execModule(path, next, load)
+	close(e.ready)
+
+	return e.globals, e.err
+}
+
+func execModule(path string, chain []string, load func(string, []string) (starlark.StringDict, error)) (starlark.StringDict, error) {
+	thread := &starlark.Thread{
+		Name: path,
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return load(module, chain)
+		},
+	}
+
+	// Modules only have access to the builtins common to all instances but
+	// not to the instance specific constants
+	builtins := starlark.StringDict{
+		"Metric":   starlark.NewBuiltin("Metric", newMetric),
+		"deepcopy": starlark.NewBuiltin("deepcopy", deepcopy),
+		"catch":    starlark.NewBuiltin("catch", catch),
+	}
+	options := syntax.FileOptions{
+		Recursion:      true,
+		GlobalReassign: true,
+		Set:            true,
+	}
+	globals, err := starlark.ExecFileOptions(&options, thread, path, nil, builtins)
+	if err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			return nil, fmt.Errorf("%s", evalErr.Backtrace())
+		}
+		return nil, err
+	}
+	globals.Freeze()
+
+	return globals, nil
+}
+
+// resolveModule returns the path of the user module searching the directory
+// of the loading module, the directory of the script and the configured
+// module paths in this order
+func (s *Common) resolveModule(module string, chain []string) (string, bool) {
+	if filepath.IsAbs(module) {
+		return module, true
+	}
+
+	dirs := make([]string, 0, len(s.ModulePaths)+2)
+	if len(chain) > 0 {
+		dirs = append(dirs, filepath.Dir(chain[len(chain)-1]))
+	}
+	if s.Script != "" {
+		dirs = append(dirs, filepath.Dir(s.Script))
+	}
+	dirs = append(dirs, s.ModulePaths...)
+	for _, dir := range dirs {
+		path := filepath.Join(dir, module)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil {
+				return abs, true
+			}
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// load loads a built-in library or a user module
+func (s *Common) load(module string, chain []string) (starlark.StringDict, error) {
+	globals, err := s.StarlarkLoadFunc(module, s.Log)
+	if err == nil {
+		return globals, nil
+	}
+
+	path, found := s.resolveModule(module, chain)
+	if !found {
+		return nil, err
+	}
+	return loadModule(path, chain, s.load)
+}
@@ -15,15 +15,17 @@ import (
 )
 
 type Common struct {
-	Source    string                 `toml:"source"`
-	Script    string                 `toml:"script"`
-	Constants map[string]interface{} `toml:"constants"`
+	Source      string                 `toml:"source"`
+	Script      string                 `toml:"script"`
+	Constants   map[string]interface{} `toml:"constants"`
+	ModulePaths []string               `toml:"module_paths"`
 
 	Log              telegraf.Logger `toml:"-"`
 	StarlarkLoadFunc func(module string, logger telegraf.Logger) (starlark.StringDict, error)
 
 	thread     *starlark.Thread
 	globals    starlark.StringDict
+	state      *starlark.Dict
 	functions  map[string]*starlark.Function
 	parameters map[string]starlark.Tuple
 }
@@ -38,8 +40,8 @@ func (s *Common) Init() error {
 
 	s.thread = &starlark.Thread{
 		Print: func(_ *starlark.Thread, msg string) { s.Log.Debug(msg) },
-		Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
-			return s.StarlarkLoadFunc(module, s.Log)
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return s.load(module, nil)
 		},
 	}
 
@@ -62,6 +64,11 @@ func (s *Common) Init() error {
 	if err != nil {
 		return err
 	}
+	// Keep the state defined by the script to allow persisting it
+	if state, ok := globals["state"].(*starlark.Dict); ok {
+		s.state = state
+	}
+
 	// Make available a shared state to the apply function
 	globals["state"] = starlark.NewDict(0)
 
@@ -77,6 +84,12 @@ func (s *Common) Init() error {
 	return nil
 }
 
+// State returns the 'state' dictionary defined by the script or nil if the
+// script does not define a state
+func (s *Common) State() *starlark.Dict {
+	return s.state
+}
+
 func (s *Common) GetParameters(name string) (starlark.Tuple, bool) {
 	parameters, found := s.parameters[name]
 	return parameters, found
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.azure_resource_graph
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/azure_resource_graph" // register plugin
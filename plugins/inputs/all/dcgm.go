@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.dcgm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/dcgm" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ebpf_network
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ebpf_network" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.file_inventory
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/file_inventory" // register plugin
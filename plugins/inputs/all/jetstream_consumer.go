@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.jetstream_consumer
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/jetstream_consumer" // register plugin
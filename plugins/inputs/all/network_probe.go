@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.network_probe
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/network_probe" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.packet_capture
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/packet_capture" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.podman
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/podman" // register plugin
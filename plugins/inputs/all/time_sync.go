@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.time_sync
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/time_sync" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.tls_scanner
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/tls_scanner" // register plugin
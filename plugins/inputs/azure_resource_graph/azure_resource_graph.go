@@ -0,0 +1,260 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package azure_resource_graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum number of rows returned per request by the API
+const pageSize = 1000
+
+type AzureResourceGraph struct {
+	TenantID         string          `toml:"tenant_id"`
+	ClientID         string          `toml:"client_id"`
+	ClientSecret     config.Secret   `toml:"client_secret"`
+	Subscriptions    []string        `toml:"subscriptions"`
+	ManagementGroups []string        `toml:"management_groups"`
+	Timeout          config.Duration `toml:"timeout"`
+	Queries          []*Query        `toml:"query"`
+	Log              telegraf.Logger `toml:"-"`
+
+	client queryClient
+}
+
+// Query is a Resource Graph query along with the columns to use as tags
+type Query struct {
+	Measurement string   `toml:"measurement"`
+	Query       string   `toml:"query"`
+	TagColumns  []string `toml:"tag_columns"`
+
+	tagColumns map[string]bool
+}
+
+// queryClient is implemented by armresourcegraph.Client and allows to mock
+// the API in tests
+type queryClient interface {
+	Resources(
+		ctx context.Context,
+		query armresourcegraph.QueryRequest,
+		options *armresourcegraph.ClientResourcesOptions,
+	) (armresourcegraph.ClientResourcesResponse, error)
+}
+
+func (*AzureResourceGraph) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AzureResourceGraph) Init() error {
+	if len(a.Queries) == 0 {
+		return errors.New("no query configured")
+	}
+	for i, q := range a.Queries {
+		if q.Query == "" {
+			return fmt.Errorf("empty query %d", i+1)
+		}
+		if q.Measurement == "" {
+			q.Measurement = "azure_resource_graph"
+		}
+		q.tagColumns = make(map[string]bool, len(q.TagColumns))
+		for _, c := range q.TagColumns {
+			q.tagColumns[c] = true
+		}
+	}
+
+	if a.client != nil {
+		return nil
+	}
+
+	cred, err := a.credential()
+	if err != nil {
+		return fmt.Errorf("creating credentials failed: %w", err)
+	}
+	client, err := armresourcegraph.NewClient(cred, nil)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	a.client = client
+
+	return nil
+}
+
+// credential returns the client secret credentials if configured and falls
+// back to the default credential chain of the Azure SDK otherwise, e.g. to
+// use environment variables or managed identities.
+func (a *AzureResourceGraph) credential() (azcore.TokenCredential, error) {
+	if a.ClientSecret.Empty() {
+		var options *azidentity.DefaultAzureCredentialOptions
+		if a.TenantID != "" {
+			options = &azidentity.DefaultAzureCredentialOptions{TenantID: a.TenantID}
+		}
+		return azidentity.NewDefaultAzureCredential(options)
+	}
+
+	if a.TenantID == "" || a.ClientID == "" {
+		return nil, errors.New("tenant_id and client_id are required when using a client secret")
+	}
+	secret, err := a.ClientSecret.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting client secret failed: %w", err)
+	}
+	defer secret.Destroy()
+
+	return azidentity.NewClientSecretCredential(a.TenantID, a.ClientID, secret.String(), nil)
+}
+
+func (a *AzureResourceGraph) Gather(acc telegraf.Accumulator) error {
+	// Run the queries sequentially as the API throttles requests per user
+	for _, q := range a.Queries {
+		if err := a.gatherQuery(acc, q); err != nil {
+			acc.AddError(fmt.Errorf("query %q failed: %w", q.Measurement, err))
+		}
+	}
+	return nil
+}
+
+func (a *AzureResourceGraph) gatherQuery(acc telegraf.Accumulator, q *Query) error {
+	ctx := context.Background()
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(a.Timeout))
+		defer cancel()
+	}
+
+	request := armresourcegraph.QueryRequest{
+		Query:            &q.Query,
+		Subscriptions:    toPointers(a.Subscriptions),
+		ManagementGroups: toPointers(a.ManagementGroups),
+		Options: &armresourcegraph.QueryRequestOptions{
+			ResultFormat: toPointer(armresourcegraph.ResultFormatTable),
+			Top:          toPointer(int32(pageSize)),
+		},
+	}
+
+	now := time.Now()
+	for {
+		resp, err := a.client.Resources(ctx, request, nil)
+		if err != nil {
+			return err
+		}
+		if err := addTable(acc, q, resp.Data, now); err != nil {
+			return err
+		}
+
+		if resp.SkipToken == nil || *resp.SkipToken == "" {
+			return nil
+		}
+		options := *request.Options
+		options.SkipToken = resp.SkipToken
+		request.Options = &options
+	}
+}
+
+// addTable adds a metric for each row of a result in the table format
+//
+//	{"columns": [{"name": "type", "type": "string"}, ...], "rows": [["microsoft.compute/disks", ...], ...]}
+func addTable(acc telegraf.Accumulator, q *Query, data interface{}, tm time.Time) error {
+	table, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected result type %T", data)
+	}
+	columns, ok := table["columns"].([]interface{})
+	if !ok {
+		return errors.New("missing columns in result")
+	}
+	rows, ok := table["rows"].([]interface{})
+	if !ok {
+		return errors.New("missing rows in result")
+	}
+
+	names := make([]string, 0, len(columns))
+	types := make([]string, 0, len(columns))
+	for _, c := range columns {
+		column, ok := c.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected column type %T", c)
+		}
+		name, _ := column["name"].(string)
+		ctype, _ := column["type"].(string)
+		names = append(names, name)
+		types = append(types, ctype)
+	}
+
+	for _, r := range rows {
+		row, ok := r.([]interface{})
+		if !ok || len(row) != len(names) {
+			return errors.New("number of values does not match number of columns")
+		}
+
+		tags := make(map[string]string)
+		fields := make(map[string]interface{})
+		for i, value := range row {
+			if value == nil {
+				continue
+			}
+			if q.tagColumns[names[i]] {
+				tags[names[i]] = fmt.Sprint(value)
+				continue
+			}
+			if v, ok := convertValue(value, types[i]); ok {
+				fields[names[i]] = v
+			}
+		}
+		acc.AddFields(q.Measurement, fields, tags, tm)
+	}
+	return nil
+}
+
+// convertValue converts the JSON decoded value of a column. Integer columns
+// are decoded as float, so convert them back according to the column type.
+// Dynamic values like arrays or property bags are skipped.
+func convertValue(value interface{}, columnType string) (interface{}, bool) {
+	switch v := value.(type) {
+	case float64:
+		switch columnType {
+		case "integer", "int", "long":
+			return int64(v), true
+		}
+		return v, true
+	case bool, string:
+		return v, true
+	}
+	return nil, false
+}
+
+func toPointer[T any](v T) *T {
+	return &v
+}
+
+func toPointers(values []string) []*string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]*string, 0, len(values))
+	for _, v := range values {
+		result = append(result, toPointer(v))
+	}
+	return result
+}
+
+func init() {
+	inputs.Add("azure_resource_graph", func() telegraf.Input {
+		return &AzureResourceGraph{
+			Timeout: config.Duration(30 * time.Second),
+		}
+	})
+}
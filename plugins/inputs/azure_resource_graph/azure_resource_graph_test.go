@@ -0,0 +1,198 @@
+package azure_resource_graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeClient struct {
+	pages    []armresourcegraph.QueryResponse
+	err      error
+	requests []armresourcegraph.QueryRequest
+}
+
+func (c *fakeClient) Resources(
+	_ context.Context,
+	query armresourcegraph.QueryRequest,
+	_ *armresourcegraph.ClientResourcesOptions,
+) (armresourcegraph.ClientResourcesResponse, error) {
+	c.requests = append(c.requests, query)
+	if c.err != nil {
+		return armresourcegraph.ClientResourcesResponse{}, c.err
+	}
+
+	page := c.pages[0]
+	c.pages = c.pages[1:]
+	return armresourcegraph.ClientResourcesResponse{QueryResponse: page}, nil
+}
+
+func table(rows ...[]interface{}) map[string]interface{} {
+	r := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		r = append(r, row)
+	}
+	return map[string]interface{}{
+		"columns": []interface{}{
+			map[string]interface{}{"name": "type", "type": "string"},
+			map[string]interface{}{"name": "location", "type": "string"},
+			map[string]interface{}{"name": "count", "type": "integer"},
+			map[string]interface{}{"name": "avg_disk_size", "type": "real"},
+			map[string]interface{}{"name": "encrypted", "type": "boolean"},
+			map[string]interface{}{"name": "sku", "type": "string"},
+			map[string]interface{}{"name": "tags", "type": "object"},
+		},
+		"rows": r,
+	}
+}
+
+func TestGather(t *testing.T) {
+	client := &fakeClient{
+		pages: []armresourcegraph.QueryResponse{
+			{
+				Data: table(
+					[]interface{}{"microsoft.compute/disks", "westeurope", 17.0, 128.5, true, "Premium_LRS", map[string]interface{}{"env": "prod"}},
+				),
+				SkipToken: toPointer("page2"),
+			},
+			{
+				Data: table(
+					[]interface{}{"microsoft.compute/disks", "northeurope", 3.0, 64.0, false, nil, nil},
+				),
+			},
+		},
+	}
+
+	plugin := &AzureResourceGraph{
+		Subscriptions: []string{"00000000-0000-0000-0000-000000000000"},
+		Queries: []*Query{
+			{
+				Query:      "Resources | where type =~ 'microsoft.compute/disks'",
+				TagColumns: []string{"type", "location"},
+			},
+		},
+		Log:    testutil.Logger{},
+		client: client,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"azure_resource_graph",
+			map[string]string{
+				"type":     "microsoft.compute/disks",
+				"location": "westeurope",
+			},
+			map[string]interface{}{
+				"count":         int64(17),
+				"avg_disk_size": 128.5,
+				"encrypted":     true,
+				"sku":           "Premium_LRS",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"azure_resource_graph",
+			map[string]string{
+				"type":     "microsoft.compute/disks",
+				"location": "northeurope",
+			},
+			map[string]interface{}{
+				"count":         int64(3),
+				"avg_disk_size": 64.0,
+				"encrypted":     false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The second page is requested with the skip token of the first one
+	require.Len(t, client.requests, 2)
+	require.Nil(t, client.requests[0].Options.SkipToken)
+	require.Equal(t, "page2", *client.requests[1].Options.SkipToken)
+	for _, r := range client.requests {
+		require.Equal(t, "Resources | where type =~ 'microsoft.compute/disks'", *r.Query)
+		require.Len(t, r.Subscriptions, 1)
+		require.Equal(t, "00000000-0000-0000-0000-000000000000", *r.Subscriptions[0])
+		require.Nil(t, r.ManagementGroups)
+		require.Equal(t, armresourcegraph.ResultFormatTable, *r.Options.ResultFormat)
+	}
+}
+
+func TestGatherError(t *testing.T) {
+	plugin := &AzureResourceGraph{
+		Queries: []*Query{
+			{Measurement: "disks", Query: "Resources"},
+			{Measurement: "vms", Query: "Resources"},
+		},
+		Log:    testutil.Logger{},
+		client: &fakeClient{err: errors.New("throttled")},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.EqualError(t, acc.Errors[0], `query "disks" failed: throttled`)
+	require.EqualError(t, acc.Errors[1], `query "vms" failed: throttled`)
+}
+
+func TestGatherInvalidResult(t *testing.T) {
+	client := &fakeClient{
+		pages: []armresourcegraph.QueryResponse{
+			{Data: []interface{}{map[string]interface{}{"type": "microsoft.compute/disks"}}},
+		},
+	}
+	plugin := &AzureResourceGraph{
+		Queries: []*Query{{Query: "Resources"}},
+		Log:     testutil.Logger{},
+		client:  client,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "unexpected result type")
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AzureResourceGraph
+		expected string
+	}{
+		{
+			name:     "no query",
+			plugin:   &AzureResourceGraph{},
+			expected: "no query configured",
+		},
+		{
+			name: "empty query",
+			plugin: &AzureResourceGraph{
+				Queries: []*Query{{Measurement: "disks"}},
+			},
+			expected: "empty query 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
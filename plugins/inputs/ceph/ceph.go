@@ -6,12 +6,16 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -44,13 +48,31 @@ type Ceph struct {
 	GatherAdminSocketStats bool   `toml:"gather_admin_socket_stats"`
 	GatherClusterStats     bool   `toml:"gather_cluster_stats"`
 
+	GatherMgrStats bool            `toml:"gather_mgr_stats"`
+	MgrURL         string          `toml:"mgr_url"`
+	MgrUsername    config.Secret   `toml:"mgr_username"`
+	MgrPassword    config.Secret   `toml:"mgr_password"`
+	MgrInclude     []string        `toml:"mgr_include"`
+	Timeout        config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
 	Log telegraf.Logger `toml:"-"`
+
+	mgrClient *http.Client
+	mgrToken  string
 }
 
 func (*Ceph) SampleConfig() string {
 	return sampleConfig
 }
 
+func (c *Ceph) Init() error {
+	if c.GatherMgrStats {
+		return c.initMgr()
+	}
+	return nil
+}
+
 func (c *Ceph) Gather(acc telegraf.Accumulator) error {
 	if c.GatherAdminSocketStats {
 		if err := c.gatherAdminSocketStats(acc); err != nil {
@@ -64,6 +86,12 @@ func (c *Ceph) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if c.GatherMgrStats {
+		if err := c.gatherMgrStats(acc); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -132,6 +160,8 @@ func init() {
 			CephConfig:             "/etc/ceph/ceph.conf",
 			GatherAdminSocketStats: true,
 			GatherClusterStats:     false,
+			MgrURL:                 "https://127.0.0.1:8443",
+			Timeout:                config.Duration(5 * time.Second),
 		}
 	})
 }
@@ -0,0 +1,311 @@
+package ceph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+)
+
+const mgrAcceptHeader = "application/vnd.ceph.api.v1.0+json"
+
+var errMgrUnauthorized = errors.New("unauthorized")
+
+// mgrValue is a performance counter reported by the dashboard API containing
+// the latest value and the rate per second
+type mgrValue struct {
+	Latest float64 `json:"latest"`
+	Rate   float64 `json:"rate"`
+}
+
+type mgrPool struct {
+	Name  string              `json:"pool_name"`
+	Stats map[string]mgrValue `json:"stats"`
+}
+
+type mgrHealth struct {
+	PGInfo struct {
+		Statuses map[string]float64 `json:"statuses"`
+	} `json:"pg_info"`
+}
+
+type mgrOSD struct {
+	ID   int64 `json:"id"`
+	Up   int64 `json:"up"`
+	In   int64 `json:"in"`
+	Tree struct {
+		DeviceClass string `json:"device_class"`
+	} `json:"tree"`
+	Host struct {
+		Name string `json:"name"`
+	} `json:"host"`
+	Stats    map[string]float64 `json:"stats"`
+	OSDStats struct {
+		PerfStat struct {
+			CommitLatencyMs float64 `json:"commit_latency_ms"`
+			ApplyLatencyMs  float64 `json:"apply_latency_ms"`
+		} `json:"perf_stat"`
+	} `json:"osd_stats"`
+}
+
+type mgrBucket struct {
+	Bucket string `json:"bucket"`
+	Owner  string `json:"owner"`
+	Usage  map[string]struct {
+		Size       float64 `json:"size"`
+		SizeActual float64 `json:"size_actual"`
+		NumObjects float64 `json:"num_objects"`
+	} `json:"usage"`
+	Quota struct {
+		Enabled    bool    `json:"enabled"`
+		MaxSize    float64 `json:"max_size"`
+		MaxObjects float64 `json:"max_objects"`
+	} `json:"bucket_quota"`
+}
+
+func (c *Ceph) gatherMgrStats(acc telegraf.Accumulator) error {
+	jobs := map[string]func(telegraf.Accumulator) error{
+		"pools":       c.gatherMgrPools,
+		"pgs":         c.gatherMgrPGs,
+		"osds":        c.gatherMgrOSDs,
+		"rgw_buckets": c.gatherMgrBuckets,
+	}
+
+	for _, name := range c.MgrInclude {
+		if err := jobs[name](acc); err != nil {
+			acc.AddError(fmt.Errorf("gathering %s from mgr failed: %w", name, err))
+		}
+	}
+
+	return nil
+}
+
+// gatherMgrPools reports the utilization and client I/O of each pool
+func (c *Ceph) gatherMgrPools(acc telegraf.Accumulator) error {
+	var pools []mgrPool
+	if err := c.mgrGet("/api/pool?stats=true", &pools); err != nil {
+		return err
+	}
+
+	for _, pool := range pools {
+		tags := map[string]string{"name": pool.Name}
+		acc.AddFields("ceph_pool_usage", map[string]interface{}{
+			"bytes_used":   pool.Stats["bytes_used"].Latest,
+			"max_avail":    pool.Stats["max_avail"].Latest,
+			"objects":      pool.Stats["objects"].Latest,
+			"percent_used": pool.Stats["percent_used"].Latest,
+			"stored":       pool.Stats["stored"].Latest,
+		}, tags)
+		acc.AddFields("ceph_pool_stats", map[string]interface{}{
+			"read_bytes_sec":   pool.Stats["rd_bytes"].Rate,
+			"read_op_per_sec":  pool.Stats["rd"].Rate,
+			"write_bytes_sec":  pool.Stats["wr_bytes"].Rate,
+			"write_op_per_sec": pool.Stats["wr"].Rate,
+		}, tags)
+	}
+	return nil
+}
+
+// gatherMgrPGs reports the number of placement groups per state
+func (c *Ceph) gatherMgrPGs(acc telegraf.Accumulator) error {
+	var health mgrHealth
+	if err := c.mgrGet("/api/health/minimal", &health); err != nil {
+		return err
+	}
+
+	for state, count := range health.PGInfo.Statuses {
+		acc.AddFields("ceph_pgmap_state", map[string]interface{}{"count": count}, map[string]string{"state": state})
+	}
+	return nil
+}
+
+// gatherMgrOSDs reports the state, utilization and latencies of each OSD
+func (c *Ceph) gatherMgrOSDs(acc telegraf.Accumulator) error {
+	var osds []mgrOSD
+	if err := c.mgrGet("/api/osd", &osds); err != nil {
+		return err
+	}
+
+	for _, osd := range osds {
+		tags := map[string]string{
+			"id":           strconv.FormatInt(osd.ID, 10),
+			"host":         osd.Host.Name,
+			"device_class": osd.Tree.DeviceClass,
+		}
+		fields := map[string]interface{}{
+			"up":                osd.Up == 1,
+			"in":                osd.In == 1,
+			"num_pgs":           osd.Stats["numpg"],
+			"bytes_total":       osd.Stats["stat_bytes"],
+			"bytes_used":        osd.Stats["stat_bytes_used"],
+			"read_op_per_sec":   osd.Stats["op_r"],
+			"write_op_per_sec":  osd.Stats["op_w"],
+			"read_bytes_sec":    osd.Stats["op_out_bytes"],
+			"write_bytes_sec":   osd.Stats["op_in_bytes"],
+			"commit_latency_ms": osd.OSDStats.PerfStat.CommitLatencyMs,
+			"apply_latency_ms":  osd.OSDStats.PerfStat.ApplyLatencyMs,
+		}
+		acc.AddFields("ceph_osd_stats", fields, tags)
+	}
+	return nil
+}
+
+// gatherMgrBuckets reports the usage and quota of each RGW bucket
+func (c *Ceph) gatherMgrBuckets(acc telegraf.Accumulator) error {
+	var buckets []mgrBucket
+	if err := c.mgrGet("/api/rgw/bucket?stats=true", &buckets); err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		tags := map[string]string{
+			"bucket": bucket.Bucket,
+			"owner":  bucket.Owner,
+		}
+
+		// Sum up the usage over all categories, e.g. the main and multipart
+		// storage of the bucket
+		var size, sizeActual, objects float64
+		for _, u := range bucket.Usage {
+			size += u.Size
+			sizeActual += u.SizeActual
+			objects += u.NumObjects
+		}
+		fields := map[string]interface{}{
+			"size_bytes":        size,
+			"size_actual_bytes": sizeActual,
+			"num_objects":       objects,
+		}
+		if bucket.Quota.Enabled {
+			fields["quota_max_size_bytes"] = bucket.Quota.MaxSize
+			fields["quota_max_objects"] = bucket.Quota.MaxObjects
+		}
+		acc.AddFields("ceph_rgw_bucket", fields, tags)
+	}
+	return nil
+}
+
+// mgrGet queries the given path of the dashboard API, requesting a new token
+// if none exists or the current one expired
+func (c *Ceph) mgrGet(path string, v interface{}) error {
+	if c.mgrToken == "" {
+		if err := c.mgrLogin(); err != nil {
+			return fmt.Errorf("login failed: %w", err)
+		}
+	}
+
+	err := c.mgrRequest(http.MethodGet, path, nil, v)
+	if !errors.Is(err, errMgrUnauthorized) {
+		return err
+	}
+
+	if err := c.mgrLogin(); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	return c.mgrRequest(http.MethodGet, path, nil, v)
+}
+
+func (c *Ceph) mgrLogin() error {
+	c.mgrToken = ""
+
+	username, err := c.MgrUsername.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := c.MgrPassword.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	body := map[string]string{
+		"username": username.String(),
+		"password": password.String(),
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := c.mgrRequest(http.MethodPost, "/api/auth", body, &resp); err != nil {
+		return err
+	}
+	c.mgrToken = resp.Token
+	return nil
+}
+
+func (c *Ceph) mgrRequest(method, path string, body, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(method, c.MgrURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", mgrAcceptHeader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.mgrToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.mgrToken)
+	}
+
+	resp, err := c.mgrClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusUnauthorized:
+		return errMgrUnauthorized
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned HTTP status %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Ceph) initMgr() error {
+	if c.MgrURL == "" {
+		return errors.New("mgr_url required")
+	}
+	c.MgrURL = strings.TrimSuffix(c.MgrURL, "/")
+	if c.MgrUsername.Empty() || c.MgrPassword.Empty() {
+		return errors.New("mgr_username and mgr_password required")
+	}
+
+	if len(c.MgrInclude) == 0 {
+		c.MgrInclude = []string{"pools", "pgs", "osds", "rgw_buckets"}
+	}
+	if err := choice.CheckSlice(c.MgrInclude, []string{"pools", "pgs", "osds", "rgw_buckets"}); err != nil {
+		return fmt.Errorf("invalid mgr_include: %w", err)
+	}
+
+	tlsCfg, err := c.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	c.mgrClient = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: time.Duration(c.Timeout),
+	}
+	return nil
+}
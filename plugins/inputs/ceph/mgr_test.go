@@ -0,0 +1,218 @@
+package ceph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var mgrResponses = map[string]string{
+	"/api/pool": `[
+  {
+    "pool_name": "rbd",
+    "pool": 1,
+    "stats": {
+      "bytes_used": {"latest": 12288, "rate": 0, "rates": []},
+      "max_avail": {"latest": 1073741824, "rate": 0, "rates": []},
+      "objects": {"latest": 3, "rate": 0, "rates": []},
+      "percent_used": {"latest": 0.5, "rate": 0, "rates": []},
+      "stored": {"latest": 4096, "rate": 0, "rates": []},
+      "rd": {"latest": 100, "rate": 2, "rates": []},
+      "rd_bytes": {"latest": 409600, "rate": 8192, "rates": []},
+      "wr": {"latest": 50, "rate": 1, "rates": []},
+      "wr_bytes": {"latest": 204800, "rate": 4096, "rates": []}
+    }
+  }
+]`,
+	"/api/health/minimal": `{
+  "health": {"status": "HEALTH_OK"},
+  "pg_info": {
+    "object_stats": {"num_objects": 3},
+    "pgs_per_osd": 33.0,
+    "statuses": {"active+clean": 32, "active+clean+scrubbing": 1}
+  }
+}`,
+	"/api/osd": `[
+  {
+    "osd": 0,
+    "id": 0,
+    "up": 1,
+    "in": 1,
+    "tree": {"device_class": "hdd", "name": "osd.0"},
+    "host": {"id": -3, "name": "node1"},
+    "stats": {"op_w": 1.5, "op_in_bytes": 6144, "op_r": 2.5, "op_out_bytes": 10240, "numpg": 33, "stat_bytes": 10737418240, "stat_bytes_used": 1073741824},
+    "osd_stats": {"perf_stat": {"commit_latency_ms": 3, "apply_latency_ms": 4}}
+  }
+]`,
+	"/api/rgw/bucket": `[
+  {
+    "bucket": "images",
+    "owner": "alice",
+    "usage": {
+      "rgw.main": {"size": 2048, "size_actual": 8192, "num_objects": 2},
+      "rgw.multimeta": {"size": 0, "size_actual": 0, "num_objects": 1}
+    },
+    "bucket_quota": {"enabled": true, "max_size": 1048576, "max_objects": -1}
+  }
+]`,
+}
+
+func newMgrServer(t *testing.T, logins *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != mgrAcceptHeader {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+
+		if r.URL.Path == "/api/auth" {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if body["username"] != "telegraf" || body["password"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			*logins++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token": "t0k3n", "username": "telegraf"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp, found := mgrResponses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(resp))
+		require.NoError(t, err)
+	}))
+}
+
+func TestMgrInitFail(t *testing.T) {
+	plugin := &Ceph{
+		GatherMgrStats: true,
+		MgrURL:         "https://127.0.0.1:8443",
+		MgrUsername:    config.NewSecret([]byte("telegraf")),
+		MgrPassword:    config.NewSecret([]byte("secret")),
+		MgrInclude:     []string{"pools", "mons"},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid mgr_include")
+
+	plugin = &Ceph{
+		GatherMgrStats: true,
+		MgrURL:         "https://127.0.0.1:8443",
+	}
+	require.ErrorContains(t, plugin.Init(), "mgr_username and mgr_password required")
+}
+
+func TestGatherMgrStats(t *testing.T) {
+	var logins int
+	server := newMgrServer(t, &logins)
+	defer server.Close()
+
+	plugin := &Ceph{
+		GatherMgrStats: true,
+		MgrURL:         server.URL,
+		MgrUsername:    config.NewSecret([]byte("telegraf")),
+		MgrPassword:    config.NewSecret([]byte("secret")),
+		Timeout:        config.Duration(5 * time.Second),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"ceph_pool_usage",
+			map[string]string{"name": "rbd"},
+			map[string]interface{}{
+				"bytes_used":   float64(12288),
+				"max_avail":    float64(1073741824),
+				"objects":      float64(3),
+				"percent_used": float64(0.5),
+				"stored":       float64(4096),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ceph_pool_stats",
+			map[string]string{"name": "rbd"},
+			map[string]interface{}{
+				"read_bytes_sec":   float64(8192),
+				"read_op_per_sec":  float64(2),
+				"write_bytes_sec":  float64(4096),
+				"write_op_per_sec": float64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ceph_pgmap_state",
+			map[string]string{"state": "active+clean"},
+			map[string]interface{}{"count": float64(32)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ceph_pgmap_state",
+			map[string]string{"state": "active+clean+scrubbing"},
+			map[string]interface{}{"count": float64(1)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ceph_osd_stats",
+			map[string]string{"id": "0", "host": "node1", "device_class": "hdd"},
+			map[string]interface{}{
+				"up":                true,
+				"in":                true,
+				"num_pgs":           float64(33),
+				"bytes_total":       float64(10737418240),
+				"bytes_used":        float64(1073741824),
+				"read_op_per_sec":   float64(2.5),
+				"write_op_per_sec":  float64(1.5),
+				"read_bytes_sec":    float64(10240),
+				"write_bytes_sec":   float64(6144),
+				"commit_latency_ms": float64(3),
+				"apply_latency_ms":  float64(4),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ceph_rgw_bucket",
+			map[string]string{"bucket": "images", "owner": "alice"},
+			map[string]interface{}{
+				"size_bytes":           float64(2048),
+				"size_actual_bytes":    float64(8192),
+				"num_objects":          float64(3),
+				"quota_max_size_bytes": float64(1048576),
+				"quota_max_objects":    float64(-1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The token is reused across gathers and renewed once expired
+	require.Equal(t, 1, logins)
+	plugin.mgrToken = "expired"
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, 2, logins)
+	require.Len(t, acc.GetTelegrafMetrics(), len(expected))
+}
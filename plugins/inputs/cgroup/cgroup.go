@@ -12,8 +12,11 @@ import (
 var sampleConfig string
 
 type CGroup struct {
-	Paths []string `toml:"paths"`
-	Files []string `toml:"files"`
+	Paths              []string `toml:"paths"`
+	Files              []string `toml:"files"`
+	DiscoverContainers bool     `toml:"discover_containers"`
+	CgroupRoot         string   `toml:"cgroup_root"`
+	ContainerTags      bool     `toml:"container_tags"`
 
 	logged map[string]bool
 }
@@ -29,5 +32,9 @@ func (cg *CGroup) Init() error {
 }
 
 func init() {
-	inputs.Add("cgroup", func() telegraf.Input { return &CGroup{} })
+	inputs.Add("cgroup", func() telegraf.Input {
+		return &CGroup{
+			CgroupRoot: "/sys/fs/cgroup",
+		}
+	})
 }
@@ -4,6 +4,8 @@ package cgroup
 
 import (
 	"fmt"
+	"io/fs"
+	"math"
 	"os"
 	"path"
 	"path/filepath"
@@ -63,6 +65,14 @@ func (g *CGroup) gatherDir(acc telegraf.Accumulator, dir string) error {
 	}
 
 	tags := map[string]string{"path": dir}
+	if g.ContainerTags {
+		if id, found := containerID(dir); found {
+			tags["container_id"] = id
+		}
+		if uid, found := podUID(dir); found {
+			tags["pod_uid"] = uid
+		}
+	}
 
 	acc.AddFields(metricName, fields, tags)
 
@@ -86,6 +96,8 @@ func isDir(pathToCheck string) (bool, error) {
 
 func (g *CGroup) generateDirs(list chan<- pathInfo) {
 	defer close(list)
+
+	seen := make(map[string]bool)
 	for _, dir := range g.Paths {
 		// getting all dirs that match the pattern 'dir'
 		items, err := filepath.Glob(dir)
@@ -102,10 +114,69 @@ func (g *CGroup) generateDirs(list chan<- pathInfo) {
 			}
 			// supply only dirs
 			if ok {
+				seen[item] = true
 				list <- pathInfo{path: item}
 			}
 		}
 	}
+
+	if g.DiscoverContainers {
+		g.discoverContainers(list, seen)
+	}
+}
+
+// discoverContainers walks the cgroup hierarchy and supplies the cgroups of
+// containers not already covered by the configured paths. The children of a
+// container cgroup are skipped.
+func (g *CGroup) discoverContainers(list chan<- pathInfo, seen map[string]bool) {
+	err := filepath.WalkDir(g.CgroupRoot, func(item string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// cgroups might vanish while walking the hierarchy
+			if os.IsNotExist(err) && item != g.CgroupRoot {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if _, found := containerID(item); !found {
+			return nil
+		}
+		if !seen[item] {
+			list <- pathInfo{path: item}
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		list <- pathInfo{err: fmt.Errorf("discovering containers in %q failed: %w", g.CgroupRoot, err)}
+	}
+}
+
+// Container cgroups are named after the container ID, optionally prefixed by
+// the runtime and suffixed by ".scope" when using the systemd cgroup driver,
+// e.g. "docker-<id>.scope" or "cri-containerd-<id>.scope".
+var containerRe = regexp.MustCompile(`^(?:(?:docker|cri-containerd|crio|libpod)-)?([0-9a-f]{64})(?:\.scope)?$`)
+
+// Kubernetes pod cgroups contain the pod UID, using underscores instead of
+// dashes when using the systemd cgroup driver, e.g.
+// "kubepods-burstable-pod<uid>.slice" or "kubepods/burstable/pod<uid>".
+var podRe = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+
+func containerID(dir string) (string, bool) {
+	matches := containerRe.FindStringSubmatch(filepath.Base(dir))
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+func podUID(dir string) (string, bool) {
+	matches := podRe.FindAllStringSubmatch(dir, -1)
+	if matches == nil {
+		return "", false
+	}
+	return strings.ReplaceAll(matches[len(matches)-1][1], "_", "-"), true
 }
 
 func (g *CGroup) generateFiles(dir string, list chan<- pathInfo) {
@@ -175,7 +246,11 @@ type fileFormat struct {
 }
 
 const keyPattern = "[[:alnum:]:_.]+"
-const valuePattern = "[\\d-]+"
+
+// Values are integers or "max" denoting no limit for cgroup v2 files. Values of
+// keyed entries might also be floating-point numbers.
+const valuePattern = "(?:[\\d-]+|max)"
+const keyedValuePattern = "(?:[\\d.-]+|max)"
 
 var fileFormats = [...]fileFormat{
 	// 	VAL\n
@@ -205,12 +280,10 @@ var fileFormats = [...]fileFormat{
 	// 	VAL0 VAL1 ...\n
 	{
 		name:    "Space separated values",
-		pattern: "^(" + valuePattern + " )+\n$",
+		pattern: "^(" + valuePattern + " )+(" + valuePattern + ")?\n$",
 		parser: func(measurement string, fields map[string]interface{}, b []byte) {
-			re := regexp.MustCompile("(" + valuePattern + ") ")
-			matches := re.FindAllStringSubmatch(string(b), -1)
-			for i, v := range matches {
-				fields[measurement+"."+strconv.Itoa(i)] = numberOrString(v[1])
+			for i, v := range strings.Fields(string(b)) {
+				fields[measurement+"."+strconv.Itoa(i)] = numberOrString(v)
 			}
 		},
 	},
@@ -229,6 +302,22 @@ var fileFormats = [...]fileFormat{
 			}
 		},
 	},
+	// 	KEY0 SUBKEY0=VAL00 SUBKEY1=VAL01 ...\n
+	// 	KEY1 SUBKEY0=VAL10 SUBKEY1=VAL11 ...\n
+	// 	...
+	{
+		name:    "Nested keyed values, separated by new line",
+		pattern: "^(" + keyPattern + "( " + keyPattern + "=" + keyedValuePattern + ")+\n)+$",
+		parser: func(measurement string, fields map[string]interface{}, b []byte) {
+			for _, line := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+				parts := strings.Fields(line)
+				for _, part := range parts[1:] {
+					k, v, _ := strings.Cut(part, "=")
+					fields[measurement+"."+parts[0]+"."+k] = numberOrString(v)
+				}
+			}
+		},
+	},
 }
 
 func numberOrString(s string) interface{} {
@@ -236,6 +325,14 @@ func numberOrString(s string) interface{} {
 	if err == nil {
 		return i
 	}
+	if s == "max" {
+		return int64(math.MaxInt64)
+	}
+	if strings.Contains(s, ".") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
 
 	return s
 }
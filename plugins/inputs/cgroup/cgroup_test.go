@@ -3,6 +3,7 @@
 package cgroup
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -442,3 +443,98 @@ func TestCgroupEscapeDir(t *testing.T) {
 	require.NoError(t, acc.GatherError(cg.Gather))
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
+
+func TestCgroupV2(t *testing.T) {
+	var acc testutil.Accumulator
+	var cg = &CGroup{
+		Paths:  []string{"testdata/v2/system.slice/docker-*.scope"},
+		Files:  []string{"cpu.max", "memory.max", "io.stat", "pids.current", "cpu.pressure"},
+		logged: make(map[string]bool),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path": "testdata/v2/system.slice/docker-3a1f6b7c9d2e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f90.scope",
+			},
+			map[string]interface{}{
+				"cpu.max.0":                int64(math.MaxInt64),
+				"cpu.max.1":                int64(100000),
+				"memory.max":               int64(math.MaxInt64),
+				"io.stat.8:0.rbytes":       int64(1048576),
+				"io.stat.8:0.wbytes":       int64(2097152),
+				"io.stat.8:0.rios":         int64(16),
+				"io.stat.8:0.wios":         int64(32),
+				"io.stat.8:0.dbytes":       int64(0),
+				"io.stat.8:0.dios":         int64(0),
+				"pids.current":             int64(12),
+				"cpu.pressure.some.avg10":  float64(0.12),
+				"cpu.pressure.some.avg60":  float64(0.05),
+				"cpu.pressure.some.avg300": float64(0.01),
+				"cpu.pressure.some.total":  int64(4242),
+				"cpu.pressure.full.avg10":  float64(0),
+				"cpu.pressure.full.avg60":  float64(0),
+				"cpu.pressure.full.avg300": float64(0),
+				"cpu.pressure.full.total":  int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	require.NoError(t, acc.GatherError(cg.Gather))
+	require.Empty(t, cg.logged)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestCgroupDiscoverContainers(t *testing.T) {
+	var acc testutil.Accumulator
+	var cg = &CGroup{
+		Paths:              []string{"testdata/v2/system.slice/*"},
+		Files:              []string{"cpu.stat"},
+		DiscoverContainers: true,
+		CgroupRoot:         "testdata/v2",
+		ContainerTags:      true,
+		logged:             make(map[string]bool),
+	}
+
+	fields := map[string]interface{}{
+		"cpu.stat.usage_usec":  int64(1000),
+		"cpu.stat.user_usec":   int64(600),
+		"cpu.stat.system_usec": int64(400),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path":         "testdata/v2/system.slice/docker-3a1f6b7c9d2e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f90.scope",
+				"container_id": "3a1f6b7c9d2e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f90",
+			},
+			fields,
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path": "testdata/v2/system.slice/sshd.service",
+			},
+			fields,
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cgroup",
+			map[string]string{
+				"path": "testdata/v2/kubepods.slice/kubepods-burstable.slice/" +
+					"kubepods-burstable-pod8f4c2e1a_5b3d_4c6e_9a7f_1b2c3d4e5f60.slice/" +
+					"cri-containerd-c0ffee00112233445566778899aabbccddeeff00112233445566778899aabbcc.scope",
+				"container_id": "c0ffee00112233445566778899aabbccddeeff00112233445566778899aabbcc",
+				"pod_uid":      "8f4c2e1a-5b3d-4c6e-9a7f-1b2c3d4e5f60",
+			},
+			fields,
+			time.Unix(0, 0),
+		),
+	}
+
+	require.NoError(t, acc.GatherError(cg.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -5,26 +5,34 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
-	"os/exec"
+	"math/bits"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
-var (
-	execCommand = exec.Command // execCommand is used to mock commands in tests.
-)
-
 type Chrony struct {
-	DNSLookup bool `toml:"dns_lookup"`
-	path      string
+	Mode      string          `toml:"mode"`
+	Server    string          `toml:"server"`
+	Timeout   config.Duration `toml:"timeout"`
+	Metrics   []string        `toml:"metrics"`
+	DNSLookup bool            `toml:"dns_lookup"`
+	Log       telegraf.Logger `toml:"-"`
+
+	network string
+	address string
 }
 
 func (*Chrony) SampleConfig() string {
@@ -32,98 +40,308 @@ func (*Chrony) SampleConfig() string {
 }
 
 func (c *Chrony) Init() error {
-	var err error
-	c.path, err = exec.LookPath("chronyc")
+	switch c.Mode {
+	case "":
+		c.Mode = "chrony"
+	case "chrony", "ntp_peer":
+	default:
+		return fmt.Errorf("invalid mode %q", c.Mode)
+	}
+
+	port := "323"
+	if c.Mode == "ntp_peer" {
+		port = "123"
+	}
+	if c.Server == "" {
+		c.Server = "udp://127.0.0.1:" + port
+	}
+	u, err := url.Parse(c.Server)
 	if err != nil {
-		return errors.New("chronyc not found: verify that chrony is installed and that chronyc is in your PATH")
+		return fmt.Errorf("parsing server address failed: %w", err)
+	}
+	switch u.Scheme {
+	case "udp":
+		c.network = "udp"
+		c.address = u.Host
+		if u.Port() == "" {
+			c.address = net.JoinHostPort(u.Hostname(), port)
+		}
+	case "unix", "unixgram":
+		if c.Mode == "ntp_peer" {
+			return errors.New("ntp_peer mode only supports udp servers")
+		}
+		c.network = "unixgram"
+		c.address = u.Path
+	default:
+		return fmt.Errorf("invalid scheme %q in server address", u.Scheme)
+	}
+
+	if len(c.Metrics) == 0 {
+		c.Metrics = []string{"tracking"}
+	}
+	if err := choice.CheckSlice(c.Metrics, []string{"tracking", "sources", "sourcestats"}); err != nil {
+		return fmt.Errorf("invalid metrics: %w", err)
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = config.Duration(5 * time.Second)
 	}
 	return nil
 }
 
 func (c *Chrony) Gather(acc telegraf.Accumulator) error {
-	flags := []string{}
-	if !c.DNSLookup {
-		flags = append(flags, "-n")
+	conn, err := c.dial()
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", c.Server, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(c.Timeout))); err != nil {
+		return err
+	}
+
+	if c.Mode == "ntp_peer" {
+		return c.gatherNTPPeers(acc, &ntpClient{conn: conn})
+	}
+
+	cl := &client{conn: conn}
+
+	for _, m := range c.Metrics {
+		var err error
+		switch m {
+		case "tracking":
+			err = c.gatherTracking(acc, cl)
+		case "sources":
+			err = c.gatherSources(acc, cl)
+		case "sourcestats":
+			err = c.gatherSourceStats(acc, cl)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying %s failed: %w", m, err))
+		}
 	}
-	flags = append(flags, "tracking")
+	return nil
+}
 
-	cmd := execCommand(c.path, flags...)
-	out, err := internal.CombinedOutputTimeout(cmd, time.Second*5)
+// dial connects to chronyd. For unix sockets chronyd sends the replies to
+// the address of the client, so we have to bind to a socket in the same
+// directory like chronyc does.
+func (c *Chrony) dial() (net.Conn, error) {
+	if c.network != "unixgram" {
+		return net.Dial(c.network, c.address)
+	}
+
+	local := filepath.Join(filepath.Dir(c.address), fmt.Sprintf("chronyc-telegraf.%d.sock", os.Getpid()))
+	_ = os.Remove(local)
+	laddr := &net.UnixAddr{Name: local, Net: "unixgram"}
+	raddr := &net.UnixAddr{Name: c.address, Net: "unixgram"}
+	conn, err := net.DialUnix("unixgram", laddr, raddr)
 	if err != nil {
-		return fmt.Errorf("failed to run command %q: %w - %s", strings.Join(cmd.Args, " "), err, string(out))
+		return nil, err
+	}
+	// Allow chronyd to send replies
+	if err := os.Chmod(local, 0666); err != nil {
+		conn.Close()
+		os.Remove(local)
+		return nil, err
+	}
+	return &unixConn{UnixConn: conn, path: local}, nil
+}
+
+// unixConn removes the socket file of the client when closing the connection
+type unixConn struct {
+	*net.UnixConn
+	path string
+}
+
+func (c *unixConn) Close() error {
+	err := c.UnixConn.Close()
+	if rerr := os.Remove(c.path); rerr != nil && err == nil {
+		err = rerr
 	}
-	fields, tags, err := processChronycOutput(string(out))
+	return err
+}
+
+func (c *Chrony) gatherTracking(acc telegraf.Accumulator, cl *client) error {
+	report, err := cl.tracking()
 	if err != nil {
 		return err
 	}
+
+	tags := map[string]string{
+		"reference_id": fmt.Sprintf("%08X", report.refID),
+		"stratum":      strconv.FormatUint(uint64(report.stratum), 10),
+	}
+	if int(report.leapStatus) < len(leapStatus) {
+		tags["leap_status"] = leapStatus[report.leapStatus]
+	}
+	fields := map[string]interface{}{
+		"system_time":     -report.currentCorrection,
+		"last_offset":     report.lastOffset,
+		"rms_offset":      report.rmsOffset,
+		"frequency":       report.freqPPM,
+		"residual_freq":   report.residFreqPPM,
+		"skew":            report.skewPPM,
+		"root_delay":      report.rootDelay,
+		"root_dispersion": report.rootDispersion,
+		"update_interval": report.lastUpdateInterval,
+	}
 	acc.AddFields("chrony", fields, tags)
 	return nil
 }
 
-// processChronycOutput takes in a string output from the chronyc command, like:
-//
-//	Reference ID    : 192.168.1.22 (ntp.example.com)
-//	Stratum         : 3
-//	Ref time (UTC)  : Thu May 12 14:27:07 2016
-//	System time     : 0.000020390 seconds fast of NTP time
-//	Last offset     : +0.000012651 seconds
-//	RMS offset      : 0.000025577 seconds
-//	Frequency       : 16.001 ppm slow
-//	Residual freq   : -0.000 ppm
-//	Skew            : 0.006 ppm
-//	Root delay      : 0.001655 seconds
-//	Root dispersion : 0.003307 seconds
-//	Update interval : 507.2 seconds
-//	Leap status     : Normal
-//
-// The value on the left side of the colon is used as field name, if the first field on
-// the right side is a float. If it cannot be parsed as float, it is a tag name.
-//
-// Ref time is ignored and all names are converted to snake case.
-//
-// It returns (<fields>, <tags>)
-func processChronycOutput(out string) (map[string]interface{}, map[string]string, error) {
-	tags := map[string]string{}
-	fields := map[string]interface{}{}
-	lines := strings.Split(strings.TrimSpace(out), "\n")
-	for _, line := range lines {
-		stats := strings.Split(line, ":")
-		if len(stats) < 2 {
-			return nil, nil, fmt.Errorf("unexpected output from chronyc, expected ':' in %s", out)
-		}
-		name := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(stats[0]), " ", "_"))
-		// ignore reference time
-		if strings.Contains(name, "ref_time") {
-			continue
+func (c *Chrony) gatherSources(acc telegraf.Accumulator, cl *client) error {
+	n, err := cl.numSources()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		src, err := cl.sourceData(i)
+		if err != nil {
+			return fmt.Errorf("source %d: %w", i, err)
 		}
-		valueFields := strings.Fields(stats[1])
-		if len(valueFields) == 0 {
-			return nil, nil, fmt.Errorf("unexpected output from chronyc: %s", out)
+
+		tags := map[string]string{
+			"source": c.lookup(src.address, src.mode != sourceModeRef),
 		}
-		if strings.Contains(strings.ToLower(name), "stratum") {
-			tags["stratum"] = valueFields[0]
-			continue
+		if int(src.mode) < len(sourceModes) {
+			tags["mode"] = sourceModes[src.mode]
 		}
-		if strings.Contains(strings.ToLower(name), "reference_id") {
-			tags["reference_id"] = valueFields[0]
-			continue
+		if int(src.state) < len(sourceStates) {
+			tags["state"] = sourceStates[src.state]
+		}
+		fields := map[string]interface{}{
+			"index":              i,
+			"poll":               int64(src.poll),
+			"stratum":            int64(src.stratum),
+			"flags":              int64(src.flags),
+			"reachability":       int64(src.reachability),
+			"reachability_count": int64(bits.OnesCount16(src.reachability)),
+			"adjusted_offset":    src.latestMeas,
+			"measured_offset":    src.origLatestMeas,
+			"offset_error":       src.latestMeasErr,
+		}
+		// The time since the last sample is unset if there is none yet
+		if src.sinceSample != 0xffffffff {
+			fields["since_sample"] = int64(src.sinceSample)
+		}
+		acc.AddFields("chrony_sources", fields, tags)
+	}
+	return nil
+}
+
+func (c *Chrony) gatherSourceStats(acc telegraf.Accumulator, cl *client) error {
+	n, err := cl.numSources()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		stats, err := cl.sourceStats(i)
+		if err != nil {
+			return fmt.Errorf("source %d: %w", i, err)
+		}
+
+		tags := map[string]string{
+			"source":       c.lookup(stats.address, net.ParseIP(stats.address) != nil),
+			"reference_id": fmt.Sprintf("%08X", stats.refID),
+		}
+		fields := map[string]interface{}{
+			"index":         i,
+			"samples":       int64(stats.samples),
+			"runs":          int64(stats.runs),
+			"span":          int64(stats.spanSeconds),
+			"std_dev":       stats.stdDev,
+			"residual_freq": stats.residFreqPPM,
+			"skew":          stats.skewPPM,
+			"offset":        stats.estOffset,
+			"offset_error":  stats.estOffsetErr,
 		}
-		value, err := strconv.ParseFloat(valueFields[0], 64)
+		acc.AddFields("chrony_sourcestats", fields, tags)
+	}
+	return nil
+}
+
+func (c *Chrony) gatherNTPPeers(acc telegraf.Accumulator, cl *ntpClient) error {
+	assocs, err := cl.associations()
+	if err != nil {
+		return fmt.Errorf("querying associations failed: %w", err)
+	}
+
+	for _, assoc := range assocs {
+		vars, err := cl.peerVariables(assoc.id)
 		if err != nil {
-			tags[name] = strings.ToLower(strings.Join(valueFields, " "))
+			acc.AddError(fmt.Errorf("querying association %d failed: %w", assoc.id, err))
 			continue
 		}
-		if strings.Contains(stats[1], "slow") {
-			value = -value
+
+		remote := vars["srcadr"]
+		tags := map[string]string{
+			"remote": c.lookup(remote, net.ParseIP(remote) != nil),
+			"refid":  vars["refid"],
+			"state":  ntpPeerSelections[(assoc.status>>8)&0x07],
+		}
+		if v, err := strconv.ParseUint(vars["hmode"], 10, 8); err == nil && v < uint64(len(ntpModes)) && ntpModes[v] != "" {
+			tags["mode"] = ntpModes[v]
+		}
+		if leap, err := parseNTPLeap(vars["leap"]); err == nil && int(leap) < len(leapStatus) {
+			tags["leap_status"] = leapStatus[leap]
+		}
+
+		fields := map[string]interface{}{
+			"association_id": int64(assoc.id),
+		}
+		for name, field := range map[string]string{"stratum": "stratum", "hpoll": "poll", "ppoll": "peer_poll"} {
+			if v, err := strconv.ParseInt(vars[name], 10, 64); err == nil {
+				fields[field] = v
+			}
+		}
+		if v, err := strconv.ParseUint(vars["reach"], 0, 8); err == nil {
+			fields["reachability"] = int64(v)
+			fields["reachability_count"] = int64(bits.OnesCount8(uint8(v)))
 		}
-		fields[name] = value
+		// ntpd reports all times in milliseconds
+		for name, field := range map[string]string{
+			"delay":      "delay",
+			"offset":     "offset",
+			"jitter":     "jitter",
+			"dispersion": "dispersion",
+			"rootdelay":  "root_delay",
+			"rootdisp":   "root_dispersion",
+		} {
+			if v, err := strconv.ParseFloat(vars[name], 64); err == nil {
+				fields[field] = v / 1000
+			}
+		}
+		acc.AddFields("ntp_peer", fields, tags)
 	}
+	return nil
+}
 
-	return fields, tags, nil
+// parseNTPLeap parses the leap indicator reported by ntpd either as number or
+// as two binary digits
+func parseNTPLeap(s string) (uint64, error) {
+	if len(s) == 2 && strings.Trim(s, "01") == "" {
+		return strconv.ParseUint(s, 2, 8)
+	}
+	return strconv.ParseUint(s, 10, 8)
+}
+
+// lookup resolves the name of the source address if DNS lookups are enabled
+func (c *Chrony) lookup(address string, isIP bool) string {
+	if !c.DNSLookup || !isIP {
+		return address
+	}
+	names, err := net.LookupAddr(address)
+	if err != nil || len(names) == 0 {
+		c.Log.Debugf("Looking up name of %q failed: %v", address, err)
+		return address
+	}
+	return strings.TrimSuffix(names[0], ".")
 }
 
 func init() {
 	inputs.Add("chrony", func() telegraf.Input {
-		return &Chrony{}
+		return &Chrony{Timeout: config.Duration(5 * time.Second)}
 	})
 }
@@ -1,112 +1,471 @@
 package chrony
 
 import (
-	"fmt"
-	"os"
-	"os/exec"
+	"encoding/binary"
+	"math"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 )
 
-func TestGather(t *testing.T) {
-	c := Chrony{
-		path: "chronyc",
+type fakeSource struct {
+	refID        uint32
+	ip           net.IP
+	family       uint16
+	mode         uint16
+	state        uint16
+	reachability uint16
+	sinceSample  uint32
+}
+
+var fakeSources = []fakeSource{
+	{
+		refID:        0xc0a80116,
+		ip:           net.IPv4(192, 168, 1, 22).To4(),
+		family:       ipAddrInet4,
+		mode:         0,
+		state:        0,
+		reachability: 0o377,
+		sinceSample:  32,
+	},
+	{
+		refID:        0x47505300,
+		ip:           net.IP{0x47, 0x50, 0x53, 0x00},
+		family:       ipAddrInet4,
+		mode:         sourceModeRef,
+		state:        4,
+		reachability: 0o17,
+		sinceSample:  0xffffffff,
+	},
+	{
+		refID:        0x1f2e3d4c,
+		ip:           net.ParseIP("2001:db8::1"),
+		family:       ipAddrInet6,
+		mode:         1,
+		state:        2,
+		reachability: 0,
+		sinceSample:  1024,
+	},
+}
+
+// fakeChronyd answers the monitoring requests of the plugin like chronyd
+type fakeChronyd struct {
+	conn   net.PacketConn
+	status map[uint16]uint16
+}
+
+func (f *fakeChronyd) serve() {
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := f.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := buf[:n]
+		// Drop requests too short for the replies like chronyd
+		if n < requestLength || req[0] != protocolVersion || req[1] != pktTypeCmdRequest {
+			continue
+		}
+		command := binary.BigEndian.Uint16(req[4:6])
+		index := binary.BigEndian.Uint32(req[20:24])
+
+		var code uint16
+		var data []byte
+		switch command {
+		case reqTracking:
+			code = rpyTracking
+			data = binary.BigEndian.AppendUint32(nil, 0xc0a80116)
+			data = append(data, ipAddr(net.IPv4(192, 168, 1, 22).To4(), ipAddrInet4)...)
+			data = binary.BigEndian.AppendUint16(data, 3)
+			data = binary.BigEndian.AppendUint16(data, 3)
+			data = append(data, make([]byte, 12)...)
+			for _, v := range []float64{-0.000020390, 0.000012651, 0.000025577, -16.001, -0.0001, 0.006, 0.001655, 0.003307, 507.2} {
+				data = binary.BigEndian.AppendUint32(data, encodeFloat(v))
+			}
+		case reqNSources:
+			code = rpyNSources
+			data = binary.BigEndian.AppendUint32(nil, uint32(len(fakeSources)))
+		case reqSourceData:
+			code = rpySourceData
+			s := fakeSources[index]
+			data = ipAddr(s.ip, s.family)
+			data = binary.BigEndian.AppendUint16(data, 6)
+			data = binary.BigEndian.AppendUint16(data, uint16(index+1))
+			data = binary.BigEndian.AppendUint16(data, s.state)
+			data = binary.BigEndian.AppendUint16(data, s.mode)
+			data = binary.BigEndian.AppendUint16(data, 0)
+			data = binary.BigEndian.AppendUint16(data, s.reachability)
+			data = binary.BigEndian.AppendUint32(data, s.sinceSample)
+			for _, v := range []float64{0.000125, 0.0001, 0.00002} {
+				data = binary.BigEndian.AppendUint32(data, encodeFloat(v))
+			}
+		case reqSourceStats:
+			code = rpySourceStats
+			s := fakeSources[index]
+			data = binary.BigEndian.AppendUint32(nil, s.refID)
+			if s.mode == sourceModeRef {
+				data = append(data, ipAddr(nil, ipAddrUnspec)...)
+			} else {
+				data = append(data, ipAddr(s.ip, s.family)...)
+			}
+			data = binary.BigEndian.AppendUint32(data, 8)
+			data = binary.BigEndian.AppendUint32(data, 5)
+			data = binary.BigEndian.AppendUint32(data, 3600)
+			for _, v := range []float64{0.00005, -0.002, 0.03, 0.0000125, 0.00001} {
+				data = binary.BigEndian.AppendUint32(data, encodeFloat(v))
+			}
+		default:
+			continue
+		}
+		data = binary.BigEndian.AppendUint32(data, 0)
+
+		rsp := make([]byte, replyHeaderLength)
+		rsp[0] = protocolVersion
+		rsp[1] = pktTypeCmdReply
+		binary.BigEndian.PutUint16(rsp[4:6], command)
+		binary.BigEndian.PutUint16(rsp[6:8], code)
+		binary.BigEndian.PutUint16(rsp[8:10], f.status[command])
+		copy(rsp[16:20], req[8:12])
+		_, _ = f.conn.WriteTo(append(rsp, data...), addr)
 	}
-	// overwriting exec commands with mock commands
-	execCommand = fakeExecCommand
-	defer func() { execCommand = exec.Command }()
-	var acc testutil.Accumulator
+}
+
+func ipAddr(ip net.IP, family uint16) []byte {
+	data := make([]byte, 20)
+	copy(data, ip)
+	binary.BigEndian.PutUint16(data[16:18], family)
+	return data
+}
+
+// encodeFloat is the inverse of decodeFloat using the smallest exponent
+// possible to keep the precision
+func encodeFloat(v float64) uint32 {
+	if v == 0 {
+		return 0
+	}
+	exp := -89
+	coef := math.Round(v / math.Pow(2, float64(exp)))
+	for math.Abs(coef) >= 1<<24 {
+		exp++
+		coef = math.Round(v / math.Pow(2, float64(exp)))
+	}
+	return uint32(int32(exp+25)&0x7f)<<25 | uint32(int32(coef))&0x1ffffff
+}
+
+func TestGather(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer udp.Close()
+	go (&fakeChronyd{conn: udp}).serve()
+
+	path := filepath.Join(t.TempDir(), "chronyd.sock")
+	unix, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err)
+	defer unix.Close()
+	go (&fakeChronyd{conn: unix}).serve()
 
-	err := c.Gather(&acc)
-	if err != nil {
-		t.Fatal(err)
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"chrony",
+			map[string]string{
+				"reference_id": "C0A80116",
+				"leap_status":  "not synchronised",
+				"stratum":      "3",
+			},
+			map[string]interface{}{
+				"system_time":     0.000020390,
+				"last_offset":     0.000012651,
+				"rms_offset":      0.000025577,
+				"frequency":       -16.001,
+				"residual_freq":   -0.0001,
+				"skew":            0.006,
+				"root_delay":      0.001655,
+				"root_dispersion": 0.003307,
+				"update_interval": 507.2,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"chrony_sources",
+			map[string]string{"source": "192.168.1.22", "mode": "client", "state": "selected"},
+			map[string]interface{}{
+				"index":              0,
+				"poll":               int64(6),
+				"stratum":            int64(1),
+				"flags":              int64(0),
+				"reachability":       int64(255),
+				"reachability_count": int64(8),
+				"since_sample":       int64(32),
+				"adjusted_offset":    0.0001,
+				"measured_offset":    0.000125,
+				"offset_error":       0.00002,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"chrony_sources",
+			map[string]string{"source": "GPS", "mode": "reference_clock", "state": "unselected"},
+			map[string]interface{}{
+				"index":              1,
+				"poll":               int64(6),
+				"stratum":            int64(2),
+				"flags":              int64(0),
+				"reachability":       int64(15),
+				"reachability_count": int64(4),
+				"adjusted_offset":    0.0001,
+				"measured_offset":    0.000125,
+				"offset_error":       0.00002,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"chrony_sources",
+			map[string]string{"source": "2001:db8::1", "mode": "peer", "state": "falseticker"},
+			map[string]interface{}{
+				"index":              2,
+				"poll":               int64(6),
+				"stratum":            int64(3),
+				"flags":              int64(0),
+				"reachability":       int64(0),
+				"reachability_count": int64(0),
+				"since_sample":       int64(1024),
+				"adjusted_offset":    0.0001,
+				"measured_offset":    0.000125,
+				"offset_error":       0.00002,
+			},
+			time.Unix(0, 0),
+		),
 	}
+	for i, src := range []struct {
+		address string
+		ref     string
+	}{
+		{"192.168.1.22", "C0A80116"},
+		{"GPS", "47505300"},
+		{"2001:db8::1", "1F2E3D4C"},
+	} {
+		expected = append(expected, testutil.MustMetric(
+			"chrony_sourcestats",
+			map[string]string{"source": src.address, "reference_id": src.ref},
+			map[string]interface{}{
+				"index":         i,
+				"samples":       int64(8),
+				"runs":          int64(5),
+				"span":          int64(3600),
+				"std_dev":       0.00005,
+				"residual_freq": -0.002,
+				"skew":          0.03,
+				"offset":        0.0000125,
+				"offset_error":  0.00001,
+			},
+			time.Unix(0, 0),
+		))
+	}
+
+	for _, server := range []string{"udp://" + udp.LocalAddr().String(), "unixgram://" + path} {
+		t.Run(server, func(t *testing.T) {
+			plugin := &Chrony{
+				Server:  server,
+				Metrics: []string{"tracking", "sources", "sourcestats"},
+				Log:     testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
 
-	tags := map[string]string{
-		"reference_id": "192.168.1.22",
-		"leap_status":  "not synchronized",
-		"stratum":      "3",
+			options := []cmp.Option{testutil.IgnoreTime(), cmpopts.EquateApprox(1e-6, 0)}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+		})
 	}
-	fields := map[string]interface{}{
-		"system_time":     0.000020390,
-		"last_offset":     0.000012651,
-		"rms_offset":      0.000025577,
-		"frequency":       -16.001,
-		"residual_freq":   0.0,
-		"skew":            0.006,
-		"root_delay":      0.001655,
-		"root_dispersion": 0.003307,
-		"update_interval": 507.2,
+}
+
+func TestGatherStatusError(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer udp.Close()
+	go (&fakeChronyd{conn: udp, status: map[uint16]uint16{reqNSources: 2}}).serve()
+
+	plugin := &Chrony{
+		Server:  "udp://" + udp.LocalAddr().String(),
+		Metrics: []string{"tracking", "sources"},
+		Log:     testutil.Logger{},
 	}
+	require.NoError(t, plugin.Init())
 
-	// tests on linux with go1.20 will add a warning about code coverage
-	// due to the code coverage dir not being set
-	delete(acc.Metrics[0].Tags, "warning")
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "querying sources failed: chronyd returned status 2")
+	require.True(t, acc.HasMeasurement("chrony"))
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &Chrony{Server: "tcp://127.0.0.1:323"}
+	require.ErrorContains(t, plugin.Init(), "invalid scheme")
 
-	acc.AssertContainsTaggedFields(t, "chrony", fields, tags)
+	plugin = &Chrony{Metrics: []string{"tracking", "activity"}}
+	require.ErrorContains(t, plugin.Init(), "invalid metrics")
 
-	// test with dns lookup
-	c.DNSLookup = true
-	err = c.Gather(&acc)
-	if err != nil {
-		t.Fatal(err)
+	plugin = &Chrony{Mode: "ntpq"}
+	require.ErrorContains(t, plugin.Init(), "invalid mode")
+
+	plugin = &Chrony{Mode: "ntp_peer", Server: "unixgram:///run/ntpd.sock"}
+	require.ErrorContains(t, plugin.Init(), "only supports udp servers")
+}
+
+func TestDecodeFloat(t *testing.T) {
+	for _, v := range []float64{0, 1, -1, 0.5, 507.2, -16.001, 0.000020390, 1e-9, 123456.789} {
+		require.InDelta(t, v, decodeFloat(binary.BigEndian.AppendUint32(nil, encodeFloat(v))), math.Abs(v)*1e-6)
 	}
-	acc.AssertContainsTaggedFields(t, "chrony", fields, tags)
+
+	// Values as encoded by chronyd
+	require.InDelta(t, 1.0, decodeFloat([]byte{0x04, 0x80, 0x00, 0x00}), 0)
+	require.InDelta(t, -2.0, decodeFloat([]byte{0x07, 0x80, 0x00, 0x00}), 0)
 }
 
-// fakeExecCommand is a helper function that mock
-// the exec.Command call (and call the test binary)
-func fakeExecCommand(command string, args ...string) *exec.Cmd {
-	cs := []string{"-test.run=TestHelperProcess", "--", command}
-	cs = append(cs, args...)
-	cmd := exec.Command(os.Args[0], cs...)
-	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
-	return cmd
+// fakeNTPd answers the control messages of the plugin like ntpd
+type fakeNTPd struct {
+	conn  net.PacketConn
+	peers map[uint16]string
 }
 
-// TestHelperProcess isn't a real test. It's used to mock exec.Command
-// For example, if you run:
-// GO_WANT_HELPER_PROCESS=1 go test -test.run=TestHelperProcess -- chrony tracking
-// it returns below mockData.
-func TestHelperProcess(_ *testing.T) {
-	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
-		return
+func (f *fakeNTPd) serve() {
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := f.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := buf[:n]
+		if n < ntpHeaderLength || req[0] != ntpControlHeader {
+			continue
+		}
+		opcode := req[1] & 0x1f
+		assoc := binary.BigEndian.Uint16(req[6:8])
+
+		var data []byte
+		switch opcode {
+		case ntpOpReadStatus:
+			// Selected system peer and a falseticker
+			data = binary.BigEndian.AppendUint16(nil, 1001)
+			data = binary.BigEndian.AppendUint16(data, 0x961a)
+			data = binary.BigEndian.AppendUint16(data, 1002)
+			data = binary.BigEndian.AppendUint16(data, 0x9114)
+		case ntpOpReadVariables:
+			data = []byte(f.peers[assoc])
+		default:
+			continue
+		}
+
+		// Split the data into fragments to check reassembly
+		for offset := 0; offset == 0 || offset < len(data); offset += 64 {
+			count := min(64, len(data)-offset)
+			rsp := make([]byte, ntpHeaderLength)
+			rsp[0] = ntpControlHeader
+			rsp[1] = ntpFlagResponse | opcode
+			if offset+count < len(data) {
+				rsp[1] |= ntpFlagMore
+			}
+			copy(rsp[2:4], req[2:4])
+			copy(rsp[6:8], req[6:8])
+			binary.BigEndian.PutUint16(rsp[8:10], uint16(offset))
+			binary.BigEndian.PutUint16(rsp[10:12], uint16(count))
+			_, _ = f.conn.WriteTo(append(rsp, data[offset:offset+count]...), addr)
+		}
 	}
+}
 
-	lookup := "Reference ID    : 192.168.1.22 (ntp.example.com)\n"
-	noLookup := "Reference ID    : 192.168.1.22 (192.168.1.22)\n"
-	mockData := `Stratum         : 3
-Ref time (UTC)  : Thu May 12 14:27:07 2016
-System time     : 0.000020390 seconds fast of NTP time
-Last offset     : +0.000012651 seconds
-RMS offset      : 0.000025577 seconds
-Frequency       : 16.001 ppm slow
-Residual freq   : -0.000 ppm
-Skew            : 0.006 ppm
-Root delay      : 0.001655 seconds
-Root dispersion : 0.003307 seconds
-Update interval : 507.2 seconds
-Leap status     : Not synchronized
-`
-
-	args := os.Args
-
-	// Previous arguments are tests stuff, that looks like :
-	// /tmp/go-build970079519/…/_test/integration.test -test.run=TestHelperProcess --
-	cmd, args := args[3], args[4:]
-
-	if cmd != "chronyc" {
-		fmt.Fprint(os.Stdout, "command not found")
-		//nolint:revive // error code is important for this "test"
-		os.Exit(1)
+func TestGatherNTPPeer(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer udp.Close()
+	go (&fakeNTPd{
+		conn: udp,
+		peers: map[uint16]string{
+			1001: "srcadr=192.168.1.22, srcport=123, dstadr=192.168.1.10, leap=00, stratum=2, precision=-23,\r\n" +
+				"rootdelay=1.250, rootdisp=3.500, refid=\"GPS\", reach=0xff, hmode=3, pmode=4, hpoll=6, ppoll=6,\r\n" +
+				"delay=0.655, offset=-0.125, jitter=0.032, dispersion=1.000",
+			1002: "srcadr=2001:db8::1, leap=11, stratum=16, refid=INIT, reach=0x0, hmode=1, hpoll=10, ppoll=10",
+		},
+	}).serve()
+
+	plugin := &Chrony{
+		Mode:   "ntp_peer",
+		Server: "udp://" + udp.LocalAddr().String(),
+		Log:    testutil.Logger{},
 	}
-	if args[0] == "tracking" {
-		fmt.Fprint(os.Stdout, lookup+mockData)
-	} else {
-		fmt.Fprint(os.Stdout, noLookup+mockData)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"ntp_peer",
+			map[string]string{
+				"remote":      "192.168.1.22",
+				"refid":       "GPS",
+				"state":       "sys_peer",
+				"mode":        "client",
+				"leap_status": "normal",
+			},
+			map[string]interface{}{
+				"association_id":     int64(1001),
+				"stratum":            int64(2),
+				"poll":               int64(6),
+				"peer_poll":          int64(6),
+				"reachability":       int64(255),
+				"reachability_count": int64(8),
+				"delay":              0.000655,
+				"offset":             -0.000125,
+				"jitter":             0.000032,
+				"dispersion":         0.001,
+				"root_delay":         0.00125,
+				"root_dispersion":    0.0035,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ntp_peer",
+			map[string]string{
+				"remote":      "2001:db8::1",
+				"refid":       "INIT",
+				"state":       "falseticker",
+				"mode":        "symmetric_active",
+				"leap_status": "not synchronised",
+			},
+			map[string]interface{}{
+				"association_id":     int64(1002),
+				"stratum":            int64(16),
+				"poll":               int64(10),
+				"peer_poll":          int64(10),
+				"reachability":       int64(0),
+				"reachability_count": int64(0),
+			},
+			time.Unix(0, 0),
+		),
 	}
+	options := []cmp.Option{testutil.IgnoreTime(), cmpopts.EquateApprox(1e-9, 0)}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
 
-	//nolint:revive // error code is important for this "test"
-	os.Exit(0)
+func TestParseNTPVariables(t *testing.T) {
+	vars := parseNTPVariables("version=\"ntpd 4.2.8p15@1.3728-o\", processor=\"x86_64\",\r\nsystem=\"Linux/6.1, x86\", leap=00")
+	require.Equal(t, map[string]string{
+		"version":   "ntpd 4.2.8p15@1.3728-o",
+		"processor": "x86_64",
+		"system":    "Linux/6.1, x86",
+		"leap":      "00",
+	}, vars)
 }
@@ -0,0 +1,140 @@
+package chrony
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// NTP control messages (mode 6) as used by ntpq, see RFC 1305 appendix B
+const (
+	ntpControlHeader = 0x16 // LI 0, version 2, mode 6
+
+	ntpOpReadStatus    = 1
+	ntpOpReadVariables = 2
+
+	ntpFlagResponse = 0x80
+	ntpFlagError    = 0x40
+	ntpFlagMore     = 0x20
+
+	ntpHeaderLength = 12
+	ntpMaxData      = 65535
+)
+
+var ntpModes = []string{"", "symmetric_active", "symmetric_passive", "client", "server", "broadcast"}
+
+var ntpPeerSelections = []string{"reject", "falseticker", "excess", "outlier", "candidate", "backup", "sys_peer", "pps_peer"}
+
+type ntpAssociation struct {
+	id     uint16
+	status uint16
+}
+
+// ntpClient exchanges control messages with ntpd over the given connection
+type ntpClient struct {
+	conn net.Conn
+	seq  uint16
+}
+
+func (c *ntpClient) request(opcode uint8, assoc uint16) ([]byte, error) {
+	c.seq++
+
+	req := make([]byte, ntpHeaderLength)
+	req[0] = ntpControlHeader
+	req[1] = opcode
+	binary.BigEndian.PutUint16(req[2:4], c.seq)
+	binary.BigEndian.PutUint16(req[6:8], assoc)
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	// Replies might be split into multiple fragments, so reassemble the data
+	// using the offset of the fragments until the last one arrived
+	var data []byte
+	var received, total int
+	last := false
+	buf := make([]byte, 2048)
+	for !last || received < total {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		rsp := buf[:n]
+		if len(rsp) < ntpHeaderLength || rsp[0]&0x07 != 6 || rsp[1]&ntpFlagResponse == 0 ||
+			rsp[1]&0x1f != opcode || binary.BigEndian.Uint16(rsp[2:4]) != c.seq {
+			// Ignore invalid or stale replies
+			continue
+		}
+		if rsp[1]&ntpFlagError != 0 {
+			return nil, fmt.Errorf("ntpd returned error %d", rsp[4])
+		}
+		offset := int(binary.BigEndian.Uint16(rsp[8:10]))
+		count := int(binary.BigEndian.Uint16(rsp[10:12]))
+		if ntpHeaderLength+count > len(rsp) || offset+count > ntpMaxData {
+			return nil, errors.New("invalid fragment")
+		}
+		if len(data) < offset+count {
+			data = append(data, make([]byte, offset+count-len(data))...)
+		}
+		copy(data[offset:], rsp[ntpHeaderLength:ntpHeaderLength+count])
+		received += count
+		if rsp[1]&ntpFlagMore == 0 {
+			last = true
+			total = offset + count
+		}
+	}
+	return data, nil
+}
+
+func (c *ntpClient) associations() ([]ntpAssociation, error) {
+	data, err := c.request(ntpOpReadStatus, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	assocs := make([]ntpAssociation, 0, len(data)/4)
+	for i := 0; i+4 <= len(data); i += 4 {
+		assocs = append(assocs, ntpAssociation{
+			id:     binary.BigEndian.Uint16(data[i : i+2]),
+			status: binary.BigEndian.Uint16(data[i+2 : i+4]),
+		})
+	}
+	return assocs, nil
+}
+
+func (c *ntpClient) peerVariables(assoc uint16) (map[string]string, error) {
+	data, err := c.request(ntpOpReadVariables, assoc)
+	if err != nil {
+		return nil, err
+	}
+	return parseNTPVariables(string(data)), nil
+}
+
+// parseNTPVariables splits a list of variables like
+//
+//	srcadr=192.168.1.22, srcport=123, refid="GPS", stratum=1
+//
+// into a map of names and values with quotes removed
+func parseNTPVariables(s string) map[string]string {
+	vars := make(map[string]string)
+	var quoted bool
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			if s[i] == '"' {
+				quoted = !quoted
+			}
+			if s[i] != ',' || quoted {
+				continue
+			}
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(s[start:i]), "=")
+		if name != "" {
+			vars[name] = strings.Trim(value, `"`)
+		}
+		start = i + 1
+	}
+	return vars
+}
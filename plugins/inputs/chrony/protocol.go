@@ -0,0 +1,281 @@
+package chrony
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+)
+
+// Command and monitoring protocol of chronyd, see candm.h of the chrony
+// sources
+const (
+	protocolVersion   = 6
+	pktTypeCmdRequest = 1
+	pktTypeCmdReply   = 2
+
+	reqNSources    = 14
+	reqSourceData  = 15
+	reqTracking    = 33
+	reqSourceStats = 34
+
+	rpyNSources    = 2
+	rpySourceData  = 3
+	rpyTracking    = 5
+	rpySourceStats = 6
+
+	sttSuccess = 0
+
+	requestHeaderLength = 20
+	replyHeaderLength   = 28
+
+	// Requests have to be at least as long as the replies to prevent
+	// amplification attacks, so pad all requests to the maximum data length
+	requestLength = requestHeaderLength + 396
+
+	ipAddrUnspec = 0
+	ipAddrInet4  = 1
+	ipAddrInet6  = 2
+	ipAddrID     = 3
+
+	sourceModeRef = 2
+)
+
+var leapStatus = []string{"normal", "insert second", "delete second", "not synchronised"}
+
+var sourceStates = []string{"selected", "nonselectable", "falseticker", "jittery", "unselected", "selectable"}
+
+var sourceModes = []string{"client", "peer", "reference_clock"}
+
+type trackingReport struct {
+	refID              uint32
+	stratum            uint16
+	leapStatus         uint16
+	currentCorrection  float64
+	lastOffset         float64
+	rmsOffset          float64
+	freqPPM            float64
+	residFreqPPM       float64
+	skewPPM            float64
+	rootDelay          float64
+	rootDispersion     float64
+	lastUpdateInterval float64
+}
+
+type sourceData struct {
+	address        string
+	poll           int16
+	stratum        uint16
+	state          uint16
+	mode           uint16
+	flags          uint16
+	reachability   uint16
+	sinceSample    uint32
+	origLatestMeas float64
+	latestMeas     float64
+	latestMeasErr  float64
+}
+
+type sourceStats struct {
+	refID        uint32
+	address      string
+	samples      uint32
+	runs         uint32
+	spanSeconds  uint32
+	stdDev       float64
+	residFreqPPM float64
+	skewPPM      float64
+	estOffset    float64
+	estOffsetErr float64
+}
+
+// client exchanges commands with chronyd over the given connection
+type client struct {
+	conn net.Conn
+	seq  uint32
+}
+
+func (c *client) request(command uint16, data []byte, reply uint16) ([]byte, error) {
+	if c.seq == 0 {
+		var buf [4]byte
+		if _, err := rand.Read(buf[:]); err != nil {
+			return nil, err
+		}
+		c.seq = binary.BigEndian.Uint32(buf[:])
+	}
+	c.seq++
+
+	req := make([]byte, requestLength)
+	req[0] = protocolVersion
+	req[1] = pktTypeCmdRequest
+	binary.BigEndian.PutUint16(req[4:6], command)
+	binary.BigEndian.PutUint32(req[8:12], c.seq)
+	copy(req[requestHeaderLength:], data)
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1024)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		rsp := buf[:n]
+		if len(rsp) < replyHeaderLength || rsp[1] != pktTypeCmdReply || binary.BigEndian.Uint32(rsp[16:20]) != c.seq {
+			// Ignore invalid or stale replies
+			continue
+		}
+		if rsp[0] != protocolVersion {
+			return nil, fmt.Errorf("unsupported protocol version %d", rsp[0])
+		}
+		if status := binary.BigEndian.Uint16(rsp[8:10]); status != sttSuccess {
+			return nil, fmt.Errorf("chronyd returned status %d", status)
+		}
+		if code := binary.BigEndian.Uint16(rsp[6:8]); code != reply {
+			return nil, fmt.Errorf("unexpected reply %d", code)
+		}
+		return rsp[replyHeaderLength:], nil
+	}
+}
+
+func (c *client) tracking() (*trackingReport, error) {
+	data, err := c.request(reqTracking, nil, rpyTracking)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 76 {
+		return nil, errors.New("truncated tracking reply")
+	}
+
+	// The reference time at offset 28 is skipped
+	return &trackingReport{
+		refID:              binary.BigEndian.Uint32(data[0:4]),
+		stratum:            binary.BigEndian.Uint16(data[24:26]),
+		leapStatus:         binary.BigEndian.Uint16(data[26:28]),
+		currentCorrection:  decodeFloat(data[40:44]),
+		lastOffset:         decodeFloat(data[44:48]),
+		rmsOffset:          decodeFloat(data[48:52]),
+		freqPPM:            decodeFloat(data[52:56]),
+		residFreqPPM:       decodeFloat(data[56:60]),
+		skewPPM:            decodeFloat(data[60:64]),
+		rootDelay:          decodeFloat(data[64:68]),
+		rootDispersion:     decodeFloat(data[68:72]),
+		lastUpdateInterval: decodeFloat(data[72:76]),
+	}, nil
+}
+
+func (c *client) numSources() (int, error) {
+	data, err := c.request(reqNSources, nil, rpyNSources)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 4 {
+		return 0, errors.New("truncated number of sources reply")
+	}
+	return int(binary.BigEndian.Uint32(data[0:4])), nil
+}
+
+func (c *client) sourceData(index int) (*sourceData, error) {
+	data, err := c.request(reqSourceData, binary.BigEndian.AppendUint32(nil, uint32(index)), rpySourceData)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 48 {
+		return nil, errors.New("truncated source data reply")
+	}
+
+	s := &sourceData{
+		poll:           int16(binary.BigEndian.Uint16(data[20:22])),
+		stratum:        binary.BigEndian.Uint16(data[22:24]),
+		state:          binary.BigEndian.Uint16(data[24:26]),
+		mode:           binary.BigEndian.Uint16(data[26:28]),
+		flags:          binary.BigEndian.Uint16(data[28:30]),
+		reachability:   binary.BigEndian.Uint16(data[30:32]),
+		sinceSample:    binary.BigEndian.Uint32(data[32:36]),
+		origLatestMeas: decodeFloat(data[36:40]),
+		latestMeas:     decodeFloat(data[40:44]),
+		latestMeasErr:  decodeFloat(data[44:48]),
+	}
+	if s.mode == sourceModeRef {
+		// Reference clocks carry their reference ID instead of an address
+		s.address = refIDString(binary.BigEndian.Uint32(data[0:4]))
+	} else {
+		s.address = decodeIPAddr(data[0:20], 0)
+	}
+	return s, nil
+}
+
+func (c *client) sourceStats(index int) (*sourceStats, error) {
+	data, err := c.request(reqSourceStats, binary.BigEndian.AppendUint32(nil, uint32(index)), rpySourceStats)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 56 {
+		return nil, errors.New("truncated source stats reply")
+	}
+
+	refID := binary.BigEndian.Uint32(data[0:4])
+	return &sourceStats{
+		refID:        refID,
+		address:      decodeIPAddr(data[4:24], refID),
+		samples:      binary.BigEndian.Uint32(data[24:28]),
+		runs:         binary.BigEndian.Uint32(data[28:32]),
+		spanSeconds:  binary.BigEndian.Uint32(data[32:36]),
+		stdDev:       decodeFloat(data[36:40]),
+		residFreqPPM: decodeFloat(data[40:44]),
+		skewPPM:      decodeFloat(data[44:48]),
+		estOffset:    decodeFloat(data[48:52]),
+		estOffsetErr: decodeFloat(data[52:56]),
+	}, nil
+}
+
+// decodeFloat decodes chrony's network floating point format consisting of
+// a 7-bit exponent and a 25-bit coefficient
+func decodeFloat(data []byte) float64 {
+	x := binary.BigEndian.Uint32(data)
+
+	exp := int32(x >> 25)
+	if exp >= 1<<6 {
+		exp -= 1 << 7
+	}
+	exp -= 25
+
+	coef := int32(x % (1 << 25))
+	if coef >= 1<<24 {
+		coef -= 1 << 25
+	}
+	return float64(coef) * math.Pow(2, float64(exp))
+}
+
+// decodeIPAddr decodes an address consisting of 16 bytes address data, the
+// address family and padding. Unspecified addresses are represented by the
+// given reference ID.
+func decodeIPAddr(data []byte, refID uint32) string {
+	switch binary.BigEndian.Uint16(data[16:18]) {
+	case ipAddrInet4:
+		return net.IP(data[0:4]).String()
+	case ipAddrInet6:
+		return net.IP(data[0:16]).String()
+	case ipAddrID:
+		return fmt.Sprintf("ID#%010d", binary.BigEndian.Uint32(data[0:4]))
+	case ipAddrUnspec:
+		return refIDString(refID)
+	}
+	return ""
+}
+
+// refIDString returns the printable characters of a reference ID, e.g. the
+// name of a reference clock like "GPS"
+func refIDString(refID uint32) string {
+	var sb strings.Builder
+	for i := 3; i >= 0; i-- {
+		if c := byte(refID >> (8 * i)); c >= 0x21 && c <= 0x7e {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 	"net"
 	"net/http"
@@ -40,6 +41,7 @@ type CloudWatchMetricStreams struct {
 	WriteTimeout     config.Duration `toml:"write_timeout"`
 	AccessKey        string          `toml:"access_key"`
 	APICompatability bool            `toml:"api_compatability"`
+	Format           string          `toml:"format"`
 
 	requestsReceived selfstat.Stat
 	writesServed     selfstat.Stat
@@ -239,7 +241,7 @@ func (cms *CloudWatchMetricStreams) serveWrite(res http.ResponseWriter, req *htt
 	defer agesInRequest.SubmitMin(cms.ageMin)
 
 	// For each record, decode the base64 data and store it in a Data struct
-	// Metrics from Metric Streams are Base64 encoded JSON
+	// Metrics from Metric Streams are Base64 encoded JSON or OpenTelemetry data
 	// https://docs.aws.amazon.com/firehose/latest/dev/httpdeliveryrequestresponse.html
 	for _, record := range r.Records {
 		b, err := base64.StdEncoding.DecodeString(record.Data)
@@ -251,25 +253,22 @@ func (cms *CloudWatchMetricStreams) serveWrite(res http.ResponseWriter, req *htt
 			return
 		}
 
-		list := strings.Split(string(b), "\n")
-
-		// If the last element is empty, remove it to avoid unexpected JSON
-		if len(list) > 0 {
-			if list[len(list)-1] == "" {
-				list = list[:len(list)-1]
+		var data []Data
+		switch cms.Format {
+		case "opentelemetry0.7":
+			data, err = parseOpenTelemetry(b)
+		default:
+			data, err = parseJSON(b)
+		}
+		if err != nil {
+			cms.Log.Errorf("unable to unmarshal metric-streams data: %v", err)
+			if err := badRequest(res); err != nil {
+				cms.Log.Debugf("error in bad-request: %v", err)
 			}
+			return
 		}
 
-		for _, js := range list {
-			var d Data
-			err = json.Unmarshal([]byte(js), &d)
-			if err != nil {
-				cms.Log.Errorf("unable to unmarshal metric-streams data: %v", err)
-				if err := badRequest(res); err != nil {
-					cms.Log.Debugf("error in bad-request: %v", err)
-				}
-				return
-			}
+		for _, d := range data {
 			cms.composeMetrics(d)
 			agesInRequest.Record(time.Since(time.Unix(d.Timestamp/1000, 0)))
 		}
@@ -300,6 +299,29 @@ func (cms *CloudWatchMetricStreams) serveWrite(res http.ResponseWriter, req *htt
 	}
 }
 
+// parseJSON decodes the data of a Firehose record in the JSON format, i.e.
+// newline separated JSON objects
+func parseJSON(buf []byte) ([]Data, error) {
+	list := strings.Split(string(buf), "\n")
+
+	// If the last element is empty, remove it to avoid unexpected JSON
+	if len(list) > 0 {
+		if list[len(list)-1] == "" {
+			list = list[:len(list)-1]
+		}
+	}
+
+	result := make([]Data, 0, len(list))
+	for _, js := range list {
+		var d Data
+		if err := json.Unmarshal([]byte(js), &d); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
 func (cms *CloudWatchMetricStreams) composeMetrics(data Data) {
 	fields := make(map[string]interface{})
 	tags := make(map[string]string)
@@ -407,6 +429,14 @@ func (cms *CloudWatchMetricStreams) Init() error {
 	cms.ageMax = selfstat.Register("cloudwatch_metric_streams", "age_max", tags)
 	cms.ageMin = selfstat.Register("cloudwatch_metric_streams", "age_min", tags)
 
+	switch cms.Format {
+	case "":
+		cms.Format = "json"
+	case "json", "opentelemetry0.7":
+	default:
+		return fmt.Errorf("invalid format %q", cms.Format)
+	}
+
 	if cms.MaxBodySize == 0 {
 		cms.MaxBodySize = config.Size(defaultMaxBodySize)
 	}
@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -380,3 +387,138 @@ func TestWriteHTTPGzippedData(t *testing.T) {
 	require.NoError(t, resp.Body.Close())
 	require.EqualValues(t, 200, resp.StatusCode)
 }
+
+// otlpMessage builds a length-delimited OpenTelemetry 0.7.0 export request
+// as sent by Metric Streams with one metric per given dimensions label
+func otlpMessage(dimensions ...string) []byte {
+	appendString := func(b []byte, num protowire.Number, s string) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendString(b, s)
+	}
+	appendMessage := func(b []byte, num protowire.Number, msg []byte) []byte {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		return protowire.AppendBytes(b, msg)
+	}
+	appendDouble := func(b []byte, num protowire.Number, v float64) []byte {
+		b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(v))
+	}
+	attribute := func(key, value string) []byte {
+		return appendMessage(appendString(nil, 1, key), 2, appendString(nil, 1, value))
+	}
+	label := func(key, value string) []byte {
+		return appendString(appendString(nil, 1, key), 2, value)
+	}
+
+	var resource []byte
+	resource = appendMessage(resource, 1, attribute("cloud.provider", "aws"))
+	resource = appendMessage(resource, 1, attribute("cloud.account.id", "546734499701"))
+	resource = appendMessage(resource, 1, attribute("cloud.region", "us-west-2"))
+	resource = appendMessage(resource, 1, attribute("aws.exporter.arn", "arn:aws:cloudwatch:us-west-2:546734499701:metric-stream/test-stream"))
+
+	var library []byte
+	for _, dims := range dimensions {
+		var point []byte
+		point = appendMessage(point, 1, label("Namespace", "AWS/EC2"))
+		point = appendMessage(point, 1, label("MetricName", "CPUUtilization"))
+		point = appendMessage(point, 1, label("Dimensions", dims))
+		point = protowire.AppendTag(point, 2, protowire.Fixed64Type)
+		point = protowire.AppendFixed64(point, 1651679340000000000)
+		point = protowire.AppendTag(point, 3, protowire.Fixed64Type)
+		point = protowire.AppendFixed64(point, 1651679400000000000)
+		point = protowire.AppendTag(point, 4, protowire.Fixed64Type)
+		point = protowire.AppendFixed64(point, 5)
+		point = appendDouble(point, 5, 1.94)
+		point = appendMessage(point, 6, appendDouble(appendDouble(nil, 1, 0), 2, 0.36))
+		point = appendMessage(point, 6, appendDouble(appendDouble(nil, 1, 1), 2, 0.43))
+
+		var metric []byte
+		metric = appendString(metric, 1, "amazonaws.com/AWS/EC2/CPUUtilization")
+		metric = appendString(metric, 3, "Percent")
+		metric = appendMessage(metric, 11, appendMessage(nil, 1, point))
+		library = appendMessage(library, 2, metric)
+	}
+
+	var resourceMetrics []byte
+	resourceMetrics = appendMessage(resourceMetrics, 1, resource)
+	resourceMetrics = appendMessage(resourceMetrics, 2, library)
+
+	return protowire.AppendBytes(nil, appendMessage(nil, 1, resourceMetrics))
+}
+
+func TestParseOpenTelemetry(t *testing.T) {
+	// Two messages in a single record
+	buf := otlpMessage(`{"InstanceId":"i-0123456789"}`)
+	buf = append(buf, otlpMessage("{AutoScalingGroupName=test-group, InstanceType=t3.micro}", "")...)
+
+	actual, err := parseOpenTelemetry(buf)
+	require.NoError(t, err)
+
+	template := Data{
+		MetricStreamName: "test-stream",
+		AccountID:        "546734499701",
+		Region:           "us-west-2",
+		Namespace:        "AWS/EC2",
+		MetricName:       "CPUUtilization",
+		Timestamp:        1651679400000,
+		Value:            map[string]float64{"max": 0.43, "min": 0.36, "sum": 1.94, "count": 5},
+		Unit:             "Percent",
+	}
+	expected := []Data{template, template, template}
+	expected[0].Dimensions = map[string]string{"InstanceId": "i-0123456789"}
+	expected[1].Dimensions = map[string]string{"AutoScalingGroupName": "test-group", "InstanceType": "t3.micro"}
+	require.Equal(t, expected, actual)
+
+	_, err = parseOpenTelemetry(buf[:len(buf)-3])
+	require.Error(t, err)
+}
+
+func TestWriteHTTPOpenTelemetry(t *testing.T) {
+	metricStream := newTestCloudWatchMetricStreams()
+	metricStream.Format = "opentelemetry0.7"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, metricStream.Init())
+	require.NoError(t, metricStream.Start(acc))
+	defer metricStream.Stop()
+
+	request := Request{
+		RequestID: "ed4acda5-034f-9f42-bba1-f29aea6d7d8f",
+		Timestamp: 1651679410000,
+	}
+	request.Records = append(request.Records, struct {
+		Data string `json:"data"`
+	}{Data: base64.StdEncoding.EncodeToString(otlpMessage(`{"AutoScalingGroupName":"test-autoscaling-group"}`))})
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	resp, err := http.Post(createURL("http", "/write"), "", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 200, resp.StatusCode)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"aws_ec2_cpuutilization",
+			map[string]string{
+				"AutoScalingGroupName": "test-autoscaling-group",
+				"accountId":            "546734499701",
+				"region":               "us-west-2",
+			},
+			map[string]interface{}{
+				"max":   0.43,
+				"min":   0.36,
+				"sum":   1.94,
+				"count": 5.0,
+			},
+			time.Unix(1651679400, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestInvalidFormat(t *testing.T) {
+	metricStream := newTestCloudWatchMetricStreams()
+	metricStream.Format = "opentelemetry1.0"
+	require.EqualError(t, metricStream.Init(), `invalid format "opentelemetry1.0"`)
+}
@@ -0,0 +1,304 @@
+package cloudwatch_metric_streams
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Metric Streams in the OpenTelemetry 0.7.0 format deliver records containing
+// a sequence of length-delimited ExportMetricsServiceRequest messages.
+// Every metric carries a single DoubleSummary data point with the minimum and
+// maximum encoded as the 0.0 and 1.0 quantiles.
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch-metric-streams-formats-opentelemetry.html
+//
+// Only the fields used by Metric Streams are decoded, which allows to decode
+// the messages without the generated code of this outdated OTLP version.
+// Field numbers are taken from the v0.7.0 protocol definitions at
+// https://github.com/open-telemetry/opentelemetry-proto/tree/v0.7.0
+
+// parseOpenTelemetry decodes the data of a Firehose record in the
+// OpenTelemetry 0.7.0 format
+func parseOpenTelemetry(buf []byte) ([]Data, error) {
+	var result []Data
+	for len(buf) > 0 {
+		msg, n := protowire.ConsumeBytes(buf)
+		if n < 0 {
+			return nil, fmt.Errorf("reading message length failed: %w", protowire.ParseError(n))
+		}
+		buf = buf[n:]
+
+		// ExportMetricsServiceRequest
+		err := walkMessage(msg, func(num protowire.Number, _ protowire.Type, v []byte) error {
+			if num != 1 { // resource_metrics
+				return nil
+			}
+			data, err := parseResourceMetrics(v)
+			if err != nil {
+				return err
+			}
+			result = append(result, data...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func parseResourceMetrics(buf []byte) ([]Data, error) {
+	var resource Data
+	var metrics [][]byte
+	err := walkMessage(buf, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1: // resource
+			return parseResource(v, &resource)
+		case 2: // instrumentation_library_metrics
+			return walkMessage(v, func(num protowire.Number, _ protowire.Type, v []byte) error {
+				if num == 2 { // metrics
+					metrics = append(metrics, v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The resource might be sent after the metrics, so decode the metrics
+	// only once all of the message is known
+	var result []Data
+	for _, m := range metrics {
+		data, err := parseMetric(m, resource)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data...)
+	}
+	return result, nil
+}
+
+func parseResource(buf []byte, d *Data) error {
+	return walkMessage(buf, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		if num != 1 { // attributes
+			return nil
+		}
+		key, value, err := parseKeyValue(v)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "cloud.account.id":
+			d.AccountID = value
+		case "cloud.region":
+			d.Region = value
+		case "aws.exporter.arn":
+			// arn:aws:cloudwatch:<region>:<account>:metric-stream/<name>
+			if _, name, found := strings.Cut(value, ":metric-stream/"); found {
+				d.MetricStreamName = name
+			}
+		}
+		return nil
+	})
+}
+
+// parseKeyValue decodes a KeyValue with a string AnyValue
+func parseKeyValue(buf []byte) (key, value string, err error) {
+	err = walkMessage(buf, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 1: // key
+			key = string(v)
+		case 2: // value
+			return walkMessage(v, func(num protowire.Number, _ protowire.Type, v []byte) error {
+				if num == 1 { // string_value
+					value = string(v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return key, value, err
+}
+
+func parseMetric(buf []byte, resource Data) ([]Data, error) {
+	var unit string
+	var points [][]byte
+	err := walkMessage(buf, func(num protowire.Number, _ protowire.Type, v []byte) error {
+		switch num {
+		case 3: // unit
+			unit = string(v)
+		case 11: // double_summary
+			return walkMessage(v, func(num protowire.Number, _ protowire.Type, v []byte) error {
+				if num == 1 { // data_points
+					points = append(points, v)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Data, 0, len(points))
+	for _, p := range points {
+		d := resource
+		d.Unit = unit
+		if err := parseSummaryDataPoint(p, &d); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func parseSummaryDataPoint(buf []byte, d *Data) error {
+	d.Value = make(map[string]float64, 4)
+	return walkMessage(buf, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		switch num {
+		case 1: // labels
+			var key, value string
+			err := walkMessage(v, func(num protowire.Number, _ protowire.Type, v []byte) error {
+				switch num {
+				case 1:
+					key = string(v)
+				case 2:
+					value = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "Namespace":
+				d.Namespace = value
+			case "MetricName":
+				d.MetricName = value
+			case "Dimensions":
+				d.Dimensions, err = parseDimensions(value)
+			}
+			return err
+		case 3: // time_unix_nano
+			if typ != protowire.Fixed64Type {
+				return errors.New("invalid type of time")
+			}
+			d.Timestamp = int64(fixed64(v) / 1000000)
+		case 4: // count
+			if typ != protowire.Fixed64Type {
+				return errors.New("invalid type of count")
+			}
+			d.Value["count"] = float64(fixed64(v))
+		case 5: // sum
+			if typ != protowire.Fixed64Type {
+				return errors.New("invalid type of sum")
+			}
+			d.Value["sum"] = math.Float64frombits(fixed64(v))
+		case 6: // quantile_values
+			var quantile, value float64
+			err := walkMessage(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if typ != protowire.Fixed64Type {
+					return nil
+				}
+				switch num {
+				case 1:
+					quantile = math.Float64frombits(fixed64(v))
+				case 2:
+					value = math.Float64frombits(fixed64(v))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			switch quantile {
+			case 0:
+				d.Value["min"] = value
+			case 1:
+				d.Value["max"] = value
+			}
+		}
+		return nil
+	})
+}
+
+// parseDimensions decodes the dimensions label which is a JSON object of the
+// dimension names and values. The "{Name=Value, ...}" notation is accepted as
+// well as fallback.
+func parseDimensions(value string) (map[string]string, error) {
+	if value == "" || value == "{}" {
+		return nil, nil
+	}
+	var dimensions map[string]string
+	if err := json.Unmarshal([]byte(value), &dimensions); err == nil {
+		return dimensions, nil
+	}
+
+	inner, hasPrefix := strings.CutPrefix(value, "{")
+	inner, hasSuffix := strings.CutSuffix(inner, "}")
+	if !hasPrefix || !hasSuffix {
+		return nil, fmt.Errorf("invalid dimensions %q", value)
+	}
+	dimensions = make(map[string]string)
+	for _, pair := range strings.Split(inner, ",") {
+		k, v, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid dimensions %q", value)
+		}
+		dimensions[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return dimensions, nil
+}
+
+// walkMessage calls the given function for each field of the protobuf
+// message. The value passed is the content of length-delimited fields and
+// the raw little-endian bytes of fixed-size fields; varint fields are skipped.
+func walkMessage(buf []byte, fn func(protowire.Number, protowire.Type, []byte) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		var v []byte
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(buf)
+		case protowire.Fixed64Type:
+			n = 8
+			if len(buf) < n {
+				return protowire.ParseError(-1)
+			}
+			v = buf[:n]
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		if v == nil {
+			continue
+		}
+		if err := fn(num, typ, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fixed64(v []byte) uint64 {
+	x, _ := protowire.ConsumeFixed64(v)
+	return x
+}
@@ -0,0 +1,200 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cri
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Container states of the CRI API
+var containerStates = map[runtimeapi.ContainerState]string{
+	runtimeapi.ContainerState_CONTAINER_CREATED: "created",
+	runtimeapi.ContainerState_CONTAINER_RUNNING: "running",
+	runtimeapi.ContainerState_CONTAINER_EXITED:  "exited",
+	runtimeapi.ContainerState_CONTAINER_UNKNOWN: "unknown",
+}
+
+type CRI struct {
+	Endpoint         string          `toml:"endpoint"`
+	Timeout          config.Duration `toml:"timeout"`
+	ContainerInclude []string        `toml:"container_name_include"`
+	ContainerExclude []string        `toml:"container_name_exclude"`
+	NamespaceInclude []string        `toml:"namespace_include"`
+	NamespaceExclude []string        `toml:"namespace_exclude"`
+	PodLabelInclude  []string        `toml:"pod_label_include"`
+	PodLabelExclude  []string        `toml:"pod_label_exclude"`
+	Log              telegraf.Logger `toml:"-"`
+
+	containerFilter filter.Filter
+	namespaceFilter filter.Filter
+	labelFilter     filter.Filter
+
+	conn   *grpc.ClientConn
+	client runtimeapi.RuntimeServiceClient
+}
+
+func (*CRI) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CRI) Init() error {
+	if c.Endpoint == "" {
+		c.Endpoint = "unix:///run/containerd/containerd.sock"
+	}
+
+	var err error
+	c.containerFilter, err = filter.NewIncludeExcludeFilter(c.ContainerInclude, c.ContainerExclude)
+	if err != nil {
+		return fmt.Errorf("creating container filter failed: %w", err)
+	}
+	c.namespaceFilter, err = filter.NewIncludeExcludeFilter(c.NamespaceInclude, c.NamespaceExclude)
+	if err != nil {
+		return fmt.Errorf("creating namespace filter failed: %w", err)
+	}
+	c.labelFilter, err = filter.NewIncludeExcludeFilter(c.PodLabelInclude, c.PodLabelExclude)
+	if err != nil {
+		return fmt.Errorf("creating pod label filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (c *CRI) Start(telegraf.Accumulator) error {
+	conn, err := grpc.Dial(
+		c.Endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUserAgent(internal.ProductToken()),
+	)
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", c.Endpoint, err)
+	}
+	c.conn = conn
+	c.client = runtimeapi.NewRuntimeServiceClient(conn)
+
+	return nil
+}
+
+func (c *CRI) Stop() {
+	if c.conn != nil {
+		if err := c.conn.Close(); err != nil {
+			c.Log.Errorf("Closing connection failed: %v", err)
+		}
+	}
+}
+
+func (c *CRI) Gather(acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout))
+	defer cancel()
+
+	sandboxes, err := c.client.ListPodSandbox(ctx, &runtimeapi.ListPodSandboxRequest{})
+	if err != nil {
+		return fmt.Errorf("listing pod sandboxes failed: %w", err)
+	}
+	pods := make(map[string]*runtimeapi.PodSandbox, len(sandboxes.Items))
+	for _, p := range sandboxes.Items {
+		pods[p.Id] = p
+	}
+
+	containers, err := c.client.ListContainers(ctx, &runtimeapi.ListContainersRequest{})
+	if err != nil {
+		return fmt.Errorf("listing containers failed: %w", err)
+	}
+	byID := make(map[string]*runtimeapi.Container, len(containers.Containers))
+	for _, ctr := range containers.Containers {
+		byID[ctr.Id] = ctr
+	}
+
+	stats, err := c.client.ListContainerStats(ctx, &runtimeapi.ListContainerStatsRequest{})
+	if err != nil {
+		return fmt.Errorf("listing container stats failed: %w", err)
+	}
+
+	now := time.Now()
+	for _, s := range stats.Stats {
+		ctr, found := byID[s.GetAttributes().GetId()]
+		if !found {
+			// The container vanished between the requests
+			continue
+		}
+		pod := pods[ctr.PodSandboxId]
+
+		name := ctr.GetMetadata().GetName()
+		if name == "" {
+			name = s.GetAttributes().GetMetadata().GetName()
+		}
+		if !c.containerFilter.Match(name) || !c.namespaceFilter.Match(pod.GetMetadata().GetNamespace()) {
+			continue
+		}
+
+		state, found := containerStates[ctr.State]
+		if !found {
+			state = containerStates[runtimeapi.ContainerState_CONTAINER_UNKNOWN]
+		}
+		tags := map[string]string{
+			"container_id":    ctr.Id,
+			"container_name":  name,
+			"container_image": ctr.GetImage().GetImage(),
+			"state":           state,
+		}
+		if pod != nil {
+			tags["pod_name"] = pod.GetMetadata().GetName()
+			tags["pod_namespace"] = pod.GetMetadata().GetNamespace()
+			tags["pod_uid"] = pod.GetMetadata().GetUid()
+			for k, v := range pod.Labels {
+				if c.labelFilter.Match(k) {
+					tags[k] = v
+				}
+			}
+		}
+
+		fields := make(map[string]interface{})
+		addField(fields, "cpu_usage_core_nanoseconds", s.GetCpu().GetUsageCoreNanoSeconds())
+		addField(fields, "cpu_usage_nanocores", s.GetCpu().GetUsageNanoCores())
+		addField(fields, "memory_working_set_bytes", s.GetMemory().GetWorkingSetBytes())
+		addField(fields, "memory_available_bytes", s.GetMemory().GetAvailableBytes())
+		addField(fields, "memory_usage_bytes", s.GetMemory().GetUsageBytes())
+		addField(fields, "memory_rss_bytes", s.GetMemory().GetRssBytes())
+		addField(fields, "memory_page_faults", s.GetMemory().GetPageFaults())
+		addField(fields, "memory_major_page_faults", s.GetMemory().GetMajorPageFaults())
+		addField(fields, "fs_used_bytes", s.GetWritableLayer().GetUsedBytes())
+		addField(fields, "fs_inodes_used", s.GetWritableLayer().GetInodesUsed())
+		if len(fields) == 0 {
+			continue
+		}
+
+		acc.AddFields("cri_container", fields, tags, now)
+	}
+
+	return nil
+}
+
+// addField adds the given optional value as field if reported by the runtime
+func addField(fields map[string]interface{}, name string, v *runtimeapi.UInt64Value) {
+	if v != nil {
+		fields[name] = v.Value
+	}
+}
+
+func init() {
+	inputs.Add("cri", func() telegraf.Input {
+		return &CRI{
+			Timeout:         config.Duration(5 * time.Second),
+			PodLabelExclude: []string{"*"},
+		}
+	})
+}
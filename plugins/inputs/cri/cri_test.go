@@ -0,0 +1,211 @@
+package cri
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	service := &runtimeService{
+		sandboxes: []*runtimeapi.PodSandbox{
+			{
+				Id: "sandbox-1",
+				Metadata: &runtimeapi.PodSandboxMetadata{
+					Name:      "web-0",
+					Uid:       "0b5f2a4e-3c1d-4e8f-9a7b-6c5d4e3f2a1b",
+					Namespace: "shop",
+				},
+				State:  runtimeapi.PodSandboxState_SANDBOX_READY,
+				Labels: map[string]string{"app": "web", "tier": "frontend"},
+			},
+			{
+				Id: "sandbox-2",
+				Metadata: &runtimeapi.PodSandboxMetadata{
+					Name:      "coredns-abc",
+					Uid:       "9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a",
+					Namespace: "kube-system",
+				},
+			},
+		},
+		containers: []*runtimeapi.Container{
+			{
+				Id:           "c1",
+				PodSandboxId: "sandbox-1",
+				Metadata:     &runtimeapi.ContainerMetadata{Name: "nginx"},
+				Image:        &runtimeapi.ImageSpec{Image: "docker.io/library/nginx:1.25"},
+				ImageRef:     "sha256:abcdef",
+				State:        runtimeapi.ContainerState_CONTAINER_RUNNING,
+			},
+			{
+				Id:           "c2",
+				PodSandboxId: "sandbox-2",
+				Metadata:     &runtimeapi.ContainerMetadata{Name: "coredns"},
+				Image:        &runtimeapi.ImageSpec{Image: "registry.k8s.io/coredns/coredns:v1.11.1"},
+				State:        runtimeapi.ContainerState_CONTAINER_RUNNING,
+			},
+		},
+		stats: []*runtimeapi.ContainerStats{
+			{
+				Attributes: &runtimeapi.ContainerAttributes{
+					Id:       "c1",
+					Metadata: &runtimeapi.ContainerMetadata{Name: "nginx"},
+					Labels:   map[string]string{"io.kubernetes.pod.name": "web-0"},
+				},
+				Cpu: &runtimeapi.CpuUsage{
+					Timestamp:            1700000000,
+					UsageCoreNanoSeconds: &runtimeapi.UInt64Value{Value: 123456789},
+					UsageNanoCores:       &runtimeapi.UInt64Value{Value: 2500000},
+				},
+				Memory: &runtimeapi.MemoryUsage{
+					Timestamp:       1700000000,
+					WorkingSetBytes: &runtimeapi.UInt64Value{Value: 10485760},
+					AvailableBytes:  &runtimeapi.UInt64Value{Value: 52428800},
+					UsageBytes:      &runtimeapi.UInt64Value{Value: 12582912},
+					RssBytes:        &runtimeapi.UInt64Value{Value: 8388608},
+					PageFaults:      &runtimeapi.UInt64Value{Value: 1234},
+					MajorPageFaults: &runtimeapi.UInt64Value{Value: 5},
+				},
+				WritableLayer: &runtimeapi.FilesystemUsage{
+					Timestamp:  1700000000,
+					FsId:       &runtimeapi.FilesystemIdentifier{Mountpoint: "/var/lib/containerd/io.containerd.snapshotter.v1.overlayfs"},
+					UsedBytes:  &runtimeapi.UInt64Value{Value: 4096},
+					InodesUsed: &runtimeapi.UInt64Value{Value: 12},
+				},
+			},
+			{
+				Attributes: &runtimeapi.ContainerAttributes{
+					Id:       "c2",
+					Metadata: &runtimeapi.ContainerMetadata{Name: "coredns"},
+				},
+				Cpu:    &runtimeapi.CpuUsage{UsageCoreNanoSeconds: &runtimeapi.UInt64Value{Value: 987654321}},
+				Memory: &runtimeapi.MemoryUsage{WorkingSetBytes: &runtimeapi.UInt64Value{Value: 20971520}},
+			},
+			// Stats of a container not listed anymore
+			{
+				Attributes: &runtimeapi.ContainerAttributes{
+					Id:       "c3",
+					Metadata: &runtimeapi.ContainerMetadata{Name: "gone"},
+				},
+				Cpu: &runtimeapi.CpuUsage{UsageCoreNanoSeconds: &runtimeapi.UInt64Value{Value: 1}},
+			},
+		},
+	}
+	endpoint := startServer(t, service)
+
+	plugin := &CRI{
+		Endpoint:         endpoint,
+		Timeout:          config.Duration(5 * time.Second),
+		NamespaceExclude: []string{"kube-system"},
+		PodLabelInclude:  []string{"app"},
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cri_container",
+			map[string]string{
+				"container_id":    "c1",
+				"container_name":  "nginx",
+				"container_image": "docker.io/library/nginx:1.25",
+				"state":           "running",
+				"pod_name":        "web-0",
+				"pod_namespace":   "shop",
+				"pod_uid":         "0b5f2a4e-3c1d-4e8f-9a7b-6c5d4e3f2a1b",
+				"app":             "web",
+			},
+			map[string]interface{}{
+				"cpu_usage_core_nanoseconds": uint64(123456789),
+				"cpu_usage_nanocores":        uint64(2500000),
+				"memory_working_set_bytes":   uint64(10485760),
+				"memory_available_bytes":     uint64(52428800),
+				"memory_usage_bytes":         uint64(12582912),
+				"memory_rss_bytes":           uint64(8388608),
+				"memory_page_faults":         uint64(1234),
+				"memory_major_page_faults":   uint64(5),
+				"fs_used_bytes":              uint64(4096),
+				"fs_inodes_used":             uint64(12),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUnavailable(t *testing.T) {
+	plugin := &CRI{
+		Endpoint: "unix://" + filepath.Join(t.TempDir(), "missing.sock"),
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "listing pod sandboxes failed")
+}
+
+// runtimeService is a CRI runtime service returning the given objects
+type runtimeService struct {
+	runtimeapi.UnimplementedRuntimeServiceServer
+
+	sandboxes  []*runtimeapi.PodSandbox
+	containers []*runtimeapi.Container
+	stats      []*runtimeapi.ContainerStats
+}
+
+func (s *runtimeService) ListPodSandbox(
+	context.Context,
+	*runtimeapi.ListPodSandboxRequest,
+) (*runtimeapi.ListPodSandboxResponse, error) {
+	return &runtimeapi.ListPodSandboxResponse{Items: s.sandboxes}, nil
+}
+
+func (s *runtimeService) ListContainers(
+	context.Context,
+	*runtimeapi.ListContainersRequest,
+) (*runtimeapi.ListContainersResponse, error) {
+	return &runtimeapi.ListContainersResponse{Containers: s.containers}, nil
+}
+
+func (s *runtimeService) ListContainerStats(
+	context.Context,
+	*runtimeapi.ListContainerStatsRequest,
+) (*runtimeapi.ListContainerStatsResponse, error) {
+	return &runtimeapi.ListContainerStatsResponse{Stats: s.stats}, nil
+}
+
+// startServer starts a CRI server with the given runtime service and returns
+// its endpoint
+func startServer(t *testing.T, service runtimeapi.RuntimeServiceServer) string {
+	sock := filepath.Join(t.TempDir(), "cri.sock")
+	listener, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	runtimeapi.RegisterRuntimeServiceServer(server, service)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return "unix://" + sock
+}
@@ -0,0 +1,191 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dcgm
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+	httpcommon "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Prefix of the metric names of DCGM fields
+const fieldPrefix = "DCGM_FI_"
+
+// Tag names for the entity labels added by the DCGM exporter
+var tagNames = map[string]string{
+	"UUID":          "uuid",
+	"modelName":     "model_name",
+	"Hostname":      "hostname",
+	"GPU_I_ID":      "gpu_instance_id",
+	"GPU_I_PROFILE": "gpu_instance_profile",
+}
+
+type DCGM struct {
+	URLs []string        `toml:"urls"`
+	Log  telegraf.Logger `toml:"-"`
+	httpcommon.HTTPClientConfig
+
+	client *http.Client
+}
+
+func (*DCGM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DCGM) Init() error {
+	if len(d.URLs) == 0 {
+		d.URLs = []string{"http://localhost:9400/metrics"}
+	}
+
+	client, err := d.HTTPClientConfig.CreateClient(context.Background(), d.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	d.client = client
+
+	return nil
+}
+
+func (d *DCGM) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range d.URLs {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			if err := d.gatherURL(acc, u); err != nil {
+				acc.AddError(fmt.Errorf("gathering %q failed: %w", u, err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (d *DCGM) gatherURL(acc telegraf.Accumulator, u string) error {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %s", resp.Status)
+	}
+
+	now := time.Now()
+	entities := make(map[string]*entity)
+	decoder := expfmt.NewDecoder(resp.Body, expfmt.ResponseFormat(resp.Header))
+	for {
+		var mf dto.MetricFamily
+		if err := decoder.Decode(&mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("decoding response failed: %w", err)
+		}
+
+		name := mf.GetName()
+		if !strings.HasPrefix(name, fieldPrefix) {
+			continue
+		}
+		field := strings.ToLower(strings.TrimPrefix(name, fieldPrefix))
+
+		for _, m := range mf.GetMetric() {
+			value, ok := metricValue(mf.GetType(), m)
+			if !ok {
+				continue
+			}
+
+			tags := make(map[string]string, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				tags[tagName(l.GetName())] = l.GetValue()
+			}
+
+			key := entityKey(tags)
+			e, found := entities[key]
+			if !found {
+				e = &entity{tags: tags, fields: make(map[string]interface{})}
+				entities[key] = e
+			}
+			e.fields[field] = value
+		}
+	}
+
+	for _, e := range entities {
+		acc.AddFields("dcgm", e.fields, e.tags, now)
+	}
+
+	return nil
+}
+
+// entity collects the fields of a GPU or GPU instance (MIG) identified by
+// the labels of the DCGM exporter metrics
+type entity struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+func entityKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tags[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func tagName(label string) string {
+	if name, found := tagNames[label]; found {
+		return name
+	}
+	return strings.ToLower(label)
+}
+
+func metricValue(typ dto.MetricType, m *dto.Metric) (float64, bool) {
+	switch typ {
+	case dto.MetricType_GAUGE:
+		return m.GetGauge().GetValue(), true
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetValue(), true
+	case dto.MetricType_UNTYPED:
+		return m.GetUntyped().GetValue(), true
+	}
+	return 0, false
+}
+
+func init() {
+	inputs.Add("dcgm", func() telegraf.Input {
+		return &DCGM{}
+	})
+}
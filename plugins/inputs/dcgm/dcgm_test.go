@@ -0,0 +1,146 @@
+package dcgm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("testdata", "metrics.txt"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write(buf)
+	}))
+	defer server.Close()
+
+	plugin := &DCGM{
+		URLs: []string{server.URL + "/metrics"},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	gpu0 := map[string]string{
+		"gpu":        "0",
+		"uuid":       "GPU-5fd4b5d8-1b2c-4a3e-9f60-7c8d9e0a1b2c",
+		"pci_bus_id": "00000000:07:00.0",
+		"device":     "nvidia0",
+		"model_name": "NVIDIA A100-SXM4-40GB",
+		"hostname":   "gpu-node-1",
+	}
+	gpu1 := map[string]string{
+		"gpu":        "1",
+		"uuid":       "GPU-8a9b0c1d-2e3f-4a5b-8c7d-9e0f1a2b3c4d",
+		"pci_bus_id": "00000000:0F:00.0",
+		"device":     "nvidia1",
+		"model_name": "NVIDIA A100-SXM4-40GB",
+		"hostname":   "gpu-node-1",
+	}
+	with := func(base map[string]string, extra map[string]string) map[string]string {
+		tags := make(map[string]string, len(base)+len(extra))
+		for k, v := range base {
+			tags[k] = v
+		}
+		for k, v := range extra {
+			tags[k] = v
+		}
+		return tags
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dcgm",
+			gpu0,
+			map[string]interface{}{
+				"dev_sm_clock":               float64(1410),
+				"dev_gpu_temp":               float64(34),
+				"dev_ecc_dbe_vol_total":      float64(0),
+				"dev_nvlink_bandwidth_total": float64(123456),
+				"prof_gr_engine_active":      float64(0.25),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dcgm",
+			gpu1,
+			map[string]interface{}{
+				"dev_sm_clock":          float64(1410),
+				"dev_gpu_temp":          float64(41),
+				"dev_ecc_dbe_vol_total": float64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dcgm",
+			with(gpu1, map[string]string{
+				"gpu_instance_id":      "1",
+				"gpu_instance_profile": "3g.20gb",
+				"namespace":            "ml",
+				"pod":                  "trainer-0",
+				"container":            "trainer",
+			}),
+			map[string]interface{}{
+				"prof_gr_engine_active": float64(0.5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dcgm",
+			with(gpu1, map[string]string{
+				"gpu_instance_id":      "2",
+				"gpu_instance_profile": "3g.20gb",
+			}),
+			map[string]interface{}{
+				"prof_gr_engine_active": float64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dcgm",
+			with(gpu1, map[string]string{
+				"err_code": "0",
+				"err_msg":  "No Error",
+			}),
+			map[string]interface{}{
+				"dev_xid_errors": float64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	plugin := &DCGM{
+		URLs: []string{server.URL + "/metrics"},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "503 Service Unavailable")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
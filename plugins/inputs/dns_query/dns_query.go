@@ -2,11 +2,17 @@
 package dns_query
 
 import (
+	"bytes"
+	"crypto/tls"
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +20,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	commontls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -26,18 +34,24 @@ const (
 	Success ResultType = iota
 	Timeout
 	Error
+	Mismatch
 )
 
 type DNSQuery struct {
-	Domains       []string        `toml:"domains"`
-	Network       string          `toml:"network"`
-	Servers       []string        `toml:"servers"`
-	RecordType    string          `toml:"record_type"`
-	Port          int             `toml:"port"`
-	Timeout       config.Duration `toml:"timeout"`
-	IncludeFields []string        `toml:"include_fields"`
+	Domains         []string        `toml:"domains"`
+	Network         string          `toml:"network"`
+	Servers         []string        `toml:"servers"`
+	RecordType      string          `toml:"record_type"`
+	Port            int             `toml:"port"`
+	Timeout         config.Duration `toml:"timeout"`
+	IncludeFields   []string        `toml:"include_fields"`
+	DNSSEC          bool            `toml:"dnssec"`
+	ExpectedAnswers []string        `toml:"expected_answers"`
+	commontls.ClientConfig
 
 	fieldEnabled map[string]bool
+	tlsCfg       *tls.Config
+	client       *http.Client
 }
 
 func (*DNSQuery) SampleConfig() string {
@@ -60,6 +74,10 @@ func (d *DNSQuery) Init() error {
 	if d.Network == "" {
 		d.Network = "udp"
 	}
+	networks := []string{"udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "tcp-tls", "tcp4-tls", "tcp6-tls", "https"}
+	if err := choice.Check(d.Network, networks); err != nil {
+		return fmt.Errorf("invalid network: %w", err)
+	}
 
 	if d.RecordType == "" {
 		d.RecordType = "NS"
@@ -71,7 +89,29 @@ func (d *DNSQuery) Init() error {
 	}
 
 	if d.Port < 1 {
-		d.Port = 53
+		if strings.HasSuffix(d.Network, "-tls") {
+			d.Port = 853
+		} else {
+			d.Port = 53
+		}
+	}
+
+	// Setup TLS for DNS-over-TLS and DNS-over-HTTPS queries
+	if d.Network == "https" || strings.HasSuffix(d.Network, "-tls") {
+		tlsCfg, err := d.ClientConfig.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("creating TLS config failed: %w", err)
+		}
+		d.tlsCfg = tlsCfg
+	}
+	if d.Network == "https" {
+		d.client = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: d.tlsCfg,
+			},
+			Timeout: time.Duration(d.Timeout),
+		}
 	}
 
 	return nil
@@ -87,11 +127,8 @@ func (d *DNSQuery) Gather(acc telegraf.Accumulator) error {
 				defer wg.Done()
 
 				fields, tags, err := d.query(domain, server)
-				if err != nil {
-					var opErr *net.OpError
-					if !errors.As(err, &opErr) || !opErr.Timeout() {
-						acc.AddError(err)
-					}
+				if err != nil && !isTimeout(err) {
+					acc.AddError(err)
 				}
 				acc.AddFields("dns_query", fields, tags)
 			}(domain, server)
@@ -115,11 +152,6 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 		"result_code":   uint64(Error),
 	}
 
-	c := dns.Client{
-		ReadTimeout: time.Duration(d.Timeout),
-		Net:         d.Network,
-	}
-
 	recordType, err := d.parseRecordType()
 	if err != nil {
 		return fields, tags, err
@@ -128,12 +160,15 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 	var msg dns.Msg
 	msg.SetQuestion(dns.Fqdn(domain), recordType)
 	msg.RecursionDesired = true
+	if d.DNSSEC {
+		// Request DNSSEC records and the validation result of the resolver
+		msg.SetEdns0(4096, true)
+		msg.AuthenticatedData = true
+	}
 
-	addr := net.JoinHostPort(server, strconv.Itoa(d.Port))
-	r, rtt, err := c.Exchange(&msg, addr)
+	r, rtt, err := d.exchange(&msg, server)
 	if err != nil {
-		var opErr *net.OpError
-		if errors.As(err, &opErr) && opErr.Timeout() {
+		if isTimeout(err) {
 			tags["result"] = "timeout"
 			fields["result_code"] = uint64(Timeout)
 			return fields, tags, err
@@ -151,6 +186,27 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 		return fields, tags, fmt.Errorf("invalid answer (%s) from %s after %s query for %s", dns.RcodeToString[r.Rcode], server, d.RecordType, domain)
 	}
 
+	if d.DNSSEC {
+		fields["authenticated_data"] = r.AuthenticatedData
+		var signatures int
+		for _, record := range r.Answer {
+			if _, ok := record.(*dns.RRSIG); ok {
+				signatures++
+			}
+		}
+		fields["rrsig_count"] = signatures
+	}
+
+	// Check the answer content if requested
+	if len(d.ExpectedAnswers) > 0 {
+		answers := extractAnswers(r.Answer, recordType)
+		if !equalAnswers(answers, d.ExpectedAnswers) {
+			tags["result"] = "mismatch"
+			fields["result_code"] = uint64(Mismatch)
+			return fields, tags, fmt.Errorf("unexpected answer %q from %s after %s query for %s", answers, server, d.RecordType, domain)
+		}
+	}
+
 	// Success
 	tags["result"] = "success"
 	fields["result_code"] = uint64(Success)
@@ -174,6 +230,61 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 	return fields, tags, nil
 }
 
+func (d *DNSQuery) exchange(msg *dns.Msg, server string) (*dns.Msg, time.Duration, error) {
+	if d.Network == "https" {
+		return d.exchangeHTTPS(msg, server)
+	}
+
+	c := dns.Client{
+		ReadTimeout: time.Duration(d.Timeout),
+		Net:         d.Network,
+		TLSConfig:   d.tlsCfg,
+	}
+
+	addr := net.JoinHostPort(server, strconv.Itoa(d.Port))
+	return c.Exchange(msg, addr)
+}
+
+// exchangeHTTPS sends the query as DNS-over-HTTPS request according to
+// RFC 8484 where the server is the URL of the resolver
+func (d *DNSQuery) exchangeHTTPS(msg *dns.Msg, server string) (*dns.Msg, time.Duration, error) {
+	// Use a zero ID to allow caching of the responses
+	msg.Id = 0
+	body, err := msg.Pack()
+	if err != nil {
+		return nil, 0, fmt.Errorf("packing query failed: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", server, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("received status %d (%s) from %s", resp.StatusCode, http.StatusText(resp.StatusCode), server)
+	}
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading response failed: %w", err)
+	}
+	rtt := time.Since(start)
+
+	var r dns.Msg
+	if err := r.Unpack(buf); err != nil {
+		return nil, 0, fmt.Errorf("unpacking response failed: %w", err)
+	}
+	return &r, rtt, nil
+}
+
 func (d *DNSQuery) parseRecordType() (uint16, error) {
 	var recordType uint16
 	var err error
@@ -201,6 +312,14 @@ func (d *DNSQuery) parseRecordType() (uint16, error) {
 		recordType = dns.TypeSRV
 	case "TXT":
 		recordType = dns.TypeTXT
+	case "CAA":
+		recordType = dns.TypeCAA
+	case "DNSKEY":
+		recordType = dns.TypeDNSKEY
+	case "DS":
+		recordType = dns.TypeDS
+	case "TLSA":
+		recordType = dns.TypeTLSA
 	default:
 		err = fmt.Errorf("record type %s not recognized", d.RecordType)
 	}
@@ -218,6 +337,65 @@ func extractIP(record dns.RR) (string, bool) {
 	return "", false
 }
 
+// extractAnswers returns the content of all answer records of the given type
+// or of all records for ANY queries
+func extractAnswers(records []dns.RR, recordType uint16) []string {
+	answers := make([]string, 0, len(records))
+	for _, record := range records {
+		if recordType != dns.TypeANY && record.Header().Rrtype != recordType {
+			continue
+		}
+		answers = append(answers, answerContent(record))
+	}
+	return answers
+}
+
+// answerContent returns the data of the record without the header
+func answerContent(record dns.RR) string {
+	switch r := record.(type) {
+	case *dns.A:
+		return r.A.String()
+	case *dns.AAAA:
+		return r.AAAA.String()
+	case *dns.TXT:
+		return strings.Join(r.Txt, "")
+	case *dns.SPF:
+		return strings.Join(r.Txt, "")
+	}
+	content := strings.TrimPrefix(record.String(), record.Header().String())
+	return strings.TrimSpace(content)
+}
+
+// equalAnswers compares the answers to the expected values ignoring the order,
+// the case and trailing dots of domain names
+func equalAnswers(answers, expected []string) bool {
+	if len(answers) != len(expected) {
+		return false
+	}
+
+	normalize := func(values []string) []string {
+		normalized := make([]string, 0, len(values))
+		for _, v := range values {
+			normalized = append(normalized, strings.ToLower(strings.TrimSuffix(v, ".")))
+		}
+		sort.Strings(normalized)
+		return normalized
+	}
+	a := normalize(answers)
+	e := normalize(expected)
+	for i := range a {
+		if a[i] != e[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 func init() {
 	inputs.Add("dns_query", func() telegraf.Input {
 		return &DNSQuery{
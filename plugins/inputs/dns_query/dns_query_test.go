@@ -1,6 +1,11 @@
 package dns_query
 
 import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -260,3 +265,177 @@ func TestRecordTypeParserError(t *testing.T) {
 	_, err := plugin.parseRecordType()
 	require.Error(t, err)
 }
+
+func TestRecordTypeParserExtended(t *testing.T) {
+	for _, tt := range []struct {
+		record   string
+		expected uint16
+	}{
+		{record: "CAA", expected: dns.TypeCAA},
+		{record: "DNSKEY", expected: dns.TypeDNSKEY},
+		{record: "DS", expected: dns.TypeDS},
+		{record: "TLSA", expected: dns.TypeTLSA},
+	} {
+		t.Run(tt.record, func(t *testing.T) {
+			plugin := DNSQuery{RecordType: tt.record}
+			recordType, err := plugin.parseRecordType()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, recordType)
+		})
+	}
+}
+
+func TestInvalidNetwork(t *testing.T) {
+	plugin := DNSQuery{
+		Network: "quic",
+		Timeout: config.Duration(2 * time.Second),
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid network")
+}
+
+func TestDefaultPortDoT(t *testing.T) {
+	plugin := DNSQuery{
+		Network: "tcp-tls",
+		Timeout: config.Duration(2 * time.Second),
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 853, plugin.Port)
+}
+
+// answerHandler replies with two A records for every query and sets the
+// authenticated-data flag for queries with the DNSSEC-OK bit
+func answerHandler(w dns.ResponseWriter, req *dns.Msg) {
+	var msg dns.Msg
+	msg.SetReply(req)
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2"} {
+		msg.Answer = append(msg.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP(ip),
+		})
+	}
+	if opt := req.IsEdns0(); opt != nil && opt.Do() {
+		msg.AuthenticatedData = true
+	}
+	_ = w.WriteMsg(&msg)
+}
+
+func TestExpectedAnswers(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(answerHandler)}
+	go server.ActivateAndServe() //nolint:errcheck // Ignore the returned error as the tests will fail anyway
+	defer server.Shutdown()      //nolint:errcheck // Ignore the returned error as the tests will fail anyway
+
+	_, port, err := net.SplitHostPort(conn.LocalAddr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		expected []string
+		result   string
+		code     uint64
+	}{
+		{
+			name:     "match",
+			expected: []string{"192.0.2.2", "192.0.2.1"},
+			result:   "success",
+			code:     uint64(Success),
+		},
+		{
+			name:     "missing answer",
+			expected: []string{"192.0.2.1"},
+			result:   "mismatch",
+			code:     uint64(Mismatch),
+		},
+		{
+			name:     "different answer",
+			expected: []string{"192.0.2.1", "192.0.2.3"},
+			result:   "mismatch",
+			code:     uint64(Mismatch),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := DNSQuery{
+				Servers:         []string{"127.0.0.1"},
+				Domains:         []string{"example.com"},
+				RecordType:      "A",
+				Port:            p,
+				Timeout:         config.Duration(2 * time.Second),
+				DNSSEC:          true,
+				ExpectedAnswers: tt.expected,
+			}
+			require.NoError(t, plugin.Init())
+
+			fields, tags, err := plugin.query("example.com", "127.0.0.1")
+			if tt.result == "success" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, "unexpected answer")
+			}
+			require.Equal(t, tt.result, tags["result"])
+			require.Equal(t, tt.code, fields["result_code"])
+			require.Equal(t, true, fields["authenticated_data"])
+			require.Equal(t, 0, fields["rrsig_count"])
+		})
+	}
+}
+
+func TestDoH(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var req dns.Msg
+		if err := req.Unpack(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var msg dns.Msg
+		msg.SetReply(&req)
+		msg.Answer = append(msg.Answer, &dns.MX{
+			Hdr:        dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeMX, Class: dns.ClassINET, Ttl: 60},
+			Preference: 10,
+			Mx:         "mail.example.com.",
+		})
+		buf, err := msg.Pack()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(buf)
+	}))
+	defer ts.Close()
+
+	plugin := DNSQuery{
+		Servers:         []string{ts.URL + "/dns-query"},
+		Domains:         []string{"example.com"},
+		Network:         "https",
+		RecordType:      "MX",
+		Timeout:         config.Duration(2 * time.Second),
+		ExpectedAnswers: []string{"10 MAIL.example.com"},
+	}
+	plugin.InsecureSkipVerify = true
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	m, ok := acc.Get("dns_query")
+	require.True(t, ok)
+	require.Equal(t, "success", m.Tags["result"])
+	require.Equal(t, "NOERROR", m.Tags["rcode"])
+	require.Equal(t, ts.URL+"/dns-query", m.Tags["server"])
+	require.Equal(t, uint64(Success), m.Fields["result_code"])
+}
@@ -0,0 +1,588 @@
+//go:build linux && (amd64 || arm64)
+
+package ebpf_network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/btf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/rlimit"
+)
+
+// Layout of the statistics shared with the eBPF programs
+const (
+	keySize   = 16 // process id (u32), padding (u32), cgroup id (u64)
+	valueSize = 40
+
+	offsetTxBytes     = 0
+	offsetRxBytes     = 8
+	offsetRetransmits = 16
+	offsetConnects    = 24
+	offsetLatency     = 32
+)
+
+// TCP states, protocol number and message flags as used by the kernel
+const (
+	tcpEstablished = 1
+	tcpSynSent     = 2
+	tcpClose       = 7
+	ipprotoTCP     = 6
+	msgPeek        = 2
+)
+
+// Flags of the map update helper
+const (
+	bpfAny     = 0
+	bpfNoExist = 1
+)
+
+// statsKey identifies the statistics of a process in a cgroup
+type statsKey struct {
+	PID    uint32
+	_      uint32
+	Cgroup uint64
+}
+
+// stats are the cumulative TCP statistics of a process
+type stats struct {
+	TxBytes        uint64
+	RxBytes        uint64
+	Retransmits    uint64
+	Connects       uint64
+	ConnectLatency uint64
+}
+
+// collector holds the maps and programs collecting the statistics in the
+// kernel. The programs are generated at runtime with the function arguments
+// and structure fields located using the BTF type information of the running
+// kernel, so they work across kernel versions and architectures without
+// compiling them for the target.
+type collector struct {
+	kernel *btf.Spec
+
+	stats  *ebpf.Map
+	owners *ebpf.Map
+	starts *ebpf.Map
+
+	programs []*ebpf.Program
+	links    []link.Link
+}
+
+func newCollector(maxProcesses, maxSockets uint32) (*collector, error) {
+	if err := rlimit.RemoveMemlock(); err != nil {
+		return nil, fmt.Errorf("removing memlock limit failed: %w", err)
+	}
+
+	kernel, err := btf.LoadKernelSpec()
+	if err != nil {
+		return nil, fmt.Errorf("loading kernel BTF failed: %w", err)
+	}
+
+	c := &collector{kernel: kernel}
+	if err := c.setup(maxProcesses, maxSockets); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *collector) setup(maxProcesses, maxSockets uint32) error {
+	var err error
+	c.stats, err = ebpf.NewMap(&ebpf.MapSpec{
+		Name:       "tcp_stats",
+		Type:       ebpf.LRUHash,
+		KeySize:    keySize,
+		ValueSize:  valueSize,
+		MaxEntries: maxProcesses,
+	})
+	if err != nil {
+		return fmt.Errorf("creating statistics map failed: %w", err)
+	}
+
+	// Owners of the sockets to attribute events not running in the context
+	// of the process, e.g. retransmits
+	c.owners, err = ebpf.NewMap(&ebpf.MapSpec{
+		Name:       "tcp_owners",
+		Type:       ebpf.LRUHash,
+		KeySize:    8,
+		ValueSize:  keySize,
+		MaxEntries: maxSockets,
+	})
+	if err != nil {
+		return fmt.Errorf("creating owner map failed: %w", err)
+	}
+
+	// Start times of pending connects
+	c.starts, err = ebpf.NewMap(&ebpf.MapSpec{
+		Name:       "tcp_starts",
+		Type:       ebpf.LRUHash,
+		KeySize:    8,
+		ValueSize:  8,
+		MaxEntries: maxSockets,
+	})
+	if err != nil {
+		return fmt.Errorf("creating start map failed: %w", err)
+	}
+
+	var sock *btf.Struct
+	if err := c.kernel.TypeByName("sock", &sock); err != nil {
+		return fmt.Errorf("looking up structure %q failed: %w", "sock", err)
+	}
+	protocol, err := structField(sock, "sk_protocol")
+	if err != nil {
+		return err
+	}
+
+	if err := c.attachThroughput("sock_send_length", "tcp_sendmsg", offsetTxBytes, protocol); err != nil {
+		return err
+	}
+	if err := c.attachThroughput("sock_recv_length", "tcp_recvmsg", offsetRxBytes, protocol); err != nil {
+		return err
+	}
+	if err := c.attachTracing("tcp_retransmit_skb", ebpf.AttachTraceRawTp, c.retransmitProgram()); err != nil {
+		return err
+	}
+	return c.attachTracing("inet_sock_set_state", ebpf.AttachTraceRawTp, c.stateProgram(protocol))
+}
+
+func (c *collector) close() {
+	for _, l := range c.links {
+		l.Close()
+	}
+	for _, p := range c.programs {
+		p.Close()
+	}
+	for _, m := range []*ebpf.Map{c.stats, c.owners, c.starts} {
+		if m != nil {
+			m.Close()
+		}
+	}
+}
+
+// read returns the statistics of all processes
+func (c *collector) read() (map[statsKey]stats, error) {
+	result := make(map[statsKey]stats)
+
+	var key, value []byte
+	iter := c.stats.Iterate()
+	for iter.Next(&key, &value) {
+		var k statsKey
+		if err := binary.Read(bytes.NewReader(key), binary.LittleEndian, &k); err != nil {
+			return nil, fmt.Errorf("decoding key failed: %w", err)
+		}
+		var v stats
+		if err := binary.Read(bytes.NewReader(value), binary.LittleEndian, &v); err != nil {
+			return nil, fmt.Errorf("decoding statistics failed: %w", err)
+		}
+		result[k] = v
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("reading statistics failed: %w", err)
+	}
+	return result, nil
+}
+
+// remove deletes the statistics of the given process
+func (c *collector) remove(k statsKey) error {
+	err := c.stats.Delete(&k)
+	if errors.Is(err, ebpf.ErrKeyNotExist) {
+		return nil
+	}
+	return err
+}
+
+// attachThroughput counts the bytes sent or received using the socket
+// tracepoint available since kernel v6.3 or when returning from the TCP
+// function on older kernels
+func (c *collector) attachThroughput(tracepoint, function string, field int32, protocol field) error {
+	var tp *btf.Typedef
+	err := c.kernel.TypeByName("btf_trace_"+tracepoint, &tp)
+	if err == nil {
+		// The arguments of the tracepoint are the socket, the size and the
+		// message flags, peeking at received data is ignored
+		insns := asm.Instructions{
+			asm.Mov.Reg(asm.R6, asm.R1),
+			asm.LoadMem(asm.R8, asm.R6, 0, asm.DWord),
+		}
+		insns = append(insns, loadField(asm.R2, asm.R8, -8, protocol)...)
+		insns = append(insns,
+			asm.JNE.Imm(asm.R2, ipprotoTCP, "exit"),
+			asm.LoadMem(asm.R2, asm.R6, 16, asm.DWord),
+			asm.And.Imm(asm.R2, msgPeek),
+			asm.JNE.Imm(asm.R2, 0, "exit"),
+			asm.LoadMem(asm.R7, asm.R6, 8, asm.DWord),
+		)
+		return c.attachTracing(tracepoint, ebpf.AttachTraceRawTp, c.throughputProgram(insns, field))
+	}
+	if !errors.Is(err, btf.ErrNotFound) {
+		return fmt.Errorf("looking up tracepoint %q failed: %w", tracepoint, err)
+	}
+
+	var fn *btf.Func
+	if err := c.kernel.TypeByName(function, &fn); err != nil {
+		return fmt.Errorf("looking up function %q failed: %w", function, err)
+	}
+	args, count, err := funcArgs(fn)
+	if err != nil {
+		return err
+	}
+	sk, found := args["sk"]
+	if !found {
+		return fmt.Errorf("argument %q missing for function %q", "sk", function)
+	}
+
+	// The arguments of the function are followed by the return value
+	// containing the size, the position of the arguments differs between
+	// kernel versions
+	insns := asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+	}
+	if flags, found := args["flags"]; found {
+		insns = append(insns,
+			asm.LoadMem(asm.R2, asm.R6, flags*8, asm.DWord),
+			asm.And.Imm(asm.R2, msgPeek),
+			asm.JNE.Imm(asm.R2, 0, "exit"),
+		)
+	}
+	insns = append(insns,
+		asm.LoadMem(asm.R7, asm.R6, count*8, asm.DWord),
+		asm.LoadMem(asm.R8, asm.R6, sk*8, asm.DWord),
+	)
+	return c.attachTracing(function, ebpf.AttachTraceFExit, c.throughputProgram(insns, field))
+}
+
+// attachTracing loads the program and attaches it to the kernel function or,
+// for raw tracepoints, to the tracepoint with the given name
+func (c *collector) attachTracing(name string, typ ebpf.AttachType, insns asm.Instructions) error {
+	prog, err := ebpf.NewProgramWithOptions(&ebpf.ProgramSpec{
+		Name:         name,
+		Type:         ebpf.Tracing,
+		AttachType:   typ,
+		AttachTo:     name,
+		Instructions: insns,
+		License:      "Dual MIT/GPL",
+	}, ebpf.ProgramOptions{KernelTypes: c.kernel})
+	if err != nil {
+		return fmt.Errorf("loading program for %q failed: %w", name, err)
+	}
+	c.programs = append(c.programs, prog)
+
+	l, err := link.AttachTracing(link.TracingOptions{Program: prog})
+	if err != nil {
+		return fmt.Errorf("attaching to %q failed: %w", name, err)
+	}
+	c.links = append(c.links, l)
+	return nil
+}
+
+// throughputProgram adds the size to the given field of the statistics of
+// the current process and records the process as owner of the socket. The
+// prologue must load the size as 32-bit integer into R7 and the socket into
+// R8.
+func (c *collector) throughputProgram(prologue asm.Instructions, field int32) asm.Instructions {
+	insns := append(prologue,
+		// The size is a signed integer with negative values for errors
+		asm.LSh.Imm(asm.R7, 32),
+		asm.ArSh.Imm(asm.R7, 32),
+		asm.JSLE.Imm(asm.R7, 0, "exit"),
+	)
+
+	// Owner key at fp-16 and socket at fp-24
+	insns = append(insns, currentKey(-16)...)
+	insns = append(insns,
+		asm.StoreMem(asm.RFP, -24, asm.R8, asm.DWord),
+		asm.LoadMapPtr(asm.R1, c.owners.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -24),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, bpfAny),
+		asm.FnMapUpdateElem.Call(),
+	)
+	insns = append(insns, c.addStats(-16, -64, "add", fieldAdd{field, asm.R7})...)
+	return append(insns, exit()...)
+}
+
+// retransmitProgram counts a retransmit for the owner of the socket passed
+// as first argument of the tracepoint
+func (c *collector) retransmitProgram() asm.Instructions {
+	insns := asm.Instructions{
+		asm.LoadMem(asm.R8, asm.R1, 0, asm.DWord),
+		asm.StoreMem(asm.RFP, -8, asm.R8, asm.DWord),
+	}
+	insns = append(insns, c.loadOwner(-8, -24)...)
+	insns = append(insns, asm.Mov.Imm(asm.R7, 1))
+	insns = append(insns, c.addStats(-24, -64, "add", fieldAdd{offsetRetransmits, asm.R7})...)
+	return append(insns, exit()...)
+}
+
+// stateProgram tracks the state changes of TCP sockets to measure the
+// latency of connects and to forget closed sockets. The arguments of the
+// tracepoint are the socket, the old and the new state.
+func (c *collector) stateProgram(protocol field) asm.Instructions {
+	insns := asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+		asm.LoadMem(asm.R8, asm.R6, 0, asm.DWord),
+		asm.StoreMem(asm.RFP, -8, asm.R8, asm.DWord),
+	}
+	insns = append(insns, loadField(asm.R2, asm.R8, -16, protocol)...)
+	insns = append(insns,
+		asm.JNE.Imm(asm.R2, ipprotoTCP, "exit"),
+		asm.LoadMem(asm.R9, asm.R6, 16, asm.DWord),
+		asm.JEq.Imm(asm.R9, tcpSynSent, "connect"),
+		asm.JEq.Imm(asm.R9, tcpClose, "close"),
+		asm.JNE.Imm(asm.R9, tcpEstablished, "exit"),
+		asm.LoadMem(asm.R2, asm.R6, 8, asm.DWord),
+		asm.JNE.Imm(asm.R2, tcpSynSent, "exit"),
+
+		// Connect finished, compute the latency from the start time
+		asm.LoadMapPtr(asm.R1, c.starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.LoadMem(asm.R7, asm.R0, 0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, c.starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapDeleteElem.Call(),
+		asm.FnKtimeGetNs.Call(),
+		asm.Sub.Reg(asm.R0, asm.R7),
+		asm.Mov.Reg(asm.R7, asm.R0),
+		asm.Mov.Imm(asm.R8, 1),
+	)
+	insns = append(insns, c.loadOwner(-8, -24)...)
+	insns = append(insns, c.addStats(-24, -64, "connected",
+		fieldAdd{offsetLatency, asm.R7},
+		fieldAdd{offsetConnects, asm.R8},
+	)...)
+	insns = append(insns, asm.Ja.Label("exit"))
+
+	// Connect started in the context of the process, remember the start
+	// time and the owner of the socket
+	insns = append(insns,
+		asm.FnKtimeGetNs.Call().WithSymbol("connect"),
+		asm.StoreMem(asm.RFP, -16, asm.R0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, c.starts.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, bpfAny),
+		asm.FnMapUpdateElem.Call(),
+	)
+	insns = append(insns, currentKey(-32)...)
+	insns = append(insns,
+		asm.LoadMapPtr(asm.R1, c.owners.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -32),
+		asm.Mov.Imm(asm.R4, bpfAny),
+		asm.FnMapUpdateElem.Call(),
+		asm.Ja.Label("exit"),
+	)
+
+	// Socket closed, forget about it
+	insns = append(insns,
+		asm.LoadMapPtr(asm.R1, c.starts.FD()).WithSymbol("close"),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapDeleteElem.Call(),
+		asm.LoadMapPtr(asm.R1, c.owners.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -8),
+		asm.FnMapDeleteElem.Call(),
+	)
+	return append(insns, exit()...)
+}
+
+// currentKey stores the statistics key of the current process on the stack
+// at the given offset
+func currentKey(offset int16) asm.Instructions {
+	return asm.Instructions{
+		asm.FnGetCurrentPidTgid.Call(),
+		asm.RSh.Imm(asm.R0, 32),
+		asm.StoreMem(asm.RFP, offset, asm.R0, asm.Word),
+		asm.StoreImm(asm.RFP, offset+4, 0, asm.Word),
+		asm.FnGetCurrentCgroupId.Call(),
+		asm.StoreMem(asm.RFP, offset+8, asm.R0, asm.DWord),
+	}
+}
+
+// loadOwner copies the owner of the socket stored on the stack at the
+// socket offset to the key offset, exiting if the owner is unknown
+func (c *collector) loadOwner(socketOffset, keyOffset int16) asm.Instructions {
+	return asm.Instructions{
+		asm.LoadMapPtr(asm.R1, c.owners.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, int32(socketOffset)),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.LoadMem(asm.R1, asm.R0, 0, asm.DWord),
+		asm.StoreMem(asm.RFP, keyOffset, asm.R1, asm.DWord),
+		asm.LoadMem(asm.R1, asm.R0, 8, asm.DWord),
+		asm.StoreMem(asm.RFP, keyOffset+8, asm.R1, asm.DWord),
+	}
+}
+
+// fieldAdd adds the value of the register to the field at the offset
+type fieldAdd struct {
+	offset int32
+	value  asm.Register
+}
+
+// addStats atomically adds the values to the statistics of the key stored
+// on the stack, creating the statistics with the zero value stored on the
+// stack if necessary. The registers R6 to R8 are preserved for the values.
+func (c *collector) addStats(keyOffset, zeroOffset int16, label string, adds ...fieldAdd) asm.Instructions {
+	insns := asm.Instructions{
+		asm.LoadMapPtr(asm.R1, c.stats.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, int32(keyOffset)),
+		asm.FnMapLookupElem.Call(),
+		asm.JNE.Imm(asm.R0, 0, label),
+	}
+	for i := int16(0); i < valueSize; i += 8 {
+		insns = append(insns, asm.StoreImm(asm.RFP, zeroOffset+i, 0, asm.DWord))
+	}
+	insns = append(insns,
+		asm.LoadMapPtr(asm.R1, c.stats.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, int32(keyOffset)),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, int32(zeroOffset)),
+		asm.Mov.Imm(asm.R4, bpfNoExist),
+		asm.FnMapUpdateElem.Call(),
+		asm.LoadMapPtr(asm.R1, c.stats.FD()),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, int32(keyOffset)),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.Mov.Reg(asm.R9, asm.R0).WithSymbol(label),
+	)
+	for _, add := range adds {
+		insns = append(insns,
+			asm.Mov.Reg(asm.R1, asm.R9),
+			asm.Add.Imm(asm.R1, add.offset),
+			asm.StoreXAdd(asm.R1, add.value, asm.DWord),
+		)
+	}
+	return insns
+}
+
+func exit() asm.Instructions {
+	return asm.Instructions{
+		asm.Mov.Imm(asm.R0, 0).WithSymbol("exit"),
+		asm.Return(),
+	}
+}
+
+// field describes how to load a structure field of the given size in bytes
+// at the byte offset. Bitfields with the given number of bits are extracted
+// from the loaded word by shifting out the surrounding bits.
+type field struct {
+	offset int32
+	size   int32
+	shift  int32
+	bits   int32
+}
+
+// structField locates the field with the given name in the structure,
+// including fields of anonymous nested structures and unions
+func structField(s *btf.Struct, name string) (field, error) {
+	offset, member, found := findMember(s.Members, name)
+	if !found {
+		return field{}, fmt.Errorf("field %q missing in structure %q", name, s.Name)
+	}
+
+	if member.BitfieldSize > 0 {
+		// Load the aligned 64-bit word containing the bits (little endian)
+		start := offset / 64 * 64
+		if offset+member.BitfieldSize > start+64 {
+			return field{}, fmt.Errorf("bitfield %q crosses a 64-bit boundary", name)
+		}
+		return field{
+			offset: int32(start / 8),
+			size:   8,
+			shift:  int32(offset - start),
+			bits:   int32(member.BitfieldSize),
+		}, nil
+	}
+
+	size, err := btf.Sizeof(member.Type)
+	if err != nil {
+		return field{}, fmt.Errorf("determining size of field %q failed: %w", name, err)
+	}
+	if offset%8 != 0 || size > 8 {
+		return field{}, fmt.Errorf("field %q is not a scalar", name)
+	}
+	return field{offset: int32(offset / 8), size: int32(size)}, nil
+}
+
+// findMember returns the member with the given name and its offset in bits
+func findMember(members []btf.Member, name string) (btf.Bits, btf.Member, bool) {
+	for _, member := range members {
+		if member.Name == name {
+			return member.Offset, member, true
+		}
+		if member.Name != "" {
+			continue
+		}
+
+		var nested []btf.Member
+		switch t := member.Type.(type) {
+		case *btf.Struct:
+			nested = t.Members
+		case *btf.Union:
+			nested = t.Members
+		}
+		if offset, m, found := findMember(nested, name); found {
+			return member.Offset + offset, m, true
+		}
+	}
+	return 0, btf.Member{}, false
+}
+
+// loadField loads the field of the structure pointed to by the register
+// into the destination register using the stack slot at the given offset
+func loadField(dst, ptr asm.Register, offset int16, f field) asm.Instructions {
+	insns := asm.Instructions{
+		asm.StoreImm(asm.RFP, offset, 0, asm.DWord),
+		asm.Mov.Reg(asm.R1, asm.RFP),
+		asm.Add.Imm(asm.R1, int32(offset)),
+		asm.Mov.Imm(asm.R2, f.size),
+		asm.Mov.Reg(asm.R3, ptr),
+		asm.Add.Imm(asm.R3, f.offset),
+		asm.FnProbeReadKernel.Call(),
+		asm.LoadMem(dst, asm.RFP, offset, asm.DWord),
+	}
+	if f.bits > 0 {
+		insns = append(insns,
+			asm.LSh.Imm(dst, 64-f.bits-f.shift),
+			asm.RSh.Imm(dst, 64-f.bits),
+		)
+	}
+	return insns
+}
+
+// funcArgs returns the indices of the arguments of the kernel function by
+// their names and the number of arguments
+func funcArgs(fn *btf.Func) (map[string]int16, int16, error) {
+	proto, ok := fn.Type.(*btf.FuncProto)
+	if !ok {
+		return nil, 0, fmt.Errorf("function %q has no prototype", fn.Name)
+	}
+
+	args := make(map[string]int16, len(proto.Params))
+	for i, param := range proto.Params {
+		args[param.Name] = int16(i)
+	}
+	return args, int16(len(proto.Params)), nil
+}
@@ -0,0 +1,156 @@
+//go:build linux && (amd64 || arm64)
+
+package ebpf_network
+
+import (
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf/btf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructField(t *testing.T) {
+	u8 := &btf.Int{Name: "u8", Size: 1}
+	u16 := &btf.Int{Name: "u16", Size: 2}
+	u32 := &btf.Int{Name: "u32", Size: 4}
+	common := &btf.Struct{
+		Name: "sock_common",
+		Size: 8,
+		Members: []btf.Member{
+			{Name: "skc_family", Type: u16, Offset: 0},
+			{Name: "skc_state", Type: u8, Offset: 16},
+		},
+	}
+	sock := &btf.Struct{
+		Name: "sock",
+		Size: 32,
+		Members: []btf.Member{
+			{Name: "__sk_common", Type: common, Offset: 0},
+			{
+				Type: &btf.Union{
+					Size: 4,
+					Members: []btf.Member{
+						{Name: "sk_flags", Type: u32},
+						{Type: &btf.Struct{
+							Size: 4,
+							Members: []btf.Member{
+								{Name: "sk_type", Type: u16, Offset: 16},
+							},
+						}},
+					},
+				},
+				Offset: 64,
+			},
+			{Name: "sk_protocol", Type: u16, Offset: 96},
+			{Name: "sk_padding", Type: u32, Offset: 128, BitfieldSize: 1},
+			{Name: "sk_kern_sock", Type: u32, Offset: 129, BitfieldSize: 1},
+			{Name: "sk_shutdown", Type: u32, Offset: 136, BitfieldSize: 8},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		expected field
+	}{
+		{name: "sk_protocol", expected: field{offset: 12, size: 2}},
+		{name: "sk_flags", expected: field{offset: 8, size: 4}},
+		{name: "sk_type", expected: field{offset: 10, size: 2}},
+		{name: "sk_kern_sock", expected: field{offset: 16, size: 8, shift: 1, bits: 1}},
+		{name: "sk_shutdown", expected: field{offset: 16, size: 8, shift: 8, bits: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := structField(sock, tt.name)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	// Fields of named nested structures are not part of the structure
+	_, err := structField(sock, "skc_family")
+	require.ErrorContains(t, err, `field "skc_family" missing in structure "sock"`)
+}
+
+func TestFuncArgs(t *testing.T) {
+	ptr := &btf.Pointer{Target: &btf.Void{}}
+	integer := &btf.Int{Name: "int", Size: 4, Encoding: btf.Signed}
+	fn := &btf.Func{
+		Name: "tcp_recvmsg",
+		Type: &btf.FuncProto{
+			Return: integer,
+			Params: []btf.FuncParam{
+				{Name: "sk", Type: ptr},
+				{Name: "msg", Type: ptr},
+				{Name: "len", Type: integer},
+				{Name: "flags", Type: integer},
+				{Name: "addr_len", Type: ptr},
+			},
+		},
+	}
+
+	args, count, err := funcArgs(fn)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int16{"sk": 0, "msg": 1, "len": 2, "flags": 3, "addr_len": 4}, args)
+	require.Equal(t, int16(5), count)
+
+	_, _, err = funcArgs(&btf.Func{Name: "broken", Type: integer})
+	require.ErrorContains(t, err, `function "broken" has no prototype`)
+}
+
+func TestCollector(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Skipping test as loading eBPF programs requires root")
+	}
+
+	c, err := newCollector(1024, 1024)
+	if err != nil {
+		t.Skipf("Skipping test as eBPF is not supported: %v", err)
+	}
+	defer c.close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write(make([]byte, 12345))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	<-done
+
+	// Sending and receiving happens in this test process
+	var self stats
+	require.Eventually(t, func() bool {
+		entries, err := c.read()
+		require.NoError(t, err)
+		self = stats{}
+		for k, v := range entries {
+			if k.PID == uint32(os.Getpid()) {
+				self.TxBytes += v.TxBytes
+				self.RxBytes += v.RxBytes
+				self.Connects += v.Connects
+				self.ConnectLatency += v.ConnectLatency
+			}
+		}
+		return self.RxBytes >= 12345
+	}, 5*time.Second, 100*time.Millisecond)
+	require.GreaterOrEqual(t, self.TxBytes, uint64(12345))
+	require.GreaterOrEqual(t, self.Connects, uint64(1))
+	require.Positive(t, self.ConnectLatency)
+}
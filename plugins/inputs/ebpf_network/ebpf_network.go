@@ -0,0 +1,176 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux && (amd64 || arm64)
+
+package ebpf_network
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Container IDs of Docker, containerd, CRI-O and Podman as part of the
+// cgroup path, e.g. "docker-<id>.scope" or "/kubepods/.../<id>"
+var containerIDRe = regexp.MustCompile(`(?:^|[/\-:])([0-9a-f]{64})(?:\.scope)?$`)
+
+type EBPFNetwork struct {
+	MaxProcesses uint32          `toml:"max_processes"`
+	MaxSockets   uint32          `toml:"max_sockets"`
+	CgroupRoot   string          `toml:"cgroup_root"`
+	ProcRoot     string          `toml:"proc_root"`
+	Log          telegraf.Logger `toml:"-"`
+
+	collector *collector
+	cgroups   map[uint64]string
+}
+
+func (*EBPFNetwork) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EBPFNetwork) Init() error {
+	if e.MaxProcesses == 0 {
+		e.MaxProcesses = 4096
+	}
+	if e.MaxSockets == 0 {
+		e.MaxSockets = 65536
+	}
+	if e.CgroupRoot == "" {
+		e.CgroupRoot = "/sys/fs/cgroup"
+	}
+	if e.ProcRoot == "" {
+		e.ProcRoot = "/proc"
+	}
+	e.cgroups = make(map[uint64]string)
+
+	return nil
+}
+
+func (e *EBPFNetwork) Start(_ telegraf.Accumulator) error {
+	c, err := newCollector(e.MaxProcesses, e.MaxSockets)
+	if err != nil {
+		return fmt.Errorf("loading eBPF programs failed: %w", err)
+	}
+	e.collector = c
+	return nil
+}
+
+func (e *EBPFNetwork) Gather(acc telegraf.Accumulator) error {
+	entries, err := e.collector.read()
+	if err != nil {
+		return err
+	}
+
+	for key, value := range entries {
+		tags := map[string]string{
+			"pid": strconv.FormatUint(uint64(key.PID), 10),
+		}
+
+		// Report the final statistics of exited processes once and free their
+		// entries for new processes
+		name, err := os.ReadFile(filepath.Join(e.ProcRoot, strconv.FormatUint(uint64(key.PID), 10), "comm"))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				acc.AddError(fmt.Errorf("reading name of process %d failed: %w", key.PID, err))
+			}
+			if err := e.collector.remove(key); err != nil {
+				acc.AddError(fmt.Errorf("removing statistics of process %d failed: %w", key.PID, err))
+			}
+		} else {
+			tags["process_name"] = strings.TrimSpace(string(name))
+		}
+
+		if path, found := e.cgroup(key.Cgroup); found {
+			tags["cgroup"] = path
+			if id := containerID(path); id != "" {
+				tags["container_id"] = id
+			}
+		}
+
+		fields := map[string]interface{}{
+			"tx_bytes":           value.TxBytes,
+			"rx_bytes":           value.RxBytes,
+			"retransmits":        value.Retransmits,
+			"connects":           value.Connects,
+			"connect_latency_ns": value.ConnectLatency,
+		}
+		acc.AddCounter("ebpf_network", fields, tags)
+	}
+
+	return nil
+}
+
+func (e *EBPFNetwork) Stop() {
+	if e.collector != nil {
+		e.collector.close()
+		e.collector = nil
+	}
+}
+
+// cgroup returns the path of the cgroup with the given ID relative to the
+// cgroup root. The ID of a cgroup v2 is the inode number of its directory,
+// so the hierarchy is scanned again if the ID is unknown. IDs are never
+// reused, so unknown IDs are remembered to avoid scanning again.
+func (e *EBPFNetwork) cgroup(id uint64) (string, bool) {
+	if path, found := e.cgroups[id]; found {
+		return path, path != ""
+	}
+
+	err := filepath.WalkDir(e.CgroupRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			// Cgroups might vanish while walking the hierarchy
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(e.CgroupRoot, path)
+		if err != nil {
+			return nil
+		}
+		e.cgroups[stat.Ino] = filepath.Join("/", rel)
+		return nil
+	})
+	if err != nil {
+		e.Log.Debugf("Scanning cgroups failed: %v", err)
+	}
+
+	path, found := e.cgroups[id]
+	if !found {
+		e.cgroups[id] = ""
+	}
+	return path, found
+}
+
+// containerID extracts the ID of the container from the cgroup path
+func containerID(path string) string {
+	match := containerIDRe.FindStringSubmatch(path)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+func init() {
+	inputs.Add("ebpf_network", func() telegraf.Input {
+		return &EBPFNetwork{}
+	})
+}
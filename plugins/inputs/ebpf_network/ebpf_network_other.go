@@ -0,0 +1,31 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux || !(amd64 || arm64)
+
+package ebpf_network
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type EBPFNetwork struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (e *EBPFNetwork) Init() error {
+	e.Log.Warn("current platform is not supported")
+	return nil
+}
+func (*EBPFNetwork) SampleConfig() string                { return sampleConfig }
+func (*EBPFNetwork) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("ebpf_network", func() telegraf.Input {
+		return &EBPFNetwork{}
+	})
+}
@@ -0,0 +1,112 @@
+//go:build linux && (amd64 || arm64)
+
+package ebpf_network
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestContainerID(t *testing.T) {
+	id := "4c01db0b339c7b4d5f0a7e6e5d0c2e8f4a1b1c9e1a6d4e1c1f0e8b7a6d5c4b3a"
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "docker systemd",
+			path:     "/system.slice/docker-" + id + ".scope",
+			expected: id,
+		},
+		{
+			name:     "docker cgroupfs",
+			path:     "/docker/" + id,
+			expected: id,
+		},
+		{
+			name:     "containerd",
+			path:     "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1234.slice/cri-containerd-" + id + ".scope",
+			expected: id,
+		},
+		{
+			name:     "cri-o",
+			path:     "/kubepods/burstable/pod1234/crio-" + id + ".scope",
+			expected: id,
+		},
+		{
+			name: "no container",
+			path: "/user.slice/user-1000.slice/session-2.scope",
+		},
+		{
+			name: "root",
+			path: "/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, containerID(tt.path))
+		})
+	}
+}
+
+func TestCgroup(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "system.slice", "nginx.service"), 0750))
+
+	plugin := &EBPFNetwork{
+		CgroupRoot: root,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var stat syscall.Stat_t
+	require.NoError(t, syscall.Stat(filepath.Join(root, "system.slice", "nginx.service"), &stat))
+	path, found := plugin.cgroup(stat.Ino)
+	require.True(t, found)
+	require.Equal(t, "/system.slice/nginx.service", path)
+
+	// Cgroups created later are found by scanning again
+	require.NoError(t, os.Mkdir(filepath.Join(root, "user.slice"), 0750))
+	require.NoError(t, syscall.Stat(filepath.Join(root, "user.slice"), &stat))
+	path, found = plugin.cgroup(stat.Ino)
+	require.True(t, found)
+	require.Equal(t, "/user.slice", path)
+
+	// Unknown cgroups are remembered
+	_, found = plugin.cgroup(0)
+	require.False(t, found)
+	require.Contains(t, plugin.cgroups, uint64(0))
+}
+
+func TestGather(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Skipping test as loading eBPF programs requires root")
+	}
+
+	plugin := &EBPFNetwork{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	if err := plugin.Start(&acc); err != nil {
+		t.Skipf("Skipping test as eBPF is not supported: %v", err)
+	}
+	defer plugin.Stop()
+
+	// Exited processes are reported and removed
+	exited := statsKey{PID: 1 << 30}
+	require.NoError(t, plugin.collector.stats.Put(&exited, &stats{TxBytes: 42, Connects: 1}))
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.True(t, acc.HasPoint("ebpf_network", map[string]string{"pid": "1073741824"}, "tx_bytes", uint64(42)))
+
+	entries, err := plugin.collector.read()
+	require.NoError(t, err)
+	require.NotContains(t, entries, exited)
+}
@@ -0,0 +1,296 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package etcd
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Etcd struct {
+	Endpoints []string        `toml:"endpoints"`
+	Username  config.Secret   `toml:"username"`
+	Password  config.Secret   `toml:"password"`
+	Timeout   config.Duration `toml:"timeout"`
+	Log       telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+// The responses of the etcd v3 gRPC JSON gateway encode 64-bit integers as
+// strings and omit fields with default values
+type statusResponse struct {
+	Header           responseHeader `json:"header"`
+	Version          string         `json:"version"`
+	DBSize           int64          `json:"dbSize,string"`
+	DBSizeInUse      int64          `json:"dbSizeInUse,string"`
+	Leader           uint64         `json:"leader,string"`
+	RaftIndex        uint64         `json:"raftIndex,string"`
+	RaftTerm         uint64         `json:"raftTerm,string"`
+	RaftAppliedIndex uint64         `json:"raftAppliedIndex,string"`
+	Errors           []string       `json:"errors"`
+	IsLearner        bool           `json:"isLearner"`
+}
+
+type responseHeader struct {
+	ClusterID uint64 `json:"cluster_id,string"`
+	MemberID  uint64 `json:"member_id,string"`
+	Revision  int64  `json:"revision,string"`
+}
+
+type healthResponse struct {
+	Health string `json:"health"`
+	Reason string `json:"reason"`
+}
+
+type alarmResponse struct {
+	Alarms []struct {
+		MemberID uint64 `json:"memberID,string"`
+		Alarm    string `json:"alarm"`
+	} `json:"alarms"`
+}
+
+type leasesResponse struct {
+	Leases []struct {
+		ID int64 `json:"ID,string"`
+	} `json:"leases"`
+}
+
+type memberListResponse struct {
+	Members []struct {
+		ID        uint64 `json:"ID,string"`
+		IsLearner bool   `json:"isLearner"`
+	} `json:"members"`
+}
+
+type authResponse struct {
+	Token string `json:"token"`
+}
+
+func (*Etcd) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Etcd) Init() error {
+	if len(e.Endpoints) == 0 {
+		e.Endpoints = []string{"http://127.0.0.1:2379"}
+	}
+	for i, endpoint := range e.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("parsing endpoint %q failed: %w", endpoint, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid scheme %q of endpoint %q", u.Scheme, endpoint)
+		}
+		e.Endpoints[i] = strings.TrimSuffix(endpoint, "/")
+	}
+
+	if e.Username.Empty() != e.Password.Empty() {
+		return errors.New("username and password must be set together")
+	}
+
+	tlsCfg, err := e.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	e.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: time.Duration(e.Timeout),
+	}
+
+	return nil
+}
+
+func (e *Etcd) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, endpoint := range e.Endpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			if err := e.gatherEndpoint(acc, endpoint); err != nil {
+				acc.AddError(fmt.Errorf("endpoint %q: %w", endpoint, err))
+			}
+		}(endpoint)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (e *Etcd) gatherEndpoint(acc telegraf.Accumulator, endpoint string) error {
+	token, err := e.authenticate(endpoint)
+	if err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	// The health endpoint reports unhealthy members with a non-OK status code
+	// so do not treat this as an error
+	var health healthResponse
+	healthy := e.request(http.MethodGet, endpoint+"/health", "", nil, &health) == nil && health.Health == "true"
+
+	var status statusResponse
+	if err := e.request(http.MethodPost, endpoint+"/v3/maintenance/status", token, struct{}{}, &status); err != nil {
+		acc.AddFields("etcd", map[string]interface{}{"healthy": false}, map[string]string{"endpoint": endpoint})
+		return fmt.Errorf("getting status failed: %w", err)
+	}
+
+	tags := map[string]string{
+		"endpoint":   endpoint,
+		"cluster_id": strconv.FormatUint(status.Header.ClusterID, 16),
+		"member_id":  strconv.FormatUint(status.Header.MemberID, 16),
+		"version":    status.Version,
+	}
+	fields := map[string]interface{}{
+		"healthy":              healthy,
+		"is_leader":            status.Leader != 0 && status.Leader == status.Header.MemberID,
+		"has_leader":           status.Leader != 0,
+		"is_learner":           status.IsLearner,
+		"db_size_bytes":        status.DBSize,
+		"db_size_in_use_bytes": status.DBSizeInUse,
+		"revision":             status.Header.Revision,
+		"raft_term":            status.RaftTerm,
+		"raft_index":           status.RaftIndex,
+		"raft_applied_index":   status.RaftAppliedIndex,
+		"errors":               len(status.Errors),
+	}
+	if status.RaftIndex >= status.RaftAppliedIndex {
+		fields["raft_pending_entries"] = status.RaftIndex - status.RaftAppliedIndex
+	}
+
+	var alarms alarmResponse
+	if err := e.request(http.MethodPost, endpoint+"/v3/maintenance/alarm", token, map[string]string{"action": "GET"}, &alarms); err != nil {
+		acc.AddError(fmt.Errorf("endpoint %q: getting alarms failed: %w", endpoint, err))
+	} else {
+		fields["alarm_nospace"] = false
+		fields["alarm_corrupt"] = false
+		for _, a := range alarms.Alarms {
+			if a.MemberID != status.Header.MemberID {
+				continue
+			}
+			switch a.Alarm {
+			case "NOSPACE":
+				fields["alarm_nospace"] = true
+			case "CORRUPT":
+				fields["alarm_corrupt"] = true
+			}
+		}
+	}
+
+	var leases leasesResponse
+	if err := e.request(http.MethodPost, endpoint+"/v3/lease/leases", token, struct{}{}, &leases); err != nil {
+		acc.AddError(fmt.Errorf("endpoint %q: getting leases failed: %w", endpoint, err))
+	} else {
+		fields["leases"] = len(leases.Leases)
+	}
+
+	var members memberListResponse
+	if err := e.request(http.MethodPost, endpoint+"/v3/cluster/member/list", token, struct{}{}, &members); err != nil {
+		acc.AddError(fmt.Errorf("endpoint %q: getting members failed: %w", endpoint, err))
+	} else {
+		var learners int
+		for _, m := range members.Members {
+			if m.IsLearner {
+				learners++
+			}
+		}
+		fields["members"] = len(members.Members)
+		fields["learners"] = learners
+	}
+
+	acc.AddFields("etcd", fields, tags)
+
+	return nil
+}
+
+// authenticate requests a new token if credentials are configured. Tokens
+// expire after a server-defined TTL, so they are not reused across gathers.
+func (e *Etcd) authenticate(endpoint string) (string, error) {
+	if e.Username.Empty() {
+		return "", nil
+	}
+
+	username, err := e.Username.Get()
+	if err != nil {
+		return "", fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := e.Password.Get()
+	if err != nil {
+		return "", fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	body := map[string]string{
+		"name":     username.String(),
+		"password": password.String(),
+	}
+	var resp authResponse
+	if err := e.request(http.MethodPost, endpoint+"/v3/auth/authenticate", "", body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+func (e *Etcd) request(method, address, token string, body, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(method, address, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func init() {
+	inputs.Add("etcd", func() telegraf.Input {
+		return &Etcd{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package etcd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const (
+	statusResp = `{
+  "header": {"cluster_id": "14841639068965178418", "member_id": "10276657743932975437", "revision": "12", "raft_term": "2"},
+  "version": "3.5.9",
+  "dbSize": "20480",
+  "leader": "10276657743932975437",
+  "raftIndex": "50",
+  "raftTerm": "2",
+  "raftAppliedIndex": "48",
+  "dbSizeInUse": "16384"
+}`
+	alarmResp   = `{"header": {}, "alarms": [{"memberID": "10276657743932975437", "alarm": "NOSPACE"}, {"memberID": "1", "alarm": "CORRUPT"}]}`
+	leasesResp  = `{"header": {}, "leases": [{"ID": "7587873409442353675"}, {"ID": "7587873409442353676"}]}`
+	membersResp = `{"header": {}, "members": [{"ID": "10276657743932975437", "name": "a"}, {"ID": "1", "name": "b"}, {"ID": "2", "name": "c", "isLearner": true}]}`
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Etcd
+		expected string
+	}{
+		{
+			name:     "invalid scheme",
+			plugin:   &Etcd{Endpoints: []string{"unix:///var/run/etcd.sock"}},
+			expected: `invalid scheme "unix"`,
+		},
+		{
+			name: "username without password",
+			plugin: &Etcd{
+				Endpoints: []string{"http://127.0.0.1:2379"},
+				Username:  config.NewSecret([]byte("root")),
+			},
+			expected: "username and password must be set together",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	responses := map[string]string{
+		"/health":                 `{"health": "true", "reason": ""}`,
+		"/v3/maintenance/status":  statusResp,
+		"/v3/maintenance/alarm":   alarmResp,
+		"/v3/lease/leases":        leasesResp,
+		"/v3/cluster/member/list": membersResp,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			var req map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["name"] != "root" || req["password"] != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token": "abc.123"}`))
+			return
+		case "/health":
+		default:
+			if r.Header.Get("Authorization") != "abc.123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		resp, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	defer server.Close()
+
+	plugin := &Etcd{
+		Endpoints: []string{server.URL},
+		Username:  config.NewSecret([]byte("root")),
+		Password:  config.NewSecret([]byte("secret")),
+		Timeout:   config.Duration(5 * time.Second),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"etcd",
+			map[string]string{
+				"endpoint":   server.URL,
+				"cluster_id": "cdf818194e3a8c32",
+				"member_id":  "8e9e05c52164694d",
+				"version":    "3.5.9",
+			},
+			map[string]interface{}{
+				"healthy":              true,
+				"is_leader":            true,
+				"has_leader":           true,
+				"is_learner":           false,
+				"db_size_bytes":        int64(20480),
+				"db_size_in_use_bytes": int64(16384),
+				"revision":             int64(12),
+				"raft_term":            uint64(2),
+				"raft_index":           uint64(50),
+				"raft_applied_index":   uint64(48),
+				"raft_pending_entries": uint64(2),
+				"errors":               0,
+				"alarm_nospace":        true,
+				"alarm_corrupt":        false,
+				"leases":               2,
+				"members":              3,
+				"learners":             1,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	plugin := &Etcd{
+		Endpoints: []string{server.URL},
+		Timeout:   config.Duration(5 * time.Second),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"etcd",
+			map[string]string{"endpoint": server.URL},
+			map[string]interface{}{"healthy": false},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
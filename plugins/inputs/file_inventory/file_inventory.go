@@ -0,0 +1,442 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package file_inventory
+
+import (
+	"crypto/sha1" //nolint:gosec // G505: sha1 is offered for compatibility with existing checksum databases
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type FileInventory struct {
+	Paths          []string          `toml:"paths"`
+	Exclude        []string          `toml:"exclude"`
+	FollowSymlinks bool              `toml:"follow_symlinks"`
+	MaxDepth       int               `toml:"max_depth"`
+	AgeBuckets     []config.Duration `toml:"age_buckets"`
+	Watch          []string          `toml:"watch"`
+	HashAlgorithm  string            `toml:"hash_algorithm"`
+	MaxHashSize    config.Size       `toml:"max_hash_size"`
+	Log            telegraf.Logger   `toml:"-"`
+
+	paths   []*globpath.GlobPath
+	exclude []*globpath.GlobPath
+	watch   []*globpath.GlobPath
+	newHash func() hash.Hash
+
+	// known holds the state of the watched files of the last gather cycle
+	// and baseline is set once the state is known to detect changes
+	known    map[string]fileState
+	baseline bool
+}
+
+type fileState struct {
+	Root    string `json:"root"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	Hash    string `json:"hash,omitempty"`
+}
+
+// rootStats holds the inventory of a single root path
+type rootStats struct {
+	files       int64
+	directories int64
+	size        int64
+	largest     int64
+	oldest      time.Time
+	newest      time.Time
+	watched     int64
+	ageCount    []int64
+	ageSize     []int64
+}
+
+func (*FileInventory) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *FileInventory) Init() error {
+	if len(f.Paths) == 0 {
+		return errors.New("no paths configured")
+	}
+
+	for _, p := range f.Paths {
+		g, err := globpath.Compile(p)
+		if err != nil {
+			return fmt.Errorf("compiling path %q failed: %w", p, err)
+		}
+		f.paths = append(f.paths, g)
+	}
+	for _, p := range f.Exclude {
+		g, err := globpath.Compile(p)
+		if err != nil {
+			return fmt.Errorf("compiling exclude pattern %q failed: %w", p, err)
+		}
+		f.exclude = append(f.exclude, g)
+	}
+	for _, p := range f.Watch {
+		g, err := globpath.Compile(p)
+		if err != nil {
+			return fmt.Errorf("compiling watch pattern %q failed: %w", p, err)
+		}
+		f.watch = append(f.watch, g)
+	}
+
+	if !sort.SliceIsSorted(f.AgeBuckets, func(i, j int) bool { return f.AgeBuckets[i] < f.AgeBuckets[j] }) {
+		return errors.New("age buckets must be sorted in ascending order")
+	}
+	for _, b := range f.AgeBuckets {
+		if b <= 0 {
+			return errors.New("age buckets must be positive")
+		}
+	}
+
+	switch f.HashAlgorithm {
+	case "", "sha256":
+		f.newHash = sha256.New
+	case "sha512":
+		f.newHash = sha512.New
+	case "sha1":
+		f.newHash = sha1.New
+	default:
+		return fmt.Errorf("unknown hash algorithm %q", f.HashAlgorithm)
+	}
+	if f.MaxHashSize < 0 {
+		return errors.New("max hash size must not be negative")
+	}
+
+	f.known = make(map[string]fileState)
+
+	return nil
+}
+
+func (f *FileInventory) GetState() interface{} {
+	return f.known
+}
+
+func (f *FileInventory) SetState(state interface{}) error {
+	known, ok := state.(map[string]fileState)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	f.known = known
+	f.baseline = true
+	return nil
+}
+
+func (f *FileInventory) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+	current := make(map[string]fileState, len(f.known))
+	var failed []string
+
+	for _, g := range f.paths {
+		for _, root := range g.Match() {
+			stats, err := f.walk(acc, root, now, current)
+			if err != nil {
+				acc.AddError(fmt.Errorf("walking %q failed: %w", root, err))
+				failed = append(failed, root)
+				continue
+			}
+			f.addStats(acc, root, stats, now)
+		}
+	}
+
+	// Report watched files that vanished. Files below roots that could not
+	// be walked keep their state to not report them as deleted.
+	for name, prev := range f.known {
+		if _, found := current[name]; found {
+			continue
+		}
+		if slices.Contains(failed, prev.Root) {
+			current[name] = prev
+			continue
+		}
+		if f.baseline {
+			f.addChange(acc, name, "deleted", prev, fileState{}, now)
+		}
+	}
+
+	f.known = current
+	f.baseline = true
+
+	return nil
+}
+
+func (f *FileInventory) walk(acc telegraf.Accumulator, root string, now time.Time, current map[string]fileState) (*rootStats, error) {
+	stats := &rootStats{
+		ageCount: make([]int64, len(f.AgeBuckets)),
+		ageSize:  make([]int64, len(f.AgeBuckets)),
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		f.addFile(acc, root, root, info, stats, now, current)
+		return stats, nil
+	}
+
+	visited := make(map[string]bool)
+	err = f.walkDir(acc, root, root, 0, visited, stats, now, current)
+	return stats, err
+}
+
+func (f *FileInventory) walkDir(
+	acc telegraf.Accumulator,
+	root, dir string,
+	depth int,
+	visited map[string]bool,
+	stats *rootStats,
+	now time.Time,
+	current map[string]fileState,
+) error {
+	// Protect against symlink loops
+	if f.FollowSymlinks {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return err
+		}
+		if visited[real] {
+			return nil
+		}
+		visited[real] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// Only fail for the root itself, unreadable sub-directories are
+		// logged and skipped
+		if dir == root {
+			return err
+		}
+		f.Log.Warnf("Reading directory %q failed: %v", dir, err)
+		return nil
+	}
+
+	for _, entry := range entries {
+		name := filepath.Join(dir, entry.Name())
+		if f.excluded(name) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				f.Log.Warnf("Getting info for %q failed: %v", name, err)
+			}
+			continue
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if !f.FollowSymlinks {
+				continue
+			}
+			if info, err = os.Stat(name); err != nil {
+				f.Log.Debugf("Following symlink %q failed: %v", name, err)
+				continue
+			}
+		}
+
+		if info.IsDir() {
+			stats.directories++
+			if f.MaxDepth > 0 && depth+1 >= f.MaxDepth {
+				continue
+			}
+			if err := f.walkDir(acc, root, name, depth+1, visited, stats, now, current); err != nil {
+				return err
+			}
+			continue
+		}
+		if info.Mode().IsRegular() {
+			f.addFile(acc, root, name, info, stats, now, current)
+		}
+	}
+
+	return nil
+}
+
+func (f *FileInventory) addFile(
+	acc telegraf.Accumulator,
+	root, name string,
+	info fs.FileInfo,
+	stats *rootStats,
+	now time.Time,
+	current map[string]fileState,
+) {
+	size := info.Size()
+	mtime := info.ModTime()
+
+	stats.files++
+	stats.size += size
+	stats.largest = max(stats.largest, size)
+	if stats.oldest.IsZero() || mtime.Before(stats.oldest) {
+		stats.oldest = mtime
+	}
+	if mtime.After(stats.newest) {
+		stats.newest = mtime
+	}
+
+	age := now.Sub(mtime)
+	for i, b := range f.AgeBuckets {
+		if age <= time.Duration(b) {
+			stats.ageCount[i]++
+			stats.ageSize[i] += size
+		}
+	}
+
+	if !f.watched(name) {
+		return
+	}
+	stats.watched++
+
+	state := fileState{Root: root, Size: size, ModTime: mtime.UnixNano()}
+	if f.MaxHashSize == 0 || size <= int64(f.MaxHashSize) {
+		sum, err := f.hashFile(name)
+		if err != nil {
+			acc.AddError(fmt.Errorf("hashing %q failed: %w", name, err))
+			// Keep the previous state to not report a change
+			if prev, found := f.known[name]; found {
+				current[name] = prev
+			}
+			return
+		}
+		state.Hash = sum
+	}
+	current[name] = state
+
+	prev, found := f.known[name]
+	switch {
+	case !f.baseline:
+	case !found:
+		f.addChange(acc, name, "created", fileState{}, state, now)
+	case prev.Hash != state.Hash:
+		f.addChange(acc, name, "modified", prev, state, now)
+	case state.Hash == "" && (prev.Size != state.Size || prev.ModTime != state.ModTime):
+		// Files too large to be hashed are compared by size and time
+		f.addChange(acc, name, "modified", prev, state, now)
+	}
+}
+
+func (f *FileInventory) addStats(acc telegraf.Accumulator, root string, stats *rootStats, now time.Time) {
+	tags := map[string]string{"path": root}
+	fields := map[string]interface{}{
+		"files":              stats.files,
+		"directories":        stats.directories,
+		"size_bytes":         stats.size,
+		"largest_file_bytes": stats.largest,
+	}
+	if stats.files > 0 {
+		fields["oldest_file_age_seconds"] = now.Sub(stats.oldest).Seconds()
+		fields["newest_file_age_seconds"] = now.Sub(stats.newest).Seconds()
+	}
+	if len(f.watch) > 0 {
+		fields["watched_files"] = stats.watched
+	}
+	acc.AddFields("file_inventory", fields, tags, now)
+
+	if len(f.AgeBuckets) == 0 {
+		return
+	}
+
+	// The age distribution is cumulative with "le" holding the upper bound
+	// of the bucket in seconds, similar to histograms.
+	for i, b := range f.AgeBuckets {
+		le := strconv.FormatFloat(time.Duration(b).Seconds(), 'f', -1, 64)
+		acc.AddFields("file_inventory_age",
+			map[string]interface{}{"files": stats.ageCount[i], "size_bytes": stats.ageSize[i]},
+			map[string]string{"path": root, "le": le},
+			now,
+		)
+	}
+	acc.AddFields("file_inventory_age",
+		map[string]interface{}{"files": stats.files, "size_bytes": stats.size},
+		map[string]string{"path": root, "le": "+Inf"},
+		now,
+	)
+}
+
+func (f *FileInventory) addChange(acc telegraf.Accumulator, name, event string, prev, state fileState, now time.Time) {
+	root := state.Root
+	if event == "deleted" {
+		root = prev.Root
+	}
+	tags := map[string]string{
+		"path":  root,
+		"file":  name,
+		"event": event,
+	}
+	fields := make(map[string]interface{}, 5)
+	if event == "deleted" {
+		fields["previous_size_bytes"] = prev.Size
+	} else {
+		fields["size_bytes"] = state.Size
+		fields["modification_time"] = state.ModTime
+		if state.Hash != "" {
+			fields["hash"] = state.Hash
+		}
+	}
+	if prev.Hash != "" {
+		fields["previous_hash"] = prev.Hash
+	}
+	acc.AddFields("file_inventory_change", fields, tags, now)
+}
+
+func (f *FileInventory) hashFile(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := f.newHash()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (f *FileInventory) excluded(name string) bool {
+	for _, g := range f.exclude {
+		if g.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FileInventory) watched(name string) bool {
+	for _, g := range f.watch {
+		if g.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	inputs.Add("file_inventory", func() telegraf.Input {
+		return &FileInventory{
+			HashAlgorithm: "sha256",
+			MaxHashSize:   config.Size(100 * 1024 * 1024),
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package file_inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// writeFile creates a file with the given content and modification time
+// relative to now
+func writeFile(t *testing.T, name, content string, age time.Duration) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0750))
+	require.NoError(t, os.WriteFile(name, []byte(content), 0600))
+	mtime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(name, mtime, mtime))
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *FileInventory
+		expected string
+	}{
+		{
+			name:     "no paths",
+			plugin:   &FileInventory{},
+			expected: "no paths configured",
+		},
+		{
+			name: "unsorted buckets",
+			plugin: &FileInventory{
+				Paths:      []string{"/tmp"},
+				AgeBuckets: []config.Duration{config.Duration(time.Hour), config.Duration(time.Minute)},
+			},
+			expected: "must be sorted",
+		},
+		{
+			name: "invalid hash algorithm",
+			plugin: &FileInventory{
+				Paths:         []string{"/tmp"},
+				HashAlgorithm: "crc32",
+			},
+			expected: `unknown hash algorithm "crc32"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInventory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "new.log"), "0123456789", 10*time.Minute)
+	writeFile(t, filepath.Join(dir, "old.log"), "01234", 48*time.Hour)
+	writeFile(t, filepath.Join(dir, "archive", "ancient.log"), "0123456789012345678901234", 30*24*time.Hour)
+	writeFile(t, filepath.Join(dir, "archive", "deep", "skipped.log"), "0123", time.Minute)
+	writeFile(t, filepath.Join(dir, "ignored.tmp"), "0123456789", time.Minute)
+
+	plugin := &FileInventory{
+		Paths:      []string{dir},
+		Exclude:    []string{filepath.Join(dir, "*.tmp")},
+		MaxDepth:   2,
+		AgeBuckets: []config.Duration{config.Duration(time.Hour), config.Duration(7 * 24 * time.Hour)},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"file_inventory",
+			map[string]string{"path": dir},
+			map[string]interface{}{
+				"files":                   int64(3),
+				"directories":             int64(2),
+				"size_bytes":              int64(40),
+				"largest_file_bytes":      int64(25),
+				"oldest_file_age_seconds": (30 * 24 * time.Hour).Seconds(),
+				"newest_file_age_seconds": (10 * time.Minute).Seconds(),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"file_inventory_age",
+			map[string]string{"path": dir, "le": "3600"},
+			map[string]interface{}{"files": int64(1), "size_bytes": int64(10)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"file_inventory_age",
+			map[string]string{"path": dir, "le": "604800"},
+			map[string]interface{}{"files": int64(2), "size_bytes": int64(15)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"file_inventory_age",
+			map[string]string{"path": dir, "le": "+Inf"},
+			map[string]interface{}{"files": int64(3), "size_bytes": int64(40)},
+			time.Unix(0, 0),
+		),
+	}
+
+	// The ages depend on the time of gathering so only check their range
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.IgnoreFields("oldest_file_age_seconds", "newest_file_age_seconds"))
+	require.InDelta(t, expected[0].Fields()["oldest_file_age_seconds"], actual[0].Fields()["oldest_file_age_seconds"], 60)
+	require.InDelta(t, expected[0].Fields()["newest_file_age_seconds"], actual[0].Fields()["newest_file_age_seconds"], 60)
+}
+
+func TestChanges(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "app.conf")
+	other := filepath.Join(dir, "other.conf")
+	unwatched := filepath.Join(dir, "cache.bin")
+	writeFile(t, conf, "a=1", time.Hour)
+	writeFile(t, other, "b=1", time.Hour)
+	writeFile(t, unwatched, "data", time.Hour)
+
+	plugin := &FileInventory{
+		Paths: []string{dir},
+		Watch: []string{filepath.Join(dir, "*.conf")},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The first cycle records the baseline
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, changes(&acc))
+	require.Equal(t, int64(2), acc.GetTelegrafMetrics()[0].Fields()["watched_files"])
+
+	// Unchanged files don't produce events
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, changes(&acc))
+
+	// Modify, create and delete watched files while touching an unwatched one
+	writeFile(t, conf, "a=2", time.Hour)
+	writeFile(t, filepath.Join(dir, "new.conf"), "c=1", time.Minute)
+	require.NoError(t, os.Remove(other))
+	writeFile(t, unwatched, "changed", time.Minute)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"file_inventory_change",
+			map[string]string{"path": dir, "file": conf, "event": "modified"},
+			map[string]interface{}{
+				"hash":          "d3043f41a0385109cbbaae1ea3c1c31674886be47b073f40681f2ef6d2603c41",
+				"previous_hash": "c22fea5d7428e5cf47ef6354c97c9223c95d6dcdc3e0d2300ff79056b1ff3d85",
+				"size_bytes":    int64(3),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"file_inventory_change",
+			map[string]string{"path": dir, "file": filepath.Join(dir, "new.conf"), "event": "created"},
+			map[string]interface{}{
+				"hash":       "682607c9a4877b48b14ae0b08d6a64e0010dc31282128f16b2d0056039c0f68c",
+				"size_bytes": int64(3),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"file_inventory_change",
+			map[string]string{"path": dir, "file": other, "event": "deleted"},
+			map[string]interface{}{
+				"previous_hash":       "cbe78bac8689bf95bcd287d4cccb0080cfaf95f7d65549758ae6297709f1193d",
+				"previous_size_bytes": int64(3),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.SortMetrics(),
+		testutil.IgnoreTime(),
+		testutil.IgnoreFields("modification_time"),
+	}
+	testutil.RequireMetricsEqual(t, expected, changes(&acc), options...)
+}
+
+func TestLargeFiles(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "large.bin")
+	writeFile(t, name, "0123456789", time.Hour)
+
+	plugin := &FileInventory{
+		Paths:       []string{dir},
+		Watch:       []string{filepath.Join(dir, "*.bin")},
+		MaxHashSize: config.Size(5),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, changes(&acc))
+
+	// Same size but different modification time
+	writeFile(t, name, "9876543210", time.Minute)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	events := changes(&acc)
+	require.Len(t, events, 1)
+	require.Equal(t, "modified", events[0].Tags()["event"])
+	require.NotContains(t, events[0].Fields(), "hash")
+}
+
+func TestState(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "app.conf")
+	writeFile(t, conf, "a=1", time.Hour)
+
+	plugin := &FileInventory{
+		Paths: []string{dir},
+		Watch: []string{filepath.Join(dir, "*.conf")},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	state, ok := plugin.GetState().(map[string]fileState)
+	require.True(t, ok)
+	require.Contains(t, state, conf)
+
+	// Restart with the persisted state after the file changed
+	writeFile(t, conf, "a=2", time.Hour)
+	restarted := &FileInventory{
+		Paths: []string{dir},
+		Watch: []string{filepath.Join(dir, "*.conf")},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, restarted.Init())
+	require.NoError(t, restarted.SetState(state))
+
+	acc.ClearMetrics()
+	require.NoError(t, restarted.Gather(&acc))
+	events := changes(&acc)
+	require.Len(t, events, 1)
+	require.Equal(t, "modified", events[0].Tags()["event"])
+	require.Equal(t, state[conf].Hash, events[0].Fields()["previous_hash"])
+}
+
+func TestMissingPath(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "conf", "app.conf")
+	writeFile(t, conf, "a=1", time.Hour)
+
+	plugin := &FileInventory{
+		Paths: []string{filepath.Join(dir, "conf")},
+		Watch: []string{filepath.Join(dir, "conf", "*.conf")},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	// Files below an unreadable path must not be reported as deleted
+	require.NoError(t, os.Chmod(filepath.Join(dir, "conf"), 0))
+	defer os.Chmod(filepath.Join(dir, "conf"), 0750) //nolint:errcheck // restore permissions for cleanup
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Empty(t, changes(&acc))
+	require.Contains(t, plugin.GetState(), conf)
+}
+
+func changes(acc *testutil.Accumulator) []telegraf.Metric {
+	var events []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "file_inventory_change" {
+			events = append(events, m)
+		}
+	}
+	return events
+}
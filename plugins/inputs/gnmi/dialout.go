@@ -0,0 +1,159 @@
+package gnmi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	gnmiLib "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/influxdata/telegraf"
+	internaltls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// startDialout starts a gRPC server accepting telemetry streams initiated by
+// the devices. The devices call a bidirectional streaming "Publish" method
+// sending gNMI SubscribeResponse messages for the subscriptions configured on
+// the device.
+func (c *GNMI) startDialout(acc telegraf.Accumulator) error {
+	var opts []grpc.ServerOption
+	if c.TLSCert != "" {
+		// Use the client certificate settings for the server side and
+		// require client certificates if a CA is given
+		serverConfig := internaltls.ServerConfig{
+			TLSCert:       c.TLSCert,
+			TLSKey:        c.TLSKey,
+			TLSKeyPwd:     c.TLSKeyPwd,
+			TLSMinVersion: c.TLSMinVersion,
+		}
+		if c.TLSCA != "" {
+			serverConfig.TLSAllowedCACerts = []string{c.TLSCA}
+		}
+		tlscfg, err := serverConfig.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("creating TLS config failed: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlscfg)))
+	}
+	if c.MaxMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(c.MaxMsgSize)))
+	}
+
+	listener, err := net.Listen("tcp", c.ServiceAddress)
+	if err != nil {
+		return fmt.Errorf("listening on %q failed: %w", c.ServiceAddress, err)
+	}
+	c.listener = listener
+
+	c.server = grpc.NewServer(opts...)
+	c.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: c.DialoutService,
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName: "Publish",
+				Handler: func(_ interface{}, stream grpc.ServerStream) error {
+					return c.publish(acc, stream)
+				},
+				ServerStreams: true,
+				ClientStreams: true,
+			},
+		},
+	}, c)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := c.server.Serve(listener); err != nil {
+			acc.AddError(fmt.Errorf("serving dial-out connections failed: %w", err))
+		}
+	}()
+	c.Log.Infof("Listening for dial-out connections on %s", listener.Addr())
+
+	return nil
+}
+
+// publish handles a single telemetry stream of a device
+func (c *GNMI) publish(acc telegraf.Accumulator, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	if err := c.checkCredentials(ctx); err != nil {
+		return err
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Internal, "unknown peer")
+	}
+	address := p.Addr.String()
+	source, _, err := net.SplitHostPort(address)
+	if err != nil {
+		source = address
+	}
+
+	h := c.newHandler(address)
+	c.Log.Debugf("Dial-out connection from gNMI device %s established", address)
+	defer c.Log.Debugf("Dial-out connection from gNMI device %s closed", address)
+
+	connectStat := selfstat.Register("gnmi", "grpc_connection_status", map[string]string{"source": address})
+	connectStat.Set(1)
+	defer connectStat.Set(0)
+
+	for {
+		var reply gnmiLib.SubscribeResponse
+		if err := stream.RecvMsg(&reply); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			acc.AddError(fmt.Errorf("aborted dial-out stream from %s: %w", address, err))
+			return err
+		}
+
+		// Updates without a target are matched by the source address
+		target := reply.GetUpdate().GetPrefix().GetTarget()
+		if target == "" {
+			target = source
+		}
+		if c.targetFilter != nil && !c.targetFilter.Match(target) {
+			c.Log.Debugf("Dropping update of target %q from %s", target, address)
+			continue
+		}
+
+		h.handleSubscribeResponse(acc, &reply)
+	}
+}
+
+// checkCredentials validates the username and password sent by the device
+// if configured
+func (c *GNMI) checkCredentials(ctx context.Context) error {
+	if c.Username == "" {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	var username, password string
+	if v := md.Get("username"); len(v) > 0 {
+		username = v[0]
+	}
+	if v := md.Get("password"); len(v) > 0 {
+		password = v[0]
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(c.Username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(password), []byte(c.Password)) == 1
+	if !userOK || !passOK {
+		return status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	return nil
+}
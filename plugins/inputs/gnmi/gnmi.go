@@ -5,16 +5,19 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/gnxi/utils/xpath"
 	gnmiLib "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal/choice"
 	internaltls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -55,11 +58,17 @@ type GNMI struct {
 	TrimFieldNames      bool              `toml:"trim_field_names"`
 	GuessPathTag        bool              `toml:"guess_path_tag"`
 	EnableTLS           bool              `toml:"enable_tls" deprecated:"1.27.0;use 'tls_enable' instead"`
+	ServiceAddress      string            `toml:"service_address"`
+	DialoutService      string            `toml:"dialout_service"`
+	AllowedTargets      []string          `toml:"allowed_targets"`
 	Log                 telegraf.Logger   `toml:"-"`
 	internaltls.ClientConfig
 
 	// Internal state
 	internalAliases map[*pathInfo]string
+	targetFilter    filter.Filter
+	server          *grpc.Server
+	listener        net.Listener
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup
 }
@@ -100,6 +109,17 @@ func (c *GNMI) Init() error {
 	if time.Duration(c.Redial) <= 0 {
 		return fmt.Errorf("redial duration must be positive")
 	}
+	if len(c.Addresses) == 0 && c.ServiceAddress == "" {
+		return fmt.Errorf("either 'addresses' or 'service_address' must be set")
+	}
+	if c.ServiceAddress != "" && c.DialoutService == "" {
+		return fmt.Errorf("'dialout_service' must be set for dial-out mode")
+	}
+
+	var err error
+	if c.targetFilter, err = filter.Compile(c.AllowedTargets); err != nil {
+		return fmt.Errorf("creating target filter failed: %w", err)
+	}
 
 	// Check vendor_specific options configured by user
 	if err := choice.CheckSlice(c.VendorSpecific, supportedExtensions); err != nil {
@@ -204,25 +224,20 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 		ctx = metadata.AppendToOutgoingContext(ctx, "username", c.Username, "password", c.Password)
 	}
 
+	// Accept connections from devices in dial-out mode
+	if c.ServiceAddress != "" {
+		if err := c.startDialout(acc); err != nil {
+			return err
+		}
+	}
+
 	// Create a goroutine for each device, dial and subscribe
 	c.wg.Add(len(c.Addresses))
 	for _, addr := range c.Addresses {
 		go func(addr string) {
 			defer c.wg.Done()
 
-			h := handler{
-				address:             addr,
-				aliases:             c.internalAliases,
-				tagsubs:             c.TagSubscriptions,
-				maxMsgSize:          int(c.MaxMsgSize),
-				vendorExt:           c.VendorSpecific,
-				tagStore:            newTagStore(c.TagSubscriptions),
-				trace:               c.Trace,
-				canonicalFieldNames: c.CanonicalFieldNames,
-				trimSlash:           c.TrimFieldNames,
-				guessPathTag:        c.GuessPathTag,
-				log:                 c.Log,
-			}
+			h := c.newHandler(addr)
 			for ctx.Err() == nil {
 				if err := h.subscribeGNMI(ctx, acc, tlscfg, request); err != nil && ctx.Err() == nil {
 					acc.AddError(err)
@@ -238,6 +253,22 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 	return nil
 }
 
+func (c *GNMI) newHandler(addr string) *handler {
+	return &handler{
+		address:             addr,
+		aliases:             c.internalAliases,
+		tagsubs:             c.TagSubscriptions,
+		maxMsgSize:          int(c.MaxMsgSize),
+		vendorExt:           c.VendorSpecific,
+		tagStore:            newTagStore(c.TagSubscriptions),
+		trace:               c.Trace,
+		canonicalFieldNames: c.CanonicalFieldNames,
+		trimSlash:           c.TrimFieldNames,
+		guessPathTag:        c.GuessPathTag,
+		log:                 c.Log,
+	}
+}
+
 func (s *Subscription) buildSubscription() (*gnmiLib.Subscription, error) {
 	gnmiPath, err := parsePath(s.Origin, s.Path, "")
 	if err != nil {
@@ -318,6 +349,9 @@ func parsePath(origin string, pathToParse string, target string) (*gnmiLib.Path,
 // Stop listener and cleanup
 func (c *GNMI) Stop() {
 	c.cancel()
+	if c.server != nil {
+		c.server.Stop()
+	}
 	c.wg.Wait()
 }
 
@@ -328,8 +362,9 @@ func (c *GNMI) Gather(_ telegraf.Accumulator) error {
 
 func New() telegraf.Input {
 	return &GNMI{
-		Encoding: "proto",
-		Redial:   config.Duration(10 * time.Second),
+		Encoding:       "proto",
+		Redial:         config.Duration(10 * time.Second),
+		DialoutService: "gnmi_dialout.gNMIDialOut",
 	}
 }
 
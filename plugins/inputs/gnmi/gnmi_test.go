@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -16,6 +17,7 @@ import (
 	gnmiExt "github.com/openconfig/gnmi/proto/gnmi_ext"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -1232,3 +1234,89 @@ func TestCases(t *testing.T) {
 		})
 	}
 }
+
+func TestDialout(t *testing.T) {
+	plugin := &GNMI{
+		Log:            testutil.Logger{},
+		Encoding:       "proto",
+		Redial:         config.Duration(1 * time.Second),
+		ServiceAddress: "127.0.0.1:0",
+		DialoutService: "gnmi_dialout.gNMIDialOut",
+		AllowedTargets: []string{"subscription"},
+		Username:       "theusername",
+		Password:       "thepassword",
+		Subscriptions: []Subscription{
+			{
+				Name:             "alias",
+				Origin:           "type",
+				Path:             "/model",
+				SubscriptionMode: "sample",
+			},
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Act as the device connecting to Telegraf
+	client, err := grpc.Dial(plugin.listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer client.Close()
+
+	desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+	method := "/gnmi_dialout.gNMIDialOut/Publish"
+
+	// Wrong credentials must be rejected
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "username", "theusername", "password", "wrong")
+	stream, err := client.NewStream(ctx, desc, method)
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&gnmiLib.SubscribeResponse{Response: &gnmiLib.SubscribeResponse_SyncResponse{SyncResponse: true}}))
+	require.NoError(t, stream.CloseSend())
+	require.ErrorContains(t, stream.RecvMsg(&gnmiLib.SubscribeResponse{}), "invalid credentials")
+
+	// Send an update of an allowed and of a foreign target
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "username", "theusername", "password", "thepassword")
+	stream, err = client.NewStream(ctx, desc, method)
+	require.NoError(t, err)
+
+	foreign := mockGNMINotification()
+	foreign.Prefix.Target = "foreign"
+	require.NoError(t, stream.SendMsg(&gnmiLib.SubscribeResponse{Response: &gnmiLib.SubscribeResponse_Update{Update: foreign}}))
+	require.NoError(t, stream.SendMsg(&gnmiLib.SubscribeResponse{Response: &gnmiLib.SubscribeResponse_Update{Update: mockGNMINotification()}}))
+	require.NoError(t, stream.CloseSend())
+	require.ErrorIs(t, stream.RecvMsg(&gnmiLib.SubscribeResponse{}), io.EOF)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"alias",
+			map[string]string{
+				"path":   "type:/model",
+				"source": "127.0.0.1",
+				"foo":    "bar",
+				"name":   "str",
+				"uint64": "1234",
+			},
+			map[string]interface{}{
+				"some/path": int64(5678),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"alias",
+			map[string]string{
+				"path":   "type:/model",
+				"source": "127.0.0.1",
+				"foo":    "bar",
+			},
+			map[string]interface{}{
+				"other/path": "foobar",
+				"other/this": "that",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	acc.Wait(len(expected))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
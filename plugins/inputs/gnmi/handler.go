@@ -98,24 +98,29 @@ func (h *handler) subscribeGNMI(ctx context.Context, acc telegraf.Accumulator, t
 			break
 		}
 
-		if h.trace {
-			buf, err := protojson.Marshal(reply)
-			if err != nil {
-				h.log.Debugf("Marshal failed: %v", err)
-			} else {
-				t := reply.GetUpdate().GetTimestamp()
-				h.log.Debugf("Got update_%v: %s", t, string(buf))
-			}
-		}
-		if response, ok := reply.Response.(*gnmiLib.SubscribeResponse_Update); ok {
-			h.handleSubscribeResponseUpdate(acc, response, reply.GetExtension())
-		}
+		h.handleSubscribeResponse(acc, reply)
 	}
 
 	connectStat.Set(0)
 	return nil
 }
 
+// Handle a SubscribeResponse message received from the device
+func (h *handler) handleSubscribeResponse(acc telegraf.Accumulator, reply *gnmiLib.SubscribeResponse) {
+	if h.trace {
+		buf, err := protojson.Marshal(reply)
+		if err != nil {
+			h.log.Debugf("Marshal failed: %v", err)
+		} else {
+			t := reply.GetUpdate().GetTimestamp()
+			h.log.Debugf("Got update_%v: %s", t, string(buf))
+		}
+	}
+	if response, ok := reply.Response.(*gnmiLib.SubscribeResponse_Update); ok {
+		h.handleSubscribeResponseUpdate(acc, response, reply.GetExtension())
+	}
+}
+
 // Handle SubscribeResponse_Update message from gNMI and parse contained telemetry data
 func (h *handler) handleSubscribeResponseUpdate(acc telegraf.Accumulator, response *gnmiLib.SubscribeResponse_Update, extension []*gnmiExt.Extension) {
 	grouper := metric.NewSeriesGrouper()
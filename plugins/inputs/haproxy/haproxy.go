@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -27,19 +28,50 @@ var sampleConfig string
 //CSV format: https://cbonte.github.io/haproxy-dconv/1.5/configuration.html#9.1
 
 type haproxy struct {
-	Servers        []string
-	KeepFieldNames bool
-	Username       string
-	Password       string
+	Servers           []string
+	KeepFieldNames    bool
+	Username          string
+	Password          string
+	RuntimeAPI        []string `toml:"runtime_api"`
+	StickTables       []string `toml:"stick_tables"`
+	StickTableEntries int      `toml:"stick_table_entries"`
 	tls.ClientConfig
 
-	client *http.Client
+	client             *http.Client
+	runtimeServers     bool
+	runtimeStickTables bool
+	tableFilter        filter.Filter
 }
 
 func (*haproxy) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *haproxy) Init() error {
+	for _, query := range h.RuntimeAPI {
+		switch query {
+		case "servers":
+			h.runtimeServers = true
+		case "stick_tables":
+			h.runtimeStickTables = true
+		default:
+			return fmt.Errorf("invalid runtime_api query %q", query)
+		}
+	}
+
+	if h.StickTableEntries < 0 {
+		return errors.New("stick_table_entries must not be negative")
+	}
+
+	f, err := filter.Compile(h.StickTables)
+	if err != nil {
+		return fmt.Errorf("compiling stick_tables filter failed: %w", err)
+	}
+	h.tableFilter = f
+
+	return nil
+}
+
 // Reads stats from all configured servers accumulates stats.
 // Returns one of the errors encountered while gather stats (if any).
 func (h *haproxy) Gather(acc telegraf.Accumulator) error {
@@ -99,13 +131,19 @@ func (h *haproxy) gatherServerSocket(addr string, acc telegraf.Accumulator) erro
 	if err != nil {
 		return fmt.Errorf("could not connect to '%s://%s': %w", network, address, err)
 	}
+	defer c.Close()
 
 	_, errw := c.Write([]byte("show stat\n"))
 	if errw != nil {
 		return fmt.Errorf("could not write to socket '%s://%s': %w", network, address, errw)
 	}
 
-	return h.importCsvResult(c, acc, address)
+	if err := h.importCsvResult(c, acc, address); err != nil {
+		return err
+	}
+
+	h.gatherRuntime(network, address, acc)
+	return nil
 }
 
 func (h *haproxy) gatherServer(addr string, acc telegraf.Accumulator) error {
@@ -11,9 +11,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -33,8 +36,15 @@ func (s statServer) serverSocket(l net.Listener) {
 			n, _ := c.Read(buf)
 
 			data := buf[:n]
-			if string(data) == "show stat\n" {
+			switch string(data) {
+			case "show stat\n":
 				c.Write(csvOutputSample) //nolint:errcheck // we return anyway
+			case "show servers state\n":
+				c.Write(mustReadTestdata("servers_state.txt")) //nolint:errcheck // we return anyway
+			case "show table\n":
+				c.Write(mustReadTestdata("show_table.txt")) //nolint:errcheck // we return anyway
+			case "show table be_app\n":
+				c.Write(mustReadTestdata("show_table_be_app.txt")) //nolint:errcheck // we return anyway
 			}
 		}(conn)
 	}
@@ -260,6 +270,191 @@ func TestHaproxyKeepFieldNames(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "haproxy", fields, tags)
 }
 
+func TestHaproxyRuntimeAPI(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	s := statServer{}
+	go s.serverSocket(l)
+
+	r := &haproxy{
+		Servers:           []string{"tcp://" + l.Addr().String()},
+		RuntimeAPI:        []string{"servers", "stick_tables"},
+		StickTableEntries: 1,
+	}
+	require.NoError(t, r.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	addr := l.Addr().String()
+	expected := []telegraf.Metric{
+		metric.New(
+			"haproxy_server_state",
+			map[string]string{
+				"server": addr,
+				"proxy":  "be_app",
+				"sv":     "app1",
+			},
+			map[string]interface{}{
+				"backend_id":             int64(3),
+				"server_id":              int64(1),
+				"addr":                   "10.0.0.1",
+				"port":                   int64(8080),
+				"op_state":               "running",
+				"admin_state":            int64(0),
+				"maintenance":            false,
+				"drain":                  false,
+				"weight":                 int64(1),
+				"initial_weight":         int64(1),
+				"time_since_last_change": int64(4518),
+				"check_status":           int64(6),
+				"check_result":           "passed",
+				"check_health":           int64(4),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy_server_state",
+			map[string]string{
+				"server": addr,
+				"proxy":  "be_app",
+				"sv":     "app2",
+			},
+			map[string]interface{}{
+				"backend_id":             int64(3),
+				"server_id":              int64(2),
+				"addr":                   "10.0.0.2",
+				"fqdn":                   "app2.example.com",
+				"port":                   int64(8080),
+				"op_state":               "stopped",
+				"admin_state":            int64(1),
+				"maintenance":            true,
+				"drain":                  false,
+				"weight":                 int64(1),
+				"initial_weight":         int64(1),
+				"time_since_last_change": int64(12),
+				"check_status":           int64(15),
+				"check_result":           "failed",
+				"check_health":           int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy_stick_table",
+			map[string]string{
+				"server": addr,
+				"table":  "be_app",
+				"type":   "ip",
+			},
+			map[string]interface{}{
+				"size": int64(204800),
+				"used": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy_stick_table_entry",
+			map[string]string{
+				"server": addr,
+				"table":  "be_app",
+				"key":    "10.0.0.10",
+			},
+			map[string]interface{}{
+				"use":           int64(0),
+				"exp":           int64(1784),
+				"server_id":     int64(1),
+				"conn_cnt":      int64(15),
+				"http_req_rate": int64(3),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy_stick_table",
+			map[string]string{
+				"server": addr,
+				"table":  "fe_limits",
+				"type":   "string",
+			},
+			map[string]interface{}{
+				"size": int64(1024),
+				"used": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "haproxy" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestHaproxyRuntimeAPIStickTableFilter(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	s := statServer{}
+	go s.serverSocket(l)
+
+	r := &haproxy{
+		Servers:     []string{"tcp://" + l.Addr().String()},
+		RuntimeAPI:  []string{"stick_tables"},
+		StickTables: []string{"fe_*"},
+	}
+	require.NoError(t, r.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"haproxy_stick_table",
+			map[string]string{
+				"server": l.Addr().String(),
+				"table":  "fe_limits",
+				"type":   "string",
+			},
+			map[string]interface{}{
+				"size": int64(1024),
+				"used": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "haproxy" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestHaproxyInitFail(t *testing.T) {
+	r := &haproxy{RuntimeAPI: []string{"sessions"}}
+	require.EqualError(t, r.Init(), `invalid runtime_api query "sessions"`)
+
+	r = &haproxy{StickTableEntries: -1}
+	require.EqualError(t, r.Init(), "stick_table_entries must not be negative")
+}
+
+func mustReadTestdata(name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		panic(fmt.Errorf("could not read from file %s: %w", name, err))
+	}
+	return data
+}
+
 func mustReadSampleOutput() []byte {
 	filePath := "testdata/sample_output.csv"
 	data, err := os.ReadFile(filePath)
@@ -0,0 +1,320 @@
+package haproxy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Runtime API documentation: https://docs.haproxy.org/2.8/management.html#9.3
+
+const runtimeTimeout = 5 * time.Second
+
+var serverOpStates = []string{"stopped", "starting", "running", "stopping"}
+var checkResults = []string{"unknown", "neutral", "failed", "passed", "condpass"}
+
+var serverStateRenames = map[string]string{
+	"be_id":                      "backend_id",
+	"srv_id":                     "server_id",
+	"srv_uweight":                "weight",
+	"srv_iweight":                "initial_weight",
+	"srv_time_since_last_change": "time_since_last_change",
+	"srv_check_status":           "check_status",
+	"srv_check_health":           "check_health",
+	"srv_port":                   "port",
+}
+
+const (
+	// Admin state flags of the server, see enum srv_admin in HAProxy
+	adminMaintenance = 0x01 | 0x02 | 0x04 | 0x20 | 0x40
+	adminDrain       = 0x08 | 0x10
+)
+
+// gatherRuntime queries the runtime API of the socket for the enabled
+// additional metrics. Failures are reported per query so that one
+// unavailable command does not hide the results of the others.
+func (h *haproxy) gatherRuntime(network, address string, acc telegraf.Accumulator) {
+	if h.runtimeServers {
+		if err := h.gatherServersState(network, address, acc); err != nil {
+			acc.AddError(fmt.Errorf("querying servers state of '%s://%s' failed: %w", network, address, err))
+		}
+	}
+	if h.runtimeStickTables {
+		if err := h.gatherStickTables(network, address, acc); err != nil {
+			acc.AddError(fmt.Errorf("querying stick tables of '%s://%s' failed: %w", network, address, err))
+		}
+	}
+}
+
+// runtimeCommand sends a single command to the runtime API and returns the
+// connection to read the response from. HAProxy closes the connection after
+// answering a command in non-interactive mode.
+func runtimeCommand(network, address, command string) (net.Conn, error) {
+	c, err := net.DialTimeout(network, address, runtimeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetDeadline(time.Now().Add(runtimeTimeout)); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if _, err := c.Write([]byte(command + "\n")); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (h *haproxy) gatherServersState(network, address string, acc telegraf.Accumulator) error {
+	c, err := runtimeCommand(network, address, "show servers state")
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return importServersState(c, acc, address)
+}
+
+// importServersState parses the output of "show servers state". It covers
+// all servers including the ones added at runtime via "add server".
+func importServersState(r io.Reader, acc telegraf.Accumulator, host string) error {
+	now := time.Now()
+
+	var headers []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			headers = strings.Fields(line[2:])
+			continue
+		}
+		if headers == nil {
+			// Format version preceding the headers
+			continue
+		}
+
+		row := strings.Fields(line)
+		if len(row) < len(headers) {
+			return fmt.Errorf("number of columns does not match number of headers. headers=%d columns=%d", len(headers), len(row))
+		}
+
+		tags := map[string]string{
+			"server": host,
+		}
+		fields := make(map[string]interface{})
+		for i, colName := range headers {
+			v := row[i]
+			switch colName {
+			case "be_name":
+				tags["proxy"] = v
+			case "srv_name":
+				tags["sv"] = v
+			case "srv_addr":
+				fields["addr"] = v
+			case "srv_fqdn":
+				if v != "-" {
+					fields["fqdn"] = v
+				}
+			case "srv_op_state":
+				vi, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return fmt.Errorf("unable to parse operational state value %q", v)
+				}
+				if vi >= 0 && vi < int64(len(serverOpStates)) {
+					fields["op_state"] = serverOpStates[vi]
+				}
+			case "srv_admin_state":
+				vi, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return fmt.Errorf("unable to parse admin state value %q", v)
+				}
+				fields["admin_state"] = vi
+				fields["maintenance"] = vi&adminMaintenance != 0
+				fields["drain"] = vi&adminDrain != 0
+			case "srv_check_result":
+				vi, err := strconv.ParseInt(v, 10, 64)
+				if err == nil && vi >= 0 && vi < int64(len(checkResults)) {
+					fields["check_result"] = checkResults[vi]
+				}
+			case "be_id", "srv_id", "srv_uweight", "srv_iweight", "srv_time_since_last_change",
+				"srv_check_status", "srv_check_health", "srv_port":
+				vi, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					continue
+				}
+				fields[serverStateRenames[colName]] = vi
+			}
+		}
+		acc.AddFields("haproxy_server_state", fields, tags, now)
+	}
+	return scanner.Err()
+}
+
+type stickTable struct {
+	name      string
+	tableType string
+	size      int64
+	used      int64
+}
+
+func (h *haproxy) gatherStickTables(network, address string, acc telegraf.Accumulator) error {
+	c, err := runtimeCommand(network, address, "show table")
+	if err != nil {
+		return err
+	}
+	tables, err := parseStickTables(c)
+	c.Close()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, table := range tables {
+		if h.tableFilter != nil && !h.tableFilter.Match(table.name) {
+			continue
+		}
+
+		tags := map[string]string{
+			"server": address,
+			"table":  table.name,
+			"type":   table.tableType,
+		}
+		fields := map[string]interface{}{
+			"size": table.size,
+			"used": table.used,
+		}
+		acc.AddFields("haproxy_stick_table", fields, tags, now)
+
+		if h.StickTableEntries > 0 && table.used > 0 {
+			if err := h.gatherStickTableEntries(network, address, table.name, acc); err != nil {
+				acc.AddError(fmt.Errorf("querying stick table %q of '%s://%s' failed: %w", table.name, network, address, err))
+			}
+		}
+	}
+	return nil
+}
+
+// parseStickTables parses the table lines of "show table" and "show table
+// <name>" having the form
+//
+//	# table: be_app, type: ip, size:204800, used:2
+func parseStickTables(r io.Reader) ([]stickTable, error) {
+	var tables []stickTable
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "# table:") {
+			continue
+		}
+		table, err := parseStickTableLine(line[2:])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, scanner.Err()
+}
+
+func parseStickTableLine(line string) (stickTable, error) {
+	var table stickTable
+	for _, part := range strings.Split(line, ",") {
+		key, value, found := strings.Cut(part, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch strings.TrimSpace(key) {
+		case "table":
+			table.name = value
+		case "type":
+			table.tableType = value
+		case "size":
+			table.size, err = strconv.ParseInt(value, 10, 64)
+		case "used":
+			table.used, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil {
+			return table, fmt.Errorf("unable to parse stick table line %q: %w", line, err)
+		}
+	}
+	if table.name == "" {
+		return table, fmt.Errorf("missing table name in %q", line)
+	}
+	return table, nil
+}
+
+func (h *haproxy) gatherStickTableEntries(network, address, name string, acc telegraf.Accumulator) error {
+	c, err := runtimeCommand(network, address, "show table "+name)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return importStickTableEntries(c, acc, address, name, h.StickTableEntries)
+}
+
+// importStickTableEntries parses at most limit entries of "show table <name>"
+// having the form
+//
+//	0x55d3c4a0e0e0: key=127.0.0.1 use=0 exp=1784 shard=0 gpc0=0 conn_rate(10000)=1
+func importStickTableEntries(r io.Reader, acc telegraf.Accumulator, host, name string, limit int) error {
+	now := time.Now()
+
+	var count int
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() && count < limit {
+		line := scanner.Bytes()
+		idx := bytes.Index(line, []byte(": "))
+		if len(line) == 0 || line[0] == '#' || idx == -1 {
+			continue
+		}
+
+		tags := map[string]string{
+			"server": host,
+			"table":  name,
+		}
+		fields := make(map[string]interface{})
+		for _, item := range strings.Fields(string(line[idx+2:])) {
+			key, value, found := strings.Cut(item, "=")
+			if !found {
+				continue
+			}
+			// Strip the period of rate counters, e.g. "conn_rate(10000)"
+			if i := strings.IndexByte(key, '('); i != -1 {
+				key = key[:i]
+			}
+
+			switch key {
+			case "key":
+				tags["key"] = value
+			case "shard":
+				// internal distribution of the entries, not of interest
+			default:
+				vi, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					continue
+				}
+				fields[key] = vi
+			}
+		}
+		if _, found := tags["key"]; !found {
+			continue
+		}
+		acc.AddFields("haproxy_stick_table_entry", fields, tags, now)
+		count++
+	}
+	return scanner.Err()
+}
@@ -56,6 +56,9 @@ type HTTPResponse struct {
 	Password config.Secret `toml:"password"`
 	tls.ClientConfig
 
+	// Sequence of requests executed instead of querying the URLs
+	Steps []*Step `toml:"step"`
+
 	Log telegraf.Logger
 
 	compiledStringMatch *regexp.Regexp
@@ -147,6 +150,8 @@ func setResult(resultString string, fields map[string]interface{}, tags map[stri
 		"timeout":                       4,
 		"dns_error":                     5,
 		"response_status_code_mismatch": 6,
+		"extraction_failed":             7,
+		"assertion_failed":              8,
 	}
 
 	tags["result"] = resultString
@@ -209,27 +214,7 @@ func (h *HTTPResponse) httpGather(u string) (map[string]interface{}, map[string]
 		return nil, nil, err
 	}
 
-	if _, uaPresent := h.Headers["User-Agent"]; !uaPresent {
-		request.Header.Set("User-Agent", internal.ProductToken())
-	}
-
-	if h.BearerToken != "" {
-		token, err := os.ReadFile(h.BearerToken)
-		if err != nil {
-			return nil, nil, err
-		}
-		bearer := "Bearer " + strings.Trim(string(token), "\n")
-		request.Header.Add("Authorization", bearer)
-	}
-
-	for key, val := range h.Headers {
-		request.Header.Add(key, val)
-		if key == "Host" {
-			request.Host = val
-		}
-	}
-
-	if err := h.setRequestAuth(request); err != nil {
+	if err := h.prepareRequest(request, h.Headers); err != nil {
 		return nil, nil, err
 	}
 
@@ -273,16 +258,8 @@ func (h *HTTPResponse) httpGather(u string) (map[string]interface{}, map[string]
 	tags["status_code"] = strconv.Itoa(resp.StatusCode)
 	fields["http_response_code"] = resp.StatusCode
 
-	if h.ResponseBodyMaxSize == 0 {
-		h.ResponseBodyMaxSize = config.Size(defaultResponseBodyMaxSize)
-	}
-	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, int64(h.ResponseBodyMaxSize)+1))
-	// Check first if the response body size exceeds the limit.
-	if err == nil && int64(len(bodyBytes)) > int64(h.ResponseBodyMaxSize) {
-		h.setBodyReadError("The body of the HTTP Response is too large", bodyBytes, fields, tags)
-		return fields, tags, nil
-	} else if err != nil {
-		h.setBodyReadError(fmt.Sprintf("Failed to read body of HTTP Response : %s", err.Error()), bodyBytes, fields, tags)
+	bodyBytes, ok := h.readBody(resp, h.ResponseStringMatch != "", fields, tags)
+	if !ok {
 		return fields, tags, nil
 	}
 
@@ -290,7 +267,7 @@ func (h *HTTPResponse) httpGather(u string) (map[string]interface{}, map[string]
 	if len(h.ResponseBodyField) > 0 {
 		// Check that the content of response contains only valid utf-8 characters.
 		if !utf8.Valid(bodyBytes) {
-			h.setBodyReadError("The body of the HTTP Response is not a valid utf-8 string", bodyBytes, fields, tags)
+			h.setBodyReadError("The body of the HTTP Response is not a valid utf-8 string", bodyBytes, h.ResponseStringMatch != "", fields, tags)
 			return fields, tags, nil
 		}
 		fields[h.ResponseBodyField] = string(bodyBytes)
@@ -328,12 +305,56 @@ func (h *HTTPResponse) httpGather(u string) (map[string]interface{}, map[string]
 	return fields, tags, nil
 }
 
+// prepareRequest adds the user-agent, authentication and the given headers
+// to the request
+func (h *HTTPResponse) prepareRequest(request *http.Request, headers map[string]string) error {
+	if _, uaPresent := headers["User-Agent"]; !uaPresent {
+		request.Header.Set("User-Agent", internal.ProductToken())
+	}
+
+	if h.BearerToken != "" {
+		token, err := os.ReadFile(h.BearerToken)
+		if err != nil {
+			return err
+		}
+		bearer := "Bearer " + strings.Trim(string(token), "\n")
+		request.Header.Add("Authorization", bearer)
+	}
+
+	for key, val := range headers {
+		request.Header.Add(key, val)
+		if key == "Host" {
+			request.Host = val
+		}
+	}
+
+	return h.setRequestAuth(request)
+}
+
+// readBody reads the response body up to the configured maximum size. In case
+// of errors the result is set and false is returned.
+func (h *HTTPResponse) readBody(resp *http.Response, stringMatch bool, fields map[string]interface{}, tags map[string]string) ([]byte, bool) {
+	if h.ResponseBodyMaxSize == 0 {
+		h.ResponseBodyMaxSize = config.Size(defaultResponseBodyMaxSize)
+	}
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, int64(h.ResponseBodyMaxSize)+1))
+	// Check first if the response body size exceeds the limit.
+	if err == nil && int64(len(bodyBytes)) > int64(h.ResponseBodyMaxSize) {
+		h.setBodyReadError("The body of the HTTP Response is too large", bodyBytes, stringMatch, fields, tags)
+		return nil, false
+	} else if err != nil {
+		h.setBodyReadError(fmt.Sprintf("Failed to read body of HTTP Response : %s", err.Error()), bodyBytes, stringMatch, fields, tags)
+		return nil, false
+	}
+	return bodyBytes, true
+}
+
 // Set result in case of a body read error
-func (h *HTTPResponse) setBodyReadError(errorMsg string, bodyBytes []byte, fields map[string]interface{}, tags map[string]string) {
+func (h *HTTPResponse) setBodyReadError(errorMsg string, bodyBytes []byte, stringMatch bool, fields map[string]interface{}, tags map[string]string) {
 	h.Log.Debugf(errorMsg)
 	setResult("body_read_error", fields, tags)
 	fields["content_length"] = len(bodyBytes)
-	if h.ResponseStringMatch != "" {
+	if stringMatch {
 		fields["response_string_match"] = 0
 	}
 }
@@ -342,6 +363,18 @@ func (*HTTPResponse) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *HTTPResponse) Init() error {
+	if len(h.Steps) > 0 && len(h.URLs) > 0 {
+		return errors.New("'urls' cannot be used together with steps")
+	}
+	for i, s := range h.Steps {
+		if err := s.init(i); err != nil {
+			return fmt.Errorf("initializing step %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
 // Gather gets all metric fields and tags and returns any errors it encounters
 func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 	// Compile the body regex if it exist
@@ -362,14 +395,6 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 		h.Method = "GET"
 	}
 
-	if len(h.URLs) == 0 {
-		if h.Address == "" {
-			h.URLs = []string{"http://localhost"}
-		} else {
-			h.URLs = []string{h.Address}
-		}
-	}
-
 	if h.client == nil {
 		client, err := h.createHTTPClient()
 		if err != nil {
@@ -378,6 +403,19 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 		h.client = client
 	}
 
+	if len(h.Steps) > 0 {
+		h.gatherSteps(acc)
+		return nil
+	}
+
+	if len(h.URLs) == 0 {
+		if h.Address == "" {
+			h.URLs = []string{"http://localhost"}
+		} else {
+			h.URLs = []string{h.Address}
+		}
+	}
+
 	for _, u := range h.URLs {
 		addr, err := url.Parse(u)
 		if err != nil {
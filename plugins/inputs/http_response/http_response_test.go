@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -1367,3 +1368,188 @@ func TestSNI(t *testing.T) {
 	absentFields := []string{"response_string_match"}
 	checkOutput(t, &acc, expectedFields, expectedTags, absentFields, nil)
 }
+
+func stepsServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || r.Method != http.MethodPost || string(body) != `{"user":"telegraf"}` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"token":"abc","user":{"id":42}}`)
+	})
+	mux.HandleFunc("/items/42", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "s3cr3t" || r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"status":"ok","count":5,"items":[{"name":"a"},{"name":"b"}]}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestSteps(t *testing.T) {
+	ts := stepsServer()
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		ResponseTimeout: config.Duration(time.Second * 2),
+		Steps: []*Step{
+			{
+				Name:               "login",
+				URL:                ts.URL + "/login",
+				Method:             "POST",
+				Body:               `{"user":"telegraf"}`,
+				ResponseStatusCode: http.StatusOK,
+				Variables:          map[string]string{"token": "token", "id": "user.id"},
+			},
+			{
+				Name:                "fetch",
+				URL:                 ts.URL + "/items/{{.id}}",
+				Headers:             map[string]string{"Authorization": "Bearer {{.token}}"},
+				ResponseStringMatch: "items",
+				Extract:             map[string]string{"item_count": "count", "first_item": "items.0.name"},
+				Assert:              map[string]string{"status": "ok", "items.#": "2"},
+			},
+		},
+	}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"http_response",
+			map[string]string{
+				"server":      ts.URL + "/login",
+				"method":      "POST",
+				"step":        "login",
+				"status_code": "200",
+				"result":      "success",
+			},
+			map[string]interface{}{
+				"http_response_code":         http.StatusOK,
+				"content_length":             33,
+				"response_status_code_match": 1,
+				"result_type":                "success",
+				"result_code":                0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"http_response",
+			map[string]string{
+				"server":      ts.URL + "/items/{{.id}}",
+				"method":      "GET",
+				"step":        "fetch",
+				"status_code": "200",
+				"result":      "success",
+			},
+			map[string]interface{}{
+				"http_response_code":    http.StatusOK,
+				"content_length":        62,
+				"response_string_match": 1,
+				"item_count":            float64(5),
+				"first_item":            "a",
+				"assertion_match":       1,
+				"result_type":           "success",
+				"result_code":           0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.IgnoreFields("response_time"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestStepsFailure(t *testing.T) {
+	ts := stepsServer()
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		steps  []*Step
+		result string
+		code   int
+	}{
+		{
+			name: "login rejected",
+			steps: []*Step{
+				{URL: ts.URL + "/login", Method: "POST", Body: `{"user":"nobody"}`, ResponseStatusCode: http.StatusOK},
+				{URL: ts.URL + "/items/42"},
+			},
+			result: "response_status_code_mismatch",
+			code:   6,
+		},
+		{
+			name: "missing path",
+			steps: []*Step{
+				{URL: ts.URL + "/login", Method: "POST", Body: `{"user":"telegraf"}`, Extract: map[string]string{"x": "does.not.exist"}},
+				{URL: ts.URL + "/items/42"},
+			},
+			result: "extraction_failed",
+			code:   7,
+		},
+		{
+			name: "assertion failed",
+			steps: []*Step{
+				{URL: ts.URL + "/login", Method: "POST", Body: `{"user":"telegraf"}`, Assert: map[string]string{"user.id": "1"}},
+				{URL: ts.URL + "/items/42"},
+			},
+			result: "assertion_failed",
+			code:   8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HTTPResponse{
+				Log:             testutil.Logger{},
+				ResponseTimeout: config.Duration(time.Second * 2),
+				Steps:           tt.steps,
+			}
+			require.NoError(t, h.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, h.Gather(&acc))
+
+			// The remaining steps must be skipped
+			metrics := acc.GetTelegrafMetrics()
+			require.Len(t, metrics, 1)
+			require.Equal(t, "step1", metrics[0].Tags()["step"])
+			require.Equal(t, tt.result, metrics[0].Tags()["result"])
+			code, found := metrics[0].GetField("result_code")
+			require.True(t, found)
+			require.EqualValues(t, tt.code, code)
+		})
+	}
+}
+
+func TestStepsInitFail(t *testing.T) {
+	h := &HTTPResponse{
+		URLs:  []string{"http://localhost"},
+		Steps: []*Step{{URL: "http://localhost"}},
+	}
+	require.ErrorContains(t, h.Init(), "cannot be used together with steps")
+
+	h = &HTTPResponse{
+		Steps: []*Step{{URL: "http://localhost/{{.id"}},
+	}
+	require.ErrorContains(t, h.Init(), "parsing url template failed")
+
+	h = &HTTPResponse{
+		Steps: []*Step{{Method: "GET"}},
+	}
+	require.ErrorContains(t, h.Init(), "missing url")
+}
@@ -0,0 +1,250 @@
+package http_response
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Step is a single request of a multi-step check. The URL, body and header
+// values are Go templates which can reference the values extracted in
+// previous steps, e.g. "{{.token}}".
+type Step struct {
+	Name                string            `toml:"name"`
+	URL                 string            `toml:"url"`
+	Method              string            `toml:"method"`
+	Body                string            `toml:"body"`
+	Headers             map[string]string `toml:"headers"`
+	ResponseStringMatch string            `toml:"response_string_match"`
+	ResponseStatusCode  int               `toml:"response_status_code"`
+	Extract             map[string]string `toml:"extract"`
+	Variables           map[string]string `toml:"variables"`
+	Assert              map[string]string `toml:"assert"`
+
+	url         *template.Template
+	body        *template.Template
+	headers     map[string]*template.Template
+	stringMatch *regexp.Regexp
+}
+
+func (s *Step) init(idx int) error {
+	if s.URL == "" {
+		return errors.New("missing url")
+	}
+	if s.Name == "" {
+		s.Name = "step" + strconv.Itoa(idx+1)
+	}
+	if s.Method == "" {
+		s.Method = "GET"
+	}
+
+	var err error
+	if s.url, err = template.New("url").Option("missingkey=error").Parse(s.URL); err != nil {
+		return fmt.Errorf("parsing url template failed: %w", err)
+	}
+	if s.body, err = template.New("body").Option("missingkey=error").Parse(s.Body); err != nil {
+		return fmt.Errorf("parsing body template failed: %w", err)
+	}
+	s.headers = make(map[string]*template.Template, len(s.Headers))
+	for k, v := range s.Headers {
+		if s.headers[k], err = template.New(k).Option("missingkey=error").Parse(v); err != nil {
+			return fmt.Errorf("parsing template for header %q failed: %w", k, err)
+		}
+	}
+
+	if s.ResponseStringMatch != "" {
+		if s.stringMatch, err = regexp.Compile(s.ResponseStringMatch); err != nil {
+			return fmt.Errorf("failed to compile regular expression %q: %w", s.ResponseStringMatch, err)
+		}
+	}
+
+	for name, path := range s.Extract {
+		if name == "" || path == "" {
+			return fmt.Errorf("invalid extraction %q = %q", name, path)
+		}
+	}
+	for name, path := range s.Variables {
+		if name == "" || path == "" {
+			return fmt.Errorf("invalid variable %q = %q", name, path)
+		}
+	}
+
+	return nil
+}
+
+func render(tmpl *template.Template, vars map[string]string) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// gatherSteps executes the steps in order and stops at the first failing step
+func (h *HTTPResponse) gatherSteps(acc telegraf.Accumulator) {
+	// Use a new cookie jar for each run to keep sessions between the steps
+	client := h.client
+	if c, ok := h.client.(*http.Client); ok {
+		clone := *c
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			acc.AddError(fmt.Errorf("creating cookie jar failed: %w", err))
+			return
+		}
+		clone.Jar = jar
+		client = &clone
+	}
+
+	vars := make(map[string]string)
+	for _, s := range h.Steps {
+		fields, tags, err := h.gatherStep(client, s, vars)
+		if err != nil {
+			acc.AddError(fmt.Errorf("step %q: %w", s.Name, err))
+			return
+		}
+		acc.AddFields("http_response", fields, tags)
+
+		if tags["result"] != "success" {
+			h.Log.Debugf("Step %q failed with result %q, skipping remaining steps", s.Name, tags["result"])
+			return
+		}
+	}
+}
+
+func (h *HTTPResponse) gatherStep(client httpClient, s *Step, vars map[string]string) (map[string]interface{}, map[string]string, error) {
+	// Use the URL template as tag to not leak extracted values like tokens
+	fields := make(map[string]interface{})
+	tags := map[string]string{"server": s.URL, "method": s.Method, "step": s.Name}
+
+	u, err := render(s.url, vars)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rendering url failed: %w", err)
+	}
+	body, err := render(s.body, vars)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rendering body failed: %w", err)
+	}
+	headers := make(map[string]string, len(h.Headers)+len(s.headers))
+	for k, v := range h.Headers {
+		headers[k] = v
+	}
+	for k, tmpl := range s.headers {
+		if headers[k], err = render(tmpl, vars); err != nil {
+			return nil, nil, fmt.Errorf("rendering header %q failed: %w", k, err)
+		}
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request, err := http.NewRequest(s.Method, u, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := h.prepareRequest(request, headers); err != nil {
+		return nil, nil, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(request)
+	responseTime := time.Since(start).Seconds()
+	if err != nil {
+		h.Log.Debugf("Network error while polling %s: %s", u, err.Error())
+		if setError(err, fields, tags) == nil {
+			setResult("connection_failed", fields, tags)
+		}
+		return fields, tags, nil
+	}
+	defer resp.Body.Close()
+
+	fields["response_time"] = responseTime
+	tags["status_code"] = strconv.Itoa(resp.StatusCode)
+	fields["http_response_code"] = resp.StatusCode
+
+	bodyBytes, ok := h.readBody(resp, s.stringMatch != nil, fields, tags)
+	if !ok {
+		return fields, tags, nil
+	}
+	fields["content_length"] = len(bodyBytes)
+
+	// Check the response status code and body
+	if s.ResponseStatusCode > 0 {
+		if resp.StatusCode != s.ResponseStatusCode {
+			fields["response_status_code_match"] = 0
+			setResult("response_status_code_mismatch", fields, tags)
+			return fields, tags, nil
+		}
+		fields["response_status_code_match"] = 1
+	}
+	if s.stringMatch != nil {
+		if !s.stringMatch.Match(bodyBytes) {
+			fields["response_string_match"] = 0
+			setResult("response_string_mismatch", fields, tags)
+			return fields, tags, nil
+		}
+		fields["response_string_match"] = 1
+	}
+
+	// Extract the values from the JSON response as fields and for use in the
+	// following steps
+	for name, path := range s.Extract {
+		result := gjson.GetBytes(bodyBytes, path)
+		if !result.Exists() {
+			h.Log.Debugf("Path %q not found in response of step %q", path, s.Name)
+			setResult("extraction_failed", fields, tags)
+			return fields, tags, nil
+		}
+		switch result.Type {
+		case gjson.Number, gjson.String, gjson.True, gjson.False:
+			fields[name] = result.Value()
+		default:
+			fields[name] = result.Raw
+		}
+		vars[name] = result.String()
+	}
+
+	// Extract values only used in the following steps, e.g. session tokens
+	for name, path := range s.Variables {
+		result := gjson.GetBytes(bodyBytes, path)
+		if !result.Exists() {
+			h.Log.Debugf("Path %q not found in response of step %q", path, s.Name)
+			setResult("extraction_failed", fields, tags)
+			return fields, tags, nil
+		}
+		vars[name] = result.String()
+	}
+
+	// Check the assertions in a deterministic order
+	paths := make([]string, 0, len(s.Assert))
+	for path := range s.Assert {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if actual := gjson.GetBytes(bodyBytes, path).String(); actual != s.Assert[path] {
+			h.Log.Debugf("Assertion for %q failed in step %q: expected %q but got %q", path, s.Name, s.Assert[path], actual)
+			fields["assertion_match"] = 0
+			setResult("assertion_failed", fields, tags)
+			return fields, tags, nil
+		}
+	}
+	if len(s.Assert) > 0 {
+		fields["assertion_match"] = 1
+	}
+
+	setResult("success", fields, tags)
+	return fields, tags, nil
+}
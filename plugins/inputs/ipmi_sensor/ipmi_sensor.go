@@ -44,7 +44,17 @@ type Ipmi struct {
 	UseCache      bool
 	CachePath     string
 
+	Client            string   `toml:"client"`
+	CipherSuite       uint8    `toml:"cipher_suite"`
+	Collect           []string `toml:"collect"`
+	MaxSessionsPerBMC int      `toml:"max_sessions_per_bmc"`
+
 	Log telegraf.Logger `toml:"-"`
+
+	bmcKey       []byte
+	bmcSlots     map[string]chan struct{}
+	sdrCache     map[string][]*sdrRecord
+	sdrCacheLock sync.Mutex
 }
 
 const cmd = "ipmitool"
@@ -54,6 +64,17 @@ func (*Ipmi) SampleConfig() string {
 }
 
 func (m *Ipmi) Init() error {
+	if m.Client == "" {
+		m.Client = "ipmitool"
+	}
+	switch m.Client {
+	case "ipmitool":
+	case "native":
+		return m.initNative()
+	default:
+		return fmt.Errorf("invalid client %q", m.Client)
+	}
+
 	// Set defaults
 	if m.Path == "" {
 		path, err := exec.LookPath(cmd)
@@ -76,6 +97,19 @@ func (m *Ipmi) Init() error {
 
 // Gather is the main execution function for the plugin
 func (m *Ipmi) Gather(acc telegraf.Accumulator) error {
+	if m.Client == "native" {
+		var wg sync.WaitGroup
+		for _, server := range m.Servers {
+			wg.Add(1)
+			go func(s string) {
+				defer wg.Done()
+				m.gatherNative(acc, s)
+			}(server)
+		}
+		wg.Wait()
+		return nil
+	}
+
 	if len(m.Path) == 0 {
 		return fmt.Errorf("ipmitool not found: verify that ipmitool is installed and that ipmitool is in your PATH")
 	}
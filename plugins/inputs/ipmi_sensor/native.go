@@ -0,0 +1,292 @@
+package ipmi_sensor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+)
+
+const defaultRMCPPort = "623"
+
+var nativeCollectors = []string{"sensors", "dcmi_power", "fru"}
+
+func (m *Ipmi) initNative() error {
+	if len(m.Servers) == 0 {
+		return errors.New("the native client requires servers to be specified")
+	}
+
+	if m.Privilege == "" {
+		m.Privilege = "ADMINISTRATOR"
+	}
+	if _, found := privilegeLevels[strings.ToUpper(m.Privilege)]; !found {
+		return fmt.Errorf("invalid privilege level %q", m.Privilege)
+	}
+	if m.CipherSuite == 0 {
+		m.CipherSuite = 3
+	}
+	if _, found := cipherSuites[m.CipherSuite]; !found {
+		return fmt.Errorf("unsupported cipher suite %d", m.CipherSuite)
+	}
+	if m.HexKey != "" {
+		key, err := hex.DecodeString(strings.TrimPrefix(m.HexKey, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid hex_key: %w", err)
+		}
+		m.bmcKey = key
+	}
+
+	if len(m.Collect) == 0 {
+		m.Collect = []string{"sensors"}
+	}
+	if err := choice.CheckSlice(m.Collect, nativeCollectors); err != nil {
+		return fmt.Errorf("invalid collect: %w", err)
+	}
+	if m.MaxSessionsPerBMC < 1 {
+		m.MaxSessionsPerBMC = 1
+	}
+
+	// Limit the number of concurrent sessions per BMC, also if the same BMC
+	// is specified multiple times, e.g. with different users
+	m.bmcSlots = make(map[string]chan struct{}, len(m.Servers))
+	for _, server := range m.Servers {
+		addr := nativeAddress(NewConnection(server, m.Privilege, m.HexKey).Hostname)
+		if _, found := m.bmcSlots[addr]; !found {
+			m.bmcSlots[addr] = make(chan struct{}, m.MaxSessionsPerBMC)
+		}
+	}
+	m.sdrCache = make(map[string][]*sdrRecord)
+
+	return nil
+}
+
+// nativeAddress returns the address of the BMC using the default RMCP port
+// if none is given
+func nativeAddress(hostname string) string {
+	if _, _, err := net.SplitHostPort(hostname); err == nil {
+		return hostname
+	}
+	return net.JoinHostPort(hostname, defaultRMCPPort)
+}
+
+// gatherNative collects the configured information from the server by
+// talking to the BMC directly. The collectors are distributed over up to
+// 'max_sessions_per_bmc' sessions.
+func (m *Ipmi) gatherNative(acc telegraf.Accumulator, server string) {
+	conn := NewConnection(server, m.Privilege, m.HexKey)
+	deadline := time.Now().Add(time.Duration(m.Timeout))
+
+	jobs := make(chan string, len(m.Collect))
+	for _, c := range m.Collect {
+		jobs <- c
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(m.MaxSessionsPerBMC, len(m.Collect)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.nativeWorker(acc, conn, deadline, jobs)
+		}()
+	}
+	wg.Wait()
+}
+
+func (m *Ipmi) nativeWorker(acc telegraf.Accumulator, conn *Connection, deadline time.Time, jobs <-chan string) {
+	job, ok := <-jobs
+	if !ok {
+		return
+	}
+
+	addr := nativeAddress(conn.Hostname)
+	slots := m.bmcSlots[addr]
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	case <-timer.C:
+		acc.AddError(fmt.Errorf("timeout waiting for a free session to %q", conn.Hostname))
+		return
+	}
+
+	privilege := privilegeLevels[strings.ToUpper(m.Privilege)]
+	s, err := newRMCPSession(addr, conn.Username, conn.Password, m.bmcKey, privilege, m.CipherSuite, deadline)
+	if err != nil {
+		acc.AddError(fmt.Errorf("opening session to %q failed: %w", conn.Hostname, err))
+		return
+	}
+	defer s.close()
+
+	for ; ok; job, ok = <-jobs {
+		var err error
+		switch job {
+		case "sensors":
+			err = m.gatherNativeSensors(acc, s, conn.Hostname)
+		case "dcmi_power":
+			err = m.gatherDCMIPower(acc, s, conn.Hostname)
+		case "fru":
+			err = m.gatherFRU(acc, s, conn.Hostname)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s from %q failed: %w", job, conn.Hostname, err))
+		}
+	}
+}
+
+// sensorRecords returns the sensor data records of the BMC, using the
+// records of previous gathers if caching is enabled
+func (m *Ipmi) sensorRecords(s *rmcpSession, hostname string) ([]*sdrRecord, error) {
+	if m.UseCache {
+		m.sdrCacheLock.Lock()
+		records, found := m.sdrCache[hostname]
+		m.sdrCacheLock.Unlock()
+		if found {
+			return records, nil
+		}
+	}
+
+	records, err := readSDRRepository(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.UseCache {
+		m.sdrCacheLock.Lock()
+		m.sdrCache[hostname] = records
+		m.sdrCacheLock.Unlock()
+	}
+	return records, nil
+}
+
+func (m *Ipmi) gatherNativeSensors(acc telegraf.Accumulator, s *rmcpSession, hostname string) error {
+	records, err := m.sensorRecords(s, hostname)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		// Sensors owned by other controllers require bridging
+		if r.ownerID != bmcSlaveAddr {
+			m.Log.Debugf("Skipping sensor %q of %q owned by controller 0x%02x", r.name, hostname, r.ownerID)
+			continue
+		}
+
+		reading, err := s.command(netFnSensor, r.ownerLUN, 0x2d, []byte{r.number})
+		if err != nil {
+			var ccErr *completionCodeError
+			if !errors.As(err, &ccErr) {
+				return fmt.Errorf("reading sensor %q failed: %w", r.name, err)
+			}
+			// Report sensors not present or not readable as without reading
+			reading = nil
+		}
+		m.addNativeSensor(acc, r, reading, hostname, time.Now())
+	}
+	return nil
+}
+
+// addNativeSensor adds the sensor reading using the same schema as for
+// the output of ipmitool
+func (m *Ipmi) addNativeSensor(acc telegraf.Accumulator, r *sdrRecord, reading []byte, hostname string, measuredAt time.Time) {
+	// Readings are invalid if scanning is disabled or unavailable
+	available := len(reading) >= 2 && reading[1]&0x40 != 0 && reading[1]&0x20 == 0
+
+	tags := map[string]string{
+		"name":   transform(r.name),
+		"server": hostname,
+	}
+
+	statusCode := "ns"
+	if available {
+		statusCode = "ok"
+		if r.readingType == readingTypeThreshold && len(reading) >= 3 {
+			var state string
+			state, statusCode = thresholdState(reading[2])
+			tags["threshold_state"] = state
+		}
+	}
+
+	var value float64
+	var states uint16
+	analog := available && r.analog()
+	if analog {
+		value = r.convert(reading[0])
+		tags["unit"] = transform(r.unit())
+	} else if available && len(reading) >= 3 {
+		states = uint16(reading[2])
+		if len(reading) >= 4 {
+			states |= uint16(reading[3]&0x7f) << 8
+		}
+		value = float64(states)
+	}
+
+	fields := make(map[string]interface{})
+	if m.MetricVersion == 2 {
+		tags["entity_id"] = fmt.Sprintf("%d.%d", r.entityID, r.entityInstance)
+		tags["status_code"] = statusCode
+		if !analog {
+			switch {
+			case !available:
+				tags["status_desc"] = "no_reading"
+			case states == 0:
+				tags["status_desc"] = statusCode
+			default:
+				tags["status_desc"] = fmt.Sprintf("0x%04x", states)
+			}
+			value = 0.0
+		}
+	} else {
+		if statusCode == "ok" {
+			fields["status"] = 1
+		} else {
+			fields["status"] = 0
+		}
+	}
+	fields["value"] = value
+
+	acc.AddFields("ipmi_sensor", fields, tags, measuredAt)
+}
+
+// gatherDCMIPower reads the system power statistics using the DCMI 'Get
+// Power Reading' command
+func (m *Ipmi) gatherDCMIPower(acc telegraf.Accumulator, s *rmcpSession, hostname string) error {
+	rsp, err := s.command(netFnGroupExt, 0, 0x02, []byte{0xdc, 0x01, 0x00, 0x00})
+	if err != nil {
+		return err
+	}
+	if len(rsp) < 18 || rsp[0] != 0xdc {
+		return errors.New("invalid DCMI power reading response")
+	}
+
+	fields := map[string]interface{}{
+		"current_watts":            int64(binary.LittleEndian.Uint16(rsp[1:3])),
+		"minimum_watts":            int64(binary.LittleEndian.Uint16(rsp[3:5])),
+		"maximum_watts":            int64(binary.LittleEndian.Uint16(rsp[5:7])),
+		"average_watts":            int64(binary.LittleEndian.Uint16(rsp[7:9])),
+		"sampling_period_ms":       int64(binary.LittleEndian.Uint32(rsp[13:17])),
+		"power_measurement_active": rsp[17]&0x40 != 0,
+	}
+	acc.AddFields("ipmi_dcmi_power", fields, map[string]string{"server": hostname})
+	return nil
+}
+
+// gatherFRU reports the inventory information of the builtin FRU device
+func (m *Ipmi) gatherFRU(acc telegraf.Accumulator, s *rmcpSession, hostname string) error {
+	fields, err := readFRU(s, 0)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		acc.AddFields("ipmi_fru", fields, map[string]string{"server": hostname})
+	}
+	return nil
+}
@@ -0,0 +1,581 @@
+package ipmi_sensor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeBMC implements the BMC side of the RMCP+ protocol for a fixed set of
+// sensors, DCMI power readings and FRU data
+type fakeBMC struct {
+	conn     *net.UDPConn
+	suite    cipherSuite
+	username string
+	password string
+
+	records  [][]byte
+	chunked  map[uint16]bool
+	readings map[uint8][]byte
+	fru      []byte
+
+	sync.Mutex
+	nextID    uint32
+	sessions  map[uint32]*fakeSession
+	active    int
+	maxActive int
+}
+
+type fakeSession struct {
+	// client session with swapped session IDs for sending and receiving
+	*rmcpSession
+	rm   []byte
+	rc   []byte
+	guid []byte
+	user []byte
+}
+
+func newFakeBMC(t *testing.T, suiteID uint8) *fakeBMC {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	bmc := &fakeBMC{
+		conn:     conn,
+		suite:    cipherSuites[suiteID],
+		username: "admin",
+		password: "secret",
+		records: [][]byte{
+			fullSDR(1, 0x20, 1, 0x37, 1, 0x00, 1, 1, 0, 0, "Inlet Temp"),
+			fullSDR(2, 0x20, 2, 0x07, 1, 0x00, 4, 6, 0, -2, "12V"),
+			fullSDR(3, 0x20, 3, 0x1d, 1, 0x00, 18, 60, 0, 0, "Fan 1"),
+			fullSDR(4, 0x20, 4, 0x1d, 2, 0x00, 18, 60, 0, 0, "Fan 2"),
+			compactSDR(5, 0x20, 5, 0x0a, 1, "PSU1 Status"),
+			compactSDR(6, 0x2c, 6, 0x2e, 1, "ME Health"),
+			{0x07, 0x00, 0x51, 0xc0, 0x02, 0x57, 0x01},
+		},
+		chunked: map[uint16]bool{2: true},
+		readings: map[uint8][]byte{
+			1: {24, 0xc0, 0x00},
+			2: {201, 0xc0, 0x08},
+			3: {80, 0xe0, 0x00},
+			5: {0x00, 0xc0, 0x01, 0x00},
+		},
+		fru:      fruData(),
+		nextID:   0x1000,
+		sessions: make(map[uint32]*fakeSession),
+	}
+	t.Cleanup(func() { conn.Close() })
+	go bmc.serve()
+	return bmc
+}
+
+func (b *fakeBMC) serve() {
+	buf := make([]byte, 2048)
+	for {
+		n, addr, err := b.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if rsp := b.handle(buf[:n]); rsp != nil {
+			_, _ = b.conn.WriteToUDP(rsp, addr)
+		}
+	}
+}
+
+func (b *fakeBMC) handle(packet []byte) []byte {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(packet) < 16 {
+		return nil
+	}
+	payloadType := packet[5] & 0x3f
+	payload := packet[16 : 16+int(binary.LittleEndian.Uint16(packet[14:16]))]
+	le32 := func(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+	hmacUser := func(data ...[]byte) []byte {
+		kuid := make([]byte, 20)
+		copy(kuid, b.password)
+		return (&rmcpSession{suite: b.suite}).hmac(kuid, data...)
+	}
+
+	switch payloadType {
+	case payloadOpenSessionReq:
+		b.nextID++
+		s := &fakeSession{rmcpSession: &rmcpSession{
+			suite:     b.suite,
+			consoleID: b.nextID,
+			managedID: binary.LittleEndian.Uint32(payload[4:8]),
+		}}
+		b.sessions[b.nextID] = s
+		rsp := []byte{payload[0], 0x00, payload[1], 0x00}
+		rsp = append(rsp, le32(s.managedID)...)
+		rsp = append(rsp, le32(s.consoleID)...)
+		rsp = append(rsp, payload[8:32]...)
+		out, _ := s.packet(payloadOpenSessionRsp, rsp)
+		return out
+	case payloadRAKP1:
+		s := b.sessions[binary.LittleEndian.Uint32(payload[4:8])]
+		s.rm = append([]byte{}, payload[8:24]...)
+		s.user = append([]byte{}, payload[24], payload[27])
+		s.user = append(s.user, payload[28:28+int(payload[27])]...)
+		if string(s.user[2:]) != b.username {
+			out, _ := s.packet(payloadRAKP2, []byte{payload[0], 0x0d, 0x00, 0x00, 0, 0, 0, 0})
+			return out
+		}
+		s.rc = bytes.Repeat([]byte{0xab}, 16)
+		s.guid = bytes.Repeat([]byte{0xcd}, 16)
+		rsp := []byte{payload[0], 0x00, 0x00, 0x00}
+		rsp = append(rsp, le32(s.managedID)...)
+		rsp = append(rsp, s.rc...)
+		rsp = append(rsp, s.guid...)
+		rsp = append(rsp, hmacUser(le32(s.managedID), le32(s.consoleID), s.rm, s.rc, s.guid, s.user)...)
+		out, _ := s.packet(payloadRAKP2, rsp)
+		return out
+	case payloadRAKP3:
+		s := b.sessions[binary.LittleEndian.Uint32(payload[4:8])]
+		if !bytes.Equal(payload[8:], hmacUser(s.rc, le32(s.managedID), s.user)) {
+			out, _ := s.packet(payloadRAKP4, []byte{payload[0], 0x0f, 0x00, 0x00, 0, 0, 0, 0})
+			return out
+		}
+		sik := hmacUser(s.rm, s.rc, s.user)
+		s.k1 = s.hmac(sik, bytes.Repeat([]byte{0x01}, 20))
+		s.k2 = s.hmac(sik, bytes.Repeat([]byte{0x02}, 20))
+		rsp := []byte{payload[0], 0x00, 0x00, 0x00}
+		rsp = append(rsp, le32(s.managedID)...)
+		rsp = append(rsp, s.hmac(sik, s.rm, le32(s.consoleID), s.guid)[:b.suite.icvLen]...)
+		out, _ := s.packet(payloadRAKP4, rsp)
+		s.active = true
+		b.active++
+		b.maxActive = max(b.maxActive, b.active)
+		return out
+	case payloadIPMI:
+		s, found := b.sessions[binary.LittleEndian.Uint32(packet[6:10])]
+		if !found || !s.active {
+			return nil
+		}
+		_, msg, err := s.parse(packet)
+		if err != nil {
+			return nil
+		}
+		netFn, cmd := msg[1]>>2, msg[5]
+		cc, data := b.command(s, netFn, cmd, msg[6:len(msg)-1])
+		rsp := []byte{remoteSWID, (netFn+1)<<2 | msg[1]&0x03}
+		rsp = append(rsp, checksum(rsp))
+		body := append([]byte{bmcSlaveAddr, msg[4], cmd, cc}, data...)
+		rsp = append(rsp, body...)
+		rsp = append(rsp, checksum(body))
+		out, _ := s.packet(payloadIPMI, rsp)
+		if netFn == netFnApp && cmd == 0x3c {
+			s.active = false
+			b.active--
+		}
+		return out
+	}
+	return nil
+}
+
+func (b *fakeBMC) command(s *fakeSession, netFn, cmd uint8, data []byte) (uint8, []byte) {
+	switch {
+	case netFn == netFnApp && cmd == 0x3b:
+		return 0x00, data[:1]
+	case netFn == netFnApp && cmd == 0x3c:
+		if binary.LittleEndian.Uint32(data) != s.consoleID {
+			return 0x87, nil
+		}
+		return 0x00, nil
+	case netFn == netFnStorage && cmd == 0x22:
+		return 0x00, []byte{0x01, 0x00}
+	case netFn == netFnStorage && cmd == 0x23:
+		id := binary.LittleEndian.Uint16(data[2:4])
+		offset, count := int(data[4]), int(data[5])
+		idx := int(id)
+		if id == 0 {
+			idx = 1
+		}
+		if idx > len(b.records) {
+			return 0xcb, nil
+		}
+		if count == 0xff && b.chunked[uint16(idx)] {
+			return 0xca, nil
+		}
+		record := b.records[idx-1]
+		next := uint16(idx + 1)
+		if idx == len(b.records) {
+			next = 0xffff
+		}
+		end := min(len(record), offset+count)
+		return 0x00, append(binary.LittleEndian.AppendUint16(nil, next), record[offset:end]...)
+	case netFn == netFnSensor && cmd == 0x2d:
+		reading, found := b.readings[data[0]]
+		if !found {
+			return 0xcb, nil
+		}
+		return 0x00, reading
+	case netFn == netFnGroupExt && cmd == 0x02:
+		rsp := []byte{0xdc, 0xd2, 0x00, 0x64, 0x00, 0x2c, 0x01, 0xc8, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe8, 0x03, 0x00, 0x00, 0x40}
+		return 0x00, rsp
+	case netFn == netFnStorage && cmd == 0x10:
+		return 0x00, []byte{byte(len(b.fru)), byte(len(b.fru) >> 8), 0x00}
+	case netFn == netFnStorage && cmd == 0x11:
+		offset := int(binary.LittleEndian.Uint16(data[1:3]))
+		end := min(len(b.fru), offset+int(data[3]))
+		return 0x00, append([]byte{byte(end - offset)}, b.fru[offset:end]...)
+	}
+	return 0xc1, nil
+}
+
+func fullSDR(id uint16, owner, number, entity, instance, units1, unit uint8, m, b, rExp int32, name string) []byte {
+	record := make([]byte, 48+len(name))
+	binary.LittleEndian.PutUint16(record, id)
+	record[2] = 0x51
+	record[3] = sdrTypeFull
+	record[4] = byte(len(record) - 5)
+	record[5] = owner
+	record[7] = number
+	record[8] = entity
+	record[9] = instance
+	record[13] = readingTypeThreshold
+	record[20] = units1
+	record[21] = unit
+	record[24] = byte(m)
+	record[25] = byte(m>>2) & 0xc0
+	record[26] = byte(b)
+	record[27] = byte(b>>2) & 0xc0
+	record[29] = byte(rExp&0x0f) << 4
+	record[47] = 0xc0 | byte(len(name))
+	copy(record[48:], name)
+	return record
+}
+
+func compactSDR(id uint16, owner, number, entity, instance uint8, name string) []byte {
+	record := make([]byte, 32+len(name))
+	binary.LittleEndian.PutUint16(record, id)
+	record[2] = 0x51
+	record[3] = sdrTypeCompact
+	record[4] = byte(len(record) - 5)
+	record[5] = owner
+	record[7] = number
+	record[8] = entity
+	record[9] = instance
+	record[12] = 0x08
+	record[13] = 0x6f
+	record[20] = 0xc0
+	record[31] = 0xc0 | byte(len(name))
+	copy(record[32:], name)
+	return record
+}
+
+func fruArea(header []byte, fields ...string) []byte {
+	area := append([]byte{}, header...)
+	for _, f := range fields {
+		area = append(area, 0xc0|byte(len(f)))
+		area = append(area, f...)
+	}
+	area = append(area, 0xc1)
+	for (len(area)+1)%8 != 0 {
+		area = append(area, 0x00)
+	}
+	area = append(area, 0x00)
+	area[1] = byte(len(area) / 8)
+	return area
+}
+
+func fruData() []byte {
+	chassis := fruArea([]byte{0x01, 0x00, 0x17}, "CH-1234", "CZ0001")
+	board := fruArea([]byte{0x01, 0x00, 0x19, 0xc0, 0x9c, 0xc0}, "ACME", "Mainboard X1", "BRD0001", "BP-42")
+	product := fruArea([]byte{0x01, 0x00, 0x19}, "ACME", "Server 9000", "SRV-9000", "A01", "SRV0001")
+
+	data := []byte{0x01, 0x00, 0x01, byte(1 + len(chassis)/8), byte(1 + (len(chassis)+len(board))/8), 0x00, 0x00, 0x00}
+	data = append(data, chassis...)
+	data = append(data, board...)
+	return append(data, product...)
+}
+
+func TestNativeGather(t *testing.T) {
+	for _, suite := range []uint8{3, 17} {
+		bmc := newFakeBMC(t, suite)
+		server := "admin:secret@lanplus(" + bmc.conn.LocalAddr().String() + ")"
+
+		plugin := &Ipmi{
+			Client:        "native",
+			CipherSuite:   suite,
+			Servers:       []string{server},
+			MetricVersion: 2,
+			Collect:       []string{"sensors", "dcmi_power", "fru"},
+			Timeout:       config.Duration(5 * time.Second),
+			Log:           testutil.Logger{},
+		}
+		require.NoError(t, plugin.Init())
+
+		var acc testutil.Accumulator
+		require.NoError(t, plugin.Gather(&acc))
+		require.Empty(t, acc.Errors)
+
+		host := bmc.conn.LocalAddr().String()
+		expected := []telegraf.Metric{
+			testutil.MustMetric(
+				"ipmi_sensor",
+				map[string]string{
+					"name":            "inlet_temp",
+					"server":          host,
+					"entity_id":       "55.1",
+					"status_code":     "ok",
+					"threshold_state": "ok",
+					"unit":            "degrees_c",
+				},
+				map[string]interface{}{"value": float64(24)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"ipmi_sensor",
+				map[string]string{
+					"name":            "12v",
+					"server":          host,
+					"entity_id":       "7.1",
+					"status_code":     "nc",
+					"threshold_state": "upper_non_critical",
+					"unit":            "volts",
+				},
+				map[string]interface{}{"value": float64(12.06)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"ipmi_sensor",
+				map[string]string{
+					"name":        "fan_1",
+					"server":      host,
+					"entity_id":   "29.1",
+					"status_code": "ns",
+					"status_desc": "no_reading",
+				},
+				map[string]interface{}{"value": float64(0)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"ipmi_sensor",
+				map[string]string{
+					"name":        "fan_2",
+					"server":      host,
+					"entity_id":   "29.2",
+					"status_code": "ns",
+					"status_desc": "no_reading",
+				},
+				map[string]interface{}{"value": float64(0)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"ipmi_sensor",
+				map[string]string{
+					"name":        "psu1_status",
+					"server":      host,
+					"entity_id":   "10.1",
+					"status_code": "ok",
+					"status_desc": "0x0001",
+				},
+				map[string]interface{}{"value": float64(0)},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"ipmi_dcmi_power",
+				map[string]string{"server": host},
+				map[string]interface{}{
+					"current_watts":            int64(210),
+					"minimum_watts":            int64(100),
+					"maximum_watts":            int64(300),
+					"average_watts":            int64(200),
+					"sampling_period_ms":       int64(1000),
+					"power_measurement_active": true,
+				},
+				time.Unix(0, 0),
+			),
+			testutil.MustMetric(
+				"ipmi_fru",
+				map[string]string{"server": host},
+				map[string]interface{}{
+					"chassis_type":         int64(0x17),
+					"chassis_part_number":  "CH-1234",
+					"chassis_serial":       "CZ0001",
+					"board_mfg_date":       "2020-01-01T00:00:00Z",
+					"board_manufacturer":   "ACME",
+					"board_product":        "Mainboard X1",
+					"board_serial":         "BRD0001",
+					"board_part_number":    "BP-42",
+					"product_manufacturer": "ACME",
+					"product_name":         "Server 9000",
+					"product_part_number":  "SRV-9000",
+					"product_version":      "A01",
+					"product_serial":       "SRV0001",
+				},
+				time.Unix(0, 0),
+			),
+		}
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+		// All collectors share a single session by default which is closed
+		// after gathering
+		bmc.Lock()
+		require.Equal(t, 1, bmc.maxActive)
+		require.Zero(t, bmc.active)
+		bmc.Unlock()
+	}
+}
+
+func TestNativeSessionLimit(t *testing.T) {
+	bmc := newFakeBMC(t, 3)
+	server := "admin:secret@lanplus(" + bmc.conn.LocalAddr().String() + ")"
+
+	plugin := &Ipmi{
+		Client:            "native",
+		Servers:           []string{server, server, server},
+		Collect:           []string{"sensors", "dcmi_power", "fru"},
+		MaxSessionsPerBMC: 2,
+		Timeout:           config.Duration(5 * time.Second),
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	bmc.Lock()
+	defer bmc.Unlock()
+	require.LessOrEqual(t, bmc.maxActive, 2)
+	require.Zero(t, bmc.active)
+}
+
+func TestNativeMetricVersion1(t *testing.T) {
+	bmc := newFakeBMC(t, 3)
+	server := "admin:secret@lanplus(" + bmc.conn.LocalAddr().String() + ")"
+
+	plugin := &Ipmi{
+		Client:  "native",
+		Servers: []string{server},
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	acc.AssertContainsTaggedFields(t, "ipmi_sensor",
+		map[string]interface{}{"status": 1, "value": float64(24)},
+		map[string]string{"name": "inlet_temp", "server": bmc.conn.LocalAddr().String(), "unit": "degrees_c", "threshold_state": "ok"},
+	)
+	acc.AssertContainsTaggedFields(t, "ipmi_sensor",
+		map[string]interface{}{"status": 0, "value": float64(12.06)},
+		map[string]string{"name": "12v", "server": bmc.conn.LocalAddr().String(), "unit": "volts", "threshold_state": "upper_non_critical"},
+	)
+	acc.AssertContainsTaggedFields(t, "ipmi_sensor",
+		map[string]interface{}{"status": 1, "value": float64(1)},
+		map[string]string{"name": "psu1_status", "server": bmc.conn.LocalAddr().String()},
+	)
+	require.False(t, acc.HasMeasurement("ipmi_dcmi_power"))
+	require.False(t, acc.HasMeasurement("ipmi_fru"))
+}
+
+func TestNativeWrongPassword(t *testing.T) {
+	bmc := newFakeBMC(t, 3)
+
+	plugin := &Ipmi{
+		Client:  "native",
+		Servers: []string{"admin:wrong@lanplus(" + bmc.conn.LocalAddr().String() + ")"},
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "invalid authentication code")
+}
+
+func TestNativeInitFail(t *testing.T) {
+	plugin := &Ipmi{Client: "native"}
+	require.ErrorContains(t, plugin.Init(), "requires servers")
+
+	plugin = &Ipmi{
+		Client:  "native",
+		Servers: []string{"admin:secret@lanplus(127.0.0.1)"},
+		Collect: []string{"sensors", "sel"},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid collect")
+
+	plugin = &Ipmi{
+		Client:      "native",
+		Servers:     []string{"admin:secret@lanplus(127.0.0.1)"},
+		CipherSuite: 1,
+	}
+	require.ErrorContains(t, plugin.Init(), "unsupported cipher suite")
+}
+
+func TestSensorConversion(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   sdrRecord
+		raw      uint8
+		expected float64
+	}{
+		{
+			name:     "unsigned",
+			record:   sdrRecord{m: 1},
+			raw:      42,
+			expected: 42,
+		},
+		{
+			name:     "scaled",
+			record:   sdrRecord{m: 5, rExp: -2},
+			raw:      61,
+			expected: 3.05,
+		},
+		{
+			name:     "offset",
+			record:   sdrRecord{m: 2, b: 5, bExp: 1, rExp: -1},
+			raw:      100,
+			expected: 25,
+		},
+		{
+			name:     "two's complement",
+			record:   sdrRecord{analogFormat: 0x02, m: 1},
+			raw:      0xf6,
+			expected: -10,
+		},
+		{
+			name:     "one's complement",
+			record:   sdrRecord{analogFormat: 0x01, m: 1},
+			raw:      0xf5,
+			expected: -10,
+		},
+		{
+			name:     "inverse",
+			record:   sdrRecord{m: 1, linearization: 7},
+			raw:      4,
+			expected: 0.25,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.expected, tt.record.convert(tt.raw), 1e-9)
+		})
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	require.Equal(t, "CPU Temp", decodeString(0x03, []byte("CPU Temp\x00\x00")))
+	require.Equal(t, "12-34.5", decodeString(0x01, []byte{0x12, 0xb3, 0x4c, 0x5a}))
+	// "IPMI" packed as 6-bit ASCII
+	require.Equal(t, "IPMI", decodeString(0x02, []byte{0x29, 0xdc, 0xa6}))
+	require.Equal(t, "0102", decodeString(0x00, []byte{0x01, 0x02}))
+}
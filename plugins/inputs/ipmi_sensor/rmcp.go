@@ -0,0 +1,458 @@
+package ipmi_sensor
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the IPMI v2.0 cipher suite 3
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net"
+	"time"
+)
+
+// IPMI v2.0 RMCP+ protocol, see the "Intelligent Platform Management
+// Interface Specification Second Generation v2.0", section 13
+const (
+	rmcpVersion      = 0x06
+	rmcpClassIPMI    = 0x07
+	authTypeRMCPPlus = 0x06
+
+	payloadIPMI           = 0x00
+	payloadOpenSessionReq = 0x10
+	payloadOpenSessionRsp = 0x11
+	payloadRAKP1          = 0x12
+	payloadRAKP2          = 0x13
+	payloadRAKP3          = 0x14
+	payloadRAKP4          = 0x15
+	payloadEncrypted      = 0x80
+	payloadAuthenticated  = 0x40
+
+	bmcSlaveAddr = 0x20
+	remoteSWID   = 0x81
+
+	netFnSensor   = 0x04
+	netFnApp      = 0x06
+	netFnStorage  = 0x0a
+	netFnGroupExt = 0x2c
+
+	privilegeUser = 0x02
+
+	// time to wait for a response before retransmitting a request
+	rmcpRetryInterval = time.Second
+)
+
+var privilegeLevels = map[string]uint8{
+	"CALLBACK":      0x01,
+	"USER":          0x02,
+	"OPERATOR":      0x03,
+	"ADMINISTRATOR": 0x04,
+}
+
+// cipherSuite describes the authentication, integrity and confidentiality
+// algorithms negotiated for a session
+type cipherSuite struct {
+	authAlg      uint8
+	integrityAlg uint8
+	confAlg      uint8
+	hash         func() hash.Hash
+	icvLen       int
+}
+
+var cipherSuites = map[uint8]cipherSuite{
+	// RAKP-HMAC-SHA1, HMAC-SHA1-96, AES-CBC-128
+	3: {authAlg: 0x01, integrityAlg: 0x01, confAlg: 0x01, hash: sha1.New, icvLen: 12},
+	// RAKP-HMAC-SHA256, HMAC-SHA256-128, AES-CBC-128
+	17: {authAlg: 0x03, integrityAlg: 0x04, confAlg: 0x01, hash: sha256.New, icvLen: 16},
+}
+
+// completionCodeError is returned if the BMC answered a command with a
+// completion code other than success
+type completionCodeError struct {
+	cmd  uint8
+	code uint8
+}
+
+func (e *completionCodeError) Error() string {
+	return fmt.Sprintf("command 0x%02x failed with completion code 0x%02x", e.cmd, e.code)
+}
+
+func hasCompletionCode(err error, codes ...uint8) bool {
+	var ccErr *completionCodeError
+	if !errors.As(err, &ccErr) {
+		return false
+	}
+	return bytes.IndexByte(codes, ccErr.code) >= 0
+}
+
+// rmcpSession is an authenticated and encrypted IPMI v2.0 session to a BMC
+type rmcpSession struct {
+	conn     net.Conn
+	suite    cipherSuite
+	deadline time.Time
+
+	consoleID uint32
+	managedID uint32
+	seq       uint32
+	rqSeq     uint8
+	tag       uint8
+	k1        []byte
+	k2        []byte
+	active    bool
+}
+
+// newRMCPSession connects to the BMC at the given address and establishes a
+// session at the given privilege level. All communication has to be finished
+// before the deadline.
+func newRMCPSession(address, username, password string, bmcKey []byte, privilege, suiteID uint8, deadline time.Time) (*rmcpSession, error) {
+	suite, found := cipherSuites[suiteID]
+	if !found {
+		return nil, fmt.Errorf("unsupported cipher suite %d", suiteID)
+	}
+	if len(username) > 16 {
+		return nil, errors.New("username exceeds 16 characters")
+	}
+	if len(password) > 20 {
+		return nil, errors.New("password exceeds 20 characters")
+	}
+
+	conn, err := net.DialTimeout("udp", address, time.Until(deadline))
+	if err != nil {
+		return nil, err
+	}
+	s := &rmcpSession{
+		conn:     conn,
+		suite:    suite,
+		deadline: deadline,
+	}
+	if err := s.open(username, password, bmcKey, privilege); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// open performs the RMCP+ session establishment and RAKP key exchange
+func (s *rmcpSession) open(username, password string, bmcKey []byte, privilege uint8) error {
+	var buf [4]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return err
+	}
+	s.consoleID = binary.LittleEndian.Uint32(buf[:]) | 1
+
+	// Open session
+	s.tag++
+	req := []byte{s.tag, privilege, 0x00, 0x00}
+	req = binary.LittleEndian.AppendUint32(req, s.consoleID)
+	req = append(req,
+		0x00, 0x00, 0x00, 0x08, s.suite.authAlg, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x08, s.suite.integrityAlg, 0x00, 0x00, 0x00,
+		0x02, 0x00, 0x00, 0x08, s.suite.confAlg, 0x00, 0x00, 0x00,
+	)
+	rsp, err := s.exchangeSessionless(payloadOpenSessionReq, payloadOpenSessionRsp, req)
+	if err != nil {
+		return fmt.Errorf("open session failed: %w", err)
+	}
+	if len(rsp) < 36 || binary.LittleEndian.Uint32(rsp[4:8]) != s.consoleID {
+		return errors.New("open session failed: invalid response")
+	}
+	if rsp[16] != s.suite.authAlg || rsp[24] != s.suite.integrityAlg || rsp[32] != s.suite.confAlg {
+		return errors.New("open session failed: cipher suite not accepted by BMC")
+	}
+	s.managedID = binary.LittleEndian.Uint32(rsp[8:12])
+
+	// RAKP message 1 and 2
+	kuid := make([]byte, 20)
+	copy(kuid, password)
+	kg := kuid
+	if len(bmcKey) > 0 {
+		kg = make([]byte, 20)
+		copy(kg, bmcKey)
+	}
+	rm := make([]byte, 16)
+	if _, err := rand.Read(rm); err != nil {
+		return err
+	}
+	// Use name-only lookup for the user
+	role := privilege | 0x10
+	user := append([]byte{role, 0x00, 0x00, byte(len(username))}, username...)
+	userHMAC := append([]byte{role, byte(len(username))}, username...)
+
+	s.tag++
+	req = []byte{s.tag, 0x00, 0x00, 0x00}
+	req = binary.LittleEndian.AppendUint32(req, s.managedID)
+	req = append(req, rm...)
+	req = append(req, user...)
+	rsp, err = s.exchangeSessionless(payloadRAKP1, payloadRAKP2, req)
+	if err != nil {
+		return fmt.Errorf("RAKP1 failed: %w", err)
+	}
+	hashLen := s.suite.hash().Size()
+	if len(rsp) < 40+hashLen || binary.LittleEndian.Uint32(rsp[4:8]) != s.consoleID {
+		return errors.New("RAKP2 failed: invalid response")
+	}
+	rc, guid := rsp[8:24], rsp[24:40]
+	expected := s.hmac(kuid,
+		binary.LittleEndian.AppendUint32(nil, s.consoleID),
+		binary.LittleEndian.AppendUint32(nil, s.managedID),
+		rm, rc, guid, userHMAC,
+	)
+	if !hmac.Equal(expected, rsp[40:40+hashLen]) {
+		return errors.New("RAKP2 failed: invalid authentication code, check the password")
+	}
+
+	// Derive the session keys
+	sik := s.hmac(kg, rm, rc, userHMAC)
+	s.k1 = s.hmac(sik, bytes.Repeat([]byte{0x01}, 20))
+	s.k2 = s.hmac(sik, bytes.Repeat([]byte{0x02}, 20))
+
+	// RAKP message 3 and 4
+	s.tag++
+	req = []byte{s.tag, 0x00, 0x00, 0x00}
+	req = binary.LittleEndian.AppendUint32(req, s.managedID)
+	req = append(req, s.hmac(kuid, rc, binary.LittleEndian.AppendUint32(nil, s.consoleID), userHMAC)...)
+	rsp, err = s.exchangeSessionless(payloadRAKP3, payloadRAKP4, req)
+	if err != nil {
+		return fmt.Errorf("RAKP3 failed: %w", err)
+	}
+	if len(rsp) < 8+s.suite.icvLen || binary.LittleEndian.Uint32(rsp[4:8]) != s.consoleID {
+		return errors.New("RAKP4 failed: invalid response")
+	}
+	icv := s.hmac(sik, rm, binary.LittleEndian.AppendUint32(nil, s.managedID), guid)[:s.suite.icvLen]
+	if !hmac.Equal(icv, rsp[8:8+s.suite.icvLen]) {
+		return errors.New("RAKP4 failed: invalid integrity check value")
+	}
+	s.active = true
+
+	// Sessions start at user level and need to be elevated explicitly
+	if privilege > privilegeUser {
+		if _, err := s.command(netFnApp, 0, 0x3b, []byte{privilege}); err != nil {
+			return fmt.Errorf("setting session privilege failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// close terminates the session and the underlying connection
+func (s *rmcpSession) close() {
+	if s.active {
+		_, _ = s.command(netFnApp, 0, 0x3c, binary.LittleEndian.AppendUint32(nil, s.managedID))
+	}
+	_ = s.conn.Close()
+}
+
+// command sends an IPMI request to the BMC and returns the response data
+// following the completion code
+func (s *rmcpSession) command(netFn, lun, cmd uint8, data []byte) ([]byte, error) {
+	s.rqSeq = (s.rqSeq + 1) & 0x3f
+	rqSeq := s.rqSeq
+
+	msg := []byte{bmcSlaveAddr, netFn<<2 | lun&0x03}
+	msg = append(msg, checksum(msg))
+	body := append([]byte{remoteSWID, rqSeq << 2, cmd}, data...)
+	msg = append(msg, body...)
+	msg = append(msg, checksum(body))
+
+	rsp, err := s.exchange(payloadIPMI, msg, func(payloadType uint8, payload []byte) bool {
+		return payloadType == payloadIPMI && len(payload) >= 8 && payload[4]>>2 == rqSeq && payload[5] == cmd
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rsp[6] != 0x00 {
+		return nil, &completionCodeError{cmd: cmd, code: rsp[6]}
+	}
+	return rsp[7 : len(rsp)-1], nil
+}
+
+// exchangeSessionless sends a session setup message and waits for the
+// response carrying the same message tag
+func (s *rmcpSession) exchangeSessionless(payloadType, responseType uint8, payload []byte) ([]byte, error) {
+	tag := payload[0]
+	rsp, err := s.exchange(payloadType, payload, func(t uint8, p []byte) bool {
+		return t == responseType && len(p) >= 8 && p[0] == tag
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rsp[1] != 0x00 {
+		return nil, fmt.Errorf("BMC returned status code 0x%02x", rsp[1])
+	}
+	return rsp, nil
+}
+
+// exchange sends the payload and retransmits it until a matching response is
+// received or the deadline of the session is reached
+func (s *rmcpSession) exchange(payloadType uint8, payload []byte, match func(uint8, []byte) bool) ([]byte, error) {
+	buf := make([]byte, 2048)
+	for time.Now().Before(s.deadline) {
+		packet, err := s.packet(payloadType, payload)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.conn.Write(packet); err != nil {
+			return nil, err
+		}
+
+		timeout := time.Now().Add(rmcpRetryInterval)
+		if timeout.After(s.deadline) {
+			timeout = s.deadline
+		}
+		if err := s.conn.SetReadDeadline(timeout); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := s.conn.Read(buf)
+			if err != nil {
+				var nerr net.Error
+				if errors.As(err, &nerr) && nerr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			// Silently drop malformed, unauthenticated and stale responses
+			t, p, err := s.parse(buf[:n])
+			if err == nil && match(t, p) {
+				return p, nil
+			}
+		}
+	}
+	return nil, errors.New("timeout waiting for response")
+}
+
+// packet wraps the payload into a RMCP+ packet, encrypting and
+// authenticating it once the session is active
+func (s *rmcpSession) packet(payloadType uint8, payload []byte) ([]byte, error) {
+	var sessionID, seq uint32
+	if s.active {
+		payloadType |= payloadEncrypted | payloadAuthenticated
+		sessionID = s.managedID
+		s.seq++
+		seq = s.seq
+
+		var err error
+		if payload, err = s.encrypt(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := []byte{rmcpVersion, 0x00, 0xff, rmcpClassIPMI, authTypeRMCPPlus, payloadType}
+	buf = binary.LittleEndian.AppendUint32(buf, sessionID)
+	buf = binary.LittleEndian.AppendUint32(buf, seq)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(payload)))
+	buf = append(buf, payload...)
+	if !s.active {
+		return buf, nil
+	}
+
+	// Pad the authenticated part, i.e. from the session header up to and
+	// including the pad length and next header, to a multiple of four bytes
+	pad := (4 - (len(buf)-4+2)%4) % 4
+	buf = append(buf, bytes.Repeat([]byte{0xff}, pad)...)
+	buf = append(buf, byte(pad), rmcpClassIPMI)
+	return append(buf, s.authCode(buf[4:])...), nil
+}
+
+// parse validates a received RMCP+ packet and returns the decrypted payload
+func (s *rmcpSession) parse(buf []byte) (uint8, []byte, error) {
+	if len(buf) < 16 || buf[0] != rmcpVersion || buf[3] != rmcpClassIPMI || buf[4] != authTypeRMCPPlus {
+		return 0, nil, errors.New("invalid packet")
+	}
+	payloadType := buf[5]
+	length := int(binary.LittleEndian.Uint16(buf[14:16]))
+	if len(buf) < 16+length {
+		return 0, nil, errors.New("truncated packet")
+	}
+	payload := buf[16 : 16+length]
+
+	if !s.active {
+		return payloadType, payload, nil
+	}
+	if payloadType&payloadAuthenticated == 0 || payloadType&payloadEncrypted == 0 {
+		return 0, nil, errors.New("unauthenticated packet")
+	}
+	if binary.LittleEndian.Uint32(buf[6:10]) != s.consoleID {
+		return 0, nil, errors.New("packet for other session")
+	}
+	if len(buf) < 16+length+2+s.suite.icvLen {
+		return 0, nil, errors.New("truncated packet")
+	}
+	authenticated := buf[4 : len(buf)-s.suite.icvLen]
+	if !hmac.Equal(s.authCode(authenticated), buf[len(buf)-s.suite.icvLen:]) {
+		return 0, nil, errors.New("invalid authentication code")
+	}
+
+	payload, err := s.decrypt(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	return payloadType &^ (payloadEncrypted | payloadAuthenticated), payload, nil
+}
+
+func (s *rmcpSession) authCode(data []byte) []byte {
+	return s.hmac(s.k1, data)[:s.suite.icvLen]
+}
+
+// encrypt encrypts the payload using AES-CBC-128 with a random IV
+func (s *rmcpSession) encrypt(payload []byte) ([]byte, error) {
+	block, err := aes.NewCipher(s.k2[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	// Confidentiality trailer with pad bytes 1, 2, 3... followed by the
+	// pad length
+	pad := (aes.BlockSize - (len(payload)+1)%aes.BlockSize) % aes.BlockSize
+	plain := append([]byte{}, payload...)
+	for i := 1; i <= pad; i++ {
+		plain = append(plain, byte(i))
+	}
+	plain = append(plain, byte(pad))
+
+	out := make([]byte, aes.BlockSize+len(plain))
+	if _, err := rand.Read(out[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], plain)
+	return out, nil
+}
+
+func (s *rmcpSession) decrypt(payload []byte) ([]byte, error) {
+	if len(payload) < 2*aes.BlockSize || len(payload)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid encrypted payload length")
+	}
+	block, err := aes.NewCipher(s.k2[:16])
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(payload)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, payload[:aes.BlockSize]).CryptBlocks(plain, payload[aes.BlockSize:])
+
+	pad := int(plain[len(plain)-1])
+	if pad >= aes.BlockSize || pad+1 > len(plain) {
+		return nil, errors.New("invalid confidentiality pad")
+	}
+	return plain[:len(plain)-pad-1], nil
+}
+
+func (s *rmcpSession) hmac(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(s.suite.hash, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// checksum computes the two's complement checksum of IPMI messages
+func checksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return -sum
+}
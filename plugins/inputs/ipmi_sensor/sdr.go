@@ -0,0 +1,472 @@
+package ipmi_sensor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	sdrTypeFull    = 0x01
+	sdrTypeCompact = 0x02
+
+	readingTypeThreshold = 0x01
+
+	// analog data format indicating a sensor without analog reading
+	analogFormatNone = 0x03
+)
+
+// Sensor unit type codes, see table 43-15 of the IPMI specification
+var sensorUnits = []string{
+	"unspecified", "degrees C", "degrees F", "degrees K", "Volts", "Amps", "Watts", "Joules",
+	"Coulombs", "VA", "Nits", "lumen", "lux", "Candela", "kPa", "PSI", "Newton", "CFM", "RPM",
+	"Hz", "microsecond", "millisecond", "second", "minute", "hour", "day", "week", "mil",
+	"inches", "feet", "cu in", "cu feet", "mm", "cm", "m", "cu cm", "cu m", "liters",
+	"fluid ounce", "radians", "steradians", "revolutions", "cycles", "gravities", "ounce",
+	"pound", "ft-lb", "oz-in", "gauss", "gilberts", "henry", "millihenry", "farad",
+	"microfarad", "ohms", "siemens", "mole", "becquerel", "PPM", "reserved", "Decibels", "DbA",
+	"DbC", "gray", "sievert", "color temp deg K", "bit", "kilobit", "megabit", "gigabit", "byte",
+	"kilobyte", "megabyte", "gigabyte", "word", "dword", "qword", "line", "hit", "miss", "retry",
+	"reset", "overflow", "underrun", "collision", "packets", "messages", "characters", "error",
+	"correctable error", "uncorrectable error", "fatal error", "grams",
+}
+
+// Threshold states of the 'Get Sensor Reading' response ordered by severity
+var thresholdStates = []struct {
+	mask  uint8
+	name  string
+	level string
+}{
+	{mask: 0x20, name: "upper_non_recoverable", level: "nr"},
+	{mask: 0x04, name: "lower_non_recoverable", level: "nr"},
+	{mask: 0x10, name: "upper_critical", level: "cr"},
+	{mask: 0x02, name: "lower_critical", level: "cr"},
+	{mask: 0x08, name: "upper_non_critical", level: "nc"},
+	{mask: 0x01, name: "lower_non_critical", level: "nc"},
+}
+
+// sdrRecord holds the information of a full or compact sensor data record
+// required to read and convert the sensor value
+type sdrRecord struct {
+	recordType     uint8
+	ownerID        uint8
+	ownerLUN       uint8
+	number         uint8
+	entityID       uint8
+	entityInstance uint8
+	readingType    uint8
+	analogFormat   uint8
+	rateUnit       uint8
+	modifierMode   uint8
+	percentage     bool
+	baseUnit       uint8
+	modifierUnit   uint8
+	linearization  uint8
+	m              int32
+	b              int32
+	bExp           int32
+	rExp           int32
+	name           string
+}
+
+// parseSDR decodes full and compact sensor records, other records types are
+// ignored
+func parseSDR(data []byte) (*sdrRecord, bool) {
+	if len(data) < 5 {
+		return nil, false
+	}
+
+	var nameOffset int
+	switch data[3] {
+	case sdrTypeFull:
+		nameOffset = 47
+	case sdrTypeCompact:
+		nameOffset = 31
+	default:
+		return nil, false
+	}
+	if len(data) <= nameOffset {
+		return nil, false
+	}
+
+	r := &sdrRecord{
+		recordType:     data[3],
+		ownerID:        data[5],
+		ownerLUN:       data[6] & 0x03,
+		number:         data[7],
+		entityID:       data[8],
+		entityInstance: data[9] & 0x7f,
+		readingType:    data[13],
+		analogFormat:   data[20] >> 6,
+		rateUnit:       (data[20] >> 3) & 0x07,
+		modifierMode:   (data[20] >> 1) & 0x03,
+		percentage:     data[20]&0x01 != 0,
+		baseUnit:       data[21],
+		modifierUnit:   data[22],
+	}
+	if r.recordType == sdrTypeCompact {
+		r.analogFormat = analogFormatNone
+	} else {
+		r.linearization = data[23] & 0x7f
+		r.m = signExtend(int32(data[24])|int32(data[25]&0xc0)<<2, 10)
+		r.b = signExtend(int32(data[26])|int32(data[27]&0xc0)<<2, 10)
+		r.rExp = signExtend(int32(data[29]>>4), 4)
+		r.bExp = signExtend(int32(data[29]&0x0f), 4)
+	}
+
+	code := data[nameOffset]
+	end := nameOffset + 1 + int(code&0x1f)
+	if end > len(data) {
+		end = len(data)
+	}
+	r.name = decodeString(code>>6, data[nameOffset+1:end])
+	return r, true
+}
+
+// analog returns true for threshold based sensors with an analog reading
+func (r *sdrRecord) analog() bool {
+	return r.readingType == readingTypeThreshold && r.analogFormat != analogFormatNone
+}
+
+// convert converts a raw reading into the sensor value using the
+// y = L[(M*x + B*10^Bexp) * 10^Rexp] formula of the IPMI specification
+func (r *sdrRecord) convert(raw uint8) float64 {
+	var x float64
+	switch r.analogFormat {
+	case 0x01:
+		// one's complement
+		if raw&0x80 != 0 {
+			x = -float64(^raw)
+		} else {
+			x = float64(raw)
+		}
+	case 0x02:
+		x = float64(int8(raw))
+	default:
+		x = float64(raw)
+	}
+
+	y := scale(float64(r.m)*x+scale(float64(r.b), r.bExp), r.rExp)
+	switch r.linearization {
+	case 1:
+		y = math.Log(y)
+	case 2:
+		y = math.Log10(y)
+	case 3:
+		y = math.Log2(y)
+	case 4:
+		y = math.Exp(y)
+	case 5:
+		y = math.Pow(10, y)
+	case 6:
+		y = math.Exp2(y)
+	case 7:
+		y = 1 / y
+	case 8:
+		y = y * y
+	case 9:
+		y = y * y * y
+	case 10:
+		y = math.Sqrt(y)
+	case 11:
+		y = math.Cbrt(y)
+	}
+	return y
+}
+
+// unit returns the unit of the sensor in the notation of ipmitool
+func (r *sdrRecord) unit() string {
+	if r.percentage && r.baseUnit == 0 {
+		return "percent"
+	}
+
+	unit := unitName(r.baseUnit)
+	switch r.modifierMode {
+	case 0x01:
+		unit += "/" + unitName(r.modifierUnit)
+	case 0x02:
+		unit += "*" + unitName(r.modifierUnit)
+	}
+	if r.percentage {
+		unit = "percent " + unit
+	}
+	return unit
+}
+
+// thresholdState returns the most severe threshold crossed and the
+// corresponding status code
+func thresholdState(state uint8) (name, level string) {
+	for _, s := range thresholdStates {
+		if state&s.mask != 0 {
+			return s.name, s.level
+		}
+	}
+	return "ok", "ok"
+}
+
+func unitName(code uint8) string {
+	if int(code) < len(sensorUnits) {
+		return sensorUnits[code]
+	}
+	return "unknown"
+}
+
+// scale multiplies the value by 10^exp, dividing for negative exponents to
+// avoid rounding artifacts such as 3.0500000000000003
+func scale(v float64, exp int32) float64 {
+	if exp < 0 {
+		return v / math.Pow10(int(-exp))
+	}
+	return v * math.Pow10(int(exp))
+}
+
+func signExtend(v int32, bits uint) int32 {
+	if v&(1<<(bits-1)) != 0 {
+		return v - 1<<bits
+	}
+	return v
+}
+
+// decodeString decodes the data of a type/length encoded string as used in
+// sensor data records and FRU areas
+func decodeString(typ uint8, data []byte) string {
+	switch typ {
+	case 0x00:
+		// binary or unspecified
+		return hex.EncodeToString(data)
+	case 0x01:
+		// BCD plus
+		const digits = "0123456789 -.:,_"
+		var sb strings.Builder
+		for _, b := range data {
+			sb.WriteByte(digits[b>>4])
+			sb.WriteByte(digits[b&0x0f])
+		}
+		return strings.TrimSpace(sb.String())
+	case 0x02:
+		// 6-bit ASCII packed, four characters in three bytes
+		var sb strings.Builder
+		for i := 0; i+2 < len(data); i += 3 {
+			v := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16
+			for j := 0; j < 4; j++ {
+				sb.WriteByte(byte(v>>(6*j)&0x3f) + 0x20)
+			}
+		}
+		return strings.TrimSpace(sb.String())
+	}
+	// 8-bit ASCII + Latin 1
+	return strings.TrimSpace(strings.TrimRight(string(data), "\x00"))
+}
+
+// readSDRRepository reads all full and compact sensor data records from the
+// BMC's SDR repository
+func readSDRRepository(s *rmcpSession) ([]*sdrRecord, error) {
+	reservation, err := reserveSDR(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*sdrRecord
+	seen := make(map[uint16]bool)
+	for id := uint16(0x0000); id != 0xffff; {
+		if seen[id] {
+			return nil, fmt.Errorf("loop in SDR repository at record 0x%04x", id)
+		}
+		seen[id] = true
+
+		var data []byte
+		var next uint16
+		for retry := 0; ; retry++ {
+			data, next, err = readSDR(s, reservation, id)
+			if err == nil || retry > 2 || !hasCompletionCode(err, 0xc5) {
+				break
+			}
+			// The reservation was canceled, e.g. by another client
+			if reservation, err = reserveSDR(s); err != nil {
+				return nil, err
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading SDR record 0x%04x failed: %w", id, err)
+		}
+
+		if r, ok := parseSDR(data); ok {
+			records = append(records, r)
+		}
+		id = next
+	}
+	return records, nil
+}
+
+func reserveSDR(s *rmcpSession) ([]byte, error) {
+	rsp, err := s.command(netFnStorage, 0, 0x22, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reserving SDR repository failed: %w", err)
+	}
+	if len(rsp) < 2 {
+		return nil, errors.New("reserving SDR repository failed: invalid response")
+	}
+	return rsp[:2], nil
+}
+
+// readSDR reads a single record, in one go if possible or in chunks
+// otherwise, and returns the record and the ID of the next record
+func readSDR(s *rmcpSession, reservation []byte, id uint16) ([]byte, uint16, error) {
+	read := func(offset, count uint8) ([]byte, uint16, error) {
+		req := append([]byte{}, reservation...)
+		req = binary.LittleEndian.AppendUint16(req, id)
+		req = append(req, offset, count)
+		rsp, err := s.command(netFnStorage, 0, 0x23, req)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(rsp) < 2 {
+			return nil, 0, errors.New("invalid response")
+		}
+		return rsp[2:], binary.LittleEndian.Uint16(rsp[:2]), nil
+	}
+
+	data, next, err := read(0, 0xff)
+	if err == nil && len(data) >= 5 && len(data) >= 5+int(data[4]) {
+		return data[:5+int(data[4])], next, nil
+	}
+	if err != nil && hasCompletionCode(err, 0xc5) {
+		return nil, 0, err
+	}
+
+	// Fall back to reading the header and the record body in chunks
+	header, next, err := read(0, 5)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(header) < 5 {
+		return nil, 0, errors.New("truncated record header")
+	}
+	data = append([]byte{}, header[:5]...)
+	length := 5 + int(header[4])
+	for len(data) < length {
+		count := min(16, length-len(data))
+		chunk, _, err := read(uint8(len(data)), uint8(count))
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(chunk) == 0 {
+			return nil, 0, errors.New("empty record chunk")
+		}
+		data = append(data, chunk[:min(len(chunk), count)]...)
+	}
+	return data, next, nil
+}
+
+// readFRU reads the board, chassis and product information of the FRU
+// inventory device with the given ID
+func readFRU(s *rmcpSession, fruID uint8) (map[string]interface{}, error) {
+	info, err := s.command(netFnStorage, 0, 0x10, []byte{fruID})
+	if err != nil {
+		return nil, fmt.Errorf("getting FRU inventory area info failed: %w", err)
+	}
+	if len(info) < 3 {
+		return nil, errors.New("getting FRU inventory area info failed: invalid response")
+	}
+	size := int(binary.LittleEndian.Uint16(info[:2]))
+	if info[2]&0x01 != 0 {
+		return nil, errors.New("FRU devices accessed by words are not supported")
+	}
+
+	read := func(offset, length int) ([]byte, error) {
+		if offset+length > size {
+			return nil, fmt.Errorf("FRU area at offset %d exceeds inventory size %d", offset, size)
+		}
+		data := make([]byte, 0, length)
+		for len(data) < length {
+			count := min(16, length-len(data))
+			pos := offset + len(data)
+			rsp, err := s.command(netFnStorage, 0, 0x11, []byte{fruID, byte(pos), byte(pos >> 8), byte(count)})
+			if err != nil {
+				return nil, err
+			}
+			if len(rsp) < 2 {
+				return nil, errors.New("empty FRU data")
+			}
+			data = append(data, rsp[1:1+min(int(rsp[0]), len(rsp)-1, count)]...)
+		}
+		return data, nil
+	}
+
+	header, err := read(0, 8)
+	if err != nil {
+		return nil, fmt.Errorf("reading FRU header failed: %w", err)
+	}
+	if header[0] != 0x01 {
+		return nil, fmt.Errorf("unsupported FRU format version %d", header[0])
+	}
+
+	fields := make(map[string]interface{})
+	for i, area := range []string{"chassis", "board", "product"} {
+		offset := int(header[2+i]) * 8
+		if offset == 0 {
+			continue
+		}
+		areaHeader, err := read(offset, 2)
+		if err != nil {
+			return nil, fmt.Errorf("reading FRU %s area failed: %w", area, err)
+		}
+		data, err := read(offset, int(areaHeader[1])*8)
+		if err != nil {
+			return nil, fmt.Errorf("reading FRU %s area failed: %w", area, err)
+		}
+		parseFRUArea(area, data, fields)
+	}
+	return fields, nil
+}
+
+// Names of the leading type/length encoded fields of the FRU areas
+var fruAreaFields = map[string][]string{
+	"chassis": {"chassis_part_number", "chassis_serial"},
+	"board":   {"board_manufacturer", "board_product", "board_serial", "board_part_number"},
+	"product": {
+		"product_manufacturer", "product_name", "product_part_number", "product_version",
+		"product_serial", "product_asset_tag",
+	},
+}
+
+// FRU manufacturing dates are given in minutes since 1996-01-01
+var fruEpoch = time.Date(1996, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func parseFRUArea(area string, data []byte, fields map[string]interface{}) {
+	// Skip the version, length and the area specific header fields
+	offset := 3
+	switch area {
+	case "board":
+		if len(data) >= 6 {
+			if minutes := uint32(data[3]) | uint32(data[4])<<8 | uint32(data[5])<<16; minutes != 0 {
+				fields["board_mfg_date"] = fruEpoch.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339)
+			}
+		}
+		offset = 6
+	case "chassis":
+		if len(data) >= 3 {
+			fields["chassis_type"] = int64(data[2])
+		}
+	}
+
+	for _, name := range fruAreaFields[area] {
+		if offset >= len(data) || data[offset] == 0xc1 {
+			// end of fields marker
+			return
+		}
+		code := data[offset]
+		end := offset + 1 + int(code&0x3f)
+		if end > len(data) {
+			return
+		}
+		if v := decodeString(code>>6, data[offset+1:end]); v != "" {
+			fields[name] = v
+		}
+		offset = end
+	}
+}
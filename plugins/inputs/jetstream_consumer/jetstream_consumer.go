@@ -0,0 +1,433 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package jetstream_consumer
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type SubjectParsingConfig struct {
+	Subject     string            `toml:"subject"`
+	Measurement string            `toml:"measurement"`
+	Tags        string            `toml:"tags"`
+	Fields      string            `toml:"fields"`
+	FieldTypes  map[string]string `toml:"types"`
+
+	subject          []string
+	measurementIndex int
+	tags             []string
+	fields           []string
+}
+
+type JetStreamConsumer struct {
+	Servers                []string               `toml:"servers"`
+	Stream                 string                 `toml:"stream"`
+	Subjects               []string               `toml:"subjects"`
+	Durable                string                 `toml:"durable"`
+	AckWait                config.Duration        `toml:"ack_wait"`
+	MaxAckPending          int                    `toml:"max_ack_pending"`
+	MaxDeliver             int                    `toml:"max_deliver"`
+	BatchSize              int                    `toml:"batch_size"`
+	FetchTimeout           config.Duration        `toml:"fetch_timeout"`
+	SubjectTag             string                 `toml:"subject_tag"`
+	SubjectParsing         []SubjectParsingConfig `toml:"subject_parsing"`
+	Username               config.Secret          `toml:"username"`
+	Password               config.Secret          `toml:"password"`
+	Credentials            string                 `toml:"credentials"`
+	NkeySeed               string                 `toml:"nkey_seed"`
+	Secure                 bool                   `toml:"secure"`
+	MaxUndeliveredMessages int                    `toml:"max_undelivered_messages"`
+	Log                    telegraf.Logger        `toml:"-"`
+	tls.ClientConfig
+
+	parser   telegraf.Parser
+	conn     *nats.Conn
+	consumer jetstream.Consumer
+	acc      telegraf.TrackingAccumulator
+	sem      chan struct{}
+
+	messages map[telegraf.TrackingID]jetstream.Msg
+	sync.Mutex
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func (*JetStreamConsumer) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JetStreamConsumer) SetParser(parser telegraf.Parser) {
+	j.parser = parser
+}
+
+func (j *JetStreamConsumer) Init() error {
+	if len(j.Servers) == 0 {
+		return errors.New("no servers specified")
+	}
+	if j.Stream == "" {
+		return errors.New("stream required")
+	}
+	if j.Durable == "" {
+		return errors.New("durable consumer name required")
+	}
+	if strings.ContainsAny(j.Durable, ". *>") {
+		return fmt.Errorf("invalid durable consumer name %q", j.Durable)
+	}
+	if j.MaxUndeliveredMessages < 1 {
+		return fmt.Errorf("invalid max_undelivered_messages %d", j.MaxUndeliveredMessages)
+	}
+	if j.BatchSize < 1 {
+		return fmt.Errorf("invalid batch_size %d", j.BatchSize)
+	}
+
+	// Do not let the server send more messages than we can track
+	if j.MaxAckPending == 0 || j.MaxAckPending > j.MaxUndeliveredMessages {
+		j.MaxAckPending = j.MaxUndeliveredMessages
+	}
+
+	for i, p := range j.SubjectParsing {
+		if p.Subject == "" {
+			return errors.New("subject parsing requires a subject")
+		}
+		j.SubjectParsing[i].subject = strings.Split(p.Subject, ".")
+		n := len(j.SubjectParsing[i].subject)
+
+		if p.Measurement != "" {
+			measurement := strings.Split(p.Measurement, ".")
+			if len(measurement) != n {
+				return fmt.Errorf("subject parsing for %q: measurement length does not equal subject length", p.Subject)
+			}
+			j.SubjectParsing[i].measurementIndex = -1
+			for k, v := range measurement {
+				if v != "_" && v != "" {
+					j.SubjectParsing[i].measurementIndex = k
+					break
+				}
+			}
+		}
+		if p.Tags != "" {
+			j.SubjectParsing[i].tags = strings.Split(p.Tags, ".")
+			if len(j.SubjectParsing[i].tags) != n {
+				return fmt.Errorf("subject parsing for %q: tags length does not equal subject length", p.Subject)
+			}
+		}
+		if p.Fields != "" {
+			j.SubjectParsing[i].fields = strings.Split(p.Fields, ".")
+			if len(j.SubjectParsing[i].fields) != n {
+				return fmt.Errorf("subject parsing for %q: fields length does not equal subject length", p.Subject)
+			}
+		}
+		for field, typ := range p.FieldTypes {
+			switch typ {
+			case "int", "uint", "float", "bool", "string":
+			default:
+				return fmt.Errorf("invalid type %q for field %q", typ, field)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (j *JetStreamConsumer) Start(acc telegraf.Accumulator) error {
+	options := []nats.Option{
+		nats.MaxReconnects(-1),
+	}
+
+	// override authentication, if any was specified
+	if !j.Username.Empty() && !j.Password.Empty() {
+		username, err := j.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := j.Password.Get()
+		if err != nil {
+			username.Destroy()
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		options = append(options, nats.UserInfo(username.String(), password.String()))
+		username.Destroy()
+		password.Destroy()
+	}
+
+	if j.Credentials != "" {
+		options = append(options, nats.UserCredentials(j.Credentials))
+	}
+
+	if j.NkeySeed != "" {
+		opt, err := nats.NkeyOptionFromSeed(j.NkeySeed)
+		if err != nil {
+			return err
+		}
+		options = append(options, opt)
+	}
+
+	if j.Secure {
+		tlsConfig, err := j.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		options = append(options, nats.Secure(tlsConfig))
+	}
+
+	conn, err := nats.Connect(strings.Join(j.Servers, ","), options...)
+	if err != nil {
+		return err
+	}
+	j.conn = conn
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("creating JetStream context failed: %w", err)
+	}
+
+	// Create the durable consumer or update its configuration. The consumer
+	// persists on the server, so processing continues at the last
+	// acknowledged message after restarting Telegraf.
+	cfg := jetstream.ConsumerConfig{
+		Durable:       j.Durable,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       time.Duration(j.AckWait),
+		MaxAckPending: j.MaxAckPending,
+		MaxDeliver:    j.MaxDeliver,
+	}
+	switch len(j.Subjects) {
+	case 0:
+	case 1:
+		cfg.FilterSubject = j.Subjects[0]
+	default:
+		cfg.FilterSubjects = j.Subjects
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	consumer, err := js.CreateOrUpdateConsumer(ctx, j.Stream, cfg)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("creating consumer %q on stream %q failed: %w", j.Durable, j.Stream, err)
+	}
+	j.consumer = consumer
+
+	j.acc = acc.WithTracking(j.MaxUndeliveredMessages)
+	j.sem = make(chan struct{}, j.MaxUndeliveredMessages)
+	j.messages = make(map[telegraf.TrackingID]jetstream.Msg)
+
+	ctx, j.cancel = context.WithCancel(context.Background())
+
+	j.wg.Add(2)
+	go func() {
+		defer j.wg.Done()
+		j.onDelivery(ctx)
+	}()
+	go func() {
+		defer j.wg.Done()
+		j.receive(ctx)
+	}()
+
+	j.Log.Infof("Started consuming stream %q as %q on %s", j.Stream, j.Durable, conn.ConnectedUrl())
+
+	return nil
+}
+
+func (*JetStreamConsumer) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (j *JetStreamConsumer) Stop() {
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.wg.Wait()
+
+	if j.conn != nil && !j.conn.IsClosed() {
+		j.conn.Close()
+	}
+}
+
+// receive pulls batches of messages from the consumer until the context is
+// cancelled
+func (j *JetStreamConsumer) receive(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := j.consumer.Fetch(j.BatchSize, jetstream.FetchMaxWait(time.Duration(j.FetchTimeout)))
+		if err != nil {
+			j.acc.AddError(fmt.Errorf("fetching messages failed: %w", err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(j.FetchTimeout)):
+			}
+			continue
+		}
+
+		for msg := range batch.Messages() {
+			// Wait for a free slot, unhandled messages are redelivered by the
+			// server after the ack wait time
+			select {
+			case <-ctx.Done():
+				return
+			case j.sem <- struct{}{}:
+			}
+			j.onMessage(msg)
+		}
+		if err := batch.Error(); err != nil {
+			j.acc.AddError(fmt.Errorf("receiving messages failed: %w", err))
+		}
+	}
+}
+
+func (j *JetStreamConsumer) onMessage(msg jetstream.Msg) {
+	metrics, err := j.parser.Parse(msg.Data())
+	if err != nil {
+		// The message will never be parseable, so stop redelivering it
+		j.acc.AddError(fmt.Errorf("parsing message of subject %q failed: %w", msg.Subject(), err))
+		if err := msg.Term(); err != nil {
+			j.Log.Errorf("Terminating message failed: %v", err)
+		}
+		<-j.sem
+		return
+	}
+	if len(metrics) == 0 {
+		if err := msg.Ack(); err != nil {
+			j.Log.Errorf("Acknowledging message failed: %v", err)
+		}
+		<-j.sem
+		return
+	}
+
+	subject := strings.Split(msg.Subject(), ".")
+	for _, m := range metrics {
+		if j.SubjectTag != "" {
+			m.AddTag(j.SubjectTag, msg.Subject())
+		}
+		for _, p := range j.SubjectParsing {
+			if !matchSubject(p.subject, subject) {
+				continue
+			}
+			if err := p.apply(subject, m); err != nil {
+				j.acc.AddError(fmt.Errorf("parsing subject %q failed: %w", msg.Subject(), err))
+				if err := msg.Term(); err != nil {
+					j.Log.Errorf("Terminating message failed: %v", err)
+				}
+				<-j.sem
+				return
+			}
+		}
+	}
+
+	j.Lock()
+	id := j.acc.AddTrackingMetricGroup(metrics)
+	j.messages[id] = msg
+	j.Unlock()
+}
+
+func (j *JetStreamConsumer) onDelivery(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case track := <-j.acc.Delivered():
+			j.Lock()
+			msg, found := j.messages[track.ID()]
+			delete(j.messages, track.ID())
+			j.Unlock()
+			if !found {
+				j.Log.Errorf("Could not mark message delivered: %d", track.ID())
+				continue
+			}
+
+			// Request redelivery of messages not accepted by the outputs
+			var err error
+			if track.Delivered() {
+				err = msg.Ack()
+			} else {
+				err = msg.Nak()
+			}
+			if err != nil {
+				j.Log.Errorf("Acknowledging message failed: %v", err)
+			}
+			<-j.sem
+		}
+	}
+}
+
+// matchSubject checks the subject against the filter supporting the "*" and
+// ">" wildcards
+func matchSubject(filter, subject []string) bool {
+	for i, token := range filter {
+		if token == ">" {
+			return len(subject) > i
+		}
+		if i >= len(subject) || (token != "*" && token != subject[i]) {
+			return false
+		}
+	}
+	return len(filter) == len(subject)
+}
+
+func (p *SubjectParsingConfig) apply(subject []string, m telegraf.Metric) error {
+	if len(p.Measurement) > 0 && p.measurementIndex >= 0 && p.measurementIndex < len(subject) {
+		m.SetName(subject[p.measurementIndex])
+	}
+	for i, key := range p.tags {
+		if key == "_" || key == "" || i >= len(subject) {
+			continue
+		}
+		m.AddTag(key, subject[i])
+	}
+	for i, key := range p.fields {
+		if key == "_" || key == "" || i >= len(subject) {
+			continue
+		}
+		value, err := convert(subject[i], p.FieldTypes[key])
+		if err != nil {
+			return fmt.Errorf("converting field %q failed: %w", key, err)
+		}
+		m.AddField(key, value)
+	}
+	return nil
+}
+
+func convert(value, typ string) (interface{}, error) {
+	switch typ {
+	case "int":
+		return strconv.ParseInt(value, 10, 64)
+	case "uint":
+		return strconv.ParseUint(value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+func init() {
+	inputs.Add("jetstream_consumer", func() telegraf.Input {
+		return &JetStreamConsumer{
+			Servers:                []string{"nats://localhost:4222"},
+			AckWait:                config.Duration(30 * time.Second),
+			BatchSize:              100,
+			FetchTimeout:           config.Duration(5 * time.Second),
+			SubjectTag:             "subject",
+			MaxUndeliveredMessages: 1000,
+		}
+	})
+}
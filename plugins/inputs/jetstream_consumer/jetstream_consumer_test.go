@@ -0,0 +1,255 @@
+package jetstream_consumer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func startServer(t *testing.T) string {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second), "server not ready")
+
+	return srv.ClientURL()
+}
+
+func createStream(t *testing.T, url string) jetstream.JetStream {
+	t.Helper()
+
+	conn, err := nats.Connect(url)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	_, err = js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "telegraf",
+		Subjects: []string{"telegraf.>"},
+	})
+	require.NoError(t, err)
+	return js
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JetStreamConsumer
+		expected string
+	}{
+		{
+			name:     "no stream",
+			plugin:   &JetStreamConsumer{Servers: []string{"nats://localhost:4222"}},
+			expected: "stream required",
+		},
+		{
+			name:     "no durable",
+			plugin:   &JetStreamConsumer{Servers: []string{"nats://localhost:4222"}, Stream: "telegraf"},
+			expected: "durable consumer name required",
+		},
+		{
+			name: "invalid durable",
+			plugin: &JetStreamConsumer{
+				Servers: []string{"nats://localhost:4222"},
+				Stream:  "telegraf",
+				Durable: "tele.graf",
+			},
+			expected: `invalid durable consumer name "tele.graf"`,
+		},
+		{
+			name: "invalid subject parsing",
+			plugin: &JetStreamConsumer{
+				Servers:                []string{"nats://localhost:4222"},
+				Stream:                 "telegraf",
+				Durable:                "telegraf",
+				MaxUndeliveredMessages: 10,
+				BatchSize:              10,
+				SubjectParsing: []SubjectParsingConfig{
+					{Subject: "telegraf.*.cpu", Tags: "_.host"},
+				},
+			},
+			expected: "tags length does not equal subject length",
+		},
+		{
+			name: "invalid field type",
+			plugin: &JetStreamConsumer{
+				Servers:                []string{"nats://localhost:4222"},
+				Stream:                 "telegraf",
+				Durable:                "telegraf",
+				MaxUndeliveredMessages: 10,
+				BatchSize:              10,
+				SubjectParsing: []SubjectParsingConfig{
+					{Subject: "telegraf.*.cpu", Fields: "_.id._", FieldTypes: map[string]string{"id": "decimal"}},
+				},
+			},
+			expected: `invalid type "decimal" for field "id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		filter   string
+		subject  string
+		expected bool
+	}{
+		{filter: "telegraf.host.cpu", subject: "telegraf.host.cpu", expected: true},
+		{filter: "telegraf.*.cpu", subject: "telegraf.host.cpu", expected: true},
+		{filter: "telegraf.*.cpu", subject: "telegraf.host.mem", expected: false},
+		{filter: "telegraf.>", subject: "telegraf.host.cpu", expected: true},
+		{filter: "telegraf.>", subject: "telegraf", expected: false},
+		{filter: "telegraf.*", subject: "telegraf.host.cpu", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter+"/"+tt.subject, func(t *testing.T) {
+			filter := strings.Split(tt.filter, ".")
+			subject := strings.Split(tt.subject, ".")
+			require.Equal(t, tt.expected, matchSubject(filter, subject))
+		})
+	}
+}
+
+func TestConsume(t *testing.T) {
+	url := startServer(t)
+	js := createStream(t, url)
+
+	ctx := context.Background()
+	_, err := js.Publish(ctx, "telegraf.host01.cpu.0", []byte("usage value=42"))
+	require.NoError(t, err)
+	_, err = js.Publish(ctx, "telegraf.host02.cpu.1", []byte("usage value=23"))
+	require.NoError(t, err)
+	_, err = js.Publish(ctx, "telegraf.host01.mem.0", []byte("usage value=1"))
+	require.NoError(t, err)
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &JetStreamConsumer{
+		Servers:                []string{url},
+		Stream:                 "telegraf",
+		Subjects:               []string{"telegraf.*.cpu.*"},
+		Durable:                "telegraf",
+		AckWait:                config.Duration(5 * time.Second),
+		BatchSize:              10,
+		FetchTimeout:           config.Duration(100 * time.Millisecond),
+		MaxUndeliveredMessages: 10,
+		SubjectParsing: []SubjectParsingConfig{
+			{
+				Subject:     "telegraf.*.*.*",
+				Measurement: "_._.measurement._",
+				Tags:        "_.host._._",
+				Fields:      "_._._.core",
+				FieldTypes:  map[string]string{"core": "int"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "host01"},
+			map[string]interface{}{"value": float64(42), "core": int64(0)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "host02"},
+			map[string]interface{}{"value": float64(23), "core": int64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Deliver the metrics to acknowledge the messages
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool {
+		consumer, err := js.Consumer(ctx, "telegraf", "telegraf")
+		if err != nil {
+			return false
+		}
+		info, err := consumer.Info(ctx)
+		return err == nil && info.NumAckPending == 0 && info.AckFloor.Stream > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestRedeliveryOnReject(t *testing.T) {
+	url := startServer(t)
+	js := createStream(t, url)
+
+	_, err := js.Publish(context.Background(), "telegraf.host01.cpu", []byte("cpu value=42"))
+	require.NoError(t, err)
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &JetStreamConsumer{
+		Servers:                []string{url},
+		Stream:                 "telegraf",
+		Durable:                "telegraf",
+		AckWait:                config.Duration(5 * time.Second),
+		BatchSize:              10,
+		FetchTimeout:           config.Duration(100 * time.Millisecond),
+		MaxUndeliveredMessages: 10,
+		SubjectTag:             "subject",
+		Log:                    testutil.Logger{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 1
+	}, 5*time.Second, 50*time.Millisecond)
+	metrics := acc.GetTelegrafMetrics()
+	require.Equal(t, "telegraf.host01.cpu", metrics[0].Tags()["subject"])
+
+	// Rejected metrics must be redelivered
+	metrics[0].Reject()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 5*time.Second, 50*time.Millisecond)
+}
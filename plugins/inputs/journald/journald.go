@@ -0,0 +1,217 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package journald
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Syslog severity keywords indexed by the priority of the entry
+var priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// Journal fields containing integer values
+var integerFields = map[string]bool{
+	"_PID":             true,
+	"_UID":             true,
+	"_GID":             true,
+	"_AUDIT_SESSION":   true,
+	"_AUDIT_LOGINUID":  true,
+	"SYSLOG_PID":       true,
+	"ERRNO":            true,
+	"CODE_LINE":        true,
+	"_SOURCE_REALTIME": true,
+}
+
+type Journald struct {
+	Path          string          `toml:"path"`
+	Units         []string        `toml:"units"`
+	Priority      string          `toml:"priority"`
+	Matches       []string        `toml:"matches"`
+	FromBeginning bool            `toml:"from_beginning"`
+	TagFields     []string        `toml:"tag_fields"`
+	ValueFields   []string        `toml:"value_fields"`
+	Log           telegraf.Logger `toml:"-"`
+
+	tagFilter   filter.Filter
+	valueFilter filter.Filter
+	maxPriority int
+
+	cursor string
+	sync.Mutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (*Journald) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *Journald) Init() error {
+	j.maxPriority = len(priorities) - 1
+	if j.Priority != "" {
+		j.maxPriority = -1
+		for i, p := range priorities {
+			if p == j.Priority {
+				j.maxPriority = i
+				break
+			}
+		}
+		if j.maxPriority < 0 {
+			return fmt.Errorf("invalid priority %q", j.Priority)
+		}
+	}
+
+	for _, match := range j.Matches {
+		if key, _, found := strings.Cut(match, "="); !found || key == "" {
+			return fmt.Errorf("invalid match %q, expected <FIELD>=<value>", match)
+		}
+	}
+
+	var err error
+	if j.tagFilter, err = filter.Compile(j.TagFields); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	if j.valueFilter, err = filter.Compile(j.ValueFields); err != nil {
+		return fmt.Errorf("creating value filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (j *Journald) GetState() interface{} {
+	j.Lock()
+	defer j.Unlock()
+	return j.cursor
+}
+
+func (j *Journald) SetState(state interface{}) error {
+	cursor, ok := state.(string)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	j.Lock()
+	j.cursor = cursor
+	j.Unlock()
+	return nil
+}
+
+func (j *Journald) Start(acc telegraf.Accumulator) error {
+	if err := j.check(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		for {
+			if err := j.read(ctx, acc); err != nil {
+				acc.AddError(err)
+			}
+
+			// Reopen the journal after reading failed, continuing after the
+			// last entry read
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (*Journald) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (j *Journald) Stop() {
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.wg.Wait()
+}
+
+// add adds the metric of the entry and remembers the cursor of the entry to
+// continue after it
+func (j *Journald) add(acc telegraf.Accumulator, entry map[string]string, cursor string) {
+	if m := j.metric(entry); m != nil {
+		acc.AddMetric(m)
+	}
+
+	j.Lock()
+	j.cursor = cursor
+	j.Unlock()
+}
+
+// metric converts the journal entry to a metric using the lower-cased field
+// names without leading underscores as tag and field keys. Nil is returned for
+// entries without any of the value fields.
+func (j *Journald) metric(entry map[string]string) telegraf.Metric {
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	for key, value := range entry {
+		// Skip address fields like the cursor
+		if strings.HasPrefix(key, "__") {
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimLeft(key, "_"))
+		if key == "PRIORITY" {
+			if p, err := strconv.Atoi(value); err == nil && p >= 0 && p < len(priorities) {
+				value = priorities[p]
+			}
+		}
+
+		if j.tagFilter != nil && j.tagFilter.Match(key) {
+			tags[name] = value
+			continue
+		}
+		if j.valueFilter == nil || !j.valueFilter.Match(key) {
+			continue
+		}
+		if integerFields[key] {
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+				fields[name] = v
+				continue
+			}
+		}
+		fields[name] = value
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	ts := time.Now()
+	if v, err := strconv.ParseInt(entry["__REALTIME_TIMESTAMP"], 10, 64); err == nil {
+		ts = time.UnixMicro(v)
+	}
+	return metric.New("journald", tags, fields, ts)
+}
+
+func init() {
+	inputs.Add("journald", func() telegraf.Input {
+		return &Journald{
+			TagFields:   []string{"_SYSTEMD_UNIT", "PRIORITY", "SYSLOG_IDENTIFIER", "_HOSTNAME"},
+			ValueFields: []string{"MESSAGE", "_PID"},
+		}
+	})
+}
@@ -0,0 +1,149 @@
+//go:build linux && !(cgo && journald_native)
+
+package journald
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Without the native reader, the journal is read by following the JSON output
+// of journalctl found in the PATH
+var journalctl = "journalctl"
+
+// check verifies the requirements for reading the journal
+func (*Journald) check() error {
+	if _, err := exec.LookPath(journalctl); err != nil {
+		return fmt.Errorf("finding journalctl failed: %w", err)
+	}
+	return nil
+}
+
+// args returns the arguments of journalctl to follow the journal with the
+// configured matches after the last read entry
+func (j *Journald) args() []string {
+	args := []string{"--follow", "--output=json", "--all", "--quiet"}
+	if j.Path != "" {
+		args = append(args, "--directory="+j.Path)
+	}
+
+	cursor := j.GetState().(string)
+	switch {
+	case cursor != "":
+		args = append(args, "--after-cursor="+cursor, "--lines=all")
+	case j.FromBeginning:
+		args = append(args, "--lines=all")
+	default:
+		args = append(args, "--lines=0")
+	}
+
+	// Matches of different fields are combined with AND while matches of the
+	// same field are combined with OR.
+	for _, unit := range j.Units {
+		args = append(args, "_SYSTEMD_UNIT="+unit)
+	}
+	if j.maxPriority < len(priorities)-1 {
+		for p := 0; p <= j.maxPriority; p++ {
+			args = append(args, "PRIORITY="+strconv.Itoa(p))
+		}
+	}
+	return append(args, j.Matches...)
+}
+
+// read runs journalctl and reads the entries until the context is cancelled
+// or journalctl exits
+func (j *Journald) read(ctx context.Context, acc telegraf.Accumulator) error {
+	cmd := exec.CommandContext(ctx, journalctl, j.args()...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("creating journalctl output pipe failed: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting journalctl failed: %w", err)
+	}
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			fields, err := parseEntry(line)
+			if err != nil {
+				acc.AddError(err)
+			} else {
+				j.add(acc, fields, fields["__CURSOR"])
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				acc.AddError(fmt.Errorf("reading journalctl output failed: %w", err))
+			}
+			break
+		}
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("journalctl failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return errors.New("journalctl exited unexpectedly")
+}
+
+// parseEntry decodes an entry of the JSON output of journalctl. Values are
+// strings, arrays of bytes for binary values or arrays of those for fields
+// occurring multiple times in which case the first value is used.
+func parseEntry(line []byte) (map[string]string, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return nil, fmt.Errorf("decoding journal entry failed: %w", err)
+	}
+
+	fields := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if v, ok := decodeValue(value); ok {
+			fields[key] = v
+		}
+	}
+	if fields["__CURSOR"] == "" {
+		return nil, errors.New("journal entry without cursor")
+	}
+	return fields, nil
+}
+
+func decodeValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []interface{}:
+		if len(v) == 0 {
+			return "", false
+		}
+		if _, ok := v[0].(float64); !ok {
+			return decodeValue(v[0])
+		}
+		buf := make([]byte, 0, len(v))
+		for _, b := range v {
+			n, ok := b.(float64)
+			if !ok {
+				return "", false
+			}
+			buf = append(buf, byte(n))
+		}
+		return string(buf), true
+	}
+	// Skip null values of fields exceeding the size limit of journalctl
+	return "", false
+}
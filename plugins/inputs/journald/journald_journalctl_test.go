@@ -0,0 +1,158 @@
+//go:build linux && !(cgo && journald_native)
+
+package journald
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func setJournalctl(t *testing.T, path string) {
+	previous := journalctl
+	journalctl = path
+	t.Cleanup(func() { journalctl = previous })
+}
+
+func TestParseEntry(t *testing.T) {
+	line := `{"__CURSOR":"s=abc;i=1","__REALTIME_TIMESTAMP":"1700000000123456",` +
+		`"MESSAGE":[104,105,0,33],"SYSLOG_IDENTIFIER":["sshd","sshd-session"],"_COMM":null}`
+	fields, err := parseEntry([]byte(line))
+	require.NoError(t, err)
+	expected := map[string]string{
+		"__CURSOR":             "s=abc;i=1",
+		"__REALTIME_TIMESTAMP": "1700000000123456",
+		"MESSAGE":              "hi\x00!",
+		"SYSLOG_IDENTIFIER":    "sshd",
+	}
+	require.Equal(t, expected, fields)
+
+	_, err = parseEntry([]byte(`{"MESSAGE":"test"}`))
+	require.ErrorContains(t, err, "without cursor")
+
+	_, err = parseEntry([]byte(`{"MESSAGE":`))
+	require.ErrorContains(t, err, "decoding journal entry failed")
+}
+
+func TestArgs(t *testing.T) {
+	plugin := &Journald{
+		Path:     "/var/log/journal",
+		Units:    []string{"sshd.service", "nginx.service"},
+		Priority: "err",
+		Matches:  []string{"_TRANSPORT=kernel"},
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	expected := []string{
+		"--follow", "--output=json", "--all", "--quiet", "--directory=/var/log/journal", "--lines=0",
+		"_SYSTEMD_UNIT=sshd.service", "_SYSTEMD_UNIT=nginx.service",
+		"PRIORITY=0", "PRIORITY=1", "PRIORITY=2", "PRIORITY=3",
+		"_TRANSPORT=kernel",
+	}
+	require.Equal(t, expected, plugin.args())
+
+	plugin = &Journald{FromBeginning: true, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"--follow", "--output=json", "--all", "--quiet", "--lines=all"}, plugin.args())
+
+	// Reading continues after the last entry read
+	require.NoError(t, plugin.SetState("s=abc;i=1"))
+	expected = []string{"--follow", "--output=json", "--all", "--quiet", "--after-cursor=s=abc;i=1", "--lines=all"}
+	require.Equal(t, expected, plugin.args())
+}
+
+func TestStartStop(t *testing.T) {
+	// Fake journalctl printing its arguments and two entries, the second
+	// call continues after the cursor of the last entry
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" >> ` + filepath.Join(dir, "args") + `
+echo '{"__CURSOR":"s=abc;i=1","__REALTIME_TIMESTAMP":"1700000000000000","MESSAGE":"first","PRIORITY":"6"}'
+echo '{"__CURSOR":"s=abc;i=2","__REALTIME_TIMESTAMP":"1700000001000000","MESSAGE":"second","PRIORITY":"3"}'
+exec sleep 10
+`
+	fake := filepath.Join(dir, "journalctl")
+	require.NoError(t, os.WriteFile(fake, []byte(script), 0700))
+	setJournalctl(t, fake)
+
+	plugin := &Journald{
+		TagFields:   []string{"PRIORITY"},
+		ValueFields: []string{"MESSAGE"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	acc.Wait(2)
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"journald",
+			map[string]string{"priority": "info"},
+			map[string]interface{}{"message": "first"},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"journald",
+			map[string]string{"priority": "err"},
+			map[string]interface{}{"message": "second"},
+			time.Unix(1700000001, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Empty(t, acc.Errors)
+	require.Equal(t, "s=abc;i=2", plugin.GetState())
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	require.NoError(t, err)
+	require.Equal(t, "--follow --output=json --all --quiet --lines=0", strings.TrimSpace(string(args)))
+}
+
+func TestRestart(t *testing.T) {
+	// Fake journalctl exiting after printing an entry
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" >> ` + filepath.Join(dir, "args") + `
+echo '{"__CURSOR":"s=abc;i=1","__REALTIME_TIMESTAMP":"1700000000000000","MESSAGE":"first"}'
+`
+	fake := filepath.Join(dir, "journalctl")
+	require.NoError(t, os.WriteFile(fake, []byte(script), 0700))
+	setJournalctl(t, fake)
+
+	plugin := &Journald{
+		ValueFields: []string{"MESSAGE"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	// Reading continues after the last entry read
+	require.Eventually(t, func() bool {
+		args, err := os.ReadFile(filepath.Join(dir, "args"))
+		if err != nil {
+			return false
+		}
+		return len(strings.Split(strings.TrimSpace(string(args)), "\n")) >= 2
+	}, 5*time.Second, 100*time.Millisecond)
+	plugin.Stop()
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+	require.Equal(t, "--follow --output=json --all --quiet --lines=0", lines[0])
+	require.Equal(t, "--follow --output=json --all --quiet --after-cursor=s=abc;i=1 --lines=all", lines[1])
+	require.NotEmpty(t, acc.Errors)
+	require.ErrorContains(t, acc.Errors[0], "journalctl exited unexpectedly")
+}
@@ -0,0 +1,122 @@
+//go:build linux && cgo && journald_native
+
+package journald
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/sdjournal"
+
+	"github.com/influxdata/telegraf"
+)
+
+// check verifies the requirements for reading the journal. The native reader
+// reads the journal files using libsystemd loaded when opening the journal.
+func (*Journald) check() error {
+	return nil
+}
+
+// read opens the journal and reads the entries until the context is
+// cancelled, waiting for new entries at the end of the journal
+func (j *Journald) read(ctx context.Context, acc telegraf.Accumulator) error {
+	var sj *sdjournal.Journal
+	var err error
+	if j.Path != "" {
+		sj, err = sdjournal.NewJournalFromDir(j.Path)
+	} else {
+		sj, err = sdjournal.NewJournal()
+	}
+	if err != nil {
+		return fmt.Errorf("opening journal failed: %w", err)
+	}
+	defer sj.Close()
+
+	if err := j.seek(sj); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		n, err := sj.Next()
+		if err != nil {
+			return fmt.Errorf("reading journal failed: %w", err)
+		}
+		if n == 0 {
+			// Wake up regularly to check for cancellation
+			sj.Wait(time.Second)
+			continue
+		}
+
+		entry, err := sj.GetEntry()
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting journal entry failed: %w", err))
+			continue
+		}
+		entry.Fields["__REALTIME_TIMESTAMP"] = strconv.FormatUint(entry.RealtimeTimestamp, 10)
+		j.add(acc, entry.Fields, entry.Cursor)
+	}
+}
+
+// seek adds the filters and positions the journal after the last read entry
+func (j *Journald) seek(sj *sdjournal.Journal) error {
+	// Matches of different fields are combined with AND while matches of the
+	// same field are combined with OR.
+	for _, unit := range j.Units {
+		if err := sj.AddMatch(sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT + "=" + unit); err != nil {
+			return fmt.Errorf("adding match for unit %q failed: %w", unit, err)
+		}
+	}
+	if j.maxPriority < len(priorities)-1 {
+		for p := 0; p <= j.maxPriority; p++ {
+			if err := sj.AddMatch(sdjournal.SD_JOURNAL_FIELD_PRIORITY + "=" + strconv.Itoa(p)); err != nil {
+				return fmt.Errorf("adding match for priority failed: %w", err)
+			}
+		}
+	}
+	for _, match := range j.Matches {
+		if err := sj.AddMatch(match); err != nil {
+			return fmt.Errorf("adding match %q failed: %w", match, err)
+		}
+	}
+
+	cursor := j.GetState().(string)
+	switch {
+	case cursor != "":
+		if err := sj.SeekCursor(cursor); err != nil {
+			return fmt.Errorf("seeking to cursor failed: %w", err)
+		}
+		// Skip the entry at the cursor as it was already read
+		if _, err := sj.Next(); err != nil {
+			return fmt.Errorf("reading entry at cursor failed: %w", err)
+		}
+		if err := sj.TestCursor(cursor); err != nil {
+			// The entry is gone, e.g. due to rotation, so start with the
+			// entry found instead
+			j.Log.Debugf("Entry at cursor not found, continuing with the next entry: %v", err)
+			if _, err := sj.Previous(); err != nil {
+				return fmt.Errorf("seeking to cursor failed: %w", err)
+			}
+		}
+	case j.FromBeginning:
+		if err := sj.SeekHead(); err != nil {
+			return fmt.Errorf("seeking to head failed: %w", err)
+		}
+	default:
+		if err := sj.SeekTail(); err != nil {
+			return fmt.Errorf("seeking to tail failed: %w", err)
+		}
+		// Position on the last entry so only new entries are read
+		if _, err := sj.Previous(); err != nil {
+			return fmt.Errorf("seeking to tail failed: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build linux && cgo && journald_native
+
+package journald
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestStartStopEmptyJournal(t *testing.T) {
+	plugin := &Journald{
+		Path:        t.TempDir(),
+		Units:       []string{"sshd.service"},
+		Priority:    "err",
+		Matches:     []string{"_TRANSPORT=kernel"},
+		ValueFields: []string{"MESSAGE"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	time.Sleep(100 * time.Millisecond)
+	plugin.Stop()
+
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Equal(t, "", plugin.GetState())
+}
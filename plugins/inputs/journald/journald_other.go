@@ -0,0 +1,31 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package journald
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Journald struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (j *Journald) Init() error {
+	j.Log.Warn("current platform is not supported")
+	return nil
+}
+func (*Journald) SampleConfig() string                { return sampleConfig }
+func (*Journald) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("journald", func() telegraf.Input {
+		return &Journald{}
+	})
+}
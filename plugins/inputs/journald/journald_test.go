@@ -0,0 +1,154 @@
+//go:build linux
+
+package journald
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Journald
+		expected string
+	}{
+		{
+			name:     "invalid priority",
+			plugin:   &Journald{Priority: "fatal"},
+			expected: `invalid priority "fatal"`,
+		},
+		{
+			name:     "match without value",
+			plugin:   &Journald{Matches: []string{"_TRANSPORT"}},
+			expected: `invalid match "_TRANSPORT"`,
+		},
+		{
+			name:     "match without field",
+			plugin:   &Journald{Matches: []string{"=kernel"}},
+			expected: `invalid match "=kernel"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitPriority(t *testing.T) {
+	plugin := &Journald{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 7, plugin.maxPriority)
+
+	plugin = &Journald{Priority: "warning", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, 4, plugin.maxPriority)
+}
+
+func TestMetric(t *testing.T) {
+	entry := map[string]string{
+		"__CURSOR":             "s=abc;i=1",
+		"__REALTIME_TIMESTAMP": "1700000000123456",
+		"_SYSTEMD_UNIT":        "sshd.service",
+		"_HOSTNAME":            "myhost",
+		"_PID":                 "1520",
+		"_COMM":                "sshd",
+		"PRIORITY":             "6",
+		"SYSLOG_IDENTIFIER":    "sshd",
+		"MESSAGE":              "Accepted publickey for admin",
+	}
+
+	tests := []struct {
+		name        string
+		tagFields   []string
+		valueFields []string
+		expected    telegraf.Metric
+	}{
+		{
+			name:        "defaults",
+			tagFields:   []string{"_SYSTEMD_UNIT", "PRIORITY", "SYSLOG_IDENTIFIER", "_HOSTNAME"},
+			valueFields: []string{"MESSAGE", "_PID"},
+			expected: metric.New(
+				"journald",
+				map[string]string{
+					"systemd_unit":      "sshd.service",
+					"priority":          "info",
+					"syslog_identifier": "sshd",
+					"hostname":          "myhost",
+				},
+				map[string]interface{}{
+					"message": "Accepted publickey for admin",
+					"pid":     int64(1520),
+				},
+				time.UnixMicro(1700000000123456),
+			),
+		},
+		{
+			name:        "globs",
+			tagFields:   []string{"_SYSTEMD_*"},
+			valueFields: []string{"*"},
+			expected: metric.New(
+				"journald",
+				map[string]string{
+					"systemd_unit": "sshd.service",
+				},
+				map[string]interface{}{
+					"hostname":          "myhost",
+					"pid":               int64(1520),
+					"comm":              "sshd",
+					"priority":          "info",
+					"syslog_identifier": "sshd",
+					"message":           "Accepted publickey for admin",
+				},
+				time.UnixMicro(1700000000123456),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Journald{
+				TagFields:   tt.tagFields,
+				ValueFields: tt.valueFields,
+				Log:         testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			testutil.RequireMetricEqual(t, tt.expected, plugin.metric(entry))
+		})
+	}
+}
+
+func TestState(t *testing.T) {
+	plugin := &Journald{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "", plugin.GetState())
+
+	require.NoError(t, plugin.SetState("s=abc;i=1"))
+	require.Equal(t, "s=abc;i=1", plugin.GetState())
+
+	require.ErrorContains(t, plugin.SetState(42), "wrong type")
+}
+
+func TestMetricWithoutValueFields(t *testing.T) {
+	plugin := &Journald{
+		TagFields:   []string{"_SYSTEMD_UNIT"},
+		ValueFields: []string{"MESSAGE"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Entries without any value field are skipped but still advance the cursor
+	var acc testutil.Accumulator
+	plugin.add(&acc, map[string]string{"_SYSTEMD_UNIT": "sshd.service"}, "s=abc;i=1")
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Equal(t, "s=abc;i=1", plugin.GetState())
+}
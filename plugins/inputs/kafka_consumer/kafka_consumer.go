@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -34,21 +36,24 @@ type empty struct{}
 type semaphore chan empty
 
 type KafkaConsumer struct {
-	Brokers                []string        `toml:"brokers"`
-	Version                string          `toml:"kafka_version"`
-	ConsumerGroup          string          `toml:"consumer_group"`
-	MaxMessageLen          int             `toml:"max_message_len"`
-	MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
-	MaxProcessingTime      config.Duration `toml:"max_processing_time"`
-	Offset                 string          `toml:"offset"`
-	BalanceStrategy        string          `toml:"balance_strategy"`
-	Topics                 []string        `toml:"topics"`
-	TopicRegexps           []string        `toml:"topic_regexps"`
-	TopicTag               string          `toml:"topic_tag"`
-	MsgHeadersAsTags       []string        `toml:"msg_headers_as_tags"`
-	MsgHeaderAsMetricName  string          `toml:"msg_header_as_metric_name"`
-	ConsumerFetchDefault   config.Size     `toml:"consumer_fetch_default"`
-	ConnectionStrategy     string          `toml:"connection_strategy"`
+	Brokers                []string          `toml:"brokers"`
+	Version                string            `toml:"kafka_version"`
+	ConsumerGroup          string            `toml:"consumer_group"`
+	MaxMessageLen          int               `toml:"max_message_len"`
+	MaxUndeliveredMessages int               `toml:"max_undelivered_messages"`
+	MaxProcessingTime      config.Duration   `toml:"max_processing_time"`
+	Offset                 string            `toml:"offset"`
+	BalanceStrategy        string            `toml:"balance_strategy"`
+	Topics                 []string          `toml:"topics"`
+	TopicRegexps           []string          `toml:"topic_regexps"`
+	TopicTag               string            `toml:"topic_tag"`
+	MsgHeadersAsTags       []string          `toml:"msg_headers_as_tags"`
+	MsgHeaderAsMetricName  string            `toml:"msg_header_as_metric_name"`
+	MsgHeaderTagMapping    map[string]string `toml:"msg_header_tag_mapping"`
+	StartAtTimestamp       string            `toml:"start_at_timestamp"`
+	StartAtOffsets         map[string]int64  `toml:"start_at_offsets"`
+	ConsumerFetchDefault   config.Size       `toml:"consumer_fetch_default"`
+	ConnectionStrategy     string            `toml:"connection_strategy"`
 
 	kafka.ReadConfig
 
@@ -66,6 +71,12 @@ type KafkaConsumer struct {
 	ticker          *time.Ticker
 	fingerprint     string
 
+	// Partitions already moved to the start position
+	startAt    time.Time
+	seekClient sarama.Client
+	seeked     map[string]bool
+	seekLock   sync.Mutex
+
 	parser    telegraf.Parser
 	topicLock sync.Mutex
 	wg        sync.WaitGroup
@@ -164,6 +175,20 @@ func (k *KafkaConsumer) Init() error {
 
 	k.config = cfg
 
+	if k.StartAtTimestamp != "" {
+		t, err := time.Parse(time.RFC3339, k.StartAtTimestamp)
+		if err != nil {
+			return fmt.Errorf("invalid start_at_timestamp: %w", err)
+		}
+		k.startAt = t
+	}
+	for key := range k.StartAtOffsets {
+		if _, _, err := splitPartitionKey(key); err != nil {
+			return fmt.Errorf("invalid start_at_offsets key %q: %w", key, err)
+		}
+	}
+	k.seeked = make(map[string]bool)
+
 	if len(k.TopicRegexps) == 0 {
 		k.allWantedTopics = k.Topics
 	} else {
@@ -260,6 +285,73 @@ func (k *KafkaConsumer) refreshTopics() error {
 	return nil
 }
 
+// splitPartitionKey splits a key of the form "<topic>:<partition>"
+func splitPartitionKey(key string) (string, int32, error) {
+	idx := strings.LastIndex(key, ":")
+	if idx < 1 {
+		return "", 0, fmt.Errorf("expected format \"<topic>:<partition>\"")
+	}
+	partition, err := strconv.ParseInt(key[idx+1:], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid partition: %w", err)
+	}
+	return key[:idx], int32(partition), nil
+}
+
+// seekPartitions moves the offsets of the claimed partitions to the configured
+// start position. Each partition is only moved once after startup, later
+// sessions e.g. after rebalancing continue at the committed offsets.
+func (k *KafkaConsumer) seekPartitions(session sarama.ConsumerGroupSession) {
+	k.seekLock.Lock()
+	defer k.seekLock.Unlock()
+
+	for topic, partitions := range session.Claims() {
+		for _, partition := range partitions {
+			key := topic + ":" + strconv.FormatInt(int64(partition), 10)
+			if k.seeked[key] {
+				continue
+			}
+			k.seeked[key] = true
+
+			offset, found := k.StartAtOffsets[key]
+			if !found {
+				if k.startAt.IsZero() {
+					continue
+				}
+				var err error
+				offset, err = k.offsetForTimestamp(topic, partition)
+				if err != nil {
+					k.Log.Errorf("Looking up offset for %s at %s failed: %v", key, k.StartAtTimestamp, err)
+					continue
+				}
+			}
+			k.Log.Debugf("Starting partition %s at offset %d", key, offset)
+			session.ResetOffset(topic, partition, offset, "")
+		}
+	}
+}
+
+// offsetForTimestamp returns the offset of the first message at or after the
+// start timestamp or the newest offset if there is no such message
+func (k *KafkaConsumer) offsetForTimestamp(topic string, partition int32) (int64, error) {
+	if k.seekClient == nil {
+		client, err := sarama.NewClient(k.Brokers, k.config)
+		if err != nil {
+			return 0, err
+		}
+		k.seekClient = client
+	}
+
+	offset, err := k.seekClient.GetOffset(topic, partition, k.startAt.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return k.seekClient.GetOffset(topic, partition, sarama.OffsetNewest)
+	}
+	return offset, nil
+}
+
 func (k *KafkaConsumer) create() error {
 	var err error
 	k.consumer, err = k.ConsumerCreator.Create(
@@ -322,6 +414,7 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 			handler := NewConsumerGroupHandler(acc, k.MaxUndeliveredMessages, k.parser, k.Log)
 			handler.MaxMessageLen = k.MaxMessageLen
 			handler.TopicTag = k.TopicTag
+			handler.ConsumerGroup = k.ConsumerGroup
 			handler.MsgHeaderToMetricName = k.MsgHeaderAsMetricName
 			//if message headers list specified, put it as map to handler
 			msgHeadersMap := make(map[string]bool, len(k.MsgHeadersAsTags))
@@ -333,6 +426,10 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 				}
 			}
 			handler.MsgHeadersToTags = msgHeadersMap
+			handler.MsgHeaderTagMapping = k.MsgHeaderTagMapping
+			if !k.startAt.IsZero() || len(k.StartAtOffsets) > 0 {
+				handler.seek = k.seekPartitions
+			}
 
 			// We need to copy allWantedTopics; the Consume() is
 			// long-running and we can easily deadlock if our
@@ -372,6 +469,10 @@ func (k *KafkaConsumer) Stop() {
 	}
 	k.cancel()
 	k.wg.Wait()
+
+	if k.seekClient != nil {
+		k.seekClient.Close()
+	}
 }
 
 // Message is an aggregate type binding the Kafka message and the session so
@@ -396,7 +497,9 @@ func NewConsumerGroupHandler(acc telegraf.Accumulator, maxUndelivered int, parse
 type ConsumerGroupHandler struct {
 	MaxMessageLen         int
 	TopicTag              string
+	ConsumerGroup         string
 	MsgHeadersToTags      map[string]bool
+	MsgHeaderTagMapping   map[string]string
 	MsgHeaderToMetricName string
 
 	acc    telegraf.TrackingAccumulator
@@ -404,18 +507,25 @@ type ConsumerGroupHandler struct {
 	parser telegraf.Parser
 	wg     sync.WaitGroup
 	cancel context.CancelFunc
+	seek   func(sarama.ConsumerGroupSession)
 
 	mu          sync.Mutex
 	undelivered map[telegraf.TrackingID]Message
+	stats       []map[string]string // tags of the partition stats of the session
 
 	log telegraf.Logger
 }
 
 // Setup is called once when a new session is opened.  It setups up the handler
 // and begins processing delivered messages.
-func (h *ConsumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+func (h *ConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
 	h.undelivered = make(map[telegraf.TrackingID]Message)
 
+	// Offsets must be reset before consuming the claims
+	if h.seek != nil {
+		h.seek(session)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	h.cancel = cancel
 
@@ -490,11 +600,16 @@ func (h *ConsumerGroupHandler) Handle(session sarama.ConsumerGroupSession, msg *
 
 	headerKey := ""
 	// Check if any message header should override metric name or should be pass as tag
-	if len(h.MsgHeadersToTags) > 0 || h.MsgHeaderToMetricName != "" {
+	if len(h.MsgHeadersToTags) > 0 || len(h.MsgHeaderTagMapping) > 0 || h.MsgHeaderToMetricName != "" {
 		for _, header := range msg.Headers {
 			//convert to a string as the header and value are byte arrays.
 			headerKey = string(header.Key)
-			if _, exists := h.MsgHeadersToTags[headerKey]; exists {
+			if tag, exists := h.MsgHeaderTagMapping[headerKey]; exists {
+				// Add the header as tag using the mapped name
+				for _, metric := range metrics {
+					metric.AddTag(tag, string(header.Value))
+				}
+			} else if _, exists := h.MsgHeadersToTags[headerKey]; exists {
 				// If message header should be pass as tag then add it to the metrics
 				for _, metric := range metrics {
 					metric.AddTag(headerKey, string(header.Value))
@@ -528,6 +643,18 @@ func (h *ConsumerGroupHandler) Handle(session sarama.ConsumerGroupSession, msg *
 func (h *ConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	ctx := session.Context()
 
+	// Report the lag of the partition as internal metrics
+	tags := map[string]string{
+		"consumer_group": h.ConsumerGroup,
+		"topic":          claim.Topic(),
+		"partition":      strconv.FormatInt(int64(claim.Partition()), 10),
+	}
+	offsetStat := selfstat.Register("kafka_consumer", "partition_offset", tags)
+	lagStat := selfstat.Register("kafka_consumer", "partition_lag", tags)
+	h.mu.Lock()
+	h.stats = append(h.stats, tags)
+	h.mu.Unlock()
+
 	for {
 		err := h.Reserve(ctx)
 		if err != nil {
@@ -541,6 +668,12 @@ func (h *ConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 			if !ok {
 				return nil
 			}
+			offsetStat.Set(msg.Offset)
+			if hwm := claim.HighWaterMarkOffset(); hwm > msg.Offset {
+				lagStat.Set(hwm - msg.Offset - 1)
+			} else {
+				lagStat.Set(0)
+			}
 			err := h.Handle(session, msg)
 			if err != nil {
 				h.acc.AddError(err)
@@ -550,10 +683,20 @@ func (h *ConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 }
 
 // Cleanup stops the internal goroutine and is called after all ConsumeClaim
-// functions have completed.
+// functions have completed. The partition statistics are removed as the
+// partitions might be revoked, the claims of the next session register them
+// again.
 func (h *ConsumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
 	h.cancel()
 	h.wg.Wait()
+
+	h.mu.Lock()
+	for _, tags := range h.stats {
+		selfstat.Unregister("kafka_consumer", "partition_offset", tags)
+		selfstat.Unregister("kafka_consumer", "partition_lag", tags)
+	}
+	h.stats = nil
+	h.mu.Unlock()
 	return nil
 }
 
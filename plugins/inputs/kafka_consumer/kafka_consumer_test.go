@@ -22,6 +22,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 	influxSerializer "github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -135,6 +136,22 @@ func TestInit(t *testing.T) {
 			},
 			initError: true,
 		},
+		{
+			name: "invalid start timestamp",
+			plugin: &KafkaConsumer{
+				StartAtTimestamp: "yesterday",
+				Log:              testutil.Logger{},
+			},
+			initError: true,
+		},
+		{
+			name: "invalid start offset key",
+			plugin: &KafkaConsumer{
+				StartAtOffsets: map[string]int64{"telegraf": 1},
+				Log:            testutil.Logger{},
+			},
+			initError: true,
+		},
 		{
 			name: "default tls without tls config",
 			plugin: &KafkaConsumer{
@@ -234,11 +251,13 @@ func TestStartStop(t *testing.T) {
 }
 
 type FakeConsumerGroupSession struct {
-	ctx context.Context
+	ctx     context.Context
+	claims  map[string][]int32
+	offsets map[string]int64
 }
 
 func (s *FakeConsumerGroupSession) Claims() map[string][]int32 {
-	panic("not implemented")
+	return s.claims
 }
 
 func (s *FakeConsumerGroupSession) MemberID() string {
@@ -253,8 +272,8 @@ func (s *FakeConsumerGroupSession) MarkOffset(_ string, _ int32, _ int64, _ stri
 	panic("not implemented")
 }
 
-func (s *FakeConsumerGroupSession) ResetOffset(_ string, _ int32, _ int64, _ string) {
-	panic("not implemented")
+func (s *FakeConsumerGroupSession) ResetOffset(topic string, partition int32, offset int64, _ string) {
+	s.offsets[fmt.Sprintf("%s:%d", topic, partition)] = offset
 }
 
 func (s *FakeConsumerGroupSession) MarkMessage(_ *sarama.ConsumerMessage, _ string) {
@@ -268,15 +287,16 @@ func (s *FakeConsumerGroupSession) Commit() {
 }
 
 type FakeConsumerGroupClaim struct {
-	messages chan *sarama.ConsumerMessage
+	messages  chan *sarama.ConsumerMessage
+	highWater int64
 }
 
 func (c *FakeConsumerGroupClaim) Topic() string {
-	panic("not implemented")
+	return "telegraf"
 }
 
 func (c *FakeConsumerGroupClaim) Partition() int32 {
-	panic("not implemented")
+	return 0
 }
 
 func (c *FakeConsumerGroupClaim) InitialOffset() int64 {
@@ -284,7 +304,7 @@ func (c *FakeConsumerGroupClaim) InitialOffset() int64 {
 }
 
 func (c *FakeConsumerGroupClaim) HighWaterMarkOffset() int64 {
-	panic("not implemented")
+	return c.highWater
 }
 
 func (c *FakeConsumerGroupClaim) Messages() <-chan *sarama.ConsumerMessage {
@@ -338,15 +358,17 @@ func TestConsumerGroupHandler_ConsumeClaim(t *testing.T) {
 
 	session := &FakeConsumerGroupSession{ctx: ctx}
 	claim := &FakeConsumerGroupClaim{
-		messages: make(chan *sarama.ConsumerMessage, 1),
+		messages:  make(chan *sarama.ConsumerMessage, 1),
+		highWater: 10,
 	}
 
 	err := cg.Setup(session)
 	require.NoError(t, err)
 
 	claim.messages <- &sarama.ConsumerMessage{
-		Topic: "telegraf",
-		Value: []byte("42"),
+		Topic:  "telegraf",
+		Value:  []byte("42"),
+		Offset: 5,
 	}
 
 	go func() {
@@ -356,6 +378,13 @@ func TestConsumerGroupHandler_ConsumeClaim(t *testing.T) {
 	}()
 
 	acc.Wait(1)
+
+	// Check the partition statistics
+	stats := partitionStats("telegraf", "0")
+	require.NotNil(t, stats)
+	require.Equal(t, int64(5), stats.Fields()["partition_offset"])
+	require.Equal(t, int64(4), stats.Fields()["partition_lag"])
+
 	cancel()
 
 	err = cg.Cleanup(session)
@@ -373,6 +402,49 @@ func TestConsumerGroupHandler_ConsumeClaim(t *testing.T) {
 	}
 
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The partition statistics should be removed with the session
+	require.Nil(t, partitionStats("telegraf", "0"))
+}
+
+// partitionStats returns the internal statistics of the given partition or nil
+// if not registered
+func partitionStats(topic, partition string) telegraf.Metric {
+	for _, m := range selfstat.Metrics() {
+		if m.Name() != "internal_kafka_consumer" {
+			continue
+		}
+		if tags := m.Tags(); tags["topic"] == topic && tags["partition"] == partition {
+			return m
+		}
+	}
+	return nil
+}
+
+func TestSeekPartitions(t *testing.T) {
+	plugin := &KafkaConsumer{
+		StartAtOffsets: map[string]int64{
+			"telegraf:0": 42,
+			"telegraf:2": 23,
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	session := &FakeConsumerGroupSession{
+		claims:  map[string][]int32{"telegraf": {0, 1}},
+		offsets: make(map[string]int64),
+	}
+	plugin.seekPartitions(session)
+	require.Equal(t, map[string]int64{"telegraf:0": 42}, session.offsets)
+
+	// Partitions are only moved once after startup
+	session = &FakeConsumerGroupSession{
+		claims:  map[string][]int32{"telegraf": {0, 1, 2}},
+		offsets: make(map[string]int64),
+	}
+	plugin.seekPartitions(session)
+	require.Equal(t, map[string]int64{"telegraf:2": 23}, session.offsets)
 }
 
 func TestConsumerGroupHandler_Handle(t *testing.T) {
@@ -380,6 +452,7 @@ func TestConsumerGroupHandler_Handle(t *testing.T) {
 		name                string
 		maxMessageLen       int
 		topicTag            string
+		headerMapping       map[string]string
 		msg                 *sarama.ConsumerMessage
 		expected            []telegraf.Metric
 		expectedHandleError string
@@ -440,6 +513,30 @@ func TestConsumerGroupHandler_Handle(t *testing.T) {
 				),
 			},
 		},
+		{
+			name:          "add mapped header tags",
+			headerMapping: map[string]string{"X-Device-Id": "device"},
+			msg: &sarama.ConsumerMessage{
+				Topic: "telegraf",
+				Value: []byte("42"),
+				Headers: []*sarama.RecordHeader{
+					{Key: []byte("X-Device-Id"), Value: []byte("sensor01")},
+					{Key: []byte("X-Other"), Value: []byte("ignored")},
+				},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{
+						"device": "sensor01",
+					},
+					map[string]interface{}{
+						"value": 42,
+					},
+					time.Now(),
+				),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -452,6 +549,7 @@ func TestConsumerGroupHandler_Handle(t *testing.T) {
 			cg := NewConsumerGroupHandler(acc, 1, &parser, testutil.Logger{})
 			cg.MaxMessageLen = tt.maxMessageLen
 			cg.TopicTag = tt.topicTag
+			cg.MsgHeaderTagMapping = tt.headerMapping
 
 			ctx := context.Background()
 			session := &FakeConsumerGroupSession{ctx: ctx}
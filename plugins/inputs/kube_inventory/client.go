@@ -6,8 +6,11 @@ import (
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
@@ -154,6 +157,30 @@ func (c *client) getResourceQuotas(ctx context.Context) (*corev1.ResourceQuotaLi
 	return c.CoreV1().ResourceQuotas(c.namespace).List(ctx, metav1.ListOptions{})
 }
 
+func (c *client) getHorizontalPodAutoscalers(ctx context.Context) (*autoscalingv2.HorizontalPodAutoscalerList, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.AutoscalingV2().HorizontalPodAutoscalers(c.namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (c *client) getPodDisruptionBudgets(ctx context.Context) (*policyv1.PodDisruptionBudgetList, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.PolicyV1().PodDisruptionBudgets(c.namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (c *client) getJobs(ctx context.Context) (*batchv1.JobList, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.BatchV1().Jobs(c.namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (c *client) getCronJobs(ctx context.Context) (*batchv1.CronJobList, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.BatchV1().CronJobs(c.namespace).List(ctx, metav1.ListOptions{})
+}
+
 func (c *client) getTLSSecrets(ctx context.Context) (*corev1.SecretList, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
@@ -0,0 +1,109 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronJob(t *testing.T) {
+	cli := &client{}
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 1, 36, 0, now.Location())
+
+	tests := []struct {
+		name     string
+		handler  *mockHandler
+		output   []telegraf.Metric
+		hasError bool
+	}{
+		{
+			name: "no cronjobs",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/cronjobs/": &batchv1.CronJobList{},
+				},
+			},
+			output:   []telegraf.Metric{},
+			hasError: false,
+		},
+		{
+			name: "collect cronjobs",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/cronjobs/": &batchv1.CronJobList{
+						Items: []batchv1.CronJob{
+							{
+								Spec: batchv1.CronJobSpec{
+									Schedule: "0 * * * *",
+									Suspend:  toBoolPtr(false),
+								},
+								Status: batchv1.CronJobStatus{
+									Active: []corev1.ObjectReference{
+										{Kind: "Job", Name: "backup-28000000"},
+									},
+									LastScheduleTime:   &metav1.Time{Time: now},
+									LastSuccessfulTime: &metav1.Time{Time: now},
+								},
+								ObjectMeta: metav1.ObjectMeta{
+									Namespace:         "ns1",
+									Name:              "backup",
+									CreationTimestamp: metav1.Time{Time: now},
+								},
+							},
+						},
+					},
+				},
+			},
+			output: []telegraf.Metric{
+				testutil.MustMetric(
+					cronJobMeasurement,
+					map[string]string{
+						"cronjob_name": "backup",
+						"namespace":    "ns1",
+						"schedule":     "0 * * * *",
+					},
+					map[string]interface{}{
+						"active":               1,
+						"suspend":              0,
+						"last_schedule_time":   now.UnixNano(),
+						"last_successful_time": now.UnixNano(),
+						"created":              now.UnixNano(),
+					},
+					time.Unix(0, 0),
+				),
+			},
+			hasError: false,
+		},
+	}
+
+	for _, v := range tests {
+		ks := &KubernetesInventory{
+			client: cli,
+		}
+		acc := new(testutil.Accumulator)
+		items := ((v.handler.responseMap["/cronjobs/"]).(*batchv1.CronJobList)).Items
+		for i := range items {
+			ks.gatherCronJob(&items[i], acc)
+		}
+
+		err := acc.FirstError()
+		if v.hasError {
+			require.Errorf(t, err, "%s failed, should have error", v.name)
+			continue
+		}
+
+		// No error case
+		require.NoErrorf(t, err, "%s failed, err: %v", v.name, err)
+
+		require.Len(t, acc.Metrics, len(v.output))
+		testutil.RequireMetricsEqual(t, v.output, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	}
+}
@@ -34,6 +34,7 @@ func (ki *KubernetesInventory) gatherDaemonSet(d *v1.DaemonSet, acc telegraf.Acc
 		"daemonset_name": d.Name,
 		"namespace":      d.Namespace,
 	}
+	ki.addMetadataTags(tags, d)
 	for key, val := range d.Spec.Selector.MatchLabels {
 		if ki.selectorFilter.Match(key) {
 			tags["selector_"+key] = val
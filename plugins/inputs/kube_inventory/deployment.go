@@ -29,6 +29,7 @@ func (ki *KubernetesInventory) gatherDeployment(d *v1.Deployment, acc telegraf.A
 		"deployment_name": d.Name,
 		"namespace":       d.Namespace,
 	}
+	ki.addMetadataTags(tags, d)
 	for key, val := range d.Spec.Selector.MatchLabels {
 		if ki.selectorFilter.Match(key) {
 			tags["selector_"+key] = val
@@ -0,0 +1,65 @@
+package kube_inventory
+
+import (
+	"context"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/influxdata/telegraf"
+)
+
+func collectHorizontalPodAutoscalers(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
+	list, err := ki.client.getHorizontalPodAutoscalers(ctx)
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+	for i := range list.Items {
+		ki.gatherHorizontalPodAutoscaler(&list.Items[i], acc)
+	}
+}
+
+func (ki *KubernetesInventory) gatherHorizontalPodAutoscaler(h *autoscalingv2.HorizontalPodAutoscaler, acc telegraf.Accumulator) {
+	fields := map[string]interface{}{
+		"max_replicas":     h.Spec.MaxReplicas,
+		"current_replicas": h.Status.CurrentReplicas,
+		"desired_replicas": h.Status.DesiredReplicas,
+	}
+	if h.Spec.MinReplicas != nil {
+		fields["min_replicas"] = *h.Spec.MinReplicas
+	}
+	creationTs := h.GetCreationTimestamp()
+	if !creationTs.IsZero() {
+		fields["created"] = creationTs.UnixNano()
+	}
+	if h.Status.LastScaleTime != nil {
+		fields["last_scale_time"] = h.Status.LastScaleTime.UnixNano()
+	}
+
+	// Conditions are reported as 1 if true and 0 otherwise
+	for _, c := range h.Status.Conditions {
+		value := 0
+		if c.Status == corev1.ConditionTrue {
+			value = 1
+		}
+		switch c.Type {
+		case autoscalingv2.AbleToScale:
+			fields["able_to_scale"] = value
+		case autoscalingv2.ScalingActive:
+			fields["scaling_active"] = value
+		case autoscalingv2.ScalingLimited:
+			fields["scaling_limited"] = value
+		}
+	}
+
+	tags := map[string]string{
+		"hpa_name":    h.Name,
+		"namespace":   h.Namespace,
+		"target_kind": h.Spec.ScaleTargetRef.Kind,
+		"target_name": h.Spec.ScaleTargetRef.Name,
+	}
+	ki.addMetadataTags(tags, h)
+
+	acc.AddFields(hpaMeasurement, fields, tags)
+}
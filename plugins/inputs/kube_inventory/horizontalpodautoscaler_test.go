@@ -0,0 +1,130 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHorizontalPodAutoscaler(t *testing.T) {
+	cli := &client{}
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 1, 36, 0, now.Location())
+
+	tests := []struct {
+		name     string
+		handler  *mockHandler
+		labels   []string
+		output   []telegraf.Metric
+		hasError bool
+	}{
+		{
+			name: "no hpas",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/horizontalpodautoscalers/": &autoscalingv2.HorizontalPodAutoscalerList{},
+				},
+			},
+			output:   []telegraf.Metric{},
+			hasError: false,
+		},
+		{
+			name: "collect hpas",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/horizontalpodautoscalers/": &autoscalingv2.HorizontalPodAutoscalerList{
+						Items: []autoscalingv2.HorizontalPodAutoscaler{
+							{
+								Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+									ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+										Kind: "Deployment",
+										Name: "deploy1",
+									},
+									MinReplicas: toInt32Ptr(2),
+									MaxReplicas: 10,
+								},
+								Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+									CurrentReplicas: 3,
+									DesiredReplicas: 4,
+									LastScaleTime:   &metav1.Time{Time: now},
+									Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+										{Type: autoscalingv2.AbleToScale, Status: corev1.ConditionTrue},
+										{Type: autoscalingv2.ScalingActive, Status: corev1.ConditionTrue},
+										{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionFalse},
+									},
+								},
+								ObjectMeta: metav1.ObjectMeta{
+									Namespace: "ns1",
+									Name:      "hpa1",
+									Labels: map[string]string{
+										"app":  "web",
+										"team": "infra",
+									},
+									CreationTimestamp: metav1.Time{Time: now},
+								},
+							},
+						},
+					},
+				},
+			},
+			labels: []string{"app"},
+			output: []telegraf.Metric{
+				testutil.MustMetric(
+					hpaMeasurement,
+					map[string]string{
+						"hpa_name":    "hpa1",
+						"namespace":   "ns1",
+						"target_kind": "Deployment",
+						"target_name": "deploy1",
+						"label_app":   "web",
+					},
+					map[string]interface{}{
+						"min_replicas":     int32(2),
+						"max_replicas":     int32(10),
+						"current_replicas": int32(3),
+						"desired_replicas": int32(4),
+						"able_to_scale":    1,
+						"scaling_active":   1,
+						"scaling_limited":  0,
+						"last_scale_time":  now.UnixNano(),
+						"created":          now.UnixNano(),
+					},
+					time.Unix(0, 0),
+				),
+			},
+			hasError: false,
+		},
+	}
+
+	for _, v := range tests {
+		ks := &KubernetesInventory{
+			client:       cli,
+			LabelInclude: v.labels,
+		}
+		require.NoError(t, ks.createMetadataFilters())
+		acc := new(testutil.Accumulator)
+		items := ((v.handler.responseMap["/horizontalpodautoscalers/"]).(*autoscalingv2.HorizontalPodAutoscalerList)).Items
+		for i := range items {
+			ks.gatherHorizontalPodAutoscaler(&items[i], acc)
+		}
+
+		err := acc.FirstError()
+		if v.hasError {
+			require.Errorf(t, err, "%s failed, should have error", v.name)
+			continue
+		}
+
+		// No error case
+		require.NoErrorf(t, err, "%s failed, err: %v", v.name, err)
+
+		require.Len(t, acc.Metrics, len(v.output))
+		testutil.RequireMetricsEqual(t, v.output, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	}
+}
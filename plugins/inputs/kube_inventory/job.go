@@ -0,0 +1,74 @@
+package kube_inventory
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/influxdata/telegraf"
+)
+
+func collectJobs(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
+	list, err := ki.client.getJobs(ctx)
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+	for i := range list.Items {
+		ki.gatherJob(&list.Items[i], acc)
+	}
+}
+
+func (ki *KubernetesInventory) gatherJob(j *batchv1.Job, acc telegraf.Accumulator) {
+	fields := map[string]interface{}{
+		"active":    j.Status.Active,
+		"succeeded": j.Status.Succeeded,
+		"failed":    j.Status.Failed,
+	}
+	if j.Spec.Completions != nil {
+		fields["completions"] = *j.Spec.Completions
+	}
+	if j.Spec.Parallelism != nil {
+		fields["parallelism"] = *j.Spec.Parallelism
+	}
+	creationTs := j.GetCreationTimestamp()
+	if !creationTs.IsZero() {
+		fields["created"] = creationTs.UnixNano()
+	}
+	if j.Status.StartTime != nil {
+		fields["start_time"] = j.Status.StartTime.UnixNano()
+	}
+	if j.Status.CompletionTime != nil {
+		fields["completion_time"] = j.Status.CompletionTime.UnixNano()
+	}
+
+	// Conditions are reported as 1 if true and 0 otherwise
+	complete, failed := 0, 0
+	for _, c := range j.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			complete = 1
+		case batchv1.JobFailed:
+			failed = 1
+		}
+	}
+	fields["status_complete"] = complete
+	fields["status_failed"] = failed
+
+	tags := map[string]string{
+		"job_name":  j.Name,
+		"namespace": j.Namespace,
+	}
+	for _, owner := range j.OwnerReferences {
+		if owner.Kind == "CronJob" {
+			tags["cronjob_name"] = owner.Name
+		}
+	}
+	ki.addMetadataTags(tags, j)
+
+	acc.AddFields(jobMeasurement, fields, tags)
+}
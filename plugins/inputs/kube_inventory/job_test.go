@@ -0,0 +1,119 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJob(t *testing.T) {
+	cli := &client{}
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 1, 36, 0, now.Location())
+	completed := now.Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		handler  *mockHandler
+		output   []telegraf.Metric
+		hasError bool
+	}{
+		{
+			name: "no jobs",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/jobs/": &batchv1.JobList{},
+				},
+			},
+			output:   []telegraf.Metric{},
+			hasError: false,
+		},
+		{
+			name: "collect jobs",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/jobs/": &batchv1.JobList{
+						Items: []batchv1.Job{
+							{
+								Spec: batchv1.JobSpec{
+									Completions: toInt32Ptr(1),
+									Parallelism: toInt32Ptr(1),
+								},
+								Status: batchv1.JobStatus{
+									Succeeded:      1,
+									StartTime:      &metav1.Time{Time: now},
+									CompletionTime: &metav1.Time{Time: completed},
+									Conditions: []batchv1.JobCondition{
+										{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+									},
+								},
+								ObjectMeta: metav1.ObjectMeta{
+									Namespace: "ns1",
+									Name:      "backup-28000000",
+									OwnerReferences: []metav1.OwnerReference{
+										{Kind: "CronJob", Name: "backup"},
+									},
+									CreationTimestamp: metav1.Time{Time: now},
+								},
+							},
+						},
+					},
+				},
+			},
+			output: []telegraf.Metric{
+				testutil.MustMetric(
+					jobMeasurement,
+					map[string]string{
+						"job_name":     "backup-28000000",
+						"namespace":    "ns1",
+						"cronjob_name": "backup",
+					},
+					map[string]interface{}{
+						"active":          int32(0),
+						"succeeded":       int32(1),
+						"failed":          int32(0),
+						"completions":     int32(1),
+						"parallelism":     int32(1),
+						"start_time":      now.UnixNano(),
+						"completion_time": completed.UnixNano(),
+						"status_complete": 1,
+						"status_failed":   0,
+						"created":         now.UnixNano(),
+					},
+					time.Unix(0, 0),
+				),
+			},
+			hasError: false,
+		},
+	}
+
+	for _, v := range tests {
+		ks := &KubernetesInventory{
+			client: cli,
+		}
+		acc := new(testutil.Accumulator)
+		items := ((v.handler.responseMap["/jobs/"]).(*batchv1.JobList)).Items
+		for i := range items {
+			ks.gatherJob(&items[i], acc)
+		}
+
+		err := acc.FirstError()
+		if v.hasError {
+			require.Errorf(t, err, "%s failed, should have error", v.name)
+			continue
+		}
+
+		// No error case
+		require.NoErrorf(t, err, "%s failed, err: %v", v.name, err)
+
+		require.Len(t, acc.Metrics, len(v.output))
+		testutil.RequireMetricsEqual(t, v.output, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	}
+}
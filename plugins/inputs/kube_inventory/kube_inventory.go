@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -42,6 +43,11 @@ type KubernetesInventory struct {
 	SelectorInclude []string `toml:"selector_include"`
 	SelectorExclude []string `toml:"selector_exclude"`
 
+	LabelInclude      []string `toml:"label_include"`
+	LabelExclude      []string `toml:"label_exclude"`
+	AnnotationInclude []string `toml:"annotation_include"`
+	AnnotationExclude []string `toml:"annotation_exclude"`
+
 	NodeName string          `toml:"node_name"`
 	Log      telegraf.Logger `toml:"-"`
 
@@ -49,7 +55,9 @@ type KubernetesInventory struct {
 	client     *client
 	httpClient *http.Client
 
-	selectorFilter filter.Filter
+	selectorFilter   filter.Filter
+	labelFilter      filter.Filter
+	annotationFilter filter.Filter
 }
 
 func (*KubernetesInventory) SampleConfig() string {
@@ -98,6 +106,10 @@ func (ki *KubernetesInventory) Gather(acc telegraf.Accumulator) (err error) {
 		return err
 	}
 
+	if err := ki.createMetadataFilters(); err != nil {
+		return err
+	}
+
 	wg := sync.WaitGroup{}
 	ctx := context.Background()
 
@@ -117,18 +129,22 @@ func (ki *KubernetesInventory) Gather(acc telegraf.Accumulator) (err error) {
 }
 
 var availableCollectors = map[string]func(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory){
-	"daemonsets":             collectDaemonSets,
-	"deployments":            collectDeployments,
-	"endpoints":              collectEndpoints,
-	"ingress":                collectIngress,
-	"nodes":                  collectNodes,
-	"pods":                   collectPods,
-	"services":               collectServices,
-	"statefulsets":           collectStatefulSets,
-	"persistentvolumes":      collectPersistentVolumes,
-	"persistentvolumeclaims": collectPersistentVolumeClaims,
-	"resourcequotas":         collectResourceQuotas,
-	"secrets":                collectSecrets,
+	"daemonsets":               collectDaemonSets,
+	"deployments":              collectDeployments,
+	"endpoints":                collectEndpoints,
+	"ingress":                  collectIngress,
+	"nodes":                    collectNodes,
+	"pods":                     collectPods,
+	"services":                 collectServices,
+	"statefulsets":             collectStatefulSets,
+	"persistentvolumes":        collectPersistentVolumes,
+	"persistentvolumeclaims":   collectPersistentVolumeClaims,
+	"resourcequotas":           collectResourceQuotas,
+	"secrets":                  collectSecrets,
+	"horizontalpodautoscalers": collectHorizontalPodAutoscalers,
+	"poddisruptionbudgets":     collectPodDisruptionBudgets,
+	"jobs":                     collectJobs,
+	"cronjobs":                 collectCronJobs,
 }
 
 func atoi(s string) int64 {
@@ -187,6 +203,49 @@ func (ki *KubernetesInventory) createSelectorFilters() error {
 	return nil
 }
 
+// createMetadataFilters creates the filters for the labels and annotations
+// added as tags. No labels or annotations are added if neither include nor
+// exclude patterns are given.
+func (ki *KubernetesInventory) createMetadataFilters() error {
+	ki.labelFilter = nil
+	if len(ki.LabelInclude) > 0 || len(ki.LabelExclude) > 0 {
+		f, err := filter.NewIncludeExcludeFilter(ki.LabelInclude, ki.LabelExclude)
+		if err != nil {
+			return fmt.Errorf("creating label filter failed: %w", err)
+		}
+		ki.labelFilter = f
+	}
+
+	ki.annotationFilter = nil
+	if len(ki.AnnotationInclude) > 0 || len(ki.AnnotationExclude) > 0 {
+		f, err := filter.NewIncludeExcludeFilter(ki.AnnotationInclude, ki.AnnotationExclude)
+		if err != nil {
+			return fmt.Errorf("creating annotation filter failed: %w", err)
+		}
+		ki.annotationFilter = f
+	}
+	return nil
+}
+
+// addMetadataTags adds the selected labels and annotations of the object as
+// tags prefixed with "label_" and "annotation_" respectively
+func (ki *KubernetesInventory) addMetadataTags(tags map[string]string, obj metav1.Object) {
+	if ki.labelFilter != nil {
+		for key, val := range obj.GetLabels() {
+			if ki.labelFilter.Match(key) {
+				tags["label_"+key] = val
+			}
+		}
+	}
+	if ki.annotationFilter != nil {
+		for key, val := range obj.GetAnnotations() {
+			if ki.annotationFilter.Match(key) {
+				tags["annotation_"+key] = val
+			}
+		}
+	}
+}
+
 const (
 	daemonSetMeasurement             = "kubernetes_daemonset"
 	deploymentMeasurement            = "kubernetes_deployment"
@@ -200,6 +259,10 @@ const (
 	statefulSetMeasurement           = "kubernetes_statefulset"
 	resourcequotaMeasurement         = "kubernetes_resourcequota"
 	certificateMeasurement           = "kubernetes_certificate"
+	hpaMeasurement                   = "kubernetes_hpa"
+	pdbMeasurement                   = "kubernetes_pdb"
+	jobMeasurement                   = "kubernetes_job"
+	cronJobMeasurement               = "kubernetes_cronjob"
 )
 
 func init() {
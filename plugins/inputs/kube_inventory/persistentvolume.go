@@ -42,6 +42,7 @@ func (ki *KubernetesInventory) gatherPersistentVolume(pv *corev1.PersistentVolum
 		"phase":        string(pv.Status.Phase),
 		"storageclass": pv.Spec.StorageClassName,
 	}
+	ki.addMetadataTags(tags, pv)
 
 	acc.AddFields(persistentVolumeMeasurement, fields, tags)
 }
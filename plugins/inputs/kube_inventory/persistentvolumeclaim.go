@@ -38,6 +38,7 @@ func (ki *KubernetesInventory) gatherPersistentVolumeClaim(pvc corev1.Persistent
 		"namespace": pvc.Namespace,
 		"phase":     string(pvc.Status.Phase),
 	}
+	ki.addMetadataTags(tags, pvc)
 	if pvc.Spec.StorageClassName != nil {
 		tags["storageclass"] = *pvc.Spec.StorageClassName
 	}
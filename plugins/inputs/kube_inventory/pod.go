@@ -102,6 +102,7 @@ func (ki *KubernetesInventory) gatherPodContainer(p *corev1.Pod, cs corev1.Conta
 		"state":          state,
 		"readiness":      readiness,
 	}
+	ki.addMetadataTags(tags, p)
 	splitImage := strings.Split(c.Image, ":")
 	if len(splitImage) == 2 {
 		tags["version"] = splitImage[1]
@@ -147,6 +148,7 @@ func (ki *KubernetesInventory) gatherPodContainer(p *corev1.Pod, cs corev1.Conta
 		if len(splitImage) == 2 {
 			conditiontags["version"] = splitImage[1]
 		}
+		ki.addMetadataTags(conditiontags, p)
 		running := 0
 		podready := 0
 		if val.Status == "True" {
@@ -0,0 +1,58 @@
+package kube_inventory
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/influxdata/telegraf"
+)
+
+func collectPodDisruptionBudgets(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
+	list, err := ki.client.getPodDisruptionBudgets(ctx)
+	if err != nil {
+		acc.AddError(err)
+		return
+	}
+	for i := range list.Items {
+		ki.gatherPodDisruptionBudget(&list.Items[i], acc)
+	}
+}
+
+func (ki *KubernetesInventory) gatherPodDisruptionBudget(p *policyv1.PodDisruptionBudget, acc telegraf.Accumulator) {
+	fields := map[string]interface{}{
+		"current_healthy":     p.Status.CurrentHealthy,
+		"desired_healthy":     p.Status.DesiredHealthy,
+		"disruptions_allowed": p.Status.DisruptionsAllowed,
+		"expected_pods":       p.Status.ExpectedPods,
+	}
+	creationTs := p.GetCreationTimestamp()
+	if !creationTs.IsZero() {
+		fields["created"] = creationTs.UnixNano()
+	}
+
+	// Percentages are not resolved as the number of pods is already reflected
+	// in the desired healthy pods
+	if p.Spec.MinAvailable != nil && p.Spec.MinAvailable.Type == intstr.Int {
+		fields["min_available"] = p.Spec.MinAvailable.IntVal
+	}
+	if p.Spec.MaxUnavailable != nil && p.Spec.MaxUnavailable.Type == intstr.Int {
+		fields["max_unavailable"] = p.Spec.MaxUnavailable.IntVal
+	}
+
+	tags := map[string]string{
+		"pdb_name":  p.Name,
+		"namespace": p.Namespace,
+	}
+	if p.Spec.Selector != nil {
+		for key, val := range p.Spec.Selector.MatchLabels {
+			if ki.selectorFilter.Match(key) {
+				tags["selector_"+key] = val
+			}
+		}
+	}
+	ki.addMetadataTags(tags, p)
+
+	acc.AddFields(pdbMeasurement, fields, tags)
+}
@@ -0,0 +1,156 @@
+package kube_inventory
+
+import (
+	"testing"
+	"time"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPodDisruptionBudget(t *testing.T) {
+	cli := &client{}
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 1, 36, 0, now.Location())
+	minAvailable := intstr.FromInt32(2)
+	maxUnavailable := intstr.FromString("50%")
+
+	tests := []struct {
+		name        string
+		handler     *mockHandler
+		annotations []string
+		output      []telegraf.Metric
+		hasError    bool
+	}{
+		{
+			name: "no pdbs",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/poddisruptionbudgets/": &policyv1.PodDisruptionBudgetList{},
+				},
+			},
+			output:   []telegraf.Metric{},
+			hasError: false,
+		},
+		{
+			name: "collect pdbs",
+			handler: &mockHandler{
+				responseMap: map[string]interface{}{
+					"/poddisruptionbudgets/": &policyv1.PodDisruptionBudgetList{
+						Items: []policyv1.PodDisruptionBudget{
+							{
+								Spec: policyv1.PodDisruptionBudgetSpec{
+									MinAvailable: &minAvailable,
+									Selector: &metav1.LabelSelector{
+										MatchLabels: map[string]string{
+											"app": "web",
+										},
+									},
+								},
+								Status: policyv1.PodDisruptionBudgetStatus{
+									CurrentHealthy:     3,
+									DesiredHealthy:     2,
+									DisruptionsAllowed: 1,
+									ExpectedPods:       3,
+								},
+								ObjectMeta: metav1.ObjectMeta{
+									Namespace: "ns1",
+									Name:      "pdb1",
+									Annotations: map[string]string{
+										"owner":   "infra",
+										"comment": "ignored",
+									},
+									CreationTimestamp: metav1.Time{Time: now},
+								},
+							},
+							{
+								Spec: policyv1.PodDisruptionBudgetSpec{
+									MaxUnavailable: &maxUnavailable,
+								},
+								Status: policyv1.PodDisruptionBudgetStatus{
+									CurrentHealthy:     4,
+									DesiredHealthy:     2,
+									DisruptionsAllowed: 2,
+									ExpectedPods:       4,
+								},
+								ObjectMeta: metav1.ObjectMeta{
+									Namespace:         "ns1",
+									Name:              "pdb2",
+									CreationTimestamp: metav1.Time{Time: now},
+								},
+							},
+						},
+					},
+				},
+			},
+			annotations: []string{"owner"},
+			output: []telegraf.Metric{
+				testutil.MustMetric(
+					pdbMeasurement,
+					map[string]string{
+						"pdb_name":         "pdb1",
+						"namespace":        "ns1",
+						"selector_app":     "web",
+						"annotation_owner": "infra",
+					},
+					map[string]interface{}{
+						"current_healthy":     int32(3),
+						"desired_healthy":     int32(2),
+						"disruptions_allowed": int32(1),
+						"expected_pods":       int32(3),
+						"min_available":       int32(2),
+						"created":             now.UnixNano(),
+					},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric(
+					pdbMeasurement,
+					map[string]string{
+						"pdb_name":  "pdb2",
+						"namespace": "ns1",
+					},
+					map[string]interface{}{
+						"current_healthy":     int32(4),
+						"desired_healthy":     int32(2),
+						"disruptions_allowed": int32(2),
+						"expected_pods":       int32(4),
+						"created":             now.UnixNano(),
+					},
+					time.Unix(0, 0),
+				),
+			},
+			hasError: false,
+		},
+	}
+
+	for _, v := range tests {
+		ks := &KubernetesInventory{
+			client:            cli,
+			AnnotationInclude: v.annotations,
+		}
+		require.NoError(t, ks.createSelectorFilters())
+		require.NoError(t, ks.createMetadataFilters())
+		acc := new(testutil.Accumulator)
+		items := ((v.handler.responseMap["/poddisruptionbudgets/"]).(*policyv1.PodDisruptionBudgetList)).Items
+		for i := range items {
+			ks.gatherPodDisruptionBudget(&items[i], acc)
+		}
+
+		err := acc.FirstError()
+		if v.hasError {
+			require.Errorf(t, err, "%s failed, should have error", v.name)
+			continue
+		}
+
+		// No error case
+		require.NoErrorf(t, err, "%s failed, err: %v", v.name, err)
+
+		require.Len(t, acc.Metrics, len(v.output))
+		testutil.RequireMetricsEqual(t, v.output, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	}
+}
@@ -26,6 +26,7 @@ func (ki *KubernetesInventory) gatherResourceQuota(r corev1.ResourceQuota, acc t
 		"resource":  r.Name,
 		"namespace": r.Namespace,
 	}
+	ki.addMetadataTags(tags, &r)
 
 	for resourceName, val := range r.Status.Hard {
 		switch resourceName {
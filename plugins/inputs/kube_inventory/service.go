@@ -34,6 +34,7 @@ func (ki *KubernetesInventory) gatherService(s *corev1.Service, acc telegraf.Acc
 		"service_name": s.Name,
 		"namespace":    s.Namespace,
 	}
+	ki.addMetadataTags(tags, s)
 
 	for key, val := range s.Spec.Selector {
 		if ki.selectorFilter.Match(key) {
@@ -37,6 +37,7 @@ func (ki *KubernetesInventory) gatherStatefulSet(s *v1.StatefulSet, acc telegraf
 		"statefulset_name": s.Name,
 		"namespace":        s.Namespace,
 	}
+	ki.addMetadataTags(tags, s)
 	if s.Spec.Selector != nil {
 		for key, val := range s.Spec.Selector.MatchLabels {
 			if ki.selectorFilter.Match(key) {
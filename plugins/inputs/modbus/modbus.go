@@ -8,7 +8,9 @@ import (
 	"net"
 	"net/url"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	mb "github.com/grid-x/modbus"
@@ -53,6 +55,7 @@ type Modbus struct {
 	StopBits          int               `toml:"stop_bits"`
 	RS485             *RS485Config      `toml:"rs485"`
 	Timeout           config.Duration   `toml:"timeout"`
+	MaxConnections    int               `toml:"max_connections"`
 	Retries           int               `toml:"busy_retries"`
 	RetriesWaitTime   config.Duration   `toml:"busy_retries_wait"`
 	DebugConnection   bool              `toml:"debug_connection"`
@@ -66,13 +69,19 @@ type Modbus struct {
 	ConfigurationPerMetric
 
 	// Connection handling
-	client      mb.Client
-	handler     mb.ClientHandler
-	isConnected bool
+	pool []*connection
 	// Request handling
 	requests map[byte]requestSet
 }
 
+// connection to the controller used for querying one or more slaves
+type connection struct {
+	handler     mb.ClientHandler
+	client      mb.Client
+	isConnected bool
+	slaves      []byte
+}
+
 type fieldConverterFunc func(bytes []byte) interface{}
 
 type requestSet struct {
@@ -135,6 +144,10 @@ func (m *Modbus) Init() error {
 		return fmt.Errorf("retries cannot be negative")
 	}
 
+	if m.MaxConnections < 0 {
+		return fmt.Errorf("max_connections cannot be negative")
+	}
+
 	// Determine the configuration style
 	var cfg Configuration
 	switch m.ConfigurationType {
@@ -203,51 +216,84 @@ func (m *Modbus) Init() error {
 
 // Gather implements the telegraf plugin interface method for data accumulation
 func (m *Modbus) Gather(acc telegraf.Accumulator) error {
-	if !m.isConnected {
-		if err := m.connect(); err != nil {
-			return err
+	if len(m.pool) == 1 {
+		c := m.pool[0]
+		for _, slaveID := range c.slaves {
+			if err := m.gatherSlave(acc, c, slaveID); err != nil {
+				return err
+			}
 		}
-	}
-
-	for slaveID, requests := range m.requests {
-		m.Log.Debugf("Reading slave %d for %s...", slaveID, m.Controller)
-		if err := m.readSlaveData(slaveID, requests); err != nil {
-			acc.AddError(fmt.Errorf("slave %d: %w", slaveID, err))
-			var mbErr *mb.Error
-			if !errors.As(err, &mbErr) || mbErr.ExceptionCode != mb.ExceptionCodeServerDeviceBusy {
-				m.Log.Debugf("Reconnecting to %s...", m.Controller)
-				if err := m.disconnect(); err != nil {
-					return fmt.Errorf("disconnecting failed: %w", err)
-				}
-				if err := m.connect(); err != nil {
-					return fmt.Errorf("slave %d: connecting failed: %w", slaveID, err)
+	} else {
+		// Query the slaves of the different connections in parallel, the
+		// slaves of each connection are queried sequentially
+		var wg sync.WaitGroup
+		for _, c := range m.pool {
+			wg.Add(1)
+			go func(c *connection) {
+				defer wg.Done()
+				for _, slaveID := range c.slaves {
+					if err := m.gatherSlave(acc, c, slaveID); err != nil {
+						acc.AddError(err)
+						return
+					}
 				}
-			}
-			continue
+			}(c)
 		}
-		timestamp := time.Now()
+		wg.Wait()
+	}
 
-		tags := map[string]string{
-			"name":     m.Name,
-			"type":     cCoils,
-			"slave_id": strconv.Itoa(int(slaveID)),
+	// Disconnect after read if configured
+	if m.Workarounds.CloseAfterGather {
+		for _, c := range m.pool {
+			if err := m.disconnect(c); err != nil {
+				return err
+			}
 		}
-		m.collectFields(acc, timestamp, tags, requests.coil)
+	}
 
-		tags["type"] = cDiscreteInputs
-		m.collectFields(acc, timestamp, tags, requests.discrete)
+	return nil
+}
 
-		tags["type"] = cHoldingRegisters
-		m.collectFields(acc, timestamp, tags, requests.holding)
+func (m *Modbus) gatherSlave(acc telegraf.Accumulator, c *connection, slaveID byte) error {
+	requests := m.requests[slaveID]
+	if !c.isConnected {
+		if err := m.connect(c); err != nil {
+			return err
+		}
+	}
 
-		tags["type"] = cInputRegisters
-		m.collectFields(acc, timestamp, tags, requests.input)
+	m.Log.Debugf("Reading slave %d for %s...", slaveID, m.Controller)
+	if err := m.readSlaveData(c, slaveID, requests); err != nil {
+		acc.AddError(fmt.Errorf("slave %d: %w", slaveID, err))
+		var mbErr *mb.Error
+		if !errors.As(err, &mbErr) || mbErr.ExceptionCode != mb.ExceptionCodeServerDeviceBusy {
+			m.Log.Debugf("Reconnecting to %s...", m.Controller)
+			if err := m.disconnect(c); err != nil {
+				return fmt.Errorf("disconnecting failed: %w", err)
+			}
+			if err := m.connect(c); err != nil {
+				return fmt.Errorf("slave %d: connecting failed: %w", slaveID, err)
+			}
+		}
+		return nil
 	}
+	timestamp := time.Now()
 
-	// Disconnect after read if configured
-	if m.Workarounds.CloseAfterGather {
-		return m.disconnect()
+	tags := map[string]string{
+		"name":     m.Name,
+		"type":     cCoils,
+		"slave_id": strconv.Itoa(int(slaveID)),
 	}
+	m.collectFields(acc, timestamp, tags, requests.coil)
+
+	tags["type"] = cDiscreteInputs
+	m.collectFields(acc, timestamp, tags, requests.discrete)
+
+	tags["type"] = cHoldingRegisters
+	m.collectFields(acc, timestamp, tags, requests.holding)
+
+	tags["type"] = cInputRegisters
+	m.collectFields(acc, timestamp, tags, requests.input)
 
 	return nil
 }
@@ -257,117 +303,160 @@ func (m *Modbus) initClient() error {
 	if err != nil {
 		return err
 	}
+	if m.MaxConnections > 1 && u.Scheme != "tcp" {
+		return fmt.Errorf("multiple connections are only supported for TCP controllers, not %q", m.Controller)
+	}
+
+	// Use a single connection for all slaves if not configured otherwise
+	// and never more connections than slaves
+	n := 1
+	if m.MaxConnections > 1 {
+		n = max(min(m.MaxConnections, len(m.requests)), 1)
+	}
+	m.pool = make([]*connection, 0, n)
+	for i := 0; i < n; i++ {
+		c, err := m.newConnection(u)
+		if err != nil {
+			return err
+		}
+		m.pool = append(m.pool, c)
+	}
+
+	// Distribute the slaves evenly across the connections
+	slaveIDs := make([]byte, 0, len(m.requests))
+	for slaveID := range m.requests {
+		slaveIDs = append(slaveIDs, slaveID)
+	}
+	sort.Slice(slaveIDs, func(i, j int) bool { return slaveIDs[i] < slaveIDs[j] })
+	for i, slaveID := range slaveIDs {
+		c := m.pool[i%n]
+		c.slaves = append(c.slaves, slaveID)
+	}
+
+	return nil
+}
+
+func (m *Modbus) newConnection(u *url.URL) (*connection, error) {
+	handler, err := m.newHandler(u)
+	if err != nil {
+		return nil, err
+	}
+	return &connection{
+		handler: handler,
+		client:  mb.NewClient(handler),
+	}, nil
+}
+
+func (m *Modbus) newHandler(u *url.URL) (mb.ClientHandler, error) {
+	var handler mb.ClientHandler
 
 	switch u.Scheme {
 	case "tcp":
 		host, port, err := net.SplitHostPort(u.Host)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		switch m.TransmissionMode {
 		case "", "auto", "TCP":
-			handler := mb.NewTCPClientHandler(host + ":" + port)
-			handler.Timeout = time.Duration(m.Timeout)
+			h := mb.NewTCPClientHandler(host + ":" + port)
+			h.Timeout = time.Duration(m.Timeout)
 			if m.DebugConnection {
-				handler.Logger = m
+				h.Logger = m
 			}
-			m.handler = handler
+			handler = h
 		case "RTUoverTCP":
-			handler := mb.NewRTUOverTCPClientHandler(host + ":" + port)
-			handler.Timeout = time.Duration(m.Timeout)
+			h := mb.NewRTUOverTCPClientHandler(host + ":" + port)
+			h.Timeout = time.Duration(m.Timeout)
 			if m.DebugConnection {
-				handler.Logger = m
+				h.Logger = m
 			}
-			m.handler = handler
+			handler = h
 		case "ASCIIoverTCP":
-			handler := mb.NewASCIIOverTCPClientHandler(host + ":" + port)
-			handler.Timeout = time.Duration(m.Timeout)
+			h := mb.NewASCIIOverTCPClientHandler(host + ":" + port)
+			h.Timeout = time.Duration(m.Timeout)
 			if m.DebugConnection {
-				handler.Logger = m
+				h.Logger = m
 			}
-			m.handler = handler
+			handler = h
 		default:
-			return fmt.Errorf("invalid transmission mode %q for %q", m.TransmissionMode, u.Scheme)
+			return nil, fmt.Errorf("invalid transmission mode %q for %q", m.TransmissionMode, u.Scheme)
 		}
 	case "", "file":
 		path := filepath.Join(u.Host, u.Path)
 		if path == "" {
-			return fmt.Errorf("invalid path for controller %q", m.Controller)
+			return nil, fmt.Errorf("invalid path for controller %q", m.Controller)
 		}
 		switch m.TransmissionMode {
 		case "", "auto", "RTU":
-			handler := mb.NewRTUClientHandler(path)
-			handler.Timeout = time.Duration(m.Timeout)
-			handler.BaudRate = m.BaudRate
-			handler.DataBits = m.DataBits
-			handler.Parity = m.Parity
-			handler.StopBits = m.StopBits
+			h := mb.NewRTUClientHandler(path)
+			h.Timeout = time.Duration(m.Timeout)
+			h.BaudRate = m.BaudRate
+			h.DataBits = m.DataBits
+			h.Parity = m.Parity
+			h.StopBits = m.StopBits
 			if m.DebugConnection {
-				handler.Logger = m
+				h.Logger = m
 			}
 			if m.RS485 != nil {
-				handler.RS485.Enabled = true
-				handler.RS485.DelayRtsBeforeSend = time.Duration(m.RS485.DelayRtsBeforeSend)
-				handler.RS485.DelayRtsAfterSend = time.Duration(m.RS485.DelayRtsAfterSend)
-				handler.RS485.RtsHighDuringSend = m.RS485.RtsHighDuringSend
-				handler.RS485.RtsHighAfterSend = m.RS485.RtsHighAfterSend
-				handler.RS485.RxDuringTx = m.RS485.RxDuringTx
+				h.RS485.Enabled = true
+				h.RS485.DelayRtsBeforeSend = time.Duration(m.RS485.DelayRtsBeforeSend)
+				h.RS485.DelayRtsAfterSend = time.Duration(m.RS485.DelayRtsAfterSend)
+				h.RS485.RtsHighDuringSend = m.RS485.RtsHighDuringSend
+				h.RS485.RtsHighAfterSend = m.RS485.RtsHighAfterSend
+				h.RS485.RxDuringTx = m.RS485.RxDuringTx
 			}
-			m.handler = handler
+			handler = h
 		case "ASCII":
-			handler := mb.NewASCIIClientHandler(path)
-			handler.Timeout = time.Duration(m.Timeout)
-			handler.BaudRate = m.BaudRate
-			handler.DataBits = m.DataBits
-			handler.Parity = m.Parity
-			handler.StopBits = m.StopBits
+			h := mb.NewASCIIClientHandler(path)
+			h.Timeout = time.Duration(m.Timeout)
+			h.BaudRate = m.BaudRate
+			h.DataBits = m.DataBits
+			h.Parity = m.Parity
+			h.StopBits = m.StopBits
 			if m.DebugConnection {
-				handler.Logger = m
+				h.Logger = m
 			}
 			if m.RS485 != nil {
-				handler.RS485.Enabled = true
-				handler.RS485.DelayRtsBeforeSend = time.Duration(m.RS485.DelayRtsBeforeSend)
-				handler.RS485.DelayRtsAfterSend = time.Duration(m.RS485.DelayRtsAfterSend)
-				handler.RS485.RtsHighDuringSend = m.RS485.RtsHighDuringSend
-				handler.RS485.RtsHighAfterSend = m.RS485.RtsHighAfterSend
-				handler.RS485.RxDuringTx = m.RS485.RxDuringTx
+				h.RS485.Enabled = true
+				h.RS485.DelayRtsBeforeSend = time.Duration(m.RS485.DelayRtsBeforeSend)
+				h.RS485.DelayRtsAfterSend = time.Duration(m.RS485.DelayRtsAfterSend)
+				h.RS485.RtsHighDuringSend = m.RS485.RtsHighDuringSend
+				h.RS485.RtsHighAfterSend = m.RS485.RtsHighAfterSend
+				h.RS485.RxDuringTx = m.RS485.RxDuringTx
 			}
-			m.handler = handler
+			handler = h
 		default:
-			return fmt.Errorf("invalid transmission mode %q for %q", m.TransmissionMode, u.Scheme)
+			return nil, fmt.Errorf("invalid transmission mode %q for %q", m.TransmissionMode, u.Scheme)
 		}
 	default:
-		return fmt.Errorf("invalid controller %q", m.Controller)
+		return nil, fmt.Errorf("invalid controller %q", m.Controller)
 	}
 
-	m.client = mb.NewClient(m.handler)
-	m.isConnected = false
-
-	return nil
+	return handler, nil
 }
 
 // Connect to a MODBUS Slave device via Modbus/[TCP|RTU|ASCII]
-func (m *Modbus) connect() error {
-	err := m.handler.Connect()
-	m.isConnected = err == nil
-	if m.isConnected && m.Workarounds.AfterConnectPause != 0 {
+func (m *Modbus) connect(c *connection) error {
+	err := c.handler.Connect()
+	c.isConnected = err == nil
+	if c.isConnected && m.Workarounds.AfterConnectPause != 0 {
 		nextRequest := time.Now().Add(time.Duration(m.Workarounds.AfterConnectPause))
 		time.Sleep(time.Until(nextRequest))
 	}
 	return err
 }
 
-func (m *Modbus) disconnect() error {
-	err := m.handler.Close()
-	m.isConnected = false
+func (m *Modbus) disconnect(c *connection) error {
+	err := c.handler.Close()
+	c.isConnected = false
 	return err
 }
 
-func (m *Modbus) readSlaveData(slaveID byte, requests requestSet) error {
-	m.handler.SetSlave(slaveID)
+func (m *Modbus) readSlaveData(c *connection, slaveID byte, requests requestSet) error {
+	c.handler.SetSlave(slaveID)
 
 	for retry := 0; retry < m.Retries; retry++ {
-		err := m.gatherFields(requests)
+		err := m.gatherFields(c.client, requests)
 		if err == nil {
 			// Reading was successful
 			return nil
@@ -383,26 +472,26 @@ func (m *Modbus) readSlaveData(slaveID byte, requests requestSet) error {
 		m.Log.Infof("Device busy! Retrying %d more time(s)...", m.Retries-retry)
 		time.Sleep(time.Duration(m.RetriesWaitTime))
 	}
-	return m.gatherFields(requests)
+	return m.gatherFields(c.client, requests)
 }
 
-func (m *Modbus) gatherFields(requests requestSet) error {
-	if err := m.gatherRequestsCoil(requests.coil); err != nil {
+func (m *Modbus) gatherFields(client mb.Client, requests requestSet) error {
+	if err := m.gatherRequestsCoil(client, requests.coil); err != nil {
 		return err
 	}
-	if err := m.gatherRequestsDiscrete(requests.discrete); err != nil {
+	if err := m.gatherRequestsDiscrete(client, requests.discrete); err != nil {
 		return err
 	}
-	if err := m.gatherRequestsHolding(requests.holding); err != nil {
+	if err := m.gatherRequestsHolding(client, requests.holding); err != nil {
 		return err
 	}
-	return m.gatherRequestsInput(requests.input)
+	return m.gatherRequestsInput(client, requests.input)
 }
 
-func (m *Modbus) gatherRequestsCoil(requests []request) error {
+func (m *Modbus) gatherRequestsCoil(client mb.Client, requests []request) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read coil@%v[%v]...", request.address, request.length)
-		bytes, err := m.client.ReadCoils(request.address, request.length)
+		bytes, err := client.ReadCoils(request.address, request.length)
 		if err != nil {
 			return err
 		}
@@ -426,10 +515,10 @@ func (m *Modbus) gatherRequestsCoil(requests []request) error {
 	return nil
 }
 
-func (m *Modbus) gatherRequestsDiscrete(requests []request) error {
+func (m *Modbus) gatherRequestsDiscrete(client mb.Client, requests []request) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read discrete@%v[%v]...", request.address, request.length)
-		bytes, err := m.client.ReadDiscreteInputs(request.address, request.length)
+		bytes, err := client.ReadDiscreteInputs(request.address, request.length)
 		if err != nil {
 			return err
 		}
@@ -453,10 +542,10 @@ func (m *Modbus) gatherRequestsDiscrete(requests []request) error {
 	return nil
 }
 
-func (m *Modbus) gatherRequestsHolding(requests []request) error {
+func (m *Modbus) gatherRequestsHolding(client mb.Client, requests []request) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read holding@%v[%v]...", request.address, request.length)
-		bytes, err := m.client.ReadHoldingRegisters(request.address, request.length)
+		bytes, err := client.ReadHoldingRegisters(request.address, request.length)
 		if err != nil {
 			return err
 		}
@@ -480,10 +569,10 @@ func (m *Modbus) gatherRequestsHolding(requests []request) error {
 	return nil
 }
 
-func (m *Modbus) gatherRequestsInput(requests []request) error {
+func (m *Modbus) gatherRequestsInput(client mb.Client, requests []request) error {
 	for _, request := range requests {
 		m.Log.Debugf("trying to read input@%v[%v]...", request.address, request.length)
-		bytes, err := m.client.ReadInputRegisters(request.address, request.length)
+		bytes, err := client.ReadInputRegisters(request.address, request.length)
 		if err != nil {
 			return err
 		}
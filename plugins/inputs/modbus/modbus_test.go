@@ -571,3 +571,126 @@ func TestRequestsWorkaroundsReadCoilsStartingAtZeroRegister(t *testing.T) {
 	require.Equal(t, maxQuantityCoils, plugin.requests[1].coil[1].address)
 	require.Equal(t, uint16(1), plugin.requests[1].coil[1].length)
 }
+
+func TestMaxConnections(t *testing.T) {
+	serv := mbserver.NewServer()
+	require.NoError(t, serv.ListenTCP("localhost:1502"))
+	defer serv.Close()
+	serv.HoldingRegisters[0] = 42
+
+	plugin := Modbus{
+		Name:              "Test",
+		Controller:        "tcp://localhost:1502",
+		ConfigurationType: "request",
+		MaxConnections:    2,
+		Log:               testutil.Logger{},
+	}
+	for _, slaveID := range []byte{1, 2} {
+		plugin.Requests = append(plugin.Requests, requestDefinition{
+			SlaveID:      slaveID,
+			ByteOrder:    "ABCD",
+			RegisterType: "holding",
+			Fields: []requestFieldDefinition{
+				{
+					Name:      "value",
+					Address:   uint16(0),
+					InputType: "UINT16",
+				},
+			},
+		})
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"modbus",
+			map[string]string{
+				"type":     cHoldingRegisters,
+				"slave_id": "1",
+				"name":     "Test",
+			},
+			map[string]interface{}{"value": uint16(42)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"modbus",
+			map[string]string{
+				"type":     cHoldingRegisters,
+				"slave_id": "2",
+				"name":     "Test",
+			},
+			map[string]interface{}{"value": uint16(42)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.pool, 2)
+	require.Equal(t, []byte{1}, plugin.pool[0].slaves)
+	require.Equal(t, []byte{2}, plugin.pool[1].slaves)
+	require.NoError(t, plugin.Gather(&acc))
+	require.NoError(t, acc.FirstError())
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestMaxConnectionsPool(t *testing.T) {
+	newPlugin := func(maxConnections int) *Modbus {
+		plugin := &Modbus{
+			Name:              "Test",
+			Controller:        "tcp://localhost:1502",
+			ConfigurationType: "request",
+			MaxConnections:    maxConnections,
+			Log:               testutil.Logger{},
+		}
+		for _, slaveID := range []byte{5, 1, 3, 2, 4} {
+			plugin.Requests = append(plugin.Requests, requestDefinition{
+				SlaveID:      slaveID,
+				ByteOrder:    "ABCD",
+				RegisterType: "holding",
+				Fields: []requestFieldDefinition{
+					{
+						Name:      "value",
+						Address:   uint16(0),
+						InputType: "UINT16",
+					},
+				},
+			})
+		}
+		return plugin
+	}
+
+	plugin := newPlugin(2)
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.pool, 2)
+	require.Equal(t, []byte{1, 3, 5}, plugin.pool[0].slaves)
+	require.Equal(t, []byte{2, 4}, plugin.pool[1].slaves)
+
+	// Never use more connections than slaves
+	plugin = newPlugin(10)
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.pool, 5)
+
+	// Use a single connection by default
+	plugin = newPlugin(0)
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.pool, 1)
+	require.Equal(t, []byte{1, 2, 3, 4, 5}, plugin.pool[0].slaves)
+}
+
+func TestMaxConnectionsSerial(t *testing.T) {
+	plugin := Modbus{
+		Name:           "Test",
+		Controller:     "file:///dev/ttyUSB0",
+		MaxConnections: 2,
+		Log:            testutil.Logger{},
+	}
+	plugin.SlaveID = 1
+	plugin.Coils = []fieldDefinition{
+		{
+			Name:    "coil",
+			Address: []uint16{0},
+		},
+	}
+	require.ErrorContains(t, plugin.Init(), "multiple connections are only supported for TCP controllers")
+}
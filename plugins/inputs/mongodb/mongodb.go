@@ -17,6 +17,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
 	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -34,8 +35,12 @@ type MongoDB struct {
 	GatherPerdbStats            bool
 	GatherColStats              bool
 	GatherTopStat               bool
+	GatherColLatencyStats       bool
+	GatherCurrentOp             bool
+	CurrentOpMinDuration        config.Duration
 	DisconnectedServersBehavior string
 	ColStatsDbs                 []string
+	Atlas                       *Atlas
 	tlsint.ClientConfig
 
 	Log telegraf.Logger `toml:"-"`
@@ -86,7 +91,14 @@ func (m *MongoDB) Init() error {
 		}
 	}
 
-	if len(m.Servers) == 0 {
+	if m.Atlas != nil {
+		if err := m.Atlas.init(m.Log); err != nil {
+			return err
+		}
+	}
+
+	// Only default to the local server if not gathering from Atlas
+	if len(m.Servers) == 0 && m.Atlas == nil {
 		m.Servers = []string{"mongodb://127.0.0.1:27017"}
 	}
 
@@ -181,9 +193,29 @@ func (m *MongoDB) Gather(acc telegraf.Accumulator) error {
 			if err != nil {
 				m.Log.Errorf("Failed to gather data: %s", err)
 			}
+
+			if m.GatherCurrentOp {
+				if err := srv.gatherCurrentOp(acc, time.Duration(m.CurrentOpMinDuration)); err != nil {
+					srv.authLog(fmt.Errorf("unable to gather current operations: %w", err))
+				}
+			}
+
+			if m.GatherColLatencyStats {
+				if err := srv.gatherCollectionLatencyStats(acc, m.ColStatsDbs); err != nil {
+					m.Log.Errorf("Failed to gather collection latency stats: %s", err)
+				}
+			}
 		}(client)
 	}
 
+	if m.Atlas != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			acc.AddError(m.Atlas.gather(acc))
+		}()
+	}
+
 	wg.Wait()
 	return nil
 }
@@ -191,11 +223,12 @@ func (m *MongoDB) Gather(acc telegraf.Accumulator) error {
 func init() {
 	inputs.Add("mongodb", func() telegraf.Input {
 		return &MongoDB{
-			GatherClusterStatus: true,
-			GatherPerdbStats:    false,
-			GatherColStats:      false,
-			GatherTopStat:       false,
-			ColStatsDbs:         []string{"local"},
+			GatherClusterStatus:  true,
+			GatherPerdbStats:     false,
+			GatherColStats:       false,
+			GatherTopStat:        false,
+			ColStatsDbs:          []string{"local"},
+			CurrentOpMinDuration: config.Duration(time.Second),
 		}
 	})
 }
@@ -0,0 +1,166 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+)
+
+const atlasAcceptHeader = "application/vnd.atlas.2023-01-01+json"
+
+// Atlas collects the process measurements via the MongoDB Atlas
+// Administration API for deployments not permitting the serverStatus command
+type Atlas struct {
+	URL          string   `toml:"url"`
+	GroupID      string   `toml:"group_id"`
+	Processes    []string `toml:"processes"`
+	Measurements []string `toml:"measurements"`
+	Granularity  string   `toml:"granularity"`
+	Period       string   `toml:"period"`
+	httpconfig.HTTPClientConfig
+
+	client *http.Client
+	last   map[string]time.Time
+}
+
+type atlasProcesses struct {
+	Results []struct {
+		ID string `json:"id"`
+	} `json:"results"`
+}
+
+type atlasMeasurements struct {
+	ProcessID    string `json:"processId"`
+	Measurements []struct {
+		Name       string `json:"name"`
+		DataPoints []struct {
+			Timestamp time.Time `json:"timestamp"`
+			Value     *float64  `json:"value"`
+		} `json:"dataPoints"`
+	} `json:"measurements"`
+}
+
+func (a *Atlas) init(log telegraf.Logger) error {
+	if a.GroupID == "" {
+		return errors.New("atlas: group_id required")
+	}
+	if a.URL == "" {
+		a.URL = "https://cloud.mongodb.com"
+	}
+	a.URL = strings.TrimSuffix(a.URL, "/")
+	if a.Granularity == "" {
+		a.Granularity = "PT1M"
+	}
+	if a.Period == "" {
+		a.Period = "PT5M"
+	}
+	if a.ClientID != "" && a.TokenURL == "" {
+		a.TokenURL = a.URL + "/api/oauth/token"
+	}
+
+	client, err := a.HTTPClientConfig.CreateClient(context.Background(), log)
+	if err != nil {
+		return fmt.Errorf("atlas: creating client failed: %w", err)
+	}
+	a.client = client
+	a.last = make(map[string]time.Time)
+
+	return nil
+}
+
+func (a *Atlas) gather(acc telegraf.Accumulator) error {
+	processes := a.Processes
+	if len(processes) == 0 {
+		var list atlasProcesses
+		address := fmt.Sprintf("%s/api/atlas/v2/groups/%s/processes?itemsPerPage=500", a.URL, url.PathEscape(a.GroupID))
+		if err := a.get(address, &list); err != nil {
+			return fmt.Errorf("listing processes failed: %w", err)
+		}
+		for _, p := range list.Results {
+			processes = append(processes, p.ID)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("granularity", a.Granularity)
+	params.Set("period", a.Period)
+	for _, m := range a.Measurements {
+		params.Add("m", m)
+	}
+
+	for _, process := range processes {
+		var result atlasMeasurements
+		address := fmt.Sprintf("%s/api/atlas/v2/groups/%s/processes/%s/measurements?%s",
+			a.URL, url.PathEscape(a.GroupID), url.PathEscape(process), params.Encode())
+		if err := a.get(address, &result); err != nil {
+			acc.AddError(fmt.Errorf("getting measurements of process %q failed: %w", process, err))
+			continue
+		}
+		a.addMeasurements(acc, process, &result)
+	}
+	return nil
+}
+
+// addMeasurements adds the latest data point of each measurement not reported
+// before grouped by the data point's timestamp
+func (a *Atlas) addMeasurements(acc telegraf.Accumulator, process string, result *atlasMeasurements) {
+	grouped := make(map[time.Time]map[string]interface{})
+	for _, m := range result.Measurements {
+		for i := len(m.DataPoints) - 1; i >= 0; i-- {
+			dp := m.DataPoints[i]
+			if dp.Value == nil {
+				continue
+			}
+
+			key := process + "/" + m.Name
+			if !dp.Timestamp.After(a.last[key]) {
+				break
+			}
+			a.last[key] = dp.Timestamp
+
+			if _, found := grouped[dp.Timestamp]; !found {
+				grouped[dp.Timestamp] = make(map[string]interface{})
+			}
+			grouped[dp.Timestamp][strings.ToLower(m.Name)] = *dp.Value
+			break
+		}
+	}
+
+	for ts, fields := range grouped {
+		tags := map[string]string{
+			"group_id": a.GroupID,
+			"hostname": process,
+		}
+		acc.AddFields("mongodb_atlas", fields, tags, ts)
+	}
+}
+
+func (a *Atlas) get(address string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", atlasAcceptHeader)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received status %d (%s): %s", resp.StatusCode, http.StatusText(resp.StatusCode), strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
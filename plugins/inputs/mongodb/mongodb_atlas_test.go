@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestAtlasInitFail(t *testing.T) {
+	plugin := &MongoDB{
+		Atlas: &Atlas{},
+		Log:   testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "atlas: group_id required")
+}
+
+func TestAtlasGather(t *testing.T) {
+	measurements := `{
+  "processId": "atlas-abc-shard-00-00.xyz.mongodb.net:27017",
+  "measurements": [
+    {
+      "name": "CONNECTIONS",
+      "units": "SCALAR",
+      "dataPoints": [
+        {"timestamp": "2023-11-02T10:00:00Z", "value": 10},
+        {"timestamp": "2023-11-02T10:01:00Z", "value": 12}
+      ]
+    },
+    {
+      "name": "OPCOUNTER_QUERY",
+      "units": "SCALAR_PER_SECOND",
+      "dataPoints": [
+        {"timestamp": "2023-11-02T10:00:00Z", "value": 1.5},
+        {"timestamp": "2023-11-02T10:01:00Z", "value": null}
+      ]
+    }
+  ]
+}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != atlasAcceptHeader {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/atlas/v2/groups/g1/processes":
+			_, _ = w.Write([]byte(`{"results": [{"id": "atlas-abc-shard-00-00.xyz.mongodb.net:27017"}], "totalCount": 1}`))
+		case "/api/atlas/v2/groups/g1/processes/atlas-abc-shard-00-00.xyz.mongodb.net:27017/measurements":
+			if r.URL.Query().Get("granularity") != "PT1M" || r.URL.Query().Get("period") != "PT5M" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(measurements))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &MongoDB{
+		Atlas: &Atlas{
+			URL:     server.URL,
+			GroupID: "g1",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Empty(t, plugin.Servers)
+	require.NoError(t, plugin.Start(nil))
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{
+		"group_id": "g1",
+		"hostname": "atlas-abc-shard-00-00.xyz.mongodb.net:27017",
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"mongodb_atlas",
+			tags,
+			map[string]interface{}{"opcounter_query": 1.5},
+			time.Date(2023, 11, 2, 10, 0, 0, 0, time.UTC),
+		),
+		metric.New(
+			"mongodb_atlas",
+			tags,
+			map[string]interface{}{"connections": float64(12)},
+			time.Date(2023, 11, 2, 10, 1, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Data points must only be reported once
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -382,6 +382,177 @@ func (s *Server) gatherData(
 	return nil
 }
 
+type currentOp struct {
+	Op               string `bson:"op"`
+	Namespace        string `bson:"ns"`
+	MicrosecsRunning int64  `bson:"microsecs_running"`
+	WaitingForLock   bool   `bson:"waitingForLock"`
+}
+
+type currentOpKey struct {
+	op        string
+	namespace string
+}
+
+type currentOpSummary struct {
+	active         int64
+	waitingForLock int64
+	totalRunning   time.Duration
+	maxRunning     time.Duration
+}
+
+// gatherCurrentOp samples the operations in progress running for at least
+// the given duration and reports them summarized by operation type and
+// namespace
+func (s *Server) gatherCurrentOp(acc telegraf.Accumulator, minDuration time.Duration) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$currentOp", Value: bson.D{{Key: "allUsers", Value: true}, {Key: "idleConnections", Value: false}}}},
+		{{Key: "$match", Value: bson.D{
+			{Key: "active", Value: true},
+			{Key: "microsecs_running", Value: bson.D{{Key: "$gte", Value: minDuration.Microseconds()}}},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "op", Value: 1},
+			{Key: "ns", Value: 1},
+			{Key: "microsecs_running", Value: 1},
+			{Key: "waitingForLock", Value: 1},
+		}}},
+	}
+
+	cursor, err := s.client.Database("admin").Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return fmt.Errorf("running $currentOp failed: %w", err)
+	}
+	var ops []currentOp
+	if err := cursor.All(context.Background(), &ops); err != nil {
+		return fmt.Errorf("decoding $currentOp result failed: %w", err)
+	}
+
+	now := time.Now()
+	for key, summary := range summarizeCurrentOps(ops) {
+		tags := s.getDefaultTags()
+		tags["op"] = key.op
+		if key.namespace != "" {
+			tags["ns"] = key.namespace
+		}
+		fields := map[string]interface{}{
+			"active":                summary.active,
+			"waiting_for_lock":      summary.waitingForLock,
+			"total_running_seconds": summary.totalRunning.Seconds(),
+			"max_running_seconds":   summary.maxRunning.Seconds(),
+		}
+		acc.AddFields("mongodb_current_op", fields, tags, now)
+	}
+	return nil
+}
+
+func summarizeCurrentOps(ops []currentOp) map[currentOpKey]*currentOpSummary {
+	summaries := make(map[currentOpKey]*currentOpSummary)
+	for _, op := range ops {
+		key := currentOpKey{op: op.Op, namespace: op.Namespace}
+		if key.op == "" {
+			key.op = "none"
+		}
+		summary, found := summaries[key]
+		if !found {
+			summary = &currentOpSummary{}
+			summaries[key] = summary
+		}
+
+		running := time.Duration(op.MicrosecsRunning) * time.Microsecond
+		summary.active++
+		summary.totalRunning += running
+		if running > summary.maxRunning {
+			summary.maxRunning = running
+		}
+		if op.WaitingForLock {
+			summary.waitingForLock++
+		}
+	}
+	return summaries
+}
+
+type latencyStat struct {
+	Latency int64 `bson:"latency"`
+	Ops     int64 `bson:"ops"`
+}
+
+type collectionLatencyStats struct {
+	Shard        string `bson:"shard"`
+	LatencyStats struct {
+		Reads        latencyStat `bson:"reads"`
+		Writes       latencyStat `bson:"writes"`
+		Commands     latencyStat `bson:"commands"`
+		Transactions latencyStat `bson:"transactions"`
+	} `bson:"latencyStats"`
+}
+
+func (c *collectionLatencyStats) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"reads_latency_us":        c.LatencyStats.Reads.Latency,
+		"reads_ops":               c.LatencyStats.Reads.Ops,
+		"writes_latency_us":       c.LatencyStats.Writes.Latency,
+		"writes_ops":              c.LatencyStats.Writes.Ops,
+		"commands_latency_us":     c.LatencyStats.Commands.Latency,
+		"commands_ops":            c.LatencyStats.Commands.Ops,
+		"transactions_latency_us": c.LatencyStats.Transactions.Latency,
+		"transactions_ops":        c.LatencyStats.Transactions.Ops,
+	}
+}
+
+// gatherCollectionLatencyStats collects the cumulative operation latencies of
+// the collections using the $collStats aggregation stage
+func (s *Server) gatherCollectionLatencyStats(acc telegraf.Accumulator, colStatsDbs []string) error {
+	names, err := s.client.ListDatabaseNames(context.Background(), bson.D{})
+	if err != nil {
+		return err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$collStats", Value: bson.D{{Key: "latencyStats", Value: bson.D{}}}}},
+	}
+
+	now := time.Now()
+	for _, dbName := range names {
+		if len(colStatsDbs) > 0 && !stringInSlice(dbName, colStatsDbs) {
+			continue
+		}
+
+		// views do not support $collStats
+		filter := bson.M{"type": bson.M{"$in": bson.A{"collection", "timeseries"}}}
+		colls, err := s.client.Database(dbName).ListCollectionNames(context.Background(), filter)
+		if err != nil {
+			s.Log.Errorf("Error getting collection names: %s", err.Error())
+			continue
+		}
+
+		for _, colName := range colls {
+			cursor, err := s.client.Database(dbName).Collection(colName).Aggregate(context.Background(), pipeline)
+			if err != nil {
+				s.authLog(fmt.Errorf("error getting latency stats from %q: %w", colName, err))
+				continue
+			}
+			var stats []collectionLatencyStats
+			if err := cursor.All(context.Background(), &stats); err != nil {
+				s.authLog(fmt.Errorf("error decoding latency stats from %q: %w", colName, err))
+				continue
+			}
+
+			// sharded collections report one document per shard
+			for _, stat := range stats {
+				tags := s.getDefaultTags()
+				tags["db_name"] = dbName
+				tags["collection"] = colName
+				if stat.Shard != "" {
+					tags["shard"] = stat.Shard
+				}
+				acc.AddFields("mongodb_col_latency_stats", stat.fields(), tags, now)
+			}
+		}
+	}
+	return nil
+}
+
 func stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
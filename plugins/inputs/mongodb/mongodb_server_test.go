@@ -3,6 +3,7 @@ package mongodb
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/require"
@@ -187,3 +188,29 @@ func TestPoolStatsVersionCompatibility(t *testing.T) {
 		})
 	}
 }
+
+func TestSummarizeCurrentOps(t *testing.T) {
+	ops := []currentOp{
+		{Op: "query", Namespace: "app.users", MicrosecsRunning: 2000000},
+		{Op: "query", Namespace: "app.users", MicrosecsRunning: 5000000, WaitingForLock: true},
+		{Op: "update", Namespace: "app.orders", MicrosecsRunning: 1500000},
+		{MicrosecsRunning: 3000000},
+	}
+
+	summaries := summarizeCurrentOps(ops)
+	require.Len(t, summaries, 3)
+
+	users := summaries[currentOpKey{op: "query", namespace: "app.users"}]
+	require.Equal(t, &currentOpSummary{
+		active:         2,
+		waitingForLock: 1,
+		totalRunning:   7 * time.Second,
+		maxRunning:     5 * time.Second,
+	}, users)
+
+	orders := summaries[currentOpKey{op: "update", namespace: "app.orders"}]
+	require.Equal(t, int64(1), orders.active)
+	require.Equal(t, 1500*time.Millisecond, orders.maxRunning)
+
+	require.Contains(t, summaries, currentOpKey{op: "none"})
+}
@@ -6,15 +6,18 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	mqttv5 "github.com/eclipse/paho.golang/paho"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -51,6 +54,7 @@ type Client interface {
 type ClientFactory func(o *mqtt.ClientOptions) Client
 type TopicParsingConfig struct {
 	Topic       string            `toml:"topic"`
+	Pattern     string            `toml:"pattern"`
 	Measurement string            `toml:"measurement"`
 	Tags        string            `toml:"tags"`
 	Fields      string            `toml:"fields"`
@@ -60,10 +64,15 @@ type TopicParsingConfig struct {
 	SplitTags        []string
 	SplitFields      []string
 	SplitTopic       []string
+
+	pattern *regexp.Regexp
 }
 type MQTTConsumer struct {
 	Servers                []string             `toml:"servers"`
+	Protocol               string               `toml:"protocol"`
 	Topics                 []string             `toml:"topics"`
+	SharedSubscription     string               `toml:"shared_subscription_group"`
+	UserPropertyTags       []string             `toml:"user_property_tags"`
 	TopicTag               *string              `toml:"topic_tag"`
 	TopicParsing           []TopicParsingConfig `toml:"topic_parsing"`
 	Username               config.Secret        `toml:"username"`
@@ -90,6 +99,7 @@ type MQTTConsumer struct {
 	messages      map[telegraf.TrackingID]mqtt.Message
 	messagesMutex sync.Mutex
 	topicTagParse string
+	propertyTags  filter.Filter
 	ctx           context.Context
 	cancel        context.CancelFunc
 	payloadSize   selfstat.Stat
@@ -123,6 +133,34 @@ func (m *MQTTConsumer) Init() error {
 	if time.Duration(m.ConnectionTimeout) < 1*time.Second {
 		return fmt.Errorf("connection_timeout must be greater than 1s: %s", time.Duration(m.ConnectionTimeout))
 	}
+	switch m.Protocol {
+	case "", "3.1.1":
+		if len(m.UserPropertyTags) > 0 {
+			return errors.New("user_property_tags requires protocol version 5")
+		}
+		if m.clientFactory == nil {
+			m.clientFactory = func(o *mqtt.ClientOptions) Client {
+				return mqtt.NewClient(o)
+			}
+		}
+	case "5":
+		if m.clientFactory == nil {
+			m.clientFactory = newMQTTv5Client
+		}
+	default:
+		return fmt.Errorf("unsupported protocol %q: must be \"3.1.1\" or \"5\"", m.Protocol)
+	}
+	if strings.ContainsAny(m.SharedSubscription, "/+#") {
+		return fmt.Errorf("invalid shared subscription group %q", m.SharedSubscription)
+	}
+	if len(m.UserPropertyTags) > 0 {
+		f, err := filter.Compile(m.UserPropertyTags)
+		if err != nil {
+			return fmt.Errorf("compiling user property tags failed: %w", err)
+		}
+		m.propertyTags = f
+	}
+
 	m.topicTagParse = "topic"
 	if m.TopicTag != nil {
 		m.topicTagParse = *m.TopicTag
@@ -135,6 +173,22 @@ func (m *MQTTConsumer) Init() error {
 	m.messages = map[telegraf.TrackingID]mqtt.Message{}
 
 	for i, p := range m.TopicParsing {
+		// Regular expressions with named groups replace the positional scheme
+		if p.Pattern != "" {
+			if p.Measurement != "" || p.Tags != "" || p.Fields != "" {
+				return errors.New("config error topic parsing: pattern cannot be combined with measurement, tags or fields")
+			}
+			re, err := regexp.Compile(p.Pattern)
+			if err != nil {
+				return fmt.Errorf("config error topic parsing: compiling pattern failed: %w", err)
+			}
+			m.TopicParsing[i].pattern = re
+			if p.Topic != "" {
+				m.TopicParsing[i].SplitTopic = strings.Split(p.Topic, "/")
+			}
+			continue
+		}
+
 		splitMeasurement := strings.Split(p.Measurement, "/")
 		for j := range splitMeasurement {
 			if splitMeasurement[j] != "_" && splitMeasurement[j] != "" {
@@ -192,7 +246,7 @@ func (m *MQTTConsumer) connect() error {
 	// know where to dispatch persisted and new messages to.  In the alternate
 	// case that we need to create the subscriptions these will be replaced.
 	for _, topic := range m.Topics {
-		m.client.AddRoute(topic, m.onMessage)
+		m.client.AddRoute(m.subscriptionTopic(topic), m.onMessage)
 	}
 	token := m.client.Connect()
 	if token.Wait() && token.Error() != nil {
@@ -213,7 +267,7 @@ func (m *MQTTConsumer) connect() error {
 	}
 	topics := make(map[string]byte)
 	for _, topic := range m.Topics {
-		topics[topic] = byte(m.QoS)
+		topics[m.subscriptionTopic(topic)] = byte(m.QoS)
 	}
 	subscribeToken := m.client.SubscribeMultiple(topics, m.onMessage)
 	subscribeToken.Wait()
@@ -222,6 +276,16 @@ func (m *MQTTConsumer) connect() error {
 	}
 	return nil
 }
+
+// subscriptionTopic returns the topic filter to subscribe to, taking into
+// account the shared subscription group if any
+func (m *MQTTConsumer) subscriptionTopic(topic string) string {
+	if m.SharedSubscription == "" {
+		return topic
+	}
+	return "$share/" + m.SharedSubscription + "/" + topic
+}
+
 func (m *MQTTConsumer) onConnectionLost(_ mqtt.Client, err error) {
 	// Should already be disconnected, but make doubly sure
 	m.client.Disconnect(5)
@@ -288,12 +352,39 @@ func (m *MQTTConsumer) onMessage(_ mqtt.Client, msg mqtt.Message) {
 		return
 	}
 
+	// User properties are only available for MQTT 5 messages
+	var properties mqttv5.UserProperties
+	if m.propertyTags != nil {
+		if msgv5, ok := msg.(interface{ UserProperties() mqttv5.UserProperties }); ok {
+			properties = msgv5.UserProperties()
+		}
+	}
+
 	for _, metric := range metrics {
 		if m.topicTagParse != "" {
 			metric.AddTag(m.topicTagParse, msg.Topic())
 		}
+		for _, property := range properties {
+			if m.propertyTags.Match(property.Key) {
+				metric.AddTag(property.Key, property.Value)
+			}
+		}
 		for _, p := range m.TopicParsing {
 			values := strings.Split(msg.Topic(), "/")
+			if p.pattern != nil {
+				if p.Topic != "" && !compareTopics(p.SplitTopic, values) {
+					continue
+				}
+				if err := parsePattern(p.pattern, msg.Topic(), p.FieldTypes, metric); err != nil {
+					if m.PersistentSession {
+						msg.Ack()
+					}
+					m.acc.AddError(err)
+					<-m.sem
+					return
+				}
+				continue
+			}
 			if !compareTopics(p.SplitTopic, values) {
 				continue
 			}
@@ -425,6 +516,38 @@ func parseMetric(keys []string, values []string, types map[string]string, isTag
 	return nil
 }
 
+// parsePattern extracts the measurement, tags and fields from the named groups
+// of the topic-parsing pattern. Groups with a configured type are added as
+// fields, all other groups except "measurement" are added as tags.
+func parsePattern(re *regexp.Regexp, topic string, types map[string]string, metric telegraf.Metric) error {
+	match := re.FindStringSubmatch(topic)
+	if match == nil {
+		return nil
+	}
+
+	for i, name := range re.SubexpNames() {
+		// Skip unnamed groups and optional groups not taking part in the match
+		if name == "" || match[i] == "" {
+			continue
+		}
+
+		if name == "measurement" {
+			metric.SetName(match[i])
+			continue
+		}
+		if _, found := types[name]; found {
+			value, err := typeConvert(types, match[i], name)
+			if err != nil {
+				return err
+			}
+			metric.AddField(name, value)
+			continue
+		}
+		metric.AddTag(name, match[i])
+	}
+	return nil
+}
+
 func typeConvert(types map[string]string, topicValue string, key string) (interface{}, error) {
 	var newType interface{}
 	var err error
@@ -467,8 +590,6 @@ func New(factory ClientFactory) *MQTTConsumer {
 }
 func init() {
 	inputs.Add("mqtt_consumer", func() telegraf.Input {
-		return New(func(o *mqtt.ClientOptions) Client {
-			return mqtt.NewClient(o)
-		})
+		return New(nil)
 	})
 }
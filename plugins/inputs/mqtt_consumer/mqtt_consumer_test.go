@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	mqttv5 "github.com/eclipse/paho.golang/paho"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/stretchr/testify/require"
 
@@ -447,6 +448,73 @@ func TestTopicTag(t *testing.T) {
 				),
 			},
 		},
+		{
+			name:  "topic parsing configured with pattern",
+			topic: "sensors/CLE/device5/temp",
+			topicTag: func() *string {
+				tag := ""
+				return &tag
+			},
+			topicParsing: []TopicParsingConfig{
+				{
+					Pattern: `^sensors/(?P<site>[^/]+)/device(?P<device>\d+)/(?P<measurement>[^/]+)$`,
+					FieldTypes: map[string]string{
+						"device": "int",
+					},
+				},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"temp",
+					map[string]string{
+						"site": "CLE",
+					},
+					map[string]interface{}{
+						"device":    5,
+						"time_idle": 42,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:  "topic parsing configured with pattern not matching",
+			topic: "telegraf/123/test",
+			topicTag: func() *string {
+				tag := ""
+				return &tag
+			},
+			topicParsing: []TopicParsingConfig{
+				{
+					Pattern: `^sensors/(?P<site>[^/]+)$`,
+				},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"time_idle": 42,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:  "topic parsing configured with pattern and positional scheme",
+			topic: "telegraf/123/test",
+			topicTag: func() *string {
+				tag := ""
+				return &tag
+			},
+			expectedError: fmt.Errorf("config error topic parsing: pattern cannot be combined with measurement, tags or fields"),
+			topicParsing: []TopicParsingConfig{
+				{
+					Pattern: `^telegraf/(?P<id>[^/]+)/test$`,
+					Tags:    "testTag/_/_",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -592,3 +660,130 @@ func TestSubscribeNotCalledIfSession(t *testing.T) {
 
 	require.Equal(t, 0, client.subscribeCallCount)
 }
+
+func TestSharedSubscription(t *testing.T) {
+	var routes []string
+	var subscriptions []string
+	client := &FakeClient{
+		ConnectF: func() mqtt.Token {
+			return &FakeToken{}
+		},
+		AddRouteF: func(topic string, callback mqtt.MessageHandler) {
+			routes = append(routes, topic)
+		},
+		SubscribeMultipleF: func(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+			for topic := range filters {
+				subscriptions = append(subscriptions, topic)
+			}
+			return &FakeToken{}
+		},
+		DisconnectF: func(quiesce uint) {
+		},
+	}
+	plugin := New(func(o *mqtt.ClientOptions) Client {
+		return client
+	})
+	plugin.Log = testutil.Logger{}
+	plugin.Protocol = "5"
+	plugin.Topics = []string{"telegraf/+/cpu"}
+	plugin.SharedSubscription = "consumers"
+
+	parser := &FakeParser{}
+	plugin.SetParser(parser)
+
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Equal(t, []string{"$share/consumers/telegraf/+/cpu"}, routes)
+	require.Equal(t, []string{"$share/consumers/telegraf/+/cpu"}, subscriptions)
+}
+
+type v5FakeMessage struct {
+	Message
+	properties mqttv5.UserProperties
+}
+
+func (m *v5FakeMessage) UserProperties() mqttv5.UserProperties {
+	return m.properties
+}
+
+func TestUserPropertyTags(t *testing.T) {
+	var handler mqtt.MessageHandler
+	client := &FakeClient{
+		ConnectF: func() mqtt.Token {
+			return &FakeToken{}
+		},
+		AddRouteF: func(topic string, callback mqtt.MessageHandler) {
+			handler = callback
+		},
+		SubscribeMultipleF: func(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+			return &FakeToken{}
+		},
+		DisconnectF: func(quiesce uint) {
+		},
+	}
+	plugin := New(func(o *mqtt.ClientOptions) Client {
+		return client
+	})
+	plugin.Log = testutil.Logger{}
+	plugin.Protocol = "5"
+	plugin.Topics = []string{"telegraf"}
+	plugin.UserPropertyTags = []string{"device_*"}
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	plugin.SetParser(parser)
+
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	m := &v5FakeMessage{
+		Message: Message{topic: "telegraf"},
+		properties: mqttv5.UserProperties{
+			{Key: "device_id", Value: "abc"},
+			{Key: "device_site", Value: "CLE"},
+			{Key: "content", Value: "ignored"},
+		},
+	}
+	handler(nil, m)
+
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"topic":       "telegraf",
+				"device_id":   "abc",
+				"device_site": "CLE",
+			},
+			map[string]interface{}{
+				"time_idle": 42,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestProtocolOptions(t *testing.T) {
+	plugin := New(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.Protocol = "4"
+	require.ErrorContains(t, plugin.Init(), `unsupported protocol "4"`)
+
+	plugin = New(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.UserPropertyTags = []string{"*"}
+	require.ErrorContains(t, plugin.Init(), "user_property_tags requires protocol version 5")
+
+	plugin = New(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.SharedSubscription = "a/b"
+	require.ErrorContains(t, plugin.Init(), `invalid shared subscription group "a/b"`)
+}
@@ -0,0 +1,234 @@
+package mqtt_consumer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/packets"
+	mqttv5 "github.com/eclipse/paho.golang/paho"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// mqttv5Client adapts the MQTT 5 client to the interface used by the plugin
+// for the MQTT 3.1.1 client. Similar to the 3.1.1 client, the connection is
+// not re-established automatically but by the plugin's Gather function.
+type mqttv5Client struct {
+	opts   *mqtt.ClientOptions
+	router *mqttv5.StandardRouter
+	client *mqttv5.Client
+	sync.Mutex
+}
+
+func newMQTTv5Client(opts *mqtt.ClientOptions) Client {
+	return &mqttv5Client{
+		opts:   opts,
+		router: mqttv5.NewStandardRouter(),
+	}
+}
+
+func (c *mqttv5Client) Connect() mqtt.Token {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.ConnectTimeout)
+	defer cancel()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return &v5Token{err: err}
+	}
+
+	client := mqttv5.NewClient(mqttv5.ClientConfig{
+		Conn:                       conn,
+		Router:                     c.router,
+		EnableManualAcknowledgment: c.opts.AutoAckDisabled,
+		OnClientError: func(err error) {
+			c.opts.OnConnectionLost(nil, err)
+		},
+		OnServerDisconnect: func(d *mqttv5.Disconnect) {
+			err := fmt.Errorf("disconnected by server with reason code %d", d.ReasonCode)
+			if d.Properties != nil && d.Properties.ReasonString != "" {
+				err = fmt.Errorf("disconnected by server: %s", d.Properties.ReasonString)
+			}
+			c.opts.OnConnectionLost(nil, err)
+		},
+	})
+
+	cp := &mqttv5.Connect{
+		ClientID:     c.opts.ClientID,
+		KeepAlive:    uint16(c.opts.KeepAlive),
+		CleanStart:   c.opts.CleanSession,
+		Username:     c.opts.Username,
+		UsernameFlag: c.opts.Username != "",
+		Password:     []byte(c.opts.Password),
+		PasswordFlag: c.opts.Password != "",
+	}
+	// In contrast to MQTT 3.1.1 the session ends with the connection unless
+	// an expiry interval is given, so keep persistent sessions forever
+	if !c.opts.CleanSession {
+		expiry := uint32(math.MaxUint32)
+		cp.Properties = &mqttv5.ConnectProperties{SessionExpiryInterval: &expiry}
+	}
+
+	connack, err := client.Connect(ctx, cp)
+	if err != nil {
+		if connack != nil && connack.Properties != nil && connack.Properties.ReasonString != "" {
+			err = fmt.Errorf("%w: %s", err, connack.Properties.ReasonString)
+		}
+		return &v5Token{err: err}
+	}
+
+	c.Lock()
+	c.client = client
+	c.Unlock()
+
+	return &v5Token{sessionPresent: connack.SessionPresent}
+}
+
+// dial connects to the first reachable server
+func (c *mqttv5Client) dial(ctx context.Context) (net.Conn, error) {
+	var errs []error
+	for _, server := range c.opts.Servers {
+		var conn net.Conn
+		var err error
+		switch server.Scheme {
+		case "tcp", "mqtt":
+			dialer := &net.Dialer{}
+			conn, err = dialer.DialContext(ctx, "tcp", server.Host)
+		case "ssl", "tls", "tcps", "mqtts":
+			dialer := &tls.Dialer{Config: c.opts.TLSConfig}
+			conn, err = dialer.DialContext(ctx, "tcp", server.Host)
+		default:
+			err = fmt.Errorf("unsupported scheme %q for protocol version 5", server.Scheme)
+		}
+		if err == nil {
+			return packets.NewThreadSafeConn(conn), nil
+		}
+		errs = append(errs, fmt.Errorf("connecting to %q failed: %w", server.Host, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (c *mqttv5Client) SubscribeMultiple(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+	c.Lock()
+	client := c.client
+	c.Unlock()
+	if client == nil {
+		return &v5Token{err: errors.New("not connected")}
+	}
+
+	subscriptions := make(map[string]mqttv5.SubscribeOptions, len(filters))
+	for topic, qos := range filters {
+		c.AddRoute(topic, callback)
+		subscriptions[topic] = mqttv5.SubscribeOptions{QoS: qos}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.ConnectTimeout)
+	defer cancel()
+	_, err := client.Subscribe(ctx, &mqttv5.Subscribe{Subscriptions: subscriptions})
+	return &v5Token{err: err}
+}
+
+func (c *mqttv5Client) AddRoute(topic string, callback mqtt.MessageHandler) {
+	// Replace existing handlers like the MQTT 3.1.1 client does
+	c.router.UnregisterHandler(topic)
+	c.router.RegisterHandler(topic, func(p *mqttv5.Publish) {
+		callback(nil, &v5Message{client: c, publish: p})
+	})
+}
+
+func (c *mqttv5Client) Disconnect(quiesce uint) {
+	c.Lock()
+	client := c.client
+	c.client = nil
+	c.Unlock()
+	if client == nil {
+		return
+	}
+
+	// Give in-flight acknowledgements the chance to be sent
+	time.Sleep(time.Duration(quiesce) * time.Millisecond)
+	_ = client.Disconnect(&mqttv5.Disconnect{ReasonCode: 0})
+}
+
+func (c *mqttv5Client) ack(p *mqttv5.Publish) {
+	c.Lock()
+	client := c.client
+	c.Unlock()
+	if client != nil {
+		_ = client.Ack(p)
+	}
+}
+
+// v5Message satisfies mqtt.Message for messages received via MQTT 5
+type v5Message struct {
+	client  *mqttv5Client
+	publish *mqttv5.Publish
+}
+
+func (*v5Message) Duplicate() bool {
+	return false
+}
+
+func (m *v5Message) Qos() byte {
+	return m.publish.QoS
+}
+
+func (m *v5Message) Retained() bool {
+	return m.publish.Retain
+}
+
+func (m *v5Message) Topic() string {
+	return m.publish.Topic
+}
+
+func (m *v5Message) MessageID() uint16 {
+	return m.publish.PacketID
+}
+
+func (m *v5Message) Payload() []byte {
+	return m.publish.Payload
+}
+
+func (m *v5Message) Ack() {
+	m.client.ack(m.publish)
+}
+
+func (m *v5Message) UserProperties() mqttv5.UserProperties {
+	if m.publish.Properties == nil {
+		return nil
+	}
+	return m.publish.Properties.User
+}
+
+// v5Token satisfies mqtt.Token for the already completed operations of the
+// MQTT 5 client
+type v5Token struct {
+	err            error
+	sessionPresent bool
+}
+
+func (*v5Token) Wait() bool {
+	return true
+}
+
+func (*v5Token) WaitTimeout(time.Duration) bool {
+	return true
+}
+
+func (*v5Token) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (t *v5Token) Error() error {
+	return t.err
+}
+
+func (t *v5Token) SessionPresent() bool {
+	return t.sessionPresent
+}
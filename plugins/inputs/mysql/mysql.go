@@ -48,6 +48,9 @@ type Mysql struct {
 	GatherPerfEventsStatements          bool             `toml:"gather_perf_events_statements"`
 	GatherGlobalVars                    bool             `toml:"gather_global_variables"`
 	GatherPerfSummaryPerAccountPerEvent bool             `toml:"gather_perf_sum_per_acc_per_event"`
+	GatherGroupReplication              bool             `toml:"gather_group_replication"`
+	GatherCloneStatus                   bool             `toml:"gather_clone_status"`
+	GatherInnoDBIOLatency               bool             `toml:"gather_innodb_io_latency"`
 	PerfSummaryEvents                   []string         `toml:"perf_summary_events"`
 	IntervalSlow                        config.Duration  `toml:"interval_slow"`
 	MetricVersion                       int              `toml:"metric_version"`
@@ -412,6 +415,53 @@ const (
 			sum_no_good_index_used
 		FROM performance_schema.events_statements_summary_by_account_by_event_name
 	`
+	groupReplicationQuery = `
+        SELECT
+            m.MEMBER_ID,
+            ifnull(m.MEMBER_HOST, ''),
+            ifnull(m.MEMBER_PORT, ''),
+            ifnull(m.MEMBER_STATE, ''),
+            ifnull(m.MEMBER_ROLE, ''),
+            ifnull(s.COUNT_TRANSACTIONS_IN_QUEUE, 0),
+            ifnull(s.COUNT_TRANSACTIONS_CHECKED, 0),
+            ifnull(s.COUNT_CONFLICTS_DETECTED, 0),
+            ifnull(s.COUNT_TRANSACTIONS_ROWS_VALIDATING, 0),
+            ifnull(s.COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE, 0),
+            ifnull(s.COUNT_TRANSACTIONS_REMOTE_APPLIED, 0),
+            ifnull(s.COUNT_TRANSACTIONS_LOCAL_PROPOSED, 0),
+            ifnull(s.COUNT_TRANSACTIONS_LOCAL_ROLLBACK, 0)
+        FROM performance_schema.replication_group_members m
+        LEFT JOIN performance_schema.replication_group_member_stats s USING (MEMBER_ID)
+    `
+	cloneStatusQuery = `
+        SELECT
+            ifnull(STATE, ''),
+            ifnull(SOURCE, ''),
+            ifnull(ERROR_NO, 0),
+            ifnull(TIMESTAMPDIFF(MICROSECOND, BEGIN_TIME, ifnull(END_TIME, NOW(3))), 0)
+        FROM performance_schema.clone_status
+    `
+	cloneProgressQuery = `
+        SELECT
+            STAGE,
+            ifnull(STATE, ''),
+            ifnull(ESTIMATE, 0),
+            ifnull(DATA, 0),
+            ifnull(NETWORK, 0),
+            ifnull(DATA_SPEED, 0),
+            ifnull(NETWORK_SPEED, 0),
+            ifnull(TIMESTAMPDIFF(MICROSECOND, BEGIN_TIME, ifnull(END_TIME, NOW(3))), 0)
+        FROM performance_schema.clone_progress
+    `
+	innoDBIOLatencyQuery = `
+        SELECT
+            EVENT_NAME,
+            COUNT_READ, SUM_TIMER_READ, MIN_TIMER_READ, AVG_TIMER_READ, MAX_TIMER_READ,
+            COUNT_WRITE, SUM_TIMER_WRITE, MIN_TIMER_WRITE, AVG_TIMER_WRITE, MAX_TIMER_WRITE,
+            COUNT_MISC, SUM_TIMER_MISC, MIN_TIMER_MISC, AVG_TIMER_MISC, MAX_TIMER_MISC
+        FROM performance_schema.file_summary_by_event_name
+        WHERE EVENT_NAME LIKE 'wait/io/file/innodb/%'
+    `
 )
 
 func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) error {
@@ -542,6 +592,27 @@ func (m *Mysql) gatherServer(server *config.Secret, acc telegraf.Accumulator) er
 			return err
 		}
 	}
+
+	if m.GatherGroupReplication {
+		err = m.gatherGroupReplication(db, servtag, acc)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.GatherCloneStatus {
+		err = m.gatherCloneStatus(db, servtag, acc)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.GatherInnoDBIOLatency {
+		err = m.gatherInnoDBIOLatency(db, servtag, acc)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1926,6 +1997,211 @@ func findThreadState(rawCommand, rawState string) string {
 }
 
 // newNamespace can be used to make a namespace
+// gatherGroupReplication can be used to collect the state and the
+// certification and applier queues of the group replication members
+func (m *Mysql) gatherGroupReplication(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
+	// the tables only exist if the group replication plugin is installed
+	var tableName string
+	err := db.QueryRow(perfSchemaTablesQuery, "replication_group_member_stats").Scan(&tableName)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	rows, err := db.Query(groupReplicationQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		memberID, memberHost, memberPort string
+		memberState, memberRole          string
+		inQueue, checked, conflicts      int64
+		rowsValidating, applierQueue     int64
+		remoteApplied                    int64
+		localProposed, localRollback     int64
+	)
+
+	for rows.Next() {
+		err = rows.Scan(
+			&memberID, &memberHost, &memberPort, &memberState, &memberRole,
+			&inQueue, &checked, &conflicts, &rowsValidating, &applierQueue,
+			&remoteApplied, &localProposed, &localRollback,
+		)
+		if err != nil {
+			return err
+		}
+
+		// members without ID are reported if group replication is stopped
+		if memberID == "" {
+			continue
+		}
+
+		tags := map[string]string{
+			"server":       servtag,
+			"member_id":    memberID,
+			"member_host":  memberHost,
+			"member_port":  memberPort,
+			"member_state": memberState,
+			"member_role":  memberRole,
+		}
+
+		var online int64
+		if memberState == "ONLINE" {
+			online = 1
+		}
+
+		fields := map[string]interface{}{
+			"online":                               online,
+			"transactions_in_queue":                inQueue,
+			"transactions_checked":                 checked,
+			"conflicts_detected":                   conflicts,
+			"transactions_rows_validating":         rowsValidating,
+			"transactions_remote_in_applier_queue": applierQueue,
+			"transactions_remote_applied":          remoteApplied,
+			"transactions_local_proposed":          localProposed,
+			"transactions_local_rollback":          localRollback,
+		}
+
+		acc.AddFields("mysql_group_replication", fields, tags)
+	}
+	return rows.Err()
+}
+
+// gatherCloneStatus can be used to collect the state and progress of the
+// latest clone operation of the clone plugin
+func (m *Mysql) gatherCloneStatus(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
+	// the tables only exist if the clone plugin is installed
+	var tableName string
+	err := db.QueryRow(perfSchemaTablesQuery, "clone_status").Scan(&tableName)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	rows, err := db.Query(cloneStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		state, source    string
+		errorNo          int64
+		durationMicrosec float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&state, &source, &errorNo, &durationMicrosec); err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"server": servtag,
+			"state":  state,
+			"source": source,
+		}
+		fields := map[string]interface{}{
+			"error_no":         errorNo,
+			"duration_seconds": durationMicrosec / 1e6,
+		}
+		acc.AddFields("mysql_clone_status", fields, tags)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = db.Query(cloneProgressQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		stage                     string
+		estimate, data, network   float64
+		dataSpeed, networkSpeed   float64
+		stageDurationMicroseconds float64
+	)
+	for rows.Next() {
+		err = rows.Scan(
+			&stage, &state, &estimate, &data, &network,
+			&dataSpeed, &networkSpeed, &stageDurationMicroseconds,
+		)
+		if err != nil {
+			return err
+		}
+
+		tags := map[string]string{
+			"server": servtag,
+			"stage":  stage,
+			"state":  state,
+		}
+		fields := map[string]interface{}{
+			"estimate_bytes":      estimate,
+			"data_bytes":          data,
+			"network_bytes":       network,
+			"data_speed_bytes":    dataSpeed,
+			"network_speed_bytes": networkSpeed,
+			"duration_seconds":    stageDurationMicroseconds / 1e6,
+		}
+		if estimate > 0 {
+			fields["progress_percent"] = data / estimate * 100
+		}
+		acc.AddFields("mysql_clone_progress", fields, tags)
+	}
+	return rows.Err()
+}
+
+// gatherInnoDBIOLatency can be used to collect the latency of the InnoDB
+// file operations such as redo log writes and fsyncs
+func (m *Mysql) gatherInnoDBIOLatency(db *sql.DB, servtag string, acc telegraf.Accumulator) error {
+	rows, err := db.Query(innoDBIOLatencyQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		eventName string
+		stats     [3][5]float64
+	)
+	modes := []string{"read", "write", "misc"}
+
+	for rows.Next() {
+		err = rows.Scan(
+			&eventName,
+			&stats[0][0], &stats[0][1], &stats[0][2], &stats[0][3], &stats[0][4],
+			&stats[1][0], &stats[1][1], &stats[1][2], &stats[1][3], &stats[1][4],
+			&stats[2][0], &stats[2][1], &stats[2][2], &stats[2][3], &stats[2][4],
+		)
+		if err != nil {
+			return err
+		}
+
+		for i, mode := range modes {
+			tags := map[string]string{
+				"server":     servtag,
+				"event_name": strings.TrimPrefix(eventName, "wait/io/file/innodb/"),
+				"mode":       mode,
+			}
+			fields := map[string]interface{}{
+				"events_total":         stats[i][0],
+				"events_seconds_total": stats[i][1] / picoSeconds,
+				"min_seconds":          stats[i][2] / picoSeconds,
+				"avg_seconds":          stats[i][3] / picoSeconds,
+				"max_seconds":          stats[i][4] / picoSeconds,
+			}
+			acc.AddFields("mysql_innodb_io_latency", fields, tags)
+		}
+	}
+	return rows.Err()
+}
+
 func newNamespace(words ...string) string {
 	return strings.ReplaceAll(strings.Join(words, "_"), " ", "_")
 }
@@ -10,7 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -380,3 +382,225 @@ func TestNewNamespace(t *testing.T) {
 		}
 	}
 }
+
+func TestGatherGroupReplication(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := Mysql{Log: testutil.Logger{}}
+
+	mock.ExpectQuery(perfSchemaTablesQuery).
+		WithArgs("replication_group_member_stats").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("replication_group_member_stats"))
+	mock.ExpectQuery(groupReplicationQuery).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE", "MEMBER_ROLE",
+			"COUNT_TRANSACTIONS_IN_QUEUE", "COUNT_TRANSACTIONS_CHECKED", "COUNT_CONFLICTS_DETECTED",
+			"COUNT_TRANSACTIONS_ROWS_VALIDATING", "COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE",
+			"COUNT_TRANSACTIONS_REMOTE_APPLIED", "COUNT_TRANSACTIONS_LOCAL_PROPOSED", "COUNT_TRANSACTIONS_LOCAL_ROLLBACK",
+		}).
+			AddRow("a1", "db1", "3306", "ONLINE", "PRIMARY", 0, 100, 1, 5, 0, 20, 80, 2).
+			AddRow("b2", "db2", "3306", "RECOVERING", "SECONDARY", 3, 90, 0, 4, 12, 70, 0, 0).
+			AddRow("", "", "", "OFFLINE", "", 0, 0, 0, 0, 0, 0, 0, 0),
+	)
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherGroupReplication(db, "127.0.0.1:3306", &acc))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mysql_group_replication",
+			map[string]string{
+				"server":       "127.0.0.1:3306",
+				"member_id":    "a1",
+				"member_host":  "db1",
+				"member_port":  "3306",
+				"member_state": "ONLINE",
+				"member_role":  "PRIMARY",
+			},
+			map[string]interface{}{
+				"online":                               int64(1),
+				"transactions_in_queue":                int64(0),
+				"transactions_checked":                 int64(100),
+				"conflicts_detected":                   int64(1),
+				"transactions_rows_validating":         int64(5),
+				"transactions_remote_in_applier_queue": int64(0),
+				"transactions_remote_applied":          int64(20),
+				"transactions_local_proposed":          int64(80),
+				"transactions_local_rollback":          int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mysql_group_replication",
+			map[string]string{
+				"server":       "127.0.0.1:3306",
+				"member_id":    "b2",
+				"member_host":  "db2",
+				"member_port":  "3306",
+				"member_state": "RECOVERING",
+				"member_role":  "SECONDARY",
+			},
+			map[string]interface{}{
+				"online":                               int64(0),
+				"transactions_in_queue":                int64(3),
+				"transactions_checked":                 int64(90),
+				"conflicts_detected":                   int64(0),
+				"transactions_rows_validating":         int64(4),
+				"transactions_remote_in_applier_queue": int64(12),
+				"transactions_remote_applied":          int64(70),
+				"transactions_local_proposed":          int64(0),
+				"transactions_local_rollback":          int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherGroupReplicationNotInstalled(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := Mysql{Log: testutil.Logger{}}
+
+	mock.ExpectQuery(perfSchemaTablesQuery).
+		WithArgs("replication_group_member_stats").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherGroupReplication(db, "127.0.0.1:3306", &acc))
+	require.NoError(t, mock.ExpectationsWereMet())
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestGatherCloneStatus(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := Mysql{Log: testutil.Logger{}}
+
+	mock.ExpectQuery(perfSchemaTablesQuery).
+		WithArgs("clone_status").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("clone_status"))
+	mock.ExpectQuery(cloneStatusQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"STATE", "SOURCE", "ERROR_NO", "DURATION"}).
+			AddRow("In Progress", "donor:3306", 0, 90000000),
+	)
+	mock.ExpectQuery(cloneProgressQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"STAGE", "STATE", "ESTIMATE", "DATA", "NETWORK", "DATA_SPEED", "NETWORK_SPEED", "DURATION"}).
+			AddRow("DROP DATA", "Completed", 0, 0, 0, 0, 0, 500000).
+			AddRow("FILE COPY", "In Progress", 1000, 250, 260, 10, 11, 89500000),
+	)
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherCloneStatus(db, "127.0.0.1:3306", &acc))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mysql_clone_status",
+			map[string]string{"server": "127.0.0.1:3306", "state": "In Progress", "source": "donor:3306"},
+			map[string]interface{}{"error_no": int64(0), "duration_seconds": float64(90)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mysql_clone_progress",
+			map[string]string{"server": "127.0.0.1:3306", "stage": "DROP DATA", "state": "Completed"},
+			map[string]interface{}{
+				"estimate_bytes":      float64(0),
+				"data_bytes":          float64(0),
+				"network_bytes":       float64(0),
+				"data_speed_bytes":    float64(0),
+				"network_speed_bytes": float64(0),
+				"duration_seconds":    float64(0.5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mysql_clone_progress",
+			map[string]string{"server": "127.0.0.1:3306", "stage": "FILE COPY", "state": "In Progress"},
+			map[string]interface{}{
+				"estimate_bytes":      float64(1000),
+				"data_bytes":          float64(250),
+				"network_bytes":       float64(260),
+				"data_speed_bytes":    float64(10),
+				"network_speed_bytes": float64(11),
+				"duration_seconds":    float64(89.5),
+				"progress_percent":    float64(25),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherInnoDBIOLatency(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := Mysql{Log: testutil.Logger{}}
+
+	mock.ExpectQuery(innoDBIOLatencyQuery).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"EVENT_NAME",
+			"COUNT_READ", "SUM_TIMER_READ", "MIN_TIMER_READ", "AVG_TIMER_READ", "MAX_TIMER_READ",
+			"COUNT_WRITE", "SUM_TIMER_WRITE", "MIN_TIMER_WRITE", "AVG_TIMER_WRITE", "MAX_TIMER_WRITE",
+			"COUNT_MISC", "SUM_TIMER_MISC", "MIN_TIMER_MISC", "AVG_TIMER_MISC", "MAX_TIMER_MISC",
+		}).AddRow(
+			"wait/io/file/innodb/innodb_log_file",
+			0, 0, 0, 0, 0,
+			4, 4e9, 5e8, 1e9, 2e9,
+			2, 6e9, 1e9, 3e9, 5e9,
+		),
+	)
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.gatherInnoDBIOLatency(db, "127.0.0.1:3306", &acc))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"mysql_innodb_io_latency",
+			map[string]string{"server": "127.0.0.1:3306", "event_name": "innodb_log_file", "mode": "read"},
+			map[string]interface{}{
+				"events_total":         float64(0),
+				"events_seconds_total": float64(0),
+				"min_seconds":          float64(0),
+				"avg_seconds":          float64(0),
+				"max_seconds":          float64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mysql_innodb_io_latency",
+			map[string]string{"server": "127.0.0.1:3306", "event_name": "innodb_log_file", "mode": "write"},
+			map[string]interface{}{
+				"events_total":         float64(4),
+				"events_seconds_total": float64(0.004),
+				"min_seconds":          float64(0.0005),
+				"avg_seconds":          float64(0.001),
+				"max_seconds":          float64(0.002),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mysql_innodb_io_latency",
+			map[string]string{"server": "127.0.0.1:3306", "event_name": "innodb_log_file", "mode": "misc"},
+			map[string]interface{}{
+				"events_total":         float64(2),
+				"events_seconds_total": float64(0.006),
+				"min_seconds":          float64(0.001),
+				"avg_seconds":          float64(0.003),
+				"max_seconds":          float64(0.005),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
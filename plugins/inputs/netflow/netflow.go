@@ -25,12 +25,15 @@ type protocolDecoder interface {
 }
 
 type NetFlow struct {
-	ServiceAddress string          `toml:"service_address"`
-	ReadBufferSize config.Size     `toml:"read_buffer_size"`
-	Protocol       string          `toml:"protocol"`
-	DumpPackets    bool            `toml:"dump_packets"`
-	PENFiles       []string        `toml:"private_enterprise_number_files"`
-	Log            telegraf.Logger `toml:"-"`
+	ServiceAddress     string          `toml:"service_address"`
+	ReadBufferSize     config.Size     `toml:"read_buffer_size"`
+	Protocol           string          `toml:"protocol"`
+	DumpPackets        bool            `toml:"dump_packets"`
+	PENFiles           []string        `toml:"private_enterprise_number_files"`
+	IncludeOptions     bool            `toml:"include_options"`
+	SamplingCorrection bool            `toml:"sampling_correction"`
+	TagFields          []string        `toml:"tag_fields"`
+	Log                telegraf.Logger `toml:"-"`
 
 	conn    *net.UDPConn
 	decoder protocolDecoder
@@ -61,19 +64,26 @@ func (n *NetFlow) Init() error {
 			n.Log.Warn("'private_enterprise_number_files' option will be ignored in 'netflow v9'")
 		}
 		n.decoder = &netflowDecoder{
-			Log: n.Log,
+			IncludeOptions:     n.IncludeOptions,
+			SamplingCorrection: n.SamplingCorrection,
+			Log:                n.Log,
 		}
 	case "", "ipfix":
 		n.decoder = &netflowDecoder{
-			PENFiles: n.PENFiles,
-			Log:      n.Log,
+			PENFiles:           n.PENFiles,
+			IncludeOptions:     n.IncludeOptions,
+			SamplingCorrection: n.SamplingCorrection,
+			Log:                n.Log,
 		}
 	case "netflow v5":
 		if len(n.PENFiles) != 0 {
 			n.Log.Warn("'private_enterprise_number_files' option will be ignored in 'netflow v5'")
 		}
-		n.decoder = &netflowv5Decoder{}
+		n.decoder = &netflowv5Decoder{SamplingCorrection: n.SamplingCorrection}
 	case "sflow", "sflow v5":
+		if n.SamplingCorrection {
+			n.Log.Warn("'sampling_correction' option will be ignored in 'sflow v5'")
+		}
 		n.decoder = &sflowv5Decoder{Log: n.Log}
 	default:
 		return fmt.Errorf("invalid protocol %q, only supports 'sflow', 'netflow v5', 'netflow v9' and 'ipfix'", n.Protocol)
@@ -145,11 +155,24 @@ func (n *NetFlow) read(acc telegraf.Accumulator) {
 			continue
 		}
 		for _, m := range metrics {
+			n.convertTagFields(m)
 			acc.AddMetric(m)
 		}
 	}
 }
 
+// convertTagFields turns the fields specified by the user into tags
+func (n *NetFlow) convertTagFields(m telegraf.Metric) {
+	for _, name := range n.TagFields {
+		v, found := m.GetField(name)
+		if !found {
+			continue
+		}
+		m.AddTag(name, fmt.Sprintf("%v", v))
+		m.RemoveField(name)
+	}
+}
+
 func (n *NetFlow) Gather(_ telegraf.Accumulator) error {
 	return nil
 }
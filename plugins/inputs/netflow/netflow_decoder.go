@@ -171,6 +171,16 @@ var fieldMappingsNetflowV9 = map[uint16][]fieldMapping{
 	40000: {{"username", decodeString}},  // NF_F_USERNAME
 }
 
+// Field mappings for the scope fields of Netflow version 9 option data records
+// From documentation at https://www.rfc-editor.org/rfc/rfc3954#section-6.1
+var fieldMappingsNetflowV9Scope = map[uint16]fieldMapping{
+	1: {"scope_system", decodeHex},     // System
+	2: {"scope_interface", decodeUint}, // Interface
+	3: {"scope_linecard", decodeUint},  // Line Card
+	4: {"scope_cache", decodeHex},      // NetFlow Cache
+	5: {"scope_template", decodeUint},  // Template
+}
+
 // Default field mappings specific to Netflow version 9
 // From documentation at
 // https://www.iana.org/assignments/ipfix/ipfix.xhtml#ipfix-information-elements
@@ -530,10 +540,13 @@ var fieldMappingsIPFIX = map[uint16][]fieldMapping{
 
 // Decoder structure
 type netflowDecoder struct {
-	PENFiles []string
-	Log      telegraf.Logger
+	PENFiles           []string
+	IncludeOptions     bool
+	SamplingCorrection bool
+	Log                telegraf.Logger
 
 	templates     map[string]*netflow.BasicTemplateSystem
+	samplers      map[string]map[uint64]uint64
 	mappingsV9    map[uint16]fieldMapping
 	mappingsIPFIX map[uint16]fieldMapping
 	mappingsPEN   map[string]fieldMapping
@@ -576,6 +589,37 @@ func (d *netflowDecoder) Decode(srcIP net.IP, payload []byte) ([]telegraf.Metric
 			case netflow.TemplateFlowSet:
 			case netflow.NFv9OptionsTemplateFlowSet:
 			case netflow.OptionsDataFlowSet:
+				for _, record := range fs.Records {
+					fields := make(map[string]interface{})
+					for _, value := range record.ScopesValues {
+						decodedFields, err := d.decodeScopeV9(value)
+						if err != nil {
+							d.Log.Errorf("decoding option scope %+v failed: %v", value, err)
+							continue
+						}
+						for _, field := range decodedFields {
+							fields[field.Key] = field.Value
+						}
+					}
+					for _, value := range record.OptionsValues {
+						decodedFields, err := d.decodeValueV9(value)
+						if err != nil {
+							d.Log.Errorf("decoding option record %+v failed: %v", record, err)
+							continue
+						}
+						for _, field := range decodedFields {
+							fields[field.Key] = field.Value
+						}
+					}
+					d.updateSampler(src, fields)
+					if d.IncludeOptions {
+						tags := map[string]string{
+							"source":  src,
+							"version": "NetFlowV9",
+						}
+						metrics = append(metrics, metric.New("netflow_options", tags, fields, t))
+					}
+				}
 			case netflow.DataFlowSet:
 				for _, record := range fs.Records {
 					tags := map[string]string{
@@ -593,6 +637,9 @@ func (d *netflowDecoder) Decode(srcIP net.IP, payload []byte) ([]telegraf.Metric
 							fields[field.Key] = field.Value
 						}
 					}
+					if d.SamplingCorrection {
+						d.correctSampling(src, fields)
+					}
 					metrics = append(metrics, metric.New("netflow", tags, fields, t))
 				}
 			}
@@ -603,6 +650,31 @@ func (d *netflowDecoder) Decode(srcIP net.IP, payload []byte) ([]telegraf.Metric
 			case netflow.TemplateFlowSet:
 			case netflow.IPFIXOptionsTemplateFlowSet:
 			case netflow.OptionsDataFlowSet:
+				for _, record := range fs.Records {
+					fields := make(map[string]interface{})
+					// IPFIX scopes are regular information elements
+					values := make([]netflow.DataField, 0, len(record.ScopesValues)+len(record.OptionsValues))
+					values = append(values, record.ScopesValues...)
+					values = append(values, record.OptionsValues...)
+					for _, value := range values {
+						decodedFields, err := d.decodeValueIPFIX(value)
+						if err != nil {
+							d.Log.Errorf("decoding option value %+v failed: %v", value, err)
+							continue
+						}
+						for _, field := range decodedFields {
+							fields[field.Key] = field.Value
+						}
+					}
+					d.updateSampler(src, fields)
+					if d.IncludeOptions {
+						tags := map[string]string{
+							"source":  src,
+							"version": "IPFIX",
+						}
+						metrics = append(metrics, metric.New("netflow_options", tags, fields, t))
+					}
+				}
 			case netflow.DataFlowSet:
 				for _, record := range fs.Records {
 					tags := map[string]string{
@@ -621,6 +693,9 @@ func (d *netflowDecoder) Decode(srcIP net.IP, payload []byte) ([]telegraf.Metric
 							fields[field.Key] = field.Value
 						}
 					}
+					if d.SamplingCorrection {
+						d.correctSampling(src, fields)
+					}
 					metrics = append(metrics, metric.New("netflow", tags, fields, t))
 				}
 			}
@@ -641,6 +716,7 @@ func (d *netflowDecoder) Init() error {
 	}
 
 	d.templates = make(map[string]*netflow.BasicTemplateSystem)
+	d.samplers = make(map[string]map[uint64]uint64)
 	d.mappingsV9 = make(map[uint16]fieldMapping)
 	d.mappingsIPFIX = make(map[uint16]fieldMapping)
 	d.mappingsPEN = make(map[string]fieldMapping)
@@ -798,3 +874,96 @@ func (d *netflowDecoder) decodeValueIPFIX(field netflow.DataField) ([]telegraf.F
 	}
 	return []telegraf.Field{{Key: key, Value: v}}, nil
 }
+
+func (d *netflowDecoder) decodeScopeV9(field netflow.DataField) ([]telegraf.Field, error) {
+	raw := field.Value.([]byte)
+
+	if m, found := fieldMappingsNetflowV9Scope[field.Type]; found {
+		v, err := m.decoder(raw)
+		if err != nil {
+			return nil, err
+		}
+		return []telegraf.Field{{Key: m.name, Value: v}}, nil
+	}
+
+	// Return the raw data if no mapping was found
+	v, err := decodeHex(raw)
+	if err != nil {
+		return nil, err
+	}
+	return []telegraf.Field{{Key: fmt.Sprintf("scope_%d", field.Type), Value: v}}, nil
+}
+
+// updateSampler remembers the sampling rate announced in an option data record
+// of the given source. The rate is stored for the sampler or selector ID of the
+// record or as the default for the source if no ID is given.
+func (d *netflowDecoder) updateSampler(src string, fields map[string]interface{}) {
+	rate, found := samplingRate(fields)
+	if !found {
+		return
+	}
+	id := samplerID(fields)
+
+	d.Lock()
+	defer d.Unlock()
+	if _, ok := d.samplers[src]; !ok {
+		d.samplers[src] = make(map[uint64]uint64)
+	}
+	d.samplers[src][id] = rate
+}
+
+// correctSampling scales the byte and packet counters of a flow record by the
+// sampling rate. The rate is taken from the record itself if present and
+// otherwise from the sampler state announced via option data records.
+func (d *netflowDecoder) correctSampling(src string, fields map[string]interface{}) {
+	rate, found := samplingRate(fields)
+	if !found {
+		id := samplerID(fields)
+		d.Lock()
+		rate, found = d.samplers[src][id]
+		if !found {
+			rate, found = d.samplers[src][0]
+		}
+		d.Unlock()
+	}
+	if !found || rate <= 1 {
+		return
+	}
+
+	for _, prefix := range []string{"", "rev_"} {
+		for _, name := range []string{"in_bytes", "in_packets", "out_bytes", "out_packets"} {
+			if v, ok := fields[prefix+name].(uint64); ok {
+				fields[prefix+name] = v * rate
+			}
+		}
+	}
+}
+
+func samplingRate(fields map[string]interface{}) (uint64, bool) {
+	for _, name := range []string{"sampling_interval", "flow_sampler_interval"} {
+		if v, ok := fields[name].(uint64); ok && v > 0 {
+			return v, true
+		}
+	}
+
+	// Systematic count-based sampling according to RFC 5477 selects
+	// <interval> packets and skips the following <space> packets.
+	interval, ok := fields["sampling_packet_interval"].(uint64)
+	if !ok || interval == 0 {
+		return 0, false
+	}
+	space, ok := fields["sampling_packet_space"].(uint64)
+	if !ok {
+		return 0, false
+	}
+	return (interval + space) / interval, true
+}
+
+func samplerID(fields map[string]interface{}) uint64 {
+	for _, name := range []string{"flow_sampler_id", "selector_id"} {
+		if v, ok := fields[name].(uint64); ok {
+			return v
+		}
+	}
+	return 0
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -169,6 +170,64 @@ func TestWrongMapping(t *testing.T) {
 	require.ErrorContains(t, plugin.Init(), "does not match pattern")
 }
 
+func TestPENMappingFiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("mappings_ipfix_pen", "*.csv"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, fn := range files {
+		t.Run(filepath.Base(fn), func(t *testing.T) {
+			// The file name is suffixed with the PEN of the contained elements
+			name := strings.TrimSuffix(filepath.Base(fn), ".csv")
+			pen := name[strings.LastIndex(name, "-")+1:]
+
+			mappings, err := loadMapping(fn)
+			require.NoError(t, err)
+			require.NotEmpty(t, mappings)
+			for id := range mappings {
+				require.Truef(t, strings.HasPrefix(id, pen+"."), "element %q does not belong to PEN %s", id, pen)
+			}
+
+			plugin := &NetFlow{
+				ServiceAddress: "udp://127.0.0.1:0",
+				Protocol:       "ipfix",
+				PENFiles:       []string{fn},
+				Log:            testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+		})
+	}
+}
+
+func TestTagFields(t *testing.T) {
+	plugin := &NetFlow{
+		TagFields: []string{"in_snmp", "out_snmp", "vlan_src"},
+	}
+	m := metric.New(
+		"netflow",
+		map[string]string{"source": "127.0.0.1"},
+		map[string]interface{}{
+			"in_snmp":  uint64(1),
+			"out_snmp": uint64(2),
+			"in_bytes": uint64(100),
+		},
+		time.Unix(0, 0),
+	)
+	plugin.convertTagFields(m)
+
+	expected := metric.New(
+		"netflow",
+		map[string]string{
+			"source":   "127.0.0.1",
+			"in_snmp":  "1",
+			"out_snmp": "2",
+		},
+		map[string]interface{}{"in_bytes": uint64(100)},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+}
+
 func TestCases(t *testing.T) {
 	// Get all directories in testdata
 	folders, err := os.ReadDir("testcases")
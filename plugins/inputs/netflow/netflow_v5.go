@@ -13,7 +13,9 @@ import (
 )
 
 // Decoder structure
-type netflowv5Decoder struct{}
+type netflowv5Decoder struct {
+	SamplingCorrection bool
+}
 
 func (d *netflowv5Decoder) Init() error {
 	if err := initL4ProtoMapping(); err != nil {
@@ -89,6 +91,13 @@ func (d *netflowv5Decoder) Decode(srcIP net.IP, payload []byte) ([]telegraf.Metr
 			return nil, fmt.Errorf("decoding 'src_tos' failed: %w", err)
 		}
 
+		// The lower 14 bits of the sampling field contain the sampling interval,
+		// the upper two bits denote the sampling mode.
+		if interval := uint64(msg.SamplingInterval & 0x3fff); d.SamplingCorrection && interval > 1 {
+			fields["in_packets"] = uint64(record.DPkts) * interval
+			fields["in_bytes"] = uint64(record.DOctets) * interval
+		}
+
 		metrics = append(metrics, metric.New("netflow", tags, fields, t))
 	}
 
@@ -0,0 +1,158 @@
+package network_probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+type iperf3Prober struct {
+	binary    string
+	address   string
+	duration  time.Duration
+	transport string
+	bandwidth string
+	parallel  int
+
+	run func(ctx context.Context, binary string, args ...string) ([]byte, error)
+}
+
+// iperf3Report is the relevant subset of the "iperf3 --json" output
+type iperf3Report struct {
+	End struct {
+		Streams []struct {
+			Sender struct {
+				MinRTT  int64 `json:"min_rtt"`
+				MeanRTT int64 `json:"mean_rtt"`
+				MaxRTT  int64 `json:"max_rtt"`
+			} `json:"sender"`
+		} `json:"streams"`
+		SumSent struct {
+			Bytes         int64   `json:"bytes"`
+			BitsPerSecond float64 `json:"bits_per_second"`
+			Retransmits   int64   `json:"retransmits"`
+		} `json:"sum_sent"`
+		SumReceived struct {
+			Bytes         int64   `json:"bytes"`
+			BitsPerSecond float64 `json:"bits_per_second"`
+		} `json:"sum_received"`
+		// Summary of UDP tests as seen by the receiver
+		Sum struct {
+			Bytes         int64   `json:"bytes"`
+			BitsPerSecond float64 `json:"bits_per_second"`
+			JitterMs      float64 `json:"jitter_ms"`
+			LostPackets   int64   `json:"lost_packets"`
+			Packets       int64   `json:"packets"`
+			LostPercent   float64 `json:"lost_percent"`
+		} `json:"sum"`
+	} `json:"end"`
+	Error string `json:"error"`
+}
+
+func (p *iperf3Prober) probe(ctx context.Context, direction string) (map[string]interface{}, string, error) {
+	args, err := p.args(direction)
+	if err != nil {
+		return nil, p.address, err
+	}
+
+	// iperf3 reports failures as JSON document with a non-zero exit code, so
+	// try to decode the output before checking the error.
+	out, runErr := p.run(ctx, p.binary, args...)
+	var report iperf3Report
+	if err := json.Unmarshal(out, &report); err != nil {
+		if runErr != nil {
+			return nil, p.address, runErr
+		}
+		return nil, p.address, fmt.Errorf("decoding iperf3 output failed: %w", err)
+	}
+	if report.Error != "" {
+		return nil, p.address, errors.New(report.Error)
+	}
+	if runErr != nil {
+		return nil, p.address, runErr
+	}
+
+	if p.transport == "udp" {
+		sum := report.End.Sum
+		return map[string]interface{}{
+			"bytes":           sum.Bytes,
+			"bits_per_second": sum.BitsPerSecond,
+			"jitter_ms":       sum.JitterMs,
+			"lost_packets":    sum.LostPackets,
+			"packets":         sum.Packets,
+			"loss_percent":    sum.LostPercent,
+		}, p.address, nil
+	}
+
+	fields := map[string]interface{}{
+		"bytes":           report.End.SumReceived.Bytes,
+		"bits_per_second": report.End.SumReceived.BitsPerSecond,
+		"retransmits":     report.End.SumSent.Retransmits,
+	}
+
+	// The round-trip times are measured by the sending side while the link
+	// is loaded. They are only available if the sender runs on Linux.
+	var minRTT, maxRTT, sumRTT, n int64
+	minRTT = math.MaxInt64
+	for _, s := range report.End.Streams {
+		if s.Sender.MeanRTT <= 0 {
+			continue
+		}
+		minRTT = min(minRTT, s.Sender.MinRTT)
+		maxRTT = max(maxRTT, s.Sender.MaxRTT)
+		sumRTT += s.Sender.MeanRTT
+		n++
+	}
+	if n > 0 {
+		fields["rtt_min_ms"] = float64(minRTT) / 1000.0
+		fields["rtt_mean_ms"] = float64(sumRTT) / float64(n) / 1000.0
+		fields["rtt_max_ms"] = float64(maxRTT) / 1000.0
+	}
+
+	return fields, p.address, nil
+}
+
+func (p *iperf3Prober) args(direction string) ([]string, error) {
+	host, port, err := net.SplitHostPort(p.address)
+	if err != nil {
+		// Address without port
+		host, port = p.address, ""
+	}
+
+	// Use the short options as their long forms differ between iperf3 versions
+	args := []string{"-c", host, "-J", "-t", strconv.Itoa(int(math.Ceil(p.duration.Seconds())))}
+	if port != "" {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return nil, fmt.Errorf("invalid port in address %q", p.address)
+		}
+		args = append(args, "-p", port)
+	}
+	if direction == directionDownload {
+		args = append(args, "-R")
+	}
+	if p.transport == "udp" {
+		args = append(args, "-u")
+	}
+	if p.bandwidth != "" {
+		args = append(args, "-b", p.bandwidth)
+	}
+	if p.parallel > 1 {
+		args = append(args, "-P", strconv.Itoa(p.parallel))
+	}
+	return args, nil
+}
+
+func runCommand(ctx context.Context, binary string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	return stdout.Bytes(), err
+}
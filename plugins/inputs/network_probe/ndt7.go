@@ -0,0 +1,289 @@
+package network_probe
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// See https://github.com/m-lab/ndt-server/blob/main/spec/ndt7-protocol.md
+const (
+	ndt7Subprotocol     = "net.measurementlab.ndt.v7"
+	ndt7DownloadPath    = "/ndt/v7/download"
+	ndt7UploadPath      = "/ndt/v7/upload"
+	ndt7MaxMessageSize  = 1 << 24
+	ndt7MinMessageSize  = 1 << 13
+	ndt7ScalingFraction = 16
+	ndt7CloseTimeout    = time.Second
+
+	defaultLocateURL = "https://locate.measurementlab.net/v2/nearest/ndt/ndt7"
+)
+
+type ndt7Prober struct {
+	address   string
+	locateURL string
+	duration  time.Duration
+	tlsConfig *tls.Config
+}
+
+// ndt7Measurement is the relevant subset of the measurements sent by the
+// server during the test
+type ndt7Measurement struct {
+	AppInfo *struct {
+		ElapsedTime int64 `json:"ElapsedTime"`
+		NumBytes    int64 `json:"NumBytes"`
+	} `json:"AppInfo"`
+	TCPInfo *struct {
+		BytesRetrans int64 `json:"BytesRetrans"`
+		BytesSent    int64 `json:"BytesSent"`
+		MinRTT       int64 `json:"MinRTT"`
+		RTT          int64 `json:"RTT"`
+	} `json:"TCPInfo"`
+}
+
+// ndt7Stats accumulates the round-trip times reported by the server
+type ndt7Stats struct {
+	last    ndt7Measurement
+	rttMin  int64
+	rttMax  int64
+	rttSum  int64
+	samples int64
+}
+
+func (s *ndt7Stats) add(m ndt7Measurement) {
+	if m.AppInfo != nil {
+		s.last.AppInfo = m.AppInfo
+	}
+	if m.TCPInfo == nil {
+		return
+	}
+	s.last.TCPInfo = m.TCPInfo
+	if m.TCPInfo.RTT <= 0 {
+		return
+	}
+	if s.samples == 0 || m.TCPInfo.MinRTT < s.rttMin {
+		s.rttMin = m.TCPInfo.MinRTT
+	}
+	s.rttMax = max(s.rttMax, m.TCPInfo.RTT)
+	s.rttSum += m.TCPInfo.RTT
+	s.samples++
+}
+
+func (s *ndt7Stats) addFields(fields map[string]interface{}) {
+	if s.samples > 0 {
+		fields["rtt_min_ms"] = float64(s.rttMin) / 1000.0
+		fields["rtt_mean_ms"] = float64(s.rttSum) / float64(s.samples) / 1000.0
+		fields["rtt_max_ms"] = float64(s.rttMax) / 1000.0
+	}
+}
+
+func (p *ndt7Prober) probe(ctx context.Context, direction string) (map[string]interface{}, string, error) {
+	u, err := p.testURL(ctx, direction)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: p.tlsConfig,
+		Subprotocols:    []string{ndt7Subprotocol},
+	}
+	header := http.Header{}
+	header.Set("User-Agent", internal.ProductToken())
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return nil, u.Host, err
+	}
+	defer conn.Close()
+	conn.SetReadLimit(ndt7MaxMessageSize)
+
+	// Abort any blocking read or write once the context is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	var fields map[string]interface{}
+	if direction == directionDownload {
+		fields, err = p.download(conn)
+	} else {
+		fields, err = p.upload(ctx, conn)
+	}
+	if ctx.Err() != nil {
+		return nil, u.Host, ctx.Err()
+	}
+	return fields, u.Host, err
+}
+
+func (p *ndt7Prober) download(conn *websocket.Conn) (map[string]interface{}, error) {
+	var stats ndt7Stats
+	var received int64
+	start := time.Now()
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil, err
+			}
+			break
+		}
+		if kind != websocket.TextMessage {
+			received += int64(len(data))
+			continue
+		}
+		var m ndt7Measurement
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("decoding measurement failed: %w", err)
+		}
+		stats.add(m)
+	}
+	elapsed := time.Since(start)
+
+	fields := map[string]interface{}{
+		"bytes":           received,
+		"bits_per_second": float64(received) * 8 / elapsed.Seconds(),
+	}
+	stats.addFields(fields)
+	if info := stats.last.TCPInfo; info != nil && info.BytesSent > 0 {
+		fields["retransmit_percent"] = float64(info.BytesRetrans) / float64(info.BytesSent) * 100.0
+	}
+	return fields, nil
+}
+
+func (p *ndt7Prober) upload(ctx context.Context, conn *websocket.Conn) (map[string]interface{}, error) {
+	// Collect the server's measurements while sending. Connection errors end
+	// the collection and are reported by the sending side if premature.
+	var stats ndt7Stats
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			kind, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- nil
+				return
+			}
+			if kind != websocket.TextMessage {
+				continue
+			}
+			var m ndt7Measurement
+			if err := json.Unmarshal(data, &m); err != nil {
+				readErr <- fmt.Errorf("decoding measurement failed: %w", err)
+				return
+			}
+			stats.add(m)
+		}
+	}()
+
+	var sent int64
+	start := time.Now()
+	msg := make([]byte, ndt7MinMessageSize)
+	for time.Since(start) < p.duration && ctx.Err() == nil {
+		if err := conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+			return nil, err
+		}
+		sent += int64(len(msg))
+
+		// Scale the message size with the amount of data sent so far to
+		// keep the per-message overhead low on fast links.
+		if len(msg) < ndt7MaxMessageSize && int64(len(msg)) <= sent/ndt7ScalingFraction {
+			msg = make([]byte, 2*len(msg))
+		}
+	}
+	elapsed := time.Since(start)
+
+	// Close the connection gracefully and give the server some time to send
+	// its final measurement before tearing down the connection.
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	if err := conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(ndt7CloseTimeout)); err != nil {
+		return nil, err
+	}
+	var err error
+	select {
+	case err = <-readErr:
+	case <-time.After(ndt7CloseTimeout):
+		conn.Close()
+		err = <-readErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer the amount of data seen by the server as the client cannot
+	// tell how much data is still buffered in the network stack.
+	fields := map[string]interface{}{
+		"bytes":           sent,
+		"bits_per_second": float64(sent) * 8 / elapsed.Seconds(),
+	}
+	if info := stats.last.AppInfo; info != nil && info.ElapsedTime > 0 {
+		fields["bytes"] = info.NumBytes
+		fields["bits_per_second"] = float64(info.NumBytes) * 8 / (float64(info.ElapsedTime) / 1e6)
+	}
+	stats.addFields(fields)
+	return fields, nil
+}
+
+// testURL returns the URL of the test in the given direction, either derived
+// from the configured address or queried from the locate service.
+func (p *ndt7Prober) testURL(ctx context.Context, direction string) (*url.URL, error) {
+	path := ndt7DownloadPath
+	if direction == directionUpload {
+		path = ndt7UploadPath
+	}
+
+	if p.address != "" {
+		address := p.address
+		if !strings.Contains(address, "://") {
+			address = "wss://" + address
+		}
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, fmt.Errorf("parsing address failed: %w", err)
+		}
+		if u.Scheme != "ws" && u.Scheme != "wss" {
+			return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+		}
+		u.Path = path
+		return u, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.locateURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying locate service failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("querying locate service failed: %s", resp.Status)
+	}
+
+	var result struct {
+		Results []struct {
+			Machine string            `json:"machine"`
+			URLs    map[string]string `json:"urls"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding locate response failed: %w", err)
+	}
+	for _, r := range result.Results {
+		if raw, found := r.URLs["wss://"+path]; found {
+			return url.Parse(raw)
+		}
+	}
+	return nil, errors.New("no ndt7 server found by locate service")
+}
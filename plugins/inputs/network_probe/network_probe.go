@@ -0,0 +1,199 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package network_probe
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type ResultType uint64
+
+const (
+	Success ResultType = 0
+	Timeout ResultType = 1
+	Failed  ResultType = 2
+)
+
+const (
+	directionDownload = "download"
+	directionUpload   = "upload"
+)
+
+type NetworkProbe struct {
+	Timeout      config.Duration `toml:"timeout"`
+	Iperf3Binary string          `toml:"iperf3_binary"`
+	LocateURL    string          `toml:"locate_url"`
+	Targets      []*Target       `toml:"target"`
+	Log          telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+}
+
+type Target struct {
+	Protocol   string            `toml:"protocol"`
+	Address    string            `toml:"address"`
+	Directions []string          `toml:"directions"`
+	Duration   config.Duration   `toml:"duration"`
+	Transport  string            `toml:"transport"`
+	Bandwidth  string            `toml:"bandwidth"`
+	Parallel   int               `toml:"parallel"`
+	Tags       map[string]string `toml:"tags"`
+
+	prober prober
+}
+
+// prober runs a single test in the given direction and returns the
+// measured fields along with the name of the server actually tested
+type prober interface {
+	probe(ctx context.Context, direction string) (fields map[string]interface{}, server string, err error)
+}
+
+func (*NetworkProbe) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *NetworkProbe) Init() error {
+	if len(n.Targets) == 0 {
+		return errors.New("no targets configured")
+	}
+	if n.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	tlsCfg, err := n.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	for i, t := range n.Targets {
+		if len(t.Directions) == 0 {
+			t.Directions = []string{directionDownload, directionUpload}
+		}
+		if err := choice.CheckSlice(t.Directions, []string{directionDownload, directionUpload}); err != nil {
+			return fmt.Errorf("target %d: invalid directions: %w", i+1, err)
+		}
+		if t.Duration <= 0 {
+			t.Duration = config.Duration(10 * time.Second)
+		}
+
+		switch t.Protocol {
+		case "iperf3":
+			if t.Address == "" {
+				return fmt.Errorf("target %d: address required for iperf3", i+1)
+			}
+			if t.Transport == "" {
+				t.Transport = "tcp"
+			}
+			if t.Transport != "tcp" && t.Transport != "udp" {
+				return fmt.Errorf("target %d: invalid transport %q", i+1, t.Transport)
+			}
+			if t.Parallel < 0 {
+				return fmt.Errorf("target %d: parallel must not be negative", i+1)
+			}
+			t.prober = &iperf3Prober{
+				binary:    n.Iperf3Binary,
+				address:   t.Address,
+				duration:  time.Duration(t.Duration),
+				transport: t.Transport,
+				bandwidth: t.Bandwidth,
+				parallel:  t.Parallel,
+				run:       runCommand,
+			}
+		case "ndt7":
+			if t.Transport != "" || t.Bandwidth != "" || t.Parallel != 0 {
+				return fmt.Errorf("target %d: transport, bandwidth and parallel are only supported for iperf3", i+1)
+			}
+			t.prober = &ndt7Prober{
+				address:   t.Address,
+				locateURL: n.LocateURL,
+				duration:  time.Duration(t.Duration),
+				tlsConfig: tlsCfg,
+			}
+		default:
+			return fmt.Errorf("target %d: unknown protocol %q", i+1, t.Protocol)
+		}
+	}
+
+	return nil
+}
+
+// Gather runs the tests of all targets one after another as concurrent tests
+// would compete for the same link and distort the results.
+func (n *NetworkProbe) Gather(acc telegraf.Accumulator) error {
+	for _, t := range n.Targets {
+		for _, direction := range t.Directions {
+			n.probe(acc, t, direction)
+		}
+	}
+	return nil
+}
+
+func (n *NetworkProbe) probe(acc telegraf.Accumulator, t *Target, direction string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(n.Timeout))
+	defer cancel()
+
+	start := time.Now()
+	fields, server, err := t.prober.probe(ctx, direction)
+
+	tags := make(map[string]string, len(t.Tags)+4)
+	for k, v := range t.Tags {
+		tags[k] = v
+	}
+	tags["protocol"] = t.Protocol
+	tags["direction"] = direction
+	if server != "" {
+		tags["server"] = server
+	}
+	if t.Transport != "" {
+		tags["transport"] = t.Transport
+	}
+
+	result := Success
+	if err != nil {
+		result = Failed
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result = Timeout
+		}
+		acc.AddError(fmt.Errorf("%s %s test against %q failed: %w", t.Protocol, direction, server, err))
+		fields = make(map[string]interface{}, 1)
+	}
+
+	setResult(result, fields, tags)
+	acc.AddFields("network_probe", fields, tags, start)
+}
+
+func setResult(result ResultType, fields map[string]interface{}, tags map[string]string) {
+	var tag string
+	switch result {
+	case Success:
+		tag = "success"
+	case Timeout:
+		tag = "timeout"
+	case Failed:
+		tag = "failed"
+	}
+
+	tags["result"] = tag
+	fields["result_code"] = uint64(result)
+}
+
+func init() {
+	inputs.Add("network_probe", func() telegraf.Input {
+		return &NetworkProbe{
+			Timeout:      config.Duration(time.Minute),
+			Iperf3Binary: "iperf3",
+			LocateURL:    defaultLocateURL,
+		}
+	})
+}
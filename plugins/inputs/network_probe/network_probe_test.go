@@ -0,0 +1,445 @@
+package network_probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		targets  []*Target
+		expected string
+	}{
+		{
+			name:     "no targets",
+			expected: "no targets configured",
+		},
+		{
+			name:     "unknown protocol",
+			targets:  []*Target{{Protocol: "ookla"}},
+			expected: `unknown protocol "ookla"`,
+		},
+		{
+			name:     "invalid direction",
+			targets:  []*Target{{Protocol: "ndt7", Directions: []string{"sideways"}}},
+			expected: "invalid directions",
+		},
+		{
+			name:     "iperf3 without address",
+			targets:  []*Target{{Protocol: "iperf3"}},
+			expected: "address required for iperf3",
+		},
+		{
+			name:     "iperf3 invalid transport",
+			targets:  []*Target{{Protocol: "iperf3", Address: "localhost", Transport: "sctp"}},
+			expected: `invalid transport "sctp"`,
+		},
+		{
+			name:     "ndt7 with iperf3 options",
+			targets:  []*Target{{Protocol: "ndt7", Transport: "udp"}},
+			expected: "only supported for iperf3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &NetworkProbe{
+				Timeout: config.Duration(time.Minute),
+				Targets: tt.targets,
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestIperf3TCP(t *testing.T) {
+	output, err := os.ReadFile("testdata/iperf3_tcp.json")
+	require.NoError(t, err)
+
+	plugin := &NetworkProbe{
+		Timeout:      config.Duration(time.Minute),
+		Iperf3Binary: "/usr/bin/iperf3",
+		Targets: []*Target{
+			{
+				Protocol:   "iperf3",
+				Address:    "iperf.example.com:5202",
+				Directions: []string{"download"},
+				Parallel:   2,
+				Tags:       map[string]string{"site": "branch-01"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var calls [][]string
+	plugin.Targets[0].prober.(*iperf3Prober).run = func(_ context.Context, binary string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{binary}, args...))
+		return output, nil
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expectedCalls := [][]string{
+		{"/usr/bin/iperf3", "-c", "iperf.example.com", "-J", "-t", "10", "-p", "5202", "-R", "-P", "2"},
+	}
+	require.Equal(t, expectedCalls, calls)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"network_probe",
+			map[string]string{
+				"site":      "branch-01",
+				"protocol":  "iperf3",
+				"direction": "download",
+				"server":    "iperf.example.com:5202",
+				"transport": "tcp",
+				"result":    "success",
+			},
+			map[string]interface{}{
+				"bytes":           int64(116129792),
+				"bits_per_second": 92514436.2,
+				"retransmits":     int64(16),
+				"rtt_min_ms":      11.872,
+				"rtt_mean_ms":     25.2,
+				"rtt_max_ms":      52.99,
+				"result_code":     uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestIperf3UDP(t *testing.T) {
+	output, err := os.ReadFile("testdata/iperf3_udp.json")
+	require.NoError(t, err)
+
+	plugin := &NetworkProbe{
+		Timeout:      config.Duration(time.Minute),
+		Iperf3Binary: "iperf3",
+		Targets: []*Target{
+			{
+				Protocol:   "iperf3",
+				Address:    "192.0.2.1",
+				Directions: []string{"upload"},
+				Duration:   config.Duration(5 * time.Second),
+				Transport:  "udp",
+				Bandwidth:  "10M",
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var calls [][]string
+	plugin.Targets[0].prober.(*iperf3Prober).run = func(_ context.Context, binary string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{binary}, args...))
+		return output, nil
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expectedCalls := [][]string{
+		{"iperf3", "-c", "192.0.2.1", "-J", "-t", "5", "-u", "-b", "10M"},
+	}
+	require.Equal(t, expectedCalls, calls)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"network_probe",
+			map[string]string{
+				"protocol":  "iperf3",
+				"direction": "upload",
+				"server":    "192.0.2.1",
+				"transport": "udp",
+				"result":    "success",
+			},
+			map[string]interface{}{
+				"bytes":           int64(12500144),
+				"bits_per_second": 10000051.1,
+				"jitter_ms":       0.482,
+				"lost_packets":    int64(43),
+				"packets":         int64(8633),
+				"loss_percent":    0.498,
+				"result_code":     uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestIperf3Error(t *testing.T) {
+	output, err := os.ReadFile("testdata/iperf3_error.json")
+	require.NoError(t, err)
+
+	plugin := &NetworkProbe{
+		Timeout:      config.Duration(time.Minute),
+		Iperf3Binary: "iperf3",
+		Targets: []*Target{
+			{
+				Protocol:   "iperf3",
+				Address:    "iperf.example.com",
+				Directions: []string{"upload"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.Targets[0].prober.(*iperf3Prober).run = func(context.Context, string, ...string) ([]byte, error) {
+		return output, errors.New("exit status 1")
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "unable to connect to server: Connection refused")
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"network_probe",
+			map[string]string{
+				"protocol":  "iperf3",
+				"direction": "upload",
+				"server":    "iperf.example.com",
+				"transport": "tcp",
+				"result":    "failed",
+			},
+			map[string]interface{}{
+				"result_code": uint64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+// ndt7Server implements the server side of the ndt7 protocol sending a fixed
+// amount of data on download and consuming data until the client closes the
+// connection on upload.
+func ndt7Server(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{Subprotocols: []string{ndt7Subprotocol}}
+	measurement := func(numBytes int64, rtt int64) []byte {
+		buf, err := json.Marshal(map[string]interface{}{
+			"AppInfo": map[string]int64{"ElapsedTime": 1000000, "NumBytes": numBytes},
+			"TCPInfo": map[string]int64{
+				"BytesRetrans": 1000,
+				"BytesSent":    100000,
+				"MinRTT":       10000,
+				"RTT":          rtt,
+			},
+		})
+		require.NoError(t, err)
+		return buf
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ndt7DownloadPath, func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		data := make([]byte, 1<<13)
+		for i := 0; i < 10; i++ {
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, measurement(int64(i+1)*int64(len(data)), int64(20000+i*1000))); err != nil {
+				return
+			}
+		}
+		closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	mux.HandleFunc(ndt7UploadPath, func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var received int64
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received += int64(len(data))
+			if err := conn.WriteMessage(websocket.TextMessage, measurement(received, 30000)); err != nil {
+				return
+			}
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestNDT7(t *testing.T) {
+	server := ndt7Server(t)
+	defer server.Close()
+	address := strings.Replace(server.URL, "http://", "ws://", 1)
+
+	plugin := &NetworkProbe{
+		Timeout: config.Duration(10 * time.Second),
+		Targets: []*Target{
+			{
+				Protocol: "ndt7",
+				Address:  address,
+				Duration: config.Duration(100 * time.Millisecond),
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+
+	host := strings.TrimPrefix(address, "ws://")
+	download := testutil.MustMetric(
+		"network_probe",
+		map[string]string{
+			"protocol":  "ndt7",
+			"direction": "download",
+			"server":    host,
+			"result":    "success",
+		},
+		map[string]interface{}{
+			"bytes":              int64(10 * (1 << 13)),
+			"bits_per_second":    0.0,
+			"rtt_min_ms":         10.0,
+			"rtt_mean_ms":        24.5,
+			"rtt_max_ms":         29.0,
+			"retransmit_percent": 1.0,
+			"result_code":        uint64(0),
+		},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricEqual(t, download, metrics[0], testutil.IgnoreTime(), testutil.IgnoreFields("bits_per_second"))
+	require.Greater(t, metrics[0].Fields()["bits_per_second"], 0.0)
+
+	// The upload throughput is derived from the server's last measurement
+	// reporting the received bytes within one second.
+	upload := metrics[1]
+	require.Equal(t, "upload", upload.Tags()["direction"])
+	require.Equal(t, "success", upload.Tags()["result"])
+	received, ok := upload.Fields()["bytes"].(int64)
+	require.True(t, ok)
+	require.Greater(t, received, int64(0))
+	require.InDelta(t, float64(received)*8, upload.Fields()["bits_per_second"], 0.001)
+	require.Equal(t, 30.0, upload.Fields()["rtt_mean_ms"])
+}
+
+func TestNDT7Locate(t *testing.T) {
+	server := ndt7Server(t)
+	defer server.Close()
+	address := strings.Replace(server.URL, "http://", "ws://", 1)
+
+	locate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		response := map[string]interface{}{
+			"results": []map[string]interface{}{
+				{
+					"machine": "mlab1-test01.example.org",
+					"urls": map[string]string{
+						"ws:///ndt/v7/download":  address + ndt7DownloadPath,
+						"wss:///ndt/v7/download": address + ndt7DownloadPath + "?access_token=abc",
+					},
+				},
+			},
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	defer locate.Close()
+
+	plugin := &NetworkProbe{
+		Timeout:   config.Duration(10 * time.Second),
+		LocateURL: locate.URL,
+		Targets: []*Target{
+			{
+				Protocol:   "ndt7",
+				Directions: []string{"download"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, strings.TrimPrefix(address, "ws://"), metrics[0].Tags()["server"])
+	require.Equal(t, "success", metrics[0].Tags()["result"])
+}
+
+func TestNDT7Timeout(t *testing.T) {
+	// Server accepting the connection but never sending any data
+	upgrader := websocket.Upgrader{Subprotocols: []string{ndt7Subprotocol}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	plugin := &NetworkProbe{
+		Timeout: config.Duration(200 * time.Millisecond),
+		Targets: []*Target{
+			{
+				Protocol:   "ndt7",
+				Address:    strings.Replace(server.URL, "http://", "ws://", 1),
+				Directions: []string{"download"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, "timeout", metrics[0].Tags()["result"])
+	require.Equal(t, uint64(1), metrics[0].Fields()["result_code"])
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -18,6 +19,9 @@ type OpcUaListener struct {
 	SubscribeClientConfig
 	client *SubscribeClient
 	Log    telegraf.Logger `toml:"-"`
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 //go:embed sample.conf
@@ -40,38 +44,55 @@ func (o *OpcUaListener) Init() (err error) {
 	return err
 }
 
-func (o *OpcUaListener) Gather(acc telegraf.Accumulator) error {
-	if o.client.State() == opcua.Connected || o.SubscribeClientConfig.ConnectFailBehavior == "ignore" {
+func (o *OpcUaListener) Gather(telegraf.Accumulator) error {
+	if o.SubscribeClientConfig.ConnectFailBehavior == "ignore" {
+		return nil
+	}
+
+	switch o.client.State() {
+	case opcua.Connected, opcua.Connecting, opcua.Reconnecting:
+		// Either connected or the client is restoring the connection
+		// including the subscription on its own
 		return nil
 	}
-	return o.connect(acc)
+
+	// The connection was lost or never established so reconnect and
+	// recreate the subscription with all monitored items
+	o.Log.Debugf("Not connected to %q, reconnecting", o.client.Config.Endpoint)
+	return o.connect()
+}
+
+func (o *OpcUaListener) connect() error {
+	_, err := o.client.StartStreamValues(context.Background())
+	return err
 }
 
-func (o *OpcUaListener) connect(acc telegraf.Accumulator) error {
-	ctx := context.Background()
-	ch, err := o.client.StartStreamValues(ctx)
-	if err != nil {
+func (o *OpcUaListener) Start(acc telegraf.Accumulator) error {
+	if err := o.connect(); err != nil {
 		return err
 	}
 
+	// Forward the metrics of all subscriptions created over the lifetime of
+	// the plugin as the client keeps the channel on reconnects
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	o.wg.Add(1)
 	go func() {
+		defer o.wg.Done()
 		for {
-			m, ok := <-ch
-			if !ok {
-				o.Log.Debug("Metric collection stopped due to closed channel")
+			select {
+			case <-ctx.Done():
+				o.Log.Debug("Metric collection stopped")
 				return
+			case m := <-o.client.metrics:
+				acc.AddMetric(m)
 			}
-			acc.AddMetric(m)
 		}
 	}()
 
 	return nil
 }
 
-func (o *OpcUaListener) Start(acc telegraf.Accumulator) error {
-	return o.connect(acc)
-}
-
 func (o *OpcUaListener) Stop() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	select {
@@ -81,6 +102,11 @@ func (o *OpcUaListener) Stop() {
 		o.Log.Warn("Timeout while stopping OPC UA subscription")
 	}
 	cancel()
+
+	if o.cancel != nil {
+		o.cancel()
+	}
+	o.wg.Wait()
 }
 
 // Add this plugin to telegraf
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/docker/go-connections/nat"
+	gopcua "github.com/gopcua/opcua"
 	"github.com/gopcua/opcua/ua"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -127,6 +128,208 @@ func TestStartPlugin(t *testing.T) {
 	require.Equal(t, opcua.Connected, plugin.client.OpcUAClient.State())
 }
 
+func TestGatherReconnect(t *testing.T) {
+	tests := []struct {
+		name      string
+		behavior  string
+		expectErr bool
+		warnings  int
+	}{
+		{
+			name:      "error",
+			behavior:  "error",
+			expectErr: true,
+		},
+		{
+			name:     "retry",
+			behavior: "retry",
+			warnings: 1,
+		},
+		{
+			name:     "ignore",
+			behavior: "ignore",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &testutil.CaptureLogger{}
+			plugin := OpcUaListener{
+				SubscribeClientConfig: SubscribeClientConfig{
+					InputClientConfig: input.InputClientConfig{
+						OpcUAClientConfig: opcua.OpcUAClientConfig{
+							Endpoint:       "opc.tcp://127.0.0.1:1",
+							SecurityPolicy: "None",
+							SecurityMode:   "None",
+							ConnectTimeout: config.Duration(time.Second),
+							RequestTimeout: config.Duration(time.Second),
+						},
+						MetricName: "opcua",
+						Timestamp:  input.TimestampSourceTelegraf,
+						RootNodes: []input.NodeSettings{
+							MapOPCTag(OPCTags{"ProductName", "0", "i", "2261", nil}),
+						},
+					},
+					ConnectFailBehavior:  tt.behavior,
+					SubscriptionInterval: config.Duration(100 * time.Millisecond),
+				},
+				Log: logger,
+			}
+			require.NoError(t, plugin.Init())
+			require.Equal(t, opcua.Disconnected, plugin.client.State())
+
+			// Gather has to try to reconnect as the client is not connected
+			var acc testutil.Accumulator
+			err := plugin.Gather(&acc)
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, logger.Warnings(), tt.warnings)
+			require.Empty(t, logger.Errors())
+			require.NotEqual(t, opcua.Connected, plugin.client.State())
+			require.Nil(t, plugin.client.processingCancel)
+		})
+	}
+}
+
+func TestProcessingRestart(t *testing.T) {
+	subscribeConfig := SubscribeClientConfig{
+		InputClientConfig: input.InputClientConfig{
+			OpcUAClientConfig: opcua.OpcUAClientConfig{
+				Endpoint:       "opc.tcp://127.0.0.1:1",
+				SecurityPolicy: "None",
+				SecurityMode:   "None",
+				ConnectTimeout: config.Duration(time.Second),
+				RequestTimeout: config.Duration(time.Second),
+			},
+			MetricName: "testing",
+			Timestamp:  input.TimestampSourceTelegraf,
+			RootNodes: []input.NodeSettings{
+				MapOPCTag(OPCTags{"goodnode", "1", "s", "the.answer", nil}),
+			},
+		},
+	}
+	o, err := subscribeConfig.CreateSubscribeClient(testutil.Logger{})
+	require.NoError(t, err)
+
+	notification := &gopcua.PublishNotificationData{
+		Value: &ua.DataChangeNotification{
+			MonitoredItems: []*ua.MonitoredItemNotification{
+				{
+					ClientHandle: 0,
+					Value: &ua.DataValue{
+						Value:  ua.MustVariant(int32(42)),
+						Status: ua.StatusOK,
+					},
+				},
+			},
+		},
+	}
+
+	// Restarting the processing, e.g. on a reconnect, must stop the
+	// previous processing goroutine
+	o.startProcessing()
+	first := o.processingDone
+	o.startProcessing()
+	defer o.stopProcessing()
+	select {
+	case <-first:
+	default:
+		require.Fail(t, "previous processing goroutine still running")
+	}
+	require.NotNil(t, o.processingDone)
+
+	o.dataNotifications <- notification
+	select {
+	case m := <-o.metrics:
+		require.Equal(t, int64(42), m.Fields()["goodnode"])
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no metric received")
+	}
+	select {
+	case m := <-o.metrics:
+		require.Failf(t, "unexpected metric", "%v", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Nothing must be processed after stopping
+	o.stopProcessing()
+	require.Nil(t, o.processingCancel)
+	o.dataNotifications <- notification
+	select {
+	case m := <-o.metrics:
+		require.Failf(t, "unexpected metric", "%v", m)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestReconnectIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	container := testutil.Container{
+		Image:        "open62541/open62541",
+		ExposedPorts: []string{servicePort},
+		WaitingFor: wait.ForAll(
+			wait.ForListeningPort(nat.Port(servicePort)),
+			wait.ForLog("TCP network layer listening on opc.tcp://"),
+		),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	plugin := OpcUaListener{
+		SubscribeClientConfig: SubscribeClientConfig{
+			InputClientConfig: input.InputClientConfig{
+				OpcUAClientConfig: opcua.OpcUAClientConfig{
+					Endpoint:       fmt.Sprintf("opc.tcp://%s:%s", container.Address, container.Ports[servicePort]),
+					SecurityPolicy: "None",
+					SecurityMode:   "None",
+					AuthMethod:     "Anonymous",
+					ConnectTimeout: config.Duration(10 * time.Second),
+					RequestTimeout: config.Duration(1 * time.Second),
+				},
+				MetricName: "opcua",
+				Timestamp:  input.TimestampSourceTelegraf,
+				RootNodes: []input.NodeSettings{
+					MapOPCTag(OPCTags{"goodnode", "1", "s", "the.answer", nil}),
+				},
+			},
+			ConnectFailBehavior:  "retry",
+			SubscriptionInterval: config.Duration(100 * time.Millisecond),
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.Equal(t, opcua.Connected, plugin.client.State())
+	acc.Wait(1)
+	require.Equal(t, int64(42), acc.GetTelegrafMetrics()[0].Fields()["goodnode"])
+	processing := plugin.client.processingDone
+
+	// Lose the session and let the next gather cycle reconnect
+	require.NoError(t, plugin.client.Disconnect(context.Background()))
+	require.Equal(t, opcua.Disconnected, plugin.client.State())
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, opcua.Connected, plugin.client.State())
+
+	// The subscription must be recreated with a single processing goroutine
+	select {
+	case <-processing:
+	default:
+		require.Fail(t, "processing goroutine of the lost session still running")
+	}
+	require.NotNil(t, plugin.client.processingDone)
+	acc.Wait(1)
+	require.Equal(t, int64(42), acc.GetTelegrafMetrics()[0].Fields()["goodnode"])
+}
+
 func TestSubscribeClientIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
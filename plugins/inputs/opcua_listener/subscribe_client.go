@@ -30,8 +30,8 @@ type SubscribeClient struct {
 	dataNotifications  chan *opcua.PublishNotificationData
 	metrics            chan telegraf.Metric
 
-	processingCtx    context.Context
 	processingCancel context.CancelFunc
+	processingDone   chan struct{}
 }
 
 func checkDataChangeFilterParameters(params *input.DataChangeFilter) error {
@@ -144,7 +144,7 @@ func (o *SubscribeClient) Stop(ctx context.Context) <-chan struct{} {
 		}
 	}
 	closing := o.OpcUAInputClient.Stop(ctx)
-	o.processingCancel()
+	o.stopProcessing()
 	return closing
 }
 
@@ -178,16 +178,43 @@ func (o *SubscribeClient) StartStreamValues(ctx context.Context) (<-chan telegra
 		}
 	}
 
-	o.processingCtx, o.processingCancel = context.WithCancel(context.Background())
-	go o.processReceivedNotifications()
+	o.startProcessing()
 
 	return o.metrics, nil
 }
 
-func (o *SubscribeClient) processReceivedNotifications() {
+// startProcessing starts processing the received notifications. Processing of
+// a previous subscription is stopped first in case of a reconnect, so there is
+// only a single goroutine processing the notifications at any time.
+func (o *SubscribeClient) startProcessing() {
+	o.stopProcessing()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	o.processingCancel = cancel
+	o.processingDone = done
+	go func() {
+		defer close(done)
+		o.processReceivedNotifications(ctx)
+	}()
+}
+
+// stopProcessing stops processing the received notifications and waits for
+// the processing goroutine to finish.
+func (o *SubscribeClient) stopProcessing() {
+	if o.processingCancel == nil {
+		return
+	}
+	o.processingCancel()
+	<-o.processingDone
+	o.processingCancel = nil
+	o.processingDone = nil
+}
+
+func (o *SubscribeClient) processReceivedNotifications(ctx context.Context) {
 	for {
 		select {
-		case <-o.processingCtx.Done():
+		case <-ctx.Done():
 			o.Log.Debug("Processing received notifications stopped")
 			return
 
@@ -211,7 +238,12 @@ func (o *SubscribeClient) processReceivedNotifications() {
 					o.UpdateNodeValue(i, monitoredItemNotif.Value)
 					o.Log.Debugf("Data change notification: node %q value changed from %v to %v",
 						o.NodeIDs[i].String(), oldValue, o.LastReceivedData[i].Value)
-					o.metrics <- o.MetricForNode(i)
+					select {
+					case o.metrics <- o.MetricForNode(i):
+					case <-ctx.Done():
+						o.Log.Debug("Processing received notifications stopped")
+						return
+					}
 				}
 
 			default:
@@ -0,0 +1,75 @@
+package oracle
+
+// builtinQueries contains predefined, statspack-style queries for commonly
+// monitored aspects of an Oracle instance, selectable via the
+// "builtin_queries" option
+var builtinQueries = map[string]Query{
+	"wait_classes": {
+		Measurement: "oracle_wait_class",
+		TagColumns:  []string{"wait_class"},
+		Query: `
+SELECT
+  wait_class,
+  total_waits,
+  time_waited * 10 AS time_waited_ms,
+  total_waits_fg,
+  time_waited_fg * 10 AS time_waited_fg_ms
+FROM v$system_wait_class
+WHERE wait_class <> 'Idle'`,
+	},
+	"tablespaces": {
+		Measurement: "oracle_tablespace",
+		TagColumns:  []string{"tablespace_name", "contents"},
+		Query: `
+SELECT
+  m.tablespace_name,
+  t.contents,
+  t.status,
+  m.used_space * t.block_size AS used_bytes,
+  m.tablespace_size * t.block_size AS max_bytes,
+  m.used_percent
+FROM dba_tablespace_usage_metrics m
+JOIN dba_tablespaces t ON t.tablespace_name = m.tablespace_name`,
+	},
+	"sessions": {
+		Measurement: "oracle_sessions",
+		TagColumns:  []string{"status", "type"},
+		Query: `
+SELECT
+  status,
+  type,
+  COUNT(*) AS sessions
+FROM v$session
+GROUP BY status, type`,
+	},
+	"resource_limits": {
+		Measurement: "oracle_resource_limit",
+		TagColumns:  []string{"resource_name"},
+		Query: `
+SELECT
+  resource_name,
+  current_utilization,
+  max_utilization,
+  CASE WHEN TRIM(limit_value) = 'UNLIMITED' THEN -1 ELSE TO_NUMBER(TRIM(limit_value)) END AS limit_value
+FROM v$resource_limit
+WHERE resource_name IN ('processes', 'sessions', 'transactions')`,
+	},
+	"sysstat": {
+		Measurement: "oracle_sysstat",
+		Query: `
+SELECT
+  SUM(CASE name WHEN 'user commits' THEN value END) AS user_commits,
+  SUM(CASE name WHEN 'user rollbacks' THEN value END) AS user_rollbacks,
+  SUM(CASE name WHEN 'user calls' THEN value END) AS user_calls,
+  SUM(CASE name WHEN 'execute count' THEN value END) AS executions,
+  SUM(CASE name WHEN 'parse count (total)' THEN value END) AS parses_total,
+  SUM(CASE name WHEN 'parse count (hard)' THEN value END) AS parses_hard,
+  SUM(CASE name WHEN 'session logical reads' THEN value END) AS logical_reads,
+  SUM(CASE name WHEN 'physical reads' THEN value END) AS physical_reads,
+  SUM(CASE name WHEN 'physical writes' THEN value END) AS physical_writes,
+  SUM(CASE name WHEN 'redo size' THEN value END) AS redo_size_bytes,
+  SUM(CASE name WHEN 'bytes sent via SQL*Net to client' THEN value END) AS bytes_sent,
+  SUM(CASE name WHEN 'bytes received via SQL*Net from client' THEN value END) AS bytes_received
+FROM v$sysstat`,
+	},
+}
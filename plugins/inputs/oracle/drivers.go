@@ -0,0 +1,6 @@
+package oracle
+
+import (
+	// Blank import to register the pure-Go thin driver as "oracle"
+	_ "github.com/sijms/go-ora/v2"
+)
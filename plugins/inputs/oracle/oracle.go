@@ -0,0 +1,260 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package oracle
+
+import (
+	"context"
+	dbsql "database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const instanceQuery = `SELECT SYS_CONTEXT('USERENV', 'DB_NAME') AS db_name, SYS_CONTEXT('USERENV', 'INSTANCE_NAME') AS instance FROM dual`
+
+type Query struct {
+	Measurement string   `toml:"measurement"`
+	Query       string   `toml:"query"`
+	TagColumns  []string `toml:"tag_columns"`
+}
+
+type Oracle struct {
+	DSN            config.Secret   `toml:"dsn"`
+	Timeout        config.Duration `toml:"timeout"`
+	MaxOpenConns   int             `toml:"connection_max_open"`
+	BuiltinQueries []string        `toml:"builtin_queries"`
+	Queries        []Query         `toml:"query"`
+	Log            telegraf.Logger `toml:"-"`
+
+	queries      []Query
+	db           *dbsql.DB
+	instanceTags map[string]string
+}
+
+func (*Oracle) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *Oracle) Init() error {
+	if o.DSN.Empty() {
+		return errors.New("missing data source name")
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = config.Duration(10 * time.Second)
+	}
+
+	o.queries = make([]Query, 0, len(o.BuiltinQueries)+len(o.Queries))
+	for _, name := range o.BuiltinQueries {
+		q, found := builtinQueries[name]
+		if !found {
+			return fmt.Errorf("unknown builtin query %q", name)
+		}
+		o.queries = append(o.queries, q)
+	}
+	for i, q := range o.Queries {
+		if q.Query == "" {
+			return fmt.Errorf("query #%d has no SQL statement", i+1)
+		}
+		if q.Measurement == "" {
+			return fmt.Errorf("query #%d has no measurement", i+1)
+		}
+		// Oracle reports unquoted column names in upper-case so compare them
+		// case-insensitively
+		tagColumns := make([]string, 0, len(q.TagColumns))
+		for _, c := range q.TagColumns {
+			tagColumns = append(tagColumns, strings.ToLower(c))
+		}
+		q.TagColumns = tagColumns
+		o.queries = append(o.queries, q)
+	}
+	if len(o.queries) == 0 {
+		return errors.New("no queries configured")
+	}
+
+	return nil
+}
+
+func (o *Oracle) Start(_ telegraf.Accumulator) error {
+	dsnSecret, err := o.DSN.Get()
+	if err != nil {
+		return fmt.Errorf("getting DSN failed: %w", err)
+	}
+	dsn := dsnSecret.String()
+	dsnSecret.Destroy()
+
+	o.db, err = dbsql.Open("oracle", dsn)
+	if err != nil {
+		return err
+	}
+	o.db.SetMaxOpenConns(o.MaxOpenConns)
+
+	// The server might not be available yet, so only warn here and retry
+	// the connection on gather.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	defer cancel()
+	if err := o.db.PingContext(ctx); err != nil {
+		o.Log.Warnf("Unable to connect to database: %v", err)
+	}
+
+	return nil
+}
+
+func (o *Oracle) Stop() {
+	if o.db != nil {
+		if err := o.db.Close(); err != nil {
+			o.Log.Errorf("Closing database connection failed: %v", err)
+		}
+	}
+}
+
+func (o *Oracle) Gather(acc telegraf.Accumulator) error {
+	if o.instanceTags == nil {
+		tags, err := o.queryInstance()
+		if err != nil {
+			return fmt.Errorf("querying instance information failed: %w", err)
+		}
+		o.instanceTags = tags
+	}
+
+	var wg sync.WaitGroup
+	tstart := time.Now()
+	for _, q := range o.queries {
+		wg.Add(1)
+		go func(q Query) {
+			defer wg.Done()
+			if err := o.executeQuery(acc, q, tstart); err != nil {
+				acc.AddError(fmt.Errorf("query for %q failed: %w", q.Measurement, err))
+			}
+		}(q)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (o *Oracle) queryInstance() (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	defer cancel()
+
+	var dbName, instance dbsql.NullString
+	if err := o.db.QueryRowContext(ctx, instanceQuery).Scan(&dbName, &instance); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"db_name":  dbName.String,
+		"instance": instance.String,
+	}, nil
+}
+
+func (o *Oracle) executeQuery(acc telegraf.Accumulator, q Query, t time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	defer cancel()
+
+	rows, err := o.db.QueryContext(ctx, q.Query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	for i, c := range columns {
+		columns[i] = strings.ToLower(c)
+	}
+
+	isTag := make(map[string]bool, len(q.TagColumns))
+	for _, c := range q.TagColumns {
+		isTag[c] = true
+	}
+
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+
+		tags := make(map[string]string, len(o.instanceTags)+len(q.TagColumns))
+		for k, v := range o.instanceTags {
+			tags[k] = v
+		}
+		fields := make(map[string]interface{}, len(columns))
+		for i, name := range columns {
+			if values[i] == nil {
+				continue
+			}
+			if isTag[name] {
+				tags[name] = tagValue(values[i])
+				continue
+			}
+			fields[name] = fieldValue(values[i])
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		acc.AddFields(q.Measurement, fields, tags, t)
+	}
+
+	return rows.Err()
+}
+
+func tagValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []byte:
+		return strings.TrimSpace(string(v))
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+func fieldValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int64, float64, bool, string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UnixNano()
+	}
+
+	// Driver specific types such as NUMBER wrappers are converted via their
+	// string representation
+	s := fmt.Sprint(v)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+func init() {
+	inputs.Add("oracle", func() telegraf.Input {
+		return &Oracle{
+			Timeout:        config.Duration(10 * time.Second),
+			MaxOpenConns:   4,
+			BuiltinQueries: []string{"wait_classes", "tablespaces", "sessions", "resource_limits", "sysstat"},
+		}
+	})
+}
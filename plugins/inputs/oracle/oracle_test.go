@@ -0,0 +1,242 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Oracle
+		expected string
+	}{
+		{
+			name:     "missing dsn",
+			plugin:   &Oracle{BuiltinQueries: []string{"sessions"}},
+			expected: "missing data source name",
+		},
+		{
+			name: "unknown builtin",
+			plugin: &Oracle{
+				DSN:            config.NewSecret([]byte("oracle://u:p@localhost/db")),
+				BuiltinQueries: []string{"foo"},
+			},
+			expected: `unknown builtin query "foo"`,
+		},
+		{
+			name: "no queries",
+			plugin: &Oracle{
+				DSN: config.NewSecret([]byte("oracle://u:p@localhost/db")),
+			},
+			expected: "no queries configured",
+		},
+		{
+			name: "query without statement",
+			plugin: &Oracle{
+				DSN:     config.NewSecret([]byte("oracle://u:p@localhost/db")),
+				Queries: []Query{{Measurement: "foo"}},
+			},
+			expected: "query #1 has no SQL statement",
+		},
+		{
+			name: "query without measurement",
+			plugin: &Oracle{
+				DSN:     config.NewSecret([]byte("oracle://u:p@localhost/db")),
+				Queries: []Query{{Query: "SELECT 1 FROM dual"}},
+			},
+			expected: "query #1 has no measurement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGatherBuiltin(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(instanceQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"DB_NAME", "INSTANCE"}).AddRow("ORCLCDB", "ORCLCDB"),
+	)
+	mock.ExpectQuery(builtinQueries["wait_classes"].Query).WillReturnRows(
+		sqlmock.NewRows([]string{"WAIT_CLASS", "TOTAL_WAITS", "TIME_WAITED_MS", "TOTAL_WAITS_FG", "TIME_WAITED_FG_MS"}).
+			AddRow("User I/O", int64(1843202), int64(512930), int64(902113), int64(341820)).
+			AddRow("Commit", int64(10392), int64(2810), int64(10392), int64(2810)),
+	)
+	mock.ExpectQuery(builtinQueries["tablespaces"].Query).WillReturnRows(
+		sqlmock.NewRows([]string{"TABLESPACE_NAME", "CONTENTS", "STATUS", "USED_BYTES", "MAX_BYTES", "USED_PERCENT"}).
+			AddRow("USERS", "PERMANENT", "ONLINE", int64(52428800), int64(34359721984), 0.15),
+	)
+	mock.ExpectQuery(builtinQueries["sessions"].Query).WillReturnRows(
+		sqlmock.NewRows([]string{"STATUS", "TYPE", "SESSIONS"}).
+			AddRow("ACTIVE", "USER", int64(12)).
+			AddRow("INACTIVE", "USER", int64(40)),
+	)
+
+	plugin := &Oracle{
+		DSN:            config.NewSecret([]byte("oracle://u:p@localhost/db")),
+		BuiltinQueries: []string{"wait_classes", "tablespaces", "sessions"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.db = db
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"oracle_wait_class",
+			map[string]string{"db_name": "ORCLCDB", "instance": "ORCLCDB", "wait_class": "User I/O"},
+			map[string]interface{}{
+				"total_waits":       int64(1843202),
+				"time_waited_ms":    int64(512930),
+				"total_waits_fg":    int64(902113),
+				"time_waited_fg_ms": int64(341820),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"oracle_wait_class",
+			map[string]string{"db_name": "ORCLCDB", "instance": "ORCLCDB", "wait_class": "Commit"},
+			map[string]interface{}{
+				"total_waits":       int64(10392),
+				"time_waited_ms":    int64(2810),
+				"total_waits_fg":    int64(10392),
+				"time_waited_fg_ms": int64(2810),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"oracle_tablespace",
+			map[string]string{"db_name": "ORCLCDB", "instance": "ORCLCDB", "tablespace_name": "USERS", "contents": "PERMANENT"},
+			map[string]interface{}{
+				"status":       "ONLINE",
+				"used_bytes":   int64(52428800),
+				"max_bytes":    int64(34359721984),
+				"used_percent": 0.15,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"oracle_sessions",
+			map[string]string{"db_name": "ORCLCDB", "instance": "ORCLCDB", "status": "ACTIVE", "type": "USER"},
+			map[string]interface{}{"sessions": int64(12)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"oracle_sessions",
+			map[string]string{"db_name": "ORCLCDB", "instance": "ORCLCDB", "status": "INACTIVE", "type": "USER"},
+			map[string]interface{}{"sessions": int64(40)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherCustomQuery(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := "SELECT owner, object_type, COUNT(*) AS count, MAX(last_ddl_time) AS last_ddl FROM dba_objects GROUP BY owner, object_type"
+	lastDDL := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+
+	mock.ExpectQuery(instanceQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"DB_NAME", "INSTANCE"}).AddRow("ORCLCDB", "ORCLCDB1"),
+	)
+	mock.ExpectQuery(query).WillReturnRows(
+		sqlmock.NewRows([]string{"OWNER", "OBJECT_TYPE", "COUNT", "LAST_DDL"}).
+			AddRow([]byte("APP  "), "VIEW", "3", lastDDL).
+			AddRow("APP", "TABLE", int64(42), nil).
+			AddRow("NOBODY", "TABLE", nil, nil),
+	)
+
+	plugin := &Oracle{
+		DSN: config.NewSecret([]byte("oracle://u:p@localhost/db")),
+		Queries: []Query{
+			{
+				Measurement: "oracle_objects",
+				Query:       query,
+				TagColumns:  []string{"OWNER", "object_type"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.db = db
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"oracle_objects",
+			map[string]string{"db_name": "ORCLCDB", "instance": "ORCLCDB1", "owner": "APP", "object_type": "VIEW"},
+			map[string]interface{}{"count": "3", "last_ddl": lastDDL.UnixNano()},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"oracle_objects",
+			map[string]string{"db_name": "ORCLCDB", "instance": "ORCLCDB1", "owner": "APP", "object_type": "TABLE"},
+			map[string]interface{}{"count": int64(42)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherQueryError(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(instanceQuery).WillReturnRows(
+		sqlmock.NewRows([]string{"DB_NAME", "INSTANCE"}).AddRow("ORCLCDB", "ORCLCDB"),
+	)
+	mock.ExpectQuery(builtinQueries["tablespaces"].Query).WillReturnError(
+		errors.New("ORA-00942: table or view does not exist"),
+	)
+
+	plugin := &Oracle{
+		DSN:            config.NewSecret([]byte("oracle://u:p@localhost/db")),
+		BuiltinQueries: []string{"tablespaces"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.db = db
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "ORA-00942")
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFieldValue(t *testing.T) {
+	require.Equal(t, int64(5), fieldValue(int64(5)))
+	require.Equal(t, "abc", fieldValue([]byte("abc")))
+	require.Equal(t, int64(12), fieldValue(uint8(12)))
+	require.InDelta(t, 1.5, fieldValue(float32(1.5)), 1e-9)
+}
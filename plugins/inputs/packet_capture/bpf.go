@@ -0,0 +1,72 @@
+package packet_capture
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/bpf"
+)
+
+// parseBPF parses a compiled BPF program in the decimal format produced by
+// "tcpdump -ddd". The first value is the number of instructions followed by
+// one instruction per line in the form "<code> <jt> <jf> <k>". Instructions
+// might also be separated by commas as used by e.g. iptables.
+func parseBPF(program string) ([]bpf.RawInstruction, error) {
+	lines := strings.FieldsFunc(program, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ','
+	})
+	var entries []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, line)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("empty program")
+	}
+
+	count, err := strconv.ParseUint(entries[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instruction count %q: %w", entries[0], err)
+	}
+	if int(count) != len(entries)-1 {
+		return nil, fmt.Errorf("expected %d instructions but got %d", count, len(entries)-1)
+	}
+	if count == 0 {
+		return nil, errors.New("program without instructions")
+	}
+
+	instructions := make([]bpf.RawInstruction, 0, count)
+	for i, entry := range entries[1:] {
+		parts := strings.Fields(entry)
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("instruction %d: expected four values but got %q", i, entry)
+		}
+		code, err := strconv.ParseUint(parts[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: invalid code: %w", i, err)
+		}
+		jt, err := strconv.ParseUint(parts[1], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: invalid jump-true offset: %w", i, err)
+		}
+		jf, err := strconv.ParseUint(parts[2], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: invalid jump-false offset: %w", i, err)
+		}
+		k, err := strconv.ParseUint(parts[3], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: invalid constant: %w", i, err)
+		}
+		instructions = append(instructions, bpf.RawInstruction{
+			Op: uint16(code),
+			Jt: uint8(jt),
+			Jf: uint8(jf),
+			K:  uint32(k),
+		})
+	}
+
+	return instructions, nil
+}
@@ -0,0 +1,113 @@
+//go:build linux
+
+package packet_capture
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// afpacketCapture captures packets using a raw AF_PACKET socket bound to a
+// single interface
+type afpacketCapture struct {
+	fd int
+}
+
+func openCapture(iface string, filter []bpf.RawInstruction, promiscuous bool) (capture, error) {
+	ifi, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, err
+	}
+
+	// The protocol must be given in network byte-order
+	var protocol [2]byte
+	binary.BigEndian.PutUint16(protocol[:], unix.ETH_P_ALL)
+	proto := binary.NativeEndian.Uint16(protocol[:])
+
+	// Create the socket without protocol so it does not receive any packets
+	// until it is bound to the interface after attaching the filter.
+	// Otherwise, unfiltered packets of all interfaces might be queued.
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("creating socket failed: %w", err)
+	}
+	c := &afpacketCapture{fd: fd}
+
+	if len(filter) > 0 {
+		instructions := make([]unix.SockFilter, 0, len(filter))
+		for _, f := range filter {
+			instructions = append(instructions, unix.SockFilter{Code: f.Op, Jt: f.Jt, Jf: f.Jf, K: f.K})
+		}
+		prog := &unix.SockFprog{
+			Len:    uint16(len(instructions)),
+			Filter: &instructions[0],
+		}
+		if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, prog); err != nil {
+			_ = c.close()
+			return nil, fmt.Errorf("attaching BPF filter failed: %w", err)
+		}
+	}
+
+	addr := &unix.SockaddrLinklayer{
+		Protocol: proto,
+		Ifindex:  ifi.Index,
+	}
+	if err := unix.Bind(fd, addr); err != nil {
+		_ = c.close()
+		return nil, fmt.Errorf("binding to interface failed: %w", err)
+	}
+
+	if promiscuous {
+		mreq := &unix.PacketMreq{
+			Ifindex: int32(ifi.Index),
+			Type:    unix.PACKET_MR_PROMISC,
+		}
+		if err := unix.SetsockoptPacketMreq(fd, unix.SOL_PACKET, unix.PACKET_ADD_MEMBERSHIP, mreq); err != nil {
+			_ = c.close()
+			return nil, fmt.Errorf("enabling promiscuous mode failed: %w", err)
+		}
+	}
+
+	// Use a read timeout to be able to stop the capture
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &unix.Timeval{Sec: 1}); err != nil {
+		_ = c.close()
+		return nil, fmt.Errorf("setting read timeout failed: %w", err)
+	}
+
+	// Reset the statistics accumulated before starting
+	if _, err := c.drops(); err != nil {
+		_ = c.close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *afpacketCapture) read(buf []byte) (captured, length int, err error) {
+	// Use MSG_TRUNC to get the original length of truncated packets
+	n, _, err := unix.Recvfrom(c.fd, buf, unix.MSG_TRUNC)
+	if err != nil {
+		if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+			return 0, 0, errTimeout
+		}
+		return 0, 0, err
+	}
+	return min(n, len(buf)), n, nil
+}
+
+func (c *afpacketCapture) drops() (uint64, error) {
+	stats, err := unix.GetsockoptTpacketStats(c.fd, unix.SOL_PACKET, unix.PACKET_STATISTICS)
+	if err != nil {
+		return 0, fmt.Errorf("reading packet statistics failed: %w", err)
+	}
+	return uint64(stats.Drops), nil
+}
+
+func (c *afpacketCapture) close() error {
+	return unix.Close(c.fd)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package packet_capture
+
+import (
+	"errors"
+
+	"golang.org/x/net/bpf"
+)
+
+func openCapture(string, []bpf.RawInstruction, bool) (capture, error) {
+	return nil, errors.New("packet capture is only supported on Linux")
+}
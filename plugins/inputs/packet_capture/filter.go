@@ -0,0 +1,531 @@
+package packet_capture
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/bpf"
+)
+
+// Offsets into Ethernet frames used by the filter
+const (
+	offEtherType  = 12
+	offIPv4       = 14 // start of the IPv4 header
+	offIPv4Frag   = 20 // flags and fragment offset
+	offIPv4Proto  = 23
+	offIPv4Src    = 26
+	offIPv4Dst    = 30
+	offIPv6Next   = 20 // next header
+	offIPv6Src    = 22
+	offIPv6Dst    = 38
+	offIPv6Ports  = 54 // first byte after the fixed IPv6 header
+	offARPSender  = 28 // sender protocol address
+	offARPTarget  = 38 // target protocol address
+	filterSnapLen = 262144
+)
+
+var etherTypes = map[string]uint32{
+	"ip":  0x0800,
+	"ip6": 0x86dd,
+	"arp": 0x0806,
+}
+
+var ipProtocols = map[string]uint32{
+	"icmp":  1,
+	"tcp":   6,
+	"udp":   17,
+	"icmp6": 58,
+	"sctp":  132,
+}
+
+// compileFilter compiles the given filter into a BPF program for Ethernet
+// frames. The filter is either an expression in a subset of the pcap-filter
+// syntax or a program in the decimal format of "tcpdump -ddd".
+func compileFilter(filter string) ([]bpf.RawInstruction, error) {
+	filter = strings.TrimSpace(filter)
+	if filter != "" && unicode.IsDigit(rune(filter[0])) {
+		return parseBPF(filter)
+	}
+
+	p := &filterParser{tokens: tokenize(filter)}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &filterCompiler{jumps: make(map[int][2]int)}
+	accept, reject := c.newLabel(), c.newLabel()
+	c.emit(root, accept, reject)
+	c.place(accept)
+	c.instructions = append(c.instructions, bpf.RetConstant{Val: filterSnapLen})
+	c.place(reject)
+	c.instructions = append(c.instructions, bpf.RetConstant{Val: 0})
+
+	return c.assemble()
+}
+
+type filterNode interface{}
+
+type andNode struct {
+	left, right filterNode
+}
+
+type orNode struct {
+	left, right filterNode
+}
+
+type notNode struct {
+	node filterNode
+}
+
+// checkNode compares a value of the packet, optionally masked, with a constant
+type checkNode struct {
+	indirect bool // offset relative to the end of the IPv4 header
+	offset   uint32
+	size     int
+	mask     uint32
+	cond     bpf.JumpTest
+	value    uint32
+}
+
+func equal(offset uint32, size int, value uint32) *checkNode {
+	return &checkNode{offset: offset, size: size, cond: bpf.JumpEqual, value: value}
+}
+
+func allOf(nodes ...filterNode) filterNode {
+	n := nodes[0]
+	for _, m := range nodes[1:] {
+		n = &andNode{left: n, right: m}
+	}
+	return n
+}
+
+func anyOf(nodes ...filterNode) filterNode {
+	n := nodes[0]
+	for _, m := range nodes[1:] {
+		n = &orNode{left: n, right: m}
+	}
+	return n
+}
+
+func tokenize(expr string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case unicode.IsSpace(rune(c)):
+			flush()
+		case c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case strings.HasPrefix(expr[i:], "&&"):
+			flush()
+			tokens = append(tokens, "and")
+			i++
+		case strings.HasPrefix(expr[i:], "||"):
+			flush()
+			tokens = append(tokens, "or")
+			i++
+		case c == '!':
+			flush()
+			tokens = append(tokens, "not")
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// filterParser parses filter expressions consisting of primitives combined
+// with "and", "or", "not" and parentheses. Primitives are of the form
+// "[proto] [src|dst] [host|net|port|portrange] <value>" or a single protocol.
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) peek(n int) string {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return ""
+}
+
+func (p *filterParser) next() string {
+	t := p.peek(0)
+	p.pos++
+	return t
+}
+
+func (p *filterParser) parse() (filterNode, error) {
+	if len(p.tokens) == 0 {
+		return nil, errors.New("empty filter expression")
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in filter expression", p.peek(0))
+	}
+	return n, nil
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(0) == "or" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(0) == "and" {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (filterNode, error) {
+	switch p.peek(0) {
+	case "not":
+		p.next()
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node: n}, nil
+	case "(":
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, errors.New("missing closing parenthesis in filter expression")
+		}
+		return n, nil
+	}
+	return p.parsePrimitive()
+}
+
+func (p *filterParser) parsePrimitive() (filterNode, error) {
+	var proto, dir, kind string
+	if _, found := etherTypes[p.peek(0)]; found {
+		proto = p.next()
+	} else if _, found := ipProtocols[p.peek(0)]; found {
+		proto = p.next()
+	}
+
+	if t := p.peek(0); t == "src" || t == "dst" {
+		dir = p.next()
+		// Combined directions "src or dst" and "src and dst"
+		if op, other := p.peek(0), p.peek(1); (op == "or" || op == "and") && (other == "src" || other == "dst") && other != dir {
+			dir = "src " + p.next() + " dst"
+			p.next()
+		}
+	}
+
+	switch p.peek(0) {
+	case "host", "net", "port", "portrange":
+		kind = p.next()
+	}
+
+	if kind == "" && dir == "" {
+		if proto == "" {
+			if t := p.peek(0); t != "" {
+				return nil, fmt.Errorf("unsupported filter primitive %q", t)
+			}
+			return nil, errors.New("unexpected end of filter expression")
+		}
+		return protocol(proto), nil
+	}
+	if kind == "" {
+		kind = "host"
+	}
+
+	value := p.next()
+	switch value {
+	case "", "and", "or", "not", "(", ")":
+		return nil, fmt.Errorf("missing value for %q in filter expression", kind)
+	}
+
+	if kind == "port" || kind == "portrange" {
+		return ports(proto, dir, kind, value)
+	}
+	return addresses(proto, dir, kind, value)
+}
+
+// protocol returns the check for a protocol given by name
+func protocol(proto string) filterNode {
+	if etherType, found := etherTypes[proto]; found {
+		return equal(offEtherType, 2, etherType)
+	}
+	ipv4 := equal(offEtherType, 2, etherTypes["ip"])
+	ipv6 := equal(offEtherType, 2, etherTypes["ip6"])
+	switch proto {
+	case "icmp":
+		return allOf(ipv4, equal(offIPv4Proto, 1, ipProtocols[proto]))
+	case "icmp6":
+		return allOf(ipv6, equal(offIPv6Next, 1, ipProtocols[proto]))
+	}
+	return anyOf(
+		allOf(ipv4, equal(offIPv4Proto, 1, ipProtocols[proto])),
+		allOf(ipv6, equal(offIPv6Next, 1, ipProtocols[proto])),
+	)
+}
+
+// directions combines the source and destination checks according to the
+// given direction qualifier
+func directions(dir string, src, dst filterNode) filterNode {
+	switch dir {
+	case "src":
+		return src
+	case "dst":
+		return dst
+	case "src and dst":
+		return allOf(src, dst)
+	}
+	return anyOf(src, dst)
+}
+
+// addresses returns the checks for host and net primitives
+func addresses(proto, dir, kind, value string) (filterNode, error) {
+	var network *net.IPNet
+	if kind == "host" {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid host %q, only IP addresses are supported", value)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		network = &net.IPNet{IP: ip, Mask: net.CIDRMask(8*len(ip), 8*len(ip))}
+	} else {
+		var err error
+		if _, network, err = net.ParseCIDR(value); err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", value, err)
+		}
+	}
+
+	isIPv4 := len(network.IP) == net.IPv4len
+	switch {
+	case proto == "":
+	case (proto == "ip" || proto == "arp") && isIPv4:
+	case proto == "ip6" && !isIPv4:
+	default:
+		return nil, fmt.Errorf("%q qualifier not supported for %s %q", proto, kind, value)
+	}
+
+	if !isIPv4 {
+		family := equal(offEtherType, 2, etherTypes["ip6"])
+		src := match(offIPv6Src, network)
+		if src == nil {
+			return family, nil
+		}
+		return allOf(family, directions(dir, src, match(offIPv6Dst, network))), nil
+	}
+
+	// Without qualifier IPv4 addresses match both IP and ARP packets
+	var families []filterNode
+	if proto != "arp" {
+		var family filterNode = equal(offEtherType, 2, etherTypes["ip"])
+		if src := match(offIPv4Src, network); src != nil {
+			family = allOf(family, directions(dir, src, match(offIPv4Dst, network)))
+		}
+		families = append(families, family)
+	}
+	if proto != "ip" {
+		var family filterNode = equal(offEtherType, 2, etherTypes["arp"])
+		if src := match(offARPSender, network); src != nil {
+			family = allOf(family, directions(dir, src, match(offARPTarget, network)))
+		}
+		families = append(families, family)
+	}
+	return anyOf(families...), nil
+}
+
+// match returns the checks of the address at the given offset against the
+// network in 32-bit words or nil if the network matches all addresses
+func match(offset uint32, network *net.IPNet) filterNode {
+	var checks []filterNode
+	for i := 0; i < len(network.IP); i += 4 {
+		mask := binary.BigEndian.Uint32(network.Mask[i : i+4])
+		if mask == 0 {
+			continue
+		}
+		c := equal(offset+uint32(i), 4, binary.BigEndian.Uint32(network.IP[i:i+4])&mask)
+		if mask != 0xffffffff {
+			c.mask = mask
+		}
+		checks = append(checks, c)
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+	return allOf(checks...)
+}
+
+// ports returns the checks for port and portrange primitives of TCP, UDP and
+// SCTP packets. IPv4 fragments other than the first and IPv6 packets with
+// extension headers never match.
+func ports(proto, dir, kind, value string) (filterNode, error) {
+	low, high := value, value
+	if kind == "portrange" {
+		var found bool
+		if low, high, found = strings.Cut(value, "-"); !found {
+			return nil, fmt.Errorf("invalid port range %q", value)
+		}
+	}
+	first, err := strconv.ParseUint(low, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %w", low, err)
+	}
+	last, err := strconv.ParseUint(high, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q: %w", high, err)
+	}
+	if first > last {
+		return nil, fmt.Errorf("invalid port range %q", value)
+	}
+
+	var protocols []uint32
+	switch proto {
+	case "", "ip", "ip6":
+		protocols = []uint32{ipProtocols["tcp"], ipProtocols["udp"], ipProtocols["sctp"]}
+	case "tcp", "udp", "sctp":
+		protocols = []uint32{ipProtocols[proto]}
+	default:
+		return nil, fmt.Errorf("%q qualifier not supported for %s", proto, kind)
+	}
+
+	port := func(indirect bool, offset uint32) filterNode {
+		if first == last {
+			return &checkNode{indirect: indirect, offset: offset, size: 2, cond: bpf.JumpEqual, value: uint32(first)}
+		}
+		return allOf(
+			&checkNode{indirect: indirect, offset: offset, size: 2, cond: bpf.JumpGreaterOrEqual, value: uint32(first)},
+			&notNode{node: &checkNode{indirect: indirect, offset: offset, size: 2, cond: bpf.JumpGreaterThan, value: uint32(last)}},
+		)
+	}
+
+	var families []filterNode
+	if proto != "ip6" {
+		protos := make([]filterNode, 0, len(protocols))
+		for _, n := range protocols {
+			protos = append(protos, equal(offIPv4Proto, 1, n))
+		}
+		families = append(families, allOf(
+			equal(offEtherType, 2, etherTypes["ip"]),
+			anyOf(protos...),
+			&checkNode{offset: offIPv4Frag, size: 2, mask: 0x1fff, cond: bpf.JumpEqual, value: 0},
+			directions(dir, port(true, 0), port(true, 2)),
+		))
+	}
+	if proto != "ip" {
+		protos := make([]filterNode, 0, len(protocols))
+		for _, n := range protocols {
+			protos = append(protos, equal(offIPv6Next, 1, n))
+		}
+		families = append(families, allOf(
+			equal(offEtherType, 2, etherTypes["ip6"]),
+			anyOf(protos...),
+			directions(dir, port(false, offIPv6Ports), port(false, offIPv6Ports+2)),
+		))
+	}
+	return anyOf(families...), nil
+}
+
+// filterCompiler generates the BPF instructions of the filter by jumping to
+// the true or false label of each node depending on the check result
+type filterCompiler struct {
+	instructions []bpf.Instruction
+	jumps        map[int][2]int // instruction index to true and false label
+	labels       []int          // instruction index of each label
+}
+
+func (c *filterCompiler) newLabel() int {
+	c.labels = append(c.labels, -1)
+	return len(c.labels) - 1
+}
+
+func (c *filterCompiler) place(label int) {
+	c.labels[label] = len(c.instructions)
+}
+
+func (c *filterCompiler) emit(node filterNode, onTrue, onFalse int) {
+	switch n := node.(type) {
+	case *andNode:
+		next := c.newLabel()
+		c.emit(n.left, next, onFalse)
+		c.place(next)
+		c.emit(n.right, onTrue, onFalse)
+	case *orNode:
+		next := c.newLabel()
+		c.emit(n.left, onTrue, next)
+		c.place(next)
+		c.emit(n.right, onTrue, onFalse)
+	case *notNode:
+		c.emit(n.node, onFalse, onTrue)
+	case *checkNode:
+		if n.indirect {
+			// Load the IPv4 header length into X
+			c.instructions = append(c.instructions,
+				bpf.LoadMemShift{Off: offIPv4},
+				bpf.LoadIndirect{Off: offIPv4 + n.offset, Size: n.size},
+			)
+		} else {
+			c.instructions = append(c.instructions, bpf.LoadAbsolute{Off: n.offset, Size: n.size})
+		}
+		if n.mask != 0 {
+			c.instructions = append(c.instructions, bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: n.mask})
+		}
+		c.jumps[len(c.instructions)] = [2]int{onTrue, onFalse}
+		c.instructions = append(c.instructions, bpf.JumpIf{Cond: n.cond, Val: n.value})
+	}
+}
+
+// assemble resolves the jump labels and assembles the program
+func (c *filterCompiler) assemble() ([]bpf.RawInstruction, error) {
+	for i, targets := range c.jumps {
+		skipTrue := c.labels[targets[0]] - i - 1
+		skipFalse := c.labels[targets[1]] - i - 1
+		if skipTrue > 255 || skipFalse > 255 {
+			return nil, errors.New("filter expression too complex")
+		}
+		jump := c.instructions[i].(bpf.JumpIf)
+		jump.SkipTrue = uint8(skipTrue)
+		jump.SkipFalse = uint8(skipFalse)
+		c.instructions[i] = jump
+	}
+	return bpf.Assemble(c.instructions)
+}
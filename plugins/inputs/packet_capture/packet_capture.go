@@ -0,0 +1,235 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package packet_capture
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// capture is the platform specific packet source of a single interface
+type capture interface {
+	// read reads the next packet into the buffer returning the number of
+	// captured bytes and the original length of the packet on the wire.
+	// The function returns errTimeout if no packet arrived within the
+	// read timeout.
+	read(buf []byte) (captured, length int, err error)
+	// drops returns the number of packets dropped by the kernel since the
+	// last call
+	drops() (uint64, error)
+	close() error
+}
+
+var errTimeout = errors.New("read timeout")
+
+type PacketCapture struct {
+	Interfaces       []string        `toml:"interfaces"`
+	BPFFilter        string          `toml:"bpf_filter"`
+	Promiscuous      bool            `toml:"promiscuous"`
+	SnapLen          int             `toml:"snaplen"`
+	TopTalkers       int             `toml:"top_talkers"`
+	HandshakeTimeout config.Duration `toml:"handshake_timeout"`
+	Log              telegraf.Logger `toml:"-"`
+
+	filter    []bpf.RawInstruction
+	summaries map[string]*summary
+	captures  []capture
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (*PacketCapture) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PacketCapture) Init() error {
+	if len(p.Interfaces) == 0 {
+		return errors.New("no interfaces specified")
+	}
+	if p.SnapLen < 64 {
+		return fmt.Errorf("invalid snaplen %d, must be at least 64 bytes", p.SnapLen)
+	}
+	if p.TopTalkers < 0 {
+		return fmt.Errorf("invalid top_talkers %d, must not be negative", p.TopTalkers)
+	}
+	if p.HandshakeTimeout <= 0 {
+		return errors.New("handshake_timeout must be positive")
+	}
+
+	if p.BPFFilter != "" {
+		filter, err := compileFilter(p.BPFFilter)
+		if err != nil {
+			return fmt.Errorf("compiling BPF filter failed: %w", err)
+		}
+		p.filter = filter
+	}
+
+	p.summaries = make(map[string]*summary, len(p.Interfaces))
+	for _, iface := range p.Interfaces {
+		if _, found := p.summaries[iface]; found {
+			return fmt.Errorf("duplicate interface %q", iface)
+		}
+		p.summaries[iface] = newSummary(time.Duration(p.HandshakeTimeout))
+	}
+
+	return nil
+}
+
+func (p *PacketCapture) Start(acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	for _, iface := range p.Interfaces {
+		c, err := openCapture(iface, p.filter, p.Promiscuous)
+		if err != nil {
+			p.Stop()
+			return fmt.Errorf("opening capture on interface %q failed: %w", iface, err)
+		}
+		p.captures = append(p.captures, c)
+		p.Log.Debugf("Capturing on interface %q", iface)
+
+		p.wg.Add(1)
+		go func(iface string, c capture) {
+			defer p.wg.Done()
+			p.read(ctx, acc, iface, c)
+		}(iface, c)
+	}
+
+	return nil
+}
+
+func (p *PacketCapture) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+
+	for _, c := range p.captures {
+		if err := c.close(); err != nil {
+			p.Log.Errorf("Closing capture failed: %v", err)
+		}
+	}
+	p.captures = nil
+}
+
+func (p *PacketCapture) read(ctx context.Context, acc telegraf.Accumulator, iface string, c capture) {
+	s := p.summaries[iface]
+	buf := make([]byte, p.SnapLen)
+	for {
+		captured, length, err := c.read(buf)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if errors.Is(err, errTimeout) {
+				continue
+			}
+			acc.AddError(fmt.Errorf("reading from interface %q failed: %w", iface, err))
+			return
+		}
+		s.add(buf[:captured], length, time.Now())
+	}
+}
+
+func (p *PacketCapture) Gather(acc telegraf.Accumulator) error {
+	for i, iface := range p.Interfaces {
+		s := p.summaries[iface]
+
+		// Kernel drop statistics are only available while capturing
+		var dropped uint64
+		if i < len(p.captures) {
+			var err error
+			if dropped, err = p.captures[i].drops(); err != nil {
+				acc.AddError(fmt.Errorf("getting drop statistics of interface %q failed: %w", iface, err))
+			}
+		}
+
+		r := s.reset(time.Now())
+		p.gatherReport(acc, iface, r, dropped)
+	}
+	return nil
+}
+
+func (p *PacketCapture) gatherReport(acc telegraf.Accumulator, iface string, r *report, dropped uint64) {
+	tags := map[string]string{"interface": iface}
+	fields := map[string]interface{}{
+		"packets":            r.packets,
+		"bytes":              r.bytes,
+		"dropped":            dropped,
+		"undecoded":          r.undecoded,
+		"handshakes":         r.handshakes,
+		"handshake_timeouts": r.handshakeTimeouts,
+	}
+	if r.handshakes > 0 {
+		fields["handshake_min_ms"] = float64(r.handshakeMin) / float64(time.Millisecond)
+		fields["handshake_max_ms"] = float64(r.handshakeMax) / float64(time.Millisecond)
+		fields["handshake_mean_ms"] = float64(r.handshakeSum) / float64(r.handshakes) / float64(time.Millisecond)
+	}
+	acc.AddFields("packet_capture", fields, tags)
+
+	for protocol, stats := range r.protocols {
+		tags := map[string]string{
+			"interface": iface,
+			"protocol":  protocol,
+		}
+		fields := map[string]interface{}{
+			"packets": stats.packets,
+			"bytes":   stats.bytes,
+		}
+		acc.AddFields("packet_capture_protocol", fields, tags)
+	}
+
+	// Report the flows with the highest byte count
+	talkers := make([]flowKey, 0, len(r.flows))
+	for k := range r.flows {
+		talkers = append(talkers, k)
+	}
+	sort.Slice(talkers, func(i, j int) bool {
+		bi, bj := r.flows[talkers[i]].bytes, r.flows[talkers[j]].bytes
+		if bi != bj {
+			return bi > bj
+		}
+		return talkers[i].less(talkers[j])
+	})
+	if len(talkers) > p.TopTalkers {
+		talkers = talkers[:p.TopTalkers]
+	}
+	for _, k := range talkers {
+		stats := r.flows[k]
+		tags := map[string]string{
+			"interface": iface,
+			"src":       k.src,
+			"dst":       k.dst,
+			"protocol":  k.protocol,
+		}
+		fields := map[string]interface{}{
+			"packets": stats.packets,
+			"bytes":   stats.bytes,
+		}
+		acc.AddFields("packet_capture_talker", fields, tags)
+	}
+}
+
+func init() {
+	inputs.Add("packet_capture", func() telegraf.Input {
+		return &PacketCapture{
+			SnapLen:          128,
+			TopTalkers:       10,
+			HandshakeTimeout: config.Duration(10 * time.Second),
+		}
+	})
+}
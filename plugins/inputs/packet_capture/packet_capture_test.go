@@ -0,0 +1,417 @@
+package packet_capture
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *PacketCapture
+		expected string
+	}{
+		{
+			name:     "no interfaces",
+			plugin:   &PacketCapture{SnapLen: 128, HandshakeTimeout: config.Duration(time.Second)},
+			expected: "no interfaces specified",
+		},
+		{
+			name: "small snaplen",
+			plugin: &PacketCapture{
+				Interfaces:       []string{"eth0"},
+				SnapLen:          10,
+				HandshakeTimeout: config.Duration(time.Second),
+			},
+			expected: "invalid snaplen 10",
+		},
+		{
+			name: "duplicate interface",
+			plugin: &PacketCapture{
+				Interfaces:       []string{"eth0", "eth0"},
+				SnapLen:          128,
+				HandshakeTimeout: config.Duration(time.Second),
+			},
+			expected: `duplicate interface "eth0"`,
+		},
+		{
+			name: "invalid filter",
+			plugin: &PacketCapture{
+				Interfaces:       []string{"eth0"},
+				BPFFilter:        "2\n6 0 0 0",
+				SnapLen:          128,
+				HandshakeTimeout: config.Duration(time.Second),
+			},
+			expected: "expected 2 instructions but got 1",
+		},
+		{
+			name: "invalid filter expression",
+			plugin: &PacketCapture{
+				Interfaces:       []string{"eth0"},
+				BPFFilter:        "tcp port https",
+				SnapLen:          128,
+				HandshakeTimeout: config.Duration(time.Second),
+			},
+			expected: `invalid port "https"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParseBPF(t *testing.T) {
+	expected := []bpf.RawInstruction{
+		{Op: 40, Jt: 0, Jf: 0, K: 12},
+		{Op: 21, Jt: 0, Jf: 1, K: 2048},
+		{Op: 6, Jt: 0, Jf: 0, K: 262144},
+		{Op: 6, Jt: 0, Jf: 0, K: 0},
+	}
+
+	// Output format of tcpdump
+	actual, err := parseBPF("4\n40 0 0 12\n21 0 1 2048\n6 0 0 262144\n6 0 0 0\n")
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	// Comma separated format
+	actual, err = parseBPF("4,40 0 0 12,21 0 1 2048,6 0 0 262144,6 0 0 0")
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	_, err = parseBPF("1\n40 0 12")
+	require.ErrorContains(t, err, "expected four values")
+
+	_, err = parseBPF("1\n40 0 256 12")
+	require.ErrorContains(t, err, "invalid jump-false offset")
+
+	// Programs are detected by the leading instruction count
+	actual, err = compileFilter("4\n40 0 0 12\n21 0 1 2048\n6 0 0 262144\n6 0 0 0\n")
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestCompileFilter(t *testing.T) {
+	packets := map[string][]byte{
+		"https":   tcpPacket(t, "10.0.0.1", "10.0.0.2", 40000, 443, true, false, 0),
+		"http":    tcpPacket(t, "10.0.0.1", "10.0.1.5", 40001, 80, true, false, 0),
+		"options": tcpOptionsPacket(t, "10.0.0.2", "10.0.0.1", 443, 40000),
+		"dns":     udpPacket(t, "10.0.0.3", "10.0.0.4", 5000, 53, 58),
+		"arp":     arpPacket(t),
+		"icmp":    icmpPacket(t, "10.0.0.1", "192.168.1.1"),
+		"ipv6":    tcp6Packet(t, "fd00::1", "fd00:1::2", 40000, 443),
+	}
+
+	tests := []struct {
+		filter   string
+		expected []string
+	}{
+		{filter: "ip", expected: []string{"https", "http", "options", "dns", "icmp"}},
+		{filter: "ip6", expected: []string{"ipv6"}},
+		{filter: "arp", expected: []string{"arp"}},
+		{filter: "tcp", expected: []string{"https", "http", "options", "ipv6"}},
+		{filter: "udp or icmp", expected: []string{"dns", "icmp"}},
+		{filter: "port 443", expected: []string{"https", "options", "ipv6"}},
+		{filter: "tcp dst port 443", expected: []string{"https", "ipv6"}},
+		{filter: "ip and tcp src port 443", expected: []string{"options"}},
+		{filter: "udp port 443", expected: nil},
+		{filter: "portrange 50-100", expected: []string{"http", "dns"}},
+		{filter: "host 10.0.0.1", expected: []string{"https", "http", "options", "arp", "icmp"}},
+		{filter: "src host 10.0.0.1", expected: []string{"https", "http", "arp", "icmp"}},
+		{filter: "dst 10.0.0.1", expected: []string{"options"}},
+		{filter: "src and dst net 10.0.0.0/24", expected: []string{"https", "options", "dns", "arp"}},
+		{filter: "ip net 10.0.0.0/8 and not port 53", expected: []string{"https", "http", "options", "icmp"}},
+		{filter: "arp host 10.0.0.2", expected: []string{"arp"}},
+		{filter: "ip host 10.0.0.2", expected: []string{"https", "options"}},
+		{filter: "ip6 dst net fd00:1::/32", expected: []string{"ipv6"}},
+		{filter: "src net fd00::/16 && (port 80 || port 443)", expected: []string{"ipv6"}},
+		{filter: "!(tcp or udp)", expected: []string{"arp", "icmp"}},
+		{filter: "not ip and not ip6", expected: []string{"arp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			raw, err := compileFilter(tt.filter)
+			require.NoError(t, err)
+
+			program := make([]bpf.Instruction, 0, len(raw))
+			for _, r := range raw {
+				program = append(program, r.Disassemble())
+			}
+			vm, err := bpf.NewVM(program)
+			require.NoError(t, err)
+
+			var actual []string
+			for name, data := range packets {
+				n, err := vm.Run(data)
+				require.NoError(t, err)
+				if n > 0 {
+					actual = append(actual, name)
+				}
+			}
+			require.ElementsMatch(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestCompileFilterFail(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+	}{
+		{filter: "", expected: "empty filter expression"},
+		{filter: "host example.com", expected: "only IP addresses are supported"},
+		{filter: "net 10.0.0.0", expected: "invalid network"},
+		{filter: "port 70000", expected: "invalid port"},
+		{filter: "portrange 100-50", expected: "invalid port range"},
+		{filter: "icmp port 53", expected: "not supported for port"},
+		{filter: "ip6 host 10.0.0.1", expected: "not supported for host"},
+		{filter: "ether host 02:00:00:00:00:01", expected: `unsupported filter primitive "ether"`},
+		{filter: "(tcp or udp", expected: "missing closing parenthesis"},
+		{filter: "tcp and", expected: "unexpected end of filter expression"},
+		{filter: "tcp udp", expected: `unexpected "udp"`},
+		{filter: "port", expected: `missing value for "port"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := compileFilter(tt.filter)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	plugin := &PacketCapture{
+		Interfaces:       []string{"eth0"},
+		SnapLen:          128,
+		TopTalkers:       2,
+		HandshakeTimeout: config.Duration(10 * time.Second),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Simulate the captured packets, note that ethernet frames are padded
+	// to 60 bytes
+	t0 := time.Now().Add(-time.Minute)
+	packets := []struct {
+		data []byte
+		ts   time.Time
+	}{
+		{tcpPacket(t, "10.0.0.1", "10.0.0.2", 40000, 443, true, false, 0), t0},
+		{tcpPacket(t, "10.0.0.2", "10.0.0.1", 443, 40000, true, true, 0), t0.Add(5 * time.Millisecond)},
+		{tcpPacket(t, "10.0.0.2", "10.0.0.1", 443, 40000, false, true, 946), t0.Add(6 * time.Millisecond)},
+		{udpPacket(t, "10.0.0.3", "10.0.0.4", 5000, 53, 58), t0},
+		{tcpPacket(t, "10.0.0.1", "10.0.0.5", 40001, 80, true, false, 0), t0},
+		{arpPacket(t), t0},
+	}
+	s := plugin.summaries["eth0"]
+	for _, p := range packets {
+		s.add(p.data, len(p.data), p.ts)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"packet_capture",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{
+				"packets":            uint64(6),
+				"bytes":              uint64(1340),
+				"dropped":            uint64(0),
+				"undecoded":          uint64(0),
+				"handshakes":         uint64(1),
+				"handshake_timeouts": uint64(1),
+				"handshake_min_ms":   float64(5),
+				"handshake_max_ms":   float64(5),
+				"handshake_mean_ms":  float64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"packet_capture_protocol",
+			map[string]string{"interface": "eth0", "protocol": "tcp"},
+			map[string]interface{}{"packets": uint64(4), "bytes": uint64(1180)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"packet_capture_protocol",
+			map[string]string{"interface": "eth0", "protocol": "udp"},
+			map[string]interface{}{"packets": uint64(1), "bytes": uint64(100)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"packet_capture_protocol",
+			map[string]string{"interface": "eth0", "protocol": "arp"},
+			map[string]interface{}{"packets": uint64(1), "bytes": uint64(60)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"packet_capture_talker",
+			map[string]string{"interface": "eth0", "src": "10.0.0.2", "dst": "10.0.0.1", "protocol": "tcp"},
+			map[string]interface{}{"packets": uint64(2), "bytes": uint64(1060)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"packet_capture_talker",
+			map[string]string{"interface": "eth0", "src": "10.0.0.3", "dst": "10.0.0.4", "protocol": "udp"},
+			map[string]interface{}{"packets": uint64(1), "bytes": uint64(100)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The counters should be reset after gathering
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	expected = []telegraf.Metric{
+		metric.New(
+			"packet_capture",
+			map[string]string{"interface": "eth0"},
+			map[string]interface{}{
+				"packets":            uint64(0),
+				"bytes":              uint64(0),
+				"dropped":            uint64(0),
+				"undecoded":          uint64(0),
+				"handshakes":         uint64(0),
+				"handshake_timeouts": uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+var (
+	srcMAC = net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	dstMAC = net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}
+)
+
+func serialize(t *testing.T, l ...gopacket.SerializableLayer) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, l...))
+	return buf.Bytes()
+}
+
+func tcpPacket(t *testing.T, src, dst string, sport, dport uint16, syn, ack bool, size int) []byte {
+	eth := &layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolTCP,
+		SrcIP:    net.ParseIP(src).To4(),
+		DstIP:    net.ParseIP(dst).To4(),
+	}
+	tcp := &layers.TCP{
+		SrcPort: layers.TCPPort(sport),
+		DstPort: layers.TCPPort(dport),
+		SYN:     syn,
+		ACK:     ack,
+		Window:  64240,
+	}
+	return serialize(t, eth, ip, tcp, gopacket.Payload(make([]byte, size)))
+}
+
+func udpPacket(t *testing.T, src, dst string, sport, dport uint16, size int) []byte {
+	eth := &layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    net.ParseIP(src).To4(),
+		DstIP:    net.ParseIP(dst).To4(),
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(sport),
+		DstPort: layers.UDPPort(dport),
+	}
+	return serialize(t, eth, ip, udp, gopacket.Payload(make([]byte, size)))
+}
+
+func tcpOptionsPacket(t *testing.T, src, dst string, sport, dport uint16) []byte {
+	eth := &layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolTCP,
+		SrcIP:    net.ParseIP(src).To4(),
+		DstIP:    net.ParseIP(dst).To4(),
+		Options: []layers.IPv4Option{
+			{OptionType: 148, OptionLength: 4, OptionData: []byte{0, 0}}, // router alert
+		},
+	}
+	tcp := &layers.TCP{
+		SrcPort: layers.TCPPort(sport),
+		DstPort: layers.TCPPort(dport),
+		ACK:     true,
+		Window:  64240,
+	}
+	return serialize(t, eth, ip, tcp)
+}
+
+func tcp6Packet(t *testing.T, src, dst string, sport, dport uint16) []byte {
+	eth := &layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv6}
+	ip := &layers.IPv6{
+		Version:    6,
+		HopLimit:   64,
+		NextHeader: layers.IPProtocolTCP,
+		SrcIP:      net.ParseIP(src),
+		DstIP:      net.ParseIP(dst),
+	}
+	tcp := &layers.TCP{
+		SrcPort: layers.TCPPort(sport),
+		DstPort: layers.TCPPort(dport),
+		SYN:     true,
+		Window:  64240,
+	}
+	return serialize(t, eth, ip, tcp)
+}
+
+func icmpPacket(t *testing.T, src, dst string) []byte {
+	eth := &layers.Ethernet{SrcMAC: srcMAC, DstMAC: dstMAC, EthernetType: layers.EthernetTypeIPv4}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolICMPv4,
+		SrcIP:    net.ParseIP(src).To4(),
+		DstIP:    net.ParseIP(dst).To4(),
+	}
+	icmp := &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0)}
+	return serialize(t, eth, ip, icmp)
+}
+
+func arpPacket(t *testing.T) []byte {
+	eth := &layers.Ethernet{
+		SrcMAC:       srcMAC,
+		DstMAC:       net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		EthernetType: layers.EthernetTypeARP,
+	}
+	arp := &layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     6,
+		ProtAddressSize:   4,
+		Operation:         layers.ARPRequest,
+		SourceHwAddress:   srcMAC,
+		SourceProtAddress: []byte{10, 0, 0, 1},
+		DstHwAddress:      []byte{0, 0, 0, 0, 0, 0},
+		DstProtAddress:    []byte{10, 0, 0, 2},
+	}
+	return serialize(t, eth, arp)
+}
@@ -0,0 +1,207 @@
+package packet_capture
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// Limit the number of tracked handshakes to bound memory during SYN floods
+const maxPendingHandshakes = 100000
+
+type flowKey struct {
+	src      string
+	dst      string
+	protocol string
+}
+
+func (k flowKey) less(other flowKey) bool {
+	if k.src != other.src {
+		return k.src < other.src
+	}
+	if k.dst != other.dst {
+		return k.dst < other.dst
+	}
+	return k.protocol < other.protocol
+}
+
+type handshakeKey struct {
+	client string
+	server string
+}
+
+type counter struct {
+	packets uint64
+	bytes   uint64
+}
+
+// report contains the statistics of a single interval
+type report struct {
+	packets   uint64
+	bytes     uint64
+	undecoded uint64
+	protocols map[string]*counter
+	flows     map[flowKey]*counter
+
+	handshakes        uint64
+	handshakeTimeouts uint64
+	handshakeMin      time.Duration
+	handshakeMax      time.Duration
+	handshakeSum      time.Duration
+}
+
+func newReport() *report {
+	return &report{
+		protocols: make(map[string]*counter),
+		flows:     make(map[flowKey]*counter),
+	}
+}
+
+// summary accumulates the captured packets of an interface
+type summary struct {
+	timeout time.Duration
+
+	// Decoding layers, reused for all packets
+	parser  *gopacket.DecodingLayerParser
+	eth     layers.Ethernet
+	dot1q   layers.Dot1Q
+	ip4     layers.IPv4
+	ip6     layers.IPv6
+	tcp     layers.TCP
+	udp     layers.UDP
+	decoded []gopacket.LayerType
+
+	current *report
+	pending map[handshakeKey]time.Time
+	sync.Mutex
+}
+
+func newSummary(timeout time.Duration) *summary {
+	s := &summary{
+		timeout: timeout,
+		current: newReport(),
+		pending: make(map[handshakeKey]time.Time),
+	}
+	s.parser = gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &s.eth, &s.dot1q, &s.ip4, &s.ip6, &s.tcp, &s.udp)
+	s.parser.IgnoreUnsupported = true
+	return s
+}
+
+// add accounts the given packet data with the original packet length
+func (s *summary) add(data []byte, length int, ts time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	r := s.current
+	r.packets++
+	r.bytes += uint64(length)
+
+	// Truncated packets cause errors for the last layer, so ignore
+	// the error and use what could be decoded.
+	_ = s.parser.DecodeLayers(data, &s.decoded)
+
+	var src, dst net.IP
+	var protocol string
+	var tcp *layers.TCP
+	for _, layer := range s.decoded {
+		switch layer {
+		case layers.LayerTypeEthernet:
+			protocol = strings.ToLower(s.eth.EthernetType.String())
+		case layers.LayerTypeDot1Q:
+			protocol = strings.ToLower(s.dot1q.Type.String())
+		case layers.LayerTypeIPv4:
+			src, dst = s.ip4.SrcIP, s.ip4.DstIP
+			protocol = strings.ToLower(s.ip4.Protocol.String())
+		case layers.LayerTypeIPv6:
+			src, dst = s.ip6.SrcIP, s.ip6.DstIP
+			protocol = strings.ToLower(s.ip6.NextHeader.String())
+		case layers.LayerTypeTCP:
+			tcp = &s.tcp
+		}
+	}
+	if protocol == "" {
+		r.undecoded++
+		return
+	}
+
+	c, found := r.protocols[protocol]
+	if !found {
+		c = &counter{}
+		r.protocols[protocol] = c
+	}
+	c.packets++
+	c.bytes += uint64(length)
+
+	if src == nil {
+		return
+	}
+	k := flowKey{src: src.String(), dst: dst.String(), protocol: protocol}
+	c, found = r.flows[k]
+	if !found {
+		c = &counter{}
+		r.flows[k] = c
+	}
+	c.packets++
+	c.bytes += uint64(length)
+
+	if tcp != nil && tcp.SYN {
+		s.trackHandshake(src, dst, tcp, ts)
+	}
+}
+
+// trackHandshake measures the time between the SYN packet of a client and
+// the SYN-ACK packet of the server
+func (s *summary) trackHandshake(src, dst net.IP, tcp *layers.TCP, ts time.Time) {
+	srcAddr := net.JoinHostPort(src.String(), strconv.Itoa(int(tcp.SrcPort)))
+	dstAddr := net.JoinHostPort(dst.String(), strconv.Itoa(int(tcp.DstPort)))
+
+	if !tcp.ACK {
+		// Keep the first SYN in case of retransmissions
+		k := handshakeKey{client: srcAddr, server: dstAddr}
+		if _, found := s.pending[k]; !found && len(s.pending) < maxPendingHandshakes {
+			s.pending[k] = ts
+		}
+		return
+	}
+
+	k := handshakeKey{client: dstAddr, server: srcAddr}
+	start, found := s.pending[k]
+	if !found {
+		return
+	}
+	delete(s.pending, k)
+
+	r := s.current
+	latency := ts.Sub(start)
+	if r.handshakes == 0 || latency < r.handshakeMin {
+		r.handshakeMin = latency
+	}
+	if latency > r.handshakeMax {
+		r.handshakeMax = latency
+	}
+	r.handshakeSum += latency
+	r.handshakes++
+}
+
+// reset returns the statistics of the current interval and starts a new one.
+// Pending handshakes older than the timeout are counted as timed out.
+func (s *summary) reset(now time.Time) *report {
+	s.Lock()
+	defer s.Unlock()
+
+	r := s.current
+	for k, start := range s.pending {
+		if now.Sub(start) > s.timeout {
+			delete(s.pending, k)
+			r.handshakeTimeouts++
+		}
+	}
+	s.current = newReport()
+
+	return r
+}
@@ -0,0 +1,224 @@
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Version of the libpod REST API used by the plugin
+const apiVersion = "v4.0.0"
+
+type systemInfo struct {
+	Host struct {
+		Hostname string `json:"hostname"`
+		Security struct {
+			Rootless bool `json:"rootless"`
+		} `json:"security"`
+	} `json:"host"`
+	Store struct {
+		ContainerStore struct {
+			Number  int64 `json:"number"`
+			Paused  int64 `json:"paused"`
+			Running int64 `json:"running"`
+			Stopped int64 `json:"stopped"`
+		} `json:"containerStore"`
+		ImageStore struct {
+			Number int64 `json:"number"`
+		} `json:"imageStore"`
+	} `json:"store"`
+	Version struct {
+		Version string `json:"Version"`
+	} `json:"version"`
+}
+
+type container struct {
+	ID        string            `json:"Id"`
+	Names     []string          `json:"Names"`
+	Image     string            `json:"Image"`
+	State     string            `json:"State"`
+	Pod       string            `json:"Pod"`
+	PodName   string            `json:"PodName"`
+	Labels    map[string]string `json:"Labels"`
+	Pid       int64             `json:"Pid"`
+	ExitCode  int64             `json:"ExitCode"`
+	StartedAt int64             `json:"StartedAt"`
+	ExitedAt  int64             `json:"ExitedAt"`
+}
+
+type containerStats struct {
+	ContainerID   string  `json:"ContainerID"`
+	CPU           float64 `json:"CPU"`
+	CPUNano       uint64  `json:"CPUNano"`
+	CPUSystemNano uint64  `json:"CPUSystemNano"`
+	MemUsage      uint64  `json:"MemUsage"`
+	MemLimit      uint64  `json:"MemLimit"`
+	MemPerc       float64 `json:"MemPerc"`
+	NetInput      uint64  `json:"NetInput"`
+	NetOutput     uint64  `json:"NetOutput"`
+	BlockInput    uint64  `json:"BlockInput"`
+	BlockOutput   uint64  `json:"BlockOutput"`
+	PIDs          uint64  `json:"PIDs"`
+	UpTime        int64   `json:"UpTime"`
+}
+
+type statsReport struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"Error"`
+	Stats []containerStats `json:"Stats"`
+}
+
+type pod struct {
+	ID         string `json:"Id"`
+	Name       string `json:"Name"`
+	Status     string `json:"Status"`
+	Containers []struct {
+		ID     string `json:"Id"`
+		Status string `json:"Status"`
+	} `json:"Containers"`
+}
+
+type diskUsage struct {
+	Images []struct {
+		Repository string `json:"Repository"`
+		Tag        string `json:"Tag"`
+		ImageID    string `json:"ImageID"`
+		Size       int64  `json:"Size"`
+		SharedSize int64  `json:"SharedSize"`
+		Containers int64  `json:"Containers"`
+	} `json:"Images"`
+	Containers []struct {
+		ContainerID string `json:"ContainerID"`
+		Image       string `json:"Image"`
+		Names       string `json:"Names"`
+		Size        int64  `json:"Size"`
+		RWSize      int64  `json:"RWSize"`
+	} `json:"Containers"`
+	Volumes []struct {
+		VolumeName string `json:"VolumeName"`
+		Links      int64  `json:"Links"`
+		Size       int64  `json:"Size"`
+	} `json:"Volumes"`
+}
+
+// client talks to the libpod REST API via a unix socket or TCP
+type client struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newClient(endpoint string, timeout time.Duration) (*client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing endpoint failed: %w", err)
+	}
+
+	transport := &http.Transport{}
+	var baseURL string
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		baseURL = "http://d"
+	case "tcp", "http":
+		baseURL = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	return &client{
+		baseURL: baseURL + "/" + apiVersion + "/libpod",
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}, nil
+}
+
+func (c *client) info(ctx context.Context) (*systemInfo, error) {
+	var v systemInfo
+	return &v, c.get(ctx, "/info", nil, &v)
+}
+
+func (c *client) containers(ctx context.Context) ([]container, error) {
+	var v []container
+	return v, c.get(ctx, "/containers/json", url.Values{"all": []string{"true"}}, &v)
+}
+
+func (c *client) stats(ctx context.Context, ids []string) ([]containerStats, error) {
+	params := url.Values{
+		"stream":     []string{"false"},
+		"containers": ids,
+	}
+	var v statsReport
+	if err := c.get(ctx, "/containers/stats", params, &v); err != nil {
+		return nil, err
+	}
+	if v.Error != nil {
+		return nil, fmt.Errorf("getting stats failed: %s", v.Error.Message)
+	}
+	return v.Stats, nil
+}
+
+func (c *client) pods(ctx context.Context) ([]pod, error) {
+	var v []pod
+	return v, c.get(ctx, "/pods/json", nil, &v)
+}
+
+func (c *client) diskUsage(ctx context.Context) (*diskUsage, error) {
+	var v diskUsage
+	return &v, c.get(ctx, "/system/df", nil, &v)
+}
+
+func (c *client) get(ctx context.Context, path string, params url.Values, v interface{}) error {
+	u := c.baseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Message != "" {
+			return fmt.Errorf("request to %q failed with status %s: %s", path, resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("request to %q failed with status %s", path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding response of %q failed: %w", path, err)
+	}
+	return nil
+}
+
+// defaultEndpoint returns the socket of the rootful or, if running as a
+// regular user, the rootless Podman service
+func defaultEndpoint(uid int, runtimeDir string) string {
+	if uid == 0 {
+		return "unix:///run/podman/podman.sock"
+	}
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", uid)
+	}
+	return "unix://" + strings.TrimSuffix(runtimeDir, "/") + "/podman/podman.sock"
+}
@@ -0,0 +1,327 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package podman
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	dockerint "github.com/influxdata/telegraf/internal/docker"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Podman struct {
+	Endpoint              string          `toml:"endpoint"`
+	Timeout               config.Duration `toml:"timeout"`
+	ContainerInclude      []string        `toml:"container_name_include"`
+	ContainerExclude      []string        `toml:"container_name_exclude"`
+	ContainerStateInclude []string        `toml:"container_state_include"`
+	ContainerStateExclude []string        `toml:"container_state_exclude"`
+	LabelInclude          []string        `toml:"podman_label_include"`
+	LabelExclude          []string        `toml:"podman_label_exclude"`
+	GatherPods            bool            `toml:"gather_pods"`
+	StorageObjects        []string        `toml:"storage_objects"`
+	Log                   telegraf.Logger `toml:"-"`
+
+	containerFilter filter.Filter
+	stateFilter     filter.Filter
+	labelFilter     filter.Filter
+
+	client *client
+}
+
+func (*Podman) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Podman) Init() error {
+	if p.Endpoint == "" {
+		p.Endpoint = defaultEndpoint(os.Geteuid(), os.Getenv("XDG_RUNTIME_DIR"))
+		p.Log.Debugf("Using endpoint %q", p.Endpoint)
+	}
+
+	if err := choice.CheckSlice(p.StorageObjects, []string{"container", "image", "volume"}); err != nil {
+		return fmt.Errorf("invalid storage_objects: %w", err)
+	}
+
+	var err error
+	p.containerFilter, err = filter.NewIncludeExcludeFilter(p.ContainerInclude, p.ContainerExclude)
+	if err != nil {
+		return fmt.Errorf("creating container filter failed: %w", err)
+	}
+	if len(p.ContainerStateInclude) == 0 && len(p.ContainerStateExclude) == 0 {
+		p.ContainerStateInclude = []string{"running"}
+	}
+	p.stateFilter, err = filter.NewIncludeExcludeFilter(p.ContainerStateInclude, p.ContainerStateExclude)
+	if err != nil {
+		return fmt.Errorf("creating container state filter failed: %w", err)
+	}
+	p.labelFilter, err = filter.NewIncludeExcludeFilter(p.LabelInclude, p.LabelExclude)
+	if err != nil {
+		return fmt.Errorf("creating label filter failed: %w", err)
+	}
+
+	p.client, err = newClient(p.Endpoint, time.Duration(p.Timeout))
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Podman) Gather(acc telegraf.Accumulator) error {
+	ctx := context.Background()
+
+	info, err := p.client.info(ctx)
+	if err != nil {
+		return fmt.Errorf("getting info failed: %w", err)
+	}
+	baseTags := map[string]string{
+		"engine_host":    info.Host.Hostname,
+		"server_version": info.Version.Version,
+	}
+
+	fields := map[string]interface{}{
+		"n_containers":         info.Store.ContainerStore.Number,
+		"n_containers_running": info.Store.ContainerStore.Running,
+		"n_containers_stopped": info.Store.ContainerStore.Stopped,
+		"n_containers_paused":  info.Store.ContainerStore.Paused,
+		"n_images":             info.Store.ImageStore.Number,
+		"rootless":             info.Host.Security.Rootless,
+	}
+
+	if p.GatherPods {
+		pods, err := p.client.pods(ctx)
+		if err != nil {
+			acc.AddError(fmt.Errorf("listing pods failed: %w", err))
+		} else {
+			fields["n_pods"] = len(pods)
+			p.gatherPods(acc, pods, baseTags)
+		}
+	}
+	acc.AddFields("podman", fields, baseTags)
+
+	if err := p.gatherContainers(ctx, acc, baseTags); err != nil {
+		acc.AddError(err)
+	}
+
+	if len(p.StorageObjects) > 0 {
+		if err := p.gatherDiskUsage(ctx, acc, baseTags); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Podman) gatherPods(acc telegraf.Accumulator, pods []pod, baseTags map[string]string) {
+	for _, pd := range pods {
+		tags := copyTags(baseTags)
+		tags["pod_name"] = pd.Name
+		tags["pod_id"] = pd.ID
+		tags["pod_status"] = strings.ToLower(pd.Status)
+
+		var running int
+		for _, c := range pd.Containers {
+			if strings.EqualFold(c.Status, "running") {
+				running++
+			}
+		}
+		acc.AddFields("podman_pod", map[string]interface{}{
+			"n_containers":         len(pd.Containers),
+			"n_containers_running": running,
+		}, tags)
+	}
+}
+
+func (p *Podman) gatherContainers(ctx context.Context, acc telegraf.Accumulator, baseTags map[string]string) error {
+	containers, err := p.client.containers(ctx)
+	if err != nil {
+		return fmt.Errorf("listing containers failed: %w", err)
+	}
+
+	type entry struct {
+		tags   map[string]string
+		status map[string]interface{}
+	}
+	selected := make(map[string]entry)
+	order := make([]string, 0, len(containers))
+	running := make([]string, 0, len(containers))
+	for _, c := range containers {
+		name := ""
+		if len(c.Names) > 0 {
+			name = c.Names[0]
+		}
+		if !p.containerFilter.Match(name) || !p.stateFilter.Match(c.State) {
+			continue
+		}
+
+		imageName, imageVersion := dockerint.ParseImage(c.Image)
+		tags := copyTags(baseTags)
+		tags["container_name"] = name
+		tags["container_image"] = imageName
+		tags["container_version"] = imageVersion
+		tags["container_status"] = c.State
+		if c.PodName != "" {
+			tags["pod_name"] = c.PodName
+		}
+		for k, v := range c.Labels {
+			if p.labelFilter.Match(k) {
+				tags[k] = v
+			}
+		}
+
+		status := map[string]interface{}{
+			"container_id": c.ID,
+			"pid":          c.Pid,
+			"exitcode":     c.ExitCode,
+			"started_at":   c.StartedAt * int64(time.Second),
+		}
+		if c.ExitedAt > 0 {
+			status["finished_at"] = c.ExitedAt * int64(time.Second)
+		}
+		selected[c.ID] = entry{tags: tags, status: status}
+		order = append(order, c.ID)
+		if c.State == "running" {
+			running = append(running, c.ID)
+		}
+	}
+
+	// Statistics are only available for running containers
+	var stats []containerStats
+	if len(running) > 0 {
+		stats, err = p.client.stats(ctx, running)
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting container stats failed: %w", err))
+		}
+	}
+
+	now := time.Now()
+	for _, s := range stats {
+		e, found := selected[s.ContainerID]
+		if !found {
+			continue
+		}
+		tags := e.tags
+		e.status["pids"] = s.PIDs
+		e.status["uptime_ns"] = s.UpTime
+
+		acc.AddFields("podman_container_mem", map[string]interface{}{
+			"usage":         s.MemUsage,
+			"limit":         s.MemLimit,
+			"usage_percent": s.MemPerc,
+			"container_id":  s.ContainerID,
+		}, tags, now)
+
+		cpuTags := copyTags(tags)
+		cpuTags["cpu"] = "cpu-total"
+		acc.AddFields("podman_container_cpu", map[string]interface{}{
+			"usage_total":         s.CPUNano,
+			"usage_in_kernelmode": s.CPUSystemNano,
+			"usage_percent":       s.CPU,
+			"container_id":        s.ContainerID,
+		}, cpuTags, now)
+
+		netTags := copyTags(tags)
+		netTags["network"] = "total"
+		acc.AddFields("podman_container_net", map[string]interface{}{
+			"rx_bytes":     s.NetInput,
+			"tx_bytes":     s.NetOutput,
+			"container_id": s.ContainerID,
+		}, netTags, now)
+
+		blkioTags := copyTags(tags)
+		blkioTags["device"] = "total"
+		acc.AddFields("podman_container_blkio", map[string]interface{}{
+			"io_service_bytes_recursive_read":  s.BlockInput,
+			"io_service_bytes_recursive_write": s.BlockOutput,
+			"container_id":                     s.ContainerID,
+		}, blkioTags, now)
+	}
+
+	for _, id := range order {
+		acc.AddFields("podman_container_status", selected[id].status, selected[id].tags, now)
+	}
+
+	return nil
+}
+
+func (p *Podman) gatherDiskUsage(ctx context.Context, acc telegraf.Accumulator, baseTags map[string]string) error {
+	du, err := p.client.diskUsage(ctx)
+	if err != nil {
+		return fmt.Errorf("getting disk usage failed: %w", err)
+	}
+
+	if choice.Contains("container", p.StorageObjects) {
+		for _, c := range du.Containers {
+			if !p.containerFilter.Match(c.Names) {
+				continue
+			}
+			imageName, imageVersion := dockerint.ParseImage(c.Image)
+			tags := copyTags(baseTags)
+			tags["container_name"] = c.Names
+			tags["container_image"] = imageName
+			tags["container_version"] = imageVersion
+			acc.AddFields("podman_disk_usage", map[string]interface{}{
+				"size_rw":      c.RWSize,
+				"size_root_fs": c.Size,
+			}, tags)
+		}
+	}
+
+	if choice.Contains("image", p.StorageObjects) {
+		for _, img := range du.Images {
+			tags := copyTags(baseTags)
+			tags["image_id"] = img.ImageID
+			if img.Repository != "" && img.Repository != "<none>" {
+				tags["image_name"] = img.Repository
+				tags["image_version"] = img.Tag
+			}
+			acc.AddFields("podman_disk_usage", map[string]interface{}{
+				"size":        img.Size,
+				"shared_size": img.SharedSize,
+				"containers":  img.Containers,
+			}, tags)
+		}
+	}
+
+	if choice.Contains("volume", p.StorageObjects) {
+		for _, v := range du.Volumes {
+			tags := copyTags(baseTags)
+			tags["volume_name"] = v.VolumeName
+			acc.AddFields("podman_disk_usage", map[string]interface{}{
+				"size":  v.Size,
+				"links": v.Links,
+			}, tags)
+		}
+	}
+
+	return nil
+}
+
+func copyTags(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+func init() {
+	inputs.Add("podman", func() telegraf.Input {
+		return &Podman{
+			Timeout:    config.Duration(5 * time.Second),
+			GatherPods: true,
+		}
+	})
+}
@@ -0,0 +1,394 @@
+package podman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestDefaultEndpoint(t *testing.T) {
+	require.Equal(t, "unix:///run/podman/podman.sock", defaultEndpoint(0, "/run/user/0"))
+	require.Equal(t, "unix:///run/user/1000/podman/podman.sock", defaultEndpoint(1000, ""))
+	require.Equal(t, "unix:///tmp/xdg/podman/podman.sock", defaultEndpoint(1000, "/tmp/xdg/"))
+}
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &Podman{
+		Endpoint:       "unix:///run/podman/podman.sock",
+		StorageObjects: []string{"network"},
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid storage_objects")
+
+	plugin = &Podman{
+		Endpoint: "ssh://host/run/podman/podman.sock",
+		Log:      testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `unsupported scheme "ssh"`)
+}
+
+func TestGather(t *testing.T) {
+	endpoint := startServer(t)
+
+	plugin := &Podman{
+		Endpoint:   endpoint,
+		GatherPods: true,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	base := map[string]string{
+		"engine_host":    "podhost",
+		"server_version": "4.9.3",
+	}
+	web := withTags(base, map[string]string{
+		"container_name":    "web",
+		"container_image":   "docker.io/library/nginx",
+		"container_version": "1.25",
+		"container_status":  "running",
+		"pod_name":          "shop",
+		"app":               "web",
+	})
+	db := withTags(base, map[string]string{
+		"container_name":    "db",
+		"container_image":   "docker.io/library/postgres",
+		"container_version": "16",
+		"container_status":  "running",
+	})
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"podman",
+			base,
+			map[string]interface{}{
+				"n_containers":         int64(3),
+				"n_containers_running": int64(2),
+				"n_containers_stopped": int64(1),
+				"n_containers_paused":  int64(0),
+				"n_images":             int64(2),
+				"n_pods":               int64(1),
+				"rootless":             true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_pod",
+			withTags(base, map[string]string{
+				"pod_name":   "shop",
+				"pod_id":     "p1",
+				"pod_status": "running",
+			}),
+			map[string]interface{}{
+				"n_containers":         int64(3),
+				"n_containers_running": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_status",
+			web,
+			map[string]interface{}{
+				"container_id": "a1b2c3",
+				"pid":          int64(4242),
+				"exitcode":     int64(0),
+				"started_at":   int64(1700000000000000000),
+				"pids":         uint64(3),
+				"uptime_ns":    int64(60000000000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_mem",
+			web,
+			map[string]interface{}{
+				"usage":         uint64(10485760),
+				"limit":         uint64(1073741824),
+				"usage_percent": float64(0.9765625),
+				"container_id":  "a1b2c3",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_cpu",
+			withTags(web, map[string]string{"cpu": "cpu-total"}),
+			map[string]interface{}{
+				"usage_total":         uint64(123456789),
+				"usage_in_kernelmode": uint64(23456789),
+				"usage_percent":       float64(1.5),
+				"container_id":        "a1b2c3",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_net",
+			withTags(web, map[string]string{"network": "total"}),
+			map[string]interface{}{
+				"rx_bytes":     uint64(2048),
+				"tx_bytes":     uint64(4096),
+				"container_id": "a1b2c3",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_blkio",
+			withTags(web, map[string]string{"device": "total"}),
+			map[string]interface{}{
+				"io_service_bytes_recursive_read":  uint64(8192),
+				"io_service_bytes_recursive_write": uint64(16384),
+				"container_id":                     "a1b2c3",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_status",
+			db,
+			map[string]interface{}{
+				"container_id": "d4e5f6",
+				"pid":          int64(4343),
+				"exitcode":     int64(0),
+				"started_at":   int64(1700000100000000000),
+				"pids":         uint64(7),
+				"uptime_ns":    int64(120000000000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_mem",
+			db,
+			map[string]interface{}{
+				"usage":         uint64(52428800),
+				"limit":         uint64(1073741824),
+				"usage_percent": float64(4.8828125),
+				"container_id":  "d4e5f6",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_cpu",
+			withTags(db, map[string]string{"cpu": "cpu-total"}),
+			map[string]interface{}{
+				"usage_total":         uint64(987654321),
+				"usage_in_kernelmode": uint64(87654321),
+				"usage_percent":       float64(0.5),
+				"container_id":        "d4e5f6",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_net",
+			withTags(db, map[string]string{"network": "total"}),
+			map[string]interface{}{
+				"rx_bytes":     uint64(100),
+				"tx_bytes":     uint64(200),
+				"container_id": "d4e5f6",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_container_blkio",
+			withTags(db, map[string]string{"device": "total"}),
+			map[string]interface{}{
+				"io_service_bytes_recursive_read":  uint64(300),
+				"io_service_bytes_recursive_write": uint64(400),
+				"container_id":                     "d4e5f6",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherExitedContainers(t *testing.T) {
+	endpoint := startServer(t)
+
+	plugin := &Podman{
+		Endpoint:              endpoint,
+		ContainerStateInclude: []string{"exited"},
+		Log:                   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"podman_container_status",
+			map[string]string{
+				"engine_host":       "podhost",
+				"server_version":    "4.9.3",
+				"container_name":    "job",
+				"container_image":   "localhost/job",
+				"container_version": "unknown",
+				"container_status":  "exited",
+			},
+			map[string]interface{}{
+				"container_id": "0a0b0c",
+				"pid":          int64(0),
+				"exitcode":     int64(1),
+				"started_at":   int64(1700000000000000000),
+				"finished_at":  int64(1700000060000000000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	actual := make([]telegraf.Metric, 0, len(expected))
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "podman_container_status" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestGatherDiskUsage(t *testing.T) {
+	endpoint := startServer(t)
+
+	plugin := &Podman{
+		Endpoint:       endpoint,
+		StorageObjects: []string{"container", "image", "volume"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	base := map[string]string{
+		"engine_host":    "podhost",
+		"server_version": "4.9.3",
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"podman_disk_usage",
+			withTags(base, map[string]string{
+				"container_name":    "web",
+				"container_image":   "docker.io/library/nginx",
+				"container_version": "1.25",
+			}),
+			map[string]interface{}{
+				"size_rw":      int64(4096),
+				"size_root_fs": int64(187004096),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_disk_usage",
+			withTags(base, map[string]string{
+				"image_id":      "sha256:1111",
+				"image_name":    "docker.io/library/nginx",
+				"image_version": "1.25",
+			}),
+			map[string]interface{}{
+				"size":        int64(187000000),
+				"shared_size": int64(0),
+				"containers":  int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_disk_usage",
+			withTags(base, map[string]string{
+				"image_id": "sha256:2222",
+			}),
+			map[string]interface{}{
+				"size":        int64(5000000),
+				"shared_size": int64(0),
+				"containers":  int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"podman_disk_usage",
+			withTags(base, map[string]string{
+				"volume_name": "pgdata",
+			}),
+			map[string]interface{}{
+				"size":  int64(104857600),
+				"links": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	actual := make([]telegraf.Metric, 0, len(expected))
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "podman_disk_usage" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherServiceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"cause":"boom","message":"something went wrong","response":500}`))
+	}))
+	defer server.Close()
+
+	plugin := &Podman{
+		Endpoint: "tcp://" + strings.TrimPrefix(server.URL, "http://"),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "something went wrong")
+}
+
+// startServer starts a server serving the libpod API responses found in
+// the testdata directory and returns its endpoint
+func startServer(t *testing.T) string {
+	files := map[string]string{
+		"/v4.0.0/libpod/info":             "info.json",
+		"/v4.0.0/libpod/containers/json":  "containers.json",
+		"/v4.0.0/libpod/containers/stats": "stats.json",
+		"/v4.0.0/libpod/pods/json":        "pods.json",
+		"/v4.0.0/libpod/system/df":        "df.json",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fn, found := files[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(filepath.Join("testdata", fn))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buf)
+	}))
+	t.Cleanup(server.Close)
+
+	return "tcp://" + strings.TrimPrefix(server.URL, "http://")
+}
+
+func withTags(base, extra map[string]string) map[string]string {
+	tags := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		tags[k] = v
+	}
+	for k, v := range extra {
+		tags[k] = v
+	}
+	return tags
+}
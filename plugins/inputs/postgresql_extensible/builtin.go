@@ -0,0 +1,150 @@
+package postgresql_extensible
+
+// builtinQueries contains predefined queries for commonly monitored aspects
+// of a server, selectable via the "builtin_queries" option
+var builtinQueries = map[string]query{
+	"replication_slots": {
+		{
+			Measurement: "postgresql_replication_slots",
+			Tagvalue:    "slot_name,slot_type,plugin",
+			MinVersion:  1000,
+			Sqlquery: `
+SELECT
+  slot_name,
+  slot_type::text,
+  COALESCE(plugin::text, '') AS plugin,
+  database::text AS datname,
+  active,
+  pg_wal_lsn_diff(
+    CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END,
+    restart_lsn
+  )::bigint AS restart_lag_bytes,
+  pg_wal_lsn_diff(
+    CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END,
+    confirmed_flush_lsn
+  )::bigint AS confirmed_flush_lag_bytes
+FROM pg_replication_slots`,
+		},
+	},
+	"replication": {
+		{
+			Measurement: "postgresql_replication",
+			Tagvalue:    "application_name,client_addr,state,sync_state",
+			MinVersion:  1000,
+			Sqlquery: `
+SELECT
+  application_name,
+  COALESCE(host(client_addr), 'local') AS client_addr,
+  state,
+  sync_state,
+  pg_wal_lsn_diff(pos.lsn, sent_lsn)::bigint AS sent_lag_bytes,
+  pg_wal_lsn_diff(pos.lsn, write_lsn)::bigint AS write_lag_bytes,
+  pg_wal_lsn_diff(pos.lsn, flush_lsn)::bigint AS flush_lag_bytes,
+  pg_wal_lsn_diff(pos.lsn, replay_lsn)::bigint AS replay_lag_bytes,
+  EXTRACT(EPOCH FROM write_lag)::float8 AS write_lag_seconds,
+  EXTRACT(EPOCH FROM flush_lag)::float8 AS flush_lag_seconds,
+  EXTRACT(EPOCH FROM replay_lag)::float8 AS replay_lag_seconds
+FROM pg_stat_replication
+CROSS JOIN (
+  SELECT CASE WHEN pg_is_in_recovery() THEN pg_last_wal_receive_lsn() ELSE pg_current_wal_lsn() END AS lsn
+) pos`,
+		},
+	},
+	"wal": {
+		{
+			Measurement: "postgresql_wal",
+			MinVersion:  1000,
+			MaxVersion:  1400,
+			Sqlquery: `
+SELECT
+  pg_is_in_recovery() AS in_recovery,
+  pg_wal_lsn_diff(
+    CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END,
+    '0/0'
+  )::bigint AS position_bytes`,
+		},
+		{
+			Measurement: "postgresql_wal",
+			MinVersion:  1400,
+			Sqlquery: `
+SELECT
+  pg_is_in_recovery() AS in_recovery,
+  pg_wal_lsn_diff(
+    CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END,
+    '0/0'
+  )::bigint AS position_bytes,
+  wal_records,
+  wal_fpi,
+  wal_bytes::bigint AS wal_bytes,
+  wal_buffers_full
+FROM pg_stat_wal`,
+		},
+	},
+	"vacuum_progress": {
+		{
+			Measurement: "postgresql_vacuum_progress",
+			Tagvalue:    "pid,table,phase",
+			MinVersion:  906,
+			MaxVersion:  1700,
+			Sqlquery: `
+SELECT
+  p.datname,
+  p.pid::text AS pid,
+  p.relid::regclass::text AS "table",
+  p.phase,
+  p.heap_blks_total,
+  p.heap_blks_scanned,
+  p.heap_blks_vacuumed,
+  p.index_vacuum_count,
+  p.max_dead_tuples,
+  p.num_dead_tuples,
+  EXTRACT(EPOCH FROM now() - a.xact_start)::float8 AS duration_seconds
+FROM pg_stat_progress_vacuum p
+LEFT JOIN pg_stat_activity a ON a.pid = p.pid`,
+		},
+		{
+			Measurement: "postgresql_vacuum_progress",
+			Tagvalue:    "pid,table,phase",
+			MinVersion:  1700,
+			Sqlquery: `
+SELECT
+  p.datname,
+  p.pid::text AS pid,
+  p.relid::regclass::text AS "table",
+  p.phase,
+  p.heap_blks_total,
+  p.heap_blks_scanned,
+  p.heap_blks_vacuumed,
+  p.index_vacuum_count,
+  p.max_dead_tuple_bytes,
+  p.dead_tuple_bytes,
+  p.num_dead_item_ids,
+  EXTRACT(EPOCH FROM now() - a.xact_start)::float8 AS duration_seconds
+FROM pg_stat_progress_vacuum p
+LEFT JOIN pg_stat_activity a ON a.pid = p.pid`,
+		},
+	},
+	"table_bloat": {
+		{
+			// The bloat is estimated from the share of dead tuples to avoid
+			// scanning the tables
+			Measurement: "postgresql_table_bloat",
+			Tagvalue:    "schema,table",
+			MinVersion:  906,
+			Sqlquery: `
+SELECT
+  current_database()::text AS datname,
+  schemaname::text AS schema,
+  relname::text AS "table",
+  n_live_tup,
+  n_dead_tup,
+  pg_table_size(relid) AS table_size_bytes,
+  CASE WHEN n_live_tup + n_dead_tup > 0
+    THEN n_dead_tup::float8 / (n_live_tup + n_dead_tup) ELSE 0 END AS dead_tuple_ratio,
+  CASE WHEN n_live_tup + n_dead_tup > 0
+    THEN (pg_table_size(relid) * n_dead_tup::float8 / (n_live_tup + n_dead_tup))::bigint ELSE 0 END AS estimated_bloat_bytes,
+  EXTRACT(EPOCH FROM now() - GREATEST(last_vacuum, last_autovacuum))::float8 AS seconds_since_vacuum
+FROM pg_stat_user_tables`,
+		},
+	},
+}
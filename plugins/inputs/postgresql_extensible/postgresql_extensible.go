@@ -29,12 +29,15 @@ type Postgresql struct {
 	Timestamp          string
 	Query              query
 	Debug              bool
-	PreparedStatements bool `toml:"prepared_statements"`
+	PreparedStatements bool     `toml:"prepared_statements"`
+	BuiltinQueries     []string `toml:"builtin_queries"`
 
 	Log telegraf.Logger
 }
 
-type query []struct {
+type query []queryEntry
+
+type queryEntry struct {
 	Sqlquery    string
 	Script      string
 	Version     int  `deprecated:"1.28.0;use minVersion to specify minimal DB version this query supports"`
@@ -53,6 +56,14 @@ func (*Postgresql) SampleConfig() string {
 }
 
 func (p *Postgresql) Init() error {
+	for _, name := range p.BuiltinQueries {
+		q, found := builtinQueries[name]
+		if !found {
+			return fmt.Errorf("unknown builtin query %q", name)
+		}
+		p.Query = append(p.Query, q...)
+	}
+
 	var err error
 	for i := range p.Query {
 		if p.Query[i].Sqlquery == "" {
@@ -358,3 +358,50 @@ func (f fakeRow) Scan(dest ...interface{}) error {
 	}
 	return nil
 }
+
+func TestBuiltinQueries(t *testing.T) {
+	p := &Postgresql{
+		Log:            testutil.Logger{},
+		BuiltinQueries: []string{"wal", "table_bloat"},
+		Query: query{{
+			Sqlquery:   "SELECT 1 AS one",
+			MinVersion: 901,
+		}},
+	}
+	require.NoError(t, p.Init())
+	require.Len(t, p.Query, 4)
+	require.Equal(t, "SELECT 1 AS one", p.Query[0].Sqlquery)
+	require.Equal(t, "postgresql_wal", p.Query[1].Measurement)
+	require.Equal(t, "postgresql_wal", p.Query[2].Measurement)
+	require.Equal(t, "postgresql_table_bloat", p.Query[3].Measurement)
+
+	// Version ranges of alternative queries must not overlap
+	for name, queries := range builtinQueries {
+		for i, a := range queries {
+			for _, b := range queries[i+1:] {
+				require.Falsef(t, a.MaxVersion == 0 || a.MaxVersion > b.MinVersion, "overlapping versions in %q", name)
+			}
+		}
+	}
+
+	p = &Postgresql{
+		Log:            testutil.Logger{},
+		BuiltinQueries: []string{"foo"},
+	}
+	require.ErrorContains(t, p.Init(), `unknown builtin query "foo"`)
+}
+
+func TestBuiltinQueriesIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	var q query
+	for _, name := range []string{"replication_slots", "replication", "wal", "vacuum_progress", "table_bloat"} {
+		q = append(q, builtinQueries[name]...)
+	}
+	acc := queryRunner(t, q)
+	require.Empty(t, acc.Errors)
+	require.True(t, acc.HasInt64Field("postgresql_wal", "position_bytes"))
+	require.True(t, acc.HasField("postgresql_wal", "in_recovery"))
+}
@@ -0,0 +1,182 @@
+package procstat
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Fields not making sense to be summed up when aggregating processes
+var nonAggregatableFields = map[string]bool{
+	"created_at":        true,
+	"nice_priority":     true,
+	"ppid":              true,
+	"realtime_priority": true,
+}
+
+// sample is the metric of a single process along with the information
+// required to aggregate it with other processes
+type sample struct {
+	pid    PID
+	metric telegraf.Metric
+	netns  string
+}
+
+// sumFields adds up the numeric fields of all samples. Network deltas are
+// only accounted once per network namespace as all processes in the same
+// namespace share the same counters.
+func sumFields(samples []*sample, prefix string) map[string]interface{} {
+	if prefix != "" {
+		prefix += "_"
+	}
+
+	sums := make(map[string]interface{})
+	seenNetns := make(map[string]bool)
+	for _, s := range samples {
+		skipNet := s.netns == "" || seenNetns[s.netns]
+		seenNetns[s.netns] = true
+
+		for _, field := range s.metric.FieldList() {
+			name := strings.TrimPrefix(field.Key, prefix)
+			if field.Key == "pid" || nonAggregatableFields[name] || strings.HasPrefix(name, "rlimit_") {
+				continue
+			}
+			if skipNet && strings.HasPrefix(name, "net_") {
+				continue
+			}
+
+			switch v := field.Value.(type) {
+			case int32:
+				sums[field.Key] = addInt(sums[field.Key], int64(v))
+			case int64:
+				sums[field.Key] = addInt(sums[field.Key], v)
+			case uint32:
+				sums[field.Key] = addUint(sums[field.Key], uint64(v))
+			case uint64:
+				sums[field.Key] = addUint(sums[field.Key], v)
+			case float32:
+				sums[field.Key] = addFloat(sums[field.Key], float64(v))
+			case float64:
+				sums[field.Key] = addFloat(sums[field.Key], v)
+			}
+		}
+	}
+	sums[prefix+"num_processes"] = int64(len(samples))
+
+	return sums
+}
+
+func addInt(sum interface{}, v int64) int64 {
+	s, _ := sum.(int64)
+	return s + v
+}
+
+func addUint(sum interface{}, v uint64) uint64 {
+	s, _ := sum.(uint64)
+	return s + v
+}
+
+func addFloat(sum interface{}, v float64) float64 {
+	s, _ := sum.(float64)
+	return s + v
+}
+
+// processTrees groups the given PIDs with all their descendants using the
+// PID to parent-PID table. Processes being a descendant of another given
+// process are accounted to the topmost ancestor only. The first element of
+// each group is the root process.
+func processTrees(pids []PID, table map[PID]PID) [][]PID {
+	matched := make(map[PID]bool, len(pids))
+	for _, pid := range pids {
+		matched[pid] = true
+	}
+
+	children := make(map[PID][]PID)
+	for pid, ppid := range table {
+		if pid != ppid {
+			children[ppid] = append(children[ppid], pid)
+		}
+	}
+	for _, c := range children {
+		sort.Slice(c, func(i, j int) bool { return c[i] < c[j] })
+	}
+
+	groups := make([][]PID, 0, len(pids))
+	for _, pid := range pids {
+		if hasMatchedAncestor(pid, table, matched) {
+			continue
+		}
+
+		group := []PID{pid}
+		visited := map[PID]bool{pid: true}
+		for i := 0; i < len(group); i++ {
+			for _, child := range children[group[i]] {
+				if !visited[child] {
+					visited[child] = true
+					group = append(group, child)
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func hasMatchedAncestor(pid PID, table map[PID]PID, matched map[PID]bool) bool {
+	// Limit the depth to protect against loops in an inconsistent table
+	// caused by PIDs being reused during the scan
+	start := pid
+	for depth := 0; depth < len(table); depth++ {
+		ppid, found := table[pid]
+		if !found || ppid == 0 || ppid == pid || ppid == start {
+			return false
+		}
+		if matched[ppid] {
+			return true
+		}
+		pid = ppid
+	}
+	return false
+}
+
+// parseCgroup extracts the cgroup path from the content of /proc/<pid>/cgroup.
+// For cgroup v1 the path of the systemd hierarchy is used, otherwise the one
+// of the unified hierarchy.
+func parseCgroup(data []byte) string {
+	var unified, first string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// Lines have the format "hierarchy-ID:controller-list:cgroup-path"
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		switch {
+		case parts[1] == "name=systemd":
+			return parts[2]
+		case parts[0] == "0" && parts[1] == "":
+			unified = parts[2]
+		case first == "":
+			first = parts[2]
+		}
+	}
+	if unified != "" {
+		return unified
+	}
+	return first
+}
+
+// systemdUnit returns the innermost systemd unit of the given cgroup path
+// e.g. "nginx.service" for "/system.slice/nginx.service"
+func systemdUnit(cgroup string) string {
+	parts := strings.Split(cgroup, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if strings.HasSuffix(parts[i], ".service") || strings.HasSuffix(parts[i], ".scope") {
+			return parts[i]
+		}
+	}
+	return ""
+}
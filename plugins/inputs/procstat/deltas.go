@@ -0,0 +1,103 @@
+package procstat
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+type ioCounters struct {
+	readBytes  uint64
+	writeBytes uint64
+}
+
+type netCounters struct {
+	netns   string
+	rxBytes uint64
+	txBytes uint64
+}
+
+// addIODelta adds the number of bytes read and written by the process since
+// the last collection
+func (p *Procstat) addIODelta(pid PID, m telegraf.Metric, prefix string) {
+	readBytes, _ := m.GetField(prefix + "read_bytes")
+	writeBytes, _ := m.GetField(prefix + "write_bytes")
+	var current ioCounters
+	var rok, wok bool
+	current.readBytes, rok = readBytes.(uint64)
+	current.writeBytes, wok = writeBytes.(uint64)
+	if !rok || !wok {
+		return
+	}
+
+	// Counters going backwards indicate a reused PID, so skip the delta
+	last, found := p.ioPrevious[pid]
+	if found && current.readBytes >= last.readBytes && current.writeBytes >= last.writeBytes {
+		m.AddField(prefix+"read_bytes_delta", current.readBytes-last.readBytes)
+		m.AddField(prefix+"write_bytes_delta", current.writeBytes-last.writeBytes)
+	}
+	p.ioPrevious[pid] = current
+}
+
+// addNetDelta adds the number of bytes received and transmitted in the
+// network namespace of the process since the last collection and returns
+// the namespace. The delta is computed once per namespace and collection.
+func (p *Procstat) addNetDelta(pid PID, m telegraf.Metric, prefix string, deltas map[string]*netCounters) string {
+	current, err := p.readNetIO(pid)
+	if err != nil {
+		p.Log.Debugf("Reading network statistics of process %d failed: %v", pid, err)
+		return ""
+	}
+
+	delta, found := deltas[current.netns]
+	if !found {
+		last, ok := p.netPrevious[current.netns]
+		if ok && current.rxBytes >= last.rxBytes && current.txBytes >= last.txBytes {
+			delta = &netCounters{
+				netns:   current.netns,
+				rxBytes: current.rxBytes - last.rxBytes,
+				txBytes: current.txBytes - last.txBytes,
+			}
+		}
+		deltas[current.netns] = delta
+		p.netPrevious[current.netns] = current
+	}
+
+	if delta != nil {
+		m.AddField(prefix+"net_rx_bytes_delta", delta.rxBytes)
+		m.AddField(prefix+"net_tx_bytes_delta", delta.txBytes)
+	}
+	return current.netns
+}
+
+// parseNetDev sums up the received and transmitted bytes of all interfaces
+// except loopback from the content of /proc/<pid>/net/dev
+func parseNetDev(data []byte) (rx, tx uint64) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		iface, stats, found := strings.Cut(scanner.Text(), ":")
+		if !found || strings.TrimSpace(iface) == "lo" {
+			continue
+		}
+		// The first 8 columns are the receive and the next 8 the transmit
+		// statistics, each starting with the number of bytes
+		fields := strings.Fields(stats)
+		if len(fields) < 9 {
+			continue
+		}
+		r, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		t, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			continue
+		}
+		rx += r
+		tx += t
+	}
+	return rx, tx
+}
@@ -76,23 +76,7 @@ func (pg *NativeFinder) FullPattern(pattern string) ([]PID, error) {
 
 // Children matches children pids on the command line when the process was executed
 func (pg *NativeFinder) Children(pid PID) ([]PID, error) {
-	// Get all running processes
-	p, err := process.NewProcess(int32(pid))
-	if err != nil {
-		return nil, fmt.Errorf("getting process %d failed: %w", pid, err)
-	}
-
-	// Get all children of the current process
-	children, err := p.Children()
-	if err != nil {
-		return nil, fmt.Errorf("unable to get children of process %d: %w", p.Pid, err)
-	}
-	pids := make([]PID, 0, len(children))
-	for _, child := range children {
-		pids = append(pids, PID(child.Pid))
-	}
-
-	return pids, err
+	return childPIDs(pid)
 }
 
 func (pg *NativeFinder) FastProcessList() ([]*process.Process, error) {
@@ -0,0 +1,47 @@
+//go:build !linux
+
+package procstat
+
+import (
+	"fmt"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func gopsutilChildren(pid PID) ([]PID, error) {
+	// Get all running processes
+	p, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil, fmt.Errorf("getting process %d failed: %w", pid, err)
+	}
+
+	// Get all children of the current process
+	children, err := p.Children()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get children of process %d: %w", p.Pid, err)
+	}
+	pids := make([]PID, 0, len(children))
+	for _, child := range children {
+		pids = append(pids, PID(child.Pid))
+	}
+
+	return pids, err
+}
+
+func gopsutilProcessTable() (map[PID]PID, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(map[PID]PID, len(procs))
+	for _, p := range procs {
+		ppid, err := p.Ppid()
+		if err != nil {
+			//skip, this can be caused by the pid no longer existing
+			continue
+		}
+		table[PID(p.Pid)] = PID(ppid)
+	}
+	return table, nil
+}
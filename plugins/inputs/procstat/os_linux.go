@@ -3,7 +3,12 @@
 package procstat
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/shirou/gopsutil/v3/process"
 )
@@ -31,3 +36,105 @@ func collectMemmap(proc Process, prefix string, fields map[string]any) {
 		fields[prefix+"memory_swap"] = memMap.Swap
 	}
 }
+
+// hostProc returns the path in the proc filesystem honoring the HOST_PROC
+// environment variable in the same way as gopsutil
+func hostProc(parts ...string) string {
+	root := os.Getenv("HOST_PROC")
+	if root == "" {
+		root = "/proc"
+	}
+	return filepath.Join(append([]string{root}, parts...)...)
+}
+
+// processTable returns the parent PID of all running processes. The table is
+// built by reading /proc/<pid>/stat directly as this is significantly faster
+// than querying each process via gopsutil when handling thousands of them.
+func processTable() (map[PID]PID, error) {
+	dir, err := os.Open(hostProc())
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(map[PID]PID, len(names))
+	for _, name := range names {
+		pid, err := strconv.ParseInt(name, 10, 32)
+		if err != nil {
+			// Not a process directory
+			continue
+		}
+		buf, err := os.ReadFile(hostProc(name, "stat"))
+		if err != nil {
+			// The process might have ended in the meantime
+			continue
+		}
+		ppid, err := parseStatPPID(buf)
+		if err != nil {
+			continue
+		}
+		table[PID(pid)] = ppid
+	}
+	return table, nil
+}
+
+// parseStatPPID extracts the parent PID from the content of /proc/<pid>/stat
+// having the format "pid (comm) state ppid ...". The command name might
+// contain spaces and parentheses so search for the last closing parenthesis.
+func parseStatPPID(buf []byte) (PID, error) {
+	idx := bytes.LastIndexByte(buf, ')')
+	if idx < 0 {
+		return 0, errors.New("invalid stat format")
+	}
+	fields := bytes.Fields(buf[idx+1:])
+	if len(fields) < 2 {
+		return 0, errors.New("invalid stat format")
+	}
+	ppid, err := strconv.ParseInt(string(fields[1]), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parent PID %q: %w", fields[1], err)
+	}
+	return PID(ppid), nil
+}
+
+func childPIDs(pid PID) ([]PID, error) {
+	table, err := processTable()
+	if err != nil {
+		return nil, fmt.Errorf("scanning processes failed: %w", err)
+	}
+
+	var pids []PID
+	for child, ppid := range table {
+		if ppid == pid {
+			pids = append(pids, child)
+		}
+	}
+	return pids, nil
+}
+
+func processCgroup(pid PID) (string, error) {
+	buf, err := os.ReadFile(hostProc(strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	return parseCgroup(buf), nil
+}
+
+func processNetIO(pid PID) (netCounters, error) {
+	base := hostProc(strconv.Itoa(int(pid)))
+	netns, err := os.Readlink(filepath.Join(base, "ns", "net"))
+	if err != nil {
+		return netCounters{}, fmt.Errorf("reading network namespace failed: %w", err)
+	}
+	buf, err := os.ReadFile(filepath.Join(base, "net", "dev"))
+	if err != nil {
+		return netCounters{}, err
+	}
+	rx, tx := parseNetDev(buf)
+	return netCounters{netns: netns, rxBytes: rx, txBytes: tx}, nil
+}
@@ -17,3 +17,19 @@ func queryPidWithWinServiceName(_ string) (uint32, error) {
 }
 
 func collectMemmap(Process, string, map[string]any) {}
+
+func processTable() (map[PID]PID, error) {
+	return gopsutilProcessTable()
+}
+
+func childPIDs(pid PID) ([]PID, error) {
+	return gopsutilChildren(pid)
+}
+
+func processCgroup(PID) (string, error) {
+	return "", errors.New("cgroups are only supported on Linux")
+}
+
+func processNetIO(PID) (netCounters, error) {
+	return netCounters{}, errors.New("network statistics are only supported on Linux")
+}
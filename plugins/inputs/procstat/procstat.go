@@ -46,14 +46,22 @@ type Procstat struct {
 	WinService             string          `toml:"win_service"`
 	Mode                   string          `toml:"mode"`
 	TagWith                []string        `toml:"tag_with"`
+	Aggregate              string          `toml:"aggregate"`
+	Deltas                 []string        `toml:"deltas"`
 	Log                    telegraf.Logger `toml:"-"`
 
 	solarisMode bool
 	finder      PIDFinder
 	processes   map[PID]Process
 	tagging     map[string]bool
+	deltas      map[string]bool
+	ioPrevious  map[PID]ioCounters
+	netPrevious map[string]netCounters
 
 	createProcess func(PID) (Process, error)
+	scanProcesses func() (map[PID]PID, error)
+	readCgroup    func(PID) (string, error)
+	readNetIO     func(PID) (netCounters, error)
 }
 
 type PidsTags struct {
@@ -126,8 +134,39 @@ func (p *Procstat) Init() error {
 		return fmt.Errorf("unknown pid_finder %q", p.PidFinder)
 	}
 
+	// Check aggregation and delta settings
+	if err := choice.Check(p.Aggregate, []string{"", "children", "systemd_unit", "cgroup"}); err != nil {
+		return fmt.Errorf("invalid aggregate setting: %w", err)
+	}
+	if err := choice.CheckSlice(p.Deltas, []string{"io", "network"}); err != nil {
+		return fmt.Errorf("invalid deltas setting: %w", err)
+	}
+	p.deltas = make(map[string]bool, len(p.Deltas))
+	for _, d := range p.Deltas {
+		p.deltas[d] = true
+	}
+	if runtime.GOOS != "linux" {
+		if p.Aggregate == "systemd_unit" || p.Aggregate == "cgroup" {
+			return fmt.Errorf("aggregate %q is only supported on Linux", p.Aggregate)
+		}
+		if p.deltas["network"] {
+			return errors.New("network deltas are only supported on Linux")
+		}
+	}
+	if p.scanProcesses == nil {
+		p.scanProcesses = processTable
+	}
+	if p.readCgroup == nil {
+		p.readCgroup = processCgroup
+	}
+	if p.readNetIO == nil {
+		p.readNetIO = processNetIO
+	}
+
 	// Initialize the running process cache
 	p.processes = make(map[PID]Process)
+	p.ioPrevious = make(map[PID]ioCounters)
+	p.netPrevious = make(map[string]netCounters)
 
 	return nil
 }
@@ -156,43 +195,74 @@ func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 	}
 
 	var count int
+	var table map[PID]PID
 	running := make(map[PID]bool)
+	netDeltas := make(map[string]*netCounters)
 	for _, r := range results {
 		if len(r.PIDs) < 1 && len(p.SupervisorUnits) > 0 {
 			continue
 		}
 		count += len(r.PIDs)
-		for _, pid := range r.PIDs {
-			// Use the cached processes as we need the existing instances
-			// to compute delta-metrics (e.g. cpu-usage).
-			proc, found := p.processes[pid]
-			if !found {
-				// We've found a process that was not recorded before so add it
-				// to the list of processes
-				proc, err = p.createProcess(pid)
+
+		if p.Aggregate == "children" {
+			// Scan the process tree only once per collection
+			if table == nil {
+				table, err = p.scanProcesses()
 				if err != nil {
-					// No problem; process may have ended after we found it
-					continue
+					acc.AddError(fmt.Errorf("scanning processes failed: %w", err))
+					table = make(map[PID]PID)
 				}
-				// Assumption: if a process has no name, it probably does not exist
-				if name, _ := proc.Name(); name == "" {
+			}
+			for _, group := range processTrees(r.PIDs, table) {
+				samples := make([]*sample, 0, len(group))
+				for _, pid := range group {
+					if s := p.sample(pid, r.Tags, netDeltas); s != nil {
+						running[pid] = true
+						samples = append(samples, s)
+					}
+				}
+				// Skip the tree if the root process is gone
+				if len(samples) == 0 || samples[0].pid != group[0] {
 					continue
 				}
-
-				// Add initial tags
-				for k, v := range r.Tags {
-					proc.SetTag(k, v)
+				m := samples[0].metric
+				for k, v := range sumFields(samples, p.Prefix) {
+					m.AddField(k, v)
 				}
+				m.SetTime(now)
+				acc.AddMetric(m)
+			}
+			continue
+		}
 
-				if p.ProcessName != "" {
-					proc.SetTag("process_name", p.ProcessName)
-				}
-				p.processes[pid] = proc
+		rollups := make(map[string][]*sample)
+		for _, pid := range r.PIDs {
+			s := p.sample(pid, r.Tags, netDeltas)
+			if s == nil {
+				continue
 			}
 			running[pid] = true
-			m := proc.Metric(p.Prefix, p.tagging, p.solarisMode)
-			m.SetTime(now)
-			acc.AddMetric(m)
+			s.metric.SetTime(now)
+			acc.AddMetric(s.metric)
+
+			if p.Aggregate == "systemd_unit" || p.Aggregate == "cgroup" {
+				key, err := p.rollupKey(pid)
+				if err != nil {
+					p.Log.Debugf("Determining %s of process %d failed: %v", p.Aggregate, pid, err)
+					continue
+				}
+				if key != "" {
+					rollups[key] = append(rollups[key], s)
+				}
+			}
+		}
+		for key, samples := range rollups {
+			tags := make(map[string]string, len(r.Tags)+1)
+			for k, v := range r.Tags {
+				tags[k] = v
+			}
+			tags[p.Aggregate] = key
+			acc.AddFields("procstat_rollup", sumFields(samples, p.Prefix), tags, now)
 		}
 	}
 
@@ -200,6 +270,12 @@ func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 	for pid := range p.processes {
 		if !running[pid] {
 			delete(p.processes, pid)
+			delete(p.ioPrevious, pid)
+		}
+	}
+	for netns := range p.netPrevious {
+		if _, found := netDeltas[netns]; !found {
+			delete(p.netPrevious, netns)
 		}
 	}
 
@@ -226,6 +302,67 @@ func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// sample collects the metric of the given process using the cached process
+// instances as those are required to compute delta-metrics (e.g. cpu-usage).
+// Nil is returned if the process does not exist (anymore).
+func (p *Procstat) sample(pid PID, tags map[string]string, netDeltas map[string]*netCounters) *sample {
+	proc, found := p.processes[pid]
+	if !found {
+		// We've found a process that was not recorded before so add it
+		// to the list of processes
+		var err error
+		proc, err = p.createProcess(pid)
+		if err != nil {
+			// No problem; process may have ended after we found it
+			return nil
+		}
+		// Assumption: if a process has no name, it probably does not exist
+		if name, _ := proc.Name(); name == "" {
+			return nil
+		}
+
+		// Add initial tags
+		for k, v := range tags {
+			proc.SetTag(k, v)
+		}
+
+		if p.ProcessName != "" {
+			proc.SetTag("process_name", p.ProcessName)
+		}
+		p.processes[pid] = proc
+	}
+
+	s := &sample{
+		pid:    pid,
+		metric: proc.Metric(p.Prefix, p.tagging, p.solarisMode),
+	}
+
+	prefix := p.Prefix
+	if prefix != "" {
+		prefix += "_"
+	}
+	if p.deltas["io"] {
+		p.addIODelta(pid, s.metric, prefix)
+	}
+	if p.deltas["network"] {
+		s.netns = p.addNetDelta(pid, s.metric, prefix, netDeltas)
+	}
+
+	return s
+}
+
+// rollupKey returns the cgroup or systemd unit of the process to aggregate by
+func (p *Procstat) rollupKey(pid PID) (string, error) {
+	cgroup, err := p.readCgroup(pid)
+	if err != nil {
+		return "", err
+	}
+	if p.Aggregate == "systemd_unit" {
+		return systemdUnit(cgroup), nil
+	}
+	return cgroup, nil
+}
+
 // Get matching PIDs and their initial tags
 func (p *Procstat) findPids() ([]PidsTags, error) {
 	switch {
@@ -653,3 +653,222 @@ func TestGather_MoresupervisorUnitPIDs(t *testing.T) {
 		}
 	}
 }
+
+func TestGather_AggregateChildren(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Aggregate:     "children",
+		Log:           testutil.Logger{},
+		finder:        newTestFinder([]PID{100, 200}),
+		createProcess: newTestProc,
+		scanProcesses: func() (map[PID]PID, error) {
+			return map[PID]PID{1: 0, 100: 1, 101: 100, 102: 101, 200: 100, 300: 1}, nil
+		},
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+
+	// Process 200 is a descendant of 100 so only a single tree is reported
+	var found int
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "procstat" {
+			continue
+		}
+		found++
+		pid, _ := m.GetField("pid")
+		require.Equal(t, int64(100), pid)
+		numProcesses, _ := m.GetField("num_processes")
+		require.Equal(t, int64(4), numProcesses)
+		numThreads, _ := m.GetField("num_threads")
+		require.Equal(t, int64(0), numThreads)
+	}
+	require.Equal(t, 1, found)
+	require.Len(t, p.processes, 4)
+}
+
+func TestGather_RollupSystemdUnit(t *testing.T) {
+	units := map[PID]string{
+		100: "/system.slice/nginx.service",
+		101: "/system.slice/nginx.service",
+		200: "/system.slice/sshd.service",
+	}
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Aggregate:     "systemd_unit",
+		Log:           testutil.Logger{},
+		finder:        newTestFinder([]PID{100, 101, 200}),
+		createProcess: newTestProc,
+		readCgroup: func(pid PID) (string, error) {
+			return units[pid], nil
+		},
+	}
+	if runtime.GOOS != "linux" {
+		require.ErrorContains(t, p.Init(), "only supported on Linux")
+		return
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+
+	rollups := make(map[string]int64)
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() != "procstat_rollup" {
+			continue
+		}
+		require.Equal(t, exe, m.Tags()["exe"])
+		numProcesses, _ := m.GetField("num_processes")
+		rollups[m.Tags()["systemd_unit"]] = numProcesses.(int64)
+	}
+	require.Equal(t, map[string]int64{"nginx.service": 2, "sshd.service": 1}, rollups)
+	require.Len(t, acc.GetTelegrafMetrics(), 6)
+}
+
+func TestGather_Deltas(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("network deltas are only supported on Linux")
+	}
+
+	var rx, tx uint64
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Aggregate:     "children",
+		Deltas:        []string{"io", "network"},
+		Log:           testutil.Logger{},
+		finder:        newTestFinder([]PID{100}),
+		createProcess: newTestProc,
+		scanProcesses: func() (map[PID]PID, error) {
+			return map[PID]PID{100: 1, 101: 100}, nil
+		},
+		readNetIO: func(PID) (netCounters, error) {
+			return netCounters{netns: "net:[4026531840]", rxBytes: rx, txBytes: tx}, nil
+		},
+	}
+	require.NoError(t, p.Init())
+
+	// No deltas on the first collection
+	var acc testutil.Accumulator
+	rx, tx = 1000, 500
+	require.NoError(t, p.Gather(&acc))
+	m, found := acc.Get("procstat")
+	require.True(t, found)
+	require.NotContains(t, m.Fields, "read_bytes_delta")
+	require.NotContains(t, m.Fields, "net_rx_bytes_delta")
+
+	// Both processes share the namespace so the traffic is accounted once
+	acc.ClearMetrics()
+	rx, tx = 1500, 800
+	require.NoError(t, p.Gather(&acc))
+	m, found = acc.Get("procstat")
+	require.True(t, found)
+	require.Equal(t, uint64(0), m.Fields["read_bytes_delta"])
+	require.Equal(t, uint64(0), m.Fields["write_bytes_delta"])
+	require.Equal(t, uint64(500), m.Fields["net_rx_bytes_delta"])
+	require.Equal(t, uint64(300), m.Fields["net_tx_bytes_delta"])
+}
+
+func TestInitInvalidAggregate(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Aggregate:     "foo",
+		Log:           testutil.Logger{},
+		createProcess: newTestProc,
+	}
+	require.ErrorContains(t, p.Init(), "invalid aggregate setting")
+
+	p = Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Deltas:        []string{"disk"},
+		Log:           testutil.Logger{},
+		createProcess: newTestProc,
+	}
+	require.ErrorContains(t, p.Init(), "invalid deltas setting")
+}
+
+func TestProcessTrees(t *testing.T) {
+	table := map[PID]PID{1: 0, 10: 1, 11: 10, 12: 10, 13: 12, 20: 1, 21: 20}
+	groups := processTrees([]PID{10, 13, 20, 99}, table)
+	require.Equal(t, [][]PID{{10, 11, 12, 13}, {20, 21}, {99}}, groups)
+
+	// Loops in the table must not hang
+	groups = processTrees([]PID{1}, map[PID]PID{1: 2, 2: 1})
+	require.Equal(t, [][]PID{{1, 2}}, groups)
+}
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		unit     string
+	}{
+		{
+			name:     "unified",
+			input:    "0::/system.slice/nginx.service\n",
+			expected: "/system.slice/nginx.service",
+			unit:     "nginx.service",
+		},
+		{
+			name:     "v1",
+			input:    "12:cpu,cpuacct:/system.slice/sshd.service\n1:name=systemd:/system.slice/sshd.service\n",
+			expected: "/system.slice/sshd.service",
+			unit:     "sshd.service",
+		},
+		{
+			name:     "user session",
+			input:    "0::/user.slice/user-1000.slice/user@1000.service/app.slice/app-foo.scope\n",
+			expected: "/user.slice/user-1000.slice/user@1000.service/app.slice/app-foo.scope",
+			unit:     "app-foo.scope",
+		},
+		{
+			name:     "no unit",
+			input:    "0::/\n",
+			expected: "/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cgroup := parseCgroup([]byte(tt.input))
+			require.Equal(t, tt.expected, cgroup)
+			require.Equal(t, tt.unit, systemdUnit(cgroup))
+		})
+	}
+}
+
+func TestParseNetDev(t *testing.T) {
+	input := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  123456     100    0    0    0     0          0         0   123456     100    0    0    0     0       0          0
+  eth0: 1000000    2000    0    0    0     0          0         0   500000    1500    0    0    0     0       0          0
+  eth1:    2000      20    0    0    0     0          0         0     1000      10    0    0    0     0       0          0
+`
+	rx, tx := parseNetDev([]byte(input))
+	require.Equal(t, uint64(1002000), rx)
+	require.Equal(t, uint64(501000), tx)
+}
+
+func TestProcessTableProcfs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping test on non-linux platform")
+	}
+	t.Setenv("HOST_PROC", filepath.Join("testdata", "proc"))
+
+	table, err := processTable()
+	require.NoError(t, err)
+	require.Equal(t, map[PID]PID{1: 0, 100: 1, 101: 100}, table)
+
+	children, err := childPIDs(100)
+	require.NoError(t, err)
+	require.Equal(t, []PID{101}, children)
+
+	cgroup, err := processCgroup(100)
+	require.NoError(t, err)
+	require.Equal(t, "/system.slice/nginx.service", cgroup)
+}
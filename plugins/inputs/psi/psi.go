@@ -0,0 +1,211 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package psi
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var validResources = []string{"cpu", "memory", "io", "irq"}
+
+type PSI struct {
+	Resources  []string        `toml:"resources"`
+	SystemWide bool            `toml:"system_wide"`
+	Cgroups    []string        `toml:"cgroups"`
+	ProcPath   string          `toml:"proc_path"`
+	CgroupPath string          `toml:"cgroup_path"`
+	Log        telegraf.Logger `toml:"-"`
+}
+
+// stat is a single line of a pressure file
+type stat struct {
+	avg10  float64
+	avg60  float64
+	avg300 float64
+	total  uint64
+}
+
+func (*PSI) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PSI) Init() error {
+	if len(p.Resources) == 0 {
+		p.Resources = []string{"cpu", "memory", "io"}
+	}
+	if err := choice.CheckSlice(p.Resources, validResources); err != nil {
+		return fmt.Errorf("invalid resources: %w", err)
+	}
+
+	if !p.SystemWide && len(p.Cgroups) == 0 {
+		return errors.New("neither system-wide nor cgroup pressure is gathered")
+	}
+
+	if p.ProcPath == "" {
+		p.ProcPath = "/proc"
+	}
+	if p.CgroupPath == "" {
+		p.CgroupPath = "/sys/fs/cgroup"
+	}
+
+	for _, pattern := range p.Cgroups {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid cgroup pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PSI) Gather(acc telegraf.Accumulator) error {
+	if p.SystemWide {
+		for _, resource := range p.Resources {
+			fn := filepath.Join(p.ProcPath, "pressure", resource)
+			if err := p.gatherFile(acc, fn, resource, nil); err != nil {
+				acc.AddError(err)
+			}
+		}
+	}
+
+	for _, pattern := range p.Cgroups {
+		dirs, err := filepath.Glob(filepath.Join(p.CgroupPath, pattern))
+		if err != nil {
+			acc.AddError(fmt.Errorf("resolving cgroups %q failed: %w", pattern, err))
+			continue
+		}
+		for _, dir := range dirs {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+			cgroup, err := filepath.Rel(p.CgroupPath, dir)
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+			tags := map[string]string{"cgroup": "/" + filepath.ToSlash(cgroup)}
+			for _, resource := range p.Resources {
+				fn := filepath.Join(dir, resource+".pressure")
+				if err := p.gatherFile(acc, fn, resource, tags); err != nil {
+					acc.AddError(err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *PSI) gatherFile(acc telegraf.Accumulator, fn, resource string, extraTags map[string]string) error {
+	now := time.Now()
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		// Cgroups might vanish between listing and reading and the
+		// controller might not be enabled for all cgroups
+		if extraTags != nil && errors.Is(err, os.ErrNotExist) {
+			p.Log.Debugf("Skipping non-existing file %q", fn)
+			return nil
+		}
+		return fmt.Errorf("reading %s pressure failed: %w", resource, err)
+	}
+
+	stats, err := parse(buf)
+	if err != nil {
+		return fmt.Errorf("parsing %q failed: %w", fn, err)
+	}
+
+	for _, typ := range []string{"some", "full"} {
+		s, found := stats[typ]
+		if !found {
+			continue
+		}
+		tags := map[string]string{
+			"resource": resource,
+			"type":     typ,
+		}
+		for k, v := range extraTags {
+			tags[k] = v
+		}
+
+		acc.AddCounter("pressure", map[string]interface{}{
+			"total": s.total,
+		}, tags, now)
+		acc.AddGauge("pressure", map[string]interface{}{
+			"avg10":  s.avg10,
+			"avg60":  s.avg60,
+			"avg300": s.avg300,
+		}, tags, now)
+	}
+	return nil
+}
+
+// parse reads the content of a pressure file in the format
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func parse(buf []byte) (map[string]stat, error) {
+	stats := make(map[string]stat, 2)
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 {
+			continue
+		}
+
+		typ := parts[0]
+		if typ != "some" && typ != "full" {
+			return nil, fmt.Errorf("unknown type %q", typ)
+		}
+
+		var s stat
+		for _, part := range parts[1:] {
+			key, value, found := strings.Cut(part, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid entry %q", part)
+			}
+
+			var err error
+			switch key {
+			case "avg10":
+				s.avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				s.avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				s.avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				s.total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("parsing %q failed: %w", part, err)
+			}
+		}
+		stats[typ] = s
+	}
+
+	return stats, scanner.Err()
+}
+
+func init() {
+	inputs.Add("psi", func() telegraf.Input {
+		return &PSI{
+			SystemWide: true,
+		}
+	})
+}
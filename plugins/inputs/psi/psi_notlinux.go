@@ -0,0 +1,31 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package psi
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type PSI struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (p *PSI) Init() error {
+	p.Log.Warn("current platform is not supported")
+	return nil
+}
+func (*PSI) SampleConfig() string                { return sampleConfig }
+func (*PSI) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("psi", func() telegraf.Input {
+		return &PSI{}
+	})
+}
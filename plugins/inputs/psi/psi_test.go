@@ -0,0 +1,138 @@
+//go:build linux
+
+package psi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &PSI{Resources: []string{"disk"}, SystemWide: true}
+	require.ErrorContains(t, plugin.Init(), "invalid resources")
+
+	plugin = &PSI{}
+	require.ErrorContains(t, plugin.Init(), "neither system-wide nor cgroup pressure")
+
+	plugin = &PSI{Cgroups: []string{"system.slice/["}}
+	require.ErrorContains(t, plugin.Init(), "invalid cgroup pattern")
+}
+
+func TestGatherSystemWide(t *testing.T) {
+	plugin := &PSI{
+		SystemWide: true,
+		ProcPath:   "testdata/proc",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := make([]telegraf.Metric, 0, 12)
+	for _, m := range [][]telegraf.Metric{
+		pressure(nil, "cpu", "some", 1088168194, 1.53, 1.87, 1.73),
+		pressure(nil, "cpu", "full", 0, 0, 0, 0),
+		pressure(nil, "memory", "some", 3463792, 0, 0, 0),
+		pressure(nil, "memory", "full", 1429641, 0, 0, 0),
+		pressure(nil, "io", "some", 68568296, 0.1, 0.2, 0.3),
+		pressure(nil, "io", "full", 54982338, 0.05, 0.1, 0.15),
+	} {
+		expected = append(expected, m...)
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherCgroups(t *testing.T) {
+	plugin := &PSI{
+		Resources:  []string{"cpu", "memory"},
+		Cgroups:    []string{"system.slice/*"},
+		CgroupPath: "testdata/cgroup",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	sshd := map[string]string{"cgroup": "/system.slice/sshd.service"}
+	cron := map[string]string{"cgroup": "/system.slice/cron.service"}
+	expected := make([]telegraf.Metric, 0, 12)
+	for _, m := range [][]telegraf.Metric{
+		pressure(sshd, "cpu", "some", 12345, 2.5, 1.25, 0.5),
+		pressure(sshd, "cpu", "full", 6789, 1, 0.5, 0.25),
+		pressure(sshd, "memory", "some", 42, 0, 0, 0),
+		pressure(sshd, "memory", "full", 21, 0, 0, 0),
+		pressure(cron, "cpu", "some", 100, 0, 0, 0),
+		pressure(cron, "cpu", "full", 50, 0, 0, 0),
+	} {
+		expected = append(expected, m...)
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherSystemWideMissing(t *testing.T) {
+	plugin := &PSI{
+		Resources:  []string{"irq"},
+		SystemWide: true,
+		ProcPath:   "testdata/proc",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "reading irq pressure failed")
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := parse([]byte("some avg10=abc avg60=0.00 avg300=0.00 total=0\n"))
+	require.ErrorContains(t, err, `parsing "avg10=abc" failed`)
+
+	_, err = parse([]byte("partial avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"))
+	require.ErrorContains(t, err, `unknown type "partial"`)
+
+	_, err = parse([]byte("some avg10\n"))
+	require.ErrorContains(t, err, `invalid entry "avg10"`)
+}
+
+// pressure returns the counter and gauge metric of a pressure line
+func pressure(extra map[string]string, resource, typ string, total uint64, avg10, avg60, avg300 float64) []telegraf.Metric {
+	tags := map[string]string{
+		"resource": resource,
+		"type":     typ,
+	}
+	for k, v := range extra {
+		tags[k] = v
+	}
+
+	return []telegraf.Metric{
+		metric.New(
+			"pressure",
+			tags,
+			map[string]interface{}{"total": total},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"pressure",
+			tags,
+			map[string]interface{}{
+				"avg10":  avg10,
+				"avg60":  avg60,
+				"avg300": avg300,
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+}
@@ -0,0 +1,89 @@
+package redis_stream_consumer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type client interface {
+	createGroup(ctx context.Context, stream, group, start string) error
+	readGroup(ctx context.Context, args *redis.XReadGroupArgs) ([]redis.XStream, error)
+	autoClaim(ctx context.Context, args *redis.XAutoClaimArgs) ([]redis.XMessage, string, error)
+	ack(ctx context.Context, stream, group string, ids ...string) error
+	Close() error
+}
+
+type redisClient struct {
+	client *redis.Client
+}
+
+func (c *redisClient) createGroup(ctx context.Context, stream, group, start string) error {
+	err := c.client.XGroupCreateMkStream(ctx, stream, group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		// The group already exists
+		return nil
+	}
+	return err
+}
+
+func (c *redisClient) readGroup(ctx context.Context, args *redis.XReadGroupArgs) ([]redis.XStream, error) {
+	return c.client.XReadGroup(ctx, args).Result()
+}
+
+// autoClaim issues the XAUTOCLAIM command directly as the reply contains a
+// third element, the list of deleted messages, since Redis 7 which is not
+// supported by the library.
+func (c *redisClient) autoClaim(ctx context.Context, args *redis.XAutoClaimArgs) ([]redis.XMessage, string, error) {
+	reply, err := c.client.Do(ctx,
+		"xautoclaim", args.Stream, args.Group, args.Consumer,
+		args.MinIdle.Milliseconds(), args.Start, "count", args.Count,
+	).Slice()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(reply) < 2 {
+		return nil, "", fmt.Errorf("unexpected reply length %d", len(reply))
+	}
+
+	next, ok := reply[0].(string)
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected cursor type %T", reply[0])
+	}
+	raw, ok := reply[1].([]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected messages type %T", reply[1])
+	}
+
+	msgs := make([]redis.XMessage, 0, len(raw))
+	for _, r := range raw {
+		// Redis 6.2 reports deleted messages as nil
+		item, ok := r.([]interface{})
+		if !ok || len(item) != 2 {
+			continue
+		}
+		id, ok := item[0].(string)
+		if !ok {
+			continue
+		}
+		kv, _ := item[1].([]interface{})
+		values := make(map[string]interface{}, len(kv)/2)
+		for i := 0; i+1 < len(kv); i += 2 {
+			if key, ok := kv[i].(string); ok {
+				values[key] = kv[i+1]
+			}
+		}
+		msgs = append(msgs, redis.XMessage{ID: id, Values: values})
+	}
+	return msgs, next, nil
+}
+
+func (c *redisClient) ack(ctx context.Context, stream, group string, ids ...string) error {
+	return c.client.XAck(ctx, stream, group, ids...).Err()
+}
+
+func (c *redisClient) Close() error {
+	return c.client.Close()
+}
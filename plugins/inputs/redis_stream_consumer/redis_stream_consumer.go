@@ -0,0 +1,455 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package redis_stream_consumer
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Stream struct {
+	Name         string `toml:"name"`
+	StartID      string `toml:"start_id"`
+	PayloadField string `toml:"payload_field"`
+	DataFormat   string `toml:"data_format"`
+
+	parser telegraf.Parser
+}
+
+type RedisStreamConsumer struct {
+	Server                 string          `toml:"server"`
+	Username               config.Secret   `toml:"username"`
+	Password               config.Secret   `toml:"password"`
+	Group                  string          `toml:"group"`
+	Consumer               string          `toml:"consumer"`
+	Streams                []*Stream       `toml:"stream"`
+	BatchSize              int             `toml:"batch_size"`
+	BlockTimeout           config.Duration `toml:"block_timeout"`
+	ClaimMinIdle           config.Duration `toml:"claim_min_idle"`
+	StreamTag              string          `toml:"stream_tag"`
+	MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
+	Log                    telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	parserFunc telegraf.ParserFunc
+	client     client
+	streams    map[string]*Stream
+	keys       []string
+	cursors    map[string]string
+	acc        telegraf.TrackingAccumulator
+	sem        chan struct{}
+
+	messages map[telegraf.TrackingID]entry
+	inflight map[entry]bool
+	sync.Mutex
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+// entry identifies a message within the streams
+type entry struct {
+	stream string
+	id     string
+}
+
+func (*RedisStreamConsumer) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RedisStreamConsumer) SetParserFunc(fn telegraf.ParserFunc) {
+	r.parserFunc = fn
+}
+
+func (r *RedisStreamConsumer) Init() error {
+	if r.Group == "" {
+		return errors.New("consumer group required")
+	}
+	if len(r.Streams) == 0 {
+		return errors.New("no streams specified")
+	}
+	if r.BatchSize < 1 {
+		return fmt.Errorf("invalid batch_size %d", r.BatchSize)
+	}
+	if r.BlockTimeout <= 0 {
+		return errors.New("block_timeout must be positive")
+	}
+	if r.MaxUndeliveredMessages < 1 {
+		return fmt.Errorf("invalid max_undelivered_messages %d", r.MaxUndeliveredMessages)
+	}
+
+	if r.Consumer == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("getting hostname for consumer name failed: %w", err)
+		}
+		r.Consumer = hostname
+	}
+
+	r.streams = make(map[string]*Stream, len(r.Streams))
+	r.keys = make([]string, 0, 2*len(r.Streams))
+	for _, s := range r.Streams {
+		if s.Name == "" {
+			return errors.New("stream without name")
+		}
+		if _, found := r.streams[s.Name]; found {
+			return fmt.Errorf("duplicate stream %q", s.Name)
+		}
+		if s.StartID == "" {
+			s.StartID = "$"
+		}
+		if s.PayloadField == "" {
+			s.PayloadField = "payload"
+		}
+
+		// Streams without a data format use the parser of the plugin
+		if s.DataFormat != "" {
+			creator, found := parsers.Parsers[s.DataFormat]
+			if !found {
+				return fmt.Errorf("undefined data format %q for stream %q", s.DataFormat, s.Name)
+			}
+			s.parser = creator("redis_stream_consumer")
+			if p, ok := s.parser.(telegraf.Initializer); ok {
+				if err := p.Init(); err != nil {
+					return fmt.Errorf("initializing parser for stream %q failed: %w", s.Name, err)
+				}
+			}
+		} else {
+			if r.parserFunc == nil {
+				return fmt.Errorf("no parser for stream %q", s.Name)
+			}
+			parser, err := r.parserFunc()
+			if err != nil {
+				return fmt.Errorf("creating parser for stream %q failed: %w", s.Name, err)
+			}
+			s.parser = parser
+		}
+
+		r.streams[s.Name] = s
+		r.keys = append(r.keys, s.Name)
+	}
+
+	// Only read messages never delivered to any consumer of the group
+	for range r.Streams {
+		r.keys = append(r.keys, ">")
+	}
+
+	return nil
+}
+
+func (r *RedisStreamConsumer) Start(acc telegraf.Accumulator) error {
+	if r.client == nil {
+		c, err := r.connect()
+		if err != nil {
+			return err
+		}
+		r.client = c
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, s := range r.Streams {
+		if err := r.client.createGroup(ctx, s.Name, r.Group, s.StartID); err != nil {
+			r.client.Close()
+			return fmt.Errorf("creating group %q for stream %q failed: %w", r.Group, s.Name, err)
+		}
+	}
+
+	r.acc = acc.WithTracking(r.MaxUndeliveredMessages)
+	r.sem = make(chan struct{}, r.MaxUndeliveredMessages)
+	r.messages = make(map[telegraf.TrackingID]entry)
+	r.inflight = make(map[entry]bool)
+	r.cursors = make(map[string]string, len(r.Streams))
+
+	ctx, r.cancel = context.WithCancel(context.Background())
+
+	r.wg.Add(2)
+	go func() {
+		defer r.wg.Done()
+		r.onDelivery(ctx)
+	}()
+	go func() {
+		defer r.wg.Done()
+		r.receive(ctx)
+	}()
+
+	return nil
+}
+
+func (r *RedisStreamConsumer) connect() (client, error) {
+	serv := r.Server
+	if !strings.HasPrefix(serv, "tcp://") && !strings.HasPrefix(serv, "unix://") {
+		serv = "tcp://" + serv
+	}
+
+	u, err := url.Parse(serv)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse to address %q: %w", serv, err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+	if !r.Username.Empty() {
+		secret, err := r.Username.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting username failed: %w", err)
+		}
+		username = secret.String()
+		secret.Destroy()
+	}
+	if !r.Password.Empty() {
+		secret, err := r.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		password = secret.String()
+		secret.Destroy()
+	}
+
+	address := u.Host
+	if u.Scheme == "unix" {
+		address = u.Path
+	}
+
+	tlsConfig, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	c := redis.NewClient(&redis.Options{
+		Addr:      address,
+		Username:  username,
+		Password:  password,
+		Network:   u.Scheme,
+		PoolSize:  2,
+		TLSConfig: tlsConfig,
+	})
+	return &redisClient{client: c}, nil
+}
+
+func (*RedisStreamConsumer) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (r *RedisStreamConsumer) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	// Closing the client interrupts blocking reads
+	if r.client != nil {
+		r.client.Close()
+	}
+	r.wg.Wait()
+}
+
+// receive reads new messages of the streams and claims the pending messages
+// of other consumers until the context is cancelled
+func (r *RedisStreamConsumer) receive(ctx context.Context) {
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		if r.ClaimMinIdle > 0 && time.Since(lastClaim) >= time.Duration(r.ClaimMinIdle) {
+			if !r.claim(ctx) {
+				return
+			}
+			lastClaim = time.Now()
+		}
+
+		streams, err := r.client.readGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.Group,
+			Consumer: r.Consumer,
+			Streams:  r.keys,
+			Count:    int64(r.BatchSize),
+			Block:    time.Duration(r.BlockTimeout),
+		})
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			r.acc.AddError(fmt.Errorf("reading streams failed: %w", err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(r.BlockTimeout)):
+			}
+			continue
+		}
+
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				if !r.onMessage(ctx, s.Stream, msg) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// claim takes over messages pending for longer than the configured idle time,
+// e.g. because their consumer died or the outputs rejected the metrics
+func (r *RedisStreamConsumer) claim(ctx context.Context) bool {
+	for _, s := range r.Streams {
+		start := r.cursors[s.Name]
+		if start == "" {
+			start = "0-0"
+		}
+		msgs, next, err := r.client.autoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   s.Name,
+			Group:    r.Group,
+			Consumer: r.Consumer,
+			MinIdle:  time.Duration(r.ClaimMinIdle),
+			Start:    start,
+			Count:    int64(r.BatchSize),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			r.acc.AddError(fmt.Errorf("claiming pending messages of stream %q failed: %w", s.Name, err))
+			continue
+		}
+		r.cursors[s.Name] = next
+
+		for _, msg := range msgs {
+			// Skip messages still waiting for the outputs
+			r.Lock()
+			busy := r.inflight[entry{stream: s.Name, id: msg.ID}]
+			r.Unlock()
+			if busy {
+				continue
+			}
+			if !r.onMessage(ctx, s.Name, msg) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *RedisStreamConsumer) onMessage(ctx context.Context, stream string, msg redis.XMessage) bool {
+	// Wait for a free slot, unhandled messages stay pending and can be
+	// claimed by other consumers
+	select {
+	case <-ctx.Done():
+		return false
+	case r.sem <- struct{}{}:
+	}
+
+	s := r.streams[stream]
+	e := entry{stream: stream, id: msg.ID}
+
+	payload, found := msg.Values[s.PayloadField]
+	if !found {
+		r.acc.AddError(fmt.Errorf("message %q of stream %q has no field %q", msg.ID, stream, s.PayloadField))
+		r.ack(e)
+		<-r.sem
+		return true
+	}
+
+	var data []byte
+	switch v := payload.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		data = []byte(fmt.Sprintf("%v", v))
+	}
+
+	// The message will never be parseable, so remove it from the group
+	metrics, err := s.parser.Parse(data)
+	if err != nil {
+		r.acc.AddError(fmt.Errorf("parsing message %q of stream %q failed: %w", msg.ID, stream, err))
+		r.ack(e)
+		<-r.sem
+		return true
+	}
+	if len(metrics) == 0 {
+		r.ack(e)
+		<-r.sem
+		return true
+	}
+
+	if r.StreamTag != "" {
+		for _, m := range metrics {
+			m.AddTag(r.StreamTag, stream)
+		}
+	}
+
+	r.Lock()
+	id := r.acc.AddTrackingMetricGroup(metrics)
+	r.messages[id] = e
+	r.inflight[e] = true
+	r.Unlock()
+
+	return true
+}
+
+func (r *RedisStreamConsumer) onDelivery(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case track := <-r.acc.Delivered():
+			r.Lock()
+			e, found := r.messages[track.ID()]
+			delete(r.messages, track.ID())
+			delete(r.inflight, e)
+			r.Unlock()
+			if !found {
+				r.Log.Errorf("Could not mark message delivered: %d", track.ID())
+				continue
+			}
+
+			// Messages not accepted by the outputs stay pending and are
+			// claimed again after the idle time
+			if track.Delivered() {
+				r.ack(e)
+			}
+			<-r.sem
+		}
+	}
+}
+
+func (r *RedisStreamConsumer) ack(e entry) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.client.ack(ctx, e.stream, r.Group, e.id); err != nil {
+		r.Log.Errorf("Acknowledging message %q of stream %q failed: %v", e.id, e.stream, err)
+	}
+}
+
+func init() {
+	inputs.Add("redis_stream_consumer", func() telegraf.Input {
+		return &RedisStreamConsumer{
+			Server:                 "tcp://localhost:6379",
+			Group:                  "telegraf",
+			BatchSize:              100,
+			BlockTimeout:           config.Duration(5 * time.Second),
+			ClaimMinIdle:           config.Duration(5 * time.Minute),
+			StreamTag:              "stream",
+			MaxUndeliveredMessages: 1000,
+		}
+	})
+}
@@ -0,0 +1,349 @@
+package redis_stream_consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/parsers/json"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeClient struct {
+	groups    map[string]string
+	messages  []redis.XStream
+	claimable map[string][]redis.XMessage
+	acked     []string
+	sync.Mutex
+}
+
+func (c *fakeClient) createGroup(_ context.Context, stream, _, start string) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.groups == nil {
+		c.groups = make(map[string]string)
+	}
+	c.groups[stream] = start
+	return nil
+}
+
+func (c *fakeClient) readGroup(ctx context.Context, args *redis.XReadGroupArgs) ([]redis.XStream, error) {
+	c.Lock()
+	messages := c.messages
+	c.messages = nil
+	c.Unlock()
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(args.Block):
+	}
+	return nil, redis.Nil
+}
+
+func (c *fakeClient) autoClaim(_ context.Context, args *redis.XAutoClaimArgs) ([]redis.XMessage, string, error) {
+	c.Lock()
+	defer c.Unlock()
+	msgs := c.claimable[args.Stream]
+	delete(c.claimable, args.Stream)
+	return msgs, "0-0", nil
+}
+
+func (c *fakeClient) ack(_ context.Context, stream, _ string, ids ...string) error {
+	c.Lock()
+	defer c.Unlock()
+	for _, id := range ids {
+		c.acked = append(c.acked, stream+"/"+id)
+	}
+	return nil
+}
+
+func (c *fakeClient) getAcked() []string {
+	c.Lock()
+	defer c.Unlock()
+	return append([]string(nil), c.acked...)
+}
+
+func (*fakeClient) Close() error {
+	return nil
+}
+
+func influxParserFunc() (telegraf.Parser, error) {
+	parser := &influx.Parser{}
+	err := parser.Init()
+	return parser, err
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RedisStreamConsumer
+		expected string
+	}{
+		{
+			name:     "no group",
+			plugin:   &RedisStreamConsumer{},
+			expected: "consumer group required",
+		},
+		{
+			name:     "no streams",
+			plugin:   &RedisStreamConsumer{Group: "telegraf"},
+			expected: "no streams specified",
+		},
+		{
+			name: "no block timeout",
+			plugin: &RedisStreamConsumer{
+				Group:                  "telegraf",
+				Streams:                []*Stream{{Name: "telegraf"}},
+				BatchSize:              10,
+				MaxUndeliveredMessages: 10,
+			},
+			expected: "block_timeout must be positive",
+		},
+		{
+			name: "duplicate stream",
+			plugin: &RedisStreamConsumer{
+				Group:                  "telegraf",
+				Streams:                []*Stream{{Name: "telegraf"}, {Name: "telegraf"}},
+				BatchSize:              10,
+				BlockTimeout:           config.Duration(time.Second),
+				MaxUndeliveredMessages: 10,
+				parserFunc:             influxParserFunc,
+			},
+			expected: `duplicate stream "telegraf"`,
+		},
+		{
+			name: "unknown data format",
+			plugin: &RedisStreamConsumer{
+				Group:                  "telegraf",
+				Streams:                []*Stream{{Name: "telegraf", DataFormat: "foo"}},
+				BatchSize:              10,
+				BlockTimeout:           config.Duration(time.Second),
+				MaxUndeliveredMessages: 10,
+			},
+			expected: `undefined data format "foo" for stream "telegraf"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitParserSelection(t *testing.T) {
+	plugin := &RedisStreamConsumer{
+		Group: "telegraf",
+		Streams: []*Stream{
+			{Name: "influx"},
+			{Name: "json", DataFormat: "json"},
+		},
+		BatchSize:              10,
+		BlockTimeout:           config.Duration(time.Second),
+		MaxUndeliveredMessages: 10,
+	}
+	plugin.SetParserFunc(influxParserFunc)
+	require.NoError(t, plugin.Init())
+
+	require.IsType(t, &influx.Parser{}, plugin.Streams[0].parser)
+	require.IsType(t, &json.Parser{}, plugin.Streams[1].parser)
+	require.Equal(t, "$", plugin.Streams[0].StartID)
+	require.Equal(t, "payload", plugin.Streams[0].PayloadField)
+	require.NotEmpty(t, plugin.Consumer)
+	require.Equal(t, []string{"influx", "json", ">", ">"}, plugin.keys)
+}
+
+func TestConsume(t *testing.T) {
+	client := &fakeClient{
+		messages: []redis.XStream{
+			{
+				Stream: "influx",
+				Messages: []redis.XMessage{
+					{ID: "1-0", Values: map[string]interface{}{"payload": "cpu,host=a value=42 1"}},
+					{ID: "2-0", Values: map[string]interface{}{"payload": "cpu,host=b value=23 2"}},
+					{ID: "3-0", Values: map[string]interface{}{"payload": "garbage"}},
+					{ID: "4-0", Values: map[string]interface{}{"data": "cpu value=1 3"}},
+				},
+			},
+			{
+				Stream: "json",
+				Messages: []redis.XMessage{
+					{ID: "1-0", Values: map[string]interface{}{"msg": `{"value": 5}`}},
+				},
+			},
+		},
+	}
+
+	plugin := &RedisStreamConsumer{
+		Group:    "telegraf",
+		Consumer: "test",
+		Streams: []*Stream{
+			{Name: "influx", StartID: "0"},
+			{Name: "json", DataFormat: "json", PayloadField: "msg"},
+		},
+		BatchSize:              10,
+		BlockTimeout:           config.Duration(10 * time.Millisecond),
+		StreamTag:              "stream",
+		MaxUndeliveredMessages: 10,
+		Log:                    testutil.Logger{},
+		client:                 client,
+	}
+	plugin.SetParserFunc(influxParserFunc)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Equal(t, map[string]string{"influx": "0", "json": "$"}, client.groups)
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a", "stream": "influx"},
+			map[string]interface{}{"value": float64(42)},
+			time.Unix(0, 1),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "b", "stream": "influx"},
+			map[string]interface{}{"value": float64(23)},
+			time.Unix(0, 2),
+		),
+		testutil.MustMetric(
+			"redis_stream_consumer",
+			map[string]string{"stream": "json"},
+			map[string]interface{}{"value": float64(5)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Unparseable messages and messages without payload are dropped
+	require.Len(t, acc.Errors, 2)
+	require.ElementsMatch(t, []string{"influx/3-0", "influx/4-0"}, client.getAcked())
+
+	// Only accepted messages must be acknowledged
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["host"] == "b" {
+			m.Reject()
+		} else {
+			m.Accept()
+		}
+	}
+	require.Eventually(t, func() bool {
+		return len(client.getAcked()) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	require.ElementsMatch(t, []string{"influx/1-0", "influx/3-0", "influx/4-0", "json/1-0"}, client.getAcked())
+}
+
+func TestClaim(t *testing.T) {
+	client := &fakeClient{
+		claimable: map[string][]redis.XMessage{
+			"telegraf": {
+				{ID: "1-0", Values: map[string]interface{}{"payload": "cpu value=42"}},
+			},
+		},
+	}
+
+	plugin := &RedisStreamConsumer{
+		Group:                  "telegraf",
+		Streams:                []*Stream{{Name: "telegraf"}},
+		BatchSize:              10,
+		BlockTimeout:           config.Duration(10 * time.Millisecond),
+		ClaimMinIdle:           config.Duration(time.Minute),
+		MaxUndeliveredMessages: 10,
+		Log:                    testutil.Logger{},
+		client:                 client,
+	}
+	plugin.SetParserFunc(influxParserFunc)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 1
+	}, 5*time.Second, 10*time.Millisecond)
+	acc.GetTelegrafMetrics()[0].Accept()
+	require.Eventually(t, func() bool {
+		return len(client.getAcked()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"telegraf/1-0"}, client.getAcked())
+}
+
+func TestConsumeIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	servicePort := "6379"
+	container := testutil.Container{
+		Image:        "redis:alpine",
+		ExposedPorts: []string{servicePort},
+		WaitingFor:   wait.ForListeningPort(nat.Port(servicePort)),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	addr := fmt.Sprintf("%s:%s", container.Address, container.Ports[servicePort])
+
+	plugin := &RedisStreamConsumer{
+		Server:                 "tcp://" + addr,
+		Group:                  "telegraf",
+		Streams:                []*Stream{{Name: "telegraf", StartID: "0"}},
+		BatchSize:              10,
+		BlockTimeout:           config.Duration(100 * time.Millisecond),
+		ClaimMinIdle:           config.Duration(time.Second),
+		MaxUndeliveredMessages: 10,
+		Log:                    testutil.Logger{},
+	}
+	plugin.SetParserFunc(influxParserFunc)
+	require.NoError(t, plugin.Init())
+
+	producer := redis.NewClient(&redis.Options{Addr: addr})
+	defer producer.Close()
+	ctx := context.Background()
+	require.NoError(t, producer.XAdd(ctx, &redis.XAddArgs{
+		Stream: "telegraf",
+		Values: map[string]interface{}{"payload": "cpu value=42"},
+	}).Err())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// Rejected messages are claimed again after the idle time
+	acc.GetTelegrafMetrics()[0].Reject()
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= 2
+	}, 10*time.Second, 100*time.Millisecond)
+
+	acc.GetTelegrafMetrics()[1].Accept()
+	require.Eventually(t, func() bool {
+		pending, err := producer.XPending(ctx, "telegraf", "telegraf").Result()
+		return err == nil && pending.Count == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -633,6 +633,94 @@ func TestExpandFlowCounter(t *testing.T) {
 	actual := makeMetrics(p)
 
 	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"sflow_interface",
+			map[string]string{
+				"agent_address":   "10.0.1.80",
+				"if_direction":    "full-duplex",
+				"if_index":        "1054596",
+				"if_type":         "6",
+				"source_id_index": "1054596",
+				"source_id_type":  "0",
+			},
+			map[string]interface{}{
+				"dot3_alignment_errors":             uint64(0x00),
+				"dot3_carrier_sense_errors":         uint64(0x00),
+				"dot3_deferred_transmissions":       uint64(0x00),
+				"dot3_excessive_collisions":         uint64(0x00),
+				"dot3_fcs_errors":                   uint64(0x00),
+				"dot3_frame_too_longs":              uint64(0x00),
+				"dot3_internal_mac_receive_errors":  uint64(0x00),
+				"dot3_internal_mac_transmit_errors": uint64(0x00),
+				"dot3_late_collisions":              uint64(0x00),
+				"dot3_multiple_collision_frames":    uint64(0x00),
+				"dot3_single_collision_frames":      uint64(0x00),
+				"dot3_sqe_test_errors":              uint64(0x00),
+				"dot3_symbol_errors":                uint64(0x00),
+				"if_admin_up":                       true,
+				"if_oper_up":                        true,
+				"if_promiscuous":                    true,
+				"if_speed":                          uint64(0x02540be400),
+				"in_broadcast_pkts":                 uint64(0x08ffb2b5),
+				"in_discards":                       uint64(0x00),
+				"in_errors":                         uint64(0x00),
+				"in_multicast_pkts":                 uint64(0x0803e8e9),
+				"in_octets":                         uint64(0x7b8ebd37b97e),
+				"in_ucast_pkts":                     uint64(0x61ff9486),
+				"in_unknown_protos":                 uint64(0x00),
+				"out_broadcast_pkts":                uint64(0x21ba9363),
+				"out_discards":                      uint64(0x00),
+				"out_errors":                        uint64(0x00),
+				"out_multicast_pkts":                uint64(0x74579ff0),
+				"out_octets":                        uint64(0x018e7c31ee7ba4),
+				"out_ucast_pkts":                    uint64(0x195f0418),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"sflow_interface",
+			map[string]string{
+				"agent_address":   "10.0.1.80",
+				"if_direction":    "full-duplex",
+				"if_index":        "1048964",
+				"if_type":         "6",
+				"source_id_index": "1048964",
+				"source_id_type":  "0",
+			},
+			map[string]interface{}{
+				"dot3_alignment_errors":             uint64(0x00),
+				"dot3_carrier_sense_errors":         uint64(0x00),
+				"dot3_deferred_transmissions":       uint64(0x00),
+				"dot3_excessive_collisions":         uint64(0x00),
+				"dot3_fcs_errors":                   uint64(0x00),
+				"dot3_frame_too_longs":              uint64(0x00),
+				"dot3_internal_mac_receive_errors":  uint64(0x00),
+				"dot3_internal_mac_transmit_errors": uint64(0x00),
+				"dot3_late_collisions":              uint64(0x00),
+				"dot3_multiple_collision_frames":    uint64(0x00),
+				"dot3_single_collision_frames":      uint64(0x00),
+				"dot3_sqe_test_errors":              uint64(0x00),
+				"dot3_symbol_errors":                uint64(0x00),
+				"if_admin_up":                       true,
+				"if_oper_up":                        true,
+				"if_promiscuous":                    true,
+				"if_speed":                          uint64(0x02540be400),
+				"in_broadcast_pkts":                 uint64(0x01e65989),
+				"in_discards":                       uint64(0x00),
+				"in_errors":                         uint64(0x00),
+				"in_multicast_pkts":                 uint64(0x03617cb4),
+				"in_octets":                         uint64(0x0841131d1fd9),
+				"in_ucast_pkts":                     uint64(0xf850bfb1),
+				"in_unknown_protos":                 uint64(0x00),
+				"out_broadcast_pkts":                uint64(0x22513250),
+				"out_discards":                      uint64(0x00),
+				"out_errors":                        uint64(0x00),
+				"out_multicast_pkts":                uint64(0x6d7996e9),
+				"out_octets":                        uint64(0x0bec1902e5da),
+				"out_ucast_pkts":                    uint64(0x9212e3e9),
+			},
+			time.Unix(0, 0),
+		),
 		testutil.MustMetric(
 			"sflow",
 			map[string]string{
@@ -834,7 +922,203 @@ func TestFlowExpandCounter(t *testing.T) {
 	require.NoError(t, err)
 	actual := makeMetrics(p)
 
-	// we don't do anything with samples yet
-	expected := []telegraf.Metric{}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"sflow_interface",
+			map[string]string{
+				"agent_address":   "10.0.1.80",
+				"if_direction":    "full-duplex",
+				"if_index":        "1258342912",
+				"if_type":         "1",
+				"source_id_index": "1258342912",
+				"source_id_type":  "0",
+			},
+			map[string]interface{}{
+				"dot3_alignment_errors":             uint64(0x00),
+				"dot3_carrier_sense_errors":         uint64(0x00),
+				"dot3_deferred_transmissions":       uint64(0x00),
+				"dot3_excessive_collisions":         uint64(0x00),
+				"dot3_fcs_errors":                   uint64(0x00),
+				"dot3_frame_too_longs":              uint64(0x00),
+				"dot3_internal_mac_receive_errors":  uint64(0x00),
+				"dot3_internal_mac_transmit_errors": uint64(0x00),
+				"dot3_late_collisions":              uint64(0x00),
+				"dot3_multiple_collision_frames":    uint64(0x00),
+				"dot3_single_collision_frames":      uint64(0x00),
+				"dot3_sqe_test_errors":              uint64(0x00),
+				"dot3_symbol_errors":                uint64(0x00),
+				"if_admin_up":                       true,
+				"if_oper_up":                        false,
+				"if_promiscuous":                    true,
+				"if_speed":                          uint64(0x00),
+				"in_broadcast_pkts":                 uint64(0x06899571),
+				"in_discards":                       uint64(0x00),
+				"in_errors":                         uint64(0x00),
+				"in_multicast_pkts":                 uint64(0x4d0bb4),
+				"in_octets":                         uint64(0x308ae33bb950),
+				"in_ucast_pkts":                     uint64(0xeb92a8a3),
+				"in_unknown_protos":                 uint64(0x00),
+				"out_broadcast_pkts":                uint64(0x04636edb),
+				"out_discards":                      uint64(0x00),
+				"out_errors":                        uint64(0x00),
+				"out_multicast_pkts":                uint64(0x04eaf0bd),
+				"out_octets":                        uint64(0x12f7ed9c9db8),
+				"out_ucast_pkts":                    uint64(0xc24ed906),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"sflow_interface",
+			map[string]string{
+				"agent_address":   "10.0.1.80",
+				"if_direction":    "full-duplex",
+				"if_index":        "1258312704",
+				"if_type":         "1",
+				"source_id_index": "1258312704",
+				"source_id_type":  "0",
+			},
+			map[string]interface{}{
+				"dot3_alignment_errors":             uint64(0x00),
+				"dot3_carrier_sense_errors":         uint64(0x00),
+				"dot3_deferred_transmissions":       uint64(0x00),
+				"dot3_excessive_collisions":         uint64(0x00),
+				"dot3_fcs_errors":                   uint64(0x00),
+				"dot3_frame_too_longs":              uint64(0x00),
+				"dot3_internal_mac_receive_errors":  uint64(0x00),
+				"dot3_internal_mac_transmit_errors": uint64(0x00),
+				"dot3_late_collisions":              uint64(0x00),
+				"dot3_multiple_collision_frames":    uint64(0x00),
+				"dot3_single_collision_frames":      uint64(0x00),
+				"dot3_sqe_test_errors":              uint64(0x00),
+				"dot3_symbol_errors":                uint64(0x00),
+				"if_admin_up":                       true,
+				"if_oper_up":                        true,
+				"if_promiscuous":                    true,
+				"if_speed":                          uint64(0x3b9aca00),
+				"in_broadcast_pkts":                 uint64(0x210866),
+				"in_discards":                       uint64(0x00),
+				"in_errors":                         uint64(0x00),
+				"in_multicast_pkts":                 uint64(0x0215ec4a),
+				"in_octets":                         uint64(0x67ba8e64fd23),
+				"in_ucast_pkts":                     uint64(0xfa65f26d),
+				"in_unknown_protos":                 uint64(0x00),
+				"out_broadcast_pkts":                uint64(0x061872),
+				"out_discards":                      uint64(0x00),
+				"out_errors":                        uint64(0x00),
+				"out_multicast_pkts":                uint64(0x1fb2f3),
+				"out_octets":                        uint64(0x2002c3b21045),
+				"out_ucast_pkts":                    uint64(0xc2378ad3),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestCounterSampleHostAndVM(t *testing.T) {
+	packet, err := hex.DecodeString(
+		"00000005" + // version
+			"00000001" + "c0a80001" + // agent address 192.168.0.1
+			"00000000" + "00000001" + "00000064" + // sub agent id, sequence number, uptime
+			"00000002" + // number of samples
+			// counters_sample for the host
+			"00000002" + "000000bc" + // sample type, length
+			"00000001" + "02000005" + // sequence number, source id type 2 index 5
+			"00000003" + // number of records
+			"000007d0" + "0000002c" + // host_descr, length
+			"00000005" + "686f737431000000" + // hostname "host1"
+			"00112233445566778899aabbccddeeff" + // uuid
+			"00000003" + "00000002" + // machine type x86_64, os linux
+			"00000004" + "352e3130" + // os release "5.10"
+			"000007d3" + "00000044" + // host_cpu, length
+			"3f000000" + "3f800000" + "3fc00000" + // load one, five, fifteen
+			"00000002" + "000000c8" + "00000004" + "00000bb8" + "00015180" + // proc run, total, cpu num, speed, uptime
+			"00000064" + "00000001" + "00000032" + "000003e8" + "00000005" + "00000006" + "00000007" + // cpu times
+			"00001000" + "00002000" + // interrupts, contexts
+			"000007d6" + "00000028" + // host_net_io, length
+			"0000000000010000" + "00000100" + "00000001" + "00000002" + // bytes, pkts, errs, drops in
+			"0000000000020000" + "00000200" + "00000003" + "00000004" + // bytes, pkts, errs, drops out
+			// counters_sample_expanded for a virtual machine
+			"00000004" + "00000064" + // sample type, length
+			"00000002" + "00000003" + "00000007" + // sequence number, source id type, index
+			"00000003" + // number of records
+			"000007d0" + "00000024" + // host_descr, length
+			"00000003" + "766d3100" + // hostname "vm1"
+			"00000000000000000000000000000000" + // uuid
+			"00000003" + "00000002" + // machine type x86_64, os linux
+			"00000000" + // os release
+			"000007d2" + "00000008" + // host_parent, length
+			"00000002" + "00000005" + // container type, index
+			"00000836" + "00000010" + // virt_memory, length
+			"0000000040000000" + "0000000080000000", // memory, max memory
+	)
+	require.NoError(t, err)
+
+	dc := NewDecoder()
+	p, err := dc.DecodeOnePacket(bytes.NewBuffer(packet))
+	require.NoError(t, err)
+	actual := makeMetrics(p)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"sflow_host",
+			map[string]string{
+				"agent_address":   "192.168.0.1",
+				"hostname":        "host1",
+				"machine_type":    "x86_64",
+				"os_name":         "linux",
+				"os_release":      "5.10",
+				"source_id_index": "5",
+				"source_id_type":  "2",
+				"uuid":            "00112233-4455-6677-8899-aabbccddeeff",
+			},
+			map[string]interface{}{
+				"load_one":     float64(0.5),
+				"load_five":    float64(1.0),
+				"load_fifteen": float64(1.5),
+				"proc_run":     uint64(2),
+				"proc_total":   uint64(200),
+				"cpu_num":      uint64(4),
+				"cpu_speed":    uint64(3000),
+				"uptime":       uint64(86400),
+				"cpu_user":     uint64(100),
+				"cpu_nice":     uint64(1),
+				"cpu_system":   uint64(50),
+				"cpu_idle":     uint64(1000),
+				"cpu_wio":      uint64(5),
+				"cpu_intr":     uint64(6),
+				"cpu_sintr":    uint64(7),
+				"interrupts":   uint64(4096),
+				"contexts":     uint64(8192),
+				"bytes_in":     uint64(65536),
+				"pkts_in":      uint64(256),
+				"errs_in":      uint64(1),
+				"drops_in":     uint64(2),
+				"bytes_out":    uint64(131072),
+				"pkts_out":     uint64(512),
+				"errs_out":     uint64(3),
+				"drops_out":    uint64(4),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"sflow_vm",
+			map[string]string{
+				"agent_address":   "192.168.0.1",
+				"hostname":        "vm1",
+				"machine_type":    "x86_64",
+				"os_name":         "linux",
+				"parent_index":    "5",
+				"parent_type":     "2",
+				"source_id_index": "7",
+				"source_id_type":  "3",
+			},
+			map[string]interface{}{
+				"memory":     uint64(0x40000000),
+				"max_memory": uint64(0x80000000),
+			},
+			time.Unix(0, 0),
+		),
+	}
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
 }
@@ -38,6 +38,72 @@ func makeMetrics(p *V5Format) []telegraf.Metric {
 				metrics = append(metrics, m)
 			}
 		}
+
+		metrics = append(metrics, makeCounterMetrics(p, sample.CounterData, now)...)
+	}
+	return metrics
+}
+
+// makeCounterMetrics merges the records of a counter sample into one metric
+// per measurement. Host structures sent along with virtual machine counters
+// describe the virtual machine and are reported with those.
+func makeCounterMetrics(p *V5Format, sample SampleDataCounterSample, now time.Time) []telegraf.Metric {
+	if len(sample.CounterRecords) == 0 {
+		return nil
+	}
+
+	isVM := false
+	for _, rec := range sample.CounterRecords {
+		switch rec.CounterFormat {
+		case CounterFormatTypeVirtCPU, CounterFormatTypeVirtMemory, CounterFormatTypeVirtDiskIO, CounterFormatTypeVirtNetIO:
+			isVM = true
+		}
+	}
+
+	var names []string
+	tags := make(map[string]map[string]string)
+	fields := make(map[string]map[string]interface{})
+	for _, rec := range sample.CounterRecords {
+		if rec.CounterData == nil {
+			continue
+		}
+
+		var name string
+		switch rec.CounterFormat {
+		case CounterFormatTypeGenericInterface, CounterFormatTypeEthernet:
+			name = "sflow_interface"
+		case CounterFormatTypeVirtCPU, CounterFormatTypeVirtMemory, CounterFormatTypeVirtDiskIO, CounterFormatTypeVirtNetIO:
+			name = "sflow_vm"
+		default:
+			name = "sflow_host"
+			if isVM {
+				name = "sflow_vm"
+			}
+		}
+
+		if _, found := fields[name]; !found {
+			names = append(names, name)
+			tags[name] = map[string]string{
+				"agent_address":   p.AgentAddress.String(),
+				"source_id_index": strconv.FormatUint(uint64(sample.SourceIDIndex), 10),
+				"source_id_type":  strconv.FormatUint(uint64(sample.SourceIDType), 10),
+			}
+			fields[name] = make(map[string]interface{})
+		}
+		for k, v := range rec.CounterData.GetTags() {
+			tags[name][k] = v
+		}
+		for k, v := range rec.CounterData.GetFields() {
+			fields[name][k] = v
+		}
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(names))
+	for _, name := range names {
+		if len(fields[name]) == 0 {
+			continue
+		}
+		metrics = append(metrics, metric.New(name, tags[name], fields[name], now))
 	}
 	return metrics
 }
@@ -130,6 +130,10 @@ func (d *PacketDecoder) decodeSample(r io.Reader) (Sample, error) {
 		sam.SampleData, err = d.decodeFlowSample(mr)
 	case SampleTypeFlowSampleExpanded:
 		sam.SampleData, err = d.decodeFlowSampleExpanded(mr)
+	case SampleTypeCounterSample:
+		sam.CounterData, err = d.decodeCounterSample(mr)
+	case SampleTypeCounterSampleExpanded:
+		sam.CounterData, err = d.decodeCounterSampleExpanded(mr)
 	default:
 		d.debug("Unknown sample type: ", sam.SampleType)
 	}
@@ -263,6 +267,137 @@ func (d *PacketDecoder) decodeFlowRecords(r io.Reader, samplingRate uint32) (rec
 	return recs, err
 }
 
+func (d *PacketDecoder) decodeCounterSample(r io.Reader) (t SampleDataCounterSample, err error) {
+	if err := read(r, &t.SequenceNumber, "SequenceNumber"); err != nil {
+		return t, err
+	}
+	var sourceID uint32
+	if err := read(r, &sourceID, "SourceID"); err != nil {
+		return t, err
+	}
+	t.SourceIDIndex = sourceID & 0x00ffffff
+	t.SourceIDType = sourceID >> 24
+
+	t.CounterRecords, err = d.decodeCounterRecords(r)
+	return t, err
+}
+
+func (d *PacketDecoder) decodeCounterSampleExpanded(r io.Reader) (t SampleDataCounterSample, err error) {
+	if err := read(r, &t.SequenceNumber, "SequenceNumber"); err != nil {
+		return t, err
+	}
+	if err := read(r, &t.SourceIDType, "SourceIDType"); err != nil {
+		return t, err
+	}
+	if err := read(r, &t.SourceIDIndex, "SourceIDIndex"); err != nil {
+		return t, err
+	}
+
+	t.CounterRecords, err = d.decodeCounterRecords(r)
+	return t, err
+}
+
+func (d *PacketDecoder) decodeCounterRecords(r io.Reader) (recs []CounterRecord, err error) {
+	var counterDataLen uint32
+	var count uint32
+	if err := read(r, &count, "CounterRecord count"); err != nil {
+		return recs, err
+	}
+	for i := uint32(0); i < count; i++ {
+		cr := CounterRecord{}
+		if err := read(r, &cr.CounterFormat, "CounterFormat"); err != nil {
+			return recs, err
+		}
+		if err := read(r, &counterDataLen, "Counter data length"); err != nil {
+			return recs, err
+		}
+
+		mr := binaryio.MinReader(r, int64(counterDataLen))
+
+		switch cr.CounterFormat {
+		case CounterFormatTypeGenericInterface:
+			var c GenericInterfaceCounters
+			err = read(mr, &c, "GenericInterfaceCounters")
+			cr.CounterData = c
+		case CounterFormatTypeEthernet:
+			var c EthernetCounters
+			err = read(mr, &c, "EthernetCounters")
+			cr.CounterData = c
+		case CounterFormatTypeProcessor:
+			var c ProcessorCounters
+			err = read(mr, &c, "ProcessorCounters")
+			cr.CounterData = c
+		case CounterFormatTypeHostDescr:
+			cr.CounterData, err = d.decodeHostDescr(mr)
+		case CounterFormatTypeHostParent:
+			var c HostParent
+			err = read(mr, &c, "HostParent")
+			cr.CounterData = c
+		case CounterFormatTypeHostCPU:
+			var c HostCPU
+			err = read(mr, &c, "HostCPU")
+			cr.CounterData = c
+		case CounterFormatTypeHostMemory:
+			var c HostMemory
+			err = read(mr, &c, "HostMemory")
+			cr.CounterData = c
+		case CounterFormatTypeHostDiskIO:
+			var c HostDiskIO
+			err = read(mr, &c, "HostDiskIO")
+			cr.CounterData = c
+		case CounterFormatTypeHostNetIO, CounterFormatTypeVirtNetIO:
+			var c HostNetIO
+			err = read(mr, &c, "NetIO")
+			cr.CounterData = c
+		case CounterFormatTypeVirtNode:
+			var c VirtNode
+			err = read(mr, &c, "VirtNode")
+			cr.CounterData = c
+		case CounterFormatTypeVirtCPU:
+			var c VirtCPU
+			err = read(mr, &c, "VirtCPU")
+			cr.CounterData = c
+		case CounterFormatTypeVirtMemory:
+			var c VirtMemory
+			err = read(mr, &c, "VirtMemory")
+			cr.CounterData = c
+		case CounterFormatTypeVirtDiskIO:
+			var c VirtDiskIO
+			err = read(mr, &c, "VirtDiskIO")
+			cr.CounterData = c
+		default:
+			d.debug("Unknown counter format: ", cr.CounterFormat)
+		}
+		if err != nil {
+			mr.Close()
+			return recs, err
+		}
+
+		recs = append(recs, cr)
+		mr.Close()
+	}
+
+	return recs, err
+}
+
+// https://sflow.org/sflow_host.txt host_descr
+func (d *PacketDecoder) decodeHostDescr(r io.Reader) (h HostDescr, err error) {
+	if h.Hostname, err = readString(r, "Hostname"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.UUID, "UUID"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.MachineType, "MachineType"); err != nil {
+		return h, err
+	}
+	if err := read(r, &h.OSName, "OSName"); err != nil {
+		return h, err
+	}
+	h.OSRelease, err = readString(r, "OSRelease")
+	return h, err
+}
+
 func (d *PacketDecoder) decodeRawPacketHeaderFlowData(r io.Reader, samplingRate uint32) (h RawPacketHeaderFlowData, err error) {
 	if err := read(r, &h.HeaderProtocol, "HeaderProtocol"); err != nil { // sflow_version_5.txt line 1940
 		return h, err
@@ -484,3 +619,20 @@ func read(r io.Reader, data interface{}, name string) error {
 	}
 	return nil
 }
+
+// readString reads a XDR string consisting of the length, the data and the
+// padding to the next multiple of four bytes
+func readString(r io.Reader, name string) (string, error) {
+	var length uint32
+	if err := read(r, &length, name+" length"); err != nil {
+		return "", err
+	}
+	if length > maxPacketSize {
+		return "", fmt.Errorf("invalid length %d of %q", length, name)
+	}
+	buf := make([]byte, (length+3)&^3)
+	if err := read(r, buf, name); err != nil {
+		return "", err
+	}
+	return string(buf[:length]), nil
+}
@@ -1,6 +1,7 @@
 package sflow
 
 import (
+	"fmt"
 	"net"
 	"strconv"
 )
@@ -34,15 +35,18 @@ type V5Format struct {
 type SampleType uint32
 
 const (
-	SampleTypeFlowSample         SampleType = 1 // sflow_version_5.txt line: 1614
-	SampleTypeFlowSampleExpanded SampleType = 3 // sflow_version_5.txt line: 1698
+	SampleTypeFlowSample            SampleType = 1 // sflow_version_5.txt line: 1614
+	SampleTypeCounterSample         SampleType = 2 // sflow_version_5.txt counters_sample
+	SampleTypeFlowSampleExpanded    SampleType = 3 // sflow_version_5.txt line: 1698
+	SampleTypeCounterSampleExpanded SampleType = 4 // sflow_version_5.txt counters_sample_expanded
 )
 
 type SampleData interface{}
 
 type Sample struct {
-	SampleType SampleType
-	SampleData SampleDataFlowSampleExpanded
+	SampleType  SampleType
+	SampleData  SampleDataFlowSampleExpanded
+	CounterData SampleDataCounterSample
 }
 
 type SampleDataFlowSampleExpanded struct {
@@ -291,3 +295,464 @@ func (h UDPHeader) GetFields() map[string]interface{} {
 		"udp_length": h.UDPLength,
 	}
 }
+
+type SampleDataCounterSample struct {
+	SequenceNumber uint32
+	SourceIDType   uint32
+	SourceIDIndex  uint32
+	CounterRecords []CounterRecord
+}
+
+type CounterFormatType uint32
+
+const (
+	CounterFormatTypeGenericInterface CounterFormatType = 1    // sflow_version_5.txt if_counters
+	CounterFormatTypeEthernet         CounterFormatType = 2    // sflow_version_5.txt ethernet_counters
+	CounterFormatTypeProcessor        CounterFormatType = 1001 // sflow_version_5.txt processor
+	CounterFormatTypeHostDescr        CounterFormatType = 2000 // sflow_host.txt
+	CounterFormatTypeHostParent       CounterFormatType = 2002 // sflow_host.txt
+	CounterFormatTypeHostCPU          CounterFormatType = 2003 // sflow_host.txt
+	CounterFormatTypeHostMemory       CounterFormatType = 2004 // sflow_host.txt
+	CounterFormatTypeHostDiskIO       CounterFormatType = 2005 // sflow_host.txt
+	CounterFormatTypeHostNetIO        CounterFormatType = 2006 // sflow_host.txt
+	CounterFormatTypeVirtNode         CounterFormatType = 2100 // sflow_host.txt
+	CounterFormatTypeVirtCPU          CounterFormatType = 2101 // sflow_host.txt
+	CounterFormatTypeVirtMemory       CounterFormatType = 2102 // sflow_host.txt
+	CounterFormatTypeVirtDiskIO       CounterFormatType = 2103 // sflow_host.txt
+	CounterFormatTypeVirtNetIO        CounterFormatType = 2104 // sflow_host.txt
+)
+
+type CounterData ContainsMetricData
+
+type CounterRecord struct {
+	CounterFormat CounterFormatType
+	CounterData   CounterData
+}
+
+var InterfaceDirectionMap = map[uint32]string{
+	0: "unknown",
+	1: "full-duplex",
+	2: "half-duplex",
+	3: "in",
+	4: "out",
+}
+
+// https://sflow.org/sflow_version_5.txt if_counters
+type GenericInterfaceCounters struct {
+	IfIndex            uint32
+	IfType             uint32
+	IfSpeed            uint64
+	IfDirection        uint32
+	IfStatus           uint32
+	IfInOctets         uint64
+	IfInUcastPkts      uint32
+	IfInMulticastPkts  uint32
+	IfInBroadcastPkts  uint32
+	IfInDiscards       uint32
+	IfInErrors         uint32
+	IfInUnknownProtos  uint32
+	IfOutOctets        uint64
+	IfOutUcastPkts     uint32
+	IfOutMulticastPkts uint32
+	IfOutBroadcastPkts uint32
+	IfOutDiscards      uint32
+	IfOutErrors        uint32
+	IfPromiscuousMode  uint32
+}
+
+func (c GenericInterfaceCounters) GetTags() map[string]string {
+	return map[string]string{
+		"if_index":     strconv.FormatUint(uint64(c.IfIndex), 10),
+		"if_type":      strconv.FormatUint(uint64(c.IfType), 10),
+		"if_direction": InterfaceDirectionMap[c.IfDirection],
+	}
+}
+func (c GenericInterfaceCounters) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"if_speed":           c.IfSpeed,
+		"if_admin_up":        c.IfStatus&0x1 != 0,
+		"if_oper_up":         c.IfStatus&0x2 != 0,
+		"if_promiscuous":     c.IfPromiscuousMode == 1,
+		"in_octets":          c.IfInOctets,
+		"in_ucast_pkts":      c.IfInUcastPkts,
+		"in_multicast_pkts":  c.IfInMulticastPkts,
+		"in_broadcast_pkts":  c.IfInBroadcastPkts,
+		"in_discards":        c.IfInDiscards,
+		"in_errors":          c.IfInErrors,
+		"in_unknown_protos":  c.IfInUnknownProtos,
+		"out_octets":         c.IfOutOctets,
+		"out_ucast_pkts":     c.IfOutUcastPkts,
+		"out_multicast_pkts": c.IfOutMulticastPkts,
+		"out_broadcast_pkts": c.IfOutBroadcastPkts,
+		"out_discards":       c.IfOutDiscards,
+		"out_errors":         c.IfOutErrors,
+	}
+}
+
+// https://sflow.org/sflow_version_5.txt ethernet_counters
+type EthernetCounters struct {
+	AlignmentErrors           uint32
+	FCSErrors                 uint32
+	SingleCollisionFrames     uint32
+	MultipleCollisionFrames   uint32
+	SQETestErrors             uint32
+	DeferredTransmissions     uint32
+	LateCollisions            uint32
+	ExcessiveCollisions       uint32
+	InternalMacTransmitErrors uint32
+	CarrierSenseErrors        uint32
+	FrameTooLongs             uint32
+	InternalMacReceiveErrors  uint32
+	SymbolErrors              uint32
+}
+
+func (c EthernetCounters) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c EthernetCounters) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"dot3_alignment_errors":             c.AlignmentErrors,
+		"dot3_fcs_errors":                   c.FCSErrors,
+		"dot3_single_collision_frames":      c.SingleCollisionFrames,
+		"dot3_multiple_collision_frames":    c.MultipleCollisionFrames,
+		"dot3_sqe_test_errors":              c.SQETestErrors,
+		"dot3_deferred_transmissions":       c.DeferredTransmissions,
+		"dot3_late_collisions":              c.LateCollisions,
+		"dot3_excessive_collisions":         c.ExcessiveCollisions,
+		"dot3_internal_mac_transmit_errors": c.InternalMacTransmitErrors,
+		"dot3_carrier_sense_errors":         c.CarrierSenseErrors,
+		"dot3_frame_too_longs":              c.FrameTooLongs,
+		"dot3_internal_mac_receive_errors":  c.InternalMacReceiveErrors,
+		"dot3_symbol_errors":                c.SymbolErrors,
+	}
+}
+
+// https://sflow.org/sflow_version_5.txt processor
+type ProcessorCounters struct {
+	CPU5s       int32 // hundredths of a percent, -1 if unknown
+	CPU1m       int32
+	CPU5m       int32
+	TotalMemory uint64
+	FreeMemory  uint64
+}
+
+func (c ProcessorCounters) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c ProcessorCounters) GetFields() map[string]interface{} {
+	f := map[string]interface{}{
+		"memory_total": c.TotalMemory,
+		"memory_free":  c.FreeMemory,
+	}
+	if c.CPU5s >= 0 {
+		f["cpu_percent_5s"] = float64(c.CPU5s) / 100
+	}
+	if c.CPU1m >= 0 {
+		f["cpu_percent_1m"] = float64(c.CPU1m) / 100
+	}
+	if c.CPU5m >= 0 {
+		f["cpu_percent_5m"] = float64(c.CPU5m) / 100
+	}
+	return f
+}
+
+var MachineTypeMap = map[uint32]string{
+	0:  "unknown",
+	1:  "other",
+	2:  "x86",
+	3:  "x86_64",
+	4:  "ia64",
+	5:  "sparc",
+	6:  "alpha",
+	7:  "powerpc",
+	8:  "m68k",
+	9:  "mips",
+	10: "arm",
+	11: "hppa",
+	12: "s390",
+}
+
+var OSNameMap = map[uint32]string{
+	0:  "unknown",
+	1:  "other",
+	2:  "linux",
+	3:  "windows",
+	4:  "darwin",
+	5:  "hpux",
+	6:  "aix",
+	7:  "dragonfly",
+	8:  "freebsd",
+	9:  "netbsd",
+	10: "openbsd",
+	11: "osf",
+	12: "solaris",
+}
+
+// https://sflow.org/sflow_host.txt host_descr
+type HostDescr struct {
+	Hostname    string
+	UUID        [16]byte
+	MachineType uint32
+	OSName      uint32
+	OSRelease   string
+}
+
+func (c HostDescr) GetTags() map[string]string {
+	t := map[string]string{
+		"hostname":     c.Hostname,
+		"machine_type": MachineTypeMap[c.MachineType],
+		"os_name":      OSNameMap[c.OSName],
+	}
+	if c.UUID != [16]byte{} {
+		u := c.UUID
+		t["uuid"] = fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+	}
+	if c.OSRelease != "" {
+		t["os_release"] = c.OSRelease
+	}
+	return t
+}
+func (c HostDescr) GetFields() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+// https://sflow.org/sflow_host.txt host_parent
+type HostParent struct {
+	ContainerType  uint32
+	ContainerIndex uint32
+}
+
+func (c HostParent) GetTags() map[string]string {
+	return map[string]string{
+		"parent_type":  strconv.FormatUint(uint64(c.ContainerType), 10),
+		"parent_index": strconv.FormatUint(uint64(c.ContainerIndex), 10),
+	}
+}
+func (c HostParent) GetFields() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+// https://sflow.org/sflow_host.txt host_cpu
+type HostCPU struct {
+	LoadOne     float32
+	LoadFive    float32
+	LoadFifteen float32
+	ProcRun     uint32
+	ProcTotal   uint32
+	CPUNum      uint32
+	CPUSpeed    uint32 // MHz
+	Uptime      uint32 // seconds
+	CPUUser     uint32 // milliseconds
+	CPUNice     uint32
+	CPUSystem   uint32
+	CPUIdle     uint32
+	CPUWio      uint32
+	CPUIntr     uint32
+	CPUSintr    uint32
+	Interrupts  uint32
+	Contexts    uint32
+}
+
+func (c HostCPU) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c HostCPU) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"load_one":     float64(c.LoadOne),
+		"load_five":    float64(c.LoadFive),
+		"load_fifteen": float64(c.LoadFifteen),
+		"proc_run":     c.ProcRun,
+		"proc_total":   c.ProcTotal,
+		"cpu_num":      c.CPUNum,
+		"cpu_speed":    c.CPUSpeed,
+		"uptime":       c.Uptime,
+		"cpu_user":     c.CPUUser,
+		"cpu_nice":     c.CPUNice,
+		"cpu_system":   c.CPUSystem,
+		"cpu_idle":     c.CPUIdle,
+		"cpu_wio":      c.CPUWio,
+		"cpu_intr":     c.CPUIntr,
+		"cpu_sintr":    c.CPUSintr,
+		"interrupts":   c.Interrupts,
+		"contexts":     c.Contexts,
+	}
+}
+
+// https://sflow.org/sflow_host.txt host_memory
+type HostMemory struct {
+	MemTotal   uint64
+	MemFree    uint64
+	MemShared  uint64
+	MemBuffers uint64
+	MemCached  uint64
+	SwapTotal  uint64
+	SwapFree   uint64
+	PageIn     uint32
+	PageOut    uint32
+	SwapIn     uint32
+	SwapOut    uint32
+}
+
+func (c HostMemory) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c HostMemory) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"mem_total":   c.MemTotal,
+		"mem_free":    c.MemFree,
+		"mem_shared":  c.MemShared,
+		"mem_buffers": c.MemBuffers,
+		"mem_cached":  c.MemCached,
+		"swap_total":  c.SwapTotal,
+		"swap_free":   c.SwapFree,
+		"page_in":     c.PageIn,
+		"page_out":    c.PageOut,
+		"swap_in":     c.SwapIn,
+		"swap_out":    c.SwapOut,
+	}
+}
+
+// https://sflow.org/sflow_host.txt host_disk_io
+type HostDiskIO struct {
+	DiskTotal    uint64
+	DiskFree     uint64
+	PartMaxUsed  int32 // hundredths of a percent, -1 if unknown
+	Reads        uint32
+	BytesRead    uint64
+	ReadTime     uint32 // milliseconds
+	Writes       uint32
+	BytesWritten uint64
+	WriteTime    uint32 // milliseconds
+}
+
+func (c HostDiskIO) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c HostDiskIO) GetFields() map[string]interface{} {
+	f := map[string]interface{}{
+		"disk_total":    c.DiskTotal,
+		"disk_free":     c.DiskFree,
+		"disk_reads":    c.Reads,
+		"bytes_read":    c.BytesRead,
+		"read_time":     c.ReadTime,
+		"disk_writes":   c.Writes,
+		"bytes_written": c.BytesWritten,
+		"write_time":    c.WriteTime,
+	}
+	if c.PartMaxUsed >= 0 {
+		f["part_max_used"] = float64(c.PartMaxUsed) / 100
+	}
+	return f
+}
+
+// https://sflow.org/sflow_host.txt host_net_io and virt_net_io
+type HostNetIO struct {
+	BytesIn  uint64
+	PktsIn   uint32
+	ErrsIn   uint32
+	DropsIn  uint32
+	BytesOut uint64
+	PktsOut  uint32
+	ErrsOut  uint32
+	DropsOut uint32
+}
+
+func (c HostNetIO) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c HostNetIO) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"bytes_in":  c.BytesIn,
+		"pkts_in":   c.PktsIn,
+		"errs_in":   c.ErrsIn,
+		"drops_in":  c.DropsIn,
+		"bytes_out": c.BytesOut,
+		"pkts_out":  c.PktsOut,
+		"errs_out":  c.ErrsOut,
+		"drops_out": c.DropsOut,
+	}
+}
+
+// https://sflow.org/sflow_host.txt virt_node
+type VirtNode struct {
+	MHz        uint32
+	CPUs       uint32
+	Memory     uint64
+	MemoryFree uint64
+	NumDomains uint32
+}
+
+func (c VirtNode) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c VirtNode) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"node_cpu_speed":   c.MHz,
+		"node_cpu_num":     c.CPUs,
+		"node_memory":      c.Memory,
+		"node_memory_free": c.MemoryFree,
+		"node_num_domains": c.NumDomains,
+	}
+}
+
+// https://sflow.org/sflow_host.txt virt_cpu
+type VirtCPU struct {
+	State     uint32
+	CPUTime   uint32 // milliseconds
+	NrVirtCPU uint32
+}
+
+func (c VirtCPU) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c VirtCPU) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"state":    c.State,
+		"cpu_time": c.CPUTime,
+		"cpu_num":  c.NrVirtCPU,
+	}
+}
+
+// https://sflow.org/sflow_host.txt virt_memory
+type VirtMemory struct {
+	Memory    uint64
+	MaxMemory uint64
+}
+
+func (c VirtMemory) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c VirtMemory) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"memory":     c.Memory,
+		"max_memory": c.MaxMemory,
+	}
+}
+
+// https://sflow.org/sflow_host.txt virt_disk_io
+type VirtDiskIO struct {
+	Capacity   uint64
+	Allocation uint64
+	Available  uint64
+	RdReq      uint32
+	RdBytes    uint64
+	WrReq      uint32
+	WrBytes    uint64
+	Errs       uint32
+}
+
+func (c VirtDiskIO) GetTags() map[string]string {
+	return map[string]string{}
+}
+func (c VirtDiskIO) GetFields() map[string]interface{} {
+	return map[string]interface{}{
+		"disk_capacity":   c.Capacity,
+		"disk_allocation": c.Allocation,
+		"disk_available":  c.Available,
+		"disk_reads":      c.RdReq,
+		"bytes_read":      c.RdBytes,
+		"disk_writes":     c.WrReq,
+		"bytes_written":   c.WrBytes,
+		"disk_errors":     c.Errs,
+	}
+}
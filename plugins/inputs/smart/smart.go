@@ -18,6 +18,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -356,16 +357,23 @@ type Smart struct {
 	Path              string          `toml:"path" deprecated:"1.16.0;use 'path_smartctl' instead"`
 	PathSmartctl      string          `toml:"path_smartctl"`
 	PathNVMe          string          `toml:"path_nvme"`
+	PathSgSes         string          `toml:"path_sg_ses"`
 	Nocheck           string          `toml:"nocheck"`
 	EnableExtensions  []string        `toml:"enable_extensions"`
 	Attributes        bool            `toml:"attributes"`
+	Includes          []string        `toml:"includes"`
 	Excludes          []string        `toml:"excludes"`
 	Devices           []string        `toml:"devices"`
 	UseSudo           bool            `toml:"use_sudo"`
+	UseJSON           bool            `toml:"use_json"`
 	TagWithDeviceType bool            `toml:"tag_with_device_type"`
+	Enclosures        bool            `toml:"enclosures"`
+	EnclosureDevices  []string        `toml:"enclosure_devices"`
 	Timeout           config.Duration `toml:"timeout"`
 	ReadMethod        string          `toml:"read_method"`
 	Log               telegraf.Logger `toml:"-"`
+
+	deviceFilter filter.Filter
 }
 
 type nvmeDevice struct {
@@ -407,7 +415,23 @@ func (m *Smart) Init() error {
 		return fmt.Errorf("provided read method %q is not valid", m.ReadMethod)
 	}
 
-	err := validatePath(m.PathSmartctl)
+	deviceFilter, err := filter.NewIncludeExcludeFilter(m.Includes, m.Excludes)
+	if err != nil {
+		return fmt.Errorf("creating device filter failed: %w", err)
+	}
+	m.deviceFilter = deviceFilter
+
+	if m.Enclosures {
+		//if `path_sg_ses` is not provided in config, try to find sg_ses binary in PATH
+		if len(m.PathSgSes) == 0 {
+			m.PathSgSes, _ = exec.LookPath("sg_ses")
+		}
+		if err := validatePath(m.PathSgSes); err != nil {
+			return fmt.Errorf("sg_ses not found: verify that sg3_utils is installed and sg_ses is in your PATH (or specified in config): %w", err)
+		}
+	}
+
+	err = validatePath(m.PathSmartctl)
 	if err != nil {
 		m.PathSmartctl = ""
 		//without smartctl, plugin will not be able to gather basic metrics
@@ -437,6 +461,12 @@ func (m *Smart) Gather(acc telegraf.Accumulator) error {
 	isNVMe := len(m.PathNVMe) != 0
 	isVendorExtension := len(m.EnableExtensions) != 0
 
+	if m.Enclosures {
+		if err := m.gatherEnclosures(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
 	if len(m.Devices) != 0 {
 		m.getAttributes(acc, devicesFromConfig)
 
@@ -513,7 +543,7 @@ func (m *Smart) scanDevices(ignoreExcludes bool, scanArgs ...string) ([]string,
 			continue
 		}
 		if !ignoreExcludes {
-			if !excludedDev(m.Excludes, strings.TrimSpace(dev[0])) {
+			if m.includedDev(strings.TrimSpace(dev[0])) {
 				devices = append(devices, strings.TrimSpace(dev[0]))
 			}
 		} else {
@@ -532,6 +562,15 @@ var runCmd = func(timeout config.Duration, sudo bool, command string, args ...st
 	return internal.CombinedOutputTimeout(cmd, time.Duration(timeout))
 }
 
+// includedDev checks if the scanned device passes the include and exclude
+// filters, falling back to exact exclude matches if the filter is not set up
+func (m *Smart) includedDev(device string) bool {
+	if m.deviceFilter == nil {
+		return !excludedDev(m.Excludes, device)
+	}
+	return m.deviceFilter.Match(device)
+}
+
 func excludedDev(excludes []string, deviceLine string) bool {
 	device := strings.Split(deviceLine, " ")
 	if len(device) != 0 {
@@ -548,12 +587,16 @@ func excludedDev(excludes []string, deviceLine string) bool {
 func (m *Smart) getAttributes(acc telegraf.Accumulator, devices []string) {
 	var wg sync.WaitGroup
 	wg.Add(len(devices))
+	gather := m.gatherDisk
+	if m.UseJSON {
+		gather = m.gatherDiskJSON
+	}
 	for _, device := range devices {
 		switch m.ReadMethod {
 		case "concurrent":
-			go m.gatherDisk(acc, device, &wg)
+			go gather(acc, device, &wg)
 		case "sequential":
-			m.gatherDisk(acc, device, &wg)
+			gather(acc, device, &wg)
 		default:
 			wg.Done()
 		}
@@ -0,0 +1,168 @@
+package smart
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	// Primary enclosure logical identifier (hex): 500143800976a280
+	sesLogicalID = regexp.MustCompile(`^\s*Primary enclosure logical identifier \(hex\):\s+(\w+)`)
+	// INVOP=0, INFO=0, NON-CRIT=0, CRIT=0, UNRECOV=0
+	sesSummary = regexp.MustCompile(`^\s*INVOP=\d+, INFO=(\d+), NON-CRIT=(\d+), CRIT=(\d+), UNRECOV=(\d+)`)
+	// Element type: Temperature sensor, subenclosure id: 0 [ti=3]
+	sesElementType = regexp.MustCompile(`^\s*Element type: ([^,]+), subenclosure id: (\d+)`)
+	// Element 0 descriptor:
+	sesElement = regexp.MustCompile(`^\s*Element (\d+) descriptor:`)
+	// Overall descriptor:
+	sesOverall = regexp.MustCompile(`^\s*Overall descriptor:`)
+	// Predicted failure=0, Disabled=0, Swap=0, status: OK
+	sesStatus = regexp.MustCompile(`Predicted failure=(\d+), Disabled=\d+, Swap=\d+, status: (.+)$`)
+	// Ident=0, Do not remove=0, Hot swap=0, Fail=0, Requested on=1
+	sesFail = regexp.MustCompile(`\bFail=(\d+)`)
+	// Temperature=24 C
+	sesTemperature = regexp.MustCompile(`Temperature=(-?\d+) C`)
+	// Off=0, Actual speed=4710 rpm, Fan at third lowest speed
+	sesFanSpeed = regexp.MustCompile(`Actual speed=(\d+) rpm`)
+	// Voltage: 5.13 volts
+	sesVoltage = regexp.MustCompile(`Voltage: (-?[\d.]+) volts`)
+	// Current: 2.17 amps
+	sesCurrent = regexp.MustCompile(`Current: (-?[\d.]+) amps`)
+)
+
+// Scan for SCSI enclosure services devices exposed by the kernel
+var findEnclosures = func() ([]string, error) {
+	matches, err := filepath.Glob("/sys/class/enclosure/*/device/scsi_generic/*")
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]string, 0, len(matches))
+	for _, m := range matches {
+		devices = append(devices, "/dev/"+filepath.Base(m))
+	}
+	return devices, nil
+}
+
+func (m *Smart) gatherEnclosures(acc telegraf.Accumulator) error {
+	devices := m.EnclosureDevices
+	if len(devices) == 0 {
+		var err error
+		if devices, err = findEnclosures(); err != nil {
+			return fmt.Errorf("failed to find enclosures: %w", err)
+		}
+	}
+
+	for _, device := range devices {
+		args := []string{"--page=es", device}
+		out, err := runCmd(m.Timeout, m.UseSudo, m.PathSgSes, args...)
+		if err != nil {
+			acc.AddError(fmt.Errorf("failed to run command '%s %s': %w - %s", m.PathSgSes, strings.Join(args, " "), err, string(out)))
+			continue
+		}
+		parseEnclosureStatus(acc, path.Base(device), string(out))
+	}
+	return nil
+}
+
+// parseEnclosureStatus parses the enclosure status diagnostic page as printed
+// by 'sg_ses --page=es' reporting the state and the sensor readings of each
+// element
+func parseEnclosureStatus(acc telegraf.Accumulator, device, output string) {
+	enclosureTags := map[string]string{"enclosure": device}
+	enclosureFields := make(map[string]interface{})
+
+	var elementTags map[string]string
+	var elementFields map[string]interface{}
+	var elementType, subenclosure string
+	flush := func() {
+		if len(elementFields) > 0 {
+			acc.AddFields("smart_enclosure_element", elementFields, elementTags)
+		}
+		elementFields = nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := sesLogicalID.FindStringSubmatch(line); match != nil {
+			enclosureTags["logical_id"] = match[1]
+			continue
+		}
+		if match := sesSummary.FindStringSubmatch(line); match != nil {
+			for i, key := range []string{"info", "non_critical", "critical", "unrecoverable"} {
+				enclosureFields[key] = match[i+1] != "0"
+			}
+			continue
+		}
+		if match := sesElementType.FindStringSubmatch(line); match != nil {
+			flush()
+			elementType, subenclosure = match[1], match[2]
+			continue
+		}
+		if sesOverall.MatchString(line) {
+			// The overall descriptor summarizes all elements of a type
+			flush()
+			continue
+		}
+		if match := sesElement.FindStringSubmatch(line); match != nil {
+			flush()
+			elementTags = map[string]string{
+				"enclosure":       device,
+				"element_type":    elementType,
+				"subenclosure_id": subenclosure,
+				"element":         match[1],
+			}
+			if id, found := enclosureTags["logical_id"]; found {
+				elementTags["logical_id"] = id
+			}
+			elementFields = make(map[string]interface{})
+			continue
+		}
+		if elementFields == nil {
+			continue
+		}
+
+		if match := sesStatus.FindStringSubmatch(line); match != nil {
+			status := strings.TrimSpace(match[2])
+			elementFields["status"] = status
+			elementFields["status_ok"] = status == "OK"
+			elementFields["predicted_failure"] = match[1] != "0"
+		}
+		if match := sesFail.FindStringSubmatch(line); match != nil {
+			elementFields["fail"] = match[1] != "0"
+		}
+		if match := sesTemperature.FindStringSubmatch(line); match != nil {
+			if v, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				elementFields["temp_c"] = v
+			}
+		}
+		if match := sesFanSpeed.FindStringSubmatch(line); match != nil {
+			if v, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+				elementFields["fan_speed_rpm"] = v
+			}
+		}
+		if match := sesVoltage.FindStringSubmatch(line); match != nil {
+			if v, err := strconv.ParseFloat(match[1], 64); err == nil {
+				elementFields["voltage_v"] = v
+			}
+		}
+		if match := sesCurrent.FindStringSubmatch(line); match != nil {
+			if v, err := strconv.ParseFloat(match[1], 64); err == nil {
+				elementFields["current_a"] = v
+			}
+		}
+	}
+	flush()
+
+	if len(enclosureFields) > 0 {
+		acc.AddFields("smart_enclosure", enclosureFields, enclosureTags)
+	}
+}
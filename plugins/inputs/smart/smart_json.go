@@ -0,0 +1,303 @@
+package smart
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// smartctlJSON represents the parts of the 'smartctl --json' output
+// (smartctl 7.0 and later) relevant for the plugin
+type smartctlJSON struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Device struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		Protocol string `json:"protocol"`
+	} `json:"device"`
+	ModelName    string `json:"model_name"`
+	ScsiProduct  string `json:"scsi_product"`
+	SerialNumber string `json:"serial_number"`
+	WWN          *struct {
+		NAA uint64 `json:"naa"`
+		OUI uint64 `json:"oui"`
+		ID  uint64 `json:"id"`
+	} `json:"wwn"`
+	UserCapacity *struct {
+		Bytes int64 `json:"bytes"`
+	} `json:"user_capacity"`
+	SmartSupport *struct {
+		Enabled bool `json:"enabled"`
+	} `json:"smart_support"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature *struct {
+		Current int64 `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours int64 `json:"hours"`
+	} `json:"power_on_time"`
+	ATASmartAttributes struct {
+		Table []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			Value      int64  `json:"value"`
+			Worst      int64  `json:"worst"`
+			Thresh     int64  `json:"thresh"`
+			WhenFailed string `json:"when_failed"`
+			Flags      struct {
+				String string `json:"string"`
+			} `json:"flags"`
+			Raw struct {
+				Value  int64  `json:"value"`
+				String string `json:"string"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeHealth     map[string]json.RawMessage `json:"nvme_smart_health_information_log"`
+	NVMeNamespaces []struct {
+		ID               int64      `json:"id"`
+		Size             blockCount `json:"size"`
+		Capacity         blockCount `json:"capacity"`
+		Utilization      blockCount `json:"utilization"`
+		FormattedLBASize int64      `json:"formatted_lba_size"`
+	} `json:"nvme_namespaces"`
+	SCSIStartStop *struct {
+		StartStopCycles  *int64 `json:"accumulated_start_stop_cycles"`
+		LoadUnloadCycles *int64 `json:"accumulated_load_unload_cycles"`
+	} `json:"scsi_start_stop_cycle_counter"`
+	SCSIGrownDefectList *int64 `json:"scsi_grown_defect_list"`
+}
+
+type blockCount struct {
+	Blocks int64 `json:"blocks"`
+	Bytes  int64 `json:"bytes"`
+}
+
+// nvmeJSONAttributes maps the keys of the NVMe health information log to the
+// attribute names also used when parsing the text output
+var nvmeJSONAttributes = map[string]struct {
+	ID   string
+	Name string
+}{
+	"critical_warning":          {Name: "Critical_Warning"},
+	"temperature":               {ID: "194", Name: "Temperature_Celsius"},
+	"available_spare":           {Name: "Available_Spare"},
+	"available_spare_threshold": {Name: "Available_Spare_Threshold"},
+	"percentage_used":           {Name: "Percentage_Used"},
+	"data_units_read":           {Name: "Data_Units_Read"},
+	"data_units_written":        {Name: "Data_Units_Written"},
+	"host_reads":                {Name: "Host_Read_Commands"},
+	"host_writes":               {Name: "Host_Write_Commands"},
+	"controller_busy_time":      {Name: "Controller_Busy_Time"},
+	"power_cycles":              {ID: "12", Name: "Power_Cycle_Count"},
+	"power_on_hours":            {ID: "9", Name: "Power_On_Hours"},
+	"unsafe_shutdowns":          {Name: "Unsafe_Shutdowns"},
+	"media_errors":              {Name: "Media_and_Data_Integrity_Errors"},
+	"num_err_log_entries":       {Name: "Error_Information_Log_Entries"},
+	"warning_temp_time":         {Name: "Warning_Temperature_Time"},
+	"critical_comp_time":        {Name: "Critical_Temperature_Time"},
+}
+
+// whenFailed maps the JSON representation of the failure state of an ATA
+// attribute to the one printed by the text output
+var whenFailed = map[string]string{
+	"":     "-",
+	"now":  "NOW",
+	"past": "Past",
+}
+
+// gatherDiskJSON gathers the same information as gatherDisk but parses the
+// JSON output of smartctl, additionally providing per-namespace NVMe metrics
+func (m *Smart) gatherDiskJSON(acc telegraf.Accumulator, device string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	args := []string{"--json", "--info", "--health", "--attributes", "--tolerance=verypermissive", "-n", m.Nocheck}
+	args = append(args, strings.Split(device, " ")...)
+	out, e := runCmd(m.Timeout, m.UseSudo, m.PathSmartctl, args...)
+
+	// Ignore all exit statuses except if it is a command line parse error
+	exitStatus, er := exitStatus(e)
+	if er != nil {
+		acc.AddError(fmt.Errorf("failed to run command '%s %s': %w - %s", m.PathSmartctl, strings.Join(args, " "), e, string(out)))
+		return
+	}
+
+	var data smartctlJSON
+	if err := json.Unmarshal(out, &data); err != nil {
+		acc.AddError(fmt.Errorf("failed to parse output of device %q: %w", device, err))
+		return
+	}
+
+	deviceTags := map[string]string{}
+	deviceNode := strings.SplitN(device, " ", 2)
+	deviceTags["device"] = path.Base(deviceNode[0])
+	if m.TagWithDeviceType && len(deviceNode) == 2 && deviceNode[1] != "" {
+		deviceTags["device_type"] = strings.TrimPrefix(deviceNode[1], "-d ")
+	}
+	if data.ModelName != "" {
+		deviceTags["model"] = data.ModelName
+	} else if data.ScsiProduct != "" {
+		deviceTags["model"] = data.ScsiProduct
+	}
+	if data.SerialNumber != "" {
+		deviceTags["serial_no"] = data.SerialNumber
+	}
+	if data.WWN != nil {
+		deviceTags["wwn"] = fmt.Sprintf("%x%06x%09x", data.WWN.NAA, data.WWN.OUI, data.WWN.ID)
+	}
+	if data.UserCapacity != nil {
+		deviceTags["capacity"] = strconv.FormatInt(data.UserCapacity.Bytes, 10)
+	}
+	if data.SmartSupport != nil {
+		deviceTags["enabled"] = "Disabled"
+		if data.SmartSupport.Enabled {
+			deviceTags["enabled"] = "Enabled"
+		}
+	}
+	for _, msg := range data.Smartctl.Messages {
+		if p := standbyInfo.FindStringSubmatch(msg.String); len(p) > 1 {
+			deviceTags["power"] = p[1]
+		}
+	}
+
+	deviceFields := map[string]interface{}{
+		"exit_status": exitStatus,
+	}
+	if data.SmartStatus != nil {
+		deviceFields["health_ok"] = data.SmartStatus.Passed
+	}
+	if data.Temperature != nil {
+		deviceFields["temp_c"] = data.Temperature.Current
+	}
+
+	attributeTags := func(extra map[string]string) map[string]string {
+		tags := make(map[string]string, len(deviceTags)+len(extra))
+		for k, v := range deviceTags {
+			tags[k] = v
+		}
+		for k, v := range extra {
+			tags[k] = v
+		}
+		return tags
+	}
+
+	// ATA attributes
+	for _, attr := range data.ATASmartAttributes.Table {
+		id := strconv.FormatInt(attr.ID, 10)
+
+		// Only use the leading value of the raw string like the text output
+		// parser, e.g. "33" for "33 (Min/Max 18/48)"
+		var rawValue string
+		if parts := strings.Fields(attr.Raw.String); len(parts) > 0 {
+			rawValue = parts[0]
+		}
+		if m.Attributes {
+			tags := attributeTags(map[string]string{
+				"id":    id,
+				"name":  attr.Name,
+				"flags": strings.TrimSpace(attr.Flags.String),
+				"fail":  whenFailed[attr.WhenFailed],
+			})
+			fields := map[string]interface{}{
+				"exit_status": exitStatus,
+				"value":       attr.Value,
+				"worst":       attr.Worst,
+				"threshold":   attr.Thresh,
+			}
+			if val, err := parseRawValue(rawValue); err == nil {
+				fields["raw_value"] = val
+			}
+			acc.AddFields("smart_attribute", fields, tags)
+		}
+
+		if field, ok := deviceFieldIds[id]; ok {
+			if val, err := parseRawValue(rawValue); err == nil {
+				deviceFields[field] = val
+			}
+		}
+		if field, ok := deviceFieldNames[attr.Name]; ok {
+			deviceFields[field] = attr.Value
+		}
+	}
+
+	// NVMe health information
+	for key, raw := range data.NVMeHealth {
+		attr, ok := nvmeJSONAttributes[key]
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseInt(string(raw), 10, 64)
+		if err != nil {
+			// Counters exceeding 64 bits are reported as floating point
+			f, err := strconv.ParseFloat(string(raw), 64)
+			if err != nil {
+				continue
+			}
+			value = int64(f)
+		}
+		if key == "temperature" {
+			deviceFields["temp_c"] = value
+		}
+		if m.Attributes {
+			tags := attributeTags(map[string]string{"name": attr.Name})
+			if attr.ID != "" {
+				tags["id"] = attr.ID
+			}
+			acc.AddFields("smart_attribute", map[string]interface{}{"raw_value": value}, tags)
+		}
+	}
+
+	// SCSI/SAS counters
+	if m.Attributes && data.Device.Protocol == "SCSI" {
+		if data.SCSIStartStop != nil && data.SCSIStartStop.StartStopCycles != nil {
+			tags := attributeTags(map[string]string{"id": "4", "name": "Start_Stop_Count"})
+			acc.AddFields("smart_attribute", map[string]interface{}{"raw_value": *data.SCSIStartStop.StartStopCycles}, tags)
+		}
+		if data.SCSIStartStop != nil && data.SCSIStartStop.LoadUnloadCycles != nil {
+			tags := attributeTags(map[string]string{"id": "193", "name": "Load_Cycle_Count"})
+			acc.AddFields("smart_attribute", map[string]interface{}{"raw_value": *data.SCSIStartStop.LoadUnloadCycles}, tags)
+		}
+		if data.SCSIGrownDefectList != nil {
+			tags := attributeTags(map[string]string{"name": "Grown_Defect_List"})
+			acc.AddFields("smart_attribute", map[string]interface{}{"raw_value": *data.SCSIGrownDefectList}, tags)
+		}
+		if data.PowerOnTime != nil {
+			tags := attributeTags(map[string]string{"id": "9", "name": "Power_On_Hours"})
+			acc.AddFields("smart_attribute", map[string]interface{}{"raw_value": data.PowerOnTime.Hours}, tags)
+		}
+	}
+
+	// NVMe namespaces
+	for _, ns := range data.NVMeNamespaces {
+		tags := map[string]string{
+			"device":    deviceTags["device"],
+			"namespace": strconv.FormatInt(ns.ID, 10),
+		}
+		for _, key := range []string{"model", "serial_no"} {
+			if v, ok := deviceTags[key]; ok {
+				tags[key] = v
+			}
+		}
+		fields := map[string]interface{}{
+			"size_bytes":         ns.Size.Bytes,
+			"capacity_bytes":     ns.Capacity.Bytes,
+			"utilization_bytes":  ns.Utilization.Bytes,
+			"formatted_lba_size": ns.FormattedLBASize,
+		}
+		acc.AddFields("smart_nvme_namespace", fields, tags)
+	}
+
+	acc.AddFields("smart_device", deviceFields, deviceTags)
+}
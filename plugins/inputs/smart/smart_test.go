@@ -481,6 +481,289 @@ func Test_integerOverflow(t *testing.T) {
 	})
 }
 
+func TestGatherJSONATA(t *testing.T) {
+	runCmd = func(timeout config.Duration, sudo bool, command string, args ...string) ([]byte, error) {
+		return []byte(smartctlJSONATAData), nil
+	}
+
+	s := newSmart()
+	s.Attributes = true
+	s.UseJSON = true
+	s.PathSmartctl = "smartctl"
+
+	var acc testutil.Accumulator
+	s.getAttributes(&acc, []string{"/dev/sda"})
+	require.Empty(t, acc.Errors)
+
+	deviceTags := map[string]string{
+		"device":    "sda",
+		"model":     "Samsung SSD 860 EVO 500GB",
+		"serial_no": "S3Z1NB0K123456",
+		"wwn":       "5002538e40a0b1c2",
+		"capacity":  "500107862016",
+		"enabled":   "Enabled",
+	}
+	attributeTags := func(id, name, flags string) map[string]string {
+		tags := map[string]string{"id": id, "name": name, "flags": flags, "fail": "-"}
+		for k, v := range deviceTags {
+			tags[k] = v
+		}
+		return tags
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"smart_attribute",
+			attributeTags("5", "Reallocated_Sector_Ct", "PO--CK"),
+			map[string]interface{}{
+				"exit_status": 0,
+				"value":       int64(100),
+				"worst":       int64(100),
+				"threshold":   int64(10),
+				"raw_value":   int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_attribute",
+			attributeTags("177", "Wear_Leveling_Count", "PO--C-"),
+			map[string]interface{}{
+				"exit_status": 0,
+				"value":       int64(98),
+				"worst":       int64(98),
+				"threshold":   int64(0),
+				"raw_value":   int64(23),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_attribute",
+			attributeTags("194", "Temperature_Celsius", "-O---K"),
+			map[string]interface{}{
+				"exit_status": 0,
+				"value":       int64(67),
+				"worst":       int64(52),
+				"threshold":   int64(0),
+				"raw_value":   int64(33),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_device",
+			deviceTags,
+			map[string]interface{}{
+				"exit_status":               0,
+				"health_ok":                 true,
+				"temp_c":                    int64(33),
+				"reallocated_sectors_count": int64(0),
+				"wear_leveling_count":       int64(98),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherJSONNVMeNamespaces(t *testing.T) {
+	runCmd = func(timeout config.Duration, sudo bool, command string, args ...string) ([]byte, error) {
+		return []byte(smartctlJSONNVMeData), nil
+	}
+
+	s := newSmart()
+	s.UseJSON = true
+	s.PathSmartctl = "smartctl"
+
+	var acc testutil.Accumulator
+	s.getAttributes(&acc, []string{"/dev/nvme0"})
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"smart_nvme_namespace",
+			map[string]string{
+				"device":    "nvme0",
+				"namespace": "1",
+				"model":     "Samsung SSD 970 EVO Plus 1TB",
+				"serial_no": "S4EWNX0N123456",
+			},
+			map[string]interface{}{
+				"size_bytes":         int64(1000204886016),
+				"capacity_bytes":     int64(1000204886016),
+				"utilization_bytes":  int64(412589256704),
+				"formatted_lba_size": int64(512),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_nvme_namespace",
+			map[string]string{
+				"device":    "nvme0",
+				"namespace": "2",
+				"model":     "Samsung SSD 970 EVO Plus 1TB",
+				"serial_no": "S4EWNX0N123456",
+			},
+			map[string]interface{}{
+				"size_bytes":         int64(4096000),
+				"capacity_bytes":     int64(4096000),
+				"utilization_bytes":  int64(0),
+				"formatted_lba_size": int64(4096),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_device",
+			map[string]string{
+				"device":    "nvme0",
+				"model":     "Samsung SSD 970 EVO Plus 1TB",
+				"serial_no": "S4EWNX0N123456",
+				"capacity":  "1000204886016",
+				"enabled":   "Enabled",
+			},
+			map[string]interface{}{
+				"exit_status": 0,
+				"health_ok":   true,
+				"temp_c":      int64(38),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+	// With attributes the NVMe health log is reported using the same names as
+	// for the text output
+	s.Attributes = true
+	acc.ClearMetrics()
+	s.getAttributes(&acc, []string{"/dev/nvme0"})
+	require.Empty(t, acc.Errors)
+	acc.AssertContainsTaggedFields(t, "smart_attribute",
+		map[string]interface{}{"raw_value": int64(2)},
+		map[string]string{
+			"device":    "nvme0",
+			"model":     "Samsung SSD 970 EVO Plus 1TB",
+			"serial_no": "S4EWNX0N123456",
+			"capacity":  "1000204886016",
+			"enabled":   "Enabled",
+			"name":      "Percentage_Used",
+		},
+	)
+}
+
+func TestDeviceFilter(t *testing.T) {
+	runCmd = func(timeout config.Duration, sudo bool, command string, args ...string) ([]byte, error) {
+		return []byte(mockScanData + "\n/dev/sdb -d scsi # /dev/sdb, SCSI device\n/dev/sdc -d scsi # /dev/sdc, SCSI device\n"), nil
+	}
+
+	s := newSmart()
+	s.PathSmartctl = "/bin/sh"
+	s.Includes = []string{"/dev/sd*"}
+	s.Excludes = []string{"/dev/sdc"}
+	s.Log = testutil.Logger{}
+	require.NoError(t, s.Init())
+
+	devices, err := s.scanDevices(false, "--scan")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/dev/sdb"}, devices)
+}
+
+func TestGatherEnclosures(t *testing.T) {
+	runCmd = func(timeout config.Duration, sudo bool, command string, args ...string) ([]byte, error) {
+		if command == "sg_ses" && len(args) == 2 && args[0] == "--page=es" && args[1] == "/dev/sg3" {
+			return []byte(sgSesEnclosureStatusData), nil
+		}
+		return nil, errors.New("command not found")
+	}
+
+	s := newSmart()
+	s.Enclosures = true
+	s.EnclosureDevices = []string{"/dev/sg3"}
+	s.PathSgSes = "sg_ses"
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.gatherEnclosures(&acc))
+	require.Empty(t, acc.Errors)
+
+	elementTags := func(elementType, element string) map[string]string {
+		return map[string]string{
+			"enclosure":       "sg3",
+			"logical_id":      "500143800976a280",
+			"element_type":    elementType,
+			"subenclosure_id": "0",
+			"element":         element,
+		}
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"smart_enclosure_element",
+			elementTags("Array device slot", "0"),
+			map[string]interface{}{
+				"status":            "OK",
+				"status_ok":         true,
+				"predicted_failure": false,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_enclosure_element",
+			elementTags("Array device slot", "1"),
+			map[string]interface{}{
+				"status":            "Critical",
+				"status_ok":         false,
+				"predicted_failure": true,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_enclosure_element",
+			elementTags("Cooling", "0"),
+			map[string]interface{}{
+				"status":            "OK",
+				"status_ok":         true,
+				"predicted_failure": false,
+				"fail":              false,
+				"fan_speed_rpm":     int64(4710),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_enclosure_element",
+			elementTags("Temperature sensor", "0"),
+			map[string]interface{}{
+				"status":            "OK",
+				"status_ok":         true,
+				"predicted_failure": false,
+				"fail":              false,
+				"temp_c":            int64(24),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_enclosure_element",
+			elementTags("Voltage sensor", "0"),
+			map[string]interface{}{
+				"status":            "OK",
+				"status_ok":         true,
+				"predicted_failure": false,
+				"fail":              false,
+				"voltage_v":         5.13,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"smart_enclosure",
+			map[string]string{"enclosure": "sg3", "logical_id": "500143800976a280"},
+			map[string]interface{}{
+				"info":          false,
+				"non_critical":  false,
+				"critical":      true,
+				"unrecoverable": false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
 var (
 	testOverflowAttributes = []telegraf.Metric{
 		testutil.MustMetric(
@@ -2619,4 +2902,132 @@ msdbd   : 0
 ps    0 : mp:25.00W operational enlat:0 exlat:0 rrt:0 rrl:0
           rwt:0 rwl:0 idle_power:- active_power:-
 `
+
+	smartctlJSONATAData = `{
+  "json_format_version": [1, 0],
+  "smartctl": {"version": [7, 3], "exit_status": 0},
+  "device": {"name": "/dev/sda", "info_name": "/dev/sda [SAT]", "type": "sat", "protocol": "ATA"},
+  "model_name": "Samsung SSD 860 EVO 500GB",
+  "serial_number": "S3Z1NB0K123456",
+  "wwn": {"naa": 5, "oui": 9528, "id": 61213815234},
+  "user_capacity": {"blocks": 976773168, "bytes": 500107862016},
+  "smart_support": {"available": true, "enabled": true},
+  "smart_status": {"passed": true},
+  "ata_smart_attributes": {
+    "revision": 1,
+    "table": [
+      {
+        "id": 5, "name": "Reallocated_Sector_Ct", "value": 100, "worst": 100, "thresh": 10, "when_failed": "",
+        "flags": {"value": 51, "string": "PO--CK "},
+        "raw": {"value": 0, "string": "0"}
+      },
+      {
+        "id": 177, "name": "Wear_Leveling_Count", "value": 98, "worst": 98, "thresh": 0, "when_failed": "",
+        "flags": {"value": 19, "string": "PO--C- "},
+        "raw": {"value": 23, "string": "23"}
+      },
+      {
+        "id": 194, "name": "Temperature_Celsius", "value": 67, "worst": 52, "thresh": 0, "when_failed": "",
+        "flags": {"value": 34, "string": "-O---K "},
+        "raw": {"value": 206159216673, "string": "33 (Min/Max 18/48)"}
+      }
+    ]
+  },
+  "temperature": {"current": 33}
+}`
+
+	smartctlJSONNVMeData = `{
+  "json_format_version": [1, 0],
+  "smartctl": {"version": [7, 3], "exit_status": 0},
+  "device": {"name": "/dev/nvme0", "info_name": "/dev/nvme0", "type": "nvme", "protocol": "NVMe"},
+  "model_name": "Samsung SSD 970 EVO Plus 1TB",
+  "serial_number": "S4EWNX0N123456",
+  "nvme_number_of_namespaces": 2,
+  "nvme_namespaces": [
+    {
+      "id": 1,
+      "size": {"blocks": 1953525168, "bytes": 1000204886016},
+      "capacity": {"blocks": 1953525168, "bytes": 1000204886016},
+      "utilization": {"blocks": 805838392, "bytes": 412589256704},
+      "formatted_lba_size": 512,
+      "eui64": {"oui": 9528, "ext_id": 123456789}
+    },
+    {
+      "id": 2,
+      "size": {"blocks": 1000, "bytes": 4096000},
+      "capacity": {"blocks": 1000, "bytes": 4096000},
+      "utilization": {"blocks": 0, "bytes": 0},
+      "formatted_lba_size": 4096
+    }
+  ],
+  "user_capacity": {"blocks": 1953525168, "bytes": 1000204886016},
+  "smart_support": {"available": true, "enabled": true},
+  "smart_status": {"passed": true, "nvme": {"value": 0}},
+  "nvme_smart_health_information_log": {
+    "critical_warning": 0,
+    "temperature": 38,
+    "available_spare": 100,
+    "available_spare_threshold": 10,
+    "percentage_used": 2,
+    "data_units_read": 25163425,
+    "data_units_written": 40233515,
+    "host_reads": 342526498,
+    "host_writes": 602836541,
+    "controller_busy_time": 1425,
+    "power_cycles": 1062,
+    "power_on_hours": 7851,
+    "unsafe_shutdowns": 54,
+    "media_errors": 0,
+    "num_err_log_entries": 1730,
+    "warning_temp_time": 0,
+    "critical_comp_time": 0,
+    "temperature_sensors": [38, 41]
+  },
+  "temperature": {"current": 38}
+}`
+
+	sgSesEnclosureStatusData = `  HP        D2700 SAS AJ941A  0149
+    Primary enclosure logical identifier (hex): 500143800976a280
+Enclosure Status diagnostic page:
+  INVOP=0, INFO=0, NON-CRIT=0, CRIT=1, UNRECOV=0
+  generation code: 0x0
+  status descriptor list
+    Element type: Array device slot, subenclosure id: 0 [ti=0]
+      Overall descriptor:
+        Predicted failure=0, Disabled=0, Swap=0, status: Unsupported
+        OK=0, Reserved device=0, Hot spare=0, Cons check=0
+      Element 0 descriptor:
+        Predicted failure=0, Disabled=0, Swap=0, status: OK
+        OK=0, Reserved device=0, Hot spare=0, Cons check=0
+        In crit array=0, In failed array=0, Rebuild/remap=0, R/R abort=0
+      Element 1 descriptor:
+        Predicted failure=1, Disabled=0, Swap=0, status: Critical
+        OK=0, Reserved device=0, Hot spare=0, Cons check=0
+    Element type: Cooling, subenclosure id: 0 [ti=2]
+      Overall descriptor:
+        Predicted failure=0, Disabled=0, Swap=0, status: OK
+        Ident=0, Do not remove=0, Hot swap=0, Fail=0, Requested on=0
+        Off=0, Actual speed=0 rpm, Fan stopped
+      Element 0 descriptor:
+        Predicted failure=0, Disabled=0, Swap=0, status: OK
+        Ident=0, Do not remove=0, Hot swap=0, Fail=0, Requested on=1
+        Off=0, Actual speed=4710 rpm, Fan at third lowest speed
+    Element type: Temperature sensor, subenclosure id: 0 [ti=3]
+      Overall descriptor:
+        Predicted failure=0, Disabled=0, Swap=0, status: Unsupported
+        Ident=0, Fail=0, OT failure=0, OT warning=0, UT failure=0
+        UT warning=0
+        Temperature: <reserved>
+      Element 0 descriptor:
+        Predicted failure=0, Disabled=0, Swap=0, status: OK
+        Ident=0, Fail=0, OT failure=0, OT warning=0, UT failure=0
+        UT warning=0
+        Temperature=24 C
+    Element type: Voltage sensor, subenclosure id: 0 [ti=4]
+      Element 0 descriptor:
+        Predicted failure=0, Disabled=0, Swap=0, status: OK
+        Ident=0, Fail=0,  Warn Over=0, Warn Under=0, Crit Over=0
+        Crit Under=0
+        Voltage: 5.13 volts
+`
 )
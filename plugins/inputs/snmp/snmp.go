@@ -63,7 +63,18 @@ type Snmp struct {
 	Name   string  `toml:"name"`
 	Fields []Field `toml:"field"`
 
+	// Limits for gathering agents and tables in parallel
+	MaxConcurrentAgents int `toml:"max_concurrent_agents"`
+	MaxConcurrentTables int `toml:"max_concurrent_tables"`
+
+	// Reduce the GETBULK max-repetitions for agents failing to answer
+	AdaptiveMaxRepetitions bool `toml:"adaptive_max_repetitions"`
+
+	// Report the rows of tables even if walking some of the fields failed
+	PartialResults bool `toml:"partial_results"`
+
 	connectionCache []snmpConnection
+	connectionPool  [][]snmpConnection
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -92,7 +103,15 @@ func (s *Snmp) Init() error {
 		return fmt.Errorf("invalid translator value")
 	}
 
+	if s.MaxConcurrentAgents < 0 {
+		return errors.New("max_concurrent_agents must not be negative")
+	}
+	if s.MaxConcurrentTables < 0 {
+		return errors.New("max_concurrent_tables must not be negative")
+	}
+
 	s.connectionCache = make([]snmpConnection, len(s.Agents))
+	s.connectionPool = make([][]snmpConnection, len(s.Agents))
 
 	for i := range s.Tables {
 		if err := s.Tables[i].Init(s.translator); err != nil {
@@ -317,43 +336,98 @@ func (e *walkError) Unwrap() error {
 // Any error encountered does not halt the process. The errors are accumulated
 // and returned at the end.
 func (s *Snmp) Gather(acc telegraf.Accumulator) error {
+	// Limit the number of agents gathered at the same time if requested
+	var limiter chan struct{}
+	if s.MaxConcurrentAgents > 0 {
+		limiter = make(chan struct{}, s.MaxConcurrentAgents)
+	}
+
 	var wg sync.WaitGroup
 	for i, agent := range s.Agents {
 		wg.Add(1)
 		go func(i int, agent string) {
 			defer wg.Done()
-			gs, err := s.getConnection(i)
-			if err != nil {
-				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
-				return
+			if limiter != nil {
+				limiter <- struct{}{}
+				defer func() { <-limiter }()
 			}
+			s.gatherAgent(acc, i, agent)
+		}(i, agent)
+	}
+	wg.Wait()
+
+	return nil
+}
 
-			// First is the top-level fields. We treat the fields as table prefixes with an empty index.
-			t := Table{
-				Name:   s.Name,
-				Fields: s.Fields,
+func (s *Snmp) gatherAgent(acc telegraf.Accumulator, idx int, agent string) {
+	gs, err := s.getConnection(idx)
+	if err != nil {
+		acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+		return
+	}
+
+	// First is the top-level fields. We treat the fields as table prefixes with an empty index.
+	t := Table{
+		Name:   s.Name,
+		Fields: s.Fields,
+	}
+	topTags := map[string]string{}
+	if err := s.gatherTable(acc, gs, t, topTags, false); err != nil {
+		acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+	}
+
+	// Now is the real tables. Each worker walks the tables using its own
+	// connection as connections must not be shared between goroutines.
+	workers := s.MaxConcurrentTables
+	if workers > len(s.Tables) {
+		workers = len(s.Tables)
+	}
+	if workers <= 1 {
+		for _, t := range s.Tables {
+			if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
+				acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
 			}
-			topTags := map[string]string{}
-			if err := s.gatherTable(acc, gs, t, topTags, false); err != nil {
-				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+		}
+		return
+	}
+
+	tables := make(chan Table)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		conn := gs
+		if w > 0 {
+			conn, err = s.getPoolConnection(idx, w)
+			if err != nil {
+				acc.AddError(fmt.Errorf("agent %s: additional connection: %w", agent, err))
+				continue
 			}
+		}
 
-			// Now is the real tables.
-			for _, t := range s.Tables {
-				if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
+		wg.Add(1)
+		go func(conn snmpConnection) {
+			defer wg.Done()
+			for t := range tables {
+				if err := s.gatherTable(acc, conn, t, topTags, true); err != nil {
 					acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
 				}
 			}
-		}(i, agent)
+		}(conn)
+	}
+	for _, t := range s.Tables {
+		tables <- t
 	}
+	close(tables)
 	wg.Wait()
-
-	return nil
 }
 
 func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmpConnection, t Table, topTags map[string]string, walk bool) error {
-	rt, err := t.Build(gs, walk, s.translator)
-	if err != nil {
+	opts := buildOptions{
+		partialResults:         s.PartialResults,
+		adaptiveMaxRepetitions: s.AdaptiveMaxRepetitions,
+		log:                    s.Log,
+	}
+	rt, err := t.build(gs, walk, s.translator, opts)
+	if rt == nil {
 		return err
 	}
 
@@ -377,12 +451,28 @@ func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmpConnection, t Table,
 		acc.AddFields(rt.Name, tr.Fields, tr.Tags, rt.Time)
 	}
 
-	return nil
+	return err
+}
+
+// buildOptions controls the error handling when building tables
+type buildOptions struct {
+	// Continue with the remaining fields if retrieving a field failed
+	partialResults bool
+	// Retry failed walks with a reduced GETBULK max-repetitions
+	adaptiveMaxRepetitions bool
+	log                    telegraf.Logger
 }
 
 // Build retrieves all the fields specified in the table and constructs the RTable.
 func (t Table) Build(gs snmpConnection, walk bool, tr Translator) (*RTable, error) {
+	return t.build(gs, walk, tr, buildOptions{})
+}
+
+// build constructs the RTable like Build. With partial results enabled, the
+// table is returned along with the errors of the fields that failed.
+func (t Table) build(gs snmpConnection, walk bool, tr Translator, opts buildOptions) (*RTable, error) {
 	rows := map[string]RTableRow{}
+	var fieldErrs []error
 
 	//translation table for secondary index (when preforming join on two tables)
 	secIdxTab := make(map[string]string)
@@ -433,7 +523,12 @@ func (t Table) Build(gs snmpConnection, walk bool, tr Translator) (*RTable, erro
 				} else if errors.Is(err, gosnmp.ErrDecryption) {
 					return nil, fmt.Errorf("decryption error (priv_protocol, priv_password)")
 				}
-				return nil, fmt.Errorf("performing get on field %s: %w", f.Name, err)
+				err = fmt.Errorf("performing get on field %s: %w", f.Name, err)
+				if !opts.partialResults {
+					return nil, err
+				}
+				fieldErrs = append(fieldErrs, err)
+				continue
 			} else if pkt != nil && len(pkt.Variables) > 0 && pkt.Variables[0].Type != gosnmp.NoSuchObject && pkt.Variables[0].Type != gosnmp.NoSuchInstance {
 				ent := pkt.Variables[0]
 				fv, err := fieldConvert(tr, f.Conversion, ent)
@@ -443,7 +538,7 @@ func (t Table) Build(gs snmpConnection, walk bool, tr Translator) (*RTable, erro
 				ifv[""] = fv
 			}
 		} else {
-			err := gs.Walk(oid, func(ent gosnmp.SnmpPDU) error {
+			walkFn := func(ent gosnmp.SnmpPDU) error {
 				if len(ent.Name) <= len(oid) || ent.Name[:len(oid)+1] != oid+"." {
 					return &walkError{} // break the walk
 				}
@@ -489,15 +584,31 @@ func (t Table) Build(gs snmpConnection, walk bool, tr Translator) (*RTable, erro
 				}
 				ifv[idx] = fv
 				return nil
-			})
-			if err != nil {
-				// Our callback always wraps errors in a walkError.
-				// If this error isn't a walkError, we know it's not
-				// from the callback
-				var walkErr *walkError
-				if !errors.As(err, &walkErr) {
-					return nil, fmt.Errorf("performing bulk walk for field %s: %w", f.Name, err)
+			}
+			err := gs.Walk(oid, walkFn)
+
+			// Our callback always wraps errors in a walkError.
+			// If this error isn't a walkError, we know it's not
+			// from the callback
+			var walkErr *walkError
+			if err != nil && !errors.As(err, &walkErr) && opts.adaptiveMaxRepetitions {
+				// Agents might fail to answer large GETBULK requests in
+				// time, so retry the walk with less repetitions
+				if reps, ok := reduceMaxRepetitions(gs); ok {
+					if opts.log != nil {
+						opts.log.Debugf("Walking field %s on %s failed, reducing max-repetitions to %d: %v", f.Name, gs.Host(), reps, err)
+					}
+					ifv = map[string]interface{}{}
+					err = gs.Walk(oid, walkFn)
+				}
+			}
+			if err != nil && !errors.As(err, &walkErr) {
+				err = fmt.Errorf("performing bulk walk for field %s: %w", f.Name, err)
+				if !opts.partialResults {
+					return nil, err
 				}
+				fieldErrs = append(fieldErrs, err)
+				continue
 			}
 		}
 
@@ -562,7 +673,7 @@ func (t Table) Build(gs snmpConnection, walk bool, tr Translator) (*RTable, erro
 	for _, r := range rows {
 		rt.Rows = append(rt.Rows, r)
 	}
-	return &rt, nil
+	return &rt, errors.Join(fieldErrs...)
 }
 
 // snmpConnection is an interface which wraps a *gosnmp.GoSNMP object.
@@ -588,21 +699,43 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 		return gs, nil
 	}
 
-	agent := s.Agents[idx]
-
-	var err error
-	var gs snmp.GosnmpWrapper
-	gs, err = snmp.NewWrapper(s.ClientConfig)
+	gs, err := s.newConnection(s.Agents[idx])
 	if err != nil {
 		return nil, err
 	}
 
-	err = gs.SetAgent(agent)
+	s.connectionCache[idx] = gs
+
+	if err := gs.Connect(); err != nil {
+		return nil, fmt.Errorf("setting up connection: %w", err)
+	}
+
+	return gs, nil
+}
+
+// getPoolConnection returns the connection of the given worker for the agent
+// with index `agentIndex`. Worker zero uses the connection of getConnection,
+// all other workers use additional connections to the same agent.
+func (s *Snmp) getPoolConnection(idx, worker int) (snmpConnection, error) {
+	if worker == 0 {
+		return s.getConnection(idx)
+	}
+
+	pool := s.connectionPool[idx]
+	if worker <= len(pool) {
+		gs := pool[worker-1]
+		if err := gs.Reconnect(); err != nil {
+			return gs, fmt.Errorf("reconnecting: %w", err)
+		}
+		return gs, nil
+	}
+
+	gs, err := s.newConnection(s.Agents[idx])
 	if err != nil {
 		return nil, err
 	}
 
-	s.connectionCache[idx] = gs
+	s.connectionPool[idx] = append(pool, gs)
 
 	if err := gs.Connect(); err != nil {
 		return nil, fmt.Errorf("setting up connection: %w", err)
@@ -611,6 +744,29 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 	return gs, nil
 }
 
+func (s *Snmp) newConnection(agent string) (snmp.GosnmpWrapper, error) {
+	gs, err := snmp.NewWrapper(s.ClientConfig)
+	if err != nil {
+		return snmp.GosnmpWrapper{}, err
+	}
+
+	if err := gs.SetAgent(agent); err != nil {
+		return snmp.GosnmpWrapper{}, err
+	}
+	return gs, nil
+}
+
+// reduceMaxRepetitions halves the GETBULK max-repetitions of the connection.
+// The reduced value is kept for all subsequent requests of the connection.
+func reduceMaxRepetitions(gs snmpConnection) (uint32, bool) {
+	w, ok := gs.(snmp.GosnmpWrapper)
+	if !ok || w.Version == gosnmp.Version1 || w.MaxRepetitions <= 1 {
+		return 0, false
+	}
+	w.MaxRepetitions /= 2
+	return w.MaxRepetitions, true
+}
+
 // fieldConvert converts from any type according to the conv specification
 func fieldConvert(tr Translator, conv string, ent gosnmp.SnmpPDU) (v interface{}, err error) {
 	if conv == "" {
@@ -1190,3 +1190,176 @@ func TestTableJoinNoIndexAsTag_walk(t *testing.T) {
 	require.Contains(t, tb.Rows, rtr2)
 	require.Contains(t, tb.Rows, rtr3)
 }
+
+// failingSNMPConnection behaves like testSNMPConnection but fails all
+// requests for the given OIDs
+type failingSNMPConnection struct {
+	*testSNMPConnection
+	failing map[string]bool
+}
+
+func (fsc *failingSNMPConnection) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	for _, oid := range oids {
+		if fsc.failing[oid] {
+			return nil, fmt.Errorf("request timeout for %s", oid)
+		}
+	}
+	return fsc.testSNMPConnection.Get(oids)
+}
+
+func (fsc *failingSNMPConnection) Walk(oid string, wf gosnmp.WalkFunc) error {
+	if fsc.failing[oid] {
+		return fmt.Errorf("request timeout for %s", oid)
+	}
+	return fsc.testSNMPConnection.Walk(oid, wf)
+}
+
+func TestGatherConcurrentTables(t *testing.T) {
+	s := &Snmp{
+		Agents:              []string{"TestGather"},
+		Name:                "mytable",
+		MaxConcurrentTables: 3,
+		Fields: []Field{
+			{
+				Name:  "myfield1",
+				Oid:   ".1.0.0.1.1",
+				IsTag: true,
+			},
+		},
+		Tables: []Table{
+			{
+				Name:        "table1",
+				InheritTags: []string{"myfield1"},
+				Fields:      []Field{{Name: "value", Oid: ".1.0.0.0.1.5"}},
+			},
+			{
+				Name:        "table2",
+				InheritTags: []string{"myfield1"},
+				Fields:      []Field{{Name: "value", Oid: ".1.0.0.0.1.2"}},
+			},
+			{
+				Name:        "table3",
+				InheritTags: []string{"myfield1"},
+				Fields:      []Field{{Name: "value", Oid: ".1.0.0.3.1.2"}},
+			},
+		},
+
+		connectionCache: []snmpConnection{tsc},
+		connectionPool:  [][]snmpConnection{{tsc, tsc}},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	counts := make(map[string]int)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "baz", m.Tags()["myfield1"])
+		counts[m.Name()]++
+	}
+	require.Equal(t, map[string]int{"table1": 1, "table2": 3, "table3": 3}, counts)
+}
+
+func TestGatherPartialResults(t *testing.T) {
+	conn := &failingSNMPConnection{
+		testSNMPConnection: tsc,
+		failing:            map[string]bool{".1.0.0.0.1.2": true},
+	}
+
+	tests := []struct {
+		name     string
+		partial  bool
+		expected int
+	}{
+		{
+			name:     "drop table",
+			expected: 0,
+		},
+		{
+			name:     "partial results",
+			partial:  true,
+			expected: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Snmp{
+				Agents:         []string{"TestGather"},
+				PartialResults: tt.partial,
+				Tables: []Table{
+					{
+						Name: "mytable",
+						Fields: []Field{
+							{Name: "myfield1", Oid: ".1.0.0.0.1.1", IsTag: true},
+							{Name: "myfield2", Oid: ".1.0.0.0.1.2"},
+							{Name: "myfield3", Oid: ".1.0.0.0.1.3", Conversion: "float"},
+						},
+					},
+				},
+
+				connectionCache: []snmpConnection{conn},
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, s.Gather(&acc))
+			require.Len(t, acc.Errors, 1)
+			require.ErrorContains(t, acc.Errors[0], "performing bulk walk for field myfield2: request timeout")
+
+			metrics := acc.GetTelegrafMetrics()
+			require.Len(t, metrics, tt.expected)
+			for _, m := range metrics {
+				require.True(t, m.HasField("myfield3"))
+				require.False(t, m.HasField("myfield2"))
+			}
+		})
+	}
+}
+
+func TestTableBuildPartialGet(t *testing.T) {
+	conn := &failingSNMPConnection{
+		testSNMPConnection: tsc,
+		failing:            map[string]bool{".1.0.0.1.2": true},
+	}
+	tbl := Table{
+		Name: "mytable",
+		Fields: []Field{
+			{Name: "myfield1", Oid: ".1.0.0.1.1", IsTag: true},
+			{Name: "myfield2", Oid: ".1.0.0.1.2"},
+			{Name: "myfield3", Oid: ".1.0.0.1.3"},
+		},
+	}
+
+	_, err := tbl.Build(conn, false, NewNetsnmpTranslator())
+	require.ErrorContains(t, err, "performing get on field myfield2")
+
+	tb, err := tbl.build(conn, false, NewNetsnmpTranslator(), buildOptions{partialResults: true})
+	require.ErrorContains(t, err, "performing get on field myfield2")
+	require.Equal(t, []RTableRow{
+		{
+			Tags:   map[string]string{"myfield1": "baz"},
+			Fields: map[string]interface{}{"myfield3": "byte slice"},
+		},
+	}, tb.Rows)
+}
+
+func TestReduceMaxRepetitions(t *testing.T) {
+	gs, err := snmp.NewWrapper(snmp.ClientConfig{Version: 2, MaxRepetitions: 10})
+	require.NoError(t, err)
+
+	for _, expected := range []uint32{5, 2, 1} {
+		reps, ok := reduceMaxRepetitions(gs)
+		require.True(t, ok)
+		require.Equal(t, expected, reps)
+		require.Equal(t, expected, gs.MaxRepetitions)
+	}
+	_, ok := reduceMaxRepetitions(gs)
+	require.False(t, ok)
+
+	// Connections not supporting GETBULK are not modified
+	gs, err = snmp.NewWrapper(snmp.ClientConfig{Version: 1, MaxRepetitions: 10})
+	require.NoError(t, err)
+	_, ok = reduceMaxRepetitions(gs)
+	require.False(t, ok)
+	_, ok = reduceMaxRepetitions(tsc)
+	require.False(t, ok)
+}
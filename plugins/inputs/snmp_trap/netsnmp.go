@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -79,9 +80,55 @@ func (s *netsnmpTranslator) snmptranslate(oid string) (e snmp.MibEntry, err erro
 	}
 	e.MibName = e.OidText[:i]
 	e.OidText = e.OidText[i+2:]
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "  -- TEXTUAL CONVENTION ") {
+			tc := strings.TrimPrefix(line, "  -- TEXTUAL CONVENTION ")
+			switch tc {
+			case "MacAddress", "PhysAddress":
+				e.Conversion = "hwaddr"
+			case "InetAddressIPv4", "InetAddressIPv6", "InetAddress", "IPSIpAddress":
+				e.Conversion = "ipaddr"
+			case "DateAndTime":
+				e.Conversion = "datetime"
+			}
+		} else if strings.HasPrefix(line, "  SYNTAX\t") {
+			e.Enums = parseEnums(strings.TrimPrefix(line, "  SYNTAX\t"))
+		}
+	}
 	return e, nil
 }
 
+// parseEnums extracts the named numbers of a syntax definition
+// like "INTEGER {up(1), down(2), testing(3)}"
+func parseEnums(syntax string) map[int64]string {
+	start := strings.Index(syntax, "{")
+	end := strings.LastIndex(syntax, "}")
+	if start == -1 || end < start {
+		return nil
+	}
+
+	enums := make(map[int64]string)
+	for _, item := range strings.Split(syntax[start+1:end], ",") {
+		item = strings.TrimSpace(item)
+		i := strings.Index(item, "(")
+		if i == -1 || !strings.HasSuffix(item, ")") {
+			continue
+		}
+		v, err := strconv.ParseInt(item[i+1:len(item)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		enums[v] = item[:i]
+	}
+	if len(enums) == 0 {
+		return nil
+	}
+	return enums
+}
+
 func newNetsnmpTranslator(timeout config.Duration) *netsnmpTranslator {
 	return &netsnmpTranslator{
 		execCmd: realExecCmd,
@@ -51,13 +51,20 @@ type SnmpTrap struct {
 	// Values: "noAuthNoPriv", "authNoPriv", "authPriv"
 	SecLevel string        `toml:"sec_level"`
 	SecName  config.Secret `toml:"sec_name"`
-	// Values: "MD5", "SHA", "". Default: ""
+	// Values: "MD5", "SHA", "SHA224", "SHA256", "SHA384", "SHA512", "". Default: ""
 	AuthProtocol string        `toml:"auth_protocol"`
 	AuthPassword config.Secret `toml:"auth_password"`
-	// Values: "DES", "AES", "". Default: ""
+	// Values: "DES", "AES", "AES192", "AES192C", "AES256", "AES256C", "". Default: ""
 	PrivProtocol string        `toml:"priv_protocol"`
 	PrivPassword config.Secret `toml:"priv_password"`
 
+	TranslateValues bool           `toml:"translate_values"`
+	Templates       []trapTemplate `toml:"template"`
+
+	msgFlags     gosnmp.SnmpV3MsgFlags
+	authProtocol gosnmp.SnmpV3AuthProtocol
+	privProtocol gosnmp.SnmpV3PrivProtocol
+
 	acc      telegraf.Accumulator
 	listener *gosnmp.TrapListener
 	timeFunc func() time.Time
@@ -111,6 +118,79 @@ func (s *SnmpTrap) Init() error {
 	if err != nil {
 		s.Log.Errorf("Could not get path %v", err)
 	}
+
+	if s.Version == "3" {
+		if err := s.initSecurity(); err != nil {
+			return err
+		}
+	}
+
+	for i := range s.Templates {
+		if err := s.Templates[i].init(); err != nil {
+			return fmt.Errorf("template %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// initSecurity checks the SNMPv3 user-based security model settings
+func (s *SnmpTrap) initSecurity() error {
+	switch strings.ToLower(s.SecLevel) {
+	case "noauthnopriv", "":
+		s.msgFlags = gosnmp.NoAuthNoPriv
+	case "authnopriv":
+		s.msgFlags = gosnmp.AuthNoPriv
+	case "authpriv":
+		s.msgFlags = gosnmp.AuthPriv
+	default:
+		return fmt.Errorf("unknown security level %q", s.SecLevel)
+	}
+
+	switch strings.ToLower(s.AuthProtocol) {
+	case "md5":
+		s.authProtocol = gosnmp.MD5
+	case "sha":
+		s.authProtocol = gosnmp.SHA
+	case "sha224":
+		s.authProtocol = gosnmp.SHA224
+	case "sha256":
+		s.authProtocol = gosnmp.SHA256
+	case "sha384":
+		s.authProtocol = gosnmp.SHA384
+	case "sha512":
+		s.authProtocol = gosnmp.SHA512
+	case "":
+		s.authProtocol = gosnmp.NoAuth
+	default:
+		return fmt.Errorf("unknown authentication protocol %q", s.AuthProtocol)
+	}
+
+	switch strings.ToLower(s.PrivProtocol) {
+	case "aes":
+		s.privProtocol = gosnmp.AES
+	case "des":
+		s.privProtocol = gosnmp.DES
+	case "aes192":
+		s.privProtocol = gosnmp.AES192
+	case "aes192c":
+		s.privProtocol = gosnmp.AES192C
+	case "aes256":
+		s.privProtocol = gosnmp.AES256
+	case "aes256c":
+		s.privProtocol = gosnmp.AES256C
+	case "":
+		s.privProtocol = gosnmp.NoPriv
+	default:
+		return fmt.Errorf("unknown privacy protocol %q", s.PrivProtocol)
+	}
+
+	// Authentication and privacy require the corresponding protocol
+	if s.msgFlags&gosnmp.AuthNoPriv != 0 && s.authProtocol == gosnmp.NoAuth {
+		return fmt.Errorf("security level %q requires an authentication protocol", s.SecLevel)
+	}
+	if s.msgFlags&gosnmp.AuthPriv == gosnmp.AuthPriv && s.privProtocol == gosnmp.NoPriv {
+		return fmt.Errorf("security level %q requires a privacy protocol", s.SecLevel)
+	}
 	return nil
 }
 
@@ -138,57 +218,7 @@ func (s *SnmpTrap) Start(acc telegraf.Accumulator) error {
 
 	if s.listener.Params.Version == gosnmp.Version3 {
 		s.listener.Params.SecurityModel = gosnmp.UserSecurityModel
-
-		switch strings.ToLower(s.SecLevel) {
-		case "noauthnopriv", "":
-			s.listener.Params.MsgFlags = gosnmp.NoAuthNoPriv
-		case "authnopriv":
-			s.listener.Params.MsgFlags = gosnmp.AuthNoPriv
-		case "authpriv":
-			s.listener.Params.MsgFlags = gosnmp.AuthPriv
-		default:
-			return fmt.Errorf("unknown security level %q", s.SecLevel)
-		}
-
-		var authenticationProtocol gosnmp.SnmpV3AuthProtocol
-		switch strings.ToLower(s.AuthProtocol) {
-		case "md5":
-			authenticationProtocol = gosnmp.MD5
-		case "sha":
-			authenticationProtocol = gosnmp.SHA
-		//case "sha224":
-		//	authenticationProtocol = gosnmp.SHA224
-		//case "sha256":
-		//	authenticationProtocol = gosnmp.SHA256
-		//case "sha384":
-		//	authenticationProtocol = gosnmp.SHA384
-		//case "sha512":
-		//	authenticationProtocol = gosnmp.SHA512
-		case "":
-			authenticationProtocol = gosnmp.NoAuth
-		default:
-			return fmt.Errorf("unknown authentication protocol %q", s.AuthProtocol)
-		}
-
-		var privacyProtocol gosnmp.SnmpV3PrivProtocol
-		switch strings.ToLower(s.PrivProtocol) {
-		case "aes":
-			privacyProtocol = gosnmp.AES
-		case "des":
-			privacyProtocol = gosnmp.DES
-		case "aes192":
-			privacyProtocol = gosnmp.AES192
-		case "aes192c":
-			privacyProtocol = gosnmp.AES192C
-		case "aes256":
-			privacyProtocol = gosnmp.AES256
-		case "aes256c":
-			privacyProtocol = gosnmp.AES256C
-		case "":
-			privacyProtocol = gosnmp.NoPriv
-		default:
-			return fmt.Errorf("unknown privacy protocol %q", s.PrivProtocol)
-		}
+		s.listener.Params.MsgFlags = s.msgFlags
 
 		secnameSecret, err := s.SecName.Get()
 		if err != nil {
@@ -199,24 +229,24 @@ func (s *SnmpTrap) Start(acc telegraf.Accumulator) error {
 
 		privPasswdSecret, err := s.PrivPassword.Get()
 		if err != nil {
-			return fmt.Errorf("getting secname failed: %w", err)
+			return fmt.Errorf("getting priv_password failed: %w", err)
 		}
 		privPasswd := privPasswdSecret.String()
 		privPasswdSecret.Destroy()
 
 		authPasswdSecret, err := s.AuthPassword.Get()
 		if err != nil {
-			return fmt.Errorf("getting secname failed: %w", err)
+			return fmt.Errorf("getting auth_password failed: %w", err)
 		}
 		authPasswd := authPasswdSecret.String()
 		authPasswdSecret.Destroy()
 
 		s.listener.Params.SecurityParameters = &gosnmp.UsmSecurityParameters{
 			UserName:                 secname,
-			PrivacyProtocol:          privacyProtocol,
+			PrivacyProtocol:          s.privProtocol,
 			PrivacyPassphrase:        privPasswd,
 			AuthenticationPassphrase: authPasswd,
-			AuthenticationProtocol:   authenticationProtocol,
+			AuthenticationProtocol:   s.authProtocol,
 		}
 	}
 
@@ -316,11 +346,6 @@ func makeTrapHandler(s *SnmpTrap) gosnmp.TrapHandlerFunc {
 
 			var value interface{}
 
-			// todo: format the pdu value based on its snmp type and
-			// the mib's textual convention.  The snmp input plugin
-			// only handles textual convention for ip and mac
-			// addresses
-
 			switch v.Type {
 			case gosnmp.ObjectIdentifier:
 				val, ok := v.Value.(string)
@@ -345,13 +370,6 @@ func makeTrapHandler(s *SnmpTrap) gosnmp.TrapHandlerFunc {
 					setTrapOid(tags, val, e)
 					continue
 				}
-			case gosnmp.OctetString:
-				// OctetStrings may contain hex data that needs its own conversion
-				if !utf8.ValidString(string(v.Value.([]byte)[:])) {
-					value = hex.EncodeToString(v.Value.([]byte))
-				} else {
-					value = v.Value
-				}
 			default:
 				value = v.Value
 			}
@@ -362,6 +380,19 @@ func makeTrapHandler(s *SnmpTrap) gosnmp.TrapHandlerFunc {
 				return
 			}
 
+			// Format the value based on the mib's textual convention
+			// or enumeration
+			if s.TranslateValues {
+				if converted, ok := translateValue(v, e); ok {
+					value = converted
+				}
+			}
+
+			// OctetStrings may contain hex data that needs its own conversion
+			if buf, ok := value.([]byte); ok && !utf8.Valid(buf) {
+				value = hex.EncodeToString(buf)
+			}
+
 			name := e.OidText
 
 			fields[name] = value
@@ -381,6 +412,14 @@ func makeTrapHandler(s *SnmpTrap) gosnmp.TrapHandlerFunc {
 			}
 		}
 
-		s.acc.AddFields("snmp_trap", fields, tags, tm)
+		measurement := "snmp_trap"
+		for i := range s.Templates {
+			if s.Templates[i].matches(tags) {
+				measurement, fields = s.Templates[i].apply(fields, tags)
+				break
+			}
+		}
+
+		s.acc.AddFields(measurement, fields, tags, tm)
 	}
 }
@@ -29,7 +29,7 @@ type testTranslator struct {
 func (t *testTranslator) lookup(input string) (snmp.MibEntry, error) {
 	for _, entry := range t.entries {
 		if input == entry.oid {
-			return snmp.MibEntry{MibName: entry.e.MibName, OidText: entry.e.OidText}, nil
+			return entry.e, nil
 		}
 	}
 	return snmp.MibEntry{}, fmt.Errorf("unexpected oid")
@@ -61,14 +61,14 @@ func newUsmSecurityParametersForV3(authProto string, privProto string, username
 		authenticationProtocol = gosnmp.MD5
 	case "sha":
 		authenticationProtocol = gosnmp.SHA
-	//case "sha224":
-	//	authenticationProtocol = gosnmp.SHA224
-	//case "sha256":
-	//	authenticationProtocol = gosnmp.SHA256
-	//case "sha384":
-	//	authenticationProtocol = gosnmp.SHA384
-	//case "sha512":
-	//	authenticationProtocol = gosnmp.SHA512
+	case "sha224":
+		authenticationProtocol = gosnmp.SHA224
+	case "sha256":
+		authenticationProtocol = gosnmp.SHA256
+	case "sha384":
+		authenticationProtocol = gosnmp.SHA384
+	case "sha512":
+		authenticationProtocol = gosnmp.SHA512
 	case "":
 		authenticationProtocol = gosnmp.NoAuth
 	default:
@@ -501,255 +501,254 @@ func TestReceiveTrap(t *testing.T) {
 				),
 			},
 		},
-		/*
-			//ordinary v3 coldstart trap SHA224 auth and no priv
-			{
-				name:      "v3 coldStart authShaNoPriv",
-				version:   gosnmp.Version3,
-				secName:   "authSha224NoPriv",
-				secLevel:  "authNoPriv",
-				authProto: "SHA224",
-				authPass:  "passpass",
-				trap: gosnmp.SnmpTrap{
-					Variables: []gosnmp.SnmpPDU{
-						{
-							Name:  ".1.3.6.1.2.1.1.3.0",
-							Type:  gosnmp.TimeTicks,
-							Value: now,
-						},
-						{
-							Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
-							Type:  gosnmp.ObjectIdentifier,
-							Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
-						},
-					},
-				},
-				entries: []entry{
+		//ordinary v3 coldstart trap SHA224 auth and no priv
+		{
+			name:      "v3 coldStart authSha224NoPriv",
+			version:   gosnmp.Version3,
+			secName:   "authSha224NoPriv",
+			secLevel:  "authNoPriv",
+			authProto: "SHA224",
+			authPass:  "passpass",
+			trap: gosnmp.SnmpTrap{
+				Variables: []gosnmp.SnmpPDU{
 					{
-						oid: ".1.3.6.1.6.3.1.1.4.1.0",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "snmpTrapOID.0",
-						},
+						Name:  ".1.3.6.1.2.1.1.3.0",
+						Type:  gosnmp.TimeTicks,
+						Value: now,
 					},
 					{
-						oid: ".1.3.6.1.6.3.1.1.5.1",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "coldStart",
-						},
+						Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
+						Type:  gosnmp.ObjectIdentifier,
+						Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
 					},
-					{
-						oid: ".1.3.6.1.2.1.1.3.0",
-						e: snmp.MibEntry{
-							MibName: "UNUSED_MIB_NAME",
-							OidText: "sysUpTimeInstance",
-						},
-					},
-				},
-				metrics: []telegraf.Metric{
-					testutil.MustMetric(
-						"snmp_trap", // name
-						map[string]string{ // tags
-							"oid":     ".1.3.6.1.6.3.1.1.5.1",
-							"name":    "coldStart",
-							"mib":     "SNMPv2-MIB",
-							"version": "3",
-							"source":  "127.0.0.1",
-						},
-						map[string]interface{}{ // fields
-							"sysUpTimeInstance": now,
-						},
-						fakeTime,
-					),
-				},
-			},
-			//ordinary v3 coldstart trap SHA256 auth and no priv
-			{
-				name:      "v3 coldStart authSha256NoPriv",
-				version:   gosnmp.Version3,
-				secName:   "authSha256NoPriv",
-				secLevel:  "authNoPriv",
-				authProto: "SHA256",
-				authPass:  "passpass",
-				trap: gosnmp.SnmpTrap{
-					Variables: []gosnmp.SnmpPDU{
-						{
-							Name:  ".1.3.6.1.2.1.1.3.0",
-							Type:  gosnmp.TimeTicks,
-							Value: now,
-						},
-						{
-							Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
-							Type:  gosnmp.ObjectIdentifier,
-							Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
-						},
-					},
-				},
-				entries: []entry{
-					{
-						oid: ".1.3.6.1.6.3.1.1.4.1.0",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "snmpTrapOID.0",
-						},
+				},
+			},
+			entries: []entry{
+				{
+					oid: ".1.3.6.1.6.3.1.1.4.1.0",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "snmpTrapOID.0",
 					},
-					{
-						oid: ".1.3.6.1.6.3.1.1.5.1",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "coldStart",
-						},
+				},
+				{
+					oid: ".1.3.6.1.6.3.1.1.5.1",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "coldStart",
 					},
+				},
+				{
+					oid: ".1.3.6.1.2.1.1.3.0",
+					e: snmp.MibEntry{
+						MibName: "UNUSED_MIB_NAME",
+						OidText: "sysUpTimeInstance",
+					},
+				},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"snmp_trap", // name
+					map[string]string{ // tags
+						"oid":     ".1.3.6.1.6.3.1.1.5.1",
+						"name":    "coldStart",
+						"mib":     "SNMPv2-MIB",
+						"version": "3",
+						"source":  "127.0.0.1",
+					},
+					map[string]interface{}{ // fields
+						"sysUpTimeInstance": now,
+					},
+					fakeTime,
+				),
+			},
+		},
+		//ordinary v3 coldstart trap SHA256 auth and no priv
+		{
+			name:      "v3 coldStart authSha256NoPriv",
+			version:   gosnmp.Version3,
+			secName:   "authSha256NoPriv",
+			secLevel:  "authNoPriv",
+			authProto: "SHA256",
+			authPass:  "passpass",
+			trap: gosnmp.SnmpTrap{
+				Variables: []gosnmp.SnmpPDU{
 					{
-						oid: ".1.3.6.1.2.1.1.3.0",
-						e: snmp.MibEntry{
-							MibName: "UNUSED_MIB_NAME",
-							OidText: "sysUpTimeInstance",
-						},
-					},
-				},
-				metrics: []telegraf.Metric{
-					testutil.MustMetric(
-						"snmp_trap", // name
-						map[string]string{ // tags
-							"oid":     ".1.3.6.1.6.3.1.1.5.1",
-							"name":    "coldStart",
-							"mib":     "SNMPv2-MIB",
-							"version": "3",
-							"source":  "127.0.0.1",
-						},
-						map[string]interface{}{ // fields
-							"sysUpTimeInstance": now,
-						},
-						fakeTime,
-					),
-				},
-			},
-			//ordinary v3 coldstart trap SHA384 auth and no priv
-			{
-				name:      "v3 coldStart authSha384NoPriv",
-				version:   gosnmp.Version3,
-				secName:   "authSha384NoPriv",
-				secLevel:  "authNoPriv",
-				authProto: "SHA384",
-				authPass:  "passpass",
-				trap: gosnmp.SnmpTrap{
-					Variables: []gosnmp.SnmpPDU{
-						{
-							Name:  ".1.3.6.1.2.1.1.3.0",
-							Type:  gosnmp.TimeTicks,
-							Value: now,
-						},
-						{
-							Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
-							Type:  gosnmp.ObjectIdentifier,
-							Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
-						},
-					},
-				},
-				entries: []entry{
-					{
-						oid: ".1.3.6.1.6.3.1.1.4.1.0",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "snmpTrapOID.0",
-						},
+						Name:  ".1.3.6.1.2.1.1.3.0",
+						Type:  gosnmp.TimeTicks,
+						Value: now,
 					},
 					{
-						oid: ".1.3.6.1.6.3.1.1.5.1",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "coldStart",
-						},
+						Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
+						Type:  gosnmp.ObjectIdentifier,
+						Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
 					},
+				},
+			},
+			entries: []entry{
+				{
+					oid: ".1.3.6.1.6.3.1.1.4.1.0",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "snmpTrapOID.0",
+					},
+				},
+				{
+					oid: ".1.3.6.1.6.3.1.1.5.1",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "coldStart",
+					},
+				},
+				{
+					oid: ".1.3.6.1.2.1.1.3.0",
+					e: snmp.MibEntry{
+						MibName: "UNUSED_MIB_NAME",
+						OidText: "sysUpTimeInstance",
+					},
+				},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"snmp_trap", // name
+					map[string]string{ // tags
+						"oid":     ".1.3.6.1.6.3.1.1.5.1",
+						"name":    "coldStart",
+						"mib":     "SNMPv2-MIB",
+						"version": "3",
+						"source":  "127.0.0.1",
+					},
+					map[string]interface{}{ // fields
+						"sysUpTimeInstance": now,
+					},
+					fakeTime,
+				),
+			},
+		},
+		//ordinary v3 coldstart trap SHA384 auth and no priv
+		{
+			name:      "v3 coldStart authSha384NoPriv",
+			version:   gosnmp.Version3,
+			secName:   "authSha384NoPriv",
+			secLevel:  "authNoPriv",
+			authProto: "SHA384",
+			authPass:  "passpass",
+			trap: gosnmp.SnmpTrap{
+				Variables: []gosnmp.SnmpPDU{
 					{
-						oid: ".1.3.6.1.2.1.1.3.0",
-						e: snmp.MibEntry{
-							MibName: "UNUSED_MIB_NAME",
-							OidText: "sysUpTimeInstance",
-						},
-					},
-				},
-				metrics: []telegraf.Metric{
-					testutil.MustMetric(
-						"snmp_trap", // name
-						map[string]string{ // tags
-							"oid":     ".1.3.6.1.6.3.1.1.5.1",
-							"name":    "coldStart",
-							"mib":     "SNMPv2-MIB",
-							"version": "3",
-							"source":  "127.0.0.1",
-						},
-						map[string]interface{}{ // fields
-							"sysUpTimeInstance": now,
-						},
-						fakeTime,
-					),
-				},
-			},
-			//ordinary v3 coldstart trap SHA512 auth and no priv
-			{
-				name:      "v3 coldStart authShaNoPriv",
-				version:   gosnmp.Version3,
-				secName:   "authSha512NoPriv",
-				secLevel:  "authNoPriv",
-				authProto: "SHA512",
-				authPass:  "passpass",
-				trap: gosnmp.SnmpTrap{
-					Variables: []gosnmp.SnmpPDU{
-						{
-							Name:  ".1.3.6.1.2.1.1.3.0",
-							Type:  gosnmp.TimeTicks,
-							Value: now,
-						},
-						{
-							Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
-							Type:  gosnmp.ObjectIdentifier,
-							Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
-						},
-					},
-				},
-				entries: []entry{
+						Name:  ".1.3.6.1.2.1.1.3.0",
+						Type:  gosnmp.TimeTicks,
+						Value: now,
+					},
 					{
-						oid: ".1.3.6.1.6.3.1.1.4.1.0",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "snmpTrapOID.0",
-						},
+						Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
+						Type:  gosnmp.ObjectIdentifier,
+						Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
+					},
+				},
+			},
+			entries: []entry{
+				{
+					oid: ".1.3.6.1.6.3.1.1.4.1.0",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "snmpTrapOID.0",
+					},
+				},
+				{
+					oid: ".1.3.6.1.6.3.1.1.5.1",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "coldStart",
+					},
+				},
+				{
+					oid: ".1.3.6.1.2.1.1.3.0",
+					e: snmp.MibEntry{
+						MibName: "UNUSED_MIB_NAME",
+						OidText: "sysUpTimeInstance",
+					},
+				},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"snmp_trap", // name
+					map[string]string{ // tags
+						"oid":     ".1.3.6.1.6.3.1.1.5.1",
+						"name":    "coldStart",
+						"mib":     "SNMPv2-MIB",
+						"version": "3",
+						"source":  "127.0.0.1",
 					},
+					map[string]interface{}{ // fields
+						"sysUpTimeInstance": now,
+					},
+					fakeTime,
+				),
+			},
+		},
+		//ordinary v3 coldstart trap SHA512 auth and no priv
+		{
+			name:      "v3 coldStart authSha512NoPriv",
+			version:   gosnmp.Version3,
+			secName:   "authSha512NoPriv",
+			secLevel:  "authNoPriv",
+			authProto: "SHA512",
+			authPass:  "passpass",
+			trap: gosnmp.SnmpTrap{
+				Variables: []gosnmp.SnmpPDU{
 					{
-						oid: ".1.3.6.1.6.3.1.1.5.1",
-						e: snmp.MibEntry{
-							MibName: "SNMPv2-MIB",
-							OidText: "coldStart",
-						},
+						Name:  ".1.3.6.1.2.1.1.3.0",
+						Type:  gosnmp.TimeTicks,
+						Value: now,
 					},
 					{
-						oid: ".1.3.6.1.2.1.1.3.0",
-						e: snmp.MibEntry{
-							MibName: "UNUSED_MIB_NAME",
-							OidText: "sysUpTimeInstance",
-						},
-					},
-				},
-				metrics: []telegraf.Metric{
-					testutil.MustMetric(
-						"snmp_trap", // name
-						map[string]string{ // tags
-							"oid":     ".1.3.6.1.6.3.1.1.5.1",
-							"name":    "coldStart",
-							"mib":     "SNMPv2-MIB",
-							"version": "3",
-							"source":  "127.0.0.1",
-						},
-						map[string]interface{}{ // fields
-							"sysUpTimeInstance": now,
-						},
-						fakeTime,
-					),
-				},
-			},*/
+						Name:  ".1.3.6.1.6.3.1.1.4.1.0", // SNMPv2-MIB::snmpTrapOID.0
+						Type:  gosnmp.ObjectIdentifier,
+						Value: ".1.3.6.1.6.3.1.1.5.1", // coldStart
+					},
+				},
+			},
+			entries: []entry{
+				{
+					oid: ".1.3.6.1.6.3.1.1.4.1.0",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "snmpTrapOID.0",
+					},
+				},
+				{
+					oid: ".1.3.6.1.6.3.1.1.5.1",
+					e: snmp.MibEntry{
+						MibName: "SNMPv2-MIB",
+						OidText: "coldStart",
+					},
+				},
+				{
+					oid: ".1.3.6.1.2.1.1.3.0",
+					e: snmp.MibEntry{
+						MibName: "UNUSED_MIB_NAME",
+						OidText: "sysUpTimeInstance",
+					},
+				},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"snmp_trap", // name
+					map[string]string{ // tags
+						"oid":     ".1.3.6.1.6.3.1.1.5.1",
+						"name":    "coldStart",
+						"mib":     "SNMPv2-MIB",
+						"version": "3",
+						"source":  "127.0.0.1",
+					},
+					map[string]interface{}{ // fields
+						"sysUpTimeInstance": now,
+					},
+					fakeTime,
+				),
+			},
+		},
 		//ordinary v3 coldstart trap SHA auth and no priv
 		{
 			name:      "v3 coldStart authShaNoPriv",
@@ -1713,3 +1712,391 @@ func TestReceiveTrapMultipleConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestInitSecurityFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		secLevel  string
+		authProto string
+		privProto string
+		expected  string
+	}{
+		{
+			name:     "unknown security level",
+			secLevel: "foo",
+			expected: `unknown security level "foo"`,
+		},
+		{
+			name:      "unknown authentication protocol",
+			secLevel:  "authNoPriv",
+			authProto: "SHA1024",
+			expected:  `unknown authentication protocol "SHA1024"`,
+		},
+		{
+			name:      "unknown privacy protocol",
+			secLevel:  "authPriv",
+			authProto: "SHA",
+			privProto: "ROT13",
+			expected:  `unknown privacy protocol "ROT13"`,
+		},
+		{
+			name:     "missing authentication protocol",
+			secLevel: "authNoPriv",
+			expected: `security level "authNoPriv" requires an authentication protocol`,
+		},
+		{
+			name:      "missing privacy protocol",
+			secLevel:  "authPriv",
+			authProto: "SHA256",
+			expected:  `security level "authPriv" requires a privacy protocol`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SnmpTrap{
+				Version:      "3",
+				SecLevel:     tt.secLevel,
+				AuthProtocol: tt.authProto,
+				PrivProtocol: tt.privProto,
+				Translator:   "netsnmp",
+				Log:          testutil.Logger{},
+			}
+			require.EqualError(t, s.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitTemplateFail(t *testing.T) {
+	s := &SnmpTrap{
+		Translator: "netsnmp",
+		Templates: []trapTemplate{
+			{Trap: "IF-MIB::linkDown", Tags: []string{"ifDescr"}, Fields: []string{"ifDescr"}},
+		},
+		Log: testutil.Logger{},
+	}
+	require.EqualError(t, s.Init(), `template 1: varbind "ifDescr" configured as both tag and field`)
+}
+
+// linkDownEntries resolves the OIDs of an IF-MIB::linkDown trap for
+// interface 2 including the MIB information for formatting the values
+var linkDownEntries = []entry{
+	{
+		oid: ".1.3.6.1.6.3.1.1.4.1.0",
+		e:   snmp.MibEntry{MibName: "SNMPv2-MIB", OidText: "snmpTrapOID.0"},
+	},
+	{
+		oid: ".1.3.6.1.6.3.1.1.5.3",
+		e:   snmp.MibEntry{MibName: "IF-MIB", OidText: "linkDown"},
+	},
+	{
+		oid: ".1.3.6.1.2.1.1.3.0",
+		e:   snmp.MibEntry{MibName: "SNMPv2-MIB", OidText: "sysUpTimeInstance"},
+	},
+	{
+		oid: ".1.3.6.1.2.1.2.2.1.1.2",
+		e:   snmp.MibEntry{MibName: "IF-MIB", OidText: "ifIndex.2"},
+	},
+	{
+		oid: ".1.3.6.1.2.1.2.2.1.2.2",
+		e:   snmp.MibEntry{MibName: "IF-MIB", OidText: "ifDescr.2"},
+	},
+	{
+		oid: ".1.3.6.1.2.1.2.2.1.6.2",
+		e:   snmp.MibEntry{MibName: "IF-MIB", OidText: "ifPhysAddress.2", Conversion: "hwaddr"},
+	},
+	{
+		oid: ".1.3.6.1.2.1.2.2.1.7.2",
+		e: snmp.MibEntry{
+			MibName: "IF-MIB",
+			OidText: "ifAdminStatus.2",
+			Enums:   map[int64]string{1: "up", 2: "down", 3: "testing"},
+		},
+	},
+	{
+		oid: ".1.3.6.1.2.1.2.2.1.8.2",
+		e: snmp.MibEntry{
+			MibName: "IF-MIB",
+			OidText: "ifOperStatus.2",
+			Enums:   map[int64]string{1: "up", 2: "down", 3: "testing"},
+		},
+	},
+	{
+		oid: ".1.3.6.1.2.1.31.1.1.1.16.2",
+		e:   snmp.MibEntry{MibName: "IF-MIB", OidText: "ifLastChange.2", Conversion: "datetime"},
+	},
+}
+
+var linkDownPacket = &gosnmp.SnmpPacket{
+	Version:   gosnmp.Version2c,
+	Community: "public",
+	Variables: []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(123123123)},
+		{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+		{Name: ".1.3.6.1.2.1.2.2.1.1.2", Type: gosnmp.Integer, Value: 2},
+		{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: []byte("eth1")},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.2", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1b, 0x21, 0x3c, 0x4d, 0xfe}},
+		{Name: ".1.3.6.1.2.1.2.2.1.7.2", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.2.2.1.8.2", Type: gosnmp.Integer, Value: 2},
+		{
+			Name:  ".1.3.6.1.2.1.31.1.1.1.16.2",
+			Type:  gosnmp.OctetString,
+			Value: []byte{0x07, 0xe8, 0x03, 0x0d, 0x0a, 0x1e, 0x2d, 0x05, '+', 0x01, 0x00},
+		},
+	},
+}
+
+func TestTranslateValues(t *testing.T) {
+	fakeTime := time.Unix(456456456, 456)
+
+	tests := []struct {
+		name      string
+		translate bool
+		expected  map[string]interface{}
+	}{
+		{
+			name: "raw values",
+			expected: map[string]interface{}{
+				"sysUpTimeInstance": uint32(123123123),
+				"ifIndex.2":         2,
+				"ifDescr.2":         "eth1",
+				"ifPhysAddress.2":   "001b213c4dfe",
+				"ifAdminStatus.2":   1,
+				"ifOperStatus.2":    2,
+				"ifLastChange.2":    "07e8030d0a1e2d052b0100",
+			},
+		},
+		{
+			name:      "translated values",
+			translate: true,
+			expected: map[string]interface{}{
+				"sysUpTimeInstance": uint32(123123123),
+				"ifIndex.2":         2,
+				"ifDescr.2":         "eth1",
+				"ifPhysAddress.2":   "00:1b:21:3c:4d:fe",
+				"ifAdminStatus.2":   "up",
+				"ifOperStatus.2":    "down",
+				"ifLastChange.2":    "2024-03-13T10:30:45.5+01:00",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acc testutil.Accumulator
+			s := &SnmpTrap{
+				TranslateValues: tt.translate,
+				Log:             testutil.Logger{},
+				acc:             &acc,
+				timeFunc:        func() time.Time { return fakeTime },
+				transl:          newTestTranslator(linkDownEntries),
+			}
+
+			handler := makeTrapHandler(s)
+			handler(linkDownPacket, &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+
+			expected := []telegraf.Metric{
+				testutil.MustMetric(
+					"snmp_trap",
+					map[string]string{
+						"oid":       ".1.3.6.1.6.3.1.1.5.3",
+						"name":      "linkDown",
+						"mib":       "IF-MIB",
+						"version":   "2c",
+						"source":    "127.0.0.1",
+						"community": "public",
+					},
+					tt.expected,
+					fakeTime,
+				),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	fakeTime := time.Unix(456456456, 456)
+
+	tests := []struct {
+		name      string
+		templates []trapTemplate
+		expected  telegraf.Metric
+	}{
+		{
+			name: "no match",
+			templates: []trapTemplate{
+				{Trap: "IF-MIB::linkUp", Measurement: "interface_link"},
+			},
+			expected: testutil.MustMetric(
+				"snmp_trap",
+				map[string]string{
+					"oid":       ".1.3.6.1.6.3.1.1.5.3",
+					"name":      "linkDown",
+					"mib":       "IF-MIB",
+					"version":   "2c",
+					"source":    "127.0.0.1",
+					"community": "public",
+				},
+				map[string]interface{}{
+					"sysUpTimeInstance": uint32(123123123),
+					"ifIndex.2":         2,
+					"ifDescr.2":         "eth1",
+					"ifPhysAddress.2":   "00:1b:21:3c:4d:fe",
+					"ifAdminStatus.2":   "up",
+					"ifOperStatus.2":    "down",
+					"ifLastChange.2":    "2024-03-13T10:30:45.5+01:00",
+				},
+				fakeTime,
+			),
+		},
+		{
+			name: "qualified name with index",
+			templates: []trapTemplate{
+				{Trap: "IF-MIB::linkUp", Measurement: "wrong"},
+				{
+					Trap:        "IF-MIB::linkDown",
+					Measurement: "interface_link",
+					Tags:        []string{"ifDescr"},
+					Fields:      []string{"ifAdminStatus", "ifOperStatus"},
+					Rename:      map[string]string{"ifDescr": "interface", "ifOperStatus": "oper_status"},
+					StripIndex:  true,
+				},
+			},
+			expected: testutil.MustMetric(
+				"interface_link",
+				map[string]string{
+					"oid":       ".1.3.6.1.6.3.1.1.5.3",
+					"name":      "linkDown",
+					"mib":       "IF-MIB",
+					"version":   "2c",
+					"source":    "127.0.0.1",
+					"community": "public",
+					"index":     "2",
+					"interface": "eth1",
+				},
+				map[string]interface{}{
+					"ifAdminStatus": "up",
+					"oper_status":   "down",
+				},
+				fakeTime,
+			),
+		},
+		{
+			name: "numeric oid",
+			templates: []trapTemplate{
+				{
+					Trap: "1.3.6.1.6.3.1.1.5.3",
+					Tags: []string{"ifDescr.2"},
+				},
+			},
+			expected: testutil.MustMetric(
+				"snmp_trap",
+				map[string]string{
+					"oid":       ".1.3.6.1.6.3.1.1.5.3",
+					"name":      "linkDown",
+					"mib":       "IF-MIB",
+					"version":   "2c",
+					"source":    "127.0.0.1",
+					"community": "public",
+					"ifDescr.2": "eth1",
+				},
+				map[string]interface{}{
+					"sysUpTimeInstance": uint32(123123123),
+					"ifIndex.2":         2,
+					"ifPhysAddress.2":   "00:1b:21:3c:4d:fe",
+					"ifAdminStatus.2":   "up",
+					"ifOperStatus.2":    "down",
+					"ifLastChange.2":    "2024-03-13T10:30:45.5+01:00",
+				},
+				fakeTime,
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SnmpTrap{
+				Translator:      "netsnmp",
+				TranslateValues: true,
+				Templates:       tt.templates,
+				Log:             testutil.Logger{},
+			}
+			require.NoError(t, s.Init())
+
+			var acc testutil.Accumulator
+			s.acc = &acc
+			s.timeFunc = func() time.Time { return fakeTime }
+			s.transl = newTestTranslator(linkDownEntries)
+
+			handler := makeTrapHandler(s)
+			handler(linkDownPacket, &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestNetsnmpTranslatorValueFormat(t *testing.T) {
+	outputs := map[string]string{
+		".1.3.6.1.2.1.2.2.1.8.2": `IF-MIB::ifOperStatus.2
+ifOperStatus OBJECT-TYPE
+  -- FROM	IF-MIB
+  SYNTAX	INTEGER {up(1), down(2), testing(3), unknown(4), dormant(5), notPresent(6), lowerLayerDown(7)}
+  MAX-ACCESS	read-only
+  STATUS	current
+  DESCRIPTION	"The current operational state of the interface."
+::= { iso(1) org(3) dod(6) internet(1) mgmt(2) mib-2(1) interfaces(2) ifTable(2) ifEntry(1) ifOperStatus(8) 2 }
+`,
+		".1.3.6.1.2.1.2.2.1.6.2": `IF-MIB::ifPhysAddress.2
+ifPhysAddress OBJECT-TYPE
+  -- FROM	IF-MIB
+  -- TEXTUAL CONVENTION PhysAddress
+  SYNTAX	OCTET STRING
+  DISPLAY-HINT	"1x:"
+  MAX-ACCESS	read-only
+  STATUS	current
+  DESCRIPTION	"The interface's address at its protocol sub-layer."
+::= { iso(1) org(3) dod(6) internet(1) mgmt(2) mib-2(1) interfaces(2) ifTable(2) ifEntry(1) ifPhysAddress(6) 2 }
+`,
+	}
+
+	tr := newNetsnmpTranslator(defaultTimeout)
+	tr.execCmd = func(_ config.Duration, _ string, args ...string) ([]byte, error) {
+		out, found := outputs[args[len(args)-1]]
+		if !found {
+			return nil, fmt.Errorf("unexpected oid")
+		}
+		return []byte(out), nil
+	}
+
+	e, err := tr.lookup(".1.3.6.1.2.1.2.2.1.8.2")
+	require.NoError(t, err)
+	require.Equal(t, snmp.MibEntry{
+		MibName: "IF-MIB",
+		OidText: "ifOperStatus.2",
+		Enums: map[int64]string{
+			1: "up",
+			2: "down",
+			3: "testing",
+			4: "unknown",
+			5: "dormant",
+			6: "notPresent",
+			7: "lowerLayerDown",
+		},
+	}, e)
+
+	e, err = tr.lookup(".1.3.6.1.2.1.2.2.1.6.2")
+	require.NoError(t, err)
+	require.Equal(t, snmp.MibEntry{MibName: "IF-MIB", OidText: "ifPhysAddress.2", Conversion: "hwaddr"}, e)
+}
+
+func TestParseDateAndTime(t *testing.T) {
+	actual, err := parseDateAndTime([]byte{0x07, 0xe8, 0x03, 0x0d, 0x0a, 0x1e, 0x2d, 0x00})
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 13, 10, 30, 45, 0, time.UTC), actual)
+
+	actual, err = parseDateAndTime([]byte{0x07, 0xe8, 0x03, 0x0d, 0x0a, 0x1e, 0x2d, 0x00, '-', 0x05, 0x1e})
+	require.NoError(t, err)
+	require.Equal(t, "2024-03-13T10:30:45-05:30", actual.Format(time.RFC3339))
+
+	_, err = parseDateAndTime([]byte{0x07, 0xe8, 0x03})
+	require.EqualError(t, err, "invalid length 3")
+}
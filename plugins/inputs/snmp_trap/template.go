@@ -0,0 +1,102 @@
+package snmp_trap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// trapTemplate converts the traps matching the given name or OID to metrics
+// with a custom measurement name, tags and fields
+type trapTemplate struct {
+	Trap        string            `toml:"trap"`
+	Measurement string            `toml:"measurement"`
+	Tags        []string          `toml:"tags"`
+	Fields      []string          `toml:"fields"`
+	Rename      map[string]string `toml:"rename"`
+	StripIndex  bool              `toml:"strip_index"`
+
+	tags   map[string]bool
+	fields map[string]bool
+}
+
+func (t *trapTemplate) init() error {
+	if t.Trap == "" {
+		return errors.New("trap must be set")
+	}
+
+	// Accept numeric OIDs with and without the leading dot
+	if t.Trap[0] >= '0' && t.Trap[0] <= '9' {
+		t.Trap = "." + t.Trap
+	}
+
+	if t.Measurement == "" {
+		t.Measurement = "snmp_trap"
+	}
+
+	t.tags = make(map[string]bool, len(t.Tags))
+	for _, name := range t.Tags {
+		t.tags[name] = true
+	}
+	t.fields = make(map[string]bool, len(t.Fields))
+	for _, name := range t.Fields {
+		if t.tags[name] {
+			return fmt.Errorf("varbind %q configured as both tag and field", name)
+		}
+		t.fields[name] = true
+	}
+
+	return nil
+}
+
+// matches checks if the trap identified by the given tags is handled by the
+// template, using either the qualified name, the name or the numeric OID
+func (t *trapTemplate) matches(tags map[string]string) bool {
+	switch t.Trap {
+	case tags["oid"], tags["name"]:
+		return true
+	case tags["mib"] + "::" + tags["name"]:
+		return tags["mib"] != ""
+	}
+	return false
+}
+
+// apply converts the varbinds of the trap according to the template and
+// returns the measurement name and the resulting fields
+func (t *trapTemplate) apply(fields map[string]interface{}, tags map[string]string) (string, map[string]interface{}) {
+	result := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		name := key
+		if t.StripIndex {
+			if i := strings.IndexByte(key, '.'); i != -1 {
+				// Scalar objects use a ".0" suffix which carries no
+				// information so only keep table indices
+				if index := key[i+1:]; index != "0" {
+					tags["index"] = index
+				}
+				name = key[:i]
+			}
+		}
+
+		target := name
+		if n, found := t.Rename[name]; found {
+			target = n
+		}
+
+		switch {
+		case t.tags[name]:
+			tags[target] = tagValue(value)
+		case len(t.fields) == 0 || t.fields[name]:
+			result[target] = value
+		}
+	}
+
+	return t.Measurement, result
+}
+
+func tagValue(value interface{}) string {
+	if buf, ok := value.([]byte); ok {
+		return string(buf)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,67 @@
+package snmp_trap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/influxdata/telegraf/internal/snmp"
+)
+
+// translateValue formats the value of the given variable using the textual
+// convention or the enumeration of the MIB object. The second return value
+// is false if no conversion applies.
+func translateValue(v gosnmp.SnmpPDU, e snmp.MibEntry) (interface{}, bool) {
+	if buf, ok := v.Value.([]byte); ok {
+		switch e.Conversion {
+		case "hwaddr":
+			return net.HardwareAddr(buf).String(), true
+		case "ipaddr":
+			if len(buf) == net.IPv4len || len(buf) == net.IPv6len {
+				return net.IP(buf).String(), true
+			}
+		case "datetime":
+			if t, err := parseDateAndTime(buf); err == nil {
+				return t.Format(time.RFC3339Nano), true
+			}
+		}
+		return nil, false
+	}
+
+	if len(e.Enums) > 0 && v.Type == gosnmp.Integer {
+		if n, ok := v.Value.(int); ok {
+			if name, found := e.Enums[int64(n)]; found {
+				return name, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// parseDateAndTime decodes the DateAndTime textual convention of SNMPv2-TC
+// with an optional timezone. Values without timezone are assumed to be UTC.
+func parseDateAndTime(buf []byte) (time.Time, error) {
+	if len(buf) != 8 && len(buf) != 11 {
+		return time.Time{}, fmt.Errorf("invalid length %d", len(buf))
+	}
+
+	loc := time.UTC
+	if len(buf) == 11 {
+		offset := int(buf[9])*3600 + int(buf[10])*60
+		switch buf[8] {
+		case '+':
+		case '-':
+			offset = -offset
+		default:
+			return time.Time{}, fmt.Errorf("invalid timezone direction %q", buf[8])
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	year := int(binary.BigEndian.Uint16(buf[0:2]))
+	t := time.Date(year, time.Month(buf[2]), int(buf[3]), int(buf[4]), int(buf[5]), int(buf[6]), int(buf[7])*100000000, loc)
+	return t, nil
+}
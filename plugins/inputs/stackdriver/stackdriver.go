@@ -17,6 +17,9 @@ import (
 	"google.golang.org/api/iterator"
 	distributionpb "google.golang.org/genproto/googleapis/api/distribution"
 	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -33,19 +36,26 @@ var sampleConfig string
 
 const (
 	defaultRateLimit = 14
+
+	// Number of times a request is retried after exceeding the quota
+	maxQuotaRetries = 5
 )
 
 var (
-	defaultCacheTTL = config.Duration(1 * time.Hour)
-	defaultWindow   = config.Duration(1 * time.Minute)
-	defaultDelay    = config.Duration(5 * time.Minute)
+	defaultCacheTTL   = config.Duration(1 * time.Hour)
+	defaultWindow     = config.Duration(1 * time.Minute)
+	defaultDelay      = config.Duration(5 * time.Minute)
+	defaultMaxBackoff = config.Duration(1 * time.Minute)
 )
 
 type (
 	// Stackdriver is the Google Stackdriver config info.
 	Stackdriver struct {
 		Project                         string                `toml:"project"`
+		Projects                        []string              `toml:"projects"`
 		RateLimit                       int                   `toml:"rate_limit"`
+		MaxConcurrentRequests           int                   `toml:"max_concurrent_requests"`
+		MaxBackoff                      config.Duration       `toml:"max_backoff"`
 		Window                          config.Duration       `toml:"window"`
 		Delay                           config.Duration       `toml:"delay"`
 		CacheTTL                        config.Duration       `toml:"cache_ttl"`
@@ -53,13 +63,22 @@ type (
 		MetricTypePrefixExclude         []string              `toml:"metric_type_prefix_exclude"`
 		GatherRawDistributionBuckets    bool                  `toml:"gather_raw_distribution_buckets"`
 		DistributionAggregationAligners []string              `toml:"distribution_aggregation_aligners"`
+		DistributionFormat              string                `toml:"distribution_format"`
 		Filter                          *ListTimeSeriesFilter `toml:"filter"`
+		Queries                         []*Query              `toml:"query"`
 
 		Log telegraf.Logger
 
-		client              metricClient
-		timeSeriesConfCache *timeSeriesConfCache
-		prevEnd             time.Time
+		client               metricClient
+		timeSeriesConfCaches map[string]*timeSeriesConfCache
+		cacheLock            sync.Mutex
+		prevEnd              time.Time
+	}
+
+	// Query is a Monitoring Query Language (MQL) query
+	Query struct {
+		Measurement string `toml:"measurement"`
+		MQL         string `toml:"mql"`
 	}
 
 	// ListTimeSeriesFilter contains resource labels and metric labels
@@ -98,17 +117,47 @@ type (
 
 	// stackdriverMetricClient is a metric client for stackdriver
 	stackdriverMetricClient struct {
-		log  telegraf.Logger
-		conn *monitoring.MetricClient
+		log        telegraf.Logger
+		conn       *monitoring.MetricClient
+		queryConn  *monitoring.QueryClient
+		maxBackoff time.Duration
 
+		// Per-project state keyed by the resource name of the project
+		projects map[string]*projectState
+	}
+
+	// projectState holds the call statistics and the quota state of a project
+	projectState struct {
 		listMetricDescriptorsCalls selfstat.Stat
 		listTimeSeriesCalls        selfstat.Stat
+		queryTimeSeriesCalls       selfstat.Stat
+		quotaExceeded              selfstat.Stat
+
+		throttle *quotaThrottle
+	}
+
+	// quotaThrottle delays all requests of a project after the quota was
+	// exceeded. The delay grows exponentially with each consecutive failure
+	// and is reset by the next successful request.
+	quotaThrottle struct {
+		sync.Mutex
+		maxBackoff time.Duration
+		failures   int
+		until      time.Time
+	}
+
+	// queryResult is a time series returned by a MQL query along with the
+	// descriptor of its labels and points
+	queryResult struct {
+		descriptor *monitoringpb.TimeSeriesDescriptor
+		data       *monitoringpb.TimeSeriesData
 	}
 
 	// metricClient is convenient for testing
 	metricClient interface {
 		ListMetricDescriptors(ctx context.Context, req *monitoringpb.ListMetricDescriptorsRequest) (<-chan *metricpb.MetricDescriptor, error)
 		ListTimeSeries(ctx context.Context, req *monitoringpb.ListTimeSeriesRequest) (<-chan *monitoringpb.TimeSeries, error)
+		QueryTimeSeries(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) (<-chan *queryResult, error)
 		Close() error
 	}
 
@@ -116,6 +165,12 @@ type (
 		sync.Mutex
 		*metric.SeriesGrouper
 	}
+
+	// seriesGroupers collect the metrics of a gather by their type
+	seriesGroupers struct {
+		fields     *lockedSeriesGrouper
+		histograms *lockedSeriesGrouper
+	}
 )
 
 func (g *lockedSeriesGrouper) Add(
@@ -136,23 +191,28 @@ func (smc *stackdriverMetricClient) ListMetricDescriptors(
 	req *monitoringpb.ListMetricDescriptorsRequest,
 ) (<-chan *metricpb.MetricDescriptor, error) {
 	mdChan := make(chan *metricpb.MetricDescriptor, 1000)
+	project := smc.project(req.Name)
 
 	go func() {
 		smc.log.Debugf("List metric descriptor request filter: %s", req.Filter)
 		defer close(mdChan)
 
 		// Iterate over metric descriptors and send them to buffered channel
-		mdResp := smc.conn.ListMetricDescriptors(ctx, req)
-		smc.listMetricDescriptorsCalls.Incr(1)
-		for {
-			mdDesc, mdErr := mdResp.Next()
-			if mdErr != nil {
-				if !errors.Is(mdErr, iterator.Done) {
-					smc.log.Errorf("Failed iterating metric descriptor responses: %q: %v", req.String(), mdErr)
+		err := smc.iterate(ctx, project, func(pageToken string) (func() error, *iterator.PageInfo) {
+			r := proto.Clone(req).(*monitoringpb.ListMetricDescriptorsRequest)
+			r.PageToken = pageToken
+			mdResp := smc.conn.ListMetricDescriptors(ctx, r)
+			project.listMetricDescriptorsCalls.Incr(1)
+			return func() error {
+				mdDesc, err := mdResp.Next()
+				if err == nil {
+					mdChan <- mdDesc
 				}
-				break
-			}
-			mdChan <- mdDesc
+				return err
+			}, mdResp.PageInfo()
+		})
+		if err != nil {
+			smc.log.Errorf("Failed iterating metric descriptor responses: %q: %v", req.String(), err)
 		}
 	}()
 
@@ -165,38 +225,215 @@ func (smc *stackdriverMetricClient) ListTimeSeries(
 	req *monitoringpb.ListTimeSeriesRequest,
 ) (<-chan *monitoringpb.TimeSeries, error) {
 	tsChan := make(chan *monitoringpb.TimeSeries, 1000)
+	project := smc.project(req.Name)
 
 	go func() {
 		smc.log.Debugf("List time series request filter: %s", req.Filter)
 		defer close(tsChan)
 
 		// Iterate over timeseries and send them to buffered channel
-		tsResp := smc.conn.ListTimeSeries(ctx, req)
-		smc.listTimeSeriesCalls.Incr(1)
-		for {
-			tsDesc, tsErr := tsResp.Next()
-			if tsErr != nil {
-				if !errors.Is(tsErr, iterator.Done) {
-					smc.log.Errorf("Failed iterating time series responses: %q: %v", req.String(), tsErr)
+		err := smc.iterate(ctx, project, func(pageToken string) (func() error, *iterator.PageInfo) {
+			r := proto.Clone(req).(*monitoringpb.ListTimeSeriesRequest)
+			r.PageToken = pageToken
+			tsResp := smc.conn.ListTimeSeries(ctx, r)
+			project.listTimeSeriesCalls.Incr(1)
+			return func() error {
+				tsDesc, err := tsResp.Next()
+				if err == nil {
+					tsChan <- tsDesc
 				}
-				break
-			}
-			tsChan <- tsDesc
+				return err
+			}, tsResp.PageInfo()
+		})
+		if err != nil {
+			smc.log.Errorf("Failed iterating time series responses: %q: %v", req.String(), err)
 		}
 	}()
 
 	return tsChan, nil
 }
 
+// QueryTimeSeries implements metricClient interface
+func (smc *stackdriverMetricClient) QueryTimeSeries(
+	ctx context.Context,
+	req *monitoringpb.QueryTimeSeriesRequest,
+) (<-chan *queryResult, error) {
+	resultChan := make(chan *queryResult, 1000)
+	project := smc.project(req.Name)
+
+	go func() {
+		smc.log.Debugf("Query time series request: %s", req.Query)
+		defer close(resultChan)
+
+		// The descriptor is part of the page response and not of the
+		// individual time series, so keep track of the latest one
+		var descriptor *monitoringpb.TimeSeriesDescriptor
+		err := smc.iterate(ctx, project, func(pageToken string) (func() error, *iterator.PageInfo) {
+			r := proto.Clone(req).(*monitoringpb.QueryTimeSeriesRequest)
+			r.PageToken = pageToken
+			queryResp := smc.queryConn.QueryTimeSeries(ctx, r)
+			project.queryTimeSeriesCalls.Incr(1)
+			return func() error {
+				data, err := queryResp.Next()
+				if err != nil {
+					return err
+				}
+				if resp, ok := queryResp.Response.(*monitoringpb.QueryTimeSeriesResponse); ok && resp.TimeSeriesDescriptor != nil {
+					descriptor = resp.TimeSeriesDescriptor
+				}
+				resultChan <- &queryResult{descriptor: descriptor, data: data}
+				return nil
+			}, queryResp.PageInfo()
+		})
+		if err != nil {
+			smc.log.Errorf("Failed iterating query responses: %q: %v", req.String(), err)
+		}
+	}()
+
+	return resultChan, nil
+}
+
 // Close implements metricClient interface
 func (smc *stackdriverMetricClient) Close() error {
-	return smc.conn.Close()
+	return errors.Join(smc.conn.Close(), smc.queryConn.Close())
+}
+
+// project returns the state of the project with the given resource name
+func (smc *stackdriverMetricClient) project(name string) *projectState {
+	if state, found := smc.projects[name]; found {
+		return state
+	}
+
+	// Should not happen as all projects are registered on creation of the
+	// client, however avoid crashing on unknown projects.
+	return &projectState{
+		listMetricDescriptorsCalls: selfstat.Register("stackdriver", "list_metric_descriptors_calls", map[string]string{}),
+		listTimeSeriesCalls:        selfstat.Register("stackdriver", "list_timeseries_calls", map[string]string{}),
+		queryTimeSeriesCalls:       selfstat.Register("stackdriver", "query_timeseries_calls", map[string]string{}),
+		quotaExceeded:              selfstat.Register("stackdriver", "quota_exceeded", map[string]string{}),
+		throttle:                   &quotaThrottle{maxBackoff: smc.maxBackoff},
+	}
+}
+
+// iterate consumes the items of a listing until it is exhausted. The open
+// function starts the listing at the given page token and returns a function
+// consuming the next item along with the page info of the iterator. If the
+// quota of the project is exceeded, the listing is resumed at the failed page
+// after backing off.
+func (smc *stackdriverMetricClient) iterate(
+	ctx context.Context,
+	project *projectState,
+	open func(pageToken string) (func() error, *iterator.PageInfo),
+) error {
+	var pageToken string
+	for retries := 0; ; retries++ {
+		if err := project.throttle.wait(ctx); err != nil {
+			return err
+		}
+
+		next, pageInfo := open(pageToken)
+		var err error
+		for err == nil {
+			err = next()
+		}
+		if errors.Is(err, iterator.Done) {
+			project.throttle.succeeded()
+			return nil
+		}
+		if status.Code(err) != codes.ResourceExhausted || retries >= maxQuotaRetries {
+			return err
+		}
+
+		project.quotaExceeded.Incr(1)
+		delay := project.throttle.exceeded()
+		smc.log.Debugf("Quota exceeded, backing off for %s: %v", delay, err)
+
+		// The token is only advanced on successfully fetched pages
+		pageToken = pageInfo.Token
+	}
+}
+
+// wait blocks until the backoff of the project is over
+func (t *quotaThrottle) wait(ctx context.Context) error {
+	t.Lock()
+	delay := time.Until(t.until)
+	t.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// exceeded registers a request failing due to the quota and returns the
+// delay until the next request of the project.
+func (t *quotaThrottle) exceeded() time.Duration {
+	t.Lock()
+	defer t.Unlock()
+
+	delay := time.Second << t.failures
+	if delay > t.maxBackoff || delay <= 0 {
+		delay = t.maxBackoff
+	} else {
+		t.failures++
+	}
+
+	// Concurrent requests might fail at the same time, only extend the
+	// backoff but never shorten it
+	if until := time.Now().Add(delay); until.After(t.until) {
+		t.until = until
+	}
+	return delay
+}
+
+// succeeded resets the backoff after a successful request
+func (t *quotaThrottle) succeeded() {
+	t.Lock()
+	defer t.Unlock()
+	t.failures = 0
 }
 
 func (*Stackdriver) SampleConfig() string {
 	return sampleConfig
 }
 
+// Init performs one time setup of the plugin
+func (s *Stackdriver) Init() error {
+	if len(s.projects()) == 0 {
+		return errors.New("no project configured")
+	}
+
+	switch s.DistributionFormat {
+	case "":
+		s.DistributionFormat = "buckets"
+	case "buckets", "histogram":
+	default:
+		return fmt.Errorf("invalid distribution format %q", s.DistributionFormat)
+	}
+
+	for i, q := range s.Queries {
+		if q.Measurement == "" {
+			return fmt.Errorf("missing measurement for query %d", i+1)
+		}
+		if q.MQL == "" {
+			return fmt.Errorf("missing MQL for query %q", q.Measurement)
+		}
+	}
+
+	if s.MaxConcurrentRequests < 0 {
+		return errors.New("max_concurrent_requests must not be negative")
+	}
+
+	return nil
+}
+
 // Gather implements telegraf.Input interface
 func (s *Stackdriver) Gather(acc telegraf.Accumulator) error {
 	ctx := context.Background()
@@ -213,7 +450,39 @@ func (s *Stackdriver) Gather(acc telegraf.Accumulator) error {
 	start, end := s.updateWindow(s.prevEnd)
 	s.prevEnd = end
 
-	tsConfs, err := s.generatetimeSeriesConfs(ctx, start, end)
+	groupers := &seriesGroupers{
+		fields:     &lockedSeriesGrouper{SeriesGrouper: metric.NewSeriesGrouper()},
+		histograms: &lockedSeriesGrouper{SeriesGrouper: metric.NewSeriesGrouper()},
+	}
+
+	// Projects are gathered concurrently, each limited by its own quota
+	var wg sync.WaitGroup
+	for _, project := range s.projects() {
+		wg.Add(1)
+		go func(project string) {
+			defer wg.Done()
+			if err := s.gatherProject(ctx, project, start, end, groupers); err != nil {
+				acc.AddError(fmt.Errorf("gathering project %q failed: %w", project, err))
+			}
+		}(project)
+	}
+	wg.Wait()
+
+	for _, groupedMetric := range groupers.fields.Metrics() {
+		acc.AddMetric(groupedMetric)
+	}
+	for _, m := range groupers.histograms.Metrics() {
+		acc.AddHistogram(m.Name(), m.Fields(), m.Tags(), m.Time())
+	}
+
+	return nil
+}
+
+// Gather the time series and queries of a single project
+func (s *Stackdriver) gatherProject(
+	ctx context.Context, project string, start, end time.Time, groupers *seriesGroupers,
+) error {
+	tsConfs, err := s.generatetimeSeriesConfs(ctx, project, start, end)
 	if err != nil {
 		return err
 	}
@@ -221,26 +490,61 @@ func (s *Stackdriver) Gather(acc telegraf.Accumulator) error {
 	lmtr := limiter.NewRateLimiter(s.RateLimit, time.Second)
 	defer lmtr.Stop()
 
-	grouper := &lockedSeriesGrouper{
-		SeriesGrouper: metric.NewSeriesGrouper(),
+	// Optionally limit the number of requests in flight
+	var sem chan struct{}
+	if s.MaxConcurrentRequests > 0 {
+		sem = make(chan struct{}, s.MaxConcurrentRequests)
+	}
+	run := func(wg *sync.WaitGroup, fn func()) {
+		<-lmtr.C
+		if sem != nil {
+			sem <- struct{}{}
+		}
+		go func() {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			fn()
+		}()
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	addError := func(err error) {
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(len(tsConfs))
+	wg.Add(len(tsConfs) + len(s.Queries))
 	for _, tsConf := range tsConfs {
-		<-lmtr.C
-		go func(tsConf *timeSeriesConf) {
-			defer wg.Done()
-			acc.AddError(s.gatherTimeSeries(ctx, grouper, tsConf))
-		}(tsConf)
+		tsConf := tsConf
+		run(&wg, func() {
+			addError(s.gatherTimeSeries(ctx, groupers, tsConf))
+		})
+	}
+	for _, q := range s.Queries {
+		q := q
+		run(&wg, func() {
+			addError(s.gatherQuery(ctx, groupers, project, q))
+		})
 	}
 	wg.Wait()
 
-	for _, groupedMetric := range grouper.Metrics() {
-		acc.AddMetric(groupedMetric)
-	}
+	return errors.Join(errs...)
+}
 
-	return nil
+// Returns the configured projects
+func (s *Stackdriver) projects() []string {
+	if s.Project == "" {
+		return s.Projects
+	}
+	return append([]string{s.Project}, s.Projects...)
 }
 
 // Returns the start and end time for the next collection.
@@ -349,7 +653,7 @@ func (s *Stackdriver) newListTimeSeriesFilter(metricType string) string {
 // Create and initialize a timeSeriesConf for a given GCP metric type with
 // defaults taken from the gcp_stackdriver plugin configuration.
 func (s *Stackdriver) newTimeSeriesConf(
-	metricType string, startTime, endTime time.Time,
+	project, metricType string, startTime, endTime time.Time,
 ) *timeSeriesConf {
 	filter := s.newListTimeSeriesFilter(metricType)
 	interval := &monitoringpb.TimeInterval{
@@ -357,7 +661,7 @@ func (s *Stackdriver) newTimeSeriesConf(
 		StartTime: &timestamppb.Timestamp{Seconds: startTime.Unix()},
 	}
 	tsReq := &monitoringpb.ListTimeSeriesRequest{
-		Name:     fmt.Sprintf("projects/%s", s.Project),
+		Name:     fmt.Sprintf("projects/%s", project),
 		Filter:   filter,
 		Interval: interval,
 	}
@@ -410,20 +714,37 @@ func (s *Stackdriver) initializeStackdriverClient(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("failed to create stackdriver monitoring client: %w", err)
 		}
+		queryClient, err := monitoring.NewQueryClient(ctx)
+		if err != nil {
+			client.Close()
+			return fmt.Errorf("failed to create stackdriver query client: %w", err)
+		}
 
-		tags := map[string]string{
-			"project_id": s.Project,
+		maxBackoff := time.Duration(s.MaxBackoff)
+		if maxBackoff <= 0 {
+			maxBackoff = time.Duration(defaultMaxBackoff)
+		}
+
+		projects := make(map[string]*projectState)
+		for _, project := range s.projects() {
+			tags := map[string]string{
+				"project_id": project,
+			}
+			projects["projects/"+project] = &projectState{
+				listMetricDescriptorsCalls: selfstat.Register("stackdriver", "list_metric_descriptors_calls", tags),
+				listTimeSeriesCalls:        selfstat.Register("stackdriver", "list_timeseries_calls", tags),
+				queryTimeSeriesCalls:       selfstat.Register("stackdriver", "query_timeseries_calls", tags),
+				quotaExceeded:              selfstat.Register("stackdriver", "quota_exceeded", tags),
+				throttle:                   &quotaThrottle{maxBackoff: maxBackoff},
+			}
 		}
-		listMetricDescriptorsCalls := selfstat.Register(
-			"stackdriver", "list_metric_descriptors_calls", tags)
-		listTimeSeriesCalls := selfstat.Register(
-			"stackdriver", "list_timeseries_calls", tags)
 
 		s.client = &stackdriverMetricClient{
-			log:                        s.Log,
-			conn:                       client,
-			listMetricDescriptorsCalls: listMetricDescriptorsCalls,
-			listTimeSeriesCalls:        listTimeSeriesCalls,
+			log:        s.Log,
+			conn:       client,
+			queryConn:  queryClient,
+			maxBackoff: maxBackoff,
+			projects:   projects,
 		}
 	}
 
@@ -477,23 +798,26 @@ func (s *Stackdriver) newListMetricDescriptorsFilters() []string {
 // Generate a list of timeSeriesConfig structs by making a ListMetricDescriptors
 // API request and filtering the result against our configuration.
 func (s *Stackdriver) generatetimeSeriesConfs(
-	ctx context.Context, startTime, endTime time.Time,
+	ctx context.Context, project string, startTime, endTime time.Time,
 ) ([]*timeSeriesConf, error) {
-	if s.timeSeriesConfCache != nil && s.timeSeriesConfCache.IsValid() {
+	s.cacheLock.Lock()
+	cache := s.timeSeriesConfCaches[project]
+	s.cacheLock.Unlock()
+	if cache != nil && cache.IsValid() {
 		// Update interval for timeseries requests in timeseries cache
 		interval := &monitoringpb.TimeInterval{
 			EndTime:   &timestamppb.Timestamp{Seconds: endTime.Unix()},
 			StartTime: &timestamppb.Timestamp{Seconds: startTime.Unix()},
 		}
-		for _, timeSeriesConf := range s.timeSeriesConfCache.TimeSeriesConfs {
+		for _, timeSeriesConf := range cache.TimeSeriesConfs {
 			timeSeriesConf.listTimeSeriesRequest.Interval = interval
 		}
-		return s.timeSeriesConfCache.TimeSeriesConfs, nil
+		return cache.TimeSeriesConfs, nil
 	}
 
 	ret := []*timeSeriesConf{}
 	req := &monitoringpb.ListMetricDescriptorsRequest{
-		Name: fmt.Sprintf("projects/%s", s.Project),
+		Name: fmt.Sprintf("projects/%s", project),
 	}
 
 	filters := s.newListMetricDescriptorsFilters()
@@ -522,25 +846,30 @@ func (s *Stackdriver) generatetimeSeriesConfs(
 
 			if valueType == metricpb.MetricDescriptor_DISTRIBUTION {
 				if s.GatherRawDistributionBuckets {
-					tsConf := s.newTimeSeriesConf(metricType, startTime, endTime)
+					tsConf := s.newTimeSeriesConf(project, metricType, startTime, endTime)
 					ret = append(ret, tsConf)
 				}
 				for _, alignerStr := range s.DistributionAggregationAligners {
-					tsConf := s.newTimeSeriesConf(metricType, startTime, endTime)
+					tsConf := s.newTimeSeriesConf(project, metricType, startTime, endTime)
 					tsConf.initForAggregate(alignerStr)
 					ret = append(ret, tsConf)
 				}
 			} else {
-				ret = append(ret, s.newTimeSeriesConf(metricType, startTime, endTime))
+				ret = append(ret, s.newTimeSeriesConf(project, metricType, startTime, endTime))
 			}
 		}
 	}
 
-	s.timeSeriesConfCache = &timeSeriesConfCache{
+	s.cacheLock.Lock()
+	if s.timeSeriesConfCaches == nil {
+		s.timeSeriesConfCaches = make(map[string]*timeSeriesConfCache)
+	}
+	s.timeSeriesConfCaches[project] = &timeSeriesConfCache{
 		TimeSeriesConfs: ret,
 		Generated:       time.Now(),
 		TTL:             time.Duration(s.CacheTTL),
 	}
+	s.cacheLock.Unlock()
 
 	return ret, nil
 }
@@ -548,7 +877,7 @@ func (s *Stackdriver) generatetimeSeriesConfs(
 // Do the work to gather an individual time series. Runs inside a
 // timeseries-specific goroutine.
 func (s *Stackdriver) gatherTimeSeries(
-	ctx context.Context, grouper *lockedSeriesGrouper, tsConf *timeSeriesConf,
+	ctx context.Context, groupers *seriesGroupers, tsConf *timeSeriesConf,
 ) error {
 	tsReq := tsConf.listTimeSeriesRequest
 
@@ -573,7 +902,7 @@ func (s *Stackdriver) gatherTimeSeries(
 
 			if tsDesc.ValueType == metricpb.MetricDescriptor_DISTRIBUTION {
 				dist := p.Value.GetDistributionValue()
-				if err := s.addDistribution(dist, tags, ts, grouper, tsConf); err != nil {
+				if err := s.addDistribution(dist, tags, ts, groupers, tsConf.measurement, tsConf.fieldKey); err != nil {
 					return err
 				}
 			} else {
@@ -592,7 +921,84 @@ func (s *Stackdriver) gatherTimeSeries(
 					value = p.Value.GetStringValue()
 				}
 
-				grouper.Add(tsConf.measurement, tags, ts, tsConf.fieldKey, value)
+				groupers.fields.Add(tsConf.measurement, tags, ts, tsConf.fieldKey, value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Do the work to gather the result of a MQL query for the given project.
+// Labels of the time series are added as tags with the "resource." or
+// "metric." prefix removed, points are added as fields named after the
+// value column.
+func (s *Stackdriver) gatherQuery(
+	ctx context.Context, groupers *seriesGroupers, project string, q *Query,
+) error {
+	req := &monitoringpb.QueryTimeSeriesRequest{
+		Name:  fmt.Sprintf("projects/%s", project),
+		Query: q.MQL,
+	}
+
+	resultChan, err := s.client.QueryTimeSeries(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for result := range resultChan {
+		if result.descriptor == nil {
+			return fmt.Errorf("missing time series descriptor for query %q", q.Measurement)
+		}
+		labels := result.descriptor.LabelDescriptors
+		points := result.descriptor.PointDescriptors
+
+		tags := make(map[string]string, len(labels))
+		for i, lv := range result.data.LabelValues {
+			if i >= len(labels) {
+				break
+			}
+			key := labels[i].Key
+			if idx := strings.LastIndex(key, "."); idx >= 0 {
+				key = key[idx+1:]
+			}
+
+			switch v := lv.Value.(type) {
+			case *monitoringpb.LabelValue_StringValue:
+				tags[key] = v.StringValue
+			case *monitoringpb.LabelValue_Int64Value:
+				tags[key] = strconv.FormatInt(v.Int64Value, 10)
+			case *monitoringpb.LabelValue_BoolValue:
+				tags[key] = strconv.FormatBool(v.BoolValue)
+			}
+		}
+
+		for _, p := range result.data.PointData {
+			ts := time.Unix(p.TimeInterval.GetEndTime().GetSeconds(), 0)
+			for i, value := range p.Values {
+				if i >= len(points) {
+					break
+				}
+				field := strings.TrimPrefix(points[i].Key, "value.")
+				if field == "" {
+					field = "value"
+				}
+
+				switch points[i].ValueType {
+				case metricpb.MetricDescriptor_BOOL:
+					groupers.fields.Add(q.Measurement, tags, ts, field, value.GetBoolValue())
+				case metricpb.MetricDescriptor_INT64:
+					groupers.fields.Add(q.Measurement, tags, ts, field, value.GetInt64Value())
+				case metricpb.MetricDescriptor_DOUBLE:
+					groupers.fields.Add(q.Measurement, tags, ts, field, value.GetDoubleValue())
+				case metricpb.MetricDescriptor_STRING:
+					groupers.fields.Add(q.Measurement, tags, ts, field, value.GetStringValue())
+				case metricpb.MetricDescriptor_DISTRIBUTION:
+					dist := value.GetDistributionValue()
+					if err := s.addDistribution(dist, tags, ts, groupers, q.Measurement, field); err != nil {
+						return err
+					}
+				}
 			}
 		}
 	}
@@ -669,10 +1075,12 @@ func NewBucket(dist *distributionpb.Distribution) (Buckets, error) {
 
 // AddDistribution adds metrics from a distribution value type.
 func (s *Stackdriver) addDistribution(dist *distributionpb.Distribution, tags map[string]string, ts time.Time,
-	grouper *lockedSeriesGrouper, tsConf *timeSeriesConf,
+	groupers *seriesGroupers, name, field string,
 ) error {
-	field := tsConf.fieldKey
-	name := tsConf.measurement
+	if s.DistributionFormat == "histogram" {
+		return addHistogram(dist, tags, ts, groupers.histograms, name, field)
+	}
+	grouper := groupers.fields
 
 	grouper.Add(name, tags, ts, field+"_count", dist.Count)
 	grouper.Add(name, tags, ts, field+"_mean", dist.Mean)
@@ -689,16 +1097,22 @@ func (s *Stackdriver) addDistribution(dist *distributionpb.Distribution, tags ma
 	}
 	numBuckets := bucket.Amount()
 
+	// Do not modify the tags of the time series shared by all its points
+	bucketTags := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		bucketTags[k] = v
+	}
+
 	var i int32
 	var count int64
 	for i = 0; i < numBuckets; i++ {
 		// The last bucket is the overflow bucket, and includes all values
 		// greater than the previous bound.
 		if i == numBuckets-1 {
-			tags["lt"] = "+Inf"
+			bucketTags["lt"] = "+Inf"
 		} else {
 			upperBound := bucket.UpperBound(i)
-			tags["lt"] = strconv.FormatFloat(upperBound, 'f', -1, 64)
+			bucketTags["lt"] = strconv.FormatFloat(upperBound, 'f', -1, 64)
 		}
 
 		// Add to the cumulative count; trailing buckets with value 0 are
@@ -706,7 +1120,46 @@ func (s *Stackdriver) addDistribution(dist *distributionpb.Distribution, tags ma
 		if i < int32(len(dist.BucketCounts)) {
 			count += dist.BucketCounts[i]
 		}
-		grouper.Add(name, tags, ts, field+"_bucket", count)
+		grouper.Add(name, bucketTags, ts, field+"_bucket", count)
+	}
+
+	return nil
+}
+
+// addHistogram adds a distribution in the histogram format used by the
+// prometheus serializers, i.e. with cumulative "le" buckets and the sum and
+// count of the values. Distribution buckets exclude their upper bound, this
+// difference is negligible for continuous values.
+func addHistogram(dist *distributionpb.Distribution, tags map[string]string, ts time.Time,
+	grouper *lockedSeriesGrouper, name, field string,
+) error {
+	bucket, err := NewBucket(dist)
+	if err != nil {
+		return err
+	}
+	numBuckets := bucket.Amount()
+
+	grouper.Add(name, tags, ts, field+"_count", dist.Count)
+	grouper.Add(name, tags, ts, field+"_sum", dist.Mean*float64(dist.Count))
+
+	bucketTags := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		bucketTags[k] = v
+	}
+
+	var i int32
+	var count int64
+	for i = 0; i < numBuckets; i++ {
+		if i == numBuckets-1 {
+			bucketTags["le"] = "+Inf"
+		} else {
+			bucketTags["le"] = strconv.FormatFloat(bucket.UpperBound(i), 'f', -1, 64)
+		}
+
+		if i < int32(len(dist.BucketCounts)) {
+			count += dist.BucketCounts[i]
+		}
+		grouper.Add(name, bucketTags, ts, field+"_bucket", count)
 	}
 
 	return nil
@@ -717,6 +1170,7 @@ func init() {
 		return &Stackdriver{
 			CacheTTL:                        defaultCacheTTL,
 			RateLimit:                       defaultRateLimit,
+			MaxBackoff:                      defaultMaxBackoff,
 			Delay:                           defaultDelay,
 			GatherRawDistributionBuckets:    true,
 			DistributionAggregationAligners: []string{},
@@ -2,6 +2,7 @@ package stackdriver
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -9,11 +10,13 @@ import (
 	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/genproto/googleapis/api/distribution"
+	"google.golang.org/genproto/googleapis/api/label"
 	metricpb "google.golang.org/genproto/googleapis/api/metric"
 	"google.golang.org/genproto/googleapis/api/monitoredres"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -26,6 +29,7 @@ type Call struct {
 type MockStackdriverClient struct {
 	ListMetricDescriptorsF func(ctx context.Context, req *monitoringpb.ListMetricDescriptorsRequest) (<-chan *metricpb.MetricDescriptor, error)
 	ListTimeSeriesF        func(ctx context.Context, req *monitoringpb.ListTimeSeriesRequest) (<-chan *monitoringpb.TimeSeries, error)
+	QueryTimeSeriesF       func(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) (<-chan *queryResult, error)
 	CloseF                 func() error
 
 	calls []*Call
@@ -54,6 +58,17 @@ func (m *MockStackdriverClient) ListTimeSeries(
 	return m.ListTimeSeriesF(ctx, req)
 }
 
+func (m *MockStackdriverClient) QueryTimeSeries(
+	ctx context.Context,
+	req *monitoringpb.QueryTimeSeriesRequest,
+) (<-chan *queryResult, error) {
+	call := &Call{name: "QueryTimeSeries", args: []interface{}{ctx, req}}
+	m.Lock()
+	m.calls = append(m.calls, call)
+	m.Unlock()
+	return m.QueryTimeSeriesF(ctx, req)
+}
+
 func (m *MockStackdriverClient) Close() error {
 	call := &Call{name: "Close", args: []interface{}{}}
 	m.Lock()
@@ -66,6 +81,7 @@ func TestInitAndRegister(t *testing.T) {
 	expected := &Stackdriver{
 		CacheTTL:                        defaultCacheTTL,
 		RateLimit:                       defaultRateLimit,
+		MaxBackoff:                      defaultMaxBackoff,
 		Delay:                           defaultDelay,
 		GatherRawDistributionBuckets:    true,
 		DistributionAggregationAligners: []string{},
@@ -1232,3 +1248,258 @@ func TestNewListTimeSeriesFilter(_ *testing.T) {
 
 func TestTimeSeriesConfCacheIsValid(_ *testing.T) {
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name        string
+		stackdriver *Stackdriver
+		expected    string
+	}{
+		{
+			name:        "no project",
+			stackdriver: &Stackdriver{},
+			expected:    "no project configured",
+		},
+		{
+			name: "invalid distribution format",
+			stackdriver: &Stackdriver{
+				Project:            "test",
+				DistributionFormat: "summary",
+			},
+			expected: `invalid distribution format "summary"`,
+		},
+		{
+			name: "query without measurement",
+			stackdriver: &Stackdriver{
+				Projects: []string{"test"},
+				Queries:  []*Query{{MQL: "fetch gce_instance"}},
+			},
+			expected: "missing measurement for query 1",
+		},
+		{
+			name: "query without MQL",
+			stackdriver: &Stackdriver{
+				Projects: []string{"test"},
+				Queries:  []*Query{{Measurement: "cpu"}},
+			},
+			expected: `missing MQL for query "cpu"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.stackdriver.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGatherMultipleProjects(t *testing.T) {
+	now := time.Now().Round(time.Second)
+
+	client := &MockStackdriverClient{
+		ListMetricDescriptorsF: func(ctx context.Context, req *monitoringpb.ListMetricDescriptorsRequest) (<-chan *metricpb.MetricDescriptor, error) {
+			ch := make(chan *metricpb.MetricDescriptor, 1)
+			ch <- &metricpb.MetricDescriptor{
+				Type:      "telegraf/cpu/usage",
+				ValueType: metricpb.MetricDescriptor_DOUBLE,
+			}
+			close(ch)
+			return ch, nil
+		},
+		ListTimeSeriesF: func(ctx context.Context, req *monitoringpb.ListTimeSeriesRequest) (<-chan *monitoringpb.TimeSeries, error) {
+			ts := createTimeSeries(
+				&monitoringpb.Point{
+					Interval: &monitoringpb.TimeInterval{
+						EndTime: &timestamppb.Timestamp{
+							Seconds: now.Unix(),
+						},
+					},
+					Value: &monitoringpb.TypedValue{
+						Value: &monitoringpb.TypedValue_DoubleValue{
+							DoubleValue: 42.0,
+						},
+					},
+				},
+				metricpb.MetricDescriptor_DOUBLE,
+			)
+			ts.Resource.Labels["project_id"] = strings.TrimPrefix(req.Name, "projects/")
+
+			ch := make(chan *monitoringpb.TimeSeries, 1)
+			ch <- ts
+			close(ch)
+			return ch, nil
+		},
+		CloseF: func() error {
+			return nil
+		},
+	}
+
+	s := &Stackdriver{
+		Log:                   testutil.Logger{},
+		Project:               "project-a",
+		Projects:              []string{"project-b", "project-c"},
+		RateLimit:             10,
+		MaxConcurrentRequests: 1,
+		client:                client,
+	}
+	require.NoError(t, s.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := make([]telegraf.Metric, 0, 3)
+	for _, project := range []string{"project-a", "project-b", "project-c"} {
+		expected = append(expected, testutil.MustMetric("telegraf/cpu",
+			map[string]string{
+				"resource_type": "global",
+				"project_id":    project,
+			},
+			map[string]interface{}{
+				"usage": 42.0,
+			},
+			now),
+		)
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Each project has its own cache of time series configurations
+	require.Len(t, s.timeSeriesConfCaches, 3)
+	for project, cache := range s.timeSeriesConfCaches {
+		require.Len(t, cache.TimeSeriesConfs, 1)
+		require.Equal(t, "projects/"+project, cache.TimeSeriesConfs[0].listTimeSeriesRequest.Name)
+	}
+}
+
+func TestGatherQuery(t *testing.T) {
+	now := time.Now().Round(time.Second)
+
+	descriptor := &monitoringpb.TimeSeriesDescriptor{
+		LabelDescriptors: []*label.LabelDescriptor{
+			{Key: "resource.project_id"},
+			{Key: "resource.instance_id"},
+			{Key: "metric.instance_name"},
+		},
+		PointDescriptors: []*monitoringpb.TimeSeriesDescriptor_ValueDescriptor{
+			{Key: "value.utilization", ValueType: metricpb.MetricDescriptor_DOUBLE},
+			{Key: "value.latency", ValueType: metricpb.MetricDescriptor_DISTRIBUTION},
+		},
+	}
+	data := &monitoringpb.TimeSeriesData{
+		LabelValues: []*monitoringpb.LabelValue{
+			{Value: &monitoringpb.LabelValue_StringValue{StringValue: "test"}},
+			{Value: &monitoringpb.LabelValue_Int64Value{Int64Value: 1234}},
+			{Value: &monitoringpb.LabelValue_StringValue{StringValue: "vm-1"}},
+		},
+		PointData: []*monitoringpb.TimeSeriesData_PointData{
+			{
+				TimeInterval: &monitoringpb.TimeInterval{
+					EndTime: &timestamppb.Timestamp{Seconds: now.Unix()},
+				},
+				Values: []*monitoringpb.TypedValue{
+					{Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: 0.25}},
+					{Value: &monitoringpb.TypedValue_DistributionValue{
+						DistributionValue: &distribution.Distribution{
+							Count:        3,
+							Mean:         2.0,
+							BucketCounts: []int64{1, 2},
+							BucketOptions: &distribution.Distribution_BucketOptions{
+								Options: &distribution.Distribution_BucketOptions_ExplicitBuckets{
+									ExplicitBuckets: &distribution.Distribution_BucketOptions_Explicit{
+										Bounds: []float64{1.5},
+									},
+								},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	client := &MockStackdriverClient{
+		ListMetricDescriptorsF: func(ctx context.Context, req *monitoringpb.ListMetricDescriptorsRequest) (<-chan *metricpb.MetricDescriptor, error) {
+			ch := make(chan *metricpb.MetricDescriptor)
+			close(ch)
+			return ch, nil
+		},
+		QueryTimeSeriesF: func(ctx context.Context, req *monitoringpb.QueryTimeSeriesRequest) (<-chan *queryResult, error) {
+			ch := make(chan *queryResult, 1)
+			ch <- &queryResult{descriptor: descriptor, data: data}
+			close(ch)
+			return ch, nil
+		},
+		CloseF: func() error {
+			return nil
+		},
+	}
+
+	s := &Stackdriver{
+		Log:                testutil.Logger{},
+		Project:            "test",
+		RateLimit:          10,
+		DistributionFormat: "histogram",
+		Queries: []*Query{
+			{
+				Measurement: "gce_instance",
+				MQL:         "fetch gce_instance | metric 'compute.googleapis.com/instance/cpu/utilization' | every 1m",
+			},
+		},
+		client: client,
+	}
+	require.NoError(t, s.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	tags := map[string]string{
+		"project_id":    "test",
+		"instance_id":   "1234",
+		"instance_name": "vm-1",
+	}
+	expected := []telegraf.Metric{
+		metric.New("gce_instance", tags, map[string]interface{}{"utilization": 0.25}, now),
+		metric.New("gce_instance", tags, map[string]interface{}{"latency_count": int64(3), "latency_sum": 6.0}, now, telegraf.Histogram),
+		metric.New("gce_instance",
+			map[string]string{"project_id": "test", "instance_id": "1234", "instance_name": "vm-1", "le": "1.5"},
+			map[string]interface{}{"latency_bucket": int64(1)},
+			now,
+			telegraf.Histogram,
+		),
+		metric.New("gce_instance",
+			map[string]string{"project_id": "test", "instance_id": "1234", "instance_name": "vm-1", "le": "+Inf"},
+			map[string]interface{}{"latency_bucket": int64(3)},
+			now,
+			telegraf.Histogram,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	require.Len(t, client.calls, 2)
+	req, ok := client.calls[1].args[1].(*monitoringpb.QueryTimeSeriesRequest)
+	require.True(t, ok)
+	require.Equal(t, "projects/test", req.Name)
+	require.Equal(t, s.Queries[0].MQL, req.Query)
+}
+
+func TestQuotaThrottle(t *testing.T) {
+	throttle := &quotaThrottle{maxBackoff: 5 * time.Second}
+
+	require.Equal(t, 1*time.Second, throttle.exceeded())
+	require.Equal(t, 2*time.Second, throttle.exceeded())
+	require.Equal(t, 4*time.Second, throttle.exceeded())
+	require.Equal(t, 5*time.Second, throttle.exceeded())
+	require.Equal(t, 5*time.Second, throttle.exceeded())
+
+	// Requests wait for the backoff of the project
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, throttle.wait(ctx), context.Canceled)
+
+	throttle.succeeded()
+	throttle.until = time.Time{}
+	require.Equal(t, 1*time.Second, throttle.exceeded())
+	throttle.until = time.Time{}
+	require.NoError(t, throttle.wait(context.Background()))
+}
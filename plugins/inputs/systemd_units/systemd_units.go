@@ -1,17 +1,21 @@
 //go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
 package systemd_units
 
 import (
-	"bytes"
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
-	"os/exec"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/coreos/go-systemd/v22/dbus"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
-	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -23,8 +27,6 @@ const measurement = "systemd_units"
 // Below are mappings of systemd state tables as defined in
 // https://github.com/systemd/systemd/blob/c87700a1335f489be31cd3549927da68b5638819/src/basic/unit-def.c
 // Duplicate strings are removed from this list.
-// This map is used by `subcommand_show` and `subcommand_list`. Changes must be
-// compatible with both subcommands.
 var loadMap = map[string]int{
 	"loaded":      0,
 	"stub":        1,
@@ -119,29 +121,59 @@ var subMap = map[string]int{
 	"elapsed": 0x00a0,
 }
 
-// SystemdUnits is a telegraf plugin to gather systemd unit status
-type SystemdUnits struct {
-	Timeout       config.Duration `toml:"timeout"`
-	SubCommand    string          `toml:"subcommand"`
-	UnitType      string          `toml:"unittype"`
-	Pattern       string          `toml:"pattern"`
-	resultParser  parseResultFunc
-	commandParams *[]string
+// The following maps configure the mapping of systemd unit properties to tags
+// and fields when details are requested. Properties of the unit itself are
+// mapped to tags, while the unit-type specific properties, e.g. of the
+// "org.freedesktop.systemd1.Service" interface, are mapped to fields.
+var detailTags = map[string]string{
+	"UnitFileState":  "uf_state",
+	"UnitFilePreset": "uf_preset",
 }
 
-type getParametersFunc func(*SystemdUnits) *[]string
-type parseResultFunc func(telegraf.Accumulator, *bytes.Buffer)
+var detailFields = map[string]string{
+	"StatusErrno":       "status_errno",
+	"NRestarts":         "restarts",
+	"MainPID":           "pid",
+	"MemoryCurrent":     "mem_current",
+	"MemoryPeak":        "mem_peak",
+	"MemorySwapCurrent": "swap_current",
+	"MemorySwapPeak":    "swap_peak",
+	"MemoryAvailable":   "mem_avail",
+	"CPUUsageNSec":      "cpu_usage_nsec",
+	"IOReadBytes":       "io_read_bytes",
+	"IOWriteBytes":      "io_write_bytes",
+	"IOReadOperations":  "io_read_ops",
+	"IOWriteOperations": "io_write_ops",
+	"TasksCurrent":      "tasks_current",
+}
 
-type subCommandInfo struct {
-	getParameters getParametersFunc
-	parseResult   parseResultFunc
+// client is the subset of the systemd D-Bus connection used by the plugin
+type client interface {
+	Connected() bool
+	Close()
+	ListUnitsByPatternsContext(ctx context.Context, states, patterns []string) ([]dbus.UnitStatus, error)
+	GetUnitPropertiesContext(ctx context.Context, unit string) (map[string]interface{}, error)
+	GetUnitTypePropertiesContext(ctx context.Context, unit, unitType string) (map[string]interface{}, error)
+}
+
+// SystemdUnits is a telegraf plugin to gather systemd unit status
+type SystemdUnits struct {
+	Timeout    config.Duration `toml:"timeout"`
+	SubCommand string          `toml:"subcommand" deprecated:"1.30.0;use 'details' instead"`
+	UnitType   string          `toml:"unittype"`
+	Pattern    string          `toml:"pattern"`
+	Details    bool            `toml:"details"`
+	Log        telegraf.Logger `toml:"-"`
+
+	client   client
+	connect  func(ctx context.Context) (client, error)
+	patterns []string
 }
 
 var (
-	defaultSubCommand = "list-units"
-	defaultTimeout    = config.Duration(time.Second)
-	defaultUnitType   = "service"
-	defaultPattern    = ""
+	defaultTimeout  = config.Duration(time.Second)
+	defaultUnitType = "service"
+	defaultPattern  = ""
 )
 
 func (*SystemdUnits) SampleConfig() string {
@@ -149,52 +181,194 @@ func (*SystemdUnits) SampleConfig() string {
 }
 
 func (s *SystemdUnits) Init() error {
-	var subCommandInfo *subCommandInfo
-
 	switch s.SubCommand {
+	case "", "list-units":
 	case "show":
-		subCommandInfo = initSubcommandShow()
-	case "list-units":
-		subCommandInfo = initSubcommandListUnits()
+		s.Details = true
 	default:
 		return fmt.Errorf("invalid value for 'subcommand': %s", s.SubCommand)
 	}
 
-	// Save the parsing function for later and pre-compute the
-	// command line because it will not change.
-	s.resultParser = subCommandInfo.parseResult
-	s.commandParams = subCommandInfo.getParameters(s)
+	if s.UnitType == "" {
+		return errors.New("'unittype' must not be empty")
+	}
+
+	// Select units by the given patterns and by type. Without a pattern all
+	// units of the type are selected.
+	s.patterns = strings.Fields(s.Pattern)
+	if len(s.patterns) == 0 {
+		s.patterns = []string{"*." + s.UnitType}
+	}
+
+	if s.connect == nil {
+		s.connect = func(ctx context.Context) (client, error) {
+			return dbus.NewSystemConnectionContext(ctx)
+		}
+	}
+
+	return nil
+}
 
+func (s *SystemdUnits) Start(telegraf.Accumulator) error {
+	// Failing to connect is not fatal as systemd might not be available yet,
+	// the connection is retried on every gather cycle.
+	if err := s.reconnect(); err != nil {
+		s.Log.Warnf("Connecting to systemd failed: %v", err)
+	}
 	return nil
 }
 
-func (s *SystemdUnits) Gather(acc telegraf.Accumulator) error {
-	// is systemctl available ?
-	systemctlPath, err := exec.LookPath("systemctl")
+func (s *SystemdUnits) Stop() {
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+}
+
+func (s *SystemdUnits) reconnect() error {
+	if s.client != nil {
+		if s.client.Connected() {
+			return nil
+		}
+		s.client.Close()
+		s.client = nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+
+	c, err := s.connect(ctx)
 	if err != nil {
 		return err
 	}
+	s.client = c
+	return nil
+}
+
+func (s *SystemdUnits) Gather(acc telegraf.Accumulator) error {
+	if err := s.reconnect(); err != nil {
+		return fmt.Errorf("connecting to systemd failed: %w", err)
+	}
 
-	cmd := exec.Command(systemctlPath, *s.commandParams...)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err = internal.RunTimeout(cmd, time.Duration(s.Timeout))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	units, err := s.client.ListUnitsByPatternsContext(ctx, nil, s.patterns)
+	cancel()
 	if err != nil {
-		return fmt.Errorf("error running systemctl %q: %w", strings.Join(*s.commandParams, " "), err)
+		return fmt.Errorf("listing units failed: %w", err)
 	}
 
-	s.resultParser(acc, &out)
+	suffix := "." + s.UnitType
+	for _, unit := range units {
+		if !strings.HasSuffix(unit.Name, suffix) {
+			continue
+		}
+
+		tags := map[string]string{
+			"name":   unit.Name,
+			"load":   unit.LoadState,
+			"active": unit.ActiveState,
+			"sub":    unit.SubState,
+		}
+
+		var (
+			loadCode   int
+			activeCode int
+			subCode    int
+			ok         bool
+		)
+		if loadCode, ok = loadMap[unit.LoadState]; !ok {
+			acc.AddError(fmt.Errorf("parsing field 'load' failed, value not in map: %s", unit.LoadState))
+			continue
+		}
+		if activeCode, ok = activeMap[unit.ActiveState]; !ok {
+			acc.AddError(fmt.Errorf("parsing field 'active' failed, value not in map: %s", unit.ActiveState))
+			continue
+		}
+		if subCode, ok = subMap[unit.SubState]; !ok {
+			acc.AddError(fmt.Errorf("parsing field 'sub' failed, value not in map: %s", unit.SubState))
+			continue
+		}
+		fields := map[string]interface{}{
+			"load_code":   loadCode,
+			"active_code": activeCode,
+			"sub_code":    subCode,
+		}
+
+		if s.Details {
+			if err := s.gatherDetails(unit.Name, tags, fields); err != nil {
+				acc.AddError(fmt.Errorf("querying properties of %q failed: %w", unit.Name, err))
+				continue
+			}
+		}
+
+		acc.AddFields(measurement, fields, tags)
+	}
 
 	return nil
 }
 
+// gatherDetails adds the unit file state and the resource accounting of the
+// given unit to the tags and fields
+func (s *SystemdUnits) gatherDetails(name string, tags map[string]string, fields map[string]interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+
+	props, err := s.client.GetUnitPropertiesContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	for property, tag := range detailTags {
+		if v, ok := props[property].(string); ok && v != "" {
+			tags[tag] = v
+		}
+	}
+
+	// The unit-type specific interface is named after the unit type, e.g.
+	// "Service" for "telegraf.service"
+	unitType := name[strings.LastIndexByte(name, '.')+1:]
+	unitType = strings.ToUpper(unitType[:1]) + unitType[1:]
+	props, err = s.client.GetUnitTypePropertiesContext(ctx, name, unitType)
+	if err != nil {
+		return err
+	}
+	for property, field := range detailFields {
+		if v, ok := propertyValue(props[property]); ok {
+			fields[field] = v
+		}
+	}
+
+	return nil
+}
+
+// propertyValue converts the given numeric property to an integer. Unset
+// values are reported by systemd as the maximum value of the type and are
+// skipped.
+func propertyValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case uint64:
+		if v == math.MaxUint64 || v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case uint32:
+		if v == math.MaxUint32 {
+			return 0, false
+		}
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
+
 func init() {
 	inputs.Add("systemd_units", func() telegraf.Input {
 		return &SystemdUnits{
-			Timeout:    defaultTimeout,
-			UnitType:   defaultUnitType,
-			Pattern:    defaultPattern,
-			SubCommand: defaultSubCommand,
+			Timeout:  defaultTimeout,
+			UnitType: defaultUnitType,
+			Pattern:  defaultPattern,
 		}
 	})
 }
@@ -1,84 +1,267 @@
+//go:build linux
+
 package systemd_units
 
 import (
-	"bytes"
-	"reflect"
-	"sort"
-	"strings"
+	"context"
+	"errors"
+	"math"
 	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
-// Global test definitions and structure.
-// Tests are located within `subcommand_list_test.go` and
-// `subcommand_show_test.go`.
-
-type TestDef struct {
-	Name   string
-	Line   string
-	Lines  []string
-	Tags   map[string]string
-	Fields map[string]interface{}
-	Status int
-	Err    error
+type fakeClient struct {
+	units     []dbus.UnitStatus
+	props     map[string]map[string]interface{}
+	typeProps map[string]map[string]interface{}
+	patterns  []string
+	connected bool
 }
 
-func runParserTests(t *testing.T, tests []TestDef, dut *subCommandInfo) {
-	for _, tt := range tests {
-		t.Run(tt.Name, func(t *testing.T) {
-			acc := new(testutil.Accumulator)
-
-			var line string
-			if len(tt.Lines) > 0 && len(tt.Line) == 0 {
-				line = strings.Join(tt.Lines, "\n")
-			} else if len(tt.Lines) == 0 && len(tt.Line) > 0 {
-				line = tt.Line
-			} else {
-				t.Error("property Line and Lines set in test definition")
-			}
-
-			dut.parseResult(acc, bytes.NewBufferString(line))
-			err := acc.FirstError()
-
-			if !reflect.DeepEqual(tt.Err, err) {
-				t.Errorf("%s: expected error '%#v' got '%#v'", tt.Name, tt.Err, err)
-			}
-			if len(acc.Metrics) > 0 {
-				m := acc.Metrics[0]
-				if !reflect.DeepEqual(m.Measurement, measurement) {
-					t.Errorf("%s: expected measurement '%#v' got '%#v'\n", tt.Name, measurement, m.Measurement)
-				}
-				if !reflect.DeepEqual(m.Tags, tt.Tags) {
-					t.Errorf("%s: expected tags\n%#v got\n%#v\n", tt.Name, tt.Tags, m.Tags)
-				}
-				if !reflect.DeepEqual(m.Fields, tt.Fields) {
-					t.Errorf("%s: expected fields\n%#v got\n%#v\n", tt.Name, tt.Fields, m.Fields)
-				}
-			}
-		})
+func (c *fakeClient) Connected() bool {
+	return c.connected
+}
+
+func (c *fakeClient) Close() {
+	c.connected = false
+}
+
+func (c *fakeClient) ListUnitsByPatternsContext(_ context.Context, _, patterns []string) ([]dbus.UnitStatus, error) {
+	c.patterns = patterns
+	return c.units, nil
+}
+
+func (c *fakeClient) GetUnitPropertiesContext(_ context.Context, unit string) (map[string]interface{}, error) {
+	props, found := c.props[unit]
+	if !found {
+		return nil, errors.New("unknown unit")
 	}
+	return props, nil
 }
 
-func runCommandLineTest(t *testing.T, paramsTemplate []string, dut *subCommandInfo, systemdUnits *SystemdUnits) {
-	params := *dut.getParameters(systemdUnits)
+func (c *fakeClient) GetUnitTypePropertiesContext(_ context.Context, unit, unitType string) (map[string]interface{}, error) {
+	props, found := c.typeProps[unit+"/"+unitType]
+	if !found {
+		return nil, errors.New("unknown unit")
+	}
+	return props, nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		connected: true,
+		units: []dbus.UnitStatus{
+			{Name: "example.service", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+			{Name: "failed.service", LoadState: "loaded", ActiveState: "failed", SubState: "failed"},
+			{Name: "example.socket", LoadState: "loaded", ActiveState: "active", SubState: "listening"},
+		},
+		props: map[string]map[string]interface{}{
+			"example.service": {"UnitFileState": "enabled", "UnitFilePreset": "disabled"},
+			"failed.service":  {"UnitFileState": "", "UnitFilePreset": ""},
+		},
+		typeProps: map[string]map[string]interface{}{
+			"example.service/Service": {
+				"StatusErrno":       int32(0),
+				"NRestarts":         uint32(1),
+				"MainPID":           uint32(9999),
+				"MemoryCurrent":     uint64(1000),
+				"MemoryPeak":        uint64(2000),
+				"MemorySwapCurrent": uint64(3000),
+				"MemorySwapPeak":    uint64(4000),
+				"MemoryAvailable":   uint64(5000),
+				"CPUUsageNSec":      uint64(123456789),
+				"IOReadBytes":       uint64(4096),
+				"IOWriteBytes":      uint64(8192),
+				"IOReadOperations":  uint64(math.MaxUint64),
+				"IOWriteOperations": uint64(math.MaxUint64),
+				"TasksCurrent":      uint64(5),
+				"Type":              "simple",
+			},
+			"failed.service/Service": {
+				"StatusErrno":   int32(2),
+				"NRestarts":     uint32(5),
+				"MainPID":       uint32(0),
+				"MemoryCurrent": uint64(math.MaxUint64),
+				"CPUUsageNSec":  uint64(math.MaxUint64),
+			},
+		},
+	}
+}
 
-	// Because we sort the params and the template array before comparison
-	// we have to compare the positional parameters first.
-	for i, v := range paramsTemplate {
-		if strings.HasPrefix(v, "--") {
-			break
-		}
+func TestGather(t *testing.T) {
+	c := newFakeClient()
+	plugin := &SystemdUnits{
+		Timeout:  config.Duration(time.Second),
+		UnitType: "service",
+		connect:  func(context.Context) (client, error) { return c, nil },
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, []string{"*.service"}, c.patterns)
 
-		if v != params[i] {
-			t.Errorf("Positional parameter %d is '%s'. Expected '%s'.", i, params[i], v)
-		}
+	expected := []telegraf.Metric{
+		metric.New(
+			"systemd_units",
+			map[string]string{
+				"name":   "example.service",
+				"load":   "loaded",
+				"active": "active",
+				"sub":    "running",
+			},
+			map[string]interface{}{
+				"load_code":   0,
+				"active_code": 0,
+				"sub_code":    0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"systemd_units",
+			map[string]string{
+				"name":   "failed.service",
+				"load":   "loaded",
+				"active": "failed",
+				"sub":    "failed",
+			},
+			map[string]interface{}{
+				"load_code":   0,
+				"active_code": 3,
+				"sub_code":    12,
+			},
+			time.Unix(0, 0),
+		),
 	}
-	// Because the maps do not lead to a stable order of the "--property"
-	// arguments sort all the command line arguments and compare them.
-	sort.Strings(params)
-	sort.Strings(paramsTemplate)
-	if !reflect.DeepEqual(params, paramsTemplate) {
-		t.Errorf("Generated list of command line arguments '%#v' do not match expected list command line arguments '%#v'", params, paramsTemplate)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherDetails(t *testing.T) {
+	c := newFakeClient()
+	plugin := &SystemdUnits{
+		Timeout:  config.Duration(time.Second),
+		UnitType: "service",
+		Pattern:  "example* failed*",
+		Details:  true,
+		connect:  func(context.Context) (client, error) { return c, nil },
 	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, []string{"example*", "failed*"}, c.patterns)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"systemd_units",
+			map[string]string{
+				"name":      "example.service",
+				"load":      "loaded",
+				"active":    "active",
+				"sub":       "running",
+				"uf_state":  "enabled",
+				"uf_preset": "disabled",
+			},
+			map[string]interface{}{
+				"load_code":      0,
+				"active_code":    0,
+				"sub_code":       0,
+				"status_errno":   int64(0),
+				"restarts":       int64(1),
+				"pid":            int64(9999),
+				"mem_current":    int64(1000),
+				"mem_peak":       int64(2000),
+				"swap_current":   int64(3000),
+				"swap_peak":      int64(4000),
+				"mem_avail":      int64(5000),
+				"cpu_usage_nsec": int64(123456789),
+				"io_read_bytes":  int64(4096),
+				"io_write_bytes": int64(8192),
+				"tasks_current":  int64(5),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"systemd_units",
+			map[string]string{
+				"name":   "failed.service",
+				"load":   "loaded",
+				"active": "failed",
+				"sub":    "failed",
+			},
+			map[string]interface{}{
+				"load_code":    0,
+				"active_code":  3,
+				"sub_code":     12,
+				"status_errno": int64(2),
+				"restarts":     int64(5),
+				"pid":          int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUnknownState(t *testing.T) {
+	c := newFakeClient()
+	c.units = append(c.units, dbus.UnitStatus{Name: "new.service", LoadState: "loaded", ActiveState: "active", SubState: "unknown"})
+	plugin := &SystemdUnits{
+		Timeout:  config.Duration(time.Second),
+		UnitType: "service",
+		connect:  func(context.Context) (client, error) { return c, nil },
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.EqualError(t, acc.FirstError(), "parsing field 'sub' failed, value not in map: unknown")
+}
+
+func TestReconnect(t *testing.T) {
+	var connects int
+	plugin := &SystemdUnits{
+		Timeout:  config.Duration(time.Second),
+		UnitType: "service",
+		connect: func(context.Context) (client, error) {
+			connects++
+			return newFakeClient(), nil
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, 1, connects)
+
+	// Simulate a lost connection, e.g. due to a restart of D-Bus
+	plugin.client.Close()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Equal(t, 2, connects)
+}
+
+func TestSubcommandCompatibility(t *testing.T) {
+	plugin := &SystemdUnits{UnitType: "service", SubCommand: "show"}
+	require.NoError(t, plugin.Init())
+	require.True(t, plugin.Details)
+
+	plugin = &SystemdUnits{UnitType: "service", SubCommand: "list-units"}
+	require.NoError(t, plugin.Init())
+	require.False(t, plugin.Details)
+
+	plugin = &SystemdUnits{UnitType: "service", SubCommand: "status"}
+	require.EqualError(t, plugin.Init(), "invalid value for 'subcommand': status")
 }
@@ -0,0 +1,165 @@
+package time_sync
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// gpsdPoll is the reply of gpsd to the "?POLL;" command containing the most
+// recent position and sky view of all active devices
+type gpsdPoll struct {
+	Time time.Time `json:"time"`
+	TPV  []gpsdTPV `json:"tpv"`
+	Sky  []gpsdSky `json:"sky"`
+}
+
+type gpsdClass struct {
+	Class   string `json:"class"`
+	Message string `json:"message"`
+}
+
+type gpsdTPV struct {
+	Device      string    `json:"device"`
+	Mode        int       `json:"mode"`
+	Status      *int      `json:"status"`
+	Time        time.Time `json:"time"`
+	Leapseconds *int      `json:"leapseconds"`
+	Ept         *float64  `json:"ept"`
+	Epx         *float64  `json:"epx"`
+	Epy         *float64  `json:"epy"`
+	Epv         *float64  `json:"epv"`
+}
+
+type gpsdSky struct {
+	Device     string          `json:"device"`
+	Hdop       *float64        `json:"hdop"`
+	Vdop       *float64        `json:"vdop"`
+	Pdop       *float64        `json:"pdop"`
+	Tdop       *float64        `json:"tdop"`
+	Gdop       *float64        `json:"gdop"`
+	NSat       *int            `json:"nSat"`
+	USat       *int            `json:"uSat"`
+	Satellites []gpsdSatellite `json:"satellites"`
+}
+
+type gpsdSatellite struct {
+	Used bool `json:"used"`
+}
+
+// Mapping of the TPV fix mode to a human readable name
+var gpsdModes = []string{"unknown", "no_fix", "2d", "3d"}
+
+func (t *TimeSync) gatherGPSD(acc telegraf.Accumulator, server, address string) error {
+	conn, err := net.DialTimeout("tcp", address, time.Duration(t.Timeout))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(t.Timeout))); err != nil {
+		return err
+	}
+
+	// Polling requires the client to be in watcher mode
+	if _, err := conn.Write([]byte("?WATCH={\"enable\":true};?POLL;\n")); err != nil {
+		return err
+	}
+	poll, err := readGPSDPoll(conn)
+	if err != nil {
+		return err
+	}
+
+	sky := make(map[string]gpsdSky, len(poll.Sky))
+	for _, s := range poll.Sky {
+		sky[s.Device] = s
+	}
+
+	for _, tpv := range poll.TPV {
+		tags := map[string]string{
+			"server": server,
+			"device": tpv.Device,
+		}
+		if tpv.Mode >= 0 && tpv.Mode < len(gpsdModes) {
+			tags["mode"] = gpsdModes[tpv.Mode]
+		}
+		fields := map[string]interface{}{
+			"mode": tpv.Mode,
+		}
+		if tpv.Status != nil {
+			fields["status"] = *tpv.Status
+		}
+		if !tpv.Time.IsZero() && !poll.Time.IsZero() {
+			fields["fix_age"] = poll.Time.Sub(tpv.Time).Seconds()
+		}
+		addOptional(fields, "leap_seconds", tpv.Leapseconds)
+		addOptional(fields, "time_error", tpv.Ept)
+		addOptional(fields, "error_x", tpv.Epx)
+		addOptional(fields, "error_y", tpv.Epy)
+		addOptional(fields, "error_vertical", tpv.Epv)
+
+		if s, found := sky[tpv.Device]; found {
+			addOptional(fields, "hdop", s.Hdop)
+			addOptional(fields, "vdop", s.Vdop)
+			addOptional(fields, "pdop", s.Pdop)
+			addOptional(fields, "tdop", s.Tdop)
+			addOptional(fields, "gdop", s.Gdop)
+
+			// Older gpsd versions only report the list of satellites
+			visible, used := len(s.Satellites), 0
+			for _, sat := range s.Satellites {
+				if sat.Used {
+					used++
+				}
+			}
+			if s.NSat != nil {
+				visible = *s.NSat
+			}
+			if s.USat != nil {
+				used = *s.USat
+			}
+			fields["satellites_visible"] = visible
+			fields["satellites_used"] = used
+		}
+		acc.AddFields("gpsd", fields, tags)
+	}
+	return nil
+}
+
+// readGPSDPoll reads the reports sent by gpsd until the reply to the poll
+// request is received
+func readGPSDPoll(conn net.Conn) (*gpsdPoll, error) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var class gpsdClass
+		if err := json.Unmarshal(scanner.Bytes(), &class); err != nil {
+			return nil, fmt.Errorf("decoding report failed: %w", err)
+		}
+		switch class.Class {
+		case "ERROR":
+			return nil, fmt.Errorf("gpsd returned error: %s", class.Message)
+		case "POLL":
+			var poll gpsdPoll
+			if err := json.Unmarshal(scanner.Bytes(), &poll); err != nil {
+				return nil, fmt.Errorf("decoding poll reply failed: %w", err)
+			}
+			return &poll, nil
+		}
+		// Skip other reports like VERSION, DEVICES or WATCH
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("connection closed before receiving poll reply")
+}
+
+func addOptional[T int | float64](fields map[string]interface{}, name string, v *T) {
+	if v != nil {
+		fields[name] = *v
+	}
+}
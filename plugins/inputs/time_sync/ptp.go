@@ -0,0 +1,379 @@
+package time_sync
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// PTP management message constants, see IEEE 1588-2008 section 15 and the
+// linuxptp implementation-specific extensions
+const (
+	ptpHeaderLength     = 34
+	ptpRequestLength    = ptpHeaderLength + 20 // management header and empty TLV
+	ptpMessageMgmt      = 0x0d
+	ptpVersion          = 0x02
+	ptpControlMgmt      = 0x04
+	ptpActionGet        = 0
+	ptpActionResponse   = 2
+	ptpTLVMgmt          = 0x0001
+	ptpTLVMgmtError     = 0x0002
+	ptpIDDefaultDataSet = 0x2000
+	ptpIDCurrentDataSet = 0x2001
+	ptpIDPortDataSet    = 0x2004
+	ptpIDTimeStatusNP   = 0xc000
+)
+
+var ptpPortStates = map[uint8]string{
+	1: "initializing",
+	2: "faulty",
+	3: "disabled",
+	4: "listening",
+	5: "pre_master",
+	6: "master",
+	7: "passive",
+	8: "uncalibrated",
+	9: "slave",
+}
+
+var ptpDelayMechanisms = map[uint8]string{
+	0x01: "e2e",
+	0x02: "p2p",
+	0xfe: "disabled",
+}
+
+type ptpDefaultDataSet struct {
+	twoStep                 bool
+	slaveOnly               bool
+	numberPorts             uint16
+	priority1               uint8
+	clockClass              uint8
+	clockAccuracy           uint8
+	offsetScaledLogVariance uint16
+	priority2               uint8
+	clockIdentity           string
+	domain                  uint8
+}
+
+type ptpCurrentDataSet struct {
+	stepsRemoved     uint16
+	offsetFromMaster float64
+	meanPathDelay    float64
+}
+
+type ptpPortDataSet struct {
+	portIdentity       string
+	portState          uint8
+	logMinDelayReqInt  int8
+	peerMeanPathDelay  float64
+	logAnnounceInt     int8
+	logSyncInt         int8
+	delayMechanism     uint8
+	logMinPdelayReqInt int8
+}
+
+type ptpTimeStatus struct {
+	masterOffset  int64
+	ingressTime   int64
+	rateOffsetPPB float64
+	gmPresent     bool
+	gmIdentity    string
+}
+
+// ptpClient sends management requests to ptp4l like pmc does
+type ptpClient struct {
+	conn   net.Conn
+	domain uint8
+	port   uint16
+	seq    uint16
+}
+
+// request sends a GET request for the given management ID and waits for the
+// expected number of responses, e.g. one for each port of the clock
+func (c *ptpClient) request(id uint16, responses int) ([][]byte, error) {
+	c.seq++
+
+	req := make([]byte, ptpRequestLength)
+	req[0] = ptpMessageMgmt
+	req[1] = ptpVersion
+	binary.BigEndian.PutUint16(req[2:4], ptpRequestLength)
+	req[4] = c.domain
+	binary.BigEndian.PutUint16(req[28:30], c.port)
+	binary.BigEndian.PutUint16(req[30:32], c.seq)
+	req[32] = ptpControlMgmt
+	req[33] = 0x7f
+	// Address all ports of the clock
+	for i := 34; i < 44; i++ {
+		req[i] = 0xff
+	}
+	req[46] = ptpActionGet
+	binary.BigEndian.PutUint16(req[48:50], ptpTLVMgmt)
+	binary.BigEndian.PutUint16(req[50:52], 2)
+	binary.BigEndian.PutUint16(req[52:54], id)
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	result := make([][]byte, 0, responses)
+	buf := make([]byte, 1500)
+	for len(result) < responses {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		data, err := c.parseResponse(buf[:n], id)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			// The buffer is reused for the next response
+			result = append(result, append([]byte(nil), data...))
+		}
+	}
+	return result, nil
+}
+
+// parseResponse returns the TLV data of the response or nil if the message
+// is not a response to the current request
+func (c *ptpClient) parseResponse(msg []byte, id uint16) ([]byte, error) {
+	if len(msg) < ptpRequestLength || msg[0]&0x0f != ptpMessageMgmt || msg[46]&0x0f != ptpActionResponse {
+		return nil, nil
+	}
+	if binary.BigEndian.Uint16(msg[30:32]) != c.seq {
+		// Ignore stale responses
+		return nil, nil
+	}
+
+	tlvType := binary.BigEndian.Uint16(msg[48:50])
+	tlvLength := int(binary.BigEndian.Uint16(msg[50:52]))
+	if tlvLength < 2 || 52+tlvLength > len(msg) {
+		return nil, fmt.Errorf("invalid TLV length %d", tlvLength)
+	}
+	switch tlvType {
+	case ptpTLVMgmt:
+		if rid := binary.BigEndian.Uint16(msg[52:54]); rid != id {
+			return nil, fmt.Errorf("unexpected management ID 0x%04x", rid)
+		}
+		return msg[54 : 52+tlvLength], nil
+	case ptpTLVMgmtError:
+		return nil, fmt.Errorf("management error 0x%04x", binary.BigEndian.Uint16(msg[52:54]))
+	}
+	return nil, fmt.Errorf("unexpected TLV type 0x%04x", tlvType)
+}
+
+func (c *ptpClient) defaultDataSet() (*ptpDefaultDataSet, error) {
+	responses, err := c.request(ptpIDDefaultDataSet, 1)
+	if err != nil {
+		return nil, err
+	}
+	data := responses[0]
+	if len(data) < 20 {
+		return nil, fmt.Errorf("invalid default data set length %d", len(data))
+	}
+	return &ptpDefaultDataSet{
+		twoStep:                 data[0]&0x01 != 0,
+		slaveOnly:               data[0]&0x02 != 0,
+		numberPorts:             binary.BigEndian.Uint16(data[2:4]),
+		priority1:               data[4],
+		clockClass:              data[5],
+		clockAccuracy:           data[6],
+		offsetScaledLogVariance: binary.BigEndian.Uint16(data[7:9]),
+		priority2:               data[9],
+		clockIdentity:           clockIdentity(data[10:18]),
+		domain:                  data[18],
+	}, nil
+}
+
+func (c *ptpClient) currentDataSet() (*ptpCurrentDataSet, error) {
+	responses, err := c.request(ptpIDCurrentDataSet, 1)
+	if err != nil {
+		return nil, err
+	}
+	data := responses[0]
+	if len(data) < 18 {
+		return nil, fmt.Errorf("invalid current data set length %d", len(data))
+	}
+	return &ptpCurrentDataSet{
+		stepsRemoved:     binary.BigEndian.Uint16(data[0:2]),
+		offsetFromMaster: timeInterval(data[2:10]),
+		meanPathDelay:    timeInterval(data[10:18]),
+	}, nil
+}
+
+func (c *ptpClient) portDataSets(ports int) ([]*ptpPortDataSet, error) {
+	responses, err := c.request(ptpIDPortDataSet, ports)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ptpPortDataSet, 0, len(responses))
+	for _, data := range responses {
+		if len(data) < 26 {
+			return nil, fmt.Errorf("invalid port data set length %d", len(data))
+		}
+		result = append(result, &ptpPortDataSet{
+			portIdentity:       fmt.Sprintf("%s-%d", clockIdentity(data[0:8]), binary.BigEndian.Uint16(data[8:10])),
+			portState:          data[10],
+			logMinDelayReqInt:  int8(data[11]),
+			peerMeanPathDelay:  timeInterval(data[12:20]),
+			logAnnounceInt:     int8(data[20]),
+			logSyncInt:         int8(data[22]),
+			delayMechanism:     data[23],
+			logMinPdelayReqInt: int8(data[24]),
+		})
+	}
+	return result, nil
+}
+
+func (c *ptpClient) timeStatus() (*ptpTimeStatus, error) {
+	responses, err := c.request(ptpIDTimeStatusNP, 1)
+	if err != nil {
+		return nil, err
+	}
+	data := responses[0]
+	if len(data) < 50 {
+		return nil, fmt.Errorf("invalid time status length %d", len(data))
+	}
+	// The cumulative rate offset is scaled by 2^41
+	rateOffset := int32(binary.BigEndian.Uint32(data[16:20]))
+	return &ptpTimeStatus{
+		masterOffset:  int64(binary.BigEndian.Uint64(data[0:8])),
+		ingressTime:   int64(binary.BigEndian.Uint64(data[8:16])),
+		rateOffsetPPB: float64(rateOffset) / (1 << 41) * 1e9,
+		gmPresent:     binary.BigEndian.Uint32(data[38:42]) != 0,
+		gmIdentity:    clockIdentity(data[42:50]),
+	}, nil
+}
+
+// timeInterval converts a PTP time interval, i.e. nanoseconds scaled by 2^16
+func timeInterval(data []byte) float64 {
+	return float64(int64(binary.BigEndian.Uint64(data))) / (1 << 16)
+}
+
+// clockIdentity formats the identity in the same way as linuxptp does
+func clockIdentity(data []byte) string {
+	return fmt.Sprintf("%02x%02x%02x.%02x%02x.%02x%02x%02x",
+		data[0], data[1], data[2], data[3], data[4], data[5], data[6], data[7])
+}
+
+func (t *TimeSync) gatherPTP(acc telegraf.Accumulator, idx int, socket string) error {
+	conn, err := dialPTP(socket, idx)
+	if err != nil {
+		return fmt.Errorf("connecting failed: %w", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(t.Timeout))); err != nil {
+		return err
+	}
+	c := &ptpClient{conn: conn, domain: t.PTPDomain, port: uint16(os.Getpid())}
+
+	dds, err := c.defaultDataSet()
+	if err != nil {
+		return fmt.Errorf("querying default data set failed: %w", err)
+	}
+	cds, err := c.currentDataSet()
+	if err != nil {
+		return fmt.Errorf("querying current data set failed: %w", err)
+	}
+
+	tags := map[string]string{
+		"socket":         socket,
+		"clock_identity": dds.clockIdentity,
+	}
+	fields := map[string]interface{}{
+		"domain":                     dds.domain,
+		"number_ports":               dds.numberPorts,
+		"priority1":                  dds.priority1,
+		"priority2":                  dds.priority2,
+		"clock_class":                dds.clockClass,
+		"clock_accuracy":             dds.clockAccuracy,
+		"offset_scaled_log_variance": dds.offsetScaledLogVariance,
+		"two_step":                   dds.twoStep,
+		"slave_only":                 dds.slaveOnly,
+		"steps_removed":              cds.stepsRemoved,
+		"offset_from_master_ns":      cds.offsetFromMaster,
+		"mean_path_delay_ns":         cds.meanPathDelay,
+	}
+
+	// The time status is a linuxptp extension which is only available for
+	// ordinary and boundary clocks
+	if ts, err := c.timeStatus(); err != nil {
+		t.Log.Debugf("Querying time status of %q failed: %v", socket, err)
+	} else {
+		tags["gm_identity"] = ts.gmIdentity
+		fields["master_offset_ns"] = ts.masterOffset
+		fields["ingress_time_ns"] = ts.ingressTime
+		fields["rate_offset_ppb"] = ts.rateOffsetPPB
+		fields["gm_present"] = ts.gmPresent
+	}
+	acc.AddFields("ptp", fields, tags)
+
+	if dds.numberPorts == 0 {
+		return nil
+	}
+	ports, err := c.portDataSets(int(dds.numberPorts))
+	if err != nil {
+		return fmt.Errorf("querying port data sets failed: %w", err)
+	}
+	for _, p := range ports {
+		state, found := ptpPortStates[p.portState]
+		if !found {
+			state = "unknown"
+		}
+		delayMechanism, found := ptpDelayMechanisms[p.delayMechanism]
+		if !found {
+			delayMechanism = "unknown"
+		}
+		tags := map[string]string{
+			"socket":         socket,
+			"clock_identity": dds.clockIdentity,
+			"port_identity":  p.portIdentity,
+			"port_state":     state,
+		}
+		fields := map[string]interface{}{
+			"state":                       p.portState,
+			"peer_mean_path_delay_ns":     p.peerMeanPathDelay,
+			"delay_mechanism":             delayMechanism,
+			"log_sync_interval":           p.logSyncInt,
+			"log_announce_interval":       p.logAnnounceInt,
+			"log_min_delay_req_interval":  p.logMinDelayReqInt,
+			"log_min_pdelay_req_interval": p.logMinPdelayReqInt,
+		}
+		acc.AddFields("ptp_port", fields, tags)
+	}
+	return nil
+}
+
+// dialPTP connects to the management socket of ptp4l. As ptp4l sends the
+// responses to the address of the client, we have to bind to a socket in the
+// same directory like pmc does.
+func dialPTP(socket string, idx int) (net.Conn, error) {
+	local := filepath.Join(filepath.Dir(socket), fmt.Sprintf("pmc-telegraf.%d.%d", os.Getpid(), idx))
+	_ = os.Remove(local)
+	laddr := &net.UnixAddr{Name: local, Net: "unixgram"}
+	raddr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	conn, err := net.DialUnix("unixgram", laddr, raddr)
+	if err != nil {
+		return nil, err
+	}
+	return &unixConn{UnixConn: conn, path: local}, nil
+}
+
+// unixConn removes the socket file of the client when closing the connection
+type unixConn struct {
+	*net.UnixConn
+	path string
+}
+
+func (c *unixConn) Close() error {
+	err := c.UnixConn.Close()
+	if rerr := os.Remove(c.path); rerr != nil && !errors.Is(rerr, os.ErrNotExist) && err == nil {
+		err = rerr
+	}
+	return err
+}
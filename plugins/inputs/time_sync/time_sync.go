@@ -0,0 +1,93 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package time_sync
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TimeSync struct {
+	GPSDServers []string        `toml:"gpsd_servers"`
+	PTPSockets  []string        `toml:"ptp_sockets"`
+	PTPDomain   uint8           `toml:"ptp_domain"`
+	Timeout     config.Duration `toml:"timeout"`
+	Log         telegraf.Logger `toml:"-"`
+
+	gpsdAddresses []string
+}
+
+func (*TimeSync) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TimeSync) Init() error {
+	if len(t.GPSDServers) == 0 && len(t.PTPSockets) == 0 {
+		return errors.New("no gpsd server or ptp socket configured")
+	}
+
+	t.gpsdAddresses = make([]string, 0, len(t.GPSDServers))
+	for _, server := range t.GPSDServers {
+		u, err := url.Parse(server)
+		if err != nil {
+			return fmt.Errorf("parsing gpsd server %q failed: %w", server, err)
+		}
+		if u.Scheme != "tcp" {
+			return fmt.Errorf("invalid scheme %q in gpsd server %q", u.Scheme, server)
+		}
+		address := u.Host
+		if u.Port() == "" {
+			address = net.JoinHostPort(u.Hostname(), "2947")
+		}
+		t.gpsdAddresses = append(t.gpsdAddresses, address)
+	}
+
+	if t.Timeout <= 0 {
+		t.Timeout = config.Duration(5 * time.Second)
+	}
+	return nil
+}
+
+func (t *TimeSync) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for i, address := range t.gpsdAddresses {
+		wg.Add(1)
+		go func(server, address string) {
+			defer wg.Done()
+			if err := t.gatherGPSD(acc, server, address); err != nil {
+				acc.AddError(fmt.Errorf("querying gpsd %q failed: %w", server, err))
+			}
+		}(t.GPSDServers[i], address)
+	}
+	for i, socket := range t.PTPSockets {
+		wg.Add(1)
+		go func(idx int, socket string) {
+			defer wg.Done()
+			if err := t.gatherPTP(acc, idx, socket); err != nil {
+				acc.AddError(fmt.Errorf("querying ptp4l %q failed: %w", socket, err))
+			}
+		}(i, socket)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func init() {
+	inputs.Add("time_sync", func() telegraf.Input {
+		return &TimeSync{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,331 @@
+package time_sync
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TimeSync
+		expected string
+	}{
+		{
+			name:     "nothing configured",
+			plugin:   &TimeSync{},
+			expected: "no gpsd server or ptp socket configured",
+		},
+		{
+			name:     "invalid gpsd scheme",
+			plugin:   &TimeSync{GPSDServers: []string{"udp://localhost:2947"}},
+			expected: "invalid scheme",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitDefaultGPSDPort(t *testing.T) {
+	plugin := &TimeSync{GPSDServers: []string{"tcp://localhost"}}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{"localhost:2947"}, plugin.gpsdAddresses)
+}
+
+// fakeGPSD answers a single client like gpsd with a receiver having a 3D fix
+func fakeGPSD(t *testing.T, listener net.Listener) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Logf("reading request failed: %v", err)
+		return
+	}
+	if !strings.Contains(line, "?WATCH=") || !strings.Contains(line, "?POLL;") {
+		t.Logf("unexpected request %q", line)
+		return
+	}
+
+	replies := []string{
+		`{"class":"VERSION","release":"3.25","rev":"3.25","proto_major":3,"proto_minor":15}`,
+		`{"class":"DEVICES","devices":[{"class":"DEVICE","path":"/dev/ttyACM0","activated":"2024-03-13T10:00:00.000Z"}]}`,
+		`{"class":"WATCH","enable":true,"json":false}`,
+		`{"class":"POLL","time":"2024-03-13T10:00:01.500Z","active":1,` +
+			`"tpv":[{"class":"TPV","device":"/dev/ttyACM0","mode":3,"status":1,"time":"2024-03-13T10:00:01.000Z",` +
+			`"leapseconds":18,"ept":0.005,"lat":48.1,"lon":11.5,"epx":2.5,"epy":3.0,"epv":8.0}],` +
+			`"sky":[{"class":"SKY","device":"/dev/ttyACM0","hdop":0.8,"vdop":1.2,"pdop":1.4,"tdop":0.9,"gdop":1.7,` +
+			`"satellites":[{"PRN":1,"used":true},{"PRN":5,"used":true},{"PRN":12,"used":false}]}]}`,
+	}
+	for _, r := range replies {
+		if _, err := conn.Write([]byte(r + "\r\n")); err != nil {
+			t.Logf("writing reply failed: %v", err)
+			return
+		}
+	}
+}
+
+func TestGatherGPSD(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go fakeGPSD(t, listener)
+
+	server := "tcp://" + listener.Addr().String()
+	plugin := &TimeSync{
+		GPSDServers: []string{server},
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"gpsd",
+			map[string]string{
+				"server": server,
+				"device": "/dev/ttyACM0",
+				"mode":   "3d",
+			},
+			map[string]interface{}{
+				"mode":               3,
+				"status":             1,
+				"fix_age":            0.5,
+				"leap_seconds":       18,
+				"time_error":         0.005,
+				"error_x":            2.5,
+				"error_y":            3.0,
+				"error_vertical":     8.0,
+				"hdop":               0.8,
+				"vdop":               1.2,
+				"pdop":               1.4,
+				"tdop":               0.9,
+				"gdop":               1.7,
+				"satellites_visible": 3,
+				"satellites_used":    2,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherGPSDError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte(`{"class":"ERROR","message":"Unrecognized request"}` + "\r\n"))
+	}()
+
+	plugin := &TimeSync{
+		GPSDServers: []string{"tcp://" + listener.Addr().String()},
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, acc.GatherError(plugin.Gather), "Unrecognized request")
+}
+
+// fakePTP4l answers management requests like ptp4l for a clock with two ports
+type fakePTP4l struct {
+	conn net.PacketConn
+}
+
+var fakeClockID = []byte{0x00, 0x1b, 0x21, 0xff, 0xfe, 0x7a, 0x3c, 0x10}
+
+func (f *fakePTP4l) serve(t *testing.T) {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := f.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := buf[:n]
+		if n < ptpRequestLength || req[0] != ptpMessageMgmt || req[46] != ptpActionGet {
+			t.Logf("invalid request %x", req)
+			continue
+		}
+
+		id := binary.BigEndian.Uint16(req[52:54])
+		var payloads [][]byte
+		switch id {
+		case ptpIDDefaultDataSet:
+			data := make([]byte, 20)
+			data[0] = 0x01
+			binary.BigEndian.PutUint16(data[2:4], 2)
+			data[4] = 128
+			data[5] = 248
+			data[6] = 0xfe
+			binary.BigEndian.PutUint16(data[7:9], 0xffff)
+			data[9] = 127
+			copy(data[10:18], fakeClockID)
+			payloads = append(payloads, data)
+		case ptpIDCurrentDataSet:
+			data := make([]byte, 18)
+			binary.BigEndian.PutUint16(data[0:2], 1)
+			offset := int64(-25 << 15) // -12.5ns
+			binary.BigEndian.PutUint64(data[2:10], uint64(offset))
+			binary.BigEndian.PutUint64(data[10:18], uint64(812<<16|1<<14))
+			payloads = append(payloads, data)
+		case ptpIDPortDataSet:
+			for port, state := range []uint8{9, 6} {
+				data := make([]byte, 26)
+				copy(data[0:8], fakeClockID)
+				binary.BigEndian.PutUint16(data[8:10], uint16(port+1))
+				data[10] = state
+				data[20] = 1
+				data[21] = 3
+				data[23] = 1
+				payloads = append(payloads, data)
+			}
+		case ptpIDTimeStatusNP:
+			data := make([]byte, 50)
+			offset := int64(-12)
+			binary.BigEndian.PutUint64(data[0:8], uint64(offset))
+			binary.BigEndian.PutUint64(data[8:16], 1710324000123456789)
+			binary.BigEndian.PutUint32(data[16:20], 1<<21)
+			binary.BigEndian.PutUint32(data[38:42], 1)
+			copy(data[42:50], []byte{0x00, 0x1b, 0x21, 0xff, 0xfe, 0xca, 0xfe, 0x01})
+			payloads = append(payloads, data)
+		}
+
+		for _, data := range payloads {
+			rsp := make([]byte, 54+len(data))
+			copy(rsp[:48], req[:48])
+			rsp[46] = ptpActionResponse
+			binary.BigEndian.PutUint16(rsp[2:4], uint16(len(rsp)))
+			binary.BigEndian.PutUint16(rsp[48:50], ptpTLVMgmt)
+			binary.BigEndian.PutUint16(rsp[50:52], uint16(2+len(data)))
+			binary.BigEndian.PutUint16(rsp[52:54], id)
+			copy(rsp[54:], data)
+			if _, err := f.conn.WriteTo(rsp, addr); err != nil {
+				t.Logf("writing response failed: %v", err)
+			}
+		}
+	}
+}
+
+func TestGatherPTP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on unsupported platform")
+	}
+
+	socket := filepath.Join(t.TempDir(), "ptp4l")
+	conn, err := net.ListenPacket("unixgram", socket)
+	require.NoError(t, err)
+	defer conn.Close()
+	server := &fakePTP4l{conn: conn}
+	go server.serve(t)
+
+	plugin := &TimeSync{
+		PTPSockets: []string{socket},
+		Timeout:    config.Duration(5 * time.Second),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"ptp",
+			map[string]string{
+				"socket":         socket,
+				"clock_identity": "001b21.fffe.7a3c10",
+				"gm_identity":    "001b21.fffe.cafe01",
+			},
+			map[string]interface{}{
+				"domain":                     uint8(0),
+				"number_ports":               uint16(2),
+				"priority1":                  uint8(128),
+				"priority2":                  uint8(127),
+				"clock_class":                uint8(248),
+				"clock_accuracy":             uint8(0xfe),
+				"offset_scaled_log_variance": uint16(0xffff),
+				"two_step":                   true,
+				"slave_only":                 false,
+				"steps_removed":              uint16(1),
+				"offset_from_master_ns":      -12.5,
+				"mean_path_delay_ns":         812.25,
+				"master_offset_ns":           int64(-12),
+				"ingress_time_ns":            int64(1710324000123456789),
+				"rate_offset_ppb":            float64(1<<21) / (1 << 41) * 1e9,
+				"gm_present":                 true,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ptp_port",
+			map[string]string{
+				"socket":         socket,
+				"clock_identity": "001b21.fffe.7a3c10",
+				"port_identity":  "001b21.fffe.7a3c10-1",
+				"port_state":     "slave",
+			},
+			map[string]interface{}{
+				"state":                       uint8(9),
+				"peer_mean_path_delay_ns":     float64(0),
+				"delay_mechanism":             "e2e",
+				"log_sync_interval":           int8(0),
+				"log_announce_interval":       int8(1),
+				"log_min_delay_req_interval":  int8(0),
+				"log_min_pdelay_req_interval": int8(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"ptp_port",
+			map[string]string{
+				"socket":         socket,
+				"clock_identity": "001b21.fffe.7a3c10",
+				"port_identity":  "001b21.fffe.7a3c10-2",
+				"port_state":     "master",
+			},
+			map[string]interface{}{
+				"state":                       uint8(6),
+				"peer_mean_path_delay_ns":     float64(0),
+				"delay_mechanism":             "e2e",
+				"log_sync_interval":           int8(0),
+				"log_announce_interval":       int8(1),
+				"log_min_delay_req_interval":  int8(0),
+				"log_min_pdelay_req_interval": int8(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestClockIdentity(t *testing.T) {
+	require.Equal(t, "001b21.fffe.7a3c10", clockIdentity(fakeClockID))
+}
@@ -0,0 +1,382 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package tls_scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	commontls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Versions probed in addition to the default handshake
+var tlsVersions = []struct {
+	version uint16
+	field   string
+}{
+	{tls.VersionTLS10, "supports_tls10"},
+	{tls.VersionTLS11, "supports_tls11"},
+	{tls.VersionTLS12, "supports_tls12"},
+	{tls.VersionTLS13, "supports_tls13"},
+}
+
+type TLSScanner struct {
+	Targets           []string        `toml:"targets"`
+	Ports             []uint16        `toml:"ports"`
+	ServerNames       []string        `toml:"server_names"`
+	Timeout           config.Duration `toml:"timeout"`
+	Concurrency       int             `toml:"concurrency"`
+	MaxEndpoints      int             `toml:"max_endpoints"`
+	ProbeVersions     bool            `toml:"probe_versions"`
+	ReportUnreachable bool            `toml:"report_unreachable"`
+	Log               telegraf.Logger `toml:"-"`
+	commontls.ClientConfig
+
+	tlsCfg    *tls.Config
+	endpoints []endpoint
+
+	// Fingerprints of the leaf certificates of the last scan
+	fingerprints map[string]string
+	sync.Mutex
+}
+
+// endpoint is a single address to scan with an optional SNI name
+type endpoint struct {
+	address    string
+	serverName string
+	// Only report unreachable endpoints explicitly configured by the user
+	explicit bool
+}
+
+func (e endpoint) key() string {
+	return e.address + "/" + e.serverName
+}
+
+func (*TLSScanner) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TLSScanner) Init() error {
+	if len(t.Targets) == 0 {
+		return errors.New("no targets specified")
+	}
+	if len(t.Ports) == 0 {
+		t.Ports = []uint16{443}
+	}
+	if t.Concurrency < 1 {
+		return fmt.Errorf("invalid concurrency %d, must be at least one", t.Concurrency)
+	}
+
+	tlsCfg, err := t.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("creating TLS config failed: %w", err)
+	}
+	if tlsCfg == nil {
+		tlsCfg = &tls.Config{}
+	}
+	// We verify the certificates ourselves to report chain issues
+	tlsCfg.InsecureSkipVerify = true
+	t.tlsCfg = tlsCfg
+
+	t.endpoints, err = t.expandTargets()
+	if err != nil {
+		return err
+	}
+	t.fingerprints = make(map[string]string)
+
+	return nil
+}
+
+// expandTargets converts the targets into the list of endpoints to scan.
+// Targets can be CIDR ranges, IP addresses or host names with an optional port.
+func (t *TLSScanner) expandTargets() ([]endpoint, error) {
+	var endpoints []endpoint
+
+	// For addresses the configured server names are probed in addition to
+	// the handshake without SNI
+	addAddress := func(addr string, explicit bool) {
+		endpoints = append(endpoints, endpoint{address: addr, explicit: explicit})
+		for _, name := range t.ServerNames {
+			endpoints = append(endpoints, endpoint{address: addr, serverName: name, explicit: explicit})
+		}
+	}
+
+	for _, target := range t.Targets {
+		// CIDR ranges
+		if prefix, err := netip.ParsePrefix(target); err == nil {
+			prefix = prefix.Masked()
+			for ip := prefix.Addr(); prefix.Contains(ip); ip = ip.Next() {
+				for _, port := range t.Ports {
+					addAddress(net.JoinHostPort(ip.String(), strconv.Itoa(int(port))), false)
+				}
+				if len(endpoints) > t.MaxEndpoints {
+					return nil, fmt.Errorf("number of endpoints exceeds the limit of %d", t.MaxEndpoints)
+				}
+			}
+			continue
+		}
+
+		// Split off a port if any
+		host, port, err := net.SplitHostPort(target)
+		if err != nil {
+			host, port = target, ""
+		}
+		ports := t.Ports
+		if port != "" {
+			p, err := strconv.ParseUint(port, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid port in target %q: %w", target, err)
+			}
+			ports = []uint16{uint16(p)}
+		}
+
+		for _, p := range ports {
+			addr := net.JoinHostPort(host, strconv.Itoa(int(p)))
+			if _, err := netip.ParseAddr(host); err == nil {
+				addAddress(addr, true)
+			} else {
+				// Use the host name for SNI
+				endpoints = append(endpoints, endpoint{address: addr, serverName: host, explicit: true})
+			}
+		}
+	}
+
+	if len(endpoints) > t.MaxEndpoints {
+		return nil, fmt.Errorf("number of endpoints exceeds the limit of %d", t.MaxEndpoints)
+	}
+	return endpoints, nil
+}
+
+func (t *TLSScanner) Gather(acc telegraf.Accumulator) error {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, t.Concurrency)
+	for _, ep := range t.endpoints {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ep endpoint) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			t.scan(ctx, acc, ep)
+		}(ep)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (t *TLSScanner) scan(ctx context.Context, acc telegraf.Accumulator, ep endpoint) {
+	now := time.Now()
+	tags := map[string]string{"target": ep.address}
+	if ep.serverName != "" {
+		tags["server_name"] = ep.serverName
+	}
+	fields := make(map[string]interface{})
+
+	state, err := t.handshake(ctx, ep, 0)
+	if err != nil {
+		var netErr net.Error
+		var opErr *net.OpError
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+			if !ep.explicit && !t.ReportUnreachable {
+				return
+			}
+			setResult("timeout", fields, tags)
+		case errors.As(err, &opErr) && opErr.Op == "dial":
+			if !ep.explicit && !t.ReportUnreachable {
+				return
+			}
+			setResult("connection_failed", fields, tags)
+		default:
+			setResult("handshake_failed", fields, tags)
+			fields["error"] = err.Error()
+		}
+		t.Log.Debugf("Scanning %s (%q) failed: %v", ep.address, ep.serverName, err)
+		acc.AddFields("tls_scanner", fields, tags, now)
+		return
+	}
+	setResult("success", fields, tags)
+
+	// Negotiated parameters of the default handshake
+	tags["tls_version"] = tls.VersionName(state.Version)
+	tags["cipher_suite"] = tls.CipherSuiteName(state.CipherSuite)
+	insecureCipher := isInsecureCipher(state.CipherSuite)
+	fields["insecure_cipher"] = insecureCipher
+
+	// Certificate information of the leaf
+	certs := state.PeerCertificates
+	leaf := certs[0]
+	tags["common_name"] = leaf.Subject.CommonName
+	tags["issuer_common_name"] = leaf.Issuer.CommonName
+	fields["serial_number"] = leaf.SerialNumber.Text(16)
+	fields["startdate"] = leaf.NotBefore.Unix()
+	fields["enddate"] = leaf.NotAfter.Unix()
+	fields["expiry"] = int64(leaf.NotAfter.Sub(now).Seconds())
+	fields["chain_length"] = len(certs)
+	fields["self_signed"] = len(certs) == 1 && leaf.Issuer.String() == leaf.Subject.String()
+
+	// Detect certificate changes between scans
+	sum := sha256.Sum256(leaf.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+	fields["fingerprint_sha256"] = fingerprint
+	t.Lock()
+	previous, found := t.fingerprints[ep.key()]
+	t.fingerprints[ep.key()] = fingerprint
+	t.Unlock()
+	fields["changed"] = found && previous != fingerprint
+
+	// Verify the chain including the host name
+	valid := true
+	if err := t.verify(ep, certs, now); err != nil {
+		valid = false
+		tags["verification"] = "invalid"
+		fields["verification_error"] = err.Error()
+	} else {
+		tags["verification"] = "valid"
+	}
+
+	// Probe the supported protocol versions
+	legacy := false
+	if t.ProbeVersions {
+		for _, v := range tlsVersions {
+			supported := v.version == state.Version
+			if !supported {
+				if _, err := t.handshake(ctx, ep, v.version); err == nil {
+					supported = true
+				}
+			}
+			fields[v.field] = supported
+			if supported && v.version < tls.VersionTLS12 {
+				legacy = true
+			}
+		}
+	}
+
+	tags["grade"] = grade(valid, state.Version, legacy, insecureCipher)
+
+	acc.AddFields("tls_scanner", fields, tags, now)
+}
+
+// handshake connects to the endpoint and performs a TLS handshake restricted
+// to the given version, or using the default versions if zero.
+func (t *TLSScanner) handshake(ctx context.Context, ep endpoint, version uint16) (*tls.ConnectionState, error) {
+	timeout := time.Duration(t.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cfg := t.tlsCfg.Clone()
+	cfg.ServerName = ep.serverName
+	if version != 0 {
+		cfg.MinVersion = version
+		cfg.MaxVersion = version
+	} else {
+		cfg.MinVersion = tls.VersionTLS10
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: timeout},
+		Config:    cfg,
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", ep.address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, errors.New("no certificates received")
+	}
+	return &state, nil
+}
+
+func (t *TLSScanner) verify(ep endpoint, certs []*x509.Certificate, now time.Time) error {
+	opts := x509.VerifyOptions{
+		Roots:         t.tlsCfg.RootCAs,
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   now,
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	// Check the host name against the SNI name or the IP address
+	opts.DNSName = ep.serverName
+	if opts.DNSName == "" {
+		host, _, err := net.SplitHostPort(ep.address)
+		if err == nil {
+			opts.DNSName = host
+		}
+	}
+
+	_, err := certs[0].Verify(opts)
+	return err
+}
+
+func isInsecureCipher(id uint16) bool {
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// grade rates the endpoint similar to common TLS scanners:
+//
+//	A: valid chain, TLS 1.2 or higher only and no insecure ciphers
+//	B: legacy protocol versions (TLS 1.0 or 1.1) supported
+//	C: insecure cipher negotiated or legacy protocol preferred
+//	F: invalid certificate chain
+func grade(valid bool, version uint16, legacy, insecureCipher bool) string {
+	switch {
+	case !valid:
+		return "F"
+	case insecureCipher || version < tls.VersionTLS12:
+		return "C"
+	case legacy:
+		return "B"
+	}
+	return "A"
+}
+
+func setResult(result string, fields map[string]interface{}, tags map[string]string) {
+	resultCodes := map[string]int{
+		"success":           0,
+		"connection_failed": 1,
+		"timeout":           2,
+		"handshake_failed":  3,
+	}
+
+	tags["result"] = result
+	fields["result_code"] = resultCodes[result]
+}
+
+func init() {
+	inputs.Add("tls_scanner", func() telegraf.Input {
+		return &TLSScanner{
+			Timeout:       config.Duration(5 * time.Second),
+			Concurrency:   32,
+			MaxEndpoints:  65536,
+			ProbeVersions: true,
+		}
+	})
+}
@@ -0,0 +1,249 @@
+package tls_scanner
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	commontls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var pki = testutil.NewPKI("../../../testutil/pki")
+
+func startServer(t *testing.T, minVersion uint16) string {
+	t.Helper()
+
+	pair, err := tls.X509KeyPair([]byte(pki.ReadServerCert()), []byte(pki.ReadServerKey()))
+	require.NoError(t, err)
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		MinVersion:   minVersion,
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TLSScanner
+		expected string
+	}{
+		{
+			name:     "no targets",
+			plugin:   &TLSScanner{Concurrency: 1, MaxEndpoints: 10},
+			expected: "no targets specified",
+		},
+		{
+			name:     "invalid concurrency",
+			plugin:   &TLSScanner{Targets: []string{"127.0.0.1"}, MaxEndpoints: 10},
+			expected: "invalid concurrency 0",
+		},
+		{
+			name:     "too many endpoints",
+			plugin:   &TLSScanner{Targets: []string{"10.0.0.0/24"}, Concurrency: 1, MaxEndpoints: 10},
+			expected: "number of endpoints exceeds the limit of 10",
+		},
+		{
+			name:     "invalid port",
+			plugin:   &TLSScanner{Targets: []string{"example.org:foo"}, Concurrency: 1, MaxEndpoints: 10},
+			expected: `invalid port in target "example.org:foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestExpandTargets(t *testing.T) {
+	plugin := &TLSScanner{
+		Targets:      []string{"192.168.1.4/31", "example.org", "10.0.0.1:8443"},
+		Ports:        []uint16{443, 636},
+		ServerNames:  []string{"intranet.local"},
+		Concurrency:  1,
+		MaxEndpoints: 100,
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []endpoint{
+		{address: "192.168.1.4:443"},
+		{address: "192.168.1.4:443", serverName: "intranet.local"},
+		{address: "192.168.1.4:636"},
+		{address: "192.168.1.4:636", serverName: "intranet.local"},
+		{address: "192.168.1.5:443"},
+		{address: "192.168.1.5:443", serverName: "intranet.local"},
+		{address: "192.168.1.5:636"},
+		{address: "192.168.1.5:636", serverName: "intranet.local"},
+		{address: "example.org:443", serverName: "example.org", explicit: true},
+		{address: "example.org:636", serverName: "example.org", explicit: true},
+		{address: "10.0.0.1:8443", explicit: true},
+		{address: "10.0.0.1:8443", serverName: "intranet.local", explicit: true},
+	}
+	require.Equal(t, expected, plugin.endpoints)
+}
+
+func TestGather(t *testing.T) {
+	addr := startServer(t, tls.VersionTLS12)
+
+	plugin := &TLSScanner{
+		Targets:       []string{addr},
+		Timeout:       config.Duration(5 * time.Second),
+		Concurrency:   1,
+		MaxEndpoints:  10,
+		ProbeVersions: true,
+		ClientConfig:  commontls.ClientConfig{TLSCA: pki.CACertPath()},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	m := metrics[0]
+
+	tags := m.Tags()
+	require.Equal(t, addr, tags["target"])
+	require.Equal(t, "success", tags["result"])
+	require.Equal(t, "localhost", tags["common_name"])
+	require.Equal(t, "valid", tags["verification"])
+	require.Equal(t, "TLS 1.3", tags["tls_version"])
+	require.Equal(t, "A", tags["grade"])
+
+	fields := m.Fields()
+	require.Equal(t, int64(0), fields["result_code"])
+	require.Equal(t, int64(1), fields["chain_length"])
+	require.Equal(t, false, fields["changed"])
+	require.Equal(t, false, fields["supports_tls10"])
+	require.Equal(t, false, fields["supports_tls11"])
+	require.Equal(t, true, fields["supports_tls12"])
+	require.Equal(t, true, fields["supports_tls13"])
+	require.NotContains(t, fields, "verification_error")
+
+	// A second scan with a different certificate must be flagged as change
+	for k := range plugin.fingerprints {
+		plugin.fingerprints[k] = "previous"
+	}
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	metrics = acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, true, metrics[0].Fields()["changed"])
+}
+
+func TestGatherLegacyAndUntrusted(t *testing.T) {
+	addr := startServer(t, tls.VersionTLS10)
+
+	plugin := &TLSScanner{
+		Targets:       []string{addr},
+		Timeout:       config.Duration(5 * time.Second),
+		Concurrency:   1,
+		MaxEndpoints:  10,
+		ProbeVersions: true,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	tags := metrics[0].Tags()
+	fields := metrics[0].Fields()
+	require.Equal(t, "invalid", tags["verification"])
+	require.Equal(t, "F", tags["grade"])
+	require.Contains(t, fields, "verification_error")
+	require.Equal(t, true, fields["supports_tls12"])
+}
+
+func TestGatherUnreachable(t *testing.T) {
+	// Reserve a port and close it again to get an unused one
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().(*net.TCPAddr)
+	require.NoError(t, listener.Close())
+
+	tests := []struct {
+		name              string
+		target            string
+		reportUnreachable bool
+		expected          int
+	}{
+		{
+			name:     "explicit target",
+			target:   addr.String(),
+			expected: 1,
+		},
+		{
+			name:   "range",
+			target: "127.0.0.1/32",
+		},
+		{
+			name:              "range with reporting",
+			target:            "127.0.0.1/32",
+			reportUnreachable: true,
+			expected:          1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &TLSScanner{
+				Targets:           []string{tt.target},
+				Ports:             []uint16{uint16(addr.Port)},
+				Timeout:           config.Duration(time.Second),
+				Concurrency:       1,
+				MaxEndpoints:      10,
+				ReportUnreachable: tt.reportUnreachable,
+				Log:               testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+
+			metrics := acc.GetTelegrafMetrics()
+			require.Len(t, metrics, tt.expected)
+			for _, m := range metrics {
+				require.Equal(t, "connection_failed", m.Tags()["result"])
+				require.Equal(t, int64(1), m.Fields()["result_code"])
+			}
+		})
+	}
+}
+
+func TestGrade(t *testing.T) {
+	require.Equal(t, "A", grade(true, tls.VersionTLS13, false, false))
+	require.Equal(t, "B", grade(true, tls.VersionTLS13, true, false))
+	require.Equal(t, "C", grade(true, tls.VersionTLS12, false, true))
+	require.Equal(t, "C", grade(true, tls.VersionTLS10, true, false))
+	require.Equal(t, "F", grade(false, tls.VersionTLS13, false, false))
+}
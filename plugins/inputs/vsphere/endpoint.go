@@ -57,6 +57,8 @@ type Endpoint struct {
 	customFields      map[int32]string
 	customAttrFilter  filter.Filter
 	customAttrEnabled bool
+	hostSensorFilter  filter.Filter
+	hostSensorEnabled bool
 	metricNameLookup  map[int32]string
 	metricNameMux     sync.RWMutex
 	log               telegraf.Logger
@@ -127,6 +129,8 @@ func NewEndpoint(ctx context.Context, parent *VSphere, address *url.URL, log tel
 		clientFactory:     NewClientFactory(address, parent),
 		customAttrFilter:  newFilterOrPanic(parent.CustomAttributeInclude, parent.CustomAttributeExclude),
 		customAttrEnabled: anythingEnabled(parent.CustomAttributeExclude),
+		hostSensorFilter:  newFilterOrPanic(parent.HostSensorInclude, parent.HostSensorExclude),
+		hostSensorEnabled: anythingEnabled(parent.HostSensorExclude),
 		log:               log,
 	}
 
@@ -272,6 +276,15 @@ func anythingEnabled(ex []string) bool {
 	return true
 }
 
+// clusterConcurrency returns the number of clusters to process in parallel
+// for the per-cluster collections (vSAN and host sensors).
+func (e *Endpoint) clusterConcurrency() int {
+	if e.Parent.ClusterConcurrency > 0 {
+		return e.Parent.ClusterConcurrency
+	}
+	return e.Parent.CollectConcurrency
+}
+
 func newFilterOrPanic(include []string, exclude []string) filter.Filter {
 	f, err := filter.NewIncludeExcludeFilter(include, exclude)
 	if err != nil {
@@ -964,6 +977,15 @@ func (e *Endpoint) Collect(ctx context.Context, acc telegraf.Accumulator) error
 		}
 	}
 	var wg sync.WaitGroup
+	if e.hostSensorEnabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := e.collectHostSensors(ctx, acc); err != nil {
+				acc.AddError(err)
+			}
+		}()
+	}
 	for k, res := range e.resourceKinds {
 		if res.enabled {
 			wg.Add(1)
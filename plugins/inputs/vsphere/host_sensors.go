@@ -0,0 +1,147 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/influxdata/telegraf"
+)
+
+const sensorInfoProperty = "runtime.healthSystemRuntime.systemHealthInfo.numericSensorInfo"
+
+// sensorHealthMap maps the sensor health state keys reported by vCenter to numerical values
+var sensorHealthMap = map[string]int{"green": 0, "yellow": 1, "red": 2, "unknown": 3}
+
+// collectHostSensors is the entry point for host hardware sensor collection. The readings are
+// gathered by vCenter from the hosts' IPMI/CIM providers and retrieved per cluster.
+func (e *Endpoint) collectHostSensors(ctx context.Context, acc telegraf.Accumulator) error {
+	client, err := e.clientFactory.GetClient(ctx)
+	if err != nil {
+		return fmt.Errorf("fail to get client when collecting host sensors: %w", err)
+	}
+	pc := property.DefaultCollector(client.Client.Client)
+
+	// Group the hosts by their parent compute resource, standalone hosts form a group on their own
+	byCluster := make(map[string][]*objectRef)
+	for _, obj := range e.resourceKinds["host"].objects {
+		key := obj.ref.Value
+		if obj.parentRef != nil {
+			key = obj.parentRef.Value
+		}
+		byCluster[key] = append(byCluster[key], obj)
+	}
+
+	te := NewThrottledExecutor(e.clusterConcurrency())
+	for _, hosts := range byCluster {
+		hosts := hosts
+		te.Run(ctx, func() {
+			if err := e.collectHostSensorsPerCluster(ctx, pc, hosts, acc); err != nil {
+				acc.AddError(err)
+			}
+		})
+	}
+	te.Wait()
+	return nil
+}
+
+// collectHostSensorsPerCluster retrieves the sensor readings of the given hosts in chunks of max_query_objects.
+func (e *Endpoint) collectHostSensorsPerCluster(ctx context.Context, pc *property.Collector, hosts []*objectRef, acc telegraf.Accumulator) error {
+	chunkSize := e.Parent.MaxQueryObjects
+	if chunkSize <= 0 {
+		chunkSize = len(hosts)
+	}
+	for start := 0; start < len(hosts); start += chunkSize {
+		chunk := hosts[start:min(start+chunkSize, len(hosts))]
+		refs := make([]types.ManagedObjectReference, 0, len(chunk))
+		lookup := make(map[string]*objectRef, len(chunk))
+		for _, obj := range chunk {
+			refs = append(refs, obj.ref)
+			lookup[obj.ref.Value] = obj
+		}
+
+		var content []mo.HostSystem
+		ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(e.Parent.Timeout))
+		err := pc.Retrieve(ctx1, refs, []string{sensorInfoProperty}, &content)
+		cancel1()
+		if err != nil {
+			return fmt.Errorf("error querying host sensors: %w", err)
+		}
+		e.addHostSensors(acc, content, lookup, time.Now())
+	}
+	return nil
+}
+
+// addHostSensors adds one metric per selected sensor of the given hosts to the accumulator
+func (e *Endpoint) addHostSensors(acc telegraf.Accumulator, hosts []mo.HostSystem, lookup map[string]*objectRef, now time.Time) {
+	measurement := strings.Join([]string{"vsphere", "host", "sensor"}, e.Parent.Separator)
+	for i := range hosts {
+		h := &hosts[i]
+		obj, ok := lookup[h.Self.Value]
+		if !ok {
+			continue
+		}
+		health := h.Runtime.HealthSystemRuntime
+		if health == nil || health.SystemHealthInfo == nil {
+			e.log.Debugf("No sensor information available for host %s", obj.name)
+			continue
+		}
+
+		hostTags := map[string]string{
+			"vcenter":     e.URL.Host,
+			"esxhostname": obj.name,
+			"moid":        obj.ref.Value,
+			"source":      obj.name,
+		}
+		if obj.dcname != "" {
+			hostTags["dcname"] = obj.dcname
+		}
+		if obj.parentRef != nil {
+			if cluster, ok := e.resourceKinds["cluster"].objects[obj.parentRef.Value]; ok {
+				hostTags["clustername"] = cluster.name
+			}
+		}
+
+		for _, sensor := range health.SystemHealthInfo.NumericSensorInfo {
+			if !e.hostSensorFilter.Match(sensor.SensorType) {
+				continue
+			}
+			tags := make(map[string]string, len(hostTags)+3)
+			for k, v := range hostTags {
+				tags[k] = v
+			}
+			tags["sensor"] = sensor.Name
+			tags["type"] = sensor.SensorType
+			if sensor.BaseUnits != "" {
+				tags["unit"] = sensor.BaseUnits
+			}
+			acc.AddFields(measurement, sensorFields(sensor), tags, now)
+		}
+	}
+}
+
+// sensorFields converts a sensor reading into its fields, scaling the raw reading by the unit modifier
+func sensorFields(sensor types.HostNumericSensorInfo) map[string]interface{} {
+	// Divide for negative modifiers to avoid rounding artifacts of the fractional powers of ten
+	value := float64(sensor.CurrentReading)
+	if sensor.UnitModifier < 0 {
+		value /= math.Pow10(-int(sensor.UnitModifier))
+	} else {
+		value *= math.Pow10(int(sensor.UnitModifier))
+	}
+	fields := map[string]interface{}{"value": value}
+	if sensor.HealthState != nil {
+		if state := sensor.HealthState.GetElementDescription(); state != nil {
+			if v, ok := sensorHealthMap[strings.ToLower(state.Key)]; ok {
+				fields["health"] = v
+			}
+		}
+	}
+	return fields
+}
@@ -30,11 +30,13 @@ const (
 var (
 	vsanPerfMetricsName    string
 	vsanSummaryMetricsName string
+	vsanHealthMetricsName  string
 	perfManagerRef         = types.ManagedObjectReference{
 		Type:  "VsanPerformanceManager",
 		Value: "vsan-performance-manager",
 	}
 	hyphenReplacer = strings.NewReplacer("-", "")
+	vsanHealthMap  = map[string]int{"red": 2, "yellow": 1, "green": 0}
 )
 
 // collectVsan is the entry point for vsan metrics collection
@@ -45,6 +47,7 @@ func (e *Endpoint) collectVsan(ctx context.Context, acc telegraf.Accumulator) er
 	}
 	vsanPerfMetricsName = strings.Join([]string{"vsphere", "vsan", "performance"}, e.Parent.Separator)
 	vsanSummaryMetricsName = strings.Join([]string{"vsphere", "vsan", "summary"}, e.Parent.Separator)
+	vsanHealthMetricsName = strings.Join([]string{"vsphere", "vsan", "health"}, e.Parent.Separator)
 	res := e.resourceKinds["vsan"]
 	client, err := e.clientFactory.GetClient(ctx)
 	if err != nil {
@@ -56,7 +59,7 @@ func (e *Endpoint) collectVsan(ctx context.Context, acc telegraf.Accumulator) er
 	// vSAN Metrics to collect
 	metrics := e.getVsanMetadata(ctx, vsanClient, res)
 	// Iterate over all clusters, run a goroutine for each cluster
-	te := NewThrottledExecutor(e.Parent.CollectConcurrency)
+	te := NewThrottledExecutor(e.clusterConcurrency())
 	for _, obj := range res.objects {
 		te.Run(ctx, func() {
 			e.collectVsanPerCluster(ctx, obj, vimClient, vsanClient, metrics, acc)
@@ -86,6 +89,11 @@ func (e *Endpoint) collectVsanPerCluster(ctx context.Context, clusterRef *object
 			acc.AddError(fmt.Errorf("error querying vsan health summary for cluster %s: %w", clusterRef.name, err))
 		}
 	}
+	if _, ok := metrics["summary.health-groups"]; ok {
+		if err := e.queryHealthGroups(ctx, vsanClient, clusterRef, acc); err != nil {
+			acc.AddError(fmt.Errorf("error querying vsan health groups for cluster %s: %w", clusterRef.name, err))
+		}
+	}
 	if _, ok := metrics["summary.resync"]; ok {
 		if err := e.queryResyncSummary(ctx, vsanClient, cluster, clusterRef, acc); err != nil {
 			acc.AddError(fmt.Errorf("error querying vsan resync summary for cluster %s: %w", clusterRef.name, err))
@@ -112,7 +120,7 @@ func (e *Endpoint) vsanEnabled(ctx context.Context, clusterObj *object.ClusterCo
 }
 
 // getVsanMetadata returns a string list of the entity types that will be queried.
-// e.g ["summary.health", "summary.disk-usage", "summary.resync", "performance.cluster-domclient", "performance.host-domclient"]
+// e.g ["summary.health", "summary.health-groups", "summary.disk-usage", "summary.resync", "performance.cluster-domclient", "performance.host-domclient"]
 func (e *Endpoint) getVsanMetadata(ctx context.Context, vsanClient *soap.Client, res *resourceKind) map[string]string {
 	metrics := make(map[string]string)
 	if res.simple { // Skip getting supported Entity types from vCenter. Using user defined metrics without verifying.
@@ -126,7 +134,7 @@ func (e *Endpoint) getVsanMetadata(ctx context.Context, vsanClient *soap.Client,
 		return metrics
 	}
 	// Use the include & exclude configuration to filter all summary metrics
-	for _, entity := range []string{"summary.health", "summary.disk-usage", "summary.resync"} {
+	for _, entity := range []string{"summary.health", "summary.health-groups", "summary.disk-usage", "summary.resync"} {
 		if res.filters.Match(entity) {
 			metrics[entity] = ""
 		}
@@ -347,9 +355,8 @@ func (e *Endpoint) queryHealthSummary(ctx context.Context, vsanClient *soap.Clie
 		return err
 	}
 	healthStr := resp.Returnval.OverallHealth
-	healthMap := map[string]int{"red": 2, "yellow": 1, "green": 0}
 	fields := make(map[string]interface{})
-	if val, ok := healthMap[healthStr]; ok {
+	if val, ok := vsanHealthMap[healthStr]; ok {
 		fields["overall_health"] = val
 	}
 	tags := populateClusterTags(make(map[string]string), clusterRef, e.URL.Host)
@@ -357,6 +364,56 @@ func (e *Endpoint) queryHealthSummary(ctx context.Context, vsanClient *soap.Clie
 	return nil
 }
 
+// queryHealthGroups adds the health of each vSAN health check group and of its tests to telegraf accumulator
+func (e *Endpoint) queryHealthGroups(ctx context.Context, vsanClient *soap.Client, clusterRef *objectRef, acc telegraf.Accumulator) error {
+	healthSystemRef := types.ManagedObjectReference{
+		Type:  "VsanVcClusterHealthSystem",
+		Value: "vsan-cluster-health-system",
+	}
+	fetchFromCache := true
+	resp, err := vsanmethods.VsanQueryVcClusterHealthSummary(ctx, vsanClient,
+		&vsantypes.VsanQueryVcClusterHealthSummary{
+			This:           healthSystemRef,
+			Cluster:        &clusterRef.ref,
+			Fields:         []string{"groups"},
+			FetchFromCache: &fetchFromCache,
+		})
+	if err != nil {
+		return err
+	}
+	addHealthGroups(acc, resp.Returnval.Groups, populateClusterTags(make(map[string]string), clusterRef, e.URL.Host))
+	return nil
+}
+
+// addHealthGroups adds one metric per health group and one per test within the group
+func addHealthGroups(acc telegraf.Accumulator, groups []vsantypes.VsanClusterHealthGroup, clusterTags map[string]string) {
+	for _, group := range groups {
+		groupTags := make(map[string]string, len(clusterTags)+2)
+		for k, v := range clusterTags {
+			groupTags[k] = v
+		}
+		groupTags["group_id"] = group.GroupId
+		groupTags["group_name"] = group.GroupName
+		if val, ok := vsanHealthMap[group.GroupHealth]; ok {
+			acc.AddFields(vsanHealthMetricsName, map[string]interface{}{"health": val}, groupTags)
+		}
+
+		for _, test := range group.GroupTests {
+			val, ok := vsanHealthMap[test.TestHealth]
+			if !ok {
+				continue
+			}
+			tags := make(map[string]string, len(groupTags)+2)
+			for k, v := range groupTags {
+				tags[k] = v
+			}
+			tags["test_id"] = test.TestId
+			tags["test_name"] = test.TestName
+			acc.AddFields(vsanHealthMetricsName, map[string]interface{}{"health": val}, tags)
+		}
+	}
+}
+
 // queryResyncSummary adds resync information to accumulator
 func (e *Endpoint) queryResyncSummary(ctx context.Context, vsanClient *soap.Client, clusterObj *object.ClusterComputeResource,
 	clusterRef *objectRef, acc telegraf.Accumulator) error {
@@ -46,6 +46,8 @@ type VSphere struct {
 	HostMetricExclude           []string        `toml:"host_metric_exclude"`
 	HostInclude                 []string        `toml:"host_include"`
 	HostExclude                 []string        `toml:"host_exclude"`
+	HostSensorInclude           []string        `toml:"host_sensor_include"`
+	HostSensorExclude           []string        `toml:"host_sensor_exclude"`
 	VMInstances                 bool            `toml:"vm_instances"`
 	VMMetricInclude             []string        `toml:"vm_metric_include"`
 	VMMetricExclude             []string        `toml:"vm_metric_exclude"`
@@ -72,6 +74,7 @@ type VSphere struct {
 	MaxQueryMetrics             int             `toml:"max_query_metrics"`
 	CollectConcurrency          int             `toml:"collect_concurrency"`
 	DiscoverConcurrency         int             `toml:"discover_concurrency"`
+	ClusterConcurrency          int             `toml:"cluster_concurrency"`
 	ForceDiscoverOnInit         bool            `toml:"force_discover_on_init" deprecated:"1.14.0;option is ignored"`
 	ObjectDiscoveryInterval     config.Duration `toml:"object_discovery_interval"`
 	Timeout                     config.Duration `toml:"timeout"`
@@ -163,6 +166,7 @@ func init() {
 			ClusterInclude:              []string{"/*/host/**"},
 			HostInstances:               true,
 			HostInclude:                 []string{"/*/host/**"},
+			HostSensorExclude:           []string{"*"},
 			ResourcePoolInclude:         []string{"/*/host/**"},
 			VMInstances:                 true,
 			VMInclude:                   []string{"/*/vm/**"},
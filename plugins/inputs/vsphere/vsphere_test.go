@@ -15,7 +15,9 @@ import (
 	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
+	vsantypes "github.com/vmware/govmomi/vsan/types"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	itls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/testutil"
@@ -456,6 +458,177 @@ func TestVsanTags(t *testing.T) {
 	require.Len(t, tags, 1)
 }
 
+func TestVsanHealthGroups(t *testing.T) {
+	vsanHealthMetricsName = "vsphere_vsan_health"
+	clusterTags := map[string]string{"vcenter": "localhost", "clustername": "C0"}
+	groups := []vsantypes.VsanClusterHealthGroup{
+		{
+			GroupId:     "com.vmware.vsan.health.test.network",
+			GroupName:   "Network",
+			GroupHealth: "yellow",
+			GroupTests: []vsantypes.VsanClusterHealthTest{
+				{TestId: "com.vmware.vsan.health.test.hostdisconnected", TestName: "Host disconnected", TestHealth: "green"},
+				{TestId: "com.vmware.vsan.health.test.vsanvmknic", TestName: "vSAN vmknic", TestHealth: "yellow"},
+				{TestId: "com.vmware.vsan.health.test.skipped", TestName: "Skipped", TestHealth: "skipped"},
+			},
+		},
+	}
+
+	var acc testutil.Accumulator
+	addHealthGroups(&acc, groups, clusterTags)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"vsphere_vsan_health",
+			map[string]string{
+				"vcenter":     "localhost",
+				"clustername": "C0",
+				"group_id":    "com.vmware.vsan.health.test.network",
+				"group_name":  "Network",
+			},
+			map[string]interface{}{"health": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"vsphere_vsan_health",
+			map[string]string{
+				"vcenter":     "localhost",
+				"clustername": "C0",
+				"group_id":    "com.vmware.vsan.health.test.network",
+				"group_name":  "Network",
+				"test_id":     "com.vmware.vsan.health.test.hostdisconnected",
+				"test_name":   "Host disconnected",
+			},
+			map[string]interface{}{"health": 0},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"vsphere_vsan_health",
+			map[string]string{
+				"vcenter":     "localhost",
+				"clustername": "C0",
+				"group_id":    "com.vmware.vsan.health.test.network",
+				"group_name":  "Network",
+				"test_id":     "com.vmware.vsan.health.test.vsanvmknic",
+				"test_name":   "vSAN vmknic",
+			},
+			map[string]interface{}{"health": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestHostSensors(t *testing.T) {
+	v := defaultVSphere()
+	v.Separator = "_"
+	v.HostSensorInclude = []string{"temperature", "fan"}
+	v.HostSensorExclude = nil
+	u, err := url.Parse("https://localhost:8989/sdk")
+	require.NoError(t, err)
+
+	clusterRef := types.ManagedObjectReference{Type: "ClusterComputeResource", Value: "domain-c7"}
+	hostRef := types.ManagedObjectReference{Type: "HostSystem", Value: "host-21"}
+	e := &Endpoint{
+		Parent:            v,
+		URL:               u,
+		log:               testutil.Logger{},
+		hostSensorFilter:  newFilterOrPanic(v.HostSensorInclude, v.HostSensorExclude),
+		hostSensorEnabled: anythingEnabled(v.HostSensorExclude),
+		resourceKinds: map[string]*resourceKind{
+			"cluster": {objects: objectMap{"domain-c7": {name: "C0", ref: clusterRef}}},
+		},
+	}
+	require.True(t, e.hostSensorEnabled)
+
+	host := mo.HostSystem{}
+	host.Self = hostRef
+	host.Runtime.HealthSystemRuntime = &types.HealthSystemRuntime{
+		SystemHealthInfo: &types.HostSystemHealthInfo{
+			NumericSensorInfo: []types.HostNumericSensorInfo{
+				{
+					Name:           "System Board 1 Inlet Temp",
+					HealthState:    &types.ElementDescription{Key: "green"},
+					CurrentReading: 2300,
+					UnitModifier:   -2,
+					BaseUnits:      "Degrees C",
+					SensorType:     "temperature",
+				},
+				{
+					Name:           "Fan 2",
+					HealthState:    &types.ElementDescription{Key: "Red"},
+					CurrentReading: 0,
+					BaseUnits:      "RPM",
+					SensorType:     "fan",
+				},
+				{
+					Name:           "Power Supply 1 Voltage",
+					HealthState:    &types.ElementDescription{Key: "green"},
+					CurrentReading: 230,
+					BaseUnits:      "Volts",
+					SensorType:     "voltage",
+				},
+			},
+		},
+	}
+	// Hosts without any health information must be skipped
+	other := mo.HostSystem{}
+	other.Self = types.ManagedObjectReference{Type: "HostSystem", Value: "host-22"}
+
+	lookup := map[string]*objectRef{
+		"host-21": {name: "esx01", ref: hostRef, parentRef: &clusterRef, dcname: "DC0"},
+		"host-22": {name: "esx02", ref: other.Self, parentRef: &clusterRef, dcname: "DC0"},
+	}
+
+	var acc testutil.Accumulator
+	e.addHostSensors(&acc, []mo.HostSystem{host, other}, lookup, time.Unix(1700000000, 0))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"vsphere_host_sensor",
+			map[string]string{
+				"vcenter":     "localhost:8989",
+				"esxhostname": "esx01",
+				"moid":        "host-21",
+				"source":      "esx01",
+				"dcname":      "DC0",
+				"clustername": "C0",
+				"sensor":      "System Board 1 Inlet Temp",
+				"type":        "temperature",
+				"unit":        "Degrees C",
+			},
+			map[string]interface{}{"value": 23.0, "health": 0},
+			time.Unix(1700000000, 0),
+		),
+		testutil.MustMetric(
+			"vsphere_host_sensor",
+			map[string]string{
+				"vcenter":     "localhost:8989",
+				"esxhostname": "esx01",
+				"moid":        "host-21",
+				"source":      "esx01",
+				"dcname":      "DC0",
+				"clustername": "C0",
+				"sensor":      "Fan 2",
+				"type":        "fan",
+				"unit":        "RPM",
+			},
+			map[string]interface{}{"value": 0.0, "health": 2},
+			time.Unix(1700000000, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestClusterConcurrency(t *testing.T) {
+	v := defaultVSphere()
+	v.CollectConcurrency = 3
+	e := &Endpoint{Parent: v}
+	require.Equal(t, 3, e.clusterConcurrency())
+	v.ClusterConcurrency = 8
+	require.Equal(t, 8, e.clusterConcurrency())
+}
+
 func TestCollectionNoClusterMetrics(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping long test in short mode")
@@ -0,0 +1,68 @@
+package alertmanager
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+)
+
+type AlertmanagerWebhook struct {
+	Path  string `toml:"path"`
+	Token string `toml:"token"`
+	acc   telegraf.Accumulator
+	log   telegraf.Logger
+	auth.BasicAuth
+}
+
+func (am *AlertmanagerWebhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(am.Path, am.eventHandler).Methods("POST")
+	am.log = log
+	am.log.Infof("Started the webhooks_alertmanager on %s", am.Path)
+	am.acc = acc
+}
+
+func (am *AlertmanagerWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !am.Verify(r) || !am.verifyToken(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	for i := range msg.Alerts {
+		alert := &msg.Alerts[i]
+		am.acc.AddFields("alertmanager_webhooks", alert.Fields(msg.GroupKey), alert.Tags(msg.Receiver), now)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// verifyToken checks the bearer token configured in the
+// "http_config.authorization" section of the Alertmanager receiver
+func (am *AlertmanagerWebhook) verifyToken(r *http.Request) bool {
+	if am.Token == "" {
+		return true
+	}
+	expected := "Bearer " + am.Token
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) == 1
+}
@@ -0,0 +1,61 @@
+package alertmanager
+
+import (
+	"time"
+)
+
+// Message is the notification payload sent by the Alertmanager webhook
+// receiver, see https://prometheus.io/docs/alerting/latest/configuration/#webhook_config
+type Message struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int64             `json:"truncatedAlerts"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// Alert is a single alert contained in the notification
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Tags returns the alert labels along with the receiver and alert status.
+// The receiver and status take precedence over equally named labels.
+func (a *Alert) Tags(receiver string) map[string]string {
+	tags := make(map[string]string, len(a.Labels)+2)
+	for k, v := range a.Labels {
+		tags[k] = v
+	}
+	tags["receiver"] = receiver
+	tags["status"] = a.Status
+	return tags
+}
+
+// Fields returns the alert annotations along with the alert details.
+// The details take precedence over equally named annotations.
+func (a *Alert) Fields(groupKey string) map[string]interface{} {
+	fields := make(map[string]interface{}, len(a.Annotations)+5)
+	for k, v := range a.Annotations {
+		fields[k] = v
+	}
+	fields["fingerprint"] = a.Fingerprint
+	fields["group_key"] = groupKey
+	fields["generator_url"] = a.GeneratorURL
+	fields["starts_at"] = a.StartsAt.UnixNano()
+	// Alertmanager reports "0001-01-01T00:00:00Z" for alerts without end
+	if !a.EndsAt.IsZero() {
+		fields["ends_at"] = a.EndsAt.UnixNano()
+	}
+	return fields
+}
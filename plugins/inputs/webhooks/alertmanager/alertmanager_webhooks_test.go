@@ -0,0 +1,103 @@
+package alertmanager
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(am *AlertmanagerWebhook, body []byte, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/alertmanager", bytes.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+
+	am.eventHandler(w, req)
+
+	return w
+}
+
+func TestAlerts(t *testing.T) {
+	body, err := os.ReadFile("testdata/alerts.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	am := &AlertmanagerWebhook{Path: "/alertmanager", acc: &acc}
+	resp := postWebhooks(am, body, nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"alertmanager_webhooks",
+			map[string]string{
+				"alertname": "HighLatency",
+				"instance":  "api-1:8080",
+				"severity":  "warning",
+				"receiver":  "telegraf",
+				"status":    "firing",
+			},
+			map[string]interface{}{
+				"summary":       "Request latency is above 500ms",
+				"fingerprint":   "a1b2c3d4e5f60718",
+				"group_key":     `{}:{alertname="HighLatency"}`,
+				"generator_url": "http://prometheus.example.com:9090/graph?g0.expr=latency",
+				"starts_at":     time.Date(2023, 11, 20, 10, 15, 30, 0, time.UTC).UnixNano(),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"alertmanager_webhooks",
+			map[string]string{
+				"alertname": "HighLatency",
+				"instance":  "api-2:8080",
+				"severity":  "warning",
+				"receiver":  "telegraf",
+				"status":    "resolved",
+			},
+			map[string]interface{}{
+				"summary":       "Request latency is above 500ms",
+				"fingerprint":   "0f1e2d3c4b5a6978",
+				"group_key":     `{}:{alertname="HighLatency"}`,
+				"generator_url": "http://prometheus.example.com:9090/graph?g0.expr=latency",
+				"starts_at":     time.Date(2023, 11, 20, 10, 10, 0, 0, time.UTC).UnixNano(),
+				"ends_at":       time.Date(2023, 11, 20, 10, 20, 0, 0, time.UTC).UnixNano(),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseError(t *testing.T) {
+	am := &AlertmanagerWebhook{Path: "/alertmanager"}
+	resp := postWebhooks(am, []byte("{"), nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestToken(t *testing.T) {
+	body, err := os.ReadFile("testdata/alerts.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	am := &AlertmanagerWebhook{Path: "/alertmanager", Token: "s3cr3t", acc: &acc}
+
+	resp := postWebhooks(am, body, nil)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postWebhooks(am, body, http.Header{"Authorization": []string{"Bearer wrong"}})
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	resp = postWebhooks(am, body, http.Header{"Authorization": []string{"Bearer s3cr3t"}})
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
@@ -0,0 +1,180 @@
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tidwall/gjson"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/signature"
+)
+
+const defaultMeasurementName = "generic_webhooks"
+
+// GenericWebhook maps arbitrary JSON payloads to metrics using GJSON paths,
+// see https://github.com/tidwall/gjson/blob/master/SYNTAX.md
+type GenericWebhook struct {
+	Path               string            `toml:"path"`
+	MeasurementName    string            `toml:"measurement_name"`
+	MetricSelection    string            `toml:"metric_selection"`
+	TagPaths           map[string]string `toml:"tag_paths"`
+	FieldPaths         map[string]string `toml:"field_paths"`
+	TimestampPath      string            `toml:"timestamp_path"`
+	TimestampFormat    string            `toml:"timestamp_format"`
+	Secret             string            `toml:"secret"`
+	SignatureHeader    string            `toml:"signature_header"`
+	SignatureAlgorithm string            `toml:"signature_algorithm"`
+	SignaturePrefix    string            `toml:"signature_prefix"`
+	acc                telegraf.Accumulator
+	log                telegraf.Logger
+	auth.BasicAuth
+
+	hash func() hash.Hash
+}
+
+func (gw *GenericWebhook) Init() error {
+	if gw.Path == "" {
+		return errors.New("path required for generic webhook")
+	}
+	if len(gw.FieldPaths) == 0 {
+		return fmt.Errorf("no field paths configured for generic webhook %q", gw.Path)
+	}
+	if gw.MeasurementName == "" {
+		gw.MeasurementName = defaultMeasurementName
+	}
+	if gw.TimestampPath != "" && gw.TimestampFormat == "" {
+		return fmt.Errorf("timestamp format required for generic webhook %q", gw.Path)
+	}
+
+	if gw.Secret != "" {
+		if gw.SignatureHeader == "" {
+			return fmt.Errorf("signature header required for generic webhook %q", gw.Path)
+		}
+		h, err := signature.HashFunc(gw.SignatureAlgorithm)
+		if err != nil {
+			return fmt.Errorf("generic webhook %q: %w", gw.Path, err)
+		}
+		gw.hash = h
+	}
+
+	return nil
+}
+
+func (gw *GenericWebhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(gw.Path, gw.eventHandler).Methods("POST")
+	gw.log = log
+	gw.log.Infof("Started the webhooks_generic on %s", gw.Path)
+	gw.acc = acc
+}
+
+func (gw *GenericWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !gw.Verify(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if gw.Secret != "" && !gw.checkSignature(data, r.Header.Get(gw.SignatureHeader)) {
+		gw.log.Errorf("Failed to check the generic webhook signature on %s", gw.Path)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if !gjson.ValidBytes(data) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	root := gjson.ParseBytes(data)
+	if gw.MetricSelection != "" {
+		root = root.Get(gw.MetricSelection)
+	}
+
+	var elements []gjson.Result
+	if root.IsArray() {
+		elements = root.Array()
+	} else if root.Exists() {
+		elements = []gjson.Result{root}
+	}
+
+	now := time.Now()
+	for _, element := range elements {
+		fields, tags, ts, err := gw.parse(element, now)
+		if err != nil {
+			gw.log.Errorf("Parsing payload on %s failed: %v", gw.Path, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		gw.acc.AddFields(gw.MeasurementName, fields, tags, ts)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (gw *GenericWebhook) parse(element gjson.Result, now time.Time) (map[string]interface{}, map[string]string, time.Time, error) {
+	tags := make(map[string]string, len(gw.TagPaths))
+	for name, path := range gw.TagPaths {
+		if v := element.Get(path); v.Exists() && v.Type != gjson.Null {
+			tags[name] = v.String()
+		}
+	}
+
+	fields := make(map[string]interface{}, len(gw.FieldPaths))
+	for name, path := range gw.FieldPaths {
+		v := element.Get(path)
+		switch v.Type {
+		case gjson.Number:
+			fields[name] = v.Float()
+		case gjson.String:
+			fields[name] = v.String()
+		case gjson.True, gjson.False:
+			fields[name] = v.Bool()
+		case gjson.JSON:
+			gw.log.Debugf("Ignoring field %q on %s as it is not a scalar value", name, gw.Path)
+		}
+	}
+
+	ts := now
+	if gw.TimestampPath != "" {
+		v := element.Get(gw.TimestampPath)
+		if !v.Exists() {
+			return nil, nil, ts, fmt.Errorf("timestamp path %q not found", gw.TimestampPath)
+		}
+		var err error
+		ts, err = internal.ParseTimestamp(gw.TimestampFormat, v.String(), nil)
+		if err != nil {
+			return nil, nil, ts, err
+		}
+	}
+
+	return fields, tags, ts, nil
+}
+
+// checkSignature verifies the hex-encoded HMAC given in the signature header
+// after stripping the optional prefix, e.g. "sha256=".
+func (gw *GenericWebhook) checkSignature(data []byte, header string) bool {
+	sig, found := strings.CutPrefix(header, gw.SignaturePrefix)
+	if !found {
+		return false
+	}
+	return signature.Verify(gw.hash, gw.Secret, sig, data)
+}
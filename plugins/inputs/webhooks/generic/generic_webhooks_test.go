@@ -0,0 +1,229 @@
+package generic
+
+import (
+	"crypto/sha1" //nolint:gosec // G505: sha1 is what the sender uses in this test
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/signature"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(gw *GenericWebhook, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", gw.Path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+
+	gw.eventHandler(w, req)
+
+	return w
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GenericWebhook
+		expected string
+	}{
+		{
+			name:     "no path",
+			plugin:   &GenericWebhook{FieldPaths: map[string]string{"value": "value"}},
+			expected: "path required",
+		},
+		{
+			name:     "no fields",
+			plugin:   &GenericWebhook{Path: "/events"},
+			expected: "no field paths configured",
+		},
+		{
+			name: "timestamp without format",
+			plugin: &GenericWebhook{
+				Path:          "/events",
+				FieldPaths:    map[string]string{"value": "value"},
+				TimestampPath: "time",
+			},
+			expected: "timestamp format required",
+		},
+		{
+			name: "secret without header",
+			plugin: &GenericWebhook{
+				Path:       "/events",
+				FieldPaths: map[string]string{"value": "value"},
+				Secret:     "s3cr3t",
+			},
+			expected: "signature header required",
+		},
+		{
+			name: "invalid algorithm",
+			plugin: &GenericWebhook{
+				Path:               "/events",
+				FieldPaths:         map[string]string{"value": "value"},
+				Secret:             "s3cr3t",
+				SignatureHeader:    "X-Signature",
+				SignatureAlgorithm: "md5",
+			},
+			expected: "unknown signature algorithm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestEvent(t *testing.T) {
+	var acc testutil.Accumulator
+	gw := &GenericWebhook{
+		Path: "/events",
+		TagPaths: map[string]string{
+			"service": "service.name",
+			"env":     "labels.env",
+			"missing": "does.not.exist",
+		},
+		FieldPaths: map[string]string{
+			"duration": "duration_ms",
+			"status":   "status",
+			"success":  "success",
+			"details":  "details",
+		},
+		TimestampPath:   "time",
+		TimestampFormat: "2006-01-02T15:04:05Z07:00",
+		acc:             &acc,
+		log:             testutil.Logger{},
+	}
+	require.NoError(t, gw.Init())
+
+	body := `{
+		"service": {"name": "checkout"},
+		"labels": {"env": "prod"},
+		"duration_ms": 125,
+		"status": "deployed",
+		"success": true,
+		"details": {"foo": "bar"},
+		"time": "2023-11-20T10:15:30Z"
+	}`
+	resp := postWebhooks(gw, body, nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"generic_webhooks",
+			map[string]string{
+				"service": "checkout",
+				"env":     "prod",
+			},
+			map[string]interface{}{
+				"duration": 125.0,
+				"status":   "deployed",
+				"success":  true,
+			},
+			time.Date(2023, 11, 20, 10, 15, 30, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestMetricSelection(t *testing.T) {
+	var acc testutil.Accumulator
+	gw := &GenericWebhook{
+		Path:            "/events",
+		MeasurementName: "builds",
+		MetricSelection: "builds",
+		TagPaths:        map[string]string{"job": "name"},
+		FieldPaths:      map[string]string{"duration": "duration"},
+		TimestampPath:   "finished",
+		TimestampFormat: "unix_ms",
+		acc:             &acc,
+		log:             testutil.Logger{},
+	}
+	require.NoError(t, gw.Init())
+
+	body := `{
+		"builds": [
+			{"name": "lint", "duration": 12.5, "finished": 1700475330000},
+			{"name": "test", "duration": 80, "finished": 1700475331000},
+			{"name": "skipped", "finished": 1700475332000}
+		]
+	}`
+	resp := postWebhooks(gw, body, nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"builds",
+			map[string]string{"job": "lint"},
+			map[string]interface{}{"duration": 12.5},
+			time.Unix(1700475330, 0),
+		),
+		testutil.MustMetric(
+			"builds",
+			map[string]string{"job": "test"},
+			map[string]interface{}{"duration": 80.0},
+			time.Unix(1700475331, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestParseError(t *testing.T) {
+	var acc testutil.Accumulator
+	gw := &GenericWebhook{
+		Path:            "/events",
+		FieldPaths:      map[string]string{"value": "value"},
+		TimestampPath:   "time",
+		TimestampFormat: "unix",
+		acc:             &acc,
+		log:             testutil.Logger{},
+	}
+	require.NoError(t, gw.Init())
+
+	resp := postWebhooks(gw, "{", nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postWebhooks(gw, `{"value": 42}`, nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestSignature(t *testing.T) {
+	var acc testutil.Accumulator
+	gw := &GenericWebhook{
+		Path:               "/events",
+		FieldPaths:         map[string]string{"value": "value"},
+		Secret:             "s3cr3t",
+		SignatureHeader:    "X-Hub-Signature",
+		SignatureAlgorithm: "sha1",
+		SignaturePrefix:    "sha1=",
+		acc:                &acc,
+		log:                testutil.Logger{},
+	}
+	require.NoError(t, gw.Init())
+
+	body := `{"value": 42}`
+	valid := signature.Sign(sha1.New, "s3cr3t", []byte(body))
+
+	resp := postWebhooks(gw, body, nil)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// Missing prefix
+	resp = postWebhooks(gw, body, http.Header{"X-Hub-Signature": []string{valid}})
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postWebhooks(gw, body, http.Header{"X-Hub-Signature": []string{"sha1=" + signature.Sign(sha1.New, "wrong", []byte(body))}})
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	resp = postWebhooks(gw, body, http.Header{"X-Hub-Signature": []string{"sha1=" + valid}})
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
@@ -0,0 +1,62 @@
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+)
+
+type GitlabWebhook struct {
+	Path   string `toml:"path"`
+	Secret string `toml:"secret"`
+	acc    telegraf.Accumulator
+	log    telegraf.Logger
+	auth.BasicAuth
+}
+
+func (gl *GitlabWebhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(gl.Path, gl.eventHandler).Methods("POST")
+	gl.log = log
+	gl.log.Infof("Started the webhooks_gitlab on %s", gl.Path)
+	gl.acc = acc
+}
+
+func (gl *GitlabWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !gl.Verify(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// GitLab does not sign the payload but sends the configured secret token
+	// verbatim in the request header.
+	if gl.Secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(gl.Secret)) != 1 {
+		gl.log.Error("Failed to check the gitlab webhook token")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil || e.ObjectKind == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	gl.acc.AddFields("gitlab_webhooks", e.Fields(), e.Tags(), time.Now())
+
+	w.WriteHeader(http.StatusOK)
+}
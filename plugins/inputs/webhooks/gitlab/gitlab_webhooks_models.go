@@ -0,0 +1,149 @@
+package gitlab
+
+import (
+	"strconv"
+)
+
+// Event holds the attributes of the GitLab webhook payloads persisted as
+// metrics, see https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html
+type Event struct {
+	ObjectKind   string  `json:"object_kind"`
+	UserUsername string  `json:"user_username"`
+	User         *User   `json:"user"`
+	Project      Project `json:"project"`
+
+	// Push and tag push events
+	Ref               string `json:"ref"`
+	TotalCommitsCount int64  `json:"total_commits_count"`
+
+	// Issue, merge request, note and pipeline events
+	ObjectAttributes *ObjectAttributes `json:"object_attributes"`
+
+	// Job events
+	BuildID       int64    `json:"build_id"`
+	BuildName     string   `json:"build_name"`
+	BuildStage    string   `json:"build_stage"`
+	BuildStatus   string   `json:"build_status"`
+	BuildDuration *float64 `json:"build_duration"`
+
+	// Deployment events
+	Status      string `json:"status"`
+	Environment string `json:"environment"`
+
+	// Release events, other events use "tag" as a boolean flag
+	Action string      `json:"action"`
+	Tag    interface{} `json:"tag"`
+}
+
+type User struct {
+	Username string `json:"username"`
+}
+
+type Project struct {
+	ID                int64  `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+type ObjectAttributes struct {
+	ID             int64    `json:"id"`
+	IID            int64    `json:"iid"`
+	State          string   `json:"state"`
+	Action         string   `json:"action"`
+	Status         string   `json:"status"`
+	Ref            string   `json:"ref"`
+	SourceBranch   string   `json:"source_branch"`
+	TargetBranch   string   `json:"target_branch"`
+	NoteableType   string   `json:"noteable_type"`
+	Duration       *float64 `json:"duration"`
+	QueuedDuration *float64 `json:"queued_duration"`
+}
+
+func (e *Event) user() string {
+	if e.UserUsername != "" {
+		return e.UserUsername
+	}
+	if e.User != nil {
+		return e.User.Username
+	}
+	return ""
+}
+
+func (e *Event) Tags() map[string]string {
+	tags := map[string]string{
+		"event":      e.ObjectKind,
+		"project":    e.Project.PathWithNamespace,
+		"project_id": strconv.FormatInt(e.Project.ID, 10),
+	}
+
+	switch e.ObjectKind {
+	case "push", "tag_push":
+		tags["ref"] = e.Ref
+	case "build":
+		tags["ref"] = e.Ref
+		tags["stage"] = e.BuildStage
+		tags["status"] = e.BuildStatus
+	case "deployment":
+		tags["environment"] = e.Environment
+		tags["status"] = e.Status
+	case "release":
+		tags["action"] = e.Action
+	}
+
+	if a := e.ObjectAttributes; a != nil {
+		switch e.ObjectKind {
+		case "issue", "merge_request":
+			tags["action"] = a.Action
+			tags["state"] = a.State
+		case "pipeline":
+			tags["ref"] = a.Ref
+			tags["status"] = a.Status
+		case "note":
+			tags["noteable_type"] = a.NoteableType
+		}
+	}
+
+	return tags
+}
+
+func (e *Event) Fields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	if user := e.user(); user != "" {
+		fields["user"] = user
+	}
+
+	switch e.ObjectKind {
+	case "push", "tag_push":
+		fields["commits"] = e.TotalCommitsCount
+	case "build":
+		fields["id"] = e.BuildID
+		fields["name"] = e.BuildName
+		if e.BuildDuration != nil {
+			fields["duration"] = *e.BuildDuration
+		}
+	case "release":
+		if tag, ok := e.Tag.(string); ok {
+			fields["tag"] = tag
+		}
+	}
+
+	if a := e.ObjectAttributes; a != nil {
+		fields["id"] = a.ID
+		switch e.ObjectKind {
+		case "issue", "merge_request":
+			fields["iid"] = a.IID
+			if e.ObjectKind == "merge_request" {
+				fields["source_branch"] = a.SourceBranch
+				fields["target_branch"] = a.TargetBranch
+			}
+		case "pipeline":
+			if a.Duration != nil {
+				fields["duration"] = *a.Duration
+			}
+			if a.QueuedDuration != nil {
+				fields["queued_duration"] = *a.QueuedDuration
+			}
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,178 @@
+package gitlab
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(gl *GitlabWebhook, body []byte, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/gitlab", bytes.NewReader(body))
+	if token != "" {
+		req.Header.Set("X-Gitlab-Token", token)
+	}
+	w := httptest.NewRecorder()
+
+	gl.eventHandler(w, req)
+
+	return w
+}
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected telegraf.Metric
+	}{
+		{
+			name: "push",
+			file: "testdata/push.json",
+			expected: testutil.MustMetric(
+				"gitlab_webhooks",
+				map[string]string{
+					"event":      "push",
+					"project":    "mike/diaspora",
+					"project_id": "15",
+					"ref":        "refs/heads/main",
+				},
+				map[string]interface{}{
+					"user":    "jsmith",
+					"commits": int64(4),
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "merge request",
+			file: "testdata/merge_request.json",
+			expected: testutil.MustMetric(
+				"gitlab_webhooks",
+				map[string]string{
+					"event":      "merge_request",
+					"project":    "gitlabhq/gitlab-test",
+					"project_id": "1",
+					"action":     "open",
+					"state":      "opened",
+				},
+				map[string]interface{}{
+					"user":          "root",
+					"id":            int64(99),
+					"iid":           int64(1),
+					"source_branch": "ms-viewport",
+					"target_branch": "master",
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "pipeline",
+			file: "testdata/pipeline.json",
+			expected: testutil.MustMetric(
+				"gitlab_webhooks",
+				map[string]string{
+					"event":      "pipeline",
+					"project":    "gitlab-org/gitlab-test",
+					"project_id": "1",
+					"ref":        "master",
+					"status":     "success",
+				},
+				map[string]interface{}{
+					"user":            "root",
+					"id":              int64(31),
+					"duration":        63.0,
+					"queued_duration": 12.0,
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "job",
+			file: "testdata/job.json",
+			expected: testutil.MustMetric(
+				"gitlab_webhooks",
+				map[string]string{
+					"event":      "build",
+					"project":    "gitlab-org/gitlab-test",
+					"project_id": "380",
+					"ref":        "main",
+					"stage":      "test",
+					"status":     "failed",
+				},
+				map[string]interface{}{
+					"user":     "user",
+					"id":       int64(1977),
+					"name":     "test",
+					"duration": 17.1,
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "release",
+			file: "testdata/release.json",
+			expected: testutil.MustMetric(
+				"gitlab_webhooks",
+				map[string]string{
+					"event":      "release",
+					"project":    "gitlab-org/release-webhook-example",
+					"project_id": "2",
+					"action":     "create",
+				},
+				map[string]interface{}{
+					"tag": "v1.1",
+				},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.ReadFile(tt.file)
+			require.NoError(t, err)
+
+			var acc testutil.Accumulator
+			gl := &GitlabWebhook{Path: "/gitlab", acc: &acc}
+			resp := postWebhooks(gl, body, "")
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	gl := &GitlabWebhook{Path: "/gitlab"}
+	resp := postWebhooks(gl, []byte("{"), "")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postWebhooks(gl, []byte(`{"foo": "bar"}`), "")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestToken(t *testing.T) {
+	body, err := os.ReadFile("testdata/push.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	gl := &GitlabWebhook{Path: "/gitlab", Secret: "s3cr3t", acc: &acc, log: testutil.Logger{}}
+
+	resp := postWebhooks(gl, body, "")
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = postWebhooks(gl, body, "wrong")
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	resp = postWebhooks(gl, body, "s3cr3t")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
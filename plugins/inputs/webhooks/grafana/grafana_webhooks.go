@@ -0,0 +1,92 @@
+package grafana
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/signature"
+)
+
+const defaultSignatureHeader = "X-Grafana-Alerting-Signature"
+
+type GrafanaWebhook struct {
+	Path            string `toml:"path"`
+	Secret          string `toml:"secret"`
+	SignatureHeader string `toml:"signature_header"`
+	TimestampHeader string `toml:"timestamp_header"`
+	acc             telegraf.Accumulator
+	log             telegraf.Logger
+	auth.BasicAuth
+}
+
+func (gf *GrafanaWebhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(gf.Path, gf.eventHandler).Methods("POST")
+	gf.log = log
+	gf.log.Infof("Started the webhooks_grafana on %s", gf.Path)
+	gf.acc = acc
+}
+
+func (gf *GrafanaWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !gf.Verify(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if gf.Secret != "" && !gf.checkSignature(r.Header, data) {
+		gf.log.Error("Failed to check the grafana webhook signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	for i := range msg.Alerts {
+		alert := &msg.Alerts[i]
+		tags := alert.Tags(msg.Receiver)
+		tags["org_id"] = strconv.FormatInt(msg.OrgID, 10)
+		gf.acc.AddFields("grafana_webhooks", alert.Fields(msg.GroupKey), tags, now)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// checkSignature verifies the HMAC-SHA256 signature of the payload. If a
+// timestamp header is configured, Grafana signs "<timestamp>:<body>" instead
+// of the plain body.
+func (gf *GrafanaWebhook) checkSignature(header http.Header, data []byte) bool {
+	name := gf.SignatureHeader
+	if name == "" {
+		name = defaultSignatureHeader
+	}
+
+	if gf.TimestampHeader == "" {
+		return signature.Verify(sha256.New, gf.Secret, header.Get(name), data)
+	}
+
+	timestamp := header.Get(gf.TimestampHeader)
+	if timestamp == "" {
+		return false
+	}
+	return signature.Verify(sha256.New, gf.Secret, header.Get(name), []byte(timestamp+":"), data)
+}
@@ -0,0 +1,38 @@
+package grafana
+
+import (
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/alertmanager"
+)
+
+// Message is the notification payload sent by the Grafana Alerting webhook
+// contact point. It extends the Alertmanager payload with Grafana specific
+// information.
+type Message struct {
+	alertmanager.Message
+	OrgID  int64   `json:"orgId"`
+	Title  string  `json:"title"`
+	State  string  `json:"state"`
+	Alerts []Alert `json:"alerts"`
+}
+
+// Alert is a single alert contained in the notification
+type Alert struct {
+	alertmanager.Alert
+	Values       map[string]float64 `json:"values"`
+	DashboardURL string             `json:"dashboardURL"`
+	PanelURL     string             `json:"panelURL"`
+}
+
+func (a *Alert) Fields(groupKey string) map[string]interface{} {
+	fields := a.Alert.Fields(groupKey)
+	for ref, v := range a.Values {
+		fields["value_"+ref] = v
+	}
+	if a.DashboardURL != "" {
+		fields["dashboard_url"] = a.DashboardURL
+	}
+	if a.PanelURL != "" {
+		fields["panel_url"] = a.PanelURL
+	}
+	return fields
+}
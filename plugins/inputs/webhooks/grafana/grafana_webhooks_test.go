@@ -0,0 +1,120 @@
+package grafana
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/signature"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(gf *GrafanaWebhook, body []byte, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/grafana", bytes.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+
+	gf.eventHandler(w, req)
+
+	return w
+}
+
+func TestAlerts(t *testing.T) {
+	body, err := os.ReadFile("testdata/alerts.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	gf := &GrafanaWebhook{Path: "/grafana", acc: &acc}
+	resp := postWebhooks(gf, body, nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"grafana_webhooks",
+			map[string]string{
+				"alertname":      "High memory usage",
+				"grafana_folder": "Infrastructure",
+				"team":           "blue",
+				"org_id":         "1",
+				"receiver":       "telegraf",
+				"status":         "firing",
+			},
+			map[string]interface{}{
+				"summary":       "Memory usage above 90%",
+				"fingerprint":   "c6eadffa33fcdf37",
+				"group_key":     `{}:{alertname="High memory usage"}`,
+				"generator_url": "https://grafana.example.com/alerting/grafana/abc123/view",
+				"dashboard_url": "https://grafana.example.com/d/node",
+				"starts_at":     time.Date(2023, 11, 20, 10, 15, 30, 0, time.UTC).UnixNano(),
+				"value_B":       93.5,
+				"value_C":       1.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseError(t *testing.T) {
+	gf := &GrafanaWebhook{Path: "/grafana"}
+	resp := postWebhooks(gf, []byte("{"), nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestSignature(t *testing.T) {
+	body, err := os.ReadFile("testdata/alerts.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	gf := &GrafanaWebhook{Path: "/grafana", Secret: "s3cr3t", acc: &acc, log: testutil.Logger{}}
+
+	resp := postWebhooks(gf, body, nil)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	header := http.Header{}
+	header.Set(defaultSignatureHeader, signature.Sign(sha256.New, "wrong", body))
+	resp = postWebhooks(gf, body, header)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	header.Set(defaultSignatureHeader, signature.Sign(sha256.New, "s3cr3t", body))
+	resp = postWebhooks(gf, body, header)
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestSignatureWithTimestamp(t *testing.T) {
+	body, err := os.ReadFile("testdata/alerts.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	gf := &GrafanaWebhook{
+		Path:            "/grafana",
+		Secret:          "s3cr3t",
+		SignatureHeader: "X-Signature",
+		TimestampHeader: "X-Timestamp",
+		acc:             &acc,
+		log:             testutil.Logger{},
+	}
+
+	// Signature over the plain body must be rejected
+	header := http.Header{}
+	header.Set("X-Timestamp", "1700475330")
+	header.Set("X-Signature", signature.Sign(sha256.New, "s3cr3t", body))
+	resp := postWebhooks(gf, body, header)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	header.Set("X-Signature", signature.Sign(sha256.New, "s3cr3t", []byte("1700475330:"), body))
+	resp = postWebhooks(gf, body, header)
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
@@ -0,0 +1,89 @@
+package pagerduty
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/auth"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/signature"
+)
+
+type PagerDutyWebhook struct {
+	Path   string `toml:"path"`
+	Secret string `toml:"secret"`
+	acc    telegraf.Accumulator
+	log    telegraf.Logger
+	auth.BasicAuth
+}
+
+func (pd *PagerDutyWebhook) Register(router *mux.Router, acc telegraf.Accumulator, log telegraf.Logger) {
+	router.HandleFunc(pd.Path, pd.eventHandler).Methods("POST")
+	pd.log = log
+	pd.log.Infof("Started the webhooks_pagerduty on %s", pd.Path)
+	pd.acc = acc
+}
+
+func (pd *PagerDutyWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !pd.Verify(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if pd.Secret != "" && !checkSignature(pd.Secret, data, r.Header.Get("X-PagerDuty-Signature")) {
+		pd.log.Error("Failed to check the pagerduty webhook signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// PagerDuty sends a "pagey.ping" event without data when testing the
+	// subscription, so only the event type is guaranteed to be present.
+	if msg.Event.EventType == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ts := msg.Event.OccurredAt
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	pd.acc.AddFields("pagerduty_webhooks", msg.Event.Fields(), msg.Event.Tags(), ts)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// checkSignature verifies the "X-PagerDuty-Signature" header. The header
+// contains a comma-separated list of "v1=<hex hmac-sha256>" entries, one per
+// active secret, to allow secret rotation.
+func checkSignature(secret string, data []byte, header string) bool {
+	for _, entry := range strings.Split(header, ",") {
+		version, sig, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || version != "v1" {
+			continue
+		}
+		if signature.Verify(sha256.New, secret, sig, data) {
+			return true
+		}
+	}
+	return false
+}
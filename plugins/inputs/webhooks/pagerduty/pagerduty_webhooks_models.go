@@ -0,0 +1,96 @@
+package pagerduty
+
+import (
+	"time"
+)
+
+// Message is the payload of a PagerDuty V3 webhook, see
+// https://developer.pagerduty.com/docs/webhooks/v3-overview/
+type Message struct {
+	Event Event `json:"event"`
+}
+
+type Event struct {
+	ID           string     `json:"id"`
+	EventType    string     `json:"event_type"`
+	ResourceType string     `json:"resource_type"`
+	OccurredAt   time.Time  `json:"occurred_at"`
+	Agent        *Reference `json:"agent"`
+	Data         Data       `json:"data"`
+}
+
+type Reference struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Summary string `json:"summary"`
+}
+
+type Data struct {
+	ID               string      `json:"id"`
+	Type             string      `json:"type"`
+	HTMLURL          string      `json:"html_url"`
+	Number           int64       `json:"number"`
+	Status           string      `json:"status"`
+	Title            string      `json:"title"`
+	Urgency          string      `json:"urgency"`
+	IncidentKey      string      `json:"incident_key"`
+	Service          *Reference  `json:"service"`
+	EscalationPolicy *Reference  `json:"escalation_policy"`
+	Priority         *Reference  `json:"priority"`
+	Incident         *Reference  `json:"incident"`
+	Assignees        []Reference `json:"assignees"`
+	Content          string      `json:"content"`
+}
+
+func (e *Event) Tags() map[string]string {
+	tags := map[string]string{
+		"event":         e.EventType,
+		"resource_type": e.ResourceType,
+	}
+	if e.Data.Service != nil {
+		tags["service"] = e.Data.Service.Summary
+	}
+	if e.Data.Priority != nil {
+		tags["priority"] = e.Data.Priority.Summary
+	}
+	if e.Data.EscalationPolicy != nil {
+		tags["escalation_policy"] = e.Data.EscalationPolicy.Summary
+	}
+	if e.Data.Status != "" {
+		tags["status"] = e.Data.Status
+	}
+	if e.Data.Urgency != "" {
+		tags["urgency"] = e.Data.Urgency
+	}
+	return tags
+}
+
+func (e *Event) Fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"id":          e.ID,
+		"resource_id": e.Data.ID,
+	}
+	if e.Agent != nil {
+		fields["agent"] = e.Agent.Summary
+	}
+	if e.Data.Number > 0 {
+		fields["incident_number"] = e.Data.Number
+	}
+	if e.Data.Title != "" {
+		fields["title"] = e.Data.Title
+	}
+	if e.Data.HTMLURL != "" {
+		fields["html_url"] = e.Data.HTMLURL
+	}
+	if e.Data.IncidentKey != "" {
+		fields["incident_key"] = e.Data.IncidentKey
+	}
+	// Sub-resources like notes or annotations refer to their incident
+	if e.Data.Incident != nil {
+		fields["incident_id"] = e.Data.Incident.ID
+	}
+	if len(e.Data.Assignees) > 0 {
+		fields["assignees"] = int64(len(e.Data.Assignees))
+	}
+	return fields
+}
@@ -0,0 +1,98 @@
+package pagerduty
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/signature"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func postWebhooks(pd *PagerDutyWebhook, body []byte, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/pagerduty", bytes.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+
+	pd.eventHandler(w, req)
+
+	return w
+}
+
+func TestIncidentTriggered(t *testing.T) {
+	body, err := os.ReadFile("testdata/incident_triggered.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	pd := &PagerDutyWebhook{Path: "/pagerduty", acc: &acc}
+	resp := postWebhooks(pd, body, nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"pagerduty_webhooks",
+			map[string]string{
+				"event":             "incident.triggered",
+				"resource_type":     "incident",
+				"service":           "API Service",
+				"priority":          "P1",
+				"escalation_policy": "Default",
+				"status":            "triggered",
+				"urgency":           "high",
+			},
+			map[string]interface{}{
+				"id":              "01DEN2KHJU5VUS6DGCQGFPH1WQ",
+				"resource_id":     "PGR0VU2",
+				"agent":           "Tenex Engineer",
+				"incident_number": int64(2),
+				"title":           "A little bump in the road",
+				"html_url":        "https://acme.pagerduty.com/incidents/PGR0VU2",
+				"incident_key":    "d3640fbd41094207a1c11e58e46b1662",
+				"assignees":       int64(1),
+			},
+			time.Date(2023, 11, 20, 10, 15, 30, 169000000, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestParseError(t *testing.T) {
+	pd := &PagerDutyWebhook{Path: "/pagerduty"}
+	resp := postWebhooks(pd, []byte("{"), nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = postWebhooks(pd, []byte(`{"messages": []}`), nil)
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestSignature(t *testing.T) {
+	body, err := os.ReadFile("testdata/incident_triggered.json")
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	pd := &PagerDutyWebhook{Path: "/pagerduty", Secret: "s3cr3t", acc: &acc, log: testutil.Logger{}}
+
+	resp := postWebhooks(pd, body, nil)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	header := http.Header{}
+	header.Set("X-PagerDuty-Signature", "v1="+signature.Sign(sha256.New, "wrong", body))
+	resp = postWebhooks(pd, body, header)
+	require.Equal(t, http.StatusUnauthorized, resp.Code)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Accept any of the signatures sent during secret rotation
+	header.Set("X-PagerDuty-Signature", "v1="+signature.Sign(sha256.New, "wrong", body)+",v1="+signature.Sign(sha256.New, "s3cr3t", body))
+	resp = postWebhooks(pd, body, header)
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
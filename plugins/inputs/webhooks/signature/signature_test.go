@@ -0,0 +1,30 @@
+package signature
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashFunc(t *testing.T) {
+	for _, algorithm := range []string{"", "sha1", "sha256", "sha512"} {
+		h, err := HashFunc(algorithm)
+		require.NoError(t, err, algorithm)
+		require.NotNil(t, h, algorithm)
+	}
+
+	_, err := HashFunc("md5")
+	require.ErrorContains(t, err, "unknown signature algorithm")
+}
+
+func TestSignAndVerify(t *testing.T) {
+	// Reference value computed with
+	// echo -n 'hello world' | openssl dgst -sha256 -hmac secret
+	expected := "734cc62f32841568f45715aeb9f4d7891324e6d948e4c6c60c0621cdac48623a"
+	require.Equal(t, expected, Sign(sha256.New, "secret", []byte("hello "), []byte("world")))
+
+	require.True(t, Verify(sha256.New, "secret", expected, []byte("hello world")))
+	require.False(t, Verify(sha256.New, "other", expected, []byte("hello world")))
+	require.False(t, Verify(sha256.New, "secret", "not-hex", []byte("hello world")))
+}
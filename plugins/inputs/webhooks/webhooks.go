@@ -14,10 +14,15 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/alertmanager"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/artifactory"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/filestack"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/generic"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/github"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/gitlab"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/grafana"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/mandrill"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/pagerduty"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/papertrail"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/particle"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/rollbar"
@@ -44,13 +49,18 @@ type Webhooks struct {
 	ReadTimeout    config.Duration `toml:"read_timeout"`
 	WriteTimeout   config.Duration `toml:"write_timeout"`
 
-	Github      *github.GithubWebhook           `toml:"github"`
-	Filestack   *filestack.FilestackWebhook     `toml:"filestack"`
-	Mandrill    *mandrill.MandrillWebhook       `toml:"mandrill"`
-	Rollbar     *rollbar.RollbarWebhook         `toml:"rollbar"`
-	Papertrail  *papertrail.PapertrailWebhook   `toml:"papertrail"`
-	Particle    *particle.ParticleWebhook       `toml:"particle"`
-	Artifactory *artifactory.ArtifactoryWebhook `toml:"artifactory"`
+	Github       *github.GithubWebhook             `toml:"github"`
+	Filestack    *filestack.FilestackWebhook       `toml:"filestack"`
+	Mandrill     *mandrill.MandrillWebhook         `toml:"mandrill"`
+	Rollbar      *rollbar.RollbarWebhook           `toml:"rollbar"`
+	Papertrail   *papertrail.PapertrailWebhook     `toml:"papertrail"`
+	Particle     *particle.ParticleWebhook         `toml:"particle"`
+	Artifactory  *artifactory.ArtifactoryWebhook   `toml:"artifactory"`
+	Gitlab       *gitlab.GitlabWebhook             `toml:"gitlab"`
+	Grafana      *grafana.GrafanaWebhook           `toml:"grafana"`
+	PagerDuty    *pagerduty.PagerDutyWebhook       `toml:"pagerduty"`
+	Alertmanager *alertmanager.AlertmanagerWebhook `toml:"alertmanager"`
+	Generic      []*generic.GenericWebhook         `toml:"generic"`
 
 	Log telegraf.Logger `toml:"-"`
 
@@ -65,11 +75,23 @@ func (*Webhooks) SampleConfig() string {
 	return sampleConfig
 }
 
+func (wb *Webhooks) Init() error {
+	for _, webhook := range wb.AvailableWebhooks() {
+		if initializer, ok := webhook.(telegraf.Initializer); ok {
+			if err := initializer.Init(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (wb *Webhooks) Gather(_ telegraf.Accumulator) error {
 	return nil
 }
 
-// AvailableWebhooks Looks for fields which implement Webhook interface
+// AvailableWebhooks Looks for fields which implement Webhook interface,
+// including slices of webhooks configured multiple times
 func (wb *Webhooks) AvailableWebhooks() []Webhook {
 	webhooks := make([]Webhook, 0)
 	s := reflect.ValueOf(wb).Elem()
@@ -80,6 +102,15 @@ func (wb *Webhooks) AvailableWebhooks() []Webhook {
 			continue
 		}
 
+		if f.Kind() == reflect.Slice {
+			for j := 0; j < f.Len(); j++ {
+				if wbPlugin, ok := f.Index(j).Interface().(Webhook); ok && !f.Index(j).IsNil() {
+					webhooks = append(webhooks, wbPlugin)
+				}
+			}
+			continue
+		}
+
 		if wbPlugin, ok := f.Interface().(Webhook); ok {
 			if !reflect.ValueOf(wbPlugin).IsNil() {
 				webhooks = append(webhooks, wbPlugin)
@@ -4,8 +4,15 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/alertmanager"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/artifactory"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/generic"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/github"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/gitlab"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/grafana"
+	"github.com/influxdata/telegraf/plugins/inputs/webhooks/pagerduty"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/papertrail"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/particle"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/rollbar"
@@ -47,4 +54,47 @@ func TestAvailableWebhooks(t *testing.T) {
 	if !reflect.DeepEqual(wb.AvailableWebhooks(), expected) {
 		t.Errorf("expected to be %v.\nGot %v", expected, wb.AvailableWebhooks())
 	}
+
+	wb.Gitlab = &gitlab.GitlabWebhook{Path: "/gitlab"}
+	expected = append(expected, wb.Gitlab)
+	if !reflect.DeepEqual(wb.AvailableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.AvailableWebhooks())
+	}
+
+	wb.Grafana = &grafana.GrafanaWebhook{Path: "/grafana"}
+	expected = append(expected, wb.Grafana)
+	if !reflect.DeepEqual(wb.AvailableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.AvailableWebhooks())
+	}
+
+	wb.PagerDuty = &pagerduty.PagerDutyWebhook{Path: "/pagerduty"}
+	expected = append(expected, wb.PagerDuty)
+	if !reflect.DeepEqual(wb.AvailableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.AvailableWebhooks())
+	}
+
+	wb.Alertmanager = &alertmanager.AlertmanagerWebhook{Path: "/alertmanager"}
+	expected = append(expected, wb.Alertmanager)
+	if !reflect.DeepEqual(wb.AvailableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.AvailableWebhooks())
+	}
+
+	wb.Generic = []*generic.GenericWebhook{{Path: "/deployments"}, {Path: "/builds"}}
+	expected = append(expected, wb.Generic[0], wb.Generic[1])
+	if !reflect.DeepEqual(wb.AvailableWebhooks(), expected) {
+		t.Errorf("expected to be %v.\nGot %v", expected, wb.AvailableWebhooks())
+	}
+}
+
+func TestInitGeneric(t *testing.T) {
+	wb := NewWebhooks()
+	wb.Generic = []*generic.GenericWebhook{
+		{Path: "/deployments", FieldPaths: map[string]string{"version": "version"}},
+		{Path: "/builds"},
+	}
+	require.ErrorContains(t, wb.Init(), `no field paths configured for generic webhook "/builds"`)
+
+	wb.Generic[1].FieldPaths = map[string]string{"duration": "duration"}
+	require.NoError(t, wb.Init())
+	require.Equal(t, "generic_webhooks", wb.Generic[0].MeasurementName)
 }
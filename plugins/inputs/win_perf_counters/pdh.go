@@ -273,6 +273,7 @@ var (
 	pdhGetCounterInfoW           *syscall.Proc
 	pdhGetRawCounterValue        *syscall.Proc
 	pdhGetRawCounterArrayW       *syscall.Proc
+	pdhRemoveCounter             *syscall.Proc
 )
 
 func init() {
@@ -293,6 +294,7 @@ func init() {
 	pdhGetCounterInfoW = libPdhDll.MustFindProc("PdhGetCounterInfoW")
 	pdhGetRawCounterValue = libPdhDll.MustFindProc("PdhGetRawCounterValue")
 	pdhGetRawCounterArrayW = libPdhDll.MustFindProc("PdhGetRawCounterArrayW")
+	pdhRemoveCounter = libPdhDll.MustFindProc("PdhRemoveCounter")
 }
 
 // PdhAddCounter adds the specified counter to the query. This is the internationalized version. Preferably, use the
@@ -367,6 +369,13 @@ func PdhAddEnglishCounter(hQuery pdhQueryHandle, szFullCounterPath string, dwUse
 	return uint32(ret)
 }
 
+// PdhRemoveCounter removes a counter from a query and closes the counter handle.
+func PdhRemoveCounter(hCounter pdhCounterHandle) uint32 {
+	ret, _, _ := pdhRemoveCounter.Call(uintptr(hCounter))
+
+	return uint32(ret)
+}
+
 // PdhCloseQuery closes all counters contained in the specified query, closes all handles related to the query,
 // and frees all memory associated with the query.
 func PdhCloseQuery(hQuery pdhQueryHandle) uint32 {
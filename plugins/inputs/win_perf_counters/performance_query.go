@@ -29,6 +29,7 @@ type PerformanceQuery interface {
 	Close() error
 	AddCounterToQuery(counterPath string) (pdhCounterHandle, error)
 	AddEnglishCounterToQuery(counterPath string) (pdhCounterHandle, error)
+	RemoveCounter(counterHandle pdhCounterHandle) error
 	GetCounterPath(counterHandle pdhCounterHandle) (string, error)
 	ExpandWildCardPath(counterPath string) ([]string, error)
 	GetFormattedCounterValueDouble(hCounter pdhCounterHandle) (float64, error)
@@ -127,6 +128,17 @@ func (m *PerformanceQueryImpl) AddEnglishCounterToQuery(counterPath string) (pdh
 	return counterHandle, nil
 }
 
+// RemoveCounter removes the counter from the query
+func (m *PerformanceQueryImpl) RemoveCounter(counterHandle pdhCounterHandle) error {
+	if m.query == 0 {
+		return errors.New("uninitialized query")
+	}
+	if ret := PdhRemoveCounter(counterHandle); ret != ErrorSuccess {
+		return NewPdhError(ret)
+	}
+	return nil
+}
+
 // GetCounterPath return counter information for given handle
 func (m *PerformanceQueryImpl) GetCounterPath(counterHandle pdhCounterHandle) (string, error) {
 	for buflen := initialBufferSize; buflen <= m.maxBufferSize; buflen *= 2 {
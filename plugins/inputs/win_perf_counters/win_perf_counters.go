@@ -29,6 +29,7 @@ type WinPerfCounters struct {
 	UsePerfCounterTime         bool
 	Object                     []perfObject
 	CountersRefreshInterval    config.Duration
+	InstancesRefreshInterval   config.Duration
 	UseWildcardsExpansion      bool
 	LocalizeWildcardsExpansion bool
 	IgnoredErrors              []string `toml:"IgnoredErrors"`
@@ -37,9 +38,11 @@ type WinPerfCounters struct {
 
 	Log telegraf.Logger
 
-	lastRefreshed time.Time
-	queryCreator  PerformanceQueryCreator
-	hostCounters  map[string]*hostCountersInfo
+	lastRefreshed          time.Time
+	lastInstancesRefreshed time.Time
+	queryCreator           PerformanceQueryCreator
+	hostCounters           map[string]*hostCountersInfo
+	wmiCounters            []*wmiCounter
 	// cached os.Hostname()
 	cachedHostname string
 }
@@ -52,6 +55,8 @@ type hostCountersInfo struct {
 	counters  []*counter
 	query     PerformanceQuery
 	timestamp time.Time
+	// counter paths with wildcards for refreshing the instances
+	wildcards []*wildcardCounter
 }
 
 type perfObject struct {
@@ -64,6 +69,7 @@ type perfObject struct {
 	FailOnMissing bool
 	IncludeTotal  bool
 	UseRawValues  bool
+	WMIClass      string
 }
 
 type counter struct {
@@ -76,6 +82,22 @@ type counter struct {
 	includeTotal  bool
 	useRawValue   bool
 	counterHandle pdhCounterHandle
+	// wildcard path the counter was expanded from and the expanded path
+	wildcard     *wildcardCounter
+	expandedPath string
+}
+
+// wildcardCounter holds a counter path with wildcards as configured to
+// re-expand it when refreshing the instances
+type wildcardCounter struct {
+	// localized counter path with wildcards
+	path         string
+	objectName   string
+	instance     string
+	counterName  string
+	measurement  string
+	includeTotal bool
+	useRawValue  bool
 }
 
 type instanceGrouping struct {
@@ -182,16 +204,30 @@ func newCounter(
 	includeTotal bool,
 	useRawValue bool,
 ) *counter {
-	measurementName := sanitizedChars.Replace(measurement)
-	if measurementName == "" {
-		measurementName = "win_perf_counters"
-	}
+	measurementName := sanitizeMeasurement(measurement)
 	newCounterName := sanitizedChars.Replace(counterName)
 	if useRawValue {
 		newCounterName += "_Raw"
 	}
-	return &counter{counterPath, computer, objectName, newCounterName, instance, measurementName,
-		includeTotal, useRawValue, counterHandle}
+	return &counter{
+		counterPath:   counterPath,
+		computer:      computer,
+		objectName:    objectName,
+		counter:       newCounterName,
+		instance:      instance,
+		measurement:   measurementName,
+		includeTotal:  includeTotal,
+		useRawValue:   useRawValue,
+		counterHandle: counterHandle,
+	}
+}
+
+func sanitizeMeasurement(measurement string) string {
+	name := sanitizedChars.Replace(measurement)
+	if name == "" {
+		return "win_perf_counters"
+	}
+	return name
 }
 
 //nolint:revive //argument-limit conditionally more arguments allowed
@@ -231,7 +267,6 @@ func (m *WinPerfCounters) AddItem(counterPath, computer, objectName, instance, c
 	}
 
 	if m.UseWildcardsExpansion {
-		origInstance := instance
 		counterPath, err = hostCounter.query.GetCounterPath(counterHandle)
 		if err != nil {
 			return err
@@ -246,74 +281,21 @@ func (m *WinPerfCounters) AddItem(counterPath, computer, objectName, instance, c
 			return err
 		}
 
+		wildcard := &wildcardCounter{
+			path:         counterPath,
+			objectName:   origObjectName,
+			instance:     instance,
+			counterName:  origCounterName,
+			measurement:  measurement,
+			includeTotal: includeTotal,
+			useRawValue:  useRawValue,
+		}
 		for _, counterPath := range counters {
-			_, err := hostCounter.query.AddCounterToQuery(counterPath)
-			if err != nil {
+			if err := m.addExpandedCounter(hostCounter, wildcard, counterPath); err != nil {
 				return err
 			}
-
-			computer, objectName, instance, counterName, err = extractCounterInfoFromCounterPath(counterPath)
-			if err != nil {
-				return err
-			}
-
-			var newItem *counter
-			if !m.LocalizeWildcardsExpansion {
-				// On localized installations of Windows, Telegraf
-				// should return English metrics, but
-				// ExpandWildCardPath returns localized counters. Undo
-				// that by using the original object and counter
-				// names, along with the expanded instance.
-
-				var newInstance string
-				if instance == "" {
-					newInstance = emptyInstance
-				} else {
-					newInstance = instance
-				}
-				counterPath = formatPath(computer, origObjectName, newInstance, origCounterName)
-				counterHandle, err = hostCounter.query.AddEnglishCounterToQuery(counterPath)
-				if err != nil {
-					return err
-				}
-				newItem = newCounter(
-					counterHandle,
-					counterPath,
-					computer,
-					origObjectName, instance,
-					origCounterName,
-					measurement,
-					includeTotal,
-					useRawValue,
-				)
-			} else {
-				counterHandle, err = hostCounter.query.AddCounterToQuery(counterPath)
-				if err != nil {
-					return err
-				}
-				newItem = newCounter(
-					counterHandle,
-					counterPath,
-					computer,
-					objectName,
-					instance,
-					counterName,
-					measurement,
-					includeTotal,
-					useRawValue,
-				)
-			}
-
-			if instance == "_Total" && origInstance == "*" && !includeTotal {
-				continue
-			}
-
-			hostCounter.counters = append(hostCounter.counters, newItem)
-
-			if m.PrintValid {
-				m.Log.Infof("Valid: %s", counterPath)
-			}
 		}
+		hostCounter.wildcards = append(hostCounter.wildcards, wildcard)
 	} else {
 		newItem := newCounter(
 			counterHandle,
@@ -335,6 +317,150 @@ func (m *WinPerfCounters) AddItem(counterPath, computer, objectName, instance, c
 	return nil
 }
 
+// addExpandedCounter adds a counter path resulting from the expansion of the
+// given wildcard path to the query of the host
+func (m *WinPerfCounters) addExpandedCounter(hostCounter *hostCountersInfo, wildcard *wildcardCounter, expandedPath string) error {
+	computer, objectName, instance, counterName, err := extractCounterInfoFromCounterPath(expandedPath)
+	if err != nil {
+		return err
+	}
+	if instance == "_Total" && wildcard.instance == "*" && !wildcard.includeTotal {
+		return nil
+	}
+
+	counterPath := expandedPath
+	var newItem *counter
+	if !m.LocalizeWildcardsExpansion {
+		// On localized installations of Windows, Telegraf
+		// should return English metrics, but
+		// ExpandWildCardPath returns localized counters. Undo
+		// that by using the original object and counter
+		// names, along with the expanded instance.
+
+		var newInstance string
+		if instance == "" {
+			newInstance = emptyInstance
+		} else {
+			newInstance = instance
+		}
+		counterPath = formatPath(computer, wildcard.objectName, newInstance, wildcard.counterName)
+		counterHandle, err := hostCounter.query.AddEnglishCounterToQuery(counterPath)
+		if err != nil {
+			return err
+		}
+		newItem = newCounter(
+			counterHandle,
+			counterPath,
+			computer,
+			wildcard.objectName,
+			instance,
+			wildcard.counterName,
+			wildcard.measurement,
+			wildcard.includeTotal,
+			wildcard.useRawValue,
+		)
+	} else {
+		counterHandle, err := hostCounter.query.AddCounterToQuery(counterPath)
+		if err != nil {
+			return err
+		}
+		newItem = newCounter(
+			counterHandle,
+			counterPath,
+			computer,
+			objectName,
+			instance,
+			counterName,
+			wildcard.measurement,
+			wildcard.includeTotal,
+			wildcard.useRawValue,
+		)
+	}
+	newItem.wildcard = wildcard
+	newItem.expandedPath = expandedPath
+
+	hostCounter.counters = append(hostCounter.counters, newItem)
+
+	if m.PrintValid {
+		m.Log.Infof("Valid: %s", counterPath)
+	}
+	return nil
+}
+
+// refreshInstances expands the counter paths with wildcards again and updates
+// the queries with the instances appeared or vanished since the last expansion.
+// In contrast to a full refresh the queries are kept, so the values of the
+// existing counters are not interrupted.
+func (m *WinPerfCounters) refreshInstances() error {
+	for _, hostCounter := range m.hostCounters {
+		for _, wildcard := range hostCounter.wildcards {
+			paths, err := hostCounter.query.ExpandWildCardPath(wildcard.path)
+			if err != nil {
+				return fmt.Errorf("expanding %q on %q failed: %w", wildcard.path, hostCounter.computer, err)
+			}
+			current := make(map[string]bool, len(paths))
+			for _, path := range paths {
+				current[path] = true
+			}
+
+			known := make(map[string]bool, len(paths))
+			counters := make([]*counter, 0, len(hostCounter.counters))
+			for _, c := range hostCounter.counters {
+				if c.wildcard != wildcard {
+					counters = append(counters, c)
+					continue
+				}
+				if !current[c.expandedPath] {
+					m.Log.Debugf("Removing vanished counter %q", c.counterPath)
+					if err := hostCounter.query.RemoveCounter(c.counterHandle); err != nil {
+						return fmt.Errorf("removing counter %q failed: %w", c.counterPath, err)
+					}
+					continue
+				}
+				known[c.expandedPath] = true
+				counters = append(counters, c)
+			}
+			hostCounter.counters = counters
+
+			for _, path := range paths {
+				if known[path] {
+					continue
+				}
+				m.Log.Debugf("Adding new counter %q", path)
+				if err := m.addExpandedCounter(hostCounter, wildcard, path); err != nil {
+					return fmt.Errorf("adding counter %q failed: %w", path, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// diagnoseMissingCounter checks which part of a counter path not available on
+// the computer is missing to help the user fixing the configuration. As the
+// wildcard expansion is used for the checks, the names must match the language
+// of the system.
+func diagnoseMissingCounter(query PerformanceQuery, computer, objectName, instance, counterName string) error {
+	exists := func(instance, counterName string) bool {
+		paths, err := query.ExpandWildCardPath(formatPath(computer, objectName, instance, counterName))
+		return err == nil && len(paths) > 0
+	}
+
+	anyInstance := "*"
+	if instance == emptyInstance {
+		anyInstance = emptyInstance
+	}
+	switch {
+	case !exists(anyInstance, "*"):
+		return fmt.Errorf("object %q does not exist", objectName)
+	case !exists(anyInstance, counterName):
+		return fmt.Errorf("counter %q does not exist in object %q", counterName, objectName)
+	case !exists(instance, counterName):
+		return fmt.Errorf("instance %q does not exist in object %q", instance, objectName)
+	}
+	return nil
+}
+
 const emptyInstance = "------"
 
 func formatPath(computer, objectName, instance, counter string) string {
@@ -383,7 +509,17 @@ func (m *WinPerfCounters) ParseConfig() error {
 					err := m.AddItem(counterPath, computer, objectName, instance, counter,
 						PerfObject.Measurement, PerfObject.IncludeTotal, PerfObject.UseRawValues)
 					if err != nil {
+						if PerfObject.WMIClass != "" && !strings.ContainsAny(counter, "*?") {
+							m.Log.Debugf("Counter %q not available, using WMI class %q instead: %v", counterPath, PerfObject.WMIClass, err)
+							m.addWMICounter(computer, PerfObject, instance, counter)
+							continue
+						}
 						if PerfObject.FailOnMissing || PerfObject.WarnOnMissing {
+							if hostCounter, ok := m.hostCounters[computer]; ok {
+								if reason := diagnoseMissingCounter(hostCounter.query, computer, objectName, instance, counter); reason != nil {
+									err = fmt.Errorf("%w: %w", err, reason)
+								}
+							}
 							m.Log.Errorf("Invalid counterPath %q: %s", counterPath, err.Error())
 						}
 						if PerfObject.FailOnMissing {
@@ -431,8 +567,15 @@ func (m *WinPerfCounters) Gather(acc telegraf.Accumulator) error {
 			}
 		}
 		m.lastRefreshed = time.Now()
+		m.lastInstancesRefreshed = m.lastRefreshed
 		// minimum time between collecting two samples
 		time.Sleep(time.Second)
+	} else if m.UseWildcardsExpansion && m.InstancesRefreshInterval > 0 &&
+		m.lastInstancesRefreshed.Add(time.Duration(m.InstancesRefreshInterval)).Before(time.Now()) {
+		if err := m.refreshInstances(); err != nil {
+			acc.AddError(m.checkError(err))
+		}
+		m.lastInstancesRefreshed = time.Now()
 	}
 
 	for _, hostCounterSet := range m.hostCounters {
@@ -463,6 +606,15 @@ func (m *WinPerfCounters) Gather(acc telegraf.Accumulator) error {
 			wg.Done()
 		}(hostCounterInfo)
 	}
+	for _, wmiCounterInfo := range m.wmiCounters {
+		wg.Add(1)
+		go func(wc *wmiCounter) {
+			defer wg.Done()
+			if err := m.gatherWMICounters(wc, acc); err != nil {
+				acc.AddError(fmt.Errorf("error during collecting data of WMI class %q on host %q: %w", wc.class, wc.computer, err))
+			}
+		}(wmiCounterInfo)
+	}
 
 	wg.Wait()
 	return nil
@@ -540,6 +692,7 @@ func (m *WinPerfCounters) cleanQueries() error {
 		}
 	}
 	m.hostCounters = nil
+	m.wmiCounters = nil
 	return nil
 }
 
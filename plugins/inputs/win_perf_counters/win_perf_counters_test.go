@@ -85,6 +85,18 @@ func (m *FakePerformanceQuery) AddEnglishCounterToQuery(counterPath string) (pdh
 	return 0, fmt.Errorf("in AddEnglishCounterToQuery: invalid counter path: %q", counterPath)
 }
 
+func (m *FakePerformanceQuery) RemoveCounter(counterHandle pdhCounterHandle) error {
+	if !m.openCalled {
+		return errors.New("in RemoveCounter: uninitialized query")
+	}
+	for _, counter := range m.counters {
+		if counter.handle == counterHandle {
+			return nil
+		}
+	}
+	return fmt.Errorf("in RemoveCounter: invalid handle: %q", counterHandle)
+}
+
 func (m *FakePerformanceQuery) GetCounterPath(counterHandle pdhCounterHandle) (string, error) {
 	for _, counter := range m.counters {
 		if counter.handle == counterHandle {
@@ -1539,6 +1551,118 @@ func TestGatherRefreshingWithExpansion(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGatherRefreshingInstances(t *testing.T) {
+	measurement := "test"
+	perfObjects := createPerfObject("", measurement, "O", []string{"*"}, []string{"C"}, true, false, false)
+	cps := []string{"\\O(*)\\C", "\\O(I1)\\C", "\\O(I2)\\C", "\\O(I3)\\C"}
+	fpm := &FakePerformanceQuery{
+		counters: createCounterMap(cps, []float64{0, 1.1, 1.2, 1.3}, []uint32{0, 0, 0, 0}),
+		expandPaths: map[string][]string{
+			"\\O(*)\\C": {"\\O(I1)\\C", "\\O(I2)\\C"},
+		},
+		vistaAndNewer: true,
+	}
+	m := WinPerfCounters{
+		Log:                   testutil.Logger{},
+		Object:                perfObjects,
+		UseWildcardsExpansion: true,
+		queryCreator: &FakePerformanceQueryCreator{
+			fakeQueries: map[string]*FakePerformanceQuery{"localhost": fpm},
+		},
+		CountersRefreshInterval:    config.Duration(time.Hour),
+		InstancesRefreshInterval:   config.Duration(time.Nanosecond),
+		LocalizeWildcardsExpansion: true,
+	}
+
+	var acc1 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc1))
+	require.Len(t, acc1.Metrics, 2)
+	acc1.AssertContainsTaggedFields(t, measurement, map[string]interface{}{"C": 1.1},
+		map[string]string{"instance": "I1", "objectname": "O", "source": hostname()})
+	acc1.AssertContainsTaggedFields(t, measurement, map[string]interface{}{"C": 1.2},
+		map[string]string{"instance": "I2", "objectname": "O", "source": hostname()})
+
+	// A new instance appeared and one vanished without a full refresh
+	fpm.expandPaths["\\O(*)\\C"] = []string{"\\O(I1)\\C", "\\O(I3)\\C"}
+
+	var acc2 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc2))
+	require.Empty(t, acc2.Errors)
+	require.Len(t, acc2.Metrics, 2)
+	acc2.AssertContainsTaggedFields(t, measurement, map[string]interface{}{"C": 1.1},
+		map[string]string{"instance": "I1", "objectname": "O", "source": hostname()})
+	acc2.AssertContainsTaggedFields(t, measurement, map[string]interface{}{"C": 1.3},
+		map[string]string{"instance": "I3", "objectname": "O", "source": hostname()})
+
+	counters, ok := m.hostCounters["localhost"]
+	require.True(t, ok)
+	require.Len(t, counters.counters, 2)
+	require.NoError(t, m.cleanQueries())
+}
+
+func TestDiagnoseMissingCounter(t *testing.T) {
+	fpm := &FakePerformanceQuery{
+		expandPaths: map[string][]string{
+			"\\O(*)\\*":   {"\\O(I1)\\C1", "\\O(I1)\\C2"},
+			"\\O(*)\\C1":  {"\\O(I1)\\C1"},
+			"\\O(I1)\\C1": {"\\O(I1)\\C1"},
+			"\\M\\*":      {"\\M\\C1"},
+		},
+	}
+
+	require.ErrorContains(t, diagnoseMissingCounter(fpm, "localhost", "X", "I1", "C1"), `object "X" does not exist`)
+	require.ErrorContains(t, diagnoseMissingCounter(fpm, "localhost", "O", "I1", "C3"), `counter "C3" does not exist in object "O"`)
+	require.ErrorContains(t, diagnoseMissingCounter(fpm, "localhost", "O", "I2", "C1"), `instance "I2" does not exist in object "O"`)
+	require.ErrorContains(t, diagnoseMissingCounter(fpm, "localhost", "M", emptyInstance, "C2"), `counter "C2" does not exist in object "M"`)
+	require.NoError(t, diagnoseMissingCounter(fpm, "localhost", "O", "I1", "C1"))
+}
+
+func TestParseConfigWMIFallback(t *testing.T) {
+	perfObjects := createPerfObject("", "m", "O", []string{"I1", "I2"}, []string{"C1", "C2"}, true, false, false)
+	perfObjects[0].WMIClass = "Win32_PerfFormattedData_O"
+	cps := []string{"\\O(I1)\\C1", "\\O(I1)\\C2"}
+	m := WinPerfCounters{
+		Log:    testutil.Logger{},
+		Object: perfObjects,
+		queryCreator: &FakePerformanceQueryCreator{
+			fakeQueries: map[string]*FakePerformanceQuery{"localhost": {
+				counters:      createCounterMap(cps, []float64{1.1, 1.2}, []uint32{0, 0}),
+				vistaAndNewer: true,
+			}},
+		},
+	}
+
+	require.NoError(t, m.ParseConfig())
+	require.Len(t, m.hostCounters["localhost"].counters, 2)
+	require.Len(t, m.wmiCounters, 1)
+	require.Equal(t, "Win32_PerfFormattedData_O", m.wmiCounters[0].class)
+	require.Equal(t, "I2", m.wmiCounters[0].instance)
+	require.Equal(t, []string{"C1", "C2"}, m.wmiCounters[0].counters)
+
+	require.NoError(t, m.cleanQueries())
+	require.Empty(t, m.wmiCounters)
+}
+
+func TestWMIPropertyName(t *testing.T) {
+	require.Equal(t, "PercentProcessorTime", wmiPropertyName("% Processor Time"))
+	require.Equal(t, "BytesSentPersec", wmiPropertyName("Bytes Sent/sec"))
+	require.Equal(t, "AvgDisksecPerRead", wmiPropertyName("Avg. Disk sec/Read"))
+	require.Equal(t, "CurrentConnections", wmiPropertyName("Current Connections"))
+}
+
+func TestWMIValue(t *testing.T) {
+	v, ok := wmiValue("18446744073709", false)
+	require.True(t, ok)
+	require.InDelta(t, 18446744073709.0, v, 0)
+
+	v, ok = wmiValue(uint32(42), true)
+	require.True(t, ok)
+	require.Equal(t, int64(42), v)
+
+	_, ok = wmiValue(nil, false)
+	require.False(t, ok)
+}
+
 func TestGatherRefreshingWithoutExpansion(t *testing.T) {
 	var err error
 	if testing.Short() {
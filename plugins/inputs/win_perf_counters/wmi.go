@@ -0,0 +1,250 @@
+//go:build windows
+
+package win_perf_counters
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+
+	ole "github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+
+	"github.com/influxdata/telegraf"
+)
+
+// S_FALSE is returned by CoInitializeEx if it was already called on this thread.
+const sFalse = 0x00000001
+
+// wmiCounter holds the counters of an object instance not available via PDH
+// which are queried from the corresponding WMI performance class instead
+type wmiCounter struct {
+	computer     string
+	tag          string
+	class        string
+	objectName   string
+	instance     string
+	measurement  string
+	includeTotal bool
+	useRawValue  bool
+	counters     []string
+}
+
+func (m *WinPerfCounters) addWMICounter(computer string, object perfObject, instance, counterName string) {
+	for _, wc := range m.wmiCounters {
+		if wc.computer == computer && wc.class == object.WMIClass && wc.instance == instance && wc.measurement == object.Measurement {
+			wc.counters = append(wc.counters, counterName)
+			return
+		}
+	}
+
+	tag := computer
+	if computer == "localhost" {
+		tag = m.hostname()
+	}
+	m.wmiCounters = append(m.wmiCounters, &wmiCounter{
+		computer:     computer,
+		tag:          tag,
+		class:        object.WMIClass,
+		objectName:   object.ObjectName,
+		instance:     instance,
+		measurement:  object.Measurement,
+		includeTotal: object.IncludeTotal,
+		useRawValue:  object.UseRawValues,
+		counters:     []string{counterName},
+	})
+}
+
+func (m *WinPerfCounters) gatherWMICounters(wc *wmiCounter, acc telegraf.Accumulator) error {
+	properties := make([]string, 0, len(wc.counters))
+	for _, c := range wc.counters {
+		properties = append(properties, wmiPropertyName(c))
+	}
+	rows, err := queryWMI(wc.computer, wc.class, properties)
+	if err != nil {
+		return err
+	}
+
+	measurement := sanitizeMeasurement(wc.measurement)
+	for _, row := range rows {
+		instance, _ := row["Name"].(string)
+		switch wc.instance {
+		case "*":
+			if !wc.includeTotal && strings.Contains(instance, "_Total") {
+				continue
+			}
+		case emptyInstance:
+		default:
+			if instance != wc.instance {
+				continue
+			}
+		}
+
+		fields := make(map[string]interface{}, len(wc.counters))
+		for i, c := range wc.counters {
+			value, ok := wmiValue(row[properties[i]], wc.useRawValue)
+			if !ok {
+				m.Log.Debugf("Skipping property %q of WMI class %q with value %v", properties[i], wc.class, row[properties[i]])
+				continue
+			}
+			name := sanitizedChars.Replace(c)
+			if wc.useRawValue {
+				name += "_Raw"
+			}
+			fields[name] = value
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		tags := map[string]string{
+			"objectname": wc.objectName,
+		}
+		if len(instance) > 0 {
+			tags["instance"] = instance
+		}
+		if len(wc.tag) > 0 {
+			tags["source"] = wc.tag
+		}
+		acc.AddFields(measurement, fields, tags)
+	}
+	return nil
+}
+
+// wmiPropertyName converts the name of a performance counter to the name of
+// the property in the WMI performance classes, e.g. "% Processor Time" is
+// available as "PercentProcessorTime" and "Bytes Sent/sec" as "BytesSentPersec"
+func wmiPropertyName(counterName string) string {
+	var sb strings.Builder
+	for _, r := range counterName {
+		switch {
+		case r == '%':
+			sb.WriteString("Percent")
+		case r == '/':
+			sb.WriteString("Per")
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// wmiValue converts the value of a WMI property to a field value matching the
+// ones returned by PDH. Note that WMI returns 64-bit integers as strings.
+func wmiValue(v interface{}, raw bool) (interface{}, bool) {
+	var value float64
+	switch v := v.(type) {
+	case int32:
+		value = float64(v)
+	case int64:
+		value = float64(v)
+	case uint8:
+		value = float64(v)
+	case uint32:
+		value = float64(v)
+	case float32:
+		value = float64(v)
+	case float64:
+		value = v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, false
+		}
+		value = f
+	default:
+		return nil, false
+	}
+	if raw {
+		return int64(value), true
+	}
+	return value, true
+}
+
+// queryWMI returns the given properties and the name of all instances of the
+// WMI class on the computer
+func queryWMI(computer, class string, properties []string) ([]map[string]interface{}, error) {
+	// COM has to be initialized for the current OS thread, so make sure the
+	// goroutine is not moved to another thread during the query
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != sFalse {
+			return nil, err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	unknown, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return nil, err
+	}
+	if unknown == nil {
+		return nil, errors.New("failed to create WbemScripting.SWbemLocator, maybe WMI is broken")
+	}
+	defer unknown.Release()
+
+	wmi, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to QueryInterface: %w", err)
+	}
+	defer wmi.Release()
+
+	server := computer
+	if computer == "localhost" {
+		server = "."
+	}
+	serviceRaw, err := oleutil.CallMethod(wmi, "ConnectServer", server, `root\cimv2`)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ConnectServer: %w", err)
+	}
+	service := serviceRaw.ToIDispatch()
+	defer serviceRaw.Clear()
+
+	resultRaw, err := oleutil.CallMethod(service, "ExecQuery", "SELECT * FROM "+class)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ExecQuery: %w", err)
+	}
+	result := resultRaw.ToIDispatch()
+	defer resultRaw.Clear()
+
+	countRaw, err := oleutil.GetProperty(result, "Count")
+	if err != nil {
+		return nil, fmt.Errorf("failed getting Count: %w", err)
+	}
+	count := countRaw.Val
+	countRaw.Clear()
+
+	rows := make([]map[string]interface{}, 0, count)
+	for i := int64(0); i < count; i++ {
+		itemRaw, err := oleutil.CallMethod(result, "ItemIndex", i)
+		if err != nil {
+			return nil, fmt.Errorf("failed calling method ItemIndex: %w", err)
+		}
+		item := itemRaw.ToIDispatch()
+
+		row := make(map[string]interface{}, len(properties)+1)
+		// Classes of single instance objects have no name
+		if prop, err := oleutil.GetProperty(item, "Name"); err == nil {
+			row["Name"] = prop.Value()
+			prop.Clear()
+		}
+		for _, name := range properties {
+			prop, err := oleutil.GetProperty(item, name)
+			if err != nil {
+				item.Release()
+				return nil, fmt.Errorf("failed getting property %q: %w", name, err)
+			}
+			row[name] = prop.Value()
+			prop.Clear()
+		}
+		item.Release()
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
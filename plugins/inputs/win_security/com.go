@@ -0,0 +1,306 @@
+//go:build windows
+
+package win_security
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"time"
+
+	ole "github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+)
+
+// S_FALSE is returned by CoInitializeEx if it was already called on this thread.
+const sFalse = 0x00000001
+
+const defenderNamespace = `root\Microsoft\Windows\Defender`
+
+// comReader queries the status of Windows Defender via WMI and the status of
+// Windows Update via the Windows Update Agent API
+type comReader struct{}
+
+func (*comReader) defender() (*defenderStatus, error) {
+	var status *defenderStatus
+	err := withCOM(func() error {
+		rows, err := queryWMI(defenderNamespace, "SELECT * FROM MSFT_MpComputerStatus", []string{
+			"AntivirusEnabled", "AntispywareEnabled", "RealTimeProtectionEnabled", "BehaviorMonitorEnabled",
+			"AMServiceEnabled", "IsTamperProtected", "AntivirusSignatureVersion", "AntivirusSignatureAge",
+			"AntispywareSignatureAge", "AntivirusSignatureLastUpdated", "QuickScanAge", "FullScanAge",
+			"QuickScanEndTime", "FullScanEndTime",
+		})
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return errors.New("no computer status available, is Windows Defender installed?")
+		}
+		row := rows[0]
+
+		signatureVersion, _ := row["AntivirusSignatureVersion"].(string)
+		status = &defenderStatus{
+			antivirusEnabled:          toBool(row["AntivirusEnabled"]),
+			antispywareEnabled:        toBool(row["AntispywareEnabled"]),
+			realtimeProtectionEnabled: toBool(row["RealTimeProtectionEnabled"]),
+			behaviorMonitorEnabled:    toBool(row["BehaviorMonitorEnabled"]),
+			serviceEnabled:            toBool(row["AMServiceEnabled"]),
+			tamperProtected:           toBool(row["IsTamperProtected"]),
+			signatureVersion:          signatureVersion,
+			antivirusSignatureAge:     toAge(row["AntivirusSignatureAge"]),
+			antispywareSignatureAge:   toAge(row["AntispywareSignatureAge"]),
+			signatureLastUpdated:      toTime(row["AntivirusSignatureLastUpdated"]),
+			quickScanAge:              toAge(row["QuickScanAge"]),
+			fullScanAge:               toAge(row["FullScanAge"]),
+			quickScanEndTime:          toTime(row["QuickScanEndTime"]),
+			fullScanEndTime:           toTime(row["FullScanEndTime"]),
+		}
+
+		threats, err := queryWMI(defenderNamespace, "SELECT * FROM MSFT_MpThreat", []string{"IsActive"})
+		if err != nil {
+			return fmt.Errorf("querying threats failed: %w", err)
+		}
+		status.threats = int64(len(threats))
+		for _, threat := range threats {
+			if toBool(threat["IsActive"]) {
+				status.activeThreats++
+			}
+		}
+		return nil
+	})
+	return status, err
+}
+
+func (*comReader) update(online bool) (*updateStatus, error) {
+	status := &updateStatus{pendingBySeverity: make(map[string]int64)}
+	err := withCOM(func() error {
+		session, err := createObject("Microsoft.Update.Session")
+		if err != nil {
+			return err
+		}
+		defer session.Release()
+
+		searcherRaw, err := oleutil.CallMethod(session, "CreateUpdateSearcher")
+		if err != nil {
+			return fmt.Errorf("creating update searcher failed: %w", err)
+		}
+		searcher := searcherRaw.ToIDispatch()
+		defer searcherRaw.Clear()
+
+		if _, err := oleutil.PutProperty(searcher, "Online", online); err != nil {
+			return fmt.Errorf("setting online mode failed: %w", err)
+		}
+		resultRaw, err := oleutil.CallMethod(searcher, "Search", "IsInstalled=0 and IsHidden=0")
+		if err != nil {
+			return fmt.Errorf("searching updates failed: %w", err)
+		}
+		result := resultRaw.ToIDispatch()
+		defer resultRaw.Clear()
+
+		updatesRaw, err := oleutil.GetProperty(result, "Updates")
+		if err != nil {
+			return fmt.Errorf("getting updates failed: %w", err)
+		}
+		updates := updatesRaw.ToIDispatch()
+		defer updatesRaw.Clear()
+
+		countRaw, err := oleutil.GetProperty(updates, "Count")
+		if err != nil {
+			return fmt.Errorf("getting update count failed: %w", err)
+		}
+		status.pending = countRaw.Val
+		countRaw.Clear()
+
+		for i := int64(0); i < status.pending; i++ {
+			itemRaw, err := oleutil.GetProperty(updates, "Item", i)
+			if err != nil {
+				return fmt.Errorf("getting update %d failed: %w", i, err)
+			}
+			severity, err := oleutil.GetProperty(itemRaw.ToIDispatch(), "MsrcSeverity")
+			if err == nil {
+				// The severity is null for updates not related to security
+				if s, ok := severity.Value().(string); ok && s != "" {
+					status.pendingBySeverity[s]++
+				}
+				severity.Clear()
+			}
+			itemRaw.Clear()
+		}
+
+		systemInfo, err := createObject("Microsoft.Update.SystemInfo")
+		if err != nil {
+			return err
+		}
+		defer systemInfo.Release()
+		rebootRaw, err := oleutil.GetProperty(systemInfo, "RebootRequired")
+		if err != nil {
+			return fmt.Errorf("getting reboot state failed: %w", err)
+		}
+		status.rebootRequired = toBool(rebootRaw.Value())
+		rebootRaw.Clear()
+
+		autoUpdate, err := createObject("Microsoft.Update.AutoUpdate")
+		if err != nil {
+			return err
+		}
+		defer autoUpdate.Release()
+		resultsRaw, err := oleutil.GetProperty(autoUpdate, "Results")
+		if err != nil {
+			return fmt.Errorf("getting update results failed: %w", err)
+		}
+		results := resultsRaw.ToIDispatch()
+		defer resultsRaw.Clear()
+		if v, err := oleutil.GetProperty(results, "LastSearchSuccessDate"); err == nil {
+			status.lastSearchSuccess, _ = v.Value().(time.Time)
+			v.Clear()
+		}
+		if v, err := oleutil.GetProperty(results, "LastInstallationSuccessDate"); err == nil {
+			status.lastInstallSuccess, _ = v.Value().(time.Time)
+			v.Clear()
+		}
+		return nil
+	})
+	return status, err
+}
+
+// withCOM runs the function with COM initialized. The goroutine is locked to
+// the current OS thread as COM is initialized per thread.
+func withCOM(f func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleCode *ole.OleError
+		if errors.As(err, &oleCode) && oleCode.Code() != ole.S_OK && oleCode.Code() != sFalse {
+			return err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	return f()
+}
+
+func createObject(name string) (*ole.IDispatch, error) {
+	unknown, err := oleutil.CreateObject(name)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s failed: %w", name, err)
+	}
+	defer unknown.Release()
+
+	obj, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to QueryInterface of %s: %w", name, err)
+	}
+	return obj, nil
+}
+
+// queryWMI returns the given properties of all results of the query
+func queryWMI(namespace, query string, properties []string) ([]map[string]interface{}, error) {
+	locator, err := createObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return nil, err
+	}
+	defer locator.Release()
+
+	serviceRaw, err := oleutil.CallMethod(locator, "ConnectServer", nil, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ConnectServer: %w", err)
+	}
+	service := serviceRaw.ToIDispatch()
+	defer serviceRaw.Clear()
+
+	resultRaw, err := oleutil.CallMethod(service, "ExecQuery", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling method ExecQuery for query %s: %w", query, err)
+	}
+	result := resultRaw.ToIDispatch()
+	defer resultRaw.Clear()
+
+	countRaw, err := oleutil.GetProperty(result, "Count")
+	if err != nil {
+		return nil, fmt.Errorf("failed getting Count: %w", err)
+	}
+	count := countRaw.Val
+	countRaw.Clear()
+
+	rows := make([]map[string]interface{}, 0, count)
+	for i := int64(0); i < count; i++ {
+		itemRaw, err := oleutil.CallMethod(result, "ItemIndex", i)
+		if err != nil {
+			return nil, fmt.Errorf("failed calling method ItemIndex: %w", err)
+		}
+		item := itemRaw.ToIDispatch()
+
+		row := make(map[string]interface{}, len(properties))
+		for _, name := range properties {
+			// Properties might not exist in older versions
+			if prop, err := oleutil.GetProperty(item, name); err == nil {
+				row[name] = prop.Value()
+				prop.Clear()
+			}
+		}
+		itemRaw.Clear()
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func toBool(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// toAge converts an age in days, reporting -1 if the age is unknown, e.g. if
+// no scan was performed yet
+func toAge(v interface{}) int64 {
+	var age int64
+	switch v := v.(type) {
+	case int32:
+		age = int64(v)
+	case uint32:
+		age = int64(v)
+	case int64:
+		age = v
+	case string:
+		var err error
+		if age, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return -1
+		}
+	default:
+		return -1
+	}
+	if age < 0 || age >= math.MaxUint32 {
+		return -1
+	}
+	return age
+}
+
+// toTime converts WMI datetime values like "20231215093012.000000+060"
+// where the suffix is the UTC offset in minutes
+func toTime(v interface{}) time.Time {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}
+	}
+	t, err := parseCIMDateTime(s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func parseCIMDateTime(s string) (time.Time, error) {
+	if len(s) != 25 || (s[21] != '+' && s[21] != '-') {
+		return time.Time{}, fmt.Errorf("invalid datetime %q", s)
+	}
+	offset, err := strconv.Atoi(s[22:])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid offset in datetime %q: %w", s, err)
+	}
+	if s[21] == '-' {
+		offset = -offset
+	}
+	return time.ParseInLocation("20060102150405.000000", s[:21], time.FixedZone("", offset*60))
+}
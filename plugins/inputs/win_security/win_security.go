@@ -0,0 +1,169 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build windows
+
+package win_security
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type WinSecurity struct {
+	Include              []string        `toml:"include"`
+	UpdateSearchInterval config.Duration `toml:"update_search_interval"`
+	UpdateSearchOnline   bool            `toml:"update_search_online"`
+	Log                  telegraf.Logger `toml:"-"`
+
+	reader     statusReader
+	update     *updateStatus
+	lastSearch time.Time
+}
+
+// statusReader queries the status from the Windows APIs
+type statusReader interface {
+	defender() (*defenderStatus, error)
+	update(online bool) (*updateStatus, error)
+}
+
+type defenderStatus struct {
+	antivirusEnabled          bool
+	antispywareEnabled        bool
+	realtimeProtectionEnabled bool
+	behaviorMonitorEnabled    bool
+	serviceEnabled            bool
+	tamperProtected           bool
+	signatureVersion          string
+	antivirusSignatureAge     int64
+	antispywareSignatureAge   int64
+	signatureLastUpdated      time.Time
+	quickScanAge              int64
+	fullScanAge               int64
+	quickScanEndTime          time.Time
+	fullScanEndTime           time.Time
+	threats                   int64
+	activeThreats             int64
+}
+
+type updateStatus struct {
+	pending            int64
+	pendingBySeverity  map[string]int64
+	rebootRequired     bool
+	lastSearchSuccess  time.Time
+	lastInstallSuccess time.Time
+}
+
+func (*WinSecurity) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinSecurity) Init() error {
+	if len(w.Include) == 0 {
+		w.Include = []string{"defender", "update"}
+	}
+	if err := choice.CheckSlice(w.Include, []string{"defender", "update"}); err != nil {
+		return fmt.Errorf("invalid include: %w", err)
+	}
+
+	if w.reader == nil {
+		w.reader = &comReader{}
+	}
+	return nil
+}
+
+func (w *WinSecurity) Gather(acc telegraf.Accumulator) error {
+	for _, include := range w.Include {
+		var err error
+		switch include {
+		case "defender":
+			err = w.gatherDefender(acc)
+		case "update":
+			err = w.gatherUpdate(acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying %s status failed: %w", include, err))
+		}
+	}
+	return nil
+}
+
+func (w *WinSecurity) gatherDefender(acc telegraf.Accumulator) error {
+	status, err := w.reader.defender()
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"antivirus_enabled":           status.antivirusEnabled,
+		"antispyware_enabled":         status.antispywareEnabled,
+		"realtime_protection_enabled": status.realtimeProtectionEnabled,
+		"behavior_monitor_enabled":    status.behaviorMonitorEnabled,
+		"service_enabled":             status.serviceEnabled,
+		"tamper_protected":            status.tamperProtected,
+		"signature_version":           status.signatureVersion,
+		"antivirus_signature_age":     status.antivirusSignatureAge,
+		"antispyware_signature_age":   status.antispywareSignatureAge,
+		"quick_scan_age":              status.quickScanAge,
+		"full_scan_age":               status.fullScanAge,
+		"threats":                     status.threats,
+		"active_threats":              status.activeThreats,
+	}
+	// Times are unset if e.g. no scan was performed yet
+	for name, t := range map[string]time.Time{
+		"signature_last_updated": status.signatureLastUpdated,
+		"quick_scan_end_time":    status.quickScanEndTime,
+		"full_scan_end_time":     status.fullScanEndTime,
+	} {
+		if !t.IsZero() {
+			fields[name] = t.Unix()
+		}
+	}
+	acc.AddFields("win_defender", fields, nil)
+	return nil
+}
+
+func (w *WinSecurity) gatherUpdate(acc telegraf.Accumulator) error {
+	if w.update == nil || time.Since(w.lastSearch) >= time.Duration(w.UpdateSearchInterval) {
+		start := time.Now()
+		status, err := w.reader.update(w.UpdateSearchOnline)
+		if err != nil {
+			return err
+		}
+		w.Log.Debugf("Searching for updates took %v", time.Since(start))
+		w.update = status
+		w.lastSearch = start
+	}
+
+	fields := map[string]interface{}{
+		"pending_updates":   w.update.pending,
+		"pending_critical":  w.update.pendingBySeverity["Critical"],
+		"pending_important": w.update.pendingBySeverity["Important"],
+		"pending_moderate":  w.update.pendingBySeverity["Moderate"],
+		"pending_low":       w.update.pendingBySeverity["Low"],
+		"reboot_required":   w.update.rebootRequired,
+	}
+	if !w.update.lastSearchSuccess.IsZero() {
+		fields["last_search_success"] = w.update.lastSearchSuccess.Unix()
+	}
+	if !w.update.lastInstallSuccess.IsZero() {
+		fields["last_install_success"] = w.update.lastInstallSuccess.Unix()
+	}
+	acc.AddFields("win_update", fields, nil)
+	return nil
+}
+
+func init() {
+	inputs.Add("win_security", func() telegraf.Input {
+		return &WinSecurity{
+			UpdateSearchInterval: config.Duration(time.Hour),
+		}
+	})
+}
@@ -0,0 +1,31 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !windows
+
+package win_security
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type WinSecurity struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (w *WinSecurity) Init() error {
+	w.Log.Warn("current platform is not supported")
+	return nil
+}
+func (w *WinSecurity) SampleConfig() string                { return sampleConfig }
+func (w *WinSecurity) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("win_security", func() telegraf.Input {
+		return &WinSecurity{}
+	})
+}
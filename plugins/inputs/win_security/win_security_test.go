@@ -0,0 +1,156 @@
+//go:build windows
+
+package win_security
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeReader struct {
+	defenderStatus *defenderStatus
+	updateStatus   *updateStatus
+	defenderErr    error
+	searches       int
+}
+
+func (f *fakeReader) defender() (*defenderStatus, error) {
+	return f.defenderStatus, f.defenderErr
+}
+
+func (f *fakeReader) update(bool) (*updateStatus, error) {
+	f.searches++
+	return f.updateStatus, nil
+}
+
+func TestGather(t *testing.T) {
+	reader := &fakeReader{
+		defenderStatus: &defenderStatus{
+			antivirusEnabled:          true,
+			antispywareEnabled:        true,
+			realtimeProtectionEnabled: true,
+			serviceEnabled:            true,
+			signatureVersion:          "1.403.1234.0",
+			antivirusSignatureAge:     2,
+			antispywareSignatureAge:   2,
+			signatureLastUpdated:      time.Unix(1702632612, 0),
+			quickScanAge:              0,
+			fullScanAge:               -1,
+			quickScanEndTime:          time.Unix(1702636212, 0),
+			threats:                   3,
+			activeThreats:             1,
+		},
+		updateStatus: &updateStatus{
+			pending:            4,
+			pendingBySeverity:  map[string]int64{"Critical": 1, "Important": 2},
+			rebootRequired:     true,
+			lastSearchSuccess:  time.Unix(1702639812, 0),
+			lastInstallSuccess: time.Unix(1702000000, 0),
+		},
+	}
+	plugin := &WinSecurity{
+		UpdateSearchInterval: config.Duration(time.Hour),
+		Log:                  testutil.Logger{},
+		reader:               reader,
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_defender",
+			map[string]string{},
+			map[string]interface{}{
+				"antivirus_enabled":           true,
+				"antispyware_enabled":         true,
+				"realtime_protection_enabled": true,
+				"behavior_monitor_enabled":    false,
+				"service_enabled":             true,
+				"tamper_protected":            false,
+				"signature_version":           "1.403.1234.0",
+				"antivirus_signature_age":     int64(2),
+				"antispyware_signature_age":   int64(2),
+				"signature_last_updated":      int64(1702632612),
+				"quick_scan_age":              int64(0),
+				"full_scan_age":               int64(-1),
+				"quick_scan_end_time":         int64(1702636212),
+				"threats":                     int64(3),
+				"active_threats":              int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_update",
+			map[string]string{},
+			map[string]interface{}{
+				"pending_updates":      int64(4),
+				"pending_critical":     int64(1),
+				"pending_important":    int64(2),
+				"pending_moderate":     int64(0),
+				"pending_low":          int64(0),
+				"reboot_required":      true,
+				"last_search_success":  int64(1702639812),
+				"last_install_success": int64(1702000000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The result of the update search is cached
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Equal(t, 1, reader.searches)
+}
+
+func TestGatherError(t *testing.T) {
+	plugin := &WinSecurity{
+		Log: testutil.Logger{},
+		reader: &fakeReader{
+			defenderErr:  errors.New("access denied"),
+			updateStatus: &updateStatus{},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "querying defender status failed: access denied")
+	require.True(t, acc.HasMeasurement("win_update"))
+}
+
+func TestInitInvalidInclude(t *testing.T) {
+	plugin := &WinSecurity{Include: []string{"firewall"}}
+	require.ErrorContains(t, plugin.Init(), "invalid include")
+}
+
+func TestParseCIMDateTime(t *testing.T) {
+	ts, err := parseCIMDateTime("20231215093012.000000+060")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 12, 15, 8, 30, 12, 0, time.UTC), ts.UTC())
+
+	ts, err = parseCIMDateTime("20231215093012.500000-300")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 12, 15, 14, 30, 12, 500000000, time.UTC), ts.UTC())
+
+	_, err = parseCIMDateTime("2023-12-15")
+	require.Error(t, err)
+}
+
+func TestToAge(t *testing.T) {
+	require.Equal(t, int64(3), toAge(uint32(3)))
+	require.Equal(t, int64(-1), toAge(uint32(4294967295)))
+	require.Equal(t, int64(-1), toAge(nil))
+}
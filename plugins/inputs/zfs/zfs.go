@@ -13,6 +13,7 @@ var sampleConfig string
 type Sysctl func(metric string) ([]string, error)
 type Zpool func() ([]string, error)
 type Zdataset func(properties []string) ([]string, error)
+type ZpoolStatus func() ([]string, error)
 type Uname func() (string, error)
 
 type Zfs struct {
@@ -20,13 +21,15 @@ type Zfs struct {
 	KstatMetrics   []string
 	PoolMetrics    bool
 	DatasetMetrics bool
+	PoolStatus     bool
 	Log            telegraf.Logger `toml:"-"`
 
-	sysctl   Sysctl   //nolint:unused // False positive - this var is used for non-default build tag: freebsd
-	zpool    Zpool    //nolint:unused // False positive - this var is used for non-default build tag: freebsd
-	zdataset Zdataset //nolint:unused // False positive - this var is used for non-default build tag: freebsd
-	uname    Uname    //nolint:unused // False positive - this var is used for non-default build tag: freebsd
-	version  int64    //nolint:unused // False positive - this var is used for non-default build tag: freebsd
+	sysctl      Sysctl      //nolint:unused // False positive - this var is used for non-default build tag: freebsd
+	zpool       Zpool       //nolint:unused // False positive - this var is used for non-default build tag: freebsd
+	zdataset    Zdataset    //nolint:unused // False positive - this var is used for non-default build tags: linux, freebsd
+	zpoolStatus ZpoolStatus //nolint:unused // False positive - this var is used for non-default build tags: linux, freebsd
+	uname       Uname       //nolint:unused // False positive - this var is used for non-default build tag: freebsd
+	version     int64       //nolint:unused // False positive - this var is used for non-default build tag: freebsd
 }
 
 func (*Zfs) SampleConfig() string {
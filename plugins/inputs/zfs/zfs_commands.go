@@ -0,0 +1,333 @@
+//go:build linux || freebsd
+
+package zfs
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+func (z *Zfs) gatherDatasetStats(acc telegraf.Accumulator) (string, error) {
+	properties := []string{"name", "avail", "used", "usedsnap", "usedds"}
+
+	lines, err := z.zdataset(properties)
+	if err != nil {
+		return "", err
+	}
+
+	datasets := []string{}
+	for _, line := range lines {
+		col := strings.Split(line, "\t")
+		datasets = append(datasets, col[0])
+	}
+
+	if !z.DatasetMetrics {
+		return strings.Join(datasets, "::"), nil
+	}
+
+	for _, line := range lines {
+		col := strings.Split(line, "\t")
+		if len(col) != len(properties) {
+			z.Log.Warnf("Invalid number of columns for line: %s", line)
+			continue
+		}
+
+		tags := map[string]string{"dataset": col[0]}
+		fields := map[string]interface{}{}
+
+		for i, key := range properties[1:] {
+			// Treat '-' entries as zero
+			if col[i+1] == "-" {
+				fields[key] = int64(0)
+				continue
+			}
+			value, err := strconv.ParseInt(col[i+1], 10, 64)
+			if err != nil {
+				return "", fmt.Errorf("Error parsing %s %q: %s", key, col[i+1], err)
+			}
+			fields[key] = value
+		}
+
+		acc.AddFields("zfs_dataset", fields, tags)
+	}
+
+	return strings.Join(datasets, "::"), nil
+}
+
+func run(command string, args ...string) ([]string, error) {
+	cmd := exec.Command(command, args...)
+	var outbuf, errbuf bytes.Buffer
+	cmd.Stdout = &outbuf
+	cmd.Stderr = &errbuf
+	err := cmd.Run()
+
+	stdout := strings.TrimSpace(outbuf.String())
+	stderr := strings.TrimSpace(errbuf.String())
+
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("%s error: %s", command, stderr)
+		}
+		return nil, fmt.Errorf("%s error: %s", command, err)
+	}
+	return strings.Split(stdout, "\n"), nil
+}
+
+func zdataset(properties []string) ([]string, error) {
+	return run("zfs", []string{"list", "-Hp", "-t", "filesystem,volume", "-o", strings.Join(properties, ",")}...)
+}
+
+func zpoolStatus() ([]string, error) {
+	return run("zpool", []string{"status", "-p"}...)
+}
+
+var (
+	statusKeyRe          = regexp.MustCompile(`^\s*(pool|state|status|action|see|scan|remove|checkpoint|config|errors):\s?(.*)$`)
+	scanInProgressRe     = regexp.MustCompile(`^(scrub|resilver) in progress since`)
+	scanPausedRe         = regexp.MustCompile(`^(scrub|resilver) paused since`)
+	scanCanceledRe       = regexp.MustCompile(`^(scrub|resilver) canceled on`)
+	scanFinishedRe       = regexp.MustCompile(`^(scrub repaired|resilvered) (\S+) in (.+?) with (\d+) errors on`)
+	scanScannedRe        = regexp.MustCompile(`(\S+) scanned at \S+, (\S+) issued at \S+, (\S+) total`)
+	scanScannedOldRe     = regexp.MustCompile(`(\S+) scanned out of (\S+) at`)
+	scanProgressRe       = regexp.MustCompile(`(\S+) (?:repaired|resilvered), ([\d.]+)% done`)
+	scanEtaRe            = regexp.MustCompile(`(?:(\d+) days )?(\d+):(\d+):(\d+) to go`)
+	scanDurationRe       = regexp.MustCompile(`^(?:(\d+) days )?(\d+):(\d+):(\d+)$`)
+	dataErrorsRe         = regexp.MustCompile(`^(\d+) data errors`)
+	sizeWithSuffixRe     = regexp.MustCompile(`^([\d.]+)([KMGTPE]?)B?$`)
+	scanFunctionByPrefix = map[string]string{"scrub repaired": "scrub", "resilvered": "resilver"}
+)
+
+// zpoolStatusInfo contains the information of a single pool parsed from the
+// output of 'zpool status -p'
+type zpoolStatusInfo struct {
+	name       string
+	state      string
+	scan       []string
+	vdevs      []zpoolVdevInfo
+	dataErrors int64
+}
+
+type zpoolVdevInfo struct {
+	name   string
+	state  string
+	read   int64
+	write  int64
+	cksum  int64
+	isRoot bool
+}
+
+func (z *Zfs) gatherPoolStatus(acc telegraf.Accumulator) error {
+	lines, err := z.zpoolStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, pool := range parseZpoolStatus(lines) {
+		tags := map[string]string{"pool": pool.name, "state": pool.state}
+		fields := pool.scanFields()
+		fields["data_errors"] = pool.dataErrors
+
+		for _, vdev := range pool.vdevs {
+			if vdev.isRoot {
+				fields["read_errors"] = vdev.read
+				fields["write_errors"] = vdev.write
+				fields["checksum_errors"] = vdev.cksum
+				continue
+			}
+			vdevTags := map[string]string{
+				"pool":  pool.name,
+				"vdev":  vdev.name,
+				"state": vdev.state,
+			}
+			vdevFields := map[string]interface{}{
+				"read_errors":     vdev.read,
+				"write_errors":    vdev.write,
+				"checksum_errors": vdev.cksum,
+			}
+			acc.AddFields("zfs_vdev", vdevFields, vdevTags)
+		}
+
+		acc.AddFields("zfs_pool_status", fields, tags)
+	}
+
+	return nil
+}
+
+func parseZpoolStatus(lines []string) []*zpoolStatusInfo {
+	var pools []*zpoolStatusInfo
+	var current *zpoolStatusInfo
+	var section string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if m := statusKeyRe.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			value := strings.TrimSpace(m[2])
+			switch section {
+			case "pool":
+				current = &zpoolStatusInfo{name: value}
+				pools = append(pools, current)
+			case "state":
+				if current != nil {
+					current.state = value
+				}
+			case "scan":
+				if current != nil {
+					current.scan = append(current.scan, value)
+				}
+			case "errors":
+				if current != nil {
+					if m := dataErrorsRe.FindStringSubmatch(value); m != nil {
+						current.dataErrors, _ = strconv.ParseInt(m[1], 10, 64)
+					}
+				}
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		switch section {
+		case "scan":
+			current.scan = append(current.scan, strings.TrimSpace(line))
+		case "config":
+			// Lines not containing the error counters, e.g. the header or the
+			// 'logs', 'cache' and 'spares' groups, are skipped
+			col := strings.Fields(line)
+			if len(col) < 5 || col[0] == "NAME" {
+				continue
+			}
+			read, errRead := parseZfsNumber(col[2])
+			write, errWrite := parseZfsNumber(col[3])
+			cksum, errCksum := parseZfsNumber(col[4])
+			if errRead != nil || errWrite != nil || errCksum != nil {
+				continue
+			}
+			current.vdevs = append(current.vdevs, zpoolVdevInfo{
+				name:   col[0],
+				state:  col[1],
+				read:   read,
+				write:  write,
+				cksum:  cksum,
+				isRoot: col[0] == current.name && len(current.vdevs) == 0,
+			})
+		}
+	}
+
+	return pools
+}
+
+// scanFields returns the state and progress of the last or currently running
+// scrub or resilver operation
+func (p *zpoolStatusInfo) scanFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"scan_function": "none",
+		"scan_state":    "none",
+	}
+	if len(p.scan) == 0 {
+		return fields
+	}
+
+	first := p.scan[0]
+	text := strings.Join(p.scan, " ")
+	switch {
+	case scanInProgressRe.MatchString(first):
+		fields["scan_function"] = scanInProgressRe.FindStringSubmatch(first)[1]
+		fields["scan_state"] = "in_progress"
+	case scanPausedRe.MatchString(first):
+		fields["scan_function"] = scanPausedRe.FindStringSubmatch(first)[1]
+		fields["scan_state"] = "paused"
+	case scanCanceledRe.MatchString(first):
+		fields["scan_function"] = scanCanceledRe.FindStringSubmatch(first)[1]
+		fields["scan_state"] = "canceled"
+		return fields
+	case scanFinishedRe.MatchString(first):
+		m := scanFinishedRe.FindStringSubmatch(first)
+		fields["scan_function"] = scanFunctionByPrefix[m[1]]
+		fields["scan_state"] = "finished"
+		fields["scan_progress_percent"] = 100.0
+		if v, err := parseZfsNumber(m[2]); err == nil {
+			fields["scan_repaired_bytes"] = v
+		}
+		if d := scanDurationRe.FindStringSubmatch(m[3]); d != nil {
+			fields["scan_duration_seconds"] = clockToSeconds(d[1:])
+		}
+		fields["scan_errors"], _ = strconv.ParseInt(m[4], 10, 64)
+		return fields
+	default:
+		return fields
+	}
+
+	// Progress information of running or paused operations
+	if m := scanScannedRe.FindStringSubmatch(text); m != nil {
+		for i, key := range []string{"scan_scanned_bytes", "scan_issued_bytes", "scan_total_bytes"} {
+			if v, err := parseZfsNumber(m[i+1]); err == nil {
+				fields[key] = v
+			}
+		}
+	} else if m := scanScannedOldRe.FindStringSubmatch(text); m != nil {
+		for i, key := range []string{"scan_scanned_bytes", "scan_total_bytes"} {
+			if v, err := parseZfsNumber(m[i+1]); err == nil {
+				fields[key] = v
+			}
+		}
+	}
+	if m := scanProgressRe.FindStringSubmatch(text); m != nil {
+		if v, err := parseZfsNumber(m[1]); err == nil {
+			fields["scan_repaired_bytes"] = v
+		}
+		if v, err := strconv.ParseFloat(m[2], 64); err == nil {
+			fields["scan_progress_percent"] = v
+		}
+	}
+	if m := scanEtaRe.FindStringSubmatch(text); m != nil {
+		fields["scan_eta_seconds"] = clockToSeconds(m[1:])
+	}
+
+	return fields
+}
+
+// clockToSeconds converts the days, hours, minutes and seconds parts of a
+// duration to seconds
+func clockToSeconds(parts []string) int64 {
+	var seconds int64
+	for i, factor := range []int64{86400, 3600, 60, 1} {
+		v, _ := strconv.ParseInt(parts[i], 10, 64)
+		seconds += v * factor
+	}
+	return seconds
+}
+
+// parseZfsNumber parses exact values as well as human-readable ones with
+// a binary unit suffix as printed by zpool versions ignoring the '-p' flag
+// for some values, e.g. "1.50M"
+func parseZfsNumber(value string) (int64, error) {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v, nil
+	}
+
+	m := sizeWithSuffixRe.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	if m[2] != "" {
+		for i := 0; i <= strings.Index("KMGTPE", m[2]); i++ {
+			v *= 1024
+		}
+	}
+	return int64(v), nil
+}
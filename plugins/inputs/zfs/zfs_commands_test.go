@@ -0,0 +1,173 @@
+//go:build linux || freebsd
+
+package zfs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const zpoolStatusContents = `  pool: tank
+ state: DEGRADED
+status: One or more devices is currently being resilvered.  The pool will
+	continue to function, possibly in a degraded state.
+action: Wait for the resilver to complete.
+  scan: resilver in progress since Sun Jul 25 16:07:49 2021
+	422576128 scanned at 105644032/s, 71722598 issued at 10485760/s, 424673280 total
+	35861299 resilvered, 16.91% done, 00:00:33 to go
+config:
+
+	NAME           STATE     READ WRITE CKSUM
+	tank           DEGRADED     0     0     0
+	  mirror-0     DEGRADED     0     0     0
+	    sda        ONLINE       0     0     2
+	    replacing-1  DEGRADED   0     0     0
+	      sdb      FAULTED     12     3     0  too many errors
+	      sdc      ONLINE       0     0     0  (resilvering)
+	logs
+	  sdd          ONLINE       0     0     0
+	spares
+	  sde          AVAIL
+
+errors: 2 data errors, use '-v' for a list
+
+  pool: zroot
+ state: ONLINE
+  scan: scrub repaired 0B in 1 days 02:03:04 with 0 errors on Sun Jul 25 16:07:50 2021
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	zroot       ONLINE       0     0     0
+	  nvme0n1p3 ONLINE       0     0     0
+
+errors: No known data errors
+
+  pool: backup
+ state: ONLINE
+  scan: none requested
+config:
+
+	NAME                                   STATE     READ WRITE CKSUM
+	backup                                 ONLINE       0     0     0
+	  usb-WD_Elements_0:0-0:0              ONLINE       0     0     0
+
+errors: No known data errors`
+
+func TestGatherPoolStatus(t *testing.T) {
+	z := &Zfs{
+		PoolStatus: true,
+		zpoolStatus: func() ([]string, error) {
+			return strings.Split(zpoolStatusContents, "\n"), nil
+		},
+		Log: testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.gatherPoolStatus(&acc))
+
+	vdev := func(pool, name, state string, read, write, cksum int64) telegraf.Metric {
+		return testutil.MustMetric(
+			"zfs_vdev",
+			map[string]string{"pool": pool, "vdev": name, "state": state},
+			map[string]interface{}{
+				"read_errors":     read,
+				"write_errors":    write,
+				"checksum_errors": cksum,
+			},
+			time.Unix(0, 0),
+		)
+	}
+
+	expected := []telegraf.Metric{
+		vdev("tank", "mirror-0", "DEGRADED", 0, 0, 0),
+		vdev("tank", "sda", "ONLINE", 0, 0, 2),
+		vdev("tank", "replacing-1", "DEGRADED", 0, 0, 0),
+		vdev("tank", "sdb", "FAULTED", 12, 3, 0),
+		vdev("tank", "sdc", "ONLINE", 0, 0, 0),
+		vdev("tank", "sdd", "ONLINE", 0, 0, 0),
+		testutil.MustMetric(
+			"zfs_pool_status",
+			map[string]string{"pool": "tank", "state": "DEGRADED"},
+			map[string]interface{}{
+				"scan_function":         "resilver",
+				"scan_state":            "in_progress",
+				"scan_scanned_bytes":    int64(422576128),
+				"scan_issued_bytes":     int64(71722598),
+				"scan_total_bytes":      int64(424673280),
+				"scan_repaired_bytes":   int64(35861299),
+				"scan_progress_percent": 16.91,
+				"scan_eta_seconds":      int64(33),
+				"read_errors":           int64(0),
+				"write_errors":          int64(0),
+				"checksum_errors":       int64(0),
+				"data_errors":           int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		vdev("zroot", "nvme0n1p3", "ONLINE", 0, 0, 0),
+		testutil.MustMetric(
+			"zfs_pool_status",
+			map[string]string{"pool": "zroot", "state": "ONLINE"},
+			map[string]interface{}{
+				"scan_function":         "scrub",
+				"scan_state":            "finished",
+				"scan_progress_percent": 100.0,
+				"scan_repaired_bytes":   int64(0),
+				"scan_duration_seconds": int64(93784),
+				"scan_errors":           int64(0),
+				"read_errors":           int64(0),
+				"write_errors":          int64(0),
+				"checksum_errors":       int64(0),
+				"data_errors":           int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		vdev("backup", "usb-WD_Elements_0:0-0:0", "ONLINE", 0, 0, 0),
+		testutil.MustMetric(
+			"zfs_pool_status",
+			map[string]string{"pool": "backup", "state": "ONLINE"},
+			map[string]interface{}{
+				"scan_function":   "none",
+				"scan_state":      "none",
+				"read_errors":     int64(0),
+				"write_errors":    int64(0),
+				"checksum_errors": int64(0),
+				"data_errors":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseZfsNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{input: "0", expected: 0},
+		{input: "424673280", expected: 424673280},
+		{input: "0B", expected: 0},
+		{input: "512B", expected: 512},
+		{input: "1.50K", expected: 1536},
+		{input: "403M", expected: 403 * 1024 * 1024},
+		{input: "2G", expected: 2 * 1024 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := parseZfsNumber(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := parseZfsNumber("too")
+	require.Error(t, err)
+}
@@ -3,9 +3,7 @@
 package zfs
 
 import (
-	"bytes"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 
@@ -59,6 +57,12 @@ func (z *Zfs) Gather(acc telegraf.Accumulator) error {
 		tags["datasets"] = datasetNames
 	}
 
+	if z.PoolStatus {
+		if err := z.gatherPoolStatus(acc); err != nil {
+			return err
+		}
+	}
+
 	// Gather information form the kernel using sysctl
 	fields := make(map[string]interface{})
 	var removeIndices []int
@@ -160,80 +164,10 @@ func (z *Zfs) gatherPoolStats(acc telegraf.Accumulator) (string, error) {
 	return strings.Join(pools, "::"), nil
 }
 
-func (z *Zfs) gatherDatasetStats(acc telegraf.Accumulator) (string, error) {
-	properties := []string{"name", "avail", "used", "usedsnap", "usedds"}
-
-	lines, err := z.zdataset(properties)
-	if err != nil {
-		return "", err
-	}
-
-	datasets := []string{}
-	for _, line := range lines {
-		col := strings.Split(line, "\t")
-		datasets = append(datasets, col[0])
-	}
-
-	if !z.DatasetMetrics {
-		return strings.Join(datasets, "::"), nil
-	}
-
-	for _, line := range lines {
-		col := strings.Split(line, "\t")
-		if len(col) != len(properties) {
-			z.Log.Warnf("Invalid number of columns for line: %s", line)
-			continue
-		}
-
-		tags := map[string]string{"dataset": col[0]}
-		fields := map[string]interface{}{}
-
-		for i, key := range properties[1:] {
-			// Treat '-' entries as zero
-			if col[i+1] == "-" {
-				fields[key] = int64(0)
-				continue
-			}
-			value, err := strconv.ParseInt(col[i+1], 10, 64)
-			if err != nil {
-				return "", fmt.Errorf("Error parsing %s %q: %s", key, col[i+1], err)
-			}
-			fields[key] = value
-		}
-
-		acc.AddFields("zfs_dataset", fields, tags)
-	}
-
-	return strings.Join(datasets, "::"), nil
-}
-
-func run(command string, args ...string) ([]string, error) {
-	cmd := exec.Command(command, args...)
-	var outbuf, errbuf bytes.Buffer
-	cmd.Stdout = &outbuf
-	cmd.Stderr = &errbuf
-	err := cmd.Run()
-
-	stdout := strings.TrimSpace(outbuf.String())
-	stderr := strings.TrimSpace(errbuf.String())
-
-	if err != nil {
-		if _, ok := err.(*exec.ExitError); ok {
-			return nil, fmt.Errorf("%s error: %s", command, stderr)
-		}
-		return nil, fmt.Errorf("%s error: %s", command, err)
-	}
-	return strings.Split(stdout, "\n"), nil
-}
-
 func zpool() ([]string, error) {
 	return run("zpool", []string{"list", "-Hp", "-o", "name,health,size,alloc,free,fragmentation,capacity,dedupratio"}...)
 }
 
-func zdataset(properties []string) ([]string, error) {
-	return run("zfs", []string{"list", "-Hp", "-t", "filesystem,volume", "-o", strings.Join(properties, ",")}...)
-}
-
 func sysctl(metric string) ([]string, error) {
 	return run("sysctl", []string{"-q", fmt.Sprintf("kstat.zfs.misc.%s", metric)}...)
 }
@@ -250,10 +184,11 @@ func uname() (string, error) {
 func init() {
 	inputs.Add("zfs", func() telegraf.Input {
 		return &Zfs{
-			sysctl:   sysctl,
-			zpool:    zpool,
-			zdataset: zdataset,
-			uname:    uname,
+			sysctl:      sysctl,
+			zpool:       zpool,
+			zdataset:    zdataset,
+			zpoolStatus: zpoolStatus,
+			uname:       uname,
 		}
 	})
 }
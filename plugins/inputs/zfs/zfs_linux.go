@@ -206,6 +206,18 @@ func (z *Zfs) Gather(acc telegraf.Accumulator) error {
 		}
 	}
 
+	if z.DatasetMetrics {
+		if _, err := z.gatherDatasetStats(acc); err != nil {
+			return err
+		}
+	}
+
+	if z.PoolStatus {
+		if err := z.gatherPoolStatus(acc); err != nil {
+			return err
+		}
+	}
+
 	fields := make(map[string]interface{})
 	for _, metric := range kstatMetrics {
 		lines, err := internal.ReadLines(kstatPath + "/" + metric)
@@ -235,6 +247,9 @@ func (z *Zfs) Gather(acc telegraf.Accumulator) error {
 
 func init() {
 	inputs.Add("zfs", func() telegraf.Input {
-		return &Zfs{}
+		return &Zfs{
+			zdataset:    zdataset,
+			zpoolStatus: zpoolStatus,
+		}
 	})
 }
@@ -279,6 +279,47 @@ func TestZfsPoolMetrics(t *testing.T) {
 	acc.AssertContainsTaggedFields(t, "zfs_pool", poolMetrics, tags)
 }
 
+func TestZfsDatasetMetrics(t *testing.T) {
+	testKstatPath := t.TempDir()
+	require.NoError(t, os.WriteFile(testKstatPath+"/arcstats", []byte(arcstatsContents), 0640))
+
+	z := &Zfs{
+		KstatPath:      testKstatPath,
+		KstatMetrics:   []string{"arcstats"},
+		DatasetMetrics: true,
+		zdataset: func(properties []string) ([]string, error) {
+			require.Equal(t, []string{"name", "avail", "used", "usedsnap", "usedds"}, properties)
+			return []string{
+				"HOME\t10741741326336\t8564135526400\t0\t90112",
+				"HOME/vol\t-\t4096\t0\t4096",
+			}, nil
+		},
+		Log: testutil.Logger{},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.Gather(&acc))
+
+	acc.AssertContainsTaggedFields(t, "zfs_dataset",
+		map[string]interface{}{
+			"avail":    int64(10741741326336),
+			"used":     int64(8564135526400),
+			"usedsnap": int64(0),
+			"usedds":   int64(90112),
+		},
+		map[string]string{"dataset": "HOME"},
+	)
+	acc.AssertContainsTaggedFields(t, "zfs_dataset",
+		map[string]interface{}{
+			"avail":    int64(0),
+			"used":     int64(4096),
+			"usedsnap": int64(0),
+			"usedds":   int64(4096),
+		},
+		map[string]string{"dataset": "HOME/vol"},
+	)
+}
+
 func TestZfsGeneratesMetrics(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "telegraf-zfs-generates")
 	require.NoError(t, err)
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.greptimedb
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/greptimedb" // register plugin
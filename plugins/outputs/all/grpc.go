@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.grpc
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/grpc" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.iceberg
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/iceberg" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.notify
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/notify" // register plugin
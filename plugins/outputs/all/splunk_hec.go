@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.splunk_hec
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/splunk_hec" // register plugin
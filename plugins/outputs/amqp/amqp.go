@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -55,6 +56,11 @@ type AMQP struct {
 	AuthMethod         string            `toml:"auth_method"`
 	RoutingTag         string            `toml:"routing_tag"`
 	RoutingKey         string            `toml:"routing_key"`
+	RoutingKeyTemplate string            `toml:"routing_key_template"`
+	ExchangeTemplate   string            `toml:"exchange_template"`
+	HeaderTags         []string          `toml:"header_tags"`
+	PublisherConfirms  bool              `toml:"publisher_confirms"`
+	ConfirmRetries     int               `toml:"confirm_retries"`
 	DeliveryMode       string            `toml:"delivery_mode"`
 	Database           string            `toml:"database" deprecated:"1.7.0;use 'headers' instead"`
 	RetentionPolicy    string            `toml:"retention_policy" deprecated:"1.7.0;use 'headers' instead"`
@@ -73,17 +79,52 @@ type AMQP struct {
 	config       *ClientConfig
 	sentMessages int
 	encoder      internal.ContentEncoder
+
+	routingKeyTmpl *template.Template
+	exchangeTmpl   *template.Template
 }
 
 type Client interface {
-	Publish(key string, body []byte) error
+	Publish(exchange, key string, headers amqp.Table, body []byte) error
 	Close() error
 }
 
+// batch is a set of metrics published as a single message
+type batch struct {
+	exchange string
+	key      string
+	headers  amqp.Table
+	metrics  []telegraf.Metric
+}
+
 func (*AMQP) SampleConfig() string {
 	return sampleConfig
 }
 
+func (q *AMQP) Init() error {
+	if q.RoutingKeyTemplate != "" {
+		tmpl, err := template.New("routing_key").Parse(q.RoutingKeyTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing routing key template failed: %w", err)
+		}
+		q.routingKeyTmpl = tmpl
+	}
+
+	if q.ExchangeTemplate != "" {
+		tmpl, err := template.New("exchange").Parse(q.ExchangeTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing exchange template failed: %w", err)
+		}
+		q.exchangeTmpl = tmpl
+	}
+
+	if q.ConfirmRetries < 0 {
+		return errors.New("confirm_retries must not be negative")
+	}
+
+	return nil
+}
+
 func (q *AMQP) SetSerializer(serializer serializers.Serializer) {
 	q.serializer = serializer
 }
@@ -118,36 +159,78 @@ func (q *AMQP) Close() error {
 	return nil
 }
 
-func (q *AMQP) routingKey(metric telegraf.Metric) string {
+func (q *AMQP) routingKey(metric telegraf.Metric) (string, error) {
+	if q.routingKeyTmpl != nil {
+		return executeTemplate(q.routingKeyTmpl, metric)
+	}
+
 	if q.RoutingTag != "" {
 		key, ok := metric.GetTag(q.RoutingTag)
 		if ok {
-			return key
+			return key, nil
 		}
 	}
-	return q.RoutingKey
+	return q.RoutingKey, nil
 }
 
-func (q *AMQP) Write(metrics []telegraf.Metric) error {
-	batches := make(map[string][]telegraf.Metric)
-	if q.ExchangeType == "header" {
-		// Since the routing_key is ignored for this exchange type send as a
-		// single batch.
-		batches[""] = metrics
-	} else {
-		for _, metric := range metrics {
-			routingKey := q.routingKey(metric)
-			if _, ok := batches[routingKey]; !ok {
-				batches[routingKey] = make([]telegraf.Metric, 0)
+func (q *AMQP) exchange(metric telegraf.Metric) (string, error) {
+	if q.exchangeTmpl != nil {
+		return executeTemplate(q.exchangeTmpl, metric)
+	}
+	return q.Exchange, nil
+}
+
+// batches groups the metrics by their destination exchange, routing key and
+// message headers
+func (q *AMQP) batches(metrics []telegraf.Metric) []*batch {
+	batches := make([]*batch, 0)
+	index := make(map[string]*batch)
+	for _, metric := range metrics {
+		exchange, err := q.exchange(metric)
+		if err != nil {
+			q.Log.Errorf("Executing exchange template failed, dropping metric: %v", err)
+			continue
+		}
+
+		// Since the routing_key is ignored for the header exchange type
+		// do not split the batch by routing key.
+		var key string
+		if q.ExchangeType != "header" {
+			key, err = q.routingKey(metric)
+			if err != nil {
+				q.Log.Errorf("Executing routing key template failed, dropping metric: %v", err)
+				continue
 			}
+		}
 
-			batches[routingKey] = append(batches[routingKey], metric)
+		id := exchange + "\x00" + key
+		var headers amqp.Table
+		if len(q.HeaderTags) > 0 {
+			headers = make(amqp.Table, len(q.HeaderTags))
+			for _, tag := range q.HeaderTags {
+				if value, ok := metric.GetTag(tag); ok {
+					headers[tag] = value
+					id += "\x00" + tag + "=" + value
+				}
+			}
 		}
+
+		b, found := index[id]
+		if !found {
+			b = &batch{exchange: exchange, key: key, headers: headers}
+			index[id] = b
+			batches = append(batches, b)
+		}
+		b.metrics = append(b.metrics, metric)
 	}
 
+	return batches
+}
+
+func (q *AMQP) Write(metrics []telegraf.Metric) error {
 	first := true
-	for key, metrics := range batches {
-		body, err := q.serialize(metrics)
+	for _, b := range q.batches(metrics) {
+		body, err := q.serialize(b.metrics)
 		if err != nil {
 			return err
 		}
@@ -157,7 +240,7 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 			return err
 		}
 
-		err = q.publish(key, body)
+		err = q.publish(b, body)
 		if err != nil {
 			// If this is the first attempt to publish and the connection is
 			// closed, try to reconnect and retry once.
@@ -165,7 +248,7 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 			var aerr *amqp.Error
 			if first && errors.As(err, &aerr) && errors.Is(aerr, amqp.ErrClosed) {
 				q.client = nil
-				err := q.publish(key, body)
+				err := q.publish(b, body)
 				if err != nil {
 					return err
 				}
@@ -191,7 +274,7 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
-func (q *AMQP) publish(key string, body []byte) error {
+func (q *AMQP) publish(b *batch, body []byte) error {
 	if q.client == nil {
 		client, err := q.connect(q.config)
 		if err != nil {
@@ -201,7 +284,16 @@ func (q *AMQP) publish(key string, body []byte) error {
 		q.client = client
 	}
 
-	err := q.client.Publish(key, body)
+	// Retry messages rejected or not confirmed by the broker
+	var err error
+	for attempt := 0; attempt <= q.ConfirmRetries; attempt++ {
+		err = q.client.Publish(b.exchange, b.key, b.headers, body)
+		if !errors.Is(err, errNotConfirmed) {
+			break
+		}
+		q.Log.Warnf("Publishing to exchange %q with routing key %q failed (attempt %d of %d): %v",
+			b.exchange, b.key, attempt+1, q.ConfirmRetries+1, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -233,6 +325,7 @@ func (q *AMQP) makeClientConfig() (*ClientConfig, error) {
 		exchangeType:    q.ExchangeType,
 		exchangePassive: q.ExchangePassive,
 		encoding:        q.ContentEncoding,
+		confirm:         q.PublisherConfirms,
 		timeout:         time.Duration(q.Timeout),
 		log:             q.Log,
 	}
@@ -316,6 +409,23 @@ func (q *AMQP) makeClientConfig() (*ClientConfig, error) {
 	return clientConfig, nil
 }
 
+func executeTemplate(tmpl *template.Template, metric telegraf.Metric) (string, error) {
+	m := metric
+	if wm, ok := metric.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return "", fmt.Errorf("metric of type %T is not a template metric", metric)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, tm); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 func connect(clientConfig *ClientConfig) (Client, error) {
 	return newClient(clientConfig)
 }
@@ -329,6 +439,7 @@ func init() {
 			Database:        DefaultDatabase,
 			RetentionPolicy: DefaultRetentionPolicy,
 			Timeout:         config.Duration(time.Second * 5),
+			ConfirmRetries:  3,
 			connect:         connect,
 		}
 	})
@@ -1,26 +1,31 @@
 package amqp
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 type MockClient struct {
-	PublishF func(key string, body []byte) error
+	PublishF func(exchange, key string, headers amqp.Table, body []byte) error
 	CloseF   func() error
 
 	PublishCallCount int
 	CloseCallCount   int
 }
 
-func (c *MockClient) Publish(key string, body []byte) error {
+func (c *MockClient) Publish(exchange, key string, headers amqp.Table, body []byte) error {
 	c.PublishCallCount++
-	return c.PublishF(key, body)
+	return c.PublishF(exchange, key, headers, body)
 }
 
 func (c *MockClient) Close() error {
@@ -30,7 +35,7 @@ func (c *MockClient) Close() error {
 
 func NewMockClient() Client {
 	return &MockClient{
-		PublishF: func(key string, body []byte) error {
+		PublishF: func(_, _ string, _ amqp.Table, _ []byte) error {
 			return nil
 		},
 		CloseF: func() error {
@@ -155,3 +160,142 @@ func TestConnect(t *testing.T) {
 		})
 	}
 }
+
+type published struct {
+	exchange string
+	key      string
+	headers  amqp.Table
+	body     string
+}
+
+func TestWriteRouting(t *testing.T) {
+	var messages []published
+	client := &MockClient{
+		PublishF: func(exchange, key string, headers amqp.Table, body []byte) error {
+			messages = append(messages, published{exchange, key, headers, string(body)})
+			return nil
+		},
+		CloseF: func() error { return nil },
+	}
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &AMQP{
+		ExchangeTemplate:   `telegraf.{{ .Tag "env" }}`,
+		RoutingKeyTemplate: `{{ .Tag "region" }}.{{ .Name }}`,
+		HeaderTags:         []string{"host"},
+		Log:                testutil.Logger{},
+		connect: func(_ *ClientConfig) (Client, error) {
+			return client, nil
+		},
+	}
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"env": "prod", "region": "eu", "host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"env": "prod", "region": "eu", "host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(1, 0)),
+		testutil.MustMetric("cpu", map[string]string{"env": "prod", "region": "eu", "host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	// Tracking metrics must be unwrapped before executing the templates
+	tm, _ := metric.WithTracking(
+		testutil.MustMetric("mem", map[string]string{"env": "dev", "region": "us"}, map[string]interface{}{"value": 4.0}, time.Unix(0, 0)),
+		func(telegraf.DeliveryInfo) {},
+	)
+	metrics = append(metrics, tm)
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Equal(t, []published{
+		{
+			exchange: "telegraf.prod",
+			key:      "eu.cpu",
+			headers:  amqp.Table{"host": "a"},
+			body:     "cpu,env=prod,host=a,region=eu value=1 0\ncpu,env=prod,host=a,region=eu value=2 1000000000\n",
+		},
+		{
+			exchange: "telegraf.prod",
+			key:      "eu.cpu",
+			headers:  amqp.Table{"host": "b"},
+			body:     "cpu,env=prod,host=b,region=eu value=3 0\n",
+		},
+		{
+			exchange: "telegraf.dev",
+			key:      "us.mem",
+			headers:  amqp.Table{},
+			body:     "mem,env=dev,region=us value=4 0\n",
+		},
+	}, messages)
+}
+
+func TestWriteConfirmRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		expected int
+		err      bool
+	}{
+		{
+			name:     "confirmed",
+			expected: 1,
+		},
+		{
+			name:     "confirmed after retry",
+			failures: 2,
+			expected: 3,
+		},
+		{
+			name:     "retries exhausted",
+			failures: 5,
+			expected: 4,
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockClient{
+				CloseF: func() error { return nil },
+			}
+			client.PublishF = func(_, _ string, _ amqp.Table, _ []byte) error {
+				if client.PublishCallCount <= tt.failures {
+					return fmt.Errorf("%w: message was rejected", errNotConfirmed)
+				}
+				return nil
+			}
+
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			plugin := &AMQP{
+				PublisherConfirms: true,
+				ConfirmRetries:    3,
+				Log:               testutil.Logger{},
+				connect: func(_ *ClientConfig) (Client, error) {
+					return client, nil
+				},
+			}
+			plugin.SetSerializer(serializer)
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			require.True(t, plugin.config.confirm)
+
+			m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+			err := plugin.Write([]telegraf.Metric{m})
+			if tt.err {
+				require.ErrorIs(t, err, errNotConfirmed)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expected, client.PublishCallCount)
+		})
+	}
+}
+
+func TestInitInvalidTemplate(t *testing.T) {
+	plugin := &AMQP{RoutingKeyTemplate: "{{ .Tag "}
+	require.ErrorContains(t, plugin.Init(), "parsing routing key template failed")
+
+	plugin = &AMQP{ExchangeTemplate: "{{ .Name"}
+	require.ErrorContains(t, plugin.Init(), "parsing exchange template failed")
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 )
 
+var errNotConfirmed = errors.New("message not confirmed by broker")
+
 type ClientConfig struct {
 	brokers           []string
 	exchange          string
@@ -25,6 +27,7 @@ type ClientConfig struct {
 	encoding          string
 	headers           amqp.Table
 	deliveryMode      uint8
+	confirm           bool
 	tlsConfig         *tls.Config
 	timeout           time.Duration
 	auth              []amqp.Authentication
@@ -33,15 +36,17 @@ type ClientConfig struct {
 }
 
 type client struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	config  *ClientConfig
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	config   *ClientConfig
+	declared map[string]bool
 }
 
 // newClient opens a connection to one of the brokers at random
 func newClient(config *ClientConfig) (*client, error) {
 	client := &client{
-		config: config,
+		config:   config,
+		declared: make(map[string]bool),
 	}
 
 	p := rand.Perm(len(config.brokers))
@@ -74,7 +79,13 @@ func newClient(config *ClientConfig) (*client, error) {
 	}
 	client.channel = channel
 
-	err = client.DeclareExchange()
+	if config.confirm {
+		if err := channel.Confirm(false); err != nil {
+			return nil, fmt.Errorf("error enabling publisher confirms: %w", err)
+		}
+	}
+
+	err = client.DeclareExchange(config.exchange)
 	if err != nil {
 		return nil, err
 	}
@@ -82,15 +93,22 @@ func newClient(config *ClientConfig) (*client, error) {
 	return client, nil
 }
 
-func (c *client) DeclareExchange() error {
-	if c.config.exchange == "" {
+func (c *client) DeclareExchange(exchange string) error {
+	if exchange == "" || c.declared[exchange] {
 		return nil
 	}
 
-	var err error
+	// A failing declaration closes the channel, so use a separate channel to
+	// keep the publishing channel usable for other exchanges.
+	channel, err := c.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("error opening channel: %w", err)
+	}
+	defer channel.Close()
+
 	if c.config.exchangePassive {
-		err = c.channel.ExchangeDeclarePassive(
-			c.config.exchange,
+		err = channel.ExchangeDeclarePassive(
+			exchange,
 			c.config.exchangeType,
 			c.config.exchangeDurable,
 			false, // delete when unused
@@ -99,8 +117,8 @@ func (c *client) DeclareExchange() error {
 			c.config.exchangeArguments,
 		)
 	} else {
-		err = c.channel.ExchangeDeclare(
-			c.config.exchange,
+		err = channel.ExchangeDeclare(
+			exchange,
 			c.config.exchangeType,
 			c.config.exchangeDurable,
 			false, // delete when unused
@@ -110,27 +128,78 @@ func (c *client) DeclareExchange() error {
 		)
 	}
 	if err != nil {
-		return fmt.Errorf("error declaring exchange: %w", err)
+		return fmt.Errorf("error declaring exchange %q: %w", exchange, err)
 	}
+	c.declared[exchange] = true
 	return nil
 }
 
-func (c *client) Publish(key string, body []byte) error {
-	// Note that since the channel is not in confirm mode, the absence of
+func (c *client) Publish(exchange, key string, headers amqp.Table, body []byte) error {
+	if err := c.DeclareExchange(exchange); err != nil {
+		return err
+	}
+
+	// Merge the per-message headers with the static ones
+	if len(headers) > 0 {
+		merged := make(amqp.Table, len(c.config.headers)+len(headers))
+		for k, v := range c.config.headers {
+			merged[k] = v
+		}
+		for k, v := range headers {
+			merged[k] = v
+		}
+		headers = merged
+	} else {
+		headers = c.config.headers
+	}
+
+	msg := amqp.Publishing{
+		Headers:         headers,
+		ContentType:     "text/plain",
+		ContentEncoding: c.config.encoding,
+		Body:            body,
+		DeliveryMode:    c.config.deliveryMode,
+	}
+
+	// Note that if the channel is not in confirm mode, the absence of
 	// an error does not indicate successful delivery.
-	return c.channel.PublishWithContext(
+	if !c.config.confirm {
+		return c.channel.PublishWithContext(
+			context.Background(),
+			exchange, // exchange
+			key,      // routing key
+			false,    // mandatory
+			false,    // immediate
+			msg,
+		)
+	}
+
+	confirmation, err := c.channel.PublishWithDeferredConfirmWithContext(
 		context.Background(),
-		c.config.exchange, // exchange
-		key,               // routing key
-		false,             // mandatory
-		false,             // immediate
-		amqp.Publishing{
-			Headers:         c.config.headers,
-			ContentType:     "text/plain",
-			ContentEncoding: c.config.encoding,
-			Body:            body,
-			DeliveryMode:    c.config.deliveryMode,
-		})
+		exchange, // exchange
+		key,      // routing key
+		false,    // mandatory
+		false,    // immediate
+		msg,
+	)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if c.config.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.timeout)
+		defer cancel()
+	}
+	ack, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNotConfirmed, err)
+	}
+	if !ack {
+		return fmt.Errorf("%w: message was rejected", errNotConfirmed)
+	}
+	return nil
 }
 
 func (c *client) Close() error {
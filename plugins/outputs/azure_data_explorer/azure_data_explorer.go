@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-kusto-go/kusto"
@@ -28,17 +29,24 @@ import (
 var sampleConfig string
 
 type AzureDataExplorer struct {
-	Endpoint        string          `toml:"endpoint_url"`
-	Database        string          `toml:"database"`
-	Log             telegraf.Logger `toml:"-"`
-	Timeout         config.Duration `toml:"timeout"`
-	MetricsGrouping string          `toml:"metrics_grouping_type"`
-	TableName       string          `toml:"table_name"`
-	CreateTables    bool            `toml:"create_tables"`
-	IngestionType   string          `toml:"ingestion_type"`
-	serializer      serializers.Serializer
-	kustoClient     *kusto.Client
-	metricIngestors map[string]ingest.Ingestor
+	Endpoint               string          `toml:"endpoint_url"`
+	Database               string          `toml:"database"`
+	Log                    telegraf.Logger `toml:"-"`
+	Timeout                config.Duration `toml:"timeout"`
+	MetricsGrouping        string          `toml:"metrics_grouping_type"`
+	TableName              string          `toml:"table_name"`
+	CreateTables           bool            `toml:"create_tables"`
+	IngestionType          string          `toml:"ingestion_type"`
+	ManagedIdentityID      string          `toml:"managed_identity_id"`
+	IngestionStatusTimeout config.Duration `toml:"ingestion_status_timeout"`
+	serializer             serializers.Serializer
+	kustoClient            *kusto.Client
+	metricIngestors        map[string]ingest.Ingestor
+
+	// State for checking the ingestion status in the background
+	statusCtx    context.Context
+	statusCancel context.CancelFunc
+	statusWg     sync.WaitGroup
 }
 
 const (
@@ -51,6 +59,7 @@ const (
 
 const managedIngestion = "managed"
 const queuedIngestion = "queued"
+const streamingIngestion = "streaming"
 
 func (*AzureDataExplorer) SampleConfig() string {
 	return sampleConfig
@@ -58,7 +67,15 @@ func (*AzureDataExplorer) SampleConfig() string {
 
 // Initialize the client and the ingestor
 func (adx *AzureDataExplorer) Connect() error {
-	conn := kusto.NewConnectionStringBuilder(adx.Endpoint).WithDefaultAzureCredential()
+	conn := kusto.NewConnectionStringBuilder(adx.Endpoint)
+	switch adx.ManagedIdentityID {
+	case "":
+		conn = conn.WithDefaultAzureCredential()
+	case "system":
+		conn = conn.WithSystemManagedIdentity()
+	default:
+		conn = conn.WithUserManagedIdentity(adx.ManagedIdentityID)
+	}
 	// Since init is called before connect, we can set the connector details here including the type. This will be used for telemetry and tracing.
 	conn.SetConnectorDetails("Telegraf", internal.ProductToken(), "", "", false, "")
 	client, err := kusto.New(conn)
@@ -67,12 +84,18 @@ func (adx *AzureDataExplorer) Connect() error {
 	}
 	adx.kustoClient = client
 	adx.metricIngestors = make(map[string]ingest.Ingestor)
+	adx.statusCtx, adx.statusCancel = context.WithCancel(context.Background())
 
 	return nil
 }
 
 // Clean up and close the ingestor
 func (adx *AzureDataExplorer) Close() error {
+	if adx.statusCancel != nil {
+		adx.statusCancel()
+	}
+	adx.statusWg.Wait()
+
 	var errs []error
 	for _, v := range adx.metricIngestors {
 		if err := v.Close(); err != nil {
@@ -166,14 +189,60 @@ func (adx *AzureDataExplorer) pushMetrics(ctx context.Context, format ingest.Fil
 	adx.Log.Debugf("Writing %d metrics to table %q", length, tableName)
 	reader := bytes.NewReader(metricsArray)
 	mapping := ingest.IngestionMappingRef(fmt.Sprintf("%s_mapping", tableName), ingest.JSON)
-	if metricIngestor != nil {
-		if _, err := metricIngestor.FromReader(ctx, reader, format, mapping); err != nil {
-			adx.Log.Errorf("sending ingestion request to Azure Data Explorer for table %q failed: %v", tableName, err)
+	if metricIngestor == nil {
+		return nil
+	}
+
+	// The final status is only reported for queued ingestion, other types
+	// fail synchronously or fall back to queued ingestion on their own
+	checkStatus := adx.IngestionType == queuedIngestion && adx.IngestionStatusTimeout > 0
+
+	options := []ingest.FileOption{format, mapping}
+	if checkStatus {
+		options = append(options, ingest.ReportResultToTable())
+	}
+	result, err := metricIngestor.FromReader(ctx, reader, options...)
+	if err != nil {
+		// Streaming ingestion fails synchronously, so retry the metrics
+		if adx.IngestionType == streamingIngestion {
+			return fmt.Errorf("streaming ingestion to table %q failed: %w", tableName, err)
 		}
+		adx.Log.Errorf("sending ingestion request to Azure Data Explorer for table %q failed: %v", tableName, err)
+		return nil
+	}
+
+	if checkStatus && result != nil {
+		adx.statusWg.Add(1)
+		go func() {
+			defer adx.statusWg.Done()
+			adx.checkIngestionStatus(tableName, result)
+		}()
 	}
 	return nil
 }
 
+// checkIngestionStatus waits for the final status of the ingestion and
+// reports the details of failed ingestions. The check runs in the background
+// to not block writes.
+func (adx *AzureDataExplorer) checkIngestionStatus(tableName string, result *ingest.Result) {
+	ctx, cancel := context.WithTimeout(adx.statusCtx, time.Duration(adx.IngestionStatusTimeout))
+	defer cancel()
+
+	err := <-result.Wait(ctx)
+	if err == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			adx.Log.Warnf("Timeout waiting for the ingestion status of table %q", tableName)
+		}
+		return
+	}
+
+	// The error contains the status and failure details reported by the service
+	adx.Log.Errorf("Ingestion to table %q failed: %v", tableName, err)
+}
+
 func (adx *AzureDataExplorer) getMetricIngestor(ctx context.Context, tableName string) (ingest.Ingestor, error) {
 	ingestor := adx.metricIngestors[tableName]
 
@@ -207,6 +276,13 @@ func (adx *AzureDataExplorer) createAzureDataExplorerTable(ctx context.Context,
 		return err
 	}
 
+	// Streaming ingestion must be enabled for the table in addition to the cluster
+	if adx.IngestionType == streamingIngestion || adx.IngestionType == managedIngestion {
+		if _, err := adx.kustoClient.Mgmt(ctx, adx.Database, enableStreamingIngestionCommand(tableName)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -231,9 +307,12 @@ func (adx *AzureDataExplorer) Init() error {
 
 	if adx.IngestionType == "" {
 		adx.IngestionType = queuedIngestion
-	} else if !(choice.Contains(adx.IngestionType, []string{managedIngestion, queuedIngestion})) {
+	} else if !(choice.Contains(adx.IngestionType, []string{managedIngestion, queuedIngestion, streamingIngestion})) {
 		return fmt.Errorf("unknown ingestion type %q", adx.IngestionType)
 	}
+	if adx.IngestionStatusTimeout > 0 && adx.IngestionType != queuedIngestion {
+		adx.Log.Warnf("Option 'ingestion_status_timeout' is only supported for %q ingestion, ignoring", queuedIngestion)
+	}
 
 	serializer := &json.Serializer{
 		TimestampUnits:  config.Duration(time.Nanosecond),
@@ -264,8 +343,11 @@ func createIngestorByTable(client *kusto.Client, database string, tableName stri
 	case queuedIngestion:
 		qi, err := ingest.New(client, database, tableName, ingest.WithStaticBuffer(bufferSize, maxBuffers))
 		return qi, err
+	case streamingIngestion:
+		si, err := ingest.NewStreaming(client, database, tableName)
+		return si, err
 	}
-	return nil, fmt.Errorf(`ingestion_type has to be one of %q or %q`, managedIngestion, queuedIngestion)
+	return nil, fmt.Errorf(`ingestion_type has to be one of %q, %q or %q`, managedIngestion, queuedIngestion, streamingIngestion)
 }
 
 func createTableCommand(table string) kusto.Statement {
@@ -286,3 +368,10 @@ func createTableMappingCommand(table string) kusto.Statement {
 
 	return builder
 }
+
+func enableStreamingIngestionCommand(table string) kusto.Statement {
+	builder := kql.New(`.alter table ['`).AddTable(table).AddLiteral(`'] `)
+	builder.AddLiteral(`policy streamingingestion enable`)
+
+	return builder
+}
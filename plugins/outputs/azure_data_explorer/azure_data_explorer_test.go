@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"os"
@@ -310,8 +311,52 @@ func TestQueryConstruction(t *testing.T) {
 		`.create-or-alter table ['mytable'] ingestion json mapping 'mytable_mapping' '[{"column":"fields", ` +
 		`"Properties":{"Path":"$[\'fields\']"}},{"column":"name", "Properties":{"Path":"$[\'name\']"}},{"column":"tags", ` +
 		`"Properties":{"Path":"$[\'tags\']"}},{"column":"timestamp", "Properties":{"Path":"$[\'timestamp\']"}}]'`
+	const expectedStreaming = `.alter table ['mytable'] policy streamingingestion enable`
 	require.Equal(t, expectedCreate, createTableCommand(tableName).String())
 	require.Equal(t, expectedMapping, createTableMappingCommand(tableName).String())
+	require.Equal(t, expectedStreaming, enableStreamingIngestionCommand(tableName).String())
+}
+
+func TestWriteIngestionFailure(t *testing.T) {
+	for _, ingestionType := range []string{queuedIngestion, managedIngestion, streamingIngestion} {
+		t.Run(ingestionType, func(t *testing.T) {
+			serializer := &telegrafJson.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			plugin := AzureDataExplorer{
+				Endpoint:        "someendpoint",
+				Database:        "databasename",
+				Log:             testutil.Logger{},
+				MetricsGrouping: tablePerMetric,
+				IngestionType:   ingestionType,
+				kustoClient:     kusto.NewMockClient(),
+				metricIngestors: map[string]ingest.Ingestor{
+					"test1": &failingIngestor{},
+				},
+				serializer: serializer,
+			}
+
+			// Only streaming ingestion fails synchronously and retries the metrics
+			err := plugin.Write(testutil.MockMetrics())
+			if ingestionType == streamingIngestion {
+				require.ErrorContains(t, err, `streaming ingestion to table "test1" failed: ingestion refused`)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInitIngestionType(t *testing.T) {
+	plugin := AzureDataExplorer{
+		Endpoint:      "someendpoint",
+		Database:      "databasename",
+		IngestionType: streamingIngestion,
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.IngestionType = "batched"
+	require.ErrorContains(t, plugin.Init(), `unknown ingestion type "batched"`)
 }
 
 type fakeIngestor struct {
@@ -337,6 +382,20 @@ func (f *fakeIngestor) Close() error {
 	return nil
 }
 
+type failingIngestor struct{}
+
+func (f *failingIngestor) FromReader(_ context.Context, _ io.Reader, _ ...ingest.FileOption) (*ingest.Result, error) {
+	return nil, errors.New("ingestion refused")
+}
+
+func (f *failingIngestor) FromFile(_ context.Context, _ string, _ ...ingest.FileOption) (*ingest.Result, error) {
+	return nil, errors.New("ingestion refused")
+}
+
+func (f *failingIngestor) Close() error {
+	return nil
+}
+
 type mockIngestor struct {
 	records []string
 }
@@ -19,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -52,6 +53,7 @@ type Elasticsearch struct {
 	pipelineTagKeys     []string
 	tagKeys             []string
 	tls.ClientConfig
+	proxy.Socks5ProxyConfig
 
 	Client *elastic.Client
 }
@@ -161,6 +163,13 @@ func (a *Elasticsearch) Connect() error {
 	tr := &http.Transport{
 		TLSClientConfig: tlsCfg,
 	}
+	if a.Socks5ProxyEnabled {
+		dialer, err := a.Socks5ProxyConfig.GetProxiedDialer()
+		if err != nil {
+			return fmt.Errorf("creating socks5 proxy dialer failed: %w", err)
+		}
+		tr.DialContext = dialer.DialContext
+	}
 
 	httpclient := &http.Client{
 		Transport: tr,
@@ -0,0 +1,352 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package greptimedb
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip" // Blank import to allow gzip encoding
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GreptimeDB struct {
+	Address            string          `toml:"address"`
+	Database           string          `toml:"database"`
+	Username           config.Secret   `toml:"username"`
+	Password           config.Secret   `toml:"password"`
+	Token              config.Secret   `toml:"token"`
+	TableTemplate      string          `toml:"table_template"`
+	TimestampColumn    string          `toml:"timestamp_column"`
+	TimestampPrecision config.Duration `toml:"timestamp_precision"`
+	Compression        string          `toml:"compression"`
+	Timeout            config.Duration `toml:"timeout"`
+	Log                telegraf.Logger `toml:"-"`
+	tls.ClientConfig
+
+	conn        *grpc.ClientConn
+	client      gpb.GreptimeDatabaseClient
+	tableTmpl   *template.Template
+	tsType      gpb.ColumnDataType
+	callOptions []grpc.CallOption
+}
+
+func (*GreptimeDB) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GreptimeDB) Init() error {
+	if g.Address == "" {
+		return errors.New("address is required")
+	}
+	if g.Database == "" {
+		g.Database = "public"
+	}
+	if g.TimestampColumn == "" {
+		g.TimestampColumn = "greptime_timestamp"
+	}
+
+	if !g.Token.Empty() && (!g.Username.Empty() || !g.Password.Empty()) {
+		return errors.New("either 'token' or 'username' and 'password' can be used")
+	}
+
+	if g.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+
+	if g.TableTemplate != "" {
+		tmpl, err := template.New("table").Parse(g.TableTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing table_template failed: %w", err)
+		}
+		g.tableTmpl = tmpl
+	}
+
+	switch time.Duration(g.TimestampPrecision) {
+	case time.Second:
+		g.tsType = gpb.ColumnDataType_TIMESTAMP_SECOND
+	case 0, time.Millisecond:
+		g.tsType = gpb.ColumnDataType_TIMESTAMP_MILLISECOND
+	case time.Microsecond:
+		g.tsType = gpb.ColumnDataType_TIMESTAMP_MICROSECOND
+	case time.Nanosecond:
+		g.tsType = gpb.ColumnDataType_TIMESTAMP_NANOSECOND
+	default:
+		return fmt.Errorf("invalid timestamp precision %q", time.Duration(g.TimestampPrecision))
+	}
+
+	switch g.Compression {
+	case "", "none":
+	case "gzip":
+		g.callOptions = []grpc.CallOption{grpc.UseCompressor(g.Compression)}
+	default:
+		return fmt.Errorf("invalid compression %q", g.Compression)
+	}
+
+	return nil
+}
+
+func (g *GreptimeDB) Connect() error {
+	creds := insecure.NewCredentials()
+	tlsConfig, err := g.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.Dial(
+		g.Address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUserAgent(internal.ProductToken()),
+	)
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", g.Address, err)
+	}
+	g.conn = conn
+	g.client = gpb.NewGreptimeDatabaseClient(conn)
+
+	return nil
+}
+
+func (g *GreptimeDB) Close() error {
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+func (g *GreptimeDB) Write(metrics []telegraf.Metric) error {
+	inserts := g.rowInserts(metrics)
+	if len(inserts) == 0 {
+		return nil
+	}
+
+	a, err := g.auth()
+	if err != nil {
+		return err
+	}
+	req := &gpb.GreptimeRequest{
+		Header: &gpb.RequestHeader{
+			Dbname:        g.Database,
+			Authorization: a,
+		},
+		Request: &gpb.GreptimeRequest_RowInserts{
+			RowInserts: &gpb.RowInsertRequests{Inserts: inserts},
+		},
+	}
+
+	// A timeout of zero disables the timeout
+	ctx := context.Background()
+	if g.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(g.Timeout))
+		defer cancel()
+	}
+
+	resp, err := g.client.Handle(ctx, req, g.callOptions...)
+	if err != nil {
+		return fmt.Errorf("inserting rows failed: %w", err)
+	}
+	if status := resp.GetHeader().GetStatus(); status.GetStatusCode() != 0 {
+		return fmt.Errorf("inserting rows failed with status %d: %s", status.GetStatusCode(), status.GetErrMsg())
+	}
+	g.Log.Debugf("Inserted %d rows", resp.GetAffectedRows().GetValue())
+
+	return nil
+}
+
+// rowInserts groups the metrics by table. The table schema is the union of
+// all tags and fields of the metrics written to the table.
+func (g *GreptimeDB) rowInserts(metrics []telegraf.Metric) []*gpb.RowInsertRequest {
+	inserts := make([]*gpb.RowInsertRequest, 0)
+	tables := make(map[string]*gpb.RowInsertRequest)
+	columns := make(map[string]map[string]int)
+	for _, m := range metrics {
+		table := g.table(m)
+		insert, found := tables[table]
+		if !found {
+			insert = &gpb.RowInsertRequest{
+				TableName: table,
+				Rows: &gpb.Rows{
+					Schema: []*gpb.ColumnSchema{{
+						ColumnName:   g.TimestampColumn,
+						Datatype:     g.tsType,
+						SemanticType: gpb.SemanticType_TIMESTAMP,
+					}},
+				},
+			}
+			tables[table] = insert
+			columns[table] = map[string]int{g.TimestampColumn: 0}
+			inserts = append(inserts, insert)
+		}
+		rows := insert.Rows
+		index := columns[table]
+
+		values := []*gpb.Value{g.timestamp(m.Time())}
+		set := func(name string, datatype gpb.ColumnDataType, semantic gpb.SemanticType, v *gpb.Value) {
+			i, found := index[name]
+			if !found {
+				i = len(rows.Schema)
+				index[name] = i
+				rows.Schema = append(rows.Schema, &gpb.ColumnSchema{
+					ColumnName:   name,
+					Datatype:     datatype,
+					SemanticType: semantic,
+				})
+			}
+			c := rows.Schema[i]
+			if c.Datatype != datatype || c.SemanticType != semantic {
+				g.Log.Debugf("Dropping column %q of metric %q due to conflicting type", name, m.Name())
+				return
+			}
+			for len(values) <= i {
+				values = append(values, &gpb.Value{})
+			}
+			values[i] = v
+		}
+
+		for _, tag := range m.TagList() {
+			set(tag.Key, gpb.ColumnDataType_STRING, gpb.SemanticType_TAG,
+				&gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: tag.Value}})
+		}
+		for _, field := range m.FieldList() {
+			switch v := field.Value.(type) {
+			case float64:
+				set(field.Key, gpb.ColumnDataType_FLOAT64, gpb.SemanticType_FIELD,
+					&gpb.Value{ValueData: &gpb.Value_F64Value{F64Value: v}})
+			case int64:
+				set(field.Key, gpb.ColumnDataType_INT64, gpb.SemanticType_FIELD,
+					&gpb.Value{ValueData: &gpb.Value_I64Value{I64Value: v}})
+			case uint64:
+				set(field.Key, gpb.ColumnDataType_UINT64, gpb.SemanticType_FIELD,
+					&gpb.Value{ValueData: &gpb.Value_U64Value{U64Value: v}})
+			case bool:
+				set(field.Key, gpb.ColumnDataType_BOOLEAN, gpb.SemanticType_FIELD,
+					&gpb.Value{ValueData: &gpb.Value_BoolValue{BoolValue: v}})
+			case string:
+				set(field.Key, gpb.ColumnDataType_STRING, gpb.SemanticType_FIELD,
+					&gpb.Value{ValueData: &gpb.Value_StringValue{StringValue: v}})
+			default:
+				g.Log.Debugf("Dropping field %q of metric %q with unsupported type %T", field.Key, m.Name(), v)
+			}
+		}
+		rows.Rows = append(rows.Rows, &gpb.Row{Values: values})
+	}
+
+	// Rows added before new columns appeared in the table need to be padded
+	// with null values, i.e. values without data
+	for _, insert := range inserts {
+		for _, row := range insert.Rows.Rows {
+			for len(row.Values) < len(insert.Rows.Schema) {
+				row.Values = append(row.Values, &gpb.Value{})
+			}
+		}
+	}
+
+	return inserts
+}
+
+func (g *GreptimeDB) table(m telegraf.Metric) string {
+	if g.tableTmpl == nil {
+		return m.Name()
+	}
+
+	unwrapped := m
+	if wm, ok := m.(telegraf.UnwrappableMetric); ok {
+		unwrapped = wm.Unwrap()
+	}
+	tm, ok := unwrapped.(telegraf.TemplateMetric)
+	if !ok {
+		g.Log.Debugf("Metric of type %T is not a template metric", m)
+		return m.Name()
+	}
+
+	var buf bytes.Buffer
+	if err := g.tableTmpl.Execute(&buf, tm); err != nil {
+		g.Log.Debugf("Executing table template failed: %v", err)
+		return m.Name()
+	}
+	table := strings.TrimSpace(buf.String())
+	if table == "" {
+		return m.Name()
+	}
+	return table
+}
+
+func (g *GreptimeDB) timestamp(t time.Time) *gpb.Value {
+	switch g.tsType {
+	case gpb.ColumnDataType_TIMESTAMP_SECOND:
+		return &gpb.Value{ValueData: &gpb.Value_TimestampSecondValue{TimestampSecondValue: t.Unix()}}
+	case gpb.ColumnDataType_TIMESTAMP_MICROSECOND:
+		return &gpb.Value{ValueData: &gpb.Value_TimestampMicrosecondValue{TimestampMicrosecondValue: t.UnixMicro()}}
+	case gpb.ColumnDataType_TIMESTAMP_NANOSECOND:
+		return &gpb.Value{ValueData: &gpb.Value_TimestampNanosecondValue{TimestampNanosecondValue: t.UnixNano()}}
+	}
+	return &gpb.Value{ValueData: &gpb.Value_TimestampMillisecondValue{TimestampMillisecondValue: t.UnixMilli()}}
+}
+
+func (g *GreptimeDB) auth() (*gpb.AuthHeader, error) {
+	if !g.Token.Empty() {
+		token, err := g.Token.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting token failed: %w", err)
+		}
+		defer token.Destroy()
+		return &gpb.AuthHeader{
+			AuthScheme: &gpb.AuthHeader_Token{Token: &gpb.Token{Token: token.String()}},
+		}, nil
+	}
+
+	if g.Username.Empty() && g.Password.Empty() {
+		return nil, nil
+	}
+
+	username, err := g.Username.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := g.Password.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	return &gpb.AuthHeader{
+		AuthScheme: &gpb.AuthHeader_Basic{
+			Basic: &gpb.Basic{Username: username.String(), Password: password.String()},
+		},
+	}, nil
+}
+
+func init() {
+	outputs.Add("greptimedb", func() telegraf.Output {
+		return &GreptimeDB{
+			Database:        "public",
+			TimestampColumn: "greptime_timestamp",
+			Compression:     "gzip",
+			Timeout:         config.Duration(5 * time.Second),
+		}
+	})
+}
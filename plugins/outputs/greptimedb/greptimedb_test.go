@@ -0,0 +1,377 @@
+package greptimedb
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	gpb "github.com/GreptimeTeam/greptime-proto/go/greptime/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// columnSchema and rowInsert are simplified forms of the received row-insert
+// requests for comparison
+type columnSchema struct {
+	name     string
+	datatype gpb.ColumnDataType
+	semantic gpb.SemanticType
+}
+
+type rowInsert struct {
+	table  string
+	schema []columnSchema
+	rows   [][]interface{}
+}
+
+type request struct {
+	dbname   string
+	username string
+	password string
+	token    string
+	encoding string
+	inserts  []*rowInsert
+}
+
+type mockServer struct {
+	gpb.UnimplementedGreptimeDatabaseServer
+
+	address string
+	status  uint32
+
+	sync.Mutex
+	requests []*request
+}
+
+func newMockServer(t *testing.T) *mockServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &mockServer{address: listener.Addr().String()}
+	server := grpc.NewServer()
+	gpb.RegisterGreptimeDatabaseServer(server, s)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return s
+}
+
+func (s *mockServer) Handle(ctx context.Context, in *gpb.GreptimeRequest) (*gpb.GreptimeResponse, error) {
+	req := &request{
+		dbname:  in.GetHeader().GetDbname(),
+		inserts: convertRowInserts(in.GetRowInserts().GetInserts()),
+	}
+	switch scheme := in.GetHeader().GetAuthorization().GetAuthScheme().(type) {
+	case *gpb.AuthHeader_Basic:
+		req.username = scheme.Basic.GetUsername()
+		req.password = scheme.Basic.GetPassword()
+	case *gpb.AuthHeader_Token:
+		req.token = scheme.Token.GetToken()
+	}
+	// The encoding header is reserved so it is not part of the metadata
+	if stream, ok := grpc.ServerTransportStreamFromContext(ctx).(interface{ RecvCompress() string }); ok {
+		req.encoding = stream.RecvCompress()
+	}
+
+	s.Lock()
+	s.requests = append(s.requests, req)
+	status := s.status
+	s.Unlock()
+
+	var rows int
+	for _, insert := range req.inserts {
+		rows += len(insert.rows)
+	}
+	return &gpb.GreptimeResponse{
+		Header: &gpb.ResponseHeader{
+			Status: &gpb.Status{StatusCode: status, ErrMsg: "mock error"},
+		},
+		Response: &gpb.GreptimeResponse_AffectedRows{
+			AffectedRows: &gpb.AffectedRows{Value: uint32(rows)},
+		},
+	}, nil
+}
+
+func (s *mockServer) received() []*request {
+	s.Lock()
+	defer s.Unlock()
+	return s.requests
+}
+
+func TestWrite(t *testing.T) {
+	server := newMockServer(t)
+
+	plugin := &GreptimeDB{
+		Address:  server.address,
+		Database: "metrics",
+		Username: config.NewSecret([]byte("user")),
+		Password: config.NewSecret([]byte("secret")),
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Add the fields one by one to get a deterministic column order
+	m1 := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"cores": int64(4)}, time.Unix(1700000000, 0))
+	m1.AddField("usage", 42.5)
+	m2 := metric.New("cpu", map[string]string{"host": "b", "region": "eu"}, map[string]interface{}{"usage": 1.0}, time.Unix(1700000001, 0))
+	m2.AddField("online", true)
+	m3 := metric.New("disk", map[string]string{}, map[string]interface{}{"free": uint64(10)}, time.Unix(1700000002, 0))
+	m3.AddField("path", "/")
+	metrics := []telegraf.Metric{m1, m2, m3}
+	require.NoError(t, plugin.Write(metrics))
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	req := requests[0]
+	require.Equal(t, "metrics", req.dbname)
+	require.Equal(t, "user", req.username)
+	require.Equal(t, "secret", req.password)
+	require.Empty(t, req.token)
+
+	expected := []*rowInsert{
+		{
+			table: "cpu",
+			schema: []columnSchema{
+				{name: "greptime_timestamp", datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND, semantic: gpb.SemanticType_TIMESTAMP},
+				{name: "host", datatype: gpb.ColumnDataType_STRING, semantic: gpb.SemanticType_TAG},
+				{name: "cores", datatype: gpb.ColumnDataType_INT64, semantic: gpb.SemanticType_FIELD},
+				{name: "usage", datatype: gpb.ColumnDataType_FLOAT64, semantic: gpb.SemanticType_FIELD},
+				{name: "region", datatype: gpb.ColumnDataType_STRING, semantic: gpb.SemanticType_TAG},
+				{name: "online", datatype: gpb.ColumnDataType_BOOLEAN, semantic: gpb.SemanticType_FIELD},
+			},
+			rows: [][]interface{}{
+				{int64(1700000000000), "a", int64(4), 42.5, nil, nil},
+				{int64(1700000001000), "b", nil, 1.0, "eu", true},
+			},
+		},
+		{
+			table: "disk",
+			schema: []columnSchema{
+				{name: "greptime_timestamp", datatype: gpb.ColumnDataType_TIMESTAMP_MILLISECOND, semantic: gpb.SemanticType_TIMESTAMP},
+				{name: "free", datatype: gpb.ColumnDataType_UINT64, semantic: gpb.SemanticType_FIELD},
+				{name: "path", datatype: gpb.ColumnDataType_STRING, semantic: gpb.SemanticType_FIELD},
+			},
+			rows: [][]interface{}{
+				{int64(1700000002000), uint64(10), "/"},
+			},
+		},
+	}
+	require.Equal(t, expected, req.inserts)
+}
+
+func TestWriteTableTemplate(t *testing.T) {
+	server := newMockServer(t)
+
+	plugin := &GreptimeDB{
+		Address:            server.address,
+		Token:              config.NewSecret([]byte("mytoken")),
+		TableTemplate:      `{{ .Name }}_{{ .Tag "env" }}`,
+		TimestampColumn:    "ts",
+		TimestampPrecision: config.Duration(time.Nanosecond),
+		Compression:        "gzip",
+		Timeout:            config.Duration(5 * time.Second),
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Tracking metrics need to be unwrapped for the template
+	tm, _ := metric.WithTracking(
+		testutil.MustMetric(
+			"mem",
+			map[string]string{"env": "dev"},
+			map[string]interface{}{"used": int64(2)},
+			time.Unix(0, 1700000000123456789),
+		),
+		func(telegraf.DeliveryInfo) {},
+	)
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"mem",
+			map[string]string{"env": "prod"},
+			map[string]interface{}{"used": int64(1)},
+			time.Unix(0, 1700000000123456789),
+		),
+		tm,
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	requests := server.received()
+	require.Len(t, requests, 1)
+	req := requests[0]
+	require.Equal(t, "public", req.dbname)
+	require.Equal(t, "mytoken", req.token)
+	require.Equal(t, "gzip", req.encoding)
+	require.Len(t, req.inserts, 2)
+	require.Equal(t, "mem_prod", req.inserts[0].table)
+	require.Equal(t, "mem_dev", req.inserts[1].table)
+	require.Equal(t, columnSchema{
+		name:     "ts",
+		datatype: gpb.ColumnDataType_TIMESTAMP_NANOSECOND,
+		semantic: gpb.SemanticType_TIMESTAMP,
+	}, req.inserts[0].schema[0])
+	require.Equal(t, int64(1700000000123456789), req.inserts[0].rows[0][0])
+}
+
+func TestWriteConflictingTypes(t *testing.T) {
+	plugin := &GreptimeDB{
+		Address: "127.0.0.1:4001",
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": "text"}, time.Unix(1, 0)),
+	}
+	inserts := convertRowInserts(plugin.rowInserts(metrics))
+	require.Len(t, inserts, 1)
+	require.Len(t, inserts[0].schema, 2)
+	require.Equal(t, [][]interface{}{{int64(0), 1.0}, {int64(1000), nil}}, inserts[0].rows)
+}
+
+func TestWriteErrorStatus(t *testing.T) {
+	server := newMockServer(t)
+	server.status = 4000
+
+	plugin := &GreptimeDB{
+		Address: server.address,
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "status 4000")
+}
+
+func TestWriteNoTimeout(t *testing.T) {
+	server := newMockServer(t)
+
+	plugin := &GreptimeDB{
+		Address: server.address,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, server.received(), 1)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GreptimeDB
+		expected string
+	}{
+		{
+			name:     "missing address",
+			plugin:   &GreptimeDB{},
+			expected: "address is required",
+		},
+		{
+			name: "token and basic auth",
+			plugin: &GreptimeDB{
+				Address:  "127.0.0.1:4001",
+				Username: config.NewSecret([]byte("user")),
+				Token:    config.NewSecret([]byte("token")),
+			},
+			expected: "either 'token' or 'username' and 'password' can be used",
+		},
+		{
+			name: "invalid precision",
+			plugin: &GreptimeDB{
+				Address:            "127.0.0.1:4001",
+				TimestampPrecision: config.Duration(time.Minute),
+			},
+			expected: "invalid timestamp precision",
+		},
+		{
+			name: "negative timeout",
+			plugin: &GreptimeDB{
+				Address: "127.0.0.1:4001",
+				Timeout: config.Duration(-time.Second),
+			},
+			expected: "timeout must not be negative",
+		},
+		{
+			name: "invalid compression",
+			plugin: &GreptimeDB{
+				Address:     "127.0.0.1:4001",
+				Compression: "zstd",
+			},
+			expected: "invalid compression",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func convertRowInserts(inserts []*gpb.RowInsertRequest) []*rowInsert {
+	converted := make([]*rowInsert, 0, len(inserts))
+	for _, insert := range inserts {
+		c := &rowInsert{table: insert.GetTableName()}
+		for _, column := range insert.GetRows().GetSchema() {
+			c.schema = append(c.schema, columnSchema{
+				name:     column.GetColumnName(),
+				datatype: column.GetDatatype(),
+				semantic: column.GetSemanticType(),
+			})
+		}
+		for _, row := range insert.GetRows().GetRows() {
+			values := make([]interface{}, 0, len(row.GetValues()))
+			for _, v := range row.GetValues() {
+				values = append(values, convertValue(v))
+			}
+			c.rows = append(c.rows, values)
+		}
+		converted = append(converted, c)
+	}
+	return converted
+}
+
+func convertValue(v *gpb.Value) interface{} {
+	switch data := v.GetValueData().(type) {
+	case *gpb.Value_I64Value:
+		return data.I64Value
+	case *gpb.Value_U64Value:
+		return data.U64Value
+	case *gpb.Value_F64Value:
+		return data.F64Value
+	case *gpb.Value_BoolValue:
+		return data.BoolValue
+	case *gpb.Value_StringValue:
+		return data.StringValue
+	case *gpb.Value_TimestampSecondValue:
+		return data.TimestampSecondValue
+	case *gpb.Value_TimestampMillisecondValue:
+		return data.TimestampMillisecondValue
+	case *gpb.Value_TimestampMicrosecondValue:
+		return data.TimestampMicrosecondValue
+	case *gpb.Value_TimestampNanosecondValue:
+		return data.TimestampNanosecondValue
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package grpc
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GRPC struct {
+	Address        string            `toml:"address"`
+	ProtoFile      string            `toml:"proto_file"`
+	ImportPaths    []string          `toml:"import_paths"`
+	Method         string            `toml:"method"`
+	DataField      string            `toml:"data_field"`
+	UseBatchFormat bool              `toml:"use_batch_format"`
+	Metadata       map[string]string `toml:"metadata"`
+	Timeout        config.Duration   `toml:"timeout"`
+	Log            telegraf.Logger   `toml:"-"`
+	tls.ClientConfig
+
+	serializer serializers.Serializer
+	conn       *grpc.ClientConn
+	method     protoreflect.MethodDescriptor
+	methodPath string
+	dataField  protoreflect.FieldDescriptor
+}
+
+func (*GRPC) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GRPC) SetSerializer(serializer serializers.Serializer) {
+	g.serializer = serializer
+}
+
+func (g *GRPC) Init() error {
+	if g.Address == "" {
+		return errors.New("address is required")
+	}
+	if g.ProtoFile == "" {
+		return errors.New("proto_file is required")
+	}
+	if g.DataField == "" {
+		g.DataField = "data"
+	}
+
+	// Split the method into service and method name
+	service, name, found := strings.Cut(strings.TrimPrefix(g.Method, "/"), "/")
+	if !found || service == "" || name == "" {
+		return fmt.Errorf("invalid method %q, expected <package>.<service>/<method>", g.Method)
+	}
+
+	// Load the file descriptors from the given protocol-buffer definition
+	parser := protoparse.Parser{
+		ImportPaths:      g.ImportPaths,
+		InferImportPaths: true,
+	}
+	fds, err := parser.ParseFiles(g.ProtoFile)
+	if err != nil {
+		return fmt.Errorf("parsing protocol-buffer definition in %q failed: %w", g.ProtoFile, err)
+	}
+	registry, err := protodesc.NewFiles(desc.ToFileDescriptorSet(fds...))
+	if err != nil {
+		return fmt.Errorf("constructing registry failed: %w", err)
+	}
+
+	// Lookup the method in the loaded service definitions
+	descriptor, err := registry.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return fmt.Errorf("looking up service %q failed: %w", service, err)
+	}
+	serviceDesc, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return fmt.Errorf("%q is not a service descriptor (%T)", service, descriptor)
+	}
+	g.method = serviceDesc.Methods().ByName(protoreflect.Name(name))
+	if g.method == nil {
+		return fmt.Errorf("service %q has no method %q", service, name)
+	}
+	if g.method.IsStreamingServer() && !g.method.IsStreamingClient() {
+		return fmt.Errorf("server-streaming method %q is not supported", g.Method)
+	}
+	g.methodPath = "/" + service + "/" + name
+
+	// Check the field to store the serialized metrics in
+	g.dataField = g.method.Input().Fields().ByName(protoreflect.Name(g.DataField))
+	if g.dataField == nil {
+		return fmt.Errorf("request message %q has no field %q", g.method.Input().FullName(), g.DataField)
+	}
+	if g.dataField.IsList() || g.dataField.IsMap() ||
+		(g.dataField.Kind() != protoreflect.BytesKind && g.dataField.Kind() != protoreflect.StringKind) {
+		return fmt.Errorf("field %q must be of type 'bytes' or 'string'", g.DataField)
+	}
+
+	return nil
+}
+
+func (g *GRPC) Connect() error {
+	var creds grpc.DialOption
+	if tlsConfig, err := g.ClientConfig.TLSConfig(); err != nil {
+		return err
+	} else if tlsConfig != nil {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	} else {
+		creds = grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+
+	conn, err := grpc.Dial(g.Address, creds, grpc.WithUserAgent(internal.ProductToken()))
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", g.Address, err)
+	}
+	g.conn = conn
+
+	return nil
+}
+
+func (g *GRPC) Close() error {
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+func (g *GRPC) Write(metrics []telegraf.Metric) error {
+	requests, err := g.requests(metrics)
+	if err != nil {
+		return err
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	if g.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(g.Timeout))
+		defer cancel()
+	}
+	if len(g.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(g.Metadata))
+	}
+
+	if g.method.IsStreamingClient() {
+		err = g.stream(ctx, requests)
+	} else {
+		err = g.call(ctx, requests)
+	}
+
+	// Retrying requests rejected by the server will not succeed
+	if status.Code(err) == codes.InvalidArgument {
+		g.Log.Errorf("Metrics rejected by server, dropping them: %v", err)
+		return nil
+	}
+	return err
+}
+
+// call sends each request using a unary call
+func (g *GRPC) call(ctx context.Context, requests []*dynamicpb.Message) error {
+	for _, req := range requests {
+		resp := dynamicpb.NewMessage(g.method.Output())
+		if err := g.conn.Invoke(ctx, g.methodPath, req, resp); err != nil {
+			return fmt.Errorf("calling %q failed: %w", g.methodPath, err)
+		}
+	}
+	return nil
+}
+
+// stream sends all requests over a single client stream
+func (g *GRPC) stream(ctx context.Context, requests []*dynamicpb.Message) error {
+	streamDesc := &grpc.StreamDesc{
+		StreamName:    string(g.method.Name()),
+		ClientStreams: true,
+		ServerStreams: g.method.IsStreamingServer(),
+	}
+	stream, err := g.conn.NewStream(ctx, streamDesc, g.methodPath)
+	if err != nil {
+		return fmt.Errorf("opening stream for %q failed: %w", g.methodPath, err)
+	}
+
+	for _, req := range requests {
+		if err := stream.SendMsg(req); err != nil {
+			// The actual error is returned when receiving the status
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("sending to %q failed: %w", g.methodPath, err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return fmt.Errorf("closing stream for %q failed: %w", g.methodPath, err)
+	}
+
+	// Drain the responses to get the final status of the call
+	for {
+		resp := dynamicpb.NewMessage(g.method.Output())
+		err := stream.RecvMsg(resp)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("streaming to %q failed: %w", g.methodPath, err)
+		}
+		if !g.method.IsStreamingServer() {
+			return nil
+		}
+	}
+}
+
+// requests serializes the metrics into request messages
+func (g *GRPC) requests(metrics []telegraf.Metric) ([]*dynamicpb.Message, error) {
+	if g.UseBatchFormat {
+		buf, err := g.serializer.SerializeBatch(metrics)
+		if err != nil {
+			return nil, fmt.Errorf("serializing metrics failed: %w", err)
+		}
+		return []*dynamicpb.Message{g.request(buf)}, nil
+	}
+
+	requests := make([]*dynamicpb.Message, 0, len(metrics))
+	for _, m := range metrics {
+		buf, err := g.serializer.Serialize(m)
+		if err != nil {
+			g.Log.Debugf("Could not serialize metric: %v", err)
+			continue
+		}
+		requests = append(requests, g.request(buf))
+	}
+	return requests, nil
+}
+
+func (g *GRPC) request(buf []byte) *dynamicpb.Message {
+	req := dynamicpb.NewMessage(g.method.Input())
+	if g.dataField.Kind() == protoreflect.StringKind {
+		req.Set(g.dataField, protoreflect.ValueOfString(string(buf)))
+	} else {
+		req.Set(g.dataField, protoreflect.ValueOfBytes(buf))
+	}
+	return req
+}
+
+func init() {
+	outputs.Add("grpc", func() telegraf.Output {
+		return &GRPC{
+			DataField:      "data",
+			UseBatchFormat: true,
+			Timeout:        config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type call struct {
+	method   string
+	metadata metadata.MD
+	data     []string
+}
+
+type server struct {
+	sync.Mutex
+	calls []call
+	code  codes.Code
+}
+
+// serve starts a server accepting calls to any method using the request and
+// response types of the given method
+func serve(t *testing.T, method protoreflect.MethodDescriptor) (*server, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &server{}
+	handler := func(_ interface{}, stream grpc.ServerStream) error {
+		name, _ := grpc.MethodFromServerStream(stream)
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		c := call{method: name, metadata: md}
+		for {
+			req := dynamicpb.NewMessage(method.Input())
+			err := stream.RecvMsg(req)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			c.data = append(c.data, string(req.Get(req.Descriptor().Fields().ByName("data")).Bytes()))
+		}
+
+		s.Lock()
+		defer s.Unlock()
+		if s.code != codes.OK {
+			return status.Error(s.code, "rejected")
+		}
+		s.calls = append(s.calls, c)
+		return stream.SendMsg(dynamicpb.NewMessage(method.Output()))
+	}
+
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(handler))
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return s, listener.Addr().String()
+}
+
+func newPlugin(t *testing.T, method string, batch bool) (*GRPC, *server) {
+	plugin := &GRPC{
+		Address:        "placeholder",
+		ProtoFile:      "testdata/collector.proto",
+		Method:         method,
+		UseBatchFormat: batch,
+		Metadata:       map[string]string{"authorization": "Bearer secret"},
+		Log:            testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+
+	srv, addr := serve(t, plugin.method)
+	plugin.Address = addr
+	require.NoError(t, plugin.Connect())
+	t.Cleanup(func() { require.NoError(t, plugin.Close()) })
+
+	return plugin, srv
+}
+
+var metrics = []telegraf.Metric{
+	testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+	testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+}
+
+func TestWriteUnary(t *testing.T) {
+	plugin, srv := newPlugin(t, "telegraf.test.Collector/Send", false)
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Len(t, srv.calls, 2)
+	for _, c := range srv.calls {
+		require.Equal(t, "/telegraf.test.Collector/Send", c.method)
+		require.Equal(t, []string{"Bearer secret"}, c.metadata.Get("authorization"))
+	}
+	require.Equal(t, []string{"cpu,host=a value=1 0\n"}, srv.calls[0].data)
+	require.Equal(t, []string{"cpu,host=b value=2 0\n"}, srv.calls[1].data)
+}
+
+func TestWriteUnaryBatch(t *testing.T) {
+	plugin, srv := newPlugin(t, "telegraf.test.Collector/Send", true)
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Len(t, srv.calls, 1)
+	require.Equal(t, []string{"cpu,host=a value=1 0\ncpu,host=b value=2 0\n"}, srv.calls[0].data)
+}
+
+func TestWriteStream(t *testing.T) {
+	plugin, srv := newPlugin(t, "telegraf.test.Collector/Stream", false)
+	require.NoError(t, plugin.Write(metrics))
+
+	// All metrics are sent over a single stream
+	require.Len(t, srv.calls, 1)
+	require.Equal(t, "/telegraf.test.Collector/Stream", srv.calls[0].method)
+	require.Equal(t, []string{"cpu,host=a value=1 0\n", "cpu,host=b value=2 0\n"}, srv.calls[0].data)
+}
+
+func TestWriteError(t *testing.T) {
+	plugin, srv := newPlugin(t, "telegraf.test.Collector/Stream", true)
+
+	srv.Lock()
+	srv.code = codes.Unavailable
+	srv.Unlock()
+	require.ErrorContains(t, plugin.Write(metrics), "rejected")
+
+	// Invalid requests are dropped
+	srv.Lock()
+	srv.code = codes.InvalidArgument
+	srv.Unlock()
+	require.NoError(t, plugin.Write(metrics))
+	require.Empty(t, srv.calls)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		field    string
+		expected string
+	}{
+		{
+			name:     "invalid method",
+			method:   "Send",
+			expected: `invalid method "Send"`,
+		},
+		{
+			name:     "unknown service",
+			method:   "telegraf.test.Unknown/Send",
+			expected: `looking up service "telegraf.test.Unknown" failed`,
+		},
+		{
+			name:     "unknown method",
+			method:   "telegraf.test.Collector/Unknown",
+			expected: `service "telegraf.test.Collector" has no method "Unknown"`,
+		},
+		{
+			name:     "server streaming",
+			method:   "telegraf.test.Collector/Subscribe",
+			expected: "server-streaming method",
+		},
+		{
+			name:     "unknown field",
+			method:   "telegraf.test.Collector/Send",
+			field:    "payload",
+			expected: `request message "telegraf.test.Batch" has no field "payload"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &GRPC{
+				Address:   "localhost:50051",
+				ProtoFile: "testdata/collector.proto",
+				Method:    tt.method,
+				DataField: tt.field,
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
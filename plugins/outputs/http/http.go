@@ -5,25 +5,18 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"crypto/sha256"
 	_ "embed"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-	"time"
-
-	awsV2 "github.com/aws/aws-sdk-go-v2/aws"
-	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
-	"golang.org/x/oauth2"
-	"google.golang.org/api/idtoken"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
 	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -53,33 +46,56 @@ type HTTP struct {
 	AwsService              string            `toml:"aws_service"`
 	NonRetryableStatusCodes []int             `toml:"non_retryable_statuscodes"`
 	httpconfig.HTTPClientConfig
+	proxy.Socks5ProxyConfig
 	Log telegraf.Logger `toml:"-"`
 
 	client     *http.Client
 	serializer serializers.Serializer
+	signers    []requestSigner
 
-	awsCfg *awsV2.Config
 	internalaws.CredentialConfig
 
 	// Google API Auth
-	CredentialsFile string `toml:"google_application_credentials"`
-	oauth2Token     *oauth2.Token
+	CredentialsFile string   `toml:"google_application_credentials"`
+	GoogleTokenType string   `toml:"google_token_type"`
+	GoogleScopes    []string `toml:"google_scopes"`
 }
 
 func (*HTTP) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *HTTP) Init() error {
+	switch h.GoogleTokenType {
+	case "", "id_token", "access_token":
+	default:
+		return fmt.Errorf("invalid google_token_type %q", h.GoogleTokenType)
+	}
+	return nil
+}
+
 func (h *HTTP) SetSerializer(serializer serializers.Serializer) {
 	h.serializer = serializer
 }
 
 func (h *HTTP) Connect() error {
+	ctx := context.Background()
+
+	h.signers = make([]requestSigner, 0, 2)
 	if h.AwsService != "" {
 		cfg, err := h.CredentialConfig.Credentials()
-		if err == nil {
-			h.awsCfg = &cfg
+		if err != nil {
+			return fmt.Errorf("getting AWS credentials failed: %w", err)
+		}
+		h.signers = append(h.signers, &awsSigner{cfg: cfg, service: h.AwsService, region: h.Region})
+	}
+
+	if h.CredentialsFile != "" {
+		signer, err := newGoogleSigner(ctx, h.CredentialsFile, h.GoogleTokenType, h.URL, h.GoogleScopes)
+		if err != nil {
+			return err
 		}
+		h.signers = append(h.signers, signer)
 	}
 
 	if h.Method == "" {
@@ -90,7 +106,7 @@ func (h *HTTP) Connect() error {
 		return fmt.Errorf("invalid method [%s] %s", h.URL, h.Method)
 	}
 
-	ctx := context.Background()
+	h.HTTPClientConfig.Socks5 = &h.Socks5ProxyConfig
 	client, err := h.HTTPClientConfig.CreateClient(ctx, h.Log)
 	if err != nil {
 		return err
@@ -138,21 +154,15 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		reqBodyBuffer = rc
 	}
 
-	var payloadHash *string
-	if h.awsCfg != nil {
-		// We need a local copy of the full buffer, the signature scheme requires a sha256 of the request body.
-		buf := new(bytes.Buffer)
-		_, err = io.Copy(buf, reqBodyBuffer)
+	// Signers might require the complete payload so we need a local copy of
+	// the (possibly encoded) body.
+	var payload []byte
+	if len(h.signers) > 0 {
+		payload, err = io.ReadAll(reqBodyBuffer)
 		if err != nil {
 			return err
 		}
-
-		sum := sha256.Sum256(buf.Bytes())
-		reqBodyBuffer = buf
-
-		// sha256 is hex encoded
-		hash := hex.EncodeToString(sum[:])
-		payloadHash = &hash
+		reqBodyBuffer = bytes.NewReader(payload)
 	}
 
 	req, err := http.NewRequest(h.Method, h.URL, reqBodyBuffer)
@@ -160,21 +170,6 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		return err
 	}
 
-	if h.awsCfg != nil {
-		signer := v4.NewSigner()
-		ctx := context.Background()
-
-		credentials, err := h.awsCfg.Credentials.Retrieve(ctx)
-		if err != nil {
-			return err
-		}
-
-		err = signer.SignHTTP(ctx, credentials, req, *payloadHash, h.AwsService, h.Region, time.Now().UTC())
-		if err != nil {
-			return err
-		}
-	}
-
 	if !h.Username.Empty() || !h.Password.Empty() {
 		username, err := h.Username.Get()
 		if err != nil {
@@ -190,15 +185,6 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		password.Destroy()
 	}
 
-	// google api auth
-	if h.CredentialsFile != "" {
-		token, err := h.getAccessToken(context.Background(), h.URL)
-		if err != nil {
-			return err
-		}
-		token.SetAuthHeader(req)
-	}
-
 	req.Header.Set("User-Agent", internal.ProductToken())
 	req.Header.Set("Content-Type", defaultContentType)
 	if h.ContentEncoding == "gzip" {
@@ -211,6 +197,12 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		req.Header.Set(k, v)
 	}
 
+	for _, signer := range h.signers {
+		if err := signer.Sign(req.Context(), req, payload); err != nil {
+			return fmt.Errorf("signing request failed: %w", err)
+		}
+	}
+
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return err
@@ -251,23 +243,3 @@ func init() {
 		}
 	})
 }
-
-func (h *HTTP) getAccessToken(ctx context.Context, audience string) (*oauth2.Token, error) {
-	if h.oauth2Token.Valid() {
-		return h.oauth2Token, nil
-	}
-
-	ts, err := idtoken.NewTokenSource(ctx, audience, idtoken.WithCredentialsFile(h.CredentialsFile))
-	if err != nil {
-		return nil, fmt.Errorf("error creating oauth2 token source: %w", err)
-	}
-
-	token, err := ts.Token()
-	if err != nil {
-		return nil, fmt.Errorf("error fetching oauth2 token: %w", err)
-	}
-
-	h.oauth2Token = token
-
-	return token, nil
-}
@@ -2,8 +2,10 @@ package http
 
 import (
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/armon/go-socks5"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -20,6 +23,7 @@ import (
 	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
 	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/common/oauth"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
@@ -445,6 +449,82 @@ func TestBasicAuth(t *testing.T) {
 	}
 }
 
+// socks5Rules records the destinations of all proxied connections
+type socks5Rules struct {
+	destinations chan string
+}
+
+func (r *socks5Rules) Allow(ctx context.Context, req *socks5.Request) (context.Context, bool) {
+	r.destinations <- req.DestAddr.Address()
+	return ctx, true
+}
+
+func TestSocks5Proxy(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	rules := &socks5Rules{destinations: make(chan string, 10)}
+	server, err := socks5.New(&socks5.Config{
+		AuthMethods: []socks5.Authenticator{socks5.UserPassAuthenticator{
+			Credentials: socks5.StaticCredentials{"user": "password"},
+		}},
+		Rules: rules,
+	})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	tests := []struct {
+		name     string
+		password string
+		err      bool
+	}{
+		{
+			name:     "valid credentials",
+			password: "password",
+		},
+		{
+			name:     "invalid credentials",
+			password: "wrong",
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &HTTP{
+				URL:    ts.URL,
+				Method: defaultMethod,
+				Socks5ProxyConfig: proxy.Socks5ProxyConfig{
+					Socks5ProxyEnabled:  true,
+					Socks5ProxyAddress:  listener.Addr().String(),
+					Socks5ProxyUsername: "user",
+					Socks5ProxyPassword: tt.password,
+				},
+			}
+
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+			plugin.SetSerializer(serializer)
+			require.NoError(t, plugin.Connect())
+
+			err := plugin.Write([]telegraf.Metric{getMetric()})
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, ts.Listener.Addr().String(), <-rules.destinations)
+		})
+	}
+}
+
 type TestHandlerFunc func(t *testing.T, w http.ResponseWriter, r *http.Request)
 
 func TestOAuthClientCredentialsGrant(t *testing.T) {
@@ -527,6 +607,37 @@ func TestOAuthClientCredentialsGrant(t *testing.T) {
 				w.WriteHeader(http.StatusOK)
 			},
 		},
+		{
+			name: "credentials in params",
+			plugin: &HTTP{
+				URL: u.String() + "/write",
+				HTTPClientConfig: httpconfig.HTTPClientConfig{
+					OAuth2Config: oauth.OAuth2Config{
+						ClientID:     "howdy",
+						ClientSecret: "secret",
+						TokenURL:     u.String() + "/token",
+						AuthStyle:    "params",
+					},
+				},
+			},
+			tokenHandler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				require.Empty(t, r.Header["Authorization"])
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "howdy", r.PostForm.Get("client_id"))
+				require.Equal(t, "secret", r.PostForm.Get("client_secret"))
+				w.WriteHeader(http.StatusOK)
+				values := url.Values{}
+				values.Add("access_token", token)
+				values.Add("token_type", "bearer")
+				values.Add("expires_in", "3600")
+				_, err = w.Write([]byte(values.Encode()))
+				require.NoError(t, err)
+			},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, []string{"Bearer " + token}, r.Header["Authorization"])
+				w.WriteHeader(http.StatusOK)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -552,6 +663,21 @@ func TestOAuthClientCredentialsGrant(t *testing.T) {
 	}
 }
 
+func TestOAuthInvalidAuthStyle(t *testing.T) {
+	plugin := &HTTP{
+		URL: "http://127.0.0.1/write",
+		HTTPClientConfig: httpconfig.HTTPClientConfig{
+			OAuth2Config: oauth.OAuth2Config{
+				ClientID:     "howdy",
+				ClientSecret: "secret",
+				TokenURL:     "http://127.0.0.1/token",
+				AuthStyle:    "foo",
+			},
+		},
+	}
+	require.ErrorContains(t, plugin.Connect(), "invalid token_auth_style")
+}
+
 func TestOAuthAuthorizationCodeGrant(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -627,6 +753,24 @@ func TestOAuthAuthorizationCodeGrant(t *testing.T) {
 				w.WriteHeader(http.StatusOK)
 			},
 		},
+		{
+			name: "access token",
+			plugin: &HTTP{
+				URL:             u.String() + "/write",
+				CredentialsFile: tmpFile.Name(),
+				GoogleTokenType: "access_token",
+			},
+			tokenHandler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_, err = w.Write([]byte(`{"access_token":"ya29.token","token_type":"Bearer","expires_in":3600}`))
+				require.NoError(t, err)
+			},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, []string{"Bearer ya29.token"}, r.Header["Authorization"])
+				w.WriteHeader(http.StatusOK)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -643,6 +787,7 @@ func TestOAuthAuthorizationCodeGrant(t *testing.T) {
 			require.NoError(t, serializer.Init())
 			tt.plugin.SetSerializer(serializer)
 
+			require.NoError(t, tt.plugin.Init())
 			require.NoError(t, tt.plugin.Connect())
 			require.NoError(t, tt.plugin.Write([]telegraf.Metric{getMetric()}))
 			require.NoError(t, err)
@@ -650,6 +795,15 @@ func TestOAuthAuthorizationCodeGrant(t *testing.T) {
 	}
 }
 
+func TestGoogleInvalidTokenType(t *testing.T) {
+	plugin := &HTTP{
+		URL:             "http://127.0.0.1/write",
+		CredentialsFile: "testdata/does-not-matter.json",
+		GoogleTokenType: "foo",
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid google_token_type")
+}
+
 func TestDefaultUserAgent(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -761,6 +915,25 @@ func TestAwsCredentials(t *testing.T) {
 				w.WriteHeader(http.StatusOK)
 			},
 		},
+		{
+			name: "compressed payload",
+			plugin: &HTTP{
+				URL:             u.String(),
+				AwsService:      "execute-api",
+				ContentEncoding: "gzip",
+				CredentialConfig: internalaws.CredentialConfig{
+					Region:    "eu-central-1",
+					AccessKey: "dummy",
+					SecretKey: "dummy",
+				},
+			},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				require.Contains(t, r.Header["Authorization"][0], "AWS4-HMAC-SHA256")
+				require.Contains(t, r.Header["Authorization"][0], "/eu-central-1/execute-api/")
+				require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+				w.WriteHeader(http.StatusOK)
+			},
+		},
 	}
 
 	for _, tt := range tests {
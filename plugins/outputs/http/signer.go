@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	awsV2 "github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/idtoken"
+)
+
+// requestSigner adds authentication information to a request before it is
+// sent. The complete (possibly encoded) request body is passed to allow
+// signature schemes covering the payload.
+type requestSigner interface {
+	Sign(ctx context.Context, req *http.Request, body []byte) error
+}
+
+// awsSigner signs requests using AWS Signature Version 4 e.g. for Amazon
+// Managed Prometheus or API Gateway endpoints.
+type awsSigner struct {
+	cfg     awsV2.Config
+	service string
+	region  string
+}
+
+func (s *awsSigner) Sign(ctx context.Context, req *http.Request, body []byte) error {
+	credentials, err := s.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The signature scheme requires a hex encoded sha256 of the request body
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	signer := v4.NewSigner()
+	return signer.SignHTTP(ctx, credentials, req, payloadHash, s.service, s.region, time.Now().UTC())
+}
+
+// googleSigner adds a bearer token obtained from a Google service account to
+// the request. Depending on the token type, an ID token for the given audience
+// (e.g. Cloud Run or Cloud Functions) or an OAuth2 access token for Google APIs
+// is used.
+type googleSigner struct {
+	source oauth2.TokenSource
+}
+
+func newGoogleSigner(ctx context.Context, credentialsFile, tokenType, audience string, scopes []string) (*googleSigner, error) {
+	var source oauth2.TokenSource
+	switch tokenType {
+	case "access_token":
+		data, err := os.ReadFile(credentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading credentials file failed: %w", err)
+		}
+		if len(scopes) == 0 {
+			scopes = []string{"https://www.googleapis.com/auth/cloud-platform"}
+		}
+		creds, err := google.CredentialsFromJSON(ctx, data, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("error creating oauth2 token source: %w", err)
+		}
+		source = creds.TokenSource
+	default:
+		ts, err := idtoken.NewTokenSource(ctx, audience, idtoken.WithCredentialsFile(credentialsFile))
+		if err != nil {
+			return nil, fmt.Errorf("error creating oauth2 token source: %w", err)
+		}
+		source = ts
+	}
+
+	// Cache the token until it expires
+	return &googleSigner{source: oauth2.ReuseTokenSource(nil, source)}, nil
+}
+
+func (s *googleSigner) Sign(_ context.Context, req *http.Request, _ []byte) error {
+	token, err := s.source.Token()
+	if err != nil {
+		return fmt.Errorf("error fetching oauth2 token: %w", err)
+	}
+	token.SetAuthHeader(req)
+	return nil
+}
@@ -0,0 +1,199 @@
+package iceberg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+const maxErrMsgLen = 1024
+
+var (
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("commit conflict")
+)
+
+// catalog is a client for the Iceberg REST catalog API, see
+// https://github.com/apache/iceberg/blob/main/open-api/rest-catalog-open-api.yaml
+type catalog struct {
+	client    *http.Client
+	url       string
+	warehouse string
+	token     config.Secret
+	namespace []string
+	table     string
+
+	// base is the URL including the prefix returned by the catalog config
+	base string
+}
+
+type tableResponse struct {
+	MetadataLocation string         `json:"metadata-location"`
+	Metadata         *tableMetadata `json:"metadata"`
+}
+
+type createTableRequest struct {
+	Name          string            `json:"name"`
+	Location      string            `json:"location,omitempty"`
+	Schema        *schema           `json:"schema"`
+	PartitionSpec *partitionSpec    `json:"partition-spec"`
+	Properties    map[string]string `json:"properties,omitempty"`
+}
+
+type identifier struct {
+	Namespace []string `json:"namespace"`
+	Name      string   `json:"name"`
+}
+
+type commitRequest struct {
+	Identifier   identifier    `json:"identifier"`
+	Requirements []interface{} `json:"requirements"`
+	Updates      []interface{} `json:"updates"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// init retrieves the catalog configuration to determine the path prefix of
+// the warehouse
+func (c *catalog) init(ctx context.Context) error {
+	endpoint := strings.TrimSuffix(c.url, "/") + "/v1/config"
+	if c.warehouse != "" {
+		endpoint += "?warehouse=" + url.QueryEscape(c.warehouse)
+	}
+
+	var cfg struct {
+		Overrides map[string]string `json:"overrides"`
+		Defaults  map[string]string `json:"defaults"`
+	}
+	if err := c.do(ctx, http.MethodGet, endpoint, nil, &cfg); err != nil {
+		return fmt.Errorf("getting catalog config failed: %w", err)
+	}
+
+	prefix := cfg.Overrides["prefix"]
+	if prefix == "" {
+		prefix = cfg.Defaults["prefix"]
+	}
+	c.base = strings.TrimSuffix(c.url, "/") + "/v1"
+	if prefix != "" {
+		c.base += "/" + strings.Trim(prefix, "/")
+	}
+	return nil
+}
+
+func (c *catalog) tablesURL() string {
+	// Namespace levels are separated by the unit separator character
+	return c.base + "/namespaces/" + url.PathEscape(strings.Join(c.namespace, "\x1f")) + "/tables"
+}
+
+func (c *catalog) loadTable(ctx context.Context) (*tableMetadata, error) {
+	var resp tableResponse
+	if err := c.do(ctx, http.MethodGet, c.tablesURL()+"/"+url.PathEscape(c.table), nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Metadata == nil {
+		return nil, errors.New("response contains no metadata")
+	}
+	return resp.Metadata, nil
+}
+
+func (c *catalog) createTable(ctx context.Context, req *createTableRequest) (*tableMetadata, error) {
+	req.Name = c.table
+
+	var resp tableResponse
+	if err := c.do(ctx, http.MethodPost, c.tablesURL(), req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Metadata == nil {
+		return nil, errors.New("response contains no metadata")
+	}
+	return resp.Metadata, nil
+}
+
+// commit applies the updates to the table if all requirements are met. An
+// error wrapping errConflict is returned if the table changed concurrently.
+func (c *catalog) commit(ctx context.Context, requirements, updates []interface{}) (*tableMetadata, error) {
+	req := &commitRequest{
+		Identifier:   identifier{Namespace: c.namespace, Name: c.table},
+		Requirements: requirements,
+		Updates:      updates,
+	}
+
+	var resp tableResponse
+	if err := c.do(ctx, http.MethodPost, c.tablesURL()+"/"+url.PathEscape(c.table), req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Metadata == nil {
+		return nil, errors.New("response contains no metadata")
+	}
+	return resp.Metadata, nil
+}
+
+func (c *catalog) do(ctx context.Context, method, endpoint string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if !c.token.Empty() {
+		token, err := c.token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrMsgLen))
+		msg := strings.TrimSpace(string(b))
+		var e errorResponse
+		if json.Unmarshal(b, &e) == nil && e.Error.Message != "" {
+			msg = e.Error.Type + ": " + e.Error.Message
+		}
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s", errNotFound, msg)
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %s", errConflict, msg)
+		}
+		return fmt.Errorf("%s %q failed with status %d: %s", method, endpoint, resp.StatusCode, msg)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
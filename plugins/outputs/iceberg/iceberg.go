@@ -0,0 +1,709 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package iceberg
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/apache/arrow/go/v13/parquet"
+	"github.com/apache/arrow/go/v13/parquet/compress"
+	"github.com/apache/arrow/go/v13/parquet/pqarrow"
+	"github.com/gofrs/uuid/v5"
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var codecs = map[string]compress.Compression{
+	"none":   compress.Codecs.Uncompressed,
+	"snappy": compress.Codecs.Snappy,
+	"gzip":   compress.Codecs.Gzip,
+	"zstd":   compress.Codecs.Zstd,
+}
+
+type Iceberg struct {
+	CatalogURL      string          `toml:"catalog_url"`
+	Warehouse       string          `toml:"warehouse"`
+	Token           config.Secret   `toml:"token"`
+	Namespace       string          `toml:"namespace"`
+	Table           string          `toml:"table"`
+	CreateTable     bool            `toml:"create_table"`
+	Location        string          `toml:"location"`
+	PartitionBy     string          `toml:"partition_by"`
+	SchemaEvolution bool            `toml:"schema_evolution"`
+	NameColumn      string          `toml:"name_column"`
+	TimestampColumn string          `toml:"timestamp_column"`
+	Compression     string          `toml:"compression"`
+	CommitRetries   int             `toml:"commit_retries"`
+	S3              S3Config        `toml:"s3"`
+	Log             telegraf.Logger `toml:"-"`
+	httpconfig.HTTPClientConfig
+
+	catalog *catalog
+	storage *storage
+	props   *parquet.WriterProperties
+	table   *table
+}
+
+func (*Iceberg) SampleConfig() string {
+	return sampleConfig
+}
+
+func (i *Iceberg) Init() error {
+	if i.CatalogURL == "" {
+		return errors.New("catalog_url is required")
+	}
+	if i.Namespace == "" {
+		return errors.New("namespace is required")
+	}
+	if i.Table == "" {
+		return errors.New("table is required")
+	}
+
+	switch i.PartitionBy {
+	case "":
+		i.PartitionBy = "day"
+	case "none", "year", "month", "day", "hour":
+	default:
+		return fmt.Errorf("invalid partition_by %q", i.PartitionBy)
+	}
+
+	if i.NameColumn == "" {
+		i.NameColumn = "name"
+	}
+	if i.TimestampColumn == "" {
+		i.TimestampColumn = "time"
+	}
+	if i.NameColumn == i.TimestampColumn {
+		return errors.New("name_column and timestamp_column must differ")
+	}
+
+	if i.Compression == "" {
+		i.Compression = "zstd"
+	}
+	codec, found := codecs[i.Compression]
+	if !found {
+		return fmt.Errorf("invalid compression %q", i.Compression)
+	}
+	i.props = parquet.NewWriterProperties(parquet.WithCompression(codec))
+
+	if i.CommitRetries < 0 {
+		return errors.New("commit_retries must not be negative")
+	}
+
+	return nil
+}
+
+func (i *Iceberg) Connect() error {
+	client, err := i.HTTPClientConfig.CreateClient(context.Background(), i.Log)
+	if err != nil {
+		return err
+	}
+	i.catalog = &catalog{
+		client:    client,
+		url:       i.CatalogURL,
+		warehouse: i.Warehouse,
+		token:     i.Token,
+		namespace: strings.Split(i.Namespace, "."),
+		table:     i.Table,
+	}
+
+	i.storage, err = newStorage(&i.S3)
+	return err
+}
+
+func (i *Iceberg) Close() error {
+	if i.catalog != nil {
+		i.catalog.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Write appends the metrics to the table by writing a data file per partition
+// and committing a new snapshot containing the files.
+func (i *Iceberg) Write(metrics []telegraf.Metric) error {
+	ctx := context.Background()
+
+	// Load the table on the first write to not fail on startup if the
+	// catalog is unavailable
+	if i.table == nil {
+		if err := i.load(ctx, metrics); err != nil {
+			return err
+		}
+	}
+
+	if i.SchemaEvolution {
+		if err := i.evolve(ctx, metrics); err != nil {
+			return err
+		}
+	}
+
+	files, err := i.writeDataFiles(ctx, metrics)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return i.commit(ctx, files)
+}
+
+// load loads the table from the catalog and creates it with a schema derived
+// from the metrics if it does not exist
+func (i *Iceberg) load(ctx context.Context, metrics []telegraf.Metric) error {
+	if i.catalog.base == "" {
+		if err := i.catalog.init(ctx); err != nil {
+			return err
+		}
+	}
+
+	meta, err := i.catalog.loadTable(ctx)
+	if errors.Is(err, errNotFound) && i.CreateTable {
+		i.Log.Infof("Creating table %s.%s", i.Namespace, i.Table)
+		meta, err = i.catalog.createTable(ctx, i.createRequest(metrics))
+		if err != nil {
+			return fmt.Errorf("creating table failed: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("loading table failed: %w", err)
+	}
+
+	return i.use(meta)
+}
+
+func (i *Iceberg) use(meta *tableMetadata) error {
+	t, err := newTable(meta, i.NameColumn, i.TimestampColumn)
+	if err != nil {
+		return fmt.Errorf("table %s.%s: %w", i.Namespace, i.Table, err)
+	}
+	i.table = t
+	return nil
+}
+
+func (i *Iceberg) createRequest(metrics []telegraf.Metric) *createTableRequest {
+	fields := []*field{
+		newField(1, i.TimestampColumn, "timestamptz", true),
+		newField(2, i.NameColumn, "string", true),
+	}
+	for _, c := range i.newColumns(nil, metrics) {
+		c.ID = len(fields) + 1
+		fields = append(fields, c)
+	}
+
+	spec := &partitionSpec{Fields: []*partitionField{}}
+	if i.PartitionBy != "none" {
+		spec.Fields = append(spec.Fields, &partitionField{
+			Name:      i.TimestampColumn + "_" + i.PartitionBy,
+			Transform: i.PartitionBy,
+			SourceID:  1,
+			FieldID:   1000,
+		})
+	}
+
+	return &createTableRequest{
+		Location:      i.Location,
+		Schema:        &schema{Type: "struct", Fields: fields},
+		PartitionSpec: spec,
+		Properties:    map[string]string{"write.format.default": "parquet"},
+	}
+}
+
+// newColumns returns the optional columns for tags and fields of the metrics
+// not contained in the given schema, sorted by name
+func (i *Iceberg) newColumns(current *schema, metrics []telegraf.Metric) []*field {
+	existing := map[string]bool{
+		i.NameColumn:      true,
+		i.TimestampColumn: true,
+	}
+	if current != nil {
+		for _, f := range current.Fields {
+			existing[f.Name] = true
+		}
+	}
+
+	added := make(map[string]string)
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			if !existing[tag.Key] {
+				added[tag.Key] = "string"
+			}
+		}
+		for _, f := range m.FieldList() {
+			if existing[f.Key] {
+				continue
+			}
+			if _, found := added[f.Key]; found {
+				continue
+			}
+			if kind := icebergType(f.Value); kind != "" {
+				added[f.Key] = kind
+			}
+		}
+	}
+
+	names := make([]string, 0, len(added))
+	for name := range added {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	columns := make([]*field, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, newField(0, name, added[name], false))
+	}
+	return columns
+}
+
+// evolve adds columns for new tags and fields to the table schema
+func (i *Iceberg) evolve(ctx context.Context, metrics []telegraf.Metric) error {
+	for attempt := 0; ; attempt++ {
+		current := i.table.schema
+		columns := i.newColumns(current, metrics)
+		if len(columns) == 0 {
+			return nil
+		}
+
+		lastID := i.table.meta.LastColumnID
+		fields := append(make([]*field, 0, len(current.Fields)+len(columns)), current.Fields...)
+		for _, c := range columns {
+			lastID++
+			c.ID = lastID
+			fields = append(fields, c)
+		}
+
+		maxSchemaID := 0
+		for _, s := range i.table.meta.Schemas {
+			maxSchemaID = max(maxSchemaID, s.SchemaID)
+		}
+		requirements := []interface{}{
+			map[string]interface{}{"type": "assert-table-uuid", "uuid": i.table.meta.TableUUID},
+			map[string]interface{}{"type": "assert-current-schema-id", "current-schema-id": current.SchemaID},
+			map[string]interface{}{"type": "assert-last-assigned-field-id", "last-assigned-field-id": i.table.meta.LastColumnID},
+		}
+		updates := []interface{}{
+			map[string]interface{}{
+				"action": "add-schema",
+				"schema": &schema{
+					Type:               "struct",
+					SchemaID:           maxSchemaID + 1,
+					IdentifierFieldIDs: current.IdentifierFieldIDs,
+					Fields:             fields,
+				},
+				"last-column-id": lastID,
+			},
+			// Use the last added schema
+			map[string]interface{}{"action": "set-current-schema", "schema-id": -1},
+		}
+
+		meta, err := i.catalog.commit(ctx, requirements, updates)
+		if err == nil {
+			i.Log.Debugf("Added %d columns to table %s.%s", len(columns), i.Namespace, i.Table)
+			return i.use(meta)
+		}
+		if !errors.Is(err, errConflict) || attempt >= i.CommitRetries {
+			return fmt.Errorf("updating schema failed: %w", err)
+		}
+		i.Log.Debugf("Retrying schema update: %v", err)
+		if err := i.load(ctx, nil); err != nil {
+			return err
+		}
+	}
+}
+
+// writeDataFiles writes the metrics to a parquet file per partition
+func (i *Iceberg) writeDataFiles(ctx context.Context, metrics []telegraf.Metric) ([]*dataFile, error) {
+	t := i.table
+
+	fields := make([]arrow.Field, 0, len(t.columns))
+	for _, c := range t.columns {
+		fields = append(fields, arrow.Field{
+			Name:     c.field.Name,
+			Type:     arrowTypes[c.kind],
+			Nullable: !c.field.Required,
+			Metadata: arrow.NewMetadata([]string{"PARQUET:field_id"}, []string{strconv.Itoa(c.field.ID)}),
+		})
+	}
+	arrowSchema := arrow.NewSchema(fields, nil)
+
+	// Group the rows by partition
+	type group struct {
+		partition map[string]interface{}
+		builder   *array.RecordBuilder
+		records   int64
+	}
+	var paths []string
+	groups := make(map[string]*group)
+	defer func() {
+		for _, g := range groups {
+			g.builder.Release()
+		}
+	}()
+
+	row := make([]interface{}, len(t.columns))
+	index := make(map[*column]int, len(t.columns))
+	for idx, c := range t.columns {
+		index[c] = idx
+	}
+	for _, m := range metrics {
+		complete := true
+		for idx, c := range t.columns {
+			row[idx] = i.value(c, m)
+			if row[idx] == nil && c.field.Required {
+				i.Log.Debugf("Dropping metric %q without value for required column %q", m.Name(), c.field.Name)
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			continue
+		}
+
+		partition := make(map[string]interface{}, len(t.partitions))
+		elements := make([]string, 0, len(t.partitions))
+		for _, p := range t.partitions {
+			v, element := p.value(row[index[p.source]])
+			if v == nil {
+				partition[avroName(p.field.Name)] = nil
+			} else {
+				partition[avroName(p.field.Name)] = goavro.Union(p.avroType(), v)
+			}
+			elements = append(elements, p.field.Name+"="+element)
+		}
+		path := strings.Join(elements, "/")
+
+		g, found := groups[path]
+		if !found {
+			g = &group{
+				partition: partition,
+				builder:   array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema),
+			}
+			groups[path] = g
+			paths = append(paths, path)
+		}
+		for idx, v := range row {
+			appendValue(g.builder.Field(idx), v)
+		}
+		g.records++
+	}
+
+	files := make([]*dataFile, 0, len(paths))
+	for _, path := range paths {
+		g := groups[path]
+		record := g.builder.NewRecord()
+		buf, err := i.encode(arrowSchema, record)
+		record.Release()
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, fmt.Errorf("generating file name failed: %w", err)
+		}
+		location := t.meta.dataLocation() + "/"
+		if path != "" {
+			location += path + "/"
+		}
+		location += id.String() + ".parquet"
+		if err := i.storage.write(ctx, location, buf); err != nil {
+			return nil, err
+		}
+
+		files = append(files, &dataFile{
+			path:      location,
+			size:      int64(len(buf)),
+			records:   g.records,
+			partition: g.partition,
+		})
+	}
+	return files, nil
+}
+
+func (i *Iceberg) encode(schema *arrow.Schema, record arrow.Record) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := pqarrow.NewFileWriter(schema, &buf, i.props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, fmt.Errorf("creating parquet writer failed: %w", err)
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, fmt.Errorf("writing parquet data failed: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("closing parquet writer failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// commit adds a snapshot containing the data files to the main branch of the
+// table retrying on concurrent modifications
+func (i *Iceberg) commit(ctx context.Context, files []*dataFile) error {
+	snapshotID := rand.Int63() //nolint:gosec // G404: not security critical
+
+	spec := i.table.spec
+	manifest, err := writeManifest(i.table, snapshotID, files)
+	if err != nil {
+		return fmt.Errorf("encoding manifest failed: %w", err)
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return fmt.Errorf("generating file name failed: %w", err)
+	}
+	manifestPath := fmt.Sprintf("%s/%s-m0.avro", i.table.meta.metadataLocation(), id)
+	if err := i.storage.write(ctx, manifestPath, manifest); err != nil {
+		return err
+	}
+
+	var records int64
+	for _, f := range files {
+		records += f.records
+	}
+	entry := map[string]interface{}{
+		"manifest_path":        manifestPath,
+		"manifest_length":      int64(len(manifest)),
+		"partition_spec_id":    spec.SpecID,
+		"content":              0, // data
+		"added_snapshot_id":    snapshotID,
+		"added_files_count":    len(files),
+		"existing_files_count": 0,
+		"deleted_files_count":  0,
+		"added_rows_count":     records,
+		"existing_rows_count":  int64(0),
+		"deleted_rows_count":   int64(0),
+		"partitions":           nil,
+		"key_metadata":         nil,
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := i.appendSnapshot(ctx, snapshotID, attempt, entry, files, records)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errConflict) || attempt >= i.CommitRetries {
+			return fmt.Errorf("committing snapshot failed: %w", err)
+		}
+		i.Log.Debugf("Retrying commit: %v", err)
+		if err := i.load(ctx, nil); err != nil {
+			return err
+		}
+		// The partition values of the data files are only valid for the
+		// spec they were written with
+		if i.table.spec.SpecID != spec.SpecID {
+			return errors.New("partition spec changed during commit")
+		}
+	}
+}
+
+func (i *Iceberg) appendSnapshot(ctx context.Context, snapshotID int64, attempt int, entry map[string]interface{}, files []*dataFile, records int64) error {
+	meta := i.table.meta
+	s := &snapshot{
+		SnapshotID:     snapshotID,
+		SequenceNumber: meta.LastSequenceNumber + 1,
+		TimestampMs:    time.Now().UnixMilli(),
+		SchemaID:       i.table.schema.SchemaID,
+		Summary: map[string]string{
+			"operation":        "append",
+			"added-data-files": strconv.Itoa(len(files)),
+			"added-records":    strconv.FormatInt(records, 10),
+		},
+	}
+
+	// Carry over the manifests of the parent snapshot
+	var manifests []interface{}
+	var parentID interface{}
+	if parent := meta.mainSnapshot(); parent != nil {
+		s.ParentSnapshotID = &parent.SnapshotID
+		parentID = parent.SnapshotID
+
+		data, err := i.storage.read(ctx, parent.ManifestList)
+		if err != nil {
+			return err
+		}
+		if manifests, err = readManifestList(data); err != nil {
+			return fmt.Errorf("reading manifest list %q failed: %w", parent.ManifestList, err)
+		}
+	}
+	entry["sequence_number"] = s.SequenceNumber
+	entry["min_sequence_number"] = s.SequenceNumber
+	manifests = append(manifests, entry)
+
+	list, err := writeManifestList(s, manifests)
+	if err != nil {
+		return fmt.Errorf("encoding manifest list failed: %w", err)
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return fmt.Errorf("generating file name failed: %w", err)
+	}
+	s.ManifestList = fmt.Sprintf("%s/snap-%d-%d-%s.avro", meta.metadataLocation(), snapshotID, attempt, id)
+	if err := i.storage.write(ctx, s.ManifestList, list); err != nil {
+		return err
+	}
+
+	requirements := []interface{}{
+		map[string]interface{}{"type": "assert-table-uuid", "uuid": meta.TableUUID},
+		map[string]interface{}{"type": "assert-ref-snapshot-id", "ref": "main", "snapshot-id": parentID},
+	}
+	updates := []interface{}{
+		map[string]interface{}{"action": "add-snapshot", "snapshot": s},
+		map[string]interface{}{"action": "set-snapshot-ref", "ref-name": "main", "type": "branch", "snapshot-id": snapshotID},
+	}
+	updated, err := i.catalog.commit(ctx, requirements, updates)
+	if err != nil {
+		return err
+	}
+	i.Log.Debugf("Committed snapshot %d with %d records in %d files", snapshotID, records, len(files))
+
+	return i.use(updated)
+}
+
+// value returns the value of the column for the metric converted to the
+// column type or nil if the metric has no matching value
+func (i *Iceberg) value(c *column, m telegraf.Metric) interface{} {
+	var v interface{}
+	switch c.field.Name {
+	case i.TimestampColumn:
+		v = m.Time()
+	case i.NameColumn:
+		v = m.Name()
+	default:
+		if c.kind == "string" {
+			if tag, found := m.GetTag(c.field.Name); found {
+				return tag
+			}
+		}
+		v, _ = m.GetField(c.field.Name)
+	}
+	return convert(c.kind, v)
+}
+
+func convert(kind string, v interface{}) interface{} {
+	switch kind {
+	case "boolean":
+		if b, ok := v.(bool); ok {
+			return b
+		}
+	case "int":
+		switch n := v.(type) {
+		case int64:
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int32(n)
+			}
+		case uint64:
+			if n <= math.MaxInt32 {
+				return int32(n)
+			}
+		}
+	case "long":
+		switch n := v.(type) {
+		case int64:
+			return n
+		case uint64:
+			if n <= math.MaxInt64 {
+				return int64(n)
+			}
+		}
+	case "float":
+		if f, ok := v.(float64); ok {
+			return float32(f)
+		}
+	case "double":
+		switch n := v.(type) {
+		case float64:
+			return n
+		case int64:
+			return float64(n)
+		case uint64:
+			return float64(n)
+		}
+	case "string":
+		if s, ok := v.(string); ok {
+			return s
+		}
+	case "timestamp", "timestamptz":
+		if ts, ok := v.(time.Time); ok {
+			return ts.UnixMicro()
+		}
+	}
+	return nil
+}
+
+var arrowTypes = map[string]arrow.DataType{
+	"boolean":     arrow.FixedWidthTypes.Boolean,
+	"int":         arrow.PrimitiveTypes.Int32,
+	"long":        arrow.PrimitiveTypes.Int64,
+	"float":       arrow.PrimitiveTypes.Float32,
+	"double":      arrow.PrimitiveTypes.Float64,
+	"string":      arrow.BinaryTypes.String,
+	"timestamp":   &arrow.TimestampType{Unit: arrow.Microsecond},
+	"timestamptz": arrow.FixedWidthTypes.Timestamp_us,
+}
+
+func appendValue(b array.Builder, value interface{}) {
+	if value == nil {
+		b.AppendNull()
+		return
+	}
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		b.Append(value.(bool))
+	case *array.Int32Builder:
+		b.Append(value.(int32))
+	case *array.Int64Builder:
+		b.Append(value.(int64))
+	case *array.Float32Builder:
+		b.Append(value.(float32))
+	case *array.Float64Builder:
+		b.Append(value.(float64))
+	case *array.StringBuilder:
+		b.Append(value.(string))
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(value.(int64)))
+	}
+}
+
+// icebergType returns the column type for the field value
+func icebergType(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "double"
+	case int64, uint64:
+		return "long"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	}
+	return ""
+}
+
+func init() {
+	outputs.Add("iceberg", func() telegraf.Output {
+		return &Iceberg{
+			CreateTable:     true,
+			PartitionBy:     "day",
+			SchemaEvolution: true,
+			NameColumn:      "name",
+			TimestampColumn: "time",
+			Compression:     "zstd",
+			CommitRetries:   3,
+		}
+	})
+}
@@ -0,0 +1,387 @@
+package iceberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v13/parquet/file"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// catalogServer is a minimal REST catalog serving a single table
+type catalogServer struct {
+	*httptest.Server
+
+	sync.Mutex
+	location  string
+	meta      *tableMetadata
+	commits   int
+	conflicts int
+}
+
+func newCatalogServer(t *testing.T) *catalogServer {
+	s := &catalogServer{location: t.TempDir()}
+
+	tableURL := "/v1/wh/namespaces/telegraf/tables/metrics"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/config", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"defaults": {}, "overrides": {"prefix": "wh"}}`))
+	})
+	mux.HandleFunc("/v1/wh/namespaces/telegraf/tables", func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+
+		var req createTableRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name != "metrics" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.meta = &tableMetadata{
+			FormatVersion:  2,
+			TableUUID:      "0cd21ba5-5dc2-4cf2-9b34-7e3a6b2ec4a4",
+			Location:       s.location,
+			LastColumnID:   len(req.Schema.Fields),
+			Schemas:        []*schema{req.Schema},
+			PartitionSpecs: []*partitionSpec{req.PartitionSpec},
+			Refs:           make(map[string]*ref),
+			Properties:     req.Properties,
+		}
+		s.respond(w)
+	})
+	mux.HandleFunc(tableURL, func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+
+		if s.meta == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"message": "Table does not exist", "type": "NoSuchTableException", "code": 404}}`))
+			return
+		}
+		if r.Method == http.MethodGet {
+			s.respond(w)
+			return
+		}
+
+		s.commits++
+		if s.conflicts > 0 {
+			s.conflicts--
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error": {"message": "Requirement failed", "type": "CommitFailedException", "code": 409}}`))
+			return
+		}
+
+		var req struct {
+			Requirements []map[string]interface{} `json:"requirements"`
+			Updates      []json.RawMessage        `json:"updates"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, requirement := range req.Requirements {
+			if requirement["type"] != "assert-ref-snapshot-id" {
+				continue
+			}
+			var expected interface{}
+			if main := s.meta.mainSnapshot(); main != nil {
+				expected = float64(main.SnapshotID)
+			}
+			if requirement["snapshot-id"] != expected {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		for _, raw := range req.Updates {
+			var update struct {
+				Action       string    `json:"action"`
+				Schema       *schema   `json:"schema"`
+				LastColumnID int       `json:"last-column-id"`
+				Snapshot     *snapshot `json:"snapshot"`
+				SnapshotID   int64     `json:"snapshot-id"`
+			}
+			if err := json.Unmarshal(raw, &update); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			switch update.Action {
+			case "add-schema":
+				s.meta.Schemas = append(s.meta.Schemas, update.Schema)
+				s.meta.LastColumnID = update.LastColumnID
+			case "set-current-schema":
+				s.meta.CurrentSchemaID = s.meta.Schemas[len(s.meta.Schemas)-1].SchemaID
+			case "add-snapshot":
+				s.meta.Snapshots = append(s.meta.Snapshots, update.Snapshot)
+				s.meta.LastSequenceNumber = update.Snapshot.SequenceNumber
+			case "set-snapshot-ref":
+				s.meta.Refs["main"] = &ref{SnapshotID: update.SnapshotID, Type: "branch"}
+			}
+		}
+		s.respond(w)
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *catalogServer) respond(w http.ResponseWriter) {
+	_ = json.NewEncoder(w).Encode(&tableResponse{Metadata: s.meta})
+}
+
+func newPlugin(t *testing.T, url string) *Iceberg {
+	plugin := &Iceberg{
+		CatalogURL:      url,
+		Namespace:       "telegraf",
+		Table:           "metrics",
+		CreateTable:     true,
+		SchemaEvolution: true,
+		CommitRetries:   3,
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	t.Cleanup(func() { require.NoError(t, plugin.Close()) })
+	return plugin
+}
+
+func readAvro(t *testing.T, path string) []map[string]interface{} {
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	reader, err := goavro.NewOCFReader(bytes.NewReader(buf))
+	require.NoError(t, err)
+
+	var records []map[string]interface{}
+	for reader.Scan() {
+		record, err := reader.Read()
+		require.NoError(t, err)
+		records = append(records, record.(map[string]interface{}))
+	}
+	require.NoError(t, reader.Err())
+	return records
+}
+
+func columns(s *schema) map[string]string {
+	result := make(map[string]string, len(s.Fields))
+	for _, f := range s.Fields {
+		result[f.Name] = f.primitive()
+	}
+	return result
+}
+
+func TestWrite(t *testing.T) {
+	srv := newCatalogServer(t)
+	plugin := newPlugin(t, srv.URL)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 98.5}, time.Unix(1704067200, 0)),
+		testutil.MustMetric("mem", map[string]string{"host": "a"}, map[string]interface{}{"used": int64(1024)}, time.Unix(1704067200, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(1704153600, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// The table is created with the columns of the metrics
+	require.NotNil(t, srv.meta)
+	require.Len(t, srv.meta.Schemas, 1)
+	require.Equal(t, map[string]string{
+		"time":       "timestamptz",
+		"name":       "string",
+		"host":       "string",
+		"usage_idle": "double",
+		"used":       "long",
+	}, columns(srv.meta.Schemas[0]))
+	require.Equal(t, []*partitionField{
+		{Name: "time_day", Transform: "day", SourceID: 1, FieldID: 1000},
+	}, srv.meta.PartitionSpecs[0].Fields)
+
+	// A data file is added per day
+	require.Len(t, srv.meta.Snapshots, 1)
+	snap := srv.meta.Snapshots[0]
+	require.Equal(t, int64(1), snap.SequenceNumber)
+	require.Equal(t, snap.SnapshotID, srv.meta.Refs["main"].SnapshotID)
+	require.Equal(t, "3", snap.Summary["added-records"])
+
+	manifests := readAvro(t, snap.ManifestList)
+	require.Len(t, manifests, 1)
+	require.Equal(t, int32(2), manifests[0]["added_files_count"])
+	require.Equal(t, int64(3), manifests[0]["added_rows_count"])
+	require.Equal(t, int64(1), manifests[0]["sequence_number"])
+
+	entries := readAvro(t, manifests[0]["manifest_path"].(string))
+	require.Len(t, entries, 2)
+	expected := []struct {
+		day     int32
+		records int64
+	}{
+		{day: 19723, records: 2},
+		{day: 19724, records: 1},
+	}
+	for idx, entry := range entries {
+		require.Equal(t, int32(1), entry["status"])
+		dataFile := entry["data_file"].(map[string]interface{})
+		require.Equal(t, map[string]interface{}{"time_day": map[string]interface{}{"int": expected[idx].day}}, dataFile["partition"])
+		require.Equal(t, expected[idx].records, dataFile["record_count"])
+		require.Contains(t, dataFile["file_path"], "/data/time_day=")
+
+		// Columns of the data files carry the field ids of the schema
+		reader, err := file.OpenParquetFile(dataFile["file_path"].(string), false)
+		require.NoError(t, err)
+		require.Equal(t, expected[idx].records, reader.NumRows())
+		root := reader.MetaData().Schema.Root()
+		for i := 0; i < root.NumFields(); i++ {
+			require.Equal(t, int32(i+1), root.Field(i).FieldID())
+		}
+		require.NoError(t, reader.Close())
+	}
+}
+
+func TestWriteSchemaEvolution(t *testing.T) {
+	srv := newCatalogServer(t)
+	plugin := newPlugin(t, srv.URL)
+
+	m := testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 98.5}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+
+	m = testutil.MustMetric("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"usage_idle": 97.5}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+
+	// The new tag is added as a column with a new id
+	require.Len(t, srv.meta.Schemas, 2)
+	require.Equal(t, 1, srv.meta.CurrentSchemaID)
+	require.Equal(t, 5, srv.meta.LastColumnID)
+	added := srv.meta.Schemas[1].Fields[4]
+	require.Equal(t, "cpu", added.Name)
+	require.Equal(t, 5, added.ID)
+	require.False(t, added.Required)
+
+	// The second snapshot contains the manifests of both writes
+	require.Len(t, srv.meta.Snapshots, 2)
+	snap := srv.meta.Snapshots[1]
+	require.Equal(t, srv.meta.Snapshots[0].SnapshotID, *snap.ParentSnapshotID)
+	require.Equal(t, 1, snap.SchemaID)
+	manifests := readAvro(t, snap.ManifestList)
+	require.Len(t, manifests, 2)
+	require.Equal(t, int64(1), manifests[0]["sequence_number"])
+	require.Equal(t, int64(2), manifests[1]["sequence_number"])
+}
+
+func TestWriteNoSchemaEvolution(t *testing.T) {
+	srv := newCatalogServer(t)
+	plugin := newPlugin(t, srv.URL)
+	plugin.SchemaEvolution = false
+
+	m := testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 98.5}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+
+	m = testutil.MustMetric("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{"usage_idle": 97.5}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, srv.meta.Schemas, 1)
+	require.Len(t, srv.meta.Snapshots, 2)
+}
+
+func TestWriteCommitConflict(t *testing.T) {
+	srv := newCatalogServer(t)
+	plugin := newPlugin(t, srv.URL)
+
+	m := testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 98.5}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Equal(t, 1, srv.commits)
+
+	// The commit is retried after reloading the table
+	srv.conflicts = 1
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Equal(t, 3, srv.commits)
+	require.Len(t, srv.meta.Snapshots, 2)
+
+	// Give up after the configured retries
+	srv.conflicts = 10
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "CommitFailedException: Requirement failed")
+	require.Equal(t, 7, srv.commits)
+	require.Len(t, srv.meta.Snapshots, 2)
+}
+
+func TestWriteTableNotFound(t *testing.T) {
+	srv := newCatalogServer(t)
+	plugin := newPlugin(t, srv.URL)
+	plugin.CreateTable = false
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "NoSuchTableException: Table does not exist")
+	require.Nil(t, srv.meta)
+}
+
+func TestPartitionValue(t *testing.T) {
+	ts := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC).UnixMicro()
+	tests := []struct {
+		transform string
+		value     interface{}
+		path      string
+	}{
+		{transform: "year", value: int32(54), path: "2024"},
+		{transform: "month", value: int32(650), path: "2024-03"},
+		{transform: "day", value: int32(19797), path: "2024-03-15"},
+		{transform: "hour", value: int32(475138), path: "2024-03-15-10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.transform, func(t *testing.T) {
+			p := &partition{
+				field:  &partitionField{Transform: tt.transform},
+				source: &column{kind: "timestamptz"},
+			}
+			value, path := p.value(ts)
+			require.Equal(t, tt.value, value)
+			require.Equal(t, tt.path, path)
+		})
+	}
+
+	p := &partition{
+		field:  &partitionField{Transform: "identity"},
+		source: &column{kind: "string"},
+	}
+	value, path := p.value("a/b")
+	require.Equal(t, "a/b", value)
+	require.Equal(t, "a%2Fb", path)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Iceberg
+		expected string
+	}{
+		{
+			name:     "missing catalog url",
+			plugin:   &Iceberg{},
+			expected: "catalog_url is required",
+		},
+		{
+			name:     "missing table",
+			plugin:   &Iceberg{CatalogURL: "http://localhost", Namespace: "telegraf"},
+			expected: "table is required",
+		},
+		{
+			name:     "invalid partitioning",
+			plugin:   &Iceberg{CatalogURL: "http://localhost", Namespace: "telegraf", Table: "metrics", PartitionBy: "week"},
+			expected: `invalid partition_by "week"`,
+		},
+		{
+			name:     "invalid compression",
+			plugin:   &Iceberg{CatalogURL: "http://localhost", Namespace: "telegraf", Table: "metrics", Compression: "lzo"},
+			expected: `invalid compression "lzo"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
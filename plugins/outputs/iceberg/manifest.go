@@ -0,0 +1,272 @@
+package iceberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// dataFile is a parquet file written to the table
+type dataFile struct {
+	path      string
+	size      int64
+	records   int64
+	partition map[string]interface{}
+}
+
+// Manifest list fields by field-id as defined in
+// https://iceberg.apache.org/spec/#manifest-lists
+var manifestFileFields = []map[string]interface{}{
+	{"name": "manifest_path", "type": "string", "field-id": 500},
+	{"name": "manifest_length", "type": "long", "field-id": 501},
+	{"name": "partition_spec_id", "type": "int", "field-id": 502},
+	{"name": "content", "type": "int", "field-id": 517},
+	{"name": "sequence_number", "type": "long", "field-id": 515},
+	{"name": "min_sequence_number", "type": "long", "field-id": 516},
+	{"name": "added_snapshot_id", "type": "long", "field-id": 503},
+	{"name": "added_files_count", "type": "int", "field-id": 504},
+	{"name": "existing_files_count", "type": "int", "field-id": 505},
+	{"name": "deleted_files_count", "type": "int", "field-id": 506},
+	{"name": "added_rows_count", "type": "long", "field-id": 512},
+	{"name": "existing_rows_count", "type": "long", "field-id": 513},
+	{"name": "deleted_rows_count", "type": "long", "field-id": 514},
+	{
+		"name": "partitions",
+		"type": []interface{}{
+			"null",
+			map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "record",
+					"name": "r508",
+					"fields": []map[string]interface{}{
+						{"name": "contains_null", "type": "boolean", "field-id": 509},
+						{"name": "contains_nan", "type": []string{"null", "boolean"}, "default": nil, "field-id": 518},
+						{"name": "lower_bound", "type": []string{"null", "bytes"}, "default": nil, "field-id": 510},
+						{"name": "upper_bound", "type": []string{"null", "bytes"}, "default": nil, "field-id": 511},
+					},
+				},
+				"element-id": 508,
+			},
+		},
+		"default":  nil,
+		"field-id": 507,
+	},
+	{"name": "key_metadata", "type": []string{"null", "bytes"}, "default": nil, "field-id": 519},
+}
+
+func manifestListSchema() (string, error) {
+	buf, err := json.Marshal(map[string]interface{}{
+		"type":   "record",
+		"name":   "manifest_file",
+		"fields": manifestFileFields,
+	})
+	return string(buf), err
+}
+
+// manifestSchema returns the schema of the manifest entries with the partition
+// record matching the given partition fields, see
+// https://iceberg.apache.org/spec/#manifests
+func manifestSchema(partitions []*partition) (string, error) {
+	fields := make([]map[string]interface{}, 0, len(partitions))
+	for _, p := range partitions {
+		fields = append(fields, map[string]interface{}{
+			"name":     avroName(p.field.Name),
+			"type":     []string{"null", p.avroType()},
+			"default":  nil,
+			"field-id": p.field.FieldID,
+		})
+	}
+
+	buf, err := json.Marshal(map[string]interface{}{
+		"type": "record",
+		"name": "manifest_entry",
+		"fields": []map[string]interface{}{
+			{"name": "status", "type": "int", "field-id": 0},
+			{"name": "snapshot_id", "type": []string{"null", "long"}, "default": nil, "field-id": 1},
+			{"name": "sequence_number", "type": []string{"null", "long"}, "default": nil, "field-id": 3},
+			{"name": "file_sequence_number", "type": []string{"null", "long"}, "default": nil, "field-id": 4},
+			{
+				"name": "data_file",
+				"type": map[string]interface{}{
+					"type": "record",
+					"name": "r2",
+					"fields": []map[string]interface{}{
+						{"name": "content", "type": "int", "field-id": 134},
+						{"name": "file_path", "type": "string", "field-id": 100},
+						{"name": "file_format", "type": "string", "field-id": 101},
+						{
+							"name":     "partition",
+							"type":     map[string]interface{}{"type": "record", "name": "r102", "fields": fields},
+							"field-id": 102,
+						},
+						{"name": "record_count", "type": "long", "field-id": 103},
+						{"name": "file_size_in_bytes", "type": "long", "field-id": 104},
+					},
+				},
+				"field-id": 2,
+			},
+		},
+	})
+	return string(buf), err
+}
+
+// writeManifest encodes the manifest adding the given data files with the
+// snapshot. Sequence numbers are inherited from the manifest list entry.
+func writeManifest(t *table, snapshotID int64, files []*dataFile) ([]byte, error) {
+	avroSchema, err := manifestSchema(t.partitions)
+	if err != nil {
+		return nil, err
+	}
+	tableSchema, err := json.Marshal(t.schema)
+	if err != nil {
+		return nil, err
+	}
+	specFields, err := json.Marshal(t.spec.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]interface{}, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, map[string]interface{}{
+			"status":               1, // added
+			"snapshot_id":          goavro.Union("long", snapshotID),
+			"sequence_number":      nil,
+			"file_sequence_number": nil,
+			"data_file": map[string]interface{}{
+				"content":            0, // data
+				"file_path":          f.path,
+				"file_format":        "PARQUET",
+				"partition":          f.partition,
+				"record_count":       f.records,
+				"file_size_in_bytes": f.size,
+			},
+		})
+	}
+
+	return encode(avroSchema, map[string][]byte{
+		"schema":            tableSchema,
+		"schema-id":         []byte(strconv.Itoa(t.schema.SchemaID)),
+		"partition-spec":    specFields,
+		"partition-spec-id": []byte(strconv.Itoa(t.spec.SpecID)),
+		"format-version":    []byte("2"),
+		"content":           []byte("data"),
+	}, entries)
+}
+
+// writeManifestList encodes the manifest list of a snapshot
+func writeManifestList(s *snapshot, manifests []interface{}) ([]byte, error) {
+	avroSchema, err := manifestListSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	parent := "null"
+	if s.ParentSnapshotID != nil {
+		parent = strconv.FormatInt(*s.ParentSnapshotID, 10)
+	}
+	return encode(avroSchema, map[string][]byte{
+		"snapshot-id":        []byte(strconv.FormatInt(s.SnapshotID, 10)),
+		"parent-snapshot-id": []byte(parent),
+		"sequence-number":    []byte(strconv.FormatInt(s.SequenceNumber, 10)),
+		"format-version":     []byte("2"),
+	}, manifests)
+}
+
+// readManifestList decodes the entries of an existing manifest list. The
+// fields are matched by field-id as writers might use different names.
+func readManifestList(data []byte) ([]interface{}, error) {
+	reader, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var fileSchema struct {
+		Fields []struct {
+			Name    string `json:"name"`
+			FieldID int    `json:"field-id"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(reader.MetaData()["avro.schema"], &fileSchema); err != nil {
+		return nil, fmt.Errorf("parsing schema failed: %w", err)
+	}
+	names := make(map[string]string, len(fileSchema.Fields))
+	for _, f := range fileSchema.Fields {
+		for _, expected := range manifestFileFields {
+			if expected["field-id"] == f.FieldID {
+				names[f.Name] = expected["name"].(string)
+			}
+		}
+	}
+
+	var entries []interface{}
+	for reader.Scan() {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		fields, ok := record.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected entry type %T", record)
+		}
+
+		entry := map[string]interface{}{"partitions": nil, "key_metadata": nil}
+		for name, value := range fields {
+			if n, found := names[name]; found {
+				entry[n] = value
+			}
+		}
+		for _, expected := range manifestFileFields {
+			if _, found := entry[expected["name"].(string)]; !found {
+				return nil, fmt.Errorf("entry lacks field %d", expected["field-id"])
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, reader.Err()
+}
+
+func encode(avroSchema string, meta map[string][]byte, records []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:               &buf,
+		Schema:          avroSchema,
+		CompressionName: goavro.CompressionDeflateLabel,
+		MetaData:        meta,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.Append(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// avroName converts the partition field name to a valid Avro name in the same
+// way as the Iceberg reference implementation
+func avroName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if i > 0 {
+			valid = valid || (r >= '0' && r <= '9')
+		}
+		if valid {
+			b.WriteRune(r)
+			continue
+		}
+		if r >= '0' && r <= '9' {
+			b.WriteRune('_')
+			b.WriteRune(r)
+			continue
+		}
+		fmt.Fprintf(&b, "_x%X", r)
+	}
+	return b.String()
+}
@@ -0,0 +1,289 @@
+package iceberg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tableMetadata is the subset of the Iceberg table metadata used to append
+// data, see https://iceberg.apache.org/spec/#table-metadata-fields
+type tableMetadata struct {
+	FormatVersion      int               `json:"format-version"`
+	TableUUID          string            `json:"table-uuid"`
+	Location           string            `json:"location"`
+	LastSequenceNumber int64             `json:"last-sequence-number"`
+	LastColumnID       int               `json:"last-column-id"`
+	CurrentSchemaID    int               `json:"current-schema-id"`
+	Schemas            []*schema         `json:"schemas"`
+	DefaultSpecID      int               `json:"default-spec-id"`
+	PartitionSpecs     []*partitionSpec  `json:"partition-specs"`
+	CurrentSnapshotID  *int64            `json:"current-snapshot-id"`
+	Snapshots          []*snapshot       `json:"snapshots"`
+	Refs               map[string]*ref   `json:"refs"`
+	Properties         map[string]string `json:"properties"`
+}
+
+type schema struct {
+	Type               string   `json:"type"`
+	SchemaID           int      `json:"schema-id"`
+	IdentifierFieldIDs []int    `json:"identifier-field-ids,omitempty"`
+	Fields             []*field `json:"fields"`
+}
+
+// field is a top-level column of a schema. The type is kept as raw JSON to
+// pass nested types not written by the plugin through unchanged.
+type field struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Required bool            `json:"required"`
+	Type     json.RawMessage `json:"type"`
+	Doc      string          `json:"doc,omitempty"`
+}
+
+type partitionSpec struct {
+	SpecID int               `json:"spec-id"`
+	Fields []*partitionField `json:"fields"`
+}
+
+type partitionField struct {
+	Name      string `json:"name"`
+	Transform string `json:"transform"`
+	SourceID  int    `json:"source-id"`
+	FieldID   int    `json:"field-id"`
+}
+
+type snapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber   int64             `json:"sequence-number"`
+	TimestampMs      int64             `json:"timestamp-ms"`
+	ManifestList     string            `json:"manifest-list"`
+	Summary          map[string]string `json:"summary"`
+	SchemaID         int               `json:"schema-id"`
+}
+
+type ref struct {
+	SnapshotID int64  `json:"snapshot-id"`
+	Type       string `json:"type"`
+}
+
+func newField(id int, name, kind string, required bool) *field {
+	return &field{
+		ID:       id,
+		Name:     name,
+		Required: required,
+		Type:     json.RawMessage(strconv.Quote(kind)),
+	}
+}
+
+// primitive returns the name of the field's type or an empty string for
+// nested types
+func (f *field) primitive() string {
+	var kind string
+	if err := json.Unmarshal(f.Type, &kind); err != nil {
+		return ""
+	}
+	return kind
+}
+
+func (m *tableMetadata) currentSchema() (*schema, error) {
+	for _, s := range m.Schemas {
+		if s.SchemaID == m.CurrentSchemaID {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("current schema %d not found", m.CurrentSchemaID)
+}
+
+func (m *tableMetadata) defaultSpec() (*partitionSpec, error) {
+	for _, s := range m.PartitionSpecs {
+		if s.SpecID == m.DefaultSpecID {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("default partition spec %d not found", m.DefaultSpecID)
+}
+
+// mainSnapshot returns the snapshot of the main branch or nil for tables
+// without data
+func (m *tableMetadata) mainSnapshot() *snapshot {
+	id := m.CurrentSnapshotID
+	if r, found := m.Refs["main"]; found {
+		id = &r.SnapshotID
+	}
+	if id == nil {
+		return nil
+	}
+	for _, s := range m.Snapshots {
+		if s.SnapshotID == *id {
+			return s
+		}
+	}
+	return nil
+}
+
+// dataLocation returns the location for data files honoring the
+// 'write.data.path' table property
+func (m *tableMetadata) dataLocation() string {
+	if p := m.Properties["write.data.path"]; p != "" {
+		return strings.TrimSuffix(p, "/")
+	}
+	return strings.TrimSuffix(m.Location, "/") + "/data"
+}
+
+// metadataLocation returns the location for manifest files honoring the
+// 'write.metadata.path' table property
+func (m *tableMetadata) metadataLocation() string {
+	if p := m.Properties["write.metadata.path"]; p != "" {
+		return strings.TrimSuffix(p, "/")
+	}
+	return strings.TrimSuffix(m.Location, "/") + "/metadata"
+}
+
+// column is a column of the current schema written by the plugin
+type column struct {
+	field *field
+	kind  string
+}
+
+// partition is a field of the default partition spec with its source column
+type partition struct {
+	field  *partitionField
+	source *column
+}
+
+// table is the loaded table metadata with the current schema and the default
+// partition spec resolved
+type table struct {
+	meta       *tableMetadata
+	schema     *schema
+	spec       *partitionSpec
+	columns    []*column
+	partitions []*partition
+}
+
+// Column types the plugin is able to write, columns with other types are
+// omitted from the data files and read as null
+var writableTypes = map[string]bool{
+	"boolean":     true,
+	"int":         true,
+	"long":        true,
+	"float":       true,
+	"double":      true,
+	"string":      true,
+	"timestamp":   true,
+	"timestamptz": true,
+}
+
+func newTable(meta *tableMetadata, nameColumn, timestampColumn string) (*table, error) {
+	if meta.FormatVersion != 2 {
+		return nil, fmt.Errorf("unsupported table format version %d", meta.FormatVersion)
+	}
+	current, err := meta.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := meta.defaultSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &table{meta: meta, schema: current, spec: spec}
+	byID := make(map[int]*column, len(current.Fields))
+	for _, f := range current.Fields {
+		kind := f.primitive()
+		switch f.Name {
+		case timestampColumn:
+			if kind != "timestamp" && kind != "timestamptz" {
+				return nil, fmt.Errorf("column %q must be of type timestamp or timestamptz", f.Name)
+			}
+		case nameColumn:
+			if kind != "string" {
+				return nil, fmt.Errorf("column %q must be of type string", f.Name)
+			}
+		}
+		if !writableTypes[kind] {
+			if f.Required {
+				return nil, fmt.Errorf("required column %q has unsupported type %s", f.Name, string(f.Type))
+			}
+			continue
+		}
+		c := &column{field: f, kind: kind}
+		t.columns = append(t.columns, c)
+		byID[f.ID] = c
+	}
+
+	for _, f := range spec.Fields {
+		source, found := byID[f.SourceID]
+		if !found {
+			return nil, fmt.Errorf("source column %d of partition field %q not found", f.SourceID, f.Name)
+		}
+		switch f.Transform {
+		case "identity":
+			if _, found := identityTypes[source.kind]; !found {
+				return nil, fmt.Errorf("identity partition on column %q of type %s not supported", source.field.Name, source.kind)
+			}
+		case "year", "month", "day", "hour":
+			if source.kind != "timestamp" && source.kind != "timestamptz" {
+				return nil, fmt.Errorf("%s partition on column %q of type %s not supported", f.Transform, source.field.Name, source.kind)
+			}
+		default:
+			return nil, fmt.Errorf("partition transform %q of field %q not supported", f.Transform, f.Name)
+		}
+		t.partitions = append(t.partitions, &partition{field: f, source: source})
+	}
+
+	return t, nil
+}
+
+// Avro types of identity partition values by column type
+var identityTypes = map[string]string{
+	"boolean": "boolean",
+	"int":     "int",
+	"long":    "long",
+	"string":  "string",
+}
+
+// avroType returns the Avro type of the partition value
+func (p *partition) avroType() string {
+	if p.field.Transform == "identity" {
+		return identityTypes[p.source.kind]
+	}
+	return "int"
+}
+
+// value applies the partition transform to the converted column value and
+// returns the partition value with its representation in the file path
+func (p *partition) value(v interface{}) (interface{}, string) {
+	if v == nil {
+		return nil, "null"
+	}
+	if p.field.Transform == "identity" {
+		return v, url.QueryEscape(fmt.Sprint(v))
+	}
+
+	micros := v.(int64)
+	ts := time.UnixMicro(micros).UTC()
+	switch p.field.Transform {
+	case "year":
+		return int32(ts.Year() - 1970), ts.Format("2006")
+	case "month":
+		return int32((ts.Year()-1970)*12 + int(ts.Month()) - 1), ts.Format("2006-01")
+	case "day":
+		return int32(floorDiv(micros, int64(24*time.Hour/time.Microsecond))), ts.Format("2006-01-02")
+	default:
+		return int32(floorDiv(micros, int64(time.Hour/time.Microsecond))), ts.Format("2006-01-02-15")
+	}
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
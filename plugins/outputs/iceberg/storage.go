@@ -0,0 +1,108 @@
+package iceberg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
+)
+
+// S3Config contains the settings for accessing tables located in S3 or
+// S3-compatible object storage
+type S3Config struct {
+	UsePathStyle bool `toml:"use_path_style"`
+	internalaws.CredentialConfig
+}
+
+// storage reads and writes the data and metadata files of the table. Files
+// are either located in S3 or in the local filesystem.
+type storage struct {
+	client *s3.Client
+}
+
+func newStorage(cfg *S3Config) (*storage, error) {
+	awsCfg, err := cfg.CredentialConfig.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("loading credentials failed: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.EndpointURL != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(cfg.EndpointURL)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+	return &storage{client: client}, nil
+}
+
+func (s *storage) read(ctx context.Context, location string) ([]byte, error) {
+	scheme, bucket, key, err := split(location)
+	if err != nil {
+		return nil, err
+	}
+	if scheme == "file" {
+		return os.ReadFile(key)
+	}
+
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %q failed: %w", location, err)
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (s *storage) write(ctx context.Context, location string, data []byte) error {
+	scheme, bucket, key, err := split(location)
+	if err != nil {
+		return err
+	}
+	if scheme == "file" {
+		if err := os.MkdirAll(filepath.Dir(key), 0750); err != nil {
+			return fmt.Errorf("creating directory failed: %w", err)
+		}
+		return os.WriteFile(key, data, 0640)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("writing %q failed: %w", location, err)
+	}
+	return nil
+}
+
+// split returns the scheme, bucket and key of an S3 location or the path for
+// local files. The location is not URL-decoded as escaped characters are part
+// of the file names.
+func split(location string) (scheme, bucket, key string, err error) {
+	scheme, path, found := strings.Cut(location, "://")
+	if !found {
+		return "file", "", location, nil
+	}
+	switch scheme {
+	case "file":
+		return "file", "", path, nil
+	case "s3", "s3a", "s3n":
+		bucket, key, _ = strings.Cut(path, "/")
+		if bucket == "" {
+			return "", "", "", fmt.Errorf("location %q has no bucket", location)
+		}
+		return "s3", bucket, key, nil
+	}
+	return "", "", "", fmt.Errorf("unsupported scheme %q of location %q", scheme, location)
+}
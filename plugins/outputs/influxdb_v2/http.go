@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/retention"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"golang.org/x/net/http2"
 )
@@ -49,6 +50,7 @@ type HTTPConfig struct {
 	Bucket           string
 	BucketTag        string
 	ExcludeBucketTag bool
+	Retention        retention.HintConfig
 	Timeout          time.Duration
 	Headers          map[string]string
 	Proxy            *url.URL
@@ -70,13 +72,15 @@ type httpClient struct {
 	Bucket           string
 	BucketTag        string
 	ExcludeBucketTag bool
-
-	client     *http.Client
-	serializer *influx.Serializer
-	url        *url.URL
-	retryTime  time.Time
-	retryCount int
-	log        telegraf.Logger
+	Retention        retention.HintConfig
+
+	client      *http.Client
+	serializer  *influx.Serializer
+	url         *url.URL
+	retryTime   time.Time
+	retryCount  int
+	retryStatus int
+	log         telegraf.Logger
 }
 
 func NewHTTPClient(cfg *HTTPConfig) (*httpClient, error) {
@@ -164,6 +168,7 @@ func NewHTTPClient(cfg *HTTPConfig) (*httpClient, error) {
 		Bucket:           cfg.Bucket,
 		BucketTag:        cfg.BucketTag,
 		ExcludeBucketTag: cfg.ExcludeBucketTag,
+		Retention:        cfg.Retention,
 		log:              cfg.Log,
 	}
 	return client, nil
@@ -193,11 +198,11 @@ func (g genericRespError) Error() string {
 
 func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error {
 	if c.retryTime.After(time.Now()) {
-		return errors.New("retry time has not elapsed")
+		return &APIError{StatusCode: c.retryStatus, Title: "retry time has not elapsed"}
 	}
 
 	batches := make(map[string][]telegraf.Metric)
-	if c.BucketTag == "" {
+	if c.BucketTag == "" && !c.Retention.Enabled() {
 		err := c.writeBatch(ctx, c.Bucket, metrics)
 		if err != nil {
 			var apiErr *APIError
@@ -211,21 +216,18 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 		}
 	} else {
 		for _, metric := range metrics {
-			bucket, ok := metric.GetTag(c.BucketTag)
-			if !ok {
-				bucket = c.Bucket
-			}
-
+			bucket := c.bucket(metric)
 			if _, ok := batches[bucket]; !ok {
 				batches[bucket] = make([]telegraf.Metric, 0)
 			}
 
-			if c.ExcludeBucketTag {
+			if c.ExcludeBucketTag && c.BucketTag != "" {
 				// Avoid modifying the metric in case we need to retry the request.
 				metric = metric.Copy()
 				metric.Accept()
 				metric.RemoveTag(c.BucketTag)
 			}
+			metric = c.Retention.Strip(metric)
 
 			batches[bucket] = append(batches[bucket], metric)
 		}
@@ -236,7 +238,7 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 				var apiErr *APIError
 				if errors.As(err, &apiErr) {
 					if apiErr.StatusCode == http.StatusRequestEntityTooLarge {
-						return c.splitAndWriteBatch(ctx, c.Bucket, metrics)
+						return c.splitAndWriteBatch(ctx, bucket, batch)
 					}
 				}
 
@@ -247,6 +249,20 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 	return nil
 }
 
+// bucket returns the bucket of the metric given by the bucket tag, the
+// retention hint or the default bucket in that order
+func (c *httpClient) bucket(metric telegraf.Metric) string {
+	if c.BucketTag != "" {
+		if bucket, ok := metric.GetTag(c.BucketTag); ok {
+			return bucket
+		}
+	}
+	if bucket, ok := c.Retention.Hint(metric); ok {
+		return bucket
+	}
+	return c.Bucket
+}
+
 func (c *httpClient) splitAndWriteBatch(ctx context.Context, bucket string, metrics []telegraf.Metric) error {
 	c.log.Warnf("Retrying write after splitting metric payload in half to reduce batch size")
 	midpoint := len(metrics) / 2
@@ -330,8 +346,12 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 		c.retryCount++
 		retryDuration := c.getRetryDuration(resp.Header)
 		c.retryTime = time.Now().Add(retryDuration)
+		c.retryStatus = resp.StatusCode
 		c.log.Warnf("Failed to write to %s; will retry in %s. (%s)\n", bucket, retryDuration, resp.Status)
-		return fmt.Errorf("waiting %s for server (%s) before sending metric again", retryDuration, bucket)
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Title:      fmt.Sprintf("waiting %s for server (%s) before sending metric again", retryDuration, bucket),
+		}
 	}
 
 	// if it's any other 4xx code, the client should not retry as it's the client's mistake.
@@ -419,23 +439,70 @@ func (c *httpClient) addHeaders(req *http.Request) {
 	}
 }
 
+// Health checks if the server is ready to accept writes using the health
+// endpoint of the server.
+func (c *httpClient) Health(ctx context.Context) error {
+	loc, err := makeURL(*c.url, "/health")
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loc.String(), nil)
+	if err != nil {
+		return err
+	}
+	c.addHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		internal.OnClientError(c.client, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check failed with status %q", resp.Status)
+	}
+
+	var health struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return fmt.Errorf("decoding health response failed: %w", err)
+	}
+	if health.Status != "pass" {
+		return fmt.Errorf("health check reported status %q: %s", health.Status, health.Message)
+	}
+
+	return nil
+}
+
 func makeWriteURL(loc url.URL, org, bucket string) (string, error) {
 	params := url.Values{}
 	params.Set("bucket", bucket)
 	params.Set("org", org)
 
+	u, err := makeURL(loc, "/api/v2/write")
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+func makeURL(loc url.URL, endpoint string) (*url.URL, error) {
 	switch loc.Scheme {
 	case "unix":
 		loc.Scheme = "http"
 		loc.Host = "127.0.0.1"
-		loc.Path = "/api/v2/write"
+		loc.Path = endpoint
 	case "http", "https":
-		loc.Path = path.Join(loc.Path, "/api/v2/write")
+		loc.Path = path.Join(loc.Path, endpoint)
 	default:
-		return "", fmt.Errorf("unsupported scheme: %q", loc.Scheme)
+		return nil, fmt.Errorf("unsupported scheme: %q", loc.Scheme)
 	}
-	loc.RawQuery = params.Encode()
-	return loc.String(), nil
+	return &loc, nil
 }
 
 func (c *httpClient) Close() {
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/retention"
 	influxdb "github.com/influxdata/telegraf/plugins/outputs/influxdb_v2"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -182,6 +185,108 @@ func TestWriteBucketTagWorksOnRetry(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWriteRetentionHints(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]string)
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v2/write" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			mu.Lock()
+			received[r.URL.Query().Get("bucket")] += string(body)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer ts.Close()
+
+	cfg := &influxdb.HTTPConfig{
+		URL:       genURL(ts.URL),
+		Bucket:    "telegraf",
+		BucketTag: "bucket",
+		Retention: retention.HintConfig{
+			RetentionTag:        "retention",
+			RetentionHints:      map[string]string{"debug": "telegraf-7d"},
+			ExcludeRetentionTag: true,
+		},
+	}
+	client, err := influxdb.NewHTTPClient(cfg)
+	require.NoError(t, err)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("debug", map[string]string{"retention": "debug"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("slo", map[string]string{"retention": "slo"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("explicit", map[string]string{"retention": "debug", "bucket": "other"}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, client.Write(context.Background(), metrics))
+
+	// Unmapped retention classes use the default bucket and the bucket tag
+	// takes precedence over the hint
+	require.Equal(t, map[string]string{
+		"telegraf-7d": "debug value=1 0\n",
+		"telegraf":    "slo value=2 0\n",
+		"other":       "explicit,bucket=other value=3 0\n",
+	}, received)
+}
+
+func TestTooLargeWriteSplitBucketTag(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v2/write" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			// Only accept a single metric per request
+			if strings.Count(string(body), "\n") > 1 {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			mu.Lock()
+			received = append(received, r.URL.Query().Get("bucket")+": "+string(body))
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+	defer ts.Close()
+
+	cfg := &influxdb.HTTPConfig{
+		URL:              genURL(ts.URL),
+		Bucket:           "telegraf",
+		BucketTag:        "bucket",
+		ExcludeBucketTag: true,
+		Log:              testutil.Logger{},
+	}
+	client, err := influxdb.NewHTTPClient(cfg)
+	require.NoError(t, err)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"bucket": "foo"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"bucket": "foo"}, map[string]interface{}{"value": 2.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, client.Write(context.Background(), metrics))
+
+	// The split batches are written to the bucket given by the tag with the
+	// tag removed
+	require.Equal(t, []string{
+		"foo: cpu value=1 0\n",
+		"foo: cpu value=2 0\n",
+	}, received)
+}
+
 func TestTooLargeWriteRetry(t *testing.T) {
 	ts := httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
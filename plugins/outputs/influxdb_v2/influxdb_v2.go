@@ -7,11 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/retention"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -28,6 +30,7 @@ var (
 
 type Client interface {
 	Write(context.Context, []telegraf.Metric) error
+	Health(context.Context) error
 
 	URL() string // for logging
 	Close()
@@ -48,11 +51,18 @@ type InfluxDB struct {
 	UintSupport      bool              `toml:"influx_uint_support"`
 	PingTimeout      config.Duration   `toml:"ping_timeout"`
 	ReadIdleTimeout  config.Duration   `toml:"read_idle_timeout"`
+	Failover         bool              `toml:"failover"`
+	HealthInterval   config.Duration   `toml:"health_check_interval"`
+	retention.HintConfig
 	tls.ClientConfig
 
 	Log telegraf.Logger `toml:"-"`
 
 	clients []Client
+
+	// State for failover mode
+	active    int
+	unhealthy []time.Time
 }
 
 func (*InfluxDB) SampleConfig() string {
@@ -60,6 +70,10 @@ func (*InfluxDB) SampleConfig() string {
 }
 
 func (i *InfluxDB) Connect() error {
+	if err := i.HintConfig.Validate(); err != nil {
+		return err
+	}
+
 	if len(i.URLs) == 0 {
 		i.URLs = append(i.URLs, defaultURL)
 	}
@@ -90,6 +104,8 @@ func (i *InfluxDB) Connect() error {
 			return fmt.Errorf("unsupported scheme [%q]: %q", u, parts.Scheme)
 		}
 	}
+	i.active = 0
+	i.unhealthy = make([]time.Time, len(i.clients))
 
 	return nil
 }
@@ -106,6 +122,10 @@ func (i *InfluxDB) Close() error {
 func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 	ctx := context.Background()
 
+	if i.Failover {
+		return i.writeFailover(ctx, metrics)
+	}
+
 	var err error
 	p := rand.Perm(len(i.clients))
 	for _, n := range p {
@@ -121,6 +141,103 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 	return fmt.Errorf("failed to send metrics to any configured server(s)")
 }
 
+// writeFailover sends the metrics to the active server as long as it accepts
+// the writes. On connection or server errors, the server is marked unhealthy
+// and the next healthy server in the configured order becomes the active one.
+// Other errors, e.g. rejected data, are returned without failing over.
+// Unhealthy servers are only considered again after passing a health check.
+// If no server is healthy, the least recently failed server is tried.
+func (i *InfluxDB) writeFailover(ctx context.Context, metrics []telegraf.Metric) error {
+	start := time.Now()
+	for k := range i.clients {
+		n := (i.active + k) % len(i.clients)
+		if !i.available(ctx, n) {
+			continue
+		}
+
+		if err := i.writeTo(ctx, n, metrics); err == nil || !isServerError(err) {
+			return err
+		}
+	}
+
+	// Try the least recently failed server not tried during this write
+	candidate := -1
+	for n, since := range i.unhealthy {
+		if since.IsZero() || !since.Before(start) {
+			continue
+		}
+		if candidate < 0 || since.Before(i.unhealthy[candidate]) {
+			candidate = n
+		}
+	}
+	if candidate >= 0 {
+		i.Log.Debugf("No healthy server, trying least recently failed [%s]", i.clients[candidate].URL())
+		if err := i.writeTo(ctx, candidate, metrics); err == nil || !isServerError(err) {
+			return err
+		}
+	}
+
+	return errors.New("failed to send metrics to any configured server(s)")
+}
+
+// writeTo writes the metrics to the server with the given index, marking it
+// unhealthy on connection or server errors and making it the active server on
+// success.
+func (i *InfluxDB) writeTo(ctx context.Context, n int, metrics []telegraf.Metric) error {
+	client := i.clients[n]
+	if err := client.Write(ctx, metrics); err != nil {
+		i.Log.Errorf("When writing to [%s]: %v", client.URL(), err)
+		if isServerError(err) {
+			i.unhealthy[n] = time.Now()
+		}
+		return err
+	}
+
+	if !i.unhealthy[n].IsZero() {
+		i.Log.Infof("Server [%s] recovered", client.URL())
+		i.unhealthy[n] = time.Time{}
+	}
+	if n != i.active {
+		i.Log.Infof("Failing over from [%s] to [%s]", i.clients[i.active].URL(), client.URL())
+		i.active = n
+	}
+	return nil
+}
+
+// isServerError returns true for errors caused by the server being unreachable
+// or failing to handle the request, i.e. errors worth failing over for.
+func isServerError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// available returns true if the server with the given index is healthy. For
+// servers marked unhealthy, a health check is performed once the check
+// interval elapsed to recover the server.
+func (i *InfluxDB) available(ctx context.Context, n int) bool {
+	since := i.unhealthy[n]
+	if since.IsZero() {
+		return true
+	}
+	if time.Since(since) < time.Duration(i.HealthInterval) {
+		return false
+	}
+
+	client := i.clients[n]
+	if err := client.Health(ctx); err != nil {
+		i.Log.Debugf("Server [%s] still unhealthy: %v", client.URL(), err)
+		i.unhealthy[n] = time.Now()
+		return false
+	}
+	i.Log.Infof("Server [%s] recovered", client.URL())
+	i.unhealthy[n] = time.Time{}
+	return true
+}
+
 func (i *InfluxDB) getHTTPClient(address *url.URL, proxy *url.URL) (Client, error) {
 	tlsConfig, err := i.ClientConfig.TLSConfig()
 	if err != nil {
@@ -139,6 +256,7 @@ func (i *InfluxDB) getHTTPClient(address *url.URL, proxy *url.URL) (Client, erro
 		Bucket:           i.Bucket,
 		BucketTag:        i.BucketTag,
 		ExcludeBucketTag: i.ExcludeBucketTag,
+		Retention:        i.HintConfig,
 		Timeout:          time.Duration(i.Timeout),
 		Headers:          i.HTTPHeaders,
 		Proxy:            proxy,
@@ -164,6 +282,7 @@ func init() {
 		return &InfluxDB{
 			Timeout:         config.Duration(time.Second * 5),
 			ContentEncoding: "gzip",
+			HealthInterval:  config.Duration(time.Second * 30),
 		}
 	})
 }
@@ -1,11 +1,18 @@
 package influxdb_v2_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	influxdb "github.com/influxdata/telegraf/plugins/outputs/influxdb_v2"
+	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -100,3 +107,165 @@ func TestUnused(_ *testing.T) {
 	thing.SampleConfig()
 	outputs.Outputs["influxdb_v2"]()
 }
+
+type server struct {
+	*httptest.Server
+	healthy atomic.Bool
+	status  atomic.Int32
+	writes  atomic.Int32
+}
+
+func newServer(t *testing.T) *server {
+	s := &server{}
+	s.healthy.Store(true)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/write":
+			if !s.healthy.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if status := s.status.Load(); status != 0 {
+				w.WriteHeader(int(status))
+				return
+			}
+			s.writes.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		case "/health":
+			if !s.healthy.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"name":"influxdb","status":"fail","message":"not ready"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"influxdb","status":"pass","message":"ready for queries and writes"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWriteFailover(t *testing.T) {
+	primary := newServer(t)
+	secondary := newServer(t)
+
+	plugin := &influxdb.InfluxDB{
+		URLs:     []string{primary.URL, secondary.URL},
+		Failover: true,
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	// Writes stick to the first server
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 2, primary.writes.Load())
+	require.EqualValues(t, 0, secondary.writes.Load())
+
+	// Fail over to the second server and stick to it even after the first
+	// server recovered
+	primary.healthy.Store(false)
+	require.NoError(t, plugin.Write(metrics))
+	primary.healthy.Store(true)
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 2, primary.writes.Load())
+	require.EqualValues(t, 2, secondary.writes.Load())
+
+	// The recovered server is used again after the second server failed
+	secondary.healthy.Store(false)
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 3, primary.writes.Load())
+
+	// Writing fails if no server is healthy
+	primary.healthy.Store(false)
+	require.ErrorContains(t, plugin.Write(metrics), "failed to send metrics to any configured server(s)")
+}
+
+func TestWriteFailoverHealthInterval(t *testing.T) {
+	primary := newServer(t)
+	secondary := newServer(t)
+
+	plugin := &influxdb.InfluxDB{
+		URLs:           []string{primary.URL, secondary.URL},
+		Failover:       true,
+		HealthInterval: config.Duration(time.Hour),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	primary.healthy.Store(false)
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 1, secondary.writes.Load())
+
+	// The failed server is not checked before the interval elapsed but, with
+	// no healthy server left, the least recently failed one is tried
+	primary.healthy.Store(true)
+	secondary.healthy.Store(false)
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 1, primary.writes.Load())
+
+	// Writing fails if the least recently failed server fails again
+	primary.healthy.Store(false)
+	require.ErrorContains(t, plugin.Write(metrics), "failed to send metrics to any configured server(s)")
+	require.EqualValues(t, 1, primary.writes.Load())
+	require.EqualValues(t, 1, secondary.writes.Load())
+}
+
+func TestWriteFailoverClientError(t *testing.T) {
+	primary := newServer(t)
+	secondary := newServer(t)
+
+	plugin := &influxdb.InfluxDB{
+		URLs:     []string{primary.URL, secondary.URL},
+		Failover: true,
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	// Client errors are returned without failing over
+	primary.status.Store(http.StatusForbidden)
+	require.ErrorContains(t, plugin.Write(metrics), "403 Forbidden")
+	require.EqualValues(t, 0, secondary.writes.Load())
+
+	primary.status.Store(0)
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 1, primary.writes.Load())
+	require.EqualValues(t, 0, secondary.writes.Load())
+}
+
+func TestWriteFailoverConnectionError(t *testing.T) {
+	primary := newServer(t)
+	secondary := newServer(t)
+
+	plugin := &influxdb.InfluxDB{
+		URLs:     []string{primary.URL, secondary.URL},
+		Failover: true,
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	primary.Close()
+	require.NoError(t, plugin.Write(metrics))
+	require.EqualValues(t, 1, secondary.writes.Load())
+}
@@ -2,10 +2,13 @@
 package kafka
 
 import (
+	"bytes"
 	_ "embed"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -14,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -29,6 +33,9 @@ var ValidTopicSuffixMethods = []string{
 
 var zeroTime = time.Unix(0, 0)
 
+// Topic names accepted by the Kafka brokers
+var validTopicName = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
 type Kafka struct {
 	Brokers         []string    `toml:"brokers"`
 	Topic           string      `toml:"topic"`
@@ -38,6 +45,9 @@ type Kafka struct {
 	RoutingTag      string      `toml:"routing_tag"`
 	RoutingKey      string      `toml:"routing_key"`
 
+	TopicTemplate      string `toml:"topic_template"`
+	RoutingKeyTemplate string `toml:"routing_key_template"`
+
 	proxy.Socks5ProxyConfig
 
 	// Legacy TLS config options
@@ -58,6 +68,9 @@ type Kafka struct {
 	producerFunc func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error)
 	producer     sarama.SyncProducer
 
+	topicTmpl      *template.Template
+	routingKeyTmpl *template.Template
+
 	serializer serializers.Serializer
 }
 
@@ -81,6 +94,12 @@ func (*Kafka) SampleConfig() string {
 }
 
 func (k *Kafka) GetTopicName(metric telegraf.Metric) (telegraf.Metric, string) {
+	if k.topicTmpl != nil {
+		if topic := k.renderTopic(metric); topic != "" {
+			return metric, topic
+		}
+	}
+
 	topic := k.Topic
 	if k.TopicTag != "" {
 		if t, ok := metric.GetTag(k.TopicTag); ok {
@@ -116,6 +135,45 @@ func (k *Kafka) GetTopicName(metric telegraf.Metric) (telegraf.Metric, string) {
 	return metric, topicName
 }
 
+// renderTopic returns the topic generated by the topic template or an empty
+// string if the template does not produce a valid topic name for the metric.
+func (k *Kafka) renderTopic(metric telegraf.Metric) string {
+	tm, err := templateMetric(metric)
+	if err != nil {
+		k.Log.Debugf("Executing topic template failed: %v", err)
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := k.topicTmpl.Execute(&buf, tm); err != nil {
+		k.Log.Debugf("Executing topic template failed: %v", err)
+		return ""
+	}
+	topic := strings.TrimSpace(buf.String())
+	if topic == "" {
+		return ""
+	}
+	if !validTopicName.MatchString(topic) {
+		k.Log.Debugf("Invalid topic %q generated by template, using fallback topic", topic)
+		return ""
+	}
+	return topic
+}
+
+// templateMetric returns the metric used for executing templates, unwrapping
+// tracking metrics as those do not provide the template functions.
+func templateMetric(raw telegraf.Metric) (telegraf.TemplateMetric, error) {
+	m := raw
+	if wm, ok := raw.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return nil, fmt.Errorf("metric of type %T is not a template metric", raw)
+	}
+	return tm, nil
+}
+
 func (k *Kafka) SetSerializer(serializer serializers.Serializer) {
 	k.serializer = serializer
 }
@@ -127,6 +185,21 @@ func (k *Kafka) Init() error {
 	if err != nil {
 		return err
 	}
+
+	if k.TopicTemplate != "" {
+		k.topicTmpl, err = template.New("topic").Parse(k.TopicTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing topic_template failed: %w", err)
+		}
+	}
+
+	if k.RoutingKeyTemplate != "" {
+		k.routingKeyTmpl, err = template.New("routing_key").Parse(k.RoutingKeyTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing routing_key_template failed: %w", err)
+		}
+	}
+
 	config := sarama.NewConfig()
 
 	if err := k.SetConfig(config, k.Log); err != nil {
@@ -168,6 +241,19 @@ func (k *Kafka) Close() error {
 }
 
 func (k *Kafka) routingKey(metric telegraf.Metric) (string, error) {
+	if k.routingKeyTmpl != nil {
+		var buf bytes.Buffer
+		tm, err := templateMetric(metric)
+		if err == nil {
+			err = k.routingKeyTmpl.Execute(&buf, tm)
+		}
+		if err != nil {
+			k.Log.Debugf("Executing routing key template failed: %v", err)
+		} else if key := buf.String(); key != "" {
+			return key, nil
+		}
+	}
+
 	if k.RoutingTag != "" {
 		key, ok := metric.GetTag(k.RoutingTag)
 		if ok {
@@ -193,6 +279,10 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 
 		buf, err := k.serializer.Serialize(metric)
 		if err != nil {
+			// Retry the batch later if the schema registry is unavailable
+			if errors.Is(err, schemaregistry.ErrRequest) {
+				return err
+			}
 			k.Log.Debugf("Could not serialize metric: %v", err)
 			continue
 		}
@@ -218,32 +308,66 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 		msgs = append(msgs, m)
 	}
 
-	err := k.producer.SendMessages(msgs)
-	if err != nil {
-		// We could have many errors, return only the first encountered.
-		var errs sarama.ProducerErrors
-		if errors.As(err, &errs) && len(errs) > 0 {
-			// Just return the first error encountered
-			firstErr := errs[0]
-			if errors.Is(firstErr.Err, sarama.ErrMessageSizeTooLarge) {
-				k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping batch")
-				return nil
-			}
-			if errors.Is(firstErr.Err, sarama.ErrInvalidTimestamp) {
-				k.Log.Error(
-					"The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; " +
-						"dropping batch",
-				)
-				return nil
-			}
-			return firstErr
+	if k.TransactionalID != "" {
+		return k.sendTransactional(msgs)
+	}
+	return k.send(msgs)
+}
+
+// sendTransactional sends the messages within a transaction so consumers
+// reading committed messages only never see partial or duplicated batches.
+func (k *Kafka) sendTransactional(msgs []*sarama.ProducerMessage) error {
+	if err := k.producer.BeginTxn(); err != nil {
+		return fmt.Errorf("beginning transaction failed: %w", err)
+	}
+
+	if err := k.producer.SendMessages(msgs); err != nil {
+		if aerr := k.producer.AbortTxn(); aerr != nil {
+			k.Log.Errorf("Aborting transaction failed: %v", aerr)
 		}
-		return err
+		return k.handleSendError(err)
 	}
 
+	if err := k.producer.CommitTxn(); err != nil {
+		if aerr := k.producer.AbortTxn(); aerr != nil {
+			k.Log.Errorf("Aborting transaction failed: %v", aerr)
+		}
+		return fmt.Errorf("committing transaction failed: %w", err)
+	}
+	return nil
+}
+
+func (k *Kafka) send(msgs []*sarama.ProducerMessage) error {
+	if err := k.producer.SendMessages(msgs); err != nil {
+		return k.handleSendError(err)
+	}
 	return nil
 }
 
+// handleSendError returns nil if the batch should be dropped instead of
+// being retried.
+func (k *Kafka) handleSendError(err error) error {
+	// We could have many errors, return only the first encountered.
+	var errs sarama.ProducerErrors
+	if errors.As(err, &errs) && len(errs) > 0 {
+		// Just return the first error encountered
+		firstErr := errs[0]
+		if errors.Is(firstErr.Err, sarama.ErrMessageSizeTooLarge) {
+			k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping batch")
+			return nil
+		}
+		if errors.Is(firstErr.Err, sarama.ErrInvalidTimestamp) {
+			k.Log.Error(
+				"The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; " +
+					"dropping batch",
+			)
+			return nil
+		}
+		return firstErr
+	}
+	return err
+}
+
 func init() {
 	outputs.Add("kafka", func() telegraf.Output {
 		return &Kafka{
@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -316,3 +317,214 @@ func TestTopicTag(t *testing.T) {
 		})
 	}
 }
+
+type MockTxnProducer struct {
+	MockProducer
+	sendErr error
+	txns    []string
+}
+
+func (p *MockTxnProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	if p.sendErr != nil {
+		return p.sendErr
+	}
+	return p.MockProducer.SendMessages(msgs)
+}
+
+func (p *MockTxnProducer) BeginTxn() error {
+	p.txns = append(p.txns, "begin")
+	return nil
+}
+
+func (p *MockTxnProducer) CommitTxn() error {
+	p.txns = append(p.txns, "commit")
+	return nil
+}
+
+func (p *MockTxnProducer) AbortTxn() error {
+	p.txns = append(p.txns, "abort")
+	return nil
+}
+
+func TestTransactionalWrite(t *testing.T) {
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"time_idle": 42.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	tests := []struct {
+		name     string
+		sendErr  error
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "commit on success",
+			expected: []string{"begin", "commit"},
+		},
+		{
+			name:     "abort on failure",
+			sendErr:  sarama.ErrOutOfBrokers,
+			expected: []string{"begin", "abort"},
+			wantErr:  true,
+		},
+		{
+			name: "abort and drop oversized batch",
+			sendErr: sarama.ProducerErrors{
+				&sarama.ProducerError{Err: sarama.ErrMessageSizeTooLarge},
+			},
+			expected: []string{"begin", "abort"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Kafka{
+				Brokers: []string{"127.0.0.1"},
+				Topic:   "telegraf",
+				WriteConfig: kafka.WriteConfig{
+					RequiredAcks:    -1,
+					TransactionalID: "telegraf-1",
+				},
+				Log: testutil.Logger{},
+			}
+
+			s := &influx.Serializer{}
+			require.NoError(t, s.Init())
+			plugin.SetSerializer(s)
+
+			producer := &MockTxnProducer{sendErr: tt.sendErr}
+			plugin.producer = producer
+
+			err := plugin.Write(input)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expected, producer.txns)
+		})
+	}
+}
+
+func TestTopicTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		tags     map[string]string
+		expected string
+	}{
+		{
+			name:     "topic per team",
+			template: `{{with .Tag "team"}}metrics-{{.}}{{end}}`,
+			tags:     map[string]string{"team": "storage"},
+			expected: "metrics-storage",
+		},
+		{
+			name:     "measurement name",
+			template: `{{.Tag "team"}}.{{.Name}}`,
+			tags:     map[string]string{"team": "storage"},
+			expected: "storage.cpu",
+		},
+		{
+			name:     "empty result falls back to topic",
+			template: `{{with .Tag "team"}}metrics-{{.}}{{end}}`,
+			tags:     map[string]string{},
+			expected: "telegraf_cpu",
+		},
+		{
+			name:     "invalid topic falls back to topic",
+			template: `metrics/{{.Tag "team"}}`,
+			tags:     map[string]string{"team": "storage"},
+			expected: "telegraf_cpu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Kafka{
+				Topic:         "telegraf",
+				TopicTemplate: tt.template,
+				TopicSuffix:   TopicSuffix{Method: "measurement", Separator: "_"},
+				Log:           testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			m := metric.New(
+				"cpu",
+				tt.tags,
+				map[string]interface{}{
+					"value": 42.0,
+				},
+				time.Unix(0, 0),
+			)
+			_, topic := plugin.GetTopicName(m)
+			require.Equal(t, tt.expected, topic)
+		})
+	}
+}
+
+func TestRoutingKeyTemplate(t *testing.T) {
+	plugin := &Kafka{
+		RoutingTag:         "host",
+		RoutingKeyTemplate: `{{with .Tag "team"}}{{.}}-{{$.Tag "host"}}{{end}}`,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"team": "storage", "host": "a"},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	key, err := plugin.routingKey(m)
+	require.NoError(t, err)
+	require.Equal(t, "storage-a", key)
+
+	// Fall back to the routing tag if the template result is empty
+	m.RemoveTag("team")
+	key, err = plugin.routingKey(m)
+	require.NoError(t, err)
+	require.Equal(t, "a", key)
+}
+
+func TestTemplateTrackingMetric(t *testing.T) {
+	plugin := &Kafka{
+		Topic:              "telegraf",
+		TopicTemplate:      `metrics-{{.Tag "team"}}`,
+		RoutingKeyTemplate: `{{.Tag "host"}}`,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"team": "storage", "host": "a"},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	tm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) {})
+
+	_, topic := plugin.GetTopicName(tm)
+	require.Equal(t, "metrics-storage", topic)
+	key, err := plugin.routingKey(tm)
+	require.NoError(t, err)
+	require.Equal(t, "a", key)
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	plugin := &Kafka{
+		TopicTemplate: `{{.Tag "team"`,
+		Log:           testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "topic_template")
+}
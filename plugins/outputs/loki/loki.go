@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -49,6 +50,7 @@ type Loki struct {
 	url    string
 	client *http.Client
 	tls.ClientConfig
+	proxy.Socks5ProxyConfig
 }
 
 func (l *Loki) createClient(ctx context.Context) (*http.Client, error) {
@@ -57,12 +59,21 @@ func (l *Loki) createClient(ctx context.Context) (*http.Client, error) {
 		return nil, fmt.Errorf("tls config fail: %w", err)
 	}
 
+	transport := &http.Transport{
+		TLSClientConfig: tlsCfg,
+		Proxy:           http.ProxyFromEnvironment,
+	}
+	if l.Socks5ProxyEnabled {
+		dialer, err := l.Socks5ProxyConfig.GetProxiedDialer()
+		if err != nil {
+			return nil, fmt.Errorf("socks5 proxy config fail: %w", err)
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
 	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: tlsCfg,
-			Proxy:           http.ProxyFromEnvironment,
-		},
-		Timeout: time.Duration(l.Timeout),
+		Transport: transport,
+		Timeout:   time.Duration(l.Timeout),
 	}
 
 	if l.ClientID != "" && l.ClientSecret != "" && l.TokenURL != "" {
@@ -24,9 +24,9 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 			return nil, "", fmt.Errorf("generating device name failed: %w", err)
 		}
 		messages = append(messages,
-			message{topic + "/$homie", []byte("4.0")},
-			message{topic + "/$name", []byte(deviceName)},
-			message{topic + "/$state", []byte("ready")},
+			message{topic + "/$homie", []byte("4.0"), nil},
+			message{topic + "/$name", []byte(deviceName), nil},
+			message{topic + "/$state", []byte("ready"), nil},
 		)
 		m.homieSeen[topic] = make(map[string]bool)
 	}
@@ -46,8 +46,8 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 		}
 		sort.Strings(nodeIDs)
 		messages = append(messages,
-			message{topic + "/$nodes", []byte(strings.Join(nodeIDs, ","))},
-			message{topic + "/" + nodeID + "/$name", []byte(nodeName)},
+			message{topic + "/$nodes", []byte(strings.Join(nodeIDs, ",")), nil},
+			message{topic + "/" + nodeID + "/$name", []byte(nodeName), nil},
 		)
 	}
 
@@ -63,6 +63,7 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 	messages = append(messages, message{
 		topic + "/" + nodeID + "/$properties",
 		[]byte(strings.Join(properties, ",")),
+		nil,
 	})
 
 	return messages, nodeID, nil
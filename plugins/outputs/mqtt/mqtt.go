@@ -21,23 +21,26 @@ import (
 var sampleConfig string
 
 type message struct {
-	topic   string
-	payload []byte
+	topic      string
+	payload    []byte
+	properties map[string]string
 }
 
 type MQTT struct {
-	TopicPrefix     string          `toml:"topic_prefix" deprecated:"1.25.0;use 'topic' instead"`
-	Topic           string          `toml:"topic"`
-	BatchMessage    bool            `toml:"batch" deprecated:"1.25.2;use 'layout = \"batch\"' instead"`
-	Layout          string          `toml:"layout"`
-	HomieDeviceName string          `toml:"homie_device_name"`
-	HomieNodeID     string          `toml:"homie_node_id"`
-	Log             telegraf.Logger `toml:"-"`
+	TopicPrefix     string            `toml:"topic_prefix" deprecated:"1.25.0;use 'topic' instead"`
+	Topic           string            `toml:"topic"`
+	BatchMessage    bool              `toml:"batch" deprecated:"1.25.2;use 'layout = \"batch\"' instead"`
+	Layout          string            `toml:"layout"`
+	HomieDeviceName string            `toml:"homie_device_name"`
+	HomieNodeID     string            `toml:"homie_node_id"`
+	TopicTemplates  map[string]string `toml:"topic_templates"`
+	Log             telegraf.Logger   `toml:"-"`
 	mqtt.MqttConfig
 
-	client     mqtt.Client
-	serializer serializers.Serializer
-	generator  *TopicNameGenerator
+	client          mqtt.Client
+	serializer      serializers.Serializer
+	generator       *TopicNameGenerator
+	metricGenerator map[string]*TopicNameGenerator
 
 	homieDeviceNameGenerator *HomieGenerator
 	homieNodeIDGenerator     *HomieGenerator
@@ -68,6 +71,14 @@ func (m *MQTT) Init() error {
 		return err
 	}
 
+	m.metricGenerator = make(map[string]*TopicNameGenerator, len(m.TopicTemplates))
+	for name, topic := range m.TopicTemplates {
+		m.metricGenerator[name], err = NewTopicNameGenerator(m.TopicPrefix, topic)
+		if err != nil {
+			return fmt.Errorf("invalid topic template for metric %q: %w", name, err)
+		}
+	}
+
 	switch m.Layout {
 	case "":
 		// For backward compatibility
@@ -130,7 +141,7 @@ func (m *MQTT) Close() error {
 	if len(m.homieSeen) > 0 {
 		for topic := range m.homieSeen {
 			// We will ignore potential errors as we cannot do anything here
-			_ = m.client.Publish(topic+"/$state", []byte("lost"))
+			_ = m.client.Publish(topic+"/$state", []byte("lost"), nil)
 		}
 		// Give the messages some time to settle
 		time.Sleep(100 * time.Millisecond)
@@ -166,7 +177,7 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	}
 
 	for _, msg := range topicMessages {
-		if err := m.client.Publish(msg.topic, msg.payload); err != nil {
+		if err := m.client.Publish(msg.topic, msg.payload, msg.properties); err != nil {
 			m.Log.Warnf("Could not publish message to MQTT server: %v", err)
 		}
 	}
@@ -177,7 +188,7 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 func (m *MQTT) collectNonBatch(hostname string, metrics []telegraf.Metric) []message {
 	collection := make([]message, 0, len(metrics))
 	for _, metric := range metrics {
-		topic, err := m.generator.Generate(hostname, metric)
+		topic, err := m.generateTopic(hostname, metric)
 		if err != nil {
 			m.Log.Warnf("Generating topic name failed: %v", err)
 			m.Log.Debugf("metric was: %v", metric)
@@ -190,7 +201,7 @@ func (m *MQTT) collectNonBatch(hostname string, metrics []telegraf.Metric) []mes
 			m.Log.Debugf("metric was: %v", metric)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, m.userProperties(metric)})
 	}
 
 	return collection
@@ -199,7 +210,7 @@ func (m *MQTT) collectNonBatch(hostname string, metrics []telegraf.Metric) []mes
 func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []message {
 	metricsCollection := make(map[string][]telegraf.Metric)
 	for _, metric := range metrics {
-		topic, err := m.generator.Generate(hostname, metric)
+		topic, err := m.generateTopic(hostname, metric)
 		if err != nil {
 			m.Log.Warnf("Generating topic name failed: %v", err)
 			m.Log.Debugf("metric was: %v", metric)
@@ -215,7 +226,7 @@ func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []messag
 			m.Log.Warnf("Could not serialize metric batch for topic %q: %v", topic, err)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, m.batchUserProperties(ms)})
 	}
 	return collection
 }
@@ -223,13 +234,14 @@ func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []messag
 func (m *MQTT) collectField(hostname string, metrics []telegraf.Metric) []message {
 	var collection []message
 	for _, metric := range metrics {
-		topic, err := m.generator.Generate(hostname, metric)
+		topic, err := m.generateTopic(hostname, metric)
 		if err != nil {
 			m.Log.Warnf("Generating topic name failed: %v", err)
 			m.Log.Debugf("metric was: %v", metric)
 			continue
 		}
 
+		properties := m.userProperties(metric)
 		for n, v := range metric.Fields() {
 			buf, err := internal.ToString(v)
 			if err != nil {
@@ -237,7 +249,7 @@ func (m *MQTT) collectField(hostname string, metrics []telegraf.Metric) []messag
 				m.Log.Debugf("metric was: %v", metric)
 				continue
 			}
-			collection = append(collection, message{topic + "/" + n, []byte(buf)})
+			collection = append(collection, message{topic + "/" + n, []byte(buf), properties})
 		}
 	}
 
@@ -247,7 +259,7 @@ func (m *MQTT) collectField(hostname string, metrics []telegraf.Metric) []messag
 func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []message {
 	var collection []message
 	for _, metric := range metrics {
-		topic, err := m.generator.Generate(hostname, metric)
+		topic, err := m.generateTopic(hostname, metric)
 		if err != nil {
 			m.Log.Warnf("Generating topic name failed: %v", err)
 			m.Log.Debugf("metric was: %v", metric)
@@ -261,6 +273,10 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 			continue
 		}
 		path := topic + "/" + nodeID
+		properties := m.userProperties(metric)
+		for i := range msgs {
+			msgs[i].properties = properties
+		}
 		collection = append(collection, msgs...)
 
 		for _, tag := range metric.TagList() {
@@ -271,9 +287,9 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 			}
 			propID := normalizeID(tag.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(tag.Value)},
-				message{path + "/" + propID + "/$name", []byte(tag.Key)},
-				message{path + "/" + propID + "/$datatype", []byte("string")},
+				message{path + "/" + propID, []byte(tag.Value), properties},
+				message{path + "/" + propID + "/$name", []byte(tag.Key), properties},
+				message{path + "/" + propID + "/$datatype", []byte("string"), properties},
 			)
 		}
 
@@ -286,9 +302,9 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 			}
 			propID := normalizeID(field.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(v)},
-				message{path + "/" + propID + "/$name", []byte(field.Key)},
-				message{path + "/" + propID + "/$datatype", []byte(dt)},
+				message{path + "/" + propID, []byte(v), properties},
+				message{path + "/" + propID + "/$name", []byte(field.Key), properties},
+				message{path + "/" + propID + "/$datatype", []byte(dt), properties},
 			)
 		}
 	}
@@ -296,6 +312,47 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 	return collection
 }
 
+func (m *MQTT) generateTopic(hostname string, metric telegraf.Metric) (string, error) {
+	if generator, found := m.metricGenerator[metric.Name()]; found {
+		return generator.Generate(hostname, metric)
+	}
+	return m.generator.Generate(hostname, metric)
+}
+
+// userProperties returns the MQTT 5 user properties of the metric's tags
+// selected by 'user_property_tags'
+func (m *MQTT) userProperties(metric telegraf.Metric) map[string]string {
+	if m.PublishPropertiesV5 == nil || len(m.PublishPropertiesV5.UserPropertyTags) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]string, len(m.PublishPropertiesV5.UserPropertyTags))
+	for _, key := range m.PublishPropertiesV5.UserPropertyTags {
+		if value, found := metric.GetTag(key); found {
+			properties[key] = value
+		}
+	}
+	return properties
+}
+
+// batchUserProperties returns the user properties shared by all metrics of
+// the batch as a message can only carry a single value per property
+func (m *MQTT) batchUserProperties(metrics []telegraf.Metric) map[string]string {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	properties := m.userProperties(metrics[0])
+	for _, metric := range metrics[1:] {
+		for key, value := range properties {
+			if v, found := metric.GetTag(key); !found || v != value {
+				delete(properties, key)
+			}
+		}
+	}
+	return properties
+}
+
 func init() {
 	outputs.Add("mqtt", func() telegraf.Output {
 		return &MQTT{
@@ -508,7 +508,7 @@ func TestIntegrationMQTTLayoutField(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{msg.Topic(), msg.Payload(), nil})
 	}
 
 	// Add routing for the messages
@@ -625,7 +625,7 @@ func TestIntegrationMQTTLayoutHomieV4(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{msg.Topic(), msg.Payload(), nil})
 	}
 
 	// Add routing for the messages
@@ -931,3 +931,67 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateTopicNamePerMetric(t *testing.T) {
+	m := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		Topic: "telegraf/{{ .PluginName }}",
+		TopicTemplates: map[string]string{
+			"modbus": `factory/{{ .Tag "line" }}/{{ .PluginName }}`,
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+
+	tags := map[string]string{"line": "l1"}
+	fields := map[string]interface{}{"value": 1}
+	modbus := metric.New("modbus", tags, fields, time.Unix(0, 0))
+	cpu := metric.New("cpu", tags, fields, time.Unix(0, 0))
+
+	actual, err := m.generateTopic("hostname", modbus)
+	require.NoError(t, err)
+	require.Equal(t, "factory/l1/modbus", actual)
+
+	actual, err = m.generateTopic("hostname", cpu)
+	require.NoError(t, err)
+	require.Equal(t, "telegraf/cpu", actual)
+}
+
+func TestInvalidTopicTemplate(t *testing.T) {
+	m := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		TopicTemplates: map[string]string{"cpu": "this/is/#/invalid"},
+	}
+	require.ErrorContains(t, m.Init(), `invalid topic template for metric "cpu"`)
+}
+
+func TestUserPropertiesFromTags(t *testing.T) {
+	m := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers:  []string{"tcp://localhost:1883"},
+			Protocol: "5",
+			PublishPropertiesV5: &mqtt.PublishProperties{
+				UserPropertyTags: []string{"line", "machine"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, m.Init())
+
+	fields := map[string]interface{}{"value": 1}
+	metrics := []telegraf.Metric{
+		metric.New("modbus", map[string]string{"line": "l1", "machine": "m1", "host": "a"}, fields, time.Unix(0, 0)),
+		metric.New("modbus", map[string]string{"line": "l1", "machine": "m2"}, fields, time.Unix(0, 0)),
+	}
+
+	require.Equal(t, map[string]string{"line": "l1", "machine": "m1"}, m.userProperties(metrics[0]))
+	require.Equal(t, map[string]string{"line": "l1"}, m.batchUserProperties(metrics))
+
+	// No properties without configured tags
+	m.PublishPropertiesV5 = nil
+	require.Nil(t, m.userProperties(metrics[0]))
+}
@@ -2,11 +2,13 @@
 package nats
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -24,19 +26,22 @@ import (
 var sampleConfig string
 
 type NATS struct {
-	Servers     []string      `toml:"servers"`
-	Secure      bool          `toml:"secure"`
-	Name        string        `toml:"name"`
-	Username    config.Secret `toml:"username"`
-	Password    config.Secret `toml:"password"`
-	Credentials string        `toml:"credentials"`
-	Subject     string        `toml:"subject"`
-	Jetstream   *StreamConfig `toml:"jetstream"`
+	Servers     []string          `toml:"servers"`
+	Secure      bool              `toml:"secure"`
+	Name        string            `toml:"name"`
+	Username    config.Secret     `toml:"username"`
+	Password    config.Secret     `toml:"password"`
+	Credentials string            `toml:"credentials"`
+	Subject     string            `toml:"subject"`
+	SubjectTmpl string            `toml:"subject_template"`
+	Headers     map[string]string `toml:"headers"`
+	Jetstream   *StreamConfig     `toml:"jetstream"`
 	tls.ClientConfig
 
 	Log telegraf.Logger `toml:"-"`
 
 	conn                  *nats.Conn
+	subjectTmpl           *template.Template
 	jetstreamClient       jetstream.JetStream
 	jetstreamStreamConfig *jetstream.StreamConfig
 	serializer            serializers.Serializer
@@ -77,6 +82,11 @@ type StreamConfig struct {
 	MirrorDirect         bool                              `toml:"mirror_direct"`
 	ConsumerLimits       jetstream.StreamConsumerLimits    `toml:"consumer_limits"`
 	Metadata             map[string]string                 `toml:"metadata"`
+
+	// Publishing settings
+	AckTimeout    config.Duration `toml:"ack_timeout"`
+	RetryAttempts int             `toml:"retry_attempts"`
+	RetryWait     config.Duration `toml:"retry_wait"`
 }
 
 func (*NATS) SampleConfig() string {
@@ -228,6 +238,14 @@ func (n *NATS) getJetstreamConfig() (*jetstream.StreamConfig, error) {
 }
 
 func (n *NATS) Init() error {
+	if n.SubjectTmpl != "" {
+		var err error
+		n.subjectTmpl, err = template.New("subject").Parse(n.SubjectTmpl)
+		if err != nil {
+			return fmt.Errorf("parsing subject_template failed: %w", err)
+		}
+	}
+
 	if n.Jetstream != nil {
 		if strings.TrimSpace(n.Jetstream.Name) == "" {
 			return errors.New("stream cannot be empty")
@@ -246,6 +264,19 @@ func (n *NATS) Init() error {
 		if err != nil {
 			return fmt.Errorf("failed to parse jetstream config: %w", err)
 		}
+
+		if n.Jetstream.AckTimeout <= 0 {
+			n.Jetstream.AckTimeout = config.Duration(5 * time.Second)
+		}
+		if n.Jetstream.RetryAttempts < 0 {
+			return errors.New("retry_attempts must not be negative")
+		}
+		if n.Jetstream.RetryAttempts == 0 {
+			n.Jetstream.RetryAttempts = 2
+		}
+		if n.Jetstream.RetryWait <= 0 {
+			n.Jetstream.RetryWait = config.Duration(250 * time.Millisecond)
+		}
 	}
 	return nil
 }
@@ -259,21 +290,125 @@ func (n *NATS) Write(metrics []telegraf.Metric) error {
 	if len(metrics) == 0 {
 		return nil
 	}
+
+	msgs := make([]*nats.Msg, 0, len(metrics))
 	for _, metric := range metrics {
 		buf, err := n.serializer.Serialize(metric)
 		if err != nil {
 			n.Log.Debugf("Could not serialize metric: %v", err)
 			continue
 		}
-		// use the same Publish API for nats core and jetstream
-		err = n.conn.Publish(n.Subject, buf)
-		if err != nil {
+
+		msg := nats.NewMsg(n.subject(metric))
+		msg.Data = buf
+		for k, v := range n.Headers {
+			msg.Header.Set(k, v)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if n.jetstreamClient != nil {
+		if err := n.publishJetstream(msgs); err != nil {
+			return fmt.Errorf("FAILED to send NATS message: %w", err)
+		}
+		return nil
+	}
+
+	for _, msg := range msgs {
+		if err := n.conn.PublishMsg(msg); err != nil {
 			return fmt.Errorf("FAILED to send NATS message: %w", err)
 		}
 	}
 	return nil
 }
 
+// publishJetstream publishes the messages asynchronously and waits for the
+// acknowledgements of the stream, so the messages are only considered written
+// once persisted. Messages that are not acknowledged are published again.
+func (n *NATS) publishJetstream(msgs []*nats.Msg) error {
+	opt := jetstream.WithExpectStream(n.Jetstream.Name)
+	for attempt := 0; ; attempt++ {
+		futures := make([]jetstream.PubAckFuture, 0, len(msgs))
+		for _, msg := range msgs {
+			future, err := n.jetstreamClient.PublishMsgAsync(msg, opt)
+			if err != nil {
+				return err
+			}
+			futures = append(futures, future)
+		}
+
+		select {
+		case <-n.jetstreamClient.PublishAsyncComplete():
+		case <-time.After(time.Duration(n.Jetstream.AckTimeout)):
+			return errors.New("timeout waiting for acknowledgements")
+		}
+
+		var lastErr error
+		failed := make([]*nats.Msg, 0)
+		for _, future := range futures {
+			select {
+			case <-future.Ok():
+			case err := <-future.Err():
+				failed = append(failed, future.Msg())
+				lastErr = err
+			}
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		if attempt >= n.Jetstream.RetryAttempts {
+			return fmt.Errorf("%d message(s) not acknowledged: %w", len(failed), lastErr)
+		}
+		n.Log.Debugf("Retrying %d message(s) not acknowledged: %v", len(failed), lastErr)
+		time.Sleep(time.Duration(n.Jetstream.RetryWait))
+		msgs = failed
+	}
+}
+
+// subject returns the subject generated by the subject template or the
+// configured subject if the template does not produce a valid subject.
+func (n *NATS) subject(metric telegraf.Metric) string {
+	if n.subjectTmpl == nil {
+		return n.Subject
+	}
+
+	m := metric
+	if wm, ok := metric.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		n.Log.Debugf("Metric of type %T is not a template metric", metric)
+		return n.Subject
+	}
+
+	var buf bytes.Buffer
+	if err := n.subjectTmpl.Execute(&buf, tm); err != nil {
+		n.Log.Debugf("Executing subject template failed: %v", err)
+		return n.Subject
+	}
+	subject := buf.String()
+	if !validSubject(subject) {
+		n.Log.Debugf("Invalid subject %q generated by template, using %q", subject, n.Subject)
+		return n.Subject
+	}
+	return subject
+}
+
+// validSubject checks if the subject can be used for publishing, i.e. it
+// consists of non-empty tokens without whitespace or wildcards.
+func validSubject(subject string) bool {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return false
+	}
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" || token == "*" || token == ">" {
+			return false
+		}
+	}
+	return true
+}
+
 func init() {
 	outputs.Add("nats", func() telegraf.Output {
 		return &NATS{}
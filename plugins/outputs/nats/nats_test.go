@@ -3,18 +3,21 @@ package nats
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/docker/go-connections/nat"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -183,3 +186,152 @@ func TestConfigParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestSubjectTemplate(t *testing.T) {
+	plugin := &NATS{
+		Subject:     "telegraf",
+		SubjectTmpl: `telegraf.{{.Tag "team"}}.{{.Name}}`,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"team": "storage"},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(0, 0),
+	)
+	require.Equal(t, "telegraf.storage.cpu", plugin.subject(m))
+
+	// Tracking metrics need to be unwrapped for the template
+	tm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) {})
+	require.Equal(t, "telegraf.storage.cpu", plugin.subject(tm))
+
+	// Missing tags result in empty tokens so the subject is used instead
+	m.RemoveTag("team")
+	require.Equal(t, "telegraf", plugin.subject(m))
+}
+
+func TestValidSubject(t *testing.T) {
+	require.True(t, validSubject("telegraf"))
+	require.True(t, validSubject("telegraf.storage.cpu"))
+	require.False(t, validSubject(""))
+	require.False(t, validSubject("telegraf..cpu"))
+	require.False(t, validSubject("telegraf.*"))
+	require.False(t, validSubject("telegraf.>"))
+	require.False(t, validSubject("tele graf"))
+}
+
+func TestWriteJetstreamRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  map[string]int
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "all acknowledged",
+			expected: []string{"telegraf.a", "telegraf.b", "telegraf.c"},
+		},
+		{
+			name:     "retry failed only",
+			failures: map[string]int{"telegraf.b": 2},
+			expected: []string{"telegraf.a", "telegraf.b", "telegraf.c", "telegraf.b", "telegraf.b"},
+		},
+		{
+			name:      "retries exhausted",
+			failures:  map[string]int{"telegraf.b": 3},
+			expected:  []string{"telegraf.a", "telegraf.b", "telegraf.c", "telegraf.b", "telegraf.b"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serializer := &influx.Serializer{}
+			require.NoError(t, serializer.Init())
+
+			plugin := &NATS{
+				Subject:     "telegraf",
+				SubjectTmpl: "telegraf.{{.Name}}",
+				Jetstream: &StreamConfig{
+					Name:      "telegraf",
+					RetryWait: config.Duration(time.Millisecond),
+				},
+				Log: testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			plugin.SetSerializer(serializer)
+
+			client := &fakeJetstream{failures: tt.failures}
+			plugin.jetstreamClient = client
+
+			metrics := make([]telegraf.Metric, 0, 3)
+			for _, name := range []string{"a", "b", "c"} {
+				metrics = append(metrics, testutil.MustMetric(
+					name,
+					map[string]string{},
+					map[string]interface{}{"value": 42.0},
+					time.Unix(0, 0),
+				))
+			}
+
+			err := plugin.Write(metrics)
+			if tt.expectErr {
+				require.ErrorContains(t, err, "not acknowledged")
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expected, client.published)
+		})
+	}
+}
+
+// fakeJetstream acknowledges all published messages except for the given
+// number of failures per subject
+type fakeJetstream struct {
+	jetstream.JetStream
+	failures  map[string]int
+	published []string
+}
+
+func (js *fakeJetstream) PublishMsgAsync(msg *nats.Msg, _ ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	js.published = append(js.published, msg.Subject)
+
+	future := &fakeFuture{
+		msg: msg,
+		ok:  make(chan *jetstream.PubAck, 1),
+		err: make(chan error, 1),
+	}
+	if js.failures[msg.Subject] > 0 {
+		js.failures[msg.Subject]--
+		future.err <- errors.New("no responders")
+	} else {
+		future.ok <- &jetstream.PubAck{Stream: "telegraf"}
+	}
+	return future, nil
+}
+
+func (*fakeJetstream) PublishAsyncComplete() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+type fakeFuture struct {
+	msg *nats.Msg
+	ok  chan *jetstream.PubAck
+	err chan error
+}
+
+func (f *fakeFuture) Ok() <-chan *jetstream.PubAck {
+	return f.ok
+}
+
+func (f *fakeFuture) Err() <-chan error {
+	return f.err
+}
+
+func (f *fakeFuture) Msg() *nats.Msg {
+	return f.msg
+}
@@ -0,0 +1,248 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package notify
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const maxErrMsgLen = 1024
+
+type Notify struct {
+	URL            string          `toml:"url"`
+	Service        string          `toml:"service"`
+	Token          config.Secret   `toml:"token"`
+	SendResolved   bool            `toml:"send_resolved"`
+	RepeatInterval config.Duration `toml:"repeat_interval"`
+	Rules          []*Rule         `toml:"rule"`
+	Log            telegraf.Logger `toml:"-"`
+	httpconfig.HTTPClientConfig
+
+	client *http.Client
+	alerts map[string]*alert
+}
+
+// alert is the state of a rule firing for a series
+type alert struct {
+	lastSent time.Time
+}
+
+// notification is the content of a message sent to the service
+type notification struct {
+	title    string
+	message  string
+	priority string
+	resolved bool
+}
+
+func (*Notify) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *Notify) Init() error {
+	if n.URL == "" {
+		return errors.New("url is required")
+	}
+
+	switch n.Service {
+	case "":
+		n.Service = "ntfy"
+	case "ntfy", "webhook":
+	default:
+		return fmt.Errorf("invalid service %q", n.Service)
+	}
+
+	if len(n.Rules) == 0 {
+		return errors.New("no rules defined")
+	}
+	seen := make(map[string]bool, len(n.Rules))
+	for _, r := range n.Rules {
+		if err := r.init(); err != nil {
+			return err
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		seen[r.Name] = true
+	}
+
+	n.alerts = make(map[string]*alert)
+
+	return nil
+}
+
+func (n *Notify) Connect() error {
+	client, err := n.HTTPClientConfig.CreateClient(context.Background(), n.Log)
+	if err != nil {
+		return err
+	}
+	n.client = client
+	return nil
+}
+
+func (n *Notify) Close() error {
+	if n.client != nil {
+		n.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Write evaluates the rules against the metrics and sends notifications for
+// series starting or stopping to fire. Notifications are only sent once per
+// state change unless a repeat interval is configured.
+func (n *Notify) Write(metrics []telegraf.Metric) error {
+	now := time.Now()
+
+	var errs []error
+	for _, m := range metrics {
+		for _, r := range n.Rules {
+			v, ok := r.value(m)
+			if !ok {
+				continue
+			}
+
+			id := r.Name + "\x00" + strconv.FormatUint(m.HashID(), 16)
+			active, found := n.alerts[id]
+			firing := r.firing(v)
+
+			switch {
+			case firing && !found:
+				if err := n.send(n.notification(r, m, v, false)); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				n.alerts[id] = &alert{lastSent: now}
+			case firing && n.RepeatInterval > 0 && now.Sub(active.lastSent) >= time.Duration(n.RepeatInterval):
+				if err := n.send(n.notification(r, m, v, false)); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				active.lastSent = now
+			case !firing && found:
+				if n.SendResolved {
+					if err := n.send(n.notification(r, m, v, true)); err != nil {
+						errs = append(errs, err)
+						continue
+					}
+				}
+				delete(n.alerts, id)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notify) notification(r *Rule, m telegraf.Metric, v float64, resolved bool) *notification {
+	series := seriesName(m)
+	value := strconv.FormatFloat(v, 'f', -1, 64)
+	if resolved {
+		return &notification{
+			title:    fmt.Sprintf("[RESOLVED] %s on %s", r.Name, series),
+			message:  fmt.Sprintf("%s=%s no longer matches %s", r.Field, value, r.condition()),
+			priority: "default",
+			resolved: true,
+		}
+	}
+	return &notification{
+		title:    fmt.Sprintf("[FIRING] %s on %s", r.Name, series),
+		message:  fmt.Sprintf("%s=%s matches %s", r.Field, value, r.condition()),
+		priority: r.Priority,
+	}
+}
+
+func (n *Notify) send(msg *notification) error {
+	var body []byte
+	switch n.Service {
+	case "ntfy":
+		body = []byte(msg.message)
+	case "webhook":
+		// Slack-compatible payload
+		var err error
+		body, err = json.Marshal(map[string]string{"text": msg.title + "\n" + msg.message})
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+
+	switch n.Service {
+	case "ntfy":
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		req.Header.Set("Title", msg.title)
+		if msg.priority != "" {
+			req.Header.Set("Priority", msg.priority)
+		}
+		if msg.resolved {
+			req.Header.Set("Tags", "white_check_mark")
+		} else {
+			req.Header.Set("Tags", "warning")
+		}
+	case "webhook":
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if !n.Token.Empty() {
+		token, err := n.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrMsgLen))
+		return fmt.Errorf("sending notification %q failed with status %d: %s", msg.title, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// seriesName returns the metric name and tags in line-protocol style
+func seriesName(m telegraf.Metric) string {
+	tags := m.TagList()
+	parts := make([]string, 0, len(tags)+1)
+	parts = append(parts, m.Name())
+	for _, tag := range tags {
+		parts = append(parts, tag.Key+"="+tag.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func init() {
+	outputs.Add("notify", func() telegraf.Output {
+		return &Notify{
+			Service:      "ntfy",
+			SendResolved: true,
+		}
+	})
+}
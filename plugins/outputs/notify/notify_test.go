@@ -0,0 +1,246 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type request struct {
+	header http.Header
+	body   string
+}
+
+type server struct {
+	*httptest.Server
+
+	sync.Mutex
+	requests []request
+	status   int
+}
+
+func newServer(t *testing.T) *server {
+	s := &server{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if s.status != http.StatusOK {
+			w.WriteHeader(s.status)
+			_, _ = w.Write([]byte("unavailable"))
+			return
+		}
+		s.requests = append(s.requests, request{header: r.Header, body: string(body)})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func cpu(host string, usage float64) telegraf.Metric {
+	return testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": host},
+		map[string]interface{}{"usage_active": usage},
+		time.Unix(0, 0),
+	)
+}
+
+func TestNtfy(t *testing.T) {
+	srv := newServer(t)
+	plugin := &Notify{
+		URL:          srv.URL,
+		Token:        config.NewSecret([]byte("secret")),
+		SendResolved: true,
+		Rules: []*Rule{
+			{
+				Name:     "high cpu",
+				Metrics:  []string{"cpu"},
+				Field:    "usage_active",
+				Priority: "high",
+				GT:       float(90),
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Only the first matching metric of a series is notified
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu("a", 95), cpu("b", 10), cpu("a", 99)}))
+	require.Len(t, srv.requests, 1)
+	req := srv.requests[0]
+	require.Equal(t, "[FIRING] high cpu on cpu,host=a", req.header.Get("Title"))
+	require.Equal(t, "high", req.header.Get("Priority"))
+	require.Equal(t, "warning", req.header.Get("Tags"))
+	require.Equal(t, "Bearer secret", req.header.Get("Authorization"))
+	require.Equal(t, "usage_active=95 matches usage_active > 90", req.body)
+
+	// Resolve the alert
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu("a", 50)}))
+	require.Len(t, srv.requests, 2)
+	req = srv.requests[1]
+	require.Equal(t, "[RESOLVED] high cpu on cpu,host=a", req.header.Get("Title"))
+	require.Equal(t, "white_check_mark", req.header.Get("Tags"))
+	require.Equal(t, "usage_active=50 no longer matches usage_active > 90", req.body)
+
+	// Nothing to resolve anymore
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu("a", 50)}))
+	require.Len(t, srv.requests, 2)
+}
+
+func TestWebhook(t *testing.T) {
+	srv := newServer(t)
+	plugin := &Notify{
+		URL:     srv.URL,
+		Service: "webhook",
+		Rules: []*Rule{
+			{
+				Name:  "disk full",
+				Field: "used_percent",
+				GE:    float(95),
+				LT:    float(100),
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	m := testutil.MustMetric(
+		"disk",
+		map[string]string{"path": "/", "host": "a"},
+		map[string]interface{}{"used_percent": 97.5},
+		time.Unix(0, 0),
+	)
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, srv.requests, 1)
+
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal([]byte(srv.requests[0].body), &payload))
+	require.Equal(t, map[string]string{
+		"text": "[FIRING] disk full on disk,host=a,path=/\nused_percent=97.5 matches used_percent >= 95 and used_percent < 100",
+	}, payload)
+	require.Equal(t, "application/json", srv.requests[0].header.Get("Content-Type"))
+
+	// Resolve notifications are disabled
+	m = testutil.MustMetric(
+		"disk",
+		map[string]string{"path": "/", "host": "a"},
+		map[string]interface{}{"used_percent": 50.0},
+		time.Unix(0, 0),
+	)
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, srv.requests, 1)
+	require.Empty(t, plugin.alerts)
+}
+
+func TestRepeatInterval(t *testing.T) {
+	srv := newServer(t)
+	plugin := &Notify{
+		URL:            srv.URL,
+		RepeatInterval: config.Duration(time.Hour),
+		Rules:          []*Rule{{Name: "high cpu", Field: "usage_active", GT: float(90)}},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu("a", 95)}))
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu("a", 95)}))
+	require.Len(t, srv.requests, 1)
+
+	// Age the alert to trigger the repetition
+	for _, a := range plugin.alerts {
+		a.lastSent = time.Now().Add(-2 * time.Hour)
+	}
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu("a", 95)}))
+	require.Len(t, srv.requests, 2)
+}
+
+func TestSendFailureRetries(t *testing.T) {
+	srv := newServer(t)
+	srv.status = http.StatusServiceUnavailable
+	plugin := &Notify{
+		URL:   srv.URL,
+		Rules: []*Rule{{Name: "high cpu", Field: "usage_active", GT: float(90)}},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{cpu("a", 95)}), "failed with status 503: unavailable")
+	require.Empty(t, plugin.alerts)
+
+	// The notification is sent with the retried write
+	srv.status = http.StatusOK
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu("a", 95)}))
+	require.Len(t, srv.requests, 1)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Notify
+		expected string
+	}{
+		{
+			name:     "missing url",
+			plugin:   &Notify{},
+			expected: "url is required",
+		},
+		{
+			name:     "invalid service",
+			plugin:   &Notify{URL: "http://localhost", Service: "email"},
+			expected: `invalid service "email"`,
+		},
+		{
+			name:     "no rules",
+			plugin:   &Notify{URL: "http://localhost"},
+			expected: "no rules defined",
+		},
+		{
+			name:     "no threshold",
+			plugin:   &Notify{URL: "http://localhost", Rules: []*Rule{{Name: "a", Field: "value"}}},
+			expected: `rule "a": no threshold defined`,
+		},
+		{
+			name: "duplicate rule",
+			plugin: &Notify{
+				URL: "http://localhost",
+				Rules: []*Rule{
+					{Name: "a", Field: "value", GT: float(1)},
+					{Name: "a", Field: "value", LT: float(1)},
+				},
+			},
+			expected: `duplicate rule "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
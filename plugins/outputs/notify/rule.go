@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Rule defines a threshold on a field of the selected metrics. The rule fires
+// if ALL given comparisons are true.
+type Rule struct {
+	Name     string   `toml:"name"`
+	Metrics  []string `toml:"metrics"`
+	Field    string   `toml:"field"`
+	Priority string   `toml:"priority"`
+	GT       *float64 `toml:"gt"`
+	GE       *float64 `toml:"ge"`
+	LT       *float64 `toml:"lt"`
+	LE       *float64 `toml:"le"`
+	EQ       *float64 `toml:"eq"`
+	NE       *float64 `toml:"ne"`
+
+	filter filter.Filter
+}
+
+func (r *Rule) init() error {
+	if r.Name == "" {
+		return errors.New("rule without name")
+	}
+	if r.Field == "" {
+		return fmt.Errorf("rule %q: field is required", r.Name)
+	}
+	if r.GT == nil && r.GE == nil && r.LT == nil && r.LE == nil && r.EQ == nil && r.NE == nil {
+		return fmt.Errorf("rule %q: no threshold defined", r.Name)
+	}
+
+	f, err := filter.Compile(r.Metrics)
+	if err != nil {
+		return fmt.Errorf("rule %q: compiling metrics filter failed: %w", r.Name, err)
+	}
+	r.filter = f
+
+	return nil
+}
+
+// value returns the value of the rule's field if the rule applies to the
+// metric
+func (r *Rule) value(m telegraf.Metric) (float64, bool) {
+	if r.filter != nil && !r.filter.Match(m.Name()) {
+		return 0, false
+	}
+
+	v, found := m.GetField(r.Field)
+	if !found {
+		return 0, false
+	}
+
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (r *Rule) firing(v float64) bool {
+	if r.GT != nil && !(v > *r.GT) {
+		return false
+	}
+	if r.GE != nil && !(v >= *r.GE) {
+		return false
+	}
+	if r.LT != nil && !(v < *r.LT) {
+		return false
+	}
+	if r.LE != nil && !(v <= *r.LE) {
+		return false
+	}
+	if r.EQ != nil && !(v == *r.EQ) {
+		return false
+	}
+	if r.NE != nil && !(v != *r.NE) {
+		return false
+	}
+	return true
+}
+
+// condition returns a human readable representation of the thresholds
+func (r *Rule) condition() string {
+	conditions := make([]string, 0, 6)
+	for _, c := range []struct {
+		op        string
+		threshold *float64
+	}{
+		{">", r.GT}, {">=", r.GE}, {"<", r.LT}, {"<=", r.LE}, {"==", r.EQ}, {"!=", r.NE},
+	} {
+		if c.threshold != nil {
+			conditions = append(conditions, r.Field+" "+c.op+" "+strconv.FormatFloat(*c.threshold, 'f', -1, 64))
+		}
+	}
+	return strings.Join(conditions, " and ")
+}
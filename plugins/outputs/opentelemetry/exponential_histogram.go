@@ -0,0 +1,276 @@
+package opentelemetry
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	defaultExponentialScale = 3
+	minExponentialScale     = -10
+	maxExponentialScale     = 20
+
+	// Tags of the histogram aggregator output
+	bucketRightTag = "le"
+	bucketLeftTag  = "gt"
+)
+
+// exponentialHistogram collects the data of a single exponential histogram
+// data point before converting it to OTLP.
+type exponentialHistogram struct {
+	name        string
+	tags        map[string]string
+	timestamp   time.Time
+	temporality pmetric.AggregationTemporality
+	cumulative  bool
+
+	// Classic buckets of the histogram aggregator
+	buckets map[float64]uint64
+
+	// Statistics of statsd timings
+	count    uint64
+	sum      float64
+	min      float64
+	max      float64
+	hasStats bool
+}
+
+// convertExponentialHistograms converts the output of the histogram aggregator
+// and statsd timings into exponential histograms with the given scale. All
+// other metrics are returned unmodified.
+func convertExponentialHistograms(metrics []telegraf.Metric, scale int32, sm pmetric.ScopeMetrics) []telegraf.Metric {
+	remaining := make([]telegraf.Metric, 0, len(metrics))
+	var order []string
+	histograms := make(map[string]*exponentialHistogram)
+	add := func(h *exponentialHistogram) *exponentialHistogram {
+		id := histogramID(h)
+		if existing, found := histograms[id]; found {
+			return existing
+		}
+		histograms[id] = h
+		order = append(order, id)
+		return h
+	}
+
+	for _, m := range metrics {
+		switch {
+		case m.HasTag(bucketRightTag):
+			if !addBuckets(m, add) {
+				remaining = append(remaining, m)
+			}
+		case m.Tags()["metric_type"] == "timing":
+			if !addTimings(m, add) {
+				remaining = append(remaining, m)
+			}
+		default:
+			remaining = append(remaining, m)
+		}
+	}
+
+	for _, id := range order {
+		histograms[id].appendTo(sm.Metrics().AppendEmpty(), scale)
+	}
+	return remaining
+}
+
+// addBuckets adds the bucket of a histogram aggregator metric to the
+// corresponding histograms.
+func addBuckets(m telegraf.Metric, add func(*exponentialHistogram) *exponentialHistogram) bool {
+	le, _ := m.GetTag(bucketRightTag)
+	upper, err := strconv.ParseFloat(le, 64)
+	if err != nil {
+		return false
+	}
+	tags := m.Tags()
+	delete(tags, bucketRightTag)
+	delete(tags, bucketLeftTag)
+
+	found := false
+	for _, field := range m.FieldList() {
+		base, ok := strings.CutSuffix(field.Key, "_bucket")
+		if !ok {
+			continue
+		}
+		count, ok := toUint64(field.Value)
+		if !ok {
+			continue
+		}
+		found = true
+
+		h := add(&exponentialHistogram{
+			name:        m.Name() + "_" + base,
+			tags:        tags,
+			timestamp:   m.Time(),
+			temporality: pmetric.AggregationTemporalityCumulative,
+			cumulative:  !m.HasTag(bucketLeftTag),
+			buckets:     make(map[float64]uint64),
+		})
+		h.buckets[upper] += count
+	}
+	return found
+}
+
+// addTimings adds the statistics of statsd timings. As only statistics are
+// available, all observations are accounted to the bucket containing the mean.
+func addTimings(m telegraf.Metric, add func(*exponentialHistogram) *exponentialHistogram) bool {
+	tags := m.Tags()
+	delete(tags, "metric_type")
+
+	fields := m.Fields()
+	found := false
+	for key, value := range fields {
+		prefix, ok := strings.CutSuffix(key, "mean")
+		if !ok || (prefix != "" && !strings.HasSuffix(prefix, "_")) {
+			continue
+		}
+		count, ok := toUint64(fields[prefix+"count"])
+		if !ok {
+			continue
+		}
+		mean, err := internal.ToFloat64(value)
+		if err != nil {
+			continue
+		}
+		found = true
+
+		name := m.Name()
+		if prefix != "" {
+			name += "_" + strings.TrimSuffix(prefix, "_")
+		}
+		h := add(&exponentialHistogram{
+			name:        name,
+			tags:        tags,
+			timestamp:   m.Time(),
+			temporality: pmetric.AggregationTemporalityDelta,
+			buckets:     map[float64]uint64{mean: count},
+			count:       count,
+			hasStats:    true,
+		})
+		h.sum, _ = internal.ToFloat64(fields[prefix+"sum"])
+		h.min, _ = internal.ToFloat64(fields[prefix+"lower"])
+		h.max, _ = internal.ToFloat64(fields[prefix+"upper"])
+	}
+	return found
+}
+
+func (h *exponentialHistogram) appendTo(m pmetric.Metric, scale int32) {
+	m.SetName(h.name)
+	eh := m.SetEmptyExponentialHistogram()
+	eh.SetAggregationTemporality(h.temporality)
+
+	dp := eh.DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(h.timestamp))
+	dp.SetScale(scale)
+	for k, v := range h.tags {
+		dp.Attributes().PutStr(k, v)
+	}
+
+	bounds := make([]float64, 0, len(h.buckets))
+	for bound := range h.buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	var zeroCount, total, previous uint64
+	var lastBound float64
+	positive := make(map[int32]uint64)
+	negative := make(map[int32]uint64)
+	for _, bound := range bounds {
+		count := h.buckets[bound]
+		if h.cumulative {
+			count, previous = count-min(count, previous), count
+		}
+		total += count
+		if count == 0 {
+			continue
+		}
+
+		// Observations above the largest finite bound are accounted to the
+		// bucket of that bound
+		if !math.IsInf(bound, 1) {
+			lastBound = bound
+		}
+		switch {
+		case lastBound > 0:
+			positive[exponentialIndex(lastBound, scale)] += count
+		case lastBound < 0:
+			negative[exponentialIndex(-lastBound, scale)] += count
+		default:
+			zeroCount += count
+		}
+	}
+
+	dp.SetZeroCount(zeroCount)
+	setExponentialBuckets(dp.Positive(), positive)
+	setExponentialBuckets(dp.Negative(), negative)
+	if h.hasStats {
+		dp.SetCount(h.count)
+		dp.SetSum(h.sum)
+		dp.SetMin(h.min)
+		dp.SetMax(h.max)
+	} else {
+		dp.SetCount(total)
+	}
+}
+
+// exponentialIndex returns the index of the bucket containing the given
+// positive value. Bucket i covers the range (base^i, base^(i+1)] with
+// base = 2^(2^-scale).
+func exponentialIndex(v float64, scale int32) int32 {
+	return int32(math.Ceil(math.Log2(v)*math.Exp2(float64(scale)))) - 1
+}
+
+func setExponentialBuckets(b pmetric.ExponentialHistogramDataPointBuckets, buckets map[int32]uint64) {
+	if len(buckets) == 0 {
+		return
+	}
+
+	first, last := int32(math.MaxInt32), int32(math.MinInt32)
+	for idx := range buckets {
+		first = min(first, idx)
+		last = max(last, idx)
+	}
+	counts := make([]uint64, last-first+1)
+	for idx, count := range buckets {
+		counts[idx-first] = count
+	}
+	b.SetOffset(first)
+	b.BucketCounts().FromRaw(counts)
+}
+
+func histogramID(h *exponentialHistogram) string {
+	keys := make([]string, 0, len(h.tags))
+	for k := range h.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var id strings.Builder
+	id.WriteString(h.name)
+	for _, k := range keys {
+		id.WriteString("\x00" + k + "=" + h.tags[k])
+	}
+	id.WriteString("\x00" + strconv.FormatInt(h.timestamp.UnixNano(), 10))
+	return id.String()
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return uint64(v), v >= 0
+	case uint64:
+		return v, true
+	case float64:
+		return uint64(v), v >= 0
+	}
+	return 0, false
+}
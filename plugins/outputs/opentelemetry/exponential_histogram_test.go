@@ -0,0 +1,131 @@
+package opentelemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestExponentialHistogramAggregator(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a", "le": "1"}, map[string]interface{}{"usage_bucket": int64(2)}, ts),
+		testutil.MustMetric("cpu", map[string]string{"host": "a", "le": "2"}, map[string]interface{}{"usage_bucket": int64(5)}, ts),
+		testutil.MustMetric("cpu", map[string]string{"host": "a", "le": "+Inf"}, map[string]interface{}{"usage_bucket": int64(6)}, ts),
+		testutil.MustMetric("mem", map[string]string{"host": "a"}, map[string]interface{}{"used": 1.0}, ts),
+	}
+
+	sm := pmetric.NewScopeMetrics()
+	remaining := convertExponentialHistograms(metrics, 0, sm)
+	require.Len(t, remaining, 1)
+	require.Equal(t, "mem", remaining[0].Name())
+
+	require.Equal(t, 1, sm.Metrics().Len())
+	m := sm.Metrics().At(0)
+	require.Equal(t, "cpu_usage", m.Name())
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, m.Type())
+	require.Equal(t, pmetric.AggregationTemporalityCumulative, m.ExponentialHistogram().AggregationTemporality())
+
+	dp := m.ExponentialHistogram().DataPoints().At(0)
+	require.Equal(t, map[string]interface{}{"host": "a"}, dp.Attributes().AsRaw())
+	require.Equal(t, int32(0), dp.Scale())
+	require.Equal(t, uint64(6), dp.Count())
+	require.False(t, dp.HasSum())
+	require.Equal(t, int32(-1), dp.Positive().Offset())
+	require.Equal(t, []uint64{2, 4}, dp.Positive().BucketCounts().AsRaw())
+	require.Equal(t, 0, dp.Negative().BucketCounts().Len())
+}
+
+func TestExponentialHistogramAggregatorNonCumulative(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"gt": "-Inf", "le": "0"}, map[string]interface{}{"usage_bucket": int64(1)}, ts),
+		testutil.MustMetric("cpu", map[string]string{"gt": "0", "le": "4"}, map[string]interface{}{"usage_bucket": int64(3)}, ts),
+		testutil.MustMetric("cpu", map[string]string{"gt": "4", "le": "+Inf"}, map[string]interface{}{"usage_bucket": int64(0)}, ts),
+	}
+
+	sm := pmetric.NewScopeMetrics()
+	require.Empty(t, convertExponentialHistograms(metrics, 1, sm))
+	require.Equal(t, 1, sm.Metrics().Len())
+
+	dp := sm.Metrics().At(0).ExponentialHistogram().DataPoints().At(0)
+	require.Equal(t, uint64(4), dp.Count())
+	require.Equal(t, uint64(1), dp.ZeroCount())
+	// log2(4) * 2^1 - 1 = 3
+	require.Equal(t, int32(3), dp.Positive().Offset())
+	require.Equal(t, []uint64{3}, dp.Positive().BucketCounts().AsRaw())
+}
+
+func TestExponentialHistogramStatsdTiming(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"request",
+			map[string]string{"metric_type": "timing", "service": "web"},
+			map[string]interface{}{
+				"mean":           3.0,
+				"count":          int64(4),
+				"sum":            12.0,
+				"lower":          1.0,
+				"upper":          5.0,
+				"stddev":         1.5,
+				"90_percentile":  4.5,
+				"latency_mean":   0.5,
+				"latency_count":  int64(2),
+				"latency_sum":    1.0,
+				"latency_lower":  0.25,
+				"latency_upper":  0.75,
+				"latency_median": 0.5,
+			},
+			time.Unix(1700000000, 0),
+		),
+	}
+
+	sm := pmetric.NewScopeMetrics()
+	require.Empty(t, convertExponentialHistograms(metrics, 0, sm))
+	require.Equal(t, 2, sm.Metrics().Len())
+
+	histograms := make(map[string]pmetric.ExponentialHistogramDataPoint)
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		m := sm.Metrics().At(i)
+		require.Equal(t, pmetric.AggregationTemporalityDelta, m.ExponentialHistogram().AggregationTemporality())
+		histograms[m.Name()] = m.ExponentialHistogram().DataPoints().At(0)
+	}
+
+	dp := histograms["request"]
+	require.Equal(t, map[string]interface{}{"service": "web"}, dp.Attributes().AsRaw())
+	require.Equal(t, uint64(4), dp.Count())
+	require.InDelta(t, 12.0, dp.Sum(), 1e-9)
+	require.InDelta(t, 1.0, dp.Min(), 1e-9)
+	require.InDelta(t, 5.0, dp.Max(), 1e-9)
+	require.Equal(t, int32(1), dp.Positive().Offset())
+	require.Equal(t, []uint64{4}, dp.Positive().BucketCounts().AsRaw())
+
+	dp = histograms["request_latency"]
+	require.Equal(t, uint64(2), dp.Count())
+	require.Equal(t, int32(-2), dp.Positive().Offset())
+	require.Equal(t, []uint64{2}, dp.Positive().BucketCounts().AsRaw())
+}
+
+func TestExponentialIndex(t *testing.T) {
+	tests := []struct {
+		value    float64
+		scale    int32
+		expected int32
+	}{
+		{value: 1, scale: 0, expected: -1},
+		{value: 1.5, scale: 0, expected: 0},
+		{value: 2, scale: 0, expected: 0},
+		{value: 2.5, scale: 0, expected: 1},
+		{value: 1.5, scale: 1, expected: 1},
+		{value: 16, scale: -1, expected: 1},
+		{value: 17, scale: -1, expected: 2},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, exponentialIndex(tt.value, tt.scale), "value %v, scale %d", tt.value, tt.scale)
+	}
+}
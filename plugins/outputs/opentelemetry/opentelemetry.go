@@ -5,11 +5,14 @@ import (
 	"context"
 	ntls "crypto/tls"
 	_ "embed"
+	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb-observability/common"
 	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -39,6 +42,10 @@ type OpenTelemetry struct {
 	Attributes  map[string]string `toml:"attributes"`
 	Coralogix   *CoralogixConfig  `toml:"coralogix"`
 
+	ExponentialHistograms bool              `toml:"exponential_histograms"`
+	ExponentialScale      int32             `toml:"exponential_histogram_scale"`
+	ResourceAttributeTags map[string]string `toml:"resource_attribute_tags"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	metricsConverter     *influx2otel.LineProtocolToOtelMetrics
@@ -78,6 +85,10 @@ func (o *OpenTelemetry) Connect() error {
 		o.Headers["Authorization"] = "Bearer " + o.Coralogix.PrivateKey
 	}
 
+	if o.ExponentialScale < minExponentialScale || o.ExponentialScale > maxExponentialScale {
+		return fmt.Errorf("exponential_histogram_scale must be between %d and %d", minExponentialScale, maxExponentialScale)
+	}
+
 	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(logger)
 	if err != nil {
 		return err
@@ -150,6 +161,48 @@ func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
 }
 
 func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
+	md := pmetric.NewMetrics()
+	for _, group := range o.groupByResource(metrics) {
+		converted := o.convert(group.metrics)
+		for i := 0; i < converted.ResourceMetrics().Len(); i++ {
+			attributes := converted.ResourceMetrics().At(i).Resource().Attributes()
+			for k, v := range group.attributes {
+				attributes.PutStr(k, v)
+			}
+		}
+		converted.ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+	}
+	if md.ResourceMetrics().Len() == 0 {
+		return nil
+	}
+
+	if len(o.Attributes) > 0 {
+		for i := 0; i < md.ResourceMetrics().Len(); i++ {
+			for k, v := range o.Attributes {
+				md.ResourceMetrics().At(i).Resource().Attributes().PutStr(k, v)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+
+	if len(o.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
+	}
+	defer cancel()
+	_, err := o.metricsServiceClient.Export(ctx, pmetricotlp.NewExportRequestFromMetrics(md), o.callOptions...)
+	return err
+}
+
+// convert converts the metrics to OpenTelemetry metrics
+func (o *OpenTelemetry) convert(metrics []telegraf.Metric) pmetric.Metrics {
+	var histograms pmetric.Metrics
+	if o.ExponentialHistograms {
+		histograms = pmetric.NewMetrics()
+		sm := histograms.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+		metrics = convertExponentialHistograms(metrics, o.ExponentialScale, sm)
+	}
+
 	batch := o.metricsConverter.NewBatch()
 	for _, metric := range metrics {
 		var vType common.InfluxMetricValueType
@@ -175,27 +228,60 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 		}
 	}
 
-	md := pmetricotlp.NewExportRequestFromMetrics(batch.GetMetrics())
-	if md.Metrics().ResourceMetrics().Len() == 0 {
-		return nil
+	md := batch.GetMetrics()
+	if o.ExponentialHistograms && histograms.MetricCount() > 0 {
+		histograms.ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
 	}
+	return md
+}
 
-	if len(o.Attributes) > 0 {
-		for i := 0; i < md.Metrics().ResourceMetrics().Len(); i++ {
-			for k, v := range o.Attributes {
-				md.Metrics().ResourceMetrics().At(i).Resource().Attributes().PutStr(k, v)
-			}
-		}
+type resourceGroup struct {
+	attributes map[string]string
+	metrics    []telegraf.Metric
+}
+
+// groupByResource groups the metrics by the values of the tags configured as
+// resource attributes and removes those tags from the metrics.
+func (o *OpenTelemetry) groupByResource(metrics []telegraf.Metric) []*resourceGroup {
+	if len(o.ResourceAttributeTags) == 0 {
+		return []*resourceGroup{{metrics: metrics}}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	keys := make([]string, 0, len(o.ResourceAttributeTags))
+	for tag := range o.ResourceAttributeTags {
+		keys = append(keys, tag)
+	}
+	sort.Strings(keys)
+
+	var groups []*resourceGroup
+	index := make(map[string]*resourceGroup)
+	for _, m := range metrics {
+		var id strings.Builder
+		attributes := make(map[string]string)
+		for _, tag := range keys {
+			value, found := m.GetTag(tag)
+			if !found {
+				continue
+			}
+			attributes[o.ResourceAttributeTags[tag]] = value
+			id.WriteString(tag + "=" + value + "\x00")
+		}
+		if len(attributes) > 0 {
+			m = m.Copy()
+			for _, tag := range keys {
+				m.RemoveTag(tag)
+			}
+		}
 
-	if len(o.Headers) > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
+		g, found := index[id.String()]
+		if !found {
+			g = &resourceGroup{attributes: attributes}
+			index[id.String()] = g
+			groups = append(groups, g)
+		}
+		g.metrics = append(g.metrics, m)
 	}
-	defer cancel()
-	_, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...)
-	return err
+	return groups
 }
 
 const (
@@ -210,6 +296,8 @@ func init() {
 			ServiceAddress: defaultServiceAddress,
 			Timeout:        defaultTimeout,
 			Compression:    defaultCompression,
+
+			ExponentialScale: defaultExponentialScale,
 		}
 	})
 }
@@ -138,3 +138,33 @@ func (m *mockOtelService) Export(ctx context.Context, request pmetricotlp.Export
 	require.True(m.t, ok)
 	return pmetricotlp.NewExportResponse(), nil
 }
+
+func TestGroupByResource(t *testing.T) {
+	plugin := &OpenTelemetry{
+		ResourceAttributeTags: map[string]string{"host": "host.name"},
+	}
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "b", "cpu": "0"}, map[string]interface{}{"usage": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", map[string]string{"host": "a"}, map[string]interface{}{"used": 3.0}, time.Unix(0, 0)),
+		testutil.MustMetric("disk", map[string]string{}, map[string]interface{}{"free": 4.0}, time.Unix(0, 0)),
+	}
+
+	groups := plugin.groupByResource(metrics)
+	require.Len(t, groups, 3)
+
+	require.Equal(t, map[string]string{"host.name": "a"}, groups[0].attributes)
+	require.Len(t, groups[0].metrics, 2)
+	require.Equal(t, map[string]string{"cpu": "0"}, groups[0].metrics[0].Tags())
+	require.Empty(t, groups[0].metrics[1].Tags())
+
+	require.Equal(t, map[string]string{"host.name": "b"}, groups[1].attributes)
+	require.Len(t, groups[1].metrics, 1)
+
+	require.Empty(t, groups[2].attributes)
+	require.Len(t, groups[2].metrics, 1)
+
+	// The original metrics must not be modified
+	require.Equal(t, map[string]string{"host": "a", "cpu": "0"}, metrics[0].Tags())
+}
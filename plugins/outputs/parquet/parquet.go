@@ -0,0 +1,412 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package parquet
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/apache/arrow/go/v13/parquet"
+	"github.com/apache/arrow/go/v13/parquet/compress"
+	"github.com/apache/arrow/go/v13/parquet/pqarrow"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Characters not allowed in partition path elements
+var pathReplacer = strings.NewReplacer("/", "_", "\\", "_", "=", "_", "..", "_")
+
+var codecs = map[string]compress.Compression{
+	"none":   compress.Codecs.Uncompressed,
+	"snappy": compress.Codecs.Snappy,
+	"gzip":   compress.Codecs.Gzip,
+	"brotli": compress.Codecs.Brotli,
+	"zstd":   compress.Codecs.Zstd,
+	"lz4":    compress.Codecs.Lz4Raw,
+}
+
+type Parquet struct {
+	Directory        string          `toml:"directory"`
+	TimePartition    string          `toml:"time_partition"`
+	PartitionTags    []string        `toml:"partition_tags"`
+	TimestampColumn  string          `toml:"timestamp_column"`
+	Compression      string          `toml:"compression"`
+	RotationInterval config.Duration `toml:"rotation_interval"`
+	RotationMaxRows  int64           `toml:"rotation_max_rows"`
+	Log              telegraf.Logger `toml:"-"`
+
+	props *parquet.WriterProperties
+	files map[string]*file
+	seq   uint64
+}
+
+// file is a parquet file currently written to. The data is written to a
+// temporary file which is renamed on close, as the file is only readable once
+// the footer is written.
+type file struct {
+	path    string
+	schema  *arrow.Schema
+	handle  *os.File
+	writer  *pqarrow.FileWriter
+	created time.Time
+	rows    int64
+}
+
+func (*Parquet) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Parquet) Init() error {
+	if p.Directory == "" {
+		return errors.New("directory is required")
+	}
+	if p.TimestampColumn == "" {
+		p.TimestampColumn = "time"
+	}
+
+	if p.Compression == "" {
+		p.Compression = "snappy"
+	}
+	codec, found := codecs[p.Compression]
+	if !found {
+		return fmt.Errorf("invalid compression %q", p.Compression)
+	}
+	p.props = parquet.NewWriterProperties(parquet.WithCompression(codec))
+
+	if p.RotationMaxRows < 0 {
+		return errors.New("rotation_max_rows must not be negative")
+	}
+
+	return nil
+}
+
+func (p *Parquet) Connect() error {
+	if err := os.MkdirAll(p.Directory, 0750); err != nil {
+		return fmt.Errorf("creating directory failed: %w", err)
+	}
+	p.files = make(map[string]*file)
+	return nil
+}
+
+func (p *Parquet) Close() error {
+	var errs []error
+	for dir, f := range p.files {
+		if err := f.close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(p.files, dir)
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Parquet) Write(metrics []telegraf.Metric) error {
+	// Group the metrics by the partition directory, each directory has its
+	// own file with a schema derived from the metrics.
+	var dirs []string
+	groups := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		dir := p.partition(m)
+		if _, found := groups[dir]; !found {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], m)
+	}
+
+	for _, dir := range dirs {
+		if err := p.write(dir, groups[dir]); err != nil {
+			return err
+		}
+	}
+
+	// Rotate files after writing to avoid keeping unreadable files around
+	// until the next write
+	if p.RotationInterval > 0 {
+		for dir, f := range p.files {
+			if time.Since(f.created) < time.Duration(p.RotationInterval) {
+				continue
+			}
+			delete(p.files, dir)
+			if err := f.close(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Parquet) write(dir string, metrics []telegraf.Metric) error {
+	schema := p.schema(metrics)
+
+	f := p.files[dir]
+	if f != nil && !compatible(f.schema, schema) {
+		// Start a new file containing the columns of both schemas
+		schema = merge(f.schema, schema)
+		delete(p.files, dir)
+		if err := f.close(); err != nil {
+			return err
+		}
+		f = nil
+	}
+	if f == nil {
+		var err error
+		if f, err = p.open(dir, schema); err != nil {
+			return err
+		}
+		p.files[dir] = f
+	} else {
+		schema = f.schema
+	}
+
+	record := p.record(schema, metrics)
+	defer record.Release()
+	if err := f.writer.Write(record); err != nil {
+		return fmt.Errorf("writing to %q failed: %w", f.path, err)
+	}
+	f.rows += record.NumRows()
+
+	if p.RotationMaxRows > 0 && f.rows >= p.RotationMaxRows {
+		delete(p.files, dir)
+		return f.close()
+	}
+	return nil
+}
+
+func (p *Parquet) open(dir string, schema *arrow.Schema) (*file, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("creating directory failed: %w", err)
+	}
+
+	now := time.Now()
+	p.seq++
+	path := filepath.Join(dir, fmt.Sprintf("part-%d-%d.parquet", now.Unix(), p.seq))
+	handle, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("creating file failed: %w", err)
+	}
+
+	writer, err := pqarrow.NewFileWriter(schema, handle, p.props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		handle.Close()
+		return nil, fmt.Errorf("creating writer for %q failed: %w", path, err)
+	}
+
+	return &file{
+		path:    path,
+		schema:  schema,
+		handle:  handle,
+		writer:  writer,
+		created: now,
+	}, nil
+}
+
+func (f *file) close() error {
+	if err := f.writer.Close(); err != nil {
+		return fmt.Errorf("closing %q failed: %w", f.path, err)
+	}
+	// The writer might close the file already
+	if err := f.handle.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return fmt.Errorf("closing %q failed: %w", f.path, err)
+	}
+	if err := os.Rename(f.path+".tmp", f.path); err != nil {
+		return fmt.Errorf("renaming %q failed: %w", f.path, err)
+	}
+	return nil
+}
+
+// partition returns the directory of the metric consisting of the metric name
+// followed by the time and tag partitions in hive-style, e.g.
+// "cpu/dt=2024-01-01/host=a".
+func (p *Parquet) partition(m telegraf.Metric) string {
+	elements := []string{p.Directory, sanitize(m.Name())}
+	if p.TimePartition != "" {
+		elements = append(elements, m.Time().UTC().Format(p.TimePartition))
+	}
+	for _, key := range p.PartitionTags {
+		value, found := m.GetTag(key)
+		if !found {
+			value = "__null__"
+		}
+		elements = append(elements, sanitize(key)+"="+sanitize(value))
+	}
+	return filepath.Join(elements...)
+}
+
+// schema derives the schema of the given metrics with the timestamp as first
+// column followed by the tags and fields in alphabetical order. Columns of
+// partition tags are omitted as they are encoded in the path.
+func (p *Parquet) schema(metrics []telegraf.Metric) *arrow.Schema {
+	tags := make(map[string]bool)
+	fields := make(map[string]arrow.DataType)
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			tags[tag.Key] = true
+		}
+		for _, field := range m.FieldList() {
+			datatype := arrowType(field.Value)
+			if datatype == nil {
+				continue
+			}
+			if existing, found := fields[field.Key]; found && !arrow.TypeEqual(existing, datatype) {
+				p.Log.Debugf("Dropping field %q of metric %q due to conflicting type", field.Key, m.Name())
+				continue
+			}
+			fields[field.Key] = datatype
+		}
+	}
+	for _, key := range p.PartitionTags {
+		delete(tags, key)
+	}
+	if tags[p.TimestampColumn] {
+		p.Log.Debugf("Dropping tag %q colliding with the timestamp column", p.TimestampColumn)
+		delete(tags, p.TimestampColumn)
+	}
+
+	columns := []arrow.Field{{Name: p.TimestampColumn, Type: arrow.FixedWidthTypes.Timestamp_ns}}
+	for _, key := range sortedKeys(tags) {
+		columns = append(columns, arrow.Field{Name: key, Type: arrow.BinaryTypes.String, Nullable: true})
+	}
+	for _, key := range sortedKeys(fields) {
+		if tags[key] || key == p.TimestampColumn {
+			p.Log.Debugf("Dropping field %q colliding with a tag or the timestamp column", key)
+			continue
+		}
+		columns = append(columns, arrow.Field{Name: key, Type: fields[key], Nullable: true})
+	}
+	return arrow.NewSchema(columns, nil)
+}
+
+func (p *Parquet) record(schema *arrow.Schema, metrics []telegraf.Metric) arrow.Record {
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+
+	for _, m := range metrics {
+		for i, column := range schema.Fields() {
+			b := builder.Field(i)
+			if i == 0 {
+				b.(*array.TimestampBuilder).Append(arrow.Timestamp(m.Time().UnixNano()))
+				continue
+			}
+
+			var value interface{}
+			if column.Type.ID() == arrow.STRING {
+				if v, found := m.GetTag(column.Name); found {
+					value = v
+				}
+			}
+			if value == nil {
+				value, _ = m.GetField(column.Name)
+			}
+			appendValue(b, value)
+		}
+	}
+	return builder.NewRecord()
+}
+
+func appendValue(b array.Builder, value interface{}) {
+	switch b := b.(type) {
+	case *array.Float64Builder:
+		if v, ok := value.(float64); ok {
+			b.Append(v)
+			return
+		}
+	case *array.Int64Builder:
+		if v, ok := value.(int64); ok {
+			b.Append(v)
+			return
+		}
+	case *array.Uint64Builder:
+		if v, ok := value.(uint64); ok {
+			b.Append(v)
+			return
+		}
+	case *array.BooleanBuilder:
+		if v, ok := value.(bool); ok {
+			b.Append(v)
+			return
+		}
+	case *array.StringBuilder:
+		if v, ok := value.(string); ok {
+			b.Append(v)
+			return
+		}
+	}
+	b.AppendNull()
+}
+
+func arrowType(value interface{}) arrow.DataType {
+	switch value.(type) {
+	case float64:
+		return arrow.PrimitiveTypes.Float64
+	case int64:
+		return arrow.PrimitiveTypes.Int64
+	case uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case bool:
+		return arrow.FixedWidthTypes.Boolean
+	case string:
+		return arrow.BinaryTypes.String
+	}
+	return nil
+}
+
+// compatible returns true if all columns of the given schema are contained in
+// the existing schema. Values conflicting with the type of an existing column
+// are written as null, so a new file is only required for new columns.
+func compatible(existing, schema *arrow.Schema) bool {
+	for _, column := range schema.Fields() {
+		if !existing.HasField(column.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// merge returns the union of both schemas keeping the existing columns first.
+func merge(existing, schema *arrow.Schema) *arrow.Schema {
+	columns := append([]arrow.Field{}, existing.Fields()...)
+	for _, column := range schema.Fields() {
+		if !existing.HasField(column.Name) {
+			columns = append(columns, column)
+		}
+	}
+	return arrow.NewSchema(columns, nil)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sanitize replaces characters not allowed in path elements
+func sanitize(s string) string {
+	return pathReplacer.Replace(s)
+}
+
+func init() {
+	outputs.Add("parquet", func() telegraf.Output {
+		return &Parquet{
+			TimePartition:    "dt=2006-01-02",
+			TimestampColumn:  "time",
+			Compression:      "snappy",
+			RotationInterval: config.Duration(time.Hour),
+		}
+	})
+}
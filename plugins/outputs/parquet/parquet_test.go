@@ -0,0 +1,180 @@
+package parquet
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/apache/arrow/go/v13/parquet"
+	"github.com/apache/arrow/go/v13/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func readTable(t *testing.T, path string) arrow.Table {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	table, err := pqarrow.ReadTable(
+		context.Background(),
+		f,
+		parquet.NewReaderProperties(memory.DefaultAllocator),
+		pqarrow.ArrowReadProperties{},
+		memory.DefaultAllocator,
+	)
+	require.NoError(t, err)
+	t.Cleanup(table.Release)
+	return table
+}
+
+func columnNames(table arrow.Table) []string {
+	names := make([]string, 0, table.NumCols())
+	for _, field := range table.Schema().Fields() {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	plugin := &Parquet{
+		Directory:     dir,
+		TimePartition: "dt=2006-01-02",
+		PartitionTags: []string{"region"},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a", "region": "eu"},
+			map[string]interface{}{"usage": 42.5, "cores": int64(4)},
+			time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"region": "eu"},
+			map[string]interface{}{"usage": 1.0, "online": true},
+			time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "b", "region": "us"},
+			map[string]interface{}{"usage": 2.0},
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Files must not be visible before closing
+	files, err := filepath.Glob(filepath.Join(dir, "cpu", "*", "*", "*.parquet"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	require.NoError(t, plugin.Close())
+
+	files, err = filepath.Glob(filepath.Join(dir, "cpu", "dt=2024-01-01", "region=eu", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	table := readTable(t, files[0])
+	require.Equal(t, []string{"time", "host", "cores", "online", "usage"}, columnNames(table))
+	require.EqualValues(t, 2, table.NumRows())
+
+	host := table.Column(1).Data().Chunk(0).(*array.String)
+	require.Equal(t, "a", host.Value(0))
+	require.True(t, host.IsNull(1))
+	usage := table.Column(4).Data().Chunk(0).(*array.Float64)
+	require.Equal(t, []float64{42.5, 1.0}, usage.Float64Values())
+	ts := table.Column(0).Data().Chunk(0).(*array.Timestamp)
+	require.Equal(t, arrow.Timestamp(metrics[0].Time().UnixNano()), ts.Value(0))
+
+	files, err = filepath.Glob(filepath.Join(dir, "cpu", "dt=2024-01-02", "region=us", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.EqualValues(t, 1, readTable(t, files[0]).NumRows())
+}
+
+func TestWriteSchemaChange(t *testing.T) {
+	dir := t.TempDir()
+	plugin := &Parquet{
+		Directory: dir,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	m1 := testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"used": int64(1)}, time.Unix(0, 0))
+	m2 := testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"used": "invalid"}, time.Unix(1, 0))
+	m3 := testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"free": int64(2)}, time.Unix(2, 0))
+
+	// Conflicting types are written to the existing file
+	require.NoError(t, plugin.Write([]telegraf.Metric{m1}))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m2}))
+	// New columns start a new file with the merged schema
+	require.NoError(t, plugin.Write([]telegraf.Metric{m3}))
+	require.NoError(t, plugin.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "mem", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	var rows int64
+	for _, fn := range files {
+		table := readTable(t, fn)
+		rows += table.NumRows()
+		if table.NumRows() == 2 {
+			require.Equal(t, []string{"time", "used"}, columnNames(table))
+			used := table.Column(1).Data().Chunk(0).(*array.Int64)
+			require.Equal(t, int64(1), used.Value(0))
+			require.True(t, used.IsNull(1))
+		} else {
+			require.Equal(t, []string{"time", "used", "free"}, columnNames(table))
+		}
+	}
+	require.EqualValues(t, 3, rows)
+}
+
+func TestRotationMaxRows(t *testing.T) {
+	dir := t.TempDir()
+	plugin := &Parquet{
+		Directory:       dir,
+		Compression:     "zstd",
+		RotationMaxRows: 2,
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m, m}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "cpu", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.NoError(t, plugin.Close())
+
+	files, err = filepath.Glob(filepath.Join(dir, "cpu", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestInitInvalidCompression(t *testing.T) {
+	plugin := &Parquet{
+		Directory:   t.TempDir(),
+		Compression: "lzo",
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid compression")
+}
@@ -6,6 +6,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -43,6 +44,8 @@ type Postgresql struct {
 	FieldsAsJsonb              bool                    `toml:"fields_as_jsonb"`
 	TimestampColumnName        string                  `toml:"timestamp_column_name"`
 	TimestampColumnType        string                  `toml:"timestamp_column_type"`
+	Hypertables                bool                    `toml:"timescaledb_hypertables"`
+	ChunkInterval              config.Duration         `toml:"timescaledb_chunk_interval"`
 	CreateTemplates            []*sqltemplate.Template `toml:"create_templates"`
 	AddColumnTemplates         []*sqltemplate.Template `toml:"add_column_templates"`
 	TagTableCreateTemplates    []*sqltemplate.Template `toml:"tag_table_create_templates"`
@@ -87,6 +90,7 @@ func newPostgresql() *Postgresql {
 		TagTableCreateTemplates:    []*sqltemplate.Template{{}},
 		TagTableAddColumnTemplates: []*sqltemplate.Template{{}},
 		RetryMaxBackoff:            config.Duration(time.Second * 15),
+		ChunkInterval:              config.Duration(7 * 24 * time.Hour),
 		Logger:                     models.NewLogger("outputs", "postgresql", ""),
 		LogLevel:                   "warn",
 	}
@@ -120,6 +124,23 @@ func (p *Postgresql) Init() error {
 		return fmt.Errorf("unknown timestamp column type %q", p.TimestampColumnType)
 	}
 
+	// Turn new metric tables into TimescaleDB hypertables partitioned by time
+	if p.Hypertables {
+		if p.ChunkInterval < config.Duration(time.Second) {
+			return errors.New("timescaledb_chunk_interval must be at least one second")
+		}
+		tmpl := &sqltemplate.Template{}
+		stmt := fmt.Sprintf(
+			`SELECT create_hypertable({{ .table|quoteLiteral }}, {{ %s|quoteLiteral }}, chunk_time_interval => INTERVAL '%d seconds', if_not_exists => TRUE)`,
+			strconv.Quote(p.TimestampColumnName),
+			int64(time.Duration(p.ChunkInterval).Seconds()),
+		)
+		if err := tmpl.UnmarshalText([]byte(stmt)); err != nil {
+			return fmt.Errorf("creating hypertable template failed: %w", err)
+		}
+		p.CreateTemplates = append(p.CreateTemplates, tmpl)
+	}
+
 	// Initialize the column prototypes
 	p.timeColumn = utils.Column{
 		Name: p.TimestampColumnName,
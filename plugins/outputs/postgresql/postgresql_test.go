@@ -17,7 +17,9 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
 	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -829,3 +831,27 @@ func TestStressConcurrencyIntegration(t *testing.T) {
 		}
 	}
 }
+
+func TestHypertableTemplate(t *testing.T) {
+	p := newPostgresql()
+	p.Hypertables = true
+	p.ChunkInterval = config.Duration(24 * time.Hour)
+	p.TimestampColumnName = "ts"
+	require.NoError(t, p.Init())
+	require.Len(t, p.CreateTemplates, 2)
+
+	tbl := sqltemplate.NewTable("public", "cpu", nil)
+	stmt, err := p.CreateTemplates[1].Render(tbl, nil, tbl, nil)
+	require.NoError(t, err)
+	require.Equal(t,
+		`SELECT create_hypertable('"public"."cpu"', 'ts', chunk_time_interval => INTERVAL '86400 seconds', if_not_exists => TRUE)`,
+		string(stmt),
+	)
+}
+
+func TestHypertableInvalidChunkInterval(t *testing.T) {
+	p := newPostgresql()
+	p.Hypertables = true
+	p.ChunkInterval = 0
+	require.ErrorContains(t, p.Init(), "timescaledb_chunk_interval")
+}
@@ -0,0 +1,614 @@
+package prometheus_client
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+const (
+	defaultNativeHistogramSchema = 8
+	minNativeHistogramSchema     = -4
+	maxNativeHistogramSchema     = 8
+
+	// Fields of metrics holding an exponential histogram
+	exponentialScaleField         = "scale"
+	exponentialCountField         = "count"
+	exponentialSumField           = "sum"
+	exponentialZeroCountField     = "zero_count"
+	exponentialZeroThresholdField = "zero_threshold"
+	exponentialPositivePrefix     = "positive_"
+	exponentialNegativePrefix     = "negative_"
+
+	// Tags and fields of classic histograms produced by the histogram
+	// aggregator and the prometheus input
+	bucketRightTag    = "le"
+	bucketLeftTag     = "gt"
+	bucketSuffix      = "_bucket"
+	classicSumField   = "sum"
+	classicCountField = "count"
+)
+
+// nativeHistogramCollector wraps a collector and exports histogram metrics
+// holding an exponential histogram as Prometheus native histograms. Classic
+// histograms are exported as native histograms in addition to their classic
+// buckets if the buckets match the buckets of a native histogram, otherwise
+// only the classic buckets are exported. All other metrics are passed to the
+// wrapped collector.
+type nativeHistogramCollector struct {
+	Collector
+	maxSchema       int32
+	expire          time.Duration
+	exportTimestamp bool
+	log             telegraf.Logger
+
+	sync.Mutex
+	histograms map[uint64]*nativeHistogram
+	classic    map[string]*classicHistogram
+}
+
+type nativeHistogram struct {
+	name      string
+	labels    []*dto.LabelPair
+	histogram *dto.Histogram
+	timestamp time.Time
+	updated   time.Time
+}
+
+func newNativeHistogramCollector(
+	coll Collector,
+	maxSchema int32,
+	expire time.Duration,
+	exportTimestamp bool,
+	log telegraf.Logger,
+) *nativeHistogramCollector {
+	return &nativeHistogramCollector{
+		Collector:       coll,
+		maxSchema:       maxSchema,
+		expire:          expire,
+		exportTimestamp: exportTimestamp,
+		log:             log,
+		histograms:      make(map[uint64]*nativeHistogram),
+		classic:         make(map[string]*classicHistogram),
+	}
+}
+
+func (c *nativeHistogramCollector) Add(metrics []telegraf.Metric) error {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	remaining := make([]telegraf.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		if _, found := m.GetField(exponentialScaleField); !found || m.Type() != telegraf.Histogram {
+			if !c.addClassic(m, now) {
+				remaining = append(remaining, m)
+			}
+			continue
+		}
+
+		h, err := exponentialToNative(m, c.maxSchema)
+		if err != nil {
+			c.log.Errorf("Converting exponential histogram %q failed: %v", m.Name(), err)
+			continue
+		}
+		name, ok := serializer.SanitizeMetricName(m.Name())
+		if !ok {
+			continue
+		}
+		c.histograms[m.HashID()] = &nativeHistogram{
+			name:      name,
+			labels:    nativeLabels(m),
+			histogram: h,
+			timestamp: m.Time(),
+			updated:   now,
+		}
+	}
+	c.expireHistograms(now)
+
+	return c.Collector.Add(remaining)
+}
+
+func (c *nativeHistogramCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Collector.Collect(ch)
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.expireHistograms(time.Now())
+	for _, h := range c.histograms {
+		m := &nativeHistogramMetric{
+			name:      h.name,
+			labels:    h.labels,
+			histogram: h.histogram,
+		}
+		if c.exportTimestamp {
+			m.timestamp = proto.Int64(h.timestamp.UnixMilli())
+		}
+		ch <- m
+	}
+	for _, h := range c.classic {
+		m := &nativeHistogramMetric{
+			name:      h.name,
+			labels:    h.labels,
+			histogram: h.histogram(c.maxSchema),
+		}
+		if c.exportTimestamp {
+			m.timestamp = proto.Int64(h.timestamp.UnixMilli())
+		}
+		ch <- m
+	}
+}
+
+func (c *nativeHistogramCollector) expireHistograms(now time.Time) {
+	if c.expire == 0 {
+		return
+	}
+	for id, h := range c.histograms {
+		if now.Sub(h.updated) > c.expire {
+			delete(c.histograms, id)
+		}
+	}
+	for id, h := range c.classic {
+		if now.Sub(h.updated) > c.expire {
+			delete(c.classic, id)
+		}
+	}
+}
+
+// addClassic adds the buckets, count and sum of classic histogram metrics
+// to the corresponding histograms. Supported are metrics with a bucket bound
+// tag and "<name>_bucket" fields as produced by the histogram aggregator and
+// the prometheus input (metric version 2) as well as histograms with fields
+// named by the bucket bounds as produced by the prometheus input (metric
+// version 1). Returns false if the metric is not part of a classic histogram.
+func (c *nativeHistogramCollector) addClassic(m telegraf.Metric, now time.Time) bool {
+	if le, found := m.GetTag(bucketRightTag); found {
+		bound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			return false
+		}
+		_, hasLeft := m.GetTag(bucketLeftTag)
+
+		var added bool
+		for _, field := range m.FieldList() {
+			name, ok := strings.CutSuffix(field.Key, bucketSuffix)
+			if !ok {
+				continue
+			}
+			count, ok := serializer.SampleCount(field.Value)
+			if !ok {
+				continue
+			}
+			added = true
+			if h := c.classicHistogram(m, serializer.MetricName(m.Name(), name, telegraf.Histogram), now); h != nil {
+				h.buckets[bound] = count
+				h.cumulative = !hasLeft
+			}
+		}
+		return added
+	}
+	if m.Type() != telegraf.Histogram {
+		return false
+	}
+
+	// Histograms with fields named by the bucket bounds
+	if _, found := m.GetField(classicCountField); found {
+		buckets := make(map[float64]uint64, len(m.FieldList()))
+		var count uint64
+		var sum float64
+		for _, field := range m.FieldList() {
+			var ok bool
+			switch field.Key {
+			case classicCountField:
+				count, ok = serializer.SampleCount(field.Value)
+			case classicSumField:
+				sum, ok = serializer.SampleSum(field.Value)
+			default:
+				bound, err := strconv.ParseFloat(field.Key, 64)
+				if err != nil {
+					return false
+				}
+				buckets[bound], ok = serializer.SampleCount(field.Value)
+			}
+			if !ok {
+				return false
+			}
+		}
+		if h := c.classicHistogram(m, m.Name(), now); h != nil {
+			h.buckets = buckets
+			h.count = &count
+			h.sum = &sum
+		}
+		return true
+	}
+
+	// Count and sum of histograms with the buckets in separate metrics
+	var added bool
+	for _, field := range m.FieldList() {
+		var h *classicHistogram
+		switch {
+		case strings.HasSuffix(field.Key, "_"+classicCountField):
+			count, ok := serializer.SampleCount(field.Value)
+			if !ok {
+				continue
+			}
+			if h = c.classicHistogram(m, serializer.MetricName(m.Name(), field.Key, telegraf.Histogram), now); h != nil {
+				h.count = &count
+			}
+		case strings.HasSuffix(field.Key, "_"+classicSumField):
+			sum, ok := serializer.SampleSum(field.Value)
+			if !ok {
+				continue
+			}
+			if h = c.classicHistogram(m, serializer.MetricName(m.Name(), field.Key, telegraf.Histogram), now); h != nil {
+				h.sum = &sum
+			}
+		default:
+			continue
+		}
+		added = true
+	}
+	return added
+}
+
+// classicHistogram returns the classic histogram with the given name and the
+// tags of the metric, replacing histograms older than the metric. Returns nil
+// for metrics older than the histogram or with an invalid name.
+func (c *nativeHistogramCollector) classicHistogram(m telegraf.Metric, name string, now time.Time) *classicHistogram {
+	name, ok := serializer.SanitizeMetricName(name)
+	if !ok {
+		return nil
+	}
+	labels := nativeLabels(m, bucketRightTag, bucketLeftTag)
+
+	var id strings.Builder
+	id.WriteString(name)
+	for _, label := range labels {
+		id.WriteString("\x00" + label.GetName() + "=" + label.GetValue())
+	}
+
+	h, found := c.classic[id.String()]
+	switch {
+	case found && m.Time().Before(h.timestamp):
+		return nil
+	case !found || m.Time().After(h.timestamp):
+		h = &classicHistogram{
+			name:       name,
+			labels:     labels,
+			buckets:    make(map[float64]uint64),
+			cumulative: true,
+			timestamp:  m.Time(),
+		}
+		c.classic[id.String()] = h
+	}
+	h.updated = now
+	return h
+}
+
+type nativeHistogramMetric struct {
+	name      string
+	labels    []*dto.LabelPair
+	histogram *dto.Histogram
+	timestamp *int64
+}
+
+func (m *nativeHistogramMetric) Desc() *prometheus.Desc {
+	labelNames := make([]string, 0, len(m.labels))
+	for _, label := range m.labels {
+		labelNames = append(labelNames, label.GetName())
+	}
+	return prometheus.NewDesc(m.name, "Telegraf collected metric", labelNames, nil)
+}
+
+func (m *nativeHistogramMetric) Write(out *dto.Metric) error {
+	out.Label = m.labels
+	out.Histogram = m.histogram
+	out.TimestampMs = m.timestamp
+	return nil
+}
+
+// nativeLabels returns the sorted labels of the given metric without the
+// excluded tags
+func nativeLabels(m telegraf.Metric, exclude ...string) []*dto.LabelPair {
+	labels := make([]*dto.LabelPair, 0, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		if slices.Contains(exclude, tag.Key) {
+			continue
+		}
+		name, ok := serializer.SanitizeLabelName(tag.Key)
+		if !ok {
+			continue
+		}
+		labels = append(labels, &dto.LabelPair{Name: proto.String(name), Value: proto.String(tag.Value)})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+	return labels
+}
+
+// exponentialToNative converts the exponential histogram of the given metric
+// to a native histogram. The metric has to contain the scale, count, sum and
+// zero count of the histogram as well as a "positive_<index>" and
+// "negative_<index>" field for each non-empty bucket using the bucket indices
+// of OpenTelemetry, i.e. bucket i covers the range (base^i, base^(i+1)]. The
+// scale corresponds to the schema of native histograms. Histograms with a
+// scale above the given maximum are downscaled by merging adjacent buckets.
+func exponentialToNative(m telegraf.Metric, maxSchema int32) (*dto.Histogram, error) {
+	v, _ := m.GetField(exponentialScaleField)
+	scale, ok := serializer.SampleSum(v)
+	if !ok || scale != math.Trunc(scale) {
+		return nil, fmt.Errorf("field %q is not an integer", exponentialScaleField)
+	}
+	if scale < minNativeHistogramSchema || scale > math.MaxInt32 {
+		return nil, fmt.Errorf("scale %v not supported by native histograms", scale)
+	}
+	schema := int32(scale)
+	var shift uint
+	if schema > maxSchema {
+		shift = uint(schema - maxSchema)
+		schema = maxSchema
+	}
+
+	h := &dto.Histogram{
+		Schema:    proto.Int32(schema),
+		ZeroCount: proto.Uint64(0),
+		// OpenTelemetry defaults to a zero threshold of zero
+		ZeroThreshold: proto.Float64(0),
+	}
+	var hasCount, hasSum bool
+	positive := make(map[int32]int64)
+	negative := make(map[int32]int64)
+	for _, field := range m.FieldList() {
+		var err error
+		switch {
+		case field.Key == exponentialScaleField:
+		case field.Key == exponentialCountField:
+			count, ok := serializer.SampleCount(field.Value)
+			if !ok {
+				return nil, fmt.Errorf("invalid value of field %q", field.Key)
+			}
+			h.SampleCount = proto.Uint64(count)
+			hasCount = true
+		case field.Key == exponentialSumField:
+			sum, ok := serializer.SampleSum(field.Value)
+			if !ok {
+				return nil, fmt.Errorf("invalid value of field %q", field.Key)
+			}
+			h.SampleSum = proto.Float64(sum)
+			hasSum = true
+		case field.Key == exponentialZeroCountField:
+			count, ok := serializer.SampleCount(field.Value)
+			if !ok {
+				return nil, fmt.Errorf("invalid value of field %q", field.Key)
+			}
+			h.ZeroCount = proto.Uint64(count)
+		case field.Key == exponentialZeroThresholdField:
+			threshold, ok := serializer.SampleValue(field.Value)
+			if !ok || threshold < 0 {
+				return nil, fmt.Errorf("invalid value of field %q", field.Key)
+			}
+			h.ZeroThreshold = proto.Float64(threshold)
+		case strings.HasPrefix(field.Key, exponentialPositivePrefix):
+			err = addExponentialBucket(positive, field, exponentialPositivePrefix, shift)
+		case strings.HasPrefix(field.Key, exponentialNegativePrefix):
+			err = addExponentialBucket(negative, field, exponentialNegativePrefix, shift)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !hasCount || !hasSum {
+		return nil, errors.New("count or sum missing")
+	}
+
+	h.PositiveSpan, h.PositiveDelta = nativeSpans(positive)
+	h.NegativeSpan, h.NegativeDelta = nativeSpans(negative)
+
+	// Mark the histogram as native even without any observation
+	if len(h.PositiveSpan) == 0 && len(h.NegativeSpan) == 0 && h.GetZeroCount() == 0 {
+		h.PositiveSpan = []*dto.BucketSpan{{
+			Offset: proto.Int32(0),
+			Length: proto.Uint32(0),
+		}}
+	}
+
+	return h, nil
+}
+
+// addExponentialBucket adds the count of the given bucket field to the native
+// buckets. Native bucket i covers the range (base^(i-1), base^i], so the
+// OpenTelemetry index is shifted by one after downscaling.
+func addExponentialBucket(buckets map[int32]int64, field *telegraf.Field, prefix string, shift uint) error {
+	index, err := strconv.ParseInt(strings.TrimPrefix(field.Key, prefix), 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid bucket index of field %q", field.Key)
+	}
+	count, ok := serializer.SampleCount(field.Value)
+	if !ok {
+		return fmt.Errorf("invalid value of field %q", field.Key)
+	}
+	if count > 0 {
+		buckets[int32(index>>shift)+1] += int64(count)
+	}
+	return nil
+}
+
+// nativeSpans returns the spans and delta-encoded counts of the given buckets.
+func nativeSpans(buckets map[int32]int64) ([]*dto.BucketSpan, []int64) {
+	if len(buckets) == 0 {
+		return nil, nil
+	}
+
+	indices := make([]int32, 0, len(buckets))
+	for idx := range buckets {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	spans := make([]*dto.BucketSpan, 0, 1)
+	deltas := make([]int64, 0, len(indices))
+	var prevIndex int32
+	var prevCount int64
+	for i, idx := range indices {
+		switch {
+		case i == 0:
+			spans = append(spans, &dto.BucketSpan{Offset: proto.Int32(idx), Length: proto.Uint32(1)})
+		case idx == prevIndex+1:
+			*spans[len(spans)-1].Length++
+		default:
+			spans = append(spans, &dto.BucketSpan{Offset: proto.Int32(idx - prevIndex - 1), Length: proto.Uint32(1)})
+		}
+		deltas = append(deltas, buckets[idx]-prevCount)
+		prevIndex = idx
+		prevCount = buckets[idx]
+	}
+	return spans, deltas
+}
+
+// classicHistogram collects the buckets, count and sum of a classic histogram
+// spread across multiple metrics
+type classicHistogram struct {
+	name       string
+	labels     []*dto.LabelPair
+	buckets    map[float64]uint64
+	cumulative bool
+	count      *uint64
+	sum        *float64
+	timestamp  time.Time
+	updated    time.Time
+}
+
+// histogram returns the classic buckets of the histogram and, if possible,
+// the native buckets. The sum is NaN if unknown, e.g. for the output of the
+// histogram aggregator.
+func (h *classicHistogram) histogram(maxSchema int32) *dto.Histogram {
+	bounds := make([]float64, 0, len(h.buckets))
+	for bound := range h.buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	out := &dto.Histogram{
+		Bucket:    make([]*dto.Bucket, 0, len(bounds)),
+		SampleSum: proto.Float64(math.NaN()),
+	}
+	counts := make([]uint64, 0, len(bounds))
+	var total uint64
+	for _, bound := range bounds {
+		if h.cumulative {
+			total = h.buckets[bound]
+		} else {
+			total += h.buckets[bound]
+		}
+		counts = append(counts, total)
+		out.Bucket = append(out.Bucket, &dto.Bucket{
+			UpperBound:      proto.Float64(bound),
+			CumulativeCount: proto.Uint64(total),
+		})
+	}
+	if h.count != nil {
+		total = *h.count
+	}
+	out.SampleCount = proto.Uint64(total)
+	if h.sum != nil {
+		out.SampleSum = proto.Float64(*h.sum)
+	}
+
+	schema, buckets, ok := classicToNative(bounds, counts, total, maxSchema)
+	if !ok {
+		return out
+	}
+	out.Schema = proto.Int32(schema)
+	out.ZeroThreshold = proto.Float64(0)
+	out.ZeroCount = proto.Uint64(0)
+	out.PositiveSpan, out.PositiveDelta = nativeSpans(buckets)
+	if len(out.PositiveSpan) == 0 {
+		// Mark the histogram as native even without any observation
+		out.PositiveSpan = []*dto.BucketSpan{{
+			Offset: proto.Int32(0),
+			Length: proto.Uint32(0),
+		}}
+	}
+	return out
+}
+
+// classicToNative returns the schema and the buckets of the native histogram
+// corresponding to the classic buckets with the given bounds and cumulative
+// counts. The exact observations within a classic bucket are unknown, so
+// the conversion is only possible if each classic bucket holding observations
+// covers exactly one native bucket, e.g. for classic buckets with bounds
+// being powers of two. Histograms with a schema above the given maximum are
+// downscaled by merging adjacent buckets.
+func classicToNative(bounds []float64, counts []uint64, total uint64, maxSchema int32) (int32, map[int32]int64, bool) {
+	const epsilon = 1e-6
+
+	type bucket struct {
+		lower, upper float64
+		count        uint64
+	}
+	var used []bucket
+	lower := math.Inf(-1)
+	var previous uint64
+	for i, upper := range bounds {
+		if counts[i] < previous {
+			return 0, nil, false
+		}
+		if count := counts[i] - previous; count > 0 {
+			// Observations must be within finite, positive bounds
+			if lower <= 0 || math.IsInf(upper, 1) {
+				return 0, nil, false
+			}
+			used = append(used, bucket{lower: lower, upper: upper, count: count})
+		}
+		lower, previous = upper, counts[i]
+	}
+	if total != previous {
+		return 0, nil, false
+	}
+	if len(used) == 0 {
+		return maxSchema, nil, true
+	}
+
+	// The schema follows from the growth factor of the buckets with
+	// base = 2^(2^-schema)
+	s := -math.Log2(math.Log2(used[0].upper / used[0].lower))
+	if math.IsNaN(s) || math.Abs(s-math.Round(s)) > epsilon {
+		return 0, nil, false
+	}
+	if s < minNativeHistogramSchema || s > maxNativeHistogramSchema {
+		return 0, nil, false
+	}
+	schema := int32(math.Round(s))
+	var shift uint
+	if schema > maxSchema {
+		shift = uint(schema - maxSchema)
+		schema = maxSchema
+	}
+
+	// Native bucket i covers the range (base^(i-1), base^i]
+	factor := math.Exp2(s)
+	buckets := make(map[int32]int64, len(used))
+	for _, b := range used {
+		lowerIndex := math.Log2(b.lower) * factor
+		upperIndex := math.Log2(b.upper) * factor
+		if math.Abs(upperIndex-math.Round(upperIndex)) > epsilon || math.Abs(upperIndex-lowerIndex-1) > epsilon {
+			return 0, nil, false
+		}
+		index := int32(math.Round(upperIndex))
+		buckets[(index+(1<<shift)-1)>>shift] += int64(b.count)
+	}
+	return schema, buckets, true
+}
@@ -0,0 +1,422 @@
+package prometheus_client
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	v2 "github.com/influxdata/telegraf/plugins/outputs/prometheus_client/v2"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func collectNative(t *testing.T, c prometheus.Collector) map[string]*dto.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		c.Collect(ch)
+	}()
+
+	metrics := make(map[string]*dto.Metric)
+	for m := range ch {
+		var out dto.Metric
+		require.NoError(t, m.Write(&out))
+		metrics[m.Desc().String()] = &out
+	}
+	return metrics
+}
+
+func newTestNativeCollector(maxSchema int32) *nativeHistogramCollector {
+	return newNativeHistogramCollector(
+		v2.NewCollector(time.Minute, true, false, serializer.MetricTypes{}),
+		maxSchema,
+		time.Minute,
+		false,
+		testutil.Logger{},
+	)
+}
+
+func TestNativeHistogram(t *testing.T) {
+	c := newTestNativeCollector(maxNativeHistogramSchema)
+	m := metric.New(
+		"request_duration",
+		map[string]string{"host": "a"},
+		map[string]interface{}{
+			"scale":          int64(0),
+			"count":          uint64(9),
+			"sum":            float64(21),
+			"zero_count":     uint64(1),
+			"zero_threshold": float64(0.001),
+			"positive_0":     uint64(2),
+			"positive_1":     uint64(3),
+			"positive_3":     uint64(1),
+			"negative_-1":    uint64(2),
+		},
+		time.Unix(0, 0),
+		telegraf.Histogram,
+	)
+	require.NoError(t, c.Add([]telegraf.Metric{m}))
+
+	metrics := collectNative(t, c)
+	require.Len(t, metrics, 1)
+	var h *dto.Histogram
+	for _, m := range metrics {
+		h = m.Histogram
+		require.Len(t, m.Label, 1)
+		require.Equal(t, "host", m.Label[0].GetName())
+	}
+	require.NotNil(t, h)
+	require.Equal(t, int32(0), h.GetSchema())
+	require.Equal(t, uint64(9), h.GetSampleCount())
+	require.InDelta(t, 21.0, h.GetSampleSum(), 0)
+	require.Equal(t, uint64(1), h.GetZeroCount())
+	require.InDelta(t, 0.001, h.GetZeroThreshold(), 0)
+	require.Empty(t, h.Bucket)
+
+	// Native bucket indices are shifted by one compared to OpenTelemetry
+	require.Len(t, h.PositiveSpan, 2)
+	require.Equal(t, int32(1), h.PositiveSpan[0].GetOffset())
+	require.Equal(t, uint32(2), h.PositiveSpan[0].GetLength())
+	require.Equal(t, int32(1), h.PositiveSpan[1].GetOffset())
+	require.Equal(t, uint32(1), h.PositiveSpan[1].GetLength())
+	require.Equal(t, []int64{2, 3 - 2, 1 - 3}, h.PositiveDelta)
+	require.Len(t, h.NegativeSpan, 1)
+	require.Equal(t, int32(0), h.NegativeSpan[0].GetOffset())
+	require.Equal(t, []int64{2}, h.NegativeDelta)
+}
+
+func TestNativeHistogramDownscale(t *testing.T) {
+	m := metric.New(
+		"request_duration",
+		map[string]string{},
+		map[string]interface{}{
+			"scale":       int64(2),
+			"count":       uint64(10),
+			"sum":         float64(30),
+			"zero_count":  uint64(0),
+			"positive_-1": uint64(1),
+			"positive_0":  uint64(2),
+			"positive_1":  uint64(3),
+			"positive_3":  uint64(4),
+		},
+		time.Unix(0, 0),
+		telegraf.Histogram,
+	)
+	h, err := exponentialToNative(m, 0)
+	require.NoError(t, err)
+
+	// Buckets -1 and 0..3 of scale 2 map to bucket -1 and 0 of scale 0
+	require.Equal(t, int32(0), h.GetSchema())
+	require.Len(t, h.PositiveSpan, 1)
+	require.Equal(t, int32(0), h.PositiveSpan[0].GetOffset())
+	require.Equal(t, uint32(2), h.PositiveSpan[0].GetLength())
+	require.Equal(t, []int64{1, 9 - 1}, h.PositiveDelta)
+}
+
+func TestNativeHistogramEmpty(t *testing.T) {
+	m := metric.New(
+		"request_duration",
+		map[string]string{},
+		map[string]interface{}{
+			"scale":      int64(3),
+			"count":      uint64(0),
+			"sum":        float64(0),
+			"zero_count": uint64(0),
+		},
+		time.Unix(0, 0),
+		telegraf.Histogram,
+	)
+	h, err := exponentialToNative(m, maxNativeHistogramSchema)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), h.GetSchema())
+	require.Len(t, h.PositiveSpan, 1)
+	require.Equal(t, uint32(0), h.PositiveSpan[0].GetLength())
+}
+
+func TestNativeHistogramInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]interface{}
+	}{
+		{
+			name:   "scale too small",
+			fields: map[string]interface{}{"scale": int64(-5), "count": uint64(0), "sum": float64(0)},
+		},
+		{
+			name:   "non-integer scale",
+			fields: map[string]interface{}{"scale": 1.5, "count": uint64(0), "sum": float64(0)},
+		},
+		{
+			name:   "missing count",
+			fields: map[string]interface{}{"scale": int64(0), "sum": float64(0)},
+		},
+		{
+			name:   "invalid bucket index",
+			fields: map[string]interface{}{"scale": int64(0), "count": uint64(1), "sum": float64(1), "positive_x": uint64(1)},
+		},
+		{
+			name:   "negative bucket count",
+			fields: map[string]interface{}{"scale": int64(0), "count": uint64(1), "sum": float64(1), "positive_1": int64(-1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metric.New("request_duration", map[string]string{}, tt.fields, time.Unix(0, 0), telegraf.Histogram)
+			_, err := exponentialToNative(m, maxNativeHistogramSchema)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNativeHistogramClassic(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics []telegraf.Metric
+		sum     float64
+	}{
+		{
+			name: "histogram aggregator cumulative",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("latency", map[string]string{"host": "a", "le": "1"}, map[string]interface{}{"value_bucket": int64(0)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "le": "2"}, map[string]interface{}{"value_bucket": int64(3)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "le": "4"}, map[string]interface{}{"value_bucket": int64(5)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "le": "8"}, map[string]interface{}{"value_bucket": int64(6)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "le": "+Inf"}, map[string]interface{}{"value_bucket": int64(6)}, time.Unix(0, 0)),
+			},
+			sum: math.NaN(),
+		},
+		{
+			name: "histogram aggregator non-cumulative",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("latency", map[string]string{"host": "a", "gt": "-Inf", "le": "1"}, map[string]interface{}{"value_bucket": int64(0)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "gt": "1", "le": "2"}, map[string]interface{}{"value_bucket": int64(3)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "gt": "2", "le": "4"}, map[string]interface{}{"value_bucket": int64(2)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "gt": "4", "le": "8"}, map[string]interface{}{"value_bucket": int64(1)}, time.Unix(0, 0)),
+				testutil.MustMetric("latency", map[string]string{"host": "a", "gt": "8", "le": "+Inf"}, map[string]interface{}{"value_bucket": int64(0)}, time.Unix(0, 0)),
+			},
+			sum: math.NaN(),
+		},
+		{
+			name: "prometheus input metric version 1",
+			metrics: []telegraf.Metric{
+				metric.New(
+					"latency_value",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"count": float64(6), "sum": float64(17), "1": float64(0), "2": float64(3), "4": float64(5), "8": float64(6), "+Inf": float64(6)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+			sum: 17,
+		},
+		{
+			name: "prometheus input metric version 2",
+			metrics: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"latency_value_count": float64(6), "latency_value_sum": float64(17)},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New("prometheus", map[string]string{"host": "a", "le": "1"}, map[string]interface{}{"latency_value_bucket": float64(0)}, time.Unix(0, 0), telegraf.Histogram),
+				metric.New("prometheus", map[string]string{"host": "a", "le": "2"}, map[string]interface{}{"latency_value_bucket": float64(3)}, time.Unix(0, 0), telegraf.Histogram),
+				metric.New("prometheus", map[string]string{"host": "a", "le": "4"}, map[string]interface{}{"latency_value_bucket": float64(5)}, time.Unix(0, 0), telegraf.Histogram),
+				metric.New("prometheus", map[string]string{"host": "a", "le": "8"}, map[string]interface{}{"latency_value_bucket": float64(6)}, time.Unix(0, 0), telegraf.Histogram),
+				metric.New("prometheus", map[string]string{"host": "a", "le": "+Inf"}, map[string]interface{}{"latency_value_bucket": float64(6)}, time.Unix(0, 0), telegraf.Histogram),
+			},
+			sum: 17,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestNativeCollector(maxNativeHistogramSchema)
+			require.NoError(t, c.Add(tt.metrics))
+
+			collected := collectNative(t, c)
+			require.Len(t, collected, 1)
+			for desc, m := range collected {
+				require.Contains(t, desc, `fqName: "latency_value"`)
+				require.Len(t, m.Label, 1)
+				require.Equal(t, "host", m.Label[0].GetName())
+
+				h := m.Histogram
+				require.NotNil(t, h)
+				require.Equal(t, uint64(6), h.GetSampleCount())
+				if math.IsNaN(tt.sum) {
+					require.True(t, math.IsNaN(h.GetSampleSum()))
+				} else {
+					require.InDelta(t, tt.sum, h.GetSampleSum(), 0)
+				}
+
+				// Classic buckets are kept for scrapers not supporting native
+				// histograms
+				require.Len(t, h.Bucket, 5)
+				require.Equal(t, uint64(3), h.Bucket[1].GetCumulativeCount())
+
+				// The classic buckets are the native buckets 1 to 3 of schema 0
+				require.Equal(t, int32(0), h.GetSchema())
+				require.Len(t, h.PositiveSpan, 1)
+				require.Equal(t, int32(1), h.PositiveSpan[0].GetOffset())
+				require.Equal(t, uint32(3), h.PositiveSpan[0].GetLength())
+				require.Equal(t, []int64{3, 2 - 3, 1 - 2}, h.PositiveDelta)
+			}
+		})
+	}
+}
+
+func TestClassicToNative(t *testing.T) {
+	bounds := []float64{1, math.Sqrt2, 2, math.Inf(1)}
+	counts := []uint64{0, 1, 3, 3}
+
+	schema, buckets, ok := classicToNative(bounds, counts, 3, maxNativeHistogramSchema)
+	require.True(t, ok)
+	require.Equal(t, int32(1), schema)
+	require.Equal(t, map[int32]int64{1: 1, 2: 2}, buckets)
+
+	// Downscaling merges the buckets
+	schema, buckets, ok = classicToNative(bounds, counts, 3, 0)
+	require.True(t, ok)
+	require.Equal(t, int32(0), schema)
+	require.Equal(t, map[int32]int64{1: 3}, buckets)
+
+	// Empty buckets of any width are fine
+	schema, buckets, ok = classicToNative([]float64{0.1, 1, 2, 10}, []uint64{0, 0, 4, 4}, 4, 3)
+	require.True(t, ok)
+	require.Equal(t, int32(0), schema)
+	require.Equal(t, map[int32]int64{1: 4}, buckets)
+}
+
+func TestClassicToNativeInexact(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds []float64
+		counts []uint64
+		total  uint64
+	}{
+		{
+			name:   "non-exponential bounds",
+			bounds: []float64{0.1, 0.5, 1},
+			counts: []uint64{0, 2, 3},
+			total:  3,
+		},
+		{
+			name:   "observations below smallest bound",
+			bounds: []float64{1, 2},
+			counts: []uint64{1, 1},
+			total:  1,
+		},
+		{
+			name:   "observations above largest bound",
+			bounds: []float64{1, 2, math.Inf(1)},
+			counts: []uint64{0, 1, 2},
+			total:  2,
+		},
+		{
+			name:   "observations without bucket",
+			bounds: []float64{1, 2},
+			counts: []uint64{0, 1},
+			total:  2,
+		},
+		{
+			name:   "non-positive bounds",
+			bounds: []float64{-1, 0, 1},
+			counts: []uint64{0, 1, 1},
+			total:  1,
+		},
+		{
+			name:   "different schemas",
+			bounds: []float64{1, 2, 2 * math.Sqrt2},
+			counts: []uint64{0, 1, 2},
+			total:  2,
+		},
+		{
+			name:   "bounds between native bounds",
+			bounds: []float64{3, 6},
+			counts: []uint64{0, 1},
+			total:  1,
+		},
+		{
+			name:   "decreasing counts",
+			bounds: []float64{1, 2, 4},
+			counts: []uint64{0, 2, 1},
+			total:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, ok := classicToNative(tt.bounds, tt.counts, tt.total, maxNativeHistogramSchema)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestNativeHistogramClassicFallback(t *testing.T) {
+	c := newTestNativeCollector(maxNativeHistogramSchema)
+	metrics := []telegraf.Metric{
+		metric.New(
+			"http",
+			map[string]string{"le": "1"},
+			map[string]interface{}{"latency_bucket": uint64(3)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"http",
+			map[string]string{"le": "+Inf"},
+			map[string]interface{}{"latency_bucket": uint64(4)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"http",
+			map[string]string{},
+			map[string]interface{}{"latency_sum": float64(5), "latency_count": uint64(4)},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		// Not a histogram, so the scale field has no special meaning
+		metric.New(
+			"zoom",
+			map[string]string{},
+			map[string]interface{}{"scale": int64(2)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	require.NoError(t, c.Add(metrics))
+
+	// The classic buckets don't match native buckets, so only the classic
+	// buckets are exported
+	collected := collectNative(t, c)
+	require.Len(t, collected, 2)
+	var found bool
+	for _, m := range collected {
+		if m.Histogram == nil {
+			continue
+		}
+		found = true
+		require.Nil(t, m.Histogram.Schema)
+		require.Empty(t, m.Histogram.PositiveSpan)
+		require.Len(t, m.Histogram.Bucket, 2)
+	}
+	require.True(t, found)
+}
+
+func TestNativeHistogramInvalidSchema(t *testing.T) {
+	plugin := &PrometheusClient{
+		Listen:            ":0",
+		MetricVersion:     2,
+		CollectorsExclude: []string{"gocollector", "process"},
+		NativeHistograms:  true,
+		NativeSchema:      9,
+		Log:               testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "native_histogram_schema")
+}
@@ -60,6 +60,8 @@ type PrometheusClient struct {
 	StringAsLabel      bool                   `toml:"string_as_label"`
 	ExportTimestamp    bool                   `toml:"export_timestamp"`
 	TypeMappings       serializer.MetricTypes `toml:"metric_types"`
+	NativeHistograms   bool                   `toml:"native_histograms"`
+	NativeSchema       int32                  `toml:"native_histogram_schema"`
 	Log                telegraf.Logger        `toml:"-"`
 
 	tlsint.ServerConfig
@@ -116,10 +118,6 @@ func (p *PrometheusClient) Init() error {
 			p.TypeMappings,
 			p.Log,
 		)
-		err := registry.Register(p.collector)
-		if err != nil {
-			return err
-		}
 	case 2:
 		p.collector = v2.NewCollector(
 			time.Duration(p.ExpirationInterval),
@@ -127,10 +125,23 @@ func (p *PrometheusClient) Init() error {
 			p.ExportTimestamp,
 			p.TypeMappings,
 		)
-		err := registry.Register(p.collector)
-		if err != nil {
-			return err
+	}
+
+	if p.NativeHistograms {
+		if p.NativeSchema < minNativeHistogramSchema || p.NativeSchema > maxNativeHistogramSchema {
+			return fmt.Errorf("native_histogram_schema must be between %d and %d", minNativeHistogramSchema, maxNativeHistogramSchema)
 		}
+		p.collector = newNativeHistogramCollector(
+			p.collector,
+			p.NativeSchema,
+			time.Duration(p.ExpirationInterval),
+			p.ExportTimestamp,
+			p.Log,
+		)
+	}
+
+	if err := registry.Register(p.collector); err != nil {
+		return err
 	}
 
 	ipRange := make([]*net.IPNet, 0, len(p.IPRange))
@@ -294,6 +305,7 @@ func init() {
 			Path:               defaultPath,
 			ExpirationInterval: defaultExpirationInterval,
 			StringAsLabel:      true,
+			NativeSchema:       defaultNativeHistogramSchema,
 		}
 	})
 }
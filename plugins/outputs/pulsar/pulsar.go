@@ -0,0 +1,406 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package pulsar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	maxErrMsgLen    = 1024
+	defaultTenant   = "public"
+	defaultNS       = "default"
+	defaultMaxBatch = 1000
+
+	// Schema of the published messages, the REST API expects the JSON
+	// representation of the schema info.
+	stringSchema = `{"type":"STRING","schema":"","properties":{}}`
+)
+
+type Pulsar struct {
+	URL             string          `toml:"url"`
+	Topic           string          `toml:"topic"`
+	TopicTemplate   string          `toml:"topic_template"`
+	RoutingTag      string          `toml:"routing_tag"`
+	RoutingKey      string          `toml:"routing_key"`
+	ProducerName    string          `toml:"producer_name"`
+	UseBatchFormat  bool            `toml:"use_batch_format"`
+	MaxBatchSize    int             `toml:"max_messages_per_request"`
+	ContentEncoding string          `toml:"content_encoding"`
+	Token           config.Secret   `toml:"token"`
+	Log             telegraf.Logger `toml:"-"`
+	httpconfig.HTTPClientConfig
+
+	client     *http.Client
+	topicTmpl  *template.Template
+	serializer serializers.Serializer
+}
+
+type producerMessages struct {
+	ProducerName string            `json:"producerName,omitempty"`
+	ValueSchema  string            `json:"valueSchema"`
+	Messages     []producerMessage `json:"messages"`
+}
+
+type producerMessage struct {
+	Key       string `json:"key,omitempty"`
+	Payload   string `json:"payload"`
+	EventTime string `json:"eventTime,omitempty"`
+}
+
+type publishResult struct {
+	MessagePublishResults []struct {
+		MessageID string `json:"messageId"`
+		ErrorCode int    `json:"errorCode"`
+		ErrorMsg  string `json:"errorMsg"`
+	} `json:"messagePublishResults"`
+}
+
+func (*Pulsar) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Pulsar) SetSerializer(serializer serializers.Serializer) {
+	p.serializer = serializer
+}
+
+func (p *Pulsar) Init() error {
+	if p.URL == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return fmt.Errorf("invalid url scheme %q", u.Scheme)
+	}
+
+	if _, err := topicPath(p.Topic); err != nil {
+		return fmt.Errorf("invalid topic: %w", err)
+	}
+
+	if p.TopicTemplate != "" {
+		p.topicTmpl, err = template.New("topic").Parse(p.TopicTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing topic_template failed: %w", err)
+		}
+	}
+
+	switch p.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("invalid content encoding %q", p.ContentEncoding)
+	}
+
+	if p.MaxBatchSize <= 0 {
+		p.MaxBatchSize = defaultMaxBatch
+	}
+
+	return nil
+}
+
+func (p *Pulsar) Connect() error {
+	client, err := p.HTTPClientConfig.CreateClient(context.Background(), p.Log)
+	if err != nil {
+		return err
+	}
+	p.client = client
+
+	return nil
+}
+
+func (p *Pulsar) Close() error {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (p *Pulsar) Write(metrics []telegraf.Metric) error {
+	// Group the metrics by topic to publish them in as few requests as
+	// possible. In batch format, metrics are additionally grouped by the value
+	// of the routing tag as all metrics of a message share the same key.
+	type group struct {
+		topic   string
+		tag     string
+		metrics []telegraf.Metric
+	}
+	var groups []*group
+	index := make(map[string]*group)
+	for _, m := range metrics {
+		topic := p.topic(m)
+		id := topic
+		var tag string
+		if p.UseBatchFormat && p.RoutingTag != "" {
+			tag, _ = m.GetTag(p.RoutingTag)
+			id += "\x00" + tag
+		}
+		g, found := index[id]
+		if !found {
+			g = &group{topic: topic, tag: tag}
+			index[id] = g
+			groups = append(groups, g)
+		}
+		g.metrics = append(g.metrics, m)
+	}
+
+	for _, g := range groups {
+		var msgs []producerMessage
+		var err error
+		if p.UseBatchFormat {
+			msgs, err = p.batchMessages(g.metrics, g.tag)
+		} else {
+			msgs, err = p.messages(g.metrics)
+		}
+		if err != nil {
+			return err
+		}
+		for start := 0; start < len(msgs); start += p.MaxBatchSize {
+			end := start + p.MaxBatchSize
+			if end > len(msgs) {
+				end = len(msgs)
+			}
+			if err := p.publish(g.topic, msgs[start:end]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// batchMessages serializes all metrics into a single message in batch format
+// using the given tag value as key. Without tag value, the key is generated
+// once for the whole message.
+func (p *Pulsar) batchMessages(metrics []telegraf.Metric, tag string) ([]producerMessage, error) {
+	key := tag
+	if key == "" {
+		var err error
+		if key, err = p.defaultKey(); err != nil {
+			return nil, fmt.Errorf("could not generate routing key: %w", err)
+		}
+	}
+
+	buf, err := p.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return nil, err
+	}
+	return []producerMessage{{
+		Key:       key,
+		Payload:   string(buf),
+		EventTime: strconv.FormatInt(metrics[0].Time().UnixMilli(), 10),
+	}}, nil
+}
+
+func (p *Pulsar) messages(metrics []telegraf.Metric) ([]producerMessage, error) {
+	msgs := make([]producerMessage, 0, len(metrics))
+	for _, m := range metrics {
+		buf, err := p.serializer.Serialize(m)
+		if err != nil {
+			p.Log.Debugf("Could not serialize metric: %v", err)
+			continue
+		}
+		key, err := p.routingKey(m)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate routing key: %w", err)
+		}
+		msgs = append(msgs, producerMessage{
+			Key:       key,
+			Payload:   string(buf),
+			EventTime: strconv.FormatInt(m.Time().UnixMilli(), 10),
+		})
+	}
+	return msgs, nil
+}
+
+func (p *Pulsar) topic(metric telegraf.Metric) string {
+	if p.topicTmpl == nil {
+		return p.Topic
+	}
+
+	m := metric
+	if wm, ok := metric.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		p.Log.Debugf("Metric of type %T is not a template metric", metric)
+		return p.Topic
+	}
+
+	var buf bytes.Buffer
+	if err := p.topicTmpl.Execute(&buf, tm); err != nil {
+		p.Log.Debugf("Executing topic template failed: %v", err)
+		return p.Topic
+	}
+	topic := strings.TrimSpace(buf.String())
+	if topic == "" {
+		return p.Topic
+	}
+	if _, err := topicPath(topic); err != nil {
+		p.Log.Debugf("Invalid topic %q generated by template, using fallback topic: %v", topic, err)
+		return p.Topic
+	}
+	return topic
+}
+
+func (p *Pulsar) routingKey(metric telegraf.Metric) (string, error) {
+	if p.RoutingTag != "" {
+		if key, ok := metric.GetTag(p.RoutingTag); ok {
+			return key, nil
+		}
+	}
+	return p.defaultKey()
+}
+
+// defaultKey returns the key used if no routing tag is set or found
+func (p *Pulsar) defaultKey() (string, error) {
+	if p.RoutingKey == "random" {
+		u, err := uuid.NewV4()
+		if err != nil {
+			return "", err
+		}
+		return u.String(), nil
+	}
+
+	return p.RoutingKey, nil
+}
+
+func (p *Pulsar) publish(topic string, msgs []producerMessage) error {
+	path, err := topicPath(topic)
+	if err != nil {
+		return err
+	}
+	address := strings.TrimSuffix(p.URL, "/") + "/topics/" + path
+
+	body, err := json.Marshal(producerMessages{
+		ProducerName: p.ProducerName,
+		ValueSchema:  stringSchema,
+		Messages:     msgs,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding messages failed: %w", err)
+	}
+
+	var reqBody io.Reader = bytes.NewReader(body)
+	if p.ContentEncoding == "gzip" {
+		rc := internal.CompressWithGzip(reqBody)
+		defer rc.Close()
+		reqBody = rc
+	}
+
+	req, err := http.NewRequest(http.MethodPost, address, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Content-Type", "application/json")
+	if p.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	if !p.Token.Empty() {
+		token, err := p.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errorLine := ""
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
+		if scanner.Scan() {
+			errorLine = scanner.Text()
+		}
+		return fmt.Errorf("publishing to %q received status code: %d. body: %s", topic, resp.StatusCode, errorLine)
+	}
+
+	var result publishResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding publish result for %q failed: %w", topic, err)
+	}
+	for _, r := range result.MessagePublishResults {
+		if r.ErrorCode != 0 {
+			return fmt.Errorf("publishing to %q failed with code %d: %s", topic, r.ErrorCode, r.ErrorMsg)
+		}
+	}
+
+	return nil
+}
+
+// topicPath converts the topic name to the path of the REST endpoint. Topics
+// can be given as short name in the default namespace, as
+// "<tenant>/<namespace>/<topic>" or as fully qualified name with domain, i.e.
+// "persistent://<tenant>/<namespace>/<topic>".
+func topicPath(topic string) (string, error) {
+	domain := "persistent"
+	name := topic
+	if d, n, found := strings.Cut(topic, "://"); found {
+		domain = d
+		name = n
+	}
+	switch domain {
+	case "persistent", "non-persistent":
+	default:
+		return "", fmt.Errorf("unknown topic domain %q", domain)
+	}
+
+	elements := strings.Split(name, "/")
+	switch len(elements) {
+	case 1:
+		elements = []string{defaultTenant, defaultNS, elements[0]}
+	case 3:
+	default:
+		return "", fmt.Errorf("invalid topic name %q", topic)
+	}
+	for i, e := range elements {
+		if e == "" {
+			return "", fmt.Errorf("invalid topic name %q", topic)
+		}
+		elements[i] = url.PathEscape(e)
+	}
+	return domain + "/" + strings.Join(elements, "/"), nil
+}
+
+func init() {
+	outputs.Add("pulsar", func() telegraf.Output {
+		return &Pulsar{
+			Topic:        "telegraf",
+			MaxBatchSize: defaultMaxBatch,
+		}
+	})
+}
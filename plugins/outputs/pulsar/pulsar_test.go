@@ -0,0 +1,273 @@
+package pulsar
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestTopicPath(t *testing.T) {
+	tests := []struct {
+		topic    string
+		expected string
+		err      bool
+	}{
+		{topic: "telegraf", expected: "persistent/public/default/telegraf"},
+		{topic: "team/metrics/cpu", expected: "persistent/team/metrics/cpu"},
+		{topic: "non-persistent://team/metrics/cpu", expected: "non-persistent/team/metrics/cpu"},
+		{topic: "persistent://team/cpu", err: true},
+		{topic: "foo://team/metrics/cpu", err: true},
+		{topic: "team//cpu", err: true},
+		{topic: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			path, err := topicPath(tt.topic)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, path)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string][]producerMessage)
+	var auth string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req producerMessages
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], req.Messages...)
+		auth = r.Header.Get("Authorization")
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"messagePublishResults":[{"messageId":"1:1:0","errorCode":0}]}`))
+	}))
+	defer ts.Close()
+
+	plugin := &Pulsar{
+		URL:           ts.URL,
+		Topic:         "telegraf",
+		TopicTemplate: `{{with .Tag "team"}}{{.}}/metrics/{{$.Name}}{{end}}`,
+		RoutingTag:    "host",
+		Token:         config.NewSecret([]byte("secret-token")),
+		Log:           testutil.Logger{},
+	}
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Tracking metrics need to be unwrapped for the template
+	tm, _ := metric.WithTracking(
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"team": "storage", "host": "a"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(1, 0),
+		),
+		func(telegraf.DeliveryInfo) {},
+	)
+	metrics := []telegraf.Metric{
+		tm,
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"value": 23.0},
+			time.Unix(2, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "Bearer secret-token", auth)
+
+	expected := map[string][]producerMessage{
+		"/topics/persistent/storage/metrics/cpu": {
+			{Key: "a", Payload: "cpu,host=a,team=storage value=42 1000000000\n", EventTime: "1000"},
+		},
+		"/topics/persistent/public/default/telegraf": {
+			{Key: "b", Payload: "cpu,host=b value=23 2000000000\n", EventTime: "2000"},
+		},
+	}
+	require.Equal(t, expected, received)
+}
+
+func TestWriteBatchFormat(t *testing.T) {
+	var mu sync.Mutex
+	var received []producerMessage
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req producerMessages
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		received = append(received, req.Messages...)
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"messagePublishResults":[{"messageId":"1:1:0","errorCode":0}]}`))
+	}))
+	defer ts.Close()
+
+	plugin := &Pulsar{
+		URL:            ts.URL,
+		Topic:          "telegraf",
+		UseBatchFormat: true,
+		Log:            testutil.Logger{},
+	}
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testutil.MockMetrics()))
+	require.NoError(t, plugin.Write(append(testutil.MockMetrics(), testutil.MockMetrics()...)))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2)
+}
+
+func TestWriteBatchFormatKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		routingTag string
+		routingKey string
+		expected   []string
+	}{
+		{
+			name:       "random key per message",
+			routingKey: "random",
+		},
+		{
+			name:       "static key",
+			routingKey: "telegraf",
+			expected:   []string{"telegraf"},
+		},
+		{
+			name:       "routing tag",
+			routingTag: "host",
+			routingKey: "random",
+			expected:   []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var received []producerMessage
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req producerMessages
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				mu.Lock()
+				received = append(received, req.Messages...)
+				mu.Unlock()
+
+				_, _ = w.Write([]byte(`{"messagePublishResults":[{"messageId":"1:1:0","errorCode":0}]}`))
+			}))
+			defer ts.Close()
+
+			plugin := &Pulsar{
+				URL:            ts.URL,
+				Topic:          "telegraf",
+				RoutingTag:     tt.routingTag,
+				RoutingKey:     tt.routingKey,
+				UseBatchFormat: true,
+				Log:            testutil.Logger{},
+			}
+			s := &influx.Serializer{}
+			require.NoError(t, s.Init())
+			plugin.SetSerializer(s)
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			metrics := []telegraf.Metric{
+				testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1, 0)),
+				testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(2, 0)),
+				testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3.0}, time.Unix(3, 0)),
+			}
+			require.NoError(t, plugin.Write(metrics))
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.routingTag == "" {
+				// All metrics of the topic are sent in a single message
+				require.Len(t, received, 1)
+				require.NotEmpty(t, received[0].Key)
+				if tt.expected != nil {
+					require.Equal(t, tt.expected[0], received[0].Key)
+				}
+				return
+			}
+			keys := make([]string, 0, len(received))
+			for _, msg := range received {
+				keys = append(keys, msg.Key)
+			}
+			require.ElementsMatch(t, tt.expected, keys)
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"messagePublishResults":[{"errorCode":2,"errorMsg":"topic fenced"}]}`))
+	}))
+	defer ts.Close()
+
+	plugin := &Pulsar{
+		URL:   ts.URL,
+		Topic: "telegraf",
+		Log:   testutil.Logger{},
+	}
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.ErrorContains(t, plugin.Write(testutil.MockMetrics()), "topic fenced")
+}
+
+func TestInitInvalidTopic(t *testing.T) {
+	plugin := &Pulsar{
+		URL:   "http://localhost:8080",
+		Topic: "foo/bar",
+		Log:   testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid topic")
+}
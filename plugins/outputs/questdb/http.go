@@ -0,0 +1,124 @@
+package questdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+)
+
+const maxErrMsgLen = 1024
+
+type httpWriter struct {
+	url    *url.URL
+	config *httpconfig.HTTPClientConfig
+	user   config.Secret
+	pass   config.Secret
+	token  config.Secret
+	log    telegraf.Logger
+
+	client *http.Client
+}
+
+// errorResponse is the error returned by the server for rejected requests
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	ErrorID string `json:"errorId"`
+}
+
+func (w *httpWriter) Connect() error {
+	client, err := w.config.CreateClient(context.Background(), w.log)
+	if err != nil {
+		return err
+	}
+	w.client = client
+
+	return nil
+}
+
+func (w *httpWriter) Close() error {
+	if w.client != nil {
+		w.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (w *httpWriter) Write(body []byte) error {
+	u := *w.url
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/write"
+	u.RawQuery = "precision=n"
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	if err := w.authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrMsgLen))
+	var e errorResponse
+	if err := json.Unmarshal(msg, &e); err == nil && e.Message != "" {
+		msg = []byte(fmt.Sprintf("%s (line %d, error id %s)", e.Message, e.Line, e.ErrorID))
+	}
+
+	// Malformed data or schema mismatches will fail again on retry, so drop
+	// the batch in this case.
+	if resp.StatusCode == http.StatusBadRequest {
+		w.log.Errorf("Dropping batch rejected by server: %s", msg)
+		return nil
+	}
+	return fmt.Errorf("writing to %q failed with status %d: %s", w.url.Host, resp.StatusCode, msg)
+}
+
+func (w *httpWriter) authorize(req *http.Request) error {
+	if !w.token.Empty() {
+		token, err := w.token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+		return nil
+	}
+
+	if !w.user.Empty() || !w.pass.Empty() {
+		username, err := w.user.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := w.pass.Get()
+		if err != nil {
+			username.Destroy()
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		req.SetBasicAuth(username.String(), password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package questdb
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Characters not allowed in QuestDB table and column names
+var (
+	tableNameReplacer = strings.NewReplacer(
+		".", "_", "?", "_", ",", "_", "'", "_", "\"", "_", "\\", "_", "/", "_",
+		":", "_", "(", "_", ")", "_", "+", "_", "*", "_", "%", "_", "~", "_",
+		"\n", "_", "\r", "_", "\t", "_", " ", "_",
+	)
+	columnNameReplacer = strings.NewReplacer(
+		".", "_", "?", "_", ",", "_", "'", "_", "\"", "_", "\\", "_", "/", "_",
+		":", "_", "(", "_", ")", "_", "+", "_", "-", "_", "*", "_", "%", "_",
+		"~", "_", "\n", "_", "\r", "_", "\t", "_", " ", "_",
+	)
+)
+
+type writer interface {
+	Connect() error
+	Write(body []byte) error
+	Close() error
+}
+
+type QuestDB struct {
+	URL                string          `toml:"url"`
+	Username           config.Secret   `toml:"username"`
+	Password           config.Secret   `toml:"password"`
+	Token              config.Secret   `toml:"token"`
+	KeyID              string          `toml:"key_id"`
+	PrivateKey         config.Secret   `toml:"private_key"`
+	TablePrefix        string          `toml:"table_prefix"`
+	UseServerTimestamp bool            `toml:"use_server_timestamp"`
+	Log                telegraf.Logger `toml:"-"`
+	httpconfig.HTTPClientConfig
+
+	writer     writer
+	serializer *influx.Serializer
+}
+
+func (*QuestDB) SampleConfig() string {
+	return sampleConfig
+}
+
+func (q *QuestDB) Init() error {
+	if q.URL == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(q.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		if q.KeyID != "" {
+			return errors.New("'key_id' is only supported for TCP connections")
+		}
+		if !q.Token.Empty() && (!q.Username.Empty() || !q.Password.Empty()) {
+			return errors.New("either 'token' or 'username' and 'password' can be used")
+		}
+		q.writer = &httpWriter{
+			url:    u,
+			config: &q.HTTPClientConfig,
+			user:   q.Username,
+			pass:   q.Password,
+			token:  q.Token,
+			log:    q.Log,
+		}
+	case "tcp", "tcps":
+		if !q.Username.Empty() || !q.Password.Empty() || !q.Token.Empty() {
+			return errors.New("'username', 'password' and 'token' are only supported for HTTP connections")
+		}
+		if (q.KeyID == "") != q.PrivateKey.Empty() {
+			return errors.New("both 'key_id' and 'private_key' are required for authentication")
+		}
+		tlsCfg, err := q.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "tcp" {
+			tlsCfg = nil
+		}
+		q.writer = &tcpWriter{
+			address: u.Host,
+			tls:     tlsCfg,
+			timeout: q.Timeout,
+			keyID:   q.KeyID,
+			key:     q.PrivateKey,
+		}
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	// QuestDB does not support unsigned integers so they are converted to
+	// signed integers.
+	q.serializer = &influx.Serializer{SortFields: true}
+	return q.serializer.Init()
+}
+
+func (q *QuestDB) Connect() error {
+	return q.writer.Connect()
+}
+
+func (q *QuestDB) Close() error {
+	return q.writer.Close()
+}
+
+func (q *QuestDB) Write(metrics []telegraf.Metric) error {
+	var body []byte
+	for _, m := range metrics {
+		line, err := q.serializer.Serialize(q.convert(m))
+		if err != nil {
+			q.Log.Errorf("Could not serialize metric: %v", err)
+			continue
+		}
+		if q.UseServerTimestamp {
+			// Omit the timestamp so the server assigns the designated
+			// timestamp on insertion.
+			if idx := bytes.LastIndexByte(line, ' '); idx > 0 {
+				line = append(line[:idx], '\n')
+			}
+		}
+		body = append(body, line...)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	return q.writer.Write(body)
+}
+
+// convert maps the metric to a row of a table with the name of the
+// measurement, tags are stored as symbols and fields as columns.
+func (q *QuestDB) convert(m telegraf.Metric) telegraf.Metric {
+	tags := make(map[string]string, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		tags[columnNameReplacer.Replace(tag.Key)] = tag.Value
+	}
+	fields := make(map[string]interface{}, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		fields[columnNameReplacer.Replace(field.Key)] = field.Value
+	}
+	name := tableNameReplacer.Replace(q.TablePrefix + m.Name())
+
+	return metric.New(name, tags, fields, m.Time(), m.Type())
+}
+
+func init() {
+	outputs.Add("questdb", func() telegraf.Output {
+		return &QuestDB{}
+	})
+}
@@ -0,0 +1,243 @@
+package questdb
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestWriteHTTP(t *testing.T) {
+	var mu sync.Mutex
+	var body, auth, query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/write" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		mu.Lock()
+		body = string(buf)
+		auth = r.Header.Get("Authorization")
+		query = r.URL.RawQuery
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &QuestDB{
+		URL:         ts.URL,
+		Token:       config.NewSecret([]byte("secret")),
+		TablePrefix: "telegraf.",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host-name": "a"},
+			map[string]interface{}{
+				"usage.idle": 42.0,
+				"count":      uint64(23),
+			},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "Bearer secret", auth)
+	require.Equal(t, "precision=n", query)
+	require.Equal(t, "telegraf_cpu,host_name=a count=23i,usage_idle=42 1000000000\n", body)
+}
+
+func TestWriteHTTPServerTimestamp(t *testing.T) {
+	var mu sync.Mutex
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		body = string(buf)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &QuestDB{
+		URL:                ts.URL,
+		UseServerTimestamp: true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": "a b"},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, "cpu value=\"a b\"\n", body)
+}
+
+func TestWriteHTTPErrors(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusBadRequest)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+		_, _ = w.Write([]byte(`{"code":"invalid","message":"failed to parse line protocol","line":1,"errorId":"a-1"}`))
+	}))
+	defer ts.Close()
+
+	plugin := &QuestDB{
+		URL: ts.URL,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Rejected data is dropped
+	require.NoError(t, plugin.Write(testutil.MockMetrics()))
+
+	// Server errors are retried
+	status.Store(http.StatusInternalServerError)
+	require.ErrorContains(t, plugin.Write(testutil.MockMetrics()), "failed to parse line protocol")
+}
+
+func TestWriteTCPAuth(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	d := base64.RawURLEncoding.EncodeToString(key.D.Bytes())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	type result struct {
+		keyID    string
+		verified bool
+		data     string
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- result{}
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		var res result
+		res.keyID, _ = reader.ReadString('\n')
+		challenge := "challenge-123"
+		_, _ = conn.Write([]byte(challenge + "\n"))
+		line, _ := reader.ReadString('\n')
+		sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+		if err == nil {
+			hash := sha256.Sum256([]byte(challenge))
+			res.verified = ecdsa.VerifyASN1(&key.PublicKey, hash[:], sig)
+		}
+		res.data, _ = reader.ReadString('\n')
+		done <- res
+	}()
+
+	plugin := &QuestDB{
+		URL:        "tcp://" + listener.Addr().String(),
+		KeyID:      "testuser",
+		PrivateKey: config.NewSecret([]byte(d)),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	res := <-done
+	require.Equal(t, "testuser\n", res.keyID)
+	require.True(t, res.verified)
+	require.Equal(t, "cpu value=42 1000000000\n", res.data)
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *QuestDB
+		expected string
+	}{
+		{
+			name:     "missing url",
+			plugin:   &QuestDB{},
+			expected: "url is required",
+		},
+		{
+			name:     "invalid scheme",
+			plugin:   &QuestDB{URL: "udp://localhost:9009"},
+			expected: "unsupported scheme",
+		},
+		{
+			name:     "key without private key",
+			plugin:   &QuestDB{URL: "tcp://localhost:9009", KeyID: "admin"},
+			expected: "both 'key_id' and 'private_key' are required",
+		},
+		{
+			name:     "key with http",
+			plugin:   &QuestDB{URL: "http://localhost:9000", KeyID: "admin"},
+			expected: "only supported for TCP",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package questdb
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+type tcpWriter struct {
+	address string
+	tls     *tls.Config
+	timeout config.Duration
+	keyID   string
+	key     config.Secret
+
+	conn net.Conn
+}
+
+func (w *tcpWriter) Connect() error {
+	timeout := time.Duration(w.timeout)
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if w.tls != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.address, w.tls)
+	} else {
+		conn, err = dialer.Dial("tcp", w.address)
+	}
+	if err != nil {
+		return err
+	}
+
+	if w.keyID != "" {
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			conn.Close()
+			return err
+		}
+		if err := w.authenticate(conn); err != nil {
+			conn.Close()
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	w.conn = conn
+
+	return nil
+}
+
+// authenticate performs the challenge-response authentication using the
+// ECDSA key of the user identified by the key ID.
+func (w *tcpWriter) authenticate(conn net.Conn) error {
+	key, err := w.privateKey()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Write([]byte(w.keyID + "\n")); err != nil {
+		return err
+	}
+	challenge, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("reading challenge failed: %w", err)
+	}
+	challenge = challenge[:len(challenge)-1]
+
+	hash := sha256.Sum256(challenge)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		return fmt.Errorf("signing challenge failed: %w", err)
+	}
+	_, err = conn.Write([]byte(base64.StdEncoding.EncodeToString(sig) + "\n"))
+	return err
+}
+
+func (w *tcpWriter) privateKey() (*ecdsa.PrivateKey, error) {
+	secret, err := w.key.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting private key failed: %w", err)
+	}
+	defer secret.Destroy()
+
+	// The key is usually given as the base64url encoded 'd' parameter of the
+	// JSON web key.
+	raw, err := base64.RawURLEncoding.DecodeString(secret.TemporaryString())
+	if err != nil {
+		raw, err = base64.StdEncoding.DecodeString(secret.TemporaryString())
+		if err != nil {
+			return nil, errors.New("decoding private key failed")
+		}
+	}
+
+	if len(raw) > 32 {
+		return nil, errors.New("invalid private key length")
+	}
+	scalar := make([]byte, 32)
+	copy(scalar[32-len(raw):], raw)
+
+	// Derive the public key from the private scalar
+	pk, err := ecdh.P256().NewPrivateKey(scalar)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	point := pk.PublicKey().Bytes()
+
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(scalar)}
+	key.PublicKey.Curve = elliptic.P256()
+	key.PublicKey.X = new(big.Int).SetBytes(point[1:33])
+	key.PublicKey.Y = new(big.Int).SetBytes(point[33:])
+	return key, nil
+}
+
+func (w *tcpWriter) Write(body []byte) error {
+	if w.conn == nil {
+		if err := w.Connect(); err != nil {
+			return err
+		}
+	}
+
+	if w.timeout > 0 {
+		if err := w.conn.SetWriteDeadline(time.Now().Add(time.Duration(w.timeout))); err != nil {
+			return err
+		}
+	}
+
+	// The server does not report errors for individual lines but closes
+	// the connection, so reconnect with the next write.
+	if _, err := w.conn.Write(body); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (w *tcpWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -2,18 +2,23 @@
 package redistimeseries
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/retention"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -22,22 +27,92 @@ import (
 var sampleConfig string
 
 type RedisTimeSeries struct {
-	Address             string          `toml:"address"`
-	Username            config.Secret   `toml:"username"`
-	Password            config.Secret   `toml:"password"`
-	Database            int             `toml:"database"`
-	ConvertStringFields bool            `toml:"convert_string_fields"`
-	Timeout             config.Duration `toml:"timeout"`
-	Log                 telegraf.Logger `toml:"-"`
+	Address             string            `toml:"address"`
+	Username            config.Secret     `toml:"username"`
+	Password            config.Secret     `toml:"password"`
+	Database            int               `toml:"database"`
+	Cluster             bool              `toml:"cluster"`
+	ConvertStringFields bool              `toml:"convert_string_fields"`
+	KeyTemplate         string            `toml:"key_template"`
+	LabelTags           []string          `toml:"label_tags"`
+	Labels              map[string]string `toml:"labels"`
+	Retention           config.Duration   `toml:"retention"`
+	DuplicatePolicy     string            `toml:"duplicate_policy"`
+	Timeout             config.Duration   `toml:"timeout"`
+	Log                 telegraf.Logger   `toml:"-"`
+	retention.HintConfig
 	tls.ClientConfig
-	client *redis.Client
+
+	client      redis.UniversalClient
+	keyTmpl     *template.Template
+	labelFilter filter.Filter
+	created     map[string]bool
+	retentions  map[string]time.Duration
 }
 
-func (r *RedisTimeSeries) Connect() error {
+// keyData is passed to the key template providing access to the metric and
+// the currently processed field.
+type keyData struct {
+	telegraf.TemplateMetric
+	FieldKey string
+}
+
+// sample is a single value of a time series
+type sample struct {
+	key       string
+	timestamp int64
+	value     float64
+	labels    map[string]string
+	retention time.Duration
+}
+
+func (r *RedisTimeSeries) Init() error {
 	if r.Address == "" {
 		return errors.New("redis address must be specified")
 	}
 
+	if r.KeyTemplate == "" {
+		r.KeyTemplate = `{{.Name}}_{{.FieldKey}}`
+	}
+	tmpl, err := template.New("key").Parse(r.KeyTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing key_template failed: %w", err)
+	}
+	r.keyTmpl = tmpl
+
+	r.labelFilter, err = filter.Compile(r.LabelTags)
+	if err != nil {
+		return fmt.Errorf("creating label filter failed: %w", err)
+	}
+
+	r.DuplicatePolicy = strings.ToUpper(r.DuplicatePolicy)
+	switch r.DuplicatePolicy {
+	case "", "BLOCK", "FIRST", "LAST", "MIN", "MAX", "SUM":
+	default:
+		return fmt.Errorf("invalid duplicate policy %q", r.DuplicatePolicy)
+	}
+
+	if r.Cluster && r.Database != 0 {
+		return errors.New("database selection is not supported in cluster mode")
+	}
+
+	// The retention hints are given as durations
+	if err := r.HintConfig.Validate(); err != nil {
+		return err
+	}
+	r.retentions = make(map[string]time.Duration, len(r.RetentionHints))
+	for class, hint := range r.RetentionHints {
+		var d config.Duration
+		if err := d.UnmarshalText([]byte(hint)); err != nil {
+			return fmt.Errorf("invalid retention hint %q for %q: %w", hint, class, err)
+		}
+		r.retentions[hint] = time.Duration(d)
+	}
+
+	return nil
+}
+
+func (r *RedisTimeSeries) Connect() error {
 	username, err := r.Username.Get()
 	if err != nil {
 		return fmt.Errorf("getting username failed: %w", err)
@@ -50,12 +125,29 @@ func (r *RedisTimeSeries) Connect() error {
 	}
 	defer password.Destroy()
 
-	r.client = redis.NewClient(&redis.Options{
-		Addr:     r.Address,
-		Username: username.String(),
-		Password: password.String(),
-		DB:       r.Database,
-	})
+	tlsConfig, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	if r.Cluster {
+		r.client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     []string{r.Address},
+			Username:  username.String(),
+			Password:  password.String(),
+			TLSConfig: tlsConfig,
+		})
+	} else {
+		r.client = redis.NewClient(&redis.Options{
+			Addr:      r.Address,
+			Username:  username.String(),
+			Password:  password.String(),
+			DB:        r.Database,
+			TLSConfig: tlsConfig,
+		})
+	}
+	r.created = make(map[string]bool)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
 	defer cancel()
 	return r.client.Ping(ctx).Err()
@@ -72,45 +164,190 @@ func (r *RedisTimeSeries) Description() string {
 func (r *RedisTimeSeries) SampleConfig() string {
 	return sampleConfig
 }
+
 func (r *RedisTimeSeries) Write(metrics []telegraf.Metric) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
 	defer cancel()
 
+	samples := make([]sample, 0, len(metrics))
 	for _, m := range metrics {
-		for name, fv := range m.Fields() {
-			key := m.Name() + "_" + name
-
-			var value float64
-			switch v := fv.(type) {
-			case float64:
-				value = v
-			case string:
-				if !r.ConvertStringFields {
-					r.Log.Debugf("Dropping string field %q of metric %q", name, m.Name())
-					continue
-				}
-				var err error
-				value, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					r.Log.Debugf("Converting string field %q of metric %q failed: %v", name, m.Name(), err)
-					continue
-				}
-			default:
-				var err error
-				value, err = internal.ToFloat64(v)
-				if err != nil {
-					r.Log.Errorf("Converting field %q (%T) of metric %q failed: %v", name, v, m.Name(), err)
-					continue
-				}
+		labels := r.labels(m)
+		seriesRetention := r.seriesRetention(m)
+		for _, field := range m.FieldList() {
+			value, ok := r.convert(m.Name(), field)
+			if !ok {
+				continue
 			}
 
-			resp := r.client.TSAddWithArgs(ctx, key, m.Time().UnixMilli(), value, &redis.TSOptions{Labels: m.Tags()})
-			if err := resp.Err(); err != nil {
-				return fmt.Errorf("adding sample %q failed: %w", key, err)
+			key, err := r.key(m, field.Key)
+			if err != nil {
+				r.Log.Errorf("Generating key for field %q of metric %q failed: %v", field.Key, m.Name(), err)
+				continue
 			}
+
+			samples = append(samples, sample{
+				key:       key,
+				timestamp: m.Time().UnixMilli(),
+				value:     value,
+				labels:    labels,
+				retention: seriesRetention,
+			})
 		}
 	}
-	return nil
+	if len(samples) == 0 {
+		return nil
+	}
+
+	// The keys of a TS.MADD command might be located on different nodes in a
+	// cluster, so add the samples individually using a pipeline instead.
+	if r.Cluster {
+		return r.writePipelined(ctx, samples)
+	}
+	return r.writeBatch(ctx, samples)
+}
+
+func (r *RedisTimeSeries) writeBatch(ctx context.Context, samples []sample) error {
+	// TS.MADD does not create the keys, so create the series with the
+	// labels and policies first.
+	for _, s := range samples {
+		if r.created[s.key] {
+			continue
+		}
+		err := r.client.TSCreateWithArgs(ctx, s.key, r.options(s)).Err()
+		if err != nil && !strings.Contains(err.Error(), "key already exists") {
+			return fmt.Errorf("creating series %q failed: %w", s.key, err)
+		}
+		r.created[s.key] = true
+	}
+
+	args := make([]interface{}, 0, 1+3*len(samples))
+	args = append(args, "TS.MADD")
+	for _, s := range samples {
+		args = append(args, s.key, s.timestamp, s.value)
+	}
+	results, err := r.client.Do(ctx, args...).Slice()
+	if err != nil {
+		// The state of the series is unknown after a failure, so make sure
+		// they are created again on the next write.
+		for _, s := range samples {
+			delete(r.created, s.key)
+		}
+		return fmt.Errorf("adding samples failed: %w", err)
+	}
+
+	var errs []error
+	for i, result := range results {
+		if err, ok := result.(error); ok && i < len(samples) {
+			delete(r.created, samples[i].key)
+			errs = append(errs, fmt.Errorf("adding sample %q failed: %w", samples[i].key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *RedisTimeSeries) writePipelined(ctx context.Context, samples []sample) error {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(samples))
+	for _, s := range samples {
+		cmds = append(cmds, pipe.TSAddWithArgs(ctx, s.key, s.timestamp, s.value, r.options(s)))
+	}
+
+	// Errors of individual commands are checked below
+	if _, err := pipe.Exec(ctx); err != nil {
+		var rerr redis.Error
+		if !errors.As(err, &rerr) {
+			return fmt.Errorf("adding samples failed: %w", err)
+		}
+	}
+
+	var errs []error
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("adding sample %q failed: %w", samples[i].key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// options returns the options used when creating the series of the sample.
+// The retention of existing series is not modified.
+func (r *RedisTimeSeries) options(s sample) *redis.TSOptions {
+	return &redis.TSOptions{
+		Retention:       int(s.retention.Milliseconds()),
+		DuplicatePolicy: r.DuplicatePolicy,
+		Labels:          s.labels,
+	}
+}
+
+// seriesRetention returns the retention given by the retention hint of the
+// metric or the default retention
+func (r *RedisTimeSeries) seriesRetention(m telegraf.Metric) time.Duration {
+	if hint, found := r.HintConfig.Hint(m); found {
+		return r.retentions[hint]
+	}
+	return time.Duration(r.Retention)
+}
+
+func (r *RedisTimeSeries) key(raw telegraf.Metric, field string) (string, error) {
+	m := raw
+	if wm, ok := raw.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return "", fmt.Errorf("metric of type %T is not a template metric", raw)
+	}
+
+	var buf bytes.Buffer
+	if err := r.keyTmpl.Execute(&buf, &keyData{TemplateMetric: tm, FieldKey: field}); err != nil {
+		return "", err
+	}
+	if buf.Len() == 0 {
+		return "", errors.New("empty key")
+	}
+	return buf.String(), nil
+}
+
+func (r *RedisTimeSeries) labels(m telegraf.Metric) map[string]string {
+	labels := make(map[string]string, len(r.Labels)+len(m.TagList()))
+	for k, v := range r.Labels {
+		labels[k] = v
+	}
+	for _, tag := range m.TagList() {
+		if r.labelFilter != nil && !r.labelFilter.Match(tag.Key) {
+			continue
+		}
+		if r.ExcludeRetentionTag && tag.Key == r.RetentionTag {
+			continue
+		}
+		labels[tag.Key] = tag.Value
+	}
+	return labels
+}
+
+func (r *RedisTimeSeries) convert(name string, field *telegraf.Field) (float64, bool) {
+	switch v := field.Value.(type) {
+	case float64:
+		return v, true
+	case string:
+		if !r.ConvertStringFields {
+			r.Log.Debugf("Dropping string field %q of metric %q", field.Key, name)
+			return 0, false
+		}
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			r.Log.Debugf("Converting string field %q of metric %q failed: %v", field.Key, name, err)
+			return 0, false
+		}
+		return value, true
+	default:
+		value, err := internal.ToFloat64(v)
+		if err != nil {
+			r.Log.Errorf("Converting field %q (%T) of metric %q failed: %v", field.Key, v, name, err)
+			return 0, false
+		}
+		return value, true
+	}
 }
 
 func init() {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +17,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/retention"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -38,6 +41,7 @@ func TestConnectAndWriteIntegration(t *testing.T) {
 		ConvertStringFields: true,
 		Timeout:             config.Duration(10 * time.Second),
 	}
+	require.NoError(t, redis.Init())
 	// Verify that we can connect to the RedisTimeSeries server
 	require.NoError(t, redis.Connect())
 	// Verify that we can successfully write data to the RedisTimeSeries server
@@ -122,6 +126,7 @@ func TestCases(t *testing.T) {
 			plugin.Log = testutil.Logger{}
 
 			// Connect and write the metric(s)
+			require.NoError(t, plugin.Init())
 			require.NoError(t, plugin.Connect())
 			defer plugin.Close()
 
@@ -155,6 +160,7 @@ func getAllRecords(address string) []string {
 			for k, v := range lmap {
 				collection = append(collection, fmt.Sprintf("%v=%v", k, v))
 			}
+			sort.Strings(collection)
 			if len(collection) > 0 {
 				labels = " " + strings.Join(collection, " ")
 			}
@@ -168,3 +174,77 @@ func getAllRecords(address string) []string {
 
 	return records
 }
+
+func TestKeyAndLabels(t *testing.T) {
+	plugin := &RedisTimeSeries{
+		Address:     "127.0.0.1:6379",
+		KeyTemplate: `{{.Tag "location"}}:{{.Name}}:{{.FieldKey}}`,
+		LabelTags:   []string{"loc*"},
+		Labels:      map[string]string{"source": "telegraf"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric(
+		"weather",
+		map[string]string{"location": "somewhere", "sensor": "a"},
+		map[string]interface{}{"temperature": 23.1},
+		time.Unix(0, 0),
+	)
+
+	key, err := plugin.key(m, "temperature")
+	require.NoError(t, err)
+	require.Equal(t, "somewhere:weather:temperature", key)
+
+	expected := map[string]string{"location": "somewhere", "source": "telegraf"}
+	require.Equal(t, expected, plugin.labels(m))
+
+	// Tracking metrics need to be unwrapped for the template
+	tm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) {})
+	key, err = plugin.key(tm, "temperature")
+	require.NoError(t, err)
+	require.Equal(t, "somewhere:weather:temperature", key)
+}
+
+func TestInitInvalidDuplicatePolicy(t *testing.T) {
+	plugin := &RedisTimeSeries{
+		Address:         "127.0.0.1:6379",
+		DuplicatePolicy: "newest",
+		Log:             testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid duplicate policy")
+}
+
+func TestRetentionHints(t *testing.T) {
+	plugin := &RedisTimeSeries{
+		Address:   "127.0.0.1:6379",
+		Retention: config.Duration(30 * 24 * time.Hour),
+		HintConfig: retention.HintConfig{
+			RetentionTag:        "retention",
+			RetentionHints:      map[string]string{"debug": "7d", "slo": "400d"},
+			ExcludeRetentionTag: true,
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := testutil.MustMetric("cpu", map[string]string{"retention": "debug", "host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	require.Equal(t, 7*24*time.Hour, plugin.seriesRetention(m))
+	require.Equal(t, map[string]string{"host": "a"}, plugin.labels(m))
+
+	// Unmapped retention classes use the default retention
+	m = testutil.MustMetric("cpu", map[string]string{"retention": "other"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	require.Equal(t, 30*24*time.Hour, plugin.seriesRetention(m))
+}
+
+func TestInitInvalidRetentionHint(t *testing.T) {
+	plugin := &RedisTimeSeries{
+		Address: "127.0.0.1:6379",
+		HintConfig: retention.HintConfig{
+			RetentionTag:   "retention",
+			RetentionHints: map[string]string{"debug": "one week"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid retention hint "one week" for "debug"`)
+}
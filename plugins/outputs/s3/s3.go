@@ -0,0 +1,402 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package s3
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Minimum part size of multipart uploads allowed by S3
+const minPartSize = 5 * 1024 * 1024
+
+type uploader interface {
+	Upload(ctx context.Context, input *s3.PutObjectInput, opts ...func(*manager.Uploader)) (*manager.UploadOutput, error)
+}
+
+type S3 struct {
+	Bucket           string          `toml:"bucket"`
+	PrefixTemplate   string          `toml:"prefix_template"`
+	ObjectSuffix     string          `toml:"object_suffix"`
+	ContentType      string          `toml:"content_type"`
+	StorageClass     string          `toml:"storage_class"`
+	Compression      string          `toml:"compression"`
+	RotationInterval config.Duration `toml:"rotation_interval"`
+	RotationMaxSize  config.Size     `toml:"rotation_max_size"`
+	PartSize         config.Size     `toml:"multipart_part_size"`
+	UsePathStyle     bool            `toml:"use_path_style"`
+	Timeout          config.Duration `toml:"timeout"`
+	Log              telegraf.Logger `toml:"-"`
+	internalaws.CredentialConfig
+
+	uploader   uploader
+	prefixTmpl *template.Template
+	encoder    internal.ContentEncoder
+	serializer serializers.Serializer
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+
+	sync.Mutex
+	objects  map[string]*object
+	pending  map[telegraf.Metric]bool
+	uploaded map[telegraf.Metric]bool
+}
+
+// object is the buffered content of an object not yet uploaded
+type object struct {
+	content []byte
+	metrics []telegraf.Metric
+	created time.Time
+}
+
+func (*S3) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *S3) SetSerializer(serializer serializers.Serializer) {
+	s.serializer = serializer
+}
+
+func (s *S3) Init() error {
+	if s.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+
+	tmpl, err := template.New("prefix").Parse(s.PrefixTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing prefix_template failed: %w", err)
+	}
+	s.prefixTmpl = tmpl
+
+	switch s.Compression {
+	case "", "none":
+		s.Compression = "identity"
+	case "gzip":
+	default:
+		return fmt.Errorf("invalid compression %q", s.Compression)
+	}
+	if s.encoder, err = internal.NewContentEncoder(s.Compression); err != nil {
+		return err
+	}
+
+	if s.StorageClass != "" {
+		valid := false
+		for _, class := range types.StorageClass("").Values() {
+			if string(class) == s.StorageClass {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid storage class %q", s.StorageClass)
+		}
+	}
+
+	if s.RotationMaxSize <= 0 && s.RotationInterval <= 0 {
+		return errors.New("either 'rotation_max_size' or 'rotation_interval' must be set")
+	}
+	if s.PartSize < minPartSize {
+		s.PartSize = minPartSize
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = config.Duration(5 * time.Minute)
+	}
+
+	return nil
+}
+
+func (s *S3) Connect() error {
+	cfg, err := s.CredentialConfig.Credentials()
+	if err != nil {
+		return fmt.Errorf("loading credentials failed: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if s.EndpointURL != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(s.EndpointURL)
+		}
+		o.UsePathStyle = s.UsePathStyle
+	})
+	s.uploader = manager.NewUploader(client, func(u *manager.Uploader) {
+		u.PartSize = int64(s.PartSize)
+	})
+	s.objects = make(map[string]*object)
+	s.pending = make(map[telegraf.Metric]bool)
+	s.uploaded = make(map[telegraf.Metric]bool)
+
+	// Objects might be due for rotation without any further write, e.g. if
+	// the metrics are kept in the output buffer until being uploaded
+	if s.RotationInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.rotate(ctx)
+		}()
+	}
+
+	return nil
+}
+
+// Close uploads all remaining objects
+func (s *S3) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+		s.wg.Wait()
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	var errs []error
+	for prefix, obj := range s.objects {
+		if err := s.uploadObject(prefix, obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rotate periodically uploads the objects reaching the rotation interval
+func (s *S3) rotate(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Lock()
+			for prefix, obj := range s.objects {
+				if !s.due(obj, now, 0) {
+					continue
+				}
+				if err := s.uploadObject(prefix, obj); err != nil {
+					s.Log.Errorf("Rotating object failed: %v", err)
+				}
+			}
+			s.Unlock()
+		}
+	}
+}
+
+// Write adds the metrics to the buffered objects. The metrics are kept in
+// the output buffer and are only acknowledged once the object containing them
+// is uploaded, so they are not lost if Telegraf stops before the upload.
+func (s *S3) Write(metrics []telegraf.Metric) error {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+
+	var prefixes []string
+	var reject []int
+	var added int
+	data := make(map[string]*object)
+	for i, m := range metrics {
+		if s.pending[m] || s.uploaded[m] {
+			continue
+		}
+
+		buf, err := s.serializer.Serialize(m)
+		if err != nil {
+			s.Log.Debugf("Could not serialize metric: %v", err)
+			reject = append(reject, i)
+			continue
+		}
+		prefix, err := s.prefix(m)
+		if err != nil {
+			s.Log.Errorf("Generating prefix for metric %q failed: %v", m.Name(), err)
+			reject = append(reject, i)
+			continue
+		}
+		obj, found := data[prefix]
+		if !found {
+			obj = &object{}
+			data[prefix] = obj
+			prefixes = append(prefixes, prefix)
+		}
+		obj.content = append(obj.content, buf...)
+		obj.metrics = append(obj.metrics, m)
+		added++
+	}
+
+	// A batch without new metrics but not containing all buffered metrics
+	// means the output buffer is blocked by the metrics waiting for upload,
+	// so upload the objects regardless of their rotation settings.
+	blocked := added == 0 && len(reject) == 0 && len(s.pending) > len(metrics)
+
+	// Upload the objects due for rotation before adding the metrics of this
+	// write, so uploaded objects never contain metrics of a failed write.
+	// This avoids duplicate metrics when the write is retried.
+	var uploadErr error
+	for prefix, obj := range s.objects {
+		var pending int
+		if d, found := data[prefix]; found {
+			pending = len(d.content)
+		}
+		if !blocked && !s.due(obj, now, pending) {
+			continue
+		}
+		if err := s.uploadObject(prefix, obj); err != nil {
+			uploadErr = err
+			break
+		}
+	}
+
+	// Only add the metrics after all uploads succeeded
+	if uploadErr == nil {
+		for _, prefix := range prefixes {
+			obj, found := s.objects[prefix]
+			if !found {
+				obj = &object{created: now}
+				s.objects[prefix] = obj
+			}
+			obj.content = append(obj.content, data[prefix].content...)
+			obj.metrics = append(obj.metrics, data[prefix].metrics...)
+			for _, m := range data[prefix].metrics {
+				s.pending[m] = true
+			}
+		}
+	}
+
+	// Acknowledge the metrics of uploaded objects and keep all others
+	accept := make([]int, 0, len(metrics))
+	for i, m := range metrics {
+		if s.uploaded[m] {
+			accept = append(accept, i)
+			delete(s.uploaded, m)
+		}
+	}
+	if uploadErr == nil && len(accept) == len(metrics) {
+		return nil
+	}
+	return &internal.PartialWriteError{
+		Err:           uploadErr,
+		MetricsAccept: accept,
+		MetricsReject: reject,
+	}
+}
+
+// due checks if the object must be uploaded before adding the given number
+// of bytes
+func (s *S3) due(obj *object, now time.Time, pending int) bool {
+	if s.RotationMaxSize > 0 {
+		size := int64(len(obj.content))
+		if size >= int64(s.RotationMaxSize) || pending > 0 && size+int64(pending) > int64(s.RotationMaxSize) {
+			return true
+		}
+	}
+	return s.RotationInterval > 0 && now.Sub(obj.created) >= time.Duration(s.RotationInterval)
+}
+
+func (s *S3) upload(prefix string, obj *object) error {
+	if len(obj.content) == 0 {
+		return nil
+	}
+
+	body, err := s.encoder.Encode(obj.content)
+	if err != nil {
+		return fmt.Errorf("compressing object failed: %w", err)
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return fmt.Errorf("generating object name failed: %w", err)
+	}
+	key := fmt.Sprintf("%d-%s%s", obj.created.Unix(), id.String(), s.ObjectSuffix)
+	if s.Compression == "gzip" {
+		key += ".gz"
+	}
+	if prefix != "" {
+		key = prefix + "/" + key
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(body),
+	}
+	if s.ContentType != "" {
+		input.ContentType = aws.String(s.ContentType)
+	}
+	if s.StorageClass != "" {
+		input.StorageClass = types.StorageClass(s.StorageClass)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+	if _, err := s.uploader.Upload(ctx, input); err != nil {
+		return fmt.Errorf("uploading %q failed: %w", key, err)
+	}
+	s.Log.Debugf("Uploaded %q with %d bytes", key, len(body))
+
+	return nil
+}
+
+// uploadObject uploads and removes the buffered object and marks its metrics
+// as uploaded to acknowledge them with the next write
+func (s *S3) uploadObject(prefix string, obj *object) error {
+	if err := s.upload(prefix, obj); err != nil {
+		return err
+	}
+	for _, m := range obj.metrics {
+		delete(s.pending, m)
+		s.uploaded[m] = true
+	}
+	delete(s.objects, prefix)
+	return nil
+}
+
+func (s *S3) prefix(raw telegraf.Metric) (string, error) {
+	m := raw
+	if wm, ok := raw.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return "", fmt.Errorf("metric of type %T is not a template metric", raw)
+	}
+
+	var buf bytes.Buffer
+	if err := s.prefixTmpl.Execute(&buf, tm); err != nil {
+		return "", err
+	}
+	return strings.Trim(buf.String(), "/"), nil
+}
+
+func init() {
+	outputs.Add("s3", func() telegraf.Output {
+		return &S3{
+			PrefixTemplate:   `telegraf/dt={{ .Time.UTC.Format "2006-01-02" }}`,
+			RotationInterval: config.Duration(5 * time.Minute),
+			RotationMaxSize:  config.Size(64 * 1024 * 1024),
+			PartSize:         config.Size(minPartSize),
+			Timeout:          config.Duration(5 * time.Minute),
+		}
+	})
+}
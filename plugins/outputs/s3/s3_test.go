@@ -0,0 +1,340 @@
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type mockUploader struct {
+	err     error
+	objects map[string][]byte
+}
+
+func (u *mockUploader) Upload(_ context.Context, input *s3.PutObjectInput, _ ...func(*manager.Uploader)) (*manager.UploadOutput, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	body, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	if u.objects == nil {
+		u.objects = make(map[string][]byte)
+	}
+	u.objects[*input.Bucket+"/"+*input.Key] = body
+	return &manager.UploadOutput{}, nil
+}
+
+func (u *mockUploader) keys() []string {
+	keys := make([]string, 0, len(u.objects))
+	for k := range u.objects {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func newPlugin(t *testing.T, plugin *S3) *mockUploader {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	plugin.Log = testutil.Logger{}
+	require.NoError(t, plugin.Init())
+
+	u := &mockUploader{}
+	plugin.uploader = u
+	plugin.objects = make(map[string]*object)
+	plugin.pending = make(map[telegraf.Metric]bool)
+	plugin.uploaded = make(map[telegraf.Metric]bool)
+	return u
+}
+
+// write writes the metrics and returns the indices of the acknowledged
+// metrics as well as the write error
+func write(t *testing.T, plugin *S3, metrics []telegraf.Metric) ([]int, error) {
+	err := plugin.Write(metrics)
+	if err == nil {
+		accept := make([]int, 0, len(metrics))
+		for i := range metrics {
+			accept = append(accept, i)
+		}
+		return accept, nil
+	}
+	var partErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &partErr)
+	return partErr.MetricsAccept, partErr.Err
+}
+
+func newMetric(name string, value float64) telegraf.Metric {
+	return testutil.MustMetric(name, map[string]string{}, map[string]interface{}{"value": value}, time.Unix(0, 0))
+}
+
+func TestWritePrefixTemplate(t *testing.T) {
+	plugin := &S3{
+		Bucket:          "telegraf",
+		PrefixTemplate:  `metrics/dt={{ .Time.UTC.Format "2006-01-02" }}/host={{ .Tag "host" }}`,
+		ObjectSuffix:    ".influx",
+		RotationMaxSize: config.Size(1024 * 1024),
+	}
+	u := newPlugin(t, plugin)
+
+	// Tracking metrics need to be unwrapped for the template
+	tm, _ := metric.WithTracking(
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(1704067200, 0)),
+		func(telegraf.DeliveryInfo) {},
+	)
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1704067200, 0)),
+		tm,
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3.0}, time.Unix(1704067201, 0)),
+	}
+	accept, err := write(t, plugin, metrics)
+	require.NoError(t, err)
+	require.Empty(t, accept)
+	require.Empty(t, u.objects)
+
+	// Buffered objects are uploaded on close
+	require.NoError(t, plugin.Close())
+	require.Len(t, u.objects, 2)
+	for key, body := range u.objects {
+		require.True(t, strings.HasSuffix(key, ".influx"), key)
+		switch {
+		case strings.HasPrefix(key, "telegraf/metrics/dt=2024-01-01/host=a/"):
+			require.Equal(t, "cpu,host=a value=1 1704067200000000000\ncpu,host=a value=3 1704067201000000000\n", string(body))
+		case strings.HasPrefix(key, "telegraf/metrics/dt=2024-01-01/host=b/"):
+			require.Equal(t, "cpu,host=b value=2 1704067200000000000\n", string(body))
+		default:
+			require.Failf(t, "unexpected object", "key %q", key)
+		}
+	}
+}
+
+func TestRotationMaxSize(t *testing.T) {
+	plugin := &S3{
+		Bucket:          "telegraf",
+		RotationMaxSize: config.Size(64),
+	}
+	u := newPlugin(t, plugin)
+
+	first := newMetric("cpu", 1)
+	accept, err := write(t, plugin, []telegraf.Metric{first})
+	require.NoError(t, err)
+	require.Empty(t, accept)
+	require.Empty(t, u.objects)
+
+	// The buffered object is uploaded before exceeding the maximum size and
+	// its metrics are acknowledged
+	metrics := []telegraf.Metric{first, newMetric("cpu", 2), newMetric("cpu", 2), newMetric("cpu", 2), newMetric("cpu", 2)}
+	accept, err = write(t, plugin, metrics)
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, accept)
+	require.Len(t, u.objects, 1)
+	for _, body := range u.objects {
+		require.Equal(t, "cpu value=1 0\n", string(body))
+	}
+	require.Len(t, plugin.objects, 1)
+	require.Equal(t, strings.Repeat("cpu value=2 0\n", 4), string(plugin.objects[""].content))
+}
+
+func TestRotationInterval(t *testing.T) {
+	plugin := &S3{
+		Bucket:           "telegraf",
+		PrefixTemplate:   "{{ .Name }}",
+		RotationInterval: config.Duration(time.Minute),
+	}
+	u := newPlugin(t, plugin)
+
+	m := newMetric("cpu", 1)
+	accept, err := write(t, plugin, []telegraf.Metric{m})
+	require.NoError(t, err)
+	require.Empty(t, accept)
+	require.Empty(t, u.objects)
+
+	// Age the object so it is uploaded with the next write of another prefix
+	plugin.objects["cpu"].created = time.Now().Add(-2 * time.Minute)
+	accept, err = write(t, plugin, []telegraf.Metric{m, newMetric("mem", 1)})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, accept)
+	require.Len(t, u.objects, 1)
+	require.True(t, strings.HasPrefix(u.keys()[0], "telegraf/cpu/"))
+	require.Contains(t, plugin.objects, "mem")
+}
+
+func TestRotationTimer(t *testing.T) {
+	plugin := &S3{
+		Bucket:           "telegraf",
+		RotationInterval: config.Duration(time.Minute),
+	}
+	u := newPlugin(t, plugin)
+
+	m := newMetric("cpu", 1)
+	_, err := write(t, plugin, []telegraf.Metric{m})
+	require.NoError(t, err)
+
+	// Objects are rotated without any further write
+	plugin.Lock()
+	plugin.objects[""].created = time.Now().Add(-2 * time.Minute)
+	plugin.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		plugin.rotate(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		plugin.Lock()
+		defer plugin.Unlock()
+		return len(u.objects) == 1
+	}, 5*time.Second, 100*time.Millisecond)
+	cancel()
+	<-done
+
+	// The metrics are acknowledged with the next write
+	accept, err := write(t, plugin, []telegraf.Metric{m})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, accept)
+}
+
+func TestBlockedBuffer(t *testing.T) {
+	plugin := &S3{
+		Bucket:          "telegraf",
+		RotationMaxSize: config.Size(1024 * 1024),
+	}
+	u := newPlugin(t, plugin)
+
+	metrics := []telegraf.Metric{newMetric("cpu", 1), newMetric("cpu", 2)}
+	_, err := write(t, plugin, metrics)
+	require.NoError(t, err)
+	require.Empty(t, u.objects)
+
+	// A batch only containing a part of the waiting metrics cannot make any
+	// progress, so the object is uploaded
+	accept, err := write(t, plugin, metrics[:1])
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, accept)
+	require.Len(t, u.objects, 1)
+	require.Empty(t, plugin.objects)
+}
+
+func TestUploadFailureKeepsBuffer(t *testing.T) {
+	plugin := &S3{
+		Bucket:          "telegraf",
+		RotationMaxSize: config.Size(16),
+	}
+	u := newPlugin(t, plugin)
+
+	first := newMetric("cpu", 1)
+	_, err := write(t, plugin, []telegraf.Metric{first})
+	require.NoError(t, err)
+
+	u.err = errors.New("connection refused")
+	metrics := []telegraf.Metric{first, newMetric("cpu", 2), newMetric("cpu", 2)}
+	accept, err := write(t, plugin, metrics)
+	require.ErrorContains(t, err, "connection refused")
+	require.Empty(t, accept)
+	require.Equal(t, "cpu value=1 0\n", string(plugin.objects[""].content))
+
+	// The retried write must not contain duplicates
+	u.err = nil
+	accept, err = write(t, plugin, metrics)
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, accept)
+	require.Len(t, u.objects, 1)
+	for _, body := range u.objects {
+		require.Equal(t, "cpu value=1 0\n", string(body))
+	}
+	require.Equal(t, strings.Repeat("cpu value=2 0\n", 2), string(plugin.objects[""].content))
+}
+
+func TestCloseFailureKeepsObjects(t *testing.T) {
+	plugin := &S3{
+		Bucket:           "telegraf",
+		RotationInterval: config.Duration(time.Minute),
+	}
+	u := newPlugin(t, plugin)
+
+	_, err := write(t, plugin, []telegraf.Metric{newMetric("cpu", 1)})
+	require.NoError(t, err)
+
+	u.err = errors.New("connection refused")
+	require.ErrorContains(t, plugin.Close(), "connection refused")
+	require.Contains(t, plugin.objects, "")
+
+	u.err = nil
+	require.NoError(t, plugin.Close())
+	require.Len(t, u.objects, 1)
+	require.Empty(t, plugin.objects)
+}
+
+func TestCompression(t *testing.T) {
+	plugin := &S3{
+		Bucket:          "telegraf",
+		Compression:     "gzip",
+		RotationMaxSize: config.Size(1),
+	}
+	u := newPlugin(t, plugin)
+
+	_, err := write(t, plugin, []telegraf.Metric{newMetric("cpu", 1)})
+	require.NoError(t, err)
+	require.NoError(t, plugin.Close())
+	require.Len(t, u.objects, 1)
+	for key, body := range u.objects {
+		require.True(t, strings.HasSuffix(key, ".gz"), key)
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "cpu value=1 0\n", string(content))
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *S3
+		expected string
+	}{
+		{
+			name:     "missing bucket",
+			plugin:   &S3{},
+			expected: "bucket is required",
+		},
+		{
+			name:     "invalid compression",
+			plugin:   &S3{Bucket: "telegraf", Compression: "lz4", RotationMaxSize: 1},
+			expected: "invalid compression",
+		},
+		{
+			name:     "invalid storage class",
+			plugin:   &S3{Bucket: "telegraf", StorageClass: "COLD", RotationMaxSize: 1},
+			expected: "invalid storage class",
+		},
+		{
+			name:     "no rotation",
+			plugin:   &S3{Bucket: "telegraf"},
+			expected: "must be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,487 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package splunk_hec
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	metricEndpoint = "/services/collector"
+	eventEndpoint  = "/services/collector/event"
+	ackEndpoint    = "/services/collector/ack"
+)
+
+type SplunkHEC struct {
+	URL             string          `toml:"url"`
+	Token           config.Secret   `toml:"token"`
+	Mode            string          `toml:"mode"`
+	HostTag         string          `toml:"host_tag"`
+	Index           string          `toml:"index"`
+	Source          string          `toml:"source"`
+	SourceType      string          `toml:"sourcetype"`
+	ContentEncoding string          `toml:"content_encoding"`
+	Channel         string          `toml:"channel"`
+	UseAck          bool            `toml:"use_ack"`
+	AckTimeout      config.Duration `toml:"ack_timeout"`
+	AckPollInterval config.Duration `toml:"ack_poll_interval"`
+	MaxBatchSize    int             `toml:"max_batch_size"`
+	Log             telegraf.Logger `toml:"-"`
+	httpconfig.HTTPClientConfig
+
+	client         *http.Client
+	encoder        internal.ContentEncoder
+	endpoint       string
+	ackEndpoint    string
+	indexTmpl      *template.Template
+	sourceTmpl     *template.Template
+	sourceTypeTmpl *template.Template
+}
+
+// event is a single entry of the HEC request body
+type event struct {
+	Time       float64                `json:"time"`
+	Host       string                 `json:"host,omitempty"`
+	Index      string                 `json:"index,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	SourceType string                 `json:"sourcetype,omitempty"`
+	Event      interface{}            `json:"event"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+}
+
+// metricEvent is the payload of events sent in 'event' mode
+type metricEvent struct {
+	Name   string                 `json:"name"`
+	Tags   map[string]string      `json:"tags,omitempty"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// chunk is a request of events for the metrics with the given indices
+type chunk struct {
+	body    []byte
+	indices []int
+	ackID   *int64
+}
+
+type response struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	AckID *int64 `json:"ackId"`
+}
+
+func (*SplunkHEC) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SplunkHEC) Init() error {
+	if s.URL == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+
+	base := strings.TrimSuffix(u.Path, "/")
+	switch s.Mode {
+	case "", "metric":
+		s.Mode = "metric"
+		u.Path = base + metricEndpoint
+	case "event":
+		u.Path = base + eventEndpoint
+	default:
+		return fmt.Errorf("invalid mode %q", s.Mode)
+	}
+	s.endpoint = u.String()
+
+	if s.indexTmpl, err = parseTemplate("index", s.Index); err != nil {
+		return err
+	}
+	if s.sourceTmpl, err = parseTemplate("source", s.Source); err != nil {
+		return err
+	}
+	if s.sourceTypeTmpl, err = parseTemplate("sourcetype", s.SourceType); err != nil {
+		return err
+	}
+
+	switch s.ContentEncoding {
+	case "", "identity":
+		s.ContentEncoding = "identity"
+	case "gzip":
+	default:
+		return fmt.Errorf("invalid content encoding %q", s.ContentEncoding)
+	}
+	if s.encoder, err = internal.NewContentEncoder(s.ContentEncoding); err != nil {
+		return err
+	}
+
+	// Acknowledgements are tracked per channel, so we need one
+	if s.Channel == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return fmt.Errorf("generating channel failed: %w", err)
+		}
+		s.Channel = id.String()
+	}
+	u.Path = base + ackEndpoint
+	u.RawQuery = url.Values{"channel": {s.Channel}}.Encode()
+	s.ackEndpoint = u.String()
+	if s.UseAck && s.AckPollInterval <= 0 {
+		return errors.New("ack_poll_interval must be positive")
+	}
+
+	return nil
+}
+
+func (s *SplunkHEC) Connect() error {
+	client, err := s.HTTPClientConfig.CreateClient(context.Background(), s.Log)
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+func (s *SplunkHEC) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (s *SplunkHEC) Write(metrics []telegraf.Metric) error {
+	// Metrics without an event are handled as written to not retry them
+	accept := make([]int, 0, len(metrics))
+	var chunks []*chunk
+	current := &chunk{}
+	for i, m := range metrics {
+		e, err := s.event(m)
+		if err != nil {
+			s.Log.Errorf("Could not create event for metric %q: %v", m.Name(), err)
+			accept = append(accept, i)
+			continue
+		}
+		if e == nil {
+			accept = append(accept, i)
+			continue
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			s.Log.Errorf("Could not serialize metric %q: %v", m.Name(), err)
+			accept = append(accept, i)
+			continue
+		}
+		current.body = append(current.body, data...)
+		current.indices = append(current.indices, i)
+		if s.MaxBatchSize > 0 && len(current.indices) >= s.MaxBatchSize {
+			chunks = append(chunks, current)
+			current = &chunk{}
+		}
+	}
+	if len(current.indices) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	// Send the chunks and stop at the first failure keeping the remaining
+	// metrics for the next write
+	var sendErr error
+	sent := make([]*chunk, 0, len(chunks))
+	for _, c := range chunks {
+		ackID, err := s.send(c.body)
+		if err != nil {
+			sendErr = err
+			break
+		}
+		c.ackID = ackID
+		sent = append(sent, c)
+	}
+
+	// Only chunks acknowledged by the server are considered written if
+	// acknowledgements are enabled
+	pending := make(map[int64]bool)
+	if s.UseAck {
+		for _, c := range sent {
+			if c.ackID != nil {
+				pending[*c.ackID] = true
+			}
+		}
+	}
+	var ackErr error
+	if len(pending) > 0 {
+		ackErr = s.waitForAcks(pending)
+	}
+	for _, c := range sent {
+		if c.ackID != nil && pending[*c.ackID] {
+			continue
+		}
+		accept = append(accept, c.indices...)
+	}
+
+	err := errors.Join(sendErr, ackErr)
+	if err == nil {
+		return nil
+	}
+	if len(accept) == 0 {
+		return err
+	}
+	return &internal.PartialWriteError{
+		Err:           err,
+		MetricsAccept: accept,
+	}
+}
+
+// event converts the metric into a HEC event. In 'metric' mode all fields
+// are sent as a single multi-metric event, in 'event' mode the whole metric
+// is sent as the event payload.
+func (s *SplunkHEC) event(m telegraf.Metric) (*event, error) {
+	e := &event{
+		Time: float64(m.Time().UnixNano()) / float64(time.Second),
+	}
+
+	var err error
+	if e.Index, err = executeTemplate(s.indexTmpl, m); err != nil {
+		return nil, fmt.Errorf("executing index template failed: %w", err)
+	}
+	if e.Source, err = executeTemplate(s.sourceTmpl, m); err != nil {
+		return nil, fmt.Errorf("executing source template failed: %w", err)
+	}
+	if e.SourceType, err = executeTemplate(s.sourceTypeTmpl, m); err != nil {
+		return nil, fmt.Errorf("executing sourcetype template failed: %w", err)
+	}
+
+	tags := m.Tags()
+	if s.HostTag != "" {
+		e.Host = tags[s.HostTag]
+		delete(tags, s.HostTag)
+	}
+
+	if s.Mode == "event" {
+		e.Event = metricEvent{
+			Name:   m.Name(),
+			Tags:   tags,
+			Fields: m.Fields(),
+		}
+		return e, nil
+	}
+
+	e.Event = "metric"
+	e.Fields = make(map[string]interface{}, len(tags)+len(m.FieldList()))
+	for k, v := range tags {
+		e.Fields[k] = v
+	}
+	var found bool
+	for _, field := range m.FieldList() {
+		value, ok := metricValue(field.Value)
+		if !ok {
+			s.Log.Debugf("Skipping field %q of metric %q with unsupported value %v", field.Key, m.Name(), field.Value)
+			continue
+		}
+		e.Fields["metric_name:"+m.Name()+"."+field.Key] = value
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	return e, nil
+}
+
+func (s *SplunkHEC) send(body []byte) (*int64, error) {
+	payload, err := s.encoder.Encode(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request failed: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setHeaders(req); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var r response
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+	if err := json.Unmarshal(data, &r); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sending events failed with status %d: %s (code %d)", resp.StatusCode, r.Text, r.Code)
+	}
+
+	return r.AckID, nil
+}
+
+// waitForAcks polls the acknowledgement endpoint until all pending requests
+// are indexed or the timeout is reached. Acknowledged requests are removed
+// from the given pending requests.
+func (s *SplunkHEC) waitForAcks(pending map[int64]bool) error {
+	deadline := time.Now().Add(time.Duration(s.AckTimeout))
+	for {
+		if err := s.pollAcks(pending); err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for acknowledgement of %d request(s)", len(pending))
+		}
+		time.Sleep(time.Duration(s.AckPollInterval))
+	}
+}
+
+func (s *SplunkHEC) pollAcks(pending map[int64]bool) error {
+	ids := make([]int64, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+	body, err := json.Marshal(map[string][]int64{"acks": ids})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.ackEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if err := s.setHeaders(req); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("querying acknowledgements failed with status %d: %s", resp.StatusCode, data)
+	}
+
+	var r struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("decoding acknowledgements failed: %w", err)
+	}
+	for k, acked := range r.Acks {
+		id, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || !acked {
+			continue
+		}
+		delete(pending, id)
+	}
+	return nil
+}
+
+func (s *SplunkHEC) setHeaders(req *http.Request) error {
+	token, err := s.Token.Get()
+	if err != nil {
+		return fmt.Errorf("getting token failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+token.String())
+	token.Destroy()
+
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("X-Splunk-Request-Channel", s.Channel)
+	return nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template failed: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func executeTemplate(tmpl *template.Template, raw telegraf.Metric) (string, error) {
+	if tmpl == nil {
+		return "", nil
+	}
+
+	m := raw
+	if wm, ok := raw.(telegraf.UnwrappableMetric); ok {
+		m = wm.Unwrap()
+	}
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return "", fmt.Errorf("metric of type %T is not a template metric", raw)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, tm); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// metricValue returns the numeric value of the field as Splunk metrics only
+// support numbers
+func metricValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case int64, uint64:
+		return v, true
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return nil, false
+}
+
+func init() {
+	outputs.Add("splunk_hec", func() telegraf.Output {
+		return &SplunkHEC{
+			Mode:            "metric",
+			HostTag:         "host",
+			ContentEncoding: "gzip",
+			AckTimeout:      config.Duration(time.Minute),
+			AckPollInterval: config.Duration(time.Second),
+		}
+	})
+}
@@ -0,0 +1,307 @@
+package splunk_hec
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type hecServer struct {
+	*httptest.Server
+
+	sync.Mutex
+	requests [][]map[string]interface{}
+	headers  []http.Header
+	nextAck  int64
+	ackPolls int
+
+	// maxRequests is the number of accepted event requests, further requests
+	// are rejected if set
+	maxRequests int
+}
+
+func newHECServer(t *testing.T, ackAfter int) *hecServer {
+	s := &hecServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+
+		if r.Header.Get("Authorization") != "Splunk secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"text":"Invalid token","code":4}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/services/collector", "/services/collector/event":
+			if s.maxRequests > 0 && len(s.requests) >= s.maxRequests {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"text":"Server is busy","code":9}`))
+				return
+			}
+			var body io.Reader = r.Body
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				gz, err := gzip.NewReader(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				body = gz
+			}
+			var events []map[string]interface{}
+			decoder := json.NewDecoder(bufio.NewReader(body))
+			for decoder.More() {
+				var e map[string]interface{}
+				if err := decoder.Decode(&e); err != nil {
+					t.Error(err)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				e["endpoint"] = r.URL.Path
+				events = append(events, e)
+			}
+			s.requests = append(s.requests, events)
+			s.headers = append(s.headers, r.Header)
+			_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":` + strconv.FormatInt(s.nextAck, 10) + `}`))
+			s.nextAck++
+		case "/services/collector/ack":
+			if r.URL.Query().Get("channel") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.ackPolls++
+			var req struct {
+				Acks []int64 `json:"acks"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			acks := make(map[string]bool, len(req.Acks))
+			for _, id := range req.Acks {
+				acks[strconv.FormatInt(id, 10)] = ackAfter >= 0 && s.ackPolls > ackAfter
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"acks": acks})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newPlugin(t *testing.T, plugin *SplunkHEC) {
+	plugin.Token = config.NewSecret([]byte("secret"))
+	plugin.Log = testutil.Logger{}
+	if plugin.AckPollInterval == 0 {
+		plugin.AckPollInterval = config.Duration(10 * time.Millisecond)
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	t.Cleanup(func() { require.NoError(t, plugin.Close()) })
+}
+
+func TestWriteMetrics(t *testing.T) {
+	server := newHECServer(t, 0)
+	plugin := &SplunkHEC{
+		URL:             server.URL,
+		HostTag:         "host",
+		Index:           `{{ .Tag "env" }}_metrics`,
+		SourceType:      "telegraf:{{ .Name }}",
+		ContentEncoding: "gzip",
+	}
+	newPlugin(t, plugin)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a", "env": "prod"},
+			map[string]interface{}{"usage": 42.5, "online": true, "state": "ok"},
+			time.Unix(1700000000, 500000000),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"env": "dev"},
+			map[string]interface{}{"state": "ok"},
+			time.Unix(1700000000, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.Len(t, server.requests, 1)
+	require.Equal(t, "gzip", server.headers[0].Get("Content-Encoding"))
+	require.NotEmpty(t, server.headers[0].Get("X-Splunk-Request-Channel"))
+
+	// The metric without numeric fields is skipped
+	require.Equal(t, []map[string]interface{}{
+		{
+			"endpoint":   "/services/collector",
+			"time":       1700000000.5,
+			"host":       "a",
+			"index":      "prod_metrics",
+			"sourcetype": "telegraf:cpu",
+			"event":      "metric",
+			"fields": map[string]interface{}{
+				"env":                    "prod",
+				"metric_name:cpu.usage":  42.5,
+				"metric_name:cpu.online": 1.0,
+			},
+		},
+	}, server.requests[0])
+}
+
+func TestWriteEvents(t *testing.T) {
+	server := newHECServer(t, 0)
+	plugin := &SplunkHEC{
+		URL:          server.URL,
+		Mode:         "event",
+		Source:       "telegraf",
+		MaxBatchSize: 1,
+	}
+	newPlugin(t, plugin)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("syslog", map[string]string{"host": "a"}, map[string]interface{}{"message": "hello"}, time.Unix(1700000000, 0)),
+		testutil.MustMetric("syslog", map[string]string{"host": "b"}, map[string]interface{}{"message": "world"}, time.Unix(1700000001, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Each request contains a single event due to the batch size
+	require.Len(t, server.requests, 2)
+	require.Equal(t, []map[string]interface{}{
+		{
+			"endpoint": "/services/collector/event",
+			"time":     1700000000.0,
+			"source":   "telegraf",
+			"event": map[string]interface{}{
+				"name":   "syslog",
+				"tags":   map[string]interface{}{"host": "a"},
+				"fields": map[string]interface{}{"message": "hello"},
+			},
+		},
+	}, server.requests[0])
+}
+
+func TestWriteAck(t *testing.T) {
+	server := newHECServer(t, 2)
+	plugin := &SplunkHEC{
+		URL:        server.URL,
+		UseAck:     true,
+		AckTimeout: config.Duration(5 * time.Second),
+	}
+	newPlugin(t, plugin)
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Equal(t, 3, server.ackPolls)
+}
+
+func TestWriteAckTimeout(t *testing.T) {
+	server := newHECServer(t, -1)
+	plugin := &SplunkHEC{
+		URL:        server.URL,
+		UseAck:     true,
+		AckTimeout: config.Duration(50 * time.Millisecond),
+	}
+	newPlugin(t, plugin)
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0))
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "timeout waiting for acknowledgement of 1 request(s)")
+}
+
+func TestWriteError(t *testing.T) {
+	server := newHECServer(t, 0)
+	plugin := &SplunkHEC{
+		URL: server.URL,
+	}
+	newPlugin(t, plugin)
+	plugin.Token = config.NewSecret([]byte("invalid"))
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0))
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{m}), "status 401: Invalid token (code 4)")
+}
+
+func TestWritePartial(t *testing.T) {
+	server := newHECServer(t, 0)
+	server.maxRequests = 1
+	plugin := &SplunkHEC{
+		URL:          server.URL,
+		MaxBatchSize: 2,
+	}
+	newPlugin(t, plugin)
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := 0; i < 4; i++ {
+		metrics = append(metrics, testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"usage": float64(i)}, time.Unix(0, 0)))
+	}
+	// Metrics without numeric fields are skipped and thus written
+	metrics = append(metrics, testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"state": "idle"}, time.Unix(0, 0)))
+
+	// Only the metrics of the accepted request are written
+	err := plugin.Write(metrics)
+	require.ErrorContains(t, err, "status 503: Server is busy (code 9)")
+	var partErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &partErr)
+	require.ElementsMatch(t, []int{0, 1, 4}, partErr.MetricsAccept)
+	require.Empty(t, partErr.MetricsReject)
+}
+
+func TestTemplateTrackingMetric(t *testing.T) {
+	tmpl, err := parseTemplate("index", `metrics_{{.Tag "team"}}`)
+	require.NoError(t, err)
+
+	m := testutil.MustMetric("cpu", map[string]string{"team": "storage"}, map[string]interface{}{"usage": 1.0}, time.Unix(0, 0))
+	tm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) {})
+	index, err := executeTemplate(tmpl, tm)
+	require.NoError(t, err)
+	require.Equal(t, "metrics_storage", index)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SplunkHEC
+		expected string
+	}{
+		{
+			name:     "missing url",
+			plugin:   &SplunkHEC{},
+			expected: "url is required",
+		},
+		{
+			name:     "invalid mode",
+			plugin:   &SplunkHEC{URL: "http://localhost:8088", Mode: "raw"},
+			expected: "invalid mode",
+		},
+		{
+			name:     "invalid template",
+			plugin:   &SplunkHEC{URL: "http://localhost:8088", Index: "{{ .Name"},
+			expected: "parsing index template failed",
+		},
+		{
+			name:     "invalid content encoding",
+			plugin:   &SplunkHEC{URL: "http://localhost:8088", ContentEncoding: "br"},
+			expected: "invalid content encoding",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
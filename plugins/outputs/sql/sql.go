@@ -4,6 +4,7 @@ package sql
 import (
 	gosql "database/sql"
 	_ "embed"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/retention"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
@@ -47,6 +49,7 @@ type SQL struct {
 	ConnectionMaxIdle     int             `toml:"connection_max_idle"`
 	ConnectionMaxOpen     int             `toml:"connection_max_open"`
 	Log                   telegraf.Logger `toml:"-"`
+	retention.HintConfig
 
 	db     *gosql.DB
 	tables map[string]bool
@@ -56,6 +59,16 @@ func (*SQL) SampleConfig() string {
 	return sampleConfig
 }
 
+func (p *SQL) Init() error {
+	if err := p.HintConfig.Validate(); err != nil {
+		return err
+	}
+	if p.HintConfig.Enabled() && !strings.Contains(p.TableTemplate, "{RETENTION}") {
+		return errors.New("retention_tag requires the {RETENTION} variable in table_template")
+	}
+	return nil
+}
+
 func (p *SQL) Connect() error {
 	db, err := gosql.Open(p.Driver, p.DataSourceName)
 	if err != nil {
@@ -141,7 +154,7 @@ func (p *SQL) deriveDatatype(value interface{}) string {
 	return datatype
 }
 
-func (p *SQL) generateCreateTable(metric telegraf.Metric) string {
+func (p *SQL) generateCreateTable(metric telegraf.Metric, hint string) string {
 	columns := make([]string, 0, len(metric.TagList())+len(metric.FieldList())+1)
 
 	if p.TimestampColumn != "" {
@@ -162,6 +175,7 @@ func (p *SQL) generateCreateTable(metric telegraf.Metric) string {
 	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(metric.Name()))
 	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(metric.Name()))
 	query = strings.ReplaceAll(query, "{COLUMNS}", strings.Join(columns, ","))
+	query = strings.ReplaceAll(query, "{RETENTION}", hint)
 
 	return query
 }
@@ -203,9 +217,13 @@ func (p *SQL) Write(metrics []telegraf.Metric) error {
 	for _, metric := range metrics {
 		tablename := metric.Name()
 
+		// The retention hint only applies when creating the table
+		hint, _ := p.HintConfig.Hint(metric)
+		metric = p.HintConfig.Strip(metric)
+
 		// create table if needed
 		if !p.tables[tablename] && !p.tableExists(tablename) {
-			createStmt := p.generateCreateTable(metric)
+			createStmt := p.generateCreateTable(metric, hint)
 			_, err := p.db.Exec(createStmt)
 			if err != nil {
 				return err
@@ -16,6 +16,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/retention"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -37,6 +38,34 @@ func TestSqlInsertStatementIntegration(t *testing.T) {
 	}
 }
 
+func TestCreateTableRetentionHint(t *testing.T) {
+	p := newSQL()
+	p.TableTemplate = "CREATE TABLE {TABLE}({COLUMNS}) ENGINE = MergeTree ORDER BY timestamp {RETENTION}"
+	p.HintConfig = retention.HintConfig{
+		RetentionTag:        "retention",
+		RetentionHints:      map[string]string{"debug": "TTL timestamp + INTERVAL 7 DAY"},
+		ExcludeRetentionTag: true,
+	}
+	require.NoError(t, p.Init())
+
+	m := testutil.MustMetric("cpu", map[string]string{"retention": "debug"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	hint, found := p.HintConfig.Hint(m)
+	require.True(t, found)
+	require.Equal(t,
+		`CREATE TABLE "cpu"("timestamp" TIMESTAMP,"value" DOUBLE) ENGINE = MergeTree ORDER BY timestamp TTL timestamp + INTERVAL 7 DAY`,
+		p.generateCreateTable(p.HintConfig.Strip(m), hint),
+	)
+}
+
+func TestInitRetentionHintWithoutVariable(t *testing.T) {
+	p := newSQL()
+	p.HintConfig = retention.HintConfig{
+		RetentionTag:   "retention",
+		RetentionHints: map[string]string{"debug": "TTL timestamp + INTERVAL 7 DAY"},
+	}
+	require.ErrorContains(t, p.Init(), "requires the {RETENTION} variable in table_template")
+}
+
 func pwgen(n int) string {
 	charset := []byte("abcdedfghijklmnopqrstABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
 
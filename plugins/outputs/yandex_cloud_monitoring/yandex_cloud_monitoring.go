@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -26,6 +27,7 @@ type YandexCloudMonitoring struct {
 	Timeout     config.Duration `toml:"timeout"`
 	EndpointURL string          `toml:"endpoint_url"`
 	Service     string          `toml:"service"`
+	proxy.Socks5ProxyConfig
 
 	Log telegraf.Logger
 
@@ -35,7 +37,8 @@ type YandexCloudMonitoring struct {
 	IAMToken               string
 	IamTokenExpirationTime time.Time
 
-	client *http.Client
+	client         *http.Client
+	metadataClient *http.Client
 
 	timeFunc func() time.Time
 
@@ -92,7 +95,24 @@ func (a *YandexCloudMonitoring) Connect() error {
 		a.MetadataFolderURL = defaultMetadataFolderURL
 	}
 
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+	if a.Socks5ProxyEnabled {
+		dialer, err := a.Socks5ProxyConfig.GetProxiedDialer()
+		if err != nil {
+			return fmt.Errorf("creating socks5 proxy dialer failed: %w", err)
+		}
+		transport.DialContext = dialer.DialContext
+	}
 	a.client = &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(a.Timeout),
+	}
+
+	// The metadata service is only reachable from the instance itself, so
+	// do not use the SOCKS5 proxy for querying it.
+	a.metadataClient = &http.Client{
 		Transport: &http.Transport{
 			Proxy: http.ProxyFromEnvironment,
 		},
@@ -116,6 +136,7 @@ func (a *YandexCloudMonitoring) Connect() error {
 // Close shuts down an any active connections
 func (a *YandexCloudMonitoring) Close() error {
 	a.client = nil
+	a.metadataClient = nil
 	return nil
 }
 
@@ -181,7 +202,7 @@ func getResponseFromMetadata(c *http.Client, metadataURL string) ([]byte, error)
 
 func (a *YandexCloudMonitoring) getFolderIDFromMetadata() (string, error) {
 	a.Log.Infof("Getting folder ID in %s", a.MetadataFolderURL)
-	body, err := getResponseFromMetadata(a.client, a.MetadataFolderURL)
+	body, err := getResponseFromMetadata(a.metadataClient, a.MetadataFolderURL)
 	if err != nil {
 		return "", err
 	}
@@ -194,7 +215,7 @@ func (a *YandexCloudMonitoring) getFolderIDFromMetadata() (string, error) {
 
 func (a *YandexCloudMonitoring) getIAMTokenFromMetadata() (string, int, error) {
 	a.Log.Debugf("Getting new IAM token in %s", a.MetadataTokenURL)
-	body, err := getResponseFromMetadata(a.client, a.MetadataTokenURL)
+	body, err := getResponseFromMetadata(a.metadataClient, a.MetadataTokenURL)
 	if err != nil {
 		return "", 0, err
 	}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/datadope-io/go-zabbix/v2"
@@ -34,6 +35,7 @@ type Zabbix struct {
 	KeyPrefix                  string          `toml:"key_prefix"`
 	HostTag                    string          `toml:"host_tag"`
 	SkipMeasurementPrefix      bool            `toml:"skip_measurement_prefix"`
+	KeyTemplate                string          `toml:"key_template"`
 	LLDSendInterval            config.Duration `toml:"lld_send_interval"`
 	LLDClearInterval           config.Duration `toml:"lld_clear_interval"`
 	Autoregister               string          `toml:"autoregister"`
@@ -48,6 +50,23 @@ type Zabbix struct {
 	autoregisterLastSend map[string]time.Time
 	// sender is the interface to send data to Zabbix.
 	sender zabbixSender
+	// keyTemplate generates the item keys if configured
+	keyTemplate *template.Template
+}
+
+// itemKey is the data available in the item key template
+type itemKey struct {
+	Prefix    string
+	Name      string
+	Field     string
+	TagValues string
+	metric    telegraf.Metric
+}
+
+// Tag returns the value of the given tag or an empty string
+func (k *itemKey) Tag(key string) string {
+	v, _ := k.metric.GetTag(key)
+	return v
 }
 
 //go:embed sample.conf
@@ -71,6 +90,14 @@ func (z *Zabbix) Init() error {
 		z.Address = net.JoinHostPort(z.Address, "10051")
 	}
 
+	if z.KeyTemplate != "" {
+		tmpl, err := template.New("key").Parse(z.KeyTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing key_template failed: %w", err)
+		}
+		z.keyTemplate = tmpl
+	}
+
 	z.sender = zabbix.NewSender(z.Address)
 	// Initialize autoregisterLastSend map with size one, as the most common scenario is to have one host.
 	z.autoregisterLastSend = make(map[string]time.Time, 1)
@@ -106,7 +133,7 @@ func (z *Zabbix) Write(metrics []telegraf.Metric) error {
 			continue
 		}
 
-		zbxMetrics = append(zbxMetrics, z.processMetric(metric)...)
+		zbxMetrics = append(zbxMetrics, z.processMetric(metric, z.keyTemplate)...)
 
 		// Handle hostname for autoregister
 		z.autoregisterAdd(hostname)
@@ -122,7 +149,8 @@ func (z *Zabbix) Write(metrics []telegraf.Metric) error {
 	if time.Since(z.lldLastSend) > time.Duration(z.LLDSendInterval) {
 		z.lldLastSend = time.Now()
 		for _, lldMetric := range z.lldHandler.Push() {
-			zbxMetrics = append(zbxMetrics, z.processMetric(lldMetric)...)
+			// LLD metrics always use the default key format
+			zbxMetrics = append(zbxMetrics, z.processMetric(lldMetric, nil)...)
 		}
 	}
 
@@ -163,11 +191,11 @@ func (z *Zabbix) sendZabbixMetrics(zbxMetrics []*zabbix.Metric) error {
 
 // processMetric converts a Telegraf metric to a list of Zabbix metrics.
 // Ignore metrics with no hostname.
-func (z Zabbix) processMetric(metric telegraf.Metric) []*zabbix.Metric {
+func (z Zabbix) processMetric(metric telegraf.Metric, keyTemplate *template.Template) []*zabbix.Metric {
 	zbxMetrics := make([]*zabbix.Metric, 0, len(metric.FieldList()))
 
 	for _, field := range metric.FieldList() {
-		zbxMetric, err := z.buildZabbixMetric(metric, field.Key, field.Value)
+		zbxMetric, err := z.buildZabbixMetric(metric, field.Key, field.Value, keyTemplate)
 		if err != nil {
 			z.Log.Errorf("Error converting telegraf metric to Zabbix format: %v", err)
 			continue
@@ -180,7 +208,8 @@ func (z Zabbix) processMetric(metric telegraf.Metric) []*zabbix.Metric {
 }
 
 // buildZabbixMetric builds a Zabbix metric from a Telegraf metric, for one particular value.
-func (z Zabbix) buildZabbixMetric(metric telegraf.Metric, fieldName string, value interface{}) (*zabbix.Metric, error) {
+// If a key template is given, it is used to generate the item key.
+func (z Zabbix) buildZabbixMetric(metric telegraf.Metric, fieldName string, value interface{}, keyTemplate *template.Template) (*zabbix.Metric, error) {
 	hostname, err := getHostname(z.HostTag, metric)
 	if err != nil {
 		return nil, fmt.Errorf("error getting hostname: %w", err)
@@ -211,7 +240,20 @@ func (z Zabbix) buildZabbixMetric(metric telegraf.Metric, fieldName string, valu
 		tagValues = append(tagValues, tag.Value)
 	}
 
-	if len(tagValues) != 0 {
+	if keyTemplate != nil {
+		var buf strings.Builder
+		data := &itemKey{
+			Prefix:    z.KeyPrefix,
+			Name:      metric.Name(),
+			Field:     fieldName,
+			TagValues: strings.Join(tagValues, ","),
+			metric:    metric,
+		}
+		if err := keyTemplate.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("error executing key template: %w", err)
+		}
+		key = buf.String()
+	} else if len(tagValues) != 0 {
 		key = fmt.Sprintf("%v[%v]", key, strings.Join(tagValues, ","))
 	}
 
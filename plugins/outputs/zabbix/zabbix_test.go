@@ -857,6 +857,7 @@ func TestBuildZabbixMetric(t *testing.T) {
 		time.Now()),
 		"value",
 		1,
+		nil,
 	)
 	require.NoError(t, err)
 	require.Equal(t, fmt.Sprintf("%sname.value[b,bar]", keyPrefix), zm.Key)
@@ -868,11 +869,42 @@ func TestBuildZabbixMetric(t *testing.T) {
 		time.Now()),
 		"value",
 		1,
+		nil,
 	)
 	require.NoError(t, err)
 	require.Equal(t, fmt.Sprintf("%sname.value", keyPrefix), zm.Key)
 }
 
+func TestBuildZabbixMetricKeyTemplate(t *testing.T) {
+	z := &Zabbix{
+		KeyPrefix:   "telegraf.",
+		HostTag:     "host",
+		KeyTemplate: `{{ .Prefix }}{{ .Name }}[{{ .Tag "device" }},{{ .Field }}]`,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, z.Init())
+
+	m := testutil.MustMetric(
+		"disk",
+		map[string]string{"host": "hostA", "device": "sda", "fstype": "ext4"},
+		map[string]interface{}{"used": 1},
+		time.Now(),
+	)
+	zm, err := z.buildZabbixMetric(m, "used", 1, z.keyTemplate)
+	require.NoError(t, err)
+	require.Equal(t, "telegraf.disk[sda,used]", zm.Key)
+	require.Equal(t, "hostA", zm.Host)
+
+	z.KeyTemplate = `{{ .Name }}.{{ .Field }}{{ if .TagValues }}[{{ .TagValues }}]{{ end }}`
+	require.NoError(t, z.Init())
+	zm, err = z.buildZabbixMetric(m, "used", 1, z.keyTemplate)
+	require.NoError(t, err)
+	require.Equal(t, "disk.used[sda,ext4]", zm.Key)
+
+	z.KeyTemplate = `{{ .Name`
+	require.ErrorContains(t, z.Init(), "parsing key_template failed")
+}
+
 func TestGetHostname(t *testing.T) {
 	hostname, err := os.Hostname()
 	require.NoError(t, err)
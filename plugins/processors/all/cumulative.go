@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.cumulative
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/cumulative" // register plugin
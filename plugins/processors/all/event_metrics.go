@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.event_metrics
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/event_metrics" // register plugin
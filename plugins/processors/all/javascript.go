@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.javascript
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/javascript" // register plugin
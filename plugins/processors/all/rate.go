@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.rate
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/rate" // register plugin
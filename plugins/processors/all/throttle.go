@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.throttle
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/throttle" // register plugin
@@ -0,0 +1,184 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cardinality
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Cardinality struct {
+	Limit         int             `toml:"limit"`
+	Limits        map[string]int  `toml:"limits"`
+	TagInclude    []string        `toml:"tag_include"`
+	TagExclude    []string        `toml:"tag_exclude"`
+	Window        config.Duration `toml:"window"`
+	Action        string          `toml:"action"`
+	OverflowValue string          `toml:"overflow_value"`
+	Log           telegraf.Logger `toml:"-"`
+
+	tagFilter filter.Filter
+	trackers  map[string]*tracker
+	dropped   selfstat.Stat
+
+	// now is used to mock the current time in tests
+	now func() time.Time
+}
+
+// tracker keeps the time each distinct value of a tag key was last seen
+type tracker struct {
+	limit    int
+	lastSeen map[string]time.Time
+
+	tracked   selfstat.Stat
+	overflows selfstat.Stat
+}
+
+func (*Cardinality) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Cardinality) Init() error {
+	if c.Limit <= 0 && len(c.Limits) == 0 {
+		return errors.New("no limit configured")
+	}
+	for key, limit := range c.Limits {
+		if limit <= 0 {
+			return fmt.Errorf("invalid limit %d for tag %q", limit, key)
+		}
+	}
+	if c.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+
+	switch c.Action {
+	case "":
+		c.Action = "replace"
+	case "replace", "drop":
+	default:
+		return fmt.Errorf("invalid action %q", c.Action)
+	}
+	if c.Action == "replace" && c.OverflowValue == "" {
+		c.OverflowValue = "overflow"
+	}
+
+	tagFilter, err := filter.NewIncludeExcludeFilter(c.TagInclude, c.TagExclude)
+	if err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+	c.tagFilter = tagFilter
+
+	c.trackers = make(map[string]*tracker)
+	c.dropped = selfstat.Register("cardinality", "dropped_metrics", map[string]string{})
+	if c.now == nil {
+		c.now = time.Now
+	}
+
+	return nil
+}
+
+func (c *Cardinality) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := c.now()
+	threshold := now.Add(-time.Duration(c.Window))
+
+	idx := 0
+	for _, m := range in {
+		overflow := false
+		for _, tag := range m.TagList() {
+			t := c.tracker(tag.Key)
+			if t == nil || t.admit(tag.Value, now, threshold) {
+				continue
+			}
+			overflow = true
+			if c.Action == "drop" {
+				break
+			}
+			tag.Value = c.OverflowValue
+		}
+
+		if overflow && c.Action == "drop" {
+			c.dropped.Incr(1)
+			m.Drop()
+			continue
+		}
+		in[idx] = m
+		idx++
+	}
+
+	return in[:idx]
+}
+
+// tracker returns the tracker for the given tag key or nil if the key is
+// not limited
+func (c *Cardinality) tracker(key string) *tracker {
+	if t, found := c.trackers[key]; found {
+		return t
+	}
+
+	limit, found := c.Limits[key]
+	if !found {
+		if c.Limit <= 0 || !c.tagFilter.Match(key) {
+			c.trackers[key] = nil
+			return nil
+		}
+		limit = c.Limit
+	}
+
+	tags := map[string]string{"key": key}
+	t := &tracker{
+		limit:     limit,
+		lastSeen:  make(map[string]time.Time),
+		tracked:   selfstat.Register("cardinality", "tracked_values", tags),
+		overflows: selfstat.Register("cardinality", "overflow_values", tags),
+	}
+	c.trackers[key] = t
+	return t
+}
+
+// admit returns true if the value is within the limit of distinct values
+// seen after the threshold and records the value as seen
+func (t *tracker) admit(value string, now, threshold time.Time) bool {
+	if seen, found := t.lastSeen[value]; found && !seen.Before(threshold) {
+		t.lastSeen[value] = now
+		return true
+	}
+
+	// Expire values not seen within the window before rejecting a new value
+	if len(t.lastSeen) >= t.limit {
+		for v, seen := range t.lastSeen {
+			if seen.Before(threshold) {
+				delete(t.lastSeen, v)
+			}
+		}
+		t.tracked.Set(int64(len(t.lastSeen)))
+	}
+	if len(t.lastSeen) >= t.limit {
+		t.overflows.Incr(1)
+		return false
+	}
+
+	t.lastSeen[value] = now
+	t.tracked.Set(int64(len(t.lastSeen)))
+	return true
+}
+
+func init() {
+	processors.Add("cardinality", func() telegraf.Processor {
+		return &Cardinality{
+			Limit:         1000,
+			Window:        config.Duration(time.Hour),
+			Action:        "replace",
+			OverflowValue: "overflow",
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package cardinality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Cardinality
+		expected string
+	}{
+		{
+			name:     "no limit",
+			plugin:   &Cardinality{Window: config.Duration(time.Hour)},
+			expected: "no limit configured",
+		},
+		{
+			name:     "invalid per-key limit",
+			plugin:   &Cardinality{Limits: map[string]int{"user": 0}, Window: config.Duration(time.Hour)},
+			expected: `invalid limit 0 for tag "user"`,
+		},
+		{
+			name:     "no window",
+			plugin:   &Cardinality{Limit: 10},
+			expected: "window must be positive",
+		},
+		{
+			name:     "invalid action",
+			plugin:   &Cardinality{Limit: 10, Window: config.Duration(time.Hour), Action: "foo"},
+			expected: `invalid action "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	plugin := &Cardinality{
+		Limit:      2,
+		Limits:     map[string]int{"user": 1},
+		TagExclude: []string{"host"},
+		Window:     config.Duration(time.Hour),
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric(map[string]string{"host": "a", "path": "/", "user": "alice"}),
+		metric(map[string]string{"host": "b", "path": "/login", "user": "alice"}),
+		metric(map[string]string{"host": "c", "path": "/logout", "user": "bob"}),
+		metric(map[string]string{"host": "d", "path": "/", "user": "alice"}),
+	}
+	expected := []telegraf.Metric{
+		metric(map[string]string{"host": "a", "path": "/", "user": "alice"}),
+		metric(map[string]string{"host": "b", "path": "/login", "user": "alice"}),
+		metric(map[string]string{"host": "c", "path": "overflow", "user": "overflow"}),
+		metric(map[string]string{"host": "d", "path": "/", "user": "alice"}),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestDrop(t *testing.T) {
+	plugin := &Cardinality{
+		Limit:  1,
+		Window: config.Duration(time.Hour),
+		Action: "drop",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric(map[string]string{"id": "1"}),
+		metric(map[string]string{"id": "2"}),
+		metric(map[string]string{"id": "1"}),
+	}
+	expected := []telegraf.Metric{
+		metric(map[string]string{"id": "1"}),
+		metric(map[string]string{"id": "1"}),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &Cardinality{
+		Limit:  1,
+		Window: config.Duration(time.Minute),
+		Log:    testutil.Logger{},
+		now:    func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{metric(map[string]string{"id": "1"})},
+		plugin.Apply(metric(map[string]string{"id": "1"})),
+	)
+
+	// The window is sliding so seeing the value again extends its lifetime
+	now = now.Add(50 * time.Second)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{metric(map[string]string{"id": "1"}), metric(map[string]string{"id": "overflow"})},
+		plugin.Apply(metric(map[string]string{"id": "1"}), metric(map[string]string{"id": "2"})),
+	)
+
+	// Values not seen within the window make room for new values
+	now = now.Add(70 * time.Second)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{metric(map[string]string{"id": "2"})},
+		plugin.Apply(metric(map[string]string{"id": "2"})),
+	)
+}
+
+func TestSelfstat(t *testing.T) {
+	plugin := &Cardinality{
+		Limit:  1,
+		Window: config.Duration(time.Hour),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	overflows := selfstat.Register("cardinality", "overflow_values", map[string]string{"key": "session"})
+	tracked := selfstat.Register("cardinality", "tracked_values", map[string]string{"key": "session"})
+	start := overflows.Get()
+
+	plugin.Apply(
+		metric(map[string]string{"session": "a"}),
+		metric(map[string]string{"session": "b"}),
+		metric(map[string]string{"session": "c"}),
+	)
+	require.Equal(t, start+2, overflows.Get())
+	require.Equal(t, int64(1), tracked.Get())
+}
+
+func metric(tags map[string]string) telegraf.Metric {
+	return testutil.MustMetric("http", tags, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+}
@@ -0,0 +1,149 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cumulative
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// sum is the accumulated value of a single field of a series. The sum is kept
+// separately per data type to avoid precision loss for large integer counters.
+type sum struct {
+	Int   int64     `json:"int,omitempty"`
+	Uint  uint64    `json:"uint,omitempty"`
+	Float float64   `json:"float,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// series holds the sums of the selected fields for a single series
+type series map[string]sum
+
+type Cumulative struct {
+	IncludeFields []string        `toml:"include_fields"`
+	ExcludeFields []string        `toml:"exclude_fields"`
+	Suffix        string          `toml:"suffix"`
+	MaxAge        config.Duration `toml:"max_age"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]series
+	lastCleanup time.Time
+}
+
+func (*Cumulative) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Cumulative) Init() error {
+	fieldFilter, err := filter.NewIncludeExcludeFilter(c.IncludeFields, c.ExcludeFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	c.fieldFilter = fieldFilter
+
+	if c.cache == nil {
+		c.cache = make(map[uint64]series)
+	}
+	c.lastCleanup = time.Now()
+
+	return nil
+}
+
+func (c *Cumulative) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range metrics {
+		id := m.HashID()
+		s, found := c.cache[id]
+		if !found {
+			s = make(series)
+			c.cache[id] = s
+		}
+
+		ts := m.Time()
+		for _, field := range m.FieldList() {
+			if !c.fieldFilter.Match(field.Key) {
+				continue
+			}
+
+			acc := s[field.Key]
+			var total interface{}
+			switch v := field.Value.(type) {
+			case int64:
+				acc.Int += v
+				total = acc.Int
+			case uint64:
+				acc.Uint += v
+				total = acc.Uint
+			case float64:
+				acc.Float += v
+				total = acc.Float
+			default:
+				continue
+			}
+			if ts.After(acc.Time) {
+				acc.Time = ts
+			}
+			s[field.Key] = acc
+
+			if c.Suffix == "" {
+				field.Value = total
+			} else {
+				m.AddField(field.Key+c.Suffix, total)
+			}
+		}
+	}
+	c.cleanup()
+
+	return metrics
+}
+
+// cleanup removes the sums of series that have not been updated within
+// max_age, at most once per max_age period. Such series restart from zero
+// which is reported as a counter reset by the consumers.
+func (c *Cumulative) cleanup() {
+	if c.MaxAge <= 0 || time.Since(c.lastCleanup) < time.Duration(c.MaxAge) {
+		return
+	}
+	c.lastCleanup = time.Now()
+
+	threshold := c.lastCleanup.Add(-time.Duration(c.MaxAge))
+	for id, s := range c.cache {
+		for key, acc := range s {
+			if acc.Time.Before(threshold) {
+				delete(s, key)
+			}
+		}
+		if len(s) == 0 {
+			delete(c.cache, id)
+		}
+	}
+}
+
+func (c *Cumulative) GetState() interface{} {
+	return c.cache
+}
+
+func (c *Cumulative) SetState(state interface{}) error {
+	s, ok := state.(map[uint64]series)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	c.cache = s
+	return nil
+}
+
+func init() {
+	processors.Add("cumulative", func() telegraf.Processor {
+		return &Cumulative{
+			MaxAge: config.Duration(24 * time.Hour),
+		}
+	})
+}
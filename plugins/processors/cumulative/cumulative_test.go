@@ -0,0 +1,116 @@
+package cumulative
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Cumulative
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "replace fields",
+			plugin: &Cumulative{},
+			input: []telegraf.Metric{
+				metric("/login", 0, map[string]interface{}{"count": int64(3), "bytes": uint64(100), "duration": 0.5, "method": "GET"}),
+				metric("/login", 10, map[string]interface{}{"count": int64(5), "bytes": uint64(50), "duration": 1.25, "method": "POST"}),
+				metric("/home", 10, map[string]interface{}{"count": int64(1)}),
+				metric("/login", 20, map[string]interface{}{"count": int64(-2)}),
+			},
+			expected: []telegraf.Metric{
+				metric("/login", 0, map[string]interface{}{"count": int64(3), "bytes": uint64(100), "duration": 0.5, "method": "GET"}),
+				metric("/login", 10, map[string]interface{}{"count": int64(8), "bytes": uint64(150), "duration": 1.75, "method": "POST"}),
+				metric("/home", 10, map[string]interface{}{"count": int64(1)}),
+				metric("/login", 20, map[string]interface{}{"count": int64(6)}),
+			},
+		},
+		{
+			name:   "suffix and filter",
+			plugin: &Cumulative{Suffix: "_total", IncludeFields: []string{"count"}},
+			input: []telegraf.Metric{
+				metric("/login", 0, map[string]interface{}{"count": int64(3), "duration": 0.5}),
+				metric("/login", 10, map[string]interface{}{"count": int64(5), "duration": 1.0}),
+			},
+			expected: []telegraf.Metric{
+				metric("/login", 0, map[string]interface{}{"count": int64(3), "count_total": int64(3), "duration": 0.5}),
+				metric("/login", 10, map[string]interface{}{"count": int64(5), "count_total": int64(8), "duration": 1.0}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			var actual []telegraf.Metric
+			for _, m := range tt.input {
+				actual = append(actual, tt.plugin.Apply(m)...)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	plugin := &Cumulative{MaxAge: config.Duration(time.Minute), Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Apply(
+		testutil.MustMetric("old", map[string]string{}, map[string]interface{}{"value": 1.0}, now.Add(-2*time.Minute)),
+		testutil.MustMetric("new", map[string]string{}, map[string]interface{}{"value": 1.0}, now),
+	)
+	require.Len(t, plugin.cache, 2)
+
+	// Force the cleanup to run on the next call
+	plugin.lastCleanup = now.Add(-2 * time.Minute)
+	plugin.Apply()
+	require.Len(t, plugin.cache, 1)
+
+	// The expired series restarts from zero
+	actual := plugin.Apply(testutil.MustMetric("old", map[string]string{}, map[string]interface{}{"value": 2.0}, now))
+	expected := []telegraf.Metric{
+		testutil.MustMetric("old", map[string]string{}, map[string]interface{}{"value": 2.0}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestState(t *testing.T) {
+	plugin := &Cumulative{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	plugin.Apply(
+		metric("/login", 0, map[string]interface{}{"count": int64(10), "bytes": uint64(1 << 62), "duration": 2.5}),
+	)
+
+	// Roundtrip the state the same way the persister does
+	buf, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	var state map[uint64]series
+	require.NoError(t, json.Unmarshal(buf, &state))
+
+	restored := &Cumulative{Log: testutil.Logger{}}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state))
+
+	actual := restored.Apply(metric("/login", 10, map[string]interface{}{"count": int64(5), "bytes": uint64(1), "duration": 0.5}))
+	expected := []telegraf.Metric{
+		metric("/login", 10, map[string]interface{}{"count": int64(15), "bytes": uint64(1<<62 + 1), "duration": 3.0}),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func metric(path string, sec int64, fields map[string]interface{}) telegraf.Metric {
+	return testutil.MustMetric("http_requests", map[string]string{"path": path}, fields, time.Unix(sec, 0))
+}
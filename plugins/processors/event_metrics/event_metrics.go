@@ -0,0 +1,299 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package event_metrics
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Rule struct {
+	Name       string   `toml:"name"`
+	Pattern    string   `toml:"pattern"`
+	GroupBy    []string `toml:"group_by"`
+	ValueGroup string   `toml:"value_group"`
+	Aggregate  string   `toml:"aggregate"`
+
+	re *regexp.Regexp
+}
+
+type EventMetrics struct {
+	MessageField string          `toml:"message_field"`
+	Period       config.Duration `toml:"period"`
+	Cumulative   bool            `toml:"cumulative"`
+	DropOriginal bool            `toml:"drop_original"`
+	Rules        []Rule          `toml:"rule"`
+	Log          telegraf.Logger `toml:"-"`
+
+	acc    telegraf.Accumulator
+	series map[string]*series
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	sync.Mutex
+}
+
+// series accumulates the events of a rule with the same tags
+type series struct {
+	name      string
+	tags      map[string]string
+	aggregate string
+	count     int64
+
+	values int64
+	sum    float64
+	min    float64
+	max    float64
+	last   float64
+}
+
+func (*EventMetrics) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rule) Init() error {
+	if r.Name == "" {
+		return errors.New("name required")
+	}
+
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("compiling pattern failed: %w", err)
+		}
+		r.re = re
+	}
+
+	if r.ValueGroup != "" {
+		if r.re == nil || r.re.SubexpIndex(r.ValueGroup) < 0 {
+			return fmt.Errorf("value group %q not found in pattern", r.ValueGroup)
+		}
+		switch r.Aggregate {
+		case "":
+			r.Aggregate = "sum"
+		case "sum", "min", "max", "mean", "last":
+		default:
+			return fmt.Errorf("invalid aggregate %q", r.Aggregate)
+		}
+	}
+
+	return nil
+}
+
+func (e *EventMetrics) Init() error {
+	if e.MessageField == "" {
+		e.MessageField = "message"
+	}
+	if e.Period <= 0 {
+		return errors.New("period must be positive")
+	}
+	if len(e.Rules) == 0 {
+		return errors.New("no rule defined")
+	}
+	for i := range e.Rules {
+		if err := e.Rules[i].Init(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+
+	e.series = make(map[string]*series)
+
+	return nil
+}
+
+func (e *EventMetrics) Start(acc telegraf.Accumulator) error {
+	e.acc = acc
+	e.done = make(chan struct{})
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(e.Period))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.done:
+				return
+			case t := <-ticker.C:
+				e.push(t)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (e *EventMetrics) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	raw, found := m.GetField(e.MessageField)
+	if !found {
+		acc.AddMetric(m)
+		return nil
+	}
+	msg, ok := raw.(string)
+	if !ok {
+		acc.AddMetric(m)
+		return nil
+	}
+
+	e.Lock()
+	matched := false
+	for i := range e.Rules {
+		if e.count(&e.Rules[i], m, msg) {
+			matched = true
+		}
+	}
+	e.Unlock()
+
+	if matched && e.DropOriginal {
+		m.Drop()
+		return nil
+	}
+	acc.AddMetric(m)
+	return nil
+}
+
+func (e *EventMetrics) Stop() {
+	if e.done != nil {
+		close(e.done)
+		e.wg.Wait()
+	}
+	e.push(time.Now())
+}
+
+// count accounts the event for the given rule and returns true if the rule
+// matched the event message
+func (e *EventMetrics) count(r *Rule, m telegraf.Metric, msg string) bool {
+	var groups []string
+	if r.re != nil {
+		groups = r.re.FindStringSubmatch(msg)
+		if groups == nil {
+			return false
+		}
+	}
+
+	tags := make(map[string]string, len(r.GroupBy))
+	for _, key := range r.GroupBy {
+		if v, found := m.GetTag(key); found {
+			tags[key] = v
+		}
+	}
+
+	// Named capture groups other than the value group become tags
+	var value string
+	if r.re != nil {
+		for i, name := range r.re.SubexpNames() {
+			switch {
+			case name == "":
+			case name == r.ValueGroup:
+				value = groups[i]
+			case groups[i] != "":
+				tags[name] = groups[i]
+			}
+		}
+	}
+
+	key := seriesKey(r.Name, tags)
+	s, found := e.series[key]
+	if !found {
+		s = &series{name: r.Name, tags: tags, aggregate: r.Aggregate}
+		e.series[key] = s
+	}
+	s.count++
+
+	if r.ValueGroup != "" {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			e.Log.Debugf("Ignoring value %q of rule %q: %v", value, r.Name, err)
+			return true
+		}
+		if s.values == 0 {
+			s.min, s.max = v, v
+		}
+		s.values++
+		s.sum += v
+		s.min = math.Min(s.min, v)
+		s.max = math.Max(s.max, v)
+		s.last = v
+	}
+
+	return true
+}
+
+// push emits the metrics of all series and resets them for the next period
+func (e *EventMetrics) push(t time.Time) {
+	e.Lock()
+	defer e.Unlock()
+
+	for key, s := range e.series {
+		fields := map[string]interface{}{"count": s.count}
+		if s.values > 0 {
+			var value float64
+			switch s.aggregate {
+			case "sum":
+				value = s.sum
+			case "min":
+				value = s.min
+			case "max":
+				value = s.max
+			case "mean":
+				value = s.sum / float64(s.values)
+			case "last":
+				value = s.last
+			}
+			fields["value"] = value
+		}
+		e.acc.AddMetric(metric.New(s.name, s.tags, fields, t))
+
+		// Counters keep counting in cumulative mode, values are always
+		// aggregated per period
+		if e.Cumulative {
+			s.values, s.sum = 0, 0
+			continue
+		}
+		delete(e.series, key)
+	}
+}
+
+// seriesKey returns an identifier for the given rule name and tags
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteString("\x00")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(tags[k])
+	}
+	return b.String()
+}
+
+func init() {
+	processors.AddStreaming("event_metrics", func() telegraf.StreamingProcessor {
+		return &EventMetrics{
+			MessageField: "message",
+			Period:       config.Duration(10 * time.Second),
+		}
+	})
+}
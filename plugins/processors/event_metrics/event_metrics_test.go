@@ -0,0 +1,167 @@
+package event_metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		expected string
+	}{
+		{
+			name:     "no name",
+			rule:     Rule{Pattern: "ERROR"},
+			expected: "rule 1: name required",
+		},
+		{
+			name:     "invalid pattern",
+			rule:     Rule{Name: "errors", Pattern: "("},
+			expected: "rule 1: compiling pattern failed: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:     "unknown value group",
+			rule:     Rule{Name: "durations", Pattern: "took (?P<duration>[0-9]+)", ValueGroup: "latency"},
+			expected: `rule 1: value group "latency" not found in pattern`,
+		},
+		{
+			name:     "invalid aggregate",
+			rule:     Rule{Name: "durations", Pattern: "took (?P<duration>[0-9]+)", ValueGroup: "duration", Aggregate: "foo"},
+			expected: `rule 1: invalid aggregate "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &EventMetrics{
+				Period: config.Duration(time.Second),
+				Rules:  []Rule{tt.rule},
+			}
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	input := []telegraf.Metric{
+		event("api", "ERROR connection refused"),
+		event("api", "INFO GET /users took 12.5ms"),
+		event("db", "ERROR disk full"),
+		event("api", "ERROR timeout"),
+		event("api", "INFO POST /users took 30ms"),
+		event("api", "INFO GET /items took 7.5ms"),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+	}
+
+	plugin := &EventMetrics{
+		MessageField: "message",
+		Period:       config.Duration(time.Hour),
+		DropOriginal: true,
+		Rules: []Rule{
+			{
+				Name:    "log_errors",
+				Pattern: `\bERROR\b`,
+				GroupBy: []string{"service"},
+			},
+			{
+				Name:       "request_duration",
+				Pattern:    `(?P<method>GET|POST) \S+ took (?P<duration>[0-9.]+)ms`,
+				ValueGroup: "duration",
+				Aggregate:  "max",
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	// Only the unmatched metric is passed through
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	acc.ClearMetrics()
+	plugin.push(time.Unix(10, 0))
+	expected = []telegraf.Metric{
+		metric.New("log_errors",
+			map[string]string{"service": "api"},
+			map[string]interface{}{"count": int64(2)},
+			time.Unix(10, 0),
+		),
+		metric.New("log_errors",
+			map[string]string{"service": "db"},
+			map[string]interface{}{"count": int64(1)},
+			time.Unix(10, 0),
+		),
+		metric.New("request_duration",
+			map[string]string{"method": "GET"},
+			map[string]interface{}{"count": int64(2), "value": 12.5},
+			time.Unix(10, 0),
+		),
+		metric.New("request_duration",
+			map[string]string{"method": "POST"},
+			map[string]interface{}{"count": int64(1), "value": 30.0},
+			time.Unix(10, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Series are reset after pushing
+	acc.ClearMetrics()
+	plugin.Stop()
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestCumulative(t *testing.T) {
+	plugin := &EventMetrics{
+		MessageField: "message",
+		Period:       config.Duration(time.Hour),
+		Cumulative:   true,
+		Rules:        []Rule{{Name: "log_errors", Pattern: "ERROR"}},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Add(event("api", "ERROR timeout"), &acc))
+	plugin.push(time.Unix(10, 0))
+	require.NoError(t, plugin.Add(event("api", "ERROR timeout"), &acc))
+	plugin.push(time.Unix(20, 0))
+	plugin.push(time.Unix(30, 0))
+
+	expected := []telegraf.Metric{
+		event("api", "ERROR timeout"),
+		metric.New("log_errors", map[string]string{}, map[string]interface{}{"count": int64(1)}, time.Unix(10, 0)),
+		event("api", "ERROR timeout"),
+		metric.New("log_errors", map[string]string{}, map[string]interface{}{"count": int64(2)}, time.Unix(20, 0)),
+		metric.New("log_errors", map[string]string{}, map[string]interface{}{"count": int64(2)}, time.Unix(30, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func event(service, message string) telegraf.Metric {
+	return metric.New(
+		"tail",
+		map[string]string{"service": service},
+		map[string]interface{}{"message": message},
+		time.Unix(0, 0),
+	)
+}
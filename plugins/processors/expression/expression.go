@@ -0,0 +1,109 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package expression
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Field struct {
+	Name       string `toml:"name"`
+	Expression string `toml:"expression"`
+	Type       string `toml:"type"`
+
+	root node
+}
+
+type Expression struct {
+	Fields []Field         `toml:"field"`
+	Log    telegraf.Logger `toml:"-"`
+}
+
+func (*Expression) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *Field) Init() error {
+	if f.Name == "" {
+		return errors.New("name required")
+	}
+	if f.Expression == "" {
+		return errors.New("expression required")
+	}
+
+	switch f.Type {
+	case "":
+		f.Type = "float"
+	case "float", "integer":
+	default:
+		return fmt.Errorf("invalid type %q", f.Type)
+	}
+
+	root, err := parse(f.Expression)
+	if err != nil {
+		return fmt.Errorf("parsing expression %q failed: %w", f.Expression, err)
+	}
+	f.root = root
+
+	return nil
+}
+
+func (e *Expression) Init() error {
+	if len(e.Fields) == 0 {
+		return errors.New("no field defined")
+	}
+
+	for i := range e.Fields {
+		if err := e.Fields[i].Init(); err != nil {
+			return fmt.Errorf("field %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (e *Expression) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		lookup := func(name string) (float64, error) {
+			raw, found := m.GetField(name)
+			if !found {
+				return 0, fmt.Errorf("field %q not found", name)
+			}
+			return internal.ToFloat64(raw)
+		}
+
+		// Evaluate in order so expressions can use fields derived before
+		for _, f := range e.Fields {
+			v, err := f.root.eval(lookup)
+			if err != nil {
+				e.Log.Debugf("Evaluating %q for metric %q failed: %v", f.Name, m.Name(), err)
+				continue
+			}
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				e.Log.Debugf("Evaluating %q for metric %q resulted in %v", f.Name, m.Name(), v)
+				continue
+			}
+
+			if f.Type == "integer" {
+				m.AddField(f.Name, int64(math.Round(v)))
+			} else {
+				m.AddField(f.Name, v)
+			}
+		}
+	}
+	return in
+}
+
+func init() {
+	processors.Add("expression", func() telegraf.Processor {
+		return &Expression{}
+	})
+}
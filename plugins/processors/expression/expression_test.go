@@ -0,0 +1,204 @@
+package expression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParse(t *testing.T) {
+	fields := map[string]float64{
+		"used":       25,
+		"total":      200,
+		"a":          2,
+		"b":          3,
+		"disk.free":  7,
+		"bytes sent": 1024,
+	}
+	lookup := func(name string) (float64, error) {
+		return fields[name], nil
+	}
+
+	tests := []struct {
+		expression string
+		expected   float64
+	}{
+		{expression: "used / total * 100", expected: 12.5},
+		{expression: "1 + 2 * 3", expected: 7},
+		{expression: "(1 + 2) * 3", expected: 9},
+		{expression: "10 - 4 - 3", expected: 3},
+		{expression: "7 % 4", expected: 3},
+		{expression: "-a + b", expected: 1},
+		{expression: "--a", expected: 2},
+		{expression: "a ^ b", expected: 8},
+		{expression: "2 ^ 3 ^ 2", expected: 512},
+		{expression: "-2 ^ 2", expected: -4},
+		{expression: "2 ^ -1", expected: 0.5},
+		{expression: "1.5e3 / 1E-1", expected: 15000},
+		{expression: "disk.free * 2", expected: 14},
+		{expression: "`bytes sent` / 1024", expected: 1},
+		{expression: "round(used / b)", expected: 8},
+		{expression: "max(a, b, used) - min(a, b)", expected: 23},
+		{expression: "sqrt(pow(a, 4))", expected: 4},
+		{expression: "abs(a - b) + floor(1.7) + ceil(1.2)", expected: 4},
+		{expression: "log10(total / a)", expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			root, err := parse(tt.expression)
+			require.NoError(t, err)
+			actual, err := root.eval(lookup)
+			require.NoError(t, err)
+			require.InDelta(t, tt.expected, actual, 1e-9)
+		})
+	}
+}
+
+func TestParseFail(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{expression: "", expected: "unexpected end of expression"},
+		{expression: "a +", expected: "unexpected end of expression"},
+		{expression: "(a + b", expected: "missing closing parenthesis at position 7"},
+		{expression: "a b", expected: `unexpected 'b' at position 3`},
+		{expression: "a * ) b", expected: `unexpected ')' at position 5`},
+		{expression: "`a + b", expected: "unterminated field name at position 1"},
+		{expression: "1.2.3", expected: `invalid number "1.2.3" at position 1`},
+		{expression: "foo(a)", expected: `unknown function "foo"`},
+		{expression: "pow(a)", expected: `invalid number of arguments for function "pow"`},
+		{expression: "max()", expected: `invalid number of arguments for function "max"`},
+		{expression: "min(a b)", expected: "expected ',' or ')' at position 7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := parse(tt.expression)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    Field
+		expected string
+	}{
+		{
+			name:     "no name",
+			field:    Field{Expression: "a + b"},
+			expected: "field 1: name required",
+		},
+		{
+			name:     "no expression",
+			field:    Field{Name: "sum"},
+			expected: "field 1: expression required",
+		},
+		{
+			name:     "invalid type",
+			field:    Field{Name: "sum", Expression: "a + b", Type: "string"},
+			expected: `field 1: invalid type "string"`,
+		},
+		{
+			name:     "invalid expression",
+			field:    Field{Name: "sum", Expression: "a +"},
+			expected: `field 1: parsing expression "a +" failed: unexpected end of expression`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Expression{Fields: []Field{tt.field}}
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	plugin := &Expression{
+		Fields: []Field{
+			{Name: "used_percent", Expression: "used / total * 100"},
+			{Name: "free_percent", Expression: "100 - used_percent", Type: "integer"},
+			{Name: "cached_ratio", Expression: "cached / used"},
+			{Name: "missing", Expression: "used + buffered"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(30), "total": uint64(120), "cached": 15.0},
+			time.Unix(0, 0),
+		),
+		metric.New("mem",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"used": int64(0), "total": uint64(100), "cached": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New("mem",
+			map[string]string{"host": "c"},
+			map[string]interface{}{"used": "unknown", "total": uint64(100)},
+			time.Unix(0, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"used":         int64(30),
+				"total":        uint64(120),
+				"cached":       15.0,
+				"used_percent": 25.0,
+				"free_percent": int64(75),
+				"cached_ratio": 0.5,
+			},
+			time.Unix(0, 0),
+		),
+		// Division by zero skips the field
+		metric.New("mem",
+			map[string]string{"host": "b"},
+			map[string]interface{}{
+				"used":         int64(0),
+				"total":        uint64(100),
+				"cached":       0.0,
+				"used_percent": 0.0,
+				"free_percent": int64(100),
+			},
+			time.Unix(0, 0),
+		),
+		// Non-numeric fields cannot be used
+		metric.New("mem",
+			map[string]string{"host": "c"},
+			map[string]interface{}{"used": "unknown", "total": uint64(100)},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestApplyInvalidResult(t *testing.T) {
+	plugin := &Expression{
+		Fields: []Field{{Name: "root", Expression: "sqrt(value)"}},
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": -1.0}, time.Unix(0, 0))
+	actual := plugin.Apply(m)
+	require.Len(t, actual, 1)
+	_, found := actual[0].GetField("root")
+	require.False(t, found)
+}
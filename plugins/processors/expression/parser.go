@@ -0,0 +1,341 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// node is an element of the parsed expression tree
+type node interface {
+	eval(lookup func(string) (float64, error)) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(func(string) (float64, error)) (float64, error) {
+	return float64(n), nil
+}
+
+type field string
+
+func (f field) eval(lookup func(string) (float64, error)) (float64, error) {
+	return lookup(string(f))
+}
+
+type unary struct {
+	operand node
+}
+
+func (u *unary) eval(lookup func(string) (float64, error)) (float64, error) {
+	v, err := u.operand.eval(lookup)
+	return -v, err
+}
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b *binary) eval(lookup func(string) (float64, error)) (float64, error) {
+	l, err := b.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	r, err := b.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return l / r, nil
+	case '%':
+		if r == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return math.Mod(l, r), nil
+	case '^':
+		return math.Pow(l, r), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", b.op)
+}
+
+type call struct {
+	fn   function
+	args []node
+}
+
+func (c *call) eval(lookup func(string) (float64, error)) (float64, error) {
+	args := make([]float64, 0, len(c.args))
+	for _, arg := range c.args {
+		v, err := arg.eval(lookup)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+	}
+	return c.fn.call(args...), nil
+}
+
+// function is a built-in function with a fixed number of arguments or with
+// at least one argument if the number is negative
+type function struct {
+	args int
+	call func(args ...float64) float64
+}
+
+var functions = map[string]function{
+	"abs":   {1, func(a ...float64) float64 { return math.Abs(a[0]) }},
+	"ceil":  {1, func(a ...float64) float64 { return math.Ceil(a[0]) }},
+	"floor": {1, func(a ...float64) float64 { return math.Floor(a[0]) }},
+	"round": {1, func(a ...float64) float64 { return math.Round(a[0]) }},
+	"sqrt":  {1, func(a ...float64) float64 { return math.Sqrt(a[0]) }},
+	"exp":   {1, func(a ...float64) float64 { return math.Exp(a[0]) }},
+	"log":   {1, func(a ...float64) float64 { return math.Log(a[0]) }},
+	"log10": {1, func(a ...float64) float64 { return math.Log10(a[0]) }},
+	"pow":   {2, func(a ...float64) float64 { return math.Pow(a[0], a[1]) }},
+	"min": {-1, func(a ...float64) float64 {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Min(result, v)
+		}
+		return result
+	}},
+	"max": {-1, func(a ...float64) float64 {
+		result := a[0]
+		for _, v := range a[1:] {
+			result = math.Max(result, v)
+		}
+		return result
+	}},
+}
+
+// parser is a recursive descent parser for the grammar
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%") unary }
+//	unary      = "-" unary | power
+//	power      = primary [ "^" unary ]
+//	primary    = number | field | function "(" [ expression { "," expression } ] ")" | "(" expression ")"
+//
+// Fields are identifiers consisting of letters, digits, underscores and dots
+// or arbitrary names enclosed in backticks.
+type parser struct {
+	input string
+	pos   int
+}
+
+func parse(input string) (node, error) {
+	p := &parser{input: input}
+	n, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos+1)
+	}
+	return n, nil
+}
+
+func (p *parser) expression() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, found := p.operator("+-")
+		if !found {
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, found := p.operator("*/%")
+		if !found {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if _, found := p.operator("-"); found {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{operand: operand}, nil
+	}
+	return p.power()
+}
+
+func (p *parser) power() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if _, found := p.operator("^"); !found {
+		return base, nil
+	}
+
+	// Exponentiation is right-associative and binds stronger than a leading
+	// negation, i.e. -2^2 is -4
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op: '^', left: base, right: exponent}, nil
+}
+
+func (p *parser) primary() (node, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return nil, errors.New("unexpected end of expression")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		n, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos+1)
+		}
+		return n, nil
+	case c == '`':
+		end := strings.IndexByte(p.input[p.pos+1:], '`')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated field name at position %d", p.pos+1)
+		}
+		name := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return field(name), nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.number()
+	case isIdentifier(rune(c)):
+		return p.identifier()
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+}
+
+func (p *parser) number() (node, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		isExponentSign := (c == '+' || c == '-') && p.pos > start && (p.input[p.pos-1] == 'e' || p.input[p.pos-1] == 'E')
+		if !(c >= '0' && c <= '9' || c == '.' || c == 'e' || c == 'E' || isExponentSign) {
+			break
+		}
+		p.pos++
+	}
+
+	v, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q at position %d", p.input[start:p.pos], start+1)
+	}
+	return number(v), nil
+}
+
+func (p *parser) identifier() (node, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isIdentifier(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+
+	// Identifiers followed by parenthesis are function calls
+	p.skipSpace()
+	if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		return field(name), nil
+	}
+	p.pos++
+
+	fn, found := functions[name]
+	if !found {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	var args []node
+	if !p.consume(')') {
+		for {
+			arg, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.consume(')') {
+				break
+			}
+			if !p.consume(',') {
+				return nil, fmt.Errorf("expected ',' or ')' at position %d", p.pos+1)
+			}
+		}
+	}
+	if fn.args >= 0 && len(args) != fn.args || fn.args < 0 && len(args) == 0 {
+		return nil, fmt.Errorf("invalid number of arguments for function %q", name)
+	}
+
+	return &call{fn: fn, args: args}, nil
+}
+
+// operator consumes one of the given operator characters if it is next in
+// the input
+func (p *parser) operator(ops string) (byte, bool) {
+	p.skipSpace()
+	if p.pos < len(p.input) && strings.IndexByte(ops, p.input[p.pos]) >= 0 {
+		op := p.input[p.pos]
+		p.pos++
+		return op, true
+	}
+	return 0, false
+}
+
+func (p *parser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func isIdentifier(c rune) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,284 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package geoip
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// attributes maps the available attributes to whether they are added as
+// field (true) or as tag (false)
+var attributes = map[string]bool{
+	"continent_code":   false,
+	"continent":        false,
+	"country_code":     false,
+	"country":          false,
+	"subdivision_code": false,
+	"subdivision":      false,
+	"city":             false,
+	"postal_code":      false,
+	"asn":              false,
+	"as_org":           false,
+	"latitude":         true,
+	"longitude":        true,
+}
+
+type GeoIP struct {
+	Databases      []string        `toml:"databases"`
+	Tags           []string        `toml:"tags"`
+	Fields         []string        `toml:"fields"`
+	Attributes     []string        `toml:"attributes"`
+	Language       string          `toml:"language"`
+	Separator      string          `toml:"separator"`
+	ReloadInterval config.Duration `toml:"reload_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	databases []*database
+	lastCheck time.Time
+}
+
+// database is an opened MaxMind database file and its modification time
+// used for detecting updates
+type database struct {
+	path    string
+	modTime time.Time
+	reader  *maxminddb.Reader
+}
+
+// record contains the union of the information provided by the City,
+// Country and ASN databases
+type record struct {
+	Continent struct {
+		Code  string            `maxminddb:"code"`
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+	ASN   uint   `maxminddb:"autonomous_system_number"`
+	ASOrg string `maxminddb:"autonomous_system_organization"`
+}
+
+func (*GeoIP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GeoIP) Init() error {
+	if len(g.Databases) == 0 {
+		return errors.New("no databases configured")
+	}
+	if len(g.Tags) == 0 && len(g.Fields) == 0 {
+		return errors.New("no tags or fields configured")
+	}
+
+	if len(g.Attributes) == 0 {
+		g.Attributes = []string{"country_code", "city", "asn", "as_org"}
+	}
+	for _, attr := range g.Attributes {
+		if _, found := attributes[attr]; !found {
+			return fmt.Errorf("unknown attribute %q", attr)
+		}
+	}
+	if g.Language == "" {
+		g.Language = "en"
+	}
+
+	g.databases = make([]*database, 0, len(g.Databases))
+	for _, path := range g.Databases {
+		db, err := openDatabase(path)
+		if err != nil {
+			return err
+		}
+		g.Log.Debugf("Opened %s database %q built at %s", db.reader.Metadata.DatabaseType, path,
+			time.Unix(int64(db.reader.Metadata.BuildEpoch), 0).UTC().Format(time.RFC3339))
+		g.databases = append(g.databases, db)
+	}
+	g.lastCheck = time.Now()
+
+	return nil
+}
+
+func (g *GeoIP) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	g.reload()
+
+	for _, m := range in {
+		for _, key := range g.Tags {
+			if value, found := m.GetTag(key); found {
+				g.annotate(m, key, value)
+			}
+		}
+		for _, key := range g.Fields {
+			if raw, found := m.GetField(key); found {
+				if value, ok := raw.(string); ok {
+					g.annotate(m, key, value)
+				}
+			}
+		}
+	}
+	return in
+}
+
+// annotate adds the configured attributes for the given address to the
+// metric using the source name as prefix
+func (g *GeoIP) annotate(m telegraf.Metric, source, address string) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		g.Log.Debugf("Ignoring invalid address %q in %q", address, source)
+		return
+	}
+
+	values := make(map[string]interface{}, len(g.Attributes))
+	for _, db := range g.databases {
+		// Avoid errors for IPv6 addresses in IPv4-only databases
+		if db.reader.Metadata.IPVersion == 4 && ip.To4() == nil {
+			continue
+		}
+
+		var r record
+		if err := db.reader.Lookup(ip, &r); err != nil {
+			g.Log.Errorf("Looking up %q in %q failed: %v", address, db.path, err)
+			continue
+		}
+
+		// The first database providing an attribute wins
+		for k, v := range r.values(g.Language) {
+			if _, found := values[k]; !found {
+				values[k] = v
+			}
+		}
+	}
+
+	for _, attr := range g.Attributes {
+		v, found := values[attr]
+		if !found {
+			continue
+		}
+		name := source + g.Separator + attr
+		if attributes[attr] {
+			m.AddField(name, v)
+		} else {
+			m.AddTag(name, v.(string))
+		}
+	}
+}
+
+// reload reopens the databases modified since they were opened, at most once
+// per reload interval. The current database is kept if reopening fails.
+func (g *GeoIP) reload() {
+	if g.ReloadInterval <= 0 || time.Since(g.lastCheck) < time.Duration(g.ReloadInterval) {
+		return
+	}
+	g.lastCheck = time.Now()
+
+	for i, db := range g.databases {
+		info, err := os.Stat(db.path)
+		if err != nil {
+			g.Log.Errorf("Checking database %q failed: %v", db.path, err)
+			continue
+		}
+		if info.ModTime().Equal(db.modTime) {
+			continue
+		}
+
+		updated, err := openDatabase(db.path)
+		if err != nil {
+			g.Log.Errorf("Reloading database %q failed: %v", db.path, err)
+			continue
+		}
+		g.databases[i] = updated
+		if err := db.reader.Close(); err != nil {
+			g.Log.Warnf("Closing previous database %q failed: %v", db.path, err)
+		}
+		g.Log.Infof("Reloaded database %q", db.path)
+	}
+}
+
+func openDatabase(path string) (*database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("checking database %q failed: %w", path, err)
+	}
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening database %q failed: %w", path, err)
+	}
+	return &database{path: path, modTime: info.ModTime(), reader: reader}, nil
+}
+
+// values returns the non-empty attributes of the record using the given
+// language for names, falling back to English
+func (r *record) values(lang string) map[string]interface{} {
+	values := make(map[string]interface{})
+	add := func(key, value string) {
+		if value != "" {
+			values[key] = value
+		}
+	}
+
+	add("continent_code", r.Continent.Code)
+	add("continent", name(r.Continent.Names, lang))
+	add("country_code", r.Country.ISOCode)
+	add("country", name(r.Country.Names, lang))
+	if len(r.Subdivisions) > 0 {
+		add("subdivision_code", r.Subdivisions[0].ISOCode)
+		add("subdivision", name(r.Subdivisions[0].Names, lang))
+	}
+	add("city", name(r.City.Names, lang))
+	add("postal_code", r.Postal.Code)
+	if r.ASN > 0 {
+		add("asn", strconv.FormatUint(uint64(r.ASN), 10))
+	}
+	add("as_org", r.ASOrg)
+	if r.Location.Latitude != nil && r.Location.Longitude != nil {
+		values["latitude"] = *r.Location.Latitude
+		values["longitude"] = *r.Location.Longitude
+	}
+
+	return values
+}
+
+func name(names map[string]string, lang string) string {
+	if n, found := names[lang]; found {
+		return n
+	}
+	return names["en"]
+}
+
+func init() {
+	processors.Add("geoip", func() telegraf.Processor {
+		return &GeoIP{
+			Language:       "en",
+			Separator:      "_",
+			ReloadInterval: config.Duration(time.Minute),
+		}
+	})
+}
@@ -0,0 +1,270 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var (
+	cityDB = filepath.Join("testdata", "GeoLite2-City-Test.mmdb")
+	asnDB  = filepath.Join("testdata", "GeoLite2-ASN-Test.mmdb")
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GeoIP
+		expected string
+	}{
+		{
+			name:     "no databases",
+			plugin:   &GeoIP{Tags: []string{"src"}},
+			expected: "no databases configured",
+		},
+		{
+			name:     "no sources",
+			plugin:   &GeoIP{Databases: []string{cityDB}},
+			expected: "no tags or fields configured",
+		},
+		{
+			name: "unknown attribute",
+			plugin: &GeoIP{
+				Databases:  []string{cityDB},
+				Tags:       []string{"src"},
+				Attributes: []string{"timezone"},
+			},
+			expected: `unknown attribute "timezone"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitMissingDatabase(t *testing.T) {
+	plugin := &GeoIP{
+		Databases: []string{filepath.Join("testdata", "missing.mmdb")},
+		Tags:      []string{"src"},
+		Log:       testutil.Logger{},
+	}
+	require.ErrorIs(t, plugin.Init(), os.ErrNotExist)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GeoIP
+		input    telegraf.Metric
+		expected telegraf.Metric
+	}{
+		{
+			name: "default attributes",
+			plugin: &GeoIP{
+				Databases: []string{cityDB, asnDB},
+				Tags:      []string{"src", "dst"},
+			},
+			input: testutil.MustMetric(
+				"netflow",
+				map[string]string{"src": "81.2.69.142", "dst": "89.160.20.115"},
+				map[string]interface{}{"bytes": int64(1500)},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"netflow",
+				map[string]string{
+					"src":              "81.2.69.142",
+					"src_country_code": "GB",
+					"src_city":         "London",
+					"src_asn":          "20712",
+					"src_as_org":       "Andrews & Arnold Ltd",
+					"dst":              "89.160.20.115",
+					"dst_country_code": "SE",
+					"dst_city":         "Linköping",
+					"dst_asn":          "29518",
+					"dst_as_org":       "Bredband2 AB",
+				},
+				map[string]interface{}{"bytes": int64(1500)},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "all attributes from field",
+			plugin: &GeoIP{
+				Databases: []string{cityDB},
+				Fields:    []string{"client_ip"},
+				Attributes: []string{
+					"continent_code", "continent", "country_code", "country", "subdivision_code",
+					"subdivision", "city", "postal_code", "latitude", "longitude",
+				},
+			},
+			input: testutil.MustMetric(
+				"weblog",
+				map[string]string{},
+				map[string]interface{}{"client_ip": "81.2.69.160", "status": int64(200)},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"weblog",
+				map[string]string{
+					"client_ip_continent_code":   "EU",
+					"client_ip_continent":        "Europe",
+					"client_ip_country_code":     "GB",
+					"client_ip_country":          "United Kingdom",
+					"client_ip_subdivision_code": "ENG",
+					"client_ip_subdivision":      "England",
+					"client_ip_city":             "London",
+					"client_ip_postal_code":      "EC2V",
+				},
+				map[string]interface{}{
+					"client_ip":           "81.2.69.160",
+					"status":              int64(200),
+					"client_ip_latitude":  51.5142,
+					"client_ip_longitude": -0.0931,
+				},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "language with fallback",
+			plugin: &GeoIP{
+				Databases:  []string{cityDB},
+				Tags:       []string{"ip"},
+				Attributes: []string{"country", "city"},
+				Language:   "de",
+			},
+			input: testutil.MustMetric(
+				"test",
+				map[string]string{"ip": "89.160.20.112"},
+				map[string]interface{}{"value": 1},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"test",
+				map[string]string{"ip": "89.160.20.112", "ip_country": "Schweden", "ip_city": "Linköping"},
+				map[string]interface{}{"value": 1},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "partial information",
+			plugin: &GeoIP{
+				Databases: []string{cityDB, asnDB},
+				Tags:      []string{"ip"},
+			},
+			input: testutil.MustMetric(
+				"test",
+				map[string]string{"ip": "1.0.0.1"},
+				map[string]interface{}{"value": 1},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"test",
+				map[string]string{"ip": "1.0.0.1", "ip_asn": "13335", "ip_as_org": "Cloudflare, Inc."},
+				map[string]interface{}{"value": 1},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name: "unknown, invalid and IPv6 addresses",
+			plugin: &GeoIP{
+				Databases: []string{cityDB, asnDB},
+				Tags:      []string{"private", "invalid", "v6"},
+			},
+			input: testutil.MustMetric(
+				"test",
+				map[string]string{"private": "192.168.1.1", "invalid": "foo", "v6": "2001:db8::1"},
+				map[string]interface{}{"value": 1},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric(
+				"test",
+				map[string]string{"private": "192.168.1.1", "invalid": "foo", "v6": "2001:db8::1"},
+				map[string]interface{}{"value": 1},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Separator = "_"
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			actual := tt.plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	// Start with the ASN data and replace the file by the city data later
+	path := filepath.Join(t.TempDir(), "GeoIP.mmdb")
+	copyFile(t, asnDB, path)
+
+	plugin := &GeoIP{
+		Databases:      []string{path},
+		Tags:           []string{"ip"},
+		Attributes:     []string{"country_code", "asn"},
+		Separator:      "_",
+		ReloadInterval: config.Duration(time.Minute),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := testutil.MustMetric("test", map[string]string{"ip": "81.2.69.142"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"test",
+			map[string]string{"ip": "81.2.69.142", "ip_asn": "20712"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input.Copy()))
+
+	// Update the database and make sure the change is detected independent
+	// of the file system's timestamp resolution
+	copyFile(t, cityDB, path)
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, future, future))
+
+	// Changes are not picked up before the reload interval elapsed
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input.Copy()))
+
+	plugin.lastCheck = time.Now().Add(-2 * time.Minute)
+	expected = []telegraf.Metric{
+		testutil.MustMetric(
+			"test",
+			map[string]string{"ip": "81.2.69.142", "ip_country_code": "GB"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input.Copy()))
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+
+	buf, err := os.ReadFile(src)
+	require.NoError(t, err)
+
+	// Write to a temporary file and rename it, like database updaters do, to
+	// not modify the memory-mapped file in place
+	tmp := dst + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, buf, 0600))
+	require.NoError(t, os.Rename(tmp, dst))
+}
@@ -0,0 +1,242 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package javascript
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type JavaScript struct {
+	Source    string                 `toml:"source"`
+	Script    string                 `toml:"script"`
+	Constants map[string]interface{} `toml:"constants"`
+	Libraries map[string]string      `toml:"libraries"`
+	Timeout   config.Duration        `toml:"timeout"`
+	Log       telegraf.Logger        `toml:"-"`
+
+	vm      *goja.Runtime
+	apply   goja.Callable
+	modules map[string]goja.Value
+}
+
+func (*JavaScript) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *JavaScript) Init() error {
+	if j.Source == "" && j.Script == "" {
+		return errors.New("one of source or script must be set")
+	}
+	if j.Source != "" && j.Script != "" {
+		return errors.New("both source or script cannot be set")
+	}
+	if j.Timeout <= 0 {
+		j.Timeout = config.Duration(time.Second)
+	}
+
+	name, src := "source", j.Source
+	if j.Script != "" {
+		buf, err := os.ReadFile(j.Script)
+		if err != nil {
+			return fmt.Errorf("reading script failed: %w", err)
+		}
+		name, src = j.Script, string(buf)
+	}
+
+	j.vm = goja.New()
+	j.vm.SetFieldNameMapper(goja.UncapFieldNameMapper())
+	j.modules = make(map[string]goja.Value)
+
+	if err := j.addBuiltins(); err != nil {
+		return err
+	}
+	for key, val := range j.Constants {
+		if err := j.vm.Set(key, val); err != nil {
+			return fmt.Errorf("setting constant %q failed: %w", key, err)
+		}
+	}
+
+	if _, err := j.vm.RunScript(name, src); err != nil {
+		return fmt.Errorf("running script failed: %w", err)
+	}
+
+	apply, ok := goja.AssertFunction(j.vm.Get("apply"))
+	if !ok {
+		return errors.New("apply is not defined as a function")
+	}
+	j.apply = apply
+
+	return nil
+}
+
+func (*JavaScript) Start(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (j *JavaScript) Add(origMetric telegraf.Metric, acc telegraf.Accumulator) error {
+	// Abort long-running or endless scripts
+	timer := time.AfterFunc(time.Duration(j.Timeout), func() {
+		j.vm.Interrupt("timeout exceeded")
+	})
+	rv, err := j.apply(goja.Undefined(), j.vm.ToValue(&jsMetric{metric: origMetric}))
+	timer.Stop()
+	j.vm.ClearInterrupt()
+	if err != nil {
+		j.Log.Errorf("Calling apply failed: %v", err)
+		return err
+	}
+
+	// Nothing returned means dropping the metric
+	if rv == nil || goja.IsUndefined(rv) || goja.IsNull(rv) {
+		origMetric.Drop()
+		return nil
+	}
+
+	var results []interface{}
+	switch v := rv.Export().(type) {
+	case *jsMetric:
+		results = []interface{}{v}
+	case []interface{}:
+		results = v
+	default:
+		origMetric.Drop()
+		return fmt.Errorf("invalid type returned: %T", v)
+	}
+
+	var origFound bool
+	seen := make(map[telegraf.Metric]bool, len(results))
+	for _, r := range results {
+		m, ok := r.(*jsMetric)
+		if !ok {
+			j.Log.Errorf("Invalid type returned in list: %T", r)
+			continue
+		}
+		if seen[m.metric] {
+			j.Log.Errorf("Duplicate metric reference detected")
+			continue
+		}
+		seen[m.metric] = true
+
+		if m.metric == origMetric {
+			origFound = true
+		}
+		acc.AddMetric(m.metric)
+	}
+
+	// If the script didn't return the original metric, mark it as
+	// successfully handled
+	if !origFound {
+		origMetric.Drop()
+	}
+
+	return nil
+}
+
+func (*JavaScript) Stop() {
+}
+
+// addBuiltins makes the global functions and objects available to the script
+func (j *JavaScript) addBuiltins() error {
+	console := j.vm.NewObject()
+	logFuncs := map[string]func(args ...interface{}){
+		"log":   j.Log.Info,
+		"info":  j.Log.Info,
+		"debug": j.Log.Debug,
+		"warn":  j.Log.Warn,
+		"error": j.Log.Error,
+	}
+	for name, fn := range logFuncs {
+		if err := console.Set(name, fn); err != nil {
+			return fmt.Errorf("setting console.%s failed: %w", name, err)
+		}
+	}
+
+	// The state is shared across all calls of the apply function
+	builtins := map[string]interface{}{
+		"console":   console,
+		"state":     j.vm.NewObject(),
+		"newMetric": newMetric,
+		"require":   j.require,
+	}
+	for name, value := range builtins {
+		if err := j.vm.Set(name, value); err != nil {
+			return fmt.Errorf("setting %q failed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// require loads the library with the given name following the CommonJS
+// convention, i.e. the library exports its members via 'module.exports' or
+// 'exports'. Libraries are only evaluated once.
+func (j *JavaScript) require(name string) goja.Value {
+	if exports, found := j.modules[name]; found {
+		return exports
+	}
+
+	path, found := j.Libraries[name]
+	if !found {
+		j.throw(fmt.Errorf("library %q not configured", name))
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		j.throw(fmt.Errorf("reading library %q failed: %w", name, err))
+	}
+
+	wrapper, err := j.vm.RunScript(path, "(function(exports, module, require) {\n"+string(src)+"\n})")
+	if err != nil {
+		j.throw(err)
+	}
+	fn, ok := goja.AssertFunction(wrapper)
+	if !ok {
+		panic(j.vm.NewTypeError("library %q is not a valid module", name))
+	}
+
+	// Register the exports before evaluating the library to allow for
+	// circular dependencies
+	module := j.vm.NewObject()
+	exports := j.vm.NewObject()
+	if err := module.Set("exports", exports); err != nil {
+		j.throw(err)
+	}
+	j.modules[name] = exports
+
+	if _, err := fn(goja.Undefined(), exports, module, j.vm.ToValue(j.require)); err != nil {
+		delete(j.modules, name)
+		j.throw(err)
+	}
+
+	// The library might have replaced the exports object
+	j.modules[name] = module.Get("exports")
+	return j.modules[name]
+}
+
+// throw raises the error as exception in the script, this function must only
+// be called from within functions called by the script
+func (j *JavaScript) throw(err error) {
+	var ex *goja.Exception
+	if errors.As(err, &ex) {
+		panic(ex.Value())
+	}
+	panic(j.vm.NewGoError(err))
+}
+
+func init() {
+	processors.AddStreaming("javascript", func() telegraf.StreamingProcessor {
+		return &JavaScript{
+			Timeout: config.Duration(time.Second),
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package javascript
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *JavaScript
+		expected string
+	}{
+		{
+			name:     "no source",
+			plugin:   &JavaScript{},
+			expected: "one of source or script must be set",
+		},
+		{
+			name:     "source and script",
+			plugin:   &JavaScript{Source: "function apply(m) { return m }", Script: "script.js"},
+			expected: "both source or script cannot be set",
+		},
+		{
+			name:     "no apply function",
+			plugin:   &JavaScript{Source: "var apply = 42"},
+			expected: "apply is not defined as a function",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		constants map[string]interface{}
+		input     []telegraf.Metric
+		expected  []telegraf.Metric
+	}{
+		{
+			name:     "passthrough",
+			source:   "function apply(metric) { return metric }",
+			input:    []telegraf.Metric{cpu(map[string]interface{}{"value": 42.0})},
+			expected: []telegraf.Metric{cpu(map[string]interface{}{"value": 42.0})},
+		},
+		{
+			name:   "drop",
+			source: "function apply(metric) { return null }",
+			input:  []telegraf.Metric{cpu(map[string]interface{}{"value": 42.0})},
+		},
+		{
+			name: "modify",
+			source: `
+function apply(metric) {
+  metric.setName("processor");
+  metric.setTag("core", metric.tag("cpu").replace("cpu", ""));
+  metric.removeTag("cpu");
+  metric.setField("idle", 100 - metric.field("usage"));
+  metric.removeField("usage");
+  return metric;
+}`,
+			input: []telegraf.Metric{cpu(map[string]interface{}{"usage": 25.5})},
+			expected: []telegraf.Metric{
+				metric.New(
+					"processor",
+					map[string]string{"core": "0"},
+					map[string]interface{}{"idle": 74.5},
+					time.Unix(1, 0),
+				),
+			},
+		},
+		{
+			name: "new metrics with constants",
+			source: `
+function apply(metric) {
+  var result = [metric];
+  for (var key in metric.fields()) {
+    var m = newMetric(prefix + key);
+    m.setField("value", metric.field(key));
+    m.setTime(metric.time());
+    result.push(m);
+  }
+  return result;
+}`,
+			constants: map[string]interface{}{"prefix": "cpu_"},
+			input:     []telegraf.Metric{cpu(map[string]interface{}{"usage": int64(3)})},
+			expected: []telegraf.Metric{
+				cpu(map[string]interface{}{"usage": int64(3)}),
+				metric.New("cpu_usage", map[string]string{}, map[string]interface{}{"value": int64(3)}, time.Unix(1, 0)),
+			},
+		},
+		{
+			name: "state",
+			source: `
+function apply(metric) {
+  state.count = (state.count || 0) + 1;
+  metric.setField("count", state.count);
+  return metric;
+}`,
+			input: []telegraf.Metric{
+				cpu(map[string]interface{}{"value": 1.0}),
+				cpu(map[string]interface{}{"value": 2.0}),
+			},
+			expected: []telegraf.Metric{
+				cpu(map[string]interface{}{"value": 1.0, "count": int64(1)}),
+				cpu(map[string]interface{}{"value": 2.0, "count": int64(2)}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &JavaScript{
+				Source:    tt.source,
+				Constants: tt.constants,
+				Log:       testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			for _, m := range tt.input {
+				require.NoError(t, plugin.Add(m, &acc))
+			}
+			plugin.Stop()
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "units.js")
+	require.NoError(t, os.WriteFile(lib, []byte(`
+module.exports = {
+  toGiB: function(v) { return v / (1024 * 1024 * 1024) },
+};
+`), 0600))
+
+	plugin := &JavaScript{
+		Source: `
+var units = require("units");
+function apply(metric) {
+  metric.setField("value", units.toGiB(metric.field("value")));
+  return metric;
+}`,
+		Libraries: map[string]string{"units": lib},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(cpu(map[string]interface{}{"value": int64(3 << 29)}), &acc))
+	plugin.Stop()
+
+	expected := []telegraf.Metric{cpu(map[string]interface{}{"value": 1.5})}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestRequireUnknownLibrary(t *testing.T) {
+	plugin := &JavaScript{
+		Source: `var lib = require("unknown"); function apply(metric) { return metric }`,
+		Log:    testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `library "unknown" not configured`)
+}
+
+func TestTimeout(t *testing.T) {
+	plugin := &JavaScript{
+		Source:  "function apply(metric) { while (true) {} }",
+		Timeout: config.Duration(100 * time.Millisecond),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.ErrorContains(t, plugin.Add(cpu(map[string]interface{}{"value": 1.0}), &acc), "timeout exceeded")
+}
+
+func cpu(fields map[string]interface{}) telegraf.Metric {
+	return metric.New("cpu", map[string]string{"cpu": "cpu0"}, fields, time.Unix(1, 0))
+}
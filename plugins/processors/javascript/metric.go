@@ -0,0 +1,82 @@
+package javascript
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// jsMetric exposes a metric to the script, methods are available in
+// lower-camel-case, e.g. 'metric.setTag("host", "a")'
+type jsMetric struct {
+	metric telegraf.Metric
+}
+
+func newMetric(name string) *jsMetric {
+	return &jsMetric{metric: metric.New(name, nil, nil, time.Now())}
+}
+
+func (m *jsMetric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *jsMetric) SetName(name string) {
+	m.metric.SetName(name)
+}
+
+// Tag returns the value of the tag or null if the tag does not exist
+func (m *jsMetric) Tag(key string) interface{} {
+	if v, found := m.metric.GetTag(key); found {
+		return v
+	}
+	return nil
+}
+
+func (m *jsMetric) Tags() map[string]string {
+	return m.metric.Tags()
+}
+
+func (m *jsMetric) SetTag(key, value string) {
+	m.metric.AddTag(key, value)
+}
+
+func (m *jsMetric) RemoveTag(key string) {
+	m.metric.RemoveTag(key)
+}
+
+// Field returns the value of the field or null if the field does not exist
+func (m *jsMetric) Field(key string) interface{} {
+	if v, found := m.metric.GetField(key); found {
+		return v
+	}
+	return nil
+}
+
+func (m *jsMetric) Fields() map[string]interface{} {
+	return m.metric.Fields()
+}
+
+func (m *jsMetric) SetField(key string, value interface{}) {
+	m.metric.AddField(key, value)
+}
+
+func (m *jsMetric) RemoveField(key string) {
+	m.metric.RemoveField(key)
+}
+
+// Time returns the metric time in milliseconds since epoch as used by the
+// JavaScript Date type
+func (m *jsMetric) Time() float64 {
+	return float64(m.metric.Time().UnixNano()) / float64(time.Millisecond)
+}
+
+// SetTime sets the metric time from milliseconds since epoch
+func (m *jsMetric) SetTime(ms float64) {
+	m.metric.SetTime(time.Unix(0, int64(ms*float64(time.Millisecond))))
+}
+
+// Copy returns a deep copy of the metric
+func (m *jsMetric) Copy() *jsMetric {
+	return &jsMetric{metric: m.metric.Copy()}
+}
@@ -0,0 +1,238 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package join
+
+import (
+	_ "embed"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Join struct {
+	Left             string          `toml:"left"`
+	Right            string          `toml:"right"`
+	On               []string        `toml:"on"`
+	Window           config.Duration `toml:"window"`
+	Name             string          `toml:"name"`
+	LeftFieldPrefix  string          `toml:"left_field_prefix"`
+	RightFieldPrefix string          `toml:"right_field_prefix"`
+	EmitUnmatched    bool            `toml:"emit_unmatched"`
+	Log              telegraf.Logger `toml:"-"`
+
+	acc   telegraf.Accumulator
+	left  map[string]*entry
+	right map[string]*entry
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	sync.Mutex
+
+	// now is used to mock the current time in tests
+	now func() time.Time
+}
+
+// entry is a metric waiting for its join partner
+type entry struct {
+	metric telegraf.Metric
+	added  time.Time
+}
+
+func (*Join) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *Join) Init() error {
+	if j.Left == "" || j.Right == "" {
+		return errors.New("left and right measurements required")
+	}
+	if j.Left == j.Right {
+		return errors.New("left and right measurements must differ")
+	}
+	if len(j.On) == 0 {
+		return errors.New("no tags to join on")
+	}
+	if j.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+
+	j.left = make(map[string]*entry)
+	j.right = make(map[string]*entry)
+	if j.now == nil {
+		j.now = time.Now
+	}
+
+	return nil
+}
+
+func (j *Join) Start(acc telegraf.Accumulator) error {
+	j.acc = acc
+	j.done = make(chan struct{})
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(j.Window))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.done:
+				return
+			case <-ticker.C:
+				j.expire(j.now().Add(-time.Duration(j.Window)))
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (j *Join) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	var own, other map[string]*entry
+	switch m.Name() {
+	case j.Left:
+		own, other = j.left, j.right
+	case j.Right:
+		own, other = j.right, j.left
+	default:
+		acc.AddMetric(m)
+		return nil
+	}
+
+	key, ok := j.key(m)
+	if !ok {
+		acc.AddMetric(m)
+		return nil
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	if e, found := other[key]; found && j.within(e.metric.Time(), m.Time()) {
+		delete(other, key)
+		if m.Name() == j.Left {
+			acc.AddMetric(j.merge(m, e.metric))
+		} else {
+			acc.AddMetric(j.merge(e.metric, m))
+		}
+		return nil
+	}
+
+	// Replace a previous metric of the same series still waiting for its
+	// partner, only the latest metric is joined
+	if e, found := own[key]; found {
+		j.unmatched(e.metric)
+	}
+	own[key] = &entry{metric: m, added: j.now()}
+
+	return nil
+}
+
+func (j *Join) Stop() {
+	if j.done != nil {
+		close(j.done)
+		j.wg.Wait()
+	}
+
+	// Handle all metrics still waiting
+	j.expire(time.Time{})
+}
+
+// expire handles all waiting metrics added before the given threshold or
+// all metrics if the threshold is zero
+func (j *Join) expire(threshold time.Time) {
+	j.Lock()
+	defer j.Unlock()
+
+	for _, pending := range []map[string]*entry{j.left, j.right} {
+		for key, e := range pending {
+			if threshold.IsZero() || e.added.Before(threshold) {
+				j.unmatched(e.metric)
+				delete(pending, key)
+			}
+		}
+	}
+}
+
+func (j *Join) unmatched(m telegraf.Metric) {
+	if j.EmitUnmatched {
+		j.acc.AddMetric(m)
+		return
+	}
+	m.Drop()
+}
+
+// key returns the values of the join tags or false if the metric does not
+// contain all join tags
+func (j *Join) key(m telegraf.Metric) (string, bool) {
+	values := make([]string, 0, len(j.On))
+	for _, tag := range j.On {
+		v, found := m.GetTag(tag)
+		if !found {
+			return "", false
+		}
+		values = append(values, v)
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+func (j *Join) within(a, b time.Time) bool {
+	diff := a.Sub(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= time.Duration(j.Window)
+}
+
+// merge adds the tags and fields of the right metric to the left metric and
+// returns the left metric
+func (j *Join) merge(left, right telegraf.Metric) telegraf.Metric {
+	if j.Name != "" {
+		left.SetName(j.Name)
+	}
+
+	if j.LeftFieldPrefix != "" {
+		// Copy the fields as renaming modifies the field list
+		fields := make([]telegraf.Field, 0, len(left.FieldList()))
+		for _, field := range left.FieldList() {
+			fields = append(fields, *field)
+		}
+		for _, field := range fields {
+			left.RemoveField(field.Key)
+			left.AddField(j.LeftFieldPrefix+field.Key, field.Value)
+		}
+	}
+	for _, field := range right.FieldList() {
+		key := j.RightFieldPrefix + field.Key
+		if !left.HasField(key) {
+			left.AddField(key, field.Value)
+		}
+	}
+	for _, tag := range right.TagList() {
+		if !left.HasTag(tag.Key) {
+			left.AddTag(tag.Key, tag.Value)
+		}
+	}
+
+	// The right metric is consumed by the join
+	right.Accept()
+
+	return left
+}
+
+func init() {
+	processors.AddStreaming("join", func() telegraf.StreamingProcessor {
+		return &Join{
+			Window:        config.Duration(10 * time.Second),
+			EmitUnmatched: true,
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Join
+		expected string
+	}{
+		{
+			name:     "no right",
+			plugin:   &Join{Left: "disk"},
+			expected: "left and right measurements required",
+		},
+		{
+			name:     "same measurements",
+			plugin:   &Join{Left: "disk", Right: "disk"},
+			expected: "left and right measurements must differ",
+		},
+		{
+			name:     "no tags",
+			plugin:   &Join{Left: "disk", Right: "smart_device"},
+			expected: "no tags to join on",
+		},
+		{
+			name:     "no window",
+			plugin:   &Join{Left: "disk", Right: "smart_device", On: []string{"device"}},
+			expected: "window must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("disk",
+			map[string]string{"device": "sda", "host": "a"},
+			map[string]interface{}{"used": 10, "temp": 1},
+			time.Unix(10, 0),
+		),
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage": 42.0},
+			time.Unix(10, 0),
+		),
+		metric.New("smart_device",
+			map[string]string{"device": "sda", "model": "foo"},
+			map[string]interface{}{"temp": 35, "health_ok": true},
+			time.Unix(12, 0),
+		),
+		metric.New("smart_device",
+			map[string]string{"device": "sdb"},
+			map[string]interface{}{"temp": 30},
+			time.Unix(12, 0),
+		),
+		metric.New("disk",
+			map[string]string{"device": "sdb"},
+			map[string]interface{}{"used": 20},
+			time.Unix(30, 0),
+		),
+	}
+
+	tests := []struct {
+		name      string
+		plugin    *Join
+		expected  []telegraf.Metric
+		unmatched []telegraf.Metric
+	}{
+		{
+			name: "default",
+			plugin: &Join{
+				Left:          "disk",
+				Right:         "smart_device",
+				On:            []string{"device"},
+				EmitUnmatched: true,
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"usage": 42.0},
+					time.Unix(10, 0),
+				),
+				metric.New("disk",
+					map[string]string{"device": "sda", "host": "a", "model": "foo"},
+					map[string]interface{}{"used": 10, "temp": 1, "health_ok": true},
+					time.Unix(10, 0),
+				),
+			},
+			unmatched: []telegraf.Metric{
+				metric.New("smart_device",
+					map[string]string{"device": "sdb"},
+					map[string]interface{}{"temp": 30},
+					time.Unix(12, 0),
+				),
+				metric.New("disk",
+					map[string]string{"device": "sdb"},
+					map[string]interface{}{"used": 20},
+					time.Unix(30, 0),
+				),
+			},
+		},
+		{
+			name: "name and prefixes without unmatched",
+			plugin: &Join{
+				Left:             "disk",
+				Right:            "smart_device",
+				On:               []string{"device"},
+				Name:             "disk_health",
+				LeftFieldPrefix:  "disk_",
+				RightFieldPrefix: "smart_",
+			},
+			expected: []telegraf.Metric{
+				metric.New("cpu",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"usage": 42.0},
+					time.Unix(10, 0),
+				),
+				metric.New("disk_health",
+					map[string]string{"device": "sda", "host": "a", "model": "foo"},
+					map[string]interface{}{"disk_used": 10, "disk_temp": 1, "smart_temp": 35, "smart_health_ok": true},
+					time.Unix(10, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := tt.plugin
+			plugin.Window = config.Duration(5 * time.Second)
+			plugin.Log = testutil.Logger{}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			for _, m := range input {
+				require.NoError(t, plugin.Add(m.Copy(), &acc))
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics())
+
+			// Waiting metrics are handled on stop
+			plugin.Stop()
+			expected := append(tt.expected, tt.unmatched...)
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+		})
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Unix(100, 0)
+	plugin := &Join{
+		Left:          "disk",
+		Right:         "smart_device",
+		On:            []string{"device"},
+		Window:        config.Duration(5 * time.Second),
+		EmitUnmatched: true,
+		Log:           testutil.Logger{},
+		now:           func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	m := metric.New("disk", map[string]string{"device": "sda"}, map[string]interface{}{"used": 10}, time.Unix(100, 0))
+	require.NoError(t, plugin.Add(m, &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	plugin.expire(now.Add(-time.Second))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	plugin.expire(now.Add(time.Second))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{m}, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,343 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package k8s_metadata
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	indexPodName     = "pod_name"
+	indexPodIP       = "pod_ip"
+	indexContainerID = "container_id"
+)
+
+type K8sMetadata struct {
+	KubeConfig        string          `toml:"kubeconfig"`
+	Namespace         string          `toml:"namespace"`
+	NodeName          string          `toml:"node_name"`
+	PodNameTag        string          `toml:"pod_name_tag"`
+	NamespaceTag      string          `toml:"namespace_tag"`
+	PodIPTag          string          `toml:"pod_ip_tag"`
+	ContainerIDTag    string          `toml:"container_id_tag"`
+	LabelInclude      []string        `toml:"label_include"`
+	LabelExclude      []string        `toml:"label_exclude"`
+	AnnotationInclude []string        `toml:"annotation_include"`
+	AnnotationExclude []string        `toml:"annotation_exclude"`
+	ResyncPeriod      config.Duration `toml:"resync_period"`
+	SyncTimeout       config.Duration `toml:"sync_timeout"`
+	Log               telegraf.Logger `toml:"-"`
+
+	labelFilter      filter.Filter
+	annotationFilter filter.Filter
+
+	client  kubernetes.Interface
+	indexer cache.Indexer
+	cancel  context.CancelFunc
+}
+
+func (*K8sMetadata) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *K8sMetadata) Init() error {
+	if k.PodNameTag == "" && k.PodIPTag == "" && k.ContainerIDTag == "" {
+		return errors.New("at least one of 'pod_name_tag', 'pod_ip_tag' or 'container_id_tag' is required")
+	}
+
+	// No labels or annotations are added if neither include nor exclude
+	// patterns are given
+	if len(k.LabelInclude) > 0 || len(k.LabelExclude) > 0 {
+		f, err := filter.NewIncludeExcludeFilter(k.LabelInclude, k.LabelExclude)
+		if err != nil {
+			return fmt.Errorf("creating label filter failed: %w", err)
+		}
+		k.labelFilter = f
+	}
+	if len(k.AnnotationInclude) > 0 || len(k.AnnotationExclude) > 0 {
+		f, err := filter.NewIncludeExcludeFilter(k.AnnotationInclude, k.AnnotationExclude)
+		if err != nil {
+			return fmt.Errorf("creating annotation filter failed: %w", err)
+		}
+		k.annotationFilter = f
+	}
+
+	if k.SyncTimeout <= 0 {
+		k.SyncTimeout = config.Duration(30 * time.Second)
+	}
+
+	return nil
+}
+
+func (k *K8sMetadata) Start(_ telegraf.Accumulator) error {
+	if k.client == nil {
+		client, err := newClient(k.KubeConfig)
+		if err != nil {
+			return err
+		}
+		k.client = client
+	}
+
+	// Only watch the pods of interest to keep the load on the API server
+	// and the memory footprint low
+	options := []informers.SharedInformerOption{
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			if k.NodeName != "" {
+				opts.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", k.NodeName).String()
+			}
+		}),
+	}
+	if k.Namespace != "" {
+		options = append(options, informers.WithNamespace(k.Namespace))
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(k.client, time.Duration(k.ResyncPeriod), options...)
+
+	informer := factory.Core().V1().Pods().Informer()
+	err := informer.AddIndexers(cache.Indexers{
+		indexPodName:     indexByPodName,
+		indexPodIP:       indexByPodIP,
+		indexContainerID: indexByContainerID,
+	})
+	if err != nil {
+		return fmt.Errorf("adding indexers failed: %w", err)
+	}
+	k.indexer = informer.GetIndexer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+	factory.Start(ctx.Done())
+
+	// Do not block startup forever if the API server is unavailable, the
+	// informer will continue to sync in the background
+	syncCtx, syncCancel := context.WithTimeout(ctx, time.Duration(k.SyncTimeout))
+	defer syncCancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced) {
+		k.Log.Warn("Pod cache not synced yet, metrics will not be enriched until the sync completed")
+	}
+
+	return nil
+}
+
+func (k *K8sMetadata) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	if pod := k.lookup(m); pod != nil {
+		k.annotate(m, pod)
+	}
+	acc.AddMetric(m)
+	return nil
+}
+
+func (k *K8sMetadata) Stop() {
+	if k.cancel != nil {
+		k.cancel()
+	}
+}
+
+// lookup returns the pod referenced by the metric trying the container ID,
+// the pod name and the pod IP in this order
+func (k *K8sMetadata) lookup(m telegraf.Metric) *corev1.Pod {
+	if k.ContainerIDTag != "" {
+		if id, found := m.GetTag(k.ContainerIDTag); found {
+			if pod := k.lookupIndex(indexContainerID, trimRuntime(id), ""); pod != nil {
+				return pod
+			}
+		}
+	}
+
+	if k.PodNameTag != "" {
+		if name, found := m.GetTag(k.PodNameTag); found {
+			namespace, _ := m.GetTag(k.NamespaceTag)
+			if pod := k.lookupIndex(indexPodName, name, namespace); pod != nil {
+				return pod
+			}
+		}
+	}
+
+	if k.PodIPTag != "" {
+		if ip, found := m.GetTag(k.PodIPTag); found {
+			if pod := k.lookupIndex(indexPodIP, ip, ""); pod != nil {
+				return pod
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupIndex returns the pod matching the given index value. If the value
+// is ambiguous, e.g. a pod name existing in multiple namespaces or an IP
+// reused by pods using the host network, nil is returned unless the namespace
+// resolves the ambiguity.
+func (k *K8sMetadata) lookupIndex(index, value, namespace string) *corev1.Pod {
+	if k.indexer == nil || value == "" {
+		return nil
+	}
+	objs, err := k.indexer.ByIndex(index, value)
+	if err != nil {
+		k.Log.Errorf("Looking up %q in index %q failed: %v", value, index, err)
+		return nil
+	}
+
+	var match *corev1.Pod
+	for _, obj := range objs {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok || (namespace != "" && pod.Namespace != namespace) {
+			continue
+		}
+		if match != nil {
+			k.Log.Debugf("Ambiguous %s %q", index, value)
+			return nil
+		}
+		match = pod
+	}
+	return match
+}
+
+// annotate adds the metadata of the pod as tags to the metric
+func (k *K8sMetadata) annotate(m telegraf.Metric, pod *corev1.Pod) {
+	m.AddTag("namespace", pod.Namespace)
+	m.AddTag("pod_name", pod.Name)
+	if pod.Spec.NodeName != "" {
+		m.AddTag("node_name", pod.Spec.NodeName)
+	}
+	if kind, name := workload(pod); kind != "" {
+		m.AddTag("workload_kind", kind)
+		m.AddTag("workload_name", name)
+	}
+
+	if k.labelFilter != nil {
+		for key, val := range pod.Labels {
+			if k.labelFilter.Match(key) {
+				m.AddTag("label_"+key, val)
+			}
+		}
+	}
+	if k.annotationFilter != nil {
+		for key, val := range pod.Annotations {
+			if k.annotationFilter.Match(key) {
+				m.AddTag("annotation_"+key, val)
+			}
+		}
+	}
+}
+
+// workload returns the kind and name of the controller owning the pod. For
+// pods of a ReplicaSet managed by a Deployment the Deployment is returned,
+// derived from the ReplicaSet name to avoid watching ReplicaSets.
+func workload(pod *corev1.Pod) (kind, name string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", ""
+	}
+
+	if owner.Kind == "ReplicaSet" {
+		if hash, found := pod.Labels["pod-template-hash"]; found && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+func indexByPodName(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	return []string{pod.Name}, nil
+}
+
+func indexByPodIP(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	ips := make([]string, 0, len(pod.Status.PodIPs))
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, ip.IP)
+	}
+	if len(ips) == 0 && pod.Status.PodIP != "" {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	return ips, nil
+}
+
+func indexByContainerID(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	var ids []string
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.InitContainerStatuses,
+		pod.Status.ContainerStatuses,
+		pod.Status.EphemeralContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if status.ContainerID != "" {
+				ids = append(ids, trimRuntime(status.ContainerID))
+			}
+		}
+	}
+	return ids, nil
+}
+
+// trimRuntime removes the runtime prefix, e.g. "containerd://", from the
+// container ID
+func trimRuntime(id string) string {
+	if idx := strings.Index(id, "://"); idx >= 0 {
+		return id[idx+3:]
+	}
+	return id
+}
+
+// newClient creates a Kubernetes client using the given kubeconfig file or
+// the in-cluster configuration if no file is given
+func newClient(kubeconfig string) (kubernetes.Interface, error) {
+	var cfg *rest.Config
+	var err error
+	if kubeconfig == "" {
+		cfg, err = rest.InClusterConfig()
+	} else {
+		cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading Kubernetes configuration failed: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating Kubernetes client failed: %w", err)
+	}
+	return client, nil
+}
+
+func init() {
+	processors.AddStreaming("k8s_metadata", func() telegraf.StreamingProcessor {
+		return &K8sMetadata{
+			PodNameTag:   "pod_name",
+			NamespaceTag: "namespace",
+			ResyncPeriod: config.Duration(time.Hour),
+			SyncTimeout:  config.Duration(30 * time.Second),
+		}
+	})
+}
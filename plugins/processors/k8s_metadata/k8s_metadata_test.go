@@ -0,0 +1,201 @@
+package k8s_metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &K8sMetadata{Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), "at least one of")
+
+	plugin = &K8sMetadata{
+		PodNameTag:   "pod_name",
+		LabelInclude: []string{"app["},
+		Log:          testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "creating label filter failed")
+}
+
+func TestEnrich(t *testing.T) {
+	isController := true
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web-7d4b9c8f5-x2kqp",
+				Namespace:   "shop",
+				Labels:      map[string]string{"app": "web", "pod-template-hash": "7d4b9c8f5", "team": "frontend"},
+				Annotations: map[string]string{"prometheus.io/scrape": "true"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "web-7d4b9c8f5", Controller: &isController},
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{
+				PodIP:  "10.244.1.12",
+				PodIPs: []corev1.PodIP{{IP: "10.244.1.12"}},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "nginx", ContainerID: "containerd://3f1c2d9a8b7e"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "db-0",
+				Namespace: "shop",
+				Labels:    map[string]string{"app": "db"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "StatefulSet", Name: "db", Controller: &isController},
+				},
+			},
+			Spec:   corev1.PodSpec{NodeName: "node-2"},
+			Status: corev1.PodStatus{PodIP: "10.244.2.7"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "staging"},
+			Spec:       corev1.PodSpec{NodeName: "node-2"},
+		},
+	}
+
+	client := fake.NewSimpleClientset()
+	for _, pod := range pods {
+		require.NoError(t, client.Tracker().Add(pod))
+	}
+
+	plugin := &K8sMetadata{
+		PodNameTag:        "pod_name",
+		NamespaceTag:      "namespace",
+		PodIPTag:          "ip",
+		ContainerIDTag:    "container_id",
+		LabelInclude:      []string{"app"},
+		AnnotationInclude: []string{"prometheus.io/*"},
+		SyncTimeout:       config.Duration(5 * time.Second),
+		Log:               testutil.Logger{},
+		client:            client,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		// Container ID without runtime prefix
+		testutil.MustMetric("docker", map[string]string{"container_id": "3f1c2d9a8b7e"}, map[string]interface{}{"cpu": 1.5}, time.Unix(0, 0)),
+		// Pod name with namespace
+		testutil.MustMetric("app", map[string]string{"pod_name": "db-0", "namespace": "shop"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		// Pod name without namespace is ambiguous
+		testutil.MustMetric("app", map[string]string{"pod_name": "db-0"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		// Pod IP
+		testutil.MustMetric("netflow", map[string]string{"ip": "10.244.2.7"}, map[string]interface{}{"bytes": int64(100)}, time.Unix(0, 0)),
+		// Unknown pod
+		testutil.MustMetric("netflow", map[string]string{"ip": "10.0.0.1"}, map[string]interface{}{"bytes": int64(200)}, time.Unix(0, 0)),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"docker",
+			map[string]string{
+				"container_id":                    "3f1c2d9a8b7e",
+				"namespace":                       "shop",
+				"pod_name":                        "web-7d4b9c8f5-x2kqp",
+				"node_name":                       "node-1",
+				"workload_kind":                   "Deployment",
+				"workload_name":                   "web",
+				"label_app":                       "web",
+				"annotation_prometheus.io/scrape": "true",
+			},
+			map[string]interface{}{"cpu": 1.5},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"app",
+			map[string]string{
+				"namespace":     "shop",
+				"pod_name":      "db-0",
+				"node_name":     "node-2",
+				"workload_kind": "StatefulSet",
+				"workload_name": "db",
+				"label_app":     "db",
+			},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("app", map[string]string{"pod_name": "db-0"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"ip":            "10.244.2.7",
+				"namespace":     "shop",
+				"pod_name":      "db-0",
+				"node_name":     "node-2",
+				"workload_kind": "StatefulSet",
+				"workload_name": "db",
+				"label_app":     "db",
+			},
+			map[string]interface{}{"bytes": int64(100)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("netflow", map[string]string{"ip": "10.0.0.1"}, map[string]interface{}{"bytes": int64(200)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestWorkload(t *testing.T) {
+	isController := true
+	tests := []struct {
+		name         string
+		pod          *corev1.Pod
+		expectedKind string
+		expectedName string
+	}{
+		{
+			name: "no owner",
+			pod:  &corev1.Pod{},
+		},
+		{
+			name: "replicaset without deployment",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "ReplicaSet", Name: "standalone", Controller: &isController},
+					},
+				},
+			},
+			expectedKind: "ReplicaSet",
+			expectedName: "standalone",
+		},
+		{
+			name: "daemonset",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "DaemonSet", Name: "telegraf", Controller: &isController},
+					},
+				},
+			},
+			expectedKind: "DaemonSet",
+			expectedName: "telegraf",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, name := workload(tt.pod)
+			require.Equal(t, tt.expectedKind, kind)
+			require.Equal(t, tt.expectedName, name)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package lookup
+
+import (
+	// Blank imports to register the SQL drivers
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v4/stdlib"
+	_ "github.com/microsoft/go-mssqldb"
+)
@@ -3,17 +3,24 @@ package lookup
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
@@ -21,13 +28,23 @@ import (
 var sampleConfig string
 
 type Processor struct {
-	Filenames   []string        `toml:"files"`
-	Fileformat  string          `toml:"format"`
-	KeyTemplate string          `toml:"key"`
-	Log         telegraf.Logger `toml:"-"`
+	Filenames       []string          `toml:"files"`
+	URL             string            `toml:"url"`
+	Headers         map[string]string `toml:"headers"`
+	SQL             *SQLSource        `toml:"sql"`
+	Redis           *RedisSource      `toml:"redis"`
+	Fileformat      string            `toml:"format"`
+	KeyTemplate     string            `toml:"key"`
+	RefreshInterval config.Duration   `toml:"refresh_interval"`
+	Log             telegraf.Logger   `toml:"-"`
+	httpconfig.HTTPClientConfig
 
 	tmpl     *template.Template
+	client   *http.Client
 	mappings map[string][]telegraf.Tag
+	mu       sync.RWMutex
+	lastLoad time.Time
+	loading  atomic.Bool
 }
 
 func (*Processor) SampleConfig() string {
@@ -35,8 +52,8 @@ func (*Processor) SampleConfig() string {
 }
 
 func (p *Processor) Init() error {
-	if len(p.Filenames) < 1 {
-		return errors.New("missing 'files'")
+	if len(p.Filenames) < 1 && p.URL == "" && p.SQL == nil && p.Redis == nil {
+		return errors.New("missing 'files', 'url', 'sql' or 'redis'")
 	}
 
 	if p.KeyTemplate == "" {
@@ -49,20 +66,50 @@ func (p *Processor) Init() error {
 	}
 	p.tmpl = tmpl
 
-	p.mappings = make(map[string][]telegraf.Tag)
-	switch strings.ToLower(p.Fileformat) {
-	case "", "json":
-		return p.loadJSONFiles()
-	case "csv_key_name_value":
-		return p.loadCSVKeyNameValueFiles()
-	case "csv_key_values":
-		return p.loadCSVKeyValuesFiles()
+	p.Fileformat = strings.ToLower(p.Fileformat)
+	switch p.Fileformat {
+	case "":
+		p.Fileformat = "json"
+	case "json", "csv_key_name_value", "csv_key_values":
+	default:
+		return fmt.Errorf("invalid format %q", p.Fileformat)
+	}
+
+	if p.URL != "" {
+		client, err := p.HTTPClientConfig.CreateClient(context.Background(), p.Log)
+		if err != nil {
+			return fmt.Errorf("creating HTTP client failed: %w", err)
+		}
+		p.client = client
+	}
+	if p.SQL != nil {
+		if err := p.SQL.init(); err != nil {
+			return fmt.Errorf("initializing SQL source failed: %w", err)
+		}
+	}
+	if p.Redis != nil {
+		if err := p.Redis.init(); err != nil {
+			return fmt.Errorf("initializing Redis source failed: %w", err)
+		}
 	}
 
-	return fmt.Errorf("invalid format %q", p.Fileformat)
+	mappings, err := p.load()
+	if err != nil {
+		return err
+	}
+	p.mappings = mappings
+	p.lastLoad = time.Now()
+
+	return nil
 }
 
 func (p *Processor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	p.refresh()
+
+	p.mu.RLock()
+	mappings := p.mappings
+	p.mu.RUnlock()
+
 	out := make([]telegraf.Metric, 0, len(in))
 	for _, raw := range in {
 		m := raw
@@ -74,54 +121,144 @@ func (p *Processor) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		if err := p.tmpl.Execute(&buf, m); err != nil {
 			p.Log.Errorf("generating key failed: %v", err)
 			p.Log.Debugf("metric was %v", m)
-		} else if tags, found := p.mappings[buf.String()]; found {
-			for _, tag := range tags {
-				m.AddTag(tag.Key, tag.Value)
+			out = append(out, raw)
+			continue
+		}
+
+		key := buf.String()
+		tags, found := mappings[key]
+		if !found && p.Redis != nil {
+			var err error
+			tags, err = p.Redis.lookup(key)
+			if err != nil {
+				p.Log.Errorf("looking up %q in Redis failed: %v", key, err)
 			}
 		}
+		for _, tag := range tags {
+			m.AddTag(tag.Key, tag.Value)
+		}
 		out = append(out, raw)
 	}
 	return out
 }
 
-func (p *Processor) loadJSONFiles() error {
-	for _, fn := range p.Filenames {
-		buf, err := os.ReadFile(fn)
-		if err != nil {
-			return fmt.Errorf("loading %q failed: %w", fn, err)
-		}
+// refresh reloads the lookup-table in the background if the refresh
+// interval elapsed. The current table is used until the new one is loaded
+// and kept if loading fails.
+func (p *Processor) refresh() {
+	if p.RefreshInterval <= 0 || time.Since(p.lastLoad) < time.Duration(p.RefreshInterval) {
+		return
+	}
+	if len(p.Filenames) == 0 && p.URL == "" && p.SQL == nil {
+		return
+	}
+	if !p.loading.CompareAndSwap(false, true) {
+		return
+	}
+	p.lastLoad = time.Now()
 
-		var data map[string]map[string]string
-		if err := json.Unmarshal(buf, &data); err != nil {
-			return fmt.Errorf("parsing %q failed: %w", fn, err)
-		}
+	go func() {
+		defer p.loading.Store(false)
 
-		for key, tags := range data {
-			for k, v := range tags {
-				p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: k, Value: v})
-			}
+		mappings, err := p.load()
+		if err != nil {
+			p.Log.Errorf("refreshing lookup-table failed: %v", err)
+			return
 		}
-	}
-	return nil
+		p.mu.Lock()
+		p.mappings = mappings
+		p.mu.Unlock()
+	}()
 }
 
-func (p *Processor) loadCSVKeyNameValueFiles() error {
+// load reads the lookup-table from all configured files, the URL and the SQL query
+func (p *Processor) load() (map[string][]telegraf.Tag, error) {
+	mappings := make(map[string][]telegraf.Tag)
 	for _, fn := range p.Filenames {
-		if err := p.loadCSVKeyNameValueFile(fn); err != nil {
-			return err
+		if err := p.loadFile(fn, mappings); err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	if p.URL != "" {
+		if err := p.loadURL(mappings); err != nil {
+			return nil, err
+		}
+	}
+	if p.SQL != nil {
+		if err := p.SQL.load(mappings); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
 }
 
-func (p *Processor) loadCSVKeyNameValueFile(fn string) error {
+func (p *Processor) loadFile(fn string, mappings map[string][]telegraf.Tag) error {
 	f, err := os.Open(fn)
 	if err != nil {
 		return fmt.Errorf("loading %q failed: %w", fn, err)
 	}
 	defer f.Close()
 
-	reader := csv.NewReader(f)
+	return p.parse(fn, f, mappings)
+}
+
+func (p *Processor) loadURL(mappings map[string][]telegraf.Tag) error {
+	req, err := http.NewRequest(http.MethodGet, p.URL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request for %q failed: %w", p.URL, err)
+	}
+	for k, v := range p.Headers {
+		if strings.EqualFold(k, "host") {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("loading %q failed: %w", p.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("loading %q failed: received status %s", p.URL, resp.Status)
+	}
+
+	return p.parse(p.URL, resp.Body, mappings)
+}
+
+func (p *Processor) parse(source string, r io.Reader, mappings map[string][]telegraf.Tag) error {
+	switch p.Fileformat {
+	case "csv_key_name_value":
+		return parseCSVKeyNameValue(source, r, mappings)
+	case "csv_key_values":
+		return parseCSVKeyValues(source, r, mappings)
+	}
+	return parseJSON(source, r, mappings)
+}
+
+func parseJSON(source string, r io.Reader, mappings map[string][]telegraf.Tag) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("loading %q failed: %w", source, err)
+	}
+
+	var data map[string]map[string]string
+	if err := json.Unmarshal(buf, &data); err != nil {
+		return fmt.Errorf("parsing %q failed: %w", source, err)
+	}
+
+	for key, tags := range data {
+		for k, v := range tags {
+			mappings[key] = append(mappings[key], telegraf.Tag{Key: k, Value: v})
+		}
+	}
+	return nil
+}
+
+func parseCSVKeyNameValue(fn string, r io.Reader, mappings map[string][]telegraf.Tag) error {
+	reader := csv.NewReader(r)
 	reader.Comment = '#'
 	reader.FieldsPerRecord = -1
 	reader.TrimLeadingSpace = true
@@ -146,30 +283,15 @@ func (p *Processor) loadCSVKeyNameValueFile(fn string) error {
 		key := data[0]
 		for i := 1; i < len(data)-1; i += 2 {
 			k, v := data[i], data[i+1]
-			p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: k, Value: v})
+			mappings[key] = append(mappings[key], telegraf.Tag{Key: k, Value: v})
 		}
 	}
 
 	return nil
 }
 
-func (p *Processor) loadCSVKeyValuesFiles() error {
-	for _, fn := range p.Filenames {
-		if err := p.loadCSVKeyValuesFile(fn); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (p *Processor) loadCSVKeyValuesFile(fn string) error {
-	f, err := os.Open(fn)
-	if err != nil {
-		return fmt.Errorf("loading %q failed: %w", fn, err)
-	}
-	defer f.Close()
-
-	reader := csv.NewReader(f)
+func parseCSVKeyValues(fn string, r io.Reader, mappings map[string][]telegraf.Tag) error {
+	reader := csv.NewReader(r)
 	reader.Comment = '#'
 	reader.TrimLeadingSpace = true
 
@@ -201,15 +323,20 @@ func (p *Processor) loadCSVKeyValuesFile(fn string) error {
 		for i, v := range data[1:] {
 			v = strings.TrimSpace(v)
 			if v != "" {
-				p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: header[i], Value: v})
+				mappings[key] = append(mappings[key], telegraf.Tag{Key: header[i], Value: v})
 			}
 		}
 	}
 
 	return nil
 }
+
 func init() {
 	processors.Add("lookup", func() telegraf.Processor {
-		return &Processor{}
+		return &Processor{
+			HTTPClientConfig: httpconfig.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
 	})
 }
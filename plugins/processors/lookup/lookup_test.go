@@ -1,12 +1,18 @@
 package lookup
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
@@ -175,3 +181,171 @@ func TestCasesTracking(t *testing.T) {
 		})
 	}
 }
+
+func TestURL(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if requests.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"xyzzy-green": {"location": "eu-central"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"xyzzy-green": {"location": "us-west"}}`))
+	}))
+	defer server.Close()
+
+	plugin := &Processor{
+		URL:             server.URL,
+		Headers:         map[string]string{"Authorization": "Bearer secret"},
+		KeyTemplate:     `{{.Tag "host"}}`,
+		RefreshInterval: config.Duration(100 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := testutil.MustMetric("test", map[string]string{"host": "xyzzy-green"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"test",
+			map[string]string{"host": "xyzzy-green", "location": "eu-central"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input.Copy()))
+
+	// Wait for the refreshed table to be used
+	require.Eventually(t, func() bool {
+		actual := plugin.Apply(input.Copy())
+		location, _ := actual[0].GetTag("location")
+		return location == "us-west"
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func TestURLFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	plugin := &Processor{
+		URL:         server.URL,
+		KeyTemplate: `{{.Tag "host"}}`,
+		Log:         testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "received status 404")
+}
+
+func TestSQL(t *testing.T) {
+	dsn := "lookup_sql"
+	db, mock, err := sqlmock.NewWithDSN(dsn, sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := "SELECT hostname, rack, datacenter FROM hosts"
+	mock.ExpectQuery(query).WillReturnRows(
+		sqlmock.NewRows([]string{"hostname", "rack", "datacenter"}).
+			AddRow("xyzzy-green", "C12-01", "eu-central").
+			AddRow("xyzzy-red", nil, "us-west").
+			AddRow(nil, "C01-01", "us-east"),
+	)
+
+	plugin := &Processor{
+		SQL: &SQLSource{
+			Driver: "sqlmock",
+			DSN:    config.NewSecret([]byte(dsn)),
+			Query:  query,
+		},
+		KeyTemplate: `{{.Tag "host"}}`,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("test", map[string]string{"host": "xyzzy-green"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		testutil.MustMetric("test", map[string]string{"host": "xyzzy-red"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		testutil.MustMetric("test", map[string]string{"host": "xyzzy-blue"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"test",
+			map[string]string{"host": "xyzzy-green", "rack": "C12-01", "datacenter": "eu-central"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"test",
+			map[string]string{"host": "xyzzy-red", "datacenter": "us-west"},
+			map[string]interface{}{"value": 2},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("test", map[string]string{"host": "xyzzy-blue"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+type fakeRedis struct {
+	data  map[string]map[string]string
+	calls int
+}
+
+func (r *fakeRedis) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	r.calls++
+	if key == "lookup:broken" {
+		return nil, errors.New("connection refused")
+	}
+	return r.data[key], nil
+}
+
+func TestRedis(t *testing.T) {
+	plugin := &Processor{
+		Redis: &RedisSource{
+			KeyPrefix: "lookup:",
+			CacheTTL:  config.Duration(time.Minute),
+		},
+		KeyTemplate: `{{.Tag "host"}}`,
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	client := &fakeRedis{
+		data: map[string]map[string]string{
+			"lookup:xyzzy-green": {"location": "eu-central", "rack": "C12-01"},
+		},
+	}
+	plugin.Redis.client = client
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("test", map[string]string{"host": "xyzzy-green"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		testutil.MustMetric("test", map[string]string{"host": "xyzzy-red"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		testutil.MustMetric("test", map[string]string{"host": "broken"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"test",
+			map[string]string{"host": "xyzzy-green", "location": "eu-central", "rack": "C12-01"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("test", map[string]string{"host": "xyzzy-red"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		testutil.MustMetric("test", map[string]string{"host": "broken"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+	require.Equal(t, 3, client.calls)
+
+	// Existing and missing keys are served from the cache, failed lookups are retried
+	plugin.Apply(input[0].Copy(), input[1].Copy(), input[2].Copy())
+	require.Equal(t, 4, client.calls)
+
+	// Expired entries are queried again
+	for k, entry := range plugin.Redis.cache {
+		entry.expires = time.Now().Add(-time.Second)
+		plugin.Redis.cache[k] = entry
+	}
+	plugin.Apply(input[0].Copy())
+	require.Equal(t, 5, client.calls)
+}
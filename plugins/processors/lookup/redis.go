@@ -0,0 +1,158 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+type redisClient interface {
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+}
+
+type goRedisClient struct {
+	client *redis.Client
+}
+
+func (c *goRedisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return c.client.HGetAll(ctx, key).Result()
+}
+
+type redisEntry struct {
+	tags    []telegraf.Tag
+	expires time.Time
+}
+
+// RedisSource looks up keys on demand as Redis hashes, with the hash fields
+// being used as tags. Results, including missing keys, are cached for the
+// configured TTL.
+type RedisSource struct {
+	Address   string          `toml:"address"`
+	Username  config.Secret   `toml:"username"`
+	Password  config.Secret   `toml:"password"`
+	DB        int             `toml:"db"`
+	KeyPrefix string          `toml:"key_prefix"`
+	CacheTTL  config.Duration `toml:"cache_ttl"`
+	Timeout   config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client    redisClient
+	cache     map[string]redisEntry
+	lastPurge time.Time
+	sync.Mutex
+}
+
+func (r *RedisSource) init() error {
+	if r.Address == "" {
+		r.Address = "tcp://localhost:6379"
+	}
+	if r.CacheTTL <= 0 {
+		r.CacheTTL = config.Duration(5 * time.Minute)
+	}
+	if r.Timeout <= 0 {
+		r.Timeout = config.Duration(time.Second)
+	}
+
+	u, err := url.Parse(r.Address)
+	if err != nil {
+		return fmt.Errorf("parsing address %q failed: %w", r.Address, err)
+	}
+	var address string
+	switch u.Scheme {
+	case "tcp":
+		address = u.Host
+	case "unix":
+		address = u.Path
+	default:
+		return fmt.Errorf("invalid scheme %q in address %q", u.Scheme, r.Address)
+	}
+
+	username, err := r.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer username.Destroy()
+	password, err := r.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer password.Destroy()
+
+	tlsConfig, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	r.client = &goRedisClient{
+		client: redis.NewClient(&redis.Options{
+			Addr:        address,
+			Network:     u.Scheme,
+			Username:    username.String(),
+			Password:    password.String(),
+			DB:          r.DB,
+			DialTimeout: time.Duration(r.Timeout),
+			ReadTimeout: time.Duration(r.Timeout),
+			PoolSize:    1,
+			TLSConfig:   tlsConfig,
+		}),
+	}
+	r.cache = make(map[string]redisEntry)
+	r.lastPurge = time.Now()
+
+	return nil
+}
+
+// lookup returns the tags for the given key, querying Redis if the key is
+// not cached or the cached entry expired
+func (r *RedisSource) lookup(key string) ([]telegraf.Tag, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	r.purge(now)
+	if entry, found := r.cache[key]; found && now.Before(entry.expires) {
+		return entry.tags, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
+	defer cancel()
+	data, err := r.client.HGetAll(ctx, r.KeyPrefix+key)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	tags := make([]telegraf.Tag, 0, len(data))
+	for k, v := range data {
+		if v = strings.TrimSpace(v); v != "" {
+			tags = append(tags, telegraf.Tag{Key: k, Value: v})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	r.cache[key] = redisEntry{tags: tags, expires: now.Add(time.Duration(r.CacheTTL))}
+
+	return tags, nil
+}
+
+// purge removes expired entries from the cache, at most once per TTL period
+func (r *RedisSource) purge(now time.Time) {
+	if now.Sub(r.lastPurge) < time.Duration(r.CacheTTL) {
+		return
+	}
+	r.lastPurge = now
+	for key, entry := range r.cache {
+		if !now.Before(entry.expires) {
+			delete(r.cache, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package lookup
+
+import (
+	"context"
+	dbsql "database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// SQLSource loads the lookup-table from the result of a SQL query. The first
+// column of the result contains the key, all other columns are used as tags
+// with the column name as tag-key.
+type SQLSource struct {
+	Driver  string          `toml:"driver"`
+	DSN     config.Secret   `toml:"dsn"`
+	Query   string          `toml:"query"`
+	Timeout config.Duration `toml:"timeout"`
+
+	db *dbsql.DB
+}
+
+func (s *SQLSource) init() error {
+	if s.Driver == "" {
+		return errors.New("missing 'driver'")
+	}
+	if s.Query == "" {
+		return errors.New("missing 'query'")
+	}
+	if s.Timeout <= 0 {
+		s.Timeout = config.Duration(5 * time.Second)
+	}
+
+	dsnSecret, err := s.DSN.Get()
+	if err != nil {
+		return fmt.Errorf("getting DSN failed: %w", err)
+	}
+	dsn := dsnSecret.String()
+	dsnSecret.Destroy()
+
+	db, err := dbsql.Open(s.Driver, dsn)
+	if err != nil {
+		return err
+	}
+	db.SetMaxOpenConns(1)
+	s.db = db
+
+	return nil
+}
+
+func (s *SQLSource) load(mappings map[string][]telegraf.Tag) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, s.Query)
+	if err != nil {
+		return fmt.Errorf("executing query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("getting columns failed: %w", err)
+	}
+	if len(columns) < 2 {
+		return errors.New("query result has not enough columns, requiring at least `key,value`")
+	}
+
+	values := make([]dbsql.NullString, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("reading row failed: %w", err)
+		}
+		if !values[0].Valid {
+			continue
+		}
+
+		key := values[0].String
+		for i, v := range values[1:] {
+			if v.Valid && v.String != "" {
+				mappings[key] = append(mappings[key], telegraf.Tag{Key: columns[i+1], Value: v.String})
+			}
+		}
+	}
+
+	return rows.Err()
+}
@@ -3,42 +3,140 @@ package pivot
 
 import (
 	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
+// Suffixes of the value fields by type as created by the unpivot processor
+var typeSuffixes = []string{"_int", "_uint", "_float", "_bool", "_string"}
+
 type Pivot struct {
-	TagKey   string `toml:"tag_key"`
-	ValueKey string `toml:"value_key"`
+	TagKey          string   `toml:"tag_key"`
+	TagKeys         []string `toml:"tag_keys"`
+	Separator       string   `toml:"separator"`
+	ValueKey        string   `toml:"value_key"`
+	ValueTypeSuffix bool     `toml:"value_type_suffix"`
+
+	valueFilter filter.Filter
 }
 
 func (*Pivot) SampleConfig() string {
 	return sampleConfig
 }
 
+func (p *Pivot) Init() error {
+	if p.TagKey != "" && len(p.TagKeys) > 0 {
+		return errors.New("tag_key and tag_keys are mutually exclusive")
+	}
+	if p.TagKey == "" && len(p.TagKeys) == 0 {
+		return errors.New("tag key required")
+	}
+	if p.ValueKey == "" {
+		return errors.New("value key required")
+	}
+	if p.Separator == "" {
+		p.Separator = "_"
+	}
+
+	// Value keys with wildcards select multiple fields
+	if strings.ContainsAny(p.ValueKey, "*?[") {
+		if p.ValueTypeSuffix {
+			return errors.New("value type suffix not supported for wildcard value keys")
+		}
+		f, err := filter.Compile([]string{p.ValueKey})
+		if err != nil {
+			return fmt.Errorf("compiling value key failed: %w", err)
+		}
+		p.valueFilter = f
+	}
+
+	return nil
+}
+
 func (p *Pivot) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 	for _, m := range metrics {
-		key, ok := m.GetTag(p.TagKey)
-		if !ok {
+		tagKeys := p.TagKeys
+		if len(tagKeys) == 0 {
+			tagKeys = []string{p.TagKey}
+		}
+
+		parts := make([]string, 0, len(tagKeys))
+		for _, k := range tagKeys {
+			v, ok := m.GetTag(k)
+			if !ok {
+				break
+			}
+			parts = append(parts, v)
+		}
+		if len(parts) != len(tagKeys) {
 			continue
 		}
+		key := strings.Join(parts, p.Separator)
 
-		value, ok := m.GetField(p.ValueKey)
+		if p.valueFilter != nil {
+			p.pivotMatching(m, tagKeys, key)
+			continue
+		}
+
+		valueKey, value, ok := p.value(m)
 		if !ok {
 			continue
 		}
 
-		m.RemoveTag(p.TagKey)
-		m.RemoveField(p.ValueKey)
+		for _, k := range tagKeys {
+			m.RemoveTag(k)
+		}
+		m.RemoveField(valueKey)
 		m.AddField(key, value)
 	}
 	return metrics
 }
 
+// value returns the key and the value of the value field
+func (p *Pivot) value(m telegraf.Metric) (string, interface{}, bool) {
+	if !p.ValueTypeSuffix {
+		value, ok := m.GetField(p.ValueKey)
+		return p.ValueKey, value, ok
+	}
+
+	for _, suffix := range typeSuffixes {
+		if value, ok := m.GetField(p.ValueKey + suffix); ok {
+			return p.ValueKey + suffix, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// pivotMatching moves all fields matching the value key to fields named
+// after the key and the original field name
+func (p *Pivot) pivotMatching(m telegraf.Metric, tagKeys []string, key string) {
+	matching := make([]*telegraf.Field, 0)
+	for _, field := range m.FieldList() {
+		if p.valueFilter.Match(field.Key) {
+			matching = append(matching, field)
+		}
+	}
+	if len(matching) == 0 {
+		return
+	}
+
+	for _, k := range tagKeys {
+		m.RemoveTag(k)
+	}
+	for _, field := range matching {
+		m.RemoveField(field.Key)
+		m.AddField(key+p.Separator+field.Key, field.Value)
+	}
+}
+
 func init() {
 	processors.Add("pivot", func() telegraf.Processor {
 		return &Pivot{}
@@ -4,10 +4,46 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 )
 
+func TestPivot_initFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		pivot    *Pivot
+		expected string
+	}{
+		{
+			name:     "no tag key",
+			pivot:    &Pivot{ValueKey: "value"},
+			expected: "tag key required",
+		},
+		{
+			name:     "tag key and tag keys",
+			pivot:    &Pivot{TagKey: "name", TagKeys: []string{"ifName", "name"}, ValueKey: "value"},
+			expected: "tag_key and tag_keys are mutually exclusive",
+		},
+		{
+			name:     "no value key",
+			pivot:    &Pivot{TagKey: "name"},
+			expected: "value key required",
+		},
+		{
+			name:     "wildcard with type suffix",
+			pivot:    &Pivot{TagKey: "name", ValueKey: "value*", ValueTypeSuffix: true},
+			expected: "value type suffix not supported for wildcard value keys",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.pivot.Init(), tt.expected)
+		})
+	}
+}
+
 func TestPivot(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
@@ -101,9 +137,137 @@ func TestPivot(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "multiple tag keys",
+			pivot: &Pivot{
+				TagKeys:  []string{"ifName", "name"},
+				ValueKey: "value",
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("interface",
+					map[string]string{
+						"agent_host": "10.0.0.1",
+						"ifName":     "eth0",
+						"name":       "in_octets",
+					},
+					map[string]interface{}{
+						"value": uint64(1024),
+					},
+					now,
+				),
+				testutil.MustMetric("interface",
+					map[string]string{
+						"agent_host": "10.0.0.1",
+						"ifName":     "eth0",
+					},
+					map[string]interface{}{
+						"value": uint64(2048),
+					},
+					now,
+				),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("interface",
+					map[string]string{
+						"agent_host": "10.0.0.1",
+					},
+					map[string]interface{}{
+						"eth0_in_octets": uint64(1024),
+					},
+					now,
+				),
+				testutil.MustMetric("interface",
+					map[string]string{
+						"agent_host": "10.0.0.1",
+						"ifName":     "eth0",
+					},
+					map[string]interface{}{
+						"value": uint64(2048),
+					},
+					now,
+				),
+			},
+		},
+		{
+			name: "value type suffix",
+			pivot: &Pivot{
+				TagKey:          "name",
+				ValueKey:        "value",
+				ValueTypeSuffix: true,
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("device",
+					map[string]string{
+						"name": "state",
+					},
+					map[string]interface{}{
+						"value_string": "up",
+					},
+					now,
+				),
+				testutil.MustMetric("device",
+					map[string]string{
+						"name": "temperature",
+					},
+					map[string]interface{}{
+						"value_float": 42.5,
+					},
+					now,
+				),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("device",
+					map[string]string{},
+					map[string]interface{}{
+						"state": "up",
+					},
+					now,
+				),
+				testutil.MustMetric("device",
+					map[string]string{},
+					map[string]interface{}{
+						"temperature": 42.5,
+					},
+					now,
+				),
+			},
+		},
+		{
+			name: "wildcard value key",
+			pivot: &Pivot{
+				TagKey:    "table",
+				Separator: ".",
+				ValueKey:  "*_rows",
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("sqlserver",
+					map[string]string{
+						"table": "orders",
+					},
+					map[string]interface{}{
+						"live_rows": int64(100),
+						"dead_rows": int64(3),
+						"size":      int64(8192),
+					},
+					now,
+				),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("sqlserver",
+					map[string]string{},
+					map[string]interface{}{
+						"orders.live_rows": int64(100),
+						"orders.dead_rows": int64(3),
+						"size":             int64(8192),
+					},
+					now,
+				),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.pivot.Init())
 			actual := tt.pivot.Apply(tt.metrics...)
 			testutil.RequireMetricsEqual(t, tt.expected, actual)
 		})
@@ -0,0 +1,237 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rate
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// sample is the last seen value of a single field of a series
+type sample struct {
+	Value float64   `json:"value"`
+	Time  time.Time `json:"time"`
+}
+
+// series holds the last samples of the selected fields for a single series
+type series map[string]sample
+
+type Rate struct {
+	IncludeFields []string        `toml:"include_fields"`
+	ExcludeFields []string        `toml:"exclude_fields"`
+	Mode          string          `toml:"mode"`
+	Suffix        string          `toml:"suffix"`
+	OnDecrease    string          `toml:"on_decrease"`
+	CounterMax    uint64          `toml:"counter_max"`
+	MaxAge        config.Duration `toml:"max_age"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	cache       map[uint64]series
+	lastCleanup time.Time
+}
+
+func (*Rate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rate) Init() error {
+	switch r.Mode {
+	case "":
+		r.Mode = "rate"
+	case "rate", "delta":
+	default:
+		return fmt.Errorf("invalid mode %q", r.Mode)
+	}
+
+	switch r.OnDecrease {
+	case "":
+		r.OnDecrease = "reset"
+	case "reset", "skip", "wrap", "keep":
+	default:
+		return fmt.Errorf("invalid on_decrease setting %q", r.OnDecrease)
+	}
+	if r.CounterMax > 0 && r.OnDecrease != "wrap" {
+		return errors.New("counter_max requires on_decrease to be \"wrap\"")
+	}
+
+	fieldFilter, err := filter.NewIncludeExcludeFilter(r.IncludeFields, r.ExcludeFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	r.fieldFilter = fieldFilter
+
+	if r.cache == nil {
+		r.cache = make(map[uint64]series)
+	}
+	r.lastCleanup = time.Now()
+
+	return nil
+}
+
+func (r *Rate) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	idx := 0
+	for _, m := range metrics {
+		id := m.HashID()
+		s, found := r.cache[id]
+		if !found {
+			s = make(series)
+			r.cache[id] = s
+		}
+
+		ts := m.Time()
+		var remove []string
+		for _, field := range m.FieldList() {
+			if !r.fieldFilter.Match(field.Key) {
+				continue
+			}
+			value, ok := toFloat(field.Value)
+			if !ok {
+				continue
+			}
+
+			result, valid := r.compute(s, field.Key, value, ts)
+			switch {
+			case valid && r.Suffix == "":
+				field.Value = result
+			case valid:
+				m.AddField(field.Key+r.Suffix, result)
+			case r.Suffix == "":
+				// Without a suffix the original value would be mistaken
+				// for the computed one, so remove it
+				remove = append(remove, field.Key)
+			}
+		}
+		for _, key := range remove {
+			m.RemoveField(key)
+		}
+
+		// Drop metrics that have no fields left, e.g. the first sample of
+		// a series with the original values being replaced
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		metrics[idx] = m
+		idx++
+	}
+	r.cleanup()
+
+	return metrics[:idx]
+}
+
+// compute returns the rate or delta of the given field with respect to the
+// previous sample of the series and updates the series state. The second
+// return value is false if no result can be computed for the sample.
+func (r *Rate) compute(s series, key string, value float64, ts time.Time) (float64, bool) {
+	prev, found := s[key]
+	if !found {
+		s[key] = sample{Value: value, Time: ts}
+		return 0, false
+	}
+
+	// Ignore out-of-order and duplicate samples and keep the current state
+	// as reference
+	elapsed := ts.Sub(prev.Time)
+	if elapsed <= 0 {
+		r.Log.Debugf("Ignoring sample of field %q not newer than the previous one", key)
+		return 0, false
+	}
+	s[key] = sample{Value: value, Time: ts}
+
+	delta := value - prev.Value
+	if delta < 0 {
+		switch r.OnDecrease {
+		case "reset":
+			// The counter restarted from zero, so the increase is the
+			// current value
+			delta = value
+		case "skip":
+			return 0, false
+		case "wrap":
+			limit := r.CounterMax
+			if limit == 0 {
+				limit = math.MaxUint64
+				if prev.Value <= math.MaxUint32 {
+					limit = math.MaxUint32
+				}
+			}
+			if prev.Value > float64(limit) {
+				r.Log.Debugf("Value of field %q exceeds the counter maximum, skipping", key)
+				return 0, false
+			}
+			delta = float64(limit) - prev.Value + value + 1
+		}
+	}
+
+	if r.Mode == "delta" {
+		return delta, true
+	}
+	return delta / elapsed.Seconds(), true
+}
+
+// cleanup removes the state of series that have not been updated within
+// max_age, at most once per max_age period
+func (r *Rate) cleanup() {
+	if r.MaxAge <= 0 || time.Since(r.lastCleanup) < time.Duration(r.MaxAge) {
+		return
+	}
+	r.lastCleanup = time.Now()
+
+	threshold := r.lastCleanup.Add(-time.Duration(r.MaxAge))
+	for id, s := range r.cache {
+		for key, last := range s {
+			if last.Time.Before(threshold) {
+				delete(s, key)
+			}
+		}
+		if len(s) == 0 {
+			delete(r.cache, id)
+		}
+	}
+}
+
+func (r *Rate) GetState() interface{} {
+	return r.cache
+}
+
+func (r *Rate) SetState(state interface{}) error {
+	s, ok := state.(map[uint64]series)
+	if !ok {
+		return fmt.Errorf("state has wrong type %T", state)
+	}
+	r.cache = s
+	return nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func init() {
+	processors.Add("rate", func() telegraf.Processor {
+		return &Rate{
+			Mode:       "rate",
+			OnDecrease: "reset",
+			MaxAge:     config.Duration(time.Hour),
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package rate
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Rate
+		expected string
+	}{
+		{
+			name:     "invalid mode",
+			plugin:   &Rate{Mode: "foo"},
+			expected: `invalid mode "foo"`,
+		},
+		{
+			name:     "invalid on_decrease",
+			plugin:   &Rate{OnDecrease: "foo"},
+			expected: `invalid on_decrease setting "foo"`,
+		},
+		{
+			name:     "counter_max without wrap",
+			plugin:   &Rate{OnDecrease: "reset", CounterMax: 255},
+			expected: `counter_max requires on_decrease to be "wrap"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Rate
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "rate replacing fields",
+			plugin: &Rate{},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"bytes": int64(1000), "state": "up"}),
+				metric(10, map[string]interface{}{"bytes": int64(6000), "state": "up"}),
+				metric(20, map[string]interface{}{"bytes": int64(6500), "state": "down"}),
+			},
+			expected: []telegraf.Metric{
+				metric(0, map[string]interface{}{"state": "up"}),
+				metric(10, map[string]interface{}{"bytes": float64(500), "state": "up"}),
+				metric(20, map[string]interface{}{"bytes": float64(50), "state": "down"}),
+			},
+		},
+		{
+			name:   "rate with suffix",
+			plugin: &Rate{Suffix: "_rate", IncludeFields: []string{"bytes*"}},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"bytes_in": uint64(100), "bytes_out": 10.0, "errors": int64(1)}),
+				metric(5, map[string]interface{}{"bytes_in": uint64(600), "bytes_out": 20.0, "errors": int64(3)}),
+			},
+			expected: []telegraf.Metric{
+				metric(0, map[string]interface{}{"bytes_in": uint64(100), "bytes_out": 10.0, "errors": int64(1)}),
+				metric(5, map[string]interface{}{
+					"bytes_in":       uint64(600),
+					"bytes_out":      20.0,
+					"errors":         int64(3),
+					"bytes_in_rate":  float64(100),
+					"bytes_out_rate": float64(2),
+				}),
+			},
+		},
+		{
+			name:   "delta dropping first sample",
+			plugin: &Rate{Mode: "delta"},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"count": int64(3)}),
+				metric(60, map[string]interface{}{"count": int64(10)}),
+			},
+			expected: []telegraf.Metric{
+				metric(60, map[string]interface{}{"count": float64(7)}),
+			},
+		},
+		{
+			name:   "counter reset",
+			plugin: &Rate{Mode: "delta", OnDecrease: "reset"},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"count": int64(100)}),
+				metric(10, map[string]interface{}{"count": int64(5)}),
+			},
+			expected: []telegraf.Metric{
+				metric(10, map[string]interface{}{"count": float64(5)}),
+			},
+		},
+		{
+			name:   "skip decrease",
+			plugin: &Rate{Mode: "delta", OnDecrease: "skip", Suffix: "_delta"},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"count": int64(100)}),
+				metric(10, map[string]interface{}{"count": int64(5)}),
+				metric(20, map[string]interface{}{"count": int64(8)}),
+			},
+			expected: []telegraf.Metric{
+				metric(0, map[string]interface{}{"count": int64(100)}),
+				metric(10, map[string]interface{}{"count": int64(5)}),
+				metric(20, map[string]interface{}{"count": int64(8), "count_delta": float64(3)}),
+			},
+		},
+		{
+			name:   "keep decrease",
+			plugin: &Rate{Mode: "delta", OnDecrease: "keep"},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"temp": 25.5}),
+				metric(10, map[string]interface{}{"temp": 24.0}),
+			},
+			expected: []telegraf.Metric{
+				metric(10, map[string]interface{}{"temp": -1.5}),
+			},
+		},
+		{
+			name:   "wrap 32-bit",
+			plugin: &Rate{Mode: "delta", OnDecrease: "wrap"},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"octets": uint64(math.MaxUint32 - 9)}),
+				metric(10, map[string]interface{}{"octets": uint64(20)}),
+			},
+			expected: []telegraf.Metric{
+				metric(10, map[string]interface{}{"octets": float64(30)}),
+			},
+		},
+		{
+			name:   "wrap custom maximum",
+			plugin: &Rate{OnDecrease: "wrap", CounterMax: 9999},
+			input: []telegraf.Metric{
+				metric(0, map[string]interface{}{"seq": int64(9990)}),
+				metric(2, map[string]interface{}{"seq": int64(10)}),
+			},
+			expected: []telegraf.Metric{
+				metric(2, map[string]interface{}{"seq": float64(10)}),
+			},
+		},
+		{
+			name:   "out of order samples",
+			plugin: &Rate{Mode: "delta", Suffix: "_delta"},
+			input: []telegraf.Metric{
+				metric(10, map[string]interface{}{"count": int64(10)}),
+				metric(5, map[string]interface{}{"count": int64(5)}),
+				metric(10, map[string]interface{}{"count": int64(10)}),
+				metric(20, map[string]interface{}{"count": int64(15)}),
+			},
+			expected: []telegraf.Metric{
+				metric(10, map[string]interface{}{"count": int64(10)}),
+				metric(5, map[string]interface{}{"count": int64(5)}),
+				metric(10, map[string]interface{}{"count": int64(10)}),
+				metric(20, map[string]interface{}{"count": int64(15), "count_delta": float64(5)}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			var actual []telegraf.Metric
+			for _, m := range tt.input {
+				actual = append(actual, tt.plugin.Apply(m)...)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSeparateSeries(t *testing.T) {
+	plugin := &Rate{Mode: "delta", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes": int64(10)}, time.Unix(0, 0)),
+		testutil.MustMetric("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"bytes": int64(100)}, time.Unix(0, 0)),
+		testutil.MustMetric("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes": int64(15)}, time.Unix(10, 0)),
+		testutil.MustMetric("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"bytes": int64(300)}, time.Unix(10, 0)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes": float64(5)}, time.Unix(10, 0)),
+		testutil.MustMetric("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"bytes": float64(200)}, time.Unix(10, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestCleanup(t *testing.T) {
+	plugin := &Rate{Mode: "delta", MaxAge: config.Duration(time.Minute), Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Apply(
+		testutil.MustMetric("old", map[string]string{}, map[string]interface{}{"value": 1.0}, now.Add(-2*time.Minute)),
+		testutil.MustMetric("new", map[string]string{}, map[string]interface{}{"value": 1.0}, now),
+	)
+	require.Len(t, plugin.cache, 2)
+
+	// Force the cleanup to run on the next call
+	plugin.lastCleanup = now.Add(-2 * time.Minute)
+	plugin.Apply()
+	require.Len(t, plugin.cache, 1)
+}
+
+func TestState(t *testing.T) {
+	plugin := &Rate{Mode: "delta", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	require.Empty(t, plugin.Apply(metric(0, map[string]interface{}{"count": int64(10)})))
+
+	// Roundtrip the state the same way the persister does
+	buf, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	var state map[uint64]series
+	require.NoError(t, json.Unmarshal(buf, &state))
+
+	restored := &Rate{Mode: "delta", Log: testutil.Logger{}}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state))
+
+	expected := []telegraf.Metric{metric(10, map[string]interface{}{"count": float64(15)})}
+	testutil.RequireMetricsEqual(t, expected, restored.Apply(metric(10, map[string]interface{}{"count": int64(25)})))
+}
+
+func metric(sec int64, fields map[string]interface{}) telegraf.Metric {
+	return testutil.MustMetric("test", map[string]string{"host": "localhost"}, fields, time.Unix(sec, 0))
+}
@@ -0,0 +1,265 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package schema
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Kinds of violations reported in the statistics
+var violationKinds = []string{"missing_tag", "missing_field", "type", "range", "unknown_field"}
+
+type Field struct {
+	Name     string   `toml:"name"`
+	Type     string   `toml:"type"`
+	Required bool     `toml:"required"`
+	Min      *float64 `toml:"min"`
+	Max      *float64 `toml:"max"`
+}
+
+type Measurement struct {
+	Names        []string `toml:"names"`
+	RequiredTags []string `toml:"required_tags"`
+	Strict       bool     `toml:"strict"`
+	Fields       []Field  `toml:"field"`
+
+	filter filter.Filter
+	fields map[string]bool
+}
+
+type Schema struct {
+	Coerce       bool            `toml:"coerce"`
+	OnViolation  string          `toml:"on_violation"`
+	Measurements []Measurement   `toml:"measurement"`
+	Log          telegraf.Logger `toml:"-"`
+
+	violations    map[string]selfstat.Stat
+	droppedMetric selfstat.Stat
+	droppedFields selfstat.Stat
+	coercedFields selfstat.Stat
+}
+
+func (*Schema) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *Field) Init() error {
+	if f.Name == "" {
+		return errors.New("name required")
+	}
+	switch f.Type {
+	case "", "float", "integer", "unsigned", "boolean", "string":
+	default:
+		return fmt.Errorf("invalid type %q", f.Type)
+	}
+	if (f.Min != nil || f.Max != nil) && (f.Type == "boolean" || f.Type == "string") {
+		return fmt.Errorf("range not supported for type %q", f.Type)
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return errors.New("minimum greater than maximum")
+	}
+	return nil
+}
+
+func (m *Measurement) Init() error {
+	if len(m.Names) == 0 {
+		return errors.New("names required")
+	}
+	f, err := filter.Compile(m.Names)
+	if err != nil {
+		return fmt.Errorf("compiling names failed: %w", err)
+	}
+	m.filter = f
+
+	m.fields = make(map[string]bool, len(m.Fields))
+	for i := range m.Fields {
+		if err := m.Fields[i].Init(); err != nil {
+			return fmt.Errorf("field %d: %w", i+1, err)
+		}
+		m.fields[m.Fields[i].Name] = true
+	}
+	return nil
+}
+
+func (s *Schema) Init() error {
+	switch s.OnViolation {
+	case "":
+		s.OnViolation = "drop_field"
+	case "drop_field", "drop_metric":
+	default:
+		return fmt.Errorf("invalid violation action %q", s.OnViolation)
+	}
+
+	if len(s.Measurements) == 0 {
+		return errors.New("no measurement defined")
+	}
+	for i := range s.Measurements {
+		if err := s.Measurements[i].Init(); err != nil {
+			return fmt.Errorf("measurement %d: %w", i+1, err)
+		}
+	}
+
+	s.violations = make(map[string]selfstat.Stat, len(violationKinds))
+	for _, kind := range violationKinds {
+		s.violations[kind] = selfstat.Register("schema", "violations", map[string]string{"type": kind})
+	}
+	s.droppedMetric = selfstat.Register("schema", "dropped_metrics", map[string]string{})
+	s.droppedFields = selfstat.Register("schema", "dropped_fields", map[string]string{})
+	s.coercedFields = selfstat.Register("schema", "coerced_fields", map[string]string{})
+
+	return nil
+}
+
+func (s *Schema) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	idx := 0
+	for _, m := range in {
+		if !s.validate(m) {
+			s.droppedMetric.Incr(1)
+			m.Drop()
+			continue
+		}
+		in[idx] = m
+		idx++
+	}
+	return in[:idx]
+}
+
+// validate checks the metric against the first matching schema, fixing or
+// removing invalid fields, and returns false if the metric must be dropped
+func (s *Schema) validate(m telegraf.Metric) bool {
+	var schema *Measurement
+	for i := range s.Measurements {
+		if s.Measurements[i].filter.Match(m.Name()) {
+			schema = &s.Measurements[i]
+			break
+		}
+	}
+	if schema == nil {
+		return true
+	}
+
+	valid := true
+	for _, key := range schema.RequiredTags {
+		if !m.HasTag(key) {
+			s.report(m, "missing_tag", "tag %q missing", key)
+			valid = false
+		}
+	}
+
+	invalid := make([]string, 0)
+	for i := range schema.Fields {
+		f := &schema.Fields[i]
+		value, found := m.GetField(f.Name)
+		if !found {
+			if f.Required {
+				s.report(m, "missing_field", "field %q missing", f.Name)
+				valid = false
+			}
+			continue
+		}
+
+		if f.Type != "" && !hasType(value, f.Type) {
+			s.report(m, "type", "field %q is %T instead of %s", f.Name, value, f.Type)
+			if !s.Coerce {
+				invalid = append(invalid, f.Name)
+				continue
+			}
+			converted, err := convert(value, f.Type)
+			if err != nil {
+				s.Log.Debugf("Coercing field %q of metric %q failed: %v", f.Name, m.Name(), err)
+				invalid = append(invalid, f.Name)
+				continue
+			}
+			m.AddField(f.Name, converted)
+			s.coercedFields.Incr(1)
+			value = converted
+		}
+
+		if f.Min != nil || f.Max != nil {
+			v, err := internal.ToFloat64(value)
+			if err != nil || f.Min != nil && v < *f.Min || f.Max != nil && v > *f.Max {
+				s.report(m, "range", "field %q value %v out of range", f.Name, value)
+				invalid = append(invalid, f.Name)
+			}
+		}
+	}
+
+	if schema.Strict {
+		for _, field := range m.FieldList() {
+			if !schema.fields[field.Key] {
+				s.report(m, "unknown_field", "field %q unknown", field.Key)
+				invalid = append(invalid, field.Key)
+			}
+		}
+	}
+
+	if len(invalid) == 0 {
+		return valid
+	}
+	if s.OnViolation == "drop_metric" {
+		return false
+	}
+	for _, key := range invalid {
+		m.RemoveField(key)
+		s.droppedFields.Incr(1)
+	}
+
+	// Metrics without fields are invalid
+	return valid && len(m.FieldList()) > 0
+}
+
+func (s *Schema) report(m telegraf.Metric, kind, format string, args ...interface{}) {
+	s.violations[kind].Incr(1)
+	s.Log.Debugf("Metric %q violates schema: "+format, append([]interface{}{m.Name()}, args...)...)
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch value.(type) {
+	case float64:
+		return typ == "float"
+	case int64:
+		return typ == "integer"
+	case uint64:
+		return typ == "unsigned"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	}
+	return false
+}
+
+func convert(value interface{}, typ string) (interface{}, error) {
+	switch typ {
+	case "float":
+		return internal.ToFloat64(value)
+	case "integer":
+		return internal.ToInt64(value)
+	case "unsigned":
+		return internal.ToUint64(value)
+	case "boolean":
+		return internal.ToBool(value)
+	case "string":
+		return internal.ToString(value)
+	}
+	return nil, fmt.Errorf("invalid type %q", typ)
+}
+
+func init() {
+	processors.Add("schema", func() telegraf.Processor {
+		return &Schema{
+			Coerce:      true,
+			OnViolation: "drop_field",
+		}
+	})
+}
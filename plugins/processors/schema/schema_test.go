@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name        string
+		measurement Measurement
+		expected    string
+	}{
+		{
+			name:        "no names",
+			measurement: Measurement{RequiredTags: []string{"host"}},
+			expected:    "measurement 1: names required",
+		},
+		{
+			name:        "invalid type",
+			measurement: Measurement{Names: []string{"cpu"}, Fields: []Field{{Name: "usage", Type: "double"}}},
+			expected:    `measurement 1: field 1: invalid type "double"`,
+		},
+		{
+			name:        "range for string",
+			measurement: Measurement{Names: []string{"cpu"}, Fields: []Field{{Name: "state", Type: "string", Min: ptr(0)}}},
+			expected:    `measurement 1: field 1: range not supported for type "string"`,
+		},
+		{
+			name:        "invalid range",
+			measurement: Measurement{Names: []string{"cpu"}, Fields: []Field{{Name: "usage", Min: ptr(100), Max: ptr(0)}}},
+			expected:    "measurement 1: field 1: minimum greater than maximum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Schema{Measurements: []Measurement{tt.measurement}}
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDropField(t *testing.T) {
+	plugin := &Schema{
+		Coerce:      true,
+		OnViolation: "drop_field",
+		Measurements: []Measurement{
+			{
+				Names:        []string{"cpu*"},
+				RequiredTags: []string{"host"},
+				Strict:       true,
+				Fields: []Field{
+					{Name: "usage", Type: "float", Required: true, Min: ptr(0), Max: ptr(100)},
+					{Name: "count", Type: "integer"},
+					{Name: "state", Type: "string"},
+				},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		// valid
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 50.0, "count": int64(3), "state": "ok"}),
+		// coerced types
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": "42.5", "count": 2.0}),
+		// out of range
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 150.0, "count": int64(1)}),
+		// unknown field
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.0, "extra": int64(1)}),
+		// missing tag
+		cpu(map[string]string{}, map[string]interface{}{"usage": 1.0}),
+		// missing field
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"count": int64(1)}),
+		// not coercible
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": "high", "count": int64(1)}),
+		// no field left
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": -1.0}),
+		// no schema
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": "lots"}, time.Unix(0, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 50.0, "count": int64(3), "state": "ok"}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5, "count": int64(2)}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"count": int64(1)}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.0}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"count": int64(1)}),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": "lots"}, time.Unix(0, 0)),
+	}
+
+	ranges := selfstat.Register("schema", "violations", map[string]string{"type": "range"})
+	dropped := selfstat.Register("schema", "dropped_metrics", map[string]string{})
+	coerced := selfstat.Register("schema", "coerced_fields", map[string]string{})
+	rangesBefore, droppedBefore, coercedBefore := ranges.Get(), dropped.Get(), coerced.Get()
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	require.Equal(t, int64(2), ranges.Get()-rangesBefore)
+	require.Equal(t, int64(3), dropped.Get()-droppedBefore)
+	require.Equal(t, int64(2), coerced.Get()-coercedBefore)
+}
+
+func TestDropMetric(t *testing.T) {
+	plugin := &Schema{
+		OnViolation: "drop_metric",
+		Measurements: []Measurement{
+			{
+				Names:  []string{"cpu"},
+				Fields: []Field{{Name: "usage", Type: "float", Max: ptr(100)}},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 50.0, "count": int64(3)}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": int64(50)}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 150.0}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"count": int64(3)}),
+	}
+
+	expected := []telegraf.Metric{
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"usage": 50.0, "count": int64(3)}),
+		cpu(map[string]string{"host": "a"}, map[string]interface{}{"count": int64(3)}),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func cpu(tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	return metric.New("cpu", tags, fields, time.Unix(0, 0))
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
@@ -3,8 +3,10 @@ package starlark
 
 import (
 	_ "embed"
+	"encoding/json"
 	"fmt"
 
+	starlarkjson "go.starlark.net/lib/json"
 	"go.starlark.net/starlark"
 
 	"github.com/influxdata/telegraf"
@@ -123,6 +125,61 @@ func (s *Starlark) Add(origMetric telegraf.Metric, acc telegraf.Accumulator) err
 func (s *Starlark) Stop() {
 }
 
+// GetState returns the entries of the script's 'state' dictionary encoded as
+// JSON for persisting them across restarts. Entries that cannot be encoded,
+// e.g. metrics, are skipped.
+func (s *Starlark) GetState() interface{} {
+	state := make(map[string]json.RawMessage)
+	dict := s.State()
+	if dict == nil {
+		return state
+	}
+
+	thread := &starlark.Thread{Name: "state"}
+	for _, item := range dict.Items() {
+		key, ok := item[0].(starlark.String)
+		if !ok {
+			s.Log.Warnf("Not persisting state with non-string key %s", item[0])
+			continue
+		}
+		if hasMetric(item[1]) {
+			s.Log.Warnf("Not persisting state %q containing metrics", string(key))
+			continue
+		}
+		v, err := starlark.Call(thread, starlarkjson.Module.Members["encode"], starlark.Tuple{item[1]}, nil)
+		if err != nil {
+			s.Log.Warnf("Not persisting state %q: %v", string(key), err)
+			continue
+		}
+		state[string(key)] = json.RawMessage(v.(starlark.String))
+	}
+	return state
+}
+
+// SetState restores the entries of the script's 'state' dictionary
+func (s *Starlark) SetState(state interface{}) error {
+	entries, ok := state.(map[string]json.RawMessage)
+	if !ok {
+		return fmt.Errorf("invalid state type %T", state)
+	}
+	dict := s.State()
+	if dict == nil {
+		return nil
+	}
+
+	thread := &starlark.Thread{Name: "state"}
+	for key, raw := range entries {
+		v, err := starlark.Call(thread, starlarkjson.Module.Members["decode"], starlark.Tuple{starlark.String(raw)}, nil)
+		if err != nil {
+			return fmt.Errorf("decoding state %q failed: %w", key, err)
+		}
+		if err := dict.SetKey(starlark.String(key), v); err != nil {
+			return fmt.Errorf("restoring state %q failed: %w", key, err)
+		}
+	}
+	return nil
+}
+
 func containsMetric(metrics []telegraf.Metric, target telegraf.Metric) bool {
 	for _, m := range metrics {
 		if m == target {
@@ -132,6 +189,34 @@ func containsMetric(metrics []telegraf.Metric, target telegraf.Metric) bool {
 	return false
 }
 
+// hasMetric returns true if the value is or contains a metric. Metrics would
+// be encoded as JSON objects of their attributes and cannot be restored.
+func hasMetric(v starlark.Value) bool {
+	switch v := v.(type) {
+	case *common.Metric:
+		return true
+	case *starlark.List:
+		for i := 0; i < v.Len(); i++ {
+			if hasMetric(v.Index(i)) {
+				return true
+			}
+		}
+	case starlark.Tuple:
+		for _, item := range v {
+			if hasMetric(item) {
+				return true
+			}
+		}
+	case *starlark.Dict:
+		for _, item := range v.Items() {
+			if hasMetric(item[0]) || hasMetric(item[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func init() {
 	processors.AddStreaming("starlark", func() telegraf.StreamingProcessor {
 		return &Starlark{
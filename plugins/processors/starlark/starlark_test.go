@@ -1,6 +1,7 @@
 package starlark
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -3563,6 +3564,138 @@ def apply(metric):
 	}
 }
 
+func TestModules(t *testing.T) {
+	// Create modules in the script directory and a separate module directory
+	scriptDir := t.TempDir()
+	moduleDir := t.TempDir()
+	modules := map[string]string{
+		filepath.Join(moduleDir, "math_helpers.star"): `
+def double(x):
+    return 2 * x
+`,
+		filepath.Join(moduleDir, "helpers.star"): `
+load("math_helpers.star", "double")
+
+def quadruple(x):
+    return double(double(x))
+`,
+		filepath.Join(scriptDir, "names.star"): `
+def rename(metric):
+    metric.name = metric.name + "_processed"
+`,
+	}
+	for fn, content := range modules {
+		require.NoError(t, os.WriteFile(fn, []byte(content), 0600))
+	}
+
+	script := filepath.Join(scriptDir, "script.star")
+	require.NoError(t, os.WriteFile(script, []byte(`
+load("helpers.star", "quadruple")
+load("names.star", "rename")
+
+def apply(metric):
+    metric.fields["value"] = quadruple(metric.fields["value"])
+    rename(metric)
+    return metric
+`), 0600))
+
+	// Use two instances to check sharing the modules
+	for i := 0; i < 2; i++ {
+		plugin := newStarlarkFromScript(script)
+		plugin.ModulePaths = []string{moduleDir}
+		require.NoError(t, plugin.Init())
+
+		acc := &testutil.Accumulator{}
+		require.NoError(t, plugin.Start(acc))
+		require.NoError(t, plugin.Add(testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(0, 0)), acc))
+		plugin.Stop()
+
+		expected := []telegraf.Metric{
+			testutil.MustMetric("cpu_processed", map[string]string{}, map[string]interface{}{"value": 12}, time.Unix(0, 0)),
+		}
+		testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	}
+}
+
+func TestModulesFail(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.star"), []byte(`load("b.star", "b")`+"\na = 1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.star"), []byte(`load("a.star", "a")`+"\nb = 1\n"), 0600))
+
+	plugin := newStarlarkFromSource(`
+load("a.star", "a")
+
+def apply(metric):
+    return metric
+`)
+	plugin.ModulePaths = []string{dir}
+	require.ErrorContains(t, plugin.Init(), "cycle in loading module")
+
+	plugin = newStarlarkFromSource(`
+load("unknown.star", "x")
+
+def apply(metric):
+    return metric
+`)
+	plugin.ModulePaths = []string{dir}
+	require.ErrorContains(t, plugin.Init(), "module unknown.star is not available")
+}
+
+func TestState(t *testing.T) {
+	source := `
+state = {"count": 0}
+
+def apply(metric):
+    state["count"] += 1
+    state["last"] = {"name": metric.name, "value": metric.fields["value"]}
+    state["metric"] = deepcopy(metric)
+    metric.fields["count"] = state["count"]
+    return metric
+`
+
+	// Process some metrics and get the state
+	logger := &testutil.CaptureLogger{}
+	plugin := newStarlarkFromSource(source)
+	plugin.Log = logger
+	require.NoError(t, plugin.Init())
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, plugin.Add(testutil.TestMetric(i), acc))
+	}
+	plugin.Stop()
+
+	// Metrics cannot be persisted so they should be skipped
+	state, ok := plugin.GetState().(map[string]json.RawMessage)
+	require.True(t, ok)
+	require.Len(t, state, 2)
+	require.JSONEq(t, "2", string(state["count"]))
+	require.JSONEq(t, `{"name": "test1", "value": 1}`, string(state["last"]))
+	warnings := logger.Warnings()
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], `Not persisting state "metric" containing metrics`)
+
+	// Restore the state in a new instance and continue counting
+	serialized, err := json.Marshal(state)
+	require.NoError(t, err)
+	var restored map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(serialized, &restored))
+
+	plugin = newStarlarkFromSource(source)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.SetState(restored))
+	acc = &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	require.NoError(t, plugin.Add(testutil.TestMetric(2), acc))
+	plugin.Stop()
+
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, 1)
+	count, found := actual[0].GetField("count")
+	require.True(t, found)
+	require.Equal(t, int64(3), count)
+}
+
 // parses metric lines out of line protocol following a header, with a trailing blank line
 func parseMetricsFrom(t *testing.T, lines []string, header string) (metrics []telegraf.Metric) {
 	parser := &influx.Parser{}
@@ -0,0 +1,154 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package tag_propagation
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TagPropagation struct {
+	KeyTag    string          `toml:"key_tag"`
+	Sources   []string        `toml:"sources"`
+	Tags      []string        `toml:"tags"`
+	Targets   []string        `toml:"targets"`
+	TTL       config.Duration `toml:"ttl"`
+	Overwrite bool            `toml:"overwrite"`
+	Log       telegraf.Logger `toml:"-"`
+
+	sourceFilter filter.Filter
+	tagFilter    filter.Filter
+	targetFilter filter.Filter
+
+	entries   map[string]*entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// entry holds the tags remembered for a key
+type entry struct {
+	tags     map[string]string
+	lastSeen time.Time
+}
+
+func (*TagPropagation) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *TagPropagation) Init() error {
+	if p.KeyTag == "" {
+		return errors.New("key tag required")
+	}
+	if len(p.Sources) == 0 {
+		return errors.New("no sources specified")
+	}
+	if len(p.Tags) == 0 {
+		return errors.New("no tags specified")
+	}
+	if p.TTL <= 0 {
+		return errors.New("ttl must be positive")
+	}
+
+	var err error
+	if p.sourceFilter, err = filter.Compile(p.Sources); err != nil {
+		return fmt.Errorf("compiling sources failed: %w", err)
+	}
+	if p.tagFilter, err = filter.Compile(p.Tags); err != nil {
+		return fmt.Errorf("compiling tags failed: %w", err)
+	}
+	if p.targetFilter, err = filter.Compile(p.Targets); err != nil {
+		return fmt.Errorf("compiling targets failed: %w", err)
+	}
+
+	p.entries = make(map[string]*entry)
+	if p.now == nil {
+		p.now = time.Now
+	}
+	p.lastSweep = p.now()
+
+	return nil
+}
+
+func (p *TagPropagation) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := p.now()
+	threshold := now.Add(-time.Duration(p.TTL))
+	p.sweep(now, threshold)
+
+	for _, m := range in {
+		key, found := m.GetTag(p.KeyTag)
+		if !found {
+			continue
+		}
+
+		if p.sourceFilter.Match(m.Name()) {
+			p.remember(key, m, now)
+			continue
+		}
+		if p.targetFilter != nil && !p.targetFilter.Match(m.Name()) {
+			continue
+		}
+
+		e, found := p.entries[key]
+		if !found || e.lastSeen.Before(threshold) {
+			continue
+		}
+		for k, v := range e.tags {
+			if p.Overwrite || !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+	return in
+}
+
+// remember stores the matching tags of the given source metric for the key
+func (p *TagPropagation) remember(key string, m telegraf.Metric, now time.Time) {
+	tags := make(map[string]string)
+	for _, tag := range m.TagList() {
+		if tag.Key != p.KeyTag && p.tagFilter.Match(tag.Key) {
+			tags[tag.Key] = tag.Value
+		}
+	}
+	if len(tags) == 0 {
+		return
+	}
+
+	e, found := p.entries[key]
+	if !found {
+		e = &entry{tags: tags}
+		p.entries[key] = e
+	}
+	for k, v := range tags {
+		e.tags[k] = v
+	}
+	e.lastSeen = now
+}
+
+// sweep removes expired entries at most once per TTL to bound the memory of
+// keys not seen anymore, e.g. of removed containers
+func (p *TagPropagation) sweep(now, threshold time.Time) {
+	if p.lastSweep.After(threshold) {
+		return
+	}
+	for key, e := range p.entries {
+		if e.lastSeen.Before(threshold) {
+			delete(p.entries, key)
+		}
+	}
+	p.lastSweep = now
+}
+
+func init() {
+	processors.Add("tag_propagation", func() telegraf.Processor {
+		return &TagPropagation{TTL: config.Duration(10 * time.Minute)}
+	})
+}
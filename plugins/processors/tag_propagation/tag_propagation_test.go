@@ -0,0 +1,157 @@
+package tag_propagation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TagPropagation
+		expected string
+	}{
+		{
+			name:     "no key tag",
+			plugin:   &TagPropagation{},
+			expected: "key tag required",
+		},
+		{
+			name:     "no sources",
+			plugin:   &TagPropagation{KeyTag: "container_id"},
+			expected: "no sources specified",
+		},
+		{
+			name:     "no tags",
+			plugin:   &TagPropagation{KeyTag: "container_id", Sources: []string{"docker_container_cpu"}},
+			expected: "no tags specified",
+		},
+		{
+			name: "no ttl",
+			plugin: &TagPropagation{
+				KeyTag:  "container_id",
+				Sources: []string{"docker_container_cpu"},
+				Tags:    []string{"container_image"},
+			},
+			expected: "ttl must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	now := time.Unix(1000, 0)
+	plugin := &TagPropagation{
+		KeyTag:  "container_id",
+		Sources: []string{"docker_container_cpu"},
+		Tags:    []string{"container_*"},
+		Targets: []string{"procstat", "cadvisor*"},
+		TTL:     config.Duration(time.Minute),
+		Log:     testutil.Logger{},
+		now:     func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	// Targets seen before the source are not enriched
+	input := []telegraf.Metric{
+		newMetric("procstat", map[string]string{"container_id": "abc"}),
+		newMetric("docker_container_cpu", map[string]string{
+			"container_id":    "abc",
+			"container_image": "nginx",
+			"container_name":  "web",
+			"engine_host":     "host1",
+		}),
+		newMetric("procstat", map[string]string{"container_id": "abc", "container_name": "custom"}),
+		newMetric("cadvisor_memory", map[string]string{"container_id": "abc"}),
+		newMetric("procstat", map[string]string{"container_id": "def"}),
+		newMetric("procstat", map[string]string{"pid": "42"}),
+		newMetric("disk", map[string]string{"container_id": "abc"}),
+	}
+	expected := []telegraf.Metric{
+		newMetric("procstat", map[string]string{"container_id": "abc"}),
+		newMetric("docker_container_cpu", map[string]string{
+			"container_id":    "abc",
+			"container_image": "nginx",
+			"container_name":  "web",
+			"engine_host":     "host1",
+		}),
+		newMetric("procstat", map[string]string{
+			"container_id":    "abc",
+			"container_image": "nginx",
+			"container_name":  "custom",
+		}),
+		newMetric("cadvisor_memory", map[string]string{
+			"container_id":    "abc",
+			"container_image": "nginx",
+			"container_name":  "web",
+		}),
+		newMetric("procstat", map[string]string{"container_id": "def"}),
+		newMetric("procstat", map[string]string{"pid": "42"}),
+		newMetric("disk", map[string]string{"container_id": "abc"}),
+	}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Tags are applied across calls within the TTL
+	now = now.Add(50 * time.Second)
+	actual = plugin.Apply(newMetric("procstat", map[string]string{"container_id": "abc"}))
+	expected = []telegraf.Metric{
+		newMetric("procstat", map[string]string{
+			"container_id":    "abc",
+			"container_image": "nginx",
+			"container_name":  "web",
+		}),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Tags expire after the TTL
+	now = now.Add(20 * time.Second)
+	actual = plugin.Apply(newMetric("procstat", map[string]string{"container_id": "abc"}))
+	expected = []telegraf.Metric{
+		newMetric("procstat", map[string]string{"container_id": "abc"}),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	now = now.Add(time.Minute)
+	plugin.Apply()
+	require.Empty(t, plugin.entries)
+}
+
+func TestOverwrite(t *testing.T) {
+	plugin := &TagPropagation{
+		KeyTag:    "container_id",
+		Sources:   []string{"docker_container_cpu"},
+		Tags:      []string{"container_name"},
+		TTL:       config.Duration(time.Minute),
+		Overwrite: true,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		newMetric("docker_container_cpu", map[string]string{"container_id": "abc", "container_name": "web"}),
+		newMetric("procstat", map[string]string{"container_id": "abc", "container_name": "custom"}),
+	}
+	expected := []telegraf.Metric{
+		newMetric("docker_container_cpu", map[string]string{"container_id": "abc", "container_name": "web"}),
+		newMetric("procstat", map[string]string{"container_id": "abc", "container_name": "web"}),
+	}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func newMetric(name string, tags map[string]string) telegraf.Metric {
+	return metric.New(name, tags, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+}
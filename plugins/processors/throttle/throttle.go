@@ -0,0 +1,196 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package throttle
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Throttle struct {
+	Period config.Duration `toml:"period"`
+	Keep   string          `toml:"keep"`
+	Field  string          `toml:"field"`
+	Burst  int             `toml:"burst"`
+	Log    telegraf.Logger `toml:"-"`
+
+	acc     telegraf.Accumulator
+	series  map[uint64]*series
+	dropped selfstat.Stat
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	sync.Mutex
+}
+
+// series keeps the state of a single series within the current period
+type series struct {
+	forwarded int
+	pending   telegraf.Metric
+	value     float64
+}
+
+func (*Throttle) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Throttle) Init() error {
+	if t.Period <= 0 {
+		return errors.New("period must be positive")
+	}
+	if t.Burst < 0 {
+		return errors.New("burst must not be negative")
+	}
+
+	switch t.Keep {
+	case "":
+		t.Keep = "first"
+	case "first", "last":
+	case "max":
+		if t.Field == "" {
+			return errors.New("field required for keeping the maximum")
+		}
+	default:
+		return fmt.Errorf("invalid keep setting %q", t.Keep)
+	}
+
+	t.series = make(map[uint64]*series)
+	t.dropped = selfstat.Register("throttle", "dropped_metrics", map[string]string{})
+
+	return nil
+}
+
+func (t *Throttle) Start(acc telegraf.Accumulator) error {
+	t.acc = acc
+	t.done = make(chan struct{})
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(t.Period))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.done:
+				return
+			case <-ticker.C:
+				t.flush()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (t *Throttle) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	t.Lock()
+	defer t.Unlock()
+
+	id := m.HashID()
+	s, found := t.series[id]
+	if !found {
+		s = &series{}
+		t.series[id] = s
+	}
+
+	// Points within the burst allowance are forwarded immediately. When
+	// keeping the first point, the point itself counts towards the allowance.
+	limit := t.Burst
+	if t.Keep == "first" {
+		limit++
+	}
+	if s.forwarded < limit {
+		s.forwarded++
+		acc.AddMetric(m)
+		return nil
+	}
+
+	switch t.Keep {
+	case "first":
+		t.drop(m)
+	case "last":
+		if s.pending != nil {
+			t.drop(s.pending)
+		}
+		s.pending = m
+	case "max":
+		value, err := t.value(m)
+		if err != nil {
+			t.Log.Debugf("Dropping metric %q: %v", m.Name(), err)
+			t.drop(m)
+			return nil
+		}
+		if s.pending != nil && value <= s.value {
+			t.drop(m)
+			return nil
+		}
+		if s.pending != nil {
+			t.drop(s.pending)
+		}
+		s.pending = m
+		s.value = value
+	}
+
+	return nil
+}
+
+func (t *Throttle) Stop() {
+	if t.done != nil {
+		close(t.done)
+		t.wg.Wait()
+	}
+	t.flush()
+}
+
+// flush forwards the pending points of all series and starts a new period
+func (t *Throttle) flush() {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, s := range t.series {
+		if s.pending != nil {
+			t.acc.AddMetric(s.pending)
+		}
+	}
+	t.series = make(map[uint64]*series, len(t.series))
+}
+
+// value returns the value of the configured field as float
+func (t *Throttle) value(m telegraf.Metric) (float64, error) {
+	raw, found := m.GetField(t.Field)
+	if !found {
+		return 0, fmt.Errorf("field %q not found", t.Field)
+	}
+	value, err := internal.ToFloat64(raw)
+	if err != nil {
+		return 0, fmt.Errorf("converting field %q failed: %w", t.Field, err)
+	}
+	return value, nil
+}
+
+func (t *Throttle) drop(m telegraf.Metric) {
+	t.dropped.Incr(1)
+	m.Drop()
+}
+
+func init() {
+	processors.AddStreaming("throttle", func() telegraf.StreamingProcessor {
+		return &Throttle{
+			Period: config.Duration(10 * time.Second),
+			Keep:   "first",
+			Field:  "value",
+		}
+	})
+}
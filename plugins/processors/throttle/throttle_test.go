@@ -0,0 +1,211 @@
+package throttle
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Throttle
+		expected string
+	}{
+		{
+			name:     "no period",
+			plugin:   &Throttle{},
+			expected: "period must be positive",
+		},
+		{
+			name:     "negative burst",
+			plugin:   &Throttle{Period: config.Duration(time.Second), Burst: -1},
+			expected: "burst must not be negative",
+		},
+		{
+			name:     "invalid keep",
+			plugin:   &Throttle{Period: config.Duration(time.Second), Keep: "foo"},
+			expected: `invalid keep setting "foo"`,
+		},
+		{
+			name:     "max without field",
+			plugin:   &Throttle{Period: config.Duration(time.Second), Keep: "max"},
+			expected: "field required for keeping the maximum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestKeep(t *testing.T) {
+	input := []telegraf.Metric{
+		point("a", 3, 1),
+		point("b", 1, 2),
+		point("a", 5, 3),
+		point("a", 4, 4),
+		point("b", 2, 5),
+	}
+
+	tests := []struct {
+		name     string
+		keep     string
+		burst    int
+		expected []telegraf.Metric
+	}{
+		{
+			name: "first",
+			keep: "first",
+			expected: []telegraf.Metric{
+				point("a", 3, 1),
+				point("b", 1, 2),
+			},
+		},
+		{
+			name: "last",
+			keep: "last",
+			expected: []telegraf.Metric{
+				point("a", 4, 4),
+				point("b", 2, 5),
+			},
+		},
+		{
+			name: "max",
+			keep: "max",
+			expected: []telegraf.Metric{
+				point("a", 5, 3),
+				point("b", 2, 5),
+			},
+		},
+		{
+			name:  "first with burst",
+			keep:  "first",
+			burst: 1,
+			expected: []telegraf.Metric{
+				point("a", 3, 1),
+				point("b", 1, 2),
+				point("a", 5, 3),
+				point("b", 2, 5),
+			},
+		},
+		{
+			name:  "last with burst",
+			keep:  "last",
+			burst: 1,
+			expected: []telegraf.Metric{
+				point("a", 3, 1),
+				point("b", 1, 2),
+				point("b", 2, 5),
+				point("a", 4, 4),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Throttle{
+				Period: config.Duration(time.Hour),
+				Keep:   tt.keep,
+				Field:  "value",
+				Burst:  tt.burst,
+				Log:    testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			for _, m := range input {
+				require.NoError(t, plugin.Add(m.Copy(), &acc))
+			}
+			plugin.Stop()
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+		})
+	}
+}
+
+func TestNewPeriod(t *testing.T) {
+	plugin := &Throttle{
+		Period: config.Duration(time.Hour),
+		Keep:   "last",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Add(point("a", 1, 1), &acc))
+	require.NoError(t, plugin.Add(point("a", 2, 2), &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	plugin.flush()
+	require.NoError(t, plugin.Add(point("a", 3, 3), &acc))
+	plugin.flush()
+
+	expected := []telegraf.Metric{
+		point("a", 2, 2),
+		point("a", 3, 3),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	input := make([]telegraf.Metric, 0, 3)
+	for i := 0; i < 3; i++ {
+		m, _ := metric.WithTracking(point("a", float64(i), int64(i)), notify)
+		input = append(input, m)
+	}
+
+	plugin := &Throttle{
+		Period: config.Duration(time.Hour),
+		Keep:   "first",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == len(input)
+	}, time.Second, 100*time.Millisecond)
+}
+
+func point(host string, value float64, ts int64) telegraf.Metric {
+	return metric.New(
+		"cpu",
+		map[string]string{"host": host},
+		map[string]interface{}{"value": value},
+		time.Unix(ts, 0),
+	)
+}
@@ -0,0 +1,96 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package timestamp
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Timestamp struct {
+	SourceField     string          `toml:"source_field"`
+	SourceFormat    string          `toml:"source_format"`
+	KeepSourceField bool            `toml:"keep_source_field"`
+	Offset          config.Duration `toml:"offset"`
+	Truncate        config.Duration `toml:"truncate"`
+	Timezone        string          `toml:"timezone"`
+	Log             telegraf.Logger `toml:"-"`
+
+	location *time.Location
+}
+
+func (*Timestamp) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Timestamp) Init() error {
+	if t.SourceField == "" && t.Offset == 0 && t.Truncate == 0 {
+		return errors.New("no source field, offset or truncation specified")
+	}
+	if t.SourceField != "" && t.SourceFormat == "" {
+		return errors.New("source format required")
+	}
+	if t.Truncate < 0 {
+		return errors.New("truncation interval must not be negative")
+	}
+
+	location, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return fmt.Errorf("loading timezone %q failed: %w", t.Timezone, err)
+	}
+	t.location = location
+
+	return nil
+}
+
+func (t *Timestamp) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		ts := m.Time()
+		if t.SourceField != "" {
+			if raw, found := m.GetField(t.SourceField); found {
+				parsed, err := internal.ParseTimestamp(t.SourceFormat, raw, t.location)
+				if err != nil {
+					t.Log.Errorf("Parsing timestamp %v of metric %q failed: %v", raw, m.Name(), err)
+					continue
+				}
+				ts = parsed
+				if !t.KeepSourceField {
+					m.RemoveField(t.SourceField)
+				}
+			}
+		}
+
+		ts = ts.Add(time.Duration(t.Offset))
+		if t.Truncate > 0 {
+			ts = t.truncate(ts)
+		}
+		m.SetTime(ts)
+	}
+	return in
+}
+
+// truncate aligns the given time to multiples of the truncation interval
+// relative to the configured timezone, e.g. to local midnight for full days
+func (t *Timestamp) truncate(ts time.Time) time.Time {
+	_, offset := ts.In(t.location).Zone()
+	shift := time.Duration(offset) * time.Second
+	return ts.Add(shift).Truncate(time.Duration(t.Truncate)).Add(-shift)
+}
+
+func init() {
+	processors.Add("timestamp", func() telegraf.Processor {
+		return &Timestamp{
+			SourceFormat: "unix",
+			Timezone:     "UTC",
+		}
+	})
+}
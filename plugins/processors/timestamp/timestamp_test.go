@@ -0,0 +1,179 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Timestamp
+		expected string
+	}{
+		{
+			name:     "nothing to do",
+			plugin:   &Timestamp{SourceFormat: "unix"},
+			expected: "no source field, offset or truncation specified",
+		},
+		{
+			name:     "no source format",
+			plugin:   &Timestamp{SourceField: "time"},
+			expected: "source format required",
+		},
+		{
+			name:     "negative truncation",
+			plugin:   &Timestamp{Truncate: config.Duration(-time.Minute)},
+			expected: "truncation interval must not be negative",
+		},
+		{
+			name:     "invalid timezone",
+			plugin:   &Timestamp{Offset: config.Duration(time.Hour), Timezone: "Mars/Olympus_Mons"},
+			expected: `loading timezone "Mars/Olympus_Mons" failed: unknown time zone Mars/Olympus_Mons`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Timestamp
+		fields   map[string]interface{}
+		ts       int64
+		expected telegraf.Metric
+	}{
+		{
+			name:   "replace from unix field",
+			plugin: &Timestamp{SourceField: "event_time", SourceFormat: "unix"},
+			fields: map[string]interface{}{"value": 42, "event_time": int64(1696152000)},
+			ts:     1696160000,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(1696152000, 0),
+			),
+		},
+		{
+			name:   "replace from rfc3339 field keeping the source",
+			plugin: &Timestamp{SourceField: "event_time", SourceFormat: "RFC3339", KeepSourceField: true},
+			fields: map[string]interface{}{"value": 42, "event_time": "2023-10-01T11:20:00+02:00"},
+			ts:     1696160000,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42, "event_time": "2023-10-01T11:20:00+02:00"},
+				time.Unix(1696152000, 0),
+			),
+		},
+		{
+			name: "replace from layout with timezone",
+			plugin: &Timestamp{
+				SourceField:  "event_time",
+				SourceFormat: "2006-01-02 15:04:05",
+				Timezone:     "Europe/Berlin",
+			},
+			fields: map[string]interface{}{"value": 42, "event_time": "2023-10-01 09:20:00"},
+			ts:     1696160000,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(1696144800, 0),
+			),
+		},
+		{
+			name:   "missing source field",
+			plugin: &Timestamp{SourceField: "event_time", SourceFormat: "unix"},
+			fields: map[string]interface{}{"value": 42},
+			ts:     1696160000,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(1696160000, 0),
+			),
+		},
+		{
+			name:   "invalid source field",
+			plugin: &Timestamp{SourceField: "event_time", SourceFormat: "unix", Offset: config.Duration(time.Hour)},
+			fields: map[string]interface{}{"value": 42, "event_time": "yesterday"},
+			ts:     1696160000,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42, "event_time": "yesterday"},
+				time.Unix(1696160000, 0),
+			),
+		},
+		{
+			name:   "shift",
+			plugin: &Timestamp{Offset: config.Duration(-time.Hour)},
+			fields: map[string]interface{}{"value": 42},
+			ts:     1696160000,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(1696156400, 0),
+			),
+		},
+		{
+			name:   "truncate",
+			plugin: &Timestamp{Truncate: config.Duration(time.Minute)},
+			fields: map[string]interface{}{"value": 42},
+			ts:     1696152042,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(1696152000, 0),
+			),
+		},
+		{
+			name:   "truncate to local day",
+			plugin: &Timestamp{Truncate: config.Duration(24 * time.Hour), Timezone: "Europe/Berlin"},
+			fields: map[string]interface{}{"value": 42},
+			ts:     1696116600,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(1696111200, 0),
+			),
+		},
+		{
+			name: "replace, shift and truncate",
+			plugin: &Timestamp{
+				SourceField:  "event_time",
+				SourceFormat: "unix_ms",
+				Offset:       config.Duration(30 * time.Second),
+				Truncate:     config.Duration(time.Minute),
+			},
+			fields: map[string]interface{}{"value": 42, "event_time": int64(1696151975000)},
+			ts:     1696160000,
+			expected: metric.New("test",
+				map[string]string{},
+				map[string]interface{}{"value": 42},
+				time.Unix(1696152000, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := tt.plugin
+			plugin.Log = testutil.Logger{}
+			require.NoError(t, plugin.Init())
+
+			input := metric.New("test", map[string]string{}, tt.fields, time.Unix(tt.ts, 0))
+			actual := plugin.Apply(input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual)
+		})
+	}
+}
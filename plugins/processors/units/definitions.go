@@ -0,0 +1,65 @@
+package units
+
+// unit defines the conversion of a unit to the base unit of its dimension
+// via 'base = value * factor + offset'
+type unit struct {
+	dimension string
+	factor    float64
+	offset    float64
+	suffix    string
+	aliases   []string
+}
+
+var definitions = []unit{
+	// Information, base unit is byte
+	{"information", 1.0 / 8, 0, "bits", []string{"bit", "bits"}},
+	{"information", 1, 0, "bytes", []string{"B", "byte", "bytes"}},
+	{"information", 1e3, 0, "kb", []string{"kB", "KB", "kilobyte", "kilobytes"}},
+	{"information", 1e6, 0, "mb", []string{"MB", "megabyte", "megabytes"}},
+	{"information", 1e9, 0, "gb", []string{"GB", "gigabyte", "gigabytes"}},
+	{"information", 1e12, 0, "tb", []string{"TB", "terabyte", "terabytes"}},
+	{"information", 1e15, 0, "pb", []string{"PB", "petabyte", "petabytes"}},
+	{"information", 1 << 10, 0, "kib", []string{"KiB", "kibibyte", "kibibytes"}},
+	{"information", 1 << 20, 0, "mib", []string{"MiB", "mebibyte", "mebibytes"}},
+	{"information", 1 << 30, 0, "gib", []string{"GiB", "gibibyte", "gibibytes"}},
+	{"information", 1 << 40, 0, "tib", []string{"TiB", "tebibyte", "tebibytes"}},
+	{"information", 1 << 50, 0, "pib", []string{"PiB", "pebibyte", "pebibytes"}},
+
+	// Time, base unit is second
+	{"time", 1e-9, 0, "ns", []string{"ns", "nanosecond", "nanoseconds"}},
+	{"time", 1e-6, 0, "us", []string{"us", "µs", "microsecond", "microseconds"}},
+	{"time", 1e-3, 0, "ms", []string{"ms", "millisecond", "milliseconds"}},
+	{"time", 1, 0, "seconds", []string{"s", "sec", "second", "seconds"}},
+	{"time", 60, 0, "minutes", []string{"min", "minute", "minutes"}},
+	{"time", 3600, 0, "hours", []string{"h", "hour", "hours"}},
+	{"time", 86400, 0, "days", []string{"d", "day", "days"}},
+
+	// Temperature, base unit is Kelvin
+	{"temperature", 1, 0, "kelvin", []string{"K", "kelvin"}},
+	{"temperature", 1, 273.15, "celsius", []string{"C", "°C", "degC", "celsius"}},
+	{"temperature", 5.0 / 9, 273.15 - 32*5.0/9, "fahrenheit", []string{"F", "°F", "degF", "fahrenheit"}},
+
+	// Frequency, base unit is Hertz
+	{"frequency", 1, 0, "hz", []string{"Hz", "hertz"}},
+	{"frequency", 1e3, 0, "khz", []string{"kHz", "kilohertz"}},
+	{"frequency", 1e6, 0, "mhz", []string{"MHz", "megahertz"}},
+	{"frequency", 1e9, 0, "ghz", []string{"GHz", "gigahertz"}},
+}
+
+// units maps all aliases to their unit definition
+var units = func() map[string]*unit {
+	m := make(map[string]*unit)
+	for i := range definitions {
+		for _, alias := range definitions[i].aliases {
+			m[alias] = &definitions[i]
+		}
+	}
+	return m
+}()
+
+// convert the value from the given unit to the target unit of the same
+// dimension
+func convert(value float64, from, to *unit) float64 {
+	base := value*from.factor + from.offset
+	return (base - to.offset) / to.factor
+}
@@ -0,0 +1,166 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package units
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Conversion struct {
+	Fields   []string `toml:"fields"`
+	From     string   `toml:"from"`
+	To       string   `toml:"to"`
+	UnitTag  string   `toml:"unit_tag"`
+	KeepName bool     `toml:"keep_name"`
+
+	fieldFilter filter.Filter
+	from        *unit
+	to          *unit
+}
+
+type Units struct {
+	Conversions []Conversion    `toml:"conversion"`
+	Log         telegraf.Logger `toml:"-"`
+}
+
+func (*Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Conversion) Init() error {
+	if len(c.Fields) == 0 {
+		return errors.New("no fields defined")
+	}
+	if c.From == "" && c.UnitTag == "" {
+		return errors.New("either 'from' or 'unit_tag' is required")
+	}
+
+	to, found := units[c.To]
+	if !found {
+		return fmt.Errorf("unknown target unit %q", c.To)
+	}
+	c.to = to
+
+	if c.From != "" {
+		from, found := units[c.From]
+		if !found {
+			return fmt.Errorf("unknown source unit %q", c.From)
+		}
+		if from.dimension != to.dimension {
+			return fmt.Errorf("cannot convert %s %q to %s %q", from.dimension, c.From, to.dimension, c.To)
+		}
+		c.from = from
+	}
+
+	f, err := filter.Compile(c.Fields)
+	if err != nil {
+		return fmt.Errorf("could not compile fields filter: %w", err)
+	}
+	c.fieldFilter = f
+
+	return nil
+}
+
+func (u *Units) Init() error {
+	if len(u.Conversions) == 0 {
+		return errors.New("no conversion defined")
+	}
+
+	for i := range u.Conversions {
+		if err := u.Conversions[i].Init(); err != nil {
+			return fmt.Errorf("conversion %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		for i := range u.Conversions {
+			u.convertFields(m, &u.Conversions[i])
+		}
+	}
+	return in
+}
+
+// convertFields converts all matching fields of the metric according to the
+// given conversion
+func (u *Units) convertFields(m telegraf.Metric, c *Conversion) {
+	from := c.from
+	if c.UnitTag != "" {
+		if name, found := m.GetTag(c.UnitTag); found {
+			from = units[name]
+			if from == nil {
+				u.Log.Debugf("Unknown unit %q in tag %q of metric %q", name, c.UnitTag, m.Name())
+				return
+			}
+			if from.dimension != c.to.dimension {
+				u.Log.Debugf("Cannot convert %s %q to %s %q in metric %q", from.dimension, name, c.to.dimension, c.To, m.Name())
+				return
+			}
+		}
+	}
+	if from == nil {
+		return
+	}
+
+	// Collect the fields first as renaming modifies the field list
+	var converted []*telegraf.Field
+	for _, field := range m.FieldList() {
+		if c.fieldFilter.Match(field.Key) {
+			converted = append(converted, field)
+		}
+	}
+	if len(converted) == 0 {
+		return
+	}
+
+	for _, field := range converted {
+		v, err := internal.ToFloat64(field.Value)
+		if err != nil {
+			u.Log.Errorf("Error converting %q to float: %v", field.Key, err)
+			continue
+		}
+		value := convert(v, from, c.to)
+
+		if c.KeepName {
+			field.Value = value
+			continue
+		}
+		m.RemoveField(field.Key)
+		m.AddField(rename(field.Key, from, c.to), value)
+	}
+
+	if c.UnitTag != "" {
+		m.AddTag(c.UnitTag, c.To)
+	}
+}
+
+// rename replaces the unit suffix of the given field name by the suffix of
+// the target unit or appends the target suffix if no unit suffix is found
+func rename(key string, from, to *unit) string {
+	lower := strings.ToLower(key)
+	for _, alias := range append([]string{from.suffix}, from.aliases...) {
+		suffix := "_" + strings.ToLower(alias)
+		if strings.HasSuffix(lower, suffix) && len(key) > len(suffix) {
+			return key[:len(key)-len(suffix)] + "_" + to.suffix
+		}
+	}
+	return key + "_" + to.suffix
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{}
+	})
+}
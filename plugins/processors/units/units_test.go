@@ -0,0 +1,160 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		conversion Conversion
+		expected   string
+	}{
+		{
+			name:       "no fields",
+			conversion: Conversion{From: "B", To: "GiB"},
+			expected:   "conversion 1: no fields defined",
+		},
+		{
+			name:       "no source",
+			conversion: Conversion{Fields: []string{"*"}, To: "GiB"},
+			expected:   "conversion 1: either 'from' or 'unit_tag' is required",
+		},
+		{
+			name:       "unknown target",
+			conversion: Conversion{Fields: []string{"*"}, From: "B", To: "foo"},
+			expected:   `conversion 1: unknown target unit "foo"`,
+		},
+		{
+			name:       "unknown source",
+			conversion: Conversion{Fields: []string{"*"}, From: "foo", To: "GiB"},
+			expected:   `conversion 1: unknown source unit "foo"`,
+		},
+		{
+			name:       "dimension mismatch",
+			conversion: Conversion{Fields: []string{"*"}, From: "ms", To: "GiB"},
+			expected:   `conversion 1: cannot convert time "ms" to information "GiB"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Units{Conversions: []Conversion{tt.conversion}}
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestConversion(t *testing.T) {
+	tests := []struct {
+		name        string
+		conversions []Conversion
+		input       telegraf.Metric
+		expected    telegraf.Metric
+	}{
+		{
+			name:        "bytes to GiB",
+			conversions: []Conversion{{Fields: []string{"*_bytes"}, From: "B", To: "GiB"}},
+			input: testutil.MustMetric("mem",
+				map[string]string{},
+				map[string]interface{}{"used_bytes": uint64(3 << 30), "used_percent": 50.0},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric("mem",
+				map[string]string{},
+				map[string]interface{}{"used_gib": 3.0, "used_percent": 50.0},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "ns to ms keeping the name",
+			conversions: []Conversion{{Fields: []string{"latency"}, From: "ns", To: "ms", KeepName: true}},
+			input: testutil.MustMetric("http",
+				map[string]string{},
+				map[string]interface{}{"latency": int64(2500000)},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric("http",
+				map[string]string{},
+				map[string]interface{}{"latency": 2.5},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "append suffix",
+			conversions: []Conversion{{Fields: []string{"uptime"}, From: "s", To: "h"}},
+			input: testutil.MustMetric("system",
+				map[string]string{},
+				map[string]interface{}{"uptime": int64(7200)},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric("system",
+				map[string]string{},
+				map[string]interface{}{"uptime_hours": 2.0},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "Celsius to Fahrenheit",
+			conversions: []Conversion{{Fields: []string{"temp_c"}, From: "C", To: "F"}},
+			input: testutil.MustMetric("sensors",
+				map[string]string{},
+				map[string]interface{}{"temp_c": 100.0},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric("sensors",
+				map[string]string{},
+				map[string]interface{}{"temp_fahrenheit": 212.0},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "unit tag",
+			conversions: []Conversion{{Fields: []string{"value"}, From: "K", UnitTag: "unit", To: "C", KeepName: true}},
+			input: testutil.MustMetric("sensors",
+				map[string]string{"unit": "F"},
+				map[string]interface{}{"value": 32.0},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric("sensors",
+				map[string]string{"unit": "C"},
+				map[string]interface{}{"value": 0.0},
+				time.Unix(0, 0),
+			),
+		},
+		{
+			name:        "unit tag with invalid dimension",
+			conversions: []Conversion{{Fields: []string{"value"}, UnitTag: "unit", To: "C"}},
+			input: testutil.MustMetric("sensors",
+				map[string]string{"unit": "ms"},
+				map[string]interface{}{"value": 32.0},
+				time.Unix(0, 0),
+			),
+			expected: testutil.MustMetric("sensors",
+				map[string]string{"unit": "ms"},
+				map[string]interface{}{"value": 32.0},
+				time.Unix(0, 0),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Units{
+				Conversions: tt.conversions,
+				Log:         testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := plugin.Apply(tt.input)
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, actual, cmpopts.EquateApprox(0, 1e-9))
+		})
+	}
+}
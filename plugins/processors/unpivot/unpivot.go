@@ -4,8 +4,10 @@ package unpivot
 import (
 	_ "embed"
 	"fmt"
+	"strings"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
@@ -13,9 +15,15 @@ import (
 var sampleConfig string
 
 type Unpivot struct {
-	FieldNameAs string `toml:"use_fieldname_as"`
-	TagKey      string `toml:"tag_key"`
-	ValueKey    string `toml:"value_key"`
+	FieldNameAs     string   `toml:"use_fieldname_as"`
+	TagKey          string   `toml:"tag_key"`
+	TagKeys         []string `toml:"tag_keys"`
+	Separator       string   `toml:"separator"`
+	ValueKey        string   `toml:"value_key"`
+	ValueTypeSuffix bool     `toml:"value_type_suffix"`
+	Fields          []string `toml:"fields"`
+
+	fieldFilter filter.Filter
 }
 
 func copyWithoutFields(metric telegraf.Metric) telegraf.Metric {
@@ -52,6 +60,15 @@ func (p *Unpivot) Init() error {
 	if p.ValueKey == "" {
 		p.ValueKey = "value"
 	}
+	if p.Separator == "" {
+		p.Separator = "_"
+	}
+
+	f, err := filter.Compile(p.Fields)
+	if err != nil {
+		return fmt.Errorf("compiling fields failed: %w", err)
+	}
+	p.fieldFilter = f
 
 	return nil
 }
@@ -65,24 +82,86 @@ func (p *Unpivot) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 
 	for _, m := range metrics {
 		base := copyWithoutFields(m)
+		remaining := make([]*telegraf.Field, 0)
 		for _, field := range m.FieldList() {
-			newMetric := base.Copy()
-			newMetric.AddField(p.ValueKey, field.Value)
+			if p.fieldFilter != nil && !p.fieldFilter.Match(field.Key) {
+				remaining = append(remaining, field)
+				continue
+			}
 
+			newMetric := base.Copy()
 			switch p.FieldNameAs {
 			case "metric":
 				newMetric.SetName(field.Key)
 			case "", "tag":
-				newMetric.AddTag(p.TagKey, field.Key)
+				tags, ok := p.tags(field.Key)
+				if !ok {
+					remaining = append(remaining, field)
+					continue
+				}
+				for k, v := range tags {
+					newMetric.AddTag(k, v)
+				}
 			}
+			newMetric.AddField(p.valueKey(field.Value), field.Value)
 
 			results = append(results, newMetric)
 		}
+
+		// Keep the fields not unpivoted in the original shape
+		if len(remaining) > 0 {
+			newMetric := base.Copy()
+			for _, field := range remaining {
+				newMetric.AddField(field.Key, field.Value)
+			}
+			results = append(results, newMetric)
+		}
 		m.Accept()
 	}
 	return results
 }
 
+// tags returns the tags to add for the given field name. With multiple tag
+// keys the name is split at the separator and the last tag receives the
+// remainder, names with too few parts cannot be unpivoted.
+func (p *Unpivot) tags(name string) (map[string]string, bool) {
+	if len(p.TagKeys) == 0 {
+		return map[string]string{p.TagKey: name}, true
+	}
+
+	parts := strings.SplitN(name, p.Separator, len(p.TagKeys))
+	if len(parts) != len(p.TagKeys) {
+		return nil, false
+	}
+	tags := make(map[string]string, len(parts))
+	for i, k := range p.TagKeys {
+		tags[k] = parts[i]
+	}
+	return tags, true
+}
+
+// valueKey returns the name of the value field, suffixed by the type of the
+// value if requested to avoid type conflicts across the resulting series
+func (p *Unpivot) valueKey(value interface{}) string {
+	if !p.ValueTypeSuffix {
+		return p.ValueKey
+	}
+
+	switch value.(type) {
+	case int64:
+		return p.ValueKey + "_int"
+	case uint64:
+		return p.ValueKey + "_uint"
+	case float64:
+		return p.ValueKey + "_float"
+	case bool:
+		return p.ValueKey + "_bool"
+	case string:
+		return p.ValueKey + "_string"
+	}
+	return p.ValueKey
+}
+
 func init() {
 	processors.Add("unpivot", func() telegraf.Processor {
 		return &Unpivot{}
@@ -219,3 +219,164 @@ func TestUnpivot_fieldMode(t *testing.T) {
 		})
 	}
 }
+
+func TestUnpivot_options(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		unpivot  *Unpivot
+		metrics  []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "field selection",
+			unpivot: &Unpivot{
+				Fields: []string{"if*Octets"},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("interface",
+					map[string]string{
+						"ifName": "eth0",
+					},
+					map[string]interface{}{
+						"ifInOctets":  uint64(1024),
+						"ifOutOctets": uint64(2048),
+						"ifMtu":       int64(1500),
+						"ifAlias":     "uplink",
+					},
+					now,
+				),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("interface",
+					map[string]string{
+						"ifName": "eth0",
+						"name":   "ifInOctets",
+					},
+					map[string]interface{}{
+						"value": uint64(1024),
+					},
+					now,
+				),
+				testutil.MustMetric("interface",
+					map[string]string{
+						"ifName": "eth0",
+						"name":   "ifOutOctets",
+					},
+					map[string]interface{}{
+						"value": uint64(2048),
+					},
+					now,
+				),
+				testutil.MustMetric("interface",
+					map[string]string{
+						"ifName": "eth0",
+					},
+					map[string]interface{}{
+						"ifMtu":   int64(1500),
+						"ifAlias": "uplink",
+					},
+					now,
+				),
+			},
+		},
+		{
+			name: "multiple tag keys",
+			unpivot: &Unpivot{
+				TagKeys: []string{"ifName", "name"},
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("interface",
+					map[string]string{},
+					map[string]interface{}{
+						"eth0_in_octets": uint64(1024),
+						"uptime":         int64(42),
+					},
+					now,
+				),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("interface",
+					map[string]string{
+						"ifName": "eth0",
+						"name":   "in_octets",
+					},
+					map[string]interface{}{
+						"value": uint64(1024),
+					},
+					now,
+				),
+				testutil.MustMetric("interface",
+					map[string]string{},
+					map[string]interface{}{
+						"uptime": int64(42),
+					},
+					now,
+				),
+			},
+		},
+		{
+			name: "value type suffix",
+			unpivot: &Unpivot{
+				ValueTypeSuffix: true,
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric("device",
+					map[string]string{},
+					map[string]interface{}{
+						"state":       "up",
+						"temperature": 42.5,
+						"errors":      int64(3),
+						"enabled":     true,
+					},
+					now,
+				),
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("device",
+					map[string]string{
+						"name": "state",
+					},
+					map[string]interface{}{
+						"value_string": "up",
+					},
+					now,
+				),
+				testutil.MustMetric("device",
+					map[string]string{
+						"name": "temperature",
+					},
+					map[string]interface{}{
+						"value_float": 42.5,
+					},
+					now,
+				),
+				testutil.MustMetric("device",
+					map[string]string{
+						"name": "errors",
+					},
+					map[string]interface{}{
+						"value_int": int64(3),
+					},
+					now,
+				),
+				testutil.MustMetric("device",
+					map[string]string{
+						"name": "enabled",
+					},
+					map[string]interface{}{
+						"value_bool": true,
+					},
+					now,
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.unpivot.Init())
+			actual := tt.unpivot.Apply(tt.metrics...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.SortMetrics())
+		})
+	}
+}
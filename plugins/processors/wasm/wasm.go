@@ -0,0 +1,273 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package wasm
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/processors"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Size of a WebAssembly memory page
+const pageSize = 64 * 1024
+
+type WASM struct {
+	Module      string            `toml:"module"`
+	Timeout     config.Duration   `toml:"timeout"`
+	MemoryLimit config.Size       `toml:"memory_limit"`
+	Environment map[string]string `toml:"environment"`
+	Log         telegraf.Logger   `toml:"-"`
+
+	parser     *influx.Parser
+	serializer *serializer.Serializer
+
+	runtime    wazero.Runtime
+	compiled   wazero.CompiledModule
+	modcfg     wazero.ModuleConfig
+	module     api.Module
+	allocate   api.Function
+	deallocate api.Function
+	apply      api.Function
+}
+
+func (*WASM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WASM) Init() error {
+	if w.Module == "" {
+		return errors.New("no module specified")
+	}
+	if w.Timeout <= 0 {
+		w.Timeout = config.Duration(5 * time.Second)
+	}
+	if w.MemoryLimit != 0 && w.MemoryLimit < pageSize {
+		return fmt.Errorf("memory limit must be at least %d bytes", pageSize)
+	}
+
+	w.parser = &influx.Parser{}
+	if err := w.parser.Init(); err != nil {
+		return fmt.Errorf("initializing parser failed: %w", err)
+	}
+	w.serializer = &serializer.Serializer{UintSupport: true}
+	if err := w.serializer.Init(); err != nil {
+		return fmt.Errorf("initializing serializer failed: %w", err)
+	}
+
+	return nil
+}
+
+func (w *WASM) Start(_ telegraf.Accumulator) error {
+	code, err := os.ReadFile(w.Module)
+	if err != nil {
+		return fmt.Errorf("reading module failed: %w", err)
+	}
+
+	ctx := context.Background()
+	cfg := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if w.MemoryLimit > 0 {
+		cfg = cfg.WithMemoryLimitPages(uint32(w.MemoryLimit / pageSize))
+	}
+	w.runtime = wazero.NewRuntimeWithConfig(ctx, cfg)
+
+	// Modules compiled for WASI, e.g. by TinyGo or Rust, require the system
+	// interface even if only using a small subset of it
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, w.runtime); err != nil {
+		w.Stop()
+		return fmt.Errorf("instantiating WASI failed: %w", err)
+	}
+	_, err = w.runtime.NewHostModuleBuilder("telegraf").
+		NewFunctionBuilder().WithFunc(w.log).Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		w.Stop()
+		return fmt.Errorf("instantiating host module failed: %w", err)
+	}
+
+	w.compiled, err = w.runtime.CompileModule(ctx, code)
+	if err != nil {
+		w.Stop()
+		return fmt.Errorf("compiling module failed: %w", err)
+	}
+
+	// Only a reactor initialization function is run, the module must not
+	// have a main function blocking the instantiation
+	w.modcfg = wazero.NewModuleConfig().
+		WithName("processor").
+		WithStartFunctions("_initialize").
+		WithStdout(&logWriter{w.Log.Info}).
+		WithStderr(&logWriter{w.Log.Error})
+	for k, v := range w.Environment {
+		w.modcfg = w.modcfg.WithEnv(k, v)
+	}
+	if err := w.instantiate(ctx); err != nil {
+		w.Stop()
+		return err
+	}
+
+	return nil
+}
+
+func (w *WASM) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	input, err := w.serializer.Serialize(m)
+	if err != nil {
+		return fmt.Errorf("serializing metric failed: %w", err)
+	}
+
+	output, err := w.call(input)
+	if err != nil {
+		// The module is closed on timeouts and might be in an inconsistent
+		// state after a trap, so start over with a fresh instance
+		if ierr := w.instantiate(context.Background()); ierr != nil {
+			return fmt.Errorf("%w; restarting module failed: %w", err, ierr)
+		}
+		return err
+	}
+
+	metrics, err := w.parser.Parse(output)
+	if err != nil {
+		return fmt.Errorf("parsing module output failed: %w", err)
+	}
+
+	// The module output cannot be tied to the input metric so accept the
+	// original metric if the module produced any output
+	if len(metrics) == 0 {
+		m.Drop()
+		return nil
+	}
+	m.Accept()
+	for _, out := range metrics {
+		acc.AddMetric(out)
+	}
+
+	return nil
+}
+
+func (w *WASM) Stop() {
+	if w.runtime != nil {
+		// Closing the runtime closes all modules and compiled code
+		if err := w.runtime.Close(context.Background()); err != nil {
+			w.Log.Errorf("Closing runtime failed: %v", err)
+		}
+		w.runtime = nil
+		w.module = nil
+	}
+}
+
+// instantiate creates a new instance of the compiled module replacing the
+// existing instance
+func (w *WASM) instantiate(ctx context.Context) error {
+	if w.module != nil {
+		// Closing fails if the module was already closed due to a timeout
+		_ = w.module.Close(ctx)
+		w.module = nil
+	}
+
+	module, err := w.runtime.InstantiateModule(ctx, w.compiled, w.modcfg)
+	if err != nil {
+		return fmt.Errorf("instantiating module failed: %w", err)
+	}
+	w.module = module
+
+	w.allocate = module.ExportedFunction("allocate")
+	w.deallocate = module.ExportedFunction("deallocate")
+	w.apply = module.ExportedFunction("apply")
+	if w.allocate == nil || w.apply == nil {
+		return errors.New("module must export 'allocate' and 'apply' functions")
+	}
+
+	return nil
+}
+
+// call passes the input to the module's apply function and returns a copy of
+// the output returned by the module
+func (w *WASM) call(input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(w.Timeout))
+	defer cancel()
+
+	size := uint64(len(input))
+	results, err := w.allocate.Call(ctx, size)
+	if err != nil {
+		return nil, fmt.Errorf("allocating memory failed: %w", err)
+	}
+	ptr := results[0]
+	if !w.module.Memory().Write(uint32(ptr), input) {
+		return nil, fmt.Errorf("writing input of %d bytes at %d out of range", size, ptr)
+	}
+
+	results, err = w.apply.Call(ctx, ptr, size)
+	if err != nil {
+		return nil, fmt.Errorf("calling apply failed: %w", err)
+	}
+
+	// The result packs the pointer to the output in the upper and the length
+	// in the lower 32 bits
+	outPtr, outSize := uint32(results[0]>>32), uint32(results[0])
+	buf, ok := w.module.Memory().Read(outPtr, outSize)
+	if !ok {
+		return nil, fmt.Errorf("reading output of %d bytes at %d out of range", outSize, outPtr)
+	}
+	output := make([]byte, len(buf))
+	copy(output, buf)
+
+	if w.deallocate != nil && outSize > 0 {
+		if _, err := w.deallocate.Call(ctx, uint64(outPtr), uint64(outSize)); err != nil {
+			return nil, fmt.Errorf("deallocating memory failed: %w", err)
+		}
+	}
+
+	return output, nil
+}
+
+// log is exported to the module to write messages to the Telegraf log
+func (w *WASM) log(_ context.Context, m api.Module, level, ptr, size uint32) {
+	buf, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		w.Log.Errorf("Reading log message of %d bytes at %d out of range", size, ptr)
+		return
+	}
+
+	switch level {
+	case 0:
+		w.Log.Error(string(buf))
+	case 1:
+		w.Log.Warn(string(buf))
+	case 2:
+		w.Log.Info(string(buf))
+	default:
+		w.Log.Debug(string(buf))
+	}
+}
+
+// logWriter forwards the module's standard output to the Telegraf log
+type logWriter struct {
+	log func(args ...interface{})
+}
+
+func (lw *logWriter) Write(p []byte) (int, error) {
+	lw.log(string(p))
+	return len(p), nil
+}
+
+func init() {
+	processors.AddStreaming("wasm", func() telegraf.StreamingProcessor {
+		return &WASM{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package wasm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// Module exporting 'allocate' returning a fixed buffer at offset 1024 and
+// 'apply' returning its input unmodified
+var echoModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> i32 and (i32, i32) -> i64
+	0x01, 0x0c, 0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e,
+	// Functions
+	0x03, 0x03, 0x02, 0x00, 0x01,
+	// Memory with one page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Exports: memory, allocate, apply
+	0x07, 0x1d, 0x03,
+	0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00,
+	0x08, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x00, 0x00,
+	0x05, 0x61, 0x70, 0x70, 0x6c, 0x79, 0x00, 0x01,
+	// Code: allocate returns 1024, apply returns ptr << 32 | len
+	0x0a, 0x14, 0x02,
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	0x0c, 0x00, 0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84, 0x0b,
+}
+
+// Valid module without any exports
+var emptyModule = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+func TestInitFail(t *testing.T) {
+	plugin := &WASM{}
+	require.EqualError(t, plugin.Init(), "no module specified")
+
+	plugin = &WASM{Module: "processor.wasm", MemoryLimit: 1024}
+	require.EqualError(t, plugin.Init(), "memory limit must be at least 65536 bytes")
+}
+
+func TestStartMissingExports(t *testing.T) {
+	plugin := &WASM{
+		Module: writeModule(t, emptyModule),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.EqualError(t, plugin.Start(&acc), "module must export 'allocate' and 'apply' functions")
+}
+
+func TestEcho(t *testing.T) {
+	plugin := &WASM{
+		Module: writeModule(t, echoModule),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 42.5, "count": uint64(3)},
+			time.Unix(1, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{"used": int64(1024), "state": "ok"},
+			time.Unix(2, 0),
+		),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m.Copy(), &acc))
+	}
+
+	testutil.RequireMetricsEqual(t, input, acc.GetTelegrafMetrics())
+}
+
+func writeModule(t *testing.T, code []byte) string {
+	fn := filepath.Join(t.TempDir(), "processor.wasm")
+	require.NoError(t, os.WriteFile(fn, code, 0600))
+	return fn
+}
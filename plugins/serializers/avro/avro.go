@@ -0,0 +1,258 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// Magic byte of the Confluent wire format preceding the schema ID
+const magicByte = 0x00
+
+var invalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Serializer encodes metrics as Avro records in the Confluent wire format.
+// The record schema is derived from the tags and fields of each metric and is
+// registered in or looked up from the schema registry.
+type Serializer struct {
+	SchemaRegistry   string `toml:"avro_schema_registry"`
+	SchemaRegistryCA string `toml:"avro_schema_registry_cert"`
+	Namespace        string `toml:"avro_namespace"`
+	SubjectStrategy  string `toml:"avro_subject_strategy"`
+	SubjectTopic     string `toml:"avro_subject_topic"`
+	AutoRegister     bool   `toml:"avro_auto_register"`
+
+	registry *schemaregistry.Registry
+
+	// Cache of subject and schema to schema ID and codec
+	cache map[string]*schema
+	sync.Mutex
+}
+
+type schema struct {
+	id    int
+	codec *goavro.Codec
+}
+
+func (s *Serializer) Init() error {
+	if s.SchemaRegistry == "" {
+		return errors.New("'avro_schema_registry' is required")
+	}
+
+	switch s.SubjectStrategy {
+	case "":
+		s.SubjectStrategy = "record"
+	case "record":
+	case "topic", "topic_record":
+		if s.SubjectTopic == "" {
+			return fmt.Errorf("'avro_subject_topic' is required for subject strategy %q", s.SubjectStrategy)
+		}
+	default:
+		return fmt.Errorf("invalid 'avro_subject_strategy' %q", s.SubjectStrategy)
+	}
+
+	registry, err := schemaregistry.New(s.SchemaRegistry, s.SchemaRegistryCA)
+	if err != nil {
+		return err
+	}
+	s.registry = registry
+	s.cache = make(map[string]*schema)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	name, schemaDef, err := s.buildSchema(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	fullname := name
+	if s.Namespace != "" {
+		fullname = s.Namespace + "." + name
+	}
+
+	var subject string
+	switch s.SubjectStrategy {
+	case "record":
+		subject = fullname
+	case "topic":
+		subject = s.SubjectTopic + "-value"
+	case "topic_record":
+		subject = s.SubjectTopic + "-" + fullname
+	}
+
+	sc, err := s.lookup(subject, schemaDef)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]interface{}, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		tags[avroName(tag.Key)] = goavro.Union("string", tag.Value)
+	}
+	fields := make(map[string]interface{}, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		v := field.Value
+		if u, ok := v.(uint64); ok {
+			if u > math.MaxInt64 {
+				return nil, fmt.Errorf("value %d of field %q exceeds the range of an Avro long", u, field.Key)
+			}
+			v = int64(u)
+		}
+		fields[avroName(field.Key)] = goavro.Union(avroType(field.Value), v)
+	}
+
+	native := map[string]interface{}{
+		"time":   metric.Time().UnixNano(),
+		"tags":   tags,
+		"fields": fields,
+	}
+
+	buf := make([]byte, 5, 256)
+	buf[0] = magicByte
+	binary.BigEndian.PutUint32(buf[1:], uint32(sc.id))
+	return sc.codec.BinaryFromNative(buf, native)
+}
+
+// SerializeBatch is not supported as the Confluent wire format only allows
+// for a single record per message
+func (*Serializer) SerializeBatch([]telegraf.Metric) ([]byte, error) {
+	return nil, errors.New("batch serialization is not supported by the avro format")
+}
+
+func (s *Serializer) lookup(subject, schemaDef string) (*schema, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	key := subject + "\n" + schemaDef
+	if sc, found := s.cache[key]; found {
+		return sc, nil
+	}
+
+	codec, err := goavro.NewCodec(schemaDef)
+	if err != nil {
+		return nil, fmt.Errorf("creating codec for subject %q failed: %w", subject, err)
+	}
+
+	var id int
+	if s.AutoRegister {
+		id, err = s.registry.Register(subject, schemaDef)
+	} else {
+		id, err = s.registry.Lookup(subject, schemaDef)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting schema ID for subject %q failed: %w", subject, err)
+	}
+
+	sc := &schema{id: id, codec: codec}
+	s.cache[key] = sc
+	return sc, nil
+}
+
+// buildSchema returns the record name and the schema for the metric. All
+// tags and fields are optional to allow for schema evolution when new tags or
+// fields appear.
+func (s *Serializer) buildSchema(metric telegraf.Metric) (string, string, error) {
+	name := avroName(metric.Name())
+
+	tags := make([]map[string]interface{}, 0, len(metric.TagList()))
+	seen := make(map[string]string, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		n := avroName(tag.Key)
+		if other, found := seen[n]; found {
+			return "", "", fmt.Errorf("tags %q and %q both map to Avro name %q", other, tag.Key, n)
+		}
+		seen[n] = tag.Key
+		tags = append(tags, map[string]interface{}{
+			"name":    n,
+			"type":    []string{"null", "string"},
+			"default": nil,
+		})
+	}
+
+	fields := make([]map[string]interface{}, 0, len(metric.FieldList()))
+	seen = make(map[string]string, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		n := avroName(field.Key)
+		if other, found := seen[n]; found {
+			return "", "", fmt.Errorf("fields %q and %q both map to Avro name %q", other, field.Key, n)
+		}
+		seen[n] = field.Key
+		fields = append(fields, map[string]interface{}{
+			"name":    n,
+			"type":    []string{"null", avroType(field.Value)},
+			"default": nil,
+		})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i]["name"].(string) < tags[j]["name"].(string) })
+	sort.Slice(fields, func(i, j int) bool { return fields[i]["name"].(string) < fields[j]["name"].(string) })
+
+	schemaDef := map[string]interface{}{
+		"type": "record",
+		"name": name,
+		"fields": []map[string]interface{}{
+			{"name": "time", "type": "long", "doc": "timestamp in nanoseconds since epoch"},
+			{"name": "tags", "type": map[string]interface{}{
+				"type":   "record",
+				"name":   name + "_tags",
+				"fields": tags,
+			}},
+			{"name": "fields", "type": map[string]interface{}{
+				"type":   "record",
+				"name":   name + "_fields",
+				"fields": fields,
+			}},
+		},
+	}
+	if s.Namespace != "" {
+		schemaDef["namespace"] = s.Namespace
+	}
+
+	// Marshalling maps is deterministic as keys are sorted
+	buf, err := json.Marshal(schemaDef)
+	if err != nil {
+		return "", "", err
+	}
+	return name, string(buf), nil
+}
+
+func avroType(v interface{}) string {
+	switch v.(type) {
+	case float64:
+		return "double"
+	case int64, uint64:
+		return "long"
+	case bool:
+		return "boolean"
+	}
+	return "string"
+}
+
+// avroName converts the given string into a valid Avro name
+func avroName(s string) string {
+	name := invalidChars.ReplaceAllString(s, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+func init() {
+	serializers.Add("avro",
+		func() serializers.Serializer {
+			return &Serializer{AutoRegister: true}
+		},
+	)
+}
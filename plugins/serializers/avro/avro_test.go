@@ -0,0 +1,228 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
+)
+
+func TestSerialize(t *testing.T) {
+	var registered atomic.Int32
+	var schemaDef atomic.Value
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/subjects/telegraf.metrics.disk_io/versions", r.URL.Path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		schemaDef.Store(body["schema"])
+		registered.Add(1)
+
+		_, err := w.Write([]byte(`{"id": 42}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	s := &Serializer{
+		SchemaRegistry: ts.URL,
+		Namespace:      "telegraf.metrics",
+		AutoRegister:   true,
+	}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"disk-io",
+		map[string]string{"host": "a", "name": "sda"},
+		map[string]interface{}{"reads": int64(12), "writes": uint64(3), "util": 0.5, "ok": true, "mode": "rw"},
+		time.Unix(1700000000, 0),
+	)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	// The schema must only be registered once
+	_, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), registered.Load())
+
+	// Check the Confluent wire format
+	require.Equal(t, byte(magicByte), buf[0])
+	require.Equal(t, uint32(42), binary.BigEndian.Uint32(buf[1:5]))
+
+	codec, err := goavro.NewCodec(schemaDef.Load().(string))
+	require.NoError(t, err)
+	native, remaining, err := codec.NativeFromBinary(buf[5:])
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+
+	record := native.(map[string]interface{})
+	require.Equal(t, int64(1700000000000000000), record["time"])
+	tags := record["tags"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"string": "sda"}, tags["name"])
+	fields := record["fields"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"long": int64(12)}, fields["reads"])
+	require.Equal(t, map[string]interface{}{"long": int64(3)}, fields["writes"])
+	require.Equal(t, map[string]interface{}{"double": 0.5}, fields["util"])
+	require.Equal(t, map[string]interface{}{"boolean": true}, fields["ok"])
+	require.Equal(t, map[string]interface{}{"string": "rw"}, fields["mode"])
+	require.Contains(t, codec.Schema(), `"name":"disk_io"`)
+	require.Contains(t, codec.Schema(), `"namespace":"telegraf.metrics"`)
+}
+
+func TestSubjectStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		expected string
+	}{
+		{"record", "/subjects/ns.cpu/versions"},
+		{"topic", "/subjects/telegraf-value/versions"},
+		{"topic_record", "/subjects/telegraf-ns.cpu/versions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			var path atomic.Value
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path.Store(r.URL.Path)
+				_, err := w.Write([]byte(`{"id": 1}`))
+				require.NoError(t, err)
+			}))
+			defer ts.Close()
+
+			s := &Serializer{
+				SchemaRegistry:  ts.URL,
+				Namespace:       "ns",
+				SubjectStrategy: tt.strategy,
+				SubjectTopic:    "telegraf",
+				AutoRegister:    true,
+			}
+			require.NoError(t, s.Init())
+
+			m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+			_, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, path.Load())
+		})
+	}
+}
+
+func TestLookupWithoutRegistration(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/subjects/cpu", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"error_code": 40401, "message": "Subject not found"}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	s := &Serializer{SchemaRegistry: ts.URL}
+	require.NoError(t, s.Init())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	_, err := s.Serialize(m)
+	require.ErrorContains(t, err, "Subject not found")
+	require.NotErrorIs(t, err, schemaregistry.ErrRequest)
+}
+
+func TestRegistryUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	s := &Serializer{SchemaRegistry: ts.URL, AutoRegister: true}
+	require.NoError(t, s.Init())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	_, err := s.Serialize(m)
+	require.ErrorIs(t, err, schemaregistry.ErrRequest)
+}
+
+func TestSerializeInvalid(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"id": 1}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		fields   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "tag name collision",
+			tags:     map[string]string{"a.b": "x", "a-b": "y"},
+			fields:   map[string]interface{}{"value": 1.0},
+			expected: `both map to Avro name "a_b"`,
+		},
+		{
+			name:     "field name collision",
+			fields:   map[string]interface{}{"a.b": 1.0, "a-b": 2.0},
+			expected: `both map to Avro name "a_b"`,
+		},
+		{
+			name:     "unsigned out of range",
+			fields:   map[string]interface{}{"value": uint64(math.MaxInt64) + 1},
+			expected: `value 9223372036854775808 of field "value" exceeds the range of an Avro long`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Serializer{SchemaRegistry: ts.URL, AutoRegister: true}
+			require.NoError(t, s.Init())
+
+			m := metric.New("cpu", tt.tags, tt.fields, time.Unix(0, 0))
+			_, err := s.Serialize(m)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer *Serializer
+		expected   string
+	}{
+		{
+			name:       "missing registry",
+			serializer: &Serializer{},
+			expected:   "'avro_schema_registry' is required",
+		},
+		{
+			name:       "invalid strategy",
+			serializer: &Serializer{SchemaRegistry: "http://localhost:8081", SubjectStrategy: "foo"},
+			expected:   `invalid 'avro_subject_strategy' "foo"`,
+		},
+		{
+			name:       "missing topic",
+			serializer: &Serializer{SchemaRegistry: "http://localhost:8081", SubjectStrategy: "topic"},
+			expected:   "'avro_subject_topic' is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.serializer.Init(), tt.expected)
+		})
+	}
+}
+
+func TestAvroName(t *testing.T) {
+	require.Equal(t, "disk_io", avroName("disk-io"))
+	require.Equal(t, "_1min", avroName("1min"))
+	require.Equal(t, "_", avroName(""))
+}
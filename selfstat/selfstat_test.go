@@ -211,3 +211,36 @@ func TestRegisterCopy(t *testing.T) {
 	tags["new"] = "value"
 	require.NotEqual(t, tags, stat.Tags())
 }
+
+func TestUnregister(t *testing.T) {
+	testLock.Lock()
+	defer testCleanup()
+	foo := map[string]string{"test": "foo"}
+	bar := map[string]string{"test": "bar"}
+	s1 := Register("test", "test_field1", foo)
+	s1.Set(10)
+	Register("test", "test_field2", foo)
+	Register("test", "test_field1", bar)
+
+	Unregister("test", "test_field1", foo)
+	_, found := registry.get(key("internal_test", foo), "test_field1")
+	require.False(t, found)
+	_, found = registry.get(key("internal_test", foo), "test_field2")
+	require.True(t, found)
+	_, found = registry.get(key("internal_test", bar), "test_field1")
+	require.True(t, found)
+
+	// Registering again should start with a new stat
+	s1 = Register("test", "test_field1", foo)
+	require.Equal(t, int64(0), s1.Get())
+
+	// Removing all fields should remove the measurement
+	Unregister("test", "test_field1", foo)
+	Unregister("test", "test_field2", foo)
+	require.NotContains(t, registry.stats, key("internal_test", foo))
+
+	// Unregistering unknown stats should be ignored
+	Unregister("test", "test_field1", map[string]string{"test": "baz"})
+	_, found = registry.get(key("internal_test", bar), "test_field1")
+	require.True(t, found)
+}